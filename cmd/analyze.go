@@ -1,19 +1,43 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+	"github.com/Yates-Labs/thunk/internal/narrative"
 	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"github.com/Yates-Labs/thunk/internal/redact"
+	"github.com/Yates-Labs/thunk/internal/state"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 )
 
 var (
-	exportFile string
+	exportFile    string
+	analyzeFormat string
+	analyzeSite   string
+	analyzeOrg    bool
+	orgInclude    []string
+	orgExclude    []string
+	orgArchived   bool
+	orgForks      bool
+	semantic      bool
+	algorithm     string
+	membership    string
+	splitOnTags   bool
+	boundaries    []string
+	artifactEps   bool
+	coChange      bool
+	sessions      bool
+	planning      bool
+	allBranches   bool
+	analyzeState  string
 )
 
 var analyzeCmd = &cobra.Command{
@@ -30,41 +54,151 @@ Each episode shows:
 Examples:
   thunk analyze /path/to/local/repo
   thunk analyze https://github.com/user/repo
-  thunk analyze https://github.com/user/repo --export episodes.json`,
+  thunk analyze https://github.com/user/repo --export episodes.json
+  thunk analyze https://github.com/user/repo --format md > report.md
+  thunk analyze https://github.com/user/repo --site docs
+  thunk analyze my-org --org --include "api-*" --exclude "*-legacy"
+  thunk analyze /path/to/local/repo --semantic
+  thunk analyze /path/to/local/repo --algorithm agglomerative
+  thunk analyze https://github.com/user/repo --algorithm pr
+  thunk analyze /path/to/local/repo --membership soft
+  thunk analyze /path/to/local/repo --modules
+  thunk analyze /path/to/local/repo --sessions
+  thunk analyze /path/to/local/repo --all-branches
+  thunk analyze https://github.com/user/repo --state state.sqlite
+  thunk analyze https://github.com/user/repo --milestones
+  thunk analyze /path/to/local/repo --boundary-tags "v*"`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAnalyze,
 }
 
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
-	analyzeCmd.Flags().StringVar(&exportFile, "export", "", "Export episodes to JSON file: --export <filename>")
+	analyzeCmd.Flags().StringVar(&exportFile, "export", "", "Export the episode report to a file, as JSON unless --format md: --export <filename>")
+	analyzeCmd.Flags().StringVar(&analyzeFormat, "format", "", "Print the episode report as json or md (Markdown) instead of a table")
+	analyzeCmd.Flags().StringVar(&analyzeSite, "site", "", "Write a static HTML site of the episodes to this directory, e.g. for GitHub Pages")
+	analyzeCmd.Flags().BoolVar(&analyzeOrg, "org", false, "Treat the argument as a GitHub organization and analyze all of its repositories")
+	analyzeCmd.Flags().StringSliceVar(&orgInclude, "include", nil, "With --org, only analyze repositories matching these glob patterns")
+	analyzeCmd.Flags().StringSliceVar(&orgExclude, "exclude", nil, "With --org, skip repositories matching these glob patterns")
+	analyzeCmd.Flags().BoolVar(&orgArchived, "archived", false, "With --org, include archived repositories")
+	analyzeCmd.Flags().BoolVar(&orgForks, "forks", false, "With --org, include forks")
+	analyzeCmd.Flags().BoolVar(&semantic, "semantic", false, "Group commits by embedding similarity of their messages and diffs (requires OPENAI_API_KEY)")
+	analyzeCmd.Flags().StringVar(&algorithm, "algorithm", string(cluster.AlgorithmGreedy), "Clustering algorithm: greedy, agglomerative, dbscan, or pr (one episode per merged pull request)")
+	analyzeCmd.Flags().StringVar(&membership, "membership", string(cluster.MembershipExclusive), "Episode membership: exclusive, or soft to let commits that fit several episodes join each of them")
+	analyzeCmd.Flags().BoolVar(&splitOnTags, "split-releases", false, "Never group commits across a release (GitHub release or git tag)")
+	analyzeCmd.Flags().BoolVar(&coChange, "modules", false, "Favor grouping commits in the same module, learned from which files change together")
+	analyzeCmd.Flags().BoolVar(&planning, "milestones", false, "Favor grouping commits whose issues and PRs share a milestone or label")
+	analyzeCmd.Flags().BoolVar(&sessions, "sessions", false, "Split episodes into work sessions at pauses of over 3h, shorter for authors who commit at a fast pace")
+	analyzeCmd.Flags().BoolVar(&allBranches, "all-branches", false, "Analyze the commits of every local and remote branch, favoring grouping commits from the same feature branch")
+	analyzeCmd.Flags().StringVar(&analyzeState, "state", "", "Only analyze the commits, issues and pull requests added since the last analysis recorded in this state database, and record this one there")
+	analyzeCmd.Flags().BoolVar(&artifactEps, "artifact-episodes", true, "Group discussed issues that no commit references into episodes of their own")
+	analyzeCmd.Flags().StringSliceVar(&boundaries, "boundary-tags", nil, "Only split at releases whose tag matches these glob patterns (implies --split-releases)")
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
 	repo := args[0]
-	ctx := context.Background()
+	ctx := cmd.Context()
+
+	var err error
+	if analyzeOrg && analyzeState != "" {
+		return fmt.Errorf("--state can't be combined with --org")
+	}
+	reportFormat := export.FormatJSON
+	if analyzeFormat != "" {
+		if reportFormat, err = export.ParseFormat(analyzeFormat); err != nil {
+			return err
+		}
+	}
+	config := cluster.DefaultGroupingConfig()
+	if semantic {
+		semanticConfig, err := orchestrator.SemanticGroupingConfig(redact.Policy{})
+		if err != nil {
+			return err
+		}
+		config = semanticConfig
+	}
+	config.Algorithm, err = cluster.ParseAlgorithm(algorithm)
+	if err != nil {
+		return err
+	}
+	config.Membership, err = cluster.ParseMembership(membership)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range boundaries {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid boundary tag pattern %q: %w", pattern, err)
+		}
+	}
+	config.SplitOnReleases = splitOnTags
+	config.BoundaryTags = boundaries
+	config.ArtifactEpisodes = artifactEps
+	if coChange {
+		config.ModuleWeight = 0.15
+	}
+	if planning {
+		config.MilestoneWeight = 0.15
+		config.LabelWeight = 0.1
+	}
+	if sessions {
+		config.SessionGap = cluster.DefaultSessionGap
+		config.AdaptiveSessionGap = true
+	}
+	config.AllBranches = allBranches
 
 	// Run the analysis
-	episodes, err := orchestrator.AnalyzeRepository(ctx, repo)
+	var episodes []cluster.Episode
+	if analyzeOrg {
+		opts := orchestrator.DefaultOrganizationOptions()
+		opts.Grouping = config
+		opts.Include = orgInclude
+		opts.Exclude = orgExclude
+		opts.IncludeArchived = orgArchived
+		opts.IncludeForks = orgForks
+		episodes, err = orchestrator.AnalyzeOrganization(ctx, repo, opts)
+	} else if analyzeState != "" {
+		states, openErr := state.NewSQLiteStore(ctx, analyzeState)
+		if openErr != nil {
+			return openErr
+		}
+		defer states.Close()
+		episodes, err = orchestrator.AnalyzeRepositoryWithState(ctx, repo, states, config)
+	} else {
+		episodes, err = orchestrator.AnalyzeRepositoryWithConfig(ctx, repo, config)
+	}
 	if err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
 
 	if len(episodes) == 0 {
+		if analyzeState != "" {
+			fmt.Println("No new activity since the last analysis")
+			return nil
+		}
 		fmt.Println("No episodes found in repository")
 		return nil
 	}
 
-	// Handle export flag
+	// Handle export and format flags
+	opts := export.Options{}
+	if !analyzeOrg {
+		opts.RepositoryURL = repositoryWebURL(repo)
+	}
+	if analyzeSite != "" {
+		return writeSite(analyzeSite, episodes, nil, opts)
+	}
 	if exportFile != "" {
-		return handleExport(episodes, exportFile)
+		return handleExport(episodes, exportFile, reportFormat, opts)
+	}
+	if analyzeFormat != "" {
+		return writeReport(os.Stdout, episodes, reportFormat, opts)
 	}
 
 	// Default: output table
 	return outputTable(episodes)
 }
 
-func handleExport(episodes []cluster.Episode, filename string) error {
+func handleExport(episodes []cluster.Episode, filename, format string, opts export.Options) error {
 	// Create output file
 	file, err := os.Create(filename)
 	if err != nil {
@@ -72,8 +206,7 @@ func handleExport(episodes []cluster.Episode, filename string) error {
 	}
 	defer file.Close()
 
-	// Export episodes as JSON
-	if err := cluster.ExportEpisodes(episodes, "json", file); err != nil {
+	if err := writeReport(file, episodes, format, opts); err != nil {
 		return fmt.Errorf("export failed: %w", err)
 	}
 
@@ -81,6 +214,32 @@ func handleExport(episodes []cluster.Episode, filename string) error {
 	return nil
 }
 
+// writeReport writes the episode report in format
+func writeReport(w io.Writer, episodes []cluster.Episode, format string, opts export.Options) error {
+	if format == export.FormatMarkdown {
+		return export.ExportEpisodesMarkdown(w, episodes, opts)
+	}
+	return export.ExportEpisodesJSON(w, episodes, opts)
+}
+
+// writeSite writes the static site of episodes and their narratives to dir
+func writeSite(dir string, episodes []cluster.Episode, narratives []*narrative.Narrative, opts export.Options) error {
+	if err := export.WriteSite(dir, episodes, narratives, opts); err != nil {
+		return fmt.Errorf("failed to write site: %w", err)
+	}
+	fmt.Printf("✓ Wrote a site of %d episodes to %s (open %s)\n", len(episodes), dir, filepath.Join(dir, "index.html"))
+	return nil
+}
+
+// repositoryWebURL returns the web address of a remote repository, for linking commits in reports;
+// local paths have none
+func repositoryWebURL(repo string) string {
+	if !strings.HasPrefix(repo, "https://") && !strings.HasPrefix(repo, "http://") {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git")
+}
+
 func outputTable(episodes []cluster.Episode) error {
 	// LipGloss signature purple/pink palette
 	var (
@@ -144,6 +303,10 @@ func outputTable(episodes []cluster.Episode) error {
 					startDate.Format("Jan 02, 15:04"),
 					endDate.Format("Jan 02, 15:04"))
 			}
+		} else if startDate, endDate := ep.GetDateRange(); !startDate.IsZero() {
+			dateRange = fmt.Sprintf("%s → %s (no commits)",
+				startDate.Format("Jan 02, 15:04"),
+				endDate.Format("Jan 02, 15:04"))
 		} else {
 			dateRange = "No commits"
 		}
@@ -183,11 +346,10 @@ func outputTable(episodes []cluster.Episode) error {
 
 	// Calculate and print summary
 	fmt.Println()
-	totalCommits := 0
+	totalCommits := cluster.CountUniqueCommits(episodes)
 	allAuthors := make(map[string]bool)
 
 	for _, ep := range episodes {
-		totalCommits += len(ep.Commits)
 		authors := ep.GetCommitAuthors()
 		for _, author := range authors {
 			allAuthors[author.Email] = true
@@ -201,6 +363,9 @@ func outputTable(episodes []cluster.Episode) error {
 	summary := fmt.Sprintf("Total: %d episodes, %d commits, %d unique authors",
 		len(episodes), totalCommits, len(allAuthors))
 	fmt.Println(summaryStyle.Render(summary))
+	if mix := cluster.FormatCategoryCounts(cluster.CountCategories(episodes)); mix != "" {
+		fmt.Println(summaryStyle.Render("Work mix: " + mix))
+	}
 
 	return nil
 }
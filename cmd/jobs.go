@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/jobs"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jobsDB         string
+	jobsFormat     string
+	jobsKind       string
+	jobsStatus     string
+	jobsLimit      int
+	jobsWait       bool
+	jobsWorkers    int
+	jobsAttempts   int
+	jobsOnce       bool
+	jobsLocalStore string
+	jobsSQLite     string
+	jobsMigrate    bool
+	jobsEmbedder   string
+	jobsLLM        string
+	jobsLLMModel   string
+	jobsLLMURL     string
+	jobsState      string
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Queue, run and track background analysis and indexing jobs",
+	Long: `Queue repository analysis and indexing as background jobs, run them with workers, and
+track their progress.
+
+Jobs are kept in a SQLite database (--db), so they survive restarts and can be queued
+by one process and worked by another. Each job is retried with backoff when it fails,
+up to --attempts times; a worker that stops part way through a job leaves it to be
+reclaimed by another once its lease runs out.
+
+Job kinds:
+  analyze - Group a repository's history into episodes
+  index   - Analyze a repository and index its episodes into the vector store
+
+Examples:
+  thunk jobs enqueue index https://github.com/user/repo
+  thunk jobs work --local-store episodes.db
+  thunk jobs list --status failed
+  thunk jobs retry 3f9a1c2b7d4e5f60`,
+}
+
+var jobsEnqueueCmd = &cobra.Command{
+	Use:   "enqueue <analyze|index> <repository>",
+	Short: "Queue a job for a repository",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runJobsEnqueue,
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List jobs, most recent first",
+	Args:  cobra.NoArgs,
+	RunE:  runJobsList,
+}
+
+var jobsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a job with its parameters, progress and result as JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsShow,
+}
+
+var jobsCancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Cancel a job that hasn't finished",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateJob(args[0], "Canceled", (*jobs.Queue).Cancel)
+	},
+}
+
+var jobsRetryCmd = &cobra.Command{
+	Use:   "retry <id>",
+	Short: "Queue a failed or canceled job to run again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateJob(args[0], "Queued", (*jobs.Queue).Retry)
+	},
+}
+
+var jobsWorkCmd = &cobra.Command{
+	Use:   "work",
+	Short: "Run queued jobs until interrupted",
+	Long: `Run queued jobs with --workers workers until interrupted, or with --once until none
+is ready to run. Interrupted jobs go back to the queue without using up an attempt.
+
+Required environment variables:
+  OPENAI_API_KEY    - Required unless embeddings and episode summaries are local or fake
+  GITHUB_TOKEN      - Fetch issues and pull requests of GitHub repositories (optional)
+  MILVUS_ADDRESS    - Milvus server address (default: localhost:19530, unused with --local-store or --sqlite-store)
+  MILVUS_COLLECTION - Milvus collection episodes are indexed into (default: thunk_episodes)`,
+	Args: cobra.NoArgs,
+	RunE: runJobsWork,
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsEnqueueCmd, jobsListCmd, jobsShowCmd, jobsCancelCmd, jobsRetryCmd, jobsWorkCmd)
+	jobsCmd.PersistentFlags().StringVar(&jobsDB, "db", "", "Job database (default: jobs.sqlite in the user config directory, or $"+jobs.DatabaseEnv+")")
+
+	jobsEnqueueCmd.Flags().IntVar(&jobsAttempts, "attempts", jobs.DefaultOptions().MaxAttempts, "Attempts the job gets before it fails")
+	jobsEnqueueCmd.Flags().BoolVar(&jobsWait, "wait", false, "Wait for the job to finish, printing its progress")
+
+	jobsListCmd.Flags().StringVar(&jobsKind, "kind", "", "Only list jobs of this kind")
+	jobsListCmd.Flags().StringVar(&jobsStatus, "status", "", "Only list jobs with this status: pending, running, succeeded, failed or canceled")
+	jobsListCmd.Flags().IntVar(&jobsLimit, "limit", 20, "Most recent jobs to list; 0 lists all")
+	jobsListCmd.Flags().StringVar(&jobsFormat, "format", "text", "Output format: text or json")
+
+	jobsWorkCmd.Flags().IntVar(&jobsWorkers, "workers", 1, "Jobs to run at once")
+	jobsWorkCmd.Flags().BoolVar(&jobsOnce, "once", false, "Exit once no job is ready to run, instead of waiting for more")
+	jobsWorkCmd.Flags().StringVar(&jobsLocalStore, "local-store", "", "Keep the vector index in this file instead of Milvus")
+	jobsWorkCmd.Flags().StringVar(&jobsSQLite, "sqlite-store", "", "Keep the vector index in this SQLite database instead of Milvus")
+	jobsWorkCmd.Flags().BoolVar(&jobsMigrate, "migrate", false, "Migrate a vector store built with an older schema or embedding dimension")
+	jobsWorkCmd.Flags().StringVar(&jobsEmbedder, "embedder", rag.EmbedderProviderOpenAI, "Embedding provider: openai, or fake for offline testing")
+	jobsWorkCmd.Flags().StringVar(&jobsLLM, "llm", narrative.ProviderOpenAI, "LLM provider for episode summaries: openai, or local for an OpenAI-compatible endpoint such as Ollama")
+	jobsWorkCmd.Flags().StringVar(&jobsLLMModel, "llm-model", "", "LLM model (default: gpt-4o for OpenAI; required with --llm local)")
+	jobsWorkCmd.Flags().StringVar(&jobsLLMURL, "llm-url", "", "Base URL of the local LLM endpoint (default: Ollama at "+narrative.DefaultLocalBaseURL+")")
+	jobsWorkCmd.Flags().StringVar(&jobsState, "state", "", "Record indexed episodes in this state database, so index jobs skip unchanged episodes and re-embed changed ones")
+	jobsWorkCmd.MarkFlagsMutuallyExclusive("local-store", "sqlite-store")
+	jobsWorkCmd.MarkFlagsMutuallyExclusive("workers", "once")
+}
+
+// openJobQueue opens the job database and a queue over it that handles analyze and index jobs
+// with config
+func openJobQueue(ctx context.Context, config orchestrator.RAGConfig, opts jobs.Options, pipelineOpts ...orchestrator.PipelineOption) (*jobs.Queue, func() error, error) {
+	path := jobsDB
+	if path == "" {
+		var err error
+		if path, err = jobs.DefaultDatabasePath(); err != nil {
+			return nil, nil, err
+		}
+	}
+	store, err := jobs.NewSQLiteStore(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	queue := jobs.NewQueue(store, opts)
+	orchestrator.RegisterJobs(queue, config, pipelineOpts...)
+	return queue, store.Close, nil
+}
+
+func runJobsEnqueue(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	queue, closeStore, err := openJobQueue(ctx, orchestrator.DefaultRAGConfig(), jobs.Options{MaxAttempts: jobsAttempts})
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	job, err := queue.Enqueue(ctx, args[0], orchestrator.RepositoryJob{Repository: args[1]})
+	if err != nil {
+		return fmt.Errorf("failed to queue job (kinds are %s and %s): %w", orchestrator.JobAnalyze, orchestrator.JobIndex, err)
+	}
+	fmt.Printf("✓ Queued %s job %s\n", job.Kind, job.ID)
+	if !jobsWait {
+		return nil
+	}
+
+	// Progress is printed as it changes until a worker finishes the job
+	var last jobs.Progress
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if job, err = queue.Get(ctx, job.ID); err != nil {
+			return err
+		}
+		if job.Progress != last && job.Progress.Message != "" {
+			fmt.Printf("  %s\n", progressText(job))
+			last = job.Progress
+		}
+		if job.Status.Done() {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	if job.Status != jobs.StatusSucceeded {
+		return fmt.Errorf("job %s %s: %s", job.ID, job.Status, job.Error)
+	}
+	fmt.Printf("✓ Job %s succeeded: %s\n", job.ID, job.Result)
+	return nil
+}
+
+func runJobsList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	status, err := jobs.ParseStatus(jobsStatus)
+	if err != nil {
+		return err
+	}
+	queue, closeStore, err := openJobQueue(ctx, orchestrator.DefaultRAGConfig(), jobs.Options{})
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	list, err := queue.List(ctx, jobs.Filter{Kind: jobsKind, Status: status, Limit: jobsLimit})
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(jobsFormat) {
+	case "json":
+		return printJSON(list)
+	case "text":
+	default:
+		return fmt.Errorf("unknown format %q: expected text or json", jobsFormat)
+	}
+	if len(list) == 0 {
+		fmt.Println("No jobs")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tSTATUS\tATTEMPTS\tCREATED\tREPOSITORY\tPROGRESS")
+	for i := range list {
+		job := &list[i]
+		var params orchestrator.RepositoryJob
+		json.Unmarshal(job.Params, &params)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\t%s\t%s\n", job.ID, job.Kind, job.Status, job.Attempts, job.MaxAttempts,
+			job.CreatedAt.Local().Format("2006-01-02 15:04"), params.Repository, progressText(job))
+	}
+	return w.Flush()
+}
+
+func runJobsShow(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	queue, closeStore, err := openJobQueue(ctx, orchestrator.DefaultRAGConfig(), jobs.Options{})
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	job, err := queue.Get(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("job %s: %w", args[0], err)
+	}
+	return printJSON(job)
+}
+
+// updateJob cancels or retries a job with update, reporting its new status as verb
+func updateJob(id, verb string, update func(*jobs.Queue, context.Context, string) (*jobs.Job, error)) error {
+	ctx := context.Background()
+	queue, closeStore, err := openJobQueue(ctx, orchestrator.DefaultRAGConfig(), jobs.Options{})
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	job, err := update(queue, ctx, id)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", id, err)
+	}
+	fmt.Printf("✓ %s %s job %s\n", verb, job.Kind, job.ID)
+	return nil
+}
+
+func runJobsWork(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loadEnvFile(".env")
+	config, err := ragConfigFromFlags(ragFlags{
+		embedder:    jobsEmbedder,
+		llm:         jobsLLM,
+		llmModel:    jobsLLMModel,
+		llmURL:      jobsLLMURL,
+		localStore:  jobsLocalStore,
+		sqliteStore: jobsSQLite,
+		migrate:     jobsMigrate,
+	})
+	if err != nil {
+		return err
+	}
+
+	if jobsState != "" {
+		states, err := state.NewSQLiteStore(ctx, jobsState)
+		if err != nil {
+			return err
+		}
+		defer states.Close()
+		config.StateStore = states
+	}
+
+	// Workers share one vector store, so jobs don't overwrite each other's records in a local store
+	vectorStore, err := orchestrator.NewVectorStore(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer vectorStore.Close()
+
+	queue, closeStore, err := openJobQueue(ctx, config, jobs.Options{Workers: jobsWorkers}, orchestrator.WithSharedVectorStore(vectorStore))
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if jobsOnce {
+		ran, err := queue.RunPending(ctx)
+		fmt.Printf("✓ Ran %d jobs\n", ran)
+		return err
+	}
+	fmt.Printf("Working jobs with %d workers\n", jobsWorkers)
+	queue.Run(ctx)
+	return nil
+}
+
+// progressText describes how far a job has got
+func progressText(job *jobs.Job) string {
+	progress := job.Progress
+	text := progress.Message
+	if progress.Total > 0 {
+		text = fmt.Sprintf("%s (%d/%d)", text, progress.Done, progress.Total)
+	}
+	if job.Error != "" {
+		text = strings.TrimSpace(text + " - " + job.Error)
+	}
+	return text
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -1,13 +1,28 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
 
+var (
+	logLevel  string
+	logFormat string
+	logQuiet  bool
+
+	reportFile   string
+	reportStrict bool
+
+	// runReport records the items of the command's run that failed or were skipped
+	runReport *report.RunReport
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "thunk",
 	Short: "Thunk - Repository episode analysis tool",
@@ -15,6 +30,84 @@ var rootCmd = &cobra.Command{
 	
 It ingests repository data, applies clustering algorithms, and presents
 development activity as coherent episodes with timing and authorship details.`,
+	PersistentPreRunE:  setupRun,
+	PersistentPostRunE: checkRunReport,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Log level: debug, info, warn or error (default info, or THUNK_LOG_LEVEL)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format: text or json (default text, or THUNK_LOG_FORMAT)")
+	rootCmd.PersistentFlags().BoolVarP(&logQuiet, "quiet", "q", false, "Don't log progress or warnings")
+	rootCmd.PersistentFlags().StringVar(&reportFile, "report", "", "Write a JSON report of the artifacts, narratives and other items that failed or were skipped to this file")
+	rootCmd.PersistentFlags().BoolVar(&reportStrict, "strict", false, "Fail the command when any item failed or was skipped, instead of carrying on without it")
+}
+
+// setupRun configures logging and starts the command's run report
+func setupRun(cmd *cobra.Command, args []string) error {
+	if err := setupLogging(cmd, args); err != nil {
+		return err
+	}
+	runReport = report.New()
+	cmd.SetContext(report.With(cmd.Context(), runReport))
+	return nil
+}
+
+// setupLogging configures the pipeline's logger from the logging flags, and identifies the
+// command's run in the records it logs
+func setupLogging(cmd *cobra.Command, args []string) error {
+	if logQuiet {
+		logging.SetLogger(logging.Discard())
+	} else {
+		level, err := logging.ParseLevel(flagOrEnv(logLevel, "THUNK_LOG_LEVEL"))
+		if err != nil {
+			return err
+		}
+		format, err := logging.ParseFormat(flagOrEnv(logFormat, "THUNK_LOG_FORMAT"))
+		if err != nil {
+			return err
+		}
+		logging.SetLogger(logging.New(logging.Options{Level: level, Format: format}))
+	}
+	cmd.SetContext(logging.WithRun(cmd.Context()))
+	return nil
+}
+
+// checkRunReport logs how many items of a successful run failed, and fails the command with their
+// errors under --strict
+func checkRunReport(cmd *cobra.Command, args []string) error {
+	if runReport.OK() {
+		return nil
+	}
+	if reportStrict {
+		// The command itself was used correctly
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d items failed or were skipped:\n%w", runReport.Failed(), runReport.Err())
+	}
+	logging.FromContext(cmd.Context()).Warn("Some items failed or were skipped", "items", runReport.Failed())
+	return nil
+}
+
+// writeRunReport writes the run report to the --report file, if one was given
+func writeRunReport() error {
+	if reportFile == "" || runReport == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(runReport, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run report: %w", err)
+	}
+	if err := os.WriteFile(reportFile, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	return nil
+}
+
+// flagOrEnv returns a flag's value, or the environment variable's when the flag is unset
+func flagOrEnv(value, env string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(env)
 }
 
 // Execute runs the root command
@@ -22,7 +115,12 @@ func Execute() {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
-	if err := rootCmd.Execute(); err != nil {
+	// The report is written even when the command fails, so automation can see what went wrong
+	err := rootCmd.Execute()
+	if reportErr := writeRunReport(); reportErr != nil && err == nil {
+		err = reportErr
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
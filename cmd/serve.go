@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Yates-Labs/thunk/internal/api"
+	"github.com/Yates-Labs/thunk/internal/api/thunkv1"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+)
+
+var (
+	serveAddr       string
+	serveLocalStore string
+	serveSQLite     string
+	serveMigrate    bool
+	serveEmbedder   string
+	serveLLM        string
+	serveLLMModel   string
+	serveLLMURL     string
+	serveTLSCert    string
+	serveTLSKey     string
+	serveAllowRepos []string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the thunk API over gRPC",
+	Long: `Run a gRPC server exposing the thunk API defined in proto/thunk/v1/thunk.proto:
+AnalyzeRepository, IndexEpisodes, GenerateNarrative and Query.
+
+Each request names the repository it is about, by local path or remote URL. The
+server analyzes a repository on first use and keeps its episodes until a client
+asks to analyze it again. Server reflection is enabled, so tools such as grpcurl
+can discover the service.
+
+The server only speaks TLS, and every call must carry the bearer token in
+THUNK_API_TOKEN in its authorization metadata. Clients may name any remote
+repository unless --allow-repo lists URL prefixes; local paths are rejected
+unless --allow-repo lists a directory containing them.
+
+Required environment variables:
+  OPENAI_API_KEY    - Required unless embeddings and narratives are local or fake
+  THUNK_API_TOKEN   - Bearer token clients must send
+  MILVUS_ADDRESS    - Milvus server address (default: localhost:19530, unused with --local-store or --sqlite-store)
+  MILVUS_COLLECTION - Milvus collection episodes are indexed into (default: thunk_episodes)
+
+Examples:
+  thunk serve --tls-cert server.crt --tls-key server.key
+  thunk serve --tls-cert server.crt --tls-key server.key --addr :50051 --allow-repo https://github.com/owner
+  thunk serve --tls-cert server.crt --tls-key server.key --local-store episodes.db --allow-repo /srv/repos
+  thunk serve --tls-cert server.crt --tls-key server.key --llm local --llm-model llama3.1`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:50051", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "TLS certificate file (required)")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "TLS private key file (required)")
+	serveCmd.Flags().StringArrayVar(&serveAllowRepos, "allow-repo", nil, "Repository URL prefix or local directory clients may name (repeatable)")
+	serveCmd.Flags().StringVar(&serveLocalStore, "local-store", "", "Keep the vector index in this file instead of Milvus")
+	serveCmd.Flags().StringVar(&serveSQLite, "sqlite-store", "", "Keep the vector index in this SQLite database instead of Milvus")
+	serveCmd.Flags().BoolVar(&serveMigrate, "migrate", false, "Migrate a vector store built with an older schema or embedding dimension")
+	serveCmd.Flags().StringVar(&serveEmbedder, "embedder", rag.EmbedderProviderOpenAI, "Embedding provider: openai, or fake for offline testing")
+	serveCmd.Flags().StringVar(&serveLLM, "llm", narrative.ProviderOpenAI, "LLM provider for narratives: openai, or local for an OpenAI-compatible endpoint such as Ollama")
+	serveCmd.Flags().StringVar(&serveLLMModel, "llm-model", "", "Model that writes narratives (default: gpt-4o for OpenAI; required with --llm local)")
+	serveCmd.Flags().StringVar(&serveLLMURL, "llm-url", "", "Base URL of the local LLM endpoint (default: Ollama at "+narrative.DefaultLocalBaseURL+")")
+	serveCmd.MarkFlagsMutuallyExclusive("local-store", "sqlite-store")
+	serveCmd.MarkFlagRequired("tls-cert")
+	serveCmd.MarkFlagRequired("tls-key")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loadEnvFile(".env")
+	config, err := ragConfigFromFlags(ragFlags{
+		embedder:    serveEmbedder,
+		llm:         serveLLM,
+		llmModel:    serveLLMModel,
+		llmURL:      serveLLMURL,
+		localStore:  serveLocalStore,
+		sqliteStore: serveSQLite,
+		migrate:     serveMigrate,
+	})
+	if err != nil {
+		return err
+	}
+	token := os.Getenv(api.TokenEnv)
+	if token == "" {
+		return fmt.Errorf("%s environment variable is required", api.TokenEnv)
+	}
+	creds, err := credentials.NewServerTLSFromFile(serveTLSCert, serveTLSKey)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	// Without a cache directory narratives are simply not cached
+	if dir, err := narrative.DefaultCacheDir(); err == nil {
+		config.NarrativeCacheDir = dir
+	}
+
+	// Repositories share one vector store, so their pipelines don't overwrite each other's records
+	vectorStore, err := orchestrator.NewVectorStore(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer vectorStore.Close()
+
+	server := api.NewServer(config, api.Allowlist(serveAllowRepos), orchestrator.WithSharedVectorStore(vectorStore))
+	defer server.Close()
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddr, err)
+	}
+	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(api.UnaryTokenAuth(token)),
+		grpc.ChainStreamInterceptor(api.StreamTokenAuth(token)),
+	)
+	thunkv1.RegisterThunkServiceServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	fmt.Printf("Serving the thunk API on %s\n", listener.Addr())
+	return grpcServer.Serve(listener)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"github.com/Yates-Labs/thunk/internal/publish"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval    time.Duration
+	watchLocalStore  string
+	watchSQLite      string
+	watchMigrate     bool
+	watchEmbedder    string
+	watchLLM         string
+	watchLLMModel    string
+	watchLLMURL      string
+	watchStyle       string
+	watchNoNarrate   bool
+	watchPublish     string
+	watchPublishFile string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [repository]",
+	Short: "Keep a repository's episodes, index and narratives current by polling",
+	Long: `Poll a repository for new commits, issues and pull requests, and keep everything built from them current.
+
+The repository is analyzed and indexed once at startup. Every --interval after that,
+new commits since the last poll are fetched (remote repositories through the clone
+cache) along with issues and pull requests that changed, and the episodes near them
+are regrouped. Changed episodes are re-embedded into the vector store, and their
+narratives are regenerated into the narrative cache, so "thunk ask" and "--site"
+reuse them. With --publish, the new narratives are posted to Slack or Discord.
+
+This is a long-running alternative to "thunk webhook" for repositories that can't
+send webhooks to it. Episodes that existed at startup are not narrated or posted.
+
+Required environment variables:
+  OPENAI_API_KEY    - Required unless embeddings and narratives are local or fake
+  GITHUB_TOKEN      - Fetch issues and pull requests of GitHub repositories (optional)
+  MILVUS_ADDRESS    - Milvus server address (default: localhost:19530, unused with --local-store or --sqlite-store)
+  MILVUS_COLLECTION - Milvus collection episodes are indexed into (default: thunk_episodes)
+
+Examples:
+  thunk watch https://github.com/user/repo --local-store episodes.db
+  thunk watch . --interval 1m --sqlite-store episodes.sqlite --no-narrate
+  thunk watch https://github.com/user/repo --publish channels.json --interval 15m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", orchestrator.DefaultWatchInterval, "Time between polls")
+	watchCmd.Flags().StringVar(&watchLocalStore, "local-store", "", "Keep the vector index in this file instead of Milvus")
+	watchCmd.Flags().StringVar(&watchSQLite, "sqlite-store", "", "Keep the vector index in this SQLite database instead of Milvus")
+	watchCmd.Flags().BoolVar(&watchMigrate, "migrate", false, "Migrate a vector store built with an older schema or embedding dimension")
+	watchCmd.Flags().StringVar(&watchEmbedder, "embedder", rag.EmbedderProviderOpenAI, "Embedding provider: openai, or fake for offline testing")
+	watchCmd.Flags().StringVar(&watchLLM, "llm", narrative.ProviderOpenAI, "LLM provider for narratives: openai, or local for an OpenAI-compatible endpoint such as Ollama")
+	watchCmd.Flags().StringVar(&watchLLMModel, "llm-model", "", "Model that writes narratives (default: gpt-4o for OpenAI; required with --llm local)")
+	watchCmd.Flags().StringVar(&watchLLMURL, "llm-url", "", "Base URL of the local LLM endpoint (default: Ollama at "+narrative.DefaultLocalBaseURL+")")
+	watchCmd.Flags().StringVar(&watchStyle, "style", string(narrative.StyleTechnical), "Audience and tone of narratives: technical, executive, deep-dive, onboarding or standup")
+	watchCmd.Flags().BoolVar(&watchNoNarrate, "no-narrate", false, "Only keep episodes and the index current, without regenerating narratives")
+	watchCmd.Flags().StringVar(&watchPublish, "publish", "", "Post the narratives of new and changed episodes to the Slack or Discord webhooks of this channel config file, or to this one webhook URL")
+	watchCmd.Flags().StringVar(&watchPublishFile, "publish-ledger", "", "File recording what was posted to each channel, so restarts don't post it again (default: published.json in the user config directory)")
+	watchCmd.MarkFlagsMutuallyExclusive("local-store", "sqlite-store")
+	watchCmd.MarkFlagsMutuallyExclusive("no-narrate", "publish")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loadEnvFile(".env")
+
+	var publisher *publish.Publisher
+	if watchPublish != "" {
+		var err error
+		if publisher, err = newPublisher(watchPublish, watchPublishFile); err != nil {
+			return err
+		}
+	}
+
+	config, err := ragConfigFromFlags(ragFlags{
+		embedder:    watchEmbedder,
+		llm:         watchLLM,
+		llmModel:    watchLLMModel,
+		llmURL:      watchLLMURL,
+		localStore:  watchLocalStore,
+		sqliteStore: watchSQLite,
+		migrate:     watchMigrate,
+	})
+	if err != nil {
+		return err
+	}
+	config.Repository = orchestrator.RepositoryKey(repo)
+	if config.LLMConfig.Style, err = narrative.ParseStyle(watchStyle); err != nil {
+		return err
+	}
+	if dir, err := narrative.DefaultCacheDir(); err == nil {
+		config.NarrativeCacheDir = dir
+	}
+
+	pipeline, err := orchestrator.NewRAGPipeline(ctx, config)
+	if err != nil {
+		return fmt.Errorf("failed to create RAG pipeline: %w", err)
+	}
+	defer pipeline.Close()
+
+	fmt.Printf("Analyzing %s...\n", repo)
+	live, err := orchestrator.NewLiveRepository(ctx, repo, cluster.DefaultGroupingConfig(), pipeline)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	fmt.Printf("✓ Found %d episodes\n", len(live.Episodes()))
+	if err := pipeline.IndexEpisodes(ctx, live.Episodes()); err != nil {
+		return fmt.Errorf("failed to index episodes: %w", err)
+	}
+
+	opts := export.Options{RepositoryURL: repositoryWebURL(repo)}
+	fmt.Printf("Watching %s every %s\n", repo, watchInterval)
+	return live.Watch(ctx, orchestrator.WatchOptions{
+		Interval: watchInterval,
+		OnChange: func(ctx context.Context, changed []cluster.Episode, removed []string) error {
+			logging.FromContext(ctx).Info("Episodes changed", "changed", len(changed), "removed", len(removed))
+			if watchNoNarrate {
+				return nil
+			}
+			// Episodes whose narrative failed, e.g. on a rate limit, are retried after the next poll
+			messages := make([]publish.Message, 0, len(changed))
+			failed := &orchestrator.EpisodeError{}
+			var errs []error
+			for i := range changed {
+				narr, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, &changed[i])
+				if err != nil {
+					failed.IDs = append(failed.IDs, changed[i].ID)
+					errs = append(errs, fmt.Errorf("failed to generate the narrative of episode %s: %w", changed[i].ID, err))
+					continue
+				}
+				messages = append(messages, publish.EpisodeMessage(&changed[i], narr, config.Repository, opts))
+			}
+			if publisher != nil && len(messages) > 0 {
+				// The ledger skips what was already posted, so a failed publish retries every change
+				if err := publishMessages(ctx, publisher, messages); err != nil {
+					return errors.Join(append(errs, err)...)
+				}
+			}
+			if len(failed.IDs) == 0 {
+				return nil
+			}
+			failed.Err = errors.Join(errs...)
+			return failed
+		},
+		OnError: func(err error) {
+			logging.FromContext(ctx).Error("Watch failed", "error", err)
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/github/webhook"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhookAddr       string
+	webhookIndex      bool
+	webhookLocalStore string
+	webhookSQLite     string
+	webhookMigrate    bool
+	webhookEmbedder   string
+	webhookLLM        string
+	webhookLLMModel   string
+	webhookLLMURL     string
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook [repository]",
+	Short: "Keep a repository's episodes current from GitHub webhooks",
+	Long: `Run an HTTP server that receives GitHub push, issue and pull request webhooks.
+
+The repository is analyzed once at startup. Each push ingests only the new commits,
+and issue and pull request events update the matching artifact, after which the
+episodes are regrouped. With --index, changed episodes are re-embedded into the
+vector store so "thunk ask" stays current without re-analyzing the repository.
+
+Configure the webhook on GitHub with content type application/json and a secret.
+
+Required environment variables:
+  THUNK_WEBHOOK_SECRET - Secret used to validate webhook signatures
+  OPENAI_API_KEY       - Required with --index, unless embeddings and narratives are local or fake
+  MILVUS_ADDRESS       - Milvus server address with --index (default: localhost:19530, unused with --local-store or --sqlite-store)
+  MILVUS_COLLECTION    - Milvus collection episodes are indexed into with --index (default: thunk_episodes)
+
+Examples:
+  thunk webhook https://github.com/user/repo --addr :8080
+  thunk webhook https://github.com/user/repo --index
+  thunk webhook https://github.com/user/repo --index --local-store episodes.db
+  thunk webhook https://github.com/user/repo --index --embedder fake --llm local --llm-model llama3.1`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhook,
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.Flags().StringVar(&webhookAddr, "addr", ":8080", "Address to listen on")
+	webhookCmd.Flags().BoolVar(&webhookIndex, "index", false, "Keep the vector store index in sync with episode changes")
+	webhookCmd.Flags().StringVar(&webhookLocalStore, "local-store", "", "With --index, keep the vector index in this file instead of Milvus")
+	webhookCmd.Flags().StringVar(&webhookSQLite, "sqlite-store", "", "With --index, keep the vector index in this SQLite database instead of Milvus")
+	webhookCmd.Flags().BoolVar(&webhookMigrate, "migrate", false, "With --index, migrate a vector store built with an older schema or embedding dimension")
+	webhookCmd.Flags().StringVar(&webhookEmbedder, "embedder", rag.EmbedderProviderOpenAI, "With --index, the embedding provider: openai, or fake for offline testing")
+	webhookCmd.Flags().StringVar(&webhookLLM, "llm", narrative.ProviderOpenAI, "With --index, the LLM provider for episode summaries: openai, or local for an OpenAI-compatible endpoint such as Ollama")
+	webhookCmd.Flags().StringVar(&webhookLLMModel, "llm-model", "", "With --index, the LLM model (default: gpt-4o for OpenAI; required with --llm local)")
+	webhookCmd.Flags().StringVar(&webhookLLMURL, "llm-url", "", "With --index, the base URL of the local LLM endpoint (default: Ollama at "+narrative.DefaultLocalBaseURL+")")
+	webhookCmd.MarkFlagsMutuallyExclusive("local-store", "sqlite-store")
+}
+
+func runWebhook(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	secret := os.Getenv(webhook.SecretEnv)
+	if secret == "" {
+		return fmt.Errorf("%s environment variable is required", webhook.SecretEnv)
+	}
+
+	var pipeline *orchestrator.RAGPipeline
+	var syncer orchestrator.EpisodeSyncer
+	if webhookIndex {
+		config, err := ragConfigFromFlags(ragFlags{
+			embedder:    webhookEmbedder,
+			llm:         webhookLLM,
+			llmModel:    webhookLLMModel,
+			llmURL:      webhookLLMURL,
+			localStore:  webhookLocalStore,
+			sqliteStore: webhookSQLite,
+			migrate:     webhookMigrate,
+		})
+		if err != nil {
+			return err
+		}
+		config.Repository = orchestrator.RepositoryKey(repo)
+
+		pipeline, err = orchestrator.NewRAGPipeline(ctx, config)
+		if err != nil {
+			return fmt.Errorf("failed to create RAG pipeline: %w", err)
+		}
+		defer pipeline.Close()
+		syncer = pipeline
+	}
+
+	fmt.Printf("Analyzing %s...\n", repo)
+	live, err := orchestrator.NewLiveRepository(ctx, repo, cluster.DefaultGroupingConfig(), syncer)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	fmt.Printf("✓ Found %d episodes\n", len(live.Episodes()))
+
+	if pipeline != nil {
+		if err := pipeline.IndexEpisodes(ctx, live.Episodes()); err != nil {
+			return fmt.Errorf("failed to index episodes: %w", err)
+		}
+	}
+
+	server, err := webhook.NewServer(live, webhook.Options{
+		Secret: []byte(secret),
+		OnError: func(update webhook.Update, err error) {
+			logging.FromContext(ctx).Error("Failed to apply webhook delivery", "event", update.Event, "delivery", update.DeliveryID, "error", err)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Listening for webhooks on %s\n", webhookAddr)
+	return server.ListenAndServe(ctx, webhookAddr)
+}
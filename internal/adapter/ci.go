@@ -0,0 +1,32 @@
+package adapter
+
+import (
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/ci"
+)
+
+// ConvertBuild converts a CI provider build to a cluster.Build
+func ConvertBuild(build ci.Build) cluster.Build {
+	return cluster.Build{
+		Provider:    build.Provider,
+		Name:        build.Name,
+		Number:      build.Number,
+		Status:      cluster.BuildStatus(build.Status),
+		CommitSHA:   build.CommitSHA,
+		Branch:      build.Branch,
+		PullRequest: build.PullRequest,
+		URL:         build.URL,
+		StartedAt:   build.StartedAt,
+		FinishedAt:  build.FinishedAt,
+	}
+}
+
+// AttachBuilds records CI builds on the pull and merge requests they built
+// Returns the number of builds that matched a request
+func AttachBuilds(artifacts []cluster.Artifact, builds []ci.Build) int {
+	converted := make([]cluster.Build, len(builds))
+	for i, build := range builds {
+		converted[i] = ConvertBuild(build)
+	}
+	return cluster.AttachBuilds(artifacts, converted)
+}
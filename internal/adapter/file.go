@@ -0,0 +1,156 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/ingest/jsonfile"
+	"github.com/Yates-Labs/thunk/internal/logging"
+)
+
+// Common errors for file import adapter operations
+var (
+	ErrInvalidFileArtifactType = errors.New("invalid artifact type: expected *jsonfile.Artifact")
+	ErrNotAnIssue              = errors.New("artifact is a pull or merge request, not an issue")
+	ErrNotAPullRequest         = errors.New("artifact is an issue or ticket, not a pull or merge request")
+)
+
+// FileAdapter implements the Adapter interface for artifacts imported from a JSON or NDJSON file
+// It lets users of unsupported trackers export their data and still link it to commits
+type FileAdapter struct {
+	Path string
+}
+
+// NewFileAdapter creates a new file import adapter for an import file
+func NewFileAdapter(path string) *FileAdapter {
+	return &FileAdapter{Path: path}
+}
+
+// GetPlatform returns the file import platform identifier
+func (a *FileAdapter) GetPlatform() cluster.SourcePlatform {
+	return cluster.PlatformFile
+}
+
+// ConvertIssue converts an imported issue or ticket to a cluster.Artifact
+func (a *FileAdapter) ConvertIssue(issue interface{}) (*cluster.Artifact, error) {
+	record, ok := issue.(*jsonfile.Artifact)
+	if !ok {
+		return nil, ErrInvalidFileArtifactType
+	}
+	if record.Type != jsonfile.TypeIssue && record.Type != jsonfile.TypeTicket {
+		return nil, ErrNotAnIssue
+	}
+	return convertFileArtifact(record), nil
+}
+
+// ConvertPullRequest converts an imported pull or merge request to a cluster.Artifact
+func (a *FileAdapter) ConvertPullRequest(pr interface{}) (*cluster.Artifact, error) {
+	record, ok := pr.(*jsonfile.Artifact)
+	if !ok {
+		return nil, ErrInvalidFileArtifactType
+	}
+	if record.Type != jsonfile.TypePullRequest && record.Type != jsonfile.TypeMergeRequest {
+		return nil, ErrNotAPullRequest
+	}
+	return convertFileArtifact(record), nil
+}
+
+// FetchArtifacts reads all artifacts of the import file; token, owner and repo are unused
+func (a *FileAdapter) FetchArtifacts(ctx context.Context, token, owner, repo string) ([]cluster.Artifact, error) {
+	log := logging.FromContext(ctx).With("path", a.Path)
+	log.Info("Importing artifacts")
+
+	records, err := jsonfile.ReadFile(a.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := make([]cluster.Artifact, 0, len(records))
+	for i := range records {
+		artifacts = append(artifacts, *convertFileArtifact(&records[i]))
+	}
+
+	log.Info("Imported artifacts", "artifacts", len(artifacts))
+
+	return artifacts, nil
+}
+
+// fileArtifactTypes maps import file types onto artifact types
+var fileArtifactTypes = map[string]cluster.ArtifactType{
+	jsonfile.TypeIssue:        cluster.ArtifactIssue,
+	jsonfile.TypePullRequest:  cluster.ArtifactPullRequest,
+	jsonfile.TypeMergeRequest: cluster.ArtifactMergeRequest,
+	jsonfile.TypeTicket:       cluster.ArtifactTicket,
+}
+
+// convertFileArtifact converts a validated import record to a cluster.Artifact
+func convertFileArtifact(record *jsonfile.Artifact) *cluster.Artifact {
+	artifact := &cluster.Artifact{
+		ID:          record.ID,
+		Number:      record.Number,
+		Type:        fileArtifactTypes[record.Type],
+		Title:       record.Title,
+		Description: record.Body,
+		State:       record.State,
+		Author:      convertFilePerson(record.Author),
+		Assignees:   record.Assignees,
+		Labels:      record.Labels,
+		CreatedAt:   record.CreatedAt,
+		UpdatedAt:   record.CreatedAt,
+		ClosedAt:    record.ClosedAt,
+		MergedAt:    record.MergedAt,
+		URL:         record.URL,
+	}
+	if record.UpdatedAt != nil {
+		artifact.UpdatedAt = *record.UpdatedAt
+	}
+	if artifact.Type == cluster.ArtifactTicket && artifact.Number == 0 {
+		artifact.Number = ticketNumber(record.Key)
+	}
+
+	artifact.Discussions = make([]cluster.Discussion, 0, len(record.Discussions))
+	for _, discussion := range record.Discussions {
+		artifact.Discussions = append(artifact.Discussions, cluster.Discussion{
+			ID:          discussion.ID,
+			Type:        cluster.DiscussionType(discussion.Type),
+			Author:      convertFilePerson(discussion.Author),
+			Body:        discussion.Body,
+			CreatedAt:   discussion.CreatedAt,
+			UpdatedAt:   discussion.CreatedAt,
+			ParentID:    discussion.ParentID,
+			FilePath:    discussion.FilePath,
+			LineNumber:  discussion.Line,
+			CommitHash:  discussion.CommitHash,
+			ReviewState: discussion.ReviewState,
+			Resolved:    discussion.Resolved,
+			Event:       discussion.Event,
+		})
+	}
+	sortDiscussions(artifact.Discussions)
+
+	artifact.Metadata = cluster.ArtifactMetadata{
+		BaseBranch:     record.BaseBranch,
+		HeadBranch:     record.HeadBranch,
+		MergeCommitSHA: record.MergeCommitSHA,
+		ChangedFiles:   len(record.Files),
+		CommitSHAs:     record.CommitSHAs,
+		Files:          record.Files,
+		ClosesIssues:   record.Closes,
+		Priority:       record.Priority,
+		Milestone:      record.Milestone,
+		TicketKey:      record.Key,
+		ParentKey:      record.Parent,
+	}
+	if record.Parent != "" {
+		artifact.Metadata.RelatedArtifacts = []string{record.Parent}
+	}
+
+	return artifact
+}
+
+// convertFilePerson converts an import file person to a git author
+func convertFilePerson(person jsonfile.Person) git.Author {
+	return git.Author{Name: person.Name, Email: person.Email}
+}
@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/jsonfile"
+)
+
+func TestFileAdapter_FetchArtifacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artifacts.json")
+	content := `{"artifacts": [
+		{"type": "ticket", "key": "OPS-12", "title": "Rotate certs", "parent": "OPS-1", "created_at": "2024-05-01T10:00:00Z"},
+		{"type": "merge_request", "number": 4, "state": "merged", "head_branch": "rotate-certs", "closes": [3],
+		 "created_at": "2024-05-02T10:00:00Z", "updated_at": "2024-05-03T10:00:00Z",
+		 "discussions": [
+			{"type": "review", "review_state": "approved", "author": {"name": "Carol"}, "created_at": "2024-05-02T12:00:00Z"},
+			{"type": "review_thread", "file_path": "tls.go", "line": 9, "body": "nit", "created_at": "2024-05-02T11:00:00Z"}
+		 ]}
+	]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := NewFileAdapter(path).FetchArtifacts(context.Background(), "", "", "")
+	if err != nil {
+		t.Fatalf("FetchArtifacts failed: %v", err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("Expected 2 artifacts, got %d", len(artifacts))
+	}
+
+	ticket := artifacts[0]
+	if ticket.Type != cluster.ArtifactTicket || ticket.Number != 12 || ticket.Metadata.TicketKey != "OPS-12" {
+		t.Errorf("Unexpected ticket: %+v", ticket)
+	}
+	if len(ticket.Metadata.RelatedArtifacts) != 1 || ticket.Metadata.RelatedArtifacts[0] != "OPS-1" {
+		t.Errorf("Expected the parent as a related artifact, got %v", ticket.Metadata.RelatedArtifacts)
+	}
+	if !ticket.UpdatedAt.Equal(ticket.CreatedAt) {
+		t.Errorf("Expected updated_at to default to created_at, got %v", ticket.UpdatedAt)
+	}
+
+	mr := artifacts[1]
+	if mr.Type != cluster.ArtifactMergeRequest || mr.ID != "merge_request-4" || mr.Metadata.HeadBranch != "rotate-certs" {
+		t.Errorf("Unexpected merge request: %+v", mr)
+	}
+	if len(mr.Metadata.ClosesIssues) != 1 || mr.Metadata.ClosesIssues[0] != 3 {
+		t.Errorf("Expected closed issues to carry over, got %v", mr.Metadata.ClosesIssues)
+	}
+	if len(mr.Discussions) != 2 || mr.Discussions[0].Type != cluster.DiscussionReviewThread || mr.Discussions[1].ReviewState != "approved" {
+		t.Errorf("Expected discussions sorted by time, got %+v", mr.Discussions)
+	}
+}
+
+func TestFileAdapter_ConvertChecksType(t *testing.T) {
+	a := NewFileAdapter("")
+	pr := &jsonfile.Artifact{ID: "pull_request-1", Type: jsonfile.TypePullRequest, Number: 1, CreatedAt: time.Now()}
+
+	if _, err := a.ConvertIssue(pr); err != ErrNotAnIssue {
+		t.Errorf("Expected ErrNotAnIssue, got %v", err)
+	}
+	if artifact, err := a.ConvertPullRequest(pr); err != nil || artifact.Type != cluster.ArtifactPullRequest {
+		t.Errorf("Expected a pull request artifact, got %+v, %v", artifact, err)
+	}
+	if _, err := a.ConvertIssue("issue"); err != ErrInvalidFileArtifactType {
+		t.Errorf("Expected ErrInvalidFileArtifactType, got %v", err)
+	}
+}
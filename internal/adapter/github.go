@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/Yates-Labs/thunk/internal/cluster"
 	"github.com/Yates-Labs/thunk/internal/ingest/git"
 	githubmodel "github.com/Yates-Labs/thunk/internal/ingest/github"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
+	"github.com/google/go-github/v77/github"
 )
 
 // Common errors for adapter operations
@@ -18,11 +22,22 @@ var (
 )
 
 // GitHubAdapter implements the Adapter interface for GitHub
-type GitHubAdapter struct{}
+type GitHubAdapter struct {
+	// Cache revalidates previously fetched responses so unchanged issues and PRs cost no rate limit
+	Cache *githubmodel.HTTPCache
+
+	// ResolveIdentities looks up the commit email of every artifact and discussion author so they
+	// share an identity with their git commits (costs up to two API calls per distinct login)
+	ResolveIdentities bool
+
+	// Since limits the issues and pull requests fetched to those updated at or after it, so an
+	// incremental analysis only fetches what changed since its last sync (zero = all)
+	Since time.Time
+}
 
 // NewGitHubAdapter creates a new GitHub adapter instance
 func NewGitHubAdapter() *GitHubAdapter {
-	return &GitHubAdapter{}
+	return &GitHubAdapter{ResolveIdentities: true}
 }
 
 // GetPlatform returns the GitHub platform identifier
@@ -50,20 +65,31 @@ func (a *GitHubAdapter) ConvertPullRequest(pr interface{}) (*cluster.Artifact, e
 
 // FetchArtifacts fetches all artifacts (issues and PRs) from GitHub
 func (a *GitHubAdapter) FetchArtifacts(ctx context.Context, token, owner, repo string) ([]cluster.Artifact, error) {
+	log := logging.FromContext(ctx).With("platform", cluster.PlatformGitHub)
+	runReport := report.FromContext(ctx)
+
 	// Create GitHub client
-	client := githubmodel.NewClient(token)
+	client := githubmodel.NewClientWithOptions(token, githubmodel.ClientOptions{
+		ThrottleOptions: githubmodel.ThrottleOptions{
+			OnWait: func(wait githubmodel.RateLimitWait) {
+				log.Warn("Rate limit reached, waiting", "limit", wait.Reason,
+					"wait", wait.Duration.Round(time.Second), "until", wait.Until.Format(time.Kitchen))
+			},
+		},
+		Cache: a.Cache,
+	})
 
 	var artifacts []cluster.Artifact
 
-	fmt.Printf("Fetching issues from GitHub...\n")
+	log.Info("Fetching issues")
 
 	// Fetch all issues (this includes both issues and PRs in GitHub's API)
-	ghIssues, err := githubmodel.ListAllIssues(ctx, client, owner, repo)
+	ghIssues, err := githubmodel.ListIssuesSince(ctx, client, owner, repo, a.Since)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch issues: %w", err)
 	}
 
-	fmt.Printf("Found %d issues/PRs, converting...\n", len(ghIssues))
+	log.Info("Converting issues and pull requests", "count", len(ghIssues))
 
 	// Convert issues directly from the lightweight API objects
 	for _, ghIssue := range ghIssues {
@@ -78,38 +104,108 @@ func (a *GitHubAdapter) FetchArtifacts(ctx context.Context, token, owner, repo s
 		// Convert to artifact using adapter
 		artifact, err := a.ConvertIssue(issue)
 		if err != nil {
-			fmt.Printf("Warning: failed to convert issue #%d: %v\n", ghIssue.GetNumber(), err)
+			log.Warn("Failed to convert issue", "number", ghIssue.GetNumber(), "error", err)
+			runReport.Skip(report.StageArtifacts, fmt.Sprintf("issue #%d", ghIssue.GetNumber()), err)
 			continue
 		}
 
 		artifacts = append(artifacts, *artifact)
 	}
 
-	fmt.Printf("Fetching pull requests from GitHub...\n")
+	log.Info("Fetching pull requests")
 
 	// Fetch all pull requests with lightweight details
-	ghPRs, err := githubmodel.ListAllPullRequests(ctx, client, owner, repo)
+	ghPRs, err := githubmodel.ListPullRequestsSince(ctx, client, owner, repo, a.Since)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch pull requests: %w", err)
 	}
 
-	fmt.Printf("Found %d PRs, converting...\n", len(ghPRs))
+	log.Info("Converting pull requests", "count", len(ghPRs))
+
+	// Closing references include sidebar links that the PR body does not mention
+	closingIssues, err := githubmodel.ListClosingIssueReferences(ctx, client, owner, repo)
+	if err != nil {
+		log.Warn("Failed to fetch closing issue references", "error", err)
+		runReport.Fail(report.StageArtifacts, "closing issue references", err)
+	}
 
 	for _, ghPR := range ghPRs {
 		// Convert lightweight PR to our model
 		pr := githubmodel.ParsePullRequest(ghPR)
+		pr.AddClosingIssues(closingIssues[pr.Number])
+
+		// Files and commits let clustering link the PR by SHA and file overlap, not just "#123" mentions
+		if err := githubmodel.ParsePullRequestChanges(ctx, client, owner, repo, pr); err != nil {
+			log.Warn("Failed to fetch pull request changes", "number", pr.Number, "error", err)
+			runReport.Fail(report.StageArtifacts, fmt.Sprintf("pull request #%d changes", pr.Number), err)
+		}
+
+		// Review threads carry the code review conversation and whether each concern was settled
+		if threads, err := githubmodel.ParseReviewThreads(ctx, client, owner, repo, pr.Number); err != nil {
+			log.Warn("Failed to fetch review threads", "number", pr.Number, "error", err)
+			runReport.Fail(report.StageArtifacts, fmt.Sprintf("pull request #%d review threads", pr.Number), err)
+		} else {
+			pr.ReviewComments = githubmodel.ReviewThreadComments(threads)
+		}
 
 		// Convert to artifact using adapter
 		artifact, err := a.ConvertPullRequest(pr)
 		if err != nil {
-			fmt.Printf("Warning: failed to convert PR #%d: %v\n", ghPR.GetNumber(), err)
+			log.Warn("Failed to convert pull request", "number", ghPR.GetNumber(), "error", err)
+			runReport.Skip(report.StageArtifacts, fmt.Sprintf("pull request #%d", ghPR.GetNumber()), err)
 			continue
 		}
 
 		artifacts = append(artifacts, *artifact)
 	}
 
-	fmt.Printf("Successfully converted %d artifacts\n", len(artifacts))
+	linkClosedIssues(artifacts)
+
+	log.Info("Fetching project boards")
+
+	// Board placement is planning context; many repositories have no boards at all
+	projectItems, err := githubmodel.ListProjectItems(ctx, client, owner, repo)
+	if err != nil {
+		log.Warn("Failed to fetch project items", "error", err)
+		runReport.Fail(report.StageArtifacts, "project items", err)
+	} else {
+		attachProjectItems(artifacts, projectItems)
+	}
+
+	log.Info("Fetching issue events")
+
+	// Event history is supplementary; artifacts are still useful without it
+	ghEvents, err := githubmodel.ListAllIssueEvents(ctx, client, owner, repo)
+	if err != nil {
+		log.Warn("Failed to fetch issue events", "error", err)
+		runReport.Fail(report.StageArtifacts, "issue events", err)
+	} else {
+		attachIssueEvents(artifacts, ghEvents)
+	}
+
+	log.Info("Fetching releases")
+
+	// Releases are milestone markers; a repository without them still yields issues and PRs
+	ghReleases, err := githubmodel.ListAllReleases(ctx, client, owner, repo)
+	if err != nil {
+		log.Warn("Failed to fetch releases", "error", err)
+		runReport.Skip(report.StageArtifacts, "releases", err)
+	}
+
+	for _, ghRelease := range ghReleases {
+		release := githubmodel.ParseRelease(ghRelease)
+		if release.Draft {
+			continue
+		}
+		artifacts = append(artifacts, *convertGitHubRelease(release))
+	}
+
+	if a.ResolveIdentities {
+		log.Info("Resolving author identities")
+		resolveAuthorEmails(ctx, githubmodel.NewIdentityResolver(client, owner, repo), artifacts)
+	}
+
+	log.Info("Converted artifacts", "artifacts", len(artifacts))
 
 	return artifacts, nil
 }
@@ -136,11 +232,13 @@ func convertGitHubIssue(issue *githubmodel.Issue) *cluster.Artifact {
 		URL:       issue.HTMLURL,
 	}
 
-	// Convert discussions (comments)
+	// Convert discussions (comments and process events)
 	artifact.Discussions = make([]cluster.Discussion, 0, len(issue.Comments))
 	for _, comment := range issue.Comments {
 		artifact.Discussions = append(artifact.Discussions, convertGitHubComment(comment))
 	}
+	artifact.Discussions = append(artifact.Discussions, convertGitHubTimelineEvents(issue.Timeline)...)
+	sortDiscussions(artifact.Discussions)
 
 	// Set metadata
 	artifact.Metadata = cluster.ArtifactMetadata{
@@ -155,6 +253,45 @@ func convertGitHubIssue(issue *githubmodel.Issue) *cluster.Artifact {
 	return artifact
 }
 
+// convertGitHubRelease converts a GitHub release to a standardized cluster.Artifact
+// The artifact is dated by its publication, which is when the release actually shipped
+func convertGitHubRelease(release *githubmodel.Release) *cluster.Artifact {
+	title := release.Name
+	if title == "" {
+		title = release.TagName
+	}
+
+	publishedAt := release.CreatedAt
+	if release.PublishedAt != nil {
+		publishedAt = *release.PublishedAt
+	}
+
+	artifact := &cluster.Artifact{
+		ID:          fmt.Sprintf("release-%d", release.ID),
+		Type:        cluster.ArtifactRelease,
+		Title:       title,
+		Description: release.Body,
+		State:       "published",
+		Author: git.Author{
+			Name:  release.Author,
+			Email: "", // GitHub API doesn't provide email in release context
+		},
+		CreatedAt: publishedAt,
+		UpdatedAt: publishedAt,
+		URL:       release.HTMLURL,
+		Metadata: cluster.ArtifactMetadata{
+			TagName:      release.TagName,
+			IsPrerelease: release.Prerelease,
+		},
+	}
+
+	for _, asset := range release.Assets {
+		artifact.Metadata.Assets = append(artifact.Metadata.Assets, asset.Name)
+	}
+
+	return artifact
+}
+
 // convertGitHubPullRequest converts a GitHub pull request to a standardized cluster.Artifact
 func convertGitHubPullRequest(pr *githubmodel.PullRequest) *cluster.Artifact {
 	artifact := &cluster.Artifact{
@@ -196,6 +333,9 @@ func convertGitHubPullRequest(pr *githubmodel.PullRequest) *cluster.Artifact {
 		artifact.Discussions = append(artifact.Discussions, convertGitHubReview(review))
 	}
 
+	// Add process events (labels, assignments, milestones, state changes)
+	artifact.Discussions = append(artifact.Discussions, convertGitHubTimelineEvents(pr.Timeline)...)
+
 	// Sort discussions by creation time
 	sortDiscussions(artifact.Discussions)
 
@@ -209,8 +349,14 @@ func convertGitHubPullRequest(pr *githubmodel.PullRequest) *cluster.Artifact {
 		ChangedFiles:     pr.ChangedFiles,
 		ReviewState:      determineReviewState(pr.Reviews),
 		IsDraft:          pr.Draft,
+		CommitSHAs:       pr.CommitSHAs,
+		Files:            pullRequestPaths(pr.Files),
+		ClosesIssues:     pr.ClosesIssues,
 		RelatedArtifacts: extractRelatedArtifacts(pr.CrossReferences),
 	}
+	for _, number := range pr.ClosesIssues {
+		artifact.Metadata.RelatedArtifacts = appendRelated(artifact.Metadata.RelatedArtifacts, fmt.Sprintf("issue-%d", number))
+	}
 
 	if pr.Milestone != nil {
 		artifact.Metadata.Milestone = pr.Milestone.Title
@@ -236,6 +382,220 @@ func convertGitHubComment(comment githubmodel.Comment) cluster.Discussion {
 	}
 }
 
+// pullRequestPaths lists the paths a PR touched, including the old side of renames
+func pullRequestPaths(files []githubmodel.PullRequestFile) []string {
+	if len(files) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Filename)
+		if file.PreviousFilename != "" && file.PreviousFilename != file.Filename {
+			paths = append(paths, file.PreviousFilename)
+		}
+	}
+	return paths
+}
+
+// convertGitHubTimelineEvents converts the process events of a timeline to discussions
+// Comments, reviews and commits also appear in timelines but are converted from their own endpoints
+func convertGitHubTimelineEvents(timeline []githubmodel.TimelineEvent) []cluster.Discussion {
+	discussions := make([]cluster.Discussion, 0)
+	for _, event := range timeline {
+		if discussion, ok := convertGitHubTimelineEvent(event); ok {
+			discussions = append(discussions, discussion)
+		}
+	}
+	return discussions
+}
+
+// convertGitHubTimelineEvent converts a process event to a cluster.Discussion
+// Returns false for events that do not describe a process change
+func convertGitHubTimelineEvent(event githubmodel.TimelineEvent) (cluster.Discussion, bool) {
+	body, ok := describeTimelineEvent(event)
+	if !ok {
+		return cluster.Discussion{}, false
+	}
+
+	discussion := cluster.Discussion{
+		ID:   fmt.Sprintf("event-%d", event.ID),
+		Type: cluster.DiscussionEvent,
+		Author: git.Author{
+			Name:  event.Actor,
+			Email: "",
+		},
+		Body:      body,
+		CreatedAt: event.CreatedAt,
+		UpdatedAt: event.CreatedAt,
+		Event:     event.Event,
+	}
+
+	// Lets clustering link the artifact to the episode containing the commit
+	switch event.Event {
+	case "closed", "merged", "referenced":
+		discussion.CommitHash = event.CommitID
+	}
+
+	return discussion, true
+}
+
+// describeTimelineEvent renders a process event as a short sentence (without the actor)
+func describeTimelineEvent(event githubmodel.TimelineEvent) (string, bool) {
+	switch event.Event {
+	case "labeled":
+		return fmt.Sprintf("added label %q", event.Label), true
+	case "unlabeled":
+		return fmt.Sprintf("removed label %q", event.Label), true
+	case "assigned":
+		return fmt.Sprintf("assigned %s", event.Assignee), true
+	case "unassigned":
+		return fmt.Sprintf("unassigned %s", event.Assignee), true
+	case "milestoned":
+		return fmt.Sprintf("added to milestone %q", event.Milestone), true
+	case "demilestoned":
+		return fmt.Sprintf("removed from milestone %q", event.Milestone), true
+	case "renamed":
+		return fmt.Sprintf("renamed from %q to %q", event.RenameFrom, event.RenameTo), true
+	case "review_requested":
+		return fmt.Sprintf("requested review from %s", event.Reviewer), true
+	case "review_request_removed":
+		return fmt.Sprintf("removed review request for %s", event.Reviewer), true
+	case "closed":
+		if event.CommitID != "" {
+			return fmt.Sprintf("closed via commit %s", shortSHA(event.CommitID)), true
+		}
+		return "closed", true
+	case "reopened":
+		return "reopened", true
+	case "merged":
+		return fmt.Sprintf("merged as commit %s", shortSHA(event.CommitID)), true
+	case "referenced":
+		if event.CommitID == "" {
+			return "", false
+		}
+		return fmt.Sprintf("referenced from commit %s", shortSHA(event.CommitID)), true
+	case "ready_for_review":
+		return "marked ready for review", true
+	case "convert_to_draft":
+		return "converted to draft", true
+	case "locked":
+		return "locked the conversation", true
+	case "unlocked":
+		return "unlocked the conversation", true
+	default:
+		return "", false
+	}
+}
+
+// shortSHA abbreviates a commit hash for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// attachIssueEvents adds repository-wide issue events to the artifacts they belong to
+// Events are matched by issue/PR number; releases are never targets
+func attachIssueEvents(artifacts []cluster.Artifact, ghEvents []*github.IssueEvent) {
+	byNumber := make(map[int][]cluster.Discussion)
+	for _, ghEvent := range ghEvents {
+		if ghEvent == nil || ghEvent.Issue == nil {
+			continue
+		}
+		if discussion, ok := convertGitHubTimelineEvent(githubmodel.ParseIssueEvent(ghEvent)); ok {
+			number := ghEvent.GetIssue().GetNumber()
+			byNumber[number] = append(byNumber[number], discussion)
+		}
+	}
+
+	for i := range artifacts {
+		artifact := &artifacts[i]
+		if artifact.Type == cluster.ArtifactRelease {
+			continue
+		}
+		if events := byNumber[artifact.Number]; len(events) > 0 {
+			artifact.Discussions = append(artifact.Discussions, events...)
+			sortDiscussions(artifact.Discussions)
+		}
+	}
+}
+
+// attachProjectItems records each issue and PR's board column and iteration in its metadata
+func attachProjectItems(artifacts []cluster.Artifact, items []githubmodel.ProjectItem) {
+	byNumber := make(map[string][]cluster.ProjectStatus)
+	for _, item := range items {
+		key := fmt.Sprintf("%s-%d", item.ContentType, item.Number)
+		byNumber[key] = append(byNumber[key], convertGitHubProjectItem(item))
+	}
+
+	for i := range artifacts {
+		artifact := &artifacts[i]
+		var contentType string
+		switch artifact.Type {
+		case cluster.ArtifactIssue:
+			contentType = githubmodel.ProjectContentIssue
+		case cluster.ArtifactPullRequest:
+			contentType = githubmodel.ProjectContentPullRequest
+		default:
+			continue
+		}
+		if projects := byNumber[fmt.Sprintf("%s-%d", contentType, artifact.Number)]; len(projects) > 0 {
+			artifact.Metadata.Projects = projects
+		}
+	}
+}
+
+// convertGitHubProjectItem converts a GitHub board item to the unified project status
+func convertGitHubProjectItem(item githubmodel.ProjectItem) cluster.ProjectStatus {
+	status := cluster.ProjectStatus{Project: item.Project, Status: item.Status}
+	if item.Iteration != nil {
+		status.Iteration = &cluster.Iteration{
+			ID:        item.Iteration.ID,
+			Title:     item.Iteration.Title,
+			StartDate: item.Iteration.StartDate,
+			EndDate:   item.Iteration.EndDate(),
+		}
+	}
+	return status
+}
+
+// identityResolver maps GitHub logins to identities (implemented by githubmodel.IdentityResolver)
+type identityResolver interface {
+	Resolve(ctx context.Context, login string) (githubmodel.Identity, error)
+}
+
+// resolveAuthorEmails fills in the commit email of artifact and discussion authors
+// Logins that cannot be resolved keep an empty email; a failed lookup is reported once per login
+func resolveAuthorEmails(ctx context.Context, resolver identityResolver, artifacts []cluster.Artifact) {
+	emails := make(map[string]string)
+	resolve := func(author *git.Author) {
+		login := author.Name
+		if login == "" || author.Email != "" {
+			return
+		}
+		email, ok := emails[login]
+		if !ok {
+			identity, err := resolver.Resolve(ctx, login)
+			if err != nil {
+				logging.FromContext(ctx).Warn("Failed to resolve identity", "login", login, "error", err)
+				report.FromContext(ctx).Fail(report.StageIdentities, login, err)
+			}
+			email = identity.Email
+			emails[login] = email
+		}
+		author.Email = email
+	}
+
+	for i := range artifacts {
+		resolve(&artifacts[i].Author)
+		for j := range artifacts[i].Discussions {
+			resolve(&artifacts[i].Discussions[j].Author)
+		}
+	}
+}
+
 // convertGitHubReviewComment converts a GitHub review comment to a cluster.Discussion
 func convertGitHubReviewComment(comment githubmodel.ReviewComment) cluster.Discussion {
 	discussion := cluster.Discussion{
@@ -251,6 +611,7 @@ func convertGitHubReviewComment(comment githubmodel.ReviewComment) cluster.Discu
 		FilePath:   comment.Path,
 		LineNumber: comment.Line,
 		CommitHash: comment.CommitID,
+		Resolved:   comment.Resolved,
 		Reactions:  convertGitHubReactions(comment.Reactions),
 	}
 
@@ -319,6 +680,38 @@ func extractRelatedArtifacts(crossRefs []githubmodel.CrossRef) []string {
 	return related
 }
 
+// appendRelated adds a related artifact reference unless it is already listed
+func appendRelated(related []string, ref string) []string {
+	for _, existing := range related {
+		if existing == ref {
+			return related
+		}
+	}
+	return append(related, ref)
+}
+
+// linkClosedIssues records on each issue the pull requests that close it, so closing links
+// are visible from both sides
+func linkClosedIssues(artifacts []cluster.Artifact) {
+	issues := make(map[int]*cluster.Artifact)
+	for i := range artifacts {
+		if artifacts[i].Type == cluster.ArtifactIssue {
+			issues[artifacts[i].Number] = &artifacts[i]
+		}
+	}
+
+	for _, artifact := range artifacts {
+		if artifact.Type != cluster.ArtifactPullRequest {
+			continue
+		}
+		for _, number := range artifact.Metadata.ClosesIssues {
+			if issue, ok := issues[number]; ok {
+				issue.Metadata.RelatedArtifacts = appendRelated(issue.Metadata.RelatedArtifacts, fmt.Sprintf("pr-%d", artifact.Number))
+			}
+		}
+	}
+}
+
 // normalizeState converts GitHub state to a normalized state
 // For PRs, also considers merged status
 func normalizeState(state string, merged bool) string {
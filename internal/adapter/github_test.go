@@ -1,11 +1,15 @@
 package adapter
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
 	githubmodel "github.com/Yates-Labs/thunk/internal/ingest/github"
+	"github.com/google/go-github/v77/github"
 )
 
 // Sample GitHub API responses for testing
@@ -621,3 +625,286 @@ func TestExtractRelatedArtifacts_Empty(t *testing.T) {
 		t.Errorf("Expected nil for empty cross-references, got %v", related)
 	}
 }
+
+func TestConvertGitHubRelease(t *testing.T) {
+	createdAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	publishedAt := time.Date(2024, 2, 2, 15, 0, 0, 0, time.UTC)
+
+	release := &githubmodel.Release{
+		ID:          777,
+		TagName:     "v1.2.0",
+		Body:        "## Changes\n- Faster parsing",
+		Author:      "alice",
+		Prerelease:  true,
+		CreatedAt:   createdAt,
+		PublishedAt: &publishedAt,
+		Assets:      []githubmodel.ReleaseAsset{{Name: "thunk-linux-amd64.tar.gz"}},
+		HTMLURL:     "https://github.com/o/r/releases/tag/v1.2.0",
+	}
+
+	artifact := convertGitHubRelease(release)
+
+	if artifact.ID != "release-777" || artifact.Type != cluster.ArtifactRelease {
+		t.Errorf("Unexpected identity: %s (%s)", artifact.ID, artifact.Type)
+	}
+	if artifact.Title != "v1.2.0" {
+		t.Errorf("Expected unnamed release to be titled by its tag, got %q", artifact.Title)
+	}
+	if !artifact.CreatedAt.Equal(publishedAt) {
+		t.Errorf("Expected release dated by publication %v, got %v", publishedAt, artifact.CreatedAt)
+	}
+	if artifact.Metadata.TagName != "v1.2.0" || !artifact.Metadata.IsPrerelease {
+		t.Errorf("Unexpected release metadata: %+v", artifact.Metadata)
+	}
+	if len(artifact.Metadata.Assets) != 1 || artifact.Metadata.Assets[0] != "thunk-linux-amd64.tar.gz" {
+		t.Errorf("Expected asset names in metadata, got %v", artifact.Metadata.Assets)
+	}
+	if artifact.Description != release.Body {
+		t.Error("Expected release notes as the description")
+	}
+}
+
+func TestConvertGitHubTimelineEvent(t *testing.T) {
+	at := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		event  githubmodel.TimelineEvent
+		body   string
+		commit string
+		ok     bool
+	}{
+		{githubmodel.TimelineEvent{Event: "labeled", Label: "bug"}, `added label "bug"`, "", true},
+		{githubmodel.TimelineEvent{Event: "assigned", Assignee: "bob"}, "assigned bob", "", true},
+		{githubmodel.TimelineEvent{Event: "demilestoned", Milestone: "v1.2"}, `removed from milestone "v1.2"`, "", true},
+		{githubmodel.TimelineEvent{Event: "closed", CommitID: "abcdef1234567"}, "closed via commit abcdef1", "abcdef1234567", true},
+		{githubmodel.TimelineEvent{Event: "closed"}, "closed", "", true},
+		{githubmodel.TimelineEvent{Event: "referenced"}, "", "", false},
+		{githubmodel.TimelineEvent{Event: "commented"}, "", "", false},
+		{githubmodel.TimelineEvent{Event: "cross-referenced"}, "", "", false},
+	}
+
+	for _, tt := range tests {
+		tt.event.ID = 42
+		tt.event.Actor = "alice"
+		tt.event.CreatedAt = at
+
+		discussion, ok := convertGitHubTimelineEvent(tt.event)
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tt.event.Event, tt.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if discussion.Type != cluster.DiscussionEvent || discussion.Event != tt.event.Event || discussion.ID != "event-42" {
+			t.Errorf("%s: unexpected discussion identity %+v", tt.event.Event, discussion)
+		}
+		if discussion.Body != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.event.Event, tt.body, discussion.Body)
+		}
+		if discussion.CommitHash != tt.commit {
+			t.Errorf("%s: expected commit %q, got %q", tt.event.Event, tt.commit, discussion.CommitHash)
+		}
+		if discussion.Author.Name != "alice" || !discussion.CreatedAt.Equal(at) {
+			t.Errorf("%s: unexpected author or time %+v", tt.event.Event, discussion)
+		}
+	}
+}
+
+func TestConvertGitHubIssue_IncludesTimelineEvents(t *testing.T) {
+	issue := createSampleIssue()
+	issue.Timeline = []githubmodel.TimelineEvent{
+		{ID: 1, Event: "labeled", Actor: "alice", Label: "bug", CreatedAt: issue.CreatedAt.Add(time.Minute)},
+		{ID: 2, Event: "commented", Actor: "bob", CreatedAt: issue.CreatedAt.Add(2 * time.Minute)},
+	}
+
+	artifact := convertGitHubIssue(issue)
+
+	events := 0
+	for _, discussion := range artifact.Discussions {
+		if discussion.Type == cluster.DiscussionEvent {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("Expected 1 process event among discussions, got %d", events)
+	}
+	for i := 1; i < len(artifact.Discussions); i++ {
+		if artifact.Discussions[i].CreatedAt.Before(artifact.Discussions[i-1].CreatedAt) {
+			t.Error("Expected discussions sorted by time")
+		}
+	}
+}
+
+func TestAttachIssueEvents(t *testing.T) {
+	at := github.Timestamp{Time: time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)}
+	artifacts := []cluster.Artifact{
+		{ID: "issue-1", Number: 7, Type: cluster.ArtifactIssue},
+		{ID: "pr-1", Number: 8, Type: cluster.ArtifactPullRequest},
+		{ID: "release-1", Type: cluster.ArtifactRelease},
+	}
+	ghEvents := []*github.IssueEvent{
+		{ID: github.Ptr(int64(1)), Event: github.Ptr("milestoned"), Milestone: &github.Milestone{Title: github.Ptr("v1")}, Issue: &github.Issue{Number: github.Ptr(7)}, CreatedAt: &at},
+		{ID: github.Ptr(int64(2)), Event: github.Ptr("merged"), CommitID: github.Ptr("abc1234"), Issue: &github.Issue{Number: github.Ptr(8)}, CreatedAt: &at},
+		{ID: github.Ptr(int64(3)), Event: github.Ptr("subscribed"), Issue: &github.Issue{Number: github.Ptr(8)}, CreatedAt: &at},
+		{ID: github.Ptr(int64(4)), Event: github.Ptr("labeled"), Label: &github.Label{Name: github.Ptr("x")}},
+		nil,
+	}
+
+	attachIssueEvents(artifacts, ghEvents)
+
+	if len(artifacts[0].Discussions) != 1 || artifacts[0].Discussions[0].Event != "milestoned" {
+		t.Errorf("Expected milestone event on issue #7, got %+v", artifacts[0].Discussions)
+	}
+	if len(artifacts[1].Discussions) != 1 || artifacts[1].Discussions[0].CommitHash != "abc1234" {
+		t.Errorf("Expected merge event with commit on PR #8, got %+v", artifacts[1].Discussions)
+	}
+	if len(artifacts[2].Discussions) != 0 {
+		t.Errorf("Expected no events on the release, got %+v", artifacts[2].Discussions)
+	}
+}
+
+func TestConvertGitHubPullRequest_Changes(t *testing.T) {
+	pr := createSamplePullRequest()
+	pr.CommitSHAs = []string{"abc123", "def456"}
+	pr.Files = []githubmodel.PullRequestFile{
+		{Filename: "parser.go", Status: "modified"},
+		{Filename: "lexer/lexer.go", PreviousFilename: "lexer.go", Status: "renamed"},
+	}
+
+	artifact := convertGitHubPullRequest(pr)
+
+	if len(artifact.Metadata.CommitSHAs) != 2 || artifact.Metadata.CommitSHAs[1] != "def456" {
+		t.Errorf("Expected commit SHAs in metadata, got %v", artifact.Metadata.CommitSHAs)
+	}
+	want := []string{"parser.go", "lexer/lexer.go", "lexer.go"}
+	if len(artifact.Metadata.Files) != len(want) {
+		t.Fatalf("Expected files %v, got %v", want, artifact.Metadata.Files)
+	}
+	for i := range want {
+		if artifact.Metadata.Files[i] != want[i] {
+			t.Errorf("File %d: expected %q, got %q", i, want[i], artifact.Metadata.Files[i])
+		}
+	}
+}
+
+// fakeIdentityResolver resolves logins from a fixed table and counts lookups
+type fakeIdentityResolver struct {
+	emails  map[string]string
+	lookups int
+}
+
+func (r *fakeIdentityResolver) Resolve(ctx context.Context, login string) (githubmodel.Identity, error) {
+	r.lookups++
+	email, ok := r.emails[login]
+	if !ok {
+		return githubmodel.Identity{Login: login}, errors.New("not found")
+	}
+	return githubmodel.Identity{Login: login, Email: email}, nil
+}
+
+func TestResolveAuthorEmails(t *testing.T) {
+	resolver := &fakeIdentityResolver{emails: map[string]string{"alice": "alice@example.com"}}
+	artifacts := []cluster.Artifact{
+		{
+			ID:     "issue-1",
+			Author: git.Author{Name: "alice"},
+			Discussions: []cluster.Discussion{
+				{Author: git.Author{Name: "alice"}},
+				{Author: git.Author{Name: "ghost"}},
+				{Author: git.Author{Name: "carol", Email: "carol@example.com"}},
+			},
+		},
+		{ID: "pr-2", Author: git.Author{Name: "ghost"}},
+	}
+
+	resolveAuthorEmails(context.Background(), resolver, artifacts)
+
+	if artifacts[0].Author.Email != "alice@example.com" || artifacts[0].Discussions[0].Author.Email != "alice@example.com" {
+		t.Errorf("Expected alice's email on the artifact and her comment, got %+v", artifacts[0])
+	}
+	if artifacts[0].Discussions[1].Author.Email != "" || artifacts[1].Author.Email != "" {
+		t.Error("Expected unresolved logins to keep an empty email")
+	}
+	if artifacts[0].Discussions[2].Author.Email != "carol@example.com" {
+		t.Error("Expected a known email to be left alone")
+	}
+	if resolver.lookups != 2 {
+		t.Errorf("Expected one lookup per distinct login, got %d", resolver.lookups)
+	}
+}
+
+func TestConvertGitHubReviewComment_Resolved(t *testing.T) {
+	root := convertGitHubReviewComment(githubmodel.ReviewComment{ID: 10, Author: "bob", Path: "cache.go", Resolved: true})
+	reply := convertGitHubReviewComment(githubmodel.ReviewComment{ID: 11, Author: "alice", InReplyToID: 10, Resolved: true})
+
+	if !root.Resolved || !reply.Resolved {
+		t.Error("Expected resolution state to carry over to discussions")
+	}
+	if root.ThreadID != "review-comment-10" || reply.ThreadID != root.ThreadID || reply.ParentID != root.ID {
+		t.Errorf("Expected the reply in the root's thread, got root %+v reply %+v", root, reply)
+	}
+}
+
+func TestConvertGitHubPullRequest_ClosesIssues(t *testing.T) {
+	pr := createSamplePullRequest()
+	pr.ClosesIssues = []int{42, 50}
+
+	artifact := convertGitHubPullRequest(pr)
+
+	if len(artifact.Metadata.ClosesIssues) != 2 || artifact.Metadata.ClosesIssues[1] != 50 {
+		t.Errorf("Expected closing issues in metadata, got %v", artifact.Metadata.ClosesIssues)
+	}
+	related := make(map[string]int)
+	for _, ref := range artifact.Metadata.RelatedArtifacts {
+		related[ref]++
+	}
+	if related["issue-50"] != 1 || related["issue-42"] != 1 {
+		t.Errorf("Expected each closed issue related exactly once, got %v", artifact.Metadata.RelatedArtifacts)
+	}
+}
+
+func TestLinkClosedIssues(t *testing.T) {
+	artifacts := []cluster.Artifact{
+		{ID: "issue-100", Type: cluster.ArtifactIssue, Number: 7},
+		{ID: "issue-101", Type: cluster.ArtifactIssue, Number: 8},
+		{ID: "pr-200", Type: cluster.ArtifactPullRequest, Number: 9, Metadata: cluster.ArtifactMetadata{ClosesIssues: []int{7, 99}}},
+	}
+
+	linkClosedIssues(artifacts)
+
+	if refs := artifacts[0].Metadata.RelatedArtifacts; len(refs) != 1 || refs[0] != "pr-9" {
+		t.Errorf("Expected issue #7 to link back to pr-9, got %v", refs)
+	}
+	if len(artifacts[1].Metadata.RelatedArtifacts) != 0 {
+		t.Errorf("Expected issue #8 to stay unlinked, got %v", artifacts[1].Metadata.RelatedArtifacts)
+	}
+}
+
+func TestAttachProjectItems(t *testing.T) {
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	artifacts := []cluster.Artifact{
+		{ID: "issue-100", Type: cluster.ArtifactIssue, Number: 7},
+		{ID: "pr-200", Type: cluster.ArtifactPullRequest, Number: 7},
+	}
+	items := []githubmodel.ProjectItem{{
+		Project:     "Roadmap",
+		ContentType: githubmodel.ProjectContentIssue,
+		Number:      7,
+		Status:      "In Progress",
+		Iteration:   &githubmodel.Iteration{ID: "it1", Title: "Sprint 4", StartDate: start, Duration: 14},
+	}}
+
+	attachProjectItems(artifacts, items)
+
+	projects := artifacts[0].Metadata.Projects
+	if len(projects) != 1 || projects[0].Status != "In Progress" || projects[0].Iteration == nil {
+		t.Fatalf("Expected issue #7 on the Roadmap board, got %+v", projects)
+	}
+	if !projects[0].Iteration.EndDate.Equal(start.AddDate(0, 0, 14)) {
+		t.Errorf("Expected iteration end date two weeks after start, got %v", projects[0].Iteration.EndDate)
+	}
+	if len(artifacts[1].Metadata.Projects) != 0 {
+		t.Error("Expected the PR with the same number to stay off the board")
+	}
+}
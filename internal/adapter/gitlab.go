@@ -0,0 +1,324 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	gitlabmodel "github.com/Yates-Labs/thunk/internal/ingest/gitlab"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// Common errors for GitLab adapter operations
+var (
+	ErrInvalidGitLabIssueType  = errors.New("invalid issue type: expected *gitlab.Issue")
+	ErrInvalidMergeRequestType = errors.New("invalid merge request type: expected *gitlab.MergeRequest")
+)
+
+// GitLabAdapter implements the Adapter interface for gitlab.com and self-hosted GitLab
+type GitLabAdapter struct {
+	// BaseURL is the instance root for self-hosted GitLab (empty for gitlab.com)
+	BaseURL string
+
+	// Since limits the issues and merge requests fetched to those updated at or after it, so an
+	// incremental analysis only fetches what changed since its last sync (zero = all)
+	Since time.Time
+}
+
+// NewGitLabAdapter creates a new GitLab adapter instance
+// baseURL is empty for gitlab.com or the instance root, e.g. "https://gitlab.example.com/"
+func NewGitLabAdapter(baseURL string) *GitLabAdapter {
+	return &GitLabAdapter{BaseURL: baseURL}
+}
+
+// GetPlatform returns the GitLab platform identifier
+func (a *GitLabAdapter) GetPlatform() cluster.SourcePlatform {
+	return cluster.PlatformGitLab
+}
+
+// ConvertIssue converts a GitLab issue to a cluster.Artifact
+func (a *GitLabAdapter) ConvertIssue(issue interface{}) (*cluster.Artifact, error) {
+	glIssue, ok := issue.(*gitlabmodel.Issue)
+	if !ok {
+		return nil, ErrInvalidGitLabIssueType
+	}
+	return convertGitLabIssue(glIssue), nil
+}
+
+// ConvertPullRequest converts a GitLab merge request to a cluster.Artifact
+func (a *GitLabAdapter) ConvertPullRequest(mr interface{}) (*cluster.Artifact, error) {
+	glMR, ok := mr.(*gitlabmodel.MergeRequest)
+	if !ok {
+		return nil, ErrInvalidMergeRequestType
+	}
+	return convertGitLabMergeRequest(glMR), nil
+}
+
+// FetchArtifacts fetches all artifacts (issues and merge requests) from GitLab
+// owner is the project's namespace, which may contain nested groups
+func (a *GitLabAdapter) FetchArtifacts(ctx context.Context, token, owner, repo string) ([]cluster.Artifact, error) {
+	client, err := gitlabmodel.NewClient(token, a.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	pid := gitlabmodel.ProjectPath(owner, repo)
+
+	log := logging.FromContext(ctx).With("platform", cluster.PlatformGitLab)
+	runReport := report.FromContext(ctx)
+	var artifacts []cluster.Artifact
+
+	log.Info("Fetching issues")
+
+	glIssues, err := gitlabmodel.ListIssuesSince(ctx, client, pid, a.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
+	}
+
+	log.Info("Converting issues", "count", len(glIssues))
+
+	for _, glIssue := range glIssues {
+		issue := gitlabmodel.ParseIssue(glIssue)
+
+		// Notes carry the conversation and, as system notes, the issue's process history
+		if notes, err := gitlabmodel.ParseIssueNotes(ctx, client, pid, issue.IID); err != nil {
+			log.Warn("Failed to fetch issue notes", "number", issue.IID, "error", err)
+			runReport.Fail(report.StageArtifacts, fmt.Sprintf("issue #%d notes", issue.IID), err)
+		} else {
+			issue.Notes = notes
+		}
+
+		artifact, err := a.ConvertIssue(issue)
+		if err != nil {
+			log.Warn("Failed to convert issue", "number", issue.IID, "error", err)
+			runReport.Skip(report.StageArtifacts, fmt.Sprintf("issue #%d", issue.IID), err)
+			continue
+		}
+
+		artifacts = append(artifacts, *artifact)
+	}
+
+	log.Info("Fetching merge requests")
+
+	glMRs, err := gitlabmodel.ListMergeRequestsSince(ctx, client, pid, a.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch merge requests: %w", err)
+	}
+
+	log.Info("Converting merge requests", "count", len(glMRs))
+
+	for _, glMR := range glMRs {
+		mr := gitlabmodel.ParseMergeRequest(glMR)
+
+		if notes, err := gitlabmodel.ParseMergeRequestNotes(ctx, client, pid, mr.IID); err != nil {
+			log.Warn("Failed to fetch merge request notes", "number", mr.IID, "error", err)
+			runReport.Fail(report.StageArtifacts, fmt.Sprintf("merge request !%d notes", mr.IID), err)
+		} else {
+			mr.Notes = notes
+		}
+
+		if approvals, err := gitlabmodel.ParseApprovals(ctx, client, pid, mr.IID); err != nil {
+			log.Warn("Failed to fetch merge request approvals", "number", mr.IID, "error", err)
+			runReport.Fail(report.StageArtifacts, fmt.Sprintf("merge request !%d approvals", mr.IID), err)
+		} else {
+			mr.Approvals = approvals
+		}
+
+		// Files and commits let clustering link the MR by SHA and file overlap, not just "!123" mentions
+		if err := gitlabmodel.ParseMergeRequestChanges(ctx, client, pid, mr); err != nil {
+			log.Warn("Failed to fetch merge request changes", "number", mr.IID, "error", err)
+			runReport.Fail(report.StageArtifacts, fmt.Sprintf("merge request !%d changes", mr.IID), err)
+		}
+
+		artifact, err := a.ConvertPullRequest(mr)
+		if err != nil {
+			log.Warn("Failed to convert merge request", "number", mr.IID, "error", err)
+			runReport.Skip(report.StageArtifacts, fmt.Sprintf("merge request !%d", mr.IID), err)
+			continue
+		}
+
+		artifacts = append(artifacts, *artifact)
+	}
+
+	log.Info("Converted artifacts", "artifacts", len(artifacts))
+
+	return artifacts, nil
+}
+
+// convertGitLabIssue converts a GitLab issue to a cluster.Artifact
+func convertGitLabIssue(issue *gitlabmodel.Issue) *cluster.Artifact {
+	artifact := &cluster.Artifact{
+		ID:          fmt.Sprintf("issue-%d", issue.ID),
+		Number:      issue.IID,
+		Type:        cluster.ArtifactIssue,
+		Title:       issue.Title,
+		Description: issue.Body,
+		State:       normalizeGitLabState(issue.State),
+		Author: git.Author{
+			Name:  issue.Author,
+			Email: "", // GitLab API doesn't provide email in issue context
+		},
+		Assignees: issue.Assignees,
+		Labels:    issue.Labels,
+		CreatedAt: issue.CreatedAt,
+		UpdatedAt: issue.UpdatedAt,
+		ClosedAt:  issue.ClosedAt,
+		URL:       issue.WebURL,
+	}
+
+	artifact.Discussions = make([]cluster.Discussion, 0, len(issue.Notes))
+	for _, note := range issue.Notes {
+		artifact.Discussions = append(artifact.Discussions, convertGitLabNote(note))
+	}
+	sortDiscussions(artifact.Discussions)
+
+	if issue.Milestone != nil {
+		artifact.Metadata.Milestone = issue.Milestone.Title
+		artifact.Metadata.DueDate = issue.Milestone.DueDate
+	}
+
+	return artifact
+}
+
+// convertGitLabMergeRequest converts a GitLab merge request to a cluster.Artifact
+func convertGitLabMergeRequest(mr *gitlabmodel.MergeRequest) *cluster.Artifact {
+	artifact := &cluster.Artifact{
+		ID:          fmt.Sprintf("mr-%d", mr.ID),
+		Number:      mr.IID,
+		Type:        cluster.ArtifactMergeRequest,
+		Title:       mr.Title,
+		Description: mr.Description,
+		State:       normalizeGitLabState(mr.State),
+		Author: git.Author{
+			Name:  mr.Author,
+			Email: "", // GitLab API doesn't provide email in MR context
+		},
+		Assignees: mr.Assignees,
+		Labels:    mr.Labels,
+		CreatedAt: mr.CreatedAt,
+		UpdatedAt: mr.UpdatedAt,
+		ClosedAt:  mr.ClosedAt,
+		MergedAt:  mr.MergedAt,
+		URL:       mr.WebURL,
+	}
+
+	artifact.Discussions = make([]cluster.Discussion, 0, len(mr.Notes)+len(mr.Approvals))
+	approved := make(map[string]bool)
+	for _, note := range mr.Notes {
+		discussion := convertGitLabNote(note)
+		if discussion.ReviewState == "approved" {
+			approved[note.Author] = true
+		}
+		artifact.Discussions = append(artifact.Discussions, discussion)
+	}
+
+	// The approvals API has no timestamps; approvals without a system note are dated by the MR's last update
+	for _, approval := range mr.Approvals {
+		if approved[approval.Author] {
+			continue
+		}
+		artifact.Discussions = append(artifact.Discussions, cluster.Discussion{
+			ID:          fmt.Sprintf("approval-%d-%s", mr.ID, approval.Author),
+			Type:        cluster.DiscussionReview,
+			Author:      git.Author{Name: approval.Author},
+			CreatedAt:   mr.UpdatedAt,
+			UpdatedAt:   mr.UpdatedAt,
+			ReviewState: "approved",
+		})
+	}
+	sortDiscussions(artifact.Discussions)
+
+	artifact.Metadata = cluster.ArtifactMetadata{
+		BaseBranch:     mr.TargetBranch,
+		HeadBranch:     mr.SourceBranch,
+		MergeCommitSHA: mr.MergeCommitSHA,
+		ChangedFiles:   len(mr.Files),
+		IsDraft:        mr.Draft,
+		CommitSHAs:     mr.CommitSHAs,
+		Files:          mr.Files,
+	}
+	if len(mr.Approvals) > 0 {
+		artifact.Metadata.ReviewState = "approved"
+	}
+
+	if mr.Milestone != nil {
+		artifact.Metadata.Milestone = mr.Milestone.Title
+		artifact.Metadata.DueDate = mr.Milestone.DueDate
+	}
+
+	return artifact
+}
+
+// convertGitLabNote converts a GitLab note to a cluster.Discussion
+// System notes become process events (or approvals), diff notes become review threads
+func convertGitLabNote(note gitlabmodel.Note) cluster.Discussion {
+	discussion := cluster.Discussion{
+		ID:   fmt.Sprintf("note-%d", note.ID),
+		Type: cluster.DiscussionNote,
+		Author: git.Author{
+			Name:  note.Author,
+			Email: "",
+		},
+		Body:      note.Body,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+	}
+
+	switch {
+	case note.System && strings.HasPrefix(note.Body, "approved this merge request"):
+		discussion.Type = cluster.DiscussionReview
+		discussion.ReviewState = "approved"
+	case note.System:
+		discussion.Type = cluster.DiscussionEvent
+		discussion.Event = gitLabSystemNoteEvent(note.Body)
+	case note.Path != "":
+		discussion.Type = cluster.DiscussionReviewThread
+		discussion.FilePath = note.Path
+		discussion.LineNumber = note.Line
+		discussion.CommitHash = note.CommitID
+		discussion.Resolved = note.Resolved
+		discussion.ThreadID = discussion.ID
+	}
+
+	return discussion
+}
+
+// gitLabSystemNotePrefixes maps the start of a system note body to the equivalent GitHub event name
+var gitLabSystemNotePrefixes = []struct {
+	prefix string
+	event  string
+}{
+	{"added ~", "labeled"},
+	{"removed ~", "unlabeled"},
+	{"assigned to ", "assigned"},
+	{"unassigned ", "unassigned"},
+	{"changed milestone to ", "milestoned"},
+	{"removed milestone", "demilestoned"},
+	{"closed", "closed"},
+	{"reopened", "reopened"},
+	{"merged", "merged"},
+	{"mentioned in ", "referenced"},
+	{"unapproved this merge request", "unapproved"},
+}
+
+// gitLabSystemNoteEvent classifies a system note, defaulting to "note" for unrecognized changes
+func gitLabSystemNoteEvent(body string) string {
+	for _, candidate := range gitLabSystemNotePrefixes {
+		if strings.HasPrefix(body, candidate.prefix) {
+			return candidate.event
+		}
+	}
+	return "note"
+}
+
+// normalizeGitLabState maps GitLab states onto the GitHub-style states used across artifacts
+func normalizeGitLabState(state string) string {
+	if state == "opened" {
+		return "open"
+	}
+	return state
+}
@@ -0,0 +1,118 @@
+package adapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	gitlabmodel "github.com/Yates-Labs/thunk/internal/ingest/gitlab"
+)
+
+func createSampleMergeRequest() *gitlabmodel.MergeRequest {
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	return &gitlabmodel.MergeRequest{
+		ID:             9001,
+		IID:            7,
+		Title:          "Add cache",
+		State:          "opened",
+		Author:         "alice",
+		CreatedAt:      created,
+		UpdatedAt:      created.Add(3 * time.Hour),
+		SourceBranch:   "feature/cache",
+		TargetBranch:   "main",
+		MergeCommitSHA: "abc123",
+		Files:          []string{"cache.go"},
+		CommitSHAs:     []string{"def456"},
+		Notes: []gitlabmodel.Note{
+			{ID: 1, Author: "bob", Body: "added ~performance label", System: true, CreatedAt: created.Add(time.Minute)},
+			{ID: 2, Author: "carol", Body: "Leaks a handle", Path: "cache.go", Line: 42, Resolved: true, CreatedAt: created.Add(time.Hour)},
+			{ID: 3, Author: "bob", Body: "approved this merge request", System: true, CreatedAt: created.Add(2 * time.Hour)},
+			{ID: 4, Author: "carol", Body: "Nice work", CreatedAt: created.Add(150 * time.Minute)},
+		},
+		Approvals: []gitlabmodel.Approval{{Author: "bob"}, {Author: "dave"}},
+	}
+}
+
+func TestConvertGitLabMergeRequest(t *testing.T) {
+	artifact, err := NewGitLabAdapter("").ConvertPullRequest(createSampleMergeRequest())
+	if err != nil {
+		t.Fatalf("ConvertPullRequest failed: %v", err)
+	}
+
+	if artifact.ID != "mr-9001" || artifact.Number != 7 || artifact.Type != cluster.ArtifactMergeRequest {
+		t.Errorf("Unexpected identity: ID=%s Number=%d Type=%s", artifact.ID, artifact.Number, artifact.Type)
+	}
+	if artifact.State != "open" {
+		t.Errorf("Expected opened to normalize to open, got %s", artifact.State)
+	}
+	if artifact.Metadata.HeadBranch != "feature/cache" || artifact.Metadata.BaseBranch != "main" {
+		t.Errorf("Expected branches feature/cache -> main, got %s -> %s", artifact.Metadata.HeadBranch, artifact.Metadata.BaseBranch)
+	}
+	if artifact.Metadata.ReviewState != "approved" {
+		t.Errorf("Expected approved review state, got %q", artifact.Metadata.ReviewState)
+	}
+
+	byType := make(map[cluster.DiscussionType]int)
+	for _, d := range artifact.Discussions {
+		byType[d.Type]++
+	}
+	// bob's approval note plus dave's approval from the approvals API
+	if byType[cluster.DiscussionReview] != 2 {
+		t.Errorf("Expected 2 approvals without duplicating bob, got %d", byType[cluster.DiscussionReview])
+	}
+	if byType[cluster.DiscussionEvent] != 1 || byType[cluster.DiscussionReviewThread] != 1 || byType[cluster.DiscussionNote] != 1 {
+		t.Errorf("Unexpected discussion types: %v", byType)
+	}
+
+	for _, d := range artifact.Discussions {
+		switch d.ID {
+		case "note-1":
+			if d.Event != "labeled" {
+				t.Errorf("Expected label note to become a labeled event, got %q", d.Event)
+			}
+		case "note-2":
+			if d.FilePath != "cache.go" || d.LineNumber != 42 || !d.Resolved {
+				t.Errorf("Expected resolved review thread on cache.go:42, got %+v", d)
+			}
+		}
+	}
+}
+
+func TestConvertGitLabIssue(t *testing.T) {
+	issue := &gitlabmodel.Issue{
+		ID:        501,
+		IID:       12,
+		Title:     "Crash on start",
+		State:     "closed",
+		Author:    "alice",
+		Milestone: &gitlabmodel.Milestone{Title: "v1.0"},
+		Notes:     []gitlabmodel.Note{{ID: 1, Author: "bob", Body: "Confirmed"}},
+	}
+
+	artifact, err := NewGitLabAdapter("").ConvertIssue(issue)
+	if err != nil {
+		t.Fatalf("ConvertIssue failed: %v", err)
+	}
+	if artifact.ID != "issue-501" || artifact.Number != 12 || artifact.State != "closed" {
+		t.Errorf("Unexpected issue artifact: %+v", artifact)
+	}
+	if artifact.Metadata.Milestone != "v1.0" {
+		t.Errorf("Expected milestone v1.0, got %q", artifact.Metadata.Milestone)
+	}
+	if len(artifact.Discussions) != 1 || artifact.Discussions[0].Type != cluster.DiscussionNote {
+		t.Errorf("Expected one note, got %+v", artifact.Discussions)
+	}
+}
+
+func TestGitLabAdapter_InvalidTypes(t *testing.T) {
+	a := NewGitLabAdapter("")
+	if _, err := a.ConvertIssue("not an issue"); err != ErrInvalidGitLabIssueType {
+		t.Errorf("Expected ErrInvalidGitLabIssueType, got %v", err)
+	}
+	if _, err := a.ConvertPullRequest(createSamplePullRequest()); err != ErrInvalidMergeRequestType {
+		t.Errorf("Expected ErrInvalidMergeRequestType, got %v", err)
+	}
+	if a.GetPlatform() != cluster.PlatformGitLab {
+		t.Errorf("Expected platform gitlab, got %s", a.GetPlatform())
+	}
+}
@@ -0,0 +1,182 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	jiramodel "github.com/Yates-Labs/thunk/internal/ingest/jira"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// Common errors for Jira adapter operations
+var (
+	ErrInvalidJiraIssueType = errors.New("invalid issue type: expected *jira.Issue")
+	ErrNoPullRequests       = errors.New("issue trackers do not track pull requests")
+)
+
+// JiraAdapter implements the Adapter interface for Jira Cloud and Jira Server/Data Center
+// Jira tickets supplement a repository's code hosting platform rather than replace it
+type JiraAdapter struct {
+	// BaseURL and Email configure the client; empty values are read from JIRA_URL and JIRA_EMAIL
+	BaseURL string
+	Email   string
+
+	// JQL overrides the default query of every issue in the project
+	JQL string
+}
+
+// NewJiraAdapter creates a new Jira adapter instance
+func NewJiraAdapter(baseURL, email string) *JiraAdapter {
+	return &JiraAdapter{BaseURL: baseURL, Email: email}
+}
+
+// GetPlatform returns the Jira platform identifier
+func (a *JiraAdapter) GetPlatform() cluster.SourcePlatform {
+	return cluster.PlatformJira
+}
+
+// ConvertIssue converts a Jira issue (story, bug, epic, ...) to a cluster.Artifact
+func (a *JiraAdapter) ConvertIssue(issue interface{}) (*cluster.Artifact, error) {
+	jiraIssue, ok := issue.(*jiramodel.Issue)
+	if !ok {
+		return nil, ErrInvalidJiraIssueType
+	}
+	return convertJiraIssue(jiraIssue), nil
+}
+
+// ConvertPullRequest always fails; Jira has no pull requests
+func (a *JiraAdapter) ConvertPullRequest(pr interface{}) (*cluster.Artifact, error) {
+	return nil, ErrNoPullRequests
+}
+
+// FetchArtifacts fetches all tickets of a Jira project
+// token is the Jira API token (JIRA_API_TOKEN when empty), owner is unused and repo is the project key
+func (a *JiraAdapter) FetchArtifacts(ctx context.Context, token, owner, repo string) ([]cluster.Artifact, error) {
+	client := jiramodel.NewClient(a.BaseURL, a.Email, token)
+
+	jql := a.JQL
+	if jql == "" {
+		if repo == "" {
+			return nil, fmt.Errorf("jira project key is required")
+		}
+		jql = jiramodel.ProjectJQL(repo)
+	}
+
+	log := logging.FromContext(ctx).With("platform", cluster.PlatformJira)
+	log.Info("Fetching tickets")
+
+	issues, err := jiramodel.SearchIssues(ctx, client, jql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+
+	artifacts := make([]cluster.Artifact, 0, len(issues))
+	for i := range issues {
+		artifact, err := a.ConvertIssue(&issues[i])
+		if err != nil {
+			log.Warn("Failed to convert ticket", "key", issues[i].Key, "error", err)
+			report.FromContext(ctx).Skip(report.StageArtifacts, issues[i].Key, err)
+			continue
+		}
+		artifacts = append(artifacts, *artifact)
+	}
+
+	log.Info("Converted tickets", "tickets", len(artifacts))
+
+	return artifacts, nil
+}
+
+// convertJiraIssue converts a Jira issue to a ticket artifact
+func convertJiraIssue(issue *jiramodel.Issue) *cluster.Artifact {
+	artifact := &cluster.Artifact{
+		ID:          fmt.Sprintf("ticket-%s", issue.ID),
+		Number:      ticketNumber(issue.Key),
+		Type:        cluster.ArtifactTicket,
+		Title:       issue.Summary,
+		Description: issue.Description,
+		State:       normalizeJiraState(issue.StatusCategory),
+		Author:      convertJiraUser(issue.Reporter),
+		Labels:      issue.Labels,
+		CreatedAt:   issue.Created,
+		UpdatedAt:   issue.Updated,
+		ClosedAt:    issue.Resolved,
+		URL:         issue.URL,
+	}
+
+	artifact.Assignees = make([]string, 0, 1)
+	if issue.Assignee != nil {
+		artifact.Assignees = append(artifact.Assignees, issue.Assignee.DisplayName)
+	}
+
+	artifact.Discussions = make([]cluster.Discussion, 0, len(issue.Comments)+len(issue.Transitions))
+	for _, comment := range issue.Comments {
+		artifact.Discussions = append(artifact.Discussions, cluster.Discussion{
+			ID:        fmt.Sprintf("comment-%s", comment.ID),
+			Type:      cluster.DiscussionComment,
+			Author:    convertJiraUser(comment.Author),
+			Body:      comment.Body,
+			CreatedAt: comment.Created,
+			UpdatedAt: comment.Updated,
+		})
+	}
+	for _, transition := range issue.Transitions {
+		artifact.Discussions = append(artifact.Discussions, cluster.Discussion{
+			ID:        fmt.Sprintf("transition-%s", transition.ID),
+			Type:      cluster.DiscussionEvent,
+			Author:    convertJiraUser(transition.Author),
+			Body:      fmt.Sprintf("moved from %s to %s", transition.From, transition.To),
+			CreatedAt: transition.At,
+			UpdatedAt: transition.At,
+			Event:     "transitioned",
+		})
+	}
+	sortDiscussions(artifact.Discussions)
+
+	artifact.Metadata = cluster.ArtifactMetadata{
+		Priority:   issue.Priority,
+		DueDate:    issue.DueDate,
+		TicketKey:  issue.Key,
+		TicketType: issue.Type,
+		ParentKey:  issue.ParentKey,
+	}
+	if len(issue.FixVersions) > 0 {
+		artifact.Metadata.Milestone = issue.FixVersions[0]
+	}
+	if issue.ParentKey != "" {
+		artifact.Metadata.RelatedArtifacts = []string{issue.ParentKey}
+	}
+
+	return artifact
+}
+
+// convertJiraUser converts a Jira user to a git author, keeping the email when Jira exposes it
+func convertJiraUser(user jiramodel.User) git.Author {
+	return git.Author{Name: user.DisplayName, Email: user.Email}
+}
+
+// ticketNumber returns the numeric part of a ticket key ("PROJ-123" -> 123), or 0
+func ticketNumber(key string) int {
+	i := strings.LastIndex(key, "-")
+	if i < 0 {
+		return 0
+	}
+	number, err := strconv.Atoi(key[i+1:])
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// normalizeJiraState maps a Jira status category onto open/closed
+func normalizeJiraState(statusCategory string) string {
+	if statusCategory == jiramodel.StatusCategoryDone {
+		return "closed"
+	}
+	return "open"
+}
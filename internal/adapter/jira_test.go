@@ -0,0 +1,70 @@
+package adapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	jiramodel "github.com/Yates-Labs/thunk/internal/ingest/jira"
+)
+
+func TestConvertJiraIssue(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	issue := &jiramodel.Issue{
+		ID:             "10002",
+		Key:            "PROJ-42",
+		Summary:        "Pay with card",
+		Type:           "Story",
+		StatusCategory: jiramodel.StatusCategoryDone,
+		Reporter:       jiramodel.User{DisplayName: "Alice", Email: "alice@example.com"},
+		Assignee:       &jiramodel.User{DisplayName: "Bob"},
+		FixVersions:    []string{"1.2"},
+		ParentKey:      "PROJ-1",
+		Created:        created,
+		Comments:       []jiramodel.Comment{{ID: "7", Author: jiramodel.User{DisplayName: "Carol"}, Body: "LGTM", Created: created.Add(2 * time.Hour)}},
+		Transitions: []jiramodel.Transition{
+			{ID: "h1", Author: jiramodel.User{DisplayName: "Bob"}, From: "To Do", To: "In Progress", At: created.Add(time.Hour)},
+		},
+	}
+
+	artifact, err := NewJiraAdapter("", "").ConvertIssue(issue)
+	if err != nil {
+		t.Fatalf("ConvertIssue failed: %v", err)
+	}
+
+	if artifact.Type != cluster.ArtifactTicket || artifact.Number != 42 || artifact.State != "closed" {
+		t.Errorf("Unexpected ticket: type=%s number=%d state=%s", artifact.Type, artifact.Number, artifact.State)
+	}
+	if artifact.Author.Email != "alice@example.com" {
+		t.Errorf("Expected reporter email to carry over, got %+v", artifact.Author)
+	}
+	if artifact.Metadata.TicketKey != "PROJ-42" || artifact.Metadata.TicketType != "Story" || artifact.Metadata.Milestone != "1.2" {
+		t.Errorf("Unexpected metadata: %+v", artifact.Metadata)
+	}
+	if len(artifact.Metadata.RelatedArtifacts) != 1 || artifact.Metadata.RelatedArtifacts[0] != "PROJ-1" {
+		t.Errorf("Expected the epic as a related artifact, got %v", artifact.Metadata.RelatedArtifacts)
+	}
+
+	if len(artifact.Discussions) != 2 {
+		t.Fatalf("Expected a transition and a comment, got %+v", artifact.Discussions)
+	}
+	transition := artifact.Discussions[0]
+	if transition.Type != cluster.DiscussionEvent || transition.Body != "moved from To Do to In Progress" {
+		t.Errorf("Expected the status transition first, got %+v", transition)
+	}
+}
+
+func TestJiraAdapter_NoPullRequests(t *testing.T) {
+	if _, err := NewJiraAdapter("", "").ConvertPullRequest(nil); err != ErrNoPullRequests {
+		t.Errorf("Expected ErrNoPullRequests, got %v", err)
+	}
+}
+
+func TestTicketNumber(t *testing.T) {
+	tests := map[string]int{"PROJ-123": 123, "A2B-7": 7, "PROJ": 0, "PROJ-x": 0}
+	for key, want := range tests {
+		if got := ticketNumber(key); got != want {
+			t.Errorf("ticketNumber(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	linearmodel "github.com/Yates-Labs/thunk/internal/ingest/linear"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// ErrInvalidLinearIssueType is returned when ConvertIssue is given anything but a Linear issue
+var ErrInvalidLinearIssueType = errors.New("invalid issue type: expected *linear.Issue")
+
+// LinearAdapter implements the Adapter interface for Linear
+// Like Jira, Linear tickets supplement a repository's code hosting platform rather than replace it
+type LinearAdapter struct{}
+
+// NewLinearAdapter creates a new Linear adapter instance
+func NewLinearAdapter() *LinearAdapter {
+	return &LinearAdapter{}
+}
+
+// GetPlatform returns the Linear platform identifier
+func (a *LinearAdapter) GetPlatform() cluster.SourcePlatform {
+	return cluster.PlatformLinear
+}
+
+// ConvertIssue converts a Linear issue to a cluster.Artifact
+func (a *LinearAdapter) ConvertIssue(issue interface{}) (*cluster.Artifact, error) {
+	linearIssue, ok := issue.(*linearmodel.Issue)
+	if !ok {
+		return nil, ErrInvalidLinearIssueType
+	}
+	return convertLinearIssue(linearIssue), nil
+}
+
+// ConvertPullRequest always fails; Linear has no pull requests
+func (a *LinearAdapter) ConvertPullRequest(pr interface{}) (*cluster.Artifact, error) {
+	return nil, ErrNoPullRequests
+}
+
+// FetchArtifacts fetches all tickets of a Linear team
+// token is the Linear API key (LINEAR_API_KEY when empty), owner is unused and repo is the team key
+func (a *LinearAdapter) FetchArtifacts(ctx context.Context, token, owner, repo string) ([]cluster.Artifact, error) {
+	if repo == "" {
+		return nil, fmt.Errorf("linear team key is required")
+	}
+	client := linearmodel.NewClient(token)
+
+	log := logging.FromContext(ctx).With("platform", cluster.PlatformLinear)
+	log.Info("Fetching tickets")
+
+	issues, err := linearmodel.ListTeamIssues(ctx, client, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+
+	artifacts := make([]cluster.Artifact, 0, len(issues))
+	for i := range issues {
+		artifact, err := a.ConvertIssue(&issues[i])
+		if err != nil {
+			log.Warn("Failed to convert ticket", "key", issues[i].Identifier, "error", err)
+			report.FromContext(ctx).Skip(report.StageArtifacts, issues[i].Identifier, err)
+			continue
+		}
+		artifacts = append(artifacts, *artifact)
+	}
+
+	log.Info("Converted tickets", "tickets", len(artifacts))
+
+	return artifacts, nil
+}
+
+// convertLinearIssue converts a Linear issue to a ticket artifact
+// The issue's project becomes its milestone and its cycle a board iteration
+func convertLinearIssue(issue *linearmodel.Issue) *cluster.Artifact {
+	state := "open"
+	if issue.IsClosed() {
+		state = "closed"
+	}
+
+	artifact := &cluster.Artifact{
+		ID:          fmt.Sprintf("ticket-%s", issue.ID),
+		Number:      issue.Number,
+		Type:        cluster.ArtifactTicket,
+		Title:       issue.Title,
+		Description: issue.Description,
+		State:       state,
+		Author:      convertLinearUser(issue.Creator),
+		Labels:      issue.Labels,
+		CreatedAt:   issue.CreatedAt,
+		UpdatedAt:   issue.UpdatedAt,
+		ClosedAt:    issue.ClosedAt(),
+		URL:         issue.URL,
+	}
+
+	artifact.Assignees = make([]string, 0, 1)
+	if issue.Assignee != nil {
+		artifact.Assignees = append(artifact.Assignees, issue.Assignee.Name)
+	}
+
+	artifact.Discussions = make([]cluster.Discussion, 0, len(issue.Comments)+len(issue.Transitions))
+	for _, comment := range issue.Comments {
+		artifact.Discussions = append(artifact.Discussions, cluster.Discussion{
+			ID:        fmt.Sprintf("comment-%s", comment.ID),
+			Type:      cluster.DiscussionComment,
+			Author:    convertLinearUser(comment.Author),
+			Body:      comment.Body,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		})
+	}
+	for _, transition := range issue.Transitions {
+		body := fmt.Sprintf("moved to %s", transition.To)
+		if transition.From != "" {
+			body = fmt.Sprintf("moved from %s to %s", transition.From, transition.To)
+		}
+		artifact.Discussions = append(artifact.Discussions, cluster.Discussion{
+			ID:        fmt.Sprintf("transition-%s", transition.ID),
+			Type:      cluster.DiscussionEvent,
+			Author:    convertLinearUser(transition.Author),
+			Body:      body,
+			CreatedAt: transition.At,
+			UpdatedAt: transition.At,
+			Event:     "transitioned",
+		})
+	}
+	sortDiscussions(artifact.Discussions)
+
+	artifact.Metadata = cluster.ArtifactMetadata{
+		Priority:  issue.Priority,
+		DueDate:   issue.DueDate,
+		Milestone: issue.Project,
+		TicketKey: issue.Identifier,
+		ParentKey: issue.ParentKey,
+	}
+	if issue.ParentKey != "" {
+		artifact.Metadata.RelatedArtifacts = []string{issue.ParentKey}
+	}
+
+	// Cycles are team sprints; record them like project board iterations so iteration summaries cover them
+	if issue.Cycle != nil {
+		artifact.Metadata.Projects = []cluster.ProjectStatus{{
+			Project: issue.Project,
+			Status:  issue.State,
+			Iteration: &cluster.Iteration{
+				ID:        issue.Cycle.ID,
+				Title:     issue.Cycle.Title(),
+				StartDate: issue.Cycle.StartsAt,
+				EndDate:   issue.Cycle.EndsAt,
+			},
+		}}
+	}
+
+	return artifact
+}
+
+// convertLinearUser converts a Linear user to a git author
+func convertLinearUser(user linearmodel.User) git.Author {
+	return git.Author{Name: user.Name, Email: user.Email}
+}
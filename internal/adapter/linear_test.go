@@ -0,0 +1,72 @@
+package adapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	linearmodel "github.com/Yates-Labs/thunk/internal/ingest/linear"
+)
+
+func TestConvertLinearIssue(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	completed := created.Add(48 * time.Hour)
+	issue := &linearmodel.Issue{
+		ID:          "uuid-42",
+		Identifier:  "ENG-42",
+		Number:      42,
+		Title:       "Fix login",
+		State:       "Done",
+		StateType:   linearmodel.StateTypeCompleted,
+		Creator:     linearmodel.User{Name: "Alice", Email: "alice@example.com"},
+		Assignee:    &linearmodel.User{Name: "Bob"},
+		Project:     "Auth revamp",
+		Cycle:       &linearmodel.Cycle{ID: "cy-1", Number: 4, StartsAt: created, EndsAt: created.Add(14 * 24 * time.Hour)},
+		ParentKey:   "ENG-41",
+		CreatedAt:   created,
+		CompletedAt: &completed,
+		Comments:    []linearmodel.Comment{{ID: "c1", Author: linearmodel.User{Name: "Carol"}, Body: "LGTM", CreatedAt: created.Add(2 * time.Hour)}},
+		Transitions: []linearmodel.Transition{
+			{ID: "h1", Author: linearmodel.User{Name: "Bob"}, From: "Todo", To: "In Progress", At: created.Add(time.Hour)},
+		},
+	}
+
+	artifact, err := NewLinearAdapter().ConvertIssue(issue)
+	if err != nil {
+		t.Fatalf("ConvertIssue failed: %v", err)
+	}
+
+	if artifact.Type != cluster.ArtifactTicket || artifact.Number != 42 || artifact.State != "closed" || artifact.ClosedAt == nil {
+		t.Errorf("Unexpected ticket: type=%s number=%d state=%s", artifact.Type, artifact.Number, artifact.State)
+	}
+	if artifact.Metadata.TicketKey != "ENG-42" || artifact.Metadata.Milestone != "Auth revamp" {
+		t.Errorf("Unexpected metadata: %+v", artifact.Metadata)
+	}
+	if len(artifact.Metadata.RelatedArtifacts) != 1 || artifact.Metadata.RelatedArtifacts[0] != "ENG-41" {
+		t.Errorf("Expected the parent as a related artifact, got %v", artifact.Metadata.RelatedArtifacts)
+	}
+
+	if len(artifact.Metadata.Projects) != 1 {
+		t.Fatalf("Expected the cycle as a board iteration, got %+v", artifact.Metadata.Projects)
+	}
+	placement := artifact.Metadata.Projects[0]
+	if placement.Status != "Done" || placement.Iteration == nil || placement.Iteration.Title != "Cycle 4" {
+		t.Errorf("Unexpected cycle placement: %+v", placement)
+	}
+
+	if len(artifact.Discussions) != 2 {
+		t.Fatalf("Expected a transition and a comment, got %+v", artifact.Discussions)
+	}
+	if transition := artifact.Discussions[0]; transition.Type != cluster.DiscussionEvent || transition.Body != "moved from Todo to In Progress" {
+		t.Errorf("Expected the state transition first, got %+v", transition)
+	}
+}
+
+func TestLinearAdapter_InvalidType(t *testing.T) {
+	if _, err := NewLinearAdapter().ConvertIssue("ENG-42"); err != ErrInvalidLinearIssueType {
+		t.Errorf("Expected ErrInvalidLinearIssueType, got %v", err)
+	}
+	if _, err := NewLinearAdapter().ConvertPullRequest(nil); err != ErrNoPullRequests {
+		t.Errorf("Expected ErrNoPullRequests, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package adapter
+
+import (
+	"sync"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+// Factory creates the adapter for a code hosting platform
+// baseURL is the instance root for self-hosted platforms, or empty for the public one
+type Factory func(baseURL string) Adapter
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[cluster.SourcePlatform]Factory{
+		cluster.PlatformGitHub: func(baseURL string) Adapter { return NewGitHubAdapter() },
+		cluster.PlatformGitLab: func(baseURL string) Adapter { return NewGitLabAdapter(baseURL) },
+	}
+)
+
+// Register makes factory the adapter for repositories hosted on platform, replacing any registered
+// before, so analysis fetches the platform's pull requests and issues with it
+func Register(platform cluster.SourcePlatform, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[platform] = factory
+}
+
+// ForPlatform creates the registered adapter for platform, or returns nil if none is registered
+func ForPlatform(platform cluster.SourcePlatform, baseURL string) Adapter {
+	registryMu.RLock()
+	factory := registry[platform]
+	registryMu.RUnlock()
+
+	if factory == nil {
+		return nil
+	}
+	return factory(baseURL)
+}
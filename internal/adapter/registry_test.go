@@ -0,0 +1,26 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+func TestForPlatform(t *testing.T) {
+	if _, ok := ForPlatform(cluster.PlatformGitHub, "").(*GitHubAdapter); !ok {
+		t.Error("Expected the GitHub adapter registered")
+	}
+	gitLab, ok := ForPlatform(cluster.PlatformGitLab, "https://gitlab.example.com/").(*GitLabAdapter)
+	if !ok || gitLab.BaseURL != "https://gitlab.example.com/" {
+		t.Errorf("Expected the GitLab adapter for the instance, got %+v", gitLab)
+	}
+	if adapter := ForPlatform(cluster.PlatformBitbucket, ""); adapter != nil {
+		t.Errorf("Expected no Bitbucket adapter, got %T", adapter)
+	}
+
+	Register(cluster.PlatformBitbucket, func(baseURL string) Adapter { return NewFileAdapter(baseURL) })
+	defer Register(cluster.PlatformBitbucket, nil)
+	if _, ok := ForPlatform(cluster.PlatformBitbucket, "").(*FileAdapter); !ok {
+		t.Error("Expected the registered Bitbucket adapter")
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TokenEnv names the environment variable holding the bearer token clients must send
+const TokenEnv = "THUNK_API_TOKEN"
+
+// Allowlist restricts the repositories clients may name
+// Entries are URL prefixes for remote repositories (e.g. https://github.com/owner) and directories
+// for local ones. Remote repositories are allowed when the list has no remote entries; local paths,
+// which would expose the server's file system, only when an entry contains them
+type Allowlist []string
+
+// Allows reports whether clients may name repo
+func (a Allowlist) Allows(repo string) bool {
+	if orchestrator.IsRemoteRepository(repo) {
+		restricted := false
+		for _, entry := range a {
+			if !orchestrator.IsRemoteRepository(entry) {
+				continue
+			}
+			restricted = true
+			if hasPathPrefix(repo, entry) {
+				return true
+			}
+		}
+		return !restricted
+	}
+
+	path := localPath(repo)
+	for _, entry := range a {
+		if orchestrator.IsRemoteRepository(entry) {
+			continue
+		}
+		rel, err := filepath.Rel(localPath(entry), path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix reports whether url is prefix or lies under it, so that https://host/owner does not
+// allow https://host/owner-other
+func hasPathPrefix(url, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return url == prefix || strings.HasPrefix(url, prefix+"/")
+}
+
+// localPath returns the absolute path of a local repository with symlinks resolved, so that a link
+// inside an allowed directory cannot point outside it
+func localPath(repo string) string {
+	path := strings.TrimPrefix(repo, "file://")
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
+
+// UnaryTokenAuth returns an interceptor that rejects calls without the bearer token in their
+// authorization metadata
+func UnaryTokenAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, token); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamTokenAuth is UnaryTokenAuth for streaming calls, such as server reflection
+func StreamTokenAuth(token string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(stream.Context(), token); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// authorize checks the bearer token of an incoming call in constant time
+func authorize(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		scheme, credentials, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") && subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
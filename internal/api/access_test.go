@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAllowlist(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "repos")
+	outside := filepath.Join(root, "secrets")
+	for _, dir := range []string{filepath.Join(allowed, "app"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(allowed, "link")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		allow Allowlist
+		repo  string
+		want  bool
+	}{
+		{"remote without entries", nil, "https://github.com/owner/repo", true},
+		{"local without entries", nil, filepath.Join(allowed, "app"), false},
+		{"local inside entry", Allowlist{allowed}, filepath.Join(allowed, "app"), true},
+		{"file URL inside entry", Allowlist{allowed}, "file://" + filepath.Join(allowed, "app"), true},
+		{"local escaping entry", Allowlist{allowed}, filepath.Join(allowed, "..", "secrets"), false},
+		{"symlink escaping entry", Allowlist{allowed}, link, false},
+		{"local entry leaves remote open", Allowlist{allowed}, "https://github.com/owner/repo", true},
+		{"remote under prefix", Allowlist{"https://github.com/owner/"}, "https://github.com/owner/repo", true},
+		{"remote sharing prefix text", Allowlist{"https://github.com/owner"}, "https://github.com/owner-evil/repo", false},
+		{"remote outside prefix", Allowlist{"https://github.com/owner"}, "git@evil.example.com:owner/repo.git", false},
+		{"remote entry keeps local closed", Allowlist{"https://github.com/owner"}, filepath.Join(allowed, "app"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.allow.Allows(tt.repo); got != tt.want {
+			t.Errorf("%s: Allows(%q) = %v, want %v", tt.name, tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestUnaryTokenAuth(t *testing.T) {
+	interceptor := UnaryTokenAuth("s3cret")
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	tests := []struct {
+		name   string
+		header []string
+		want   codes.Code
+	}{
+		{"valid token", []string{"authorization", "Bearer s3cret"}, codes.OK},
+		{"lowercase scheme", []string{"authorization", "bearer s3cret"}, codes.OK},
+		{"wrong token", []string{"authorization", "Bearer guess"}, codes.Unauthenticated},
+		{"basic auth", []string{"authorization", "Basic s3cret"}, codes.Unauthenticated},
+		{"no metadata", nil, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.header != nil {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tt.header...))
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		if code := status.Code(err); code != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, code)
+		}
+	}
+}
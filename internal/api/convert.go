@@ -0,0 +1,159 @@
+package api
+
+import (
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/api/thunkv1"
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// episodeProto converts an episode to its protobuf message
+func episodeProto(ep *cluster.Episode) *thunkv1.Episode {
+	msg := &thunkv1.Episode{
+		Id:         ep.ID,
+		Repository: ep.Repository,
+		Category:   string(ep.Category),
+		Stats:      statsProto(ep.Stats),
+	}
+	for i := range ep.Commits {
+		msg.Commits = append(msg.Commits, commitProto(&ep.Commits[i]))
+	}
+	for i := range ep.Artifacts {
+		msg.Artifacts = append(msg.Artifacts, artifactProto(&ep.Artifacts[i]))
+	}
+	return msg
+}
+
+// statsProto converts an episode's statistics to their protobuf message
+func statsProto(stats cluster.EpisodeStats) *thunkv1.EpisodeStats {
+	msg := &thunkv1.EpisodeStats{
+		Additions:      int32(stats.Additions),
+		Deletions:      int32(stats.Deletions),
+		FilesChanged:   int32(stats.FilesChanged),
+		TopFiles:       stats.TopFiles,
+		TopDirectories: stats.TopDirectories,
+		ReviewComments: int32(stats.ReviewComments),
+		MergeCount:     int32(stats.MergeCount),
+		Duration:       durationpb.New(stats.Duration),
+	}
+	if len(stats.Languages) > 0 {
+		msg.Languages = make(map[string]int32, len(stats.Languages))
+		for language, lines := range stats.Languages {
+			msg.Languages[language] = int32(lines)
+		}
+	}
+	return msg
+}
+
+// commitProto converts a commit, with its file changes, to its protobuf message
+func commitProto(commit *git.Commit) *thunkv1.Commit {
+	msg := &thunkv1.Commit{
+		Hash:              commit.Hash,
+		ShortHash:         commit.ShortHash,
+		Author:            authorProto(commit.Author),
+		Committer:         authorProto(commit.Committer),
+		Message:           commit.Message,
+		CommittedAt:       timestampProto(commit.CommittedAt),
+		ParentHashes:      commit.ParentHashes,
+		IsMerge:           commit.IsMerge,
+		Tags:              commit.Tags,
+		PullRequestNumber: int32(commit.PullRequestNumber),
+	}
+	for _, diff := range commit.Diffs {
+		msg.Files = append(msg.Files, &thunkv1.FileChange{
+			Path:      diff.FilePath,
+			OldPath:   diff.OldPath,
+			Status:    diff.Status,
+			Additions: int32(diff.Additions),
+			Deletions: int32(diff.Deletions),
+			Language:  diff.Language,
+			IsBinary:  diff.IsBinary,
+			Patch:     diff.Patch,
+		})
+	}
+	return msg
+}
+
+// artifactProto converts an artifact, with its discussions, to its protobuf message
+func artifactProto(artifact *cluster.Artifact) *thunkv1.Artifact {
+	msg := &thunkv1.Artifact{
+		Id:          artifact.ID,
+		Number:      int32(artifact.Number),
+		Type:        string(artifact.Type),
+		Title:       artifact.Title,
+		Description: artifact.Description,
+		State:       artifact.State,
+		Author:      authorProto(artifact.Author),
+		Assignees:   artifact.Assignees,
+		Labels:      artifact.Labels,
+		CreatedAt:   timestampProto(artifact.CreatedAt),
+		UpdatedAt:   timestampProto(artifact.UpdatedAt),
+		Url:         artifact.URL,
+	}
+	if artifact.ClosedAt != nil {
+		msg.ClosedAt = timestampProto(*artifact.ClosedAt)
+	}
+	if artifact.MergedAt != nil {
+		msg.MergedAt = timestampProto(*artifact.MergedAt)
+	}
+	for _, discussion := range artifact.Discussions {
+		msg.Discussions = append(msg.Discussions, &thunkv1.Discussion{
+			Id:          discussion.ID,
+			Type:        string(discussion.Type),
+			Author:      authorProto(discussion.Author),
+			Body:        discussion.Body,
+			CreatedAt:   timestampProto(discussion.CreatedAt),
+			ParentId:    discussion.ParentID,
+			ThreadId:    discussion.ThreadID,
+			FilePath:    discussion.FilePath,
+			LineNumber:  int32(discussion.LineNumber),
+			CommitHash:  discussion.CommitHash,
+			ReviewState: discussion.ReviewState,
+			Resolved:    discussion.Resolved,
+		})
+	}
+	return msg
+}
+
+// authorProto converts a commit or artifact author to its protobuf message
+func authorProto(author git.Author) *thunkv1.Author {
+	return &thunkv1.Author{Name: author.Name, Email: author.Email, When: timestampProto(author.When)}
+}
+
+// narrativeProto converts a narrative, with its provenance when known, to its protobuf message
+func narrativeProto(narr *narrative.Narrative) *thunkv1.Narrative {
+	msg := &thunkv1.Narrative{
+		EpisodeId:   narr.EpisodeID,
+		Text:        narr.Text,
+		GeneratedAt: timestampProto(narr.GeneratedAt),
+		Model:       narr.Model,
+		Style:       string(narr.Style),
+	}
+	if p := narr.Provenance; p != nil {
+		msg.Provenance = &thunkv1.Provenance{Prompt: string(p.Prompt), Template: p.Template, PromptHash: p.PromptHash}
+		for _, source := range p.Context {
+			msg.Provenance.Context = append(msg.Provenance.Context, &thunkv1.ContextSource{
+				EpisodeId:   source.EpisodeID,
+				ChunkId:     source.ChunkID,
+				Granularity: string(source.Granularity),
+				Repository:  source.Repository,
+				Score:       source.Score,
+				TextHash:    source.TextHash,
+				Truncated:   source.Truncated,
+			})
+		}
+	}
+	return msg
+}
+
+// timestampProto converts a time to a timestamp, leaving the zero time unset
+func timestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,248 @@
+// Package api serves the orchestrator over gRPC, for platforms that integrate with thunk through
+// the service in proto/thunk/v1/thunk.proto rather than the command line.
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/Yates-Labs/thunk/internal/api/thunkv1"
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements thunkv1.ThunkServiceServer on top of the orchestrator.
+// Each repository gets its own RAG pipeline, scoped to it in the shared vector store, and keeps the
+// episodes it was last analyzed into until it is analyzed again.
+type Server struct {
+	thunkv1.UnimplementedThunkServiceServer
+
+	config orchestrator.RAGConfig
+	allow  Allowlist
+
+	// analyze and newPipeline default to the orchestrator's; tests replace them
+	analyze     func(ctx context.Context, repo string) ([]cluster.Episode, error)
+	newPipeline func(ctx context.Context, config orchestrator.RAGConfig) (*orchestrator.RAGPipeline, error)
+
+	mu           sync.Mutex
+	repositories map[string]*repository
+}
+
+// repository is the state kept for a repository served, by the path or URL clients name it with
+type repository struct {
+	mu       sync.Mutex // Held while analyzing or creating the pipeline, so each happens once
+	episodes []cluster.Episode
+	analyzed bool
+	pipeline *orchestrator.RAGPipeline
+}
+
+// NewServer creates a server whose pipelines use config, with Repository set to each repository's key,
+// and opts; the pipelines of all repositories should share one store through
+// orchestrator.WithSharedVectorStore rather than each open the configured one
+// Clients may only name the repositories allow allows
+func NewServer(config orchestrator.RAGConfig, allow Allowlist, opts ...orchestrator.PipelineOption) *Server {
+	return &Server{
+		config: config,
+		allow:  allow,
+		analyze: func(ctx context.Context, repo string) ([]cluster.Episode, error) {
+			return orchestrator.AnalyzeRepository(ctx, repo)
+		},
+		newPipeline: func(ctx context.Context, config orchestrator.RAGConfig) (*orchestrator.RAGPipeline, error) {
+			return orchestrator.NewRAGPipeline(ctx, config, opts...)
+		},
+		repositories: make(map[string]*repository),
+	}
+}
+
+// Close closes the pipelines of every repository served
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, repo := range s.repositories {
+		if repo.pipeline != nil {
+			errs = append(errs, repo.pipeline.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AnalyzeRepository analyzes a repository into episodes, reusing the last analysis unless refreshed
+func (s *Server) AnalyzeRepository(ctx context.Context, req *thunkv1.AnalyzeRepositoryRequest) (*thunkv1.AnalyzeRepositoryResponse, error) {
+	episodes, err := s.episodes(ctx, req.GetRepository(), req.GetRefresh())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &thunkv1.AnalyzeRepositoryResponse{Episodes: make([]*thunkv1.Episode, len(episodes))}
+	for i := range episodes {
+		resp.Episodes[i] = episodeProto(&episodes[i])
+	}
+	return resp, nil
+}
+
+// IndexEpisodes indexes a repository's episodes, or those of them named, into the vector store
+func (s *Server) IndexEpisodes(ctx context.Context, req *thunkv1.IndexEpisodesRequest) (*thunkv1.IndexEpisodesResponse, error) {
+	episodes, err := s.episodes(ctx, req.GetRepository(), false)
+	if err != nil {
+		return nil, err
+	}
+	if ids := req.GetEpisodeIds(); len(ids) > 0 {
+		selected := make([]cluster.Episode, 0, len(ids))
+		for _, id := range ids {
+			ep, err := findEpisode(episodes, id)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, *ep)
+		}
+		episodes = selected
+	}
+
+	pipeline, err := s.pipeline(ctx, req.GetRepository())
+	if err != nil {
+		return nil, err
+	}
+	if err := pipeline.IndexEpisodes(ctx, episodes); err != nil {
+		return nil, status.Errorf(codes.Internal, "indexing failed: %v", err)
+	}
+	return &thunkv1.IndexEpisodesResponse{Indexed: int32(len(episodes))}, nil
+}
+
+// GenerateNarrative writes the narrative of one of a repository's episodes
+func (s *Server) GenerateNarrative(ctx context.Context, req *thunkv1.GenerateNarrativeRequest) (*thunkv1.GenerateNarrativeResponse, error) {
+	style, err := parseStyle(req.GetStyle())
+	if err != nil {
+		return nil, err
+	}
+	episodes, err := s.episodes(ctx, req.GetRepository(), false)
+	if err != nil {
+		return nil, err
+	}
+	episode, err := findEpisode(episodes, req.GetEpisodeId())
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := s.pipeline(ctx, req.GetRepository())
+	if err != nil {
+		return nil, err
+	}
+
+	narr, err := pipeline.WithStyle(style).GenerateEpisodeNarrativeRAG(ctx, episode)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "narrative generation failed: %v", err)
+	}
+	return &thunkv1.GenerateNarrativeResponse{Narrative: narrativeProto(narr)}, nil
+}
+
+// Query answers a question about a repository from its indexed episodes
+func (s *Server) Query(ctx context.Context, req *thunkv1.QueryRequest) (*thunkv1.QueryResponse, error) {
+	question := strings.TrimSpace(req.GetQuestion())
+	if question == "" {
+		return nil, status.Error(codes.InvalidArgument, "question is required")
+	}
+	style, err := parseStyle(req.GetStyle())
+	if err != nil {
+		return nil, err
+	}
+	episodes, err := s.episodes(ctx, req.GetRepository(), false)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := s.pipeline(ctx, req.GetRepository())
+	if err != nil {
+		return nil, err
+	}
+
+	narr, err := pipeline.WithStyle(style).GenerateProjectNarrativeRAG(ctx, question, episodes)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "query failed: %v", err)
+	}
+	return &thunkv1.QueryResponse{Narrative: narrativeProto(narr)}, nil
+}
+
+// repository returns the state kept for a repository, creating it on first use
+func (s *Server) repository(name string) (*repository, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "repository is required")
+	}
+	if !s.allow.Allows(name) {
+		return nil, status.Errorf(codes.PermissionDenied, "repository %q is not allowed", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, ok := s.repositories[name]
+	if !ok {
+		repo = &repository{}
+		s.repositories[name] = repo
+	}
+	return repo, nil
+}
+
+// episodes returns a repository's episodes, analyzing it if it hasn't been yet or refresh is set
+func (s *Server) episodes(ctx context.Context, name string, refresh bool) ([]cluster.Episode, error) {
+	repo, err := s.repository(name)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.analyzed && !refresh {
+		return repo.episodes, nil
+	}
+	logging.FromContext(ctx).Info("Analyzing repository", logging.RepositoryKey, name)
+	episodes, err := s.analyze(ctx, strings.TrimSpace(name))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "analysis failed: %v", err)
+	}
+	repo.episodes, repo.analyzed = episodes, true
+	return episodes, nil
+}
+
+// pipeline returns a repository's RAG pipeline, creating it on first use
+func (s *Server) pipeline(ctx context.Context, name string) (*orchestrator.RAGPipeline, error) {
+	repo, err := s.repository(name)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.pipeline == nil {
+		config := s.config
+		config.Repository = orchestrator.RepositoryKey(strings.TrimSpace(name))
+		if repo.pipeline, err = s.newPipeline(ctx, config); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to create RAG pipeline: %v", err)
+		}
+	}
+	return repo.pipeline, nil
+}
+
+// findEpisode returns the episode with the given ID
+func findEpisode(episodes []cluster.Episode, id string) (*cluster.Episode, error) {
+	for i := range episodes {
+		if episodes[i].ID == id {
+			return &episodes[i], nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "episode %q not found", id)
+}
+
+// parseStyle parses a requested narrative style, defaulting to technical
+func parseStyle(name string) (narrative.Style, error) {
+	style, err := narrative.ParseStyle(name)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return style, nil
+}
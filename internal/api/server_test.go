@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/api/thunkv1"
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testClient serves a server over an in-memory connection, with a fake embedder, a local vector
+// store and a local LLM endpoint that answers every prompt with the same text
+func testClient(t *testing.T, analyze func(ctx context.Context, repo string) ([]cluster.Episode, error)) thunkv1.ThunkServiceClient {
+	t.Helper()
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","object":"chat.completion","model":"llama3.1","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Retries were added."}}]}`))
+	}))
+	t.Cleanup(llm.Close)
+
+	config := orchestrator.DefaultRAGConfig()
+	config.EmbedderProvider = rag.EmbedderProviderFake
+	config.EmbedderDimension = 64
+	config.LocalStore = rag.DefaultLocalStoreConfig(filepath.Join(t.TempDir(), "episodes.db"))
+	config.LocalStore.Dimension = 64
+	config.LLMConfig = narrative.LLMConfig{Provider: narrative.ProviderLocal, BaseURL: llm.URL + "/v1", Model: "llama3.1", MaxTokens: 500}
+	config.SummaryModel = ""
+	server := NewServer(config, Allowlist{"/src"})
+	server.analyze = analyze
+	t.Cleanup(func() { server.Close() })
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	thunkv1.RegisterThunkServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return thunkv1.NewThunkServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	analyses := 0
+	client := testClient(t, func(ctx context.Context, repo string) ([]cluster.Episode, error) {
+		analyses++
+		return []cluster.Episode{
+			{ID: "E1", Commits: []git.Commit{{Hash: "c1", Message: "Add retries", Author: git.Author{Name: "Ann"}, CommittedAt: when,
+				Diffs: []git.Diff{{FilePath: "retry.go", Status: "added", Additions: 3}}}}},
+			{ID: "E2", Commits: []git.Commit{{Hash: "c2", Message: "Fix login", Author: git.Author{Name: "Bob"}, CommittedAt: when.Add(time.Hour)}},
+				Artifacts: []cluster.Artifact{{ID: "PR-7", Number: 7, Type: cluster.ArtifactPullRequest, Title: "Fix login",
+					Discussions: []cluster.Discussion{{ID: "d1", Type: cluster.DiscussionReview, Body: "LGTM"}}}}},
+		}, nil
+	})
+	ctx := context.Background()
+
+	analyzed, err := client.AnalyzeRepository(ctx, &thunkv1.AnalyzeRepositoryRequest{Repository: "/src/app"})
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	if len(analyzed.Episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(analyzed.Episodes))
+	}
+	commit := analyzed.Episodes[0].Commits[0]
+	if commit.Message != "Add retries" || !commit.CommittedAt.AsTime().Equal(when) || commit.Files[0].Path != "retry.go" {
+		t.Errorf("Unexpected commit %v", commit)
+	}
+	if artifact := analyzed.Episodes[1].Artifacts[0]; artifact.Type != "pull_request" || artifact.Discussions[0].Body != "LGTM" || artifact.ClosedAt != nil {
+		t.Errorf("Unexpected artifact %v", artifact)
+	}
+
+	indexed, err := client.IndexEpisodes(ctx, &thunkv1.IndexEpisodesRequest{Repository: "/src/app"})
+	if err != nil || indexed.Indexed != 2 {
+		t.Fatalf("Expected 2 episodes indexed, got %v (error %v)", indexed, err)
+	}
+	generated, err := client.GenerateNarrative(ctx, &thunkv1.GenerateNarrativeRequest{Repository: "/src/app", EpisodeId: "E1", Style: "executive"})
+	if err != nil {
+		t.Fatalf("GenerateNarrative failed: %v", err)
+	}
+	if n := generated.Narrative; n.EpisodeId != "E1" || n.Text != "Retries were added." || n.Style != "executive" || n.Provenance.GetPrompt() != "episode" {
+		t.Errorf("Unexpected narrative %v", n)
+	}
+	answered, err := client.Query(ctx, &thunkv1.QueryRequest{Repository: "/src/app", Question: "What changed?"})
+	if err != nil || !strings.Contains(answered.Narrative.GetText(), "Retries") {
+		t.Fatalf("Expected an answer, got %v (error %v)", answered, err)
+	}
+	if analyses != 1 {
+		t.Errorf("Expected the repository analyzed once, got %d analyses", analyses)
+	}
+
+	for name, call := range map[string]func() error{
+		"missing repository": func() error {
+			_, err := client.AnalyzeRepository(ctx, &thunkv1.AnalyzeRepositoryRequest{})
+			return err
+		},
+		"unknown style": func() error {
+			_, err := client.GenerateNarrative(ctx, &thunkv1.GenerateNarrativeRequest{Repository: "/src/app", EpisodeId: "E1", Style: "poetic"})
+			return err
+		},
+		"empty question": func() error {
+			_, err := client.Query(ctx, &thunkv1.QueryRequest{Repository: "/src/app"})
+			return err
+		},
+	} {
+		if code := status.Code(call()); code != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %s", name, code)
+		}
+	}
+	_, err = client.GenerateNarrative(ctx, &thunkv1.GenerateNarrativeRequest{Repository: "/src/app", EpisodeId: "E9"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown episode, got %s", code)
+	}
+	_, err = client.AnalyzeRepository(ctx, &thunkv1.AnalyzeRepositoryRequest{Repository: "/etc"})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for a path outside the allowlist, got %s", code)
+	}
+}
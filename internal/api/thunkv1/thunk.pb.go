@@ -0,0 +1,1721 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: thunk/v1/thunk.proto
+
+// Thunk's gRPC API mirrors the orchestrator: analyze a repository into episodes, index them for
+// retrieval, and generate narratives of single episodes or answers to questions about the project.
+
+package thunkv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AnalyzeRepositoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Local path or remote URL of the repository
+	Repository string `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	// Analyze the repository again even if the server already has its episodes
+	Refresh       bool `protobuf:"varint,2,opt,name=refresh,proto3" json:"refresh,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeRepositoryRequest) Reset() {
+	*x = AnalyzeRepositoryRequest{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeRepositoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRepositoryRequest) ProtoMessage() {}
+
+func (x *AnalyzeRepositoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRepositoryRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeRepositoryRequest) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AnalyzeRepositoryRequest) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *AnalyzeRepositoryRequest) GetRefresh() bool {
+	if x != nil {
+		return x.Refresh
+	}
+	return false
+}
+
+type AnalyzeRepositoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Episodes      []*Episode             `protobuf:"bytes,1,rep,name=episodes,proto3" json:"episodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeRepositoryResponse) Reset() {
+	*x = AnalyzeRepositoryResponse{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeRepositoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRepositoryResponse) ProtoMessage() {}
+
+func (x *AnalyzeRepositoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRepositoryResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeRepositoryResponse) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AnalyzeRepositoryResponse) GetEpisodes() []*Episode {
+	if x != nil {
+		return x.Episodes
+	}
+	return nil
+}
+
+type IndexEpisodesRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Repository string                 `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	// Episodes to index; empty indexes all of them
+	EpisodeIds    []string `protobuf:"bytes,2,rep,name=episode_ids,json=episodeIds,proto3" json:"episode_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexEpisodesRequest) Reset() {
+	*x = IndexEpisodesRequest{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexEpisodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexEpisodesRequest) ProtoMessage() {}
+
+func (x *IndexEpisodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexEpisodesRequest.ProtoReflect.Descriptor instead.
+func (*IndexEpisodesRequest) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IndexEpisodesRequest) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *IndexEpisodesRequest) GetEpisodeIds() []string {
+	if x != nil {
+		return x.EpisodeIds
+	}
+	return nil
+}
+
+type IndexEpisodesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Indexed       int32                  `protobuf:"varint,1,opt,name=indexed,proto3" json:"indexed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexEpisodesResponse) Reset() {
+	*x = IndexEpisodesResponse{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexEpisodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexEpisodesResponse) ProtoMessage() {}
+
+func (x *IndexEpisodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexEpisodesResponse.ProtoReflect.Descriptor instead.
+func (*IndexEpisodesResponse) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IndexEpisodesResponse) GetIndexed() int32 {
+	if x != nil {
+		return x.Indexed
+	}
+	return 0
+}
+
+type GenerateNarrativeRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Repository string                 `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	EpisodeId  string                 `protobuf:"bytes,2,opt,name=episode_id,json=episodeId,proto3" json:"episode_id,omitempty"`
+	// Audience and tone: technical (the default), executive, deep-dive, onboarding or standup
+	Style         string `protobuf:"bytes,3,opt,name=style,proto3" json:"style,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateNarrativeRequest) Reset() {
+	*x = GenerateNarrativeRequest{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateNarrativeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateNarrativeRequest) ProtoMessage() {}
+
+func (x *GenerateNarrativeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateNarrativeRequest.ProtoReflect.Descriptor instead.
+func (*GenerateNarrativeRequest) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateNarrativeRequest) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *GenerateNarrativeRequest) GetEpisodeId() string {
+	if x != nil {
+		return x.EpisodeId
+	}
+	return ""
+}
+
+func (x *GenerateNarrativeRequest) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+type GenerateNarrativeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Narrative     *Narrative             `protobuf:"bytes,1,opt,name=narrative,proto3" json:"narrative,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateNarrativeResponse) Reset() {
+	*x = GenerateNarrativeResponse{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateNarrativeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateNarrativeResponse) ProtoMessage() {}
+
+func (x *GenerateNarrativeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateNarrativeResponse.ProtoReflect.Descriptor instead.
+func (*GenerateNarrativeResponse) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GenerateNarrativeResponse) GetNarrative() *Narrative {
+	if x != nil {
+		return x.Narrative
+	}
+	return nil
+}
+
+type QueryRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Repository string                 `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	Question   string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	// Audience and tone, as for GenerateNarrativeRequest
+	Style         string `protobuf:"bytes,3,opt,name=style,proto3" json:"style,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *QueryRequest) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+type QueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Narrative     *Narrative             `protobuf:"bytes,1,opt,name=narrative,proto3" json:"narrative,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResponse) Reset() {
+	*x = QueryResponse{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResponse) ProtoMessage() {}
+
+func (x *QueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *QueryResponse) GetNarrative() *Narrative {
+	if x != nil {
+		return x.Narrative
+	}
+	return nil
+}
+
+// Episode is a coherent unit of work: commits and the issues, pull requests and tickets around them.
+type Episode struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// owner/name, set when episodes span several repositories
+	Repository string `protobuf:"bytes,2,opt,name=repository,proto3" json:"repository,omitempty"`
+	// Kind of work, such as feature or bugfix; empty when unclassified
+	Category      string        `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	Commits       []*Commit     `protobuf:"bytes,4,rep,name=commits,proto3" json:"commits,omitempty"`
+	Artifacts     []*Artifact   `protobuf:"bytes,5,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	Stats         *EpisodeStats `protobuf:"bytes,6,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Episode) Reset() {
+	*x = Episode{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Episode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Episode) ProtoMessage() {}
+
+func (x *Episode) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Episode.ProtoReflect.Descriptor instead.
+func (*Episode) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Episode) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Episode) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *Episode) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Episode) GetCommits() []*Commit {
+	if x != nil {
+		return x.Commits
+	}
+	return nil
+}
+
+func (x *Episode) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *Episode) GetStats() *EpisodeStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type EpisodeStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Additions      int32                  `protobuf:"varint,1,opt,name=additions,proto3" json:"additions,omitempty"`
+	Deletions      int32                  `protobuf:"varint,2,opt,name=deletions,proto3" json:"deletions,omitempty"`
+	FilesChanged   int32                  `protobuf:"varint,3,opt,name=files_changed,json=filesChanged,proto3" json:"files_changed,omitempty"`
+	TopFiles       []string               `protobuf:"bytes,4,rep,name=top_files,json=topFiles,proto3" json:"top_files,omitempty"`
+	TopDirectories []string               `protobuf:"bytes,5,rep,name=top_directories,json=topDirectories,proto3" json:"top_directories,omitempty"`
+	Languages      map[string]int32       `protobuf:"bytes,6,rep,name=languages,proto3" json:"languages,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	ReviewComments int32                  `protobuf:"varint,7,opt,name=review_comments,json=reviewComments,proto3" json:"review_comments,omitempty"`
+	MergeCount     int32                  `protobuf:"varint,8,opt,name=merge_count,json=mergeCount,proto3" json:"merge_count,omitempty"`
+	Duration       *durationpb.Duration   `protobuf:"bytes,9,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EpisodeStats) Reset() {
+	*x = EpisodeStats{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EpisodeStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EpisodeStats) ProtoMessage() {}
+
+func (x *EpisodeStats) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EpisodeStats.ProtoReflect.Descriptor instead.
+func (*EpisodeStats) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EpisodeStats) GetAdditions() int32 {
+	if x != nil {
+		return x.Additions
+	}
+	return 0
+}
+
+func (x *EpisodeStats) GetDeletions() int32 {
+	if x != nil {
+		return x.Deletions
+	}
+	return 0
+}
+
+func (x *EpisodeStats) GetFilesChanged() int32 {
+	if x != nil {
+		return x.FilesChanged
+	}
+	return 0
+}
+
+func (x *EpisodeStats) GetTopFiles() []string {
+	if x != nil {
+		return x.TopFiles
+	}
+	return nil
+}
+
+func (x *EpisodeStats) GetTopDirectories() []string {
+	if x != nil {
+		return x.TopDirectories
+	}
+	return nil
+}
+
+func (x *EpisodeStats) GetLanguages() map[string]int32 {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *EpisodeStats) GetReviewComments() int32 {
+	if x != nil {
+		return x.ReviewComments
+	}
+	return 0
+}
+
+func (x *EpisodeStats) GetMergeCount() int32 {
+	if x != nil {
+		return x.MergeCount
+	}
+	return 0
+}
+
+func (x *EpisodeStats) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type Author struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	When          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=when,proto3" json:"when,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Author) Reset() {
+	*x = Author{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Author) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Author) ProtoMessage() {}
+
+func (x *Author) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Author.ProtoReflect.Descriptor instead.
+func (*Author) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Author) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Author) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Author) GetWhen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.When
+	}
+	return nil
+}
+
+type Commit struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Hash         string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	ShortHash    string                 `protobuf:"bytes,2,opt,name=short_hash,json=shortHash,proto3" json:"short_hash,omitempty"`
+	Author       *Author                `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Committer    *Author                `protobuf:"bytes,4,opt,name=committer,proto3" json:"committer,omitempty"`
+	Message      string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	CommittedAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	ParentHashes []string               `protobuf:"bytes,7,rep,name=parent_hashes,json=parentHashes,proto3" json:"parent_hashes,omitempty"`
+	IsMerge      bool                   `protobuf:"varint,8,opt,name=is_merge,json=isMerge,proto3" json:"is_merge,omitempty"`
+	Tags         []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Pull or merge request merged by the commit; 0 when none was detected
+	PullRequestNumber int32         `protobuf:"varint,10,opt,name=pull_request_number,json=pullRequestNumber,proto3" json:"pull_request_number,omitempty"`
+	Files             []*FileChange `protobuf:"bytes,11,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Commit) Reset() {
+	*x = Commit{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Commit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Commit) ProtoMessage() {}
+
+func (x *Commit) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Commit.ProtoReflect.Descriptor instead.
+func (*Commit) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Commit) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Commit) GetShortHash() string {
+	if x != nil {
+		return x.ShortHash
+	}
+	return ""
+}
+
+func (x *Commit) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Commit) GetCommitter() *Author {
+	if x != nil {
+		return x.Committer
+	}
+	return nil
+}
+
+func (x *Commit) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Commit) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *Commit) GetParentHashes() []string {
+	if x != nil {
+		return x.ParentHashes
+	}
+	return nil
+}
+
+func (x *Commit) GetIsMerge() bool {
+	if x != nil {
+		return x.IsMerge
+	}
+	return false
+}
+
+func (x *Commit) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Commit) GetPullRequestNumber() int32 {
+	if x != nil {
+		return x.PullRequestNumber
+	}
+	return 0
+}
+
+func (x *Commit) GetFiles() []*FileChange {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Path before a rename
+	OldPath string `protobuf:"bytes,2,opt,name=old_path,json=oldPath,proto3" json:"old_path,omitempty"`
+	// added, modified, deleted or renamed
+	Status    string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Additions int32  `protobuf:"varint,4,opt,name=additions,proto3" json:"additions,omitempty"`
+	Deletions int32  `protobuf:"varint,5,opt,name=deletions,proto3" json:"deletions,omitempty"`
+	Language  string `protobuf:"bytes,6,opt,name=language,proto3" json:"language,omitempty"`
+	IsBinary  bool   `protobuf:"varint,7,opt,name=is_binary,json=isBinary,proto3" json:"is_binary,omitempty"`
+	// Unified diff hunks, when the repository was parsed with patches
+	Patch         string `protobuf:"bytes,8,opt,name=patch,proto3" json:"patch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChange) Reset() {
+	*x = FileChange{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChange) ProtoMessage() {}
+
+func (x *FileChange) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChange.ProtoReflect.Descriptor instead.
+func (*FileChange) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FileChange) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileChange) GetOldPath() string {
+	if x != nil {
+		return x.OldPath
+	}
+	return ""
+}
+
+func (x *FileChange) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *FileChange) GetAdditions() int32 {
+	if x != nil {
+		return x.Additions
+	}
+	return 0
+}
+
+func (x *FileChange) GetDeletions() int32 {
+	if x != nil {
+		return x.Deletions
+	}
+	return 0
+}
+
+func (x *FileChange) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *FileChange) GetIsBinary() bool {
+	if x != nil {
+		return x.IsBinary
+	}
+	return false
+}
+
+func (x *FileChange) GetPatch() string {
+	if x != nil {
+		return x.Patch
+	}
+	return ""
+}
+
+// Artifact is an issue, pull or merge request, ticket or release.
+type Artifact struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	// issue, pull_request, merge_request, ticket or release
+	Type        string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Title       string `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Description string `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	// open, closed or merged
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	Author        *Author                `protobuf:"bytes,7,opt,name=author,proto3" json:"author,omitempty"`
+	Assignees     []string               `protobuf:"bytes,8,rep,name=assignees,proto3" json:"assignees,omitempty"`
+	Labels        []string               `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ClosedAt      *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	MergedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=merged_at,json=mergedAt,proto3" json:"merged_at,omitempty"`
+	Url           string                 `protobuf:"bytes,14,opt,name=url,proto3" json:"url,omitempty"`
+	Discussions   []*Discussion          `protobuf:"bytes,15,rep,name=discussions,proto3" json:"discussions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Artifact) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Artifact) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Artifact) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Artifact) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Artifact) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Artifact) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Artifact) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Artifact) GetAssignees() []string {
+	if x != nil {
+		return x.Assignees
+	}
+	return nil
+}
+
+func (x *Artifact) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Artifact) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Artifact) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Artifact) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+func (x *Artifact) GetMergedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MergedAt
+	}
+	return nil
+}
+
+func (x *Artifact) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Artifact) GetDiscussions() []*Discussion {
+	if x != nil {
+		return x.Discussions
+	}
+	return nil
+}
+
+// Discussion is a comment, review, review thread comment or process event on an artifact.
+type Discussion struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// comment, review, review_thread, note or event
+	Type       string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Author     *Author                `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Body       string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ParentId   string                 `protobuf:"bytes,6,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	ThreadId   string                 `protobuf:"bytes,7,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	FilePath   string                 `protobuf:"bytes,8,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	LineNumber int32                  `protobuf:"varint,9,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	CommitHash string                 `protobuf:"bytes,10,opt,name=commit_hash,json=commitHash,proto3" json:"commit_hash,omitempty"`
+	// approved, changes_requested or commented
+	ReviewState   string `protobuf:"bytes,11,opt,name=review_state,json=reviewState,proto3" json:"review_state,omitempty"`
+	Resolved      bool   `protobuf:"varint,12,opt,name=resolved,proto3" json:"resolved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Discussion) Reset() {
+	*x = Discussion{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Discussion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Discussion) ProtoMessage() {}
+
+func (x *Discussion) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Discussion.ProtoReflect.Descriptor instead.
+func (*Discussion) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Discussion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Discussion) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Discussion) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Discussion) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Discussion) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Discussion) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Discussion) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *Discussion) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *Discussion) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *Discussion) GetCommitHash() string {
+	if x != nil {
+		return x.CommitHash
+	}
+	return ""
+}
+
+func (x *Discussion) GetReviewState() string {
+	if x != nil {
+		return x.ReviewState
+	}
+	return ""
+}
+
+func (x *Discussion) GetResolved() bool {
+	if x != nil {
+		return x.Resolved
+	}
+	return false
+}
+
+type Narrative struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Episode the narrative describes, or "project" for answers to questions
+	EpisodeId   string                 `protobuf:"bytes,1,opt,name=episode_id,json=episodeId,proto3" json:"episode_id,omitempty"`
+	Text        string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	GeneratedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	Model       string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`
+	Style       string                 `protobuf:"bytes,5,opt,name=style,proto3" json:"style,omitempty"`
+	// Prompt and retrieved context the narrative was written from, when known
+	Provenance    *Provenance `protobuf:"bytes,6,opt,name=provenance,proto3" json:"provenance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Narrative) Reset() {
+	*x = Narrative{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Narrative) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Narrative) ProtoMessage() {}
+
+func (x *Narrative) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Narrative.ProtoReflect.Descriptor instead.
+func (*Narrative) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Narrative) GetEpisodeId() string {
+	if x != nil {
+		return x.EpisodeId
+	}
+	return ""
+}
+
+func (x *Narrative) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Narrative) GetGeneratedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return nil
+}
+
+func (x *Narrative) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Narrative) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+func (x *Narrative) GetProvenance() *Provenance {
+	if x != nil {
+		return x.Provenance
+	}
+	return nil
+}
+
+type Provenance struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Type of the prompt the narrative was written from
+	Prompt string `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	// Version of the prompt's template
+	Template string `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	// SHA-256 of the prompt sent to the model
+	PromptHash string `protobuf:"bytes,3,opt,name=prompt_hash,json=promptHash,proto3" json:"prompt_hash,omitempty"`
+	// Context chunks in the prompt, in prompt order
+	Context       []*ContextSource `protobuf:"bytes,4,rep,name=context,proto3" json:"context,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Provenance) Reset() {
+	*x = Provenance{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Provenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Provenance) ProtoMessage() {}
+
+func (x *Provenance) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Provenance.ProtoReflect.Descriptor instead.
+func (*Provenance) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Provenance) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *Provenance) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *Provenance) GetPromptHash() string {
+	if x != nil {
+		return x.PromptHash
+	}
+	return ""
+}
+
+func (x *Provenance) GetContext() []*ContextSource {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+type ContextSource struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EpisodeId string                 `protobuf:"bytes,1,opt,name=episode_id,json=episodeId,proto3" json:"episode_id,omitempty"`
+	ChunkId   string                 `protobuf:"bytes,2,opt,name=chunk_id,json=chunkId,proto3" json:"chunk_id,omitempty"`
+	// episode, chunk or commit
+	Granularity string  `protobuf:"bytes,3,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	Repository  string  `protobuf:"bytes,4,opt,name=repository,proto3" json:"repository,omitempty"`
+	Score       float32 `protobuf:"fixed32,5,opt,name=score,proto3" json:"score,omitempty"`
+	// SHA-256 of the chunk's text as it appeared in the prompt
+	TextHash string `protobuf:"bytes,6,opt,name=text_hash,json=textHash,proto3" json:"text_hash,omitempty"`
+	// The prompt budget cut the text short
+	Truncated     bool `protobuf:"varint,7,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContextSource) Reset() {
+	*x = ContextSource{}
+	mi := &file_thunk_v1_thunk_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextSource) ProtoMessage() {}
+
+func (x *ContextSource) ProtoReflect() protoreflect.Message {
+	mi := &file_thunk_v1_thunk_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextSource.ProtoReflect.Descriptor instead.
+func (*ContextSource) Descriptor() ([]byte, []int) {
+	return file_thunk_v1_thunk_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ContextSource) GetEpisodeId() string {
+	if x != nil {
+		return x.EpisodeId
+	}
+	return ""
+}
+
+func (x *ContextSource) GetChunkId() string {
+	if x != nil {
+		return x.ChunkId
+	}
+	return ""
+}
+
+func (x *ContextSource) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *ContextSource) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *ContextSource) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ContextSource) GetTextHash() string {
+	if x != nil {
+		return x.TextHash
+	}
+	return ""
+}
+
+func (x *ContextSource) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+var File_thunk_v1_thunk_proto protoreflect.FileDescriptor
+
+const file_thunk_v1_thunk_proto_rawDesc = "" +
+	"\n" +
+	"\x14thunk/v1/thunk.proto\x12\bthunk.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"T\n" +
+	"\x18AnalyzeRepositoryRequest\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x01 \x01(\tR\n" +
+	"repository\x12\x18\n" +
+	"\arefresh\x18\x02 \x01(\bR\arefresh\"J\n" +
+	"\x19AnalyzeRepositoryResponse\x12-\n" +
+	"\bepisodes\x18\x01 \x03(\v2\x11.thunk.v1.EpisodeR\bepisodes\"W\n" +
+	"\x14IndexEpisodesRequest\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x01 \x01(\tR\n" +
+	"repository\x12\x1f\n" +
+	"\vepisode_ids\x18\x02 \x03(\tR\n" +
+	"episodeIds\"1\n" +
+	"\x15IndexEpisodesResponse\x12\x18\n" +
+	"\aindexed\x18\x01 \x01(\x05R\aindexed\"o\n" +
+	"\x18GenerateNarrativeRequest\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x01 \x01(\tR\n" +
+	"repository\x12\x1d\n" +
+	"\n" +
+	"episode_id\x18\x02 \x01(\tR\tepisodeId\x12\x14\n" +
+	"\x05style\x18\x03 \x01(\tR\x05style\"N\n" +
+	"\x19GenerateNarrativeResponse\x121\n" +
+	"\tnarrative\x18\x01 \x01(\v2\x13.thunk.v1.NarrativeR\tnarrative\"`\n" +
+	"\fQueryRequest\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x01 \x01(\tR\n" +
+	"repository\x12\x1a\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x14\n" +
+	"\x05style\x18\x03 \x01(\tR\x05style\"B\n" +
+	"\rQueryResponse\x121\n" +
+	"\tnarrative\x18\x01 \x01(\v2\x13.thunk.v1.NarrativeR\tnarrative\"\xe1\x01\n" +
+	"\aEpisode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x02 \x01(\tR\n" +
+	"repository\x12\x1a\n" +
+	"\bcategory\x18\x03 \x01(\tR\bcategory\x12*\n" +
+	"\acommits\x18\x04 \x03(\v2\x10.thunk.v1.CommitR\acommits\x120\n" +
+	"\tartifacts\x18\x05 \x03(\v2\x12.thunk.v1.ArtifactR\tartifacts\x12,\n" +
+	"\x05stats\x18\x06 \x01(\v2\x16.thunk.v1.EpisodeStatsR\x05stats\"\xb9\x03\n" +
+	"\fEpisodeStats\x12\x1c\n" +
+	"\tadditions\x18\x01 \x01(\x05R\tadditions\x12\x1c\n" +
+	"\tdeletions\x18\x02 \x01(\x05R\tdeletions\x12#\n" +
+	"\rfiles_changed\x18\x03 \x01(\x05R\ffilesChanged\x12\x1b\n" +
+	"\ttop_files\x18\x04 \x03(\tR\btopFiles\x12'\n" +
+	"\x0ftop_directories\x18\x05 \x03(\tR\x0etopDirectories\x12C\n" +
+	"\tlanguages\x18\x06 \x03(\v2%.thunk.v1.EpisodeStats.LanguagesEntryR\tlanguages\x12'\n" +
+	"\x0freview_comments\x18\a \x01(\x05R\x0ereviewComments\x12\x1f\n" +
+	"\vmerge_count\x18\b \x01(\x05R\n" +
+	"mergeCount\x125\n" +
+	"\bduration\x18\t \x01(\v2\x19.google.protobuf.DurationR\bduration\x1a<\n" +
+	"\x0eLanguagesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"b\n" +
+	"\x06Author\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12.\n" +
+	"\x04when\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04when\"\x9e\x03\n" +
+	"\x06Commit\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x1d\n" +
+	"\n" +
+	"short_hash\x18\x02 \x01(\tR\tshortHash\x12(\n" +
+	"\x06author\x18\x03 \x01(\v2\x10.thunk.v1.AuthorR\x06author\x12.\n" +
+	"\tcommitter\x18\x04 \x01(\v2\x10.thunk.v1.AuthorR\tcommitter\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12=\n" +
+	"\fcommitted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x12#\n" +
+	"\rparent_hashes\x18\a \x03(\tR\fparentHashes\x12\x19\n" +
+	"\bis_merge\x18\b \x01(\bR\aisMerge\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12.\n" +
+	"\x13pull_request_number\x18\n" +
+	" \x01(\x05R\x11pullRequestNumber\x12*\n" +
+	"\x05files\x18\v \x03(\v2\x14.thunk.v1.FileChangeR\x05files\"\xde\x01\n" +
+	"\n" +
+	"FileChange\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x19\n" +
+	"\bold_path\x18\x02 \x01(\tR\aoldPath\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1c\n" +
+	"\tadditions\x18\x04 \x01(\x05R\tadditions\x12\x1c\n" +
+	"\tdeletions\x18\x05 \x01(\x05R\tdeletions\x12\x1a\n" +
+	"\blanguage\x18\x06 \x01(\tR\blanguage\x12\x1b\n" +
+	"\tis_binary\x18\a \x01(\bR\bisBinary\x12\x14\n" +
+	"\x05patch\x18\b \x01(\tR\x05patch\"\xa6\x04\n" +
+	"\bArtifact\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12(\n" +
+	"\x06author\x18\a \x01(\v2\x10.thunk.v1.AuthorR\x06author\x12\x1c\n" +
+	"\tassignees\x18\b \x03(\tR\tassignees\x12\x16\n" +
+	"\x06labels\x18\t \x03(\tR\x06labels\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x127\n" +
+	"\tclosed_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\x127\n" +
+	"\tmerged_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\bmergedAt\x12\x10\n" +
+	"\x03url\x18\x0e \x01(\tR\x03url\x126\n" +
+	"\vdiscussions\x18\x0f \x03(\v2\x14.thunk.v1.DiscussionR\vdiscussions\"\x81\x03\n" +
+	"\n" +
+	"Discussion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12(\n" +
+	"\x06author\x18\x03 \x01(\v2\x10.thunk.v1.AuthorR\x06author\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1b\n" +
+	"\tparent_id\x18\x06 \x01(\tR\bparentId\x12\x1b\n" +
+	"\tthread_id\x18\a \x01(\tR\bthreadId\x12\x1b\n" +
+	"\tfile_path\x18\b \x01(\tR\bfilePath\x12\x1f\n" +
+	"\vline_number\x18\t \x01(\x05R\n" +
+	"lineNumber\x12\x1f\n" +
+	"\vcommit_hash\x18\n" +
+	" \x01(\tR\n" +
+	"commitHash\x12!\n" +
+	"\freview_state\x18\v \x01(\tR\vreviewState\x12\x1a\n" +
+	"\bresolved\x18\f \x01(\bR\bresolved\"\xdf\x01\n" +
+	"\tNarrative\x12\x1d\n" +
+	"\n" +
+	"episode_id\x18\x01 \x01(\tR\tepisodeId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12=\n" +
+	"\fgenerated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vgeneratedAt\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x14\n" +
+	"\x05style\x18\x05 \x01(\tR\x05style\x124\n" +
+	"\n" +
+	"provenance\x18\x06 \x01(\v2\x14.thunk.v1.ProvenanceR\n" +
+	"provenance\"\x94\x01\n" +
+	"\n" +
+	"Provenance\x12\x16\n" +
+	"\x06prompt\x18\x01 \x01(\tR\x06prompt\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\x12\x1f\n" +
+	"\vprompt_hash\x18\x03 \x01(\tR\n" +
+	"promptHash\x121\n" +
+	"\acontext\x18\x04 \x03(\v2\x17.thunk.v1.ContextSourceR\acontext\"\xdc\x01\n" +
+	"\rContextSource\x12\x1d\n" +
+	"\n" +
+	"episode_id\x18\x01 \x01(\tR\tepisodeId\x12\x19\n" +
+	"\bchunk_id\x18\x02 \x01(\tR\achunkId\x12 \n" +
+	"\vgranularity\x18\x03 \x01(\tR\vgranularity\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x04 \x01(\tR\n" +
+	"repository\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x02R\x05score\x12\x1b\n" +
+	"\ttext_hash\x18\x06 \x01(\tR\btextHash\x12\x1c\n" +
+	"\ttruncated\x18\a \x01(\bR\ttruncated2\xd6\x02\n" +
+	"\fThunkService\x12\\\n" +
+	"\x11AnalyzeRepository\x12\".thunk.v1.AnalyzeRepositoryRequest\x1a#.thunk.v1.AnalyzeRepositoryResponse\x12P\n" +
+	"\rIndexEpisodes\x12\x1e.thunk.v1.IndexEpisodesRequest\x1a\x1f.thunk.v1.IndexEpisodesResponse\x12\\\n" +
+	"\x11GenerateNarrative\x12\".thunk.v1.GenerateNarrativeRequest\x1a#.thunk.v1.GenerateNarrativeResponse\x128\n" +
+	"\x05Query\x12\x16.thunk.v1.QueryRequest\x1a\x17.thunk.v1.QueryResponseB:Z8github.com/Yates-Labs/thunk/internal/api/thunkv1;thunkv1b\x06proto3"
+
+var (
+	file_thunk_v1_thunk_proto_rawDescOnce sync.Once
+	file_thunk_v1_thunk_proto_rawDescData []byte
+)
+
+func file_thunk_v1_thunk_proto_rawDescGZIP() []byte {
+	file_thunk_v1_thunk_proto_rawDescOnce.Do(func() {
+		file_thunk_v1_thunk_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_thunk_v1_thunk_proto_rawDesc), len(file_thunk_v1_thunk_proto_rawDesc)))
+	})
+	return file_thunk_v1_thunk_proto_rawDescData
+}
+
+var file_thunk_v1_thunk_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_thunk_v1_thunk_proto_goTypes = []any{
+	(*AnalyzeRepositoryRequest)(nil),  // 0: thunk.v1.AnalyzeRepositoryRequest
+	(*AnalyzeRepositoryResponse)(nil), // 1: thunk.v1.AnalyzeRepositoryResponse
+	(*IndexEpisodesRequest)(nil),      // 2: thunk.v1.IndexEpisodesRequest
+	(*IndexEpisodesResponse)(nil),     // 3: thunk.v1.IndexEpisodesResponse
+	(*GenerateNarrativeRequest)(nil),  // 4: thunk.v1.GenerateNarrativeRequest
+	(*GenerateNarrativeResponse)(nil), // 5: thunk.v1.GenerateNarrativeResponse
+	(*QueryRequest)(nil),              // 6: thunk.v1.QueryRequest
+	(*QueryResponse)(nil),             // 7: thunk.v1.QueryResponse
+	(*Episode)(nil),                   // 8: thunk.v1.Episode
+	(*EpisodeStats)(nil),              // 9: thunk.v1.EpisodeStats
+	(*Author)(nil),                    // 10: thunk.v1.Author
+	(*Commit)(nil),                    // 11: thunk.v1.Commit
+	(*FileChange)(nil),                // 12: thunk.v1.FileChange
+	(*Artifact)(nil),                  // 13: thunk.v1.Artifact
+	(*Discussion)(nil),                // 14: thunk.v1.Discussion
+	(*Narrative)(nil),                 // 15: thunk.v1.Narrative
+	(*Provenance)(nil),                // 16: thunk.v1.Provenance
+	(*ContextSource)(nil),             // 17: thunk.v1.ContextSource
+	nil,                               // 18: thunk.v1.EpisodeStats.LanguagesEntry
+	(*durationpb.Duration)(nil),       // 19: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),     // 20: google.protobuf.Timestamp
+}
+var file_thunk_v1_thunk_proto_depIdxs = []int32{
+	8,  // 0: thunk.v1.AnalyzeRepositoryResponse.episodes:type_name -> thunk.v1.Episode
+	15, // 1: thunk.v1.GenerateNarrativeResponse.narrative:type_name -> thunk.v1.Narrative
+	15, // 2: thunk.v1.QueryResponse.narrative:type_name -> thunk.v1.Narrative
+	11, // 3: thunk.v1.Episode.commits:type_name -> thunk.v1.Commit
+	13, // 4: thunk.v1.Episode.artifacts:type_name -> thunk.v1.Artifact
+	9,  // 5: thunk.v1.Episode.stats:type_name -> thunk.v1.EpisodeStats
+	18, // 6: thunk.v1.EpisodeStats.languages:type_name -> thunk.v1.EpisodeStats.LanguagesEntry
+	19, // 7: thunk.v1.EpisodeStats.duration:type_name -> google.protobuf.Duration
+	20, // 8: thunk.v1.Author.when:type_name -> google.protobuf.Timestamp
+	10, // 9: thunk.v1.Commit.author:type_name -> thunk.v1.Author
+	10, // 10: thunk.v1.Commit.committer:type_name -> thunk.v1.Author
+	20, // 11: thunk.v1.Commit.committed_at:type_name -> google.protobuf.Timestamp
+	12, // 12: thunk.v1.Commit.files:type_name -> thunk.v1.FileChange
+	10, // 13: thunk.v1.Artifact.author:type_name -> thunk.v1.Author
+	20, // 14: thunk.v1.Artifact.created_at:type_name -> google.protobuf.Timestamp
+	20, // 15: thunk.v1.Artifact.updated_at:type_name -> google.protobuf.Timestamp
+	20, // 16: thunk.v1.Artifact.closed_at:type_name -> google.protobuf.Timestamp
+	20, // 17: thunk.v1.Artifact.merged_at:type_name -> google.protobuf.Timestamp
+	14, // 18: thunk.v1.Artifact.discussions:type_name -> thunk.v1.Discussion
+	10, // 19: thunk.v1.Discussion.author:type_name -> thunk.v1.Author
+	20, // 20: thunk.v1.Discussion.created_at:type_name -> google.protobuf.Timestamp
+	20, // 21: thunk.v1.Narrative.generated_at:type_name -> google.protobuf.Timestamp
+	16, // 22: thunk.v1.Narrative.provenance:type_name -> thunk.v1.Provenance
+	17, // 23: thunk.v1.Provenance.context:type_name -> thunk.v1.ContextSource
+	0,  // 24: thunk.v1.ThunkService.AnalyzeRepository:input_type -> thunk.v1.AnalyzeRepositoryRequest
+	2,  // 25: thunk.v1.ThunkService.IndexEpisodes:input_type -> thunk.v1.IndexEpisodesRequest
+	4,  // 26: thunk.v1.ThunkService.GenerateNarrative:input_type -> thunk.v1.GenerateNarrativeRequest
+	6,  // 27: thunk.v1.ThunkService.Query:input_type -> thunk.v1.QueryRequest
+	1,  // 28: thunk.v1.ThunkService.AnalyzeRepository:output_type -> thunk.v1.AnalyzeRepositoryResponse
+	3,  // 29: thunk.v1.ThunkService.IndexEpisodes:output_type -> thunk.v1.IndexEpisodesResponse
+	5,  // 30: thunk.v1.ThunkService.GenerateNarrative:output_type -> thunk.v1.GenerateNarrativeResponse
+	7,  // 31: thunk.v1.ThunkService.Query:output_type -> thunk.v1.QueryResponse
+	28, // [28:32] is the sub-list for method output_type
+	24, // [24:28] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_thunk_v1_thunk_proto_init() }
+func file_thunk_v1_thunk_proto_init() {
+	if File_thunk_v1_thunk_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_thunk_v1_thunk_proto_rawDesc), len(file_thunk_v1_thunk_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_thunk_v1_thunk_proto_goTypes,
+		DependencyIndexes: file_thunk_v1_thunk_proto_depIdxs,
+		MessageInfos:      file_thunk_v1_thunk_proto_msgTypes,
+	}.Build()
+	File_thunk_v1_thunk_proto = out.File
+	file_thunk_v1_thunk_proto_goTypes = nil
+	file_thunk_v1_thunk_proto_depIdxs = nil
+}
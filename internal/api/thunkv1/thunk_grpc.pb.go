@@ -0,0 +1,223 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: thunk/v1/thunk.proto
+
+package thunkv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ThunkServiceClient is the client API for ThunkService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ThunkServiceClient interface {
+	// AnalyzeRepository parses a repository's history and artifacts and groups them into episodes.
+	// The server keeps the episodes for the other calls on the same repository.
+	AnalyzeRepository(ctx context.Context, in *AnalyzeRepositoryRequest, opts ...grpc.CallOption) (*AnalyzeRepositoryResponse, error)
+	// IndexEpisodes embeds a repository's episodes into the vector store, analyzing it first if needed.
+	IndexEpisodes(ctx context.Context, in *IndexEpisodesRequest, opts ...grpc.CallOption) (*IndexEpisodesResponse, error)
+	// GenerateNarrative writes the narrative of one episode, with related episodes as context.
+	GenerateNarrative(ctx context.Context, in *GenerateNarrativeRequest, opts ...grpc.CallOption) (*GenerateNarrativeResponse, error)
+	// Query answers a question about a repository from the episodes most relevant to it.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+}
+
+type thunkServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThunkServiceClient(cc grpc.ClientConnInterface) ThunkServiceClient {
+	return &thunkServiceClient{cc}
+}
+
+func (c *thunkServiceClient) AnalyzeRepository(ctx context.Context, in *AnalyzeRepositoryRequest, opts ...grpc.CallOption) (*AnalyzeRepositoryResponse, error) {
+	out := new(AnalyzeRepositoryResponse)
+	err := c.cc.Invoke(ctx, "/thunk.v1.ThunkService/AnalyzeRepository", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thunkServiceClient) IndexEpisodes(ctx context.Context, in *IndexEpisodesRequest, opts ...grpc.CallOption) (*IndexEpisodesResponse, error) {
+	out := new(IndexEpisodesResponse)
+	err := c.cc.Invoke(ctx, "/thunk.v1.ThunkService/IndexEpisodes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thunkServiceClient) GenerateNarrative(ctx context.Context, in *GenerateNarrativeRequest, opts ...grpc.CallOption) (*GenerateNarrativeResponse, error) {
+	out := new(GenerateNarrativeResponse)
+	err := c.cc.Invoke(ctx, "/thunk.v1.ThunkService/GenerateNarrative", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thunkServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, "/thunk.v1.ThunkService/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ThunkServiceServer is the server API for ThunkService service.
+// All implementations must embed UnimplementedThunkServiceServer
+// for forward compatibility
+type ThunkServiceServer interface {
+	// AnalyzeRepository parses a repository's history and artifacts and groups them into episodes.
+	// The server keeps the episodes for the other calls on the same repository.
+	AnalyzeRepository(context.Context, *AnalyzeRepositoryRequest) (*AnalyzeRepositoryResponse, error)
+	// IndexEpisodes embeds a repository's episodes into the vector store, analyzing it first if needed.
+	IndexEpisodes(context.Context, *IndexEpisodesRequest) (*IndexEpisodesResponse, error)
+	// GenerateNarrative writes the narrative of one episode, with related episodes as context.
+	GenerateNarrative(context.Context, *GenerateNarrativeRequest) (*GenerateNarrativeResponse, error)
+	// Query answers a question about a repository from the episodes most relevant to it.
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	mustEmbedUnimplementedThunkServiceServer()
+}
+
+// UnimplementedThunkServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedThunkServiceServer struct {
+}
+
+func (UnimplementedThunkServiceServer) AnalyzeRepository(context.Context, *AnalyzeRepositoryRequest) (*AnalyzeRepositoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeRepository not implemented")
+}
+func (UnimplementedThunkServiceServer) IndexEpisodes(context.Context, *IndexEpisodesRequest) (*IndexEpisodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IndexEpisodes not implemented")
+}
+func (UnimplementedThunkServiceServer) GenerateNarrative(context.Context, *GenerateNarrativeRequest) (*GenerateNarrativeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateNarrative not implemented")
+}
+func (UnimplementedThunkServiceServer) Query(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedThunkServiceServer) mustEmbedUnimplementedThunkServiceServer() {}
+
+// UnsafeThunkServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ThunkServiceServer will
+// result in compilation errors.
+type UnsafeThunkServiceServer interface {
+	mustEmbedUnimplementedThunkServiceServer()
+}
+
+func RegisterThunkServiceServer(s grpc.ServiceRegistrar, srv ThunkServiceServer) {
+	s.RegisterService(&ThunkService_ServiceDesc, srv)
+}
+
+func _ThunkService_AnalyzeRepository_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeRepositoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThunkServiceServer).AnalyzeRepository(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/thunk.v1.ThunkService/AnalyzeRepository",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThunkServiceServer).AnalyzeRepository(ctx, req.(*AnalyzeRepositoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThunkService_IndexEpisodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexEpisodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThunkServiceServer).IndexEpisodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/thunk.v1.ThunkService/IndexEpisodes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThunkServiceServer).IndexEpisodes(ctx, req.(*IndexEpisodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThunkService_GenerateNarrative_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateNarrativeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThunkServiceServer).GenerateNarrative(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/thunk.v1.ThunkService/GenerateNarrative",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThunkServiceServer).GenerateNarrative(ctx, req.(*GenerateNarrativeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThunkService_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThunkServiceServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/thunk.v1.ThunkService/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThunkServiceServer).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ThunkService_ServiceDesc is the grpc.ServiceDesc for ThunkService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ThunkService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "thunk.v1.ThunkService",
+	HandlerType: (*ThunkServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnalyzeRepository",
+			Handler:    _ThunkService_AnalyzeRepository_Handler,
+		},
+		{
+			MethodName: "IndexEpisodes",
+			Handler:    _ThunkService_IndexEpisodes_Handler,
+		},
+		{
+			MethodName: "GenerateNarrative",
+			Handler:    _ThunkService_GenerateNarrative_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _ThunkService_Query_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "thunk/v1/thunk.proto",
+}
@@ -0,0 +1,120 @@
+// Package azureopenai points OpenAI clients at Azure OpenAI deployments.
+// Azure serves the OpenAI API under a per-deployment path with an api-version query parameter,
+// and authenticates with an api-key header or a Microsoft Entra ID (AAD) bearer token, so
+// the same openai-go client code drives both providers once configured with RequestOptions.
+package azureopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/option"
+)
+
+// DefaultAPIVersion is the Azure OpenAI REST API version used when none is configured
+const DefaultAPIVersion = "2024-10-21"
+
+// Environment variables read by FromEnv
+const (
+	EnvEndpoint   = "AZURE_OPENAI_ENDPOINT"    // https://<resource>.openai.azure.com
+	EnvAPIKey     = "AZURE_OPENAI_API_KEY"     // Key auth; without it, tokens come from a managed identity
+	EnvAPIVersion = "AZURE_OPENAI_API_VERSION" // REST API version (default: DefaultAPIVersion)
+	EnvClientID   = "AZURE_CLIENT_ID"          // User-assigned managed identity to authenticate as
+)
+
+// ErrInvalidConfig is returned for configurations missing an endpoint or deployment
+var ErrInvalidConfig = errors.New("invalid Azure OpenAI configuration")
+
+// TokenSource supplies Microsoft Entra ID access tokens for Azure OpenAI requests
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a fixed access token, e.g. from `az account get-access-token`
+type StaticToken string
+
+// Token returns the token itself
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Config identifies an Azure OpenAI deployment and how to authenticate with it
+type Config struct {
+	// Endpoint is the resource endpoint, e.g. "https://my-resource.openai.azure.com"
+	Endpoint string
+
+	// Deployment is the deployment to call; Azure routes by deployment, not model name
+	Deployment string
+
+	// APIVersion is the REST API version (default: DefaultAPIVersion)
+	APIVersion string
+
+	// APIKey authenticates with a resource key; when empty, TokenSource is used instead
+	APIKey string
+
+	// TokenSource supplies Entra ID tokens when there is no APIKey (default: a ManagedIdentity)
+	TokenSource TokenSource
+}
+
+// FromEnv returns the endpoint, key, API version and managed identity set in the environment for
+// deployment; the endpoint is empty when Azure OpenAI isn't configured
+func FromEnv(deployment string) Config {
+	config := Config{
+		Endpoint:   os.Getenv(EnvEndpoint),
+		Deployment: deployment,
+		APIVersion: os.Getenv(EnvAPIVersion),
+		APIKey:     os.Getenv(EnvAPIKey),
+	}
+	if config.APIKey == "" {
+		config.TokenSource = &ManagedIdentity{ClientID: os.Getenv(EnvClientID)}
+	}
+	return config
+}
+
+// RequestOptions configures an openai-go client to call the deployment: requests go to the
+// deployment's path with the API version, authenticated by key or by token, and the
+// OPENAI_API_KEY the client picks up from the environment is never sent to Azure
+func (c Config) RequestOptions() ([]option.RequestOption, error) {
+	if c.Endpoint == "" {
+		return nil, fmt.Errorf("%w: missing endpoint", ErrInvalidConfig)
+	}
+	if c.Deployment == "" {
+		return nil, fmt.Errorf("%w: missing deployment name", ErrInvalidConfig)
+	}
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint %q is not a URL", ErrInvalidConfig, c.Endpoint)
+	}
+	apiVersion := c.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+
+	baseURL := strings.TrimSuffix(endpoint.String(), "/") + "/openai/deployments/" + url.PathEscape(c.Deployment) + "/"
+	opts := []option.RequestOption{
+		option.WithBaseURL(baseURL),
+		option.WithQuery("api-version", apiVersion),
+		option.WithHeaderDel("authorization"),
+	}
+
+	if c.APIKey != "" {
+		return append(opts, option.WithHeader("api-key", c.APIKey)), nil
+	}
+	tokens := c.TokenSource
+	if tokens == nil {
+		tokens = &ManagedIdentity{}
+	}
+	return append(opts, option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		token, err := tokens.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Azure access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return next(req)
+	})), nil
+}
@@ -0,0 +1,115 @@
+package azureopenai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// azureServer records the last request and answers like an Azure chat completions deployment
+func azureServer(t *testing.T, last **http.Request) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = r
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func chat(t *testing.T, config Config) {
+	t.Helper()
+	opts, err := config.RequestOptions()
+	if err != nil {
+		t.Fatalf("RequestOptions failed: %v", err)
+	}
+	client := openai.NewClient(opts...)
+	_, err = client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+	})
+	if err != nil {
+		t.Fatalf("chat completion failed: %v", err)
+	}
+}
+
+func TestRequestOptions_APIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-should-not-leak")
+	var last *http.Request
+	server := azureServer(t, &last)
+
+	chat(t, Config{Endpoint: server.URL + "/", Deployment: "narratives", APIKey: "azure-key"})
+
+	if last.URL.Path != "/openai/deployments/narratives/chat/completions" {
+		t.Errorf("unexpected path: %s", last.URL.Path)
+	}
+	if got := last.URL.Query().Get("api-version"); got != DefaultAPIVersion {
+		t.Errorf("expected the default API version, got %q", got)
+	}
+	if last.Header.Get("api-key") != "azure-key" || last.Header.Get("Authorization") != "" {
+		t.Errorf("expected key auth only, got api-key %q and Authorization %q", last.Header.Get("api-key"), last.Header.Get("Authorization"))
+	}
+}
+
+func TestRequestOptions_TokenSource(t *testing.T) {
+	var last *http.Request
+	server := azureServer(t, &last)
+
+	chat(t, Config{Endpoint: server.URL, Deployment: "narratives", APIVersion: "2025-01-01-preview", TokenSource: StaticToken("entra-token")})
+
+	if got := last.Header.Get("Authorization"); got != "Bearer entra-token" {
+		t.Errorf("expected the token as bearer auth, got %q", got)
+	}
+	if got := last.URL.Query().Get("api-version"); got != "2025-01-01-preview" {
+		t.Errorf("expected the configured API version, got %q", got)
+	}
+
+	for _, config := range []Config{{Deployment: "d"}, {Endpoint: server.URL}, {Endpoint: "not a url", Deployment: "d"}} {
+		if _, err := config.RequestOptions(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected %+v to be rejected, got %v", config, err)
+		}
+	}
+}
+
+func TestManagedIdentity_Token(t *testing.T) {
+	var requests atomic.Int32
+	var query, header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		query, header = r.URL.RawQuery, r.Header.Get("X-IDENTITY-HEADER")
+		expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		w.Write([]byte(`{"access_token":"mi-token","expires_on":"` + expires + `"}`))
+	}))
+	defer server.Close()
+	t.Setenv("IDENTITY_ENDPOINT", server.URL)
+	t.Setenv("IDENTITY_HEADER", "secret-header")
+
+	identity := &ManagedIdentity{ClientID: "client-1"}
+	for range 2 {
+		token, err := identity.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if token != "mi-token" {
+			t.Errorf("unexpected token %q", token)
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("expected the token to be cached, got %d requests", requests.Load())
+	}
+	if header != "secret-header" {
+		t.Errorf("expected the identity header, got %q", header)
+	}
+	if want := "api-version=2019-08-01&client_id=client-1&resource=https%3A%2F%2Fcognitiveservices.azure.com"; query != want {
+		t.Errorf("expected query %q, got %q", want, query)
+	}
+}
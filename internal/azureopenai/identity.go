@@ -0,0 +1,120 @@
+package azureopenai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// cognitiveServicesResource is the token audience Azure OpenAI accepts
+	cognitiveServicesResource = "https://cognitiveservices.azure.com"
+
+	// imdsEndpoint is the instance metadata service VMs and AKS nodes get managed identity tokens from
+	imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+	// tokenRefreshMargin renews tokens this long before they expire, so none expires mid-request
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// ManagedIdentity gets Entra ID tokens for the Azure managed identity the process runs as: from
+// the App Service / Functions / Container Apps identity endpoint when its environment variables
+// are set, else from the VM instance metadata service; tokens are cached until shortly before
+// they expire
+type ManagedIdentity struct {
+	// ClientID selects a user-assigned identity; empty uses the system-assigned one
+	ClientID string
+
+	// HTTPClient makes the token requests (default: a client with a 10 second timeout)
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// managedIdentityToken is the token response of both identity endpoints
+type managedIdentityToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresOn   string `json:"expires_on"` // Unix seconds, as a string
+}
+
+// Token returns a cached token, fetching a new one when it is missing or about to expire
+func (m *ManagedIdentity) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != "" && time.Until(m.expires) > tokenRefreshMargin {
+		return m.token, nil
+	}
+	token, expires, err := m.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	m.token, m.expires = token, expires
+	return token, nil
+}
+
+// fetch requests a new token from the identity endpoint available to the process
+func (m *ManagedIdentity) fetch(ctx context.Context) (string, time.Time, error) {
+	query := url.Values{"resource": {cognitiveServicesResource}}
+	endpoint, header := os.Getenv("IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_HEADER")
+	if endpoint != "" && header != "" {
+		query.Set("api-version", "2019-08-01")
+	} else {
+		endpoint, header = imdsEndpoint, ""
+		query.Set("api-version", "2018-02-01")
+	}
+	if m.ClientID != "" {
+		query.Set("client_id", m.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create managed identity request: %w", err)
+	}
+	if header != "" {
+		req.Header.Set("X-IDENTITY-HEADER", header)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+
+	client := m.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("managed identity endpoint unreachable (not running in Azure?): %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read managed identity token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("managed identity endpoint returned %s: %s", resp.Status, body)
+	}
+
+	var token managedIdentityToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse managed identity token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("managed identity endpoint returned no token")
+	}
+	// An unparseable expiry leaves the token expired, so it is fetched again next time
+	var expires time.Time
+	if seconds, err := strconv.ParseInt(token.ExpiresOn, 10, 64); err == nil {
+		expires = time.Unix(seconds, 0)
+	}
+	return token.AccessToken, expires, nil
+}
@@ -0,0 +1,314 @@
+package cluster
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// Algorithm selects how commits are clustered into episodes
+type Algorithm string
+
+const (
+	// AlgorithmGreedy compares each commit with the current episode only (the default)
+	AlgorithmGreedy Algorithm = "greedy"
+
+	// AlgorithmAgglomerative repeatedly merges the two most similar clusters (average linkage)
+	// until no pair reaches MinSimilarityScore, so interleaved workstreams end up in separate episodes
+	AlgorithmAgglomerative Algorithm = "agglomerative"
+
+	// AlgorithmDensity grows clusters DBSCAN-style from commits with at least DensityMinPoints
+	// commits (themselves included) scoring MinSimilarityScore or more; outliers become their own episodes
+	AlgorithmDensity Algorithm = "dbscan"
+
+	// AlgorithmPullRequest makes every merged pull or merge request an episode with its commits, linked
+	// issues and reviews, grouping commits outside any request greedily
+	AlgorithmPullRequest Algorithm = "pr"
+)
+
+// ParseAlgorithm converts an algorithm name to an Algorithm; the empty string selects AlgorithmGreedy
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch algorithm := Algorithm(strings.ToLower(strings.TrimSpace(name))); algorithm {
+	case "", AlgorithmGreedy:
+		return AlgorithmGreedy, nil
+	case AlgorithmAgglomerative, AlgorithmDensity, AlgorithmPullRequest:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unknown clustering algorithm %q (supported: %s, %s, %s, %s)",
+			name, AlgorithmGreedy, AlgorithmAgglomerative, AlgorithmDensity, AlgorithmPullRequest)
+	}
+}
+
+// clusterEpisodes clusters commits over their pairwise similarity matrix with a non-greedy algorithm
+// and turns each cluster into an episode, ordered by first commit
+func (ra *RepositoryActivity) clusterEpisodes(commits []git.Commit, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) []Episode {
+	similarities := ra.similarityMatrix(commits, config, score, artifactRefMap, boundaries)
+
+	var clusters [][]int
+	if config.Algorithm == AlgorithmDensity {
+		clusters = densityClusters(similarities, config.MinSimilarityScore, config.DensityMinPoints)
+	} else {
+		clusters = agglomerativeClusters(similarities, config.MinSimilarityScore)
+	}
+
+	for _, members := range clusters {
+		sort.Ints(members)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+
+	var episodes []Episode
+	for _, members := range clusters {
+		if len(members) < config.MinCommits {
+			continue
+		}
+		var episode Episode
+		for _, index := range members {
+			episode.Commits = append(episode.Commits, commits[index])
+			addReferencedArtifacts(&episode, commits[index], artifactRefMap, ra.Artifacts)
+		}
+		episodes = append(episodes, episode)
+	}
+
+	// Sessions of an interleaved cluster may start after later clusters do
+	episodes = ra.splitWorkSessions(episodes, config, artifactRefMap)
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].Commits[0].CommittedAt.Before(episodes[j].Commits[0].CommittedAt)
+	})
+	return episodes
+}
+
+// similarityMatrix scores every pair of commits close enough in time to be related
+// Commits are compared as single-commit episodes in both directions, keeping the higher score
+// Pairs further apart than the largest time gap are not compared; when splitting on releases, neither
+// are pairs a release separates
+// The result is sparse and symmetric: similarities[i][j] is set for every compared pair
+// Rows are scored concurrently over time partitions (see GroupingConfig.Workers)
+func (ra *RepositoryActivity) similarityMatrix(commits []git.Commit, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) []map[int]float64 {
+	window := config.MaxTimeGap
+	for _, gap := range config.AuthorTimeGaps {
+		window = max(window, gap)
+	}
+
+	epochs := releaseEpochs(commits, config, boundaries)
+
+	singles := make([]Episode, len(commits))
+	for i, commit := range commits {
+		singles[i] = Episode{Commits: []git.Commit{commit}}
+		addReferencedArtifacts(&singles[i], commit, artifactRefMap, ra.Artifacts)
+	}
+
+	// Each time partition scores its own commits against later ones, writing only its own rows
+	similarities := make([]map[int]float64, len(commits))
+	for i := range similarities {
+		similarities[i] = make(map[int]float64)
+	}
+	runPartitions(timePartitions(len(commits), config.partitionCount(len(commits))), func(_ int, part partition) {
+		for i := part.start; i < part.end; i++ {
+			for j := i + 1; j < len(commits); j++ {
+				if commits[j].CommittedAt.Sub(commits[i].CommittedAt) > window {
+					break
+				}
+				if epochs[i] != epochs[j] {
+					continue
+				}
+				similarities[i][j] = max(score(&singles[i], commits[j], config), score(&singles[j], commits[i], config))
+			}
+		}
+	})
+
+	for i := range similarities {
+		for j, similarity := range similarities[i] {
+			if i < j {
+				similarities[j][i] = similarity
+			}
+		}
+	}
+	return similarities
+}
+
+// releaseEpochs numbers the stretches of time-ordered commits between releases; commits in different
+// epochs have a release between them. Without release splitting every commit is in epoch 0
+func releaseEpochs(commits []git.Commit, config GroupingConfig, boundaries releaseBoundaries) []int {
+	epochs := make([]int, len(commits))
+	for i := 1; i < len(commits); i++ {
+		epochs[i] = epochs[i-1]
+		if config.splitsOnReleases() && boundaries.separates(commits[i-1], commits[i]) {
+			epochs[i]++
+		}
+	}
+	return epochs
+}
+
+// clusterLink accumulates the similarities of the compared commit pairs between two clusters
+type clusterLink struct {
+	sum   float64
+	count int
+}
+
+// mergeCandidate is a cluster pair in the merge queue; stale once either cluster has changed
+type mergeCandidate struct {
+	a, b               int
+	similarity         float64
+	versionA, versionB int
+}
+
+// mergeQueue orders merge candidates by similarity, highest first, then by cluster IDs for determinism
+type mergeQueue []mergeCandidate
+
+func (q mergeQueue) Len() int { return len(q) }
+func (q mergeQueue) Less(i, j int) bool {
+	if q[i].similarity != q[j].similarity {
+		return q[i].similarity > q[j].similarity
+	}
+	if q[i].a != q[j].a {
+		return q[i].a < q[j].a
+	}
+	return q[i].b < q[j].b
+}
+func (q mergeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *mergeQueue) Push(x any)   { *q = append(*q, x.(mergeCandidate)) }
+func (q *mergeQueue) Pop() any {
+	old := *q
+	candidate := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return candidate
+}
+
+// agglomerativeClusters merges clusters by average linkage until no pair reaches threshold
+// The average is taken over compared commit pairs only, so a workstream spanning weeks can still
+// merge through its overlapping neighborhoods
+func agglomerativeClusters(similarities []map[int]float64, threshold float64) [][]int {
+	n := len(similarities)
+	members := make([][]int, n)
+	links := make([]map[int]*clusterLink, n)
+	versions := make([]int, n)
+	alive := make([]bool, n)
+	for i := range n {
+		members[i] = []int{i}
+		links[i] = make(map[int]*clusterLink)
+		alive[i] = true
+	}
+
+	queue := &mergeQueue{}
+	for i := range n {
+		for j, similarity := range similarities[i] {
+			if i < j {
+				link := &clusterLink{sum: similarity, count: 1}
+				links[i][j] = link
+				links[j][i] = link
+				*queue = append(*queue, mergeCandidate{a: i, b: j, similarity: similarity})
+			}
+		}
+	}
+	heap.Init(queue)
+
+	for queue.Len() > 0 {
+		candidate := heap.Pop(queue).(mergeCandidate)
+		a, b := candidate.a, candidate.b
+		if !alive[a] || !alive[b] || versions[a] != candidate.versionA || versions[b] != candidate.versionB {
+			continue
+		}
+		if candidate.similarity < threshold {
+			break
+		}
+
+		// Merge b into a, combining b's links into a's
+		members[a] = append(members[a], members[b]...)
+		alive[b] = false
+		versions[a]++
+		delete(links[a], b)
+		for c, link := range links[b] {
+			if c == a {
+				continue
+			}
+			delete(links[c], b)
+			if existing, ok := links[a][c]; ok {
+				existing.sum += link.sum
+				existing.count += link.count
+			} else {
+				links[a][c] = link
+				links[c][a] = link
+			}
+		}
+		links[b] = nil
+
+		for c, link := range links[a] {
+			first, second := a, c
+			if second < first {
+				first, second = second, first
+			}
+			heap.Push(queue, mergeCandidate{
+				a: first, b: second,
+				similarity: link.sum / float64(link.count),
+				versionA:   versions[first], versionB: versions[second],
+			})
+		}
+	}
+
+	var clusters [][]int
+	for i := range n {
+		if alive[i] {
+			clusters = append(clusters, members[i])
+		}
+	}
+	return clusters
+}
+
+// densityClusters runs DBSCAN over the similarity matrix: commits scoring threshold or more are
+// neighbors, and commits whose neighborhood (themselves included) has minPoints commits are core points
+// Commits reachable from no core point become single-commit clusters rather than being dropped
+func densityClusters(similarities []map[int]float64, threshold float64, minPoints int) [][]int {
+	minPoints = max(minPoints, 2)
+
+	neighbors := make([][]int, len(similarities))
+	for i, row := range similarities {
+		for j, similarity := range row {
+			if similarity >= threshold {
+				neighbors[i] = append(neighbors[i], j)
+			}
+		}
+		sort.Ints(neighbors[i])
+	}
+	isCore := func(i int) bool { return len(neighbors[i])+1 >= minPoints }
+
+	assigned := make([]int, len(similarities))
+	for i := range assigned {
+		assigned[i] = -1
+	}
+
+	var clusters [][]int
+	for i := range similarities {
+		if assigned[i] >= 0 || !isCore(i) {
+			continue
+		}
+		id := len(clusters)
+		cluster := []int{i}
+		assigned[i] = id
+		for queue := []int{i}; len(queue) > 0; queue = queue[1:] {
+			point := queue[0]
+			if !isCore(point) {
+				continue
+			}
+			for _, neighbor := range neighbors[point] {
+				if assigned[neighbor] >= 0 {
+					continue
+				}
+				assigned[neighbor] = id
+				cluster = append(cluster, neighbor)
+				queue = append(queue, neighbor)
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	// Noise: commits similar to no core point
+	for i := range similarities {
+		if assigned[i] < 0 {
+			clusters = append(clusters, []int{i})
+		}
+	}
+	return clusters
+}
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// interleavedActivity has Alice on auth and Bob on docs, committing in alternation
+func interleavedActivity() *RepositoryActivity {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+
+	var commits []git.Commit
+	for i := range 3 {
+		at := base.Add(time.Duration(2*i) * time.Hour)
+		commits = append(commits,
+			createTestCommit("a000000"+string(rune('1'+i)), "Refactor login session handling", alice, at, []string{"auth/session.go"}),
+			createTestCommit("b000000"+string(rune('1'+i)), "Update installation guide", bob, at.Add(time.Hour), []string{"docs/install.md"}),
+		)
+	}
+	return &RepositoryActivity{Commits: commits}
+}
+
+func TestGroupIntoEpisodes_InterleavedWorkstreams(t *testing.T) {
+	greedy := interleavedActivity().GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(greedy) != 6 {
+		t.Fatalf("Expected greedy grouping to fragment into 6 episodes, got %d", len(greedy))
+	}
+
+	for _, algorithm := range []Algorithm{AlgorithmAgglomerative, AlgorithmDensity} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			config := DefaultGroupingConfig()
+			config.Algorithm = algorithm
+			episodes := interleavedActivity().GroupIntoEpisodes(config)
+
+			if len(episodes) != 2 {
+				t.Fatalf("Expected 2 episodes, got %d", len(episodes))
+			}
+			for i, episode := range episodes {
+				if episode.ID != EpisodeID(&episodes[i]) {
+					t.Errorf("Expected episode %d to have its content-derived ID, got %s", i, episode.ID)
+				}
+				if len(episode.Commits) != 3 {
+					t.Fatalf("Expected 3 commits in %s, got %d", episode.ID, len(episode.Commits))
+				}
+				author := episode.Commits[0].Author.Email
+				for j, commit := range episode.Commits {
+					if commit.Author.Email != author {
+						t.Errorf("Expected %s to hold one workstream, got %s and %s", episode.ID, author, commit.Author.Email)
+					}
+					if j > 0 && commit.CommittedAt.Before(episode.Commits[j-1].CommittedAt) {
+						t.Errorf("Expected %s commits in time order", episode.ID)
+					}
+				}
+			}
+			if episodes[0].Commits[0].Author.Name != "Alice" {
+				t.Errorf("Expected episodes ordered by first commit, got %s first", episodes[0].Commits[0].Author.Name)
+			}
+		})
+	}
+}
+
+func TestGroupIntoEpisodes_ClusteringRespectsTimeGap(t *testing.T) {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	activity := &RepositoryActivity{Commits: []git.Commit{
+		createTestCommit("c000001", "Add parser", alice, base, []string{"parser.go"}),
+		createTestCommit("c000002", "Fix parser", alice, base.Add(time.Hour), []string{"parser.go"}),
+		createTestCommit("c000003", "Tweak parser", alice, base.Add(10*24*time.Hour), []string{"parser.go"}),
+	}}
+
+	for _, algorithm := range []Algorithm{AlgorithmAgglomerative, AlgorithmDensity} {
+		config := DefaultGroupingConfig()
+		config.Algorithm = algorithm
+		episodes := activity.GroupIntoEpisodes(config)
+		if len(episodes) != 2 {
+			t.Errorf("%s: expected 2 episodes across a 10 day gap, got %d", algorithm, len(episodes))
+		}
+	}
+}
+
+func TestAgglomerativeClusters_AverageLinkage(t *testing.T) {
+	// 0-1 and 1-2 are similar but 0-2 is not: the merged {0,1} averages 0.45 with 2
+	similarities := []map[int]float64{
+		{1: 0.9, 2: 0.2},
+		{0: 0.9, 2: 0.7},
+		{0: 0.2, 1: 0.7},
+	}
+
+	clusters := agglomerativeClusters(similarities, 0.5)
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %v", clusters)
+	}
+
+	clusters = agglomerativeClusters(similarities, 0.4)
+	if len(clusters) != 1 || len(clusters[0]) != 3 {
+		t.Errorf("Expected a single cluster at a 0.4 threshold, got %v", clusters)
+	}
+}
+
+func TestDensityClusters_Noise(t *testing.T) {
+	similarities := []map[int]float64{
+		{1: 0.8, 2: 0.8},
+		{0: 0.8, 2: 0.8},
+		{0: 0.8, 1: 0.8, 3: 0.1},
+		{2: 0.1},
+	}
+
+	clusters := densityClusters(similarities, 0.5, 3)
+	if len(clusters) != 2 {
+		t.Fatalf("Expected a cluster and a noise point, got %v", clusters)
+	}
+	if len(clusters[0]) != 3 {
+		t.Errorf("Expected the dense cluster to hold 3 commits, got %v", clusters[0])
+	}
+	if len(clusters[1]) != 1 || clusters[1][0] != 3 {
+		t.Errorf("Expected commit 3 as noise, got %v", clusters[1])
+	}
+
+	// Too few neighbors for any core point: everything is noise
+	if clusters := densityClusters(similarities, 0.5, 5); len(clusters) != 4 {
+		t.Errorf("Expected 4 singleton clusters, got %v", clusters)
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := map[string]Algorithm{
+		"":              AlgorithmGreedy,
+		"greedy":        AlgorithmGreedy,
+		"Agglomerative": AlgorithmAgglomerative,
+		" dbscan ":      AlgorithmDensity,
+	}
+	for name, expected := range tests {
+		algorithm, err := ParseAlgorithm(name)
+		if err != nil {
+			t.Errorf("ParseAlgorithm(%q) returned error: %v", name, err)
+		}
+		if algorithm != expected {
+			t.Errorf("ParseAlgorithm(%q) = %q, expected %q", name, algorithm, expected)
+		}
+	}
+
+	if _, err := ParseAlgorithm("kmeans"); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+}
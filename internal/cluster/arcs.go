@@ -0,0 +1,273 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArcStrategy selects how episodes are grouped into arcs
+type ArcStrategy string
+
+const (
+	// ArcByMilestone groups episodes by the milestone most of their artifacts belong to
+	ArcByMilestone ArcStrategy = "milestone"
+
+	// ArcByLabel groups episodes by the label most common across their artifacts
+	ArcByLabel ArcStrategy = "label"
+
+	// ArcBySemantic groups episodes whose embedded commits and artifacts are similar
+	ArcBySemantic ArcStrategy = "semantic"
+)
+
+// ParseArcStrategy converts a strategy name to an ArcStrategy; the empty string selects ArcByMilestone
+func ParseArcStrategy(name string) (ArcStrategy, error) {
+	switch strategy := ArcStrategy(strings.ToLower(strings.TrimSpace(name))); strategy {
+	case "", ArcByMilestone:
+		return ArcByMilestone, nil
+	case ArcByLabel, ArcBySemantic:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown arc strategy %q (supported: %s, %s, %s)", name, ArcByMilestone, ArcByLabel, ArcBySemantic)
+	}
+}
+
+// Arc is a storyline above episodes, such as an epic or milestone, holding its episodes in time order
+type Arc struct {
+	ID       string      `json:"id"`
+	Title    string      `json:"title"`
+	Strategy ArcStrategy `json:"strategy"`
+	Key      string      `json:"key,omitempty"` // Milestone or label the arc was grouped by; empty for standalone and semantic arcs
+	Episodes []Episode   `json:"episodes"`
+}
+
+// ArcConfig defines parameters for grouping episodes into arcs
+type ArcConfig struct {
+	Strategy ArcStrategy
+
+	// Minimum average cosine similarity between the episodes of a semantic arc
+	MinSimilarity float64
+
+	// Embedder used by ArcBySemantic
+	Embedder Embedder
+}
+
+// DefaultArcConfig returns sensible default arc grouping parameters
+func DefaultArcConfig() ArcConfig {
+	return ArcConfig{
+		Strategy:      ArcByMilestone,
+		MinSimilarity: 0.75,
+	}
+}
+
+// Limit for the text embedded per episode
+const maxEpisodeEmbeddingText = 4000
+
+// GroupIntoArcs groups episodes into arcs with the configured strategy
+// Episodes with no milestone or label of their own form standalone single-episode arcs
+// Arcs are ordered by their first episode, and their episodes by start date
+func GroupIntoArcs(ctx context.Context, episodes []Episode, config ArcConfig) ([]Arc, error) {
+	var groups [][]int
+	var keys []string
+	switch config.Strategy {
+	case ArcBySemantic:
+		if config.Embedder == nil {
+			return nil, fmt.Errorf("semantic arcs require an embedder")
+		}
+		similarities, err := episodeSimilarities(ctx, config.Embedder, episodes)
+		if err != nil {
+			return nil, err
+		}
+		groups = agglomerativeClusters(similarities, config.MinSimilarity)
+		keys = make([]string, len(groups))
+	case ArcByLabel:
+		groups, keys = groupEpisodesByKey(episodes, dominantLabel)
+	case ArcByMilestone, "":
+		groups, keys = groupEpisodesByKey(episodes, dominantMilestone)
+	default:
+		return nil, fmt.Errorf("unknown arc strategy %q", config.Strategy)
+	}
+
+	starts := make([]time.Time, len(episodes))
+	for i := range episodes {
+		starts[i], _ = episodes[i].GetDateRange()
+	}
+
+	arcs := make([]Arc, len(groups))
+	for i, members := range groups {
+		sort.SliceStable(members, func(a, b int) bool { return starts[members[a]].Before(starts[members[b]]) })
+		arcs[i] = Arc{Strategy: config.Strategy, Key: keys[i]}
+		if arcs[i].Strategy == "" {
+			arcs[i].Strategy = ArcByMilestone
+		}
+		for _, index := range members {
+			arcs[i].Episodes = append(arcs[i].Episodes, episodes[index])
+		}
+		arcs[i].Title = arcTitle(&arcs[i])
+	}
+
+	sort.SliceStable(arcs, func(i, j int) bool {
+		a, _ := arcs[i].GetDateRange()
+		b, _ := arcs[j].GetDateRange()
+		return a.Before(b)
+	})
+	for i := range arcs {
+		arcs[i].ID = fmt.Sprintf("A%d", i+1)
+	}
+	return arcs, nil
+}
+
+// groupEpisodesByKey groups episodes sharing a key, in order of first appearance
+// Episodes without a key each form their own group with an empty key
+func groupEpisodesByKey(episodes []Episode, key func(*Episode) string) ([][]int, []string) {
+	var groups [][]int
+	var keys []string
+	index := make(map[string]int)
+	for i := range episodes {
+		k := key(&episodes[i])
+		if k == "" {
+			groups = append(groups, []int{i})
+			keys = append(keys, "")
+			continue
+		}
+		if g, ok := index[k]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		index[k] = len(groups)
+		groups = append(groups, []int{i})
+		keys = append(keys, k)
+	}
+	return groups, keys
+}
+
+// dominantMilestone returns the milestone most of the episode's artifacts belong to
+func dominantMilestone(e *Episode) string {
+	counts := make(map[string]int)
+	for _, artifact := range e.Artifacts {
+		if artifact.Metadata.Milestone != "" {
+			counts[artifact.Metadata.Milestone]++
+		}
+	}
+	return mostCommon(counts)
+}
+
+// dominantLabel returns the label most common across the episode's artifacts
+func dominantLabel(e *Episode) string {
+	counts := make(map[string]int)
+	for _, artifact := range e.Artifacts {
+		for _, label := range artifact.Labels {
+			counts[label]++
+		}
+	}
+	return mostCommon(counts)
+}
+
+// mostCommon returns the key with the highest count, the alphabetically first on ties, or "" if empty
+func mostCommon(counts map[string]int) string {
+	best := ""
+	for key, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && key < best) {
+			best = key
+		}
+	}
+	return best
+}
+
+// episodeSimilarities embeds each episode and returns the full pairwise cosine similarity matrix
+// in the sparse form agglomerativeClusters expects
+func episodeSimilarities(ctx context.Context, embedder Embedder, episodes []Episode) ([]map[int]float64, error) {
+	texts := make([]string, len(episodes))
+	for i := range episodes {
+		texts[i] = episodeEmbeddingText(&episodes[i])
+	}
+	embeddings, err := embedInBatches(ctx, embedder, texts, "episodes")
+	if err != nil {
+		return nil, err
+	}
+
+	similarities := make([]map[int]float64, len(episodes))
+	for i := range similarities {
+		similarities[i] = make(map[int]float64)
+	}
+	for i := range embeddings {
+		vector := make([]float64, len(embeddings[i]))
+		for k, value := range embeddings[i] {
+			vector[k] = float64(value)
+		}
+		for j := i + 1; j < len(embeddings); j++ {
+			similarity := cosineSimilarity(embeddings[j], vector)
+			similarities[i][j] = similarity
+			similarities[j][i] = similarity
+		}
+	}
+	return similarities, nil
+}
+
+// episodeEmbeddingText describes an episode for embedding: its artifact titles, then commit subjects
+func episodeEmbeddingText(e *Episode) string {
+	var b strings.Builder
+	for _, artifact := range e.Artifacts {
+		if artifact.Title != "" {
+			b.WriteString(artifact.Title)
+			b.WriteString("\n")
+		}
+	}
+	for _, commit := range e.Commits {
+		b.WriteString(commit.MessageSubject)
+		b.WriteString("\n")
+	}
+
+	text := b.String()
+	if len(text) > maxEpisodeEmbeddingText {
+		text = text[:maxEpisodeEmbeddingText]
+	}
+	return text
+}
+
+// arcTitle names an arc after its key, else the first titled pull request, issue or ticket in it,
+// else its first commit
+func arcTitle(a *Arc) string {
+	if a.Key != "" {
+		return a.Key
+	}
+	for _, episode := range a.Episodes {
+		for _, artifact := range episode.Artifacts {
+			if artifact.Title == "" {
+				continue
+			}
+			switch artifact.Type {
+			case ArtifactPullRequest, ArtifactMergeRequest, ArtifactIssue, ArtifactTicket:
+				return artifact.Title
+			}
+		}
+	}
+	for _, episode := range a.Episodes {
+		if len(episode.Commits) > 0 {
+			return episode.Commits[0].MessageSubject
+		}
+	}
+	return fmt.Sprintf("Arc of %d episodes", len(a.Episodes))
+}
+
+// GetDateRange returns the earliest and latest timestamps across the arc's episodes
+func (a *Arc) GetDateRange() (time.Time, time.Time) {
+	var earliest, latest time.Time
+	for i := range a.Episodes {
+		start, end := a.Episodes[i].GetDateRange()
+		if !start.IsZero() && (earliest.IsZero() || start.Before(earliest)) {
+			earliest = start
+		}
+		if end.After(latest) {
+			latest = end
+		}
+	}
+	return earliest, latest
+}
+
+// GetCommitCount returns the number of distinct commits across the arc's episodes
+func (a *Arc) GetCommitCount() int {
+	return CountUniqueCommits(a.Episodes)
+}
@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// arcEpisodes returns four episodes a day apart: two in the "v1" milestone, one in "v2" and one without
+func arcEpisodes() []Episode {
+	base := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	episode := func(id, subject string, day int, artifacts ...Artifact) Episode {
+		commit := createTestCommit(id+"000000", subject, alice, base.Add(time.Duration(day)*24*time.Hour), []string{"main.go"})
+		return Episode{ID: id, Commits: []git.Commit{commit}, Artifacts: artifacts}
+	}
+	issue := func(id, title, milestone string, labels ...string) Artifact {
+		return Artifact{ID: id, Type: ArtifactIssue, Title: title, Labels: labels, Metadata: ArtifactMetadata{Milestone: milestone}}
+	}
+
+	// Out of date order, so arcs must be sorted
+	return []Episode{
+		episode("E3", "Add login", 2, issue("i3", "Login page", "v2", "auth")),
+		episode("E1", "Add parser", 0, issue("i1", "Parser", "v1", "parsing"), issue("i4", "Docs", "", "docs")),
+		episode("E4", "Fix typo", 3),
+		episode("E2", "Add lexer", 1, issue("i2", "Lexer", "v1", "parsing")),
+	}
+}
+
+func TestGroupIntoArcs_ByMilestone(t *testing.T) {
+	arcs, err := GroupIntoArcs(context.Background(), arcEpisodes(), DefaultArcConfig())
+	if err != nil {
+		t.Fatalf("GroupIntoArcs failed: %v", err)
+	}
+
+	if len(arcs) != 3 {
+		t.Fatalf("Expected v1, v2 and a standalone arc, got %d arcs", len(arcs))
+	}
+	expected := []struct {
+		id, title, key string
+		episodes       []string
+	}{
+		{"A1", "v1", "v1", []string{"E1", "E2"}},
+		{"A2", "v2", "v2", []string{"E3"}},
+		{"A3", "Fix typo", "", []string{"E4"}},
+	}
+	for i, want := range expected {
+		arc := arcs[i]
+		if arc.ID != want.id || arc.Title != want.title || arc.Key != want.key || arc.Strategy != ArcByMilestone {
+			t.Errorf("Arc %d: expected %s %q (key %q), got %s %q (key %q, %s)", i, want.id, want.title, want.key, arc.ID, arc.Title, arc.Key, arc.Strategy)
+		}
+		if len(arc.Episodes) != len(want.episodes) {
+			t.Errorf("Arc %s: expected episodes %v, got %d", arc.ID, want.episodes, len(arc.Episodes))
+			continue
+		}
+		for j, id := range want.episodes {
+			if arc.Episodes[j].ID != id {
+				t.Errorf("Arc %s: expected episode %s at %d, got %s", arc.ID, id, j, arc.Episodes[j].ID)
+			}
+		}
+	}
+
+	if arcs[0].GetCommitCount() != 2 {
+		t.Errorf("Expected 2 commits in arc A1, got %d", arcs[0].GetCommitCount())
+	}
+	start, end := arcs[0].GetDateRange()
+	if end.Sub(start) != 24*time.Hour {
+		t.Errorf("Expected arc A1 to span a day, got %s", end.Sub(start))
+	}
+}
+
+func TestGroupIntoArcs_ByLabel(t *testing.T) {
+	config := DefaultArcConfig()
+	config.Strategy = ArcByLabel
+	arcs, err := GroupIntoArcs(context.Background(), arcEpisodes(), config)
+	if err != nil {
+		t.Fatalf("GroupIntoArcs failed: %v", err)
+	}
+
+	// E1 ties "docs" and "parsing"; the alphabetically first label wins
+	if len(arcs) != 4 || arcs[0].Title != "docs" || arcs[1].Title != "parsing" || arcs[2].Title != "auth" {
+		titles := make([]string, len(arcs))
+		for i, arc := range arcs {
+			titles[i] = arc.Title
+		}
+		t.Errorf("Expected docs, parsing, auth and standalone arcs, got %v", titles)
+	}
+}
+
+func TestGroupIntoArcs_Semantic(t *testing.T) {
+	config := DefaultArcConfig()
+	config.Strategy = ArcBySemantic
+	embedder := &topicEmbedder{topics: []string{"Add", "Fix"}}
+	config.Embedder = embedder
+	arcs, err := GroupIntoArcs(context.Background(), arcEpisodes(), config)
+	if err != nil {
+		t.Fatalf("GroupIntoArcs failed: %v", err)
+	}
+
+	if len(arcs) != 2 {
+		t.Fatalf("Expected feature work and the typo fix as 2 arcs, got %d", len(arcs))
+	}
+	if len(arcs[0].Episodes) != 3 || arcs[0].Title != "Parser" || arcs[0].Key != "" {
+		t.Errorf("Expected 3 episodes titled by the first issue, got %d titled %q", len(arcs[0].Episodes), arcs[0].Title)
+	}
+	if len(arcs[1].Episodes) != 1 || arcs[1].Episodes[0].ID != "E4" {
+		t.Errorf("Expected the typo fix alone, got %+v", arcs[1].Episodes)
+	}
+	if embedder.calls != 1 {
+		t.Errorf("Expected episodes embedded in one batch, got %d calls", embedder.calls)
+	}
+
+	if _, err := GroupIntoArcs(context.Background(), arcEpisodes(), ArcConfig{Strategy: ArcBySemantic}); err == nil {
+		t.Error("Expected an error without an embedder")
+	}
+}
+
+func TestParseArcStrategy(t *testing.T) {
+	for name, expected := range map[string]ArcStrategy{"": ArcByMilestone, "Label": ArcByLabel, "semantic": ArcBySemantic} {
+		if strategy, err := ParseArcStrategy(name); err != nil || strategy != expected {
+			t.Errorf("ParseArcStrategy(%q) = %q, %v; expected %q", name, strategy, err, expected)
+		}
+	}
+	if _, err := ParseArcStrategy("author"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
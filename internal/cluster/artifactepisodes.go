@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// addArtifactEpisodes groups artifacts that no episode holds into episodes without commits and
+// inserts them among the existing episodes by start time
+// Releases never qualify, and other artifacts need MinArtifactDiscussions comments or reviews.
+// Two artifacts end up together when one references the other (in its text, cross-references or
+// parent ticket) or when they share a label and their activity lies within MaxTimeGap
+func addArtifactEpisodes(episodes []Episode, artifacts []Artifact, config GroupingConfig) []Episode {
+	held := make(map[string]bool)
+	for i := range episodes {
+		for _, artifact := range episodes[i].Artifacts {
+			held[artifact.ID] = true
+		}
+	}
+
+	var candidates []Artifact
+	for _, artifact := range artifacts {
+		if artifact.Type != ArtifactRelease && !held[artifact.ID] && countDiscussions(artifact) >= config.MinArtifactDiscussions {
+			candidates = append(candidates, artifact)
+		}
+	}
+	if len(candidates) == 0 {
+		return episodes
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+
+	groups := newDisjointSet(len(candidates))
+	index := make(map[*Artifact]int, len(candidates))
+	refMap := buildArtifactReferenceMap(candidates)
+	for i := range candidates {
+		index[&candidates[i]] = i
+	}
+	for i := range candidates {
+		for _, ref := range artifactReferences(&candidates[i]) {
+			if target, ok := refMap[ref]; ok {
+				groups.union(i, index[target])
+			}
+		}
+		for j := i + 1; j < len(candidates); j++ {
+			if shareLabel(&candidates[i], &candidates[j]) && activityGap(&candidates[i], &candidates[j]) <= config.MaxTimeGap {
+				groups.union(i, j)
+			}
+		}
+	}
+
+	// Candidates are in creation order, so each group's artifacts and the groups themselves are too
+	var order []int
+	members := make(map[int][]Artifact)
+	for i := range candidates {
+		root := groups.find(i)
+		if _, ok := members[root]; !ok {
+			order = append(order, root)
+		}
+		members[root] = append(members[root], candidates[i])
+	}
+
+	result := make([]Episode, 0, len(episodes)+len(order))
+	next := 0
+	for _, root := range order {
+		episode := Episode{Artifacts: members[root]}
+		start := episode.Artifacts[0].CreatedAt
+		for next < len(episodes) && !episodeStart(&episodes[next]).After(start) {
+			result = append(result, episodes[next])
+			next++
+		}
+		result = append(result, episode)
+	}
+	return append(result, episodes[next:]...)
+}
+
+// countDiscussions counts an artifact's comments and reviews, leaving out process events
+func countDiscussions(artifact Artifact) int {
+	count := 0
+	for _, discussion := range artifact.Discussions {
+		if discussion.Type != DiscussionEvent {
+			count++
+		}
+	}
+	return count
+}
+
+// artifactReferences returns the reference keys an artifact mentions in its title, description and
+// discussions, plus its recorded cross-references and parent ticket
+func artifactReferences(artifact *Artifact) []string {
+	texts := []string{artifact.Title, artifact.Description}
+	for _, discussion := range artifact.Discussions {
+		texts = append(texts, discussion.Body)
+	}
+
+	var refs []string
+	for ref := range extractArtifactReferences(strings.Join(texts, "\n")) {
+		refs = append(refs, ref)
+	}
+	refs = append(refs, artifact.Metadata.RelatedArtifacts...)
+	if artifact.Metadata.ParentKey != "" {
+		refs = append(refs, strings.ToUpper(artifact.Metadata.ParentKey))
+	}
+	return refs
+}
+
+// shareLabel reports whether two artifacts have a label in common, ignoring case
+func shareLabel(a, b *Artifact) bool {
+	for _, left := range a.Labels {
+		for _, right := range b.Labels {
+			if strings.EqualFold(left, right) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// activityGap returns the time between two artifacts' activity (creation to last update), 0 when they overlap
+func activityGap(a, b *Artifact) time.Duration {
+	aEnd, bEnd := lastActivity(a), lastActivity(b)
+	switch {
+	case bEnd.Before(a.CreatedAt):
+		return a.CreatedAt.Sub(bEnd)
+	case aEnd.Before(b.CreatedAt):
+		return b.CreatedAt.Sub(aEnd)
+	default:
+		return 0
+	}
+}
+
+// lastActivity returns when an artifact was last updated, or created when it never was
+func lastActivity(artifact *Artifact) time.Time {
+	if artifact.UpdatedAt.After(artifact.CreatedAt) {
+		return artifact.UpdatedAt
+	}
+	return artifact.CreatedAt
+}
+
+// disjointSet is a union-find over indices
+type disjointSet []int
+
+func newDisjointSet(n int) disjointSet {
+	set := make(disjointSet, n)
+	for i := range set {
+		set[i] = i
+	}
+	return set
+}
+
+func (s disjointSet) find(i int) int {
+	for s[i] != i {
+		s[i] = s[s[i]]
+		i = s[i]
+	}
+	return i
+}
+
+// union joins two sets, keeping the lower index as the root
+func (s disjointSet) union(a, b int) {
+	ra, rb := s.find(a), s.find(b)
+	if ra < rb {
+		s[rb] = ra
+	} else if rb < ra {
+		s[ra] = rb
+	}
+}
@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func discussedIssue(number int, title string, created time.Time, labels ...string) Artifact {
+	return Artifact{
+		ID:          fmt.Sprintf("issue-%d", number),
+		Number:      number,
+		Type:        ArtifactIssue,
+		Title:       title,
+		Labels:      labels,
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(2 * time.Hour),
+		Discussions: []Discussion{{ID: title, Type: DiscussionComment, Body: "Thoughts?"}},
+	}
+}
+
+func TestGroupIntoEpisodes_ArtifactEpisodes(t *testing.T) {
+	base := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	design := discussedIssue(1, "Design plugin API", base.Add(-48*time.Hour), "design")
+	followUp := discussedIssue(2, "Plugin loading order", base.Add(-46*time.Hour), "design")
+	related := discussedIssue(3, "Follow up on #1 naming", base.Add(10*24*time.Hour))
+	quiet := discussedIssue(4, "Typo", base.Add(-72*time.Hour))
+	quiet.Discussions = []Discussion{{ID: "e1", Type: DiscussionEvent, Body: "labeled"}}
+	fixed := discussedIssue(5, "Parser crash", base)
+	release := releaseArtifact("release-v1", "v1", base.Add(5*24*time.Hour))
+
+	ra := &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Fix #5 parser crash", author, base, []string{"parser.go"}),
+		},
+		Artifacts: []Artifact{fixed, related, design, followUp, quiet, release},
+	}
+
+	episodes := ra.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 2 {
+		t.Fatalf("Expected a commit episode and one artifact episode, got %d", len(episodes))
+	}
+
+	first := episodes[0]
+	if len(first.Commits) != 0 || len(first.Artifacts) != 3 {
+		t.Fatalf("Expected the design discussion to come first with 3 artifacts, got %d commits and %d artifacts", len(first.Commits), len(first.Artifacts))
+	}
+	wantOrder := []string{"issue-1", "issue-2", "issue-3"}
+	for i, id := range wantOrder {
+		if first.Artifacts[i].ID != id {
+			t.Errorf("Artifact %d: expected %s, got %s", i, id, first.Artifacts[i].ID)
+		}
+	}
+	if first.ID != EpisodeID(&first) {
+		t.Errorf("Expected a content-derived ID, got %s", first.ID)
+	}
+
+	if len(episodes[1].Commits) != 1 || episodes[1].Artifacts[0].ID != "issue-5" {
+		t.Errorf("Expected the commit episode to keep issue-5, got %+v", episodes[1].Artifacts)
+	}
+
+	config := DefaultGroupingConfig()
+	config.ArtifactEpisodes = false
+	if got := len(ra.GroupIntoEpisodes(config)); got != 1 {
+		t.Errorf("Expected no artifact episodes when disabled, got %d episodes", got)
+	}
+}
+
+func TestGroupIntoEpisodes_ArtifactEpisodesWithoutCommits(t *testing.T) {
+	base := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	ra := &RepositoryActivity{
+		Artifacts: []Artifact{
+			discussedIssue(1, "Roadmap", base, "planning"),
+			discussedIssue(2, "Triage backlog", base.Add(90*24*time.Hour), "planning"),
+		},
+	}
+
+	episodes := ra.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 2 {
+		t.Fatalf("Expected label matches far apart in time to stay separate, got %d episodes", len(episodes))
+	}
+	if episodes[0].Artifacts[0].ID != "issue-1" || episodes[0].ID == episodes[1].ID {
+		t.Errorf("Expected distinct episodes in creation order, got %s and %s", episodes[0].ID, episodes[1].ID)
+	}
+}
+
+func TestActivityGap(t *testing.T) {
+	base := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	a := Artifact{CreatedAt: base, UpdatedAt: base.Add(time.Hour)}
+	b := Artifact{CreatedAt: base.Add(3 * time.Hour)}
+	if got := activityGap(&a, &b); got != 2*time.Hour {
+		t.Errorf("Expected a 2h gap, got %v", got)
+	}
+	if got := activityGap(&b, &a); got != 2*time.Hour {
+		t.Errorf("Expected the gap to be symmetric, got %v", got)
+	}
+	b.CreatedAt = base.Add(30 * time.Minute)
+	if got := activityGap(&a, &b); got != 0 {
+		t.Errorf("Expected overlapping activity to have no gap, got %v", got)
+	}
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import "sort"
+
+// AttachBuilds records CI builds on the pull and merge requests they built and returns the number of
+// builds that matched a request
+// A build matches by pull request number, by one of the request's commits, or by running on the
+// request's head branch while the request was open; builds already recorded are not duplicated
+func AttachBuilds(artifacts []Artifact, builds []Build) int {
+	matched := 0
+	for _, build := range builds {
+		attached := false
+		for i := range artifacts {
+			artifact := &artifacts[i]
+			if !buildMatchesRequest(artifact, build) {
+				continue
+			}
+			if !hasBuild(artifact.Metadata.Builds, build) {
+				artifact.Metadata.Builds = append(artifact.Metadata.Builds, build)
+			}
+			attached = true
+		}
+		if attached {
+			matched++
+		}
+	}
+
+	for i := range artifacts {
+		builds := artifacts[i].Metadata.Builds
+		sort.SliceStable(builds, func(a, b int) bool { return builds[a].StartedAt.Before(builds[b].StartedAt) })
+	}
+	return matched
+}
+
+// buildMatchesRequest reports whether a build ran for a pull or merge request
+func buildMatchesRequest(artifact *Artifact, build Build) bool {
+	if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+		return false
+	}
+	if build.PullRequest > 0 {
+		return build.PullRequest == artifact.Number
+	}
+	if build.CommitSHA != "" && pullRequestContainsCommit(artifact, build.CommitSHA) {
+		return true
+	}
+
+	// Branch names are reused, so only builds during the request's lifetime count
+	if build.Branch == "" || build.Branch != artifact.Metadata.HeadBranch || build.StartedAt.Before(artifact.CreatedAt) {
+		return false
+	}
+	end := artifact.MergedAt
+	if end == nil {
+		end = artifact.ClosedAt
+	}
+	return end == nil || !build.StartedAt.After(*end)
+}
+
+// hasBuild reports whether a build is already recorded
+func hasBuild(builds []Build, build Build) bool {
+	for _, existing := range builds {
+		if existing.Provider == build.Provider && existing.Name == build.Name && existing.Number == build.Number &&
+			existing.URL == build.URL && existing.StartedAt.Equal(build.StartedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildSummary counts the CI outcomes of a pull or merge request
+type BuildSummary struct {
+	Total             int    `json:"total"`
+	Failed            int    `json:"failed"`
+	FailedBeforeMerge int    `json:"failed_before_merge"` // Failed builds started before the request merged
+	Latest            *Build `json:"latest,omitempty"`
+}
+
+// GetBuildSummary summarizes the artifact's CI builds
+func (a *Artifact) GetBuildSummary() BuildSummary {
+	summary := BuildSummary{Total: len(a.Metadata.Builds)}
+	for i, build := range a.Metadata.Builds {
+		if summary.Latest == nil || !build.StartedAt.Before(summary.Latest.StartedAt) {
+			summary.Latest = &a.Metadata.Builds[i]
+		}
+		if build.Status != BuildFailed {
+			continue
+		}
+		summary.Failed++
+		if a.MergedAt != nil && build.StartedAt.Before(*a.MergedAt) {
+			summary.FailedBeforeMerge++
+		}
+	}
+	return summary
+}
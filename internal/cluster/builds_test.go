@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttachBuilds(t *testing.T) {
+	opened := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	merged := opened.Add(48 * time.Hour)
+	artifacts := []Artifact{
+		{ID: "pr-1", Type: ArtifactPullRequest, Number: 12, CreatedAt: opened, MergedAt: &merged,
+			Metadata: ArtifactMetadata{HeadBranch: "fix-login", CommitSHAs: []string{"abc123"}}},
+		{ID: "issue-1", Type: ArtifactIssue, Number: 12, CreatedAt: opened},
+	}
+	builds := []Build{
+		{Provider: "jenkins", Name: "svc/PR-12", Number: 1, Status: BuildFailed, PullRequest: 12, StartedAt: opened.Add(2 * time.Hour)},
+		{Provider: "circleci", Name: "test", Number: 40, Status: BuildFailed, CommitSHA: "abc123", StartedAt: opened.Add(time.Hour)},
+		{Provider: "circleci", Name: "test", Number: 41, Status: BuildSuccess, Branch: "fix-login", StartedAt: opened.Add(24 * time.Hour)},
+		{Provider: "circleci", Name: "test", Number: 42, Status: BuildFailed, Branch: "fix-login", StartedAt: merged.Add(time.Hour)},
+		{Provider: "jenkins", Name: "svc/PR-13", Number: 1, Status: BuildFailed, PullRequest: 13, CommitSHA: "abc123", StartedAt: opened},
+	}
+
+	if matched := AttachBuilds(artifacts, builds); matched != 3 {
+		t.Errorf("Expected 3 matching builds, got %d", matched)
+	}
+	// Attaching the same builds again must not duplicate them
+	AttachBuilds(artifacts, builds)
+
+	pr := artifacts[0]
+	if len(pr.Metadata.Builds) != 3 || pr.Metadata.Builds[0].Number != 40 {
+		t.Fatalf("Expected 3 builds oldest first, got %+v", pr.Metadata.Builds)
+	}
+	if len(artifacts[1].Metadata.Builds) != 0 {
+		t.Errorf("Expected builds only on pull requests, got %+v", artifacts[1].Metadata.Builds)
+	}
+
+	summary := pr.GetBuildSummary()
+	if summary.Total != 3 || summary.Failed != 2 || summary.FailedBeforeMerge != 2 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.Latest == nil || summary.Latest.Status != BuildSuccess {
+		t.Errorf("Expected the latest build to be the successful one, got %+v", summary.Latest)
+	}
+}
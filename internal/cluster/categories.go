@@ -0,0 +1,249 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Category classifies the kind of work an episode represents
+type Category string
+
+const (
+	CategoryFeature  Category = "feature"
+	CategoryBugfix   Category = "bugfix"
+	CategoryRefactor Category = "refactor"
+	CategoryChore    Category = "chore"
+)
+
+// categories lists the categories in tie-breaking order
+var categories = []Category{CategoryFeature, CategoryBugfix, CategoryRefactor, CategoryChore}
+
+// conventionalCommitPattern matches a conventional commit prefix such as "feat(api)!: "
+var conventionalCommitPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?!?:\s`)
+
+// conventionalTypes maps conventional commit types to categories
+var conventionalTypes = map[string]Category{
+	"feat": CategoryFeature, "feature": CategoryFeature,
+	"fix": CategoryBugfix, "bugfix": CategoryBugfix, "hotfix": CategoryBugfix,
+	"refactor": CategoryRefactor, "perf": CategoryRefactor, "style": CategoryRefactor,
+	"chore": CategoryChore, "docs": CategoryChore, "doc": CategoryChore, "test": CategoryChore, "tests": CategoryChore,
+	"ci": CategoryChore, "build": CategoryChore, "deps": CategoryChore, "revert": CategoryChore, "release": CategoryChore,
+}
+
+// subjectVerbs maps the leading word of free-form commit subjects to categories
+var subjectVerbs = map[string]Category{
+	"add": CategoryFeature, "adds": CategoryFeature, "added": CategoryFeature, "implement": CategoryFeature,
+	"implements": CategoryFeature, "introduce": CategoryFeature, "support": CategoryFeature, "enable": CategoryFeature,
+	"fix": CategoryBugfix, "fixes": CategoryBugfix, "fixed": CategoryBugfix, "resolve": CategoryBugfix,
+	"correct": CategoryBugfix, "patch": CategoryBugfix,
+	"refactor": CategoryRefactor, "rename": CategoryRefactor, "simplify": CategoryRefactor, "clean": CategoryRefactor,
+	"cleanup": CategoryRefactor, "restructure": CategoryRefactor, "extract": CategoryRefactor, "move": CategoryRefactor,
+	"bump": CategoryChore, "upgrade": CategoryChore, "docs": CategoryChore, "document": CategoryChore, "release": CategoryChore,
+}
+
+// labelCategories maps issue and pull request labels (and ticket types) to categories
+var labelCategories = map[string]Category{
+	"feature": CategoryFeature, "enhancement": CategoryFeature, "new feature": CategoryFeature, "story": CategoryFeature,
+	"bug": CategoryBugfix, "defect": CategoryBugfix, "regression": CategoryBugfix, "bugfix": CategoryBugfix,
+	"refactor": CategoryRefactor, "refactoring": CategoryRefactor, "tech debt": CategoryRefactor,
+	"technical debt": CategoryRefactor, "cleanup": CategoryRefactor,
+	"chore": CategoryChore, "documentation": CategoryChore, "docs": CategoryChore, "dependencies": CategoryChore,
+	"ci": CategoryChore, "build": CategoryChore, "maintenance": CategoryChore,
+}
+
+// ClassifyEpisode assigns a category from the episode's own commits and artifacts, or "" when nothing matches
+// Conventional commit prefixes and artifact labels (or ticket types) count fully, the leading verb of
+// other commit subjects counts half; merge commits and releases are ignored
+func ClassifyEpisode(e *Episode) Category {
+	votes := make(map[Category]float64)
+
+	for _, commit := range e.GetPrimaryCommits() {
+		if commit.IsMerge {
+			continue
+		}
+		subject := commit.MessageSubject
+		if subject == "" {
+			subject, _, _ = strings.Cut(commit.Message, "\n")
+		}
+		if match := conventionalCommitPattern.FindStringSubmatch(subject); match != nil {
+			if category, ok := conventionalTypes[strings.ToLower(match[1])]; ok {
+				votes[category]++
+			}
+			continue
+		}
+		if fields := strings.Fields(subject); len(fields) > 0 {
+			if category, ok := subjectVerbs[strings.ToLower(strings.Trim(fields[0], ":,."))]; ok {
+				votes[category] += 0.5
+			}
+		}
+	}
+
+	for _, artifact := range e.Artifacts {
+		if artifact.Type == ArtifactRelease {
+			continue
+		}
+		labels := append([]string{artifact.Metadata.TicketType}, artifact.Labels...)
+		for _, label := range labels {
+			if category, ok := labelCategories[normalizeLabel(label)]; ok {
+				votes[category]++
+				break
+			}
+		}
+	}
+
+	var best Category
+	for _, category := range categories {
+		if votes[category] > votes[best] {
+			best = category
+		}
+	}
+	return best
+}
+
+// normalizeLabel lowercases a label and strips a scope such as "type: " or "kind/"
+func normalizeLabel(label string) string {
+	label = strings.ToLower(label)
+	if i := strings.LastIndexAny(label, ":/"); i >= 0 {
+		label = label[i+1:]
+	}
+	return strings.TrimSpace(strings.ReplaceAll(label, "-", " "))
+}
+
+// classifyEpisodes sets the rule-based category of episodes that have none yet
+func classifyEpisodes(episodes []Episode) {
+	for i := range episodes {
+		if episodes[i].Category == "" {
+			episodes[i].Category = ClassifyEpisode(&episodes[i])
+		}
+	}
+}
+
+// CategoryLLM generates text from a prompt (narrative.LLM satisfies it)
+type CategoryLLM interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+}
+
+// categoryPromptCommitLimit caps the commit subjects included in classification prompts
+const categoryPromptCommitLimit = 10
+
+// ClassifyUncategorized asks llm for the category of each episode the rules left without one
+// Replies that name no known category leave the episode uncategorized
+func ClassifyUncategorized(ctx context.Context, episodes []Episode, llm CategoryLLM) error {
+	for i := range episodes {
+		if episodes[i].Category != "" {
+			continue
+		}
+		reply, err := llm.Generate(ctx, categoryPrompt(&episodes[i]))
+		if err != nil {
+			return fmt.Errorf("failed to classify episode %s: %w", episodes[i].ID, err)
+		}
+		episodes[i].Category = parseCategoryReply(reply)
+	}
+	return nil
+}
+
+// categoryPrompt asks for a one-word category from the episode's commit subjects and artifact titles
+func categoryPrompt(e *Episode) string {
+	var b strings.Builder
+	b.WriteString("Classify this software development episode as exactly one of: feature, bugfix, refactor, chore.\n")
+	b.WriteString("Answer with the single word only.\n\n")
+
+	commits := e.GetPrimaryCommits()
+	if len(commits) > 0 {
+		b.WriteString("Commits:\n")
+		for i, commit := range commits {
+			if i >= categoryPromptCommitLimit {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(commits)-categoryPromptCommitLimit)
+				break
+			}
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			fmt.Fprintf(&b, "- %s\n", subject)
+		}
+	}
+	if len(e.Artifacts) > 0 {
+		b.WriteString("Issues and pull requests:\n")
+		for _, artifact := range e.Artifacts {
+			if len(artifact.Labels) > 0 {
+				fmt.Fprintf(&b, "- %s [%s]\n", artifact.Title, strings.Join(artifact.Labels, ", "))
+			} else {
+				fmt.Fprintf(&b, "- %s\n", artifact.Title)
+			}
+		}
+	}
+	return b.String()
+}
+
+// parseCategoryReply returns the first known category named in a reply, or ""
+func parseCategoryReply(reply string) Category {
+	for _, word := range strings.Fields(strings.ToLower(reply)) {
+		word = strings.Trim(word, ".,:;!\"'`*")
+		for _, category := range categories {
+			if word == string(category) {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+// CountCategories counts episodes per category; uncategorized episodes are not counted
+func CountCategories(episodes []Episode) map[Category]int {
+	counts := make(map[Category]int)
+	for _, episode := range episodes {
+		if episode.Category != "" {
+			counts[episode.Category]++
+		}
+	}
+	return counts
+}
+
+// FormatCategoryCounts describes category counts, e.g. "12 features, 30 bugfixes", or "" when all are zero
+func FormatCategoryCounts(counts map[Category]int) string {
+	var parts []string
+	for _, category := range categories {
+		count := counts[category]
+		if count == 0 {
+			continue
+		}
+		noun := string(category)
+		if count != 1 {
+			noun += "s"
+			if category == CategoryBugfix {
+				noun = "bugfixes"
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, noun))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// QuarterCategories holds the category counts of the episodes that started in one calendar quarter
+type QuarterCategories struct {
+	Quarter string // e.g. "2024 Q2"
+	Counts  map[Category]int
+}
+
+// CountCategoriesByQuarter counts categorized episodes per quarter of their start date, oldest first
+func CountCategoriesByQuarter(episodes []Episode) []QuarterCategories {
+	var quarters []QuarterCategories
+	index := make(map[string]int)
+	for i := range episodes {
+		start, _ := episodes[i].GetDateRange()
+		if episodes[i].Category == "" || start.IsZero() {
+			continue
+		}
+		quarter := fmt.Sprintf("%d Q%d", start.Year(), (int(start.Month())-1)/3+1)
+		j, ok := index[quarter]
+		if !ok {
+			j = len(quarters)
+			index[quarter] = j
+			quarters = append(quarters, QuarterCategories{Quarter: quarter, Counts: make(map[Category]int)})
+		}
+		quarters[j].Counts[episodes[i].Category]++
+	}
+	sort.Slice(quarters, func(i, j int) bool { return quarters[i].Quarter < quarters[j].Quarter })
+	return quarters
+}
@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestClassifyEpisode(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	commit := func(message string) git.Commit {
+		return createTestCommit("abc1234", message, author, base, []string{"main.go"})
+	}
+
+	tests := []struct {
+		name    string
+		episode Episode
+		want    Category
+	}{
+		{"conventional feature", Episode{Commits: []git.Commit{commit("feat(api): add search"), commit("fix typo")}}, CategoryFeature},
+		{"conventional breaking fix", Episode{Commits: []git.Commit{commit("fix!: drop nil check")}}, CategoryBugfix},
+		{"conventional docs", Episode{Commits: []git.Commit{commit("docs: explain config")}}, CategoryChore},
+		{"subject verb", Episode{Commits: []git.Commit{commit("Rename grouping helpers")}}, CategoryRefactor},
+		{"label outweighs verb", Episode{
+			Commits:   []git.Commit{commit("Add nil check")},
+			Artifacts: []Artifact{{ID: "issue-1", Type: ArtifactIssue, Labels: []string{"type: bug"}}},
+		}, CategoryBugfix},
+		{"ticket type", Episode{Artifacts: []Artifact{{ID: "ENG-1", Type: ArtifactTicket, Metadata: ArtifactMetadata{TicketType: "Story"}}}}, CategoryFeature},
+		{"nothing matches", Episode{Commits: []git.Commit{commit("WIP")}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyEpisode(&tt.episode); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGroupIntoEpisodes_SetsCategory(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	ra := &RepositoryActivity{Commits: []git.Commit{
+		createTestCommit("abc1234", "fix: handle empty input", author, base, []string{"main.go"}),
+	}}
+
+	episodes := ra.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 || episodes[0].Category != CategoryBugfix {
+		t.Fatalf("Expected one bugfix episode, got %+v", episodes)
+	}
+}
+
+// replyLLM answers every prompt with a fixed reply
+type replyLLM struct {
+	reply   string
+	err     error
+	prompts []string
+}
+
+func (l *replyLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	l.prompts = append(l.prompts, prompt)
+	return l.reply, l.err
+}
+
+func TestClassifyUncategorized(t *testing.T) {
+	episodes := []Episode{
+		{ID: "E1", Category: CategoryFeature},
+		{ID: "E2", Artifacts: []Artifact{{ID: "issue-2", Title: "Flaky upload", Labels: []string{"triage"}}}},
+	}
+
+	llm := &replyLLM{reply: "Bugfix."}
+	if err := ClassifyUncategorized(context.Background(), episodes, llm); err != nil {
+		t.Fatalf("ClassifyUncategorized failed: %v", err)
+	}
+	if len(llm.prompts) != 1 || !strings.Contains(llm.prompts[0], "- Flaky upload [triage]") {
+		t.Errorf("Expected one prompt describing the uncategorized episode, got %q", llm.prompts)
+	}
+	if episodes[0].Category != CategoryFeature || episodes[1].Category != CategoryBugfix {
+		t.Errorf("Expected feature and bugfix, got %q and %q", episodes[0].Category, episodes[1].Category)
+	}
+
+	episodes[1].Category = ""
+	if err := ClassifyUncategorized(context.Background(), episodes, &replyLLM{reply: "not sure"}); err != nil || episodes[1].Category != "" {
+		t.Errorf("Expected an unknown reply to leave the episode uncategorized, got %q (%v)", episodes[1].Category, err)
+	}
+	if err := ClassifyUncategorized(context.Background(), episodes, &replyLLM{err: errors.New("rate limited")}); err == nil {
+		t.Error("Expected the LLM error to be returned")
+	}
+}
+
+func TestFormatCategoryCounts(t *testing.T) {
+	got := FormatCategoryCounts(map[Category]int{CategoryBugfix: 30, CategoryFeature: 12, CategoryChore: 1})
+	if got != "12 features, 30 bugfixes, 1 chore" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	if got := FormatCategoryCounts(nil); got != "" {
+		t.Errorf("Expected an empty summary, got %q", got)
+	}
+}
+
+func TestCountCategoriesByQuarter(t *testing.T) {
+	episode := func(category Category, month time.Month) Episode {
+		return Episode{Category: category, Commits: []git.Commit{{CommittedAt: time.Date(2024, month, 10, 0, 0, 0, 0, time.UTC)}}}
+	}
+	episodes := []Episode{
+		episode(CategoryFeature, time.May),
+		episode(CategoryBugfix, time.January),
+		episode(CategoryBugfix, time.June),
+		episode("", time.June),
+	}
+
+	quarters := CountCategoriesByQuarter(episodes)
+	if len(quarters) != 2 {
+		t.Fatalf("Expected 2 quarters, got %d", len(quarters))
+	}
+	if quarters[0].Quarter != "2024 Q1" || quarters[0].Counts[CategoryBugfix] != 1 {
+		t.Errorf("Unexpected first quarter %+v", quarters[0])
+	}
+	if quarters[1].Quarter != "2024 Q2" || quarters[1].Counts[CategoryFeature] != 1 || quarters[1].Counts[CategoryBugfix] != 1 {
+		t.Errorf("Unexpected second quarter %+v", quarters[1])
+	}
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// LowCohesionThreshold is the cohesion below which an episode's grouping is considered loose
+const LowCohesionThreshold = 0.4
+
+// cohesionNeighborLimit bounds cohesion to pairs at most this many commits apart in time order,
+// so large episodes cost linear rather than quadratic scoring
+const cohesionNeighborLimit = 20
+
+// scoreCohesion sets each episode's Cohesion, the average similarity over pairs of its own commits,
+// and the Confidence of each of those commits, its score against the rest of the episode
+// Single-commit episodes are fully cohesive and confident; episodes without commits get neither
+// Episodes are scored concurrently in time partitions (see GroupingConfig.Workers)
+func scoreCohesion(episodes []Episode, config GroupingConfig, score episodeScorer) {
+	commits := 0
+	for i := range episodes {
+		commits += len(episodes[i].Commits)
+	}
+	partitions := timePartitions(len(episodes), min(len(episodes), config.partitionCount(commits)))
+	runPartitions(partitions, func(_ int, part partition) {
+		for i := part.start; i < part.end; i++ {
+			scoreEpisodeCohesion(&episodes[i], config, score)
+		}
+	})
+}
+
+// scoreEpisodeCohesion sets one episode's Cohesion and Confidence
+func scoreEpisodeCohesion(episode *Episode, config GroupingConfig, score episodeScorer) {
+	commits := episode.GetPrimaryCommits()
+	episode.Cohesion = 0
+	episode.Confidence = nil
+	if len(commits) == 0 {
+		return
+	}
+
+	episode.Confidence = make(map[string]float64, len(commits))
+	if len(commits) == 1 {
+		episode.Cohesion = 1
+		episode.Confidence[commits[0].Hash] = 1
+		return
+	}
+
+	total, pairs := 0.0, 0
+	for a := range commits {
+		for b := a + 1; b < len(commits) && b-a <= cohesionNeighborLimit; b++ {
+			total += pairSimilarity(commits[a], commits[b], episode.Artifacts, config, score)
+			pairs++
+		}
+	}
+	episode.Cohesion = total / float64(pairs)
+
+	for c, commit := range commits {
+		rest := make([]git.Commit, 0, len(commits)-1)
+		rest = append(rest, commits[:c]...)
+		rest = append(rest, commits[c+1:]...)
+
+		// Score against the rest of the episode as if the nearest commit were its latest
+		nearest := nearestCommit(rest, commit)
+		view := Episode{Commits: make([]git.Commit, 0, len(rest)), Artifacts: episode.Artifacts}
+		view.Commits = append(view.Commits, rest[:nearest]...)
+		view.Commits = append(view.Commits, rest[nearest+1:]...)
+		view.Commits = append(view.Commits, rest[nearest])
+		episode.Confidence[commit.Hash] = score(&view, commit, config)
+	}
+}
+
+// pairSimilarity scores two commits of an episode against each other, taking the higher direction
+func pairSimilarity(a, b git.Commit, artifacts []Artifact, config GroupingConfig, score episodeScorer) float64 {
+	forward := score(&Episode{Commits: []git.Commit{a}, Artifacts: artifacts}, b, config)
+	backward := score(&Episode{Commits: []git.Commit{b}, Artifacts: artifacts}, a, config)
+	return max(forward, backward)
+}
+
+// IsLowCohesion reports whether an episode with several commits of its own is loosely grouped
+func (e *Episode) IsLowCohesion() bool {
+	return len(e.Confidence) > 1 && e.Cohesion < LowCohesionThreshold
+}
+
+// GetLowConfidenceCommits returns the episode's own commits whose confidence is below threshold
+func (e *Episode) GetLowConfidenceCommits(threshold float64) []git.Commit {
+	var commits []git.Commit
+	for _, commit := range e.GetPrimaryCommits() {
+		if confidence, ok := e.Confidence[commit.Hash]; ok && confidence < threshold {
+			commits = append(commits, commit)
+		}
+	}
+	return commits
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestScoreCohesion(t *testing.T) {
+	base := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+
+	tight := Episode{Commits: []git.Commit{
+		createTestCommit("aaaaaaa1", "Add parser", alice, base, []string{"parser.go"}),
+		createTestCommit("aaaaaaa2", "Extend parser", alice, base.Add(10*time.Minute), []string{"parser.go"}),
+		createTestCommit("aaaaaaa3", "Test parser", alice, base.Add(20*time.Minute), []string{"parser.go"}),
+	}}
+	loose := Episode{Commits: []git.Commit{
+		createTestCommit("bbbbbbb1", "Add parser", alice, base, []string{"parser.go"}),
+		createTestCommit("bbbbbbb2", "Extend parser", alice, base.Add(10*time.Minute), []string{"parser.go"}),
+		createTestCommit("bbbbbbb3", "Update logo", bob, base.Add(20*time.Hour), []string{"assets/logo.svg"}),
+	}}
+	single := Episode{Commits: []git.Commit{createTestCommit("ccccccc1", "Bump version", alice, base, []string{"VERSION"})}}
+	empty := Episode{Artifacts: []Artifact{{ID: "issue-1"}}}
+
+	episodes := []Episode{tight, loose, single, empty}
+	scoreCohesion(episodes, DefaultGroupingConfig(), calculateEpisodeSimilarity)
+
+	if episodes[0].Cohesion <= episodes[1].Cohesion {
+		t.Errorf("Expected the tight episode to be more cohesive, got %f and %f", episodes[0].Cohesion, episodes[1].Cohesion)
+	}
+	if episodes[0].IsLowCohesion() || !episodes[1].IsLowCohesion() {
+		t.Errorf("Expected only the loose episode to be flagged, got cohesion %f and %f", episodes[0].Cohesion, episodes[1].Cohesion)
+	}
+
+	outliers := episodes[1].GetLowConfidenceCommits(DefaultGroupingConfig().MinSimilarityScore)
+	if len(outliers) != 1 || outliers[0].Hash != "bbbbbbb3" {
+		t.Errorf("Expected the logo commit to be the only low-confidence member, got %+v", episodes[1].Confidence)
+	}
+
+	if episodes[2].Cohesion != 1 || episodes[2].Confidence["ccccccc1"] != 1 || episodes[2].IsLowCohesion() {
+		t.Errorf("Expected a single commit to be fully cohesive, got %f %+v", episodes[2].Cohesion, episodes[2].Confidence)
+	}
+	if episodes[3].Cohesion != 0 || episodes[3].Confidence != nil {
+		t.Errorf("Expected no scores without commits, got %f %+v", episodes[3].Cohesion, episodes[3].Confidence)
+	}
+}
+
+func TestGroupIntoEpisodes_ScoresCohesion(t *testing.T) {
+	base := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	ra := &RepositoryActivity{Commits: []git.Commit{
+		createTestCommit("aaaaaaa1", "Add parser", author, base, []string{"parser.go"}),
+		createTestCommit("aaaaaaa2", "Extend parser", author, base.Add(time.Hour), []string{"parser.go"}),
+	}}
+
+	episodes := ra.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 {
+		t.Fatalf("Expected 1 episode, got %d", len(episodes))
+	}
+	if episodes[0].Cohesion <= 0 || len(episodes[0].Confidence) != 2 {
+		t.Errorf("Expected cohesion and confidence for both commits, got %f %+v", episodes[0].Cohesion, episodes[0].Confidence)
+	}
+}
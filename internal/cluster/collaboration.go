@@ -0,0 +1,161 @@
+package cluster
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// Collaboration records one person's review feedback on another's commits within an episode
+type Collaboration struct {
+	Author   string `json:"author"`             // Wrote the commits
+	Reviewer string `json:"reviewer"`           // Reviewed or commented on them
+	Reviews  int    `json:"reviews"`            // Reviews and review thread comments
+	Comments int    `json:"comments"`           // Conversation comments and notes
+	Approved bool   `json:"approved,omitempty"` // Reviewer approved at least once
+}
+
+// Interactions returns the reviewer's total reviews and comments
+func (c Collaboration) Interactions() int {
+	return c.Reviews + c.Comments
+}
+
+// CollaborationGraph links the episode's commit authors to the people who reviewed their work
+type CollaborationGraph struct {
+	Authors []string        `json:"authors"` // Commit authors, sorted by name
+	Pairs   []Collaboration `json:"pairs"`   // Most interactions first
+}
+
+// GetCollaborationGraph returns who reviewed or commented on whose commits within the episode
+// Feedback on a pull or merge request is credited to the authors of the episode's commits on it
+// (by review commit, listed SHA or merging PR number), falling back to the request's author.
+// Self-reviews, process events and discussions on issues are ignored
+func (e *Episode) GetCollaborationGraph() CollaborationGraph {
+	graph := CollaborationGraph{Authors: make([]string, 0), Pairs: make([]Collaboration, 0)}
+
+	authorSet := make(map[string]bool)
+	for _, commit := range e.Commits {
+		if name := personName(commit.Author); name != "" {
+			authorSet[name] = true
+		}
+	}
+	for name := range authorSet {
+		graph.Authors = append(graph.Authors, name)
+	}
+	sort.Strings(graph.Authors)
+
+	commitsByHash := make(map[string]git.Commit, len(e.Commits))
+	for _, commit := range e.Commits {
+		commitsByHash[commit.Hash] = commit
+	}
+
+	pairs := make(map[[2]string]*Collaboration)
+	for i := range e.Artifacts {
+		artifact := &e.Artifacts[i]
+		if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+			continue
+		}
+		authors := requestAuthors(artifact, e.Commits)
+
+		for _, discussion := range artifact.Discussions {
+			if discussion.Type == DiscussionEvent {
+				continue
+			}
+			reviewer := personName(discussion.Author)
+			if reviewer == "" {
+				continue
+			}
+
+			targets := authors
+			if commit, ok := commitsByHash[discussion.CommitHash]; ok {
+				targets = []git.Author{commit.Author}
+			}
+			for _, author := range targets {
+				if samePerson(author, discussion.Author) {
+					continue
+				}
+				key := [2]string{personName(author), reviewer}
+				pair, ok := pairs[key]
+				if !ok {
+					pair = &Collaboration{Author: key[0], Reviewer: reviewer}
+					pairs[key] = pair
+				}
+				switch discussion.Type {
+				case DiscussionReview, DiscussionReviewThread:
+					pair.Reviews++
+				default:
+					pair.Comments++
+				}
+				if strings.EqualFold(discussion.ReviewState, "approved") {
+					pair.Approved = true
+				}
+			}
+		}
+	}
+
+	for _, pair := range pairs {
+		graph.Pairs = append(graph.Pairs, *pair)
+	}
+	sort.Slice(graph.Pairs, func(i, j int) bool {
+		a, b := graph.Pairs[i], graph.Pairs[j]
+		if a.Interactions() != b.Interactions() {
+			return a.Interactions() > b.Interactions()
+		}
+		if a.Author != b.Author {
+			return a.Author < b.Author
+		}
+		return a.Reviewer < b.Reviewer
+	})
+
+	return graph
+}
+
+// GetReviewers returns the people who reviewed or commented on the author's commits, most active first
+func (g CollaborationGraph) GetReviewers(author string) []string {
+	reviewers := make([]string, 0)
+	for _, pair := range g.Pairs {
+		if pair.Author == author {
+			reviewers = append(reviewers, pair.Reviewer)
+		}
+	}
+	return reviewers
+}
+
+// requestAuthors returns the distinct authors of the episode's commits on a pull or merge request,
+// or the request's own author when none of its commits are in the episode
+func requestAuthors(artifact *Artifact, commits []git.Commit) []git.Author {
+	authors := make([]git.Author, 0)
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		onRequest := commit.Hash == artifact.Metadata.MergeCommitSHA ||
+			(artifact.Number > 0 && commit.PullRequestNumber == artifact.Number) ||
+			pullRequestContainsCommit(artifact, commit.Hash)
+		name := personName(commit.Author)
+		if !onRequest || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		authors = append(authors, commit.Author)
+	}
+	if len(authors) == 0 && personName(artifact.Author) != "" {
+		authors = append(authors, artifact.Author)
+	}
+	return authors
+}
+
+// personName returns the name to show for an author, falling back to the email address
+func personName(author git.Author) string {
+	if author.Name != "" {
+		return author.Name
+	}
+	return author.Email
+}
+
+// samePerson reports whether two authors share a name or an email address
+func samePerson(a, b git.Author) bool {
+	if a.Name != "" && strings.EqualFold(a.Name, b.Name) {
+		return true
+	}
+	return a.Email != "" && strings.EqualFold(a.Email, b.Email)
+}
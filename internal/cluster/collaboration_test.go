@@ -0,0 +1,81 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestGetCollaborationGraph(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	carol := git.Author{Name: "Carol", Email: "carol@example.com"}
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	first := createTestCommit("aaaaaaa1", "Add exporter", alice, base, []string{"export.go"})
+	first.PullRequestNumber = 7
+	second := createTestCommit("ccccccc3", "Fix exporter test", carol, base.Add(time.Hour), []string{"export_test.go"})
+
+	episode := &Episode{
+		ID:      "E1",
+		Commits: []git.Commit{first, second},
+		Artifacts: []Artifact{
+			{
+				Type:   ArtifactPullRequest,
+				Number: 7,
+				Author: alice,
+				Metadata: ArtifactMetadata{
+					CommitSHAs: []string{"aaaaaaa1", "ccccccc3"},
+				},
+				Discussions: []Discussion{
+					{ID: "r1", Type: DiscussionReview, Author: git.Author{Name: "Bob"}, ReviewState: "approved"},
+					{ID: "t1", Type: DiscussionReviewThread, Author: git.Author{Name: "Bob"}, CommitHash: "ccccccc3"},
+					{ID: "c1", Type: DiscussionComment, Author: git.Author{Name: "alice", Email: "alice@example.com"}},
+					{ID: "e1", Type: DiscussionEvent, Author: git.Author{Name: "Dave"}},
+				},
+			},
+			{
+				Type:        ArtifactIssue,
+				Number:      3,
+				Discussions: []Discussion{{ID: "i1", Type: DiscussionComment, Author: git.Author{Name: "Eve"}}},
+			},
+		},
+	}
+
+	graph := episode.GetCollaborationGraph()
+	if want := []string{"Alice", "Carol"}; !reflect.DeepEqual(graph.Authors, want) {
+		t.Errorf("Authors = %v, want %v", graph.Authors, want)
+	}
+
+	want := []Collaboration{
+		{Author: "Carol", Reviewer: "Bob", Reviews: 2, Approved: true},
+		{Author: "Alice", Reviewer: "Bob", Reviews: 1, Approved: true},
+		{Author: "Carol", Reviewer: "alice", Comments: 1},
+	}
+	if !reflect.DeepEqual(graph.Pairs, want) {
+		t.Errorf("Pairs = %+v, want %+v", graph.Pairs, want)
+	}
+	if reviewers := graph.GetReviewers("Carol"); !reflect.DeepEqual(reviewers, []string{"Bob", "alice"}) {
+		t.Errorf("GetReviewers(Carol) = %v", reviewers)
+	}
+}
+
+func TestGetCollaborationGraph_FallsBackToRequestAuthor(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	episode := &Episode{
+		ID: "E1",
+		Artifacts: []Artifact{{
+			Type:        ArtifactMergeRequest,
+			Number:      12,
+			Author:      alice,
+			Discussions: []Discussion{{ID: "n1", Type: DiscussionNote, Author: git.Author{Name: "Bob"}}},
+		}},
+	}
+
+	graph := episode.GetCollaborationGraph()
+	want := []Collaboration{{Author: "Alice", Reviewer: "Bob", Comments: 1}}
+	if !reflect.DeepEqual(graph.Pairs, want) {
+		t.Errorf("Pairs = %+v, want %+v", graph.Pairs, want)
+	}
+}
@@ -0,0 +1,177 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EpisodeChangeKind describes how episodes changed between two clustering runs
+type EpisodeChangeKind string
+
+const (
+	ChangeAdded     EpisodeChangeKind = "added"     // New episode sharing no members with the old run
+	ChangeRemoved   EpisodeChangeKind = "removed"   // Old episode sharing no members with the new run
+	ChangeMerged    EpisodeChangeKind = "merged"    // Several old episodes became one
+	ChangeSplit     EpisodeChangeKind = "split"     // One old episode became several
+	ChangeRegrouped EpisodeChangeKind = "regrouped" // Several old episodes were redistributed over several new ones
+	ChangeModified  EpisodeChangeKind = "modified"  // One old episode became one new episode with different members
+)
+
+// EpisodeChange is one group of old episodes that became a group of new episodes
+type EpisodeChange struct {
+	Kind EpisodeChangeKind `json:"kind"`
+	Old  []string          `json:"old,omitempty"` // Old episode IDs
+	New  []string          `json:"new,omitempty"` // New episode IDs
+}
+
+// EpisodeSetDiff reports how a clustering run differs from a previous one
+type EpisodeSetDiff struct {
+	Unchanged int             `json:"unchanged"` // Episodes with identical members in both runs
+	Changes   []EpisodeChange `json:"changes"`
+}
+
+// DiffEpisodeSets compares two clustering runs of the same history, e.g. before and after a
+// GroupingConfig change. Episodes are matched by their members: their own commits, or their
+// artifacts when they have no commits. Old and new episodes sharing members, directly or
+// through other episodes, form one change, so a commit moving between episodes shows up as a
+// single merge, split or regrouping. Episode IDs only identify episodes in the report
+func DiffEpisodeSets(before, after []Episode) EpisodeSetDiff {
+	oldMembers := make([]map[string]bool, len(before))
+	for i := range before {
+		oldMembers[i] = diffMembers(&before[i])
+	}
+	newMembers := make([]map[string]bool, len(after))
+	for i := range after {
+		newMembers[i] = diffMembers(&after[i])
+	}
+
+	// Nodes 0..len(before)-1 are old episodes, the rest new ones
+	sets := newDisjointSet(len(before) + len(after))
+	owner := make(map[string]int)
+	for i, members := range oldMembers {
+		for member := range members {
+			owner[member] = i
+		}
+	}
+	for j, members := range newMembers {
+		for member := range members {
+			if i, ok := owner[member]; ok {
+				sets.union(i, len(before)+j)
+			}
+		}
+	}
+
+	groups := make(map[int]*EpisodeChange)
+	var roots []int
+	oldIndex := make(map[int][]int)
+	newIndex := make(map[int][]int)
+	for node := 0; node < len(before)+len(after); node++ {
+		root := sets.find(node)
+		change, ok := groups[root]
+		if !ok {
+			change = &EpisodeChange{}
+			groups[root] = change
+			roots = append(roots, root)
+		}
+		if node < len(before) {
+			change.Old = append(change.Old, before[node].ID)
+			oldIndex[root] = append(oldIndex[root], node)
+		} else {
+			change.New = append(change.New, after[node-len(before)].ID)
+			newIndex[root] = append(newIndex[root], node-len(before))
+		}
+	}
+
+	diff := EpisodeSetDiff{Changes: make([]EpisodeChange, 0)}
+	for _, root := range roots {
+		change := groups[root]
+		switch {
+		case len(change.Old) == 0:
+			change.Kind = ChangeAdded
+		case len(change.New) == 0:
+			change.Kind = ChangeRemoved
+		case len(change.Old) == 1 && len(change.New) == 1:
+			o, n := oldIndex[root][0], newIndex[root][0]
+			if sameMembers(oldMembers[o], newMembers[n]) && sameArtifacts(&before[o], &after[n]) {
+				diff.Unchanged++
+				continue
+			}
+			change.Kind = ChangeModified
+		case len(change.New) == 1:
+			change.Kind = ChangeMerged
+		case len(change.Old) == 1:
+			change.Kind = ChangeSplit
+		default:
+			change.Kind = ChangeRegrouped
+		}
+		diff.Changes = append(diff.Changes, *change)
+	}
+
+	return diff
+}
+
+// Count returns the number of changes of the given kind
+func (d EpisodeSetDiff) Count(kind EpisodeChangeKind) int {
+	count := 0
+	for _, change := range d.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// String summarizes the diff, e.g. "12 unchanged, 2 merged, 1 split"
+func (d EpisodeSetDiff) String() string {
+	parts := []string{fmt.Sprintf("%d unchanged", d.Unchanged)}
+	for _, kind := range []EpisodeChangeKind{ChangeModified, ChangeMerged, ChangeSplit, ChangeRegrouped, ChangeAdded, ChangeRemoved} {
+		if count := d.Count(kind); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, kind))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffMembers returns the keys an episode is matched by: its own commits, or its artifacts without commits
+func diffMembers(episode *Episode) map[string]bool {
+	members := make(map[string]bool)
+	for _, commit := range episode.GetPrimaryCommits() {
+		members["commit:"+commit.Hash] = true
+	}
+	if len(members) > 0 {
+		return members
+	}
+	for _, artifact := range episode.Artifacts {
+		if !episode.IsShared(artifact.ID) {
+			members["artifact:"+artifact.ID] = true
+		}
+	}
+	return members
+}
+
+// sameMembers reports whether two member sets are equal
+func sameMembers(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for member := range a {
+		if !b[member] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameArtifacts reports whether two episodes hold the same artifacts, regardless of order
+func sameArtifacts(a, b *Episode) bool {
+	ids := func(episode *Episode) []string {
+		result := make([]string, len(episode.Artifacts))
+		for i, artifact := range episode.Artifacts {
+			result[i] = artifact.ID
+		}
+		sort.Strings(result)
+		return result
+	}
+	return strings.Join(ids(a), "\x00") == strings.Join(ids(b), "\x00")
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// diffEpisode builds an episode holding commits with the given hashes
+func diffEpisode(id string, hashes ...string) Episode {
+	commits := make([]git.Commit, len(hashes))
+	for i, hash := range hashes {
+		commits[i] = git.Commit{Hash: hash}
+	}
+	return Episode{ID: id, Commits: commits}
+}
+
+func TestDiffEpisodeSets(t *testing.T) {
+	before := []Episode{
+		diffEpisode("A", "c1", "c2"),
+		diffEpisode("B", "c3"),
+		diffEpisode("C", "c4"),
+		diffEpisode("D", "c5", "c6"),
+		diffEpisode("E", "c7", "c8"),
+		diffEpisode("F", "c9", "c10"),
+		diffEpisode("G", "c11", "c12"),
+		diffEpisode("H", "c13"),
+		{ID: "I", Artifacts: []Artifact{{ID: "issue-1"}}},
+	}
+	after := []Episode{
+		diffEpisode("a", "c1", "c2"),
+		diffEpisode("bc", "c3", "c4"),
+		diffEpisode("d1", "c5"),
+		diffEpisode("d2", "c6"),
+		diffEpisode("e", "c7"),
+		diffEpisode("fg1", "c9", "c11"),
+		diffEpisode("fg2", "c10", "c12"),
+		diffEpisode("x", "c14"),
+		{ID: "i", Artifacts: []Artifact{{ID: "issue-1"}}},
+	}
+
+	diff := DiffEpisodeSets(before, after)
+	if diff.Unchanged != 2 {
+		t.Errorf("Unchanged = %d, want 2", diff.Unchanged)
+	}
+
+	want := []EpisodeChange{
+		{Kind: ChangeMerged, Old: []string{"B", "C"}, New: []string{"bc"}},
+		{Kind: ChangeSplit, Old: []string{"D"}, New: []string{"d1", "d2"}},
+		{Kind: ChangeModified, Old: []string{"E"}, New: []string{"e"}},
+		{Kind: ChangeRegrouped, Old: []string{"F", "G"}, New: []string{"fg1", "fg2"}},
+		{Kind: ChangeRemoved, Old: []string{"H"}},
+		{Kind: ChangeAdded, New: []string{"x"}},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("Changes =\n%+v\nwant\n%+v", diff.Changes, want)
+	}
+
+	if got := diff.String(); got != "2 unchanged, 1 modified, 1 merged, 1 split, 1 regrouped, 1 added, 1 removed" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestDiffEpisodeSets_ArtifactChanges(t *testing.T) {
+	before := []Episode{diffEpisode("A", "c1")}
+	after := []Episode{diffEpisode("A", "c1")}
+	after[0].Artifacts = []Artifact{{ID: "pr-1"}}
+
+	diff := DiffEpisodeSets(before, after)
+	if diff.Unchanged != 0 || diff.Count(ChangeModified) != 1 {
+		t.Errorf("linking a PR should modify the episode, got %s", diff)
+	}
+}
+
+func TestDiffEpisodeSets_Identical(t *testing.T) {
+	episodes := []Episode{diffEpisode("A", "c1"), diffEpisode("B", "c2", "c3")}
+
+	diff := DiffEpisodeSets(episodes, episodes)
+	if diff.Unchanged != 2 || len(diff.Changes) != 0 {
+		t.Errorf("identical runs should have no changes, got %s", diff)
+	}
+}
@@ -12,7 +12,7 @@ func (e *Episode) GetCommitAuthors() []git.Author {
 	// Discover unique authors from commits
 	authorMap := make(map[string]git.Author)
 	for _, commit := range e.Commits {
-		key := commit.Author.Email
+		key := git.AuthorKey(commit.Author)
 		if _, exists := authorMap[key]; !exists {
 			authorMap[key] = commit.Author
 		}
@@ -195,3 +195,147 @@ func (e *Episode) GetFileCount() int {
 
 	return len(fileSet)
 }
+
+// GetLanguages aggregates the changed lines per language across all commits
+func (e *Episode) GetLanguages() map[string]int {
+	languages := make(map[string]int)
+	for _, commit := range e.Commits {
+		for language, lines := range commit.Languages {
+			languages[language] += lines
+		}
+	}
+	return languages
+}
+
+// GetPrimaryLanguages returns up to n languages ordered by changed lines, most first
+// Languages below 10% of the episode's changes are omitted unless nothing else qualifies
+func (e *Episode) GetPrimaryLanguages(n int) []string {
+	languages := e.GetLanguages()
+
+	total := 0
+	names := make([]string, 0, len(languages))
+	for language, lines := range languages {
+		total += lines
+		names = append(names, language)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if languages[names[i]] != languages[names[j]] {
+			return languages[names[i]] > languages[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	primary := make([]string, 0, n)
+	for _, language := range names {
+		if len(primary) >= n {
+			break
+		}
+		if len(primary) > 0 && languages[language]*10 < total {
+			break
+		}
+		primary = append(primary, language)
+	}
+	return primary
+}
+
+// GetUnverifiedCommits returns the episode's commits that are unsigned or whose signature
+// was not verified against a trusted key
+func (e *Episode) GetUnverifiedCommits() []git.Commit {
+	unverified := make([]git.Commit, 0)
+	for _, commit := range e.Commits {
+		if !commit.Signed || !commit.SignatureValid {
+			unverified = append(unverified, commit)
+		}
+	}
+	return unverified
+}
+
+// GetReviewThreads returns the opening comment of each of the artifact's code review threads
+func (a *Artifact) GetReviewThreads() []Discussion {
+	threads := make([]Discussion, 0)
+	for _, d := range a.Discussions {
+		if d.Type == DiscussionReviewThread && d.ParentID == "" {
+			threads = append(threads, d)
+		}
+	}
+	return threads
+}
+
+// GetOpenReviewThreads returns the artifact's review threads that were not marked resolved
+func (a *Artifact) GetOpenReviewThreads() []Discussion {
+	open := make([]Discussion, 0)
+	for _, thread := range a.GetReviewThreads() {
+		if !thread.Resolved {
+			open = append(open, thread)
+		}
+	}
+	return open
+}
+
+// MembershipScore returns how strongly a commit (by hash) or artifact (by ID) belongs to the episode:
+// 1 for primary members, the recorded score for members shared from other episodes, and 0 otherwise
+func (e *Episode) MembershipScore(id string) float64 {
+	if score, shared := e.Memberships[id]; shared {
+		return score
+	}
+	for _, commit := range e.Commits {
+		if commit.Hash == id {
+			return 1
+		}
+	}
+	if e.hasArtifact(id) {
+		return 1
+	}
+	return 0
+}
+
+// IsShared reports whether a commit or artifact was shared into the episode from another episode
+func (e *Episode) IsShared(id string) bool {
+	_, shared := e.Memberships[id]
+	return shared
+}
+
+// GetPrimaryCommits returns the commits grouped into the episode itself, without shared ones
+func (e *Episode) GetPrimaryCommits() []git.Commit {
+	primary := make([]git.Commit, 0, len(e.Commits))
+	for _, commit := range e.Commits {
+		if !e.IsShared(commit.Hash) {
+			primary = append(primary, commit)
+		}
+	}
+	return primary
+}
+
+// GetSharedCommits returns the commits shared into the episode from other episodes
+func (e *Episode) GetSharedCommits() []git.Commit {
+	shared := make([]git.Commit, 0)
+	for _, commit := range e.Commits {
+		if e.IsShared(commit.Hash) {
+			shared = append(shared, commit)
+		}
+	}
+	return shared
+}
+
+// CountUniqueCommits counts distinct commits across episodes, so commits shared by several count once
+func CountUniqueCommits(episodes []Episode) int {
+	seen := make(map[string]bool)
+	for _, episode := range episodes {
+		for _, commit := range episode.Commits {
+			seen[commit.Hash] = true
+		}
+	}
+	return len(seen)
+}
+
+// CommitEpisodes maps each commit hash to the IDs of the episodes containing it, in episode order
+func CommitEpisodes(episodes []Episode) map[string][]string {
+	membership := make(map[string][]string)
+	for _, episode := range episodes {
+		for _, commit := range episode.Commits {
+			membership[commit.Hash] = append(membership[commit.Hash], episode.ID)
+		}
+	}
+	return membership
+}
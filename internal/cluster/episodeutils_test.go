@@ -903,3 +903,68 @@ func TestEpisode_GetDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestEpisode_GetUnverifiedCommits(t *testing.T) {
+	episode := &Episode{
+		ID: "test-episode",
+		Commits: []git.Commit{
+			{Hash: "signed", Signed: true, SignatureValid: true},
+			{Hash: "untrusted", Signed: true, SignatureValid: false},
+			{Hash: "unsigned"},
+		},
+	}
+
+	unverified := episode.GetUnverifiedCommits()
+	if len(unverified) != 2 {
+		t.Fatalf("Expected 2 unverified commits, got %d", len(unverified))
+	}
+	if unverified[0].Hash != "untrusted" || unverified[1].Hash != "unsigned" {
+		t.Errorf("Unexpected unverified commits: %s, %s", unverified[0].Hash, unverified[1].Hash)
+	}
+}
+
+func TestEpisode_GetPrimaryLanguages(t *testing.T) {
+	episode := &Episode{
+		ID: "test-episode",
+		Commits: []git.Commit{
+			{Hash: "a", Languages: map[string]int{"Go": 80, "YAML": 2}},
+			{Hash: "b", Languages: map[string]int{"Go": 20, "SQL": 30}},
+		},
+	}
+
+	languages := episode.GetLanguages()
+	if languages["Go"] != 100 || languages["SQL"] != 30 || languages["YAML"] != 2 {
+		t.Errorf("Unexpected language totals: %v", languages)
+	}
+
+	// YAML is below the 10% threshold
+	primary := episode.GetPrimaryLanguages(3)
+	if len(primary) != 2 || primary[0] != "Go" || primary[1] != "SQL" {
+		t.Errorf("Expected [Go SQL], got %v", primary)
+	}
+
+	if primary := episode.GetPrimaryLanguages(1); len(primary) != 1 || primary[0] != "Go" {
+		t.Errorf("Expected [Go], got %v", primary)
+	}
+
+	if primary := (&Episode{}).GetPrimaryLanguages(3); len(primary) != 0 {
+		t.Errorf("Expected no languages for empty episode, got %v", primary)
+	}
+}
+
+func TestArtifact_GetReviewThreads(t *testing.T) {
+	artifact := Artifact{Discussions: []Discussion{
+		{ID: "review-comment-1", Type: DiscussionReviewThread, ThreadID: "review-comment-1", Resolved: true},
+		{ID: "review-comment-2", Type: DiscussionReviewThread, ParentID: "review-comment-1", ThreadID: "review-comment-1", Resolved: true},
+		{ID: "review-comment-3", Type: DiscussionReviewThread, ThreadID: "review-comment-3"},
+		{ID: "comment-4", Type: DiscussionComment},
+	}}
+
+	if threads := artifact.GetReviewThreads(); len(threads) != 2 {
+		t.Errorf("Expected 2 threads (replies excluded), got %d", len(threads))
+	}
+	open := artifact.GetOpenReviewThreads()
+	if len(open) != 1 || open[0].ID != "review-comment-3" {
+		t.Errorf("Expected only review-comment-3 open, got %+v", open)
+	}
+}
@@ -19,20 +19,40 @@ const (
 
 // EpisodeExport represents an episode with enrichment counts for export
 type EpisodeExport struct {
-	ID           string       `json:"id"`
-	CommitCount  int          `json:"commit_count"`
-	AuthorCount  int          `json:"author_count"`
-	PRCount      int          `json:"pr_count"`
-	IssueCount   int          `json:"issue_count"`
-	StartDate    time.Time    `json:"start_date"`
-	EndDate      time.Time    `json:"end_date"`
-	Duration     string       `json:"duration"`
-	Authors      []string     `json:"authors"`
-	CommitHashes []string     `json:"commit_hashes"`
-	Commits      []git.Commit `json:"commits"`
-	Artifacts    []Artifact   `json:"artifacts"`
+	ID           string        `json:"id"`
+	Repository   string        `json:"repository,omitempty"`
+	Category     Category      `json:"category,omitempty"`
+	CommitCount  int           `json:"commit_count"`
+	AuthorCount  int           `json:"author_count"`
+	PRCount      int           `json:"pr_count"`
+	IssueCount   int           `json:"issue_count"`
+	Releases     []string      `json:"releases,omitempty"` // Tags of the releases that shipped this episode
+	StartDate    time.Time     `json:"start_date"`
+	EndDate      time.Time     `json:"end_date"`
+	Duration     string        `json:"duration"`
+	Authors      []string      `json:"authors"`
+	CommitHashes []string      `json:"commit_hashes"`
+	Commits      []git.Commit  `json:"commits"`
+	Artifacts    []Artifact    `json:"artifacts"`
+	Hotspots     []FileHotspot `json:"hotspots,omitempty"` // Most frequently changed files in the episode
+
+	// Scores of commits and artifacts shared from other episodes, keyed by hash or ID
+	Memberships map[string]float64 `json:"memberships,omitempty"`
+
+	// Average pairwise similarity of the episode's own commits and each one's assignment confidence
+	Cohesion   float64            `json:"cohesion,omitempty"`
+	Confidence map[string]float64 `json:"confidence,omitempty"`
+
+	// Size and shape of the episode (additions, top files and directories, review comments, ...)
+	Stats EpisodeStats `json:"stats"`
+
+	// Who reviewed or commented on whose commits
+	Collaboration []Collaboration `json:"collaboration,omitempty"`
 }
 
+// exportHotspotLimit caps the hotspots included per exported episode
+const exportHotspotLimit = 5
+
 // ExportEpisodes exports episodes in JSON format
 func ExportEpisodes(episodes []Episode, format string, writer io.Writer) error {
 	exportFormat := ExportFormat(strings.ToLower(format))
@@ -40,7 +60,7 @@ func ExportEpisodes(episodes []Episode, format string, writer io.Writer) error {
 	// Convert episodes to export format with enrichment
 	exports := make([]EpisodeExport, len(episodes))
 	for i, ep := range episodes {
-		exports[i] = enrichEpisode(ep)
+		exports[i] = EnrichEpisode(ep)
 	}
 
 	if exportFormat != FormatJSON {
@@ -50,8 +70,8 @@ func ExportEpisodes(episodes []Episode, format string, writer io.Writer) error {
 	return exportJSON(exports, writer)
 }
 
-// enrichEpisode converts an Episode to EpisodeExport with calculated enrichments
-func enrichEpisode(ep Episode) EpisodeExport {
+// EnrichEpisode converts an Episode to EpisodeExport with calculated enrichments
+func EnrichEpisode(ep Episode) EpisodeExport {
 	authorNames := ep.GetAuthorNames()
 
 	commitHashes := make([]string, len(ep.Commits))
@@ -71,21 +91,35 @@ func enrichEpisode(ep Episode) EpisodeExport {
 		}
 	}
 
+	releases := make([]string, 0)
+	for _, release := range ep.GetReleases() {
+		releases = append(releases, release.Metadata.TagName)
+	}
+
 	startDate, endDate := ep.GetDateRange()
 
 	return EpisodeExport{
-		ID:           ep.ID,
-		CommitCount:  len(ep.Commits),
-		AuthorCount:  len(authorNames),
-		PRCount:      prCount,
-		IssueCount:   issueCount,
-		StartDate:    startDate,
-		EndDate:      endDate,
-		Duration:     ep.GetDuration().String(),
-		Authors:      authorNames,
-		CommitHashes: commitHashes,
-		Commits:      ep.Commits,
-		Artifacts:    ep.Artifacts,
+		ID:            ep.ID,
+		Repository:    ep.Repository,
+		Category:      ep.Category,
+		CommitCount:   len(ep.Commits),
+		AuthorCount:   len(authorNames),
+		PRCount:       prCount,
+		IssueCount:    issueCount,
+		Releases:      releases,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		Duration:      ep.GetDuration().String(),
+		Authors:       authorNames,
+		CommitHashes:  commitHashes,
+		Commits:       ep.Commits,
+		Artifacts:     ep.Artifacts,
+		Hotspots:      ep.GetHotspots(exportHotspotLimit),
+		Memberships:   ep.Memberships,
+		Cohesion:      ep.Cohesion,
+		Confidence:    ep.Confidence,
+		Stats:         ep.Stats,
+		Collaboration: ep.GetCollaborationGraph().Pairs,
 	}
 }
 
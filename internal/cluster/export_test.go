@@ -119,7 +119,7 @@ func TestEnrichEpisode(t *testing.T) {
 		},
 	}
 
-	export := enrichEpisode(episode)
+	export := EnrichEpisode(episode)
 
 	if export.ID != "test-episode" {
 		t.Errorf("Expected ID 'test-episode', got '%s'", export.ID)
@@ -2,6 +2,8 @@ package cluster
 
 import (
 	"fmt"
+	"math"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
@@ -15,6 +17,22 @@ type GroupingConfig struct {
 	// Maximum time gap between commits in the same episode
 	MaxTimeGap time.Duration
 
+	// Optional per-author replacement for MaxTimeGap, keyed by git.AuthorKey (see LearnAuthorTimeGaps)
+	AuthorTimeGaps map[string]time.Duration
+
+	// AdaptiveTimeGap learns AuthorTimeGaps from the commits being grouped when none are given
+	AdaptiveTimeGap bool
+
+	// SessionGap splits episodes at pauses longer than this between consecutive commits, even within
+	// MaxTimeGap, so each episode covers one work session (0 disables; see DefaultSessionGap)
+	SessionGap time.Duration
+
+	// Optional per-author replacement for SessionGap, keyed by git.AuthorKey (see LearnAuthorSessionGaps)
+	AuthorSessionGaps map[string]time.Duration
+
+	// AdaptiveSessionGap learns AuthorSessionGaps from the commits being grouped when none are given
+	AdaptiveSessionGap bool
+
 	// Minimum number of commits to form an episode
 	MinCommits int
 
@@ -25,85 +43,316 @@ type GroupingConfig struct {
 	MessageWeight  float64
 	ArtifactWeight float64
 
+	// Directory-aware file scoring: a file the episode hasn't touched still partly matches one it has
+	// in a nearby directory. SiblingFileWeight is the credit for a file in the same directory (0 compares
+	// exact paths only), DirectoryDecay multiplies it for every directory level between the two files,
+	// and files more than MaxDirectoryDistance levels apart or sharing no directory don't match
+	SiblingFileWeight    float64
+	DirectoryDecay       float64
+	MaxDirectoryDistance int
+
+	// Bonus weight applied when both commits carry branch topology and share a feature branch, i.e. a
+	// branch other than the repository's default one; not part of the weight sum above
+	// 0 applies DefaultBranchWeight when the commits were parsed from several branches (see
+	// git.ParseCommitsAllBranches), and no bonus otherwise; a negative weight turns the bonus off
+	BranchWeight float64
+
+	// AllBranches has analyses ingest the commits of every local and remote branch instead of HEAD's
+	// history alone, so commits carry the branch topology BranchWeight scores
+	AllBranches bool
+
+	// Bonus weight applied for overlap between the commit's and the episode's languages
+	// Not part of the weight sum above; disabled by default
+	LanguageWeight float64
+
+	// Bonus weight applied for overlap between the modules (co-change communities, see LearnModules)
+	// of the commit's and the episode's files; not part of the weight sum above, disabled by default
+	ModuleWeight float64
+
+	// Optional file-to-module map for ModuleWeight; learned from the commits being grouped when nil
+	Modules FileModules
+
+	// Bonus weights applied when the issues and PRs the commit refers to share a milestone (e.g. "v2.0")
+	// or labels (e.g. "auth-epic", by Jaccard similarity) with the episode's; labels on most artifacts,
+	// such as "bug", are ignored. Not part of the weight sum above, disabled by default
+	MilestoneWeight float64
+	LabelWeight     float64
+
+	// Milestones and labels of each commit's artifacts, built by groupCommits when either weight is set
+	planning *planningIndex
+
+	// Default branch of the repository being grouped, set by groupCommits; empty when unknown
+	defaultBranch string
+
+	// Optional identity map applied before scoring so aliases of the same person count as one author
+	Identities *git.Mailmap
+
+	// SplitOnReleases ends an episode at every release (GitHub release or git tag)
+	// so no episode spans two versions
+	SplitOnReleases bool
+
+	// BoundaryTags restricts release boundaries to tags matching one of these glob patterns (e.g. "v*"),
+	// so pre-release or nightly tags don't split episodes; setting it implies SplitOnReleases
+	BoundaryTags []string
+
+	// Keywords controls how commit subjects are reduced to keywords for message similarity
+	Keywords KeywordConfig
+
+	// ArtifactEpisodes groups issues and other artifacts that no commit episode holds into episodes of
+	// their own, so design and triage work appears in narratives; artifacts need at least
+	// MinArtifactDiscussions comments or reviews to qualify
+	ArtifactEpisodes       bool
+	MinArtifactDiscussions int
+
 	// Similarity thresholds
 	MinSimilarityScore float64 // Minimum score to group commits together
+
+	// Algorithm selects the clustering backend; empty means AlgorithmGreedy
+	Algorithm Algorithm
+
+	// DensityMinPoints is the neighborhood size (the commit included) that makes a commit a core point
+	// for AlgorithmDensity; values below 2 mean 2
+	DensityMinPoints int
+
+	// Membership selects whether commits may also join episodes other than their own; empty means MembershipExclusive
+	Membership Membership
+
+	// MinMembershipScore is the score a commit needs against another episode to be shared with it under
+	// MembershipSoft; zero means MinSimilarityScore
+	MinMembershipScore float64
+
+	// Semantic grouping (GroupIntoEpisodesSemantic) blends embedding similarity into the heuristic score:
+	// 1 uses embeddings only, 0 only the heuristics above
+	SemanticWeight float64
+
+	// Optional embedder; when set the orchestrator groups with GroupIntoEpisodesSemantic
+	Embedder Embedder
+
+	// Workers is the number of goroutines scoring large histories, each over its own time partition
+	// (<= 0 = runtime.NumCPU()); the episodes are the same for any number of workers
+	Workers int
 }
 
+// DefaultBranchWeight is the branch bonus applied to commits parsed from several branches when
+// GroupingConfig.BranchWeight is 0
+const DefaultBranchWeight = 0.1
+
 // DefaultGroupingConfig returns sensible default grouping parameters
 func DefaultGroupingConfig() GroupingConfig {
 	return GroupingConfig{
-		MaxTimeGap:         24 * time.Hour, // 24 hours
-		MinCommits:         1,
-		TimeWeight:         0.3,
-		AuthorWeight:       0.25,
-		FileWeight:         0.25,
-		MessageWeight:      0.1,
-		ArtifactWeight:     0.1,
-		MinSimilarityScore: 0.5,
+		MaxTimeGap:             24 * time.Hour, // 24 hours
+		MinCommits:             1,
+		TimeWeight:             0.3,
+		AuthorWeight:           0.25,
+		FileWeight:             0.25,
+		MessageWeight:          0.1,
+		ArtifactWeight:         0.1,
+		SiblingFileWeight:      0.5,
+		DirectoryDecay:         0.5,
+		MaxDirectoryDistance:   2,
+		Keywords:               DefaultKeywordConfig(),
+		ArtifactEpisodes:       true,
+		MinArtifactDiscussions: 1,
+		MinSimilarityScore:     0.5,
+		SemanticWeight:         0.6,
 	}
 }
 
 // GroupIntoEpisodes groups commits and artifacts into logical episodes
 // using heuristics based on time, author, file paths, and artifact references
 func (ra *RepositoryActivity) GroupIntoEpisodes(config GroupingConfig) []Episode {
+	return ra.groupCommits(config, calculateEpisodeSimilarity)
+}
+
+// episodeScorer scores how well a commit continues an episode, from 0 to 1
+type episodeScorer func(episode *Episode, commit git.Commit, config GroupingConfig) float64
+
+// groupCommits groups commits with the configured algorithm, then links artifacts and releases to
+// the resulting episodes
+func (ra *RepositoryActivity) groupCommits(config GroupingConfig, score episodeScorer) []Episode {
 	if len(ra.Commits) == 0 {
-		return []Episode{}
+		episodes := []Episode{}
+		if config.ArtifactEpisodes {
+			episodes = addArtifactEpisodes(episodes, ra.Artifacts, config)
+			classifyEpisodes(episodes)
+			computeEpisodeStats(episodes)
+			assignEpisodeIDs(episodes)
+		}
+		return episodes
 	}
 
 	// Sort commits by time (oldest first)
 	commits := make([]git.Commit, len(ra.Commits))
 	copy(commits, ra.Commits)
+	if config.Identities != nil {
+		commits = git.ResolveIdentities(commits, config.Identities)
+	}
 	sortCommitsByTime(commits)
 
+	if config.AdaptiveTimeGap && config.AuthorTimeGaps == nil {
+		config.AuthorTimeGaps = LearnAuthorTimeGaps(commits)
+	}
+	if config.AdaptiveSessionGap && config.SessionGap > 0 && config.AuthorSessionGaps == nil {
+		config.AuthorSessionGaps = LearnAuthorSessionGaps(commits)
+	}
+	if config.ModuleWeight > 0 && config.Modules == nil {
+		config.Modules = LearnModules(commits)
+	}
+	if config.BranchWeight == 0 && hasBranchTopology(commits) {
+		config.BranchWeight = DefaultBranchWeight
+	}
+	config.defaultBranch = ra.DefaultBranch
+
 	// Build artifact reference map for quick lookup
 	artifactRefMap := buildArtifactReferenceMap(ra.Artifacts)
+	if config.MilestoneWeight > 0 || config.LabelWeight > 0 {
+		config.planning = ra.buildPlanningIndex(commits, artifactRefMap)
+	}
+
+	markers := ra.releaseMarkers()
+	boundaries := newReleaseBoundaries(boundaryMarkers(markers, config.BoundaryTags))
 
+	var episodes []Episode
+	switch config.Algorithm {
+	case AlgorithmAgglomerative, AlgorithmDensity:
+		episodes = ra.clusterEpisodes(commits, config, score, artifactRefMap, boundaries)
+	case AlgorithmPullRequest:
+		episodes = ra.pullRequestEpisodes(commits, config, score, artifactRefMap, boundaries)
+	default:
+		episodes = ra.greedyEpisodes(commits, config, score, artifactRefMap, boundaries)
+	}
+
+	soft := config.Membership == MembershipSoft
+	if soft {
+		ra.assignSoftMemberships(episodes, commits, config, score, artifactRefMap, boundaries)
+	}
+
+	attachReleases(episodes, markers)
+	linkPullRequestsByFiles(episodes, ra.Artifacts, config.MaxTimeGap, soft)
+	attachClosedIssues(episodes, ra.Artifacts)
+	scoreCohesion(episodes, config, score)
+	if config.ArtifactEpisodes {
+		episodes = addArtifactEpisodes(episodes, ra.Artifacts, config)
+	}
+	classifyEpisodes(episodes)
+	computeEpisodeStats(episodes)
+	assignEpisodeIDs(episodes)
+
+	return episodes
+}
+
+// greedyEpisodes walks commits oldest first, extending the current episode while the commit's score
+// reaches MinSimilarityScore and starting a new one otherwise
+// Large histories are split into time partitions walked concurrently (see stitchGreedyPartitions),
+// which yields the same episodes as a single walk
+func (ra *RepositoryActivity) greedyEpisodes(commits []git.Commit, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) []Episode {
+	partitions := timePartitions(len(commits), config.partitionCount(len(commits)))
+
+	var episodes []Episode
+	if len(partitions) <= 1 {
+		episodes = ra.greedyWalk(commits, config, score, artifactRefMap, boundaries)
+	} else {
+		speculative := make([][]Episode, len(partitions))
+		runPartitions(partitions, func(p int, part partition) {
+			speculative[p] = ra.greedyWalk(commits[part.start:part.end], config, score, artifactRefMap, boundaries)
+		})
+		episodes = ra.stitchGreedyPartitions(commits, partitions, speculative, config, score, artifactRefMap, boundaries)
+	}
+	episodes = ra.splitWorkSessions(episodes, config, artifactRefMap)
+
+	kept := episodes[:0]
+	for _, episode := range episodes {
+		if len(episode.Commits) >= config.MinCommits {
+			kept = append(kept, episode)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// greedyWalk groups commits in one pass, returning every episode (the last one included) before
+// MinCommits filtering
+func (ra *RepositoryActivity) greedyWalk(commits []git.Commit, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) []Episode {
 	var episodes []Episode
 	var currentEpisode *Episode
 
-	for i, commit := range commits {
-		if currentEpisode == nil {
-			// Start a new episode
-			currentEpisode = &Episode{
-				Commits: []git.Commit{commit},
-			}
-			// Check for artifact references
+	for _, commit := range commits {
+		if currentEpisode != nil && joinsEpisode(currentEpisode, commit, config, score, boundaries) {
+			currentEpisode.Commits = append(currentEpisode.Commits, commit)
 			addReferencedArtifacts(currentEpisode, commit, artifactRefMap, ra.Artifacts)
-		} else {
-			// Calculate similarity with current episode
-			similarity := calculateEpisodeSimilarity(currentEpisode, commit, config)
-
-			if similarity >= config.MinSimilarityScore {
-				// Add to current episode
-				currentEpisode.Commits = append(currentEpisode.Commits, commit)
-				addReferencedArtifacts(currentEpisode, commit, artifactRefMap, ra.Artifacts)
-			} else {
-				// Finalize current episode if it meets minimum criteria
-				if len(currentEpisode.Commits) >= config.MinCommits {
-					currentEpisode.ID = fmt.Sprintf("E%d", len(episodes)+1)
-					episodes = append(episodes, *currentEpisode)
-				}
-
-				// Start new episode
-				currentEpisode = &Episode{
-					Commits: []git.Commit{commit},
-				}
-				addReferencedArtifacts(currentEpisode, commit, artifactRefMap, ra.Artifacts)
-			}
+			continue
 		}
 
-		// Check if this is the last commit
-		if i == len(commits)-1 && currentEpisode != nil {
-			if len(currentEpisode.Commits) >= config.MinCommits {
-				currentEpisode.ID = fmt.Sprintf("E%d", len(episodes)+1)
-				episodes = append(episodes, *currentEpisode)
-			}
+		if currentEpisode != nil {
+			episodes = append(episodes, *currentEpisode)
 		}
+		currentEpisode = &Episode{
+			Commits: []git.Commit{commit},
+		}
+		addReferencedArtifacts(currentEpisode, commit, artifactRefMap, ra.Artifacts)
 	}
 
+	if currentEpisode != nil {
+		episodes = append(episodes, *currentEpisode)
+	}
 	return episodes
 }
 
+// joinsEpisode reports whether the greedy walk adds commit to episode rather than starting a new one
+func joinsEpisode(episode *Episode, commit git.Commit, config GroupingConfig, score episodeScorer, boundaries releaseBoundaries) bool {
+	lastCommit := episode.Commits[len(episode.Commits)-1]
+	if config.splitsOnReleases() && boundaries.separates(lastCommit, commit) {
+		return false
+	}
+	return score(episode, commit, config) >= config.MinSimilarityScore
+}
+
+// stitchGreedyPartitions joins greedy walks of consecutive time partitions into the walk of the whole
+// history. Each partition was walked as if it started a new episode; the open episode carried over
+// from the previous partitions is extended commit by commit instead, until the walk starts a new
+// episode at a commit where the partition's walk did too. From there both walks are identical, so
+// the rest of the partition's episodes are taken as they are
+func (ra *RepositoryActivity) stitchGreedyPartitions(commits []git.Commit, partitions []partition, speculative [][]Episode, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) []Episode {
+	first := speculative[0]
+	episodes := append([]Episode(nil), first[:len(first)-1]...)
+	current := &first[len(first)-1]
+
+	for p := 1; p < len(partitions); p++ {
+		walked := speculative[p]
+
+		// Index of the partition episode starting at each commit
+		starts := make(map[int]int, len(walked))
+		next := partitions[p].start
+		for k, episode := range walked {
+			starts[next] = k
+			next += len(episode.Commits)
+		}
+
+		for i := partitions[p].start; i < partitions[p].end; i++ {
+			commit := commits[i]
+			if joinsEpisode(current, commit, config, score, boundaries) {
+				current.Commits = append(current.Commits, commit)
+				addReferencedArtifacts(current, commit, artifactRefMap, ra.Artifacts)
+				continue
+			}
+
+			episodes = append(episodes, *current)
+			if k, ok := starts[i]; ok {
+				episodes = append(episodes, walked[k:len(walked)-1]...)
+				current = &walked[len(walked)-1]
+				break
+			}
+			// Scorers may cache per episode pointer, so every new episode gets its own
+			current = &Episode{Commits: []git.Commit{commit}}
+			addReferencedArtifacts(current, commit, artifactRefMap, ra.Artifacts)
+		}
+	}
+
+	return append(episodes, *current)
+}
+
 // sortCommitsByTime sorts commits in chronological order (oldest first)
 func sortCommitsByTime(commits []git.Commit) {
 	sort.Slice(commits, func(i, j int) bool {
@@ -119,17 +368,21 @@ func calculateEpisodeSimilarity(episode *Episode, commit git.Commit, config Grou
 
 	lastCommit := episode.Commits[len(episode.Commits)-1]
 
-	// Time similarity (inverse of time gap, normalized)
-	timeScore := calculateTimeScore(lastCommit, commit, config.MaxTimeGap)
+	// Time similarity (inverse of time gap, normalized), using the author's own rhythm when known
+	maxGap := config.MaxTimeGap
+	if gap, ok := config.AuthorTimeGaps[git.AuthorKey(commit.Author)]; ok {
+		maxGap = gap
+	}
+	timeScore := calculateTimeScore(lastCommit, commit, maxGap)
 
 	// Author similarity
 	authorScore := calculateAuthorScore(episode, commit)
 
 	// File path overlap
-	fileScore := calculateFileScore(episode, commit)
+	fileScore := calculateFileScore(episode, commit, config)
 
 	// Commit message similarity
-	messageScore := calculateMessageScore(episode, commit)
+	messageScore := calculateMessageScore(episode, commit, config.Keywords)
 
 	// Artifact reference similarity
 	artifactScore := calculateArtifactScore(episode, commit)
@@ -141,9 +394,175 @@ func calculateEpisodeSimilarity(episode *Episode, commit git.Commit, config Grou
 		(messageScore * config.MessageWeight) +
 		(artifactScore * config.ArtifactWeight)
 
+	// Branch topology bonus (only when branch data is available)
+	if config.BranchWeight > 0 {
+		if branchScore, ok := calculateBranchScore(episode, commit, config.defaultBranch); ok {
+			totalScore += branchScore * config.BranchWeight
+			if totalScore > 1.0 {
+				totalScore = 1.0
+			}
+		}
+	}
+
+	// Module bonus (only when both sides touch files with a co-change module)
+	if config.ModuleWeight > 0 {
+		if moduleScore, ok := calculateModuleScore(episode, commit, config.Modules); ok {
+			totalScore += moduleScore * config.ModuleWeight
+			if totalScore > 1.0 {
+				totalScore = 1.0
+			}
+		}
+	}
+
+	// Milestone and label bonuses (only when both sides refer to artifacts with them)
+	if config.planning != nil {
+		milestoneScore, milestoneOK, labelScore, labelOK := calculatePlanningScores(episode, commit, config.planning)
+		if milestoneOK {
+			totalScore += milestoneScore * config.MilestoneWeight
+		}
+		if labelOK {
+			totalScore += labelScore * config.LabelWeight
+		}
+		if totalScore > 1.0 {
+			totalScore = 1.0
+		}
+	}
+
+	// Language bonus (only when both sides have detected languages)
+	if languageScore, ok := calculateLanguageScore(episode, commit); ok && config.LanguageWeight > 0 {
+		totalScore += languageScore * config.LanguageWeight
+		if totalScore > 1.0 {
+			totalScore = 1.0
+		}
+	}
+
 	return totalScore
 }
 
+// Bounds and sample size for learned per-author time gaps
+const (
+	minLearnedTimeGap  = time.Hour
+	maxLearnedTimeGap  = 7 * 24 * time.Hour
+	minTimeGapSamples  = 5
+	learnedGapQuantile = 0.75
+)
+
+// LearnAuthorTimeGaps derives a per-author MaxTimeGap from each author's commit rhythm
+// The gap is the author's 75th percentile time between commits, clamped to [1h, 7d]
+// Authors with fewer than 5 gaps are omitted and fall back to MaxTimeGap
+func LearnAuthorTimeGaps(commits []git.Commit) map[string]time.Duration {
+	gaps := make(map[string]time.Duration)
+	for key, pattern := range git.AnalyzeWorkPatterns(commits) {
+		if pattern.GapCount() < minTimeGapSamples {
+			continue
+		}
+
+		gap := pattern.GapPercentile(learnedGapQuantile)
+		gap = max(gap, minLearnedTimeGap)
+		gap = min(gap, maxLearnedTimeGap)
+		gaps[key] = gap
+	}
+	return gaps
+}
+
+// calculateLanguageScore calculates language overlap using Jaccard similarity
+// The second return value is false when either side has no detected languages
+func calculateLanguageScore(episode *Episode, commit git.Commit) (float64, bool) {
+	episodeLanguages := episode.GetLanguages()
+	if len(episodeLanguages) == 0 || len(commit.Languages) == 0 {
+		return 0, false
+	}
+
+	intersection := 0
+	union := len(episodeLanguages)
+	for language := range commit.Languages {
+		if _, ok := episodeLanguages[language]; ok {
+			intersection++
+		} else {
+			union++
+		}
+	}
+
+	return float64(intersection) / float64(union), true
+}
+
+// calculateBranchScore returns 1.0 if the commit shares a feature branch with the episode
+// The second return value is false when either side has no branch information
+func calculateBranchScore(episode *Episode, commit git.Commit, defaultBranch string) (float64, bool) {
+	commitBranches := featureBranches(commit, defaultBranch)
+	if commitBranches == nil {
+		return 0, false
+	}
+
+	hasEpisodeBranches := false
+	for _, episodeCommit := range episode.Commits {
+		episodeBranches := featureBranches(episodeCommit, defaultBranch)
+		if episodeBranches == nil {
+			continue
+		}
+		hasEpisodeBranches = true
+
+		for branch := range commitBranches {
+			if episodeBranches[branch] {
+				return 1.0, true
+			}
+		}
+	}
+
+	if !hasEpisodeBranches {
+		return 0, false
+	}
+	return 0.0, true
+}
+
+// featureBranches returns the non-default branches containing a commit
+// Returns nil if the commit carries no branch information at all
+func featureBranches(commit git.Commit, defaultBranch string) map[string]bool {
+	names := commit.Branches
+	if len(names) == 0 && commit.Branch != nil {
+		names = []string{commit.Branch.Name}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	branches := make(map[string]bool)
+	for _, name := range names {
+		if !isDefaultBranch(name, defaultBranch) {
+			branches[shortBranchName(name)] = true
+		}
+	}
+	return branches
+}
+
+// isDefaultBranch reports whether a branch name refers to the repository's default branch (local or
+// remote), or to main/master when the default branch is unknown
+func isDefaultBranch(name, defaultBranch string) bool {
+	short := shortBranchName(name)
+	if defaultBranch != "" {
+		return short == shortBranchName(defaultBranch)
+	}
+	return short == "main" || short == "master"
+}
+
+// hasBranchTopology reports whether any commit was parsed with the branches containing it
+func hasBranchTopology(commits []git.Commit) bool {
+	for i := range commits {
+		if len(commits[i].Branches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// shortBranchName strips a remote prefix (e.g., origin/feature -> feature)
+func shortBranchName(name string) string {
+	if idx := strings.Index(name, "/"); idx >= 0 && strings.HasPrefix(name, "origin/") {
+		return name[idx+1:]
+	}
+	return name
+}
+
 // calculateTimeScore returns 1.0 if within max gap, decays to 0 beyond that
 func calculateTimeScore(lastCommit, commit git.Commit, maxGap time.Duration) float64 {
 	timeDiff := commit.CommittedAt.Sub(lastCommit.CommittedAt)
@@ -162,7 +581,7 @@ func calculateTimeScore(lastCommit, commit git.Commit, maxGap time.Duration) flo
 // calculateAuthorScore returns 1.0 if author matches any in episode
 func calculateAuthorScore(episode *Episode, commit git.Commit) float64 {
 	for _, episodeCommit := range episode.Commits {
-		if episodeCommit.Author.Email == commit.Author.Email {
+		if git.AuthorKey(episodeCommit.Author) == git.AuthorKey(commit.Author) {
 			return 1.0
 		}
 	}
@@ -170,7 +589,9 @@ func calculateAuthorScore(episode *Episode, commit git.Commit) float64 {
 }
 
 // calculateFileScore calculates file path overlap using Jaccard similarity
-func calculateFileScore(episode *Episode, commit git.Commit) float64 {
+// With directory-aware scoring, commit files the episode hasn't touched count as partial
+// matches by their distance to the nearest episode file (see pathProximity)
+func calculateFileScore(episode *Episode, commit git.Commit, config GroupingConfig) float64 {
 	// Collect all file paths from episode
 	episodeFiles := make(map[string]bool)
 	for _, episodeCommit := range episode.Commits {
@@ -195,29 +616,78 @@ func calculateFileScore(episode *Episode, commit git.Commit) float64 {
 		return 0.0
 	}
 
-	// Calculate Jaccard similarity: intersection / union
-	intersection := 0
-	union := len(episodeFiles)
+	var proximity *pathProximity
+	if config.SiblingFileWeight > 0 {
+		proximity = newPathProximity(episodeFiles, config.MaxDirectoryDistance)
+	}
 
+	// Calculate Jaccard similarity: intersection / union, where near misses count fractionally
+	intersection := 0.0
 	for file := range commitFiles {
 		if episodeFiles[file] {
 			intersection++
-		} else {
-			union++
+		} else if proximity != nil {
+			intersection += proximity.score(file, config)
 		}
 	}
 
-	if union == 0 {
+	union := float64(len(episodeFiles)+len(commitFiles)) - intersection
+	if union <= 0 {
 		return 0.0
 	}
 
-	return float64(intersection) / float64(union)
+	return intersection / union
+}
+
+// pathProximity indexes a set of files by their ancestor directories, recording for each
+// directory the fewest levels any file sits below it
+type pathProximity struct {
+	levels      map[string]int
+	maxDistance int
+}
+
+// newPathProximity indexes files' ancestor directories up to maxDistance levels above them
+func newPathProximity(files map[string]bool, maxDistance int) *pathProximity {
+	p := &pathProximity{levels: make(map[string]int), maxDistance: maxDistance}
+	for file := range files {
+		for up, dir := range ancestorDirs(file, maxDistance) {
+			if level, ok := p.levels[dir]; !ok || up < level {
+				p.levels[dir] = up
+			}
+		}
+	}
+	return p
+}
+
+// score returns SiblingFileWeight decayed by the number of directory levels between the file and
+// the nearest indexed file through their closest shared directory, or 0 when none is close enough
+func (p *pathProximity) score(file string, config GroupingConfig) float64 {
+	distance := -1
+	for up, dir := range ancestorDirs(file, p.maxDistance) {
+		if level, ok := p.levels[dir]; ok && up+level <= p.maxDistance && (distance < 0 || up+level < distance) {
+			distance = up + level
+		}
+	}
+	if distance < 0 {
+		return 0
+	}
+	return config.SiblingFileWeight * math.Pow(config.DirectoryDecay, float64(distance))
+}
+
+// ancestorDirs returns a file's directories from its own upwards, at most maxDistance levels above it
+// The repository root is not included, so top-level files have no ancestors
+func ancestorDirs(file string, maxDistance int) []string {
+	var dirs []string
+	for dir := path.Dir(file); dir != "." && dir != "/" && len(dirs) <= maxDistance; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	return dirs
 }
 
 // calculateMessageScore looks for common keywords and patterns in commit messages
-func calculateMessageScore(episode *Episode, commit git.Commit) float64 {
+func calculateMessageScore(episode *Episode, commit git.Commit, config KeywordConfig) float64 {
 	// Extract keywords from new commit message
-	commitKeywords := extractKeywords(commit.MessageSubject)
+	commitKeywords := extractKeywords(commit.MessageSubject, config)
 	if len(commitKeywords) == 0 {
 		return 0.0
 	}
@@ -225,7 +695,7 @@ func calculateMessageScore(episode *Episode, commit git.Commit) float64 {
 	// Check for overlap with episode commit messages
 	maxOverlap := 0.0
 	for _, episodeCommit := range episode.Commits {
-		episodeKeywords := extractKeywords(episodeCommit.MessageSubject)
+		episodeKeywords := extractKeywords(episodeCommit.MessageSubject, config)
 		if len(episodeKeywords) == 0 {
 			continue
 		}
@@ -247,35 +717,24 @@ func calculateMessageScore(episode *Episode, commit git.Commit) float64 {
 	return maxOverlap
 }
 
-// extractKeywords extracts meaningful words from commit message
-func extractKeywords(message string) map[string]bool {
-	// Common stop words to ignore
-	stopWords := map[string]bool{
-		"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
-		"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
-		"it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
-		"to": true, "was": true, "will": true, "with": true,
-	}
-
-	message = strings.ToLower(message)
-	words := regexp.MustCompile(`\w+`).FindAllString(message, -1)
-
-	keywords := make(map[string]bool)
-	for _, word := range words {
-		if len(word) > 2 && !stopWords[word] {
-			keywords[word] = true
-		}
-	}
-
-	return keywords
-}
-
 // calculateArtifactScore checks if commit references same artifacts as episode
 func calculateArtifactScore(episode *Episode, commit git.Commit) float64 {
 	if len(episode.Artifacts) == 0 {
 		return 0.0
 	}
 
+	// A merge or squash commit of a PR already in the episode is a definite match
+	if commit.PullRequestNumber > 0 && findPullRequest(episode.Artifacts, commit.PullRequestNumber) != nil {
+		return 1.0
+	}
+
+	// A commit that is part of a PR already in the episode is a definite match too
+	for i := range episode.Artifacts {
+		if pullRequestContainsCommit(&episode.Artifacts[i], commit.Hash) {
+			return 1.0
+		}
+	}
+
 	// Extract artifact references from commit message (e.g., #123, PR-456)
 	commitRefs := extractArtifactReferences(commit.Message)
 	if len(commitRefs) == 0 {
@@ -313,6 +772,8 @@ func extractArtifactReferences(text string) map[string]bool {
 		regexp.MustCompile(`(?i)PR-?(\d+)`),
 		regexp.MustCompile(`(?i)issue-?(\d+)`),
 		regexp.MustCompile(`(?i)MR-?(\d+)`),
+		regexp.MustCompile(`!(\d+)`),                    // GitLab merge request
+		regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-(\d+)\b`), // Ticket key, e.g. PROJ-123 (Jira) or ENG-42 (Linear)
 	}
 
 	for _, pattern := range patterns {
@@ -327,15 +788,44 @@ func extractArtifactReferences(text string) map[string]bool {
 	return refs
 }
 
+// branchTicketKeyPattern matches ticket keys in branch names, which are usually lowercased
+var branchTicketKeyPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([a-z][a-z0-9]*-\d+)`)
+
+// extractBranchTicketKeys returns the uppercased ticket keys in a branch name
+func extractBranchTicketKeys(branch string) []string {
+	var keys []string
+	for _, match := range branchTicketKeyPattern.FindAllStringSubmatch(branch, -1) {
+		keys = append(keys, strings.ToUpper(match[1]))
+	}
+	return keys
+}
+
 // buildArtifactReferenceMap creates a map of artifact references to artifacts
 func buildArtifactReferenceMap(artifacts []Artifact) map[string]*Artifact {
 	refMap := make(map[string]*Artifact)
 
 	for i := range artifacts {
 		artifact := &artifacts[i]
-		refMap[fmt.Sprintf("#%d", artifact.Number)] = artifact
+		// Releases are attached by tag, not referenced by number
+		if artifact.Type == ArtifactRelease {
+			continue
+		}
 		refMap[artifact.ID] = artifact
 
+		// Tickets are referenced by their tracker key (PROJ-123), never by bare number
+		if artifact.Type == ArtifactTicket && artifact.Metadata.TicketKey != "" {
+			refMap[strings.ToUpper(artifact.Metadata.TicketKey)] = artifact
+			continue
+		}
+
+		// GitLab numbers merge requests separately from issues and writes them as !123
+		if artifact.Type == ArtifactMergeRequest {
+			refMap[fmt.Sprintf("!%d", artifact.Number)] = artifact
+			refMap[fmt.Sprintf("MR-%d", artifact.Number)] = artifact
+			continue
+		}
+		refMap[fmt.Sprintf("#%d", artifact.Number)] = artifact
+
 		// Add common reference patterns
 		refMap[fmt.Sprintf("PR-%d", artifact.Number)] = artifact
 		refMap[fmt.Sprintf("issue-%d", artifact.Number)] = artifact
@@ -345,6 +835,20 @@ func buildArtifactReferenceMap(artifacts []Artifact) map[string]*Artifact {
 	return refMap
 }
 
+// findPullRequest returns the pull or merge request artifact with the given number
+func findPullRequest(artifacts []Artifact, number int) *Artifact {
+	for i := range artifacts {
+		artifact := &artifacts[i]
+		if artifact.Number != number {
+			continue
+		}
+		if artifact.Type == ArtifactPullRequest || artifact.Type == ArtifactMergeRequest {
+			return artifact
+		}
+	}
+	return nil
+}
+
 // addReferencedArtifacts finds and adds artifacts referenced in commit message
 func addReferencedArtifacts(episode *Episode, commit git.Commit, refMap map[string]*Artifact, allArtifacts []Artifact) {
 	refs := extractArtifactReferences(commit.Message)
@@ -364,6 +868,14 @@ func addReferencedArtifacts(episode *Episode, commit git.Commit, refMap map[stri
 		}
 	}
 
+	// Link the PR that this commit merged, even if the message doesn't mention it as free text
+	if commit.PullRequestNumber > 0 {
+		if artifact := findPullRequest(allArtifacts, commit.PullRequestNumber); artifact != nil && !existingArtifacts[artifact.ID] {
+			episode.Artifacts = append(episode.Artifacts, *artifact)
+			existingArtifacts[artifact.ID] = true
+		}
+	}
+
 	// Check if commit hash is referenced in artifact discussions
 	for i := range allArtifacts {
 		artifact := &allArtifacts[i]
@@ -380,15 +892,15 @@ func addReferencedArtifacts(episode *Episode, commit git.Commit, refMap map[stri
 		}
 	}
 
-	// Check if commit hash matches PR merge commit SHA
+	// Check if commit hash matches PR merge commit SHA or one of the PR's own commits
 	for i := range allArtifacts {
 		artifact := &allArtifacts[i]
 		if existingArtifacts[artifact.ID] {
 			continue
 		}
 
-		if artifact.Type == ArtifactPullRequest &&
-			artifact.Metadata.MergeCommitSHA == commit.Hash {
+		if (artifact.Type == ArtifactPullRequest || artifact.Type == ArtifactMergeRequest) &&
+			(artifact.Metadata.MergeCommitSHA == commit.Hash || pullRequestContainsCommit(artifact, commit.Hash)) {
 			episode.Artifacts = append(episode.Artifacts, *artifact)
 			existingArtifacts[artifact.ID] = true
 		}
@@ -396,6 +908,14 @@ func addReferencedArtifacts(episode *Episode, commit git.Commit, refMap map[stri
 
 	// Match PRs by branch name if commit is on a feature branch
 	if commit.Branch != nil && commit.Branch.Name != "main" && commit.Branch.Name != "master" {
+		// Issue trackers name branches after the ticket (e.g. alice/eng-42-fix-login)
+		for _, key := range extractBranchTicketKeys(commit.Branch.Name) {
+			if artifact, exists := refMap[key]; exists && artifact.Type == ArtifactTicket && !existingArtifacts[artifact.ID] {
+				episode.Artifacts = append(episode.Artifacts, *artifact)
+				existingArtifacts[artifact.ID] = true
+			}
+		}
+
 		branchName := commit.Branch.Name
 		// Strip remote prefix if present (e.g., origin/feature -> feature)
 		if strings.Contains(branchName, "/") {
@@ -412,7 +932,7 @@ func addReferencedArtifacts(episode *Episode, commit git.Commit, refMap map[stri
 			}
 
 			// Match PR if head branch matches commit branch
-			if artifact.Type == ArtifactPullRequest &&
+			if (artifact.Type == ArtifactPullRequest || artifact.Type == ArtifactMergeRequest) &&
 				artifact.Metadata.HeadBranch == branchName {
 				episode.Artifacts = append(episode.Artifacts, *artifact)
 				existingArtifacts[artifact.ID] = true
@@ -1,6 +1,8 @@
 package cluster
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -385,26 +387,64 @@ func TestCalculateFileScore(t *testing.T) {
 
 	// Same files
 	sameFilesCommit := createTestCommit("def5678", "Commit", author, baseTime, []string{"main.go", "utils.go"})
-	score := calculateFileScore(episode, sameFilesCommit)
+	score := calculateFileScore(episode, sameFilesCommit, DefaultGroupingConfig())
 	if score != 1.0 {
 		t.Errorf("Expected score 1.0 for identical files, got %f", score)
 	}
 
 	// Partial overlap
 	partialCommit := createTestCommit("ghi9012", "Commit", author, baseTime, []string{"main.go", "other.go"})
-	score = calculateFileScore(episode, partialCommit)
+	score = calculateFileScore(episode, partialCommit, DefaultGroupingConfig())
 	if score <= 0.0 || score >= 1.0 {
 		t.Errorf("Expected score between 0 and 1 for partial overlap, got %f", score)
 	}
 
 	// No overlap
 	noOverlapCommit := createTestCommit("jkl3456", "Commit", author, baseTime, []string{"different.go"})
-	score = calculateFileScore(episode, noOverlapCommit)
+	score = calculateFileScore(episode, noOverlapCommit, DefaultGroupingConfig())
 	if score != 0.0 {
 		t.Errorf("Expected score 0.0 for no overlap, got %f", score)
 	}
 }
 
+func TestCalculateFileScore_Directories(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com", When: baseTime}
+	config := DefaultGroupingConfig()
+
+	episode := &Episode{
+		Commits: []git.Commit{
+			createTestCommit("abc1234", "Commit", author, baseTime, []string{"internal/cluster/grouping.go"}),
+		},
+	}
+	score := func(file string, config GroupingConfig) float64 {
+		return calculateFileScore(episode, createTestCommit("def5678", "Commit", author, baseTime, []string{file}), config)
+	}
+
+	sibling := score("internal/cluster/arcs.go", config)
+	nested := score("internal/cluster/testdata/arcs.go", config)
+	cousin := score("internal/rag/indexer.go", config)
+	if !(sibling > nested && nested > cousin && cousin > 0) {
+		t.Errorf("Expected sibling > nested > cousin > 0, got %f, %f, %f", sibling, nested, cousin)
+	}
+	if sibling >= 1.0 {
+		t.Errorf("Expected a sibling file to score below an exact match, got %f", sibling)
+	}
+
+	if got := score("cmd/root.go", config); got != 0 {
+		t.Errorf("Expected files sharing no directory to score 0, got %f", got)
+	}
+	if got := score("internal/ingest/github/webhook/server.go", config); got != 0 {
+		t.Errorf("Expected files beyond MaxDirectoryDistance to score 0, got %f", got)
+	}
+
+	exact := config
+	exact.SiblingFileWeight = 0
+	if got := score("internal/cluster/arcs.go", exact); got != 0 {
+		t.Errorf("Expected exact path matching without SiblingFileWeight, got %f", got)
+	}
+}
+
 func TestExtractKeywords(t *testing.T) {
 	tests := []struct {
 		message          string
@@ -430,7 +470,7 @@ func TestExtractKeywords(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.message, func(t *testing.T) {
-			keywords := extractKeywords(tt.message)
+			keywords := extractKeywords(tt.message, KeywordConfig{})
 
 			for _, expected := range tt.expectedKeywords {
 				if !keywords[expected] {
@@ -468,6 +508,14 @@ func TestExtractArtifactReferences(t *testing.T) {
 			text:     "Merge MR-999 into main",
 			expected: []string{"MR-999"},
 		},
+		{
+			text:     "See merge request group/project!42",
+			expected: []string{"!42"},
+		},
+		{
+			text:     "PROJ-123 #comment handle retries",
+			expected: []string{"PROJ-123"},
+		},
 		{
 			text:     "No references here",
 			expected: []string{},
@@ -551,14 +599,14 @@ func TestCalculateMessageScore(t *testing.T) {
 
 	// Similar message
 	similarCommit := createTestCommit("def5678", "Fix authentication issue", author, baseTime, []string{"main.go"})
-	score := calculateMessageScore(episode, similarCommit)
+	score := calculateMessageScore(episode, similarCommit, DefaultKeywordConfig())
 	if score <= 0.0 {
 		t.Errorf("Expected positive score for similar messages, got %f", score)
 	}
 
 	// Completely different message
 	differentCommit := createTestCommit("ghi9012", "Update documentation", author, baseTime, []string{"main.go"})
-	score = calculateMessageScore(episode, differentCommit)
+	score = calculateMessageScore(episode, differentCommit, DefaultKeywordConfig())
 	if score != 0.0 {
 		t.Errorf("Expected score 0.0 for completely different message, got %f", score)
 	}
@@ -612,3 +660,292 @@ func TestGroupIntoEpisodes_ComplexScenario(t *testing.T) {
 		t.Error("Expected artifact to be linked in first episode")
 	}
 }
+
+func TestCalculateBranchScore(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	onFeature := createTestCommit("aaa1111", "Work", author, baseTime, []string{"a.go"})
+	onFeature.Branches = []string{"feature/auth", "main"}
+
+	sameFeature := createTestCommit("bbb2222", "More work", author, baseTime, []string{"b.go"})
+	sameFeature.Branches = []string{"origin/feature/auth"}
+
+	otherFeature := createTestCommit("ccc3333", "Other", author, baseTime, []string{"c.go"})
+	otherFeature.Branches = []string{"feature/billing"}
+
+	noBranch := createTestCommit("ddd4444", "Unknown", author, baseTime, []string{"d.go"})
+
+	episode := &Episode{Commits: []git.Commit{onFeature}}
+
+	if score, ok := calculateBranchScore(episode, sameFeature, ""); !ok || score != 1.0 {
+		t.Errorf("Expected shared feature branch score 1.0, got %f (ok=%v)", score, ok)
+	}
+	if score, ok := calculateBranchScore(episode, otherFeature, ""); !ok || score != 0.0 {
+		t.Errorf("Expected different feature branch score 0.0, got %f (ok=%v)", score, ok)
+	}
+	if _, ok := calculateBranchScore(episode, noBranch, ""); ok {
+		t.Error("Expected no branch score for commit without branch data")
+	}
+
+	// Default branches alone never count as shared topology
+	mainOnly := createTestCommit("eee5555", "Main", author, baseTime, []string{"e.go"})
+	mainOnly.Branches = []string{"main"}
+	if score, _ := calculateBranchScore(&Episode{Commits: []git.Commit{mainOnly}}, mainOnly, ""); score != 0.0 {
+		t.Errorf("Expected default branch to score 0, got %f", score)
+	}
+
+	// A known default branch replaces main/master, so main is a feature branch of a develop repository
+	onDevelop := createTestCommit("fff6666", "Develop", author, baseTime, []string{"f.go"})
+	onDevelop.Branches = []string{"origin/develop"}
+	if score, _ := calculateBranchScore(&Episode{Commits: []git.Commit{onDevelop}}, onDevelop, "develop"); score != 0.0 {
+		t.Errorf("Expected the repository's default branch to score 0, got %f", score)
+	}
+	if score, _ := calculateBranchScore(&Episode{Commits: []git.Commit{mainOnly}}, mainOnly, "develop"); score != 1.0 {
+		t.Errorf("Expected main to be a feature branch when develop is the default, got %f", score)
+	}
+}
+
+func TestGroupIntoEpisodes_BranchWeightNeedsTopology(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+
+	// Just below the similarity threshold without the branch bonus
+	first := createTestCommit("aaa1111", "Add parser", alice, baseTime, []string{"parser/lex.go"})
+	second := createTestCommit("bbb2222", "Update docs", bob, baseTime.Add(time.Hour), []string{"docs/guide.md"})
+
+	config := DefaultGroupingConfig()
+	config.MessageWeight, config.ArtifactWeight = 0, 0
+	config.MinSimilarityScore = 0.35
+
+	// HEAD-only parsing attributes every commit to a single branch, which isn't feature-branch topology
+	first.Branch, second.Branch = &git.Branch{Name: "develop"}, &git.Branch{Name: "develop"}
+	headOnly := &RepositoryActivity{DefaultBranch: "trunk", Commits: []git.Commit{first, second}}
+	if episodes := headOnly.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Errorf("Expected no branch bonus without multi-branch topology, got %d episodes", len(episodes))
+	}
+
+	first.Branches, second.Branches = []string{"feature/parser"}, []string{"origin/feature/parser"}
+	multiBranch := &RepositoryActivity{DefaultBranch: "trunk", Commits: []git.Commit{first, second}}
+	if episodes := multiBranch.GroupIntoEpisodes(config); len(episodes) != 1 {
+		t.Errorf("Expected the default branch bonus to join commits on a shared feature branch, got %d episodes", len(episodes))
+	}
+
+	config.BranchWeight = -1
+	if episodes := multiBranch.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Errorf("Expected a negative branch weight to turn the bonus off, got %d episodes", len(episodes))
+	}
+}
+
+func TestCalculateLanguageScore(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	goCommit := createTestCommit("aaa1111", "Work", author, baseTime, []string{"a.go"})
+	goCommit.Languages = map[string]int{"Go": 10}
+
+	mixed := createTestCommit("bbb2222", "More work", author, baseTime, []string{"b.go", "schema.sql"})
+	mixed.Languages = map[string]int{"Go": 5, "SQL": 5}
+
+	unknown := createTestCommit("ccc3333", "Docs", author, baseTime, []string{"NOTES"})
+
+	episode := &Episode{Commits: []git.Commit{goCommit}}
+
+	if score, ok := calculateLanguageScore(episode, mixed); !ok || score != 0.5 {
+		t.Errorf("Expected language score 0.5, got %f (ok=%v)", score, ok)
+	}
+	if _, ok := calculateLanguageScore(episode, unknown); ok {
+		t.Error("Expected no language score for commit without languages")
+	}
+
+	// The bonus only applies when enabled
+	config := DefaultGroupingConfig()
+	base := calculateEpisodeSimilarity(episode, mixed, config)
+	config.LanguageWeight = 0.1
+	if boosted := calculateEpisodeSimilarity(episode, mixed, config); boosted <= base && base < 1.0 {
+		t.Errorf("Expected language bonus to raise similarity above %f, got %f", base, boosted)
+	}
+}
+
+func TestLearnAuthorTimeGaps(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	steady := git.Author{Name: "Alice", Email: "alice@example.com"}
+	sporadic := git.Author{Name: "Bob", Email: "bob@example.com"}
+	occasional := git.Author{Name: "Carol", Email: "carol@example.com"}
+
+	commits := make([]git.Commit, 0)
+	for i := 0; i < 8; i++ {
+		// Alice commits every 10 minutes, Bob every 20 days
+		commits = append(commits,
+			createTestCommit(fmt.Sprintf("aaa%04d", i), "Work", steady, baseTime.Add(time.Duration(i)*10*time.Minute), []string{"a.go"}),
+			createTestCommit(fmt.Sprintf("bbb%04d", i), "Work", sporadic, baseTime.Add(time.Duration(i)*20*24*time.Hour), []string{"b.go"}),
+		)
+	}
+	commits = append(commits, createTestCommit("ccc0000", "Work", occasional, baseTime, []string{"c.go"}))
+
+	gaps := LearnAuthorTimeGaps(commits)
+	if gaps["alice@example.com"] != time.Hour {
+		t.Errorf("Expected short gaps clamped to 1h, got %v", gaps["alice@example.com"])
+	}
+	if gaps["bob@example.com"] != 7*24*time.Hour {
+		t.Errorf("Expected long gaps clamped to 7d, got %v", gaps["bob@example.com"])
+	}
+	if _, ok := gaps["carol@example.com"]; ok {
+		t.Error("Expected authors with too few commits to be omitted")
+	}
+
+	// A per-author gap replaces MaxTimeGap in time scoring
+	episode := &Episode{Commits: []git.Commit{commits[0]}}
+	late := createTestCommit("aaa0009", "Work", steady, baseTime.Add(2*time.Hour), []string{"a.go"})
+	config := DefaultGroupingConfig()
+	withDefault := calculateEpisodeSimilarity(episode, late, config)
+	config.AuthorTimeGaps = gaps
+	if learned := calculateEpisodeSimilarity(episode, late, config); learned >= withDefault {
+		t.Errorf("Expected learned 1h gap to lower similarity for a 2h break, got %f >= %f", learned, withDefault)
+	}
+}
+
+func TestCalculateAuthorScore_Identities(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	work := createTestCommit("aaa1111", "Work", git.Author{Name: "Alice", Email: "alice@example.com"}, baseTime, []string{"a.go"})
+	caseAlias := createTestCommit("bbb2222", "More", git.Author{Name: "alice", Email: "Alice@Example.com"}, baseTime, []string{"a.go"})
+	homeAlias := createTestCommit("ccc3333", "Home", git.Author{Name: "alice", Email: "alice@home.net"}, baseTime, []string{"b.go"})
+
+	episode := &Episode{Commits: []git.Commit{work}}
+	if score := calculateAuthorScore(episode, caseAlias); score != 1.0 {
+		t.Errorf("Expected case-insensitive email match, got %f", score)
+	}
+	if score := calculateAuthorScore(episode, homeAlias); score != 0.0 {
+		t.Errorf("Expected unmapped alias to differ, got %f", score)
+	}
+
+	config := DefaultGroupingConfig()
+	activity := &RepositoryActivity{Commits: []git.Commit{work, homeAlias}}
+	if episodes := activity.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Fatalf("Expected unmapped aliases in 2 episodes, got %d", len(episodes))
+	}
+
+	mailmap := git.NewMailmap()
+	mailmap.Add("Alice", "alice@example.com", "", "alice@home.net")
+	config.Identities = mailmap
+
+	episodes := activity.GroupIntoEpisodes(config)
+	if len(episodes) != 1 {
+		t.Fatalf("Expected aliases to group into 1 episode, got %d", len(episodes))
+	}
+	if authors := episodes[0].GetCommitAuthors(); len(authors) != 1 {
+		t.Errorf("Expected 1 canonical author, got %d", len(authors))
+	}
+}
+
+func TestPullRequestNumberLinking(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	pr := Artifact{ID: "pr-7", Number: 7, Type: ArtifactPullRequest, Title: "Add caching"}
+	issue := Artifact{ID: "issue-9", Number: 9, Type: ArtifactIssue, Title: "Slow queries"}
+	artifacts := []Artifact{pr, issue}
+
+	// GitLab-style merge subject carries no "#7" reference for free-text extraction
+	merge := createTestCommit("aaa1111", "Merge branch 'cache' into 'main'", author, baseTime, []string{"cache.go"})
+	merge.PullRequestNumber = 7
+
+	episode := &Episode{}
+	addReferencedArtifacts(episode, merge, buildArtifactReferenceMap(artifacts), artifacts)
+	if len(episode.Artifacts) != 1 || episode.Artifacts[0].ID != "pr-7" {
+		t.Fatalf("Expected PR 7 linked via PullRequestNumber, got %+v", episode.Artifacts)
+	}
+
+	if score := calculateArtifactScore(episode, merge); score != 1.0 {
+		t.Errorf("Expected artifact score 1.0 for merged PR, got %f", score)
+	}
+
+	// Issues with the same number are not treated as the merged PR
+	if found := findPullRequest(artifacts, 9); found != nil {
+		t.Errorf("Expected no pull request for issue number, got %+v", found)
+	}
+}
+
+func TestBuildArtifactReferenceMap_GitLabMergeRequest(t *testing.T) {
+	artifacts := []Artifact{
+		{ID: "issue-1", Number: 5, Type: ArtifactIssue},
+		{ID: "mr-2", Number: 5, Type: ArtifactMergeRequest},
+	}
+
+	refMap := buildArtifactReferenceMap(artifacts)
+
+	// GitLab issues and merge requests are numbered separately, so #5 and !5 differ
+	if refMap["#5"] == nil || refMap["#5"].ID != "issue-1" {
+		t.Errorf("Expected #5 to map to the issue, got %+v", refMap["#5"])
+	}
+	if refMap["!5"] == nil || refMap["!5"].ID != "mr-2" {
+		t.Errorf("Expected !5 to map to the merge request, got %+v", refMap["!5"])
+	}
+}
+
+func TestGroupIntoEpisodes_LinksJiraTicketByKey(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "PROJ-42 Add card payments", alice, base, []string{"pay.go"}),
+		},
+		Artifacts: []Artifact{
+			{ID: "ticket-10002", Number: 42, Type: ArtifactTicket, Title: "Pay with card", Metadata: ArtifactMetadata{TicketKey: "PROJ-42"}},
+			{ID: "issue-7", Number: 42, Type: ArtifactIssue, Title: "Unrelated GitHub issue"},
+		},
+	}
+
+	episodes := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 {
+		t.Fatalf("Expected 1 episode, got %d", len(episodes))
+	}
+	if len(episodes[0].Artifacts) != 1 || episodes[0].Artifacts[0].ID != "ticket-10002" {
+		t.Errorf("Expected only the Jira ticket to be linked, got %+v", episodes[0].Artifacts)
+	}
+}
+
+func TestGroupIntoEpisodes_LinksTicketByBranchName(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	commit := createTestCommit("bbbbbbb1", "Handle expired sessions", alice, base, []string{"auth.go"})
+	commit.Branch = &git.Branch{Name: "origin/alice/eng-42-fix-login"}
+
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{commit},
+		Artifacts: []Artifact{
+			{ID: "ticket-abc", Number: 42, Type: ArtifactTicket, Title: "Fix login", Metadata: ArtifactMetadata{TicketKey: "ENG-42"}},
+			{ID: "issue-7", Number: 42, Type: ArtifactIssue, Title: "Unrelated GitHub issue"},
+		},
+	}
+
+	episodes := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 {
+		t.Fatalf("Expected 1 episode, got %d", len(episodes))
+	}
+	if len(episodes[0].Artifacts) != 1 || episodes[0].Artifacts[0].ID != "ticket-abc" {
+		t.Errorf("Expected the Linear ticket to be linked by branch name, got %+v", episodes[0].Artifacts)
+	}
+}
+
+func TestExtractBranchTicketKeys(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"alice/eng-42-fix-login", []string{"ENG-42"}},
+		{"feature/fix-ENG-7", []string{"ENG-7"}},
+		{"feature/login", nil},
+	}
+
+	for _, tt := range tests {
+		got := extractBranchTicketKeys(tt.branch)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("extractBranchTicketKeys(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// FileHotspot summarizes how often and how heavily a file was changed
+type FileHotspot struct {
+	Path        string    `json:"path"`
+	Changes     int       `json:"changes"`  // Commits that touched the file
+	Episodes    int       `json:"episodes"` // Episodes containing at least one of those commits
+	Additions   int       `json:"additions"`
+	Deletions   int       `json:"deletions"`
+	Churn       int       `json:"churn"`   // Additions + deletions
+	Authors     []string  `json:"authors"` // Distinct authors, sorted by name
+	LastChanged time.Time `json:"last_changed"`
+}
+
+// ComputeHotspots ranks files by change frequency, then churn, then number of distinct authors
+// Renamed files are tracked under their newest path. The in-progress episode is excluded
+// because uncommitted work is not part of the project's history yet. Paths of episodes
+// tagged with a repository are prefixed with it so same-named files in different
+// repositories are counted separately. Commits shared by several episodes count once
+// as a change but toward every episode containing them
+func ComputeHotspots(episodes []Episode) []FileHotspot {
+	commits := make([]episodeCommit, 0)
+	for i := range episodes {
+		if episodes[i].IsWorkingEpisode() {
+			continue
+		}
+		for _, commit := range episodes[i].Commits {
+			commits = append(commits, episodeCommit{
+				episode:    episodes[i].ID,
+				repository: episodes[i].Repository,
+				commit:     commit,
+				shared:     episodes[i].IsShared(commit.Hash),
+			})
+		}
+	}
+	return rankHotspots(commits)
+}
+
+// GetHotspots returns up to n of the episode's most frequently changed files
+func (e *Episode) GetHotspots(n int) []FileHotspot {
+	commits := make([]episodeCommit, len(e.Commits))
+	for i, commit := range e.Commits {
+		commits[i] = episodeCommit{episode: e.ID, commit: commit}
+	}
+
+	hotspots := rankHotspots(commits)
+	if len(hotspots) > n {
+		hotspots = hotspots[:n]
+	}
+	return hotspots
+}
+
+// episodeCommit pairs a commit with an episode containing it
+type episodeCommit struct {
+	episode    string
+	repository string
+	commit     git.Commit
+	shared     bool // Shared from another episode; already counted there
+}
+
+// hotspotStats accumulates a file's metrics before ranking
+type hotspotStats struct {
+	hotspot  FileHotspot
+	episodes map[string]bool
+	authors  map[string]string
+}
+
+// rankHotspots aggregates per-file metrics across commits and sorts the result
+func rankHotspots(commits []episodeCommit) []FileHotspot {
+	// Walk oldest first so renames can carry a file's history over to its new path
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].commit.CommittedAt.Before(commits[j].commit.CommittedAt)
+	})
+
+	files := make(map[string]*hotspotStats)
+	for _, ec := range commits {
+		for _, diff := range ec.commit.Diffs {
+			if diff.FilePath == "" {
+				continue
+			}
+			filePath := qualifyPath(ec.repository, diff.FilePath)
+
+			stats, ok := files[filePath]
+			if diff.OldPath != "" && diff.OldPath != diff.FilePath {
+				oldPath := qualifyPath(ec.repository, diff.OldPath)
+				if previous, renamed := files[oldPath]; renamed {
+					delete(files, oldPath)
+					if !ok {
+						stats, ok = previous, true
+						stats.hotspot.Path = filePath
+						files[filePath] = stats
+					}
+				}
+			}
+			if !ok {
+				stats = &hotspotStats{
+					hotspot:  FileHotspot{Path: filePath},
+					episodes: make(map[string]bool),
+					authors:  make(map[string]string),
+				}
+				files[filePath] = stats
+			}
+
+			stats.episodes[ec.episode] = true
+			if ec.shared {
+				continue
+			}
+
+			stats.hotspot.Changes++
+			stats.hotspot.Additions += diff.Additions
+			stats.hotspot.Deletions += diff.Deletions
+			stats.authors[git.AuthorKey(ec.commit.Author)] = ec.commit.Author.Name
+			if ec.commit.CommittedAt.After(stats.hotspot.LastChanged) {
+				stats.hotspot.LastChanged = ec.commit.CommittedAt
+			}
+		}
+	}
+
+	hotspots := make([]FileHotspot, 0, len(files))
+	for _, stats := range files {
+		hotspot := stats.hotspot
+		hotspot.Churn = hotspot.Additions + hotspot.Deletions
+		hotspot.Episodes = len(stats.episodes)
+		hotspot.Authors = make([]string, 0, len(stats.authors))
+		for _, name := range stats.authors {
+			hotspot.Authors = append(hotspot.Authors, name)
+		}
+		sort.Strings(hotspot.Authors)
+		hotspots = append(hotspots, hotspot)
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		if a.Churn != b.Churn {
+			return a.Churn > b.Churn
+		}
+		if len(a.Authors) != len(b.Authors) {
+			return len(a.Authors) > len(b.Authors)
+		}
+		return a.Path < b.Path
+	})
+
+	return hotspots
+}
+
+// qualifyPath prefixes a file path with its repository, if any
+func qualifyPath(repository, filePath string) string {
+	if repository == "" {
+		return filePath
+	}
+	return repository + "/" + filePath
+}
@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestComputeHotspots(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	episodes := []Episode{
+		{ID: "ep-1", Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Add parser", alice, base, []string{"parser.go", "README.md"}),
+			createTestCommit("aaaaaaa2", "Fix parser", bob, base.Add(time.Hour), []string{"parser.go"}),
+		}},
+		{ID: "ep-2", Commits: []git.Commit{
+			createTestCommit("bbbbbbb1", "Tweak parser", alice, base.Add(48*time.Hour), []string{"parser.go", "lexer.go"}),
+		}},
+		{ID: WorkingEpisodeID, Commits: []git.Commit{
+			createTestCommit("ccccccc1", "Uncommitted", alice, base.Add(72*time.Hour), []string{"lexer.go", "lexer.go", "lexer.go"}),
+		}},
+	}
+
+	hotspots := ComputeHotspots(episodes)
+	if len(hotspots) != 3 {
+		t.Fatalf("Expected 3 hotspots, got %d", len(hotspots))
+	}
+
+	top := hotspots[0]
+	if top.Path != "parser.go" {
+		t.Fatalf("Expected parser.go to rank first, got %s", top.Path)
+	}
+	if top.Changes != 3 || top.Episodes != 2 || top.Churn != 45 {
+		t.Errorf("Unexpected parser.go metrics: %+v", top)
+	}
+	if len(top.Authors) != 2 || top.Authors[0] != "Alice" || top.Authors[1] != "Bob" {
+		t.Errorf("Expected authors [Alice Bob], got %v", top.Authors)
+	}
+	if !top.LastChanged.Equal(base.Add(48 * time.Hour)) {
+		t.Errorf("Expected last change at %v, got %v", base.Add(48*time.Hour), top.LastChanged)
+	}
+
+	// Ties on changes and churn fall back to path order; the working episode is ignored
+	if hotspots[1].Path != "README.md" || hotspots[2].Path != "lexer.go" || hotspots[2].Changes != 1 {
+		t.Errorf("Unexpected ranking: %s, %s (%d changes)", hotspots[1].Path, hotspots[2].Path, hotspots[2].Changes)
+	}
+}
+
+func TestComputeHotspots_FollowsRenames(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	renamed := createTestCommit("aaaaaaa2", "Rename", alice, base.Add(time.Hour), nil)
+	renamed.Diffs = []git.Diff{{FilePath: "new.go", OldPath: "old.go", Status: "renamed", Additions: 1}}
+
+	// Listed newest first to check that history is replayed in commit order
+	episodes := []Episode{{ID: "ep-1", Commits: []git.Commit{
+		renamed,
+		createTestCommit("aaaaaaa1", "Create", alice, base, []string{"old.go"}),
+	}}}
+
+	hotspots := ComputeHotspots(episodes)
+	if len(hotspots) != 1 {
+		t.Fatalf("Expected renamed file to be tracked once, got %+v", hotspots)
+	}
+	if hotspots[0].Path != "new.go" || hotspots[0].Changes != 2 || hotspots[0].Churn != 16 {
+		t.Errorf("Unexpected rename metrics: %+v", hotspots[0])
+	}
+}
+
+func TestEpisode_GetHotspots(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	ep := Episode{ID: "ep-1", Commits: []git.Commit{
+		createTestCommit("aaaaaaa1", "One", alice, base, []string{"a.go", "b.go", "c.go"}),
+		createTestCommit("aaaaaaa2", "Two", alice, base.Add(time.Hour), []string{"b.go"}),
+	}}
+
+	hotspots := ep.GetHotspots(2)
+	if len(hotspots) != 2 {
+		t.Fatalf("Expected 2 hotspots, got %d", len(hotspots))
+	}
+	if hotspots[0].Path != "b.go" || hotspots[1].Path != "a.go" {
+		t.Errorf("Expected [b.go a.go], got [%s %s]", hotspots[0].Path, hotspots[1].Path)
+	}
+
+	if ep.Commits[0].Hash != "aaaaaaa1" {
+		t.Error("GetHotspots should not reorder the episode's commits")
+	}
+}
+
+func TestComputeHotspots_QualifiesRepositories(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	episodes := []Episode{
+		{ID: "api:E1", Repository: "acme/api", Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Docs", alice, base, []string{"README.md"}),
+		}},
+		{ID: "web:E1", Repository: "acme/web", Commits: []git.Commit{
+			createTestCommit("bbbbbbb1", "Docs", alice, base, []string{"README.md"}),
+		}},
+	}
+
+	hotspots := ComputeHotspots(episodes)
+	if len(hotspots) != 2 {
+		t.Fatalf("Expected same-named files in different repositories to stay separate, got %+v", hotspots)
+	}
+	if hotspots[0].Path != "acme/api/README.md" || hotspots[1].Path != "acme/web/README.md" {
+		t.Errorf("Expected repository-qualified paths, got %s and %s", hotspots[0].Path, hotspots[1].Path)
+	}
+}
@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// episodeIDDigits is the number of hex digits of the content hash kept in episode IDs
+const episodeIDDigits = 12
+
+// EpisodeID derives a stable ID from the hashes of the episode's own commits, so the same commits get
+// the same ID however the rest of the history changes; commits shared from other episodes don't count
+// Episodes without commits of their own are identified by their artifacts instead
+func EpisodeID(e *Episode) string {
+	members := make([]string, 0, len(e.Commits))
+	for _, commit := range e.GetPrimaryCommits() {
+		members = append(members, commit.Hash)
+	}
+	if len(members) == 0 {
+		for _, artifact := range e.Artifacts {
+			members = append(members, "artifact:"+artifact.ID)
+		}
+	}
+	sort.Strings(members)
+
+	sum := sha256.Sum256([]byte(strings.Join(members, "\n")))
+	return "E" + hex.EncodeToString(sum[:])[:episodeIDDigits]
+}
+
+// assignEpisodeIDs gives every episode its content-derived ID
+// Episodes whose members hash alike, which only identical member sets do in practice, get a numeric suffix in order
+func assignEpisodeIDs(episodes []Episode) {
+	seen := make(map[string]int, len(episodes))
+	for i := range episodes {
+		id := EpisodeID(&episodes[i])
+		seen[id]++
+		if seen[id] > 1 {
+			id = fmt.Sprintf("%s-%d", id, seen[id])
+		}
+		episodes[i].ID = id
+	}
+}
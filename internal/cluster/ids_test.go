@@ -0,0 +1,67 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestGroupIntoEpisodes_StableIDs(t *testing.T) {
+	base := time.Date(2024, 9, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	activity := &RepositoryActivity{Commits: []git.Commit{
+		createTestCommit("a0000001", "Add parser", alice, base, []string{"parser.go"}),
+		createTestCommit("a0000002", "Fix parser", alice, base.Add(time.Hour), []string{"parser.go"}),
+		createTestCommit("b0000001", "Rewrite docs", alice, base.Add(30*24*time.Hour), []string{"README.md"}),
+	}}
+
+	before := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(before) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(before))
+	}
+	if !strings.HasPrefix(before[0].ID, "E") || len(before[0].ID) != 1+episodeIDDigits || before[0].ID == before[1].ID {
+		t.Errorf("Expected distinct content-derived IDs, got %s and %s", before[0].ID, before[1].ID)
+	}
+
+	// Earlier history arriving later shifts positions but not the IDs of unchanged episodes
+	activity.Commits = append(activity.Commits,
+		createTestCommit("c0000001", "Initial commit", alice, base.Add(-60*24*time.Hour), []string{"main.go"}),
+		createTestCommit("b0000002", "Polish docs", alice, base.Add(30*24*time.Hour+time.Hour), []string{"README.md"}),
+	)
+	after := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(after) != 3 {
+		t.Fatalf("Expected 3 episodes, got %d", len(after))
+	}
+	if after[1].ID != before[0].ID {
+		t.Errorf("Expected the unchanged parser episode to keep ID %s, got %s", before[0].ID, after[1].ID)
+	}
+	if after[2].ID == before[1].ID {
+		t.Error("Expected the docs episode's ID to change with its commits")
+	}
+}
+
+func TestEpisodeID(t *testing.T) {
+	a := Episode{Commits: []git.Commit{{Hash: "a1"}, {Hash: "a2"}}}
+	reordered := Episode{Commits: []git.Commit{{Hash: "a2"}, {Hash: "a1"}}}
+	if EpisodeID(&a) != EpisodeID(&reordered) {
+		t.Error("Expected the ID not to depend on commit order")
+	}
+
+	shared := Episode{Commits: []git.Commit{{Hash: "a1"}, {Hash: "a2"}, {Hash: "b1"}}, Memberships: map[string]float64{"b1": 0.6}}
+	if EpisodeID(&a) != EpisodeID(&shared) {
+		t.Error("Expected shared commits not to affect the ID")
+	}
+
+	artifactOnly := Episode{Artifacts: []Artifact{{ID: "pr-1"}}}
+	if EpisodeID(&artifactOnly) == EpisodeID(&Episode{}) {
+		t.Error("Expected episodes without commits to be identified by their artifacts")
+	}
+
+	duplicates := []Episode{a, reordered}
+	assignEpisodeIDs(duplicates)
+	if duplicates[1].ID != duplicates[0].ID+"-2" {
+		t.Errorf("Expected a suffix on the duplicate ID, got %s and %s", duplicates[0].ID, duplicates[1].ID)
+	}
+}
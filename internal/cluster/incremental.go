@@ -0,0 +1,210 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// AppendAndRecluster adds new commits and new or updated artifacts to the activity and regroups only
+// the episodes they can affect, keeping every other episode (and its ID) as it was
+// An episode is affected when its commits are within the time gap of a new commit, when it holds an
+// updated artifact, or when its commits reference a new one. The affected episodes' commits and the
+// new commits are regrouped together; regrouped episodes get content-derived IDs, so those whose
+// commits did not change keep their IDs too. Soft memberships are only computed among regrouped episodes,
+// and episodes without commits are rebuilt from whatever artifacts the others leave over
+// Without previous Episodes the whole activity is grouped. The result is stored in Episodes and returned
+func (ra *RepositoryActivity) AppendAndRecluster(newCommits []git.Commit, newArtifacts []Artifact, config GroupingConfig) []Episode {
+	added := ra.appendCommits(newCommits)
+	for _, artifact := range newArtifacts {
+		ra.upsertArtifact(artifact)
+	}
+
+	if len(ra.Episodes) == 0 {
+		ra.Episodes = ra.GroupIntoEpisodes(config)
+		return ra.copyEpisodes()
+	}
+	if len(added) == 0 && len(newArtifacts) == 0 {
+		return ra.copyEpisodes()
+	}
+
+	// Modules and session gaps come from the whole history, not just the regrouped region
+	if config.ModuleWeight > 0 && config.Modules == nil {
+		config.Modules = LearnModules(ra.Commits)
+	}
+	if config.AdaptiveSessionGap && config.SessionGap > 0 && config.AuthorSessionGaps == nil {
+		config.AuthorSessionGaps = LearnAuthorSessionGaps(ra.Commits)
+	}
+	affected := ra.affectedEpisodes(added, newArtifacts, config)
+
+	var kept []Episode
+	var region []git.Commit
+	heldReleases := make(map[string]bool)
+	for i := range ra.Episodes {
+		if len(ra.Episodes[i].Commits) == 0 {
+			continue
+		}
+		if affected[i] {
+			region = append(region, ra.Episodes[i].GetPrimaryCommits()...)
+			continue
+		}
+		kept = append(kept, ra.Episodes[i])
+		for _, artifact := range ra.Episodes[i].Artifacts {
+			if artifact.Type == ArtifactRelease {
+				heldReleases[artifact.ID] = true
+			}
+		}
+	}
+	region = append(region, added...)
+
+	// Regroup the region against the full history's artifacts and tags, minus releases already
+	// attached to kept episodes so they are not attached twice
+	sub := &RepositoryActivity{
+		Platform:       ra.Platform,
+		RepositoryURL:  ra.RepositoryURL,
+		RepositoryName: ra.RepositoryName,
+		Owner:          ra.Owner,
+		DefaultBranch:  ra.DefaultBranch,
+		Commits:        region,
+		Tags:           ra.Tags,
+	}
+	for _, artifact := range ra.Artifacts {
+		if !heldReleases[artifact.ID] {
+			sub.Artifacts = append(sub.Artifacts, artifact)
+		}
+	}
+	subConfig := config
+	subConfig.ArtifactEpisodes = false
+	regrouped := sub.GroupIntoEpisodes(subConfig)
+
+	taken := make(map[string]bool, len(kept))
+	for _, episode := range kept {
+		taken[episode.ID] = true
+	}
+	for i := range regrouped {
+		regrouped[i].ID = unusedEpisodeID(regrouped[i].ID, taken)
+	}
+
+	episodes := append(kept, regrouped...)
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodeStart(&episodes[i]).Before(episodeStart(&episodes[j]))
+	})
+	if config.ArtifactEpisodes {
+		episodes = addArtifactEpisodes(episodes, ra.Artifacts, config)
+		for i := range episodes {
+			if episodes[i].ID == "" {
+				episodes[i].ID = unusedEpisodeID(EpisodeID(&episodes[i]), taken)
+			}
+		}
+		classifyEpisodes(episodes)
+		computeEpisodeStats(episodes)
+	}
+	ra.Episodes = episodes
+	return ra.copyEpisodes()
+}
+
+// unusedEpisodeID returns id, or id with the first numeric suffix not yet taken, and marks it taken
+func unusedEpisodeID(id string, taken map[string]bool) string {
+	unused := id
+	for n := 2; taken[unused]; n++ {
+		unused = fmt.Sprintf("%s-%d", id, n)
+	}
+	taken[unused] = true
+	return unused
+}
+
+// affectedEpisodes reports which of the activity's episodes new commits or artifacts can change
+func (ra *RepositoryActivity) affectedEpisodes(added []git.Commit, newArtifacts []Artifact, config GroupingConfig) map[int]bool {
+	window := config.MaxTimeGap
+	for _, gap := range config.AuthorTimeGaps {
+		window = max(window, gap)
+	}
+
+	updated := make(map[string]bool, len(newArtifacts))
+	for _, artifact := range newArtifacts {
+		updated[artifact.ID] = true
+	}
+	refMap := buildArtifactReferenceMap(newArtifacts)
+
+	affected := make(map[int]bool)
+	for i := range ra.Episodes {
+		episode := &ra.Episodes[i]
+		if len(episode.Commits) == 0 {
+			continue
+		}
+		start := episode.Commits[0].CommittedAt.Add(-window)
+		end := episode.Commits[len(episode.Commits)-1].CommittedAt.Add(window)
+		for _, commit := range added {
+			if !commit.CommittedAt.Before(start) && !commit.CommittedAt.After(end) {
+				affected[i] = true
+				break
+			}
+		}
+		if affected[i] || len(newArtifacts) == 0 {
+			continue
+		}
+
+		for _, artifact := range episode.Artifacts {
+			if updated[artifact.ID] {
+				affected[i] = true
+				break
+			}
+		}
+		for _, commit := range episode.Commits {
+			if affected[i] {
+				break
+			}
+			var referenced Episode
+			addReferencedArtifacts(&referenced, commit, refMap, newArtifacts)
+			affected[i] = len(referenced.Artifacts) > 0
+		}
+	}
+	return affected
+}
+
+// appendCommits adds commits whose hashes are not already present and returns those added
+func (ra *RepositoryActivity) appendCommits(commits []git.Commit) []git.Commit {
+	seen := make(map[string]bool, len(ra.Commits))
+	for _, commit := range ra.Commits {
+		seen[commit.Hash] = true
+	}
+
+	var added []git.Commit
+	for _, commit := range commits {
+		if !seen[commit.Hash] {
+			seen[commit.Hash] = true
+			added = append(added, commit)
+		}
+	}
+	ra.Commits = append(ra.Commits, added...)
+	return added
+}
+
+// upsertArtifact replaces the artifact with the same ID or appends it
+func (ra *RepositoryActivity) upsertArtifact(artifact Artifact) {
+	for i := range ra.Artifacts {
+		if ra.Artifacts[i].ID == artifact.ID {
+			ra.Artifacts[i] = artifact
+			return
+		}
+	}
+	ra.Artifacts = append(ra.Artifacts, artifact)
+}
+
+// copyEpisodes returns a copy of the activity's episodes so callers can't reorder them
+func (ra *RepositoryActivity) copyEpisodes() []Episode {
+	episodes := make([]Episode, len(ra.Episodes))
+	copy(episodes, ra.Episodes)
+	return episodes
+}
+
+// episodeStart returns the episode's earliest commit time, or its date range start without commits
+func episodeStart(e *Episode) time.Time {
+	if len(e.Commits) > 0 {
+		return e.Commits[0].CommittedAt
+	}
+	start, _ := e.GetDateRange()
+	return start
+}
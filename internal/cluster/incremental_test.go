@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func incrementalTestActivity() (*RepositoryActivity, time.Time) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	return &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Add parser", author, base, []string{"parser.go"}),
+			createTestCommit("aaaaaaa2", "Fix #7 in parser", author, base.Add(time.Hour), []string{"parser.go"}),
+			createTestCommit("bbbbbbb1", "Add lexer", author, base.Add(30*24*time.Hour), []string{"lexer.go"}),
+			createTestCommit("bbbbbbb2", "Extend lexer", author, base.Add(30*24*time.Hour+time.Hour), []string{"lexer.go"}),
+		},
+	}, base
+}
+
+func TestAppendAndRecluster_GroupsEverythingFirst(t *testing.T) {
+	ra, _ := incrementalTestActivity()
+	config := DefaultGroupingConfig()
+
+	episodes := ra.AppendAndRecluster(nil, nil, config)
+	full := ra.GroupIntoEpisodes(config)
+	if len(episodes) != len(full) || len(ra.Episodes) != len(full) {
+		t.Fatalf("Expected %d episodes, got %d (stored %d)", len(full), len(episodes), len(ra.Episodes))
+	}
+	for i := range full {
+		if episodes[i].ID != full[i].ID {
+			t.Errorf("Episode %d: expected ID %s, got %s", i, full[i].ID, episodes[i].ID)
+		}
+	}
+}
+
+func TestAppendAndRecluster_OnlyRegroupsNearbyEpisodes(t *testing.T) {
+	ra, base := incrementalTestActivity()
+	config := DefaultGroupingConfig()
+	before := ra.AppendAndRecluster(nil, nil, config)
+	if len(before) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(before))
+	}
+	// A marker on the untouched episode shows it was kept rather than regrouped
+	ra.Episodes[0].Repository = "cached"
+
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	late := createTestCommit("bbbbbbb3", "Finish lexer", author, base.Add(30*24*time.Hour+2*time.Hour), []string{"lexer.go"})
+	episodes := ra.AppendAndRecluster([]git.Commit{late, ra.Commits[0]}, nil, config)
+
+	if len(ra.Commits) != 5 {
+		t.Errorf("Expected only the new commit to be appended, got %d commits", len(ra.Commits))
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(episodes))
+	}
+	if episodes[0].ID != before[0].ID || episodes[0].Repository != "cached" {
+		t.Errorf("Expected the earlier episode to be kept as is, got %s %q", episodes[0].ID, episodes[0].Repository)
+	}
+	if len(episodes[1].Commits) != 3 {
+		t.Errorf("Expected the new commit to join the lexer episode, got %d commits", len(episodes[1].Commits))
+	}
+	if episodes[1].ID == before[1].ID {
+		t.Error("Expected the regrouped episode to get a new ID")
+	}
+	if episodes[1].ID != EpisodeID(&episodes[1]) {
+		t.Errorf("Expected the regrouped episode to get its content ID, got %s", episodes[1].ID)
+	}
+}
+
+func TestAppendAndRecluster_RegroupsEpisodesReferencingNewArtifacts(t *testing.T) {
+	ra, base := incrementalTestActivity()
+	config := DefaultGroupingConfig()
+	before := ra.AppendAndRecluster(nil, nil, config)
+
+	issue := Artifact{ID: "issue-7", Number: 7, Type: ArtifactIssue, Title: "Parser crash", CreatedAt: base}
+	episodes := ra.AppendAndRecluster(nil, []Artifact{issue}, config)
+
+	if len(episodes[0].Artifacts) != 1 || episodes[0].Artifacts[0].ID != "issue-7" {
+		t.Fatalf("Expected the parser episode to pick up issue-7, got %+v", episodes[0].Artifacts)
+	}
+	if episodes[0].ID != before[0].ID || episodes[1].ID != before[1].ID {
+		t.Error("Expected episode IDs to be preserved when commits are unchanged")
+	}
+
+	closed := issue
+	closed.State = "closed"
+	episodes = ra.AppendAndRecluster(nil, []Artifact{closed}, config)
+	if len(ra.Artifacts) != 1 {
+		t.Errorf("Expected the update to replace the artifact, got %d artifacts", len(ra.Artifacts))
+	}
+	if episodes[0].Artifacts[0].State != "closed" {
+		t.Errorf("Expected the updated artifact to be attached, got %q", episodes[0].Artifacts[0].State)
+	}
+}
+
+func TestAppendAndRecluster_KeepsReleasesOnKeptEpisodes(t *testing.T) {
+	ra, base := incrementalTestActivity()
+	ra.Tags = []git.Tag{{Name: "v1.0.0", Hash: "aaaaaaa2", Date: base.Add(2 * time.Hour)}}
+	ra.Artifacts = []Artifact{releaseArtifact("release-v1.0.0", "v1.0.0", base.Add(2*time.Hour))}
+	config := DefaultGroupingConfig()
+	ra.AppendAndRecluster(nil, nil, config)
+
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	late := createTestCommit("bbbbbbb3", "Finish lexer", author, base.Add(30*24*time.Hour+2*time.Hour), []string{"lexer.go"})
+	episodes := ra.AppendAndRecluster([]git.Commit{late}, nil, config)
+
+	releases := 0
+	for _, episode := range episodes {
+		for _, artifact := range episode.Artifacts {
+			if artifact.Type == ArtifactRelease {
+				releases++
+			}
+		}
+	}
+	if releases != 1 {
+		t.Errorf("Expected the release to stay attached exactly once, got %d", releases)
+	}
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import "sort"
+
+// IterationProgress summarizes the planned work of one project iteration
+type IterationProgress struct {
+	Iteration Iteration      `json:"iteration"`
+	Items     int            `json:"items"`
+	Done      int            `json:"done"`     // Items whose artifact is closed or merged
+	Statuses  map[string]int `json:"statuses"` // Item count per board column
+}
+
+// SummarizeIterations groups artifacts by the iterations they are planned in, oldest iteration first
+// An artifact on several boards counts once per distinct iteration
+func SummarizeIterations(artifacts []Artifact) []IterationProgress {
+	byKey := make(map[string]*IterationProgress)
+	order := make([]string, 0)
+
+	for _, artifact := range artifacts {
+		counted := make(map[string]bool)
+		for _, project := range artifact.Metadata.Projects {
+			if project.Iteration == nil {
+				continue
+			}
+			key := iterationKey(*project.Iteration)
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+
+			progress, ok := byKey[key]
+			if !ok {
+				progress = &IterationProgress{Iteration: *project.Iteration, Statuses: make(map[string]int)}
+				byKey[key] = progress
+				order = append(order, key)
+			}
+			progress.Items++
+			if artifact.State == "closed" || artifact.State == "merged" {
+				progress.Done++
+			}
+			if project.Status != "" {
+				progress.Statuses[project.Status]++
+			}
+		}
+	}
+
+	summaries := make([]IterationProgress, 0, len(order))
+	for _, key := range order {
+		summaries = append(summaries, *byKey[key])
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Iteration.StartDate.Before(summaries[j].Iteration.StartDate)
+	})
+	return summaries
+}
+
+// GetIterations returns the iterations the episode's artifacts were planned in, with their progress
+func (e *Episode) GetIterations() []IterationProgress {
+	return SummarizeIterations(e.Artifacts)
+}
+
+// iterationKey identifies an iteration, falling back to its title when the board gave no ID
+func iterationKey(it Iteration) string {
+	if it.ID != "" {
+		return it.ID
+	}
+	return it.Title
+}
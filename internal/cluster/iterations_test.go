@@ -0,0 +1,35 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeIterations(t *testing.T) {
+	sprint1 := &Iteration{ID: "it1", Title: "Sprint 1", StartDate: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)}
+	sprint2 := &Iteration{ID: "it2", Title: "Sprint 2", StartDate: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)}
+
+	artifacts := []Artifact{
+		{ID: "issue-1", State: "open", Metadata: ArtifactMetadata{Projects: []ProjectStatus{{Project: "Roadmap", Status: "In Progress", Iteration: sprint2}}}},
+		{ID: "pr-2", State: "merged", Metadata: ArtifactMetadata{Projects: []ProjectStatus{
+			{Project: "Roadmap", Status: "Done", Iteration: sprint1},
+			{Project: "Team", Status: "Shipped", Iteration: sprint1},
+		}}},
+		{ID: "issue-3", State: "closed", Metadata: ArtifactMetadata{Projects: []ProjectStatus{{Project: "Roadmap", Status: "Done", Iteration: sprint2}}}},
+		{ID: "issue-4", State: "open", Metadata: ArtifactMetadata{Projects: []ProjectStatus{{Project: "Roadmap", Status: "Todo"}}}},
+	}
+
+	summaries := SummarizeIterations(artifacts)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 iterations, got %+v", summaries)
+	}
+
+	first := summaries[0]
+	if first.Iteration.Title != "Sprint 1" || first.Items != 1 || first.Done != 1 {
+		t.Errorf("Expected Sprint 1 first with one done item, got %+v", first)
+	}
+	second := summaries[1]
+	if second.Items != 2 || second.Done != 1 || second.Statuses["In Progress"] != 1 || second.Statuses["Done"] != 1 {
+		t.Errorf("Unexpected Sprint 2 progress: %+v", second)
+	}
+}
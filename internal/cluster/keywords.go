@@ -0,0 +1,180 @@
+package cluster
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeywordConfig controls how commit messages are reduced to keywords for message similarity
+type KeywordConfig struct {
+	// Languages selects the built-in stop-word sets by ISO 639-1 code (see StopWordLanguages);
+	// empty means all of them, so mixed-language histories work without configuration
+	Languages []string
+
+	// StopWords adds project-specific words to ignore, e.g. a product name present in every message
+	StopWords []string
+
+	// Stem strips common inflection suffixes so "fixes", "fixed" and "fixing" all match "fix"
+	Stem bool
+}
+
+// DefaultKeywordConfig returns keyword extraction over all built-in stop-word sets with stemming
+func DefaultKeywordConfig() KeywordConfig {
+	return KeywordConfig{Stem: true}
+}
+
+// stopWordLists holds the built-in stop words by language
+var stopWordLists = map[string][]string{
+	"en": {"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "in", "is", "it", "of", "on", "or",
+		"that", "the", "this", "to", "was", "will", "with", "when", "into", "not"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "fur", "von", "den", "dem", "des", "ein", "eine",
+		"einen", "auf", "bei", "als", "auch", "aus", "wenn", "nach", "beim", "zum", "zur"},
+	"fr": {"le", "la", "les", "des", "une", "un", "et", "est", "pour", "dans", "du", "de", "avec", "sur", "pas",
+		"par", "au", "aux", "lors", "sans", "qui", "que"},
+	"es": {"el", "la", "los", "las", "un", "una", "y", "es", "para", "con", "por", "del", "de", "en", "que",
+		"se", "no", "al", "cuando", "sin"},
+	"pt": {"o", "a", "os", "as", "um", "uma", "e", "para", "com", "por", "do", "da", "dos", "das", "em", "no",
+		"na", "que", "nao", "ao", "quando", "sem"},
+	"it": {"il", "lo", "la", "gli", "le", "un", "una", "e", "per", "con", "di", "del", "della", "che", "non",
+		"in", "su", "nel", "nella", "quando", "senza"},
+	"nl": {"de", "het", "een", "en", "is", "van", "voor", "met", "op", "niet", "te", "dat", "die", "in", "bij",
+		"naar", "als", "wanneer", "zonder"},
+}
+
+// stopWordSets indexes stopWordLists for lookup
+var stopWordSets = func() map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(stopWordLists))
+	for language, words := range stopWordLists {
+		sets[language] = make(map[string]bool, len(words))
+		for _, word := range words {
+			sets[language][word] = true
+		}
+	}
+	return sets
+}()
+
+// StopWordLanguages lists the languages with built-in stop words
+func StopWordLanguages() []string {
+	return []string{"de", "en", "es", "fr", "it", "nl", "pt"}
+}
+
+// isStopWord reports whether a lowercased, accent-folded word is a stop word under the config
+func (c KeywordConfig) isStopWord(word string) bool {
+	if len(c.Languages) == 0 {
+		for _, set := range stopWordSets {
+			if set[word] {
+				return true
+			}
+		}
+	}
+	for _, language := range c.Languages {
+		if stopWordSets[strings.ToLower(language)][word] {
+			return true
+		}
+	}
+	for _, stopWord := range c.StopWords {
+		if foldAccents(strings.ToLower(stopWord)) == word {
+			return true
+		}
+	}
+	return false
+}
+
+// wordPattern matches runs of letters, digits and underscores in any script
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// accentFolder maps accented Latin letters to their base letter
+var accentFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// foldAccents replaces accented Latin letters so "für" and "fur" or "café" and "cafe" match
+func foldAccents(word string) string {
+	return accentFolder.Replace(word)
+}
+
+// extractKeywords extracts meaningful words from commit message
+// Words are lowercased and accent-folded, stop words and words under 3 letters are dropped, and
+// scripts written without spaces (Chinese, Japanese kana) are split into overlapping character pairs
+func extractKeywords(message string, config KeywordConfig) map[string]bool {
+	keywords := make(map[string]bool)
+	for _, word := range wordPattern.FindAllString(strings.ToLower(message), -1) {
+		if isUnspacedScript(word) {
+			for _, pair := range runePairs(word) {
+				keywords[pair] = true
+			}
+			continue
+		}
+
+		word = foldAccents(word)
+		if utf8.RuneCountInString(word) <= 2 || config.isStopWord(word) {
+			continue
+		}
+		if config.Stem {
+			word = stem(word)
+		}
+		keywords[word] = true
+	}
+
+	return keywords
+}
+
+// isUnspacedScript reports whether a word is written in a script that doesn't separate words with spaces
+func isUnspacedScript(word string) bool {
+	for _, r := range word {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			return true
+		}
+	}
+	return false
+}
+
+// runePairs returns a word's overlapping two-character sequences, or the word itself when shorter
+func runePairs(word string) []string {
+	runes := []rune(word)
+	if len(runes) < 2 {
+		return []string{word}
+	}
+	pairs := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		pairs = append(pairs, string(runes[i:i+2]))
+	}
+	return pairs
+}
+
+// stemSuffixes are the inflection suffixes stem strips, longest first
+var stemSuffixes = []string{"ations", "ation", "ings", "ing", "ied", "ies", "ers", "er", "ed", "es", "s"}
+
+// stem strips one common (mostly English) inflection suffix and a trailing "e", keeping at least
+// three letters, so "update", "updates", "updated" and "updating" all become "updat"
+// This is deliberately crude: it only needs related forms of a word to meet, not to produce real words
+func stem(word string) string {
+	for _, suffix := range stemSuffixes {
+		base, ok := strings.CutSuffix(word, suffix)
+		if !ok || utf8.RuneCountInString(base) < 3 || strings.HasSuffix(base, "s") && suffix == "s" {
+			continue
+		}
+		switch suffix {
+		case "ies", "ied":
+			return base + "y"
+		case "ing", "ed", "er", "ers", "ings":
+			// "mapped" -> "map", but "added" -> "add" and "installed" -> "install"
+			if n := len(base); n >= 4 && base[n-1] == base[n-2] && base[n-1] != 'l' && base[n-1] != 's' {
+				base = base[:n-1]
+			}
+		}
+		word = base
+		break
+	}
+
+	if base, ok := strings.CutSuffix(word, "e"); ok && utf8.RuneCountInString(base) >= 3 {
+		return base
+	}
+	return word
+}
@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestExtractKeywords_Stemming(t *testing.T) {
+	config := DefaultKeywordConfig()
+	forms := []string{"Update parser", "Updates parsers", "Updated parsing", "Updating the parser"}
+
+	want := extractKeywords(forms[0], config)
+	for _, message := range forms[1:] {
+		got := extractKeywords(message, config)
+		if len(got) != len(want) {
+			t.Errorf("%q: expected keywords %v, got %v", message, want, got)
+			continue
+		}
+		for keyword := range want {
+			if !got[keyword] {
+				t.Errorf("%q: expected keyword %q, got %v", message, keyword, got)
+			}
+		}
+	}
+
+	if keywords := extractKeywords("Added mapped dependencies", config); !keywords["add"] || !keywords["map"] || !keywords["dependency"] {
+		t.Errorf("Unexpected stems %v", keywords)
+	}
+}
+
+func TestExtractKeywords_Languages(t *testing.T) {
+	keywords := extractKeywords("Füge Prüfung für die Anmeldung hinzu", DefaultKeywordConfig())
+	for _, stopWord := range []string{"fur", "für", "die"} {
+		if keywords[stopWord] {
+			t.Errorf("Expected German stop word %q to be dropped, got %v", stopWord, keywords)
+		}
+	}
+	if !keywords["prufung"] || !keywords["anmeldung"] {
+		t.Errorf("Expected accent-folded German keywords, got %v", keywords)
+	}
+
+	english := KeywordConfig{Languages: []string{"en"}}
+	if keywords := extractKeywords("Corrige les erreurs", english); !keywords["les"] {
+		t.Errorf("Expected French stop words to be kept when only English is selected, got %v", keywords)
+	}
+
+	custom := KeywordConfig{StopWords: []string{"Thunk"}}
+	if keywords := extractKeywords("Thunk: speed up grouping", custom); keywords["thunk"] {
+		t.Errorf("Expected custom stop word to be dropped, got %v", keywords)
+	}
+}
+
+func TestExtractKeywords_UnspacedScripts(t *testing.T) {
+	config := DefaultKeywordConfig()
+	first := extractKeywords("修复登录错误", config)
+	second := extractKeywords("登录页面重构", config)
+
+	if !first["登录"] || !second["登录"] {
+		t.Errorf("Expected both messages to share the pair 登录, got %v and %v", first, second)
+	}
+	if first["修复登录错误"] {
+		t.Error("Expected Chinese text to be split into character pairs")
+	}
+}
+
+func TestCalculateMessageScore_Inflections(t *testing.T) {
+	episode := &Episode{Commits: []git.Commit{{MessageSubject: "Fix flaky uploads"}}}
+	commit := git.Commit{MessageSubject: "Fixes flaky upload"}
+
+	if score := calculateMessageScore(episode, commit, DefaultKeywordConfig()); score != 1.0 {
+		t.Errorf("Expected inflected forms to match fully with stemming, got %f", score)
+	}
+	if score := calculateMessageScore(episode, commit, KeywordConfig{}); score >= 1.0 {
+		t.Errorf("Expected a partial match without stemming, got %f", score)
+	}
+}
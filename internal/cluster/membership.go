@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// Membership selects whether commits and artifacts may belong to more than one episode
+type Membership string
+
+const (
+	// MembershipExclusive places every commit in exactly one episode (the default)
+	MembershipExclusive Membership = "exclusive"
+
+	// MembershipSoft also adds a commit to every other episode it scores MinMembershipScore or more against,
+	// together with the artifacts it references, recording the score in the episode's Memberships
+	MembershipSoft Membership = "soft"
+)
+
+// ParseMembership converts a membership mode name to a Membership; the empty string selects MembershipExclusive
+func ParseMembership(name string) (Membership, error) {
+	switch membership := Membership(strings.ToLower(strings.TrimSpace(name))); membership {
+	case "", MembershipExclusive:
+		return MembershipExclusive, nil
+	case MembershipSoft:
+		return membership, nil
+	default:
+		return "", fmt.Errorf("unknown membership mode %q (supported: %s, %s)", name, MembershipExclusive, MembershipSoft)
+	}
+}
+
+// sharedMember is a commit that also fits an episode other than the one it was grouped into
+type sharedMember struct {
+	episode int
+	commit  git.Commit
+	score   float64
+}
+
+// assignSoftMemberships adds commits to the other episodes they also fit
+// Each commit is scored against the commits of episodes within its time gap as if it followed the
+// episode commit nearest to it; commits a release separates from that neighbor are not shared when
+// splitting on releases. All scores are taken before any episode changes, so the result does not depend on order
+func (ra *RepositoryActivity) assignSoftMemberships(episodes []Episode, commits []git.Commit, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) {
+	threshold := config.MinMembershipScore
+	if threshold <= 0 {
+		threshold = config.MinSimilarityScore
+	}
+
+	window := config.MaxTimeGap
+	for _, gap := range config.AuthorTimeGaps {
+		window = max(window, gap)
+	}
+
+	epochs := make(map[string]int, len(commits))
+	for i, epoch := range releaseEpochs(commits, config, boundaries) {
+		epochs[commits[i].Hash] = epoch
+	}
+
+	var shared []sharedMember
+	for i := range episodes {
+		target := &episodes[i]
+		if len(target.Commits) == 0 {
+			continue
+		}
+		start, end := target.Commits[0].CommittedAt, target.Commits[len(target.Commits)-1].CommittedAt
+
+		for j := range episodes {
+			if i == j || len(episodes[j].Commits) == 0 {
+				continue
+			}
+			source := episodes[j].Commits
+			if source[0].CommittedAt.After(end.Add(window)) || source[len(source)-1].CommittedAt.Before(start.Add(-window)) {
+				continue
+			}
+
+			for _, commit := range source {
+				nearest := nearestCommit(target.Commits, commit)
+				gap := config.MaxTimeGap
+				if authorGap, ok := config.AuthorTimeGaps[git.AuthorKey(commit.Author)]; ok {
+					gap = authorGap
+				}
+				distance := commit.CommittedAt.Sub(target.Commits[nearest].CommittedAt)
+				if distance < 0 {
+					distance = -distance
+				}
+				if distance > gap || epochs[commit.Hash] != epochs[target.Commits[nearest].Hash] {
+					continue
+				}
+
+				// Score against the episode as if the nearest commit were its latest
+				view := Episode{Commits: make([]git.Commit, 0, len(target.Commits)), Artifacts: target.Artifacts}
+				view.Commits = append(view.Commits, target.Commits[:nearest]...)
+				view.Commits = append(view.Commits, target.Commits[nearest+1:]...)
+				view.Commits = append(view.Commits, target.Commits[nearest])
+
+				if similarity := score(&view, commit, config); similarity >= threshold {
+					shared = append(shared, sharedMember{episode: i, commit: commit, score: similarity})
+				}
+			}
+		}
+	}
+
+	for _, member := range shared {
+		addSharedMember(&episodes[member.episode], member.commit, member.score, artifactRefMap, ra.Artifacts)
+	}
+}
+
+// nearestCommit returns the index of the time-ordered commit closest in time to commit
+func nearestCommit(commits []git.Commit, commit git.Commit) int {
+	i := sort.Search(len(commits), func(i int) bool { return !commits[i].CommittedAt.Before(commit.CommittedAt) })
+	if i == len(commits) {
+		return i - 1
+	}
+	if i > 0 && commit.CommittedAt.Sub(commits[i-1].CommittedAt) < commits[i].CommittedAt.Sub(commit.CommittedAt) {
+		return i - 1
+	}
+	return i
+}
+
+// addSharedMember inserts a commit shared from another episode in time order, along with the artifacts
+// it references; shared artifacts keep the highest score of the commits that brought them in
+func addSharedMember(episode *Episode, commit git.Commit, score float64, refMap map[string]*Artifact, allArtifacts []Artifact) {
+	if episode.Memberships == nil {
+		episode.Memberships = make(map[string]float64)
+	}
+	episode.Memberships[commit.Hash] = score
+
+	at := sort.Search(len(episode.Commits), func(i int) bool { return episode.Commits[i].CommittedAt.After(commit.CommittedAt) })
+	episode.Commits = slices.Insert(episode.Commits, at, commit)
+
+	var referenced Episode
+	addReferencedArtifacts(&referenced, commit, refMap, allArtifacts)
+	for _, artifact := range referenced.Artifacts {
+		if episode.hasArtifact(artifact.ID) {
+			if existing, isShared := episode.Memberships[artifact.ID]; isShared && existing < score {
+				episode.Memberships[artifact.ID] = score
+			}
+			continue
+		}
+		episode.Artifacts = append(episode.Artifacts, artifact)
+		episode.Memberships[artifact.ID] = score
+	}
+}
+
+// hasArtifact reports whether the episode contains the artifact
+func (e *Episode) hasArtifact(id string) bool {
+	for _, artifact := range e.Artifacts {
+		if artifact.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// refactorActivity has Alice on auth and Bob on docs, then a refactor of Bob's touching both
+func refactorActivity() *RepositoryActivity {
+	base := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+
+	return &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("a0000001", "Add session store", alice, base, []string{"auth/session.go"}),
+			createTestCommit("a0000002", "Expire session tokens", alice, base.Add(time.Hour), []string{"auth/session.go"}),
+			createTestCommit("b0000001", "Write install guide", bob, base.Add(2*time.Hour), []string{"docs/install.md"}),
+			createTestCommit("b0000002", "Document install flags", bob, base.Add(3*time.Hour), []string{"docs/install.md"}),
+			createTestCommit("c0000001", "Rename session config in install guide (#12)", bob, base.Add(4*time.Hour),
+				[]string{"auth/session.go", "docs/install.md"}),
+		},
+		Artifacts: []Artifact{
+			{ID: "issue-12", Type: ArtifactIssue, Number: 12, Title: "Confusing session config name"},
+		},
+	}
+}
+
+func TestGroupIntoEpisodes_SoftMembership(t *testing.T) {
+	config := DefaultGroupingConfig()
+	exclusive := refactorActivity().GroupIntoEpisodes(config)
+	if len(exclusive) != 2 || CountUniqueCommits(exclusive) != 5 || len(exclusive[0].Commits)+len(exclusive[1].Commits) != 5 {
+		t.Fatalf("Expected every commit in exactly one of 2 episodes, got %d episodes", len(exclusive))
+	}
+
+	config.Membership = MembershipSoft
+	config.MinMembershipScore = 0.3
+	episodes := refactorActivity().GroupIntoEpisodes(config)
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(episodes))
+	}
+	auth, docs := &episodes[0], &episodes[1]
+
+	if len(auth.Commits) != 3 || auth.Commits[2].Hash != "c0000001" {
+		t.Fatalf("Expected the refactor shared into the auth episode, got %d commits", len(auth.Commits))
+	}
+	score := auth.MembershipScore("c0000001")
+	if score < 0.3 || score >= 1 {
+		t.Errorf("Expected a partial membership score, got %f", score)
+	}
+	if !auth.IsShared("issue-12") || auth.MembershipScore("issue-12") != score {
+		t.Errorf("Expected the refactor's issue shared with its score, got %v", auth.Memberships)
+	}
+	if len(auth.GetPrimaryCommits()) != 2 || len(auth.GetSharedCommits()) != 1 {
+		t.Errorf("Expected 2 primary and 1 shared commit, got %d and %d", len(auth.GetPrimaryCommits()), len(auth.GetSharedCommits()))
+	}
+
+	if docs.IsShared("c0000001") || docs.MembershipScore("c0000001") != 1 || docs.MembershipScore("issue-12") != 1 {
+		t.Error("Expected the refactor and its issue to belong to the docs episode primarily")
+	}
+	if auth.MembershipScore("b0000001") != 0 {
+		t.Error("Expected unrelated docs commits not to be shared")
+	}
+
+	if total := CountUniqueCommits(episodes); total != 5 {
+		t.Errorf("Expected shared commits counted once, got %d", total)
+	}
+	if ids := CommitEpisodes(episodes)["c0000001"]; len(ids) != 2 || ids[0] != auth.ID || ids[1] != docs.ID {
+		t.Errorf("Expected the refactor in %s and %s, got %v", auth.ID, docs.ID, ids)
+	}
+	if auth.ID != exclusive[0].ID || docs.ID != exclusive[1].ID {
+		t.Error("Expected shared commits not to change episode IDs")
+	}
+}
+
+func TestGroupIntoEpisodes_SoftMembershipThreshold(t *testing.T) {
+	config := DefaultGroupingConfig()
+	config.Membership = MembershipSoft
+	episodes := refactorActivity().GroupIntoEpisodes(config)
+
+	for _, episode := range episodes {
+		if len(episode.Memberships) != 0 {
+			t.Errorf("Expected nothing shared at MinSimilarityScore, got %v in %s", episode.Memberships, episode.ID)
+		}
+	}
+}
+
+func TestComputeHotspots_SharedCommits(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	commit := createTestCommit("a0000001", "Refactor", alice, time.Now(), []string{"shared.go"})
+	episodes := []Episode{
+		{ID: "E1", Commits: []git.Commit{commit}},
+		{ID: "E2", Commits: []git.Commit{commit}, Memberships: map[string]float64{commit.Hash: 0.6}},
+	}
+
+	hotspots := ComputeHotspots(episodes)
+	if len(hotspots) != 1 || hotspots[0].Changes != 1 || hotspots[0].Churn != 15 || hotspots[0].Episodes != 2 {
+		t.Errorf("Expected a shared commit counted once across 2 episodes, got %+v", hotspots)
+	}
+}
+
+func TestParseMembership(t *testing.T) {
+	for name, expected := range map[string]Membership{"": MembershipExclusive, "exclusive": MembershipExclusive, "Soft": MembershipSoft} {
+		if membership, err := ParseMembership(name); err != nil || membership != expected {
+			t.Errorf("ParseMembership(%q) = %q, %v; expected %q", name, membership, err, expected)
+		}
+	}
+	if _, err := ParseMembership("fuzzy"); err == nil {
+		t.Error("Expected an error for an unknown membership mode")
+	}
+}
@@ -15,6 +15,9 @@ const (
 	PlatformGitLab    SourcePlatform = "gitlab"
 	PlatformBitbucket SourcePlatform = "bitbucket"
 	PlatformLocal     SourcePlatform = "local"
+	PlatformJira      SourcePlatform = "jira"   // Issue tracker only; supplements a code hosting platform
+	PlatformLinear    SourcePlatform = "linear" // Issue tracker only, like Jira
+	PlatformFile      SourcePlatform = "file"   // Artifacts imported from a JSON/NDJSON export
 )
 
 // ArtifactType represents the type of development artifact
@@ -25,6 +28,7 @@ const (
 	ArtifactPullRequest  ArtifactType = "pull_request"
 	ArtifactMergeRequest ArtifactType = "merge_request" // GitLab terminology
 	ArtifactTicket       ArtifactType = "ticket"
+	ArtifactRelease      ArtifactType = "release" // Published release; a milestone marker rather than a work item
 )
 
 // RepositoryActivity represents unified repository data across all platforms
@@ -36,11 +40,20 @@ type RepositoryActivity struct {
 	Owner          string         `json:"owner"`
 	DefaultBranch  string         `json:"default_branch"`
 	Commits        []git.Commit   `json:"commits"`
+	Tags           []git.Tag      `json:"tags,omitempty"` // Release markers, oldest first
 	Artifacts      []Artifact     `json:"artifacts"`
 	FetchedAt      time.Time      `json:"fetched_at"`
+	SyncedAt       time.Time      `json:"synced_at,omitzero"` // When the platform's artifacts were fetched; zero if they weren't
+
+	// Submodule ingestion: path of this repository within its superproject, and nested submodule activity
+	SubmodulePath string               `json:"submodule_path,omitempty"`
+	Submodules    []RepositoryActivity `json:"submodules,omitempty"`
+
+	// Episodes last grouped by AppendAndRecluster, which re-evaluates only those near new activity
+	Episodes []Episode `json:"episodes,omitempty"`
 }
 
-// Artifact represents unified development artifacts (issues, PRs, tickets)
+// Artifact represents unified development artifacts (issues, PRs, tickets, releases)
 // Normalizes issue/PR data across GitHub, GitLab, Bitbucket, etc.
 type Artifact struct {
 	ID          string           `json:"id"`
@@ -64,24 +77,80 @@ type Artifact struct {
 // ArtifactMetadata contains type-specific metadata for artifacts
 type ArtifactMetadata struct {
 	// Pull Request / Merge Request specific
-	BaseBranch     string `json:"base_branch,omitempty"`
-	HeadBranch     string `json:"head_branch,omitempty"`
-	MergeCommitSHA string `json:"merge_commit_sha,omitempty"`
-	Additions      int    `json:"additions,omitempty"`
-	Deletions      int    `json:"deletions,omitempty"`
-	ChangedFiles   int    `json:"changed_files,omitempty"`
-	ReviewState    string `json:"review_state,omitempty"`
-	IsDraft        bool   `json:"is_draft,omitempty"`
+	BaseBranch     string   `json:"base_branch,omitempty"`
+	HeadBranch     string   `json:"head_branch,omitempty"`
+	MergeCommitSHA string   `json:"merge_commit_sha,omitempty"`
+	Additions      int      `json:"additions,omitempty"`
+	Deletions      int      `json:"deletions,omitempty"`
+	ChangedFiles   int      `json:"changed_files,omitempty"`
+	ReviewState    string   `json:"review_state,omitempty"`
+	IsDraft        bool     `json:"is_draft,omitempty"`
+	CommitSHAs     []string `json:"commit_shas,omitempty"`   // Commits on the PR branch, used to link it to episodes
+	Files          []string `json:"files,omitempty"`         // Paths changed by the PR (both sides of renames)
+	ClosesIssues   []int    `json:"closes_issues,omitempty"` // Numbers of the issues the PR closes
 
 	// Issue / Ticket specific
-	Priority  string     `json:"priority,omitempty"`
-	Milestone string     `json:"milestone,omitempty"`
-	DueDate   *time.Time `json:"due_date,omitempty"`
+	Priority   string     `json:"priority,omitempty"`
+	Milestone  string     `json:"milestone,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	TicketKey  string     `json:"ticket_key,omitempty"`  // Issue tracker key, e.g. "PROJ-123" or "ENG-42"
+	TicketType string     `json:"ticket_type,omitempty"` // Issue tracker type, e.g. "Story", "Bug", "Epic"
+	ParentKey  string     `json:"parent_key,omitempty"`  // Key of the epic or parent ticket
+
+	// Release specific
+	TagName      string   `json:"tag_name,omitempty"`
+	IsPrerelease bool     `json:"is_prerelease,omitempty"`
+	Assets       []string `json:"assets,omitempty"` // Names of attached release files
+
+	// Project board placement (GitHub Projects, Linear cycles)
+	Projects []ProjectStatus `json:"projects,omitempty"`
+
+	// CI builds of a pull or merge request, oldest first
+	Builds []Build `json:"builds,omitempty"`
 
 	// Cross-references
 	RelatedArtifacts []string `json:"related_artifacts,omitempty"`
 }
 
+// ProjectStatus records where an artifact sits on a project board
+type ProjectStatus struct {
+	Project   string     `json:"project"`
+	Status    string     `json:"status,omitempty"` // Board column, e.g. "In Progress"
+	Iteration *Iteration `json:"iteration,omitempty"`
+}
+
+// Iteration is a time-boxed sprint from a project board
+type Iteration struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"` // Exclusive
+}
+
+// Build is one CI run (a Jenkins build, a CircleCI workflow, ...) of a commit
+type Build struct {
+	Provider    string      `json:"provider"`       // e.g. "jenkins", "circleci"
+	Name        string      `json:"name,omitempty"` // Job or workflow name
+	Number      int         `json:"number,omitempty"`
+	Status      BuildStatus `json:"status"`
+	CommitSHA   string      `json:"commit_sha,omitempty"`
+	Branch      string      `json:"branch,omitempty"`
+	PullRequest int         `json:"pull_request,omitempty"` // Number of the request built, when the provider knows it
+	URL         string      `json:"url,omitempty"`
+	StartedAt   time.Time   `json:"started_at"`
+	FinishedAt  *time.Time  `json:"finished_at,omitempty"`
+}
+
+// BuildStatus is the normalized outcome of a CI build
+type BuildStatus string
+
+const (
+	BuildSuccess  BuildStatus = "success"
+	BuildFailed   BuildStatus = "failed" // Includes errored and unstable builds
+	BuildCanceled BuildStatus = "canceled"
+	BuildRunning  BuildStatus = "running"
+)
+
 // Discussion represents unified conversation threads
 // Normalizes comments, reviews, and discussion threads across platforms
 type Discussion struct {
@@ -101,6 +170,10 @@ type Discussion struct {
 	LineNumber  int    `json:"line_number,omitempty"`
 	CommitHash  string `json:"commit_hash,omitempty"`
 	ReviewState string `json:"review_state,omitempty"` // approved, changes_requested, commented
+	Resolved    bool   `json:"resolved,omitempty"`     // Review thread was marked resolved
+
+	// Process event specific: the platform's event name, e.g. labeled, assigned, milestoned, closed
+	Event string `json:"event,omitempty"`
 
 	// Engagement
 	Reactions Reactions `json:"reactions,omitempty"`
@@ -113,7 +186,8 @@ const (
 	DiscussionComment      DiscussionType = "comment"
 	DiscussionReview       DiscussionType = "review"
 	DiscussionReviewThread DiscussionType = "review_thread"
-	DiscussionNote         DiscussionType = "note" // GitLab terminology
+	DiscussionNote         DiscussionType = "note"  // GitLab terminology
+	DiscussionEvent        DiscussionType = "event" // Process change (labels, assignees, milestones, state); Body describes it
 )
 
 // Reactions represents engagement reactions on discussions
@@ -131,7 +205,21 @@ type Reactions struct {
 
 // Episode represents a narrative grouping of commits and optionally artifacts
 type Episode struct {
-	ID        string       `json:"id"`
-	Commits   []git.Commit `json:"commits"`
-	Artifacts []Artifact   `json:"artifacts,omitempty"`
+	ID         string       `json:"id"`
+	Repository string       `json:"repository,omitempty"` // owner/name, set when episodes span several repositories
+	Commits    []git.Commit `json:"commits"`
+	Artifacts  []Artifact   `json:"artifacts,omitempty"`
+	Category   Category     `json:"category,omitempty"` // Kind of work, "" when unclassified
+
+	// Membership scores of commits (by hash) and artifacts (by ID) shared from other episodes under
+	// MembershipSoft; members without an entry belong to this episode primarily
+	Memberships map[string]float64 `json:"memberships,omitempty"`
+
+	// Cohesion is the average pairwise similarity of the episode's own commits, and Confidence how
+	// firmly each of them (by hash) belongs to it, both from 0 to 1 (see LowCohesionThreshold)
+	Cohesion   float64            `json:"cohesion,omitempty"`
+	Confidence map[string]float64 `json:"confidence,omitempty"`
+
+	// Size and shape of the episode, computed at grouping time
+	Stats EpisodeStats `json:"stats"`
 }
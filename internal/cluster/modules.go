@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"sort"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// FileModules maps file paths to the module (co-change community) they belong to
+// Files that never changed alongside another file have no module
+type FileModules map[string]int
+
+// moduleMaxCommitFiles skips commits touching more files than this when building the co-change
+// graph, since sweeping changes (formatting, renames, vendoring) say little about structure
+const moduleMaxCommitFiles = 50
+
+// moduleMaxRounds bounds label propagation, which usually settles in a handful of rounds
+const moduleMaxRounds = 20
+
+// LearnModules derives modules from history: files are linked by how often they change in the same
+// commit, each commit contributing 1/(n-1) per pair of its n files so that large commits don't
+// dominate, and weighted label propagation then splits the graph into communities
+// The result is deterministic for the same commits; modules are numbered by their first file in path order
+func LearnModules(commits []git.Commit) FileModules {
+	graph := make(map[string]map[string]float64)
+	for _, commit := range commits {
+		files := commitFiles(commit)
+		if len(files) < 2 || len(files) > moduleMaxCommitFiles {
+			continue
+		}
+		weight := 1 / float64(len(files)-1)
+		for i, a := range files {
+			for _, b := range files[i+1:] {
+				addCoChange(graph, a, b, weight)
+				addCoChange(graph, b, a, weight)
+			}
+		}
+	}
+
+	files := make([]string, 0, len(graph))
+	for file := range graph {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	labels := make(map[string]int, len(files))
+	for i, file := range files {
+		labels[file] = i
+	}
+
+	for round := 0; round < moduleMaxRounds; round++ {
+		changed := false
+		for _, file := range files {
+			weights := make(map[int]float64)
+			for neighbor, weight := range graph[file] {
+				weights[labels[neighbor]] += weight
+			}
+
+			// Take the heaviest neighboring label; on ties keep the current one so propagation
+			// settles, else take the lowest
+			bestWeight := 0.0
+			for _, weight := range weights {
+				bestWeight = max(bestWeight, weight)
+			}
+			best := labels[file]
+			if weights[best] < bestWeight {
+				best = len(files)
+				for label, weight := range weights {
+					if weight == bestWeight && label < best {
+						best = label
+					}
+				}
+			}
+			if best != labels[file] {
+				labels[file] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	modules := make(FileModules, len(files))
+	numbers := make(map[int]int)
+	for _, file := range files {
+		number, ok := numbers[labels[file]]
+		if !ok {
+			number = len(numbers)
+			numbers[labels[file]] = number
+		}
+		modules[file] = number
+	}
+	return modules
+}
+
+// commitFiles returns the distinct paths a commit touches, both sides of renames included, sorted
+func commitFiles(commit git.Commit) []string {
+	seen := make(map[string]bool, len(commit.Diffs))
+	for _, diff := range commit.Diffs {
+		seen[diff.FilePath] = true
+		if diff.OldPath != "" {
+			seen[diff.OldPath] = true
+		}
+	}
+	delete(seen, "")
+
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// addCoChange adds weight to the edge from a to b
+func addCoChange(graph map[string]map[string]float64, a, b string, weight float64) {
+	if graph[a] == nil {
+		graph[a] = make(map[string]float64)
+	}
+	graph[a][b] += weight
+}
+
+// calculateModuleScore calculates overlap between the modules of the commit's and the episode's files
+// using Jaccard similarity
+// The second return value is false when either side touches no file with a module
+func calculateModuleScore(episode *Episode, commit git.Commit, modules FileModules) (float64, bool) {
+	if len(modules) == 0 {
+		return 0, false
+	}
+
+	episodeModules := make(map[int]bool)
+	for _, episodeCommit := range episode.Commits {
+		for _, file := range commitFiles(episodeCommit) {
+			if module, ok := modules[file]; ok {
+				episodeModules[module] = true
+			}
+		}
+	}
+	commitModules := make(map[int]bool)
+	for _, file := range commitFiles(commit) {
+		if module, ok := modules[file]; ok {
+			commitModules[module] = true
+		}
+	}
+	if len(episodeModules) == 0 || len(commitModules) == 0 {
+		return 0, false
+	}
+
+	intersection := 0
+	union := len(episodeModules)
+	for module := range commitModules {
+		if episodeModules[module] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+	return float64(intersection) / float64(union), true
+}
@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// moduleTestHistory returns commits where the auth and billing files each change together
+func moduleTestHistory(base time.Time) []git.Commit {
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	var commits []git.Commit
+	for i := 0; i < 3; i++ {
+		at := base.Add(time.Duration(i) * 24 * time.Hour)
+		commits = append(commits,
+			createTestCommit(fmt.Sprintf("aaaaaa%d", i), "Change auth", author, at, []string{"auth/login.go", "auth/session.go", "web/login.tsx"}),
+			createTestCommit(fmt.Sprintf("bbbbbb%d", i), "Change billing", author, at.Add(time.Hour), []string{"billing/invoice.go", "billing/tax.go"}),
+		)
+	}
+	return commits
+}
+
+func TestLearnModules(t *testing.T) {
+	modules := LearnModules(moduleTestHistory(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)))
+
+	if len(modules) != 5 {
+		t.Fatalf("Expected 5 files with modules, got %v", modules)
+	}
+	if modules["auth/login.go"] != modules["web/login.tsx"] || modules["auth/login.go"] != modules["auth/session.go"] {
+		t.Errorf("Expected the auth files to share a module, got %v", modules)
+	}
+	if modules["billing/invoice.go"] != modules["billing/tax.go"] {
+		t.Errorf("Expected the billing files to share a module, got %v", modules)
+	}
+	if modules["auth/login.go"] == modules["billing/tax.go"] {
+		t.Errorf("Expected auth and billing to be separate modules, got %v", modules)
+	}
+	if modules["auth/login.go"] != 0 {
+		t.Errorf("Expected modules numbered by first file in path order, got %v", modules)
+	}
+}
+
+func TestCalculateModuleScore(t *testing.T) {
+	base := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	modules := LearnModules(moduleTestHistory(base))
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	episode := &Episode{Commits: []git.Commit{createTestCommit("ccccccc1", "Tweak session", author, base, []string{"auth/session.go"})}}
+	sameModule := createTestCommit("ccccccc2", "Restyle login", author, base, []string{"web/login.tsx"})
+	otherModule := createTestCommit("ccccccc3", "Round tax", author, base, []string{"billing/tax.go"})
+	unknown := createTestCommit("ccccccc4", "Edit notes", author, base, []string{"NOTES.md"})
+
+	if score, ok := calculateModuleScore(episode, sameModule, modules); !ok || score != 1.0 {
+		t.Errorf("Expected full module overlap without shared files, got %f (%v)", score, ok)
+	}
+	if score, ok := calculateModuleScore(episode, otherModule, modules); !ok || score != 0 {
+		t.Errorf("Expected no overlap across modules, got %f (%v)", score, ok)
+	}
+	if _, ok := calculateModuleScore(episode, unknown, modules); ok {
+		t.Error("Expected no score for files without a module")
+	}
+}
+
+func TestGroupIntoEpisodes_ModuleWeight(t *testing.T) {
+	base := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+	later := base.Add(30 * 24 * time.Hour)
+
+	ra := &RepositoryActivity{Commits: append(moduleTestHistory(base),
+		createTestCommit("ddddddd1", "Tweak session", alice, later, []string{"auth/session.go"}),
+		createTestCommit("ddddddd2", "Restyle login", bob, later.Add(6*time.Hour), []string{"web/login.tsx"}),
+	)}
+
+	config := DefaultGroupingConfig()
+	config.ArtifactEpisodes = false
+	withoutModules := ra.GroupIntoEpisodes(config)
+
+	config.ModuleWeight = 0.3
+	withModules := ra.GroupIntoEpisodes(config)
+
+	last := withModules[len(withModules)-1]
+	if len(last.Commits) != 2 {
+		t.Errorf("Expected the module bonus to group the session and login commits, got %d commits", len(last.Commits))
+	}
+	if len(withoutModules[len(withoutModules)-1].Commits) != 1 {
+		t.Error("Expected the commits to stay apart without the module bonus")
+	}
+}
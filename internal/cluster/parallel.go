@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minPartitionCommits is the fewest commits worth scoring on a goroutine of their own
+const minPartitionCommits = 256
+
+// partition is a contiguous range [start, end) of time-ordered commits
+type partition struct {
+	start, end int
+}
+
+// partitionCount returns how many time partitions n commits are scored in: one per worker, as long
+// as each gets at least minPartitionCommits commits
+func (c GroupingConfig) partitionCount(n int) int {
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return max(1, min(workers, n/minPartitionCommits))
+}
+
+// timePartitions splits n time-ordered commits into k contiguous partitions of nearly equal size
+func timePartitions(n, k int) []partition {
+	if n == 0 {
+		return nil
+	}
+	k = max(1, min(k, n))
+
+	partitions := make([]partition, k)
+	for p := range partitions {
+		partitions[p] = partition{start: p * n / k, end: (p + 1) * n / k}
+	}
+	return partitions
+}
+
+// runPartitions calls fn for every partition on its own goroutine and waits for all of them
+func runPartitions(partitions []partition, fn func(p int, part partition)) {
+	if len(partitions) == 1 {
+		fn(0, partitions[0])
+		return
+	}
+
+	var wg sync.WaitGroup
+	for p, part := range partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(p, part)
+		}()
+	}
+	wg.Wait()
+}
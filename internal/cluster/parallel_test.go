@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// syntheticHistory generates n commits by a handful of authors working on a few areas of a repository,
+// with gaps from minutes to days between commits
+func syntheticHistory(n int, seed int64) *RepositoryActivity {
+	rng := rand.New(rand.NewSource(seed))
+	authors := []git.Author{
+		{Name: "Ann", Email: "ann@example.com"},
+		{Name: "Ben", Email: "ben@example.com"},
+		{Name: "Cas", Email: "cas@example.com"},
+		{Name: "Dee", Email: "dee@example.com"},
+	}
+	areas := []string{"api", "store", "web/components", "web/pages", "docs", "cmd"}
+	verbs := []string{"Add", "Fix", "Refactor", "Update", "Document"}
+	topics := []string{"cache", "login", "export", "search", "billing", "config"}
+
+	at := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	commits := make([]git.Commit, n)
+	for i := range commits {
+		switch r := rng.Float64(); {
+		case r < 0.6:
+			at = at.Add(time.Duration(5+rng.Intn(120)) * time.Minute)
+		case r < 0.9:
+			at = at.Add(time.Duration(2+rng.Intn(20)) * time.Hour)
+		default:
+			at = at.Add(time.Duration(1+rng.Intn(5)) * 24 * time.Hour)
+		}
+
+		area := areas[rng.Intn(len(areas))]
+		files := make([]string, 1+rng.Intn(3))
+		for f := range files {
+			files[f] = fmt.Sprintf("%s/file%d.go", area, rng.Intn(8))
+		}
+		message := fmt.Sprintf("%s %s %s", verbs[rng.Intn(len(verbs))], topics[rng.Intn(len(topics))], area)
+		commits[i] = createTestCommit(fmt.Sprintf("%040x", i+1), message, authors[rng.Intn(len(authors))], at, files)
+	}
+	return &RepositoryActivity{Commits: commits}
+}
+
+func TestGroupIntoEpisodes_WorkersDoNotChangeResults(t *testing.T) {
+	activity := syntheticHistory(4*minPartitionCommits, 7)
+
+	for _, algorithm := range []Algorithm{AlgorithmGreedy, AlgorithmAgglomerative, AlgorithmDensity} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			config := DefaultGroupingConfig()
+			config.Algorithm = algorithm
+			config.Workers = 1
+			sequential := activity.GroupIntoEpisodes(config)
+
+			for _, workers := range []int{2, 3, 4} {
+				config.Workers = workers
+				if parallel := activity.GroupIntoEpisodes(config); !reflect.DeepEqual(parallel, sequential) {
+					t.Errorf("workers=%d: got %d episodes, want the %d of a sequential run", workers, len(parallel), len(sequential))
+				}
+			}
+		})
+	}
+}
+
+func TestGreedyEpisodes_StitchesEpisodesAcrossPartitions(t *testing.T) {
+	// One author editing one file every ten minutes forms a single episode spanning every partition
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	commits := make([]git.Commit, 2*minPartitionCommits)
+	for i := range commits {
+		commits[i] = createTestCommit(fmt.Sprintf("%040x", i+1), "Tune parser", author, base.Add(time.Duration(i)*10*time.Minute), []string{"parser.go"})
+	}
+
+	config := DefaultGroupingConfig()
+	config.Workers = 2
+	episodes := (&RepositoryActivity{Commits: commits}).GroupIntoEpisodes(config)
+	if len(episodes) != 1 || len(episodes[0].Commits) != len(commits) {
+		t.Fatalf("got %d episodes, want one with all %d commits", len(episodes), len(commits))
+	}
+}
+
+func TestTimePartitions(t *testing.T) {
+	partitions := timePartitions(10, 3)
+	want := []partition{{0, 3}, {3, 6}, {6, 10}}
+	if !reflect.DeepEqual(partitions, want) {
+		t.Errorf("timePartitions(10, 3) = %v, want %v", partitions, want)
+	}
+	if got := timePartitions(2, 5); len(got) != 2 {
+		t.Errorf("timePartitions(2, 5) = %v, want 2 partitions", got)
+	}
+
+	config := GroupingConfig{Workers: 8}
+	if got := config.partitionCount(minPartitionCommits - 1); got != 1 {
+		t.Errorf("partitionCount of a small history = %d, want 1", got)
+	}
+	if got := config.partitionCount(100 * minPartitionCommits); got != 8 {
+		t.Errorf("partitionCount of a large history = %d, want 8", got)
+	}
+}
+
+func BenchmarkGroupIntoEpisodes(b *testing.B) {
+	benchmarks := []struct {
+		algorithm Algorithm
+		commits   int
+	}{
+		{AlgorithmGreedy, 20000},
+		{AlgorithmAgglomerative, 5000},
+	}
+
+	for _, bm := range benchmarks {
+		activity := syntheticHistory(bm.commits, 1)
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%s/commits=%d/workers=%d", bm.algorithm, bm.commits, workers), func(b *testing.B) {
+				config := DefaultGroupingConfig()
+				config.Algorithm = bm.algorithm
+				config.Workers = workers
+				for i := 0; i < b.N; i++ {
+					activity.GroupIntoEpisodes(config)
+				}
+			})
+		}
+	}
+}
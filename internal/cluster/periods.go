@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period is a calendar period episodes are bucketed by for digests
+type Period string
+
+const (
+	PeriodWeek  Period = "week"  // ISO weeks, starting on Monday
+	PeriodMonth Period = "month" // Calendar months
+)
+
+// ParsePeriod returns the period with the given name, ignoring case; an empty name is PeriodWeek
+func ParsePeriod(name string) (Period, error) {
+	switch period := Period(strings.ToLower(strings.TrimSpace(name))); period {
+	case "", PeriodWeek:
+		return PeriodWeek, nil
+	case PeriodMonth:
+		return period, nil
+	default:
+		return "", fmt.Errorf("unknown period %q (supported: %s, %s)", name, PeriodWeek, PeriodMonth)
+	}
+}
+
+// Floor returns the start of the period holding t: midnight on the Monday of its week, or on the
+// first of its month, in t's location
+func (p Period) Floor(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if p == PeriodMonth {
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Add returns the start of the period n periods after the one starting at start
+func (p Period) Add(start time.Time, n int) time.Time {
+	if p == PeriodMonth {
+		return start.AddDate(0, n, 0)
+	}
+	return start.AddDate(0, 0, 7*n)
+}
+
+// Label names the period starting at start, e.g. "2024-W10" or "2024-03"
+func (p Period) Label(start time.Time) string {
+	if p == PeriodMonth {
+		return start.Format("2006-01")
+	}
+	year, week := start.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// PeriodBucket holds the work of one calendar period
+type PeriodBucket struct {
+	Label    string
+	Start    time.Time // First instant of the period
+	End      time.Time // First instant of the next period
+	Episodes []Episode // Episodes with commits in the period, narrowed to those commits
+}
+
+// BucketByPeriod splits the work in episodes into consecutive periods, oldest first, from the period
+// holding since to the one holding until; periods without commits are kept, empty
+// An episode spanning several periods appears in each, narrowed to its commits in that period and to
+// the artifacts holding them or created in it, with statistics of its own
+func BucketByPeriod(episodes []Episode, period Period, since, until time.Time) []PeriodBucket {
+	var buckets []PeriodBucket
+	for start := period.Floor(since); !start.After(until); start = period.Add(start, 1) {
+		buckets = append(buckets, PeriodBucket{Label: period.Label(start), Start: start, End: period.Add(start, 1)})
+	}
+
+	for i := range buckets {
+		bucket := &buckets[i]
+		in := func(t time.Time) bool { return !t.Before(bucket.Start) && t.Before(bucket.End) }
+		for _, ep := range episodes {
+			part := ep
+			part.Commits = nil
+			part.Artifacts = nil
+			shas := make(map[string]bool)
+			for _, commit := range ep.Commits {
+				if in(commit.CommittedAt) {
+					part.Commits = append(part.Commits, commit)
+					shas[commit.Hash] = true
+				}
+			}
+			if len(part.Commits) == 0 {
+				continue
+			}
+			for _, artifact := range ep.Artifacts {
+				holds := in(artifact.CreatedAt)
+				for _, sha := range artifact.Metadata.CommitSHAs {
+					holds = holds || shas[sha]
+				}
+				if holds {
+					part.Artifacts = append(part.Artifacts, artifact)
+				}
+			}
+			part.Stats = part.ComputeStats()
+			bucket.Episodes = append(bucket.Episodes, part)
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestPeriodFloorAndLabel(t *testing.T) {
+	// Sunday 10 March 2024 belongs to the ISO week starting Monday 4 March
+	sunday := time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC)
+	if got := PeriodWeek.Floor(sunday); !got.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the week to start on 4 March, got %s", got)
+	}
+	if got := PeriodWeek.Label(PeriodWeek.Floor(sunday)); got != "2024-W10" {
+		t.Errorf("Expected 2024-W10, got %s", got)
+	}
+	if got := PeriodMonth.Floor(sunday); !got.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the month to start on 1 March, got %s", got)
+	}
+	if got := PeriodMonth.Label(PeriodMonth.Add(PeriodMonth.Floor(sunday), 10)); got != "2025-01" {
+		t.Errorf("Expected 2025-01, got %s", got)
+	}
+
+	if _, err := ParsePeriod("fortnight"); err == nil {
+		t.Error("Expected an error for an unknown period")
+	}
+	if period, err := ParsePeriod("Month"); err != nil || period != PeriodMonth {
+		t.Errorf("Expected PeriodMonth, got %q (error %v)", period, err)
+	}
+}
+
+func TestBucketByPeriod(t *testing.T) {
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	week10 := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	episodes := []Episode{
+		{
+			ID: "E1",
+			Commits: []git.Commit{
+				createTestCommit("commit1", "Start", author, week10, []string{"a.go"}),
+				createTestCommit("commit2", "Finish", author, week10.AddDate(0, 0, 14), []string{"a.go"}),
+			},
+			Artifacts: []Artifact{{ID: "pr", Type: ArtifactPullRequest, CreatedAt: week10.AddDate(0, 0, 13), Metadata: ArtifactMetadata{CommitSHAs: []string{"commit2"}}}},
+		},
+	}
+
+	buckets := BucketByPeriod(episodes, PeriodWeek, week10, week10.AddDate(0, 0, 14))
+	if len(buckets) != 3 {
+		t.Fatalf("Expected 3 weeks, got %d", len(buckets))
+	}
+	if buckets[0].Label != "2024-W10" || len(buckets[0].Episodes) != 1 || len(buckets[0].Episodes[0].Commits) != 1 {
+		t.Errorf("Expected the first commit in %s, got %+v", buckets[0].Label, buckets[0].Episodes)
+	}
+	if len(buckets[0].Episodes[0].Artifacts) != 0 {
+		t.Error("Expected the pull request only in the week holding its commit")
+	}
+	if len(buckets[1].Episodes) != 0 {
+		t.Errorf("Expected a quiet week kept empty, got %d episodes", len(buckets[1].Episodes))
+	}
+	if last := buckets[2]; len(last.Episodes) != 1 || last.Episodes[0].Commits[0].Hash != "commit2" || len(last.Episodes[0].Artifacts) != 1 {
+		t.Errorf("Expected the last commit and its pull request in %s, got %+v", last.Label, last.Episodes)
+	}
+}
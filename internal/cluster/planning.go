@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// commonLabelShare is the share of labeled artifacts above which a label (e.g. "bug") is too common
+// to say which work belongs together; it only applies with at least minLabeledArtifacts labeled artifacts
+const (
+	commonLabelShare    = 0.5
+	minLabeledArtifacts = 4
+)
+
+// planningTags are the milestones and labels work is organized under
+type planningTags struct {
+	milestones map[string]bool
+	labels     map[string]bool
+}
+
+// planningIndex holds the planning tags of the issues and PRs each commit refers to, keyed by hash
+type planningIndex struct {
+	commits map[string]planningTags
+	common  map[string]bool // Labels ignored as too common
+}
+
+// buildPlanningIndex collects the milestones and labels of the artifacts each commit references,
+// merged, or is part of
+func (ra *RepositoryActivity) buildPlanningIndex(commits []git.Commit, artifactRefMap map[string]*Artifact) *planningIndex {
+	index := &planningIndex{
+		commits: make(map[string]planningTags, len(commits)),
+		common:  make(map[string]bool),
+	}
+
+	labeled := 0
+	usage := make(map[string]int)
+	for _, artifact := range ra.Artifacts {
+		labels := make(map[string]bool)
+		for _, label := range artifact.Labels {
+			labels[normalizeLabel(label)] = true
+		}
+		if len(labels) > 0 {
+			labeled++
+		}
+		for label := range labels {
+			usage[label]++
+		}
+	}
+	if labeled >= minLabeledArtifacts {
+		for label, count := range usage {
+			if float64(count) > commonLabelShare*float64(labeled) {
+				index.common[label] = true
+			}
+		}
+	}
+
+	// Pull and merge requests by the SHAs of their commits
+	requests := make(map[string][]int)
+	for i, artifact := range ra.Artifacts {
+		if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+			continue
+		}
+		for _, sha := range artifact.Metadata.CommitSHAs {
+			requests[sha] = append(requests[sha], i)
+		}
+	}
+
+	for _, commit := range commits {
+		var linked Episode
+		addReferencedArtifacts(&linked, commit, artifactRefMap, ra.Artifacts)
+		for _, i := range requests[commit.Hash] {
+			linked.Artifacts = append(linked.Artifacts, ra.Artifacts[i])
+		}
+
+		tags := planningTags{}
+		for _, artifact := range linked.Artifacts {
+			index.addArtifact(&tags, artifact)
+		}
+		if len(tags.milestones) > 0 || len(tags.labels) > 0 {
+			index.commits[commit.Hash] = tags
+		}
+	}
+	return index
+}
+
+// addArtifact adds an artifact's milestone and uncommon labels to tags
+func (p *planningIndex) addArtifact(tags *planningTags, artifact Artifact) {
+	if milestone := strings.TrimSpace(artifact.Metadata.Milestone); milestone != "" {
+		if tags.milestones == nil {
+			tags.milestones = make(map[string]bool)
+		}
+		tags.milestones[strings.ToLower(milestone)] = true
+	}
+	for _, label := range artifact.Labels {
+		label = normalizeLabel(label)
+		if label == "" || p.common[label] {
+			continue
+		}
+		if tags.labels == nil {
+			tags.labels = make(map[string]bool)
+		}
+		tags.labels[label] = true
+	}
+}
+
+// episodeTags returns the planning tags of an episode's commits and artifacts
+func (p *planningIndex) episodeTags(episode *Episode) planningTags {
+	tags := planningTags{}
+	for _, commit := range episode.Commits {
+		commitTags := p.commits[commit.Hash]
+		for milestone := range commitTags.milestones {
+			if tags.milestones == nil {
+				tags.milestones = make(map[string]bool)
+			}
+			tags.milestones[milestone] = true
+		}
+		for label := range commitTags.labels {
+			if tags.labels == nil {
+				tags.labels = make(map[string]bool)
+			}
+			tags.labels[label] = true
+		}
+	}
+	for _, artifact := range episode.Artifacts {
+		p.addArtifact(&tags, artifact)
+	}
+	return tags
+}
+
+// calculatePlanningScores scores the overlap of the commit's and the episode's milestones (1 when
+// they share one) and labels (Jaccard similarity)
+// Each ok is false when either side has no milestone, respectively no label
+func calculatePlanningScores(episode *Episode, commit git.Commit, index *planningIndex) (milestone float64, milestoneOK bool, label float64, labelOK bool) {
+	if index == nil {
+		return 0, false, 0, false
+	}
+	commitTags, ok := index.commits[commit.Hash]
+	if !ok {
+		return 0, false, 0, false
+	}
+	episodeTags := index.episodeTags(episode)
+
+	if len(commitTags.milestones) > 0 && len(episodeTags.milestones) > 0 {
+		milestoneOK = true
+		for name := range commitTags.milestones {
+			if episodeTags.milestones[name] {
+				milestone = 1
+				break
+			}
+		}
+	}
+
+	if len(commitTags.labels) > 0 && len(episodeTags.labels) > 0 {
+		labelOK = true
+		intersection := 0
+		union := len(episodeTags.labels)
+		for name := range commitTags.labels {
+			if episodeTags.labels[name] {
+				intersection++
+			} else {
+				union++
+			}
+		}
+		label = float64(intersection) / float64(union)
+	}
+
+	return milestone, milestoneOK, label, labelOK
+}
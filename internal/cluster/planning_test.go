@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// planningHistory has two unrelated-looking commits six hours apart, each fixing its own issue
+func planningHistory(first, second Artifact) *RepositoryActivity {
+	base := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	return &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Add token refresh (#1)", git.Author{Name: "Ann", Email: "ann@example.com"}, base, []string{"auth/token.go"}),
+			createTestCommit("bbbbbbb2", "Validate session cookie (#2)", git.Author{Name: "Ben", Email: "ben@example.com"}, base.Add(6*time.Hour), []string{"web/cookie.go"}),
+		},
+		Artifacts: []Artifact{first, second},
+	}
+}
+
+func planningIssue(number int, milestone string, labels ...string) Artifact {
+	return Artifact{
+		ID:       fmt.Sprintf("issue-%d", number),
+		Type:     ArtifactIssue,
+		Number:   number,
+		Labels:   labels,
+		Metadata: ArtifactMetadata{Milestone: milestone},
+	}
+}
+
+func TestGroupIntoEpisodes_MilestoneWeight(t *testing.T) {
+	ra := planningHistory(planningIssue(1, "v2.0"), planningIssue(2, "v2.0"))
+
+	config := DefaultGroupingConfig()
+	config.ArtifactEpisodes = false
+	if episodes := ra.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Fatalf("without milestone weight got %d episodes, want 2", len(episodes))
+	}
+
+	config.MilestoneWeight = 0.3
+	if episodes := ra.GroupIntoEpisodes(config); len(episodes) != 1 {
+		t.Errorf("with a shared milestone got %d episodes, want 1", len(episodes))
+	}
+
+	other := planningHistory(planningIssue(1, "v2.0"), planningIssue(2, "v3.0"))
+	if episodes := other.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Errorf("with different milestones got %d episodes, want 2", len(episodes))
+	}
+}
+
+func TestGroupIntoEpisodes_LabelWeight(t *testing.T) {
+	config := DefaultGroupingConfig()
+	config.ArtifactEpisodes = false
+	config.LabelWeight = 0.3
+
+	ra := planningHistory(planningIssue(1, "", "Auth-Epic"), planningIssue(2, "", "auth-epic"))
+	if episodes := ra.GroupIntoEpisodes(config); len(episodes) != 1 {
+		t.Errorf("with a shared label got %d episodes, want 1", len(episodes))
+	}
+
+	// "bug" is on every labeled artifact, so sharing it says nothing
+	ra = planningHistory(planningIssue(1, "", "bug"), planningIssue(2, "", "bug"))
+	ra.Artifacts = append(ra.Artifacts, planningIssue(3, "", "bug", "ui"), planningIssue(4, "", "docs"))
+	if episodes := ra.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Errorf("sharing only a common label got %d episodes, want 2", len(episodes))
+	}
+}
+
+func TestCalculatePlanningScores(t *testing.T) {
+	ra := planningHistory(planningIssue(1, "v2.0", "auth", "ui"), planningIssue(2, "", "auth"))
+	index := ra.buildPlanningIndex(ra.Commits, buildArtifactReferenceMap(ra.Artifacts))
+	episode := &Episode{Commits: ra.Commits[:1]}
+
+	milestone, milestoneOK, label, labelOK := calculatePlanningScores(episode, ra.Commits[1], index)
+	if milestoneOK || milestone != 0 {
+		t.Errorf("milestone = %v (ok %v), want no score when the commit has no milestone", milestone, milestoneOK)
+	}
+	if !labelOK || label != 0.5 {
+		t.Errorf("label = %v (ok %v), want 0.5", label, labelOK)
+	}
+
+	if _, milestoneOK, _, labelOK := calculatePlanningScores(episode, ra.Commits[1], nil); milestoneOK || labelOK {
+		t.Error("scores without an index should be unavailable")
+	}
+}
@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"sort"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// pullRequestEpisodes makes an episode of every merged pull or merge request with commits in the
+// history, holding the request and its commits, and groups the remaining commits with the greedy
+// heuristics
+// A commit belongs to the request it merged (merge or squash commit), whose commits list it, or whose
+// head branch it was on while the request was open; a commit on several requests goes to the first merged
+// Request episodes are kept whatever their size; MinCommits applies to heuristic episodes only
+// Release boundaries and work sessions split heuristic episodes only, as a request's commits ship together
+func (ra *RepositoryActivity) pullRequestEpisodes(commits []git.Commit, config GroupingConfig, score episodeScorer, artifactRefMap map[string]*Artifact, boundaries releaseBoundaries) []Episode {
+	requests := mergedPullRequests(ra.Artifacts)
+
+	owners := make([]int, len(commits))
+	for i, commit := range commits {
+		owners[i] = commitPullRequest(commit, requests, ra.DefaultBranch)
+	}
+
+	grouped := make([]*Episode, len(requests))
+	var orphans []git.Commit
+	for i, commit := range commits {
+		owner := owners[i]
+		if owner < 0 {
+			orphans = append(orphans, commit)
+			continue
+		}
+		if grouped[owner] == nil {
+			grouped[owner] = &Episode{Artifacts: []Artifact{*requests[owner]}}
+		}
+		grouped[owner].Commits = append(grouped[owner].Commits, commit)
+		addReferencedArtifacts(grouped[owner], commit, artifactRefMap, ra.Artifacts)
+	}
+
+	var episodes []Episode
+	for _, episode := range grouped {
+		if episode != nil {
+			episodes = append(episodes, *episode)
+		}
+	}
+	if len(orphans) > 0 {
+		episodes = append(episodes, ra.greedyEpisodes(orphans, config, score, artifactRefMap, boundaries)...)
+	}
+
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].Commits[0].CommittedAt.Before(episodes[j].Commits[0].CommittedAt)
+	})
+	return episodes
+}
+
+// mergedPullRequests returns the merged pull and merge requests among artifacts, earliest merged first
+func mergedPullRequests(artifacts []Artifact) []*Artifact {
+	var requests []*Artifact
+	for i := range artifacts {
+		artifact := &artifacts[i]
+		if (artifact.Type == ArtifactPullRequest || artifact.Type == ArtifactMergeRequest) && artifact.MergedAt != nil {
+			requests = append(requests, artifact)
+		}
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].MergedAt.Before(*requests[j].MergedAt) })
+	return requests
+}
+
+// commitPullRequest returns the index of the merged request a commit belongs to, or -1
+// The request the commit merged wins over requests listing it, which win over head branch matches
+// defaultBranch is the repository's default branch, whose commits never match a head branch
+func commitPullRequest(commit git.Commit, requests []*Artifact, defaultBranch string) int {
+	if commit.PullRequestNumber > 0 {
+		for i, request := range requests {
+			if request.Number == commit.PullRequestNumber {
+				return i
+			}
+		}
+	}
+
+	for i, request := range requests {
+		if request.Metadata.MergeCommitSHA == commit.Hash || pullRequestContainsCommit(request, commit.Hash) {
+			return i
+		}
+	}
+
+	// Branch names are reused, so only commits made while the request was open count
+	if commit.Branch == nil || isDefaultBranch(commit.Branch.Name, defaultBranch) {
+		return -1
+	}
+	branch := shortBranchName(commit.Branch.Name)
+	for i, request := range requests {
+		if request.Metadata.HeadBranch == branch &&
+			!commit.CommittedAt.Before(request.CreatedAt) && !commit.CommittedAt.After(*request.MergedAt) {
+			return i
+		}
+	}
+	return -1
+}
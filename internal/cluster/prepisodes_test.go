@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestGroupIntoEpisodes_PullRequestMode(t *testing.T) {
+	base := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@example.com"}
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+	merged := func(hours int) *time.Time {
+		mergedAt := at(hours)
+		return &mergedAt
+	}
+
+	squash := createTestCommit("c0000001", "Add lexer (#11)", bob, at(3), []string{"lexer.go"})
+	squash.PullRequestNumber = 11
+	branchCommit := createTestCommit("d0000001", "Tune cache", alice, at(4), []string{"cache.go"})
+	branchCommit.Branch = &git.Branch{Name: "origin/cache-tuning"}
+	staleBranch := createTestCommit("d0000002", "Old cache work", alice, at(-48), []string{"cache.go"})
+	staleBranch.Branch = &git.Branch{Name: "origin/cache-tuning"}
+
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{
+			staleBranch,
+			createTestCommit("a0000001", "Add parser", alice, at(0), []string{"parser.go"}),
+			createTestCommit("b0000001", "Bump dependencies", bob, at(1), []string{"go.mod"}),
+			createTestCommit("a0000002", "Test parser", alice, at(2), []string{"parser_test.go"}),
+			squash,
+			branchCommit,
+			createTestCommit("b0000002", "Bump dependencies again", bob, at(5), []string{"go.mod"}),
+		},
+		Artifacts: []Artifact{
+			{
+				ID: "pr-10", Type: ArtifactPullRequest, Number: 10, CreatedAt: at(0), MergedAt: merged(6),
+				Metadata:    ArtifactMetadata{CommitSHAs: []string{"a0000001", "a0000002"}, ClosesIssues: []int{5}},
+				Discussions: []Discussion{{ID: "r1", Type: DiscussionReviewThread, Body: "Handle EOF"}},
+			},
+			{ID: "pr-11", Type: ArtifactPullRequest, Number: 11, CreatedAt: at(2), MergedAt: merged(3)},
+			{
+				ID: "pr-12", Type: ArtifactPullRequest, Number: 12, CreatedAt: at(3), MergedAt: merged(7),
+				Metadata: ArtifactMetadata{HeadBranch: "cache-tuning"},
+			},
+			{
+				ID: "pr-13", Type: ArtifactPullRequest, Number: 13, CreatedAt: at(0),
+				Metadata: ArtifactMetadata{CommitSHAs: []string{"b0000001"}},
+			},
+			{ID: "issue-5", Type: ArtifactIssue, Number: 5, Title: "Parser needed"},
+		},
+	}
+
+	config := DefaultGroupingConfig()
+	config.Algorithm = AlgorithmPullRequest
+	episodes := activity.GroupIntoEpisodes(config)
+
+	expected := []struct {
+		commits   []string
+		artifacts []string
+	}{
+		{[]string{"d0000002"}, nil},
+		{[]string{"a0000001", "a0000002"}, []string{"pr-10", "issue-5"}},
+		{[]string{"b0000001", "b0000002"}, []string{"pr-13"}},
+		{[]string{"c0000001"}, []string{"pr-11"}},
+		{[]string{"d0000001"}, []string{"pr-12"}},
+	}
+	if len(episodes) != len(expected) {
+		t.Fatalf("Expected %d episodes, got %d", len(expected), len(episodes))
+	}
+	for i, want := range expected {
+		episode := episodes[i]
+		if len(episode.Commits) != len(want.commits) {
+			t.Errorf("%s: expected commits %v, got %d", episode.ID, want.commits, len(episode.Commits))
+			continue
+		}
+		for j, hash := range want.commits {
+			if episode.Commits[j].Hash != hash {
+				t.Errorf("%s: expected commit %s at %d, got %s", episode.ID, hash, j, episode.Commits[j].Hash)
+			}
+		}
+		for _, id := range want.artifacts {
+			if !episode.hasArtifact(id) {
+				t.Errorf("%s: expected artifact %s, got %d artifacts", episode.ID, id, len(episode.Artifacts))
+			}
+		}
+	}
+
+	if threads := episodes[1].Artifacts[0].GetReviewThreads(); len(threads) != 1 {
+		t.Errorf("Expected the PR episode to carry its review discussion, got %d threads", len(threads))
+	}
+}
+
+func TestGroupIntoEpisodes_PullRequestModeWithoutRequests(t *testing.T) {
+	base := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	activity := &RepositoryActivity{Commits: []git.Commit{
+		createTestCommit("a0000001", "Add parser", alice, base, []string{"parser.go"}),
+		createTestCommit("a0000002", "Fix parser", alice, base.Add(time.Hour), []string{"parser.go"}),
+	}}
+
+	config := DefaultGroupingConfig()
+	config.Algorithm = AlgorithmPullRequest
+	pr := activity.GroupIntoEpisodes(config)
+	greedy := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(pr) != len(greedy) || len(pr) != 1 || pr[0].ID != greedy[0].ID {
+		t.Errorf("Expected the greedy grouping without pull requests, got %d episodes", len(pr))
+	}
+}
@@ -0,0 +1,208 @@
+package cluster
+
+import "time"
+
+// minPullRequestFileOverlap is the fraction of a PR's files an episode must touch to be linked by files alone
+const minPullRequestFileOverlap = 0.5
+
+// pullRequestContainsCommit reports whether a pull or merge request lists the commit among its own
+func pullRequestContainsCommit(artifact *Artifact, hash string) bool {
+	if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+		return false
+	}
+	for _, sha := range artifact.Metadata.CommitSHAs {
+		if sha == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkPullRequests links the activity's commits and pull or merge requests by number, once its
+// artifacts are fetched, and returns the number of links made
+// A commit whose hash is a request's merge commit records the request's number, and a request
+// without a merge commit records the commit that merged it, as named by the commit's message
+func (ra *RepositoryActivity) LinkPullRequests() int {
+	merged := make(map[string]int)
+	for i := range ra.Artifacts {
+		artifact := &ra.Artifacts[i]
+		if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+			continue
+		}
+		if artifact.Number > 0 && artifact.Metadata.MergeCommitSHA != "" {
+			merged[artifact.Metadata.MergeCommitSHA] = artifact.Number
+		}
+	}
+
+	links := 0
+	for i := range ra.Commits {
+		commit := &ra.Commits[i]
+		if commit.PullRequestNumber == 0 {
+			if number, ok := merged[commit.Hash]; ok {
+				commit.PullRequestNumber = number
+				links++
+			}
+			continue
+		}
+
+		request := findPullRequest(ra.Artifacts, commit.PullRequestNumber)
+		if request != nil && request.Metadata.MergeCommitSHA == "" {
+			request.Metadata.MergeCommitSHA = commit.Hash
+			links++
+		}
+	}
+	return links
+}
+
+// linkPullRequestsByFiles attaches PRs that no episode picked up by reference or SHA to the episode
+// whose changed files cover most of the PR's files, among episodes active while the PR was open
+// This catches squash merges and rebased branches whose commits no longer match the PR's SHAs
+// With shared set, the PR is also shared with every other episode covering enough of its files,
+// scored by that coverage relative to the best episode's
+func linkPullRequestsByFiles(episodes []Episode, artifacts []Artifact, slack time.Duration, shared bool) {
+	linked := make(map[string]bool)
+	for _, episode := range episodes {
+		for _, artifact := range episode.Artifacts {
+			linked[artifact.ID] = true
+		}
+	}
+
+	episodeFiles := make([]map[string]bool, len(episodes))
+	for i := range episodes {
+		episodeFiles[i] = episodePaths(&episodes[i])
+	}
+
+	for i := range artifacts {
+		artifact := &artifacts[i]
+		if linked[artifact.ID] || len(artifact.Metadata.Files) == 0 {
+			continue
+		}
+		if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+			continue
+		}
+
+		opened, closed := pullRequestWindow(artifact)
+		opened = opened.Add(-slack)
+		closed = closed.Add(slack)
+
+		best, bestOverlap := -1, 0.0
+		overlaps := make([]float64, len(episodes))
+		for j := range episodes {
+			start, end := episodes[j].GetDateRange()
+			if end.Before(opened) || start.After(closed) {
+				continue
+			}
+
+			overlaps[j] = fileCoverage(artifact.Metadata.Files, episodeFiles[j])
+			if overlaps[j] > bestOverlap {
+				best, bestOverlap = j, overlaps[j]
+			}
+		}
+
+		if best < 0 || bestOverlap < minPullRequestFileOverlap {
+			continue
+		}
+		episodes[best].Artifacts = append(episodes[best].Artifacts, *artifact)
+		linked[artifact.ID] = true
+
+		if !shared {
+			continue
+		}
+		for j, overlap := range overlaps {
+			if j == best || overlap < minPullRequestFileOverlap {
+				continue
+			}
+			if episodes[j].Memberships == nil {
+				episodes[j].Memberships = make(map[string]float64)
+			}
+			episodes[j].Artifacts = append(episodes[j].Artifacts, *artifact)
+			episodes[j].Memberships[artifact.ID] = overlap / bestOverlap
+		}
+	}
+}
+
+// pullRequestWindow returns when a PR was opened and when it was merged or closed
+// Open PRs extend to their last update
+func pullRequestWindow(artifact *Artifact) (time.Time, time.Time) {
+	end := artifact.UpdatedAt
+	if artifact.MergedAt != nil {
+		end = *artifact.MergedAt
+	} else if artifact.ClosedAt != nil {
+		end = *artifact.ClosedAt
+	}
+	if end.Before(artifact.CreatedAt) {
+		end = artifact.CreatedAt
+	}
+	return artifact.CreatedAt, end
+}
+
+// episodePaths collects every path changed by an episode's commits, including rename sources
+func episodePaths(episode *Episode) map[string]bool {
+	paths := make(map[string]bool)
+	for _, commit := range episode.Commits {
+		for _, diff := range commit.Diffs {
+			if diff.FilePath != "" {
+				paths[diff.FilePath] = true
+			}
+			if diff.OldPath != "" {
+				paths[diff.OldPath] = true
+			}
+		}
+	}
+	return paths
+}
+
+// fileCoverage returns the fraction of files present in paths
+func fileCoverage(files []string, paths map[string]bool) float64 {
+	if len(files) == 0 {
+		return 0
+	}
+
+	covered := 0
+	for _, file := range files {
+		if paths[file] {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(files))
+}
+
+// attachClosedIssues adds the issues closed by each episode's pull requests to the episode
+// An issue belongs with the work that resolved it even when no commit message mentions it
+// Issues closed by a pull request shared from another episode are shared with the PR's score
+func attachClosedIssues(episodes []Episode, artifacts []Artifact) {
+	issues := make(map[int]*Artifact)
+	for i := range artifacts {
+		if artifacts[i].Type == ArtifactIssue || artifacts[i].Type == ArtifactTicket {
+			issues[artifacts[i].Number] = &artifacts[i]
+		}
+	}
+	if len(issues) == 0 {
+		return
+	}
+
+	for i := range episodes {
+		episode := &episodes[i]
+		present := make(map[string]bool, len(episode.Artifacts))
+		for _, artifact := range episode.Artifacts {
+			present[artifact.ID] = true
+		}
+
+		// Index-based loop: closed issues appended below are not pull requests and need no visit
+		for j := 0; j < len(episode.Artifacts); j++ {
+			artifact := episode.Artifacts[j]
+			if artifact.Type != ArtifactPullRequest && artifact.Type != ArtifactMergeRequest {
+				continue
+			}
+			for _, number := range artifact.Metadata.ClosesIssues {
+				if issue, ok := issues[number]; ok && !present[issue.ID] {
+					episode.Artifacts = append(episode.Artifacts, *issue)
+					present[issue.ID] = true
+					if score, shared := episode.Memberships[artifact.ID]; shared {
+						episode.Memberships[issue.ID] = score
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestGroupIntoEpisodes_LinksPullRequestByCommitSHA(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{
+			// Neither message references the PR
+			createTestCommit("aaaaaaa1", "Start parser", alice, base, []string{"parser.go"}),
+			createTestCommit("bbbbbbb1", "Handle comments", alice, base.Add(time.Hour), []string{"parser.go"}),
+		},
+		Artifacts: []Artifact{{
+			ID:       "pr-1",
+			Number:   5,
+			Type:     ArtifactPullRequest,
+			Metadata: ArtifactMetadata{CommitSHAs: []string{"aaaaaaa1", "bbbbbbb1"}},
+		}},
+	}
+
+	episodes := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 {
+		t.Fatalf("Expected 1 episode, got %d", len(episodes))
+	}
+	if len(episodes[0].Artifacts) != 1 || episodes[0].Artifacts[0].ID != "pr-1" {
+		t.Errorf("Expected PR linked once by commit SHA, got %+v", episodes[0].Artifacts)
+	}
+}
+
+func TestCalculateArtifactScore_PullRequestCommit(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	episode := &Episode{Artifacts: []Artifact{{
+		ID:       "pr-1",
+		Type:     ArtifactPullRequest,
+		Metadata: ArtifactMetadata{CommitSHAs: []string{"aaaaaaa1"}},
+	}}}
+
+	if score := calculateArtifactScore(episode, createTestCommit("aaaaaaa1", "Tweak", alice, time.Now(), nil)); score != 1.0 {
+		t.Errorf("Expected a commit of an episode PR to score 1.0, got %v", score)
+	}
+	if score := calculateArtifactScore(episode, createTestCommit("bbbbbbb1", "Tweak", alice, time.Now(), nil)); score != 0.0 {
+		t.Errorf("Expected an unrelated commit to score 0, got %v", score)
+	}
+}
+
+func TestLinkPullRequestsByFiles(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	merged := base.Add(2 * time.Hour)
+
+	episodes := []Episode{
+		{ID: "E1", Commits: []git.Commit{createTestCommit("aaaaaaa1", "Docs", alice, base, []string{"README.md"})}},
+		{ID: "E2", Commits: []git.Commit{createTestCommit("aaaaaaa2", "Squashed", alice, base.Add(time.Hour), []string{"parser.go", "lexer.go"})}},
+		{ID: "E3", Commits: []git.Commit{createTestCommit("aaaaaaa3", "Later", alice, base.Add(30*24*time.Hour), []string{"parser.go", "lexer.go"})}},
+	}
+	artifacts := []Artifact{
+		{
+			ID: "pr-squashed", Type: ArtifactPullRequest, CreatedAt: base, MergedAt: &merged,
+			Metadata: ArtifactMetadata{Files: []string{"parser.go", "lexer.go", "parser_test.go"}},
+		},
+		{
+			ID: "pr-unrelated", Type: ArtifactPullRequest, CreatedAt: base, MergedAt: &merged,
+			Metadata: ArtifactMetadata{Files: []string{"ci.yml", "Makefile"}},
+		},
+		{
+			ID: "issue-files", Type: ArtifactIssue, CreatedAt: base,
+			Metadata: ArtifactMetadata{Files: []string{"parser.go"}},
+		},
+	}
+
+	linkPullRequestsByFiles(episodes, artifacts, time.Hour, false)
+
+	if len(episodes[1].Artifacts) != 1 || episodes[1].Artifacts[0].ID != "pr-squashed" {
+		t.Errorf("Expected squashed PR linked to E2 by file overlap, got %+v", episodes[1].Artifacts)
+	}
+	if len(episodes[0].Artifacts) != 0 || len(episodes[2].Artifacts) != 0 {
+		t.Error("Expected no links outside the PR's time window or without file overlap")
+	}
+}
+
+func TestLinkPullRequestsByFiles_SkipsAlreadyLinked(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	pr := Artifact{ID: "pr-1", Type: ArtifactPullRequest, CreatedAt: base, UpdatedAt: base,
+		Metadata: ArtifactMetadata{Files: []string{"parser.go"}}}
+
+	episodes := []Episode{
+		{ID: "E1", Commits: []git.Commit{createTestCommit("aaaaaaa1", "One", alice, base, []string{"README.md"})}, Artifacts: []Artifact{pr}},
+		{ID: "E2", Commits: []git.Commit{createTestCommit("aaaaaaa2", "Two", alice, base, []string{"parser.go"})}},
+	}
+
+	linkPullRequestsByFiles(episodes, []Artifact{pr}, time.Hour, false)
+
+	if len(episodes[1].Artifacts) != 0 {
+		t.Error("Expected a PR already linked by reference not to be linked again")
+	}
+}
+
+func TestAttachClosedIssues(t *testing.T) {
+	issue := Artifact{ID: "issue-100", Type: ArtifactIssue, Number: 7}
+	pr := Artifact{ID: "pr-200", Type: ArtifactPullRequest, Number: 9, Metadata: ArtifactMetadata{ClosesIssues: []int{7, 99}}}
+	episodes := []Episode{
+		{ID: "E1", Artifacts: []Artifact{pr}},
+		{ID: "E2", Artifacts: []Artifact{pr, issue}},
+	}
+
+	attachClosedIssues(episodes, []Artifact{issue, pr})
+
+	if len(episodes[0].Artifacts) != 2 || episodes[0].Artifacts[1].ID != "issue-100" {
+		t.Errorf("Expected closed issue attached to E1, got %v", episodes[0].Artifacts)
+	}
+	if len(episodes[1].Artifacts) != 2 {
+		t.Errorf("Expected E2 to keep its existing issue without duplication, got %d artifacts", len(episodes[1].Artifacts))
+	}
+}
+
+func TestLinkPullRequestsByFiles_Shared(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	merged := base.Add(2 * time.Hour)
+	pr := Artifact{
+		ID: "pr-1", Type: ArtifactPullRequest, Number: 1, CreatedAt: base, MergedAt: &merged,
+		Metadata: ArtifactMetadata{Files: []string{"parser.go", "lexer.go"}, ClosesIssues: []int{5}},
+	}
+	issue := Artifact{ID: "issue-5", Type: ArtifactIssue, Number: 5}
+
+	episodes := []Episode{
+		{ID: "E1", Commits: []git.Commit{createTestCommit("aaaaaaa1", "Parser", alice, base, []string{"parser.go", "lexer.go"})}},
+		{ID: "E2", Commits: []git.Commit{createTestCommit("aaaaaaa2", "Lexer", alice, base.Add(time.Hour), []string{"lexer.go"})}},
+	}
+
+	linkPullRequestsByFiles(episodes, []Artifact{pr}, time.Hour, true)
+	attachClosedIssues(episodes, []Artifact{pr, issue})
+
+	if episodes[0].IsShared("pr-1") || episodes[0].MembershipScore("pr-1") != 1 {
+		t.Error("Expected the PR to belong primarily to the episode covering all its files")
+	}
+	if score := episodes[1].MembershipScore("pr-1"); !episodes[1].IsShared("pr-1") || score != 0.5 {
+		t.Errorf("Expected the PR shared with E2 at 0.5, got %f", score)
+	}
+	if score := episodes[1].MembershipScore("issue-5"); score != 0.5 {
+		t.Errorf("Expected the closed issue shared with the PR's score, got %f", score)
+	}
+}
+
+func TestLinkPullRequests(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+
+	// Rebase merges keep the branch's messages, so only the PR's merge SHA names the commit
+	rebased := createTestCommit("aaaaaaa1", "Start parser", alice, base, []string{"parser.go"})
+	squashed := createTestCommit("bbbbbbb1", "Handle comments (#6)", alice, base.Add(time.Hour), []string{"parser.go"})
+	squashed.PullRequestNumber = 6
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{rebased, squashed},
+		Artifacts: []Artifact{
+			{ID: "issue-5", Number: 5, Type: ArtifactIssue},
+			{ID: "pr-5", Number: 5, Type: ArtifactPullRequest, Metadata: ArtifactMetadata{MergeCommitSHA: "aaaaaaa1"}},
+			{ID: "pr-6", Number: 6, Type: ArtifactPullRequest},
+		},
+	}
+
+	if links := activity.LinkPullRequests(); links != 2 {
+		t.Errorf("Expected 2 links, got %d", links)
+	}
+	if activity.Commits[0].PullRequestNumber != 5 {
+		t.Errorf("Expected the merge commit to record PR 5, got %d", activity.Commits[0].PullRequestNumber)
+	}
+	if activity.Artifacts[2].Metadata.MergeCommitSHA != "bbbbbbb1" {
+		t.Errorf("Expected PR 6 to record its squash commit, got %q", activity.Artifacts[2].Metadata.MergeCommitSHA)
+	}
+	if activity.Artifacts[0].Metadata.MergeCommitSHA != "" {
+		t.Errorf("Expected the issue sharing PR 5's number left alone, got %q", activity.Artifacts[0].Metadata.MergeCommitSHA)
+	}
+
+	// Linking again finds nothing new
+	if links := activity.LinkPullRequests(); links != 0 {
+		t.Errorf("Expected no new links, got %d", links)
+	}
+}
@@ -0,0 +1,192 @@
+package cluster
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// releaseMarker is a point in history where a release shipped
+type releaseMarker struct {
+	tag      string
+	hash     string    // Tagged commit, when the tag is known locally
+	date     time.Time // Publication date (or tag date when there is no release)
+	artifact *Artifact // Release artifact, nil for a plain git tag
+}
+
+// releaseMarkers merges release artifacts with git tags into milestone markers, oldest first
+// A release and a tag with the same name become one marker carrying both the commit and the release
+func (ra *RepositoryActivity) releaseMarkers() []releaseMarker {
+	byTag := make(map[string]*releaseMarker)
+	markers := make([]*releaseMarker, 0)
+
+	for _, tag := range ra.Tags {
+		marker := &releaseMarker{tag: tag.Name, hash: tag.Hash, date: tag.Date}
+		byTag[tag.Name] = marker
+		markers = append(markers, marker)
+	}
+
+	for i := range ra.Artifacts {
+		artifact := &ra.Artifacts[i]
+		if artifact.Type != ArtifactRelease {
+			continue
+		}
+
+		tag := artifact.Metadata.TagName
+		if marker, ok := byTag[tag]; ok && tag != "" {
+			marker.artifact = artifact
+			marker.date = artifact.CreatedAt
+			continue
+		}
+
+		marker := &releaseMarker{tag: tag, date: artifact.CreatedAt, artifact: artifact}
+		if tag != "" {
+			byTag[tag] = marker
+		}
+		markers = append(markers, marker)
+	}
+
+	sorted := make([]releaseMarker, len(markers))
+	for i, marker := range markers {
+		sorted[i] = *marker
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].date.Before(sorted[j].date) })
+	return sorted
+}
+
+// splitsOnReleases reports whether releases are hard episode boundaries
+func (c GroupingConfig) splitsOnReleases() bool {
+	return c.SplitOnReleases || len(c.BoundaryTags) > 0
+}
+
+// boundaryMarkers returns the markers whose tag matches one of patterns, or all markers without patterns
+// Malformed patterns match nothing
+func boundaryMarkers(markers []releaseMarker, patterns []string) []releaseMarker {
+	if len(patterns) == 0 {
+		return markers
+	}
+
+	matching := make([]releaseMarker, 0, len(markers))
+	for _, marker := range markers {
+		for _, pattern := range patterns {
+			if matched, err := path.Match(pattern, marker.tag); err == nil && matched {
+				matching = append(matching, marker)
+				break
+			}
+		}
+	}
+	return matching
+}
+
+// releaseBoundaries indexes markers for fast boundary checks during grouping
+type releaseBoundaries struct {
+	tagged map[string]bool // Hashes of commits that were released
+	dated  []time.Time     // Publication times of releases whose commit is unknown, ascending
+}
+
+// newReleaseBoundaries builds boundary lookups from markers
+func newReleaseBoundaries(markers []releaseMarker) releaseBoundaries {
+	boundaries := releaseBoundaries{tagged: make(map[string]bool)}
+	for _, marker := range markers {
+		if marker.hash != "" {
+			boundaries.tagged[marker.hash] = true
+		} else if !marker.date.IsZero() {
+			boundaries.dated = append(boundaries.dated, marker.date)
+		}
+	}
+	return boundaries
+}
+
+// separates reports whether a release shipped between two consecutive commits
+// A released commit closes its episode; releases without a known commit split by publication time
+func (b releaseBoundaries) separates(previous, next git.Commit) bool {
+	if b.tagged[previous.Hash] {
+		return true
+	}
+
+	i := sort.Search(len(b.dated), func(i int) bool { return !b.dated[i].Before(previous.CommittedAt) })
+	return i < len(b.dated) && b.dated[i].Before(next.CommittedAt)
+}
+
+// attachReleases adds each release artifact to the episode that shipped in it
+// That is the episode containing the tagged commit, or else the last episode with work before publication
+func attachReleases(episodes []Episode, markers []releaseMarker) {
+	for _, marker := range markers {
+		if marker.artifact == nil {
+			continue
+		}
+		if index := releaseEpisode(episodes, marker); index >= 0 {
+			episodes[index].Artifacts = append(episodes[index].Artifacts, *marker.artifact)
+		}
+	}
+}
+
+// releaseEpisode finds the index of the episode a release belongs to, or -1
+func releaseEpisode(episodes []Episode, marker releaseMarker) int {
+	if marker.hash != "" {
+		for i := range episodes {
+			for _, commit := range episodes[i].Commits {
+				if commit.Hash == marker.hash {
+					return i
+				}
+			}
+		}
+	}
+
+	best := -1
+	var bestTime time.Time
+	for i := range episodes {
+		for _, commit := range episodes[i].Commits {
+			if commit.CommittedAt.After(marker.date) {
+				continue
+			}
+			if best < 0 || commit.CommittedAt.After(bestTime) {
+				best, bestTime = i, commit.CommittedAt
+			}
+		}
+	}
+	return best
+}
+
+// GetReleases returns the release artifacts attached to the episode
+func (e *Episode) GetReleases() []Artifact {
+	releases := make([]Artifact, 0)
+	for _, artifact := range e.Artifacts {
+		if artifact.Type == ArtifactRelease {
+			releases = append(releases, artifact)
+		}
+	}
+	return releases
+}
+
+// HasRelease reports whether the episode shipped in the given release or tag
+// Matching ignores case and a leading "v", so "1.2" finds "v1.2"
+func (e *Episode) HasRelease(tag string) bool {
+	want := normalizeReleaseTag(tag)
+	if want == "" {
+		return false
+	}
+
+	for _, artifact := range e.GetReleases() {
+		if normalizeReleaseTag(artifact.Metadata.TagName) == want {
+			return true
+		}
+	}
+	for _, commit := range e.Commits {
+		for _, name := range commit.Tags {
+			if normalizeReleaseTag(name) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeReleaseTag lowercases a tag and strips a leading "v"
+func normalizeReleaseTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	return strings.TrimPrefix(tag, "v")
+}
@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// releaseArtifact creates a published release artifact for tests
+func releaseArtifact(id, tag string, published time.Time) Artifact {
+	return Artifact{
+		ID:        id,
+		Type:      ArtifactRelease,
+		Title:     "Release " + tag,
+		CreatedAt: published,
+		Metadata:  ArtifactMetadata{TagName: tag},
+	}
+}
+
+// releaseTestActivity returns three closely spaced commits by one author touching the same file
+func releaseTestActivity(base time.Time) *RepositoryActivity {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	return &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Add parser", alice, base, []string{"parser.go"}),
+			createTestCommit("aaaaaaa2", "Fix parser", alice, base.Add(time.Hour), []string{"parser.go"}),
+			createTestCommit("aaaaaaa3", "Tune parser", alice, base.Add(2*time.Hour), []string{"parser.go"}),
+		},
+	}
+}
+
+func TestGroupIntoEpisodes_SplitOnTaggedRelease(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	activity := releaseTestActivity(base)
+	activity.Tags = []git.Tag{{Name: "v1.0.0", Hash: "aaaaaaa2", Date: base.Add(time.Hour)}}
+	activity.Artifacts = []Artifact{releaseArtifact("release-1", "v1.0.0", base.Add(90*time.Minute))}
+
+	config := DefaultGroupingConfig()
+	if episodes := activity.GroupIntoEpisodes(config); len(episodes) != 1 {
+		t.Fatalf("Expected releases not to split episodes by default, got %d episodes", len(episodes))
+	}
+
+	config.SplitOnReleases = true
+	episodes := activity.GroupIntoEpisodes(config)
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes split at the release, got %d", len(episodes))
+	}
+	if len(episodes[0].Commits) != 2 || episodes[0].Commits[1].Hash != "aaaaaaa2" {
+		t.Errorf("Expected the first episode to end with the released commit")
+	}
+
+	releases := episodes[0].GetReleases()
+	if len(releases) != 1 || releases[0].Metadata.TagName != "v1.0.0" {
+		t.Errorf("Expected v1.0.0 attached to the first episode, got %+v", releases)
+	}
+	if len(episodes[1].GetReleases()) != 0 {
+		t.Error("Expected no release attached to the second episode")
+	}
+}
+
+func TestGroupIntoEpisodes_SplitOnReleaseDate(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	activity := releaseTestActivity(base)
+	// The release's tag is not in the local clone, so its publication time marks the boundary
+	activity.Artifacts = []Artifact{releaseArtifact("release-1", "v2.0.0", base.Add(30*time.Minute))}
+
+	config := DefaultGroupingConfig()
+	config.SplitOnReleases = true
+	episodes := activity.GroupIntoEpisodes(config)
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes split at the release date, got %d", len(episodes))
+	}
+	if len(episodes[0].Commits) != 1 || !episodes[0].HasRelease("v2.0.0") {
+		t.Errorf("Expected the release attached to the episode before it was published")
+	}
+}
+
+func TestGroupIntoEpisodes_BoundaryTags(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	activity := releaseTestActivity(base)
+	activity.Tags = []git.Tag{
+		{Name: "nightly-20240301", Hash: "aaaaaaa1", Date: base},
+		{Name: "v1.0.0", Hash: "aaaaaaa2", Date: base.Add(time.Hour)},
+	}
+
+	config := DefaultGroupingConfig()
+	config.BoundaryTags = []string{"v*"}
+	episodes := activity.GroupIntoEpisodes(config)
+	if len(episodes) != 2 {
+		t.Fatalf("Expected only the v1.0.0 tag to split episodes, got %d episodes", len(episodes))
+	}
+	if len(episodes[0].Commits) != 2 || episodes[0].Commits[1].Hash != "aaaaaaa2" {
+		t.Errorf("Expected the first episode to end at v1.0.0")
+	}
+
+	for _, algorithm := range []Algorithm{AlgorithmAgglomerative, AlgorithmDensity} {
+		config.Algorithm = algorithm
+		if episodes := activity.GroupIntoEpisodes(config); len(episodes) != 2 || len(episodes[0].Commits) != 2 {
+			t.Errorf("%s: expected the v1.0.0 boundary respected, got %d episodes", algorithm, len(episodes))
+		}
+	}
+
+	config = DefaultGroupingConfig()
+	config.SplitOnReleases = true
+	if episodes := activity.GroupIntoEpisodes(config); len(episodes) != 3 {
+		t.Errorf("Expected every tag to split without BoundaryTags, got %d episodes", len(episodes))
+	}
+}
+
+func TestBoundaryMarkers(t *testing.T) {
+	markers := []releaseMarker{{tag: "v1.0.0"}, {tag: "v1.1.0-rc.1"}, {tag: "nightly"}, {tag: ""}}
+
+	if all := boundaryMarkers(markers, nil); len(all) != 4 {
+		t.Errorf("Expected all markers without patterns, got %d", len(all))
+	}
+
+	matching := boundaryMarkers(markers, []string{"v*.0", "[", "nightly"})
+	if len(matching) != 2 || matching[0].tag != "v1.0.0" || matching[1].tag != "nightly" {
+		t.Errorf("Expected v1.0.0 and nightly, got %+v", matching)
+	}
+}
+
+func TestGroupIntoEpisodes_ReleasesNotReferencedByNumber(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	activity := &RepositoryActivity{
+		Commits:   []git.Commit{createTestCommit("aaaaaaa1", "Handle #0 edge case", alice, base, []string{"a.go"})},
+		Artifacts: []Artifact{releaseArtifact("release-1", "v1.0.0", base.Add(-time.Hour))},
+	}
+
+	episodes := activity.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 || len(episodes[0].Artifacts) != 0 {
+		t.Errorf("Expected the earlier release not to attach via a #0 reference, got %+v", episodes[0].Artifacts)
+	}
+}
+
+func TestEpisode_HasRelease(t *testing.T) {
+	ep := Episode{
+		Commits:   []git.Commit{{Hash: "aaaaaaa1", Tags: []string{"v1.1.0"}}},
+		Artifacts: []Artifact{releaseArtifact("release-2", "V1.2", time.Now())},
+	}
+
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"v1.2", true},
+		{"1.2", true},
+		{"1.1.0", true},
+		{"v1.3", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ep.HasRelease(tt.tag); got != tt.want {
+			t.Errorf("HasRelease(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// Embedder turns texts into embedding vectors, one per text in input order
+// rag.NewClusterEmbedder adapts a rag.Embedder
+type Embedder interface {
+	EmbedTexts(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Limits for the text embedded per commit and the texts sent per embedding request
+const (
+	maxCommitEmbeddingText = 4000
+	embeddingBatchSize     = 100
+)
+
+// GroupIntoEpisodesSemantic groups commits like GroupIntoEpisodes, but scores each commit by the cosine
+// similarity of its embedded message and diff to the episode's centroid, blended with the heuristic
+// score by SemanticWeight
+// Time stays a hard constraint: a commit further than the time gap from the episode's last commit
+// always starts a new episode, however similar its text
+func (ra *RepositoryActivity) GroupIntoEpisodesSemantic(ctx context.Context, embedder Embedder, config GroupingConfig) ([]Episode, error) {
+	vectors, err := embedCommits(ctx, embedder, ra.Commits)
+	if err != nil {
+		return nil, err
+	}
+
+	centroids := newCentroidCache(vectors)
+	score := func(episode *Episode, commit git.Commit, config GroupingConfig) float64 {
+		lastCommit := episode.Commits[len(episode.Commits)-1]
+		maxGap := config.MaxTimeGap
+		if gap, ok := config.AuthorTimeGaps[git.AuthorKey(commit.Author)]; ok {
+			maxGap = gap
+		}
+		if commit.CommittedAt.Sub(lastCommit.CommittedAt) > maxGap {
+			return 0
+		}
+
+		heuristic := calculateEpisodeSimilarity(episode, commit, config)
+		vector, ok := vectors[commit.Hash]
+		if !ok {
+			return heuristic
+		}
+		semantic := cosineSimilarity(vector, centroids.centroid(episode))
+		return config.SemanticWeight*semantic + (1-config.SemanticWeight)*heuristic
+	}
+
+	return ra.groupCommits(config, score), nil
+}
+
+// embedCommits embeds every commit's text in batches, keyed by commit hash
+func embedCommits(ctx context.Context, embedder Embedder, commits []git.Commit) (map[string][]float32, error) {
+	texts := make([]string, len(commits))
+	for i, commit := range commits {
+		texts[i] = commitEmbeddingText(commit)
+	}
+
+	embeddings, err := embedInBatches(ctx, embedder, texts, "commits")
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make(map[string][]float32, len(commits))
+	for i, commit := range commits {
+		vectors[commit.Hash] = embeddings[i]
+	}
+	return vectors, nil
+}
+
+// embedInBatches embeds texts in batches of embeddingBatchSize, returning one embedding per text
+// noun names what the texts describe in errors
+func embedInBatches(ctx context.Context, embedder Embedder, texts []string, noun string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+
+		batch, err := embedder.EmbedTexts(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed %s: %w", noun, err)
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("failed to embed %s: got %d embeddings for %d %s", noun, len(batch), end-start, noun)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// commitEmbeddingText describes a commit for embedding: its message, changed paths and as much of
+// its patch as fits
+func commitEmbeddingText(commit git.Commit) string {
+	var b strings.Builder
+	b.WriteString(commit.Message)
+
+	if len(commit.Diffs) > 0 {
+		paths := make([]string, len(commit.Diffs))
+		for i, diff := range commit.Diffs {
+			paths[i] = diff.FilePath
+		}
+		b.WriteString("\nFiles: " + strings.Join(paths, ", "))
+	}
+
+	for _, diff := range commit.Diffs {
+		if diff.Patch == "" || diff.IsBinary || b.Len() >= maxCommitEmbeddingText {
+			continue
+		}
+		b.WriteString("\n" + diff.Patch)
+	}
+
+	text := b.String()
+	if len(text) > maxCommitEmbeddingText {
+		text = text[:maxCommitEmbeddingText]
+	}
+	return text
+}
+
+// centroidCache maintains the running mean embedding of each open episode
+// Episodes only grow during grouping, so new commits are folded into the sum incrementally
+// Safe for concurrent use by the partitions of a parallel grouping
+type centroidCache struct {
+	mu      sync.Mutex
+	vectors map[string][]float32
+	sums    map[*Episode][]float64
+	counts  map[*Episode]int // Commits folded in, embedded or not
+	sizes   map[*Episode]int // Commits with an embedding
+}
+
+func newCentroidCache(vectors map[string][]float32) *centroidCache {
+	return &centroidCache{
+		vectors: vectors,
+		sums:    make(map[*Episode][]float64),
+		counts:  make(map[*Episode]int),
+		sizes:   make(map[*Episode]int),
+	}
+}
+
+// centroid returns the mean embedding of an episode's commits, or nil if none has an embedding
+// Single-commit episodes, such as the throwaway views used for pairwise scoring, are not cached
+func (c *centroidCache) centroid(episode *Episode) []float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(episode.Commits) == 1 && c.counts[episode] == 0 {
+		vector, ok := c.vectors[episode.Commits[0].Hash]
+		if !ok {
+			return nil
+		}
+		mean := make([]float64, len(vector))
+		for i, value := range vector {
+			mean[i] = float64(value)
+		}
+		return mean
+	}
+
+	sum := c.sums[episode]
+	for _, commit := range episode.Commits[c.counts[episode]:] {
+		vector, ok := c.vectors[commit.Hash]
+		if !ok {
+			continue
+		}
+		if sum == nil {
+			sum = make([]float64, len(vector))
+		}
+		for i := 0; i < len(sum) && i < len(vector); i++ {
+			sum[i] += float64(vector[i])
+		}
+		c.sizes[episode]++
+	}
+	c.sums[episode] = sum
+	c.counts[episode] = len(episode.Commits)
+
+	if sum == nil {
+		return nil
+	}
+	mean := make([]float64, len(sum))
+	for i, value := range sum {
+		mean[i] = value / float64(c.sizes[episode])
+	}
+	return mean
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors clamped to [0, 1], or 0 if either is empty
+func cosineSimilarity(a []float32, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		dot += float64(a[i]) * b[i]
+		normA += float64(a[i]) * float64(a[i])
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return math.Max(0, dot/(math.Sqrt(normA)*math.Sqrt(normB)))
+}
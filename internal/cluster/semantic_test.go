@@ -0,0 +1,106 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// topicEmbedder embeds texts onto one axis per topic keyword
+type topicEmbedder struct {
+	topics []string
+	calls  int
+	err    error
+}
+
+func (e *topicEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = make([]float32, len(e.topics))
+		for j, topic := range e.topics {
+			if strings.Contains(text, topic) {
+				embeddings[i][j] = 1
+			}
+		}
+	}
+	return embeddings, nil
+}
+
+func TestGroupIntoEpisodesSemantic_SplitsByTopic(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{
+			createTestCommit("aaaaaaa1", "Add login form", alice, base, []string{"auth/login.go"}),
+			createTestCommit("aaaaaaa2", "Store login session", alice, base.Add(time.Hour), []string{"auth/session.go"}),
+			createTestCommit("aaaaaaa3", "Describe setup in readme", alice, base.Add(2*time.Hour), []string{"README.md"}),
+			createTestCommit("aaaaaaa4", "Expand readme install steps", alice, base.Add(3*time.Hour), []string{"README.md"}),
+			createTestCommit("aaaaaaa5", "Fix login redirect", alice, base.Add(72*time.Hour), []string{"auth/login.go"}),
+		},
+	}
+
+	config := DefaultGroupingConfig()
+	if heuristic := activity.GroupIntoEpisodes(config); len(heuristic) != 2 {
+		t.Fatalf("Expected the heuristic to merge topics and split only on time, got %d episodes", len(heuristic))
+	}
+
+	config.SemanticWeight = 1
+	embedder := &topicEmbedder{topics: []string{"login", "readme"}}
+	episodes, err := activity.GroupIntoEpisodesSemantic(context.Background(), embedder, config)
+	if err != nil {
+		t.Fatalf("GroupIntoEpisodesSemantic failed: %v", err)
+	}
+
+	if len(episodes) != 3 {
+		t.Fatalf("Expected login, readme and a later login episode, got %d", len(episodes))
+	}
+	if len(episodes[0].Commits) != 2 || len(episodes[1].Commits) != 2 || len(episodes[2].Commits) != 1 {
+		t.Errorf("Unexpected grouping: %d/%d/%d commits", len(episodes[0].Commits), len(episodes[1].Commits), len(episodes[2].Commits))
+	}
+	if embedder.calls != 1 {
+		t.Errorf("Expected commits embedded in a single batch, got %d calls", embedder.calls)
+	}
+}
+
+func TestGroupIntoEpisodesSemantic_EmbeddingError(t *testing.T) {
+	alice := git.Author{Name: "Alice"}
+	activity := &RepositoryActivity{
+		Commits: []git.Commit{createTestCommit("aaaaaaa1", "Add login", alice, time.Now(), nil)},
+	}
+
+	embedder := &topicEmbedder{err: errors.New("rate limited")}
+	if _, err := activity.GroupIntoEpisodesSemantic(context.Background(), embedder, DefaultGroupingConfig()); err == nil {
+		t.Error("Expected the embedding error to be returned")
+	}
+}
+
+func TestCommitEmbeddingText(t *testing.T) {
+	commit := git.Commit{
+		Message: "Add login",
+		Diffs: []git.Diff{
+			{FilePath: "auth/login.go", Patch: "+func Login() {}"},
+			{FilePath: "logo.png", IsBinary: true, Patch: "binary"},
+		},
+	}
+
+	text := commitEmbeddingText(commit)
+	if !strings.Contains(text, "Files: auth/login.go, logo.png") || !strings.Contains(text, "+func Login") {
+		t.Errorf("Expected message, paths and patch, got %q", text)
+	}
+	if strings.Contains(text, "binary") {
+		t.Errorf("Expected binary patches to be skipped, got %q", text)
+	}
+
+	commit.Diffs[0].Patch = strings.Repeat("x", 2*maxCommitEmbeddingText)
+	if text := commitEmbeddingText(commit); len(text) != maxCommitEmbeddingText {
+		t.Errorf("Expected text truncated to %d bytes, got %d", maxCommitEmbeddingText, len(text))
+	}
+}
@@ -0,0 +1,51 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EpisodeSchemaVersion is the version of the document written by MarshalEpisodes
+// Bump it when a field is renamed, removed or changes meaning; new optional fields do not need a bump
+const EpisodeSchemaVersion = 1
+
+// EpisodeDocument is the persisted form of a set of episodes
+type EpisodeDocument struct {
+	SchemaVersion int       `json:"schema_version"`
+	Episodes      []Episode `json:"episodes"`
+}
+
+// MarshalEpisodes encodes episodes as an indented JSON document tagged with EpisodeSchemaVersion
+// Lazily parsed commits are written as they are; call LoadDiffs first to persist full diffs
+func MarshalEpisodes(episodes []Episode) ([]byte, error) {
+	if episodes == nil {
+		episodes = []Episode{}
+	}
+
+	data, err := json.MarshalIndent(EpisodeDocument{SchemaVersion: EpisodeSchemaVersion, Episodes: episodes}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode episodes: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalEpisodes decodes a document written by MarshalEpisodes
+// Documents without a schema version or from a newer schema are rejected rather than misread
+func UnmarshalEpisodes(data []byte) ([]Episode, error) {
+	var document EpisodeDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse episodes: %w", err)
+	}
+
+	switch {
+	case document.SchemaVersion == 0:
+		return nil, fmt.Errorf("failed to parse episodes: missing schema_version")
+	case document.SchemaVersion > EpisodeSchemaVersion:
+		return nil, fmt.Errorf("unsupported episode schema version %d (newest supported: %d)", document.SchemaVersion, EpisodeSchemaVersion)
+	}
+
+	if document.Episodes == nil {
+		document.Episodes = []Episode{}
+	}
+	return document.Episodes, nil
+}
@@ -0,0 +1,134 @@
+package cluster
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func serializeTestEpisodes() []Episode {
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	base := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	merged := base.Add(2 * time.Hour)
+
+	first := createTestCommit("aaaaaaa1", "Add schema", author, base, []string{"schema/v1.go"})
+	first.Languages = map[string]int{"Go": 15}
+	second := createTestCommit("bbbbbbb2", "Document schema", author, base.Add(time.Hour), []string{"docs/schema.md"})
+
+	episode := Episode{
+		ID:         "E1",
+		Repository: "acme/thunk",
+		Commits:    []git.Commit{first, second},
+		Artifacts: []Artifact{{
+			ID:        "pr-9",
+			Type:      ArtifactPullRequest,
+			Number:    9,
+			Title:     "Schema",
+			State:     "merged",
+			Author:    author,
+			CreatedAt: base,
+			UpdatedAt: merged,
+			MergedAt:  &merged,
+			Discussions: []Discussion{
+				{ID: "r1", Type: DiscussionReview, Author: git.Author{Name: "Bob"}, ReviewState: "approved", CreatedAt: merged},
+			},
+		}},
+		Category:    CategoryFeature,
+		Memberships: map[string]float64{"bbbbbbb2": 0.7},
+		Cohesion:    0.8,
+		Confidence:  map[string]float64{"aaaaaaa1": 0.9},
+	}
+	episode.Stats = episode.ComputeStats()
+
+	return []Episode{episode, {ID: "E2", Commits: []git.Commit{}}}
+}
+
+func TestMarshalEpisodes_RoundTrip(t *testing.T) {
+	episodes := serializeTestEpisodes()
+
+	data, err := MarshalEpisodes(episodes)
+	if err != nil {
+		t.Fatalf("MarshalEpisodes: %v", err)
+	}
+	decoded, err := UnmarshalEpisodes(data)
+	if err != nil {
+		t.Fatalf("UnmarshalEpisodes: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, episodes) {
+		t.Errorf("round trip changed episodes:\ngot  %+v\nwant %+v", decoded, episodes)
+	}
+
+	again, err := MarshalEpisodes(decoded)
+	if err != nil {
+		t.Fatalf("MarshalEpisodes: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Error("re-encoding decoded episodes produced a different document")
+	}
+}
+
+func TestMarshalEpisodes_StableFieldNames(t *testing.T) {
+	data, err := MarshalEpisodes(serializeTestEpisodes()[:1])
+	if err != nil {
+		t.Fatalf("MarshalEpisodes: %v", err)
+	}
+
+	var document struct {
+		SchemaVersion int                          `json:"schema_version"`
+		Episodes      []map[string]json.RawMessage `json:"episodes"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if document.SchemaVersion != EpisodeSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", document.SchemaVersion, EpisodeSchemaVersion)
+	}
+
+	keys := make([]string, 0)
+	for key := range document.Episodes[0] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := []string{"artifacts", "category", "cohesion", "commits", "confidence", "id", "memberships", "repository", "stats"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("episode fields = %v, want %v (bump EpisodeSchemaVersion when renaming fields)", keys, want)
+	}
+}
+
+func TestUnmarshalEpisodes_Versions(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+		want    int
+	}{
+		{name: "current", data: `{"schema_version": 1, "episodes": [{"id": "E1", "commits": []}]}`, want: 1},
+		{name: "empty", data: `{"schema_version": 1}`, want: 0},
+		{name: "missing version", data: `{"episodes": []}`, wantErr: "missing schema_version"},
+		{name: "newer version", data: `{"schema_version": 99, "episodes": []}`, wantErr: "unsupported episode schema version 99"},
+		{name: "bare array", data: `[{"id": "E1"}]`, wantErr: "failed to parse episodes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			episodes, err := UnmarshalEpisodes([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if episodes == nil || len(episodes) != tt.want {
+				t.Errorf("got %v, want %d episodes", episodes, tt.want)
+			}
+		})
+	}
+}
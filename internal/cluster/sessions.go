@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// DefaultSessionGap is a typical pause that ends a work session, e.g. a long meeting or the evening
+const DefaultSessionGap = 3 * time.Hour
+
+// Bounds, scale and sample size for learned per-author session gaps
+const (
+	minLearnedSessionGap = 30 * time.Minute
+	sessionGapScale      = 3
+	minSessionGapSamples = 10
+)
+
+// LearnAuthorSessionGaps derives a per-author SessionGap from each author's pace within a working day
+// The gap is three times the author's median gap between commits on the same day, clamped to
+// [30m, DefaultSessionGap], so very active contributors get shorter sessions. Authors with fewer than
+// 10 same-day gaps are omitted and fall back to SessionGap
+func LearnAuthorSessionGaps(commits []git.Commit) map[string]time.Duration {
+	gaps := make(map[string]time.Duration)
+	for key, pattern := range git.AnalyzeWorkPatterns(commits) {
+		if pattern.SameDayGapCount() < minSessionGapSamples {
+			continue
+		}
+
+		gap := sessionGapScale * pattern.SameDayGapPercentile(0.5)
+		gap = max(gap, minLearnedSessionGap)
+		gap = min(gap, DefaultSessionGap)
+		gaps[key] = gap
+	}
+	return gaps
+}
+
+// sessionGap returns the pause after which a commit by author starts a new work session
+func (c GroupingConfig) sessionGap(author git.Author) time.Duration {
+	if gap, ok := c.AuthorSessionGaps[git.AuthorKey(author)]; ok {
+		return gap
+	}
+	return c.SessionGap
+}
+
+// splitWorkSessions splits episodes wherever a commit follows the previous one by more than its
+// author's session gap, even within MaxTimeGap, so each episode covers one work session
+// Split episodes keep the artifacts their commits reference; the rest stay with the first session
+func (ra *RepositoryActivity) splitWorkSessions(episodes []Episode, config GroupingConfig, artifactRefMap map[string]*Artifact) []Episode {
+	if config.SessionGap <= 0 {
+		return episodes
+	}
+
+	result := make([]Episode, 0, len(episodes))
+	for _, episode := range episodes {
+		sessions := ra.sessionsOf(episode, config, artifactRefMap)
+		for _, session := range sessions {
+			if len(session.Commits) >= config.MinCommits {
+				result = append(result, session)
+			}
+		}
+	}
+	return result
+}
+
+// sessionsOf splits one episode into its work sessions, returning it unchanged when it has only one
+func (ra *RepositoryActivity) sessionsOf(episode Episode, config GroupingConfig, artifactRefMap map[string]*Artifact) []Episode {
+	var sessions []Episode
+	start := 0
+	for i := 1; i <= len(episode.Commits); i++ {
+		if i < len(episode.Commits) {
+			commit := episode.Commits[i]
+			if commit.CommittedAt.Sub(episode.Commits[i-1].CommittedAt) <= config.sessionGap(commit.Author) {
+				continue
+			}
+		}
+		if start == 0 && i == len(episode.Commits) {
+			return []Episode{episode}
+		}
+
+		session := Episode{Commits: episode.Commits[start:i:i]}
+		for _, commit := range session.Commits {
+			addReferencedArtifacts(&session, commit, artifactRefMap, ra.Artifacts)
+		}
+		sessions = append(sessions, session)
+		start = i
+	}
+
+	held := make(map[string]bool)
+	for _, session := range sessions {
+		for _, artifact := range session.Artifacts {
+			held[artifact.ID] = true
+		}
+	}
+	for _, artifact := range episode.Artifacts {
+		if !held[artifact.ID] {
+			sessions[0].Artifacts = append(sessions[0].Artifacts, artifact)
+		}
+	}
+	return sessions
+}
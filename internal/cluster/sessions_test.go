@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestGroupIntoEpisodes_SessionGap(t *testing.T) {
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	base := time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC)
+
+	// A morning and an afternoon session on the same files, four hours apart
+	var commits []git.Commit
+	for i, offset := range []time.Duration{0, 20 * time.Minute, 40 * time.Minute, 280 * time.Minute, 300 * time.Minute} {
+		message := "Tune parser"
+		if i == 3 {
+			message = "Tune parser, fixes #12"
+		}
+		commits = append(commits, createTestCommit(fmt.Sprintf("%07d", i+1), message, author, base.Add(offset), []string{"parser.go"}))
+	}
+	issue := Artifact{ID: "issue-12", Type: ArtifactIssue, Number: 12, Title: "Parser is slow"}
+	ra := &RepositoryActivity{Commits: commits, Artifacts: []Artifact{issue}}
+
+	config := DefaultGroupingConfig()
+	config.ArtifactEpisodes = false
+	if episodes := ra.GroupIntoEpisodes(config); len(episodes) != 1 {
+		t.Fatalf("without sessions got %d episodes, want 1", len(episodes))
+	}
+
+	config.SessionGap = DefaultSessionGap
+	episodes := ra.GroupIntoEpisodes(config)
+	if len(episodes) != 2 {
+		t.Fatalf("with sessions got %d episodes, want 2", len(episodes))
+	}
+	if len(episodes[0].Commits) != 3 || len(episodes[1].Commits) != 2 {
+		t.Errorf("sessions have %d and %d commits, want 3 and 2", len(episodes[0].Commits), len(episodes[1].Commits))
+	}
+	if len(episodes[0].Artifacts) != 0 || len(episodes[1].Artifacts) != 1 {
+		t.Errorf("the referenced issue should move with its session, got %d and %d artifacts", len(episodes[0].Artifacts), len(episodes[1].Artifacts))
+	}
+
+	config.Algorithm = AlgorithmAgglomerative
+	if episodes := ra.GroupIntoEpisodes(config); len(episodes) != 2 {
+		t.Errorf("agglomerative with sessions got %d episodes, want 2", len(episodes))
+	}
+}
+
+func TestLearnAuthorSessionGaps(t *testing.T) {
+	fast := git.Author{Name: "Fay", Email: "fay@example.com"}
+	slow := git.Author{Name: "Sol", Email: "sol@example.com"}
+	rare := git.Author{Name: "Ray", Email: "ray@example.com"}
+	base := time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)
+
+	var commits []git.Commit
+	for day := 0; day < 3; day++ {
+		start := base.AddDate(0, 0, day)
+		for i := 0; i < 6; i++ {
+			commits = append(commits,
+				git.Commit{Author: fast, CommittedAt: start.Add(time.Duration(i) * 10 * time.Minute)},
+				git.Commit{Author: slow, CommittedAt: start.Add(time.Duration(i) * 90 * time.Minute)})
+		}
+		commits = append(commits, git.Commit{Author: rare, CommittedAt: start})
+	}
+
+	gaps := LearnAuthorSessionGaps(commits)
+	if gap := gaps["fay@example.com"]; gap != minLearnedSessionGap {
+		t.Errorf("fast author gap = %v, want the %v floor", gap, minLearnedSessionGap)
+	}
+	if gap := gaps["sol@example.com"]; gap != DefaultSessionGap {
+		t.Errorf("slow author gap = %v, want the %v ceiling", gap, DefaultSessionGap)
+	}
+	if _, ok := gaps["ray@example.com"]; ok {
+		t.Error("authors without same-day gaps should fall back to SessionGap")
+	}
+
+	config := GroupingConfig{SessionGap: 2 * time.Hour, AuthorSessionGaps: gaps}
+	if got := config.sessionGap(rare); got != 2*time.Hour {
+		t.Errorf("sessionGap fallback = %v, want 2h", got)
+	}
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Split divides the episode into chronological parts of at most size commits and artifacts, so an
+// episode too large to describe at once can be summarized part by part
+// An artifact goes into the part with the first of its commits, or else is placed by when it was
+// created; each part gets its own ID ("<id>/part-<n>") and statistics
+// An episode that already fits in one part is returned as is
+func (e *Episode) Split(size int) []Episode {
+	if size <= 0 || len(e.Commits)+len(e.Artifacts) <= size {
+		return []Episode{*e}
+	}
+
+	// Commits and artifacts are ordered together by the time they belong at
+	type item struct {
+		at       time.Time
+		commit   int // Index into e.Commits, or -1 for an artifact
+		artifact int // Index into e.Artifacts, or -1 for a commit
+	}
+	commitTimes := make(map[string]time.Time, len(e.Commits))
+	items := make([]item, 0, len(e.Commits)+len(e.Artifacts))
+	for i, commit := range e.Commits {
+		commitTimes[commit.Hash] = commit.CommittedAt
+		items = append(items, item{at: commit.CommittedAt, commit: i, artifact: -1})
+	}
+	for i, artifact := range e.Artifacts {
+		at := artifact.CreatedAt
+		first := time.Time{}
+		for _, sha := range artifact.Metadata.CommitSHAs {
+			if t, ok := commitTimes[sha]; ok && (first.IsZero() || t.Before(first)) {
+				first = t
+			}
+		}
+		if !first.IsZero() {
+			at = first
+		}
+		items = append(items, item{at: at, commit: -1, artifact: i})
+	}
+	// Stable, so ties keep commits before artifacts and each in their episode order
+	sort.SliceStable(items, func(i, j int) bool { return items[i].at.Before(items[j].at) })
+
+	parts := make([]Episode, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		part := Episode{
+			ID:          fmt.Sprintf("%s/part-%d", e.ID, len(parts)+1),
+			Repository:  e.Repository,
+			Category:    e.Category,
+			Memberships: e.Memberships,
+		}
+		for _, it := range items[start:min(start+size, len(items))] {
+			if it.commit >= 0 {
+				part.Commits = append(part.Commits, e.Commits[it.commit])
+			} else {
+				part.Artifacts = append(part.Artifacts, e.Artifacts[it.artifact])
+			}
+		}
+		part.Stats = part.ComputeStats()
+		parts = append(parts, part)
+	}
+	return parts
+}
@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestEpisodeSplit(t *testing.T) {
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	episode := &Episode{ID: "E1", Repository: "owner/app", Category: CategoryFeature}
+	for i := 0; i < 5; i++ {
+		episode.Commits = append(episode.Commits, createTestCommit(fmt.Sprintf("commit%d", i), "Change", author, base.Add(time.Duration(i)*time.Hour), []string{"main.go"}))
+	}
+	episode.Artifacts = []Artifact{
+		// Created before any commit, but placed with the commit it contains
+		{ID: "pr", Type: ArtifactPullRequest, CreatedAt: base.Add(-time.Hour), Metadata: ArtifactMetadata{CommitSHAs: []string{"commit4"}}},
+		// No commits of its own, so placed by when it was created
+		{ID: "issue", Type: ArtifactIssue, CreatedAt: base.Add(90 * time.Minute)},
+	}
+
+	parts := episode.Split(3)
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 parts of 7 items, got %d", len(parts))
+	}
+
+	var order []string
+	for i, part := range parts {
+		if want := fmt.Sprintf("E1/part-%d", i+1); part.ID != want || part.Repository != "owner/app" || part.Category != CategoryFeature {
+			t.Errorf("Part %d: unexpected identity %q %q %q", i+1, part.ID, part.Repository, part.Category)
+		}
+		for _, c := range part.Commits {
+			order = append(order, c.Hash)
+		}
+		for _, a := range part.Artifacts {
+			order = append(order, a.ID)
+		}
+		if len(part.Commits) > 0 && part.Stats.FilesChanged == 0 {
+			t.Errorf("Part %d: expected its own statistics", i+1)
+		}
+	}
+	if got := fmt.Sprint(order); got != "[commit0 commit1 issue commit2 commit3 commit4 pr]" {
+		t.Errorf("Unexpected order of items across parts: %s", got)
+	}
+
+	if whole := episode.Split(10); len(whole) != 1 || whole[0].ID != "E1" {
+		t.Errorf("Expected an episode that fits to be returned as is, got %d parts", len(whole))
+	}
+}
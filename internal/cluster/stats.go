@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"path"
+	"sort"
+	"time"
+)
+
+// episodeStatsTopN caps the files and directories listed in episode statistics
+const episodeStatsTopN = 5
+
+// EpisodeStats summarizes an episode's size and shape; it is computed when episodes are grouped
+// Commit figures cover the episode's own commits, not those shared from other episodes
+type EpisodeStats struct {
+	Additions      int            `json:"additions"`
+	Deletions      int            `json:"deletions"`
+	FilesChanged   int            `json:"files_changed"`
+	TopFiles       []string       `json:"top_files,omitempty"`       // Most frequently changed files, most first
+	TopDirectories []string       `json:"top_directories,omitempty"` // Directories with the most file changes, most first
+	Languages      map[string]int `json:"languages,omitempty"`       // Changed lines per language
+	ReviewComments int            `json:"review_comments"`           // Reviews and review thread comments on the episode's PRs
+	MergeCount     int            `json:"merge_count"`               // Merge commits
+	Duration       time.Duration  `json:"duration"`
+}
+
+// ComputeStats computes the episode's statistics from its commits and artifacts
+func (e *Episode) ComputeStats() EpisodeStats {
+	commits := e.GetPrimaryCommits()
+	stats := EpisodeStats{Duration: e.GetDuration()}
+
+	files := make(map[string]bool)
+	directories := make(map[string]int)
+	ranked := make([]episodeCommit, len(commits))
+	for i, commit := range commits {
+		ranked[i] = episodeCommit{episode: e.ID, commit: commit}
+		stats.Additions += commit.Stats.Additions
+		stats.Deletions += commit.Stats.Deletions
+		if commit.IsMerge {
+			stats.MergeCount++
+		}
+		for language, lines := range commit.Languages {
+			if stats.Languages == nil {
+				stats.Languages = make(map[string]int)
+			}
+			stats.Languages[language] += lines
+		}
+		for _, file := range commitFiles(commit) {
+			files[file] = true
+			if dir := path.Dir(file); dir != "." {
+				directories[dir]++
+			}
+		}
+	}
+	stats.FilesChanged = len(files)
+
+	for i, hotspot := range rankHotspots(ranked) {
+		if i >= episodeStatsTopN {
+			break
+		}
+		stats.TopFiles = append(stats.TopFiles, hotspot.Path)
+	}
+	stats.TopDirectories = topDirectories(directories, episodeStatsTopN)
+
+	for _, artifact := range e.Artifacts {
+		for _, discussion := range artifact.Discussions {
+			if discussion.Type == DiscussionReview || discussion.Type == DiscussionReviewThread {
+				stats.ReviewComments++
+			}
+		}
+	}
+
+	return stats
+}
+
+// topDirectories returns up to n directories by change count, most first, ties in path order
+func topDirectories(counts map[string]int, n int) []string {
+	directories := make([]string, 0, len(counts))
+	for dir := range counts {
+		directories = append(directories, dir)
+	}
+	sort.Slice(directories, func(i, j int) bool {
+		if counts[directories[i]] != counts[directories[j]] {
+			return counts[directories[i]] > counts[directories[j]]
+		}
+		return directories[i] < directories[j]
+	})
+	if len(directories) > n {
+		directories = directories[:n]
+	}
+	return directories
+}
+
+// computeEpisodeStats sets the statistics of every episode
+func computeEpisodeStats(episodes []Episode) {
+	for i := range episodes {
+		episodes[i].Stats = episodes[i].ComputeStats()
+	}
+}
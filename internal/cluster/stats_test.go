@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestComputeStats(t *testing.T) {
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	first := createTestCommit("aaaaaaa1", "Add parser", author, base, []string{"internal/parse/lexer.go", "internal/parse/parser.go", "README.md"})
+	first.Languages = map[string]int{"Go": 20, "Markdown": 10}
+	second := createTestCommit("bbbbbbb2", "Merge branch 'parser'", author, base.Add(3*time.Hour), []string{"internal/parse/parser.go", "cmd/main.go"})
+	second.IsMerge = true
+	second.Languages = map[string]int{"Go": 30}
+	shared := createTestCommit("ccccccc3", "Unrelated", author, base.Add(time.Hour), []string{"docs/guide.md"})
+
+	episode := &Episode{
+		ID:          "E1",
+		Commits:     []git.Commit{first, shared, second},
+		Memberships: map[string]float64{shared.Hash: 0.6},
+		Artifacts: []Artifact{{
+			Type:   ArtifactPullRequest,
+			Number: 4,
+			Discussions: []Discussion{
+				{ID: "r1", Type: DiscussionReview},
+				{ID: "t1", Type: DiscussionReviewThread},
+				{ID: "c1", Type: DiscussionComment},
+			},
+		}},
+	}
+
+	stats := episode.ComputeStats()
+	if stats.Additions != 50 || stats.Deletions != 25 {
+		t.Errorf("lines = +%d/-%d, want +50/-25", stats.Additions, stats.Deletions)
+	}
+	if stats.FilesChanged != 4 {
+		t.Errorf("FilesChanged = %d, want 4 (shared commit excluded)", stats.FilesChanged)
+	}
+	if len(stats.TopFiles) == 0 || stats.TopFiles[0] != "internal/parse/parser.go" {
+		t.Errorf("TopFiles = %v, want parser.go first", stats.TopFiles)
+	}
+	if want := []string{"internal/parse", "cmd"}; !reflect.DeepEqual(stats.TopDirectories, want) {
+		t.Errorf("TopDirectories = %v, want %v", stats.TopDirectories, want)
+	}
+	if want := map[string]int{"Go": 50, "Markdown": 10}; !reflect.DeepEqual(stats.Languages, want) {
+		t.Errorf("Languages = %v, want %v", stats.Languages, want)
+	}
+	if stats.ReviewComments != 2 {
+		t.Errorf("ReviewComments = %d, want 2", stats.ReviewComments)
+	}
+	if stats.MergeCount != 1 {
+		t.Errorf("MergeCount = %d, want 1", stats.MergeCount)
+	}
+	if stats.Duration != 3*time.Hour {
+		t.Errorf("Duration = %v, want 3h", stats.Duration)
+	}
+}
+
+func TestGroupIntoEpisodes_PopulatesStats(t *testing.T) {
+	author := git.Author{Name: "Ann", Email: "ann@example.com"}
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	ra := &RepositoryActivity{Commits: []git.Commit{
+		createTestCommit("aaaaaaa1", "Add cache", author, base, []string{"cache/store.go"}),
+		createTestCommit("bbbbbbb2", "Tune cache", author, base.Add(time.Hour), []string{"cache/store.go"}),
+	}}
+
+	episodes := ra.GroupIntoEpisodes(DefaultGroupingConfig())
+	if len(episodes) != 1 {
+		t.Fatalf("got %d episodes, want 1", len(episodes))
+	}
+	stats := episodes[0].Stats
+	if stats.Additions != 20 || stats.FilesChanged != 1 || stats.Duration != time.Hour {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportEpisodes(episodes, "json", &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"top_directories": [`) {
+		t.Errorf("export missing stats: %s", buf.String())
+	}
+}
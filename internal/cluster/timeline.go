@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// TimelineEventType identifies what happened in a timeline event
+type TimelineEventType string
+
+const (
+	TimelineCommit     TimelineEventType = "commit"
+	TimelineOpened     TimelineEventType = "opened"     // Issue, PR or ticket created
+	TimelineMerged     TimelineEventType = "merged"     // Pull or merge request merged
+	TimelineClosed     TimelineEventType = "closed"     // Closed without being merged
+	TimelineReleased   TimelineEventType = "released"   // Release published
+	TimelineDiscussion TimelineEventType = "discussion" // Comment, review, review thread or note
+	TimelineProcess    TimelineEventType = "process"    // Label, assignment, milestone or state change
+)
+
+// TimelineEvent is one entry of an episode's timeline
+// Commit, Artifact and Discussion point into the episode for the entries they describe; Artifact is
+// also set for discussions and process events, to the artifact they belong to
+type TimelineEvent struct {
+	Type       TimelineEventType
+	Time       time.Time
+	Commit     *git.Commit
+	Artifact   *Artifact
+	Discussion *Discussion
+}
+
+// GetTimeline merges the episode's commits, artifact openings, merges, closures and releases, and
+// their discussions into one stream ordered by time
+// Events without a timestamp come first; events at the same time keep commits before artifacts
+func (e *Episode) GetTimeline() []TimelineEvent {
+	var events []TimelineEvent
+	for i := range e.Commits {
+		events = append(events, TimelineEvent{Type: TimelineCommit, Time: e.Commits[i].CommittedAt, Commit: &e.Commits[i]})
+	}
+
+	for i := range e.Artifacts {
+		artifact := &e.Artifacts[i]
+		if artifact.Type == ArtifactRelease {
+			events = append(events, TimelineEvent{Type: TimelineReleased, Time: artifact.CreatedAt, Artifact: artifact})
+			continue
+		}
+
+		events = append(events, TimelineEvent{Type: TimelineOpened, Time: artifact.CreatedAt, Artifact: artifact})
+		if artifact.MergedAt != nil {
+			events = append(events, TimelineEvent{Type: TimelineMerged, Time: *artifact.MergedAt, Artifact: artifact})
+		} else if artifact.ClosedAt != nil {
+			events = append(events, TimelineEvent{Type: TimelineClosed, Time: *artifact.ClosedAt, Artifact: artifact})
+		}
+
+		for j := range artifact.Discussions {
+			discussion := &artifact.Discussions[j]
+			eventType := TimelineDiscussion
+			if discussion.Type == DiscussionEvent {
+				eventType = TimelineProcess
+			}
+			events = append(events, TimelineEvent{Type: eventType, Time: discussion.CreatedAt, Artifact: artifact, Discussion: discussion})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+// Actor returns the name of whoever caused the event, or "" when unknown
+func (t TimelineEvent) Actor() string {
+	switch {
+	case t.Commit != nil:
+		return t.Commit.Author.Name
+	case t.Discussion != nil:
+		return t.Discussion.Author.Name
+	case t.Artifact != nil && t.Type == TimelineOpened:
+		return t.Artifact.Author.Name
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestGetTimeline(t *testing.T) {
+	base := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+	alice := git.Author{Name: "Alice"}
+	bob := git.Author{Name: "Bob"}
+	merged := base.Add(5 * time.Hour)
+	closed := base.Add(6 * time.Hour)
+
+	episode := Episode{
+		Commits: []git.Commit{
+			{Hash: "c1", Author: alice, CommittedAt: base.Add(time.Hour)},
+			{Hash: "c2", Author: alice, CommittedAt: base.Add(3 * time.Hour)},
+		},
+		Artifacts: []Artifact{
+			{ID: "pr-2", Type: ArtifactPullRequest, Author: alice, CreatedAt: base.Add(2 * time.Hour), MergedAt: &merged, ClosedAt: &merged,
+				Discussions: []Discussion{{Type: DiscussionReview, Author: bob, CreatedAt: base.Add(4 * time.Hour)}}},
+			{ID: "issue-1", Type: ArtifactIssue, Author: bob, CreatedAt: base, ClosedAt: &closed,
+				Discussions: []Discussion{{Type: DiscussionEvent, Author: alice, CreatedAt: base.Add(30 * time.Minute)}}},
+			{ID: "release-v1", Type: ArtifactRelease, CreatedAt: base.Add(7 * time.Hour)},
+		},
+	}
+
+	timeline := episode.GetTimeline()
+	want := []struct {
+		eventType TimelineEventType
+		actor     string
+	}{
+		{TimelineOpened, "Bob"},
+		{TimelineProcess, "Alice"},
+		{TimelineCommit, "Alice"},
+		{TimelineOpened, "Alice"},
+		{TimelineCommit, "Alice"},
+		{TimelineDiscussion, "Bob"},
+		{TimelineMerged, ""},
+		{TimelineClosed, ""},
+		{TimelineReleased, ""},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(want), len(timeline), timeline)
+	}
+	for i, w := range want {
+		if timeline[i].Type != w.eventType || timeline[i].Actor() != w.actor {
+			t.Errorf("Event %d: expected %s by %q, got %s by %q", i, w.eventType, w.actor, timeline[i].Type, timeline[i].Actor())
+		}
+	}
+	if timeline[5].Artifact == nil || timeline[5].Artifact.ID != "pr-2" {
+		t.Error("Expected the review to point at its pull request")
+	}
+	if timeline[2].Commit != &episode.Commits[0] {
+		t.Error("Expected commit events to point into the episode")
+	}
+}
@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// WorkingEpisodeID identifies the synthetic episode holding work that is not on the remote yet
+const WorkingEpisodeID = "in-progress"
+
+// NewWorkingEpisode builds an "in-progress" pseudo-episode from a working state
+// Local-only commits come first (oldest first, like other episodes), followed by a synthetic
+// commit holding staged, unstaged and untracked changes. Returns nil for a clean state
+func NewWorkingEpisode(state *git.WorkingState) *Episode {
+	if state == nil || state.IsClean() {
+		return nil
+	}
+
+	commits := make([]git.Commit, 0, len(state.LocalCommits)+1)
+	for i := len(state.LocalCommits) - 1; i >= 0; i-- {
+		commits = append(commits, state.LocalCommits[i])
+	}
+
+	if state.HasUncommittedChanges() {
+		commits = append(commits, state.UncommittedCommit())
+	}
+
+	return &Episode{
+		ID:      WorkingEpisodeID,
+		Commits: commits,
+	}
+}
+
+// IsWorkingEpisode reports whether an episode is the synthetic in-progress episode
+func (e *Episode) IsWorkingEpisode() bool {
+	return e.ID == WorkingEpisodeID
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+func TestNewWorkingEpisode(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if episode := NewWorkingEpisode(&git.WorkingState{}); episode != nil {
+		t.Errorf("Expected no episode for clean state, got %+v", episode)
+	}
+
+	state := &git.WorkingState{
+		Branch:   "feature",
+		HeadHash: "bbb2222",
+		Author:   git.Author{Name: "Alice", Email: "alice@example.com"},
+		LocalCommits: []git.Commit{
+			{Hash: "bbb2222", CommittedAt: baseTime.Add(time.Hour)},
+			{Hash: "aaa1111", CommittedAt: baseTime},
+		},
+		Unstaged:   []git.Diff{{FilePath: "auth.go", Status: "modified", Additions: 4}},
+		CapturedAt: baseTime.Add(2 * time.Hour),
+	}
+
+	episode := NewWorkingEpisode(state)
+	if episode == nil || !episode.IsWorkingEpisode() {
+		t.Fatalf("Expected in-progress episode, got %+v", episode)
+	}
+	if len(episode.Commits) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(episode.Commits))
+	}
+	if episode.Commits[0].Hash != "aaa1111" || episode.Commits[2].Hash != git.UncommittedHash {
+		t.Errorf("Expected oldest local commit first and uncommitted changes last, got %s..%s",
+			episode.Commits[0].Hash, episode.Commits[2].Hash)
+	}
+	if names := episode.GetAuthorNames(); len(names) == 0 || names[0] != "Alice" {
+		t.Errorf("Expected uncommitted changes attributed to Alice, got %v", names)
+	}
+	if episode.GetFileCount() != 1 {
+		t.Errorf("Expected 1 file, got %d", episode.GetFileCount())
+	}
+}
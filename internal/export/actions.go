@@ -0,0 +1,183 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// FormatGitHub writes a GitHub Actions job summary and step outputs instead of a report
+const FormatGitHub = "github"
+
+// Files GitHub Actions gives a step for its job summary and outputs
+const (
+	StepSummaryEnv = "GITHUB_STEP_SUMMARY"
+	StepOutputEnv  = "GITHUB_OUTPUT"
+)
+
+// Caps on what a job summary holds; GitHub rejects summaries over 1 MiB
+const (
+	jobSummaryLimit   = 1 << 20
+	jobSummaryEpisode = 50
+)
+
+// ActionOutput is a step output that later steps read as steps.<id>.outputs.<name>
+type ActionOutput struct {
+	Name, Value string
+}
+
+// NarrativeJobSummary renders the job summary of a narrative written from episodes: its title,
+// the totals of the episodes, the narrative and a table of the episodes
+func NarrativeJobSummary(narr *narrative.Narrative, episodes []cluster.Episode, opts Options) string {
+	report := NewReport(episodes, opts)
+	var buf bytes.Buffer
+	b := bufio.NewWriter(&buf)
+
+	fmt.Fprintf(b, "# %s\n\n", orDefault(opts.Title, "Narrative"))
+	writeSummary(b, report)
+	fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(narr.Text))
+	fmt.Fprintf(b, "_%s._\n\n", byline(narr))
+
+	if len(report.Episodes) > 0 {
+		b.WriteString("## Episodes\n\n")
+		b.WriteString("| Episode | Category | Dates | Commits | Authors | Lines |\n")
+		b.WriteString("| --- | --- | --- | ---: | --- | ---: |\n")
+		for i, ep := range report.Episodes {
+			if i == jobSummaryEpisode {
+				fmt.Fprintf(b, "\n_... and %d more episodes._\n", len(report.Episodes)-jobSummaryEpisode)
+				break
+			}
+			title := cell(ep.Title)
+			for _, link := range ep.Links {
+				if link.Kind != "commit" {
+					title = fmt.Sprintf("[%s](%s)", title, link.URL)
+					break
+				}
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %d | %s | +%d / -%d |\n",
+				title, cell(string(ep.Category)), dateRange(ep.StartDate, ep.EndDate), ep.CommitCount,
+				cell(strings.Join(ep.Authors, ", ")), ep.Stats.Additions, ep.Stats.Deletions)
+		}
+		b.WriteString("\n")
+	}
+	b.Flush()
+	return buf.String()
+}
+
+// NarrativeOutputs returns the step outputs of a narrative written from episodes, with summary
+// as rendered by NarrativeJobSummary
+func NarrativeOutputs(narr *narrative.Narrative, episodes []cluster.Episode, summary string) []ActionOutput {
+	return SummaryOutputs(strings.TrimSpace(narr.Text), summary, summarize(episodes))
+}
+
+// SummaryOutputs returns step outputs of a narrative's text, its job summary and the totals of the
+// work it covers
+func SummaryOutputs(text, summary string, totals Summary) []ActionOutput {
+	outputs := []ActionOutput{
+		{"narrative", text},
+		{"summary", summary},
+		{"episodes", fmt.Sprint(totals.Episodes)},
+		{"commits", fmt.Sprint(totals.Commits)},
+		{"authors", fmt.Sprint(totals.Authors)},
+		{"additions", fmt.Sprint(totals.Additions)},
+		{"deletions", fmt.Sprint(totals.Deletions)},
+		{"work-mix", totals.WorkMix},
+	}
+	if !totals.Start.IsZero() {
+		outputs = append(outputs,
+			ActionOutput{"start-date", totals.Start.Format("2006-01-02")},
+			ActionOutput{"end-date", totals.End.Format("2006-01-02")})
+	}
+	return outputs
+}
+
+// WriteActionOutputs writes step outputs in the format of GitHub's output file, each value
+// between delimiters so it may span lines
+func WriteActionOutputs(w io.Writer, outputs []ActionOutput) error {
+	b := bufio.NewWriter(w)
+	for _, output := range outputs {
+		delimiter, err := outputDelimiter(output.Value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s<<%s\n%s\n%s\n", output.Name, delimiter, output.Value, delimiter)
+	}
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("failed to write step outputs: %w", err)
+	}
+	return nil
+}
+
+// outputDelimiter returns a random delimiter that doesn't occur in value
+func outputDelimiter(value string) (string, error) {
+	for {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+		}
+		delimiter := "THUNK_" + hex.EncodeToString(random)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+// WriteJobSummary appends summary and outputs to the files GitHub Actions gives the step, and
+// reports whether it runs in one; outside of GitHub Actions it writes nothing. A summary over
+// GitHub's 1 MiB limit is cut at a line and ends with a note.
+func WriteJobSummary(summary string, outputs []ActionOutput) (bool, error) {
+	summaryPath, outputPath := os.Getenv(StepSummaryEnv), os.Getenv(StepOutputEnv)
+	if summaryPath == "" && outputPath == "" {
+		return false, nil
+	}
+	if summaryPath != "" {
+		if err := appendFile(summaryPath, func(w io.Writer) error {
+			_, err := io.WriteString(w, truncateSummary(summary))
+			return err
+		}); err != nil {
+			return true, fmt.Errorf("failed to write job summary: %w", err)
+		}
+	}
+	if outputPath != "" {
+		if err := appendFile(outputPath, func(w io.Writer) error {
+			return WriteActionOutputs(w, outputs)
+		}); err != nil {
+			return true, fmt.Errorf("failed to write step outputs: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// appendFile appends what write writes to the file at path
+func appendFile(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// truncateSummary cuts a summary over GitHub's size limit at a line, noting the cut
+func truncateSummary(summary string) string {
+	const note = "\n\n_The summary was cut to fit GitHub's 1 MiB limit._\n"
+	if len(summary) <= jobSummaryLimit {
+		return summary
+	}
+	cut := summary[:jobSummaryLimit-len(note)]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + note
+}
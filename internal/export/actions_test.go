@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+func TestNarrativeJobSummary(t *testing.T) {
+	episodes := testEpisodes()
+	narr := &narrative.Narrative{Text: "Retries shipped and login was fixed.\n", Model: "gpt-4o"}
+	opts := Options{Title: "What shipped in v2.0?", RepositoryURL: "https://github.com/acme/app", GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+
+	summary := NarrativeJobSummary(narr, episodes, opts)
+	for _, want := range []string{
+		"# What shipped in v2.0?\n\n",
+		"_2 episodes, 3 commits and 2 authors, 2024-01-01 to 2024-01-03.",
+		"Retries shipped and login was fixed.\n\n_Written by gpt-4o._",
+		"| [Add retries](https://github.com/acme/app/pull/7) | feature | 2024-01-01 to 2024-01-02 | 2 | Ann | +13 / -2 |",
+		"| Fix login | bugfix | 2024-01-03 | 1 | Bob | +1 / -1 |",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected the summary to contain %q, got\n%s", want, summary)
+		}
+	}
+
+	outputs := make(map[string]string)
+	for _, output := range NarrativeOutputs(narr, episodes, summary) {
+		outputs[output.Name] = output.Value
+	}
+	if outputs["narrative"] != "Retries shipped and login was fixed." || outputs["summary"] != summary {
+		t.Errorf("Expected the narrative and summary as outputs, got %v", outputs)
+	}
+	if outputs["episodes"] != "2" || outputs["commits"] != "3" || outputs["additions"] != "14" || outputs["start-date"] != "2024-01-01" || outputs["end-date"] != "2024-01-03" {
+		t.Errorf("Unexpected totals %v", outputs)
+	}
+}
+
+func TestWriteActionOutputs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteActionOutputs(&buf, []ActionOutput{{"commits", "3"}, {"narrative", "Line one\nLine two"}}); err != nil {
+		t.Fatalf("WriteActionOutputs failed: %v", err)
+	}
+	pattern := regexp.MustCompile(`^commits<<(THUNK_[0-9a-f]{16})\n3\n(THUNK_[0-9a-f]{16})\nnarrative<<(THUNK_[0-9a-f]{16})\nLine one\nLine two\n(THUNK_[0-9a-f]{16})\n$`)
+	match := pattern.FindStringSubmatch(buf.String())
+	if match == nil || match[1] != match[2] || match[3] != match[4] {
+		t.Errorf("Expected each output between matching delimiters, got\n%s", buf.String())
+	}
+}
+
+func TestWriteJobSummary(t *testing.T) {
+	t.Setenv(StepSummaryEnv, "")
+	t.Setenv(StepOutputEnv, "")
+	if inActions, err := WriteJobSummary("# Summary\n", nil); inActions || err != nil {
+		t.Errorf("Expected nothing written outside of GitHub Actions, got %v, %v", inActions, err)
+	}
+
+	dir := t.TempDir()
+	summaryPath, outputPath := filepath.Join(dir, "summary.md"), filepath.Join(dir, "output")
+	if err := os.WriteFile(summaryPath, []byte("# Tests\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(StepSummaryEnv, summaryPath)
+	t.Setenv(StepOutputEnv, outputPath)
+
+	if inActions, err := WriteJobSummary("# Summary\n", []ActionOutput{{"commits", "3"}}); !inActions || err != nil {
+		t.Fatalf("WriteJobSummary failed: %v", err)
+	}
+	if data, _ := os.ReadFile(summaryPath); string(data) != "# Tests\n\n# Summary\n" {
+		t.Errorf("Expected the summary appended to the earlier steps', got %q", data)
+	}
+	if data, _ := os.ReadFile(outputPath); !strings.HasPrefix(string(data), "commits<<THUNK_") {
+		t.Errorf("Expected the outputs written, got %q", data)
+	}
+}
+
+func TestTruncateSummary(t *testing.T) {
+	summary := strings.Repeat(strings.Repeat("x", 99)+"\n", jobSummaryLimit/100+10)
+	got := truncateSummary(summary)
+	if len(got) > jobSummaryLimit || !strings.HasSuffix(got, "1 MiB limit._\n") || !strings.Contains(got, "x\n\n_The summary") {
+		t.Errorf("Expected the summary cut at a line under %d bytes, got %d bytes ending %q", jobSummaryLimit, len(got), got[len(got)-80:])
+	}
+	if short := "# Summary\n"; truncateSummary(short) != short {
+		t.Error("Expected a short summary unchanged")
+	}
+}
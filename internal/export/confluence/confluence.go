@@ -0,0 +1,225 @@
+// Package confluence exports episode pages to a Confluence space through the Confluence REST API.
+// Each episode's page is labelled with its episode ID, so exporting again updates pages in place
+// and leaves unchanged ones alone.
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/export"
+)
+
+// Environment variables read by NewClient when arguments are empty
+const (
+	URLEnv   = "CONFLUENCE_URL"
+	EmailEnv = "CONFLUENCE_EMAIL"
+	TokenEnv = "CONFLUENCE_API_TOKEN"
+
+	// Label carries every page the exporter writes
+	Label = "thunk"
+
+	// versionPrefix starts the version message that records what a page shows
+	versionPrefix = "thunk "
+)
+
+// Client is a minimal Confluence REST API client for Confluence Cloud and Server/Data Center
+type Client struct {
+	BaseURL string // Confluence root, e.g. "https://example.atlassian.net/wiki"
+
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Confluence client
+// Empty arguments are read from CONFLUENCE_URL, CONFLUENCE_EMAIL and CONFLUENCE_API_TOKEN
+// With an email the token is sent as a Confluence Cloud API token (basic auth); without one it is
+// sent as a Server/Data Center personal access token (bearer auth)
+func NewClient(baseURL, email, token string) *Client {
+	if baseURL == "" {
+		baseURL = os.Getenv(URLEnv)
+	}
+	if email == "" {
+		email = os.Getenv(EmailEnv)
+	}
+	if token == "" {
+		token = os.Getenv(TokenEnv)
+	}
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		email:      email,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Result counts the pages an export created, updated and left unchanged
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// ExportPages writes each page to the space, under the page parentID when it is set, with the
+// episode's narrative, statistics, pull requests, issues and commits. Pages are found by a label
+// of their episode ID: new episodes are added, changed ones get a new version and the rest are
+// skipped. Titles end with the episode ID, as Confluence requires them unique within a space.
+func (c *Client) ExportPages(ctx context.Context, space, parentID string, pages []export.Page) (Result, error) {
+	var result Result
+	if space == "" {
+		return result, fmt.Errorf("a confluence space key is required")
+	}
+
+	for i := range pages {
+		page := &pages[i]
+		title := fmt.Sprintf("%s (%s)", page.Title, page.ID)
+		body := storageBody(page)
+		sum := sha256.Sum256([]byte(title + "\n" + body))
+		message := versionPrefix + hex.EncodeToString(sum[:])
+
+		existing, err := c.findPage(ctx, space, page.ID)
+		if err != nil {
+			return result, err
+		}
+		request := map[string]any{
+			"type":  "page",
+			"title": title,
+			"space": map[string]string{"key": space},
+			"body":  map[string]any{"storage": map[string]string{"value": body, "representation": "storage"}},
+		}
+
+		switch {
+		case existing == nil:
+			request["version"] = map[string]any{"number": 1, "message": message}
+			if parentID != "" {
+				request["ancestors"] = []map[string]string{{"id": parentID}}
+			}
+			var created content
+			if err := c.do(ctx, http.MethodPost, "/rest/api/content", request, &created); err != nil {
+				return result, fmt.Errorf("failed to create the page of episode %s: %w", page.ID, err)
+			}
+			labels := []map[string]string{{"prefix": "global", "name": Label}, {"prefix": "global", "name": episodeLabel(page.ID)}}
+			if err := c.do(ctx, http.MethodPost, "/rest/api/content/"+created.ID+"/label", labels, nil); err != nil {
+				return result, fmt.Errorf("failed to label the page of episode %s: %w", page.ID, err)
+			}
+			result.Created++
+		case existing.Version.Message == message:
+			result.Unchanged++
+		default:
+			request["id"] = existing.ID
+			request["version"] = map[string]any{"number": existing.Version.Number + 1, "message": message}
+			if err := c.do(ctx, http.MethodPut, "/rest/api/content/"+existing.ID, request, nil); err != nil {
+				return result, fmt.Errorf("failed to update the page of episode %s: %w", page.ID, err)
+			}
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// content is a page as returned by the REST API
+type content struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Version struct {
+		Number  int    `json:"number"`
+		Message string `json:"message"`
+	} `json:"version"`
+}
+
+// findPage returns the page of an episode in a space, or nil when it has none yet
+func (c *Client) findPage(ctx context.Context, space, episodeID string) (*content, error) {
+	query := url.Values{
+		"cql":    {fmt.Sprintf(`type = page AND space = "%s" AND label = "%s"`, strings.ReplaceAll(space, `"`, `\"`), episodeLabel(episodeID))},
+		"expand": {"version"},
+		"limit":  {"1"},
+	}
+	var response struct {
+		Results []content `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/content/search?"+query.Encode(), nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to look up the page of episode %s: %w", episodeID, err)
+	}
+	if len(response.Results) == 0 {
+		return nil, nil
+	}
+	return &response.Results[0], nil
+}
+
+// labelUnsafe matches characters Confluence labels can't hold
+var labelUnsafe = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// episodeLabel returns the label that marks the page of an episode
+func episodeLabel(episodeID string) string {
+	return "thunk-episode-" + labelUnsafe.ReplaceAllString(strings.ToLower(episodeID), "-")
+}
+
+// do performs an authenticated request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("confluence base URL is not configured (set %s)", URLEnv)
+	}
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode confluence request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create confluence request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("confluence request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		msg := strings.TrimSpace(string(data))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			msg = apiErr.Message
+		}
+		if retryAfter := resp.Header.Get("Retry-After"); resp.StatusCode == http.StatusTooManyRequests && retryAfter != "" {
+			msg = fmt.Sprintf("rate limited, retry after %ss", retryAfter)
+		}
+		path, _, _ = strings.Cut(path, "?")
+		return fmt.Errorf("confluence request %s %s failed: %s: %s", method, path, resp.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode confluence response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// fakePage is a page kept by fakeConfluence
+type fakePage struct {
+	Title, Body, Space, Parent, Message string
+	Version                             int
+	Labels                              []string
+}
+
+// fakeConfluence serves the parts of the Confluence REST API the exporter uses
+func fakeConfluence(t *testing.T) (map[string]*fakePage, *Client) {
+	t.Helper()
+	pages := make(map[string]*fakePage)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "ann@example.com" || token != "secret" {
+			http.Error(w, `{"statusCode": 401, "message": "Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			ID        string `json:"id"`
+			Title     string `json:"title"`
+			Space     struct{ Key string }
+			Ancestors []struct{ ID string }
+			Body      struct{ Storage struct{ Value string } }
+			Version   struct {
+				Number  int
+				Message string
+			}
+		}
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/rest/api/content/search":
+			cql := r.URL.Query().Get("cql")
+			results := []any{}
+			for id, page := range pages {
+				for _, label := range page.Labels {
+					if strings.Contains(cql, `space = "`+page.Space+`"`) && strings.Contains(cql, `label = "`+label+`"`) {
+						results = append(results, map[string]any{"id": id, "title": page.Title, "version": map[string]any{"number": page.Version, "message": page.Message}})
+					}
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"results": results})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content":
+			id := fmt.Sprint(100 + len(pages))
+			page := &fakePage{Title: body.Title, Body: body.Body.Storage.Value, Space: body.Space.Key, Message: body.Version.Message, Version: 1}
+			if len(body.Ancestors) > 0 {
+				page.Parent = body.Ancestors[0].ID
+			}
+			pages[id] = page
+			json.NewEncoder(w).Encode(map[string]string{"id": id})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/label"):
+			id := strings.Split(r.URL.Path, "/")[5]
+			var labels []struct{ Name string }
+			json.Unmarshal(data, &labels)
+			for _, label := range labels {
+				pages[id].Labels = append(pages[id].Labels, label.Name)
+			}
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPut:
+			page := pages[strings.TrimPrefix(r.URL.Path, "/wiki/rest/api/content/")]
+			if body.Version.Number != page.Version+1 {
+				http.Error(w, `{"message": "Version must be incremented on update."}`, http.StatusConflict)
+				return
+			}
+			page.Title, page.Body, page.Message, page.Version = body.Title, body.Body.Storage.Value, body.Version.Message, body.Version.Number
+			w.Write([]byte(`{}`))
+		default:
+			http.Error(w, `{"message": "not found"}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return pages, NewClient(server.URL+"/wiki/", "ann@example.com", "secret")
+}
+
+func testPages(text string) []export.Page {
+	when := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	ep := cluster.Episode{ID: "E1", Category: cluster.CategoryFeature,
+		Commits:   []git.Commit{{Hash: "aaaaaaa111", Message: "Add retries", Author: git.Author{Name: "Ann"}, CommittedAt: when}},
+		Artifacts: []cluster.Artifact{{ID: "PR-7", Number: 7, Type: cluster.ArtifactPullRequest, Title: "Retries & backoff", URL: "https://github.com/acme/app/pull/7"}},
+	}
+	ep.Stats = ep.ComputeStats()
+	narr := &narrative.Narrative{EpisodeID: "E1", Text: text, Model: "gpt-4o"}
+	return export.NewPages([]cluster.Episode{ep}, []*narrative.Narrative{narr}, export.Options{RepositoryURL: "https://github.com/acme/app"})
+}
+
+func TestExportPages(t *testing.T) {
+	pages, client := fakeConfluence(t)
+	ctx := context.Background()
+
+	result, err := client.ExportPages(ctx, "ENG", "42", testPages("Retries now <back off>."))
+	if err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+	if result != (Result{Created: 1}) || len(pages) != 1 {
+		t.Fatalf("Expected the page created, got %+v", result)
+	}
+	page := pages["100"]
+	if page.Title != "Add retries (E1)" || page.Space != "ENG" || page.Parent != "42" {
+		t.Errorf("Unexpected page %+v", page)
+	}
+	if !strings.HasPrefix(page.Body, "<p>Retries now &lt;back off&gt;.</p>") || !strings.Contains(page.Body, `<a href="https://github.com/acme/app/pull/7">PR #7</a>: Retries &amp; backoff`) {
+		t.Errorf("Expected an escaped storage body, got %s", page.Body)
+	}
+
+	// Exporting the same pages again leaves the page alone
+	if result, err = client.ExportPages(ctx, "ENG", "42", testPages("Retries now <back off>.")); err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+	if result != (Result{Unchanged: 1}) || page.Version != 1 {
+		t.Errorf("Expected the page unchanged, got %+v", result)
+	}
+
+	// A changed narrative adds a version of the same page
+	if result, err = client.ExportPages(ctx, "ENG", "42", testPages("Retries now back off and are capped.")); err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+	if result != (Result{Updated: 1}) || len(pages) != 1 || page.Version != 2 || !strings.Contains(page.Body, "capped") {
+		t.Errorf("Expected the page updated in place, got %+v and %+v", result, page)
+	}
+
+	// Another space gets its own page
+	if result, err = client.ExportPages(ctx, "OPS", "", testPages("Retries now back off.")); err != nil || result.Created != 1 {
+		t.Errorf("Expected a page created in another space, got %+v (error %v)", result, err)
+	}
+}
+
+func TestExportPages_Errors(t *testing.T) {
+	_, client := fakeConfluence(t)
+	client.token = "wrong"
+	if _, err := client.ExportPages(context.Background(), "ENG", "", testPages("")); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Expected Confluence's error message, got %v", err)
+	}
+	if _, err := client.ExportPages(context.Background(), "", "", testPages("")); err == nil || !strings.Contains(err.Error(), "space") {
+		t.Errorf("Expected an error for a missing space, got %v", err)
+	}
+}
+
+func TestEpisodeLabel(t *testing.T) {
+	if got := episodeLabel("Ep 3/A"); got != "thunk-episode-ep-3-a" {
+		t.Errorf("episodeLabel() = %q", got)
+	}
+}
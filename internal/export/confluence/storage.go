@@ -0,0 +1,75 @@
+package confluence
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/export"
+)
+
+// commitLimit caps the commits a page lists
+const commitLimit = 20
+
+// storageBody renders a page in Confluence's storage format (XHTML): the narrative and its
+// byline, a table of statistics and lists of the episode's pull requests, issues and commits
+func storageBody(page *export.Page) string {
+	var b strings.Builder
+	for _, paragraph := range page.Paragraphs {
+		fmt.Fprintf(&b, "<p>%s</p>", escape(paragraph))
+	}
+	if byline := page.Byline(); byline != "" {
+		fmt.Fprintf(&b, "<p><em>%s</em></p>", escape(byline))
+	}
+
+	b.WriteString("<h2>Statistics</h2><table><tbody>")
+	for _, stat := range page.Statistics() {
+		fmt.Fprintf(&b, "<tr><th>%s</th><td>%s</td></tr>", escape(stat.Name), escape(stat.Value))
+	}
+	b.WriteString("</tbody></table>")
+
+	if len(page.Artifacts) > 0 {
+		b.WriteString("<h2>Pull requests and issues</h2><ul>")
+		for _, artifact := range page.Artifacts {
+			b.WriteString("<li>" + link(artifact.Label, artifact.URL))
+			if artifact.Title != "" {
+				b.WriteString(": " + escape(artifact.Title))
+			}
+			if artifact.State != "" {
+				b.WriteString(" (" + escape(artifact.State) + ")")
+			}
+			b.WriteString("</li>")
+		}
+		b.WriteString("</ul>")
+	}
+
+	if len(page.CommitList) > 0 {
+		b.WriteString("<h2>Commits</h2><ul>")
+		for i, commit := range page.CommitList {
+			if i == commitLimit {
+				fmt.Fprintf(&b, "<li>and %d more</li>", len(page.CommitList)-commitLimit)
+				break
+			}
+			fmt.Fprintf(&b, "<li><code>%s</code> %s", link(commit.Hash, commit.URL), escape(commit.Subject))
+			if commit.Author != "" {
+				fmt.Fprintf(&b, " (%s, %s)", escape(commit.Author), commit.Date.Format("2006-01-02"))
+			}
+			b.WriteString("</li>")
+		}
+		b.WriteString("</ul>")
+	}
+	return b.String()
+}
+
+// link returns text linked to url, or just the text without a url
+func link(text, url string) string {
+	if url == "" {
+		return escape(text)
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, escape(url), escape(text))
+}
+
+// escape escapes text for XHTML, keeping line breaks within a paragraph
+func escape(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br/>")
+}
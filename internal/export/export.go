@@ -0,0 +1,202 @@
+// Package export writes episodes and narratives as reports to share outside thunk: structured JSON
+// for other tools, and Markdown with per-episode sections, statistics tables and links for people.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+// Formats reports can be written in
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "md"
+)
+
+// ParseFormat parses a report format name, accepting "markdown" for md
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(name)); format {
+	case FormatJSON, FormatMarkdown:
+		return format, nil
+	case "markdown":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unsupported report format %q (supported: %s, %s)", name, FormatJSON, FormatMarkdown)
+	}
+}
+
+// Options configures a report
+type Options struct {
+	// Title heads Markdown reports; empty uses a title for the kind of report
+	Title string
+
+	// RepositoryURL is the repository's web address, such as https://github.com/owner/repo;
+	// commits link to RepositoryURL/commit/<hash> when it is set
+	RepositoryURL string
+
+	// GeneratedAt dates the report; zero uses the current time
+	GeneratedAt time.Time
+}
+
+// generatedAt returns when the report was generated
+func (o Options) generatedAt() time.Time {
+	if o.GeneratedAt.IsZero() {
+		return time.Now().UTC()
+	}
+	return o.GeneratedAt
+}
+
+// commitURL returns the web address of a commit, or "" without a repository URL
+func (o Options) commitURL(hash string) string {
+	if o.RepositoryURL == "" || hash == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(o.RepositoryURL, "/"), ".git") + "/commit/" + hash
+}
+
+// Report is the structured form of an episode report
+type Report struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Repository  string          `json:"repository,omitempty"` // Options.RepositoryURL
+	Summary     Summary         `json:"summary"`
+	Episodes    []EpisodeReport `json:"episodes"`
+}
+
+// Summary totals the work across a report's episodes
+type Summary struct {
+	Episodes  int       `json:"episodes"`
+	Commits   int       `json:"commits"`
+	Authors   int       `json:"authors"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	WorkMix   string    `json:"work_mix,omitempty"` // Share of each category, e.g. "60% feature, 40% bugfix"
+}
+
+// EpisodeReport is an exported episode with a title and links to its commits and artifacts
+type EpisodeReport struct {
+	cluster.EpisodeExport
+	Title string `json:"title"`
+	Links []Link `json:"links,omitempty"`
+}
+
+// Link points to a commit, pull request, issue, ticket or release on the web
+type Link struct {
+	Kind  string `json:"kind"` // "commit" or the artifact type
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// NewReport builds the report of episodes
+func NewReport(episodes []cluster.Episode, opts Options) Report {
+	report := Report{
+		GeneratedAt: opts.generatedAt(),
+		Repository:  opts.RepositoryURL,
+		Summary:     summarize(episodes),
+		Episodes:    make([]EpisodeReport, len(episodes)),
+	}
+	for i := range episodes {
+		report.Episodes[i] = EpisodeReport{
+			EpisodeExport: cluster.EnrichEpisode(episodes[i]),
+			Title:         episodeTitle(&episodes[i]),
+			Links:         episodeLinks(&episodes[i], opts),
+		}
+	}
+	return report
+}
+
+// ExportEpisodesJSON writes the report of episodes as indented JSON
+func ExportEpisodesJSON(w io.Writer, episodes []cluster.Episode, opts Options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(NewReport(episodes, opts)); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
+
+// summarize totals the work across episodes, counting commits shared between them once
+func summarize(episodes []cluster.Episode) Summary {
+	summary := Summary{
+		Episodes: len(episodes),
+		Commits:  cluster.CountUniqueCommits(episodes),
+		WorkMix:  cluster.FormatCategoryCounts(cluster.CountCategories(episodes)),
+	}
+	authors := make(map[string]bool)
+	for i := range episodes {
+		for _, commit := range episodes[i].GetPrimaryCommits() {
+			authors[strings.ToLower(commit.Author.Email)] = true
+			summary.Additions += commit.Stats.Additions
+			summary.Deletions += commit.Stats.Deletions
+		}
+		start, end := episodes[i].GetDateRange()
+		if !start.IsZero() && (summary.Start.IsZero() || start.Before(summary.Start)) {
+			summary.Start = start
+		}
+		if end.After(summary.End) {
+			summary.End = end
+		}
+	}
+	summary.Authors = len(authors)
+	return summary
+}
+
+// episodeTitle names an episode after its first commit's subject, else its first artifact's title
+func episodeTitle(ep *cluster.Episode) string {
+	if len(ep.Commits) > 0 {
+		subject, _, _ := strings.Cut(strings.TrimSpace(ep.Commits[0].Message), "\n")
+		return subject
+	}
+	if len(ep.Artifacts) > 0 {
+		return ep.Artifacts[0].Title
+	}
+	return ""
+}
+
+// episodeLinks links an episode's artifacts, then its commits when the repository URL is known
+func episodeLinks(ep *cluster.Episode, opts Options) []Link {
+	var links []Link
+	for _, artifact := range ep.Artifacts {
+		if artifact.URL != "" {
+			links = append(links, Link{Kind: string(artifact.Type), Label: artifactLabel(&artifact), URL: artifact.URL})
+		}
+	}
+	for _, commit := range ep.Commits {
+		if url := opts.commitURL(commit.Hash); url != "" {
+			links = append(links, Link{Kind: "commit", Label: shortHash(commit.Hash), URL: url})
+		}
+	}
+	return links
+}
+
+// artifactLabel names an artifact the way its platform does, such as "PR #7" or "PROJ-12"
+func artifactLabel(artifact *cluster.Artifact) string {
+	switch artifact.Type {
+	case cluster.ArtifactPullRequest:
+		return fmt.Sprintf("PR #%d", artifact.Number)
+	case cluster.ArtifactMergeRequest:
+		return fmt.Sprintf("MR !%d", artifact.Number)
+	case cluster.ArtifactIssue:
+		return fmt.Sprintf("Issue #%d", artifact.Number)
+	case cluster.ArtifactRelease:
+		if artifact.Metadata.TagName != "" {
+			return "Release " + artifact.Metadata.TagName
+		}
+	case cluster.ArtifactTicket:
+		if artifact.Metadata.TicketKey != "" {
+			return artifact.Metadata.TicketKey
+		}
+	}
+	return artifact.ID
+}
+
+// shortHash abbreviates a commit hash to 7 characters
+func shortHash(hash string) string {
+	return hash[:min(len(hash), 7)]
+}
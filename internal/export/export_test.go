@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// testEpisodes returns two episodes: retries built over two commits with a pull request, and a
+// login fix by another author
+func testEpisodes() []cluster.Episode {
+	when := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	ann := git.Author{Name: "Ann", Email: "ann@example.com"}
+	episodes := []cluster.Episode{
+		{ID: "E1", Category: "feature",
+			Commits: []git.Commit{
+				{Hash: "aaaaaaa111", Message: "Add retries\n\nWith backoff.", Author: ann, CommittedAt: when,
+					Stats: git.CommitStats{Additions: 10, Deletions: 2}, Diffs: []git.Diff{{FilePath: "retry.go", Additions: 10, Deletions: 2}}},
+				{Hash: "bbbbbbb222", Message: "Cap retries", Author: ann, CommittedAt: when.Add(24 * time.Hour),
+					Stats: git.CommitStats{Additions: 3}, Diffs: []git.Diff{{FilePath: "retry.go", Additions: 3}}},
+			},
+			Artifacts: []cluster.Artifact{{ID: "PR-7", Number: 7, Type: cluster.ArtifactPullRequest, Title: "Retries | backoff", State: "merged", URL: "https://github.com/acme/app/pull/7"}},
+		},
+		{ID: "E2", Category: "bugfix",
+			Commits: []git.Commit{{Hash: "ccccccc333", Message: "Fix login", Author: git.Author{Name: "Bob", Email: "bob@example.com"}, CommittedAt: when.Add(48 * time.Hour),
+				Stats: git.CommitStats{Additions: 1, Deletions: 1}}},
+		},
+	}
+	for i := range episodes {
+		episodes[i].Stats = episodes[i].ComputeStats()
+	}
+	return episodes
+}
+
+func TestExportEpisodesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{RepositoryURL: "https://github.com/acme/app.git", GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	if err := ExportEpisodesJSON(&buf, testEpisodes(), opts); err != nil {
+		t.Fatalf("ExportEpisodesJSON failed: %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, buf.String())
+	}
+	s := report.Summary
+	if s.Episodes != 2 || s.Commits != 3 || s.Authors != 2 || s.Additions != 14 || s.Deletions != 3 || s.WorkMix == "" {
+		t.Errorf("Unexpected summary %+v", s)
+	}
+	if !s.Start.Equal(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)) || !s.End.Equal(time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the summary to span the episodes, got %s to %s", s.Start, s.End)
+	}
+
+	ep := report.Episodes[0]
+	if ep.ID != "E1" || ep.Title != "Add retries" || ep.CommitCount != 2 || ep.PRCount != 1 {
+		t.Errorf("Expected the enriched episode with its title, got %+v", ep)
+	}
+	want := []Link{
+		{Kind: "pull_request", Label: "PR #7", URL: "https://github.com/acme/app/pull/7"},
+		{Kind: "commit", Label: "aaaaaaa", URL: "https://github.com/acme/app/commit/aaaaaaa111"},
+		{Kind: "commit", Label: "bbbbbbb", URL: "https://github.com/acme/app/commit/bbbbbbb222"},
+	}
+	if len(ep.Links) != len(want) {
+		t.Fatalf("Expected links %+v, got %+v", want, ep.Links)
+	}
+	for i := range want {
+		if ep.Links[i] != want[i] {
+			t.Errorf("Expected link %+v, got %+v", want[i], ep.Links[i])
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]string{"json": FormatJSON, " MD": FormatMarkdown, "markdown": FormatMarkdown} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("html"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,221 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// Caps on what a Markdown episode section lists
+const (
+	markdownCommitLimit  = 20
+	markdownHotspotLimit = 5
+)
+
+// ExportEpisodesMarkdown writes the report of episodes as Markdown: a summary table linking to a
+// section per episode with its statistics, pull requests, issues and commits
+func ExportEpisodesMarkdown(w io.Writer, episodes []cluster.Episode, opts Options) error {
+	report := NewReport(episodes, opts)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "# %s\n\n", orDefault(opts.Title, "Episode report"))
+	writeSummary(b, report)
+
+	if len(report.Episodes) > 0 {
+		b.WriteString("| Episode | Title | Category | Dates | Commits | Authors | Lines |\n")
+		b.WriteString("| --- | --- | --- | --- | ---: | --- | ---: |\n")
+		for _, ep := range report.Episodes {
+			fmt.Fprintf(b, "| [%s](#%s) | %s | %s | %s | %d | %s | +%d / -%d |\n",
+				ep.ID, anchor(ep.ID), cell(ep.Title), cell(string(ep.Category)), dateRange(ep.StartDate, ep.EndDate),
+				ep.CommitCount, cell(strings.Join(ep.Authors, ", ")), ep.Stats.Additions, ep.Stats.Deletions)
+		}
+		b.WriteString("\n")
+	}
+
+	for i := range episodes {
+		writeEpisode(b, &episodes[i], report.Episodes[i], opts)
+	}
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	return nil
+}
+
+// ExportNarrativesMarkdown writes narratives as Markdown, a section each; narratives of one of the
+// episodes given are headed by its title and statistics and followed by links to its work
+func ExportNarrativesMarkdown(w io.Writer, narratives []*narrative.Narrative, episodes []cluster.Episode, opts Options) error {
+	byID := make(map[string]*cluster.Episode, len(episodes))
+	for i := range episodes {
+		byID[episodes[i].ID] = &episodes[i]
+	}
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "# %s\n\n", orDefault(opts.Title, "Narratives"))
+	fmt.Fprintf(b, "_Generated %s._\n\n", opts.generatedAt().Format("2006-01-02 15:04 MST"))
+
+	for _, narr := range narratives {
+		if narr == nil {
+			continue
+		}
+		ep, ok := byID[narr.EpisodeID]
+		if ok {
+			fmt.Fprintf(b, "## %s\n\n", episodeHeading(ep.ID, episodeTitle(ep)))
+			start, end := ep.GetDateRange()
+			facts := []string{dateRange(start, end), plural(len(ep.Commits), "commit")}
+			if authors := ep.GetAuthorNames(); len(authors) > 0 {
+				facts = append(facts, strings.Join(authors, ", "))
+			}
+			if ep.Category != "" {
+				facts = append(facts, string(ep.Category))
+			}
+			fmt.Fprintf(b, "_%s_\n\n", strings.Join(facts, " · "))
+		} else {
+			fmt.Fprintf(b, "## %s\n\n", narr.EpisodeID)
+		}
+
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(narr.Text))
+
+		written := byline(narr)
+		if p := narr.Provenance; p != nil && len(p.Context) > 0 {
+			sources := make([]string, len(p.Context))
+			for i, source := range p.Context {
+				sources[i] = source.EpisodeID
+			}
+			written += ", with context from " + strings.Join(sources, ", ")
+		}
+		fmt.Fprintf(b, "_%s._\n\n", written)
+
+		if ok {
+			if links := episodeLinks(ep, opts); len(links) > 0 {
+				labels := make([]string, len(links))
+				for i, link := range links {
+					labels[i] = fmt.Sprintf("[%s](%s)", link.Label, link.URL)
+				}
+				fmt.Fprintf(b, "**Links:** %s\n\n", strings.Join(labels, ", "))
+			}
+		}
+	}
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	return nil
+}
+
+// writeSummary writes the totals line and work mix of a report
+func writeSummary(b *bufio.Writer, report Report) {
+	s := report.Summary
+	fmt.Fprintf(b, "_%s, %s and %s", plural(s.Episodes, "episode"), plural(s.Commits, "commit"), plural(s.Authors, "author"))
+	if !s.Start.IsZero() {
+		fmt.Fprintf(b, ", %s", dateRange(s.Start, s.End))
+	}
+	fmt.Fprintf(b, ". Generated %s._\n\n", report.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	if report.Repository != "" {
+		fmt.Fprintf(b, "Repository: <%s>\n\n", report.Repository)
+	}
+	if s.WorkMix != "" {
+		fmt.Fprintf(b, "Work mix: %s\n\n", s.WorkMix)
+	}
+}
+
+// writeEpisode writes an episode's section: its statistics table, artifacts, hotspots and commits
+func writeEpisode(b *bufio.Writer, ep *cluster.Episode, report EpisodeReport, opts Options) {
+	fmt.Fprintf(b, "<a id=\"%s\"></a>\n\n## %s\n\n", anchor(ep.ID), episodeHeading(ep.ID, report.Title))
+
+	b.WriteString("| Statistic | Value |\n| --- | --- |\n")
+	for _, stat := range report.Statistics() {
+		fmt.Fprintf(b, "| %s | %s |\n", stat.Name, cell(stat.Value))
+	}
+	b.WriteString("\n")
+
+	if len(ep.Artifacts) > 0 {
+		b.WriteString("### Pull requests and issues\n\n")
+		for _, artifact := range ep.Artifacts {
+			label := artifactLabel(&artifact)
+			if artifact.URL != "" {
+				label = fmt.Sprintf("[%s](%s)", label, artifact.URL)
+			}
+			line := fmt.Sprintf("- %s: %s", label, artifact.Title)
+			if artifact.State != "" {
+				line += fmt.Sprintf(" (%s)", artifact.State)
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if hotspots := report.Hotspots; len(hotspots) > 0 {
+		b.WriteString("### Most changed files\n\n")
+		for _, hotspot := range hotspots[:min(len(hotspots), markdownHotspotLimit)] {
+			fmt.Fprintf(b, "- `%s`: %s, +%d / -%d\n", hotspot.Path, plural(hotspot.Changes, "change"), hotspot.Additions, hotspot.Deletions)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(ep.Commits) > 0 {
+		b.WriteString("### Commits\n\n")
+		for i, commit := range ep.Commits {
+			if i == markdownCommitLimit {
+				fmt.Fprintf(b, "- ... and %d more\n", len(ep.Commits)-markdownCommitLimit)
+				break
+			}
+			hash := "`" + shortHash(commit.Hash) + "`"
+			if url := opts.commitURL(commit.Hash); url != "" {
+				hash = fmt.Sprintf("[%s](%s)", hash, url)
+			}
+			subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+			fmt.Fprintf(b, "- %s %s (%s, %s)\n", hash, subject, commit.Author.Name, commit.CommittedAt.Format("2006-01-02"))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// episodeHeading heads an episode's section
+func episodeHeading(id, title string) string {
+	if title == "" {
+		return "Episode " + id
+	}
+	return fmt.Sprintf("Episode %s: %s", id, title)
+}
+
+// anchor returns the link target of an episode's section
+func anchor(id string) string {
+	return "episode-" + strings.ToLower(strings.NewReplacer(" ", "-", "/", "-").Replace(id))
+}
+
+// cell escapes text for a Markdown table cell
+func cell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// dateRange formats the days a span of work covers
+func dateRange(start, end time.Time) string {
+	switch {
+	case start.IsZero():
+		return "n/a"
+	case end.IsZero() || start.Format("2006-01-02") == end.Format("2006-01-02"):
+		return start.Format("2006-01-02")
+	default:
+		return start.Format("2006-01-02") + " to " + end.Format("2006-01-02")
+	}
+}
+
+// plural formats a count of things, such as "1 commit" or "3 commits"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// orDefault returns s, or fallback when s is empty
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+func TestExportEpisodesMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Title: "Q1 report", RepositoryURL: "https://github.com/acme/app", GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	if err := ExportEpisodesMarkdown(&buf, testEpisodes(), opts); err != nil {
+		t.Fatalf("ExportEpisodesMarkdown failed: %v", err)
+	}
+	md := buf.String()
+
+	for _, want := range []string{
+		"# Q1 report\n\n_2 episodes, 3 commits and 2 authors, 2024-01-01 to 2024-01-03. Generated 2024-02-01 00:00 UTC._",
+		"| [E1](#episode-e1) | Add retries | feature | 2024-01-01 to 2024-01-02 | 2 | Ann | +13 / -2 |",
+		"<a id=\"episode-e1\"></a>\n\n## Episode E1: Add retries",
+		"| Lines changed | +13 / -2 |",
+		"- [PR #7](https://github.com/acme/app/pull/7): Retries | backoff (merged)",
+		"- `retry.go`: 2 changes, +13 / -2",
+		"- [`aaaaaaa`](https://github.com/acme/app/commit/aaaaaaa111) Add retries (Ann, 2024-01-01)",
+		"## Episode E2: Fix login",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the report to contain %q, got\n%s", want, md)
+		}
+	}
+}
+
+func TestExportNarrativesMarkdown(t *testing.T) {
+	narratives := []*narrative.Narrative{
+		{EpisodeID: "E1", Text: " Retries now back off.\n", Model: "gpt-4o", Style: narrative.StyleExecutive, GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Provenance: &narrative.Provenance{Prompt: narrative.PromptEpisode, Context: []narrative.ContextSource{{EpisodeID: "E0"}}}},
+		{EpisodeID: "project", Text: "The team shipped retries."},
+	}
+	var buf bytes.Buffer
+	if err := ExportNarrativesMarkdown(&buf, narratives, testEpisodes(), Options{RepositoryURL: "https://github.com/acme/app"}); err != nil {
+		t.Fatalf("ExportNarrativesMarkdown failed: %v", err)
+	}
+	md := buf.String()
+
+	for _, want := range []string{
+		"# Narratives\n\n",
+		"## Episode E1: Add retries\n\n_2024-01-01 to 2024-01-02 · 2 commits · Ann · feature_\n\nRetries now back off.\n\n",
+		"_Written by gpt-4o in the executive style on 2024-02-01, with context from E0._",
+		"**Links:** [PR #7](https://github.com/acme/app/pull/7), [aaaaaaa](https://github.com/acme/app/commit/aaaaaaa111)",
+		"## project\n\nThe team shipped retries.\n\n_Written by an unknown model._",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the report to contain %q, got\n%s", want, md)
+		}
+	}
+}
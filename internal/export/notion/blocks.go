@@ -0,0 +1,152 @@
+package notion
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Yates-Labs/thunk/internal/export"
+)
+
+// block is a Notion block object
+type block map[string]any
+
+// entryProperties returns the database properties of a page's entry, keyed by property name
+func entryProperties(page *export.Page, title, repository string) map[string]any {
+	if page.Repository != "" {
+		repository = page.Repository
+	}
+	properties := map[string]any{
+		title:              map[string]any{"title": richText(page.Title, "", nil)},
+		PropertyEpisodeID:  map[string]any{"rich_text": richText(page.ID, "", nil)},
+		PropertyRepository: map[string]any{"rich_text": richText(repository, "", nil)},
+		PropertyCommits:    map[string]any{"number": page.CommitCount},
+		PropertyAuthors:    map[string]any{"multi_select": options(page.Authors)},
+		PropertyLabels:     map[string]any{"multi_select": options(page.Labels)},
+	}
+	if page.Category != "" {
+		properties[PropertyCategory] = map[string]any{"select": map[string]string{"name": string(page.Category)}}
+	}
+	if !page.StartDate.IsZero() {
+		dates := map[string]any{"start": page.StartDate.Format("2006-01-02")}
+		if end := page.EndDate.Format("2006-01-02"); !page.EndDate.IsZero() && end != dates["start"] {
+			dates["end"] = end
+		}
+		properties[PropertyDates] = map[string]any{"date": dates}
+	}
+	return properties
+}
+
+// options returns multi-select options of names, which Notion forbids commas in
+func options(names []string) []map[string]string {
+	opts := make([]map[string]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ReplaceAll(name, ",", ""))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		opts = append(opts, map[string]string{"name": truncate(name, 100)})
+	}
+	return opts
+}
+
+// pageBlocks returns the body of a page's entry: the narrative, its byline and lists of the
+// episode's statistics, pull requests and issues, and commits
+func pageBlocks(page *export.Page) []block {
+	var blocks []block
+	for _, paragraph := range page.Paragraphs {
+		blocks = append(blocks, textBlock("paragraph", richText(paragraph, "", nil)))
+	}
+	if byline := page.Byline(); byline != "" {
+		blocks = append(blocks, textBlock("paragraph", richText(byline, "", map[string]bool{"italic": true})))
+	}
+
+	blocks = append(blocks, textBlock("heading_2", richText("Statistics", "", nil)))
+	for _, stat := range page.Statistics() {
+		text := append(richText(stat.Name+": ", "", map[string]bool{"bold": true}), richText(stat.Value, "", nil)...)
+		blocks = append(blocks, textBlock("bulleted_list_item", text))
+	}
+
+	if len(page.Artifacts) > 0 {
+		blocks = append(blocks, textBlock("heading_2", richText("Pull requests and issues", "", nil)))
+		for _, artifact := range page.Artifacts {
+			text := richText(artifact.Label, artifact.URL, nil)
+			if artifact.Title != "" {
+				text = append(text, richText(": "+artifact.Title, "", nil)...)
+			}
+			if artifact.State != "" {
+				text = append(text, richText(" ("+artifact.State+")", "", nil)...)
+			}
+			blocks = append(blocks, textBlock("bulleted_list_item", text))
+		}
+	}
+
+	if len(page.CommitList) > 0 {
+		blocks = append(blocks, textBlock("heading_2", richText("Commits", "", nil)))
+		for i, commit := range page.CommitList {
+			if i == commitLimit {
+				more := fmt.Sprintf("and %d more", len(page.CommitList)-commitLimit)
+				blocks = append(blocks, textBlock("bulleted_list_item", richText(more, "", nil)))
+				break
+			}
+			text := append(richText(commit.Hash, commit.URL, map[string]bool{"code": true}), richText(" "+commit.Subject, "", nil)...)
+			if commit.Author != "" {
+				text = append(text, richText(" ("+commit.Author+", "+commit.Date.Format("2006-01-02")+")", "", nil)...)
+			}
+			blocks = append(blocks, textBlock("bulleted_list_item", text))
+		}
+	}
+	return blocks
+}
+
+// textBlock returns a block of a text type such as "paragraph" or "heading_2"
+func textBlock(typ string, text []map[string]any) block {
+	return block{"object": "block", "type": typ, typ: map[string]any{"rich_text": text}}
+}
+
+// richText returns text as rich text objects, split to fit Notion's length limit, linked to url
+// when it is set and with annotations such as bold or italic
+func richText(text, url string, annotations map[string]bool) []map[string]any {
+	var objects []map[string]any
+	for _, chunk := range split(text, textLimit) {
+		content := map[string]any{"content": chunk}
+		if url != "" {
+			content["link"] = map[string]string{"url": url}
+		}
+		object := map[string]any{"type": "text", "text": content}
+		if len(annotations) > 0 {
+			object["annotations"] = annotations
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+// split splits text into pieces of at most limit characters
+func split(text string, limit int) []string {
+	if text == "" {
+		return []string{""}
+	}
+	var pieces []string
+	for utf8.RuneCountInString(text) > limit {
+		cut := 0
+		for i := 0; i < limit; i++ {
+			_, size := utf8.DecodeRuneInString(text[cut:])
+			cut += size
+		}
+		pieces = append(pieces, text[:cut])
+		text = text[cut:]
+	}
+	return append(pieces, text)
+}
+
+// truncate cuts text to at most limit characters, ending it with an ellipsis when cut
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
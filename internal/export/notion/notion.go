@@ -0,0 +1,343 @@
+// Package notion exports episode pages to a Notion database through the Notion API. Each
+// episode is a database entry keyed by its episode ID, so exporting again updates entries in
+// place and leaves unchanged ones alone.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/export"
+)
+
+const (
+	// TokenEnv is read by NewClient when the token is empty
+	TokenEnv = "NOTION_TOKEN"
+
+	// DefaultEndpoint is the Notion API
+	DefaultEndpoint = "https://api.notion.com/v1"
+
+	apiVersion = "2022-06-28"
+
+	// Notion caps text objects at 2000 characters and appends 100 blocks per request
+	textLimit  = 2000
+	blockLimit = 100
+
+	// commitLimit caps the commits a page lists
+	commitLimit = 20
+
+	// maxRateLimitRetries caps how often a request is retried after Notion rate limits it
+	maxRateLimitRetries = 3
+)
+
+// Database properties written for each episode, added to the database when it lacks them
+const (
+	PropertyEpisodeID  = "Episode ID"
+	PropertyRepository = "Repository"
+	PropertyCategory   = "Category"
+	PropertyDates      = "Dates"
+	PropertyCommits    = "Commits"
+	PropertyAuthors    = "Authors"
+	PropertyLabels     = "Labels"
+	PropertyHash       = "Content Hash" // Hash of what the entry shows, to skip unchanged episodes
+)
+
+// propertyTypes are the types of the properties written for each episode
+var propertyTypes = map[string]string{
+	PropertyEpisodeID:  "rich_text",
+	PropertyRepository: "rich_text",
+	PropertyCategory:   "select",
+	PropertyDates:      "date",
+	PropertyCommits:    "number",
+	PropertyAuthors:    "multi_select",
+	PropertyLabels:     "multi_select",
+	PropertyHash:       "rich_text",
+}
+
+// Client is a minimal Notion API client
+type Client struct {
+	Endpoint string
+
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Notion client authenticated with an internal integration token
+// An empty token is read from NOTION_TOKEN; the database must be shared with the integration
+func NewClient(token string) *Client {
+	if token == "" {
+		token = os.Getenv(TokenEnv)
+	}
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Result counts the entries an export created, updated and left unchanged
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// ExportPages writes each page to the database as an entry with the episode's properties and a
+// body of its narrative, statistics, pull requests, issues and commits. Entries are matched by
+// their Episode ID property: new episodes are added, changed ones rewritten and the rest skipped.
+// repository fills the Repository property of episodes that don't record their own.
+func (c *Client) ExportPages(ctx context.Context, databaseID, repository string, pages []export.Page) (Result, error) {
+	var result Result
+	title, err := c.ensureSchema(ctx, databaseID)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range pages {
+		page := &pages[i]
+		properties := entryProperties(page, title, repository)
+		children := pageBlocks(page)
+		hash := contentHash(properties, children)
+		properties[PropertyHash] = map[string]any{"rich_text": richText(hash, "", nil)}
+
+		existing, err := c.findEntry(ctx, databaseID, page.ID)
+		if err != nil {
+			return result, err
+		}
+		switch {
+		case existing == nil:
+			created := struct {
+				ID string `json:"id"`
+			}{}
+			body := map[string]any{
+				"parent":     map[string]string{"database_id": databaseID},
+				"properties": properties,
+				"children":   children[:min(len(children), blockLimit)],
+			}
+			if err := c.do(ctx, http.MethodPost, "/pages", body, &created); err != nil {
+				return result, fmt.Errorf("failed to create the entry of episode %s: %w", page.ID, err)
+			}
+			if err := c.appendBlocks(ctx, created.ID, children[min(len(children), blockLimit):]); err != nil {
+				return result, fmt.Errorf("failed to write the entry of episode %s: %w", page.ID, err)
+			}
+			result.Created++
+		case existing.hash() == hash:
+			result.Unchanged++
+		default:
+			if err := c.do(ctx, http.MethodPatch, "/pages/"+existing.ID, map[string]any{"properties": properties}, nil); err != nil {
+				return result, fmt.Errorf("failed to update the entry of episode %s: %w", page.ID, err)
+			}
+			if err := c.replaceBlocks(ctx, existing.ID, children); err != nil {
+				return result, fmt.Errorf("failed to rewrite the entry of episode %s: %w", page.ID, err)
+			}
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// ensureSchema adds the properties the database lacks and returns the name of its title property
+func (c *Client) ensureSchema(ctx context.Context, databaseID string) (string, error) {
+	var database struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/databases/"+databaseID, nil, &database); err != nil {
+		return "", fmt.Errorf("failed to read notion database: %w", err)
+	}
+
+	title := ""
+	missing := make(map[string]any)
+	for name, typ := range propertyTypes {
+		property, ok := database.Properties[name]
+		if !ok {
+			missing[name] = map[string]any{typ: map[string]any{}}
+			continue
+		}
+		if property.Type != typ {
+			return "", fmt.Errorf("notion database property %q is a %s property, not %s", name, property.Type, typ)
+		}
+	}
+	for name, property := range database.Properties {
+		if property.Type == "title" {
+			title = name
+		}
+	}
+	if title == "" {
+		return "", fmt.Errorf("notion database has no title property")
+	}
+
+	if len(missing) > 0 {
+		if err := c.do(ctx, http.MethodPatch, "/databases/"+databaseID, map[string]any{"properties": missing}, nil); err != nil {
+			return "", fmt.Errorf("failed to add properties to notion database: %w", err)
+		}
+	}
+	return title, nil
+}
+
+// entry is a database entry as returned by a query
+type entry struct {
+	ID         string `json:"id"`
+	Properties map[string]struct {
+		RichText []struct {
+			PlainText string `json:"plain_text"`
+		} `json:"rich_text"`
+	} `json:"properties"`
+}
+
+// hash returns the content hash recorded on the entry
+func (e *entry) hash() string {
+	var b strings.Builder
+	for _, text := range e.Properties[PropertyHash].RichText {
+		b.WriteString(text.PlainText)
+	}
+	return b.String()
+}
+
+// findEntry returns the database entry of an episode, or nil when it has none yet
+func (c *Client) findEntry(ctx context.Context, databaseID, episodeID string) (*entry, error) {
+	var response struct {
+		Results []entry `json:"results"`
+	}
+	query := map[string]any{
+		"filter":    map[string]any{"property": PropertyEpisodeID, "rich_text": map[string]string{"equals": episodeID}},
+		"page_size": 1,
+	}
+	if err := c.do(ctx, http.MethodPost, "/databases/"+databaseID+"/query", query, &response); err != nil {
+		return nil, fmt.Errorf("failed to look up the entry of episode %s: %w", episodeID, err)
+	}
+	if len(response.Results) == 0 {
+		return nil, nil
+	}
+	return &response.Results[0], nil
+}
+
+// replaceBlocks deletes the body of a page and writes blocks in its place
+func (c *Client) replaceBlocks(ctx context.Context, pageID string, blocks []block) error {
+	var ids []string
+	cursor := ""
+	for {
+		path := fmt.Sprintf("/blocks/%s/children?page_size=%d", pageID, blockLimit)
+		if cursor != "" {
+			path += "&start_cursor=" + cursor
+		}
+		var response struct {
+			Results []struct {
+				ID string `json:"id"`
+			} `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+			return err
+		}
+		for _, child := range response.Results {
+			ids = append(ids, child.ID)
+		}
+		if !response.HasMore || response.NextCursor == "" {
+			break
+		}
+		cursor = response.NextCursor
+	}
+
+	for _, id := range ids {
+		if err := c.do(ctx, http.MethodDelete, "/blocks/"+id, nil, nil); err != nil {
+			return err
+		}
+	}
+	return c.appendBlocks(ctx, pageID, blocks)
+}
+
+// appendBlocks appends blocks to a page, as many per request as Notion allows
+func (c *Client) appendBlocks(ctx context.Context, pageID string, blocks []block) error {
+	for len(blocks) > 0 {
+		batch := blocks[:min(len(blocks), blockLimit)]
+		if err := c.do(ctx, http.MethodPatch, "/blocks/"+pageID+"/children", map[string]any{"children": batch}, nil); err != nil {
+			return err
+		}
+		blocks = blocks[len(batch):]
+	}
+	return nil
+}
+
+// do performs an authenticated request, waiting out rate limits, and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	if c.token == "" {
+		return fmt.Errorf("notion token is not configured (set %s)", TokenEnv)
+	}
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode notion request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.Endpoint+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create notion request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Notion-Version", apiVersion)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("notion request failed: %w", err)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			resp.Body.Close()
+			wait := time.Second
+			if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+				wait = time.Duration(seconds * float64(time.Second))
+			}
+			select {
+			case <-time.After(wait):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			var apiErr struct {
+				Message string `json:"message"`
+			}
+			data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+			if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+				apiErr.Message = strings.TrimSpace(string(data))
+			}
+			return fmt.Errorf("notion request %s %s failed: %s: %s", method, path, resp.Status, apiErr.Message)
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode notion response: %w", err)
+		}
+		return nil
+	}
+}
+
+// contentHash hashes the properties and body an entry is written with
+func contentHash(properties map[string]any, blocks []block) string {
+	// Maps marshal with sorted keys, so equal content hashes alike
+	data, _ := json.Marshal(map[string]any{"properties": properties, "blocks": blocks})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,229 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// fakeNotion serves the parts of the Notion API the exporter uses, keeping entries in memory
+type fakeNotion struct {
+	mu         sync.Mutex
+	properties map[string]string         // Database property types by name
+	entries    map[string]map[string]any // Entry properties by page ID
+	blocks     map[string][]any          // Entry bodies by page ID
+	requests   []string
+}
+
+func newFakeNotion(t *testing.T) (*fakeNotion, *Client) {
+	t.Helper()
+	fake := &fakeNotion{
+		properties: map[string]string{"Name": "title", PropertyEpisodeID: "rich_text"},
+		entries:    make(map[string]map[string]any),
+		blocks:     make(map[string][]any),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	client := NewClient("secret")
+	client.Endpoint = server.URL
+	return fake, client
+}
+
+func (f *fakeNotion) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Notion-Version") == "" {
+		http.Error(w, `{"message": "API token is invalid."}`, http.StatusUnauthorized)
+		return
+	}
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && parts[0] == "databases":
+		properties := make(map[string]any)
+		for name, typ := range f.properties {
+			properties[name] = map[string]string{"type": typ}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"properties": properties})
+	case r.Method == http.MethodPatch && parts[0] == "databases":
+		for name, property := range body["properties"].(map[string]any) {
+			for typ := range property.(map[string]any) {
+				f.properties[name] = typ
+			}
+		}
+		w.Write([]byte(`{}`))
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "query":
+		want := body["filter"].(map[string]any)["rich_text"].(map[string]any)["equals"]
+		results := []any{}
+		for id, properties := range f.entries {
+			if plainText(properties[PropertyEpisodeID]) == want {
+				results = append(results, map[string]any{"id": id, "properties": properties})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	case r.Method == http.MethodPost && parts[0] == "pages":
+		id := fmt.Sprintf("page-%d", len(f.entries)+1)
+		f.entries[id] = withPlainText(body["properties"].(map[string]any))
+		f.blocks[id] = body["children"].([]any)
+		json.NewEncoder(w).Encode(map[string]string{"id": id})
+	case r.Method == http.MethodPatch && parts[0] == "pages":
+		f.entries[parts[1]] = withPlainText(body["properties"].(map[string]any))
+		w.Write([]byte(`{}`))
+	case r.Method == http.MethodGet && parts[0] == "blocks":
+		results := []any{}
+		for i := range f.blocks[parts[1]] {
+			results = append(results, map[string]string{"id": fmt.Sprintf("%s:%d", parts[1], i)})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results, "has_more": false})
+	case r.Method == http.MethodDelete && parts[0] == "blocks":
+		page, _, _ := strings.Cut(parts[1], ":")
+		f.blocks[page] = f.blocks[page][:len(f.blocks[page])-1]
+		w.Write([]byte(`{}`))
+	case r.Method == http.MethodPatch && parts[0] == "blocks":
+		f.blocks[parts[1]] = append(f.blocks[parts[1]], body["children"].([]any)...)
+		w.Write([]byte(`{}`))
+	default:
+		http.Error(w, `{"message": "not found"}`, http.StatusNotFound)
+	}
+}
+
+// withPlainText fills in the plain_text Notion returns for each rich text object
+func withPlainText(properties map[string]any) map[string]any {
+	for _, property := range properties {
+		for _, texts := range property.(map[string]any) {
+			if objects, ok := texts.([]any); ok {
+				for _, object := range objects {
+					if object, ok := object.(map[string]any); ok && object["text"] != nil {
+						object["plain_text"] = object["text"].(map[string]any)["content"]
+					}
+				}
+			}
+		}
+	}
+	return properties
+}
+
+func plainText(property any) string {
+	var b strings.Builder
+	for _, object := range property.(map[string]any)["rich_text"].([]any) {
+		b.WriteString(object.(map[string]any)["plain_text"].(string))
+	}
+	return b.String()
+}
+
+func testPages(text string) []export.Page {
+	when := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	ep := cluster.Episode{ID: "E1", Category: cluster.CategoryFeature,
+		Commits: []git.Commit{{Hash: "aaaaaaa111", Message: "Add retries", Author: git.Author{Name: "Ann"}, CommittedAt: when}},
+		Artifacts: []cluster.Artifact{{ID: "PR-7", Number: 7, Type: cluster.ArtifactPullRequest, Title: "Retries", URL: "https://github.com/acme/app/pull/7",
+			Labels: []string{"backend", "a,b"}}},
+	}
+	ep.Stats = ep.ComputeStats()
+	narr := &narrative.Narrative{EpisodeID: "E1", Text: text, Model: "gpt-4o"}
+	return export.NewPages([]cluster.Episode{ep}, []*narrative.Narrative{narr}, export.Options{RepositoryURL: "https://github.com/acme/app"})
+}
+
+func TestExportPages(t *testing.T) {
+	fake, client := newFakeNotion(t)
+	ctx := context.Background()
+
+	result, err := client.ExportPages(ctx, "db1", "acme/app", testPages("Retries now back off.\n\nThey are capped."))
+	if err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+	if result != (Result{Created: 1}) {
+		t.Errorf("Expected the entry created, got %+v", result)
+	}
+	if fake.properties[PropertyHash] != "rich_text" || fake.properties[PropertyAuthors] != "multi_select" {
+		t.Errorf("Expected the missing properties added, got %v", fake.properties)
+	}
+	properties := fake.entries["page-1"]
+	if title := properties["Name"].(map[string]any)["title"].([]any)[0].(map[string]any)["plain_text"]; title != "Add retries" {
+		t.Errorf("Expected the episode title as the entry's title, got %v", title)
+	}
+	if repository := plainText(properties[PropertyRepository]); repository != "acme/app" {
+		t.Errorf("Expected the repository property, got %q", repository)
+	}
+	labels := properties[PropertyLabels].(map[string]any)["multi_select"].([]any)
+	if len(labels) != 2 || labels[1].(map[string]any)["name"] != "ab" {
+		t.Errorf("Expected labels without commas, got %v", labels)
+	}
+	created := len(fake.blocks["page-1"])
+
+	// Exporting the same pages again leaves the entry alone
+	fake.requests = nil
+	if result, err = client.ExportPages(ctx, "db1", "acme/app", testPages("Retries now back off.\n\nThey are capped.")); err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+	if result != (Result{Unchanged: 1}) {
+		t.Errorf("Expected the entry unchanged, got %+v", result)
+	}
+	for _, request := range fake.requests {
+		if !strings.HasPrefix(request, "GET /databases") && !strings.HasSuffix(request, "/query") {
+			t.Errorf("Expected no writes for an unchanged entry, got %s", request)
+		}
+	}
+
+	// A changed narrative rewrites the entry in place
+	if result, err = client.ExportPages(ctx, "db1", "acme/app", testPages("Retries now back off.")); err != nil {
+		t.Fatalf("ExportPages failed: %v", err)
+	}
+	if result != (Result{Updated: 1}) || len(fake.entries) != 1 {
+		t.Errorf("Expected the entry updated in place, got %+v and %d entries", result, len(fake.entries))
+	}
+	if got := len(fake.blocks["page-1"]); got != created-1 {
+		t.Errorf("Expected the body rewritten with one paragraph fewer, got %d blocks after %d", got, created)
+	}
+}
+
+func TestExportPages_Errors(t *testing.T) {
+	fake, client := newFakeNotion(t)
+	fake.properties[PropertyCommits] = "rich_text"
+	if _, err := client.ExportPages(context.Background(), "db1", "", testPages("")); err == nil || !strings.Contains(err.Error(), `"Commits"`) {
+		t.Errorf("Expected an error for a property of the wrong type, got %v", err)
+	}
+
+	endpoint := client.Endpoint
+	client = NewClient("wrong")
+	client.Endpoint = endpoint
+	if _, err := client.ExportPages(context.Background(), "db1", "", testPages("")); err == nil || !strings.Contains(err.Error(), "API token is invalid.") {
+		t.Errorf("Expected Notion's error message, got %v", err)
+	}
+
+	t.Setenv(TokenEnv, "")
+	if _, err := NewClient("").ExportPages(context.Background(), "db1", "", nil); err == nil || !strings.Contains(err.Error(), TokenEnv) {
+		t.Errorf("Expected an error naming %s, got %v", TokenEnv, err)
+	}
+}
+
+func TestPageBlocks(t *testing.T) {
+	pages := testPages(strings.Repeat("x", textLimit+10))
+	blocks := pageBlocks(&pages[0])
+
+	text := blocks[0]["paragraph"].(map[string]any)["rich_text"].([]map[string]any)
+	if len(text) != 2 || text[1]["text"].(map[string]any)["content"] != "xxxxxxxxxx" {
+		t.Errorf("Expected the paragraph split at %d characters, got %d pieces", textLimit, len(text))
+	}
+	if byline := blocks[1]["paragraph"].(map[string]any)["rich_text"].([]map[string]any)[0]; byline["annotations"].(map[string]bool)["italic"] != true {
+		t.Errorf("Expected an italic byline, got %v", byline)
+	}
+	last := blocks[len(blocks)-1]["bulleted_list_item"].(map[string]any)["rich_text"].([]map[string]any)
+	if link := last[0]["text"].(map[string]any)["link"]; link.(map[string]string)["url"] != "https://github.com/acme/app/commit/aaaaaaa111" {
+		t.Errorf("Expected the commit linked, got %v", link)
+	}
+}
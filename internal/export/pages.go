@@ -0,0 +1,124 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// Page is what a documentation page shows of an episode: its report, its narrative when it has
+// one, and the pull requests, issues and commits it links to
+type Page struct {
+	EpisodeReport
+	Narrative  *narrative.Narrative
+	Paragraphs []string // Paragraphs of the narrative
+	Labels     []string // Labels of the episode's artifacts
+	Artifacts  []ArtifactLink
+	CommitList []CommitLink
+}
+
+// ArtifactLink is a pull request, issue, ticket or release a page links to
+type ArtifactLink struct {
+	Label, Title, State, URL string
+}
+
+// CommitLink is a commit a page lists
+type CommitLink struct {
+	Hash, URL, Subject, Author string
+	Date                       time.Time
+}
+
+// NewPages builds the page of each episode, with the narrative written for it among narratives
+func NewPages(episodes []cluster.Episode, narratives []*narrative.Narrative, opts Options) []Page {
+	report := NewReport(episodes, opts)
+	byID := make(map[string]*narrative.Narrative, len(narratives))
+	for _, narr := range narratives {
+		if narr != nil {
+			byID[narr.EpisodeID] = narr
+		}
+	}
+
+	pages := make([]Page, len(episodes))
+	for i := range episodes {
+		ep := &episodes[i]
+		page := Page{EpisodeReport: report.Episodes[i], Narrative: byID[ep.ID]}
+		if page.Title == "" {
+			page.Title = "Episode " + ep.ID
+		}
+		if page.Narrative != nil {
+			for _, paragraph := range strings.Split(strings.TrimSpace(page.Narrative.Text), "\n\n") {
+				if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+					page.Paragraphs = append(page.Paragraphs, paragraph)
+				}
+			}
+		}
+		for _, artifact := range ep.Artifacts {
+			page.Artifacts = append(page.Artifacts, ArtifactLink{Label: artifactLabel(&artifact), Title: artifact.Title, State: artifact.State, URL: artifact.URL})
+			for _, label := range artifact.Labels {
+				if !slices.Contains(page.Labels, label) {
+					page.Labels = append(page.Labels, label)
+				}
+			}
+		}
+		for _, commit := range ep.Commits {
+			subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+			page.CommitList = append(page.CommitList, CommitLink{
+				Hash: shortHash(commit.Hash), URL: opts.commitURL(commit.Hash), Subject: subject, Author: commit.Author.Name, Date: commit.CommittedAt,
+			})
+		}
+		pages[i] = page
+	}
+	return pages
+}
+
+// Statistic is a row of an episode's statistics table
+type Statistic struct {
+	Name, Value string
+}
+
+// Statistics lists an episode's statistics as reports and pages show them
+func (r *EpisodeReport) Statistics() []Statistic {
+	stats := []Statistic{
+		{"Dates", dateRange(r.StartDate, r.EndDate)},
+		{"Duration", r.Duration},
+		{"Commits", fmt.Sprint(r.CommitCount)},
+		{"Authors", strings.Join(r.Authors, ", ")},
+		{"Lines changed", fmt.Sprintf("+%d / -%d", r.Stats.Additions, r.Stats.Deletions)},
+		{"Files changed", fmt.Sprint(r.Stats.FilesChanged)},
+		{"Pull requests", fmt.Sprint(r.PRCount)},
+		{"Issues", fmt.Sprint(r.IssueCount)},
+		{"Review comments", fmt.Sprint(r.Stats.ReviewComments)},
+	}
+	if r.Category != "" {
+		stats = append(stats, Statistic{"Category", string(r.Category)})
+	}
+	if len(r.Releases) > 0 {
+		stats = append(stats, Statistic{"Releases", strings.Join(r.Releases, ", ")})
+	}
+	return stats
+}
+
+// Byline credits the page's narrative to the model and style that wrote it, or is "" without one
+func (p *Page) Byline() string {
+	if p.Narrative == nil {
+		return ""
+	}
+	return byline(p.Narrative)
+}
+
+// byline credits a narrative to the model and style that wrote it, such as "Written by gpt-4o in
+// the executive style on 2024-02-01"
+func byline(narr *narrative.Narrative) string {
+	written := fmt.Sprintf("Written by %s", orDefault(narr.Model, "an unknown model"))
+	if narr.Style != "" {
+		written += fmt.Sprintf(" in the %s style", narr.Style)
+	}
+	if !narr.GeneratedAt.IsZero() {
+		written += " on " + narr.GeneratedAt.Format("2006-01-02")
+	}
+	return written
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+func TestNewPages(t *testing.T) {
+	episodes := testEpisodes()
+	episodes[0].Artifacts[0].Labels = []string{"reliability", "backend"}
+	episodes[1].Commits[0].Message = ""
+	narratives := []*narrative.Narrative{{EpisodeID: "E1", Text: " Retries now back off.\n\n\n\nThey are capped.\n", Model: "gpt-4o", Style: "executive",
+		GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}}
+
+	pages := NewPages(episodes, narratives, Options{RepositoryURL: "https://github.com/acme/app"})
+	if len(pages) != 2 {
+		t.Fatalf("Expected a page per episode, got %d", len(pages))
+	}
+	first := pages[0]
+	if len(first.Paragraphs) != 2 || first.Paragraphs[1] != "They are capped." {
+		t.Errorf("Expected the narrative split into paragraphs, got %q", first.Paragraphs)
+	}
+	if first.Byline() != "Written by gpt-4o in the executive style on 2024-02-01" {
+		t.Errorf("Unexpected byline %q", first.Byline())
+	}
+	if len(first.Labels) != 2 || first.Artifacts[0].Label != "PR #7" || first.Artifacts[0].State != "merged" {
+		t.Errorf("Unexpected labels %v and artifacts %+v", first.Labels, first.Artifacts)
+	}
+	if commit := first.CommitList[0]; commit.Hash != "aaaaaaa" || commit.Subject != "Add retries" || commit.URL != "https://github.com/acme/app/commit/aaaaaaa111" {
+		t.Errorf("Unexpected commit %+v", commit)
+	}
+
+	second := pages[1]
+	if second.Narrative != nil || second.Byline() != "" || second.Title != "Episode E2" {
+		t.Errorf("Expected a page without a narrative titled by its ID, got %+v", second)
+	}
+	stats := second.Statistics()
+	if stats[0] != (Statistic{"Dates", "2024-01-03"}) || stats[len(stats)-1] != (Statistic{"Category", "bugfix"}) {
+		t.Errorf("Unexpected statistics %+v", stats)
+	}
+}
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+//go:embed templates/site.html templates/style.css
+var siteFiles embed.FS
+
+// siteTemplates renders the pages of a site
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"anchor":    pageName,
+	"dateRange": dateRange,
+	"plural":    plural,
+	"join":      strings.Join,
+}).ParseFS(siteFiles, "templates/site.html"))
+
+// siteEpisode is an episode's page on a site
+type siteEpisode struct {
+	Page
+	Href string // Path of the episode's page from the site root
+}
+
+// siteGroup is a section of a listing page: the episodes of a month, an author or a label
+type siteGroup struct {
+	Name, Anchor string
+	Episodes     []*siteEpisode
+}
+
+// sitePage is the data a page template is rendered with; listings are at the site root
+type sitePage struct {
+	Title   string
+	Root    string // Relative path from the page to the site root
+	Nav     string // Listing the page belongs to: "index", "authors" or "labels"
+	Report  Report
+	Groups  []siteGroup
+	Episode *siteEpisode
+}
+
+// WriteSite writes a static site of episodes and their narratives to dir, ready to publish on
+// GitHub Pages: listings of the episodes by time, author and label, and a page per episode with
+// its narrative, statistics, pull requests, issues and commits. Narratives of other episodes are
+// ignored, and episodes without a narrative get a page without one.
+func WriteSite(dir string, episodes []cluster.Episode, narratives []*narrative.Narrative, opts Options) error {
+	report := NewReport(episodes, opts)
+
+	// Newest work first throughout the site
+	entries := make([]*siteEpisode, len(episodes))
+	for i, page := range NewPages(episodes, narratives, opts) {
+		entries[i] = &siteEpisode{Page: page, Href: "episodes/" + pageName(page.ID) + ".html"}
+	}
+	slices.SortStableFunc(entries, func(a, b *siteEpisode) int { return b.StartDate.Compare(a.StartDate) })
+
+	title := orDefault(opts.Title, "Project history")
+	pages := map[string]sitePage{
+		"index.html":   {Title: title, Nav: "index", Report: report, Groups: groupEpisodes(entries, byMonth, false)},
+		"authors.html": {Title: title + ": authors", Nav: "authors", Report: report, Groups: groupEpisodes(entries, byAuthor, true)},
+		"labels.html":  {Title: title + ": labels", Nav: "labels", Report: report, Groups: groupEpisodes(entries, byLabel, true)},
+	}
+	for _, entry := range entries {
+		pages[entry.Href] = sitePage{Title: entry.Title, Root: "../", Report: report, Episode: entry}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "episodes"), 0o755); err != nil {
+		return fmt.Errorf("failed to create site directory: %w", err)
+	}
+	for name, page := range pages {
+		templateName := "listing"
+		if page.Episode != nil {
+			templateName = "episode"
+		}
+		var buf bytes.Buffer
+		if err := siteTemplates.ExecuteTemplate(&buf, templateName, page); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	style, err := siteFiles.ReadFile("templates/style.css")
+	if err != nil {
+		return fmt.Errorf("failed to read stylesheet: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), style, 0o644); err != nil {
+		return fmt.Errorf("failed to write style.css: %w", err)
+	}
+	// Keep GitHub Pages from running the site through Jekyll
+	if err := os.WriteFile(filepath.Join(dir, ".nojekyll"), nil, 0o644); err != nil {
+		return fmt.Errorf("failed to write .nojekyll: %w", err)
+	}
+	return nil
+}
+
+// Keys that listing pages group episodes by
+var (
+	byMonth = func(e *siteEpisode) []string {
+		if e.StartDate.IsZero() {
+			return []string{"Undated"}
+		}
+		return []string{e.StartDate.Format("January 2006")}
+	}
+	byAuthor = func(e *siteEpisode) []string { return e.Authors }
+	byLabel  = func(e *siteEpisode) []string { return e.Labels }
+)
+
+// groupEpisodes groups episodes under each of their keys, keeping their order within a group;
+// groups come in the order of their first episode, or sorted by name
+func groupEpisodes(episodes []*siteEpisode, keys func(*siteEpisode) []string, sorted bool) []siteGroup {
+	var groups []siteGroup
+	index := make(map[string]int)
+	for _, ep := range episodes {
+		for _, key := range keys(ep) {
+			i, ok := index[key]
+			if !ok {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, siteGroup{Name: key, Anchor: pageName(key)})
+			}
+			groups[i].Episodes = append(groups[i].Episodes, ep)
+		}
+	}
+	if sorted {
+		slices.SortFunc(groups, func(a, b siteGroup) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) })
+	}
+	return groups
+}
+
+// pageName turns an episode ID or group name into a file name or anchor safe on any host
+func pageName(name string) string {
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, name), "-.")
+	return orDefault(name, "untitled")
+}
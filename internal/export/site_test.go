@@ -0,0 +1,73 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+func TestWriteSite(t *testing.T) {
+	dir := t.TempDir()
+	episodes := testEpisodes()
+	episodes[0].Artifacts[0].Labels = []string{"Reliability"}
+	narratives := []*narrative.Narrative{{EpisodeID: "E1", Text: "Retries now back off.\n\nThey are <capped>.", Model: "gpt-4o"}}
+	opts := Options{Title: "Acme history", RepositoryURL: "https://github.com/acme/app", GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	if err := WriteSite(dir, episodes, narratives, opts); err != nil {
+		t.Fatalf("WriteSite failed: %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		return string(data)
+	}
+	for name, wants := range map[string][]string{
+		"index.html": {
+			"<title>Acme history</title>",
+			"<h2>January 2024</h2>",
+			`<a href="episodes/e1.html">Add retries</a>`,
+		},
+		"authors.html": {
+			`<a href="#ann">Ann</a> (1)`,
+			`<section id="bob">`,
+		},
+		"labels.html": {
+			`<section id="reliability">`,
+			`<a href="episodes/e1.html">Add retries</a>`,
+		},
+		"episodes/e1.html": {
+			`<link rel="stylesheet" href="../style.css">`,
+			"<p>Retries now back off.</p>\n<p>They are &lt;capped&gt;.</p>",
+			"Written by gpt-4o.",
+			`<a class="label" href="../labels.html#reliability">Reliability</a>`,
+			`<a href="../authors.html#ann">Ann</a>`,
+			`<a href="https://github.com/acme/app/pull/7">PR #7</a>: Retries | backoff`,
+			`<a href="https://github.com/acme/app/commit/aaaaaaa111"><code>aaaaaaa</code></a> Add retries`,
+		},
+	} {
+		page := read(name)
+		for _, want := range wants {
+			if !strings.Contains(page, want) {
+				t.Errorf("Expected %s to contain %q, got\n%s", name, want, page)
+			}
+		}
+	}
+
+	// Newest work first, and no narrative section without a narrative
+	index := read("index.html")
+	if strings.Index(index, "Fix login") > strings.Index(index, "Add retries") {
+		t.Errorf("Expected the newest episode first, got\n%s", index)
+	}
+	if page := read("episodes/e2.html"); strings.Contains(page, "narrative") {
+		t.Errorf("Expected no narrative on the page of E2, got\n%s", page)
+	}
+	read("style.css")
+	read(".nojekyll")
+}
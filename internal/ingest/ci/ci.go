@@ -0,0 +1,52 @@
+// Package ci ingests build results from external CI providers
+package ci
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Provider lists the builds of one CI project
+type Provider interface {
+	// Name identifies the provider, e.g. "jenkins"
+	Name() string
+
+	// ListBuilds returns the builds started at or after since (all builds when since is zero)
+	ListBuilds(ctx context.Context, since time.Time) ([]Build, error)
+}
+
+// ProvidersFromEnv returns a provider for each CI system configured in the environment
+// Jenkins is configured by JENKINS_URL and JENKINS_JOB, CircleCI by CIRCLECI_PROJECT
+func ProvidersFromEnv() []Provider {
+	var providers []Provider
+	if os.Getenv(JenkinsURLEnv) != "" && os.Getenv(JenkinsJobEnv) != "" {
+		providers = append(providers, NewJenkins("", "", "", ""))
+	}
+	if os.Getenv(CircleCIProjectEnv) != "" {
+		providers = append(providers, NewCircleCI("", ""))
+	}
+	return providers
+}
+
+// newHTTPClient returns the HTTP client used by providers
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// pullRequestBranchPattern matches the branch or job names CI systems give pull/merge request builds,
+// e.g. "PR-12" (Jenkins), "MR-12" (Jenkins with GitLab) and "pull/12" (CircleCI forked PRs)
+var pullRequestBranchPattern = regexp.MustCompile(`^(?:PR-|MR-|pull/)(\d+)$`)
+
+// pullRequestNumber returns the pull/merge request number encoded in a branch name, or 0
+func pullRequestNumber(branch string) int {
+	match := pullRequestBranchPattern.FindStringSubmatch(branch)
+	if match == nil {
+		return 0
+	}
+	number, _ := strconv.Atoi(match[1])
+	return number
+}
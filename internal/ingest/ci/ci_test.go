@@ -0,0 +1,118 @@
+package ci
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJenkins_ListBuilds_Multibranch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "bot" || token != "secret" {
+			t.Errorf("Expected basic auth, got %q/%q", user, token)
+		}
+		switch r.URL.EscapedPath() {
+		case "/job/team/job/service/api/json":
+			w.Write([]byte(`{"jobs": [{"name": "PR-12"}, {"name": "feature%2Flogin"}]}`))
+		case "/job/team/job/service/job/PR-12/api/json":
+			w.Write([]byte(`{"builds": [
+				{"number": 2, "result": null, "building": true, "timestamp": 1714989600000, "url": "https://ci/pr12/2"},
+				{"number": 1, "result": "UNSTABLE", "timestamp": 1714986000000, "duration": 60000,
+				 "actions": [{}, {"lastBuiltRevision": {"SHA1": "abc123", "branch": [{"name": "PR-12"}]}}]},
+				{"number": 0, "result": "SUCCESS", "timestamp": 1000}
+			]}`))
+		case "/job/team/job/service/job/feature%252Flogin/api/json":
+			w.Write([]byte(`{"builds": [{"number": 7, "result": "ABORTED", "timestamp": 1714986000000}]}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.EscapedPath())
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	jenkins := NewJenkins(server.URL, "team/service", "bot", "secret")
+	builds, err := jenkins.ListBuilds(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ListBuilds failed: %v", err)
+	}
+	if len(builds) != 3 {
+		t.Fatalf("Expected 3 builds since the cutoff, got %+v", builds)
+	}
+
+	if running := builds[0]; running.Status != StatusRunning || running.FinishedAt != nil || running.PullRequest != 12 {
+		t.Errorf("Unexpected running build: %+v", running)
+	}
+	unstable := builds[1]
+	if unstable.Status != StatusFailed || unstable.CommitSHA != "abc123" || unstable.Name != "team/service/PR-12" {
+		t.Errorf("Unexpected unstable build: %+v", unstable)
+	}
+	if unstable.FinishedAt == nil || unstable.FinishedAt.Sub(unstable.StartedAt) != time.Minute {
+		t.Errorf("Expected the finish time from the duration, got %v", unstable.FinishedAt)
+	}
+	if branch := builds[2]; branch.Status != StatusCanceled || branch.Branch != "feature/login" || branch.PullRequest != 0 {
+		t.Errorf("Unexpected branch build: %+v", branch)
+	}
+}
+
+func TestCircleCI_ListBuilds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Circle-Token") != "token" {
+			t.Errorf("Expected the Circle-Token header")
+		}
+		switch {
+		case r.URL.Path == "/project/gh/org/repo/pipeline" && r.URL.Query().Get("page-token") == "":
+			w.Write([]byte(`{"items": [{"id": "p2", "number": 41, "created_at": "2024-05-06T10:00:00Z",
+				"vcs": {"revision": "def456", "branch": "pull/12"}}], "next_page_token": "next"}`))
+		case r.URL.Path == "/project/gh/org/repo/pipeline":
+			w.Write([]byte(`{"items": [
+				{"id": "p1", "number": 40, "created_at": "2024-05-05T10:00:00Z", "vcs": {"revision": "abc123", "branch": "fix-login"}},
+				{"id": "p0", "number": 39, "created_at": "2023-12-01T10:00:00Z", "vcs": {"revision": "old", "branch": "main"}}
+			], "next_page_token": "never-fetched"}`))
+		case r.URL.Path == "/pipeline/p2/workflow":
+			w.Write([]byte(`{"items": [{"id": "w2", "name": "test", "status": "running", "created_at": "2024-05-06T10:00:05Z"}]}`))
+		case r.URL.Path == "/pipeline/p1/workflow":
+			w.Write([]byte(`{"items": [
+				{"id": "w1", "name": "test", "status": "failed", "created_at": "2024-05-05T10:00:05Z", "stopped_at": "2024-05-05T10:05:00Z"},
+				{"id": "w0", "name": "deploy", "status": "not_run", "created_at": "2024-05-05T10:00:05Z"}
+			]}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.String())
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	circle := NewCircleCI("gh/org/repo", "token")
+	circle.BaseURL = server.URL
+
+	builds, err := circle.ListBuilds(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ListBuilds failed: %v", err)
+	}
+	if len(builds) != 3 {
+		t.Fatalf("Expected 3 workflows from pipelines since the cutoff, got %+v", builds)
+	}
+	if pr := builds[0]; pr.Status != StatusRunning || pr.PullRequest != 12 || !strings.HasSuffix(pr.URL, "/w2") {
+		t.Errorf("Unexpected PR build: %+v", pr)
+	}
+	if failed := builds[1]; failed.Status != StatusFailed || failed.CommitSHA != "abc123" || failed.Branch != "fix-login" || failed.FinishedAt == nil {
+		t.Errorf("Unexpected failed build: %+v", failed)
+	}
+	if skipped := builds[2]; skipped.Status != StatusCanceled {
+		t.Errorf("Expected not_run workflows to count as canceled, got %+v", skipped)
+	}
+}
+
+func TestProvidersFromEnv(t *testing.T) {
+	t.Setenv(JenkinsURLEnv, "https://jenkins.example.com")
+	t.Setenv(JenkinsJobEnv, "")
+	t.Setenv(CircleCIProjectEnv, "gh/org/repo")
+
+	providers := ProvidersFromEnv()
+	if len(providers) != 1 || providers[0].Name() != "circleci" {
+		t.Errorf("Expected only CircleCI without a Jenkins job, got %v", providers)
+	}
+}
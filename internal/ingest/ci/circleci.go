@@ -0,0 +1,190 @@
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewCircleCI when arguments are empty
+const (
+	CircleCIProjectEnv = "CIRCLECI_PROJECT" // Project slug, e.g. "gh/org/repo"
+	CircleCITokenEnv   = "CIRCLECI_TOKEN"
+
+	// CircleCIBaseURL is the CircleCI API v2 root
+	CircleCIBaseURL = "https://circleci.com/api/v2"
+)
+
+// CircleCI lists the workflows of a CircleCI project; each workflow run counts as one build
+type CircleCI struct {
+	BaseURL string
+	Project string
+
+	token      string
+	httpClient *http.Client
+}
+
+// NewCircleCI creates a CircleCI provider; empty arguments are read from CIRCLECI_PROJECT and CIRCLECI_TOKEN
+func NewCircleCI(project, token string) *CircleCI {
+	if project == "" {
+		project = os.Getenv(CircleCIProjectEnv)
+	}
+	if token == "" {
+		token = os.Getenv(CircleCITokenEnv)
+	}
+	return &CircleCI{
+		BaseURL:    CircleCIBaseURL,
+		Project:    strings.Trim(project, "/"),
+		token:      token,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// Name returns the provider name
+func (c *CircleCI) Name() string {
+	return "circleci"
+}
+
+// circleCIPipeline is a pipeline as returned by the API
+type circleCIPipeline struct {
+	ID        string    `json:"id"`
+	Number    int       `json:"number"`
+	CreatedAt time.Time `json:"created_at"`
+	VCS       struct {
+		Revision string `json:"revision"`
+		Branch   string `json:"branch"`
+	} `json:"vcs"`
+}
+
+// circleCIWorkflow is a workflow as returned by the API
+type circleCIWorkflow struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+}
+
+// ListBuilds returns the workflows of pipelines created at or after since
+// Pipelines are listed newest first, so paging stops at the first pipeline older than since
+func (c *CircleCI) ListBuilds(ctx context.Context, since time.Time) ([]Build, error) {
+	if c.Project == "" {
+		return nil, fmt.Errorf("circleci is not configured (set %s)", CircleCIProjectEnv)
+	}
+
+	var builds []Build
+	pageToken := ""
+	for {
+		query := url.Values{}
+		if pageToken != "" {
+			query.Set("page-token", pageToken)
+		}
+		var page struct {
+			Items         []circleCIPipeline `json:"items"`
+			NextPageToken string             `json:"next_page_token"`
+		}
+		if err := c.get(ctx, "/project/"+c.Project+"/pipeline", query, &page); err != nil {
+			return nil, err
+		}
+
+		for _, pipeline := range page.Items {
+			if pipeline.CreatedAt.Before(since) {
+				return builds, nil
+			}
+			workflows, err := c.listWorkflows(ctx, pipeline.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, workflow := range workflows {
+				builds = append(builds, Build{
+					Provider:    c.Name(),
+					Name:        workflow.Name,
+					Number:      pipeline.Number,
+					Status:      circleCIStatus(workflow.Status),
+					CommitSHA:   pipeline.VCS.Revision,
+					Branch:      pipeline.VCS.Branch,
+					PullRequest: pullRequestNumber(pipeline.VCS.Branch),
+					URL:         "https://app.circleci.com/pipelines/workflows/" + workflow.ID,
+					StartedAt:   workflow.CreatedAt,
+					FinishedAt:  workflow.StoppedAt,
+				})
+			}
+		}
+
+		if page.NextPageToken == "" {
+			return builds, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// listWorkflows fetches all workflows of a pipeline
+func (c *CircleCI) listWorkflows(ctx context.Context, pipelineID string) ([]circleCIWorkflow, error) {
+	var workflows []circleCIWorkflow
+	query := url.Values{}
+	for {
+		var page struct {
+			Items         []circleCIWorkflow `json:"items"`
+			NextPageToken string             `json:"next_page_token"`
+		}
+		if err := c.get(ctx, "/pipeline/"+url.PathEscape(pipelineID)+"/workflow", query, &page); err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, page.Items...)
+		if page.NextPageToken == "" {
+			return workflows, nil
+		}
+		query.Set("page-token", page.NextPageToken)
+	}
+}
+
+// get performs an authenticated API request and decodes the JSON response into out
+func (c *CircleCI) get(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := c.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create circleci request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Circle-Token", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("circleci request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("circleci request %s failed: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode circleci response: %w", err)
+	}
+	return nil
+}
+
+// circleCIStatus maps a CircleCI workflow status onto a normalized status
+func circleCIStatus(status string) string {
+	switch status {
+	case "success":
+		return StatusSuccess
+	case "failed", "error", "failing", "unauthorized":
+		return StatusFailed
+	case "canceled", "not_run":
+		return StatusCanceled
+	default: // running, on_hold
+		return StatusRunning
+	}
+}
@@ -0,0 +1,212 @@
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewJenkins when arguments are empty
+const (
+	JenkinsURLEnv   = "JENKINS_URL"
+	JenkinsJobEnv   = "JENKINS_JOB" // Job path, e.g. "team/service"; may be a multibranch pipeline
+	JenkinsUserEnv  = "JENKINS_USER"
+	JenkinsTokenEnv = "JENKINS_API_TOKEN"
+)
+
+// jenkinsBuildTree selects the build fields read from the JSON API, newest 100 builds per job
+const jenkinsBuildTree = "builds[number,result,building,timestamp,duration,url," +
+	"actions[lastBuiltRevision[SHA1,branch[name]]]]{0,100}"
+
+// Jenkins lists the builds of a Jenkins job
+// Multibranch pipelines are expanded into one job per branch and pull request
+type Jenkins struct {
+	BaseURL string
+	Job     string
+
+	user       string
+	token      string
+	httpClient *http.Client
+}
+
+// NewJenkins creates a Jenkins provider; empty arguments are read from JENKINS_URL, JENKINS_JOB,
+// JENKINS_USER and JENKINS_API_TOKEN
+func NewJenkins(baseURL, job, user, token string) *Jenkins {
+	if baseURL == "" {
+		baseURL = os.Getenv(JenkinsURLEnv)
+	}
+	if job == "" {
+		job = os.Getenv(JenkinsJobEnv)
+	}
+	if user == "" {
+		user = os.Getenv(JenkinsUserEnv)
+	}
+	if token == "" {
+		token = os.Getenv(JenkinsTokenEnv)
+	}
+	return &Jenkins{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Job:        strings.Trim(job, "/"),
+		user:       user,
+		token:      token,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// Name returns the provider name
+func (j *Jenkins) Name() string {
+	return "jenkins"
+}
+
+// jenkinsJob is a job as returned by the JSON API
+type jenkinsJob struct {
+	Jobs []struct {
+		Name string `json:"name"`
+	} `json:"jobs"`
+	Builds []struct {
+		Number    int     `json:"number"`
+		Result    *string `json:"result"`
+		Building  bool    `json:"building"`
+		Timestamp int64   `json:"timestamp"` // Milliseconds since the epoch
+		Duration  int64   `json:"duration"`  // Milliseconds
+		URL       string  `json:"url"`
+		Actions   []struct {
+			LastBuiltRevision *struct {
+				SHA1   string `json:"SHA1"`
+				Branch []struct {
+					Name string `json:"name"`
+				} `json:"branch"`
+			} `json:"lastBuiltRevision"`
+		} `json:"actions"`
+	} `json:"builds"`
+}
+
+// ListBuilds returns the job's builds started at or after since
+func (j *Jenkins) ListBuilds(ctx context.Context, since time.Time) ([]Build, error) {
+	if j.BaseURL == "" || j.Job == "" {
+		return nil, fmt.Errorf("jenkins is not configured (set %s and %s)", JenkinsURLEnv, JenkinsJobEnv)
+	}
+
+	jobPath := ""
+	for _, segment := range strings.Split(j.Job, "/") {
+		jobPath += "/job/" + url.PathEscape(segment)
+	}
+
+	var job jenkinsJob
+	if err := j.get(ctx, jobPath, "jobs[name],"+jenkinsBuildTree, &job); err != nil {
+		return nil, err
+	}
+
+	builds := j.parseBuilds(job, j.Job, "", since)
+
+	// Multibranch pipelines hold one child job per branch or pull request
+	for _, child := range job.Jobs {
+		var branchJob jenkinsJob
+		if err := j.get(ctx, jobPath+"/job/"+url.PathEscape(child.Name), jenkinsBuildTree, &branchJob); err != nil {
+			return nil, err
+		}
+		// Branch job names are the branch name with "/" escaped
+		branch, err := url.PathUnescape(child.Name)
+		if err != nil {
+			branch = child.Name
+		}
+		builds = append(builds, j.parseBuilds(branchJob, j.Job+"/"+child.Name, branch, since)...)
+	}
+
+	return builds, nil
+}
+
+// parseBuilds converts a job's builds; branch is the multibranch branch or pull request name, if any
+func (j *Jenkins) parseBuilds(job jenkinsJob, name, branch string, since time.Time) []Build {
+	builds := make([]Build, 0, len(job.Builds))
+	for _, raw := range job.Builds {
+		started := time.UnixMilli(raw.Timestamp).UTC()
+		if started.Before(since) {
+			continue
+		}
+
+		build := Build{
+			Provider:    j.Name(),
+			Name:        name,
+			Number:      raw.Number,
+			Status:      jenkinsStatus(raw.Result, raw.Building),
+			Branch:      branch,
+			PullRequest: pullRequestNumber(branch),
+			URL:         raw.URL,
+			StartedAt:   started,
+		}
+		if !raw.Building {
+			finished := started.Add(time.Duration(raw.Duration) * time.Millisecond)
+			build.FinishedAt = &finished
+		}
+		for _, action := range raw.Actions {
+			if action.LastBuiltRevision == nil {
+				continue
+			}
+			build.CommitSHA = action.LastBuiltRevision.SHA1
+			if build.Branch == "" && len(action.LastBuiltRevision.Branch) > 0 {
+				build.Branch = trimRemoteBranch(action.LastBuiltRevision.Branch[0].Name)
+			}
+		}
+		builds = append(builds, build)
+	}
+	return builds
+}
+
+// get performs an authenticated request to a job's JSON API
+func (j *Jenkins) get(ctx context.Context, jobPath, tree string, out any) error {
+	endpoint := j.BaseURL + jobPath + "/api/json?tree=" + url.QueryEscape(tree)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create jenkins request: %w", err)
+	}
+	if j.user != "" {
+		req.SetBasicAuth(j.user, j.token)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("jenkins request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("jenkins request %s failed: %s: %s", jobPath, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode jenkins response: %w", err)
+	}
+	return nil
+}
+
+// jenkinsStatus maps a Jenkins build result onto a normalized status
+// Unstable builds (failing tests) count as failed
+func jenkinsStatus(result *string, building bool) string {
+	if building || result == nil {
+		return StatusRunning
+	}
+	switch *result {
+	case "SUCCESS":
+		return StatusSuccess
+	case "ABORTED", "NOT_BUILT":
+		return StatusCanceled
+	default:
+		return StatusFailed
+	}
+}
+
+// trimRemoteBranch strips the remote prefix of a git plugin branch name ("origin/main" -> "main")
+func trimRemoteBranch(name string) string {
+	name = strings.TrimPrefix(name, "refs/remotes/")
+	if i := strings.Index(name, "/"); i >= 0 && !strings.HasPrefix(name, "refs/") {
+		return name[i+1:]
+	}
+	return name
+}
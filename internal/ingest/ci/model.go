@@ -0,0 +1,25 @@
+package ci
+
+import "time"
+
+// Build is one CI run of a commit as reported by a provider
+type Build struct {
+	Provider    string     `json:"provider"`
+	Name        string     `json:"name"` // Job or workflow name
+	Number      int        `json:"number"`
+	Status      string     `json:"status"` // One of the Status constants
+	CommitSHA   string     `json:"commit_sha,omitempty"`
+	Branch      string     `json:"branch,omitempty"`
+	PullRequest int        `json:"pull_request,omitempty"` // Number of the pull/merge request built, when known
+	URL         string     `json:"url,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// Normalized build outcomes; provider-specific results map onto these
+const (
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	StatusCanceled = "canceled"
+	StatusRunning  = "running"
+)
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/go-git/go-git/v6/plumbing/transport/ssh"
+)
+
+// tokenUsername is the basic-auth username GitHub expects alongside access tokens
+// Works for personal access tokens and GitHub App installation tokens
+const tokenUsername = "x-access-token"
+
+// AuthOptions holds credentials for cloning and fetching private repositories
+// The zero value clones anonymously
+type AuthOptions struct {
+	// HTTPS basic authentication
+	Username string
+	Password string
+
+	// Token is an HTTPS access token (personal access token or GitHub App installation token)
+	// Used as the basic-auth password; Username defaults to "x-access-token"
+	Token string
+
+	// SSHKeyPath is a private key file used for SSH URLs
+	// If empty, SSH URLs fall back to the SSH agent
+	SSHKeyPath       string
+	SSHKeyPassphrase string
+
+	// SSHUser is the SSH login user (default "git")
+	SSHUser string
+
+	// Host restricts HTTPS credentials to a single hostname so tokens aren't sent
+	// to other servers, e.g. submodules hosted elsewhere (empty = any host)
+	Host string
+}
+
+// IsZero reports whether no credentials are configured
+func (a AuthOptions) IsZero() bool {
+	return a == AuthOptions{}
+}
+
+// Method returns the go-git auth method for the given remote URL, or nil for anonymous access
+// SSH credentials apply to ssh:// and scp-style URLs; token and basic auth apply to HTTP(S) URLs
+func (a AuthOptions) Method(url string) (transport.AuthMethod, error) {
+	if isSSHURL(url) {
+		user := a.SSHUser
+		if user == "" {
+			user = "git"
+		}
+
+		if a.SSHKeyPath == "" {
+			// nil lets go-git use the SSH agent
+			return nil, nil
+		}
+
+		keys, err := ssh.NewPublicKeysFromFile(user, a.SSHKeyPath, a.SSHKeyPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key %s: %w", a.SSHKeyPath, err)
+		}
+		return keys, nil
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		// Local paths and file:// URLs need no credentials
+		return nil, nil
+	}
+
+	if a.Host != "" {
+		parsed, err := neturl.Parse(url)
+		if err != nil || !strings.EqualFold(parsed.Hostname(), a.Host) {
+			return nil, nil
+		}
+	}
+
+	if a.Token != "" {
+		username := a.Username
+		if username == "" {
+			username = tokenUsername
+		}
+		return &http.BasicAuth{Username: username, Password: a.Token}, nil
+	}
+
+	if a.Username != "" || a.Password != "" {
+		return &http.BasicAuth{Username: a.Username, Password: a.Password}, nil
+	}
+
+	return nil, nil
+}
+
+// isSSHURL reports whether url uses the ssh:// scheme or scp-like syntax (user@host:path)
+func isSSHURL(url string) bool {
+	if strings.HasPrefix(url, "ssh://") || strings.HasPrefix(url, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(url, "://") {
+		return false
+	}
+
+	// scp-like: a colon before any slash, with a host part (single letters are Windows drives)
+	colon := strings.Index(url, ":")
+	slash := strings.Index(url, "/")
+	return colon > 1 && (slash < 0 || colon < slash)
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+func TestAuthOptions_Method(t *testing.T) {
+	// Token defaults to the x-access-token user
+	method, err := AuthOptions{Token: "secret"}.Method("https://github.com/acme/private.git")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	basic, ok := method.(*http.BasicAuth)
+	if !ok || basic.Username != "x-access-token" || basic.Password != "secret" {
+		t.Errorf("Expected token basic auth, got %#v", method)
+	}
+
+	// Explicit basic auth
+	method, _ = AuthOptions{Username: "alice", Password: "pw"}.Method("https://git.example.com/repo.git")
+	if basic, ok := method.(*http.BasicAuth); !ok || basic.Username != "alice" {
+		t.Errorf("Expected basic auth for alice, got %#v", method)
+	}
+
+	// Host restriction keeps credentials away from other servers
+	method, _ = AuthOptions{Token: "secret", Host: "github.com"}.Method("https://gitlab.com/acme/lib.git")
+	if method != nil {
+		t.Errorf("Expected no credentials for other host, got %#v", method)
+	}
+
+	// Anonymous and local sources
+	if method, _ := (AuthOptions{}).Method("https://github.com/acme/public.git"); method != nil {
+		t.Errorf("Expected anonymous access, got %#v", method)
+	}
+	if method, _ := (AuthOptions{Token: "secret"}).Method("/srv/repos/app"); method != nil {
+		t.Errorf("Expected no credentials for local path, got %#v", method)
+	}
+
+	// SSH without a key falls back to the agent; a missing key file is an error
+	if method, err := (AuthOptions{}).Method("git@github.com:acme/private.git"); method != nil || err != nil {
+		t.Errorf("Expected agent fallback, got %#v, %v", method, err)
+	}
+	missingKey := filepath.Join(t.TempDir(), "id_ed25519")
+	if _, err := (AuthOptions{SSHKeyPath: missingKey}).Method("ssh://git@github.com/acme/private.git"); err == nil {
+		t.Error("Expected error for missing SSH key")
+	}
+}
+
+func TestIsSSHURL(t *testing.T) {
+	tests := map[string]bool{
+		"git@github.com:acme/app.git":         true,
+		"ssh://git@github.com/acme/app.git":   true,
+		"https://github.com/acme/app.git":     false,
+		"/srv/repos/app":                      false,
+		"./relative/repo":                     false,
+		`C:\repos\app`:                        false,
+		"file:///srv/repos/app":               false,
+		"github.com:acme/app.git":             true,
+		"https://user:pw@github.com/acme/app": false,
+	}
+
+	for url, expected := range tests {
+		if got := isSSHURL(url); got != expected {
+			t.Errorf("isSSHURL(%q) = %v, expected %v", url, got, expected)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// BinaryPolicy controls how binary and Git LFS files appear in parsed diffs
+type BinaryPolicy string
+
+const (
+	// BinarySizeOnly keeps binary and LFS files with their size but no line counts or patch (default)
+	BinarySizeOnly BinaryPolicy = "size_only"
+
+	// BinarySkip drops binary and LFS files from diffs and stats
+	BinarySkip BinaryPolicy = "skip"
+
+	// BinaryPointer is BinarySizeOnly plus LFS pointer metadata (object ID, pointer patch)
+	BinaryPointer BinaryPolicy = "pointer"
+)
+
+// lfsPointerMaxSize is the largest blob git-lfs treats as a pointer file
+const lfsPointerMaxSize = 1024
+
+// lfsSpecPrefixes are the version lines a Git LFS pointer file may start with
+var lfsSpecPrefixes = []string{
+	"version https://git-lfs.github.com/spec/v1",
+	"version https://hawser.github.com/spec/v1",
+}
+
+// LFSPointer is the metadata stored in a Git LFS pointer file
+type LFSPointer struct {
+	OID  string `json:"oid"`  // Object ID including the hash algorithm, e.g. "sha256:4d7a..."
+	Size int64  `json:"size"` // Size of the real file content in bytes
+}
+
+// ParseLFSPointer parses the content of a Git LFS pointer file
+// Returns false if the content is not a pointer
+func ParseLFSPointer(content string) (LFSPointer, bool) {
+	if len(content) > lfsPointerMaxSize {
+		return LFSPointer{}, false
+	}
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	isPointer := false
+	for _, prefix := range lfsSpecPrefixes {
+		if lines[0] == prefix {
+			isPointer = true
+			break
+		}
+	}
+	if !isPointer {
+		return LFSPointer{}, false
+	}
+
+	pointer := LFSPointer{Size: -1}
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return LFSPointer{}, false
+		}
+		switch key {
+		case "oid":
+			pointer.OID = value
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return LFSPointer{}, false
+			}
+			pointer.Size = size
+		}
+	}
+
+	if pointer.OID == "" || pointer.Size < 0 {
+		return LFSPointer{}, false
+	}
+	return pointer, true
+}
+
+// Validate checks that the policy is known
+func (p BinaryPolicy) Validate() error {
+	switch p {
+	case "", BinarySizeOnly, BinarySkip, BinaryPointer:
+		return nil
+	default:
+		return fmt.Errorf("invalid binary policy %q", p)
+	}
+}
+
+// apply reshapes a commit's binary and LFS diffs according to the policy and recomputes its stats
+func (p BinaryPolicy) apply(commit *Commit) {
+	kept := commit.Diffs[:0]
+	for _, diff := range commit.Diffs {
+		if !diff.IsBinary && !diff.IsLFS {
+			kept = append(kept, diff)
+			continue
+		}
+
+		switch p {
+		case BinarySkip:
+			continue
+		case BinaryPointer:
+			if !diff.IsLFS {
+				diff.Patch = ""
+			}
+		default:
+			diff.Patch = ""
+			diff.LFS = nil
+		}
+
+		kept = append(kept, diff)
+	}
+
+	commit.Diffs = kept
+	commit.Stats = calculateStats(kept)
+	commit.Languages = commitLanguages(kept)
+}
+
+// describeBlob records the size of a diff's file and detects Git LFS pointers
+// LFS files report the size of the real content and no line counts
+func describeBlob(diff *Diff, file *object.File) {
+	if file == nil {
+		return
+	}
+
+	diff.SizeBytes = file.Size
+	if diff.IsBinary || file.Size > lfsPointerMaxSize {
+		return
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return
+	}
+	pointer, ok := ParseLFSPointer(content)
+	if !ok {
+		return
+	}
+
+	diff.IsLFS = true
+	diff.LFS = &pointer
+	diff.SizeBytes = pointer.Size
+	diff.Additions = 0
+	diff.Deletions = 0
+}
+
+// treeFile looks up a file in a tree, returning nil if it is missing or not a regular file
+func treeFile(tree *object.Tree, path string) *object.File {
+	if tree == nil {
+		return nil
+	}
+	file, err := tree.File(path)
+	if err != nil {
+		return nil
+	}
+	return file
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLFSPointer = "version https://git-lfs.github.com/spec/v1\n" +
+	"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+	"size 12345\n"
+
+func TestParseLFSPointer(t *testing.T) {
+	pointer, ok := ParseLFSPointer(testLFSPointer)
+	if !ok {
+		t.Fatal("Expected valid LFS pointer")
+	}
+	if pointer.Size != 12345 {
+		t.Errorf("Expected size 12345, got %d", pointer.Size)
+	}
+	if !strings.HasPrefix(pointer.OID, "sha256:4d7a") {
+		t.Errorf("Unexpected OID %s", pointer.OID)
+	}
+
+	invalid := []string{
+		"package main\n",
+		"version https://git-lfs.github.com/spec/v1\nsize 10\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize -1\n",
+		testLFSPointer + strings.Repeat("x", lfsPointerMaxSize),
+	}
+	for _, content := range invalid {
+		if _, ok := ParseLFSPointer(content); ok {
+			t.Errorf("Expected %q not to parse as a pointer", content)
+		}
+	}
+}
+
+func TestParseCommitsWithOptions_BinaryPolicy(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	commitTestFile(t, repo, dir, "main.go", "package main\n", "Initial commit", baseTime)
+	commitTestFile(t, repo, dir, "logo.png", "\x89PNG\x00\x01\x02\x03", "Add logo", baseTime.Add(time.Minute))
+	commitTestFile(t, repo, dir, "model.bin", testLFSPointer, "Add model", baseTime.Add(2*time.Minute))
+
+	parse := func(policy BinaryPolicy) map[string]Commit {
+		t.Helper()
+		opts := DefaultParseOptions()
+		opts.IncludePatch = true
+		opts.BinaryPolicy = policy
+		commits, err := ParseCommitsWithOptions(ctx, repo, opts)
+		if err != nil {
+			t.Fatalf("Failed to parse commits with policy %q: %v", policy, err)
+		}
+		bySubject := make(map[string]Commit)
+		for _, commit := range commits {
+			bySubject[commit.MessageSubject] = commit
+		}
+		return bySubject
+	}
+
+	// Default policy records sizes without line counts or pointer metadata
+	commits := parse("")
+	logo := commits["Add logo"].Diffs[0]
+	if !logo.IsBinary || logo.SizeBytes != 8 || logo.Additions != 0 {
+		t.Errorf("Unexpected binary diff: %+v", logo)
+	}
+	model := commits["Add model"]
+	if len(model.Diffs) != 1 {
+		t.Fatalf("Expected 1 diff for LFS commit, got %d", len(model.Diffs))
+	}
+	if !model.Diffs[0].IsLFS || model.Diffs[0].SizeBytes != 12345 || model.Diffs[0].LFS != nil || model.Diffs[0].Patch != "" {
+		t.Errorf("Unexpected size-only LFS diff: %+v", model.Diffs[0])
+	}
+	if model.Stats.Additions != 0 {
+		t.Errorf("Expected LFS pointer lines not to count as additions, got %d", model.Stats.Additions)
+	}
+	if main := commits["Initial commit"].Diffs[0]; main.SizeBytes != int64(len("package main\n")) || main.Additions == 0 {
+		t.Errorf("Unexpected text diff: %+v", main)
+	}
+
+	// Pointer policy keeps LFS metadata
+	commits = parse(BinaryPointer)
+	pointer := commits["Add model"].Diffs[0]
+	if pointer.LFS == nil || pointer.LFS.Size != 12345 || pointer.Patch == "" {
+		t.Errorf("Expected LFS pointer metadata, got %+v", pointer)
+	}
+
+	// Skip policy drops binary and LFS files but keeps the commits
+	commits = parse(BinarySkip)
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(commits))
+	}
+	if diffs := commits["Add logo"].Diffs; len(diffs) != 0 {
+		t.Errorf("Expected binary diff to be skipped, got %+v", diffs)
+	}
+	if stats := commits["Add model"].Stats; stats.FilesChanged != 0 {
+		t.Errorf("Expected LFS file to be excluded from stats, got %+v", stats)
+	}
+
+	opts := DefaultParseOptions()
+	opts.BinaryPolicy = "compress"
+	if _, err := ParseCommitsWithOptions(ctx, repo, opts); err == nil {
+		t.Error("Expected error for unknown binary policy")
+	}
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// branchSet is a bitset of indexes into a branch slice
+type branchSet []uint64
+
+// newBranchSet creates an empty set able to hold n branches
+func newBranchSet(n int) branchSet {
+	return make(branchSet, (n+63)/64)
+}
+
+// add inserts branch index i
+func (s branchSet) add(i int) {
+	s[i/64] |= 1 << (uint(i) % 64)
+}
+
+// union adds every branch in other to s
+func (s branchSet) union(other branchSet) {
+	for i := range s {
+		s[i] |= other[i]
+	}
+}
+
+// first returns the lowest branch index in the set, or -1 if it is empty
+func (s branchSet) first() int {
+	for i, word := range s {
+		if word != 0 {
+			return i*64 + bits.TrailingZeros64(word)
+		}
+	}
+	return -1
+}
+
+// indexes returns every branch index in the set in ascending order
+func (s branchSet) indexes() []int {
+	indexes := make([]int, 0)
+	for i, word := range s {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			indexes = append(indexes, i*64+bit)
+			word &^= 1 << uint(bit)
+		}
+	}
+	return indexes
+}
+
+// branchGraph records which branches contain each commit reachable from a set of branch tips
+type branchGraph struct {
+	commits map[plumbing.Hash]*object.Commit
+	reach   map[plumbing.Hash]branchSet
+}
+
+// buildBranchGraph computes branch membership for all reachable commits in a single traversal
+// Every commit is read once; membership flows from children to parents in topological order,
+// replacing one full log walk per branch. Parents missing from a shallow clone are skipped
+func buildBranchGraph(ctx context.Context, repo *git.Repository, branches []Branch) (*branchGraph, error) {
+	graph := &branchGraph{
+		commits: make(map[plumbing.Hash]*object.Commit),
+		reach:   make(map[plumbing.Hash]branchSet),
+	}
+
+	// Discover reachable commits and count each commit's children within the graph
+	children := make(map[plumbing.Hash]int)
+	stack := make([]plumbing.Hash, 0, len(branches))
+	for i, branch := range branches {
+		hash := plumbing.NewHash(branch.Hash)
+		if _, ok := graph.reach[hash]; !ok {
+			graph.reach[hash] = newBranchSet(len(branches))
+			stack = append(stack, hash)
+		}
+		graph.reach[hash].add(i)
+	}
+
+	for len(stack) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, seen := graph.commits[hash]; seen {
+			continue
+		}
+
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				delete(graph.reach, hash)
+				continue
+			}
+			return nil, fmt.Errorf("failed to load commit %s: %w", hash, err)
+		}
+		graph.commits[hash] = commit
+
+		for _, parent := range commit.ParentHashes {
+			children[parent]++
+			if _, ok := graph.reach[parent]; !ok {
+				graph.reach[parent] = newBranchSet(len(branches))
+			}
+			stack = append(stack, parent)
+		}
+	}
+
+	// Propagate membership from children to parents once all children are done
+	queue := make([]plumbing.Hash, 0)
+	for hash := range graph.commits {
+		if children[hash] == 0 {
+			queue = append(queue, hash)
+		}
+	}
+
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+
+		commit := graph.commits[hash]
+		for _, parent := range commit.ParentHashes {
+			if _, ok := graph.commits[parent]; !ok {
+				continue
+			}
+			graph.reach[parent].union(graph.reach[hash])
+			children[parent]--
+			if children[parent] == 0 {
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// associateBranches points each commit at the first branch (in slice order) that contains it
+func associateBranches(ctx context.Context, repo *git.Repository, branches []Branch, commits []Commit) error {
+	graph, err := buildBranchGraph(ctx, repo, branches)
+	if err != nil {
+		return err
+	}
+
+	for i := range commits {
+		set, ok := graph.reach[plumbing.NewHash(commits[i].Hash)]
+		if !ok {
+			continue
+		}
+		if index := set.first(); index >= 0 {
+			commits[i].Branch = &branches[index]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// createBranchedRepository builds a linear history of n commits with a branch ref at every step-th commit
+func createBranchedRepository(t testing.TB, n, step int) *git.Repository {
+	t.Helper()
+
+	_, repo := createHistoryRepository(t, n)
+
+	iter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get log: %v", err)
+	}
+	i := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		if i%step == 0 {
+			ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(fmt.Sprintf("branch-%04d", i)), c.Hash)
+			if err := repo.Storer.SetReference(ref); err != nil {
+				return err
+			}
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create branches: %v", err)
+	}
+
+	return repo
+}
+
+// perBranchMembership is the reference implementation: one full log walk per branch
+func perBranchMembership(t testing.TB, repo *git.Repository, branches []Branch) map[string][]string {
+	t.Helper()
+
+	membership := make(map[string][]string)
+	for _, branch := range branches {
+		iter, err := repo.Log(&git.LogOptions{From: plumbing.NewHash(branch.Hash)})
+		if err != nil {
+			t.Fatalf("Failed to get log for %s: %v", branch.Name, err)
+		}
+		_ = iter.ForEach(func(c *object.Commit) error {
+			membership[c.Hash.String()] = append(membership[c.Hash.String()], branch.Name)
+			return nil
+		})
+	}
+	for hash := range membership {
+		sort.Strings(membership[hash])
+	}
+	return membership
+}
+
+func TestBuildBranchGraph_MatchesPerBranchWalk(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	base := commitTestFile(t, repo, dir, "a.go", "package a\n", "Add a", baseTime)
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatalf("Failed to create feature branch: %v", err)
+	}
+	feature := commitTestFile(t, repo, dir, "feature.go", "package a\n", "Add feature", baseTime.Add(time.Hour))
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatalf("Failed to checkout master: %v", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("hotfix"), Create: true}); err != nil {
+		t.Fatalf("Failed to create hotfix branch: %v", err)
+	}
+	commitTestFile(t, repo, dir, "fix.go", "package a\n", "Fix", baseTime.Add(2*time.Hour))
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatalf("Failed to checkout master: %v", err)
+	}
+	main := commitTestFile(t, repo, dir, "b.go", "package a\n", "Add b", baseTime.Add(3*time.Hour))
+
+	// Merge feature into master
+	sig := &object.Signature{Name: "Alice", Email: "alice@example.com", When: baseTime.Add(4 * time.Hour)}
+	_, err = wt.Commit("Merge feature", &git.CommitOptions{
+		Author:            sig,
+		Committer:         sig,
+		Parents:           []plumbing.Hash{plumbing.NewHash(main), plumbing.NewHash(feature)},
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create merge commit: %v", err)
+	}
+
+	branches, err := ParseBranches(repo)
+	if err != nil {
+		t.Fatalf("Failed to parse branches: %v", err)
+	}
+
+	graph, err := buildBranchGraph(ctx, repo, branches)
+	if err != nil {
+		t.Fatalf("Failed to build branch graph: %v", err)
+	}
+
+	expected := perBranchMembership(t, repo, branches)
+	if len(graph.commits) != len(expected) {
+		t.Fatalf("Expected %d reachable commits, got %d", len(expected), len(graph.commits))
+	}
+	for hash, names := range expected {
+		got := make([]string, 0)
+		for _, index := range graph.reach[plumbing.NewHash(hash)].indexes() {
+			got = append(got, branches[index].Name)
+		}
+		sort.Strings(got)
+		if fmt.Sprint(got) != fmt.Sprint(names) {
+			t.Errorf("Commit %s: expected branches %v, got %v", hash[:8], names, got)
+		}
+	}
+
+	if n := len(graph.reach[plumbing.NewHash(base)].indexes()); n != 3 {
+		t.Errorf("Expected root commit on 3 branches, got %d", n)
+	}
+}
+
+func TestAssociateBranches_PrefersFirstBranch(t *testing.T) {
+	ctx := context.Background()
+	repo := createBranchedRepository(t, 12, 4)
+
+	commits, err := ParseCommits(ctx, repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	branches, err := ParseBranches(repo)
+	if err != nil {
+		t.Fatalf("Failed to parse branches: %v", err)
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+
+	if err := associateBranches(ctx, repo, branches, commits); err != nil {
+		t.Fatalf("Failed to associate branches: %v", err)
+	}
+
+	// branch-0000 is at HEAD and sorts first, so it claims every commit
+	for _, commit := range commits {
+		if commit.Branch == nil || commit.Branch.Name != "branch-0000" {
+			t.Errorf("Expected commit %s on branch-0000, got %v", commit.ShortHash, commit.Branch)
+		}
+	}
+}
+
+func BenchmarkBranchAssociation(b *testing.B) {
+	ctx := context.Background()
+
+	for _, branchCount := range []int{10, 100, 300} {
+		repo := createBranchedRepository(b, 300, 300/branchCount)
+		branches, err := ParseBranches(repo)
+		if err != nil {
+			b.Fatalf("Failed to parse branches: %v", err)
+		}
+
+		b.Run(fmt.Sprintf("branches=%d/single-pass", len(branches)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := buildBranchGraph(ctx, repo, branches); err != nil {
+					b.Fatalf("Failed to build branch graph: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("branches=%d/per-branch", len(branches)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				perBranchMembership(b, repo, branches)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// CacheDirEnv is the environment variable that overrides the default clone cache directory
+const CacheDirEnv = "THUNK_CACHE_DIR"
+
+// mirrorRefSpec maps every remote ref onto the same local ref so cached clones track the remote exactly
+const mirrorRefSpec = "+refs/*:refs/*"
+
+// EvictionPolicy controls which cached clones are removed by EvictCache
+type EvictionPolicy struct {
+	// MaxAge removes clones that have not been used for longer than this (0 = no age limit)
+	MaxAge time.Duration
+
+	// MaxEntries keeps at most this many clones, evicting least recently used first (0 = unlimited)
+	MaxEntries int
+}
+
+// DefaultEvictionPolicy returns sensible defaults for clone cache eviction
+func DefaultEvictionPolicy() EvictionPolicy {
+	return EvictionPolicy{
+		MaxAge:     30 * 24 * time.Hour, // 30 days
+		MaxEntries: 50,
+	}
+}
+
+// CacheEntry describes a cached clone on disk
+type CacheEntry struct {
+	Path     string    `json:"path"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// DefaultCacheDir returns the clone cache directory
+// Uses THUNK_CACHE_DIR if set, otherwise the user cache directory
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+
+	return filepath.Join(userCache, "thunk", "repos"), nil
+}
+
+// cachePath returns the directory used to cache a clone of the given URL
+func cachePath(url, cacheDir string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:])[:16])
+}
+
+// OpenOrClone opens a cached mirror clone of url from cacheDir, fetching only new refs,
+// or clones it into the cache if it is not present yet
+// A cached clone whose fetch fails, e.g. offline, is returned as it was last fetched
+func OpenOrClone(ctx context.Context, url, cacheDir string) (*git.Repository, error) {
+	return OpenOrCloneWithAuth(ctx, url, cacheDir, AuthOptions{})
+}
+
+// OpenOrCloneWithAuth is OpenOrClone for private repositories, using auth for both clone and fetch
+func OpenOrCloneWithAuth(ctx context.Context, url, cacheDir string, auth AuthOptions) (*git.Repository, error) {
+	method, err := auth.Method(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheDir == "" {
+		return nil, fmt.Errorf("cache directory is required")
+	}
+
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	path := cachePath(url, cacheDir)
+
+	repo, err := git.PlainOpen(path)
+	if err == nil {
+		if err := fetchCached(ctx, repo, method); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			// The clone is still usable when the remote can't be reached, e.g. offline, just stale
+			logging.FromContext(ctx).Warn("Failed to update cached clone, using it as cached", "path", path, "error", err)
+		}
+		touchCacheEntry(path)
+		return repo, nil
+	}
+
+	if !errors.Is(err, git.ErrRepositoryNotExists) {
+		// Corrupt cache entry: discard it and clone fresh
+		if err := os.RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("failed to remove corrupt cache entry: %w", err)
+		}
+	}
+
+	repo, err = git.PlainCloneContext(ctx, path, &git.CloneOptions{
+		URL:    url,
+		Auth:   method,
+		Mirror: true,
+	})
+	if err != nil {
+		// Don't leave a partial clone behind
+		_ = os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to clone into cache: %w", err)
+	}
+
+	return repo, nil
+}
+
+// fetchCached updates a cached mirror with any new refs from its origin
+func fetchCached(ctx context.Context, repo *git.Repository, auth transport.AuthMethod) error {
+	err := repo.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{mirrorRefSpec},
+		Auth:     auth,
+		Force:    true,
+		Prune:    true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch cached repository: %w", err)
+	}
+	return nil
+}
+
+// touchCacheEntry records a cache hit by bumping the entry's modification time
+func touchCacheEntry(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
+
+// ListCache returns all cached clones, most recently used first
+func ListCache(cacheDir string) ([]CacheEntry, error) {
+	dirEntries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []CacheEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	entries := make([]CacheEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, CacheEntry{
+			Path:     filepath.Join(cacheDir, dirEntry.Name()),
+			LastUsed: info.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+
+	return entries, nil
+}
+
+// EvictCache removes cached clones according to the eviction policy
+// Returns the paths of the evicted entries
+func EvictCache(cacheDir string, policy EvictionPolicy) ([]string, error) {
+	entries, err := ListCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	evicted := make([]string, 0)
+	cutoff := time.Now().Add(-policy.MaxAge)
+
+	for i, entry := range entries {
+		expired := policy.MaxAge > 0 && entry.LastUsed.Before(cutoff)
+		overflow := policy.MaxEntries > 0 && i >= policy.MaxEntries
+		if !expired && !overflow {
+			continue
+		}
+
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return evicted, fmt.Errorf("failed to evict %s: %w", entry.Path, err)
+		}
+		evicted = append(evicted, entry.Path)
+	}
+
+	return evicted, nil
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// createTestRepository initializes an on-disk repository for offline tests
+func createTestRepository(t testing.TB) (string, *git.Repository) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	return dir, repo
+}
+
+// commitTestFile writes a file into the worktree and commits it
+func commitTestFile(t testing.TB, repo *git.Repository, dir, name, content, message string, when time.Time) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	sig := &object.Signature{Name: "Alice", Email: "alice@example.com", When: when}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	return hash.String()
+}
+
+func TestOpenOrClone(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	sourceDir, source := createTestRepository(t)
+	commitTestFile(t, source, sourceDir, "main.go", "package main\n", "Initial commit", baseTime)
+
+	cacheDir := t.TempDir()
+
+	// First call clones into the cache
+	repo, err := OpenOrClone(ctx, sourceDir, cacheDir)
+	if err != nil {
+		t.Fatalf("Failed to clone into cache: %v", err)
+	}
+
+	commits, err := ParseCommits(ctx, repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("Expected 1 commit, got %d", len(commits))
+	}
+
+	// New upstream activity should be fetched on the next call
+	newHash := commitTestFile(t, source, sourceDir, "util.go", "package main\n", "Add util", baseTime.Add(time.Hour))
+
+	repo, err = OpenOrClone(ctx, sourceDir, cacheDir)
+	if err != nil {
+		t.Fatalf("Failed to reopen cached clone: %v", err)
+	}
+
+	commits, err = ParseCommits(ctx, repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits after fetch, got %d", len(commits))
+	}
+	if commits[0].Hash != newHash {
+		t.Errorf("Expected HEAD to be %s, got %s", newHash, commits[0].Hash)
+	}
+
+	entries, err := ListCache(cacheDir)
+	if err != nil {
+		t.Fatalf("Failed to list cache: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 cache entry, got %d", len(entries))
+	}
+}
+
+func TestOpenOrClone_FetchFailsUsesCache(t *testing.T) {
+	ctx := context.Background()
+	sourceDir, source := createTestRepository(t)
+	hash := commitTestFile(t, source, sourceDir, "main.go", "package main\n", "Initial commit", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	cacheDir := t.TempDir()
+	if _, err := OpenOrClone(ctx, sourceDir, cacheDir); err != nil {
+		t.Fatalf("Failed to clone into cache: %v", err)
+	}
+
+	// With the remote gone the cached clone is still served
+	if err := os.RemoveAll(sourceDir); err != nil {
+		t.Fatalf("Failed to remove source repository: %v", err)
+	}
+	repo, err := OpenOrClone(ctx, sourceDir, cacheDir)
+	if err != nil {
+		t.Fatalf("Expected the cached clone despite the failed fetch, got %v", err)
+	}
+	commits, err := ParseCommits(ctx, repo, 0, false)
+	if err != nil || len(commits) != 1 || commits[0].Hash != hash {
+		t.Errorf("Expected the cached commit, got %d (error %v)", len(commits), err)
+	}
+}
+
+func TestOpenOrClone_InvalidSource(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	_, err := OpenOrClone(context.Background(), filepath.Join(t.TempDir(), "missing"), cacheDir)
+	if err == nil {
+		t.Fatal("Expected error for missing source repository")
+	}
+
+	entries, err := ListCache(cacheDir)
+	if err != nil {
+		t.Fatalf("Failed to list cache: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected partial clone to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestEvictCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	now := time.Now()
+
+	ages := map[string]time.Duration{
+		"fresh":  time.Hour,
+		"recent": 48 * time.Hour,
+		"stale":  60 * 24 * time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(cacheDir, name)
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatalf("Failed to set entry time: %v", err)
+		}
+	}
+
+	// Age-based eviction removes only the stale entry
+	evicted, err := EvictCache(cacheDir, EvictionPolicy{MaxAge: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to evict: %v", err)
+	}
+	if len(evicted) != 1 || filepath.Base(evicted[0]) != "stale" {
+		t.Errorf("Expected only stale entry evicted, got %v", evicted)
+	}
+
+	// Size-based eviction keeps the most recently used entry
+	evicted, err = EvictCache(cacheDir, EvictionPolicy{MaxEntries: 1})
+	if err != nil {
+		t.Fatalf("Failed to evict: %v", err)
+	}
+	if len(evicted) != 1 || filepath.Base(evicted[0]) != "recent" {
+		t.Errorf("Expected recent entry evicted, got %v", evicted)
+	}
+
+	entries, _ := ListCache(cacheDir)
+	if len(entries) != 1 || filepath.Base(entries[0].Path) != "fresh" {
+		t.Errorf("Expected only fresh entry to remain, got %v", entries)
+	}
+}
+
+func TestDefaultCacheDir_EnvOverride(t *testing.T) {
+	t.Setenv(CacheDirEnv, "/tmp/thunk-cache-test")
+
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dir != "/tmp/thunk-cache-test" {
+		t.Errorf("Expected env override, got %s", dir)
+	}
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CommitCacheDirEnv is the environment variable that overrides the default parsed-commit cache directory
+const CommitCacheDirEnv = "THUNK_COMMIT_CACHE_DIR"
+
+// commitCacheVersion is bumped whenever the cached Commit encoding changes, invalidating old entries
+const commitCacheVersion = 2
+
+// CommitCache stores parsed commits on disk keyed by commit hash
+// Commits are immutable, so an entry never goes stale; entries are laid out
+// like git's loose objects (two-character fan-out directories) and written atomically
+type CommitCache struct {
+	dir string
+}
+
+// NewCommitCache opens (creating if needed) a parsed-commit cache rooted at dir
+func NewCommitCache(dir string) (*CommitCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("commit cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create commit cache directory: %w", err)
+	}
+	return &CommitCache{dir: dir}, nil
+}
+
+// DefaultCommitCacheDir returns the parsed-commit cache directory
+// Uses THUNK_COMMIT_CACHE_DIR if set, otherwise the user cache directory
+func DefaultCommitCacheDir() (string, error) {
+	if dir := os.Getenv(CommitCacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+
+	return filepath.Join(userCache, "thunk", "commits"), nil
+}
+
+// path returns the entry location for a commit hash and patch variant
+func (c *CommitCache) path(hash string, includePatch bool) string {
+	variant := "stats"
+	if includePatch {
+		variant = "patch"
+	}
+	name := fmt.Sprintf("%s.v%d.%s.json", hash[2:], commitCacheVersion, variant)
+	return filepath.Join(c.dir, hash[:2], name)
+}
+
+// Get returns the cached commit for hash, if present
+// A commit cached with patches also satisfies requests without them
+func (c *CommitCache) Get(hash string, includePatch bool) (*Commit, bool) {
+	if c == nil || len(hash) < 3 {
+		return nil, false
+	}
+
+	commit, ok := c.read(c.path(hash, includePatch))
+	if ok || includePatch {
+		return commit, ok
+	}
+
+	commit, ok = c.read(c.path(hash, true))
+	if !ok {
+		return nil, false
+	}
+	for i := range commit.Diffs {
+		commit.Diffs[i].Patch = ""
+	}
+	return commit, true
+}
+
+// read decodes a cache entry; unreadable or corrupt entries count as misses
+func (c *CommitCache) read(path string) (*Commit, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var commit Commit
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return nil, false
+	}
+	return &commit, true
+}
+
+// Put stores a parsed commit
+// The commit must be fully parsed; branch and tag associations are not cached
+func (c *CommitCache) Put(commit *Commit, includePatch bool) error {
+	if c == nil {
+		return nil
+	}
+	if len(commit.Hash) < 3 {
+		return fmt.Errorf("invalid commit hash %q", commit.Hash)
+	}
+	if !commit.DiffsLoaded() {
+		return fmt.Errorf("commit %s has unloaded diffs", commit.ShortHash)
+	}
+
+	entry := *commit
+	entry.Branch = nil
+	entry.Branches = nil
+	entry.Tags = nil
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode commit %s: %w", commit.ShortHash, err)
+	}
+
+	path := c.path(commit.Hash, includePatch)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create commit cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write commit cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write commit cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write commit cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write commit cache entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitCache(t *testing.T) {
+	cache, err := NewCommitCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	commit := &Commit{
+		Hash:      "4d7a214614ab2935c943f9e0ff69d22eadbb8f32",
+		ShortHash: "4d7a2146",
+		Message:   "Add store",
+		Diffs:     []Diff{{FilePath: "store.go", Additions: 3, Patch: "+package store\n", Language: "Go"}},
+		Tags:      []string{"v1.0.0"},
+	}
+
+	if _, ok := cache.Get(commit.Hash, false); ok {
+		t.Fatal("Expected miss on empty cache")
+	}
+	if err := cache.Put(commit, true); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+
+	cached, ok := cache.Get(commit.Hash, true)
+	if !ok {
+		t.Fatal("Expected cache hit")
+	}
+	if cached.Message != "Add store" || cached.Diffs[0].Patch == "" || cached.Diffs[0].Language != "Go" {
+		t.Errorf("Unexpected cached commit: %+v", cached)
+	}
+	if cached.Tags != nil {
+		t.Errorf("Expected tags not to be cached, got %v", cached.Tags)
+	}
+
+	// Patch entries also serve requests without patches
+	stats, ok := cache.Get(commit.Hash, false)
+	if !ok || stats.Diffs[0].Patch != "" {
+		t.Errorf("Expected patch-free hit from patch entry, got %+v (ok=%v)", stats, ok)
+	}
+
+	// Corrupt entries are treated as misses
+	if err := os.WriteFile(cache.path(commit.Hash, true), []byte("{"), 0o644); err != nil {
+		t.Fatalf("Failed to corrupt entry: %v", err)
+	}
+	if _, ok := cache.Get(commit.Hash, true); ok {
+		t.Error("Expected miss for corrupt entry")
+	}
+
+	// A nil cache is a no-op
+	var disabled *CommitCache
+	if err := disabled.Put(commit, false); err != nil {
+		t.Errorf("Expected nil cache Put to succeed, got %v", err)
+	}
+	if _, ok := disabled.Get(commit.Hash, false); ok {
+		t.Error("Expected nil cache to miss")
+	}
+}
+
+func TestParseCommitsWithOptions_Cache(t *testing.T) {
+	ctx := context.Background()
+	_, repo := createHistoryRepository(t, 6)
+
+	cacheDir := t.TempDir()
+	cache, err := NewCommitCache(cacheDir)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	opts := DefaultParseOptions()
+	opts.Cache = cache
+	first, err := ParseCommitsWithOptions(ctx, repo, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+
+	entries, err := filepath.Glob(filepath.Join(cacheDir, "*", "*.json"))
+	if err != nil {
+		t.Fatalf("Failed to list cache: %v", err)
+	}
+	if len(entries) != len(first) {
+		t.Fatalf("Expected %d cache entries, got %d", len(first), len(entries))
+	}
+
+	// Tamper with one entry to prove the second run reads from the cache
+	cached, _ := cache.Get(first[0].Hash, false)
+	cached.MessageSubject = "from cache"
+	if err := cache.Put(cached, false); err != nil {
+		t.Fatalf("Failed to update cache entry: %v", err)
+	}
+
+	second, err := ParseCommitsWithOptions(ctx, repo, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if second[0].MessageSubject != "from cache" {
+		t.Errorf("Expected cached commit to be reused, got %q", second[0].MessageSubject)
+	}
+	for i := 1; i < len(second); i++ {
+		if second[i].Hash != first[i].Hash || second[i].Stats != first[i].Stats {
+			t.Errorf("Commit %d differs between cached and fresh parse", i)
+		}
+	}
+}
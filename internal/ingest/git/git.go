@@ -1,14 +1,20 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/cache"
 	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/storage/filesystem"
 	"github.com/go-git/go-git/v6/storage/memory"
 )
 
@@ -18,9 +24,21 @@ func OpenRepository(path string) (*git.Repository, error) {
 }
 
 // CloneRepository clones a Git repository to memory
-func CloneRepository(url string) (*git.Repository, error) {
-	return git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
-		URL: url,
+// The clone is aborted if ctx is cancelled
+func CloneRepository(ctx context.Context, url string) (*git.Repository, error) {
+	return CloneRepositoryWithAuth(ctx, url, AuthOptions{})
+}
+
+// CloneRepositoryWithAuth clones a private Git repository to memory using the given credentials
+func CloneRepositoryWithAuth(ctx context.Context, url string, auth AuthOptions) (*git.Repository, error) {
+	method, err := auth.Method(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:  url,
+		Auth: method,
 	})
 }
 
@@ -67,6 +85,61 @@ func ParseBranches(repo *git.Repository) ([]Branch, error) {
 	return branches, nil
 }
 
+// ParseTags extracts all tags from a repository, oldest first
+// Annotated tags are peeled to the commit they reference; tags on non-commit objects are skipped
+func ParseTags(repo *git.Repository) ([]Tag, error) {
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tags := make([]Tag, 0)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := Tag{Name: ref.Name().Short()}
+
+		tagObj, err := repo.TagObject(ref.Hash())
+		switch {
+		case err == nil:
+			commit, err := tagObj.Commit()
+			if err != nil {
+				// Tag points at a tree or blob, not a release
+				return nil
+			}
+			tagger := ParseAuthor(tagObj.Tagger)
+			tag.Hash = commit.Hash.String()
+			tag.Tagger = &tagger
+			tag.Message = strings.TrimSpace(tagObj.Message)
+			tag.Date = tagObj.Tagger.When
+			tag.IsAnnotated = true
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// Lightweight tag: the reference points directly at a commit
+			commit, err := repo.CommitObject(ref.Hash())
+			if err != nil {
+				return nil
+			}
+			tag.Hash = commit.Hash.String()
+			tag.Date = commit.Committer.When
+		default:
+			return fmt.Errorf("failed to resolve tag %s: %w", tag.Name, err)
+		}
+
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].Date.Equal(tags[j].Date) {
+			return tags[i].Name < tags[j].Name
+		}
+		return tags[i].Date.Before(tags[j].Date)
+	})
+
+	return tags, nil
+}
+
 // ParseAuthor converts go-git Signature to Author
 func ParseAuthor(sig object.Signature) Author {
 	return Author{
@@ -86,7 +159,7 @@ func getFileType(path string) string {
 }
 
 // ParseCommitDiffs extracts diffs for a commit with detailed metadata
-func ParseCommitDiffs(commit *object.Commit, includePatch bool) ([]Diff, error) {
+func ParseCommitDiffs(ctx context.Context, commit *object.Commit, includePatch bool) ([]Diff, error) {
 	var diffs []Diff
 
 	// Get parent commit for diff comparison
@@ -100,6 +173,10 @@ func ParseCommitDiffs(commit *object.Commit, includePatch bool) ([]Diff, error)
 
 		// All files are added in first commit
 		err = tree.Files().ForEach(func(file *object.File) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
 			isBinary, _ := file.IsBinary()
 			content := ""
 			if !isBinary && includePatch {
@@ -112,15 +189,19 @@ func ParseCommitDiffs(commit *object.Commit, includePatch bool) ([]Diff, error)
 				lines = strings.Count(c, "\n") + 1
 			}
 
-			diffs = append(diffs, Diff{
+			diff := Diff{
 				FilePath:  file.Name,
 				Status:    "added",
 				Additions: lines,
 				Deletions: 0,
 				IsBinary:  isBinary,
 				FileType:  getFileType(file.Name),
-				Patch:     content,
-			})
+				Patch:     addedHunk(content),
+			}
+			describeBlob(&diff, file)
+			detectFileLanguage(&diff, file)
+
+			diffs = append(diffs, diff)
 			return nil
 		})
 
@@ -128,11 +209,21 @@ func ParseCommitDiffs(commit *object.Commit, includePatch bool) ([]Diff, error)
 	}
 
 	// Get diff with parent
-	patch, err := parent.Patch(commit)
+	patch, err := parent.PatchContext(ctx, commit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get patch: %w", err)
 	}
 
+	// Trees are used to look up file sizes on either side of the change
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent tree: %w", err)
+	}
+
 	// Parse file patches
 	for _, filePatch := range patch.FilePatches() {
 		from, to := filePatch.Files()
@@ -168,13 +259,9 @@ func ParseCommitDiffs(commit *object.Commit, includePatch bool) ([]Diff, error)
 		// Count additions and deletions from chunks
 		additions := 0
 		deletions := 0
-		patchText := ""
 
 		for _, chunk := range filePatch.Chunks() {
 			content := chunk.Content()
-			if includePatch {
-				patchText += content
-			}
 
 			// Count lines based on chunk type
 			switch chunk.Type() {
@@ -188,9 +275,19 @@ func ParseCommitDiffs(commit *object.Commit, includePatch bool) ([]Diff, error)
 		diff.Additions = additions
 		diff.Deletions = deletions
 		if includePatch && !isBinary {
-			diff.Patch = patchText
+			if diff.Patch, err = unifiedHunks(filePatch); err != nil {
+				return nil, fmt.Errorf("failed to render patch for %s: %w", diff.FilePath, err)
+			}
 		}
 
+		// Deleted files report their size before the change
+		file := treeFile(tree, diff.FilePath)
+		if diff.Status == "deleted" {
+			file = treeFile(parentTree, diff.FilePath)
+		}
+		describeBlob(&diff, file)
+		detectFileLanguage(&diff, file)
+
 		diffs = append(diffs, diff)
 	}
 
@@ -208,7 +305,30 @@ func parseCommitMessage(message string) (subject, body string) {
 }
 
 // ParseCommit converts a go-git Commit to our Commit struct with full metadata
-func ParseCommit(commit *object.Commit, includePatch bool) (*Commit, error) {
+func ParseCommit(ctx context.Context, commit *object.Commit, includePatch bool) (*Commit, error) {
+	// Parse diffs
+	diffs, err := ParseCommitDiffs(ctx, commit, includePatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diffs: %w", err)
+	}
+
+	return newCommit(commit, diffs)
+}
+
+// ParseCommitSummary converts a go-git Commit using only a tree comparison
+// Diffs carry file paths and statuses but no line counts or patches; use
+// LoadDiffs on a lazily parsed commit to fill them in
+func ParseCommitSummary(ctx context.Context, commit *object.Commit) (*Commit, error) {
+	diffs, err := parseChangedFiles(ctx, commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse changed files: %w", err)
+	}
+
+	return newCommit(commit, diffs)
+}
+
+// newCommit builds a Commit from a go-git commit and its parsed diffs
+func newCommit(commit *object.Commit, diffs []Diff) (*Commit, error) {
 	// Parse parent hashes
 	parentHashes := make([]string, 0, commit.NumParents())
 	err := commit.Parents().ForEach(func(parent *object.Commit) error {
@@ -219,13 +339,33 @@ func ParseCommit(commit *object.Commit, includePatch bool) (*Commit, error) {
 		return nil, fmt.Errorf("failed to parse parents: %w", err)
 	}
 
-	// Parse diffs
-	diffs, err := ParseCommitDiffs(commit, includePatch)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse diffs: %w", err)
-	}
+	// Parse commit message
+	subject, body := parseCommitMessage(commit.Message)
+
+	return &Commit{
+		Hash:              commit.Hash.String(),
+		ShortHash:         commit.Hash.String()[:8],
+		Author:            ParseAuthor(commit.Author),
+		Committer:         ParseAuthor(commit.Committer),
+		Message:           commit.Message,
+		MessageSubject:    subject,
+		MessageBody:       body,
+		CommittedAt:       commit.Committer.When,
+		ParentHashes:      parentHashes,
+		TreeHash:          commit.TreeHash.String(),
+		Diffs:             diffs,
+		Stats:             calculateStats(diffs),
+		Languages:         commitLanguages(diffs),
+		IsMerge:           commit.NumParents() > 1,
+		Branch:            nil, // Will be set by caller if needed
+		PullRequestNumber: ExtractPullRequestNumber(commit.Message),
+		Signed:            commit.PGPSignature != "",
+		SignatureType:     signatureType(commit.PGPSignature),
+	}, nil
+}
 
-	// Calculate statistics
+// calculateStats aggregates line statistics over a commit's diffs
+func calculateStats(diffs []Diff) CommitStats {
 	stats := CommitStats{
 		FilesChanged: len(diffs),
 	}
@@ -234,94 +374,684 @@ func ParseCommit(commit *object.Commit, includePatch bool) (*Commit, error) {
 		stats.Deletions += diff.Deletions
 	}
 	stats.NetChange = stats.Additions - stats.Deletions
+	return stats
+}
 
-	// Parse commit message
-	subject, body := parseCommitMessage(commit.Message)
+// parseChangedFiles lists the files touched by a commit without reading line-level changes
+func parseChangedFiles(ctx context.Context, commit *object.Commit) ([]Diff, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
 
-	return &Commit{
-		Hash:           commit.Hash.String(),
-		ShortHash:      commit.Hash.String()[:8],
-		Author:         ParseAuthor(commit.Author),
-		Committer:      ParseAuthor(commit.Committer),
-		Message:        commit.Message,
-		MessageSubject: subject,
-		MessageBody:    body,
-		CommittedAt:    commit.Committer.When,
-		ParentHashes:   parentHashes,
-		TreeHash:       commit.TreeHash.String(),
-		Diffs:          diffs,
-		Stats:          stats,
-		IsMerge:        commit.NumParents() > 1,
-		Branch:         nil, // Will be set by caller if needed
-	}, nil
+	var parentTree *object.Tree
+	parent, err := commit.Parents().Next()
+	if err == nil {
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent tree: %w", err)
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	diffs := make([]Diff, 0, len(changes))
+	for _, change := range changes {
+		from, to := change.From.Name, change.To.Name
+
+		diff := Diff{}
+		switch {
+		case from == "":
+			diff.FilePath = to
+			diff.Status = "added"
+		case to == "":
+			diff.FilePath = from
+			diff.Status = "deleted"
+		case from != to:
+			diff.FilePath = to
+			diff.OldPath = from
+			diff.Status = "renamed"
+		default:
+			diff.FilePath = to
+			diff.OldPath = from
+			diff.Status = "modified"
+		}
+		diff.FileType = getFileType(diff.FilePath)
+		diff.Language = DetectLanguage(diff.FilePath, "")
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// DiffsLoaded reports whether the commit's diffs include the patches requested
+// Only commits parsed with ParseOptions.LazyDiffs and IncludePatch start out unloaded
+func (c *Commit) DiffsLoaded() bool {
+	return c.lazy == nil
+}
+
+// LoadDiffs computes the patches of a lazily parsed commit
+// It is a no-op for commits whose diffs are already loaded
+func (c *Commit) LoadDiffs(ctx context.Context) error {
+	if c.lazy == nil {
+		return nil
+	}
+
+	commit, err := c.lazy.repo.CommitObject(plumbing.NewHash(c.Hash))
+	if err != nil {
+		return fmt.Errorf("failed to load commit %s: %w", c.ShortHash, err)
+	}
+
+	diffs, err := ParseCommitDiffs(ctx, commit, true)
+	if err != nil {
+		return fmt.Errorf("failed to parse diffs for %s: %w", c.ShortHash, err)
+	}
+
+	c.Diffs = diffs
+	c.lazy.binaryPolicy.apply(c)
+	c.lazy.filter.apply(c)
+	c.lazy = nil
+	return nil
+}
+
+// errMaxCommitsReached stops commit iteration once the requested limit is hit
+var errMaxCommitsReached = errors.New("max commits reached")
+
+// ParseOptions controls how commits are extracted from a repository
+type ParseOptions struct {
+	// MaxCommits limits the number of commits parsed (0 = unlimited)
+	MaxCommits int
+
+	// IncludePatch includes full diff patches (can be large)
+	IncludePatch bool
+
+	// Workers is the number of commits whose diffs are computed concurrently (<= 0 = runtime.NumCPU())
+	Workers int
+
+	// LazyDiffs defers the patches IncludePatch requests until Commit.LoadDiffs is called;
+	// changed files and line counts are parsed up front
+	LazyDiffs bool
+
+	// IncludePaths and ExcludePaths scope parsing to matching files (see PathFilter)
+	// Commits touching no in-scope files are dropped and stats cover in-scope files only
+	IncludePaths []string
+	ExcludePaths []string
+
+	// Cache reuses previously parsed commits by hash instead of recomputing their diffs (nil = no cache)
+	// With LazyDiffs it holds the commits without their patches
+	Cache *CommitCache
+
+	// BinaryPolicy controls how binary and Git LFS files are reported (default BinarySizeOnly)
+	// With LazyDiffs it is applied when the diffs are loaded
+	BinaryPolicy BinaryPolicy
+
+	// Revision parses the history of this commit, branch or tag instead of HEAD's (empty = HEAD)
+	// ParseRepositoryWithOptions then reports it as the head, and the submodules it pins
+	Revision string
+
+	// AllBranches parses the commits reachable from any local or remote branch in place of HEAD's
+	// (or Revision's) history, recording the branches containing each (see ParseCommitsAllBranches)
+	AllBranches bool
+}
+
+// pathFilter returns the path scope described by the options
+func (o ParseOptions) pathFilter() PathFilter {
+	return PathFilter{Include: o.IncludePaths, Exclude: o.ExcludePaths}
+}
+
+// DefaultParseOptions returns parse options with unlimited commits, no patches and one worker per CPU
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{
+		Workers: runtime.NumCPU(),
+	}
 }
 
 // ParseCommits extracts commits from a repository
 // maxCommits: 0 for unlimited, >0 to limit
 // includePatch: whether to include full diff patches (can be large)
-func ParseCommits(repo *git.Repository, maxCommits int, includePatch bool) ([]Commit, error) {
-	// Get HEAD reference
-	ref, err := repo.Head()
+// Iteration stops promptly and returns the context error if ctx is cancelled
+func ParseCommits(ctx context.Context, repo *git.Repository, maxCommits int, includePatch bool) ([]Commit, error) {
+	opts := DefaultParseOptions()
+	opts.MaxCommits = maxCommits
+	opts.IncludePatch = includePatch
+	return ParseCommitsWithOptions(ctx, repo, opts)
+}
+
+// ParseCommitsWithOptions extracts commits reachable from HEAD (or opts.Revision), newest first
+// Diffs are computed by a pool of opts.Workers goroutines; output order matches the log order
+// With a path scope, MaxCommits counts only commits that touch in-scope files
+func ParseCommitsWithOptions(ctx context.Context, repo *git.Repository, opts ParseOptions) ([]Commit, error) {
+	filter := opts.pathFilter()
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.BinaryPolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	objects, branches, err := collectCommits(ctx, repo, opts, filter, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		return nil, err
+	}
+	return parseScopedCommits(ctx, repo, objects, branches, opts, filter)
+}
+
+// collectCommits walks history from HEAD (or opts.Revision, or every branch with opts.AllBranches) and
+// collects the commits that touch the filter's paths, up to opts.MaxCommits; commits for which skip
+// returns true are passed over without counting. With opts.AllBranches it also returns the names of
+// the branches containing each commit
+func collectCommits(ctx context.Context, repo *git.Repository, opts ParseOptions, filter PathFilter, skip func(*object.Commit) bool) ([]*object.Commit, map[plumbing.Hash][]string, error) {
+	var forEach func(func(*object.Commit) error) error
+	var branches map[plumbing.Hash][]string
+	if opts.AllBranches {
+		all, err := ParseBranches(repo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse branches: %w", err)
+		}
+		ordered, names, err := branchCommits(ctx, repo, all)
+		if err != nil {
+			return nil, nil, err
+		}
+		branches = names
+		forEach = func(visit func(*object.Commit) error) error {
+			for _, c := range ordered {
+				if err := visit(c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	} else {
+		from, err := resolveRevision(repo, opts.Revision)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Get commit iterator
+		commitIter, err := repo.Log(&git.LogOptions{
+			From: from,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get log: %w", err)
+		}
+		forEach = commitIter.ForEach
 	}
 
-	// Get commit iterator
-	commitIter, err := repo.Log(&git.LogOptions{
-		From: ref.Hash(),
+	objects := make([]*object.Commit, 0)
+
+	err := forEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if skip != nil && skip(c) {
+			return nil
+		}
+		if opts.MaxCommits > 0 && len(objects) >= opts.MaxCommits {
+			return errMaxCommitsReached
+		}
+
+		// Cheap tree comparison skips out-of-scope commits before computing full diffs
+		if !filter.IsEmpty() {
+			inScope, err := touchesPaths(ctx, c, filter)
+			if err != nil {
+				return fmt.Errorf("failed to check paths for commit %s: %w", c.Hash, err)
+			}
+			if !inScope {
+				return nil
+			}
+		}
+
+		objects = append(objects, c)
+		return nil
 	})
+
+	// Reaching the commit limit is not a real error
+	if err != nil && !errors.Is(err, errMaxCommitsReached) {
+		return nil, nil, fmt.Errorf("failed to iterate commits: %w", err)
+	}
+	return objects, branches, nil
+}
+
+// parseScopedCommits parses collected commits, deferring their patches when opts.LazyDiffs is set,
+// and keeps only their changes within the filter's paths; branches, when not nil, names the
+// branches containing each commit
+func parseScopedCommits(ctx context.Context, repo *git.Repository, objects []*object.Commit, branches map[plumbing.Hash][]string, opts ParseOptions, filter PathFilter) ([]Commit, error) {
+	parseOpts := opts
+	if opts.LazyDiffs {
+		parseOpts.IncludePatch = false
+	}
+	commits, err := parseCommitObjects(ctx, repo, objects, parseOpts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.LazyDiffs && opts.IncludePatch {
+		for i := range commits {
+			commits[i].lazy = &lazyDiffs{repo: repo, filter: filter, binaryPolicy: opts.BinaryPolicy}
+		}
+	}
+	if branches != nil {
+		for i := range commits {
+			commits[i].Branches = branches[objects[i].Hash]
+		}
+	}
+
+	if filter.IsEmpty() {
+		return commits, nil
+	}
+
+	scoped := commits[:0]
+	for i := range commits {
+		if filter.apply(&commits[i]) {
+			scoped = append(scoped, commits[i])
+		}
+	}
+	return scoped, nil
+}
+
+// touchesPaths reports whether a commit changes any file within the filter's scope
+func touchesPaths(ctx context.Context, commit *object.Commit, filter PathFilter) (bool, error) {
+	diffs, err := parseChangedFiles(ctx, commit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get log: %w", err)
+		return false, err
 	}
+	for _, diff := range diffs {
+		if filter.matchDiff(diff) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	commits := make([]Commit, 0)
-	count := 0
+// parseCommitObjects parses commits concurrently with opts.Workers workers, applying the binary policy
+// Results keep the order of objects; the first error cancels the remaining work
+func parseCommitObjects(ctx context.Context, repo *git.Repository, objects []*object.Commit, opts ParseOptions) ([]Commit, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(objects) {
+		workers = len(objects)
+	}
 
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		if maxCommits > 0 && count >= maxCommits {
-			return fmt.Errorf("max commits reached")
+	commits := make([]Commit, len(objects))
+
+	// Serial path avoids goroutine overhead for small inputs
+	if workers <= 1 {
+		for i, c := range objects {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			commit, err := parseCachedCommit(ctx, c, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse commit %s: %w", c.Hash, err)
+			}
+			commits[i] = *commit
 		}
+		return commits, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		commit, err := ParseCommit(c, includePatch)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		workerRepo, err := workerRepository(repo)
 		if err != nil {
-			return fmt.Errorf("failed to parse commit %s: %w", c.Hash, err)
+			fail(err)
+			break
 		}
 
-		commits = append(commits, *commit)
-		count++
-		return nil
-	})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c, err := workerRepo.CommitObject(objects[i].Hash)
+				if err != nil {
+					fail(fmt.Errorf("failed to load commit %s: %w", objects[i].Hash, err))
+					continue
+				}
+				commit, err := parseCachedCommit(ctx, c, opts)
+				if err != nil {
+					fail(fmt.Errorf("failed to parse commit %s: %w", c.Hash, err))
+					continue
+				}
+				commits[i] = *commit
+			}
+		}()
+	}
+
+dispatch:
+	for i := range objects {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// parseCachedCommit parses a commit's full diffs, reusing and populating opts.Cache when set
+// Cache write failures are ignored since the parsed commit is still valid
+func parseCachedCommit(ctx context.Context, c *object.Commit, opts ParseOptions) (*Commit, error) {
+	commit, ok := opts.Cache.Get(c.Hash.String(), opts.IncludePatch)
+	if !ok {
+		var err error
+		commit, err = ParseCommit(ctx, c, opts.IncludePatch)
+		if err != nil {
+			return nil, err
+		}
+		_ = opts.Cache.Put(commit, opts.IncludePatch)
+	}
+
+	opts.BinaryPolicy.apply(commit)
+	return commit, nil
+}
+
+// workerRepository returns a repository handle safe to read from a separate goroutine
+// On-disk storage shares packfile readers, so each worker gets its own storage over the same directory
+func workerRepository(repo *git.Repository) (*git.Repository, error) {
+	fsStorage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return repo, nil
+	}
+
+	workerRepo, err := git.Open(filesystem.NewStorage(fsStorage.Filesystem(), cache.NewObjectLRUDefault()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository for worker: %w", err)
+	}
+	return workerRepo, nil
+}
 
-	// "max commits reached" is not a real error
-	if err != nil && err.Error() != "max commits reached" {
-		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+// ParseCommitsAllBranches extracts commits reachable from any local or remote branch
+// Shared history is deduplicated and each commit records every branch that contains it
+func ParseCommitsAllBranches(ctx context.Context, repo *git.Repository, maxCommits int, includePatch bool) ([]Commit, error) {
+	return ParseCommitsForBranches(ctx, repo, nil, maxCommits, includePatch)
+}
+
+// ParseCommitsForBranches extracts commits reachable from the named branches
+// A nil or empty branchNames selects all branches
+// Commits are returned newest first; maxCommits: 0 for unlimited, >0 to limit
+func ParseCommitsForBranches(ctx context.Context, repo *git.Repository, branchNames []string, maxCommits int, includePatch bool) ([]Commit, error) {
+	branches, err := ParseBranches(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse branches: %w", err)
+	}
+
+	selected := branches
+	if len(branchNames) > 0 {
+		wanted := make(map[string]bool, len(branchNames))
+		for _, name := range branchNames {
+			wanted[name] = true
+		}
+
+		selected = make([]Branch, 0, len(branchNames))
+		for _, branch := range branches {
+			if wanted[branch.Name] {
+				selected = append(selected, branch)
+				delete(wanted, branch.Name)
+			}
+		}
+
+		for name := range wanted {
+			return nil, fmt.Errorf("branch not found: %s", name)
+		}
+	}
+
+	ordered, branchNamesOf, err := branchCommits(ctx, repo, selected)
+	if err != nil {
+		return nil, err
+	}
+	if maxCommits > 0 && len(ordered) > maxCommits {
+		ordered = ordered[:maxCommits]
+	}
+
+	opts := DefaultParseOptions()
+	opts.IncludePatch = includePatch
+	commits, err := parseCommitObjects(ctx, repo, ordered, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range ordered {
+		commits[i].Branches = branchNamesOf[c.Hash]
 	}
 
 	return commits, nil
 }
 
+// branchCommits walks the branches at once, deduplicating shared history, and returns their commits
+// newest first along with the sorted names of the branches containing each
+func branchCommits(ctx context.Context, repo *git.Repository, branches []Branch) ([]*object.Commit, map[plumbing.Hash][]string, error) {
+	graph, err := buildBranchGraph(ctx, repo, branches)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to walk branches: %w", err)
+	}
+
+	// Order newest first to match single-branch log output
+	ordered := make([]*object.Commit, 0, len(graph.commits))
+	for _, c := range graph.commits {
+		ordered = append(ordered, c)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		ti, tj := ordered[i].Committer.When, ordered[j].Committer.When
+		if ti.Equal(tj) {
+			return ordered[i].Hash.String() < ordered[j].Hash.String()
+		}
+		return ti.After(tj)
+	})
+
+	names := make(map[plumbing.Hash][]string, len(ordered))
+	for _, c := range ordered {
+		indexes := graph.reach[c.Hash].indexes()
+		branchNames := make([]string, 0, len(indexes))
+		for _, index := range indexes {
+			branchNames = append(branchNames, branches[index].Name)
+		}
+		sort.Strings(branchNames)
+		names[c.Hash] = branchNames
+	}
+	return ordered, names, nil
+}
+
+// resolveRevision returns the commit a revision names, or HEAD's for an empty revision
+func resolveRevision(repo *git.Repository, revision string) (plumbing.Hash, error) {
+	if revision == "" {
+		ref, err := repo.Head()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		return ref.Hash(), nil
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	return *hash, nil
+}
+
+// ParseRepositoryAllBranches extracts repository metadata with commits from every branch
+func ParseRepositoryAllBranches(ctx context.Context, repo *git.Repository, url string, maxCommits int, includePatch bool) (*Repository, error) {
+	commits, err := ParseCommitsAllBranches(ctx, repo, maxCommits, includePatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commits: %w", err)
+	}
+
+	return buildRepository(ctx, repo, url, commits, "")
+}
+
+// ErrCheckpointNotFound is returned when a checkpoint hash is not part of the repository history
+var ErrCheckpointNotFound = errors.New("checkpoint commit not found")
+
+// ParseCommitsSince extracts only commits made after a checkpoint
+// Commits reachable from the checkpoint hash are excluded, as are commits
+// committed at or before the checkpoint time
+func ParseCommitsSince(ctx context.Context, repo *git.Repository, since Checkpoint, includePatch bool) ([]Commit, error) {
+	opts := DefaultParseOptions()
+	opts.IncludePatch = includePatch
+	return ParseCommitsSinceWithOptions(ctx, repo, since, opts)
+}
+
+// ParseCommitsSinceWithOptions is ParseCommitsSince with full parse options
+// MaxCommits, path scoping and lazy diffs apply to the new commits as they do to a full parse
+func ParseCommitsSinceWithOptions(ctx context.Context, repo *git.Repository, since Checkpoint, opts ParseOptions) ([]Commit, error) {
+	if since.IsZero() {
+		return ParseCommitsWithOptions(ctx, repo, opts)
+	}
+	filter := opts.pathFilter()
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.BinaryPolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Collect everything already seen at the checkpoint so merges of old work are skipped too
+	seen := make(map[string]bool)
+	if since.Hash != "" {
+		seenIter, err := repo.Log(&git.LogOptions{
+			From: plumbing.NewHash(since.Hash),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCheckpointNotFound, since.Hash)
+		}
+		err = seenIter.ForEach(func(c *object.Commit) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			seen[c.Hash.String()] = true
+			return nil
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrCheckpointNotFound, since.Hash, err)
+		}
+	}
+
+	objects, branches, err := collectCommits(ctx, repo, opts, filter, func(c *object.Commit) bool {
+		return seen[c.Hash.String()] || (!since.Time.IsZero() && !c.Committer.When.After(since.Time))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseScopedCommits(ctx, repo, objects, branches, opts, filter)
+}
+
 // ParseRepository extracts all metadata from a repository
 // Optimized for narrative generation with configurable depth
-func ParseRepository(repo *git.Repository, url string, maxCommits int, includePatch bool) (*Repository, error) {
+func ParseRepository(ctx context.Context, repo *git.Repository, url string, maxCommits int, includePatch bool) (*Repository, error) {
+	// Parse commits
+	commits, err := ParseCommits(ctx, repo, maxCommits, includePatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commits: %w", err)
+	}
+
+	return buildRepository(ctx, repo, url, commits, "")
+}
+
+// ParseRepositoryWithOptions extracts repository metadata using the given parse options
+func ParseRepositoryWithOptions(ctx context.Context, repo *git.Repository, url string, opts ParseOptions) (*Repository, error) {
+	commits, err := ParseCommitsWithOptions(ctx, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commits: %w", err)
+	}
+
+	return buildRepository(ctx, repo, url, commits, opts.Revision)
+}
+
+// ParseRepositorySince extracts repository metadata with only the commits made after a checkpoint
+func ParseRepositorySince(ctx context.Context, repo *git.Repository, url string, since Checkpoint, includePatch bool) (*Repository, error) {
+	commits, err := ParseCommitsSince(ctx, repo, since, includePatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commits: %w", err)
+	}
+
+	return buildRepository(ctx, repo, url, commits, "")
+}
+
+// ParseRepositorySinceWithOptions is ParseRepositorySince with full parse options
+func ParseRepositorySinceWithOptions(ctx context.Context, repo *git.Repository, url string, since Checkpoint, opts ParseOptions) (*Repository, error) {
+	commits, err := ParseCommitsSinceWithOptions(ctx, repo, since, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commits: %w", err)
+	}
+
+	return buildRepository(ctx, repo, url, commits, opts.Revision)
+}
+
+// buildRepository assembles a Repository from parsed commits, resolving HEAD and branch associations
+// A non-empty revision is reported as the head commit, and its submodules are parsed, in place of HEAD's
+func buildRepository(ctx context.Context, repo *git.Repository, url string, commits []Commit, revision string) (*Repository, error) {
 	// Parse branches
 	branches, err := ParseBranches(repo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse branches: %w", err)
 	}
 
-	// Parse commits
-	commits, err := ParseCommits(repo, maxCommits, includePatch)
+	// Parse tags and attach them to the commits they mark
+	tags, err := ParseTags(repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse commits: %w", err)
+		return nil, fmt.Errorf("failed to parse tags: %w", err)
 	}
 
-	// Get HEAD info
+	tagsByCommit := make(map[string][]string)
+	for _, tag := range tags {
+		tagsByCommit[tag.Hash] = append(tagsByCommit[tag.Hash], tag.Name)
+	}
+	for i := range commits {
+		commits[i].Tags = tagsByCommit[commits[i].Hash]
+	}
+
+	// Get HEAD info; a revision replaces HEAD's commit, while HEAD's branch stays the default branch
 	head, err := repo.Head()
 	var headHash, headBranch string
 	if err == nil {
 		headHash = head.Hash().String()
 		headBranch = head.Name().Short()
 	}
+	if revision != "" {
+		hash, err := resolveRevision(repo, revision)
+		if err != nil {
+			return nil, err
+		}
+		headHash = hash.String()
+	}
+
+	submodules := make([]Submodule, 0)
+	if headHash != "" {
+		if submodules, err = ParseSubmodulesAt(repo, headHash); err != nil {
+			return nil, fmt.Errorf("failed to parse submodules: %w", err)
+		}
+	}
 
 	// Associate commits with branches
 	// Sort branches to prioritize main/master so shared history is attributed to them
@@ -340,39 +1070,16 @@ func ParseRepository(repo *git.Repository, url string, maxCommits int, includePa
 		return nameI < nameJ
 	})
 
-	// Create a map of commit hash to branch for quick lookup
-	commitToBranch := make(map[string]*Branch)
-	for i := range branches {
-		branch := &branches[i]
-
-		// Get commit iterator for this branch
-		commitIter, err := repo.Log(&git.LogOptions{
-			From: plumbing.NewHash(branch.Hash),
-		})
-		if err != nil {
-			continue
-		}
-
-		// Mark all commits in this branch
-		commitIter.ForEach(func(c *object.Commit) error {
-			commitHash := c.Hash.String()
-			if _, exists := commitToBranch[commitHash]; !exists {
-				commitToBranch[commitHash] = branch
-			}
-			return nil
-		})
-	}
-
-	// Assign branch pointers to commits
-	for i := range commits {
-		if branch, exists := commitToBranch[commits[i].Hash]; exists {
-			commits[i].Branch = branch
-		}
+	// Attribute each commit to the highest-priority branch containing it
+	if err := associateBranches(ctx, repo, branches, commits); err != nil {
+		return nil, fmt.Errorf("failed to associate branches: %w", err)
 	}
 
 	return &Repository{
 		URL:          url,
 		Branches:     branches,
+		Tags:         tags,
+		Submodules:   submodules,
 		Commits:      commits,
 		HeadHash:     headHash,
 		HeadBranch:   headBranch,
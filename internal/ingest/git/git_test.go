@@ -1,12 +1,22 @@
 package git
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
 )
 
 func TestCloneRepository(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
@@ -17,7 +27,7 @@ func TestCloneRepository(t *testing.T) {
 }
 
 func TestParseBranches(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
@@ -52,13 +62,13 @@ func TestParseBranches(t *testing.T) {
 }
 
 func TestParseCommits(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
 	// Test without patches for speed
-	commits, err := ParseCommits(repo, 5, false)
+	commits, err := ParseCommits(context.Background(), repo, 5, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -109,13 +119,13 @@ func TestParseCommits(t *testing.T) {
 }
 
 func TestParseCommitWithPatch(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
 	// Parse commits with patches included
-	commits, err := ParseCommits(repo, 2, true)
+	commits, err := ParseCommits(context.Background(), repo, 2, true)
 	if err != nil {
 		t.Fatalf("Failed to parse commits with patches: %v", err)
 	}
@@ -143,12 +153,12 @@ func TestParseCommitWithPatch(t *testing.T) {
 }
 
 func TestParseCommitDiffs(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	commits, err := ParseCommits(repo, 5, false)
+	commits, err := ParseCommits(context.Background(), repo, 5, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -195,12 +205,12 @@ func TestParseCommitDiffs(t *testing.T) {
 
 func TestParseRepository(t *testing.T) {
 	url := "https://github.com/Yates-Labs/thunk"
-	repo, err := CloneRepository(url)
+	repo, err := CloneRepository(context.Background(), url)
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	repoData, err := ParseRepository(repo, url, 10, false)
+	repoData, err := ParseRepository(context.Background(), repo, url, 10, false)
 	if err != nil {
 		t.Fatalf("Failed to parse repository: %v", err)
 	}
@@ -246,12 +256,12 @@ func TestParseRepository(t *testing.T) {
 }
 
 func TestGetCommitsByAuthor(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	commits, err := ParseCommits(repo, 10, false)
+	commits, err := ParseCommits(context.Background(), repo, 10, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -278,12 +288,12 @@ func TestGetCommitsByAuthor(t *testing.T) {
 }
 
 func TestGetCommitsByDateRange(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	commits, err := ParseCommits(repo, 10, false)
+	commits, err := ParseCommits(context.Background(), repo, 10, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -312,12 +322,12 @@ func TestGetCommitsByDateRange(t *testing.T) {
 }
 
 func TestGetFileHistory(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	commits, err := ParseCommits(repo, 10, false)
+	commits, err := ParseCommits(context.Background(), repo, 10, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -362,12 +372,12 @@ func TestGetFileHistory(t *testing.T) {
 }
 
 func TestGetContributorStats(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	commits, err := ParseCommits(repo, 10, false)
+	commits, err := ParseCommits(context.Background(), repo, 10, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -401,12 +411,12 @@ func TestGetContributorStats(t *testing.T) {
 }
 
 func TestCommitMergeDetection(t *testing.T) {
-	repo, err := CloneRepository("https://github.com/Yates-Labs/thunk")
+	repo, err := CloneRepository(context.Background(), "https://github.com/Yates-Labs/thunk")
 	if err != nil {
 		t.Fatalf("Failed to clone repository: %v", err)
 	}
 
-	commits, err := ParseCommits(repo, 20, false)
+	commits, err := ParseCommits(context.Background(), repo, 20, false)
 	if err != nil {
 		t.Fatalf("Failed to parse commits: %v", err)
 	}
@@ -425,3 +435,479 @@ func TestCommitMergeDetection(t *testing.T) {
 
 	t.Logf("Found %d merge commits out of %d total", mergeCount, len(commits))
 }
+
+func TestParseCommitsSince(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	first := commitTestFile(t, repo, dir, "a.go", "package a\n", "Add a", baseTime)
+	commitTestFile(t, repo, dir, "b.go", "package b\n", "Add b", baseTime.Add(time.Hour))
+	third := commitTestFile(t, repo, dir, "c.go", "package c\n", "Add c", baseTime.Add(2*time.Hour))
+
+	// Hash checkpoint excludes the checkpoint commit and its ancestors
+	commits, err := ParseCommitsSince(context.Background(), repo, Checkpoint{Hash: first}, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits since hash: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits since %s, got %d", first[:8], len(commits))
+	}
+	if commits[0].Hash != third {
+		t.Errorf("Expected newest commit first, got %s", commits[0].MessageSubject)
+	}
+
+	// Time checkpoint excludes commits at or before the given time
+	commits, err = ParseCommitsSince(context.Background(), repo, Checkpoint{Time: baseTime.Add(time.Hour)}, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits since time: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Hash != third {
+		t.Errorf("Expected only the latest commit, got %d commits", len(commits))
+	}
+
+	// Zero checkpoint returns full history
+	commits, err = ParseCommitsSince(context.Background(), repo, Checkpoint{}, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Errorf("Expected 3 commits, got %d", len(commits))
+	}
+
+	// Up-to-date checkpoint yields nothing
+	commits, err = ParseCommitsSince(context.Background(), repo, Checkpoint{Hash: third}, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("Expected no new commits, got %d", len(commits))
+	}
+}
+
+func TestParseCommitsSinceWithOptions_PathFilter(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Old in-scope history before the checkpoint, then a mix of in- and out-of-scope commits after it
+	dir, repo := createTestRepository(t)
+	checkpoint := commitTestFile(t, repo, dir, "pkg1/old.go", "package pkg1\n", "Add old", baseTime)
+	commitTestFile(t, repo, dir, "pkg1/a.go", "package pkg1\n", "Add a", baseTime.Add(time.Hour))
+	commitTestFile(t, repo, dir, "docs/guide.md", "# Guide\n", "Add docs", baseTime.Add(2*time.Hour))
+	newest := commitTestFile(t, repo, dir, "pkg1/b.go", "package pkg1\n", "Add b", baseTime.Add(3*time.Hour))
+	commitTestFile(t, repo, dir, "pkg2/c.go", "package pkg2\n", "Add c", baseTime.Add(4*time.Hour))
+
+	since := Checkpoint{Hash: checkpoint}
+	commits, err := ParseCommitsSinceWithOptions(ctx, repo, since, ParseOptions{IncludePaths: []string{"pkg1/**"}})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != newest {
+		t.Fatalf("Expected the 2 new in-scope commits, newest first, got %d", len(commits))
+	}
+	for _, commit := range commits {
+		for _, diff := range commit.Diffs {
+			if !strings.HasPrefix(diff.FilePath, "pkg1/") {
+				t.Errorf("Unexpected out-of-scope diff %s in %s", diff.FilePath, commit.ShortHash)
+			}
+		}
+	}
+
+	excluded, err := ParseCommitsSinceWithOptions(ctx, repo, since, ParseOptions{ExcludePaths: []string{"pkg1/**"}})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(excluded) != 2 {
+		t.Errorf("Expected the 2 new commits outside pkg1, got %d", len(excluded))
+	}
+
+	// MaxCommits counts new in-scope commits only
+	limited, err := ParseCommitsSinceWithOptions(ctx, repo, since, ParseOptions{IncludePaths: []string{"pkg1/**"}, MaxCommits: 1})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(limited) != 1 || limited[0].Hash != newest {
+		t.Errorf("Expected only the newest in-scope commit, got %d", len(limited))
+	}
+
+	// Lazy loading applies and keeps the scope
+	lazy, err := ParseCommitsSinceWithOptions(ctx, repo, since, ParseOptions{IncludePaths: []string{"pkg1/**"}, LazyDiffs: true, IncludePatch: true})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(lazy) != 2 || lazy[0].DiffsLoaded() {
+		t.Fatalf("Expected 2 commits with unloaded diffs, got %d", len(lazy))
+	}
+	if err := lazy[0].LoadDiffs(ctx); err != nil {
+		t.Fatalf("Failed to load diffs: %v", err)
+	}
+	if len(lazy[0].Diffs) != 1 || lazy[0].Diffs[0].FilePath != "pkg1/b.go" {
+		t.Errorf("Expected loaded diffs to stay scoped, got %+v", lazy[0].Diffs)
+	}
+
+	if _, err := ParseCommitsSinceWithOptions(ctx, repo, since, ParseOptions{ExcludePaths: []string{"[bad"}}); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
+
+func TestParseCommitsSince_UnknownHash(t *testing.T) {
+	dir, repo := createTestRepository(t)
+	commitTestFile(t, repo, dir, "a.go", "package a\n", "Add a", time.Now())
+
+	_, err := ParseCommitsSince(context.Background(), repo, Checkpoint{Hash: "0123456789abcdef0123456789abcdef01234567"}, false)
+	if !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("Expected ErrCheckpointNotFound, got %v", err)
+	}
+}
+
+func TestParseCommits_ContextCancellation(t *testing.T) {
+	dir, repo := createTestRepository(t)
+	commitTestFile(t, repo, dir, "a.go", "package a\n", "Add a", time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ParseCommits(ctx, repo, 0, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from ParseCommits, got %v", err)
+	}
+	if _, err := ParseRepository(ctx, repo, dir, 0, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from ParseRepository, got %v", err)
+	}
+	if _, err := CloneRepository(ctx, dir); err == nil {
+		t.Error("Expected error when cloning with cancelled context")
+	}
+}
+
+func TestParseCommitsAllBranches(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	shared := commitTestFile(t, repo, dir, "a.go", "package a\n", "Add a", baseTime)
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatalf("Failed to create feature branch: %v", err)
+	}
+	featureOnly := commitTestFile(t, repo, dir, "feature.go", "package a\n", "Add feature", baseTime.Add(time.Hour))
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatalf("Failed to checkout master: %v", err)
+	}
+	commitTestFile(t, repo, dir, "b.go", "package a\n", "Add b", baseTime.Add(2*time.Hour))
+
+	// HEAD-only parsing misses feature branch work
+	headCommits, err := ParseCommits(context.Background(), repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(headCommits) != 2 {
+		t.Fatalf("Expected 2 HEAD commits, got %d", len(headCommits))
+	}
+
+	commits, err := ParseCommitsAllBranches(context.Background(), repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse all branches: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 deduplicated commits, got %d", len(commits))
+	}
+
+	for _, commit := range commits {
+		switch commit.Hash {
+		case shared:
+			if len(commit.Branches) != 2 {
+				t.Errorf("Expected shared commit on 2 branches, got %v", commit.Branches)
+			}
+		case featureOnly:
+			if len(commit.Branches) != 1 || commit.Branches[0] != "feature" {
+				t.Errorf("Expected feature commit only on feature, got %v", commit.Branches)
+			}
+		}
+	}
+
+	// Per-branch selection
+	commits, err = ParseCommitsForBranches(context.Background(), repo, []string{"feature"}, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse feature branch: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("Expected 2 commits on feature branch, got %d", len(commits))
+	}
+
+	if _, err := ParseCommitsForBranches(context.Background(), repo, []string{"missing"}, 0, false); err == nil {
+		t.Error("Expected error for unknown branch")
+	}
+
+	// Parse options walk every branch too, with path scoping and lazy diffs applied as to HEAD's history
+	opts := DefaultParseOptions()
+	opts.AllBranches = true
+	opts.LazyDiffs = true
+	opts.IncludePaths = []string{"feature.go"}
+	commits, err = ParseCommitsWithOptions(context.Background(), repo, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse all branches with options: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Hash != featureOnly || len(commits[0].Branches) != 1 || commits[0].Branches[0] != "feature" {
+		t.Errorf("Expected only the feature commit, on feature, got %+v", commits)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	first := commitTestFile(t, repo, dir, "a.go", "package a\n", "Add a", baseTime)
+	second := commitTestFile(t, repo, dir, "b.go", "package a\n", "Add b", baseTime.Add(24*time.Hour))
+
+	if _, err := repo.CreateTag("v0.1.0", plumbing.NewHash(first), nil); err != nil {
+		t.Fatalf("Failed to create lightweight tag: %v", err)
+	}
+	tagger := &object.Signature{Name: "Bob", Email: "bob@example.com", When: baseTime.Add(48 * time.Hour)}
+	if _, err := repo.CreateTag("v1.0.0", plumbing.NewHash(second), &git.CreateTagOptions{Tagger: tagger, Message: "First stable release\n"}); err != nil {
+		t.Fatalf("Failed to create annotated tag: %v", err)
+	}
+
+	tags, err := ParseTags(repo)
+	if err != nil {
+		t.Fatalf("Failed to parse tags: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %d", len(tags))
+	}
+
+	lightweight, annotated := tags[0], tags[1]
+	if lightweight.Name != "v0.1.0" || lightweight.Hash != first || lightweight.IsAnnotated {
+		t.Errorf("Unexpected lightweight tag: %+v", lightweight)
+	}
+	if !lightweight.Date.Equal(baseTime) {
+		t.Errorf("Expected lightweight tag date from commit, got %v", lightweight.Date)
+	}
+
+	if annotated.Name != "v1.0.0" || annotated.Hash != second || !annotated.IsAnnotated {
+		t.Errorf("Unexpected annotated tag: %+v", annotated)
+	}
+	if annotated.Tagger == nil || annotated.Tagger.Name != "Bob" {
+		t.Errorf("Expected tagger Bob, got %+v", annotated.Tagger)
+	}
+	if annotated.Message != "First stable release" {
+		t.Errorf("Expected trimmed tag message, got %q", annotated.Message)
+	}
+
+	// Tags are surfaced on the repository and attached to commits
+	repoData, err := ParseRepository(context.Background(), repo, dir, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse repository: %v", err)
+	}
+	if len(repoData.Tags) != 2 {
+		t.Errorf("Expected 2 repository tags, got %d", len(repoData.Tags))
+	}
+	for _, commit := range repoData.Commits {
+		if commit.Hash == second && (len(commit.Tags) != 1 || commit.Tags[0] != "v1.0.0") {
+			t.Errorf("Expected v1.0.0 on tagged commit, got %v", commit.Tags)
+		}
+	}
+}
+
+// createHistoryRepository builds a local repository with n commits touching a handful of files
+func createHistoryRepository(t testing.TB, n int) (string, *git.Repository) {
+	t.Helper()
+
+	dir, repo := createTestRepository(t)
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("pkg%d/file%d.go", i%5, i%13)
+		content := strings.Repeat(fmt.Sprintf("// revision %d\n", i), 50+i%20)
+		commitTestFile(t, repo, dir, name, content, fmt.Sprintf("Change %d", i), baseTime.Add(time.Duration(i)*time.Minute))
+	}
+
+	return dir, repo
+}
+
+func TestParseCommitsWithOptions_WorkersPreserveOrder(t *testing.T) {
+	ctx := context.Background()
+	dir, _ := createHistoryRepository(t, 40)
+
+	// Use a packed mirror clone as well as the loose-object worktree
+	packed, err := OpenOrClone(ctx, dir, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	loose, err := OpenRepository(dir)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	inMemory, err := CloneRepository(ctx, dir)
+	if err != nil {
+		t.Fatalf("Failed to clone into memory: %v", err)
+	}
+
+	for name, repo := range map[string]*git.Repository{"loose": loose, "packed": packed, "memory": inMemory} {
+		t.Run(name, func(t *testing.T) {
+			serial, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{Workers: 1, IncludePatch: true})
+			if err != nil {
+				t.Fatalf("Serial parse failed: %v", err)
+			}
+			parallel, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{Workers: 8, IncludePatch: true})
+			if err != nil {
+				t.Fatalf("Parallel parse failed: %v", err)
+			}
+
+			if len(serial) != 40 || len(parallel) != len(serial) {
+				t.Fatalf("Expected 40 commits, got serial=%d parallel=%d", len(serial), len(parallel))
+			}
+			for i := range serial {
+				if serial[i].Hash != parallel[i].Hash {
+					t.Fatalf("Order mismatch at %d: %s vs %s", i, serial[i].ShortHash, parallel[i].ShortHash)
+				}
+				if serial[i].Stats != parallel[i].Stats {
+					t.Errorf("Stats mismatch for %s: %+v vs %+v", serial[i].ShortHash, serial[i].Stats, parallel[i].Stats)
+				}
+			}
+		})
+	}
+
+	limited, err := ParseCommitsWithOptions(ctx, loose, ParseOptions{Workers: 4, MaxCommits: 5})
+	if err != nil {
+		t.Fatalf("Limited parse failed: %v", err)
+	}
+	if len(limited) != 5 {
+		t.Errorf("Expected 5 commits, got %d", len(limited))
+	}
+}
+
+func BenchmarkParseCommits(b *testing.B) {
+	ctx := context.Background()
+	dir, _ := createHistoryRepository(b, 100)
+
+	repo, err := OpenOrClone(ctx, dir, b.TempDir())
+	if err != nil {
+		b.Fatalf("Failed to clone: %v", err)
+	}
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{Workers: workers}); err != nil {
+					b.Fatalf("Parse failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCommitsWithOptions_LazyDiffs(t *testing.T) {
+	ctx := context.Background()
+	_, repo := createHistoryRepository(t, 8)
+
+	full, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{Workers: 1})
+	if err != nil {
+		t.Fatalf("Full parse failed: %v", err)
+	}
+	lazy, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{LazyDiffs: true, IncludePatch: true})
+	if err != nil {
+		t.Fatalf("Lazy parse failed: %v", err)
+	}
+	if len(lazy) != len(full) {
+		t.Fatalf("Expected %d commits, got %d", len(full), len(lazy))
+	}
+
+	for i := range lazy {
+		commit := &lazy[i]
+		if commit.DiffsLoaded() {
+			t.Fatalf("Expected %s to start with unloaded diffs", commit.ShortHash)
+		}
+
+		// Changed files and line counts are available without loading
+		if len(commit.Diffs) != len(full[i].Diffs) {
+			t.Fatalf("Expected %d changed files for %s, got %d", len(full[i].Diffs), commit.ShortHash, len(commit.Diffs))
+		}
+		for j := range commit.Diffs {
+			if commit.Diffs[j].FilePath != full[i].Diffs[j].FilePath || commit.Diffs[j].Status != full[i].Diffs[j].Status || commit.Diffs[j].Patch != "" {
+				t.Errorf("Diff mismatch for %s: %+v vs %+v", commit.ShortHash, commit.Diffs[j], full[i].Diffs[j])
+			}
+		}
+		if commit.Stats != full[i].Stats {
+			t.Errorf("Expected stats %+v before loading, got %+v", full[i].Stats, commit.Stats)
+		}
+
+		if err := commit.LoadDiffs(ctx); err != nil {
+			t.Fatalf("Failed to load diffs: %v", err)
+		}
+		if !commit.DiffsLoaded() {
+			t.Error("Expected diffs to be loaded")
+		}
+		if commit.Stats != full[i].Stats {
+			t.Errorf("Expected stats %+v after loading, got %+v", full[i].Stats, commit.Stats)
+		}
+		if commit.Diffs[0].Patch == "" {
+			t.Errorf("Expected patch content for %s", commit.ShortHash)
+		}
+	}
+}
+
+func TestParseCommitsWithOptions_PathFilter(t *testing.T) {
+	ctx := context.Background()
+	dir, repo := createHistoryRepository(t, 10)
+
+	// A commit touching both in-scope and out-of-scope files keeps only the in-scope diff
+	if err := os.WriteFile(filepath.Join(dir, "pkg1", "extra.go"), []byte("package pkg1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	wt, _ := repo.Worktree()
+	if _, err := wt.Add("pkg1/extra.go"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+	commitTestFile(t, repo, dir, "docs/guide.md", "# Guide\n", "Add docs and extra", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	commits, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{IncludePaths: []string{"pkg1/**"}})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+
+	// Changes 1 and 6 touch pkg1, plus the mixed commit
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 in-scope commits, got %d", len(commits))
+	}
+	for _, commit := range commits {
+		for _, diff := range commit.Diffs {
+			if !strings.HasPrefix(diff.FilePath, "pkg1/") {
+				t.Errorf("Unexpected out-of-scope diff %s in %s", diff.FilePath, commit.ShortHash)
+			}
+		}
+		if commit.Stats.FilesChanged != len(commit.Diffs) {
+			t.Errorf("Expected stats recomputed over filtered diffs, got %+v", commit.Stats)
+		}
+	}
+
+	mixed := commits[0]
+	if mixed.MessageSubject != "Add docs and extra" || mixed.Stats.Additions != 1 {
+		t.Errorf("Expected mixed commit scoped to pkg1/extra.go, got %s %+v", mixed.MessageSubject, mixed.Stats)
+	}
+
+	// MaxCommits counts in-scope commits only
+	limited, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{IncludePaths: []string{"pkg1/**"}, MaxCommits: 2})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(limited) != 2 {
+		t.Errorf("Expected 2 commits, got %d", len(limited))
+	}
+
+	// Lazy loading keeps the scope
+	lazy, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{IncludePaths: []string{"pkg1/**"}, LazyDiffs: true, IncludePatch: true})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if err := lazy[0].LoadDiffs(ctx); err != nil {
+		t.Fatalf("Failed to load diffs: %v", err)
+	}
+	if len(lazy[0].Diffs) != 1 || lazy[0].Diffs[0].FilePath != "pkg1/extra.go" || lazy[0].Diffs[0].Patch == "" {
+		t.Errorf("Expected loaded diffs to stay scoped, got %+v", lazy[0].Diffs)
+	}
+
+	if _, err := ParseCommitsWithOptions(ctx, repo, ParseOptions{ExcludePaths: []string{"[bad"}}); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
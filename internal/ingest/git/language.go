@@ -0,0 +1,194 @@
+package git
+
+import (
+	"bufio"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// languageByExtension maps lowercase file extensions to language names
+var languageByExtension = map[string]string{
+	"go":     "Go",
+	"py":     "Python",
+	"pyi":    "Python",
+	"js":     "JavaScript",
+	"mjs":    "JavaScript",
+	"cjs":    "JavaScript",
+	"jsx":    "JavaScript",
+	"ts":     "TypeScript",
+	"tsx":    "TypeScript",
+	"java":   "Java",
+	"kt":     "Kotlin",
+	"kts":    "Kotlin",
+	"scala":  "Scala",
+	"rb":     "Ruby",
+	"rs":     "Rust",
+	"c":      "C",
+	"h":      "C",
+	"cc":     "C++",
+	"cpp":    "C++",
+	"cxx":    "C++",
+	"hpp":    "C++",
+	"hh":     "C++",
+	"cs":     "C#",
+	"swift":  "Swift",
+	"m":      "Objective-C",
+	"mm":     "Objective-C",
+	"php":    "PHP",
+	"pl":     "Perl",
+	"pm":     "Perl",
+	"lua":    "Lua",
+	"r":      "R",
+	"dart":   "Dart",
+	"ex":     "Elixir",
+	"exs":    "Elixir",
+	"erl":    "Erlang",
+	"hs":     "Haskell",
+	"clj":    "Clojure",
+	"sh":     "Shell",
+	"bash":   "Shell",
+	"zsh":    "Shell",
+	"ps1":    "PowerShell",
+	"sql":    "SQL",
+	"html":   "HTML",
+	"htm":    "HTML",
+	"css":    "CSS",
+	"scss":   "SCSS",
+	"less":   "Less",
+	"vue":    "Vue",
+	"svelte": "Svelte",
+	"proto":  "Protocol Buffers",
+	"tf":     "HCL",
+	"hcl":    "HCL",
+	"json":   "JSON",
+	"yaml":   "YAML",
+	"yml":    "YAML",
+	"toml":   "TOML",
+	"xml":    "XML",
+	"md":     "Markdown",
+	"rst":    "reStructuredText",
+}
+
+// languageByFilename maps well-known file names without a useful extension to language names
+var languageByFilename = map[string]string{
+	"makefile":       "Makefile",
+	"gnumakefile":    "Makefile",
+	"dockerfile":     "Dockerfile",
+	"containerfile":  "Dockerfile",
+	"rakefile":       "Ruby",
+	"gemfile":        "Ruby",
+	"jenkinsfile":    "Groovy",
+	"cmakelists.txt": "CMake",
+	"go.mod":         "Go Module",
+	"go.sum":         "Go Module",
+}
+
+// languageByInterpreter maps shebang interpreters to language names
+var languageByInterpreter = map[string]string{
+	"sh":      "Shell",
+	"bash":    "Shell",
+	"zsh":     "Shell",
+	"python":  "Python",
+	"python3": "Python",
+	"node":    "JavaScript",
+	"ruby":    "Ruby",
+	"perl":    "Perl",
+	"php":     "PHP",
+	"lua":     "Lua",
+	"Rscript": "R",
+}
+
+// DetectLanguage classifies a file by name, falling back to the shebang line of its content
+// content may be empty when only the path is known; returns "" if the language is unknown
+func DetectLanguage(filePath, content string) string {
+	base := path.Base(filePath)
+	lower := strings.ToLower(base)
+
+	if language, ok := languageByFilename[lower]; ok {
+		return language
+	}
+	if strings.HasPrefix(lower, "dockerfile.") {
+		return "Dockerfile"
+	}
+	if ext := path.Ext(lower); ext != "" {
+		if language, ok := languageByExtension[ext[1:]]; ok {
+			return language
+		}
+	}
+
+	return detectShebang(content)
+}
+
+// detectShebang returns the language named by a "#!" interpreter line
+func detectShebang(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	// "#!/usr/bin/env python3" names the interpreter as the first argument
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return ""
+		}
+		interpreter = path.Base(args[0])
+	}
+
+	if language, ok := languageByInterpreter[interpreter]; ok {
+		return language
+	}
+	// Versioned interpreters such as python3.12
+	if stripped := strings.TrimRight(interpreter, "0123456789."); stripped != interpreter {
+		return languageByInterpreter[stripped]
+	}
+	return ""
+}
+
+// detectFileLanguage sets a diff's language, reading the file's first line only when the name is not enough
+func detectFileLanguage(diff *Diff, file *object.File) {
+	diff.Language = DetectLanguage(diff.FilePath, "")
+	if diff.Language != "" || file == nil || diff.IsBinary || diff.IsLFS {
+		return
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	firstLine, _ := bufio.NewReader(reader).ReadString('\n')
+	diff.Language = detectShebang(firstLine)
+}
+
+// commitLanguages aggregates changed lines per language over a commit's diffs
+// Every file counts at least once so binary and lazily parsed files are still represented
+func commitLanguages(diffs []Diff) map[string]int {
+	languages := make(map[string]int)
+	for _, diff := range diffs {
+		if diff.Language == "" {
+			continue
+		}
+		weight := diff.Additions + diff.Deletions
+		if weight == 0 {
+			weight = 1
+		}
+		languages[diff.Language] += weight
+	}
+	if len(languages) == 0 {
+		return nil
+	}
+	return languages
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{"internal/ingest/git/git.go", "", "Go"},
+		{"migrations/001_init.SQL", "", "SQL"},
+		{"web/src/App.tsx", "", "TypeScript"},
+		{"Makefile", "", "Makefile"},
+		{"deploy/Dockerfile.prod", "", "Dockerfile"},
+		{"scripts/release", "#!/usr/bin/env python3\nprint('hi')\n", "Python"},
+		{"scripts/setup", "#!/bin/bash\nset -e\n", "Shell"},
+		{"bin/run", "#!/usr/bin/env -S python3.12 -u\n", "Python"},
+		{"LICENSE", "MIT License\n", ""},
+		{"data.unknownext", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.path, tt.content); got != tt.expected {
+			t.Errorf("DetectLanguage(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestParseCommits_Languages(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	commitTestFile(t, repo, dir, "main.go", "package main\n", "Initial commit", baseTime)
+	commitTestFile(t, repo, dir, "schema.sql", "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n", "Add schema", baseTime.Add(time.Minute))
+	commitTestFile(t, repo, dir, "tools/lint", "#!/bin/sh\nexit 0\n", "Add lint script", baseTime.Add(2*time.Minute))
+
+	commits, err := ParseCommits(ctx, repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(commits))
+	}
+
+	if lang := commits[0].Diffs[0].Language; lang != "Shell" {
+		t.Errorf("Expected shebang detection to find Shell, got %q", lang)
+	}
+	if commits[1].Languages["SQL"] != 2 {
+		t.Errorf("Expected 2 SQL lines, got %v", commits[1].Languages)
+	}
+	if _, ok := commits[2].Languages["Go"]; !ok {
+		t.Errorf("Expected Go in initial commit languages, got %v", commits[2].Languages)
+	}
+
+	// Lazy parsing counts lines per language before the patches are loaded
+	opts := DefaultParseOptions()
+	opts.LazyDiffs = true
+	opts.IncludePatch = true
+	lazy, err := ParseCommitsWithOptions(ctx, repo, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse lazily: %v", err)
+	}
+	if lazy[1].DiffsLoaded() || lazy[1].Languages["SQL"] != commits[1].Languages["SQL"] {
+		t.Errorf("Expected %d SQL lines before loading diffs, got %v", commits[1].Languages["SQL"], lazy[1].Languages)
+	}
+}
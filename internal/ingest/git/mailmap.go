@@ -0,0 +1,214 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// MailmapFile is the conventional location of the mailmap at the repository root
+const MailmapFile = ".mailmap"
+
+// Mailmap maps the names and emails recorded in commits to canonical identities
+// Follows git's .mailmap semantics: matching is case-insensitive and an entry
+// keyed by both name and email takes precedence over an email-only entry
+type Mailmap struct {
+	byEmail     map[string]mailmapIdentity
+	byNameEmail map[string]mailmapIdentity
+}
+
+// mailmapIdentity is the canonical identity for a mailmap entry
+// Empty fields leave the corresponding commit field unchanged
+type mailmapIdentity struct {
+	name  string
+	email string
+}
+
+// NewMailmap creates an empty mailmap
+func NewMailmap() *Mailmap {
+	return &Mailmap{
+		byEmail:     make(map[string]mailmapIdentity),
+		byNameEmail: make(map[string]mailmapIdentity),
+	}
+}
+
+// Add registers an identity mapping
+// commitName may be empty to match every commit using commitEmail
+// properName or properEmail may be empty to keep the commit's value
+func (m *Mailmap) Add(properName, properEmail, commitName, commitEmail string) {
+	identity := mailmapIdentity{name: properName, email: properEmail}
+	if commitName == "" {
+		m.byEmail[normalizeEmail(commitEmail)] = identity
+		return
+	}
+	m.byNameEmail[nameEmailKey(commitName, commitEmail)] = identity
+}
+
+// Len returns the number of mappings
+func (m *Mailmap) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.byEmail) + len(m.byNameEmail)
+}
+
+// Resolve returns the canonical identity for an author
+// Authors without a matching entry are returned unchanged
+func (m *Mailmap) Resolve(author Author) Author {
+	if m == nil {
+		return author
+	}
+
+	identity, ok := m.byNameEmail[nameEmailKey(author.Name, author.Email)]
+	if !ok {
+		identity, ok = m.byEmail[normalizeEmail(author.Email)]
+	}
+	if !ok {
+		return author
+	}
+
+	if identity.name != "" {
+		author.Name = identity.name
+	}
+	if identity.email != "" {
+		author.Email = identity.email
+	}
+	return author
+}
+
+// ParseMailmap reads mailmap entries in git's .mailmap format
+// Supported line forms:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func ParseMailmap(r io.Reader) (*Mailmap, error) {
+	mailmap := NewMailmap()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name1, email1, rest, ok := parseMailmapIdentity(line)
+		if !ok {
+			continue
+		}
+
+		name2, email2, _, ok := parseMailmapIdentity(rest)
+		if !ok {
+			// Single identity: canonical name for an email
+			if name1 != "" {
+				mailmap.Add(name1, "", "", email1)
+			}
+			continue
+		}
+
+		mailmap.Add(name1, email1, name2, email2)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+
+	return mailmap, nil
+}
+
+// LoadMailmap reads a mailmap file from disk
+func LoadMailmap(path string) (*Mailmap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mailmap: %w", err)
+	}
+	defer file.Close()
+
+	return ParseMailmap(file)
+}
+
+// ReadRepositoryMailmap reads the .mailmap committed at HEAD
+// Returns an empty mailmap if the repository has no HEAD or no .mailmap
+func ReadRepositoryMailmap(repo *git.Repository) (*Mailmap, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return NewMailmap(), nil
+		}
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	file, err := commit.File(MailmapFile)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return NewMailmap(), nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", MailmapFile, err)
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", MailmapFile, err)
+	}
+	defer reader.Close()
+
+	return ParseMailmap(reader)
+}
+
+// ResolveIdentities returns a copy of commits with author and committer
+// identities rewritten to their canonical form
+func ResolveIdentities(commits []Commit, mailmap *Mailmap) []Commit {
+	resolved := make([]Commit, len(commits))
+	copy(resolved, commits)
+
+	if mailmap.Len() == 0 {
+		return resolved
+	}
+
+	for i := range resolved {
+		resolved[i].Author = mailmap.Resolve(resolved[i].Author)
+		resolved[i].Committer = mailmap.Resolve(resolved[i].Committer)
+	}
+
+	return resolved
+}
+
+// parseMailmapIdentity parses a leading "Name <email>" from s and returns the remainder
+func parseMailmapIdentity(s string) (name, email, rest string, ok bool) {
+	open := strings.Index(s, "<")
+	if open < 0 {
+		return "", "", "", false
+	}
+	closing := strings.Index(s[open:], ">")
+	if closing < 0 {
+		return "", "", "", false
+	}
+	closing += open
+
+	name = strings.TrimSpace(s[:open])
+	email = strings.TrimSpace(s[open+1 : closing])
+	return name, email, s[closing+1:], true
+}
+
+// normalizeEmail returns the case-insensitive form used for matching
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// nameEmailKey builds the lookup key for name+email mailmap entries
+func nameEmailKey(name, email string) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "\x00" + normalizeEmail(email)
+}
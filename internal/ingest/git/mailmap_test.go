@@ -0,0 +1,91 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMailmap(t *testing.T) {
+	input := `# Canonical identities
+Alice Smith <alice@example.com>
+<bob@example.com> <bob@old-company.com>
+Carol Jones <carol@example.com> <cjones@laptop.local>
+Dave <dave@example.com> dave-bot <bot@ci.example.com>
+not a valid line
+`
+
+	mailmap, err := ParseMailmap(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse mailmap: %v", err)
+	}
+	if mailmap.Len() != 4 {
+		t.Fatalf("Expected 4 entries, got %d", mailmap.Len())
+	}
+
+	tests := []struct {
+		name     string
+		input    Author
+		expected Author
+	}{
+		{"name only", Author{Name: "alice", Email: "Alice@Example.com"}, Author{Name: "Alice Smith", Email: "Alice@Example.com"}},
+		{"email only", Author{Name: "Bob", Email: "bob@old-company.com"}, Author{Name: "Bob", Email: "bob@example.com"}},
+		{"name and email", Author{Name: "cj", Email: "cjones@laptop.local"}, Author{Name: "Carol Jones", Email: "carol@example.com"}},
+		{"name and email match", Author{Name: "dave-bot", Email: "bot@ci.example.com"}, Author{Name: "Dave", Email: "dave@example.com"}},
+		{"name mismatch", Author{Name: "other-bot", Email: "bot@ci.example.com"}, Author{Name: "other-bot", Email: "bot@ci.example.com"}},
+		{"unknown", Author{Name: "Eve", Email: "eve@example.com"}, Author{Name: "Eve", Email: "eve@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mailmap.Resolve(tt.input)
+			if got.Name != tt.expected.Name || got.Email != tt.expected.Email {
+				t.Errorf("Expected %s <%s>, got %s <%s>", tt.expected.Name, tt.expected.Email, got.Name, got.Email)
+			}
+		})
+	}
+}
+
+func TestResolveIdentities(t *testing.T) {
+	mailmap := NewMailmap()
+	mailmap.Add("Alice", "alice@example.com", "", "alice@home.net")
+
+	commits := []Commit{
+		{Hash: "a", Author: Author{Name: "alice", Email: "alice@home.net"}, Committer: Author{Name: "alice", Email: "alice@home.net"}},
+		{Hash: "b", Author: Author{Name: "Bob", Email: "bob@example.com"}},
+	}
+
+	resolved := ResolveIdentities(commits, mailmap)
+	if resolved[0].Author.Email != "alice@example.com" || resolved[0].Committer.Name != "Alice" {
+		t.Errorf("Expected canonical identity, got %+v", resolved[0].Author)
+	}
+	if resolved[1].Author.Email != "bob@example.com" {
+		t.Errorf("Expected unmapped author unchanged, got %+v", resolved[1].Author)
+	}
+	if commits[0].Author.Email != "alice@home.net" {
+		t.Error("Expected input commits to be left unmodified")
+	}
+}
+
+func TestReadRepositoryMailmap(t *testing.T) {
+	dir, repo := createTestRepository(t)
+
+	// Repository without .mailmap yields an empty map
+	commitTestFile(t, repo, dir, "main.go", "package main\n", "Initial commit", time.Now())
+	mailmap, err := ReadRepositoryMailmap(repo)
+	if err != nil {
+		t.Fatalf("Failed to read mailmap: %v", err)
+	}
+	if mailmap.Len() != 0 {
+		t.Errorf("Expected empty mailmap, got %d entries", mailmap.Len())
+	}
+
+	commitTestFile(t, repo, dir, MailmapFile, "Alice <alice@example.com> <alice@home.net>\n", "Add mailmap", time.Now())
+	mailmap, err = ReadRepositoryMailmap(repo)
+	if err != nil {
+		t.Fatalf("Failed to read mailmap: %v", err)
+	}
+	if got := mailmap.Resolve(Author{Email: "alice@home.net"}); got.Email != "alice@example.com" {
+		t.Errorf("Expected committed mailmap to apply, got %+v", got)
+	}
+}
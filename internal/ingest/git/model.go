@@ -1,6 +1,10 @@
 package git
 
-import "time"
+import (
+	"time"
+
+	"github.com/go-git/go-git/v6"
+)
 
 // Author represents Git author/committer information
 // Optimized for narrative generation with full contact and temporal data
@@ -13,33 +17,56 @@ type Author struct {
 // Diff represents a file change in a commit
 // Includes detailed metadata for understanding code evolution
 type Diff struct {
-	FilePath  string `json:"file_path"`
-	OldPath   string `json:"old_path,omitempty"` // For renames
-	Status    string `json:"status"`             // "added", "modified", "deleted", "renamed"
-	Additions int    `json:"additions"`
-	Deletions int    `json:"deletions"`
-	Patch     string `json:"patch,omitempty"` // Actual diff content (optional for large repos)
-	IsBinary  bool   `json:"is_binary"`
-	FileType  string `json:"file_type"` // Extension/language for context
+	FilePath  string      `json:"file_path"`
+	OldPath   string      `json:"old_path,omitempty"` // For renames
+	Status    string      `json:"status"`             // "added", "modified", "deleted", "renamed"
+	Additions int         `json:"additions"`
+	Deletions int         `json:"deletions"`
+	Patch     string      `json:"patch,omitempty"` // Actual diff content (optional for large repos)
+	IsBinary  bool        `json:"is_binary"`
+	FileType  string      `json:"file_type"`          // Extension/language for context
+	Language  string      `json:"language,omitempty"` // Programming language detected from name or shebang
+	SizeBytes int64       `json:"size_bytes"`         // File size after the change (before it for deletions); real content size for LFS files
+	IsLFS     bool        `json:"is_lfs"`             // File is stored as a Git LFS pointer
+	LFS       *LFSPointer `json:"lfs,omitempty"`      // Pointer metadata (BinaryPointer policy only)
 }
 
 // Commit represents a Git commit with full metadata
 // Designed to capture the complete context of each change
 type Commit struct {
-	Hash           string      `json:"hash"`
-	ShortHash      string      `json:"short_hash"` // First 8 chars for display
-	Author         Author      `json:"author"`
-	Committer      Author      `json:"committer"`
-	Message        string      `json:"message"`
-	MessageSubject string      `json:"message_subject"` // First line of message
-	MessageBody    string      `json:"message_body"`    // Rest of message
-	CommittedAt    time.Time   `json:"committed_at"`
-	ParentHashes   []string    `json:"parent_hashes"`
-	TreeHash       string      `json:"tree_hash"`
-	IsMerge        bool        `json:"is_merge"`
-	Branch         *Branch     `json:"branch,omitempty"`
-	Diffs          []Diff      `json:"files_changed"`
-	Stats          CommitStats `json:"stats"`
+	Hash              string         `json:"hash"`
+	ShortHash         string         `json:"short_hash"` // First 8 chars for display
+	Author            Author         `json:"author"`
+	Committer         Author         `json:"committer"`
+	Message           string         `json:"message"`
+	MessageSubject    string         `json:"message_subject"` // First line of message
+	MessageBody       string         `json:"message_body"`    // Rest of message
+	CommittedAt       time.Time      `json:"committed_at"`
+	ParentHashes      []string       `json:"parent_hashes"`
+	TreeHash          string         `json:"tree_hash"`
+	IsMerge           bool           `json:"is_merge"`
+	Branch            *Branch        `json:"branch,omitempty"`
+	Branches          []string       `json:"branches,omitempty"`            // All branches containing this commit (multi-branch parsing)
+	Tags              []string       `json:"tags,omitempty"`                // Tags pointing at this commit (e.g. release versions)
+	PullRequestNumber int            `json:"pull_request_number,omitempty"` // PR/MR merged by this commit (0 = none detected)
+	Signed            bool           `json:"signed"`
+	SignatureType     string         `json:"signature_type,omitempty"` // "gpg", "ssh", "x509" or "unknown"
+	SignatureValid    bool           `json:"signature_valid"`          // Set by VerifySignatures for trusted signers
+	Signer            string         `json:"signer,omitempty"`         // Verified signer identity
+	SignerKeyID       string         `json:"signer_key_id,omitempty"`  // PGP key ID or SSH key fingerprint
+	Diffs             []Diff         `json:"files_changed"`
+	Stats             CommitStats    `json:"stats"`
+	Languages         map[string]int `json:"languages,omitempty"` // Changed lines per language (each file counts at least 1)
+
+	// Set when patches were deferred; LoadDiffs fills them in on demand
+	lazy *lazyDiffs
+}
+
+// lazyDiffs holds what is needed to compute a commit's patches later
+type lazyDiffs struct {
+	repo         *git.Repository
+	filter       PathFilter
+	binaryPolicy BinaryPolicy
 }
 
 // CommitStats represents aggregate statistics for a commit
@@ -58,14 +85,48 @@ type Branch struct {
 	IsHead   bool   `json:"is_head"`
 }
 
+// Tag represents a Git tag, typically marking a release
+// Lightweight tags have no tagger or message and take their date from the tagged commit
+type Tag struct {
+	Name        string    `json:"name"`
+	Hash        string    `json:"hash"` // Hash of the tagged commit
+	Tagger      *Author   `json:"tagger,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Date        time.Time `json:"date"`
+	IsAnnotated bool      `json:"is_annotated"`
+}
+
+// Submodule represents a submodule declared in .gitmodules and pinned in the HEAD tree
+type Submodule struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Branch string `json:"branch,omitempty"`
+	Hash   string `json:"hash"` // Commit pinned by the superproject (empty if not committed)
+}
+
 // Repository represents a Git repository with parsed metadata
 // Central data structure for narrative generation
 type Repository struct {
-	URL          string   `json:"url"`
-	LocalPath    string   `json:"local_path,omitempty"`
-	Branches     []Branch `json:"branches"`
-	Commits      []Commit `json:"commits"`
-	HeadHash     string   `json:"head_hash"`
-	HeadBranch   string   `json:"head_branch"`
-	TotalCommits int      `json:"total_commits"`
+	URL          string      `json:"url"`
+	LocalPath    string      `json:"local_path,omitempty"`
+	Branches     []Branch    `json:"branches"`
+	Tags         []Tag       `json:"tags"`
+	Submodules   []Submodule `json:"submodules,omitempty"`
+	Commits      []Commit    `json:"commits"`
+	HeadHash     string      `json:"head_hash"`
+	HeadBranch   string      `json:"head_branch"`
+	TotalCommits int         `json:"total_commits"`
+}
+
+// Checkpoint marks a previously analyzed position in repository history
+// Either Hash or Time (or both) may be set; zero values are ignored
+type Checkpoint struct {
+	Hash string    `json:"hash,omitempty"`
+	Time time.Time `json:"time,omitempty"`
+}
+
+// IsZero reports whether the checkpoint carries no position information
+func (c Checkpoint) IsZero() bool {
+	return c.Hash == "" && c.Time.IsZero()
 }
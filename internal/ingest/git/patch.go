@@ -0,0 +1,55 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	fdiff "github.com/go-git/go-git/v6/plumbing/format/diff"
+)
+
+// singleFilePatch presents one file's changes as a patch, so they can be encoded on their own
+type singleFilePatch struct {
+	filePatch fdiff.FilePatch
+}
+
+func (p singleFilePatch) FilePatches() []fdiff.FilePatch { return []fdiff.FilePatch{p.filePatch} }
+func (p singleFilePatch) Message() string                { return "" }
+
+// unifiedHunks renders a file's changes as unified diff hunks with three lines of context, without
+// the file header, so a patch holds only what changed rather than the whole file
+func unifiedHunks(filePatch fdiff.FilePatch) (string, error) {
+	var b strings.Builder
+	if err := fdiff.NewUnifiedEncoder(&b, fdiff.DefaultContextLines).Encode(singleFilePatch{filePatch}); err != nil {
+		return "", fmt.Errorf("failed to encode patch: %w", err)
+	}
+	text := b.String()
+	if strings.HasPrefix(text, "@@") {
+		return text, nil
+	}
+	if i := strings.Index(text, "\n@@"); i >= 0 {
+		return text[i+1:], nil
+	}
+	return "", nil
+}
+
+// addedHunk renders the contents of a file added in a root commit as a single unified diff hunk
+func addedHunk(content string) string {
+	if content == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -0,0 +1,%d @@\n", len(lines))
+	for _, line := range lines {
+		b.WriteString("+")
+		b.WriteString(line)
+	}
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n\\ No newline at end of file\n")
+	}
+	return b.String()
+}
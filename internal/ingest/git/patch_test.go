@@ -0,0 +1,46 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommitsWithOptions_UnifiedPatches(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+	lines[9] = "line ten"
+
+	dir, repo := createTestRepository(t)
+	commitTestFile(t, repo, dir, "notes.txt", original, "Add notes", baseTime)
+	commitTestFile(t, repo, dir, "notes.txt", strings.Join(lines, "\n")+"\n", "Edit notes", baseTime.Add(time.Minute))
+
+	opts := DefaultParseOptions()
+	opts.IncludePatch = true
+	commits, err := ParseCommitsWithOptions(context.Background(), repo, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	patches := make(map[string]string)
+	for _, commit := range commits {
+		patches[commit.MessageSubject] = commit.Diffs[0].Patch
+	}
+
+	// Modified files keep only the changed lines and three lines of context either side
+	want := "@@ -7,7 +7,7 @@ line 6\n line 7\n line 8\n line 9\n-line 10\n+line ten\n line 11\n line 12\n line 13\n"
+	if got := patches["Edit notes"]; got != want {
+		t.Errorf("Expected the edit's hunk\n%s\ngot\n%s", want, got)
+	}
+
+	// Files added in the root commit are one hunk of added lines
+	added := patches["Add notes"]
+	if !strings.HasPrefix(added, "@@ -0,0 +1,20 @@\n+line 1\n") || !strings.HasSuffix(added, "+line 20\n") {
+		t.Errorf("Expected the added file as one hunk, got\n%s", added)
+	}
+}
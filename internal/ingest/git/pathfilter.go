@@ -0,0 +1,116 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathFilter scopes ingestion to a subset of repository paths
+// Patterns are slash-separated globs where "*" matches within a path segment
+// and "**" matches any number of segments, e.g. "services/payments/**"
+type PathFilter struct {
+	// Include keeps only files matching at least one pattern (empty = all files)
+	Include []string
+
+	// Exclude drops files matching any pattern; takes precedence over Include
+	Exclude []string
+}
+
+// IsEmpty reports whether the filter keeps every path
+func (f PathFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Validate checks that all patterns are well-formed
+func (f PathFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		for _, segment := range strings.Split(pattern, "/") {
+			if segment == "**" {
+				continue
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether a file path is within the filter's scope
+func (f PathFilter) Match(filePath string) bool {
+	for _, pattern := range f.Exclude {
+		if matchGlob(pattern, filePath) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range f.Include {
+		if matchGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDiff reports whether either side of a diff is within scope, so renames into or out of scope are kept
+func (f PathFilter) matchDiff(diff Diff) bool {
+	return f.Match(diff.FilePath) || (diff.OldPath != "" && f.Match(diff.OldPath))
+}
+
+// apply drops out-of-scope diffs from a commit and recomputes its stats
+// Returns false if no diffs remain
+func (f PathFilter) apply(commit *Commit) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	kept := make([]Diff, 0, len(commit.Diffs))
+	for _, diff := range commit.Diffs {
+		if f.matchDiff(diff) {
+			kept = append(kept, diff)
+		}
+	}
+
+	commit.Diffs = kept
+	commit.Stats = calculateStats(kept)
+	commit.Languages = commitLanguages(kept)
+	return len(kept) > 0
+}
+
+// matchGlob matches a slash-separated path against a glob pattern with "**" support
+func matchGlob(pattern, filePath string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(filePath, "/"))
+}
+
+// matchSegments matches path segments, letting "**" consume zero or more segments
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive "**" and try every possible split point
+			rest := pattern[1:]
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(rest, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
@@ -0,0 +1,39 @@
+package git
+
+import "testing"
+
+func TestPathFilter_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   PathFilter
+		path     string
+		expected bool
+	}{
+		{"empty filter", PathFilter{}, "any/file.go", true},
+		{"recursive include", PathFilter{Include: []string{"services/payments/**"}}, "services/payments/api/handler.go", true},
+		{"recursive include root file", PathFilter{Include: []string{"services/payments/**"}}, "services/payments/go.mod", true},
+		{"outside include", PathFilter{Include: []string{"services/payments/**"}}, "services/billing/handler.go", false},
+		{"single segment wildcard", PathFilter{Include: []string{"cmd/*.go"}}, "cmd/main.go", true},
+		{"single segment wildcard depth", PathFilter{Include: []string{"cmd/*.go"}}, "cmd/sub/main.go", false},
+		{"leading double star", PathFilter{Include: []string{"**/*_test.go"}}, "internal/git/git_test.go", true},
+		{"exclude wins", PathFilter{Include: []string{"services/**"}, Exclude: []string{"**/vendor/**"}}, "services/vendor/lib.go", false},
+		{"exclude only", PathFilter{Exclude: []string{"docs/**"}}, "README.md", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.path); got != tt.expected {
+				t.Errorf("Match(%q) = %v, expected %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPathFilter_Validate(t *testing.T) {
+	if err := (PathFilter{Include: []string{"src/**/*.go"}}).Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := (PathFilter{Exclude: []string{"src/[.go"}}).Validate(); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
@@ -0,0 +1,38 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var (
+	// GitHub merge commits: "Merge pull request #123 from owner/branch"
+	mergePullRequestPattern = regexp.MustCompile(`^Merge pull request #(\d+)\b`)
+
+	// GitHub squash merges append the PR number to the subject: "Add feature (#123)"
+	squashPullRequestPattern = regexp.MustCompile(`\(#(\d+)\)\s*$`)
+
+	// GitLab merge commits reference the MR in the body: "See merge request group/project!123"
+	gitLabMergeRequestPattern = regexp.MustCompile(`(?m)^See merge request \S*!(\d+)\s*$`)
+)
+
+// ExtractPullRequestNumber returns the pull/merge request a commit message merged, or 0 if none
+// Recognizes GitHub merge and squash commit subjects and GitLab merge request trailers
+func ExtractPullRequestNumber(message string) int {
+	subject, body := parseCommitMessage(message)
+
+	for _, match := range [][]string{
+		mergePullRequestPattern.FindStringSubmatch(subject),
+		squashPullRequestPattern.FindStringSubmatch(subject),
+		gitLabMergeRequestPattern.FindStringSubmatch(body),
+	} {
+		if len(match) < 2 {
+			continue
+		}
+		if number, err := strconv.Atoi(match[1]); err == nil {
+			return number
+		}
+	}
+
+	return 0
+}
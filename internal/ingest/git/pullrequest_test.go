@@ -0,0 +1,27 @@
+package git
+
+import "testing"
+
+func TestExtractPullRequestNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected int
+	}{
+		{"github merge", "Merge pull request #42 from alice/feature-auth\n\nAdd authentication", 42},
+		{"github squash", "Add JWT validation (#108)", 108},
+		{"squash with body", "Add JWT validation (#108)\n\n* wip\n* fix tests", 108},
+		{"gitlab merge", "Merge branch 'feature' into 'main'\n\nAdd feature\n\nSee merge request group/project!17", 17},
+		{"issue reference only", "Fix crash, closes #12", 0},
+		{"mid-subject reference", "Revert (#5) partially and retry", 0},
+		{"plain commit", "Refactor parser", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractPullRequestNumber(tt.message); got != tt.expected {
+				t.Errorf("ExtractPullRequestNumber(%q) = %d, expected %d", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signature types recorded in Commit.SignatureType
+const (
+	SignatureGPG     = "gpg"
+	SignatureSSH     = "ssh"
+	SignatureX509    = "x509"
+	SignatureUnknown = "unknown"
+)
+
+// sshSigNamespace is the namespace git uses for SSH commit signatures
+const sshSigNamespace = "git"
+
+// signatureType classifies an armored commit signature, or returns "" for unsigned commits
+func signatureType(signature string) string {
+	signature = strings.TrimSpace(signature)
+	switch {
+	case signature == "":
+		return ""
+	case strings.HasPrefix(signature, "-----BEGIN PGP SIGNATURE-----"), strings.HasPrefix(signature, "-----BEGIN PGP MESSAGE-----"):
+		return SignatureGPG
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		return SignatureSSH
+	case strings.HasPrefix(signature, "-----BEGIN SIGNED MESSAGE-----"), strings.HasPrefix(signature, "-----BEGIN CERTIFICATE-----"):
+		return SignatureX509
+	default:
+		return SignatureUnknown
+	}
+}
+
+// SignatureVerifier checks commit signatures against trusted GPG keys and SSH allowed signers
+type SignatureVerifier struct {
+	pgpKeys    openpgp.EntityList
+	sshSigners []allowedSigner
+}
+
+// allowedSigner is an entry from an SSH allowed_signers file
+type allowedSigner struct {
+	principals []string
+	key        ssh.PublicKey
+}
+
+// NewSignatureVerifier creates a verifier with no trusted keys
+func NewSignatureVerifier() *SignatureVerifier {
+	return &SignatureVerifier{}
+}
+
+// AddPGPKeyRing trusts the keys in an armored OpenPGP key ring
+func (v *SignatureVerifier) AddPGPKeyRing(r io.Reader) error {
+	keys, err := openpgp.ReadArmoredKeyRing(r)
+	if err != nil {
+		return fmt.Errorf("failed to read PGP key ring: %w", err)
+	}
+	v.pgpKeys = append(v.pgpKeys, keys...)
+	return nil
+}
+
+// AddAllowedSigners trusts the keys in an SSH allowed_signers file (gpg.ssh.allowedSignersFile)
+// Each line is "principals [options] keytype base64-key [comment]"
+func (v *SignatureVerifier) AddAllowedSigners(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		principals, rest, ok := strings.Cut(text, " ")
+		if !ok {
+			return fmt.Errorf("invalid allowed signers entry on line %d", line)
+		}
+
+		// The remainder is authorized_keys syntax, including optional options
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+		if err != nil {
+			return fmt.Errorf("invalid allowed signers key on line %d: %w", line, err)
+		}
+
+		v.sshSigners = append(v.sshSigners, allowedSigner{
+			principals: strings.Split(principals, ","),
+			key:        key,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read allowed signers: %w", err)
+	}
+	return nil
+}
+
+// VerifySignatures checks the signature of every signed commit and records the outcome
+// Commits signed with untrusted or unsupported keys are marked invalid
+func VerifySignatures(ctx context.Context, repo *git.Repository, commits []Commit, verifier *SignatureVerifier) error {
+	for i := range commits {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		commit := &commits[i]
+		if !commit.Signed {
+			continue
+		}
+
+		obj, err := repo.CommitObject(plumbing.NewHash(commit.Hash))
+		if err != nil {
+			return fmt.Errorf("failed to load commit %s: %w", commit.ShortHash, err)
+		}
+
+		commit.SignatureValid, commit.Signer, commit.SignerKeyID = verifier.verify(obj)
+	}
+
+	return nil
+}
+
+// verify checks a single commit's signature, returning the signer identity and key ID on success
+func (v *SignatureVerifier) verify(commit *object.Commit) (valid bool, signer, keyID string) {
+	payload, err := signedPayload(commit)
+	if err != nil {
+		return false, "", ""
+	}
+
+	switch signatureType(commit.PGPSignature) {
+	case SignatureGPG:
+		return v.verifyPGP(payload, commit.PGPSignature)
+	case SignatureSSH:
+		return v.verifySSH(payload, commit.PGPSignature)
+	default:
+		return false, "", ""
+	}
+}
+
+// verifyPGP checks an armored detached OpenPGP signature against the trusted key ring
+func (v *SignatureVerifier) verifyPGP(payload []byte, signature string) (bool, string, string) {
+	if len(v.pgpKeys) == 0 {
+		return false, "", ""
+	}
+
+	entity, err := openpgp.CheckArmoredDetachedSignature(v.pgpKeys, bytes.NewReader(payload), strings.NewReader(signature), nil)
+	if err != nil {
+		return false, "", ""
+	}
+
+	signer := ""
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		signer = identity.Name
+	}
+	return true, signer, fmt.Sprintf("%016X", entity.PrimaryKey.KeyId)
+}
+
+// verifySSH checks an armored SSH signature (sshsig format) against the allowed signers
+func (v *SignatureVerifier) verifySSH(payload []byte, signature string) (bool, string, string) {
+	sig, err := parseSSHSignature(signature)
+	if err != nil || sig.namespace != sshSigNamespace {
+		return false, "", ""
+	}
+
+	for _, allowed := range v.sshSigners {
+		if !bytes.Equal(allowed.key.Marshal(), sig.publicKey.Marshal()) {
+			continue
+		}
+		if err := sig.verify(payload); err != nil {
+			return false, "", ""
+		}
+		return true, allowed.principals[0], ssh.FingerprintSHA256(allowed.key)
+	}
+
+	return false, "", ""
+}
+
+// signedPayload returns the commit encoding that the signature covers
+func signedPayload(commit *object.Commit) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return nil, err
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// sshSignature is a decoded sshsig blob (see OpenSSH PROTOCOL.sshsig)
+type sshSignature struct {
+	publicKey ssh.PublicKey
+	namespace string
+	reserved  string
+	hashAlgo  string
+	signature *ssh.Signature
+}
+
+// sshSigMagic prefixes both the sshsig blob and the signed data
+const sshSigMagic = "SSHSIG"
+
+// parseSSHSignature decodes an armored "SSH SIGNATURE" block
+func parseSSHSignature(armored string) (*sshSignature, error) {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, "-----BEGIN SSH SIGNATURE-----")
+	body = strings.TrimSuffix(body, "-----END SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH signature encoding: %w", err)
+	}
+
+	if !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		return nil, errors.New("missing SSHSIG magic")
+	}
+	blob = blob[len(sshSigMagic):]
+
+	var decoded struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlgo  string
+		Signature []byte
+	}
+	if err := ssh.Unmarshal(blob, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if decoded.Version != 1 {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", decoded.Version)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(decoded.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH signature key: %w", err)
+	}
+
+	signature := &ssh.Signature{}
+	if err := ssh.Unmarshal(decoded.Signature, signature); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature blob: %w", err)
+	}
+
+	return &sshSignature{
+		publicKey: publicKey,
+		namespace: decoded.Namespace,
+		reserved:  decoded.Reserved,
+		hashAlgo:  decoded.HashAlgo,
+		signature: signature,
+	}, nil
+}
+
+// verify checks the signature over message
+func (s *sshSignature) verify(message []byte) error {
+	data, err := sshSignedData(s.namespace, s.reserved, s.hashAlgo, message)
+	if err != nil {
+		return err
+	}
+	return s.publicKey.Verify(data, s.signature)
+}
+
+// sshSignedData builds the data an sshsig signature is computed over
+func sshSignedData(namespace, reserved, hashAlgo string, message []byte) ([]byte, error) {
+	var h hash.Hash
+	switch hashAlgo {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("unsupported SSH signature hash %q", hashAlgo)
+	}
+	h.Write(message)
+
+	var buf bytes.Buffer
+	buf.WriteString(sshSigMagic)
+	for _, field := range [][]byte{[]byte(namespace), []byte(reserved), []byte(hashAlgo), h.Sum(nil)} {
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(field)))
+		buf.Write(field)
+	}
+	return buf.Bytes(), nil
+}
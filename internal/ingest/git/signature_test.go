@@ -0,0 +1,170 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHSigner produces sshsig signatures the way `git commit -S` does with gpg.format=ssh
+type testSSHSigner struct {
+	signer ssh.Signer
+}
+
+func (s testSSHSigner) Sign(message io.Reader) ([]byte, error) {
+	payload, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	data, err := sshSignedData(sshSigNamespace, "", "sha512", payload)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.signer.Sign(rand.Reader, data)
+	if err != nil {
+		return nil, err
+	}
+
+	blob := []byte(sshSigMagic)
+	blob = append(blob, ssh.Marshal(struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlgo  string
+		Signature []byte
+	}{1, s.signer.PublicKey().Marshal(), sshSigNamespace, "", "sha512", ssh.Marshal(sig)})...)
+
+	armored := "-----BEGIN SSH SIGNATURE-----\n" + base64.StdEncoding.EncodeToString(blob) + "\n-----END SSH SIGNATURE-----\n"
+	return []byte(armored), nil
+}
+
+// commitSigned commits a file using the given commit options for signing
+func commitSigned(t *testing.T, repo *git.Repository, dir, name string, opts *git.CommitOptions) string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	wt, _ := repo.Worktree()
+	if _, err := wt.Add(name); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	sig := &object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Now()}
+	opts.Author, opts.Committer = sig, sig
+	hash, err := wt.Commit("Add "+name, opts)
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash.String()
+}
+
+func TestVerifySignatures(t *testing.T) {
+	ctx := context.Background()
+	dir, repo := createTestRepository(t)
+
+	// PGP-signed commit
+	entity, err := openpgp.NewEntity("Alice", "", "alice@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create PGP key: %v", err)
+	}
+	gpgHash := commitSigned(t, repo, dir, "gpg.txt", &git.CommitOptions{SignKey: entity})
+
+	// SSH-signed commit
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	sshSigner, err := ssh.NewSignerFromKey(edKey)
+	if err != nil {
+		t.Fatalf("Failed to create SSH signer: %v", err)
+	}
+	sshHash := commitSigned(t, repo, dir, "ssh.txt", &git.CommitOptions{Signer: testSSHSigner{sshSigner}})
+
+	// SSH-signed by an untrusted key
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := ssh.NewSignerFromKey(otherKey)
+	untrustedHash := commitSigned(t, repo, dir, "untrusted.txt", &git.CommitOptions{Signer: testSSHSigner{otherSigner}})
+
+	unsignedHash := commitTestFile(t, repo, dir, "plain.txt", "plain\n", "Add plain", time.Now())
+
+	commits, err := ParseCommits(ctx, repo, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+
+	byHash := make(map[string]*Commit)
+	for i := range commits {
+		byHash[commits[i].Hash] = &commits[i]
+	}
+	if c := byHash[gpgHash]; !c.Signed || c.SignatureType != SignatureGPG {
+		t.Errorf("Expected GPG signature detected, got signed=%v type=%q", c.Signed, c.SignatureType)
+	}
+	if c := byHash[sshHash]; !c.Signed || c.SignatureType != SignatureSSH {
+		t.Errorf("Expected SSH signature detected, got signed=%v type=%q", c.Signed, c.SignatureType)
+	}
+	if byHash[unsignedHash].Signed {
+		t.Error("Expected plain commit to be unsigned")
+	}
+
+	// Build trust: armored public key ring and allowed signers file
+	var keyring bytes.Buffer
+	w, _ := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("Failed to serialize key: %v", err)
+	}
+	w.Close()
+
+	verifier := NewSignatureVerifier()
+	if err := verifier.AddPGPKeyRing(&keyring); err != nil {
+		t.Fatalf("Failed to add key ring: %v", err)
+	}
+	allowed := `alice@example.com namespaces="git" ` + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshSigner.PublicKey())))
+	if err := verifier.AddAllowedSigners(strings.NewReader("# trusted\n" + allowed + "\n")); err != nil {
+		t.Fatalf("Failed to add allowed signers: %v", err)
+	}
+
+	if err := VerifySignatures(ctx, repo, commits, verifier); err != nil {
+		t.Fatalf("Failed to verify signatures: %v", err)
+	}
+
+	if c := byHash[gpgHash]; !c.SignatureValid || !strings.Contains(c.Signer, "alice@example.com") || c.SignerKeyID == "" {
+		t.Errorf("Expected valid GPG signature, got %+v", c)
+	}
+	if c := byHash[sshHash]; !c.SignatureValid || c.Signer != "alice@example.com" || !strings.HasPrefix(c.SignerKeyID, "SHA256:") {
+		t.Errorf("Expected valid SSH signature, got valid=%v signer=%q key=%q", c.SignatureValid, c.Signer, c.SignerKeyID)
+	}
+	if c := byHash[untrustedHash]; c.SignatureValid {
+		t.Error("Expected untrusted SSH key to be invalid")
+	}
+	if byHash[unsignedHash].SignatureValid {
+		t.Error("Expected unsigned commit to be invalid")
+	}
+}
+
+func TestSignatureType(t *testing.T) {
+	tests := map[string]string{
+		"": "",
+		"-----BEGIN PGP SIGNATURE-----\nabc\n-----END PGP SIGNATURE-----": SignatureGPG,
+		"-----BEGIN SSH SIGNATURE-----\nabc\n-----END SSH SIGNATURE-----": SignatureSSH,
+		"-----BEGIN SIGNED MESSAGE-----\nabc":                             SignatureX509,
+		"garbage":                                                         SignatureUnknown,
+	}
+	for signature, expected := range tests {
+		if got := signatureType(signature); got != expected {
+			t.Errorf("signatureType(%q) = %q, expected %q", signature, got, expected)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// GitModulesFile is the file declaring a repository's submodules
+const GitModulesFile = ".gitmodules"
+
+// ParseSubmodules extracts the submodules declared in .gitmodules at HEAD, sorted by path
+// Each submodule records the commit pinned in the HEAD tree
+// Returns an empty slice if the repository has no HEAD or no .gitmodules
+func ParseSubmodules(repo *git.Repository) ([]Submodule, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return make([]Submodule, 0), nil
+		}
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	return ParseSubmodulesAt(repo, head.Hash().String())
+}
+
+// ParseSubmodulesAt extracts the submodules declared in .gitmodules at a commit, sorted by path
+// Each submodule records the commit pinned in that commit's tree
+// Returns an empty slice if the commit has no .gitmodules
+func ParseSubmodulesAt(repo *git.Repository, hash string) ([]Submodule, error) {
+	submodules := make([]Submodule, 0)
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+
+	file, err := commit.File(GitModulesFile)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return submodules, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", GitModulesFile, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", GitModulesFile, err)
+	}
+
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", GitModulesFile, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	for _, module := range modules.Submodules {
+		submodule := Submodule{
+			Name:   module.Name,
+			Path:   module.Path,
+			URL:    module.URL,
+			Branch: module.Branch,
+		}
+
+		// Declared but never committed submodules have no gitlink entry
+		if entry, err := tree.FindEntry(module.Path); err == nil && entry.Mode == filemode.Submodule {
+			submodule.Hash = entry.Hash.String()
+		}
+
+		submodules = append(submodules, submodule)
+	}
+
+	sort.Slice(submodules, func(i, j int) bool {
+		return submodules[i].Path < submodules[j].Path
+	})
+
+	return submodules, nil
+}
+
+// ResolveSubmoduleURL resolves a submodule URL against its superproject's URL
+// Relative URLs ("./lib.git", "../lib.git") are resolved as git does, for
+// HTTPS, scp-style SSH, and local path parents; absolute URLs are returned unchanged
+func ResolveSubmoduleURL(parentURL, submoduleURL string) string {
+	if !strings.HasPrefix(submoduleURL, "./") && !strings.HasPrefix(submoduleURL, "../") {
+		return submoduleURL
+	}
+
+	base := strings.TrimSuffix(parentURL, "/")
+	rel := submoduleURL
+	sep := "/"
+
+	for {
+		switch {
+		case strings.HasPrefix(rel, "./"):
+			rel = rel[2:]
+		case strings.HasPrefix(rel, "../"):
+			rel = rel[3:]
+			// Drop the last path component; scp-style URLs separate the host with ':'
+			if idx := strings.LastIndexAny(base, "/:"); idx >= 0 {
+				sep = string(base[idx])
+				base = base[:idx]
+			}
+		default:
+			return base + sep + rel
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+)
+
+// addTestSubmodule declares a submodule in .gitmodules and stages a gitlink pinned to the source's HEAD
+// go-git cannot stage gitlinks through the worktree, so the index entry is written directly
+func addTestSubmodule(t testing.TB, repo *git.Repository, dir, subPath, sourceDir string) string {
+	t.Helper()
+
+	source, err := git.PlainOpen(sourceDir)
+	if err != nil {
+		t.Fatalf("Failed to open submodule source: %v", err)
+	}
+	head, err := source.Head()
+	if err != nil {
+		t.Fatalf("Failed to get submodule HEAD: %v", err)
+	}
+
+	if _, err := git.PlainClone(filepath.Join(dir, subPath), &git.CloneOptions{URL: sourceDir}); err != nil {
+		t.Fatalf("Failed to check out submodule: %v", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	entry := idx.Add(subPath)
+	entry.Hash = head.Hash()
+	entry.Mode = filemode.Submodule
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+
+	modules := "[submodule \"" + subPath + "\"]\n\tpath = " + subPath + "\n\turl = " + sourceDir + "\n"
+	if err := os.WriteFile(filepath.Join(dir, GitModulesFile), []byte(modules), 0o644); err != nil {
+		t.Fatalf("Failed to write .gitmodules: %v", err)
+	}
+
+	return head.Hash().String()
+}
+
+func TestParseSubmodules(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	libDir, libRepo := createTestRepository(t)
+	commitTestFile(t, libRepo, libDir, "lib.go", "package lib\n", "Add lib", baseTime)
+
+	dir, repo := createTestRepository(t)
+	initial := commitTestFile(t, repo, dir, "main.go", "package main\n", "Initial commit", baseTime)
+
+	// No .gitmodules yet
+	submodules, err := ParseSubmodules(repo)
+	if err != nil {
+		t.Fatalf("Failed to parse submodules: %v", err)
+	}
+	if len(submodules) != 0 {
+		t.Errorf("Expected no submodules, got %d", len(submodules))
+	}
+
+	pinned := addTestSubmodule(t, repo, dir, "libs/lib", libDir)
+	wt, _ := repo.Worktree()
+	if _, err := wt.Add(GitModulesFile); err != nil {
+		t.Fatalf("Failed to stage .gitmodules: %v", err)
+	}
+	commitTestFile(t, repo, dir, "go.mod", "module app\n", "Add lib submodule", baseTime.Add(time.Hour))
+
+	repoData, err := ParseRepository(context.Background(), repo, dir, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to parse repository: %v", err)
+	}
+	if len(repoData.Submodules) != 1 {
+		t.Fatalf("Expected 1 submodule, got %d", len(repoData.Submodules))
+	}
+
+	submodule := repoData.Submodules[0]
+	if submodule.Path != "libs/lib" || submodule.URL != libDir {
+		t.Errorf("Unexpected submodule: %+v", submodule)
+	}
+	if submodule.Hash != pinned {
+		t.Errorf("Expected pinned commit %s, got %s", pinned, submodule.Hash)
+	}
+
+	// An earlier revision is parsed as the head, with the submodules it pins
+	opts := DefaultParseOptions()
+	opts.Revision = initial
+	earlier, err := ParseRepositoryWithOptions(context.Background(), repo, dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse repository at %s: %v", initial, err)
+	}
+	if len(earlier.Commits) != 1 || earlier.HeadHash != initial || len(earlier.Submodules) != 0 {
+		t.Errorf("Expected 1 commit, head %s and no submodules, got %d commits, head %s and %d submodules",
+			initial, len(earlier.Commits), earlier.HeadHash, len(earlier.Submodules))
+	}
+	if earlier.HeadBranch != repoData.HeadBranch {
+		t.Errorf("Expected the default branch %q kept, got %q", repoData.HeadBranch, earlier.HeadBranch)
+	}
+}
+
+func TestResolveSubmoduleURL(t *testing.T) {
+	tests := []struct {
+		parent   string
+		url      string
+		expected string
+	}{
+		{"https://github.com/acme/app.git", "https://github.com/acme/lib.git", "https://github.com/acme/lib.git"},
+		{"https://github.com/acme/app.git", "../lib.git", "https://github.com/acme/lib.git"},
+		{"https://github.com/acme/app", "../../other/lib.git", "https://github.com/other/lib.git"},
+		{"https://github.com/acme/app.git", "./lib.git", "https://github.com/acme/app.git/lib.git"},
+		{"git@github.com:acme/app.git", "../lib.git", "git@github.com:acme/lib.git"},
+		{"git@github.com:acme/app.git", "../../other/lib.git", "git@github.com:other/lib.git"},
+		{"/srv/repos/app", "../lib", "/srv/repos/lib"},
+	}
+
+	for _, tt := range tests {
+		if got := ResolveSubmoduleURL(tt.parent, tt.url); got != tt.expected {
+			t.Errorf("ResolveSubmoduleURL(%q, %q) = %q, expected %q", tt.parent, tt.url, got, tt.expected)
+		}
+	}
+}
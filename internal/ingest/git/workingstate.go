@@ -0,0 +1,363 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// WorkingState captures work in a local clone that is not on the remote yet
+type WorkingState struct {
+	Branch       string    `json:"branch,omitempty"` // Checked-out branch ("" when HEAD is detached)
+	HeadHash     string    `json:"head_hash,omitempty"`
+	Upstream     string    `json:"upstream,omitempty"` // Remote-tracking branch local commits are compared against
+	Author       Author    `json:"author"`             // Configured user, who owns the uncommitted changes
+	Staged       []Diff    `json:"staged,omitempty"`   // Index changes relative to HEAD
+	Unstaged     []Diff    `json:"unstaged,omitempty"` // Worktree changes relative to the index
+	Untracked    []string  `json:"untracked,omitempty"`
+	LocalCommits []Commit  `json:"local_commits,omitempty"` // Commits on HEAD not on the upstream, newest first
+	CapturedAt   time.Time `json:"captured_at"`
+}
+
+// IsClean reports whether there is no local-only work at all
+func (w *WorkingState) IsClean() bool {
+	return len(w.Staged) == 0 && len(w.Unstaged) == 0 && len(w.Untracked) == 0 && len(w.LocalCommits) == 0
+}
+
+// HasUncommittedChanges reports whether the index or worktree differ from HEAD
+func (w *WorkingState) HasUncommittedChanges() bool {
+	return len(w.Staged) > 0 || len(w.Unstaged) > 0 || len(w.Untracked) > 0
+}
+
+// UncommittedHash is the placeholder hash of the synthetic commit returned by UncommittedCommit
+const UncommittedHash = "uncommitted"
+
+// UncommittedCommit represents staged, unstaged and untracked changes as a single synthetic commit
+// so they can flow through clustering and prompts like real history
+func (w *WorkingState) UncommittedCommit() Commit {
+	diffs := make([]Diff, 0, len(w.Staged)+len(w.Unstaged)+len(w.Untracked))
+	diffs = append(diffs, w.Staged...)
+	diffs = append(diffs, w.Unstaged...)
+	for _, path := range w.Untracked {
+		diffs = append(diffs, Diff{
+			FilePath: path,
+			Status:   "added",
+			FileType: getFileType(path),
+			Language: DetectLanguage(path, ""),
+		})
+	}
+
+	var sections []string
+	for _, group := range []struct {
+		label string
+		paths []string
+	}{
+		{"Staged", diffPaths(w.Staged)},
+		{"Unstaged", diffPaths(w.Unstaged)},
+		{"Untracked", w.Untracked},
+	} {
+		if len(group.paths) > 0 {
+			sections = append(sections, fmt.Sprintf("%s: %s", group.label, strings.Join(group.paths, ", ")))
+		}
+	}
+
+	subject := "Uncommitted changes"
+	if w.Branch != "" {
+		subject = fmt.Sprintf("Uncommitted changes on %s", w.Branch)
+	}
+	body := strings.Join(sections, "\n")
+
+	commit := Commit{
+		Hash:           UncommittedHash,
+		ShortHash:      UncommittedHash,
+		Author:         w.Author,
+		Committer:      w.Author,
+		Message:        subject + "\n\n" + body,
+		MessageSubject: subject,
+		MessageBody:    body,
+		CommittedAt:    w.CapturedAt,
+		Diffs:          diffs,
+		Stats:          calculateStats(diffs),
+		Languages:      commitLanguages(diffs),
+	}
+	if w.HeadHash != "" {
+		commit.ParentHashes = []string{w.HeadHash}
+	}
+	return commit
+}
+
+// diffPaths lists the file paths of a set of diffs
+func diffPaths(diffs []Diff) []string {
+	paths := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		paths = append(paths, diff.FilePath)
+	}
+	return paths
+}
+
+// ParseWorkingState captures staged and unstaged changes, untracked files and local-only commits
+// Local commits are those not reachable from HEAD's upstream branch, or from any remote-tracking
+// branch when no upstream is configured. Requires a repository with a worktree
+func ParseWorkingState(ctx context.Context, repo *git.Repository) (*WorkingState, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	state := &WorkingState{
+		Author:     configuredUser(repo),
+		CapturedAt: time.Now(),
+	}
+
+	var headTree *object.Tree
+	head, err := repo.Head()
+	switch {
+	case err == nil:
+		state.HeadHash = head.Hash().String()
+		if head.Name().IsBranch() {
+			state.Branch = head.Name().Short()
+		}
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+		}
+		if headTree, err = commit.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
+		}
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	if err := parseUncommittedChanges(repo, wt, headTree, state); err != nil {
+		return nil, err
+	}
+
+	if head != nil {
+		if err := parseLocalCommits(ctx, repo, head, state); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// parseUncommittedChanges fills in staged, unstaged and untracked files from the worktree status
+func parseUncommittedChanges(repo *git.Repository, wt *git.Worktree, headTree *object.Tree, state *WorkingState) error {
+	status, err := wt.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	paths := make([]string, 0, len(status))
+	for path := range status {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Readers for each side of a change; ok is false when the file does not exist there
+	headContent := func(path string) (string, bool) {
+		file := treeFile(headTree, path)
+		if file == nil {
+			return "", false
+		}
+		content, err := file.Contents()
+		return content, err == nil
+	}
+	indexContent := func(path string) (string, bool) {
+		entry, err := idx.Entry(path)
+		if err != nil {
+			return "", false
+		}
+		blob, err := repo.BlobObject(entry.Hash)
+		if err != nil {
+			return "", false
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return "", false
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		return string(data), err == nil
+	}
+	worktreeContent := func(path string) (string, bool) {
+		file, err := wt.Filesystem.Open(path)
+		if err != nil {
+			return "", false
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		return string(data), err == nil
+	}
+
+	for _, path := range paths {
+		fileStatus := status[path]
+
+		if fileStatus.Worktree == git.Untracked {
+			state.Untracked = append(state.Untracked, path)
+			continue
+		}
+
+		if fileStatus.Staging != git.Unmodified {
+			from, fromOK := headContent(path)
+			to, toOK := indexContent(path)
+			state.Staged = append(state.Staged, workingDiff(path, from, fromOK, to, toOK))
+		}
+
+		if fileStatus.Worktree != git.Unmodified {
+			from, fromOK := indexContent(path)
+			to, toOK := worktreeContent(path)
+			state.Unstaged = append(state.Unstaged, workingDiff(path, from, fromOK, to, toOK))
+		}
+	}
+
+	return nil
+}
+
+// workingDiff builds a Diff between two versions of an uncommitted file
+func workingDiff(path, from string, fromOK bool, to string, toOK bool) Diff {
+	d := Diff{
+		FilePath: path,
+		FileType: getFileType(path),
+	}
+
+	switch {
+	case !fromOK:
+		d.Status = "added"
+	case !toOK:
+		d.Status = "deleted"
+	default:
+		d.Status = "modified"
+	}
+
+	content := to
+	if !toOK {
+		content = from
+	}
+	d.SizeBytes = int64(len(content))
+	d.IsBinary = isBinaryContent(from) || isBinaryContent(to)
+	d.Language = DetectLanguage(path, content)
+
+	if !d.IsBinary {
+		d.Additions, d.Deletions = countLineChanges(from, to)
+	}
+	return d
+}
+
+// countLineChanges counts added and deleted lines between two texts
+func countLineChanges(from, to string) (additions, deletions int) {
+	for _, chunk := range diff.Do(from, to) {
+		lines := strings.Count(chunk.Text, "\n")
+		if chunk.Text != "" && !strings.HasSuffix(chunk.Text, "\n") {
+			lines++
+		}
+		switch chunk.Type {
+		case diffmatchpatch.DiffInsert:
+			additions += lines
+		case diffmatchpatch.DiffDelete:
+			deletions += lines
+		}
+	}
+	return additions, deletions
+}
+
+// isBinaryContent applies git's heuristic: a NUL byte within the first 8000 bytes
+func isBinaryContent(content string) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return strings.IndexByte(content, 0) >= 0
+}
+
+// parseLocalCommits finds commits on HEAD that are not on the remote yet
+func parseLocalCommits(ctx context.Context, repo *git.Repository, head *plumbing.Reference, state *WorkingState) error {
+	tips, upstream, err := remoteTips(repo, head)
+	if err != nil {
+		return err
+	}
+	state.Upstream = upstream
+
+	pushed, err := buildBranchGraph(ctx, repo, tips)
+	if err != nil {
+		return fmt.Errorf("failed to walk remote branches: %w", err)
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return fmt.Errorf("failed to get log: %w", err)
+	}
+
+	objects := make([]*object.Commit, 0)
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := pushed.commits[c.Hash]; !ok {
+			objects = append(objects, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to iterate commits: %w", err)
+	}
+
+	state.LocalCommits, err = parseCommitObjects(ctx, repo, objects, DefaultParseOptions())
+	return err
+}
+
+// remoteTips returns the remote-tracking branches that count as pushed work
+// Uses HEAD's upstream when one exists, otherwise every remote-tracking branch
+func remoteTips(repo *git.Repository, head *plumbing.Reference) ([]Branch, string, error) {
+	if head.Name().IsBranch() {
+		candidates := make([]plumbing.ReferenceName, 0, 2)
+		if cfg, err := repo.Config(); err == nil {
+			if branch, ok := cfg.Branches[head.Name().Short()]; ok && branch.Remote != "" && branch.Merge != "" {
+				candidates = append(candidates, plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short()))
+			}
+		}
+		candidates = append(candidates, plumbing.NewRemoteReferenceName("origin", head.Name().Short()))
+
+		for _, name := range candidates {
+			if ref, err := repo.Reference(name, true); err == nil {
+				return []Branch{{Name: name.Short(), Hash: ref.Hash().String(), IsRemote: true}}, name.Short(), nil
+			}
+		}
+	}
+
+	branches, err := ParseBranches(repo)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse branches: %w", err)
+	}
+	remotes := make([]Branch, 0)
+	for _, branch := range branches {
+		if branch.IsRemote {
+			remotes = append(remotes, branch)
+		}
+	}
+	return remotes, "", nil
+}
+
+// configuredUser returns the user.name and user.email git would commit with
+func configuredUser(repo *git.Repository) Author {
+	cfg, err := repo.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return Author{}
+	}
+	return Author{Name: cfg.User.Name, Email: cfg.User.Email}
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+)
+
+func TestParseWorkingState(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	sourceDir, source := createTestRepository(t)
+	commitTestFile(t, source, sourceDir, "main.go", "package main\n", "Initial commit", baseTime)
+	commitTestFile(t, source, sourceDir, "util.go", "package main\n\nfunc util() {}\n", "Add util", baseTime.Add(time.Hour))
+
+	dir := t.TempDir()
+	repo, err := git.PlainClone(dir, &git.CloneOptions{URL: sourceDir})
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	// A freshly cloned repository has no local-only work
+	state, err := ParseWorkingState(ctx, repo)
+	if err != nil {
+		t.Fatalf("Failed to parse working state: %v", err)
+	}
+	if !state.IsClean() {
+		t.Fatalf("Expected clean state, got %+v", state)
+	}
+	if state.Upstream != "origin/master" {
+		t.Errorf("Expected upstream origin/master, got %q", state.Upstream)
+	}
+
+	local := commitTestFile(t, repo, dir, "local.go", "package main\n", "Local work", baseTime.Add(2*time.Hour))
+
+	// Staged change to util.go, then a further unstaged edit on top
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	writeFile("util.go", "package main\n\nfunc util() {}\n\nfunc helper() {}\n")
+	if _, err := wt.Add("util.go"); err != nil {
+		t.Fatalf("Failed to stage util.go: %v", err)
+	}
+	writeFile("util.go", "package main\n\nfunc util() {}\n")
+	writeFile("notes.sql", "SELECT 1;\n")
+
+	state, err = ParseWorkingState(ctx, repo)
+	if err != nil {
+		t.Fatalf("Failed to parse working state: %v", err)
+	}
+
+	if state.Branch != "master" || state.HeadHash != local {
+		t.Errorf("Unexpected HEAD: branch %q hash %s", state.Branch, state.HeadHash)
+	}
+	if len(state.LocalCommits) != 1 || state.LocalCommits[0].Hash != local {
+		t.Fatalf("Expected only the local commit, got %d commits", len(state.LocalCommits))
+	}
+	if len(state.Staged) != 1 || state.Staged[0].FilePath != "util.go" || state.Staged[0].Additions != 2 {
+		t.Errorf("Unexpected staged changes: %+v", state.Staged)
+	}
+	if len(state.Unstaged) != 1 || state.Unstaged[0].Deletions != 2 || state.Unstaged[0].Language != "Go" {
+		t.Errorf("Unexpected unstaged changes: %+v", state.Unstaged)
+	}
+	if len(state.Untracked) != 1 || state.Untracked[0] != "notes.sql" {
+		t.Errorf("Unexpected untracked files: %v", state.Untracked)
+	}
+
+	commit := state.UncommittedCommit()
+	if commit.Hash != UncommittedHash || len(commit.Diffs) != 3 {
+		t.Errorf("Unexpected uncommitted commit: %+v", commit)
+	}
+	if !strings.Contains(commit.Message, "Staged: util.go") || !strings.Contains(commit.Message, "Untracked: notes.sql") {
+		t.Errorf("Unexpected uncommitted commit message: %q", commit.Message)
+	}
+	if commit.Languages["SQL"] != 1 {
+		t.Errorf("Expected untracked SQL file in languages, got %v", commit.Languages)
+	}
+}
+
+func TestParseWorkingState_NoRemote(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir, repo := createTestRepository(t)
+	commitTestFile(t, repo, dir, "main.go", "package main\n", "Initial commit", baseTime)
+
+	// Without any remote, all history is local
+	state, err := ParseWorkingState(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Failed to parse working state: %v", err)
+	}
+	if state.Upstream != "" || len(state.LocalCommits) != 1 {
+		t.Errorf("Expected 1 local commit and no upstream, got %d (%q)", len(state.LocalCommits), state.Upstream)
+	}
+	if state.HasUncommittedChanges() {
+		t.Errorf("Expected no uncommitted changes, got %+v", state)
+	}
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WorkPattern summarizes when and how often an author commits
+// Times are taken in the author's own time zone, as recorded in each commit
+type WorkPattern struct {
+	Author           Author        `json:"author"`
+	Commits          int           `json:"commits"`
+	FirstCommit      time.Time     `json:"first_commit"`
+	LastCommit       time.Time     `json:"last_commit"`
+	HourHistogram    [24]int       `json:"hour_histogram"`    // Commits per hour of day (0-23)
+	WeekdayHistogram [7]int        `json:"weekday_histogram"` // Commits per weekday (Sunday = 0)
+	ActiveDays       int           `json:"active_days"`       // Distinct calendar days with at least one commit
+	CommitsPerDay    float64       `json:"commits_per_day"`   // Average commits per active day
+	LongestStreak    int           `json:"longest_streak"`    // Most consecutive active days
+	MedianGap        time.Duration `json:"median_gap"`        // Typical time between consecutive commits
+	P90Gap           time.Duration `json:"p90_gap"`
+
+	// Sorted gaps between consecutive commits, overall and within one calendar day, used for percentile queries
+	gaps    []time.Duration
+	dayGaps []time.Duration
+}
+
+// GapCount returns the number of gaps between consecutive commits (Commits - 1)
+func (p *WorkPattern) GapCount() int {
+	return len(p.gaps)
+}
+
+// GapPercentile returns the gap below which the given fraction (0-1) of the author's gaps fall
+// Uses nearest-rank; returns 0 for authors with a single commit
+func (p *WorkPattern) GapPercentile(q float64) time.Duration {
+	return percentile(p.gaps, q)
+}
+
+// percentile returns the nearest-rank percentile of sorted gaps, or 0 when there are none
+func percentile(gaps []time.Duration, q float64) time.Duration {
+	if len(gaps) == 0 {
+		return 0
+	}
+	q = math.Max(0, math.Min(1, q))
+	rank := int(math.Ceil(q*float64(len(gaps)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return gaps[rank]
+}
+
+// SameDayGapCount returns the number of gaps between consecutive commits made on the same calendar day
+func (p *WorkPattern) SameDayGapCount() int {
+	return len(p.dayGaps)
+}
+
+// SameDayGapPercentile returns the gap below which the given fraction (0-1) of the author's same-day
+// gaps fall, describing their pace within a working day; returns 0 without same-day gaps
+func (p *WorkPattern) SameDayGapPercentile(q float64) time.Duration {
+	return percentile(p.dayGaps, q)
+}
+
+// PeakHour returns the hour of day with the most commits (earliest hour on ties)
+func (p *WorkPattern) PeakHour() int {
+	peak := 0
+	for hour, count := range p.HourHistogram {
+		if count > p.HourHistogram[peak] {
+			peak = hour
+		}
+	}
+	return peak
+}
+
+// AuthorKey returns the identity used to group an author's commits: the lowercased email,
+// falling back to the lowercased name when no email is recorded
+func AuthorKey(author Author) string {
+	if email := normalizeEmail(author.Email); email != "" {
+		return email
+	}
+	return strings.ToLower(strings.TrimSpace(author.Name))
+}
+
+// AnalyzeWorkPatterns computes per-author activity metrics keyed by AuthorKey
+// Resolve identities with a mailmap first so aliases are counted as one author
+func AnalyzeWorkPatterns(commits []Commit) map[string]*WorkPattern {
+	byAuthor := make(map[string][]time.Time)
+	authors := make(map[string]Author)
+
+	for _, commit := range commits {
+		key := AuthorKey(commit.Author)
+		when := commit.Author.When
+		if when.IsZero() {
+			when = commit.CommittedAt
+		}
+		byAuthor[key] = append(byAuthor[key], when)
+		if _, ok := authors[key]; !ok {
+			authors[key] = commit.Author
+		}
+	}
+
+	patterns := make(map[string]*WorkPattern, len(byAuthor))
+	for key, times := range byAuthor {
+		patterns[key] = newWorkPattern(authors[key], times)
+	}
+	return patterns
+}
+
+// newWorkPattern computes the metrics for one author's commit times
+func newWorkPattern(author Author, times []time.Time) *WorkPattern {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	pattern := &WorkPattern{
+		Author:      author,
+		Commits:     len(times),
+		FirstCommit: times[0],
+		LastCommit:  times[len(times)-1],
+		gaps:        make([]time.Duration, 0, len(times)-1),
+	}
+
+	days := make(map[time.Time]bool)
+	for i, t := range times {
+		pattern.HourHistogram[t.Hour()]++
+		pattern.WeekdayHistogram[t.Weekday()]++
+		days[time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)] = true
+
+		if i > 0 {
+			gap := t.Sub(times[i-1])
+			pattern.gaps = append(pattern.gaps, gap)
+			if previous := times[i-1]; previous.YearDay() == t.YearDay() && previous.Year() == t.Year() {
+				pattern.dayGaps = append(pattern.dayGaps, gap)
+			}
+		}
+	}
+
+	pattern.ActiveDays = len(days)
+	pattern.CommitsPerDay = float64(pattern.Commits) / float64(pattern.ActiveDays)
+	pattern.LongestStreak = longestStreak(days)
+
+	sort.Slice(pattern.gaps, func(i, j int) bool { return pattern.gaps[i] < pattern.gaps[j] })
+	sort.Slice(pattern.dayGaps, func(i, j int) bool { return pattern.dayGaps[i] < pattern.dayGaps[j] })
+	pattern.MedianGap = pattern.GapPercentile(0.5)
+	pattern.P90Gap = pattern.GapPercentile(0.9)
+
+	return pattern
+}
+
+// longestStreak returns the most consecutive calendar days present in days
+func longestStreak(days map[time.Time]bool) int {
+	longest := 0
+	for day := range days {
+		// Only count from the first day of each run
+		if days[day.AddDate(0, 0, -1)] {
+			continue
+		}
+		length := 1
+		for days[day.AddDate(0, 0, length)] {
+			length++
+		}
+		if length > longest {
+			longest = length
+		}
+	}
+	return longest
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyzeWorkPatterns(t *testing.T) {
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	alice := Author{Name: "Alice", Email: "alice@example.com"}
+	aliceAlias := Author{Name: "alice", Email: "Alice@Example.com"}
+	bob := Author{Name: "Bob", Email: "bob@example.com"}
+
+	// Alice commits on three consecutive days, then once more a week later, at 9-10am local time
+	times := []time.Time{
+		time.Date(2025, 3, 3, 9, 0, 0, 0, zone),
+		time.Date(2025, 3, 3, 9, 30, 0, 0, zone),
+		time.Date(2025, 3, 4, 10, 0, 0, 0, zone),
+		time.Date(2025, 3, 5, 9, 15, 0, 0, zone),
+		time.Date(2025, 3, 12, 9, 45, 0, 0, zone),
+	}
+	commits := make([]Commit, 0)
+	for i, when := range times {
+		author := alice
+		if i%2 == 1 {
+			author = aliceAlias
+		}
+		author.When = when
+		commits = append(commits, Commit{Author: author, CommittedAt: when})
+	}
+	commits = append(commits, Commit{Author: bob, CommittedAt: time.Date(2025, 3, 4, 22, 0, 0, 0, time.UTC)})
+
+	patterns := AnalyzeWorkPatterns(commits)
+	if len(patterns) != 2 {
+		t.Fatalf("Expected 2 authors, got %d", len(patterns))
+	}
+
+	p := patterns["alice@example.com"]
+	if p == nil {
+		t.Fatal("Expected pattern keyed by lowercased email")
+	}
+	if p.Commits != 5 || p.ActiveDays != 4 || p.LongestStreak != 3 {
+		t.Errorf("Unexpected counts: commits=%d activeDays=%d streak=%d", p.Commits, p.ActiveDays, p.LongestStreak)
+	}
+	if p.PeakHour() != 9 {
+		t.Errorf("Expected peak hour 9 in the author's time zone, got %d", p.PeakHour())
+	}
+	if p.WeekdayHistogram[time.Monday] != 2 {
+		t.Errorf("Expected 2 Monday commits, got %d", p.WeekdayHistogram[time.Monday])
+	}
+	if p.CommitsPerDay != 1.25 {
+		t.Errorf("Expected 1.25 commits per active day, got %f", p.CommitsPerDay)
+	}
+	if p.GapCount() != 4 {
+		t.Fatalf("Expected 4 gaps, got %d", p.GapCount())
+	}
+	// Gaps: 30m, 23h15m, 24h30m, 7d30m (sorted)
+	if p.GapPercentile(0) != 30*time.Minute {
+		t.Errorf("Expected smallest gap 30m, got %v", p.GapPercentile(0))
+	}
+	if p.MedianGap != 23*time.Hour+15*time.Minute {
+		t.Errorf("Expected median gap 23h15m, got %v", p.MedianGap)
+	}
+	if p.P90Gap != 7*24*time.Hour+30*time.Minute {
+		t.Errorf("Expected p90 gap of a week, got %v", p.P90Gap)
+	}
+	// Only the 30m gap on March 3rd falls within one day
+	if p.SameDayGapCount() != 1 || p.SameDayGapPercentile(0.5) != 30*time.Minute {
+		t.Errorf("Expected one 30m same-day gap, got %d (median %v)", p.SameDayGapCount(), p.SameDayGapPercentile(0.5))
+	}
+
+	// Single-commit authors have no gaps; CommittedAt is used when the author time is missing
+	b := patterns["bob@example.com"]
+	if b.GapCount() != 0 || b.SameDayGapCount() != 0 || b.MedianGap != 0 || b.LongestStreak != 1 || b.PeakHour() != 22 {
+		t.Errorf("Unexpected single-commit pattern: %+v", b)
+	}
+}
+
+func TestAuthorKey(t *testing.T) {
+	if key := AuthorKey(Author{Name: "Alice", Email: " Alice@Example.COM "}); key != "alice@example.com" {
+		t.Errorf("Expected lowercased email, got %q", key)
+	}
+	if key := AuthorKey(Author{Name: "Alice"}); key != "alice" {
+		t.Errorf("Expected lowercased name fallback, got %q", key)
+	}
+}
@@ -0,0 +1,108 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v77/github"
+)
+
+// appJWTLifetime is how long a GitHub App JWT is valid (GitHub allows at most 10 minutes)
+const appJWTLifetime = 9 * time.Minute
+
+// InstallationToken is a short-lived access token for a GitHub App installation
+// Usable both for the REST API and as an HTTPS git credential
+type InstallationToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// CreateInstallationToken exchanges GitHub App credentials for an installation access token
+// privateKeyPEM is the App's private key as downloaded from GitHub (PKCS#1 or PKCS#8)
+func CreateInstallationToken(ctx context.Context, appID, installationID int64, privateKeyPEM []byte) (*InstallationToken, error) {
+	return createInstallationToken(ctx, github.NewClient(nil), appID, installationID, privateKeyPEM)
+}
+
+// createInstallationToken requests an installation token through the given client
+func createInstallationToken(ctx context.Context, client *github.Client, appID, installationID int64, privateKeyPEM []byte) (*InstallationToken, error) {
+	key, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt, err := signAppJWT(appID, key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	token, _, err := client.WithAuthToken(jwt).Apps.CreateInstallationToken(ctx, installationID, nil)
+	if err != nil {
+		return nil, handleAPIError(err, fmt.Sprintf("failed to create token for installation %d", installationID))
+	}
+
+	return &InstallationToken{
+		Token:     token.GetToken(),
+		ExpiresAt: token.GetExpiresAt().Time,
+	}, nil
+}
+
+// signAppJWT builds the RS256 JWT that authenticates as a GitHub App
+// Issued-at is backdated a minute to tolerate clock drift, as GitHub recommends
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey decodes a PEM-encoded RSA private key in PKCS#1 or PKCS#8 form
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode app private key: no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("failed to parse app private key: not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v77/github"
+)
+
+func generateTestKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestSignAppJWT(t *testing.T) {
+	key, _ := generateTestKey(t)
+	now := time.Unix(1700000000, 0)
+
+	jwt, err := signAppJWT(12345, key, now)
+	if err != nil {
+		t.Fatalf("Failed to sign JWT: %v", err)
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 JWT parts, got %d", len(parts))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("Failed to decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("Signature does not verify: %v", err)
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("Failed to decode claims: %v", err)
+	}
+	if claims["iss"] != "12345" {
+		t.Errorf("Expected iss 12345, got %v", claims["iss"])
+	}
+	if int64(claims["iat"].(float64)) != now.Add(-time.Minute).Unix() {
+		t.Errorf("Expected backdated iat, got %v", claims["iat"])
+	}
+}
+
+func TestCreateInstallationToken(t *testing.T) {
+	_, keyPEM := generateTestKey(t)
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/99/access_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_test","expires_at":%q}`, expires.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	token, err := createInstallationToken(context.Background(), client, 1, 99, keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+	if token.Token != "ghs_test" || !token.ExpiresAt.Equal(expires) {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+
+	if _, err := createInstallationToken(context.Background(), client, 1, 99, []byte("not a key")); err == nil {
+		t.Error("Expected error for invalid private key")
+	}
+}
@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/google/go-github/v77/github"
+)
+
+// closingKeywordRegex matches GitHub's closing keywords followed by a same-repository issue reference
+// e.g. "Fixes #12", "closes: #7", "Resolved #3"
+var closingKeywordRegex = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#(\d+)\b`)
+
+// ParseClosingReferences extracts the issue numbers a pull request body closes with keywords
+// Unlike ParseBodyReferences, plain mentions such as "see #12" are ignored
+func ParseClosingReferences(body string) []int {
+	var numbers []int
+	for _, match := range closingKeywordRegex.FindAllStringSubmatch(body, -1) {
+		if num, err := strconv.Atoi(match[1]); err == nil {
+			numbers = append(numbers, num)
+		}
+	}
+	return mergeIssueNumbers(numbers)
+}
+
+// closingIssuesNode is a pull request with the issues GitHub will close when it merges
+type closingIssuesNode struct {
+	Number                  int `json:"number"`
+	ClosingIssuesReferences struct {
+		Nodes []struct {
+			Number int `json:"number"`
+		} `json:"nodes"`
+	} `json:"closingIssuesReferences"`
+}
+
+// issueNumbers returns the node's closing issue numbers
+func (n closingIssuesNode) issueNumbers() []int {
+	numbers := make([]int, 0, len(n.ClosingIssuesReferences.Nodes))
+	for _, issue := range n.ClosingIssuesReferences.Nodes {
+		numbers = append(numbers, issue.Number)
+	}
+	return numbers
+}
+
+// closingIssuesQuery pages through every pull request's closing issue references
+// These include issues linked manually in the sidebar, which body parsing cannot see
+const closingIssuesQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(first: 100, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number
+        closingIssuesReferences(first: 50) { nodes { number } }
+      }
+    }
+  }
+}`
+
+// pullRequestClosingIssuesQuery fetches the closing issue references of one pull request
+const pullRequestClosingIssuesQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      number
+      closingIssuesReferences(first: 50) { nodes { number } }
+    }
+  }
+}`
+
+// ListClosingIssueReferences returns, per pull request number, the issues GitHub links as closed by it
+// Pull requests without closing references are omitted
+func ListClosingIssueReferences(ctx context.Context, client *github.Client, owner, repo string) (map[int][]int, error) {
+	closes := make(map[int][]int)
+	variables := map[string]any{"owner": owner, "name": repo}
+
+	for {
+		var data struct {
+			Repository struct {
+				PullRequests struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []closingIssuesNode `json:"nodes"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}
+		if err := queryGraphQL(ctx, client, closingIssuesQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to list closing issue references: %w", err)
+		}
+
+		prs := data.Repository.PullRequests
+		for _, node := range prs.Nodes {
+			if numbers := node.issueNumbers(); len(numbers) > 0 {
+				closes[node.Number] = numbers
+			}
+		}
+
+		if !prs.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = prs.PageInfo.EndCursor
+	}
+
+	return closes, nil
+}
+
+// ParseClosingIssues fetches the issues GitHub links as closed by a single pull request
+func ParseClosingIssues(ctx context.Context, client *github.Client, owner, repo string, number int) ([]int, error) {
+	var data struct {
+		Repository struct {
+			PullRequest *closingIssuesNode `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	variables := map[string]any{"owner": owner, "name": repo, "number": number}
+	if err := queryGraphQL(ctx, client, pullRequestClosingIssuesQuery, variables, &data); err != nil {
+		return nil, fmt.Errorf("failed to get closing issue references: %w", err)
+	}
+	if data.Repository.PullRequest == nil {
+		return nil, fmt.Errorf("failed to get closing issue references: pull request #%d not found", number)
+	}
+	return data.Repository.PullRequest.issueNumbers(), nil
+}
+
+// mergeIssueNumbers combines issue number lists into one sorted list without duplicates
+func mergeIssueNumbers(lists ...[]int) []int {
+	seen := make(map[int]bool)
+	merged := make([]int, 0)
+	for _, list := range lists {
+		for _, number := range list {
+			if !seen[number] {
+				seen[number] = true
+				merged = append(merged, number)
+			}
+		}
+	}
+	sort.Ints(merged)
+	return merged
+}
+
+// AddClosingIssues merges additional closing issue numbers into a pull request
+func (pr *PullRequest) AddClosingIssues(numbers []int) {
+	pr.ClosesIssues = mergeIssueNumbers(pr.ClosesIssues, numbers)
+}
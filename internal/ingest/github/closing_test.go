@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseClosingReferences(t *testing.T) {
+	tests := []struct {
+		body string
+		want []int
+	}{
+		{"Fixes #12, closes: #7, see #3", []int{7, 12}},
+		{"Resolved #4 and resolves #4", []int{4}},
+		{"Related to #9", []int{}},
+		{"", []int{}},
+	}
+
+	for _, tt := range tests {
+		got := ParseClosingReferences(tt.body)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseClosingReferences(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestListClosingIssueReferences(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if body.Variables["cursor"] == nil {
+			w.Write([]byte(`{"data": {"repository": {"pullRequests": {
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+				"nodes": [{"number": 5, "closingIssuesReferences": {"nodes": [{"number": 2}, {"number": 3}]}},
+					{"number": 6, "closingIssuesReferences": {"nodes": []}}]
+			}}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"repository": {"pullRequests": {
+			"pageInfo": {"hasNextPage": false},
+			"nodes": [{"number": 8, "closingIssuesReferences": {"nodes": [{"number": 1}]}}]
+		}}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	closes, err := ListClosingIssueReferences(context.Background(), client, "o", "r")
+	if err != nil {
+		t.Fatalf("ListClosingIssueReferences failed: %v", err)
+	}
+
+	want := map[int][]int{5: {2, 3}, 8: {1}}
+	if !reflect.DeepEqual(closes, want) {
+		t.Errorf("Expected %v, got %v", want, closes)
+	}
+}
+
+func TestAddClosingIssues(t *testing.T) {
+	pr := &PullRequest{ClosesIssues: []int{7}}
+	pr.AddClosingIssues([]int{3, 7})
+
+	if !reflect.DeepEqual(pr.ClosesIssues, []int{3, 7}) {
+		t.Errorf("Expected merged closing issues [3 7], got %v", pr.ClosesIssues)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"regexp"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/google/go-github/v77/github"
 	"github.com/joho/godotenv"
@@ -18,13 +20,18 @@ func init() {
 	_ = godotenv.Load("../../../.env")
 }
 
-// NewClient creates a GitHub API client with authentication
+// NewClient creates a GitHub API client with authentication that waits out rate limits
 // If token is empty, attempts to load from GITHUB_TOKEN environment variable
 func NewClient(token string) *github.Client {
+	return NewClientWithOptions(token, ClientOptions{})
+}
+
+// newClient creates an authenticated client on top of the given HTTP client
+func newClient(token string, httpClient *http.Client) *github.Client {
 	if token == "" {
 		token = os.Getenv("GITHUB_TOKEN")
 	}
-	return github.NewClient(nil).WithAuthToken(token)
+	return github.NewClient(httpClient).WithAuthToken(token)
 }
 
 // GetIssue fetches a GitHub issue with all comments and timeline
@@ -78,6 +85,11 @@ func GetPullRequest(ctx context.Context, client *github.Client, owner, repo stri
 	}
 	pr.ReviewComments = reviewComments
 
+	// Thread resolution is only available through GraphQL; comments stay unresolved without it
+	if threads, err := ParseReviewThreads(ctx, client, owner, repo, number); err == nil {
+		ApplyReviewThreads(pr.ReviewComments, threads)
+	}
+
 	// Get reviews
 	reviews, err := ParseReviews(ctx, client, owner, repo, number)
 	if err != nil {
@@ -85,6 +97,16 @@ func GetPullRequest(ctx context.Context, client *github.Client, owner, repo stri
 	}
 	pr.Reviews = reviews
 
+	// Get changed files and commits
+	if err := ParsePullRequestChanges(ctx, client, owner, repo, pr); err != nil {
+		return nil, err
+	}
+
+	// Issues linked in the sidebar only show up in GitHub's closing references
+	if closes, err := ParseClosingIssues(ctx, client, owner, repo, number); err == nil {
+		pr.AddClosingIssues(closes)
+	}
+
 	// Get timeline
 	timeline, err := ParseTimeline(ctx, client, owner, repo, number)
 	if err != nil {
@@ -206,6 +228,10 @@ func ParsePullRequest(ghPR *github.PullRequest) *PullRequest {
 		pr.Milestone = ParseMilestone(ghPR.Milestone)
 	}
 
+	if closes := ParseClosingReferences(pr.Description); len(closes) > 0 {
+		pr.ClosesIssues = closes
+	}
+
 	return pr
 }
 
@@ -297,6 +323,85 @@ func ParseReviews(ctx context.Context, client *github.Client, owner, repo string
 	return allReviews, nil
 }
 
+// ParsePullRequestChanges fills in the files and commits of a pull request
+func ParsePullRequestChanges(ctx context.Context, client *github.Client, owner, repo string, pr *PullRequest) error {
+	files, err := ParsePullRequestFiles(ctx, client, owner, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get files: %w", err)
+	}
+	pr.Files = files
+
+	commits, err := ParsePullRequestCommits(ctx, client, owner, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+	pr.CommitSHAs = commits
+
+	return nil
+}
+
+// ParsePullRequestFiles fetches the files changed by a PR with pagination
+// GitHub returns at most 3000 files per PR
+func ParsePullRequestFiles(ctx context.Context, client *github.Client, owner, repo string, number int) ([]PullRequestFile, error) {
+	var allFiles []PullRequestFile
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		files, resp, err := client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list pull request files")
+		}
+
+		for _, file := range files {
+			if file != nil {
+				allFiles = append(allFiles, PullRequestFile{
+					Filename:         file.GetFilename(),
+					PreviousFilename: file.GetPreviousFilename(),
+					Status:           file.GetStatus(),
+					Additions:        file.GetAdditions(),
+					Deletions:        file.GetDeletions(),
+				})
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allFiles, nil
+}
+
+// ParsePullRequestCommits fetches the SHAs of a PR's commits with pagination, oldest first
+// GitHub returns at most 250 commits per PR
+func ParsePullRequestCommits(ctx context.Context, client *github.Client, owner, repo string, number int) ([]string, error) {
+	var allSHAs []string
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		commits, resp, err := client.PullRequests.ListCommits(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list pull request commits")
+		}
+
+		for _, commit := range commits {
+			if sha := commit.GetSHA(); sha != "" {
+				allSHAs = append(allSHAs, sha)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allSHAs, nil
+}
+
 // ParseTimeline fetches timeline events for cross-references
 func ParseTimeline(ctx context.Context, client *github.Client, owner, repo string, number int) ([]TimelineEvent, error) {
 	var events []TimelineEvent
@@ -453,6 +558,17 @@ func ParseTimelineEvent(ghEvent *github.Timeline) TimelineEvent {
 		event.Actor = actor.GetLogin()
 	}
 
+	event.ID = ghEvent.GetID()
+	event.CommitID = ghEvent.GetCommitID()
+	event.Label = ghEvent.GetLabel().GetName()
+	event.Assignee = ghEvent.GetAssignee().GetLogin()
+	event.Milestone = ghEvent.GetMilestone().GetTitle()
+	event.Reviewer = ghEvent.GetReviewer().GetLogin()
+	if rename := ghEvent.GetRename(); rename != nil {
+		event.RenameFrom = rename.GetFrom()
+		event.RenameTo = rename.GetTo()
+	}
+
 	if ghEvent.GetEvent() == "cross-referenced" && ghEvent.Source != nil {
 		source := &TimelineSource{}
 
@@ -474,6 +590,28 @@ func ParseTimelineEvent(ghEvent *github.Timeline) TimelineEvent {
 	return event
 }
 
+// ParseIssueEvent converts a go-github IssueEvent from the repository-wide event feed to a TimelineEvent
+func ParseIssueEvent(ghEvent *github.IssueEvent) TimelineEvent {
+	event := TimelineEvent{
+		ID:        ghEvent.GetID(),
+		Event:     ghEvent.GetEvent(),
+		Actor:     ghEvent.GetActor().GetLogin(),
+		CreatedAt: ghEvent.GetCreatedAt().Time,
+		Label:     ghEvent.GetLabel().GetName(),
+		Assignee:  ghEvent.GetAssignee().GetLogin(),
+		Milestone: ghEvent.GetMilestone().GetTitle(),
+		Reviewer:  ghEvent.GetRequestedReviewer().GetLogin(),
+		CommitID:  ghEvent.GetCommitID(),
+	}
+
+	if rename := ghEvent.GetRename(); rename != nil {
+		event.RenameFrom = rename.GetFrom()
+		event.RenameTo = rename.GetTo()
+	}
+
+	return event
+}
+
 // extractCrossReferences extracts cross-references from timeline events
 func extractCrossReferences(timeline []TimelineEvent) []CrossRef {
 	var refs []CrossRef
@@ -494,6 +632,77 @@ func extractCrossReferences(timeline []TimelineEvent) []CrossRef {
 	return refs
 }
 
+// ParseRelease converts a go-github RepositoryRelease to our Release struct
+func ParseRelease(ghRelease *github.RepositoryRelease) *Release {
+	release := &Release{
+		ID:              ghRelease.GetID(),
+		TagName:         ghRelease.GetTagName(),
+		Name:            ghRelease.GetName(),
+		Body:            ghRelease.GetBody(),
+		TargetCommitish: ghRelease.GetTargetCommitish(),
+		Draft:           ghRelease.GetDraft(),
+		Prerelease:      ghRelease.GetPrerelease(),
+		CreatedAt:       ghRelease.GetCreatedAt().Time,
+		URL:             ghRelease.GetURL(),
+		HTMLURL:         ghRelease.GetHTMLURL(),
+	}
+
+	if author := ghRelease.GetAuthor(); author != nil {
+		release.Author = author.GetLogin()
+	}
+
+	if ghRelease.PublishedAt != nil {
+		publishedAt := ghRelease.GetPublishedAt().Time
+		release.PublishedAt = &publishedAt
+	}
+
+	release.Assets = make([]ReleaseAsset, 0, len(ghRelease.Assets))
+	for _, asset := range ghRelease.Assets {
+		if asset == nil {
+			continue
+		}
+		release.Assets = append(release.Assets, ReleaseAsset{
+			ID:            asset.GetID(),
+			Name:          asset.GetName(),
+			ContentType:   asset.GetContentType(),
+			Size:          asset.GetSize(),
+			DownloadCount: asset.GetDownloadCount(),
+			DownloadURL:   asset.GetBrowserDownloadURL(),
+		})
+	}
+
+	return release
+}
+
+// ParseRepository converts a go-github Repository to our Repository struct
+func ParseRepository(ghRepo *github.Repository) Repository {
+	repo := Repository{
+		ID:            ghRepo.GetID(),
+		Name:          ghRepo.GetName(),
+		FullName:      ghRepo.GetFullName(),
+		Owner:         ghRepo.GetOwner().GetLogin(),
+		Description:   ghRepo.GetDescription(),
+		DefaultBranch: ghRepo.GetDefaultBranch(),
+		Language:      ghRepo.GetLanguage(),
+		Topics:        ghRepo.Topics,
+		Private:       ghRepo.GetPrivate(),
+		Fork:          ghRepo.GetFork(),
+		Archived:      ghRepo.GetArchived(),
+		CloneURL:      ghRepo.GetCloneURL(),
+		HTMLURL:       ghRepo.GetHTMLURL(),
+	}
+
+	if repo.Topics == nil {
+		repo.Topics = []string{}
+	}
+	if ghRepo.PushedAt != nil {
+		pushedAt := ghRepo.GetPushedAt().Time
+		repo.PushedAt = &pushedAt
+	}
+
+	return repo
+}
+
 // handleAPIError wraps API errors with context and detects rate limiting
 func handleAPIError(err error, msg string) error {
 	if err == nil {
@@ -606,10 +815,16 @@ func ParseBodyReferences(body string) []int {
 // ListAllIssues fetches all issues from a repository with pagination
 // This includes both issues and pull requests (GitHub API returns both)
 func ListAllIssues(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Issue, error) {
+	return ListIssuesSince(ctx, client, owner, repo, time.Time{})
+}
+
+// ListIssuesSince fetches the issues of a repository updated at or after since (zero = all)
+func ListIssuesSince(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.Issue, error) {
 	var allIssues []*github.Issue
 
 	opts := &github.IssueListByRepoOptions{
 		State:       "all", // Get both open and closed
+		Since:       since,
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
@@ -632,12 +847,22 @@ func ListAllIssues(ctx context.Context, client *github.Client, owner, repo strin
 
 // ListAllPullRequests fetches all pull requests from a repository with pagination
 func ListAllPullRequests(ctx context.Context, client *github.Client, owner, repo string) ([]*github.PullRequest, error) {
+	return ListPullRequestsSince(ctx, client, owner, repo, time.Time{})
+}
+
+// ListPullRequestsSince fetches the pull requests of a repository updated at or after since (zero = all)
+// The pull request API has no since filter, so they are listed most recently updated first and
+// paging stops at the first one updated before since
+func ListPullRequestsSince(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.PullRequest, error) {
 	var allPRs []*github.PullRequest
 
 	opts := &github.PullRequestListOptions{
 		State:       "all", // Get both open and closed
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
+	if !since.IsZero() {
+		opts.Sort, opts.Direction = "updated", "desc"
+	}
 
 	for {
 		prs, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
@@ -645,6 +870,11 @@ func ListAllPullRequests(ctx context.Context, client *github.Client, owner, repo
 			return nil, handleAPIError(err, "failed to list pull requests")
 		}
 
+		for i, pr := range prs {
+			if !since.IsZero() && pr.GetUpdatedAt().Before(since) {
+				return append(allPRs, prs[:i]...), nil
+			}
+		}
 		allPRs = append(allPRs, prs...)
 
 		if resp.NextPage == 0 {
@@ -655,3 +885,84 @@ func ListAllPullRequests(ctx context.Context, client *github.Client, owner, repo
 
 	return allPRs, nil
 }
+
+// ListAllReleases fetches all releases from a repository with pagination
+// Drafts are only visible to tokens with push access
+func ListAllReleases(ctx context.Context, client *github.Client, owner, repo string) ([]*github.RepositoryRelease, error) {
+	var allReleases []*github.RepositoryRelease
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list releases")
+		}
+
+		allReleases = append(allReleases, releases...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allReleases, nil
+}
+
+// ListAllIssueEvents fetches the event history of every issue and PR in a repository with pagination
+// One paginated feed replaces a timeline request per issue; each event's Issue identifies its target
+func ListAllIssueEvents(ctx context.Context, client *github.Client, owner, repo string) ([]*github.IssueEvent, error) {
+	var allEvents []*github.IssueEvent
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		events, resp, err := client.Issues.ListRepositoryEvents(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list issue events")
+		}
+
+		allEvents = append(allEvents, events...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allEvents, nil
+}
+
+// FetchOrganizationRepos lists every repository in an organization visible to the client, sorted by name
+// Private repositories are only included when the token has access to them
+func FetchOrganizationRepos(ctx context.Context, client *github.Client, org string) ([]Repository, error) {
+	var repos []Repository
+
+	opts := &github.RepositoryListByOrgOptions{
+		Type:        "all",
+		Sort:        "full_name",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		page, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, handleAPIError(err, fmt.Sprintf("failed to list repositories for %s", org))
+		}
+
+		for _, ghRepo := range page {
+			if ghRepo != nil {
+				repos = append(repos, ParseRepository(ghRepo))
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+	return repos, nil
+}
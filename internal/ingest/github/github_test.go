@@ -2,8 +2,12 @@ package github
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v77/github"
 )
@@ -548,3 +552,164 @@ func TestRateLimitHandling(t *testing.T) {
 	}
 	t.Logf("Generic error handling: %v", genericErr)
 }
+
+func TestParseRelease(t *testing.T) {
+	published := github.Timestamp{Time: time.Date(2024, 2, 2, 15, 0, 0, 0, time.UTC)}
+	ghRelease := &github.RepositoryRelease{
+		ID:          github.Ptr(int64(1)),
+		TagName:     github.Ptr("v1.2.0"),
+		Name:        github.Ptr("Parser overhaul"),
+		Body:        github.Ptr("Faster parsing"),
+		Prerelease:  github.Ptr(false),
+		PublishedAt: &published,
+		Author:      &github.User{Login: github.Ptr("alice")},
+		Assets: []*github.ReleaseAsset{
+			{Name: github.Ptr("thunk.tar.gz"), Size: github.Ptr(2048), DownloadCount: github.Ptr(12)},
+			nil,
+		},
+	}
+
+	release := ParseRelease(ghRelease)
+
+	if release.TagName != "v1.2.0" || release.Name != "Parser overhaul" || release.Author != "alice" {
+		t.Errorf("Unexpected release fields: %+v", release)
+	}
+	if release.PublishedAt == nil || !release.PublishedAt.Equal(published.Time) {
+		t.Errorf("Expected published date %v, got %v", published.Time, release.PublishedAt)
+	}
+	if len(release.Assets) != 1 || release.Assets[0].Size != 2048 || release.Assets[0].DownloadCount != 12 {
+		t.Errorf("Unexpected assets: %+v", release.Assets)
+	}
+}
+
+func TestParseTimelineEvent_ProcessFields(t *testing.T) {
+	ghEvent := &github.Timeline{
+		ID:        github.Ptr(int64(9)),
+		Event:     github.Ptr("renamed"),
+		Actor:     &github.User{Login: github.Ptr("alice")},
+		Rename:    &github.Rename{From: github.Ptr("Old title"), To: github.Ptr("New title")},
+		Label:     &github.Label{Name: github.Ptr("bug")},
+		Milestone: &github.Milestone{Title: github.Ptr("v1.2")},
+		CommitID:  github.Ptr("abc123"),
+	}
+
+	event := ParseTimelineEvent(ghEvent)
+
+	if event.ID != 9 || event.Actor != "alice" || event.RenameFrom != "Old title" || event.RenameTo != "New title" {
+		t.Errorf("Unexpected rename fields: %+v", event)
+	}
+	if event.Label != "bug" || event.Milestone != "v1.2" || event.CommitID != "abc123" {
+		t.Errorf("Unexpected process fields: %+v", event)
+	}
+}
+
+func TestParseIssueEvent(t *testing.T) {
+	ghEvent := &github.IssueEvent{
+		ID:                github.Ptr(int64(5)),
+		Event:             github.Ptr("review_requested"),
+		Actor:             &github.User{Login: github.Ptr("alice")},
+		RequestedReviewer: &github.User{Login: github.Ptr("bob")},
+		CreatedAt:         &github.Timestamp{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	event := ParseIssueEvent(ghEvent)
+
+	if event.Event != "review_requested" || event.Actor != "alice" || event.Reviewer != "bob" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.Label != "" || event.Assignee != "" {
+		t.Errorf("Expected fields of other events to stay empty: %+v", event)
+	}
+}
+
+func TestParseRepository(t *testing.T) {
+	pushedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ghRepo := &github.Repository{
+		ID:            github.Ptr(int64(42)),
+		Name:          github.Ptr("thunk"),
+		FullName:      github.Ptr("Yates-Labs/thunk"),
+		Owner:         &github.User{Login: github.Ptr("Yates-Labs")},
+		DefaultBranch: github.Ptr("main"),
+		Archived:      github.Ptr(true),
+		PushedAt:      &github.Timestamp{Time: pushedAt},
+		CloneURL:      github.Ptr("https://github.com/Yates-Labs/thunk.git"),
+	}
+
+	repo := ParseRepository(ghRepo)
+
+	if repo.Name != "thunk" || repo.FullName != "Yates-Labs/thunk" || repo.Owner != "Yates-Labs" {
+		t.Errorf("Unexpected identity: %+v", repo)
+	}
+	if !repo.Archived || repo.Fork || repo.DefaultBranch != "main" {
+		t.Errorf("Unexpected flags: %+v", repo)
+	}
+	if repo.PushedAt == nil || !repo.PushedAt.Equal(pushedAt) {
+		t.Errorf("Expected pushed at %v, got %v", pushedAt, repo.PushedAt)
+	}
+	if repo.Topics == nil {
+		t.Error("Expected empty topics rather than nil")
+	}
+}
+
+func TestFetchOrganizationRepos(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/orgs/Yates-Labs/repos") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"name": "api", "full_name": "Yates-Labs/api"}]`))
+			return
+		}
+		w.Header().Set("Link", `<`+server.URL+`/api/v3/orgs/Yates-Labs/repos?page=2>; rel="next"`)
+		w.Write([]byte(`[{"name": "thunk", "full_name": "Yates-Labs/thunk"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	repos, err := FetchOrganizationRepos(context.Background(), client, "Yates-Labs")
+	if err != nil {
+		t.Fatalf("FetchOrganizationRepos failed: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Name != "api" || repos[1].Name != "thunk" {
+		t.Errorf("Expected both pages sorted by name, got %+v", repos)
+	}
+}
+
+func TestListPullRequestsSince_StopsAtOlderUpdates(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	var server *httptest.Server
+	pages := 0
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		if q := r.URL.Query(); q.Get("sort") != "updated" || q.Get("direction") != "desc" {
+			t.Errorf("Expected pull requests listed most recently updated first, got %q", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Link", `<`+server.URL+`/api/v3/repos/o/r/pulls?page=2>; rel="next"`)
+		w.Write([]byte(`[{"number": 2, "updated_at": "2025-07-01T00:00:00Z"}, {"number": 1, "updated_at": "2025-05-01T00:00:00Z"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	prs, err := ListPullRequestsSince(context.Background(), client, "o", "r", since)
+	if err != nil {
+		t.Fatalf("ListPullRequestsSince failed: %v", err)
+	}
+	if len(prs) != 1 || prs[0].GetNumber() != 2 {
+		t.Errorf("Expected only the pull request updated since, got %d", len(prs))
+	}
+	if pages != 1 {
+		t.Errorf("Expected paging to stop at the first older pull request, fetched %d pages", pages)
+	}
+}
@@ -0,0 +1,198 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// HTTPCacheDirEnv is the environment variable that overrides the default GitHub response cache directory
+const HTTPCacheDirEnv = "THUNK_GITHUB_CACHE_DIR"
+
+// headerFromCache marks responses served from the cache (go-github also recognizes it)
+const headerFromCache = "X-From-Cache"
+
+// HTTPCache stores GitHub API responses on disk and revalidates them with conditional requests
+// Unchanged resources come back as 304 Not Modified, which GitHub does not count against the
+// rate limit, so repeated ingestions of the same repository are nearly free
+type HTTPCache struct {
+	dir string
+}
+
+// httpCacheEntry is a cached response
+type httpCacheEntry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// NewHTTPCache opens (creating if needed) a response cache rooted at dir
+func NewHTTPCache(dir string) (*HTTPCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("http cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create http cache directory: %w", err)
+	}
+	return &HTTPCache{dir: dir}, nil
+}
+
+// DefaultHTTPCacheDir returns the GitHub response cache directory
+// Uses THUNK_GITHUB_CACHE_DIR if set, otherwise the user cache directory
+func DefaultHTTPCacheDir() (string, error) {
+	if dir := os.Getenv(HTTPCacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+
+	return filepath.Join(userCache, "thunk", "github"), nil
+}
+
+// Transport wraps next so GET requests are revalidated against the cache
+func (c *HTTPCache) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &cachingTransport{cache: c, next: next}
+}
+
+// cachingTransport adds ETag/If-Modified-Since validators to requests and serves 304s from the cache
+type cachingTransport struct {
+	cache *HTTPCache
+	next  http.RoundTripper
+}
+
+// RoundTrip sends a conditional request when a cached response exists
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	path := t.cache.path(req)
+	entry, cached := t.cache.read(path)
+
+	if cached {
+		conditional := req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			conditional.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			conditional.Header.Set("If-Modified-Since", modified)
+		}
+		req = conditional
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		resp.Body.Close()
+		return entry.response(req, resp.Header), nil
+	}
+
+	if resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		// A failed write only costs a cache miss next time
+		_ = t.cache.write(path, httpCacheEntry{StatusCode: resp.StatusCode, Header: resp.Header, Body: body})
+	}
+
+	return resp, nil
+}
+
+// response rebuilds a cached response, taking fresh headers (rate limits, validators) from the 304
+func (e httpCacheEntry) response(req *http.Request, fresh http.Header) *http.Response {
+	header := e.Header.Clone()
+	for key, values := range fresh {
+		header[key] = values
+	}
+	header.Set(headerFromCache, "1")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// path returns the entry location for a request
+// The key covers the URL, media type and credentials so tokens never share entries
+func (c *HTTPCache) path(req *http.Request) string {
+	hash := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	key := hex.EncodeToString(hash.Sum(nil))
+	return filepath.Join(c.dir, key[:2], key[2:]+".json")
+}
+
+// read decodes a cache entry; unreadable or corrupt entries count as misses
+func (c *HTTPCache) read(path string) (httpCacheEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return httpCacheEntry{}, false
+	}
+
+	var entry httpCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return httpCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// write stores a cache entry atomically
+func (c *HTTPCache) write(path string, entry httpCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode http cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create http cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write http cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write http cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write http cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write http cache entry: %w", err)
+	}
+
+	return nil
+}
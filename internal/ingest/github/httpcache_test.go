@@ -0,0 +1,118 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPCache_ServesNotModifiedFromCache(t *testing.T) {
+	var calls, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.Header().Set(headerRateRemaining, "4999")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number": 7, "title": "Cached"}`))
+	}))
+	defer server.Close()
+
+	cache, err := NewHTTPCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	client, err := NewClientWithOptions("test-token", ClientOptions{Cache: cache}).WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		issue, resp, err := client.Issues.Get(context.Background(), "o", "r", 7)
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+		if issue.GetTitle() != "Cached" {
+			t.Errorf("Request %d: expected title %q, got %q", i+1, "Cached", issue.GetTitle())
+		}
+		if fromCache := resp.Header.Get(headerFromCache) != ""; fromCache != (i == 1) {
+			t.Errorf("Request %d: expected from-cache %v", i+1, i == 1)
+		}
+	}
+
+	if calls != 2 || notModified != 1 {
+		t.Errorf("Expected 2 requests with 1 revalidation, got %d requests and %d revalidations", calls, notModified)
+	}
+}
+
+func TestHTTPCache_KeysByCredentials(t *testing.T) {
+	var conditional int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") != "" {
+			atomic.AddInt32(&conditional, 1)
+		}
+		resp := rateResponse(http.StatusOK, map[string]string{"ETag": `"v1"`})
+		return resp, nil
+	})
+
+	cache, err := NewHTTPCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	cached := cache.Transport(transport)
+
+	for _, token := range []string{"Bearer one", "Bearer two"} {
+		req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/issues", nil)
+		req.Header.Set("Authorization", token)
+		resp, err := cached.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if conditional != 0 {
+		t.Errorf("Expected different credentials not to share cache entries, got %d conditional requests", conditional)
+	}
+}
+
+func TestHTTPCache_SkipsNonGetAndUncacheable(t *testing.T) {
+	var conditional int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+			atomic.AddInt32(&conditional, 1)
+		}
+		if req.Method == http.MethodPost {
+			return rateResponse(http.StatusOK, map[string]string{"ETag": `"post"`}), nil
+		}
+		return rateResponse(http.StatusOK, nil), nil
+	})
+
+	cache, err := NewHTTPCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	cached := cache.Transport(transport)
+
+	for _, method := range []string{http.MethodPost, http.MethodPost, http.MethodGet, http.MethodGet} {
+		req := httptest.NewRequest(method, "https://api.github.com/repos/o/r/issues", nil)
+		resp, err := cached.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("%s request failed: %v", method, err)
+		}
+		resp.Body.Close()
+	}
+
+	if conditional != 0 {
+		t.Errorf("Expected no conditional requests, got %d", conditional)
+	}
+}
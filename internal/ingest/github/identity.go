@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v77/github"
+)
+
+// identityCommitSample is how many of a login's commits are inspected for their email
+const identityCommitSample = 30
+
+// Identity is a GitHub account with the email its commits are authored under
+type Identity struct {
+	Login string
+	Name  string
+	Email string // Empty when no email could be determined
+}
+
+// IdentityResolver maps GitHub logins to the emails used in their git commits
+// so artifacts authored by a login can be matched to that person's commits
+// Results (including misses) are memoized; pair the client with an HTTPCache to reuse
+// lookups across runs at no rate limit cost
+type IdentityResolver struct {
+	client *github.Client
+	owner  string
+	repo   string
+
+	mu         sync.Mutex
+	identities map[string]Identity
+}
+
+// NewIdentityResolver creates a resolver that looks up commit emails in owner/repo
+func NewIdentityResolver(client *github.Client, owner, repo string) *IdentityResolver {
+	return &IdentityResolver{
+		client:     client,
+		owner:      owner,
+		repo:       repo,
+		identities: make(map[string]Identity),
+	}
+}
+
+// Resolve returns the identity for a login
+// The email comes from the login's commits in the repository, preferring commits GitHub verified,
+// and falls back to the public profile email for users without commits there
+func (r *IdentityResolver) Resolve(ctx context.Context, login string) (Identity, error) {
+	key := strings.ToLower(login)
+
+	r.mu.Lock()
+	identity, ok := r.identities[key]
+	r.mu.Unlock()
+	if ok {
+		return identity, nil
+	}
+
+	identity, err := r.lookup(ctx, login)
+	if err != nil {
+		return Identity{Login: login}, err
+	}
+
+	r.mu.Lock()
+	r.identities[key] = identity
+	r.mu.Unlock()
+	return identity, nil
+}
+
+// lookup queries the commits API, then the users API
+func (r *IdentityResolver) lookup(ctx context.Context, login string) (Identity, error) {
+	identity := Identity{Login: login}
+
+	commits, _, err := r.client.Repositories.ListCommits(ctx, r.owner, r.repo, &github.CommitsListOptions{
+		Author:      login,
+		ListOptions: github.ListOptions{PerPage: identityCommitSample},
+	})
+	if err != nil {
+		return identity, handleAPIError(err, fmt.Sprintf("failed to list commits by %s", login))
+	}
+	identity.Name, identity.Email = commitIdentity(commits, login)
+	if identity.Email != "" {
+		return identity, nil
+	}
+
+	user, _, err := r.client.Users.Get(ctx, login)
+	if err != nil {
+		return identity, handleAPIError(err, fmt.Sprintf("failed to get user %s", login))
+	}
+	identity.Email = user.GetEmail()
+	if identity.Name == "" {
+		identity.Name = user.GetName()
+	}
+	return identity, nil
+}
+
+// commitIdentity picks the author name and email a login commits under
+// Emails on verified commits win; ties are broken by how often the email is used
+func commitIdentity(commits []*github.RepositoryCommit, login string) (string, string) {
+	type candidate struct {
+		name     string
+		count    int
+		verified int
+	}
+
+	candidates := make(map[string]*candidate)
+	for _, commit := range commits {
+		if commit == nil || commit.Commit == nil || commit.Commit.Author == nil {
+			continue
+		}
+		// Only trust commits GitHub attributes to the login's account
+		if !strings.EqualFold(commit.GetAuthor().GetLogin(), login) {
+			continue
+		}
+		email := strings.ToLower(strings.TrimSpace(commit.Commit.Author.GetEmail()))
+		if email == "" {
+			continue
+		}
+
+		c, ok := candidates[email]
+		if !ok {
+			c = &candidate{name: commit.Commit.Author.GetName()}
+			candidates[email] = c
+		}
+		c.count++
+		if commit.Commit.GetVerification().GetVerified() {
+			c.verified++
+		}
+	}
+
+	emails := make([]string, 0, len(candidates))
+	for email := range candidates {
+		emails = append(emails, email)
+	}
+	if len(emails) == 0 {
+		return "", ""
+	}
+
+	sort.Slice(emails, func(i, j int) bool {
+		a, b := candidates[emails[i]], candidates[emails[j]]
+		if a.verified != b.verified {
+			return a.verified > b.verified
+		}
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return emails[i] < emails[j]
+	})
+	best := emails[0]
+	return candidates[best].name, best
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func newIdentityTestServer(t *testing.T, calls *int32) *IdentityResolver {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/api/v3/repos/o/r/commits" && r.URL.Query().Get("author") == "alice":
+			w.Write([]byte(`[
+				{"author": {"login": "alice"}, "commit": {"author": {"name": "Alice", "email": "alice@home.example"}}},
+				{"author": {"login": "alice"}, "commit": {"author": {"name": "Alice", "email": "alice@home.example"}}},
+				{"author": {"login": "alice"}, "commit": {"author": {"name": "Alice Smith", "email": "Alice@Work.example"},
+					"verification": {"verified": true}}},
+				{"author": {"login": "mallory"}, "commit": {"author": {"name": "Mallory", "email": "mallory@example.com"}}}
+			]`))
+		case r.URL.Path == "/api/v3/repos/o/r/commits":
+			w.Write([]byte(`[]`))
+		case r.URL.Path == "/api/v3/users/bob":
+			w.Write([]byte(`{"login": "bob", "name": "Bob", "email": "bob@example.com"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+	return NewIdentityResolver(client, "o", "r")
+}
+
+func TestIdentityResolver_PrefersVerifiedCommitEmail(t *testing.T) {
+	var calls int32
+	resolver := newIdentityTestServer(t, &calls)
+
+	identity, err := resolver.Resolve(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if identity.Email != "alice@work.example" || identity.Name != "Alice Smith" {
+		t.Errorf("Expected the verified commit identity, got %+v", identity)
+	}
+
+	// Lookups are memoized regardless of login case
+	if _, err := resolver.Resolve(context.Background(), "Alice"); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 API call, got %d", calls)
+	}
+}
+
+func TestIdentityResolver_FallsBackToProfileEmail(t *testing.T) {
+	var calls int32
+	resolver := newIdentityTestServer(t, &calls)
+
+	identity, err := resolver.Resolve(context.Background(), "bob")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if identity.Email != "bob@example.com" || identity.Name != "Bob" {
+		t.Errorf("Expected the public profile identity, got %+v", identity)
+	}
+}
+
+func TestIdentityResolver_UnknownUser(t *testing.T) {
+	var calls int32
+	resolver := newIdentityTestServer(t, &calls)
+
+	identity, err := resolver.Resolve(context.Background(), "ghost")
+	if err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
+	if identity.Login != "ghost" || identity.Email != "" {
+		t.Errorf("Expected an identity without email, got %+v", identity)
+	}
+}
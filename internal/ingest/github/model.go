@@ -29,37 +29,67 @@ type Issue struct {
 // PullRequest represents GitHub pull request data with discussions
 // Designed to capture complete code review context
 type PullRequest struct {
-	ID                  int64           `json:"id"`
-	Number              int             `json:"number"`
-	Title               string          `json:"title"`
-	Description         string          `json:"description"`
-	State               string          `json:"state"`
-	Author              string          `json:"author"`
-	CreatedAt           time.Time       `json:"created_at"`
-	UpdatedAt           time.Time       `json:"updated_at"`
-	MergedAt            *time.Time      `json:"merged_at,omitempty"`
-	ClosedAt            *time.Time      `json:"closed_at,omitempty"`
-	Labels              []string        `json:"labels"`
-	Assignees           []string        `json:"assignees"`
-	RequestedReviewers  []string        `json:"requested_reviewers"`
-	Milestone           *Milestone      `json:"milestone,omitempty"`
-	Comments            []Comment       `json:"comments"`
-	ReviewComments      []ReviewComment `json:"review_comments"`
-	Reviews             []Review        `json:"reviews"`
-	Timeline            []TimelineEvent `json:"timeline"`
-	BaseBranch          string          `json:"base_branch"`
-	HeadBranch          string          `json:"head_branch"`
-	Merged              bool            `json:"merged"`
-	Mergeable           bool            `json:"mergeable"`
-	Draft               bool            `json:"draft"`
-	Additions           int             `json:"additions"`
-	Deletions           int             `json:"deletions"`
-	ChangedFiles        int             `json:"changed_files"`
-	MergeCommitSHA      string          `json:"merge_commit_sha,omitempty"`
-	MaintainerCanModify bool            `json:"maintainer_can_modify"`
-	URL                 string          `json:"url"`
-	HTMLURL             string          `json:"html_url"`
-	CrossReferences     []CrossRef      `json:"cross_references"`
+	ID                  int64             `json:"id"`
+	Number              int               `json:"number"`
+	Title               string            `json:"title"`
+	Description         string            `json:"description"`
+	State               string            `json:"state"`
+	Author              string            `json:"author"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	MergedAt            *time.Time        `json:"merged_at,omitempty"`
+	ClosedAt            *time.Time        `json:"closed_at,omitempty"`
+	Labels              []string          `json:"labels"`
+	Assignees           []string          `json:"assignees"`
+	RequestedReviewers  []string          `json:"requested_reviewers"`
+	Milestone           *Milestone        `json:"milestone,omitempty"`
+	Comments            []Comment         `json:"comments"`
+	ReviewComments      []ReviewComment   `json:"review_comments"`
+	Reviews             []Review          `json:"reviews"`
+	Timeline            []TimelineEvent   `json:"timeline"`
+	BaseBranch          string            `json:"base_branch"`
+	HeadBranch          string            `json:"head_branch"`
+	Merged              bool              `json:"merged"`
+	Mergeable           bool              `json:"mergeable"`
+	Draft               bool              `json:"draft"`
+	Additions           int               `json:"additions"`
+	Deletions           int               `json:"deletions"`
+	ChangedFiles        int               `json:"changed_files"`
+	MergeCommitSHA      string            `json:"merge_commit_sha,omitempty"`
+	MaintainerCanModify bool              `json:"maintainer_can_modify"`
+	Files               []PullRequestFile `json:"files,omitempty"`
+	CommitSHAs          []string          `json:"commit_shas,omitempty"`   // Commits on the PR branch, oldest first
+	ClosesIssues        []int             `json:"closes_issues,omitempty"` // Issues closed when the PR merges, sorted
+	URL                 string            `json:"url"`
+	HTMLURL             string            `json:"html_url"`
+	CrossReferences     []CrossRef        `json:"cross_references"`
+}
+
+// Repository represents a GitHub repository as listed for an organization
+type Repository struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	FullName      string     `json:"full_name"` // owner/name
+	Owner         string     `json:"owner"`
+	Description   string     `json:"description"`
+	DefaultBranch string     `json:"default_branch"`
+	Language      string     `json:"language"`
+	Topics        []string   `json:"topics"`
+	Private       bool       `json:"private"`
+	Fork          bool       `json:"fork"`
+	Archived      bool       `json:"archived"`
+	PushedAt      *time.Time `json:"pushed_at,omitempty"`
+	CloneURL      string     `json:"clone_url"`
+	HTMLURL       string     `json:"html_url"`
+}
+
+// PullRequestFile represents a file changed by a pull request
+type PullRequestFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"` // Set for renames
+	Status           string `json:"status"`                      // added, removed, modified, renamed, copied, changed, unchanged
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
 }
 
 // Comment represents a comment on an issue or PR
@@ -95,6 +125,8 @@ type ReviewComment struct {
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
 	Reactions         *Reactions `json:"reactions,omitempty"`
+	Resolved          bool       `json:"resolved"`              // The review thread was marked resolved
+	ResolvedBy        string     `json:"resolved_by,omitempty"` // Who resolved the thread
 	URL               string     `json:"url"`
 	HTMLURL           string     `json:"html_url"`
 }
@@ -120,12 +152,50 @@ type Milestone struct {
 	DueOn       *time.Time `json:"due_on,omitempty"`
 }
 
+// Release represents a published GitHub release
+// Releases mark what shipped when and are used as milestone markers during clustering
+type Release struct {
+	ID              int64          `json:"id"`
+	TagName         string         `json:"tag_name"`
+	Name            string         `json:"name"`
+	Body            string         `json:"body"` // Release notes / changelog
+	Author          string         `json:"author"`
+	TargetCommitish string         `json:"target_commitish"`
+	Draft           bool           `json:"draft"`
+	Prerelease      bool           `json:"prerelease"`
+	CreatedAt       time.Time      `json:"created_at"`
+	PublishedAt     *time.Time     `json:"published_at,omitempty"`
+	Assets          []ReleaseAsset `json:"assets"`
+	URL             string         `json:"url"`
+	HTMLURL         string         `json:"html_url"`
+}
+
+// ReleaseAsset represents a file attached to a release
+type ReleaseAsset struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	ContentType   string `json:"content_type"`
+	Size          int    `json:"size"`
+	DownloadCount int    `json:"download_count"`
+	DownloadURL   string `json:"download_url"`
+}
+
 // TimelineEvent represents an event in the issue/PR timeline
+// Event-specific fields are only set for the events that carry them
 type TimelineEvent struct {
+	ID        int64           `json:"id,omitempty"`
 	Event     string          `json:"event"`
 	Actor     string          `json:"actor"`
 	CreatedAt time.Time       `json:"created_at"`
 	Source    *TimelineSource `json:"source,omitempty"`
+
+	Label      string `json:"label,omitempty"`       // labeled, unlabeled
+	Assignee   string `json:"assignee,omitempty"`    // assigned, unassigned
+	Milestone  string `json:"milestone,omitempty"`   // milestoned, demilestoned
+	RenameFrom string `json:"rename_from,omitempty"` // renamed
+	RenameTo   string `json:"rename_to,omitempty"`   // renamed
+	Reviewer   string `json:"reviewer,omitempty"`    // review_requested, review_request_removed
+	CommitID   string `json:"commit_id,omitempty"`   // closed, merged, referenced: the commit involved
 }
 
 // TimelineSource represents the source of a cross-reference
@@ -0,0 +1,234 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v77/github"
+)
+
+// ProjectItem is an issue or pull request's place on a GitHub Projects (v2) board
+type ProjectItem struct {
+	Project       string     `json:"project"` // Board title
+	ProjectNumber int        `json:"project_number"`
+	ContentType   string     `json:"content_type"` // "Issue" or "PullRequest"
+	Number        int        `json:"number"`
+	Status        string     `json:"status,omitempty"` // Value of the board's "Status" column field
+	Iteration     *Iteration `json:"iteration,omitempty"`
+}
+
+// Iteration is a sprint from a board's iteration field
+type Iteration struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	StartDate time.Time `json:"start_date"`
+	Duration  int       `json:"duration"` // Length in days
+}
+
+// EndDate returns the first day after the iteration
+func (it Iteration) EndDate() time.Time {
+	return it.StartDate.AddDate(0, 0, it.Duration)
+}
+
+// ProjectContentIssue and ProjectContentPullRequest are the board item types that carry repository content
+const (
+	ProjectContentIssue       = "Issue"
+	ProjectContentPullRequest = "PullRequest"
+)
+
+// statusFieldName is the single-select field GitHub creates on every board for its columns
+const statusFieldName = "Status"
+
+// repositoryProjectsQuery pages through the boards linked to a repository
+const repositoryProjectsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    projectsV2(first: 20, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes { id number title }
+    }
+  }
+}`
+
+// projectItemsQuery pages through a board's items with their column and iteration values
+const projectItemsQuery = `query($id: ID!, $cursor: String) {
+  node(id: $id) {
+    ... on ProjectV2 {
+      items(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          content {
+            __typename
+            ... on Issue { number repository { nameWithOwner } }
+            ... on PullRequest { number repository { nameWithOwner } }
+          }
+          fieldValues(first: 20) {
+            nodes {
+              __typename
+              ... on ProjectV2ItemFieldSingleSelectValue { name field { ... on ProjectV2FieldCommon { name } } }
+              ... on ProjectV2ItemFieldIterationValue { iterationId title startDate duration }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// projectNode is a board linked to a repository
+type projectNode struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// projectItemNode is a board item as returned by projectItemsQuery
+type projectItemNode struct {
+	Content *struct {
+		TypeName   string `json:"__typename"`
+		Number     int    `json:"number"`
+		Repository struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+	} `json:"content"`
+	FieldValues struct {
+		Nodes []struct {
+			TypeName string `json:"__typename"`
+			Name     string `json:"name"`
+			Field    struct {
+				Name string `json:"name"`
+			} `json:"field"`
+			IterationID string `json:"iterationId"`
+			Title       string `json:"title"`
+			StartDate   string `json:"startDate"`
+			Duration    int    `json:"duration"`
+		} `json:"nodes"`
+	} `json:"fieldValues"`
+}
+
+// ListProjectItems returns the board placement of the repository's issues and pull requests
+// across every Projects (v2) board linked to it
+// Draft items and items from other repositories on shared boards are skipped
+func ListProjectItems(ctx context.Context, client *github.Client, owner, repo string) ([]ProjectItem, error) {
+	projects, err := listRepositoryProjects(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := owner + "/" + repo
+	items := make([]ProjectItem, 0)
+	for _, project := range projects {
+		variables := map[string]any{"id": project.ID}
+		for {
+			var data struct {
+				Node struct {
+					Items struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []projectItemNode `json:"nodes"`
+					} `json:"items"`
+				} `json:"node"`
+			}
+			if err := queryGraphQL(ctx, client, projectItemsQuery, variables, &data); err != nil {
+				return nil, fmt.Errorf("failed to list items of project %q: %w", project.Title, err)
+			}
+
+			page := data.Node.Items
+			for _, node := range page.Nodes {
+				if item, ok := parseProjectItem(project, node, fullName); ok {
+					items = append(items, item)
+				}
+			}
+
+			if !page.PageInfo.HasNextPage {
+				break
+			}
+			variables["cursor"] = page.PageInfo.EndCursor
+		}
+	}
+
+	return items, nil
+}
+
+// listRepositoryProjects returns the boards linked to a repository
+func listRepositoryProjects(ctx context.Context, client *github.Client, owner, repo string) ([]projectNode, error) {
+	var projects []projectNode
+	variables := map[string]any{"owner": owner, "name": repo}
+
+	for {
+		var data struct {
+			Repository struct {
+				ProjectsV2 struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []projectNode `json:"nodes"`
+				} `json:"projectsV2"`
+			} `json:"repository"`
+		}
+		if err := queryGraphQL(ctx, client, repositoryProjectsQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		page := data.Repository.ProjectsV2
+		projects = append(projects, page.Nodes...)
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = page.PageInfo.EndCursor
+	}
+
+	return projects, nil
+}
+
+// parseProjectItem converts a board item, reporting false for drafts and other repositories' content
+func parseProjectItem(project projectNode, node projectItemNode, fullName string) (ProjectItem, bool) {
+	content := node.Content
+	if content == nil || content.Number == 0 {
+		return ProjectItem{}, false
+	}
+	if content.TypeName != ProjectContentIssue && content.TypeName != ProjectContentPullRequest {
+		return ProjectItem{}, false
+	}
+	if !strings.EqualFold(content.Repository.NameWithOwner, fullName) {
+		return ProjectItem{}, false
+	}
+
+	item := ProjectItem{
+		Project:       project.Title,
+		ProjectNumber: project.Number,
+		ContentType:   content.TypeName,
+		Number:        content.Number,
+	}
+
+	for _, value := range node.FieldValues.Nodes {
+		switch value.TypeName {
+		case "ProjectV2ItemFieldSingleSelectValue":
+			if strings.EqualFold(value.Field.Name, statusFieldName) {
+				item.Status = value.Name
+			}
+		case "ProjectV2ItemFieldIterationValue":
+			// Boards rarely have more than one iteration field; the first one is the sprint
+			if item.Iteration != nil {
+				continue
+			}
+			start, err := time.Parse("2006-01-02", value.StartDate)
+			if err != nil {
+				continue
+			}
+			item.Iteration = &Iteration{
+				ID:        value.IterationID,
+				Title:     value.Title,
+				StartDate: start,
+				Duration:  value.Duration,
+			}
+		}
+	}
+
+	return item, true
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListProjectItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body.Query, "projectsV2") {
+			w.Write([]byte(`{"data": {"repository": {"projectsV2": {
+				"pageInfo": {"hasNextPage": false},
+				"nodes": [{"id": "P1", "number": 3, "title": "Roadmap"}]
+			}}}}`))
+			return
+		}
+		if body.Variables["id"] != "P1" {
+			t.Errorf("Expected items query for P1, got %v", body.Variables["id"])
+		}
+		w.Write([]byte(`{"data": {"node": {"items": {
+			"pageInfo": {"hasNextPage": false},
+			"nodes": [
+				{"content": {"__typename": "Issue", "number": 12, "repository": {"nameWithOwner": "o/r"}},
+					"fieldValues": {"nodes": [
+						{"__typename": "ProjectV2ItemFieldSingleSelectValue", "name": "In Progress", "field": {"name": "Status"}},
+						{"__typename": "ProjectV2ItemFieldSingleSelectValue", "name": "High", "field": {"name": "Priority"}},
+						{"__typename": "ProjectV2ItemFieldIterationValue", "iterationId": "it1", "title": "Sprint 4", "startDate": "2024-05-06", "duration": 14}
+					]}},
+				{"content": {"__typename": "PullRequest", "number": 13, "repository": {"nameWithOwner": "O/R"}},
+					"fieldValues": {"nodes": [{"__typename": "ProjectV2ItemFieldSingleSelectValue", "name": "Done", "field": {"name": "Status"}}]}},
+				{"content": {"__typename": "Issue", "number": 7, "repository": {"nameWithOwner": "o/other"}}, "fieldValues": {"nodes": []}},
+				{"content": {"__typename": "DraftIssue"}, "fieldValues": {"nodes": []}}
+			]
+		}}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	items, err := ListProjectItems(context.Background(), client, "o", "r")
+	if err != nil {
+		t.Fatalf("ListProjectItems failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items from this repository, got %+v", items)
+	}
+
+	issue := items[0]
+	if issue.Project != "Roadmap" || issue.ContentType != ProjectContentIssue || issue.Number != 12 || issue.Status != "In Progress" {
+		t.Errorf("Unexpected issue item: %+v", issue)
+	}
+	if issue.Iteration == nil || issue.Iteration.Title != "Sprint 4" {
+		t.Fatalf("Expected Sprint 4 iteration, got %+v", issue.Iteration)
+	}
+	if want := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC); !issue.Iteration.EndDate().Equal(want) {
+		t.Errorf("Expected iteration to end %v, got %v", want, issue.Iteration.EndDate())
+	}
+
+	if pr := items[1]; pr.ContentType != ProjectContentPullRequest || pr.Status != "Done" || pr.Iteration != nil {
+		t.Errorf("Unexpected pull request item: %+v", pr)
+	}
+}
@@ -0,0 +1,260 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v77/github"
+)
+
+// ReviewThread is a pull request review conversation anchored to a line of code
+// Resolution state is only exposed by the GraphQL API
+type ReviewThread struct {
+	ID         string          `json:"id"`
+	Resolved   bool            `json:"resolved"`
+	ResolvedBy string          `json:"resolved_by,omitempty"`
+	Outdated   bool            `json:"outdated"` // The code the thread is anchored to has since changed
+	Path       string          `json:"path"`
+	Line       int             `json:"line,omitempty"`
+	Comments   []ReviewComment `json:"comments"` // Oldest first; the first comment starts the thread
+}
+
+// reviewThreadsQuery pages through a pull request's review threads with their comments
+const reviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          id
+          isResolved
+          isOutdated
+          path
+          line
+          resolvedBy { login }
+          comments(first: 100) {
+            nodes {
+              databaseId
+              body
+              path
+              line
+              originalLine
+              diffHunk
+              createdAt
+              updatedAt
+              url
+              author { login }
+              commit { oid }
+              originalCommit { oid }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// reviewThreadsResponse mirrors the data returned by reviewThreadsQuery
+type reviewThreadsResponse struct {
+	Repository struct {
+		PullRequest *struct {
+			ReviewThreads struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []struct {
+					ID         string `json:"id"`
+					IsResolved bool   `json:"isResolved"`
+					IsOutdated bool   `json:"isOutdated"`
+					Path       string `json:"path"`
+					Line       int    `json:"line"`
+					ResolvedBy *struct {
+						Login string `json:"login"`
+					} `json:"resolvedBy"`
+					Comments struct {
+						Nodes []graphQLReviewComment `json:"nodes"`
+					} `json:"comments"`
+				} `json:"nodes"`
+			} `json:"reviewThreads"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+// graphQLReviewComment is a review comment as returned by GraphQL
+type graphQLReviewComment struct {
+	DatabaseID   int64     `json:"databaseId"`
+	Body         string    `json:"body"`
+	Path         string    `json:"path"`
+	Line         int       `json:"line"`
+	OriginalLine int       `json:"originalLine"`
+	DiffHunk     string    `json:"diffHunk"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	URL          string    `json:"url"`
+	Author       *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Commit *struct {
+		OID string `json:"oid"`
+	} `json:"commit"`
+	OriginalCommit *struct {
+		OID string `json:"oid"`
+	} `json:"originalCommit"`
+}
+
+// ParseReviewThreads fetches all review threads of a pull request with their resolution state
+func ParseReviewThreads(ctx context.Context, client *github.Client, owner, repo string, number int) ([]ReviewThread, error) {
+	threads := make([]ReviewThread, 0)
+	variables := map[string]any{"owner": owner, "name": repo, "number": number}
+
+	for {
+		var data reviewThreadsResponse
+		if err := queryGraphQL(ctx, client, reviewThreadsQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to get review threads: %w", err)
+		}
+		pr := data.Repository.PullRequest
+		if pr == nil {
+			return nil, fmt.Errorf("failed to get review threads: pull request #%d not found", number)
+		}
+
+		for _, node := range pr.ReviewThreads.Nodes {
+			thread := ReviewThread{
+				ID:       node.ID,
+				Resolved: node.IsResolved,
+				Outdated: node.IsOutdated,
+				Path:     node.Path,
+				Line:     node.Line,
+				Comments: make([]ReviewComment, 0, len(node.Comments.Nodes)),
+			}
+			if node.ResolvedBy != nil {
+				thread.ResolvedBy = node.ResolvedBy.Login
+			}
+
+			for _, c := range node.Comments.Nodes {
+				comment := ReviewComment{
+					ID:           c.DatabaseID,
+					Body:         c.Body,
+					Path:         c.Path,
+					Line:         c.Line,
+					OriginalLine: c.OriginalLine,
+					DiffHunk:     c.DiffHunk,
+					CreatedAt:    c.CreatedAt,
+					UpdatedAt:    c.UpdatedAt,
+					HTMLURL:      c.URL,
+					Resolved:     thread.Resolved,
+					ResolvedBy:   thread.ResolvedBy,
+				}
+				if c.Author != nil {
+					comment.Author = c.Author.Login
+				}
+				if c.Commit != nil {
+					comment.CommitID = c.Commit.OID
+				}
+				if c.OriginalCommit != nil {
+					comment.OriginalCommitID = c.OriginalCommit.OID
+				}
+				if len(thread.Comments) > 0 {
+					comment.InReplyToID = thread.Comments[0].ID
+				}
+				thread.Comments = append(thread.Comments, comment)
+			}
+
+			threads = append(threads, thread)
+		}
+
+		if !pr.ReviewThreads.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = pr.ReviewThreads.PageInfo.EndCursor
+	}
+
+	return threads, nil
+}
+
+// ApplyReviewThreads marks review comments with the resolution state of the thread they belong to
+func ApplyReviewThreads(comments []ReviewComment, threads []ReviewThread) {
+	type resolution struct {
+		resolved bool
+		by       string
+	}
+
+	byComment := make(map[int64]resolution)
+	for _, thread := range threads {
+		for _, comment := range thread.Comments {
+			byComment[comment.ID] = resolution{resolved: thread.Resolved, by: thread.ResolvedBy}
+		}
+	}
+
+	for i := range comments {
+		if r, ok := byComment[comments[i].ID]; ok {
+			comments[i].Resolved = r.resolved
+			comments[i].ResolvedBy = r.by
+		}
+	}
+}
+
+// ReviewThreadComments flattens threads into review comments, oldest first
+func ReviewThreadComments(threads []ReviewThread) []ReviewComment {
+	comments := make([]ReviewComment, 0)
+	for _, thread := range threads {
+		comments = append(comments, thread.Comments...)
+	}
+	sortReviewCommentsByTime(comments)
+	return comments
+}
+
+// graphQLError is an error reported in a GraphQL response body
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// queryGraphQL runs a GraphQL query through the client's transport (and so its rate limiting and auth)
+func queryGraphQL(ctx context.Context, client *github.Client, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode graphql query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphQLURL(client), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create graphql request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if _, err := client.Do(ctx, req, &result); err != nil {
+		return handleAPIError(err, "graphql request failed")
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("graphql query failed: %s", strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("failed to decode graphql response: %w", err)
+	}
+	return nil
+}
+
+// graphQLURL returns the GraphQL endpoint for the client's API host
+// GitHub Enterprise serves REST under /api/v3/ and GraphQL under /api/graphql
+func graphQLURL(client *github.Client) string {
+	base := client.BaseURL.String()
+	if strings.HasSuffix(base, "/api/v3/") {
+		return strings.TrimSuffix(base, "v3/") + "graphql"
+	}
+	return base + "graphql"
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseReviewThreads(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode query: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if body.Variables["cursor"] == nil {
+			w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+				"nodes": [{"id": "T1", "isResolved": true, "path": "cache.go", "resolvedBy": {"login": "alice"},
+					"comments": {"nodes": [
+						{"databaseId": 10, "body": "Leaks handles", "author": {"login": "bob"}, "createdAt": "2024-05-01T10:00:00Z"},
+						{"databaseId": 11, "body": "Fixed", "author": {"login": "alice"}, "createdAt": "2024-05-01T11:00:00Z"}
+					]}}]
+			}}}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"pageInfo": {"hasNextPage": false},
+			"nodes": [{"id": "T2", "isResolved": false, "path": "main.go",
+				"comments": {"nodes": [{"databaseId": 20, "body": "Why?", "author": {"login": "carol"}, "createdAt": "2024-05-01T09:00:00Z"}]}}]
+		}}}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	threads, err := ParseReviewThreads(context.Background(), client, "o", "r", 5)
+	if err != nil {
+		t.Fatalf("ParseReviewThreads failed: %v", err)
+	}
+	if requests != 2 || len(threads) != 2 {
+		t.Fatalf("Expected 2 threads over 2 pages, got %d threads in %d requests", len(threads), requests)
+	}
+
+	first := threads[0]
+	if !first.Resolved || first.ResolvedBy != "alice" || len(first.Comments) != 2 {
+		t.Errorf("Unexpected first thread: %+v", first)
+	}
+	if reply := first.Comments[1]; reply.InReplyToID != 10 || !reply.Resolved || reply.Author != "alice" {
+		t.Errorf("Expected reply linked to the thread's first comment, got %+v", reply)
+	}
+
+	comments := ReviewThreadComments(threads)
+	if len(comments) != 3 || comments[0].ID != 20 || comments[0].Resolved {
+		t.Errorf("Expected flattened comments oldest first, got %+v", comments)
+	}
+}
+
+func TestParseReviewThreads_GraphQLError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": null, "errors": [{"message": "Could not resolve to a Repository"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token").WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	if _, err := ParseReviewThreads(context.Background(), client, "o", "missing", 1); err == nil {
+		t.Error("Expected GraphQL errors to be reported")
+	}
+}
+
+func TestApplyReviewThreads(t *testing.T) {
+	comments := []ReviewComment{{ID: 10}, {ID: 11}, {ID: 99}}
+	threads := []ReviewThread{
+		{Resolved: true, ResolvedBy: "alice", Comments: []ReviewComment{{ID: 10}, {ID: 11}}},
+	}
+
+	ApplyReviewThreads(comments, threads)
+
+	if !comments[0].Resolved || !comments[1].Resolved || comments[1].ResolvedBy != "alice" {
+		t.Errorf("Expected thread comments marked resolved, got %+v", comments[:2])
+	}
+	if comments[2].Resolved {
+		t.Error("Expected a comment outside any thread to stay unresolved")
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	if got := graphQLURL(NewClient("t")); got != "https://api.github.com/graphql" {
+		t.Errorf("Expected the public GraphQL endpoint, got %s", got)
+	}
+
+	client, err := NewClient("t").WithEnterpriseURLs("https://ghe.example.com", "https://ghe.example.com")
+	if err != nil {
+		t.Fatalf("Failed to create enterprise client: %v", err)
+	}
+	if got := graphQLURL(client); got != "https://ghe.example.com/api/graphql" {
+		t.Errorf("Expected the enterprise GraphQL endpoint, got %s", got)
+	}
+}
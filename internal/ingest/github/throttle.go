@@ -0,0 +1,311 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v77/github"
+)
+
+// Rate limit response headers
+const (
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRetryAfter    = "Retry-After"
+)
+
+// Defaults for secondary rate limits that arrive without a Retry-After header
+// GitHub asks clients to wait at least a minute and back off exponentially
+const (
+	defaultSecondaryBackoff = time.Minute
+	defaultMaxRetries       = 5
+
+	// rateResetBuffer pads primary-limit waits to absorb clock skew with GitHub
+	rateResetBuffer = time.Second
+)
+
+// WaitReason identifies which rate limit caused a wait
+type WaitReason string
+
+const (
+	WaitPrimary   WaitReason = "primary"   // Hourly quota exhausted; waiting for the reset time
+	WaitSecondary WaitReason = "secondary" // Secondary (abuse) limit; waiting for Retry-After or backing off
+)
+
+// RateLimitWait describes a pause taken because of rate limiting
+type RateLimitWait struct {
+	Reason   WaitReason
+	Category github.RateLimitCategory
+	Duration time.Duration
+	Until    time.Time
+	Attempt  int // Retry attempt for the current request (0 when waiting before the first attempt)
+}
+
+// ThrottleOptions configures rate-limit handling for a GitHub client
+type ThrottleOptions struct {
+	// Transport performs the underlying requests (defaults to http.DefaultTransport)
+	Transport http.RoundTripper
+
+	// OnWait is called before every rate-limit pause so callers can report progress
+	OnWait func(RateLimitWait)
+
+	// MaxRetries caps how often one request is retried after being rate limited (defaults to 5)
+	MaxRetries int
+
+	// MaxWait caps a single pause; longer waits fail the request instead (0 means no cap)
+	MaxWait time.Duration
+}
+
+// rateState is the last known quota for one rate limit category
+type rateState struct {
+	remaining int
+	reset     time.Time
+	known     bool
+}
+
+// Throttler is an http.RoundTripper that waits out GitHub rate limits instead of failing
+// It tracks the remaining quota per category from response headers, sleeps until the reset
+// once a quota is exhausted, and retries requests rejected by primary or secondary limits
+type Throttler struct {
+	transport  http.RoundTripper
+	onWait     func(RateLimitWait)
+	maxRetries int
+	maxWait    time.Duration
+
+	mu              sync.Mutex
+	rates           map[github.RateLimitCategory]rateState
+	secondaryResume time.Time
+
+	// Replaced in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewThrottler creates a rate-limit aware transport
+func NewThrottler(opts ThrottleOptions) *Throttler {
+	transport := opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	return &Throttler{
+		transport:  transport,
+		onWait:     opts.OnWait,
+		maxRetries: maxRetries,
+		maxWait:    opts.MaxWait,
+		rates:      make(map[github.RateLimitCategory]rateState),
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// ClientOptions configures a GitHub API client
+type ClientOptions struct {
+	ThrottleOptions
+
+	// Cache serves unchanged responses through conditional requests (nil disables caching)
+	Cache *HTTPCache
+}
+
+// NewClientWithOptions creates an authenticated GitHub API client whose requests wait out rate limits
+// If token is empty, attempts to load from GITHUB_TOKEN environment variable
+func NewClientWithOptions(token string, opts ClientOptions) *github.Client {
+	var transport http.RoundTripper = NewThrottler(opts.ThrottleOptions)
+	if opts.Cache != nil {
+		transport = opts.Cache.Transport(transport)
+	}
+
+	client := newClient(token, &http.Client{Transport: transport})
+	// The throttler does its own tracking; go-github would otherwise fail fast on exhausted quotas
+	client.DisableRateLimitCheck = true
+	return client
+}
+
+// RoundTrip sends a request, pausing before it while a quota is exhausted and retrying it
+// after rate-limited responses
+func (t *Throttler) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	category := github.GetRateLimitCategory(req.Method, req.URL.Path)
+
+	if err := t.waitForQuota(ctx, category); err != nil {
+		return nil, err
+	}
+
+	backoff := defaultSecondaryBackoff
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.transport.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		t.record(category, resp)
+
+		wait, ok := t.retryDelay(resp, backoff)
+		if !ok || attempt >= t.maxRetries || !canRewind(req) || (t.maxWait > 0 && wait.Duration > t.maxWait) {
+			return resp, nil
+		}
+		if wait.Reason == WaitSecondary && resp.Header.Get(headerRetryAfter) == "" {
+			backoff *= 2
+		}
+		resp.Body.Close()
+
+		wait.Category = category
+		wait.Attempt = attempt + 1
+		if err := t.pause(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForQuota blocks while the category's quota or a secondary limit is known to be exhausted
+func (t *Throttler) waitForQuota(ctx context.Context, category github.RateLimitCategory) error {
+	t.mu.Lock()
+	now := t.now()
+	var wait RateLimitWait
+	if state := t.rates[category]; state.known && state.remaining == 0 && now.Before(state.reset) {
+		wait = RateLimitWait{Reason: WaitPrimary, Until: state.reset.Add(rateResetBuffer)}
+	}
+	if t.secondaryResume.After(now) && t.secondaryResume.After(wait.Until) {
+		wait = RateLimitWait{Reason: WaitSecondary, Until: t.secondaryResume}
+	}
+	t.mu.Unlock()
+
+	if wait.Until.IsZero() {
+		return nil
+	}
+
+	wait.Category = category
+	wait.Duration = wait.Until.Sub(now)
+	if t.maxWait > 0 && wait.Duration > t.maxWait {
+		// Let the request through; GitHub's rejection is reported by handleAPIError
+		return nil
+	}
+	return t.pause(ctx, wait)
+}
+
+// record updates the tracked quota from a response's rate limit headers
+func (t *Throttler) record(category github.RateLimitCategory, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get(headerRateRemaining))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get(headerRateReset), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.rates[category] = rateState{remaining: remaining, reset: time.Unix(reset, 0), known: true}
+	t.mu.Unlock()
+}
+
+// retryDelay decides whether a response was rate limited and how long to wait before retrying
+// Retry-After wins when present; otherwise an exhausted quota waits for its reset and a bare
+// 429 falls back to exponential backoff
+func (t *Throttler) retryDelay(resp *http.Response, backoff time.Duration) (RateLimitWait, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return RateLimitWait{}, false
+	}
+	now := t.now()
+
+	if retryAfter := resp.Header.Get(headerRetryAfter); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return t.secondaryWait(now, time.Duration(seconds)*time.Second), true
+		}
+		if when, err := http.ParseTime(retryAfter); err == nil {
+			return t.secondaryWait(now, when.Sub(now)), true
+		}
+	}
+
+	if resp.Header.Get(headerRateRemaining) == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get(headerRateReset), 10, 64); err == nil {
+			until := time.Unix(reset, 0).Add(rateResetBuffer)
+			if until.Before(now) {
+				until = now
+			}
+			return RateLimitWait{Reason: WaitPrimary, Until: until, Duration: until.Sub(now)}, true
+		}
+	}
+
+	// A 403 without rate limit headers is a permission error, not throttling
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return t.secondaryWait(now, backoff), true
+	}
+	return RateLimitWait{}, false
+}
+
+// secondaryWait records a secondary limit so concurrent requests also hold off until it lifts
+func (t *Throttler) secondaryWait(now time.Time, d time.Duration) RateLimitWait {
+	if d < 0 {
+		d = 0
+	}
+	until := now.Add(d)
+
+	t.mu.Lock()
+	if until.After(t.secondaryResume) {
+		t.secondaryResume = until
+	}
+	t.mu.Unlock()
+
+	return RateLimitWait{Reason: WaitSecondary, Until: until, Duration: d}
+}
+
+// pause reports a wait and sleeps through it, returning early if the context is cancelled
+func (t *Throttler) pause(ctx context.Context, wait RateLimitWait) error {
+	if t.onWait != nil {
+		t.onWait(wait)
+	}
+	if err := t.sleep(ctx, wait.Duration); err != nil {
+		return fmt.Errorf("waiting for %s rate limit: %w", wait.Reason, err)
+	}
+	return nil
+}
+
+// rewindRequest returns a request that can be sent again, re-reading the body for retries
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// canRewind reports whether a request can be retried
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// sleepContext sleeps for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,228 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// rateResponse builds a response with the given status and headers
+func rateResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}
+	for key, value := range headers {
+		resp.Header.Set(key, value)
+	}
+	return resp
+}
+
+// newTestThrottler returns a throttler with a fixed clock that records sleeps instead of sleeping
+func newTestThrottler(transport http.RoundTripper, now time.Time) (*Throttler, *[]time.Duration) {
+	slept := make([]time.Duration, 0)
+	throttler := NewThrottler(ThrottleOptions{Transport: transport})
+	throttler.now = func() time.Time { return now }
+	throttler.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	return throttler, &slept
+}
+
+func TestThrottler_WaitsForExhaustedQuota(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	reset := now.Add(10 * time.Minute)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return rateResponse(http.StatusOK, map[string]string{
+			headerRateRemaining: "0",
+			headerRateReset:     strconv.FormatInt(reset.Unix(), 10),
+		}), nil
+	})
+
+	throttler, slept := newTestThrottler(transport, now)
+	var waits []RateLimitWait
+	throttler.onWait = func(wait RateLimitWait) { waits = append(waits, wait) }
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/issues", nil)
+	if _, err := throttler.RoundTrip(req); err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+	if len(*slept) != 0 {
+		t.Fatalf("Expected no wait before the quota is known, got %v", *slept)
+	}
+
+	if _, err := throttler.RoundTrip(req); err != nil {
+		t.Fatalf("Second request failed: %v", err)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 10*time.Minute+rateResetBuffer {
+		t.Fatalf("Expected one wait until reset, got %v", *slept)
+	}
+	if len(waits) != 1 || waits[0].Reason != WaitPrimary || waits[0].Attempt != 0 {
+		t.Errorf("Unexpected wait callback: %+v", waits)
+	}
+}
+
+func TestThrottler_RetriesSecondaryLimit(t *testing.T) {
+	var calls int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return rateResponse(http.StatusForbidden, map[string]string{headerRetryAfter: "30"}), nil
+		}
+		return rateResponse(http.StatusOK, nil), nil
+	})
+
+	throttler, slept := newTestThrottler(transport, time.Unix(1_700_000_000, 0))
+	var waits []RateLimitWait
+	throttler.onWait = func(wait RateLimitWait) { waits = append(waits, wait) }
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/pulls", nil)
+	resp, err := throttler.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected retried request to succeed, got status %d", resp.StatusCode)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 30*time.Second {
+		t.Errorf("Expected a 30s Retry-After wait, got %v", *slept)
+	}
+	if len(waits) != 1 || waits[0].Reason != WaitSecondary || waits[0].Attempt != 1 {
+		t.Errorf("Unexpected wait callback: %+v", waits)
+	}
+}
+
+func TestThrottler_BacksOffWithoutRetryAfter(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return rateResponse(http.StatusTooManyRequests, nil), nil
+	})
+
+	throttler, slept := newTestThrottler(transport, time.Unix(1_700_000_000, 0))
+	throttler.maxRetries = 3
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/issues", nil)
+	resp, err := throttler.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected the final rate-limited response after retries, got %d", resp.StatusCode)
+	}
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}
+	if len(*slept) != len(expected) {
+		t.Fatalf("Expected waits %v, got %v", expected, *slept)
+	}
+	for i := range expected {
+		if (*slept)[i] != expected[i] {
+			t.Errorf("Wait %d: expected %v, got %v", i, expected[i], (*slept)[i])
+		}
+	}
+}
+
+func TestThrottler_PassesThroughPermissionErrors(t *testing.T) {
+	var calls int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return rateResponse(http.StatusForbidden, map[string]string{headerRateRemaining: "4000"}), nil
+	})
+
+	throttler, slept := newTestThrottler(transport, time.Unix(1_700_000_000, 0))
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r", nil)
+	resp, err := throttler.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden || calls != 1 || len(*slept) != 0 {
+		t.Errorf("Expected a single unretried 403, got status %d after %d calls and waits %v", resp.StatusCode, calls, *slept)
+	}
+}
+
+func TestThrottler_MaxWait(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return rateResponse(http.StatusForbidden, map[string]string{
+			headerRateRemaining: "0",
+			headerRateReset:     strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+		}), nil
+	})
+
+	throttler, slept := newTestThrottler(transport, now)
+	throttler.maxWait = time.Minute
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/issues", nil)
+	resp, err := throttler.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden || len(*slept) != 0 {
+		t.Errorf("Expected the rate-limited response without waiting, got status %d and waits %v", resp.StatusCode, *slept)
+	}
+}
+
+func TestThrottler_ContextCancelled(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return rateResponse(http.StatusTooManyRequests, map[string]string{headerRetryAfter: "60"}), nil
+	})
+
+	throttler := NewThrottler(ThrottleOptions{Transport: transport})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/issues", nil).WithContext(ctx)
+
+	if _, err := throttler.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNewClientWithOptions_RetriesThroughClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set(headerRetryAfter, "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number": 7, "title": "Retried"}`))
+	}))
+	defer server.Close()
+
+	var waited int32
+	client := NewClientWithOptions("test-token", ClientOptions{ThrottleOptions: ThrottleOptions{
+		OnWait: func(RateLimitWait) { atomic.AddInt32(&waited, 1) },
+	}})
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to point client at test server: %v", err)
+	}
+
+	issue, _, err := client.Issues.Get(context.Background(), "o", "r", 7)
+	if err != nil {
+		t.Fatalf("Expected request to succeed after retry: %v", err)
+	}
+	if issue.GetTitle() != "Retried" || calls != 2 || waited != 1 {
+		t.Errorf("Unexpected result: title %q, %d calls, %d waits", issue.GetTitle(), calls, waited)
+	}
+}
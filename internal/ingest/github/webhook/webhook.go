@@ -0,0 +1,241 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/adapter"
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	githubmodel "github.com/Yates-Labs/thunk/internal/ingest/github"
+	"github.com/google/go-github/v77/github"
+)
+
+// SecretEnv is the environment variable holding the webhook secret configured on GitHub
+const SecretEnv = "THUNK_WEBHOOK_SECRET"
+
+// defaultQueueSize bounds how many accepted deliveries may wait for processing
+const defaultQueueSize = 100
+
+// shutdownTimeout is how long in-flight requests get to finish when the server stops
+const shutdownTimeout = 10 * time.Second
+
+// ErrMissingSecret is returned when a server is created without a webhook secret
+var ErrMissingSecret = errors.New("webhook secret is required")
+
+// EventType identifies the kind of webhook delivery
+type EventType string
+
+const (
+	EventPush        EventType = "push"
+	EventIssue       EventType = "issues"
+	EventPullRequest EventType = "pull_request"
+)
+
+// Update is a webhook delivery converted into ingestion input
+type Update struct {
+	Event      EventType
+	Action     string // Issue/PR action such as "opened" or "closed" (empty for pushes)
+	DeliveryID string
+	Owner      string
+	Repo       string
+
+	// Push details
+	Ref    string
+	Before string
+	After  string
+	Forced bool // History was rewritten, so previously ingested commits may be gone
+
+	// Artifact is the converted issue or pull request (nil for pushes)
+	Artifact *cluster.Artifact
+}
+
+// Sink receives updates in delivery order
+type Sink interface {
+	ApplyUpdate(ctx context.Context, update Update) error
+}
+
+// Options configures a webhook server
+type Options struct {
+	// Secret validates the X-Hub-Signature-256 header of every delivery
+	Secret []byte
+
+	// QueueSize caps deliveries waiting to be applied; further ones are rejected with 503 (defaults to 100)
+	QueueSize int
+
+	// OnError is called when the sink fails to apply an update (defaults to ignoring the error)
+	OnError func(Update, error)
+}
+
+// Server receives GitHub webhooks and feeds them to a sink
+// Deliveries are validated and converted synchronously, then applied one at a time in the
+// background so GitHub gets a fast response even when re-ingestion is slow
+type Server struct {
+	sink    Sink
+	secret  []byte
+	onError func(Update, error)
+	queue   chan Update
+}
+
+// NewServer creates a webhook server that applies updates to sink
+func NewServer(sink Sink, opts Options) (*Server, error) {
+	if len(opts.Secret) == 0 {
+		return nil, ErrMissingSecret
+	}
+	if sink == nil {
+		return nil, fmt.Errorf("webhook sink is required")
+	}
+
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	return &Server{
+		sink:    sink,
+		secret:  opts.Secret,
+		onError: opts.OnError,
+		queue:   make(chan Update, queueSize),
+	}, nil
+}
+
+// ServeHTTP validates a delivery's signature, converts its payload and queues the update
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := github.ValidatePayload(r, s.secret)
+	if err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	// Pings and events that do not affect history are acknowledged without being parsed
+	eventType := EventType(github.WebHookType(r))
+	if eventType != EventPush && eventType != EventIssue && eventType != EventPullRequest {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	event, err := github.ParseWebHook(string(eventType), payload)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	update, ok, err := ConvertEvent(event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	update.DeliveryID = github.DeliveryID(r)
+
+	select {
+	case s.queue <- update:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "update queue full", http.StatusServiceUnavailable)
+	}
+}
+
+// Run applies queued updates until ctx is cancelled
+func (s *Server) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update := <-s.queue:
+			if err := s.sink.ApplyUpdate(ctx, update); err != nil && s.onError != nil {
+				s.onError(update, err)
+			}
+		}
+	}
+}
+
+// ListenAndServe serves webhooks on addr and applies updates until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go s.Run(ctx)
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("webhook server failed: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down webhook server: %w", err)
+		}
+		return nil
+	}
+}
+
+// ConvertEvent turns a parsed webhook payload into an update
+// Returns false for events that do not affect ingested history
+func ConvertEvent(event interface{}) (Update, bool, error) {
+	githubAdapter := adapter.NewGitHubAdapter()
+
+	switch e := event.(type) {
+	case *github.PushEvent:
+		return Update{
+			Event:  EventPush,
+			Owner:  e.GetRepo().GetOwner().GetLogin(),
+			Repo:   e.GetRepo().GetName(),
+			Ref:    e.GetRef(),
+			Before: e.GetBefore(),
+			After:  e.GetAfter(),
+			Forced: e.GetForced(),
+		}, true, nil
+
+	case *github.IssuesEvent:
+		if e.Issue == nil {
+			return Update{}, false, fmt.Errorf("issues event without issue")
+		}
+		artifact, err := githubAdapter.ConvertIssue(githubmodel.ParseIssue(e.Issue))
+		if err != nil {
+			return Update{}, false, fmt.Errorf("failed to convert issue: %w", err)
+		}
+		return Update{
+			Event:    EventIssue,
+			Action:   e.GetAction(),
+			Owner:    e.GetRepo().GetOwner().GetLogin(),
+			Repo:     e.GetRepo().GetName(),
+			Artifact: artifact,
+		}, true, nil
+
+	case *github.PullRequestEvent:
+		if e.PullRequest == nil {
+			return Update{}, false, fmt.Errorf("pull request event without pull request")
+		}
+		artifact, err := githubAdapter.ConvertPullRequest(githubmodel.ParsePullRequest(e.PullRequest))
+		if err != nil {
+			return Update{}, false, fmt.Errorf("failed to convert pull request: %w", err)
+		}
+		return Update{
+			Event:    EventPullRequest,
+			Action:   e.GetAction(),
+			Owner:    e.GetRepo().GetOwner().GetLogin(),
+			Repo:     e.GetRepo().GetName(),
+			Artifact: artifact,
+		}, true, nil
+	}
+
+	return Update{}, false, nil
+}
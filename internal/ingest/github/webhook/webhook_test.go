@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+const testSecret = "s3cret"
+
+// recordingSink collects applied updates
+type recordingSink struct {
+	mu      sync.Mutex
+	updates []Update
+	err     error
+}
+
+func (s *recordingSink) ApplyUpdate(ctx context.Context, update Update) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, update)
+	return s.err
+}
+
+// signedRequest builds a webhook delivery signed with secret
+func signedRequest(event, body, secret string) *http.Request {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func newTestServer(t *testing.T, sink Sink, opts Options) *Server {
+	t.Helper()
+	opts.Secret = []byte(testSecret)
+	server, err := NewServer(sink, opts)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return server
+}
+
+func TestNewServer_RequiresSecret(t *testing.T) {
+	if _, err := NewServer(&recordingSink{}, Options{}); !errors.Is(err, ErrMissingSecret) {
+		t.Errorf("Expected ErrMissingSecret, got %v", err)
+	}
+}
+
+func TestServer_RejectsInvalidSignature(t *testing.T) {
+	server := newTestServer(t, &recordingSink{}, Options{})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, signedRequest("push", `{"ref":"refs/heads/main"}`, "wrong"))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, got %d", rec.Code)
+	}
+	if len(server.queue) != 0 {
+		t.Error("Expected no update to be queued")
+	}
+}
+
+func TestServer_AcknowledgesIgnoredEvents(t *testing.T) {
+	server := newTestServer(t, &recordingSink{}, Options{})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, signedRequest("ping", `{"zen":"Keep it logically awesome."}`, testSecret))
+
+	if rec.Code != http.StatusNoContent || len(server.queue) != 0 {
+		t.Errorf("Expected ping acknowledged without an update, got %d with %d queued", rec.Code, len(server.queue))
+	}
+}
+
+func TestServer_QueuesIssueUpdate(t *testing.T) {
+	server := newTestServer(t, &recordingSink{}, Options{})
+
+	body := `{
+		"action": "closed",
+		"issue": {"number": 12, "title": "Crash on start", "state": "closed", "user": {"login": "alice"},
+			"created_at": "2024-05-01T10:00:00Z", "updated_at": "2024-05-02T10:00:00Z"},
+		"repository": {"name": "thunk", "owner": {"login": "Yates-Labs"}}
+	}`
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, signedRequest("issues", body, testSecret))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	update := <-server.queue
+	if update.Event != EventIssue || update.Action != "closed" || update.DeliveryID != "delivery-1" {
+		t.Errorf("Unexpected update: %+v", update)
+	}
+	if update.Owner != "Yates-Labs" || update.Repo != "thunk" {
+		t.Errorf("Expected repository Yates-Labs/thunk, got %s/%s", update.Owner, update.Repo)
+	}
+	if update.Artifact == nil || update.Artifact.Number != 12 || update.Artifact.Type != cluster.ArtifactIssue {
+		t.Errorf("Expected converted issue #12, got %+v", update.Artifact)
+	}
+}
+
+func TestServer_QueueFull(t *testing.T) {
+	server := newTestServer(t, &recordingSink{}, Options{QueueSize: 1})
+	body := `{"ref":"refs/heads/main","repository":{"name":"thunk","owner":{"login":"Yates-Labs"}}}`
+
+	first := httptest.NewRecorder()
+	server.ServeHTTP(first, signedRequest("push", body, testSecret))
+	second := httptest.NewRecorder()
+	server.ServeHTTP(second, signedRequest("push", body, testSecret))
+
+	if first.Code != http.StatusAccepted || second.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 202 then 503, got %d and %d", first.Code, second.Code)
+	}
+}
+
+func TestServer_RunAppliesUpdatesInOrder(t *testing.T) {
+	sink := &recordingSink{err: errors.New("ingest failed")}
+	var failed []Update
+	var failedMu sync.Mutex
+	server := newTestServer(t, sink, Options{OnError: func(update Update, err error) {
+		failedMu.Lock()
+		failed = append(failed, update)
+		failedMu.Unlock()
+	}})
+
+	for _, after := range []string{"aaa", "bbb"} {
+		body := `{"ref":"refs/heads/main","after":"` + after + `","forced":true,"repository":{"name":"thunk","owner":{"login":"Yates-Labs"}}}`
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, signedRequest("push", body, testSecret))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sink.mu.Lock()
+		n := len(sink.updates)
+		sink.mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if len(sink.updates) != 2 || sink.updates[0].After != "aaa" || sink.updates[1].After != "bbb" {
+		t.Fatalf("Expected both pushes applied in order, got %+v", sink.updates)
+	}
+	if !sink.updates[0].Forced || sink.updates[0].Ref != "refs/heads/main" {
+		t.Errorf("Expected push details to be converted, got %+v", sink.updates[0])
+	}
+	if len(failed) != 2 {
+		t.Errorf("Expected OnError for each failed update, got %d", len(failed))
+	}
+}
@@ -0,0 +1,423 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// NewClient creates a GitLab API client for gitlab.com or a self-hosted instance
+// If token is empty, attempts to load from GITLAB_TOKEN environment variable
+// An empty baseURL targets gitlab.com; otherwise it is the instance root, e.g. "https://gitlab.example.com/"
+func NewClient(token, baseURL string) (*gitlab.Client, error) {
+	if token == "" {
+		token = os.Getenv("GITLAB_TOKEN")
+	}
+
+	var opts []gitlab.ClientOptionFunc
+	if baseURL != "" {
+		opts = append(opts, gitlab.WithBaseURL(baseURL))
+	}
+
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gitlab client: %w", err)
+	}
+	return client, nil
+}
+
+// ProjectPath returns the "namespace/project" path GitLab accepts in place of a project ID
+// The namespace may contain nested groups, e.g. "group/subgroup"
+func ProjectPath(namespace, project string) string {
+	return namespace + "/" + project
+}
+
+// ListAllIssues fetches all issues from a project with pagination
+func ListAllIssues(ctx context.Context, client *gitlab.Client, pid string) ([]*gitlab.Issue, error) {
+	return ListIssuesSince(ctx, client, pid, time.Time{})
+}
+
+// ListIssuesSince fetches the issues of a project updated at or after since (zero = all)
+func ListIssuesSince(ctx context.Context, client *gitlab.Client, pid string, since time.Time) ([]*gitlab.Issue, error) {
+	var allIssues []*gitlab.Issue
+
+	opts := &gitlab.ListProjectIssuesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}
+	if !since.IsZero() {
+		opts.UpdatedAfter = &since
+	}
+
+	for {
+		issues, resp, err := client.Issues.ListProjectIssues(pid, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list issues")
+		}
+
+		allIssues = append(allIssues, issues...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
+// ListAllMergeRequests fetches all merge requests from a project with pagination
+func ListAllMergeRequests(ctx context.Context, client *gitlab.Client, pid string) ([]*gitlab.BasicMergeRequest, error) {
+	return ListMergeRequestsSince(ctx, client, pid, time.Time{})
+}
+
+// ListMergeRequestsSince fetches the merge requests of a project updated at or after since (zero = all)
+func ListMergeRequestsSince(ctx context.Context, client *gitlab.Client, pid string, since time.Time) ([]*gitlab.BasicMergeRequest, error) {
+	var allMRs []*gitlab.BasicMergeRequest
+
+	opts := &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}
+	if !since.IsZero() {
+		opts.UpdatedAfter = &since
+	}
+
+	for {
+		mrs, resp, err := client.MergeRequests.ListProjectMergeRequests(pid, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list merge requests")
+		}
+
+		allMRs = append(allMRs, mrs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allMRs, nil
+}
+
+// ParseIssue converts a client-go Issue to our Issue struct
+func ParseIssue(glIssue *gitlab.Issue) *Issue {
+	issue := &Issue{
+		ID:       glIssue.ID,
+		IID:      int(glIssue.IID),
+		Title:    glIssue.Title,
+		Body:     glIssue.Description,
+		State:    glIssue.State,
+		ClosedAt: glIssue.ClosedAt,
+		Labels:   append([]string{}, glIssue.Labels...),
+		WebURL:   glIssue.WebURL,
+	}
+
+	if glIssue.Author != nil {
+		issue.Author = glIssue.Author.Username
+	}
+	if glIssue.CreatedAt != nil {
+		issue.CreatedAt = *glIssue.CreatedAt
+	}
+	if glIssue.UpdatedAt != nil {
+		issue.UpdatedAt = *glIssue.UpdatedAt
+	}
+
+	issue.Assignees = make([]string, 0, len(glIssue.Assignees))
+	for _, assignee := range glIssue.Assignees {
+		issue.Assignees = append(issue.Assignees, assignee.Username)
+	}
+
+	issue.Milestone = ParseMilestone(glIssue.Milestone)
+
+	return issue
+}
+
+// ParseMergeRequest converts a client-go BasicMergeRequest to our MergeRequest struct
+func ParseMergeRequest(glMR *gitlab.BasicMergeRequest) *MergeRequest {
+	mr := &MergeRequest{
+		ID:             glMR.ID,
+		IID:            int(glMR.IID),
+		Title:          glMR.Title,
+		Description:    glMR.Description,
+		State:          glMR.State,
+		MergedAt:       glMR.MergedAt,
+		ClosedAt:       glMR.ClosedAt,
+		Labels:         append([]string{}, glMR.Labels...),
+		SourceBranch:   glMR.SourceBranch,
+		TargetBranch:   glMR.TargetBranch,
+		Draft:          glMR.Draft,
+		MergeCommitSHA: glMR.MergeCommitSHA,
+		WebURL:         glMR.WebURL,
+	}
+
+	// Squashed merge requests land as the squash commit rather than a merge commit
+	if mr.MergeCommitSHA == "" {
+		mr.MergeCommitSHA = glMR.SquashCommitSHA
+	}
+
+	if glMR.Author != nil {
+		mr.Author = glMR.Author.Username
+	}
+	if glMR.CreatedAt != nil {
+		mr.CreatedAt = *glMR.CreatedAt
+	}
+	if glMR.UpdatedAt != nil {
+		mr.UpdatedAt = *glMR.UpdatedAt
+	}
+
+	mr.Assignees = usernames(glMR.Assignees)
+	mr.Reviewers = usernames(glMR.Reviewers)
+	mr.Milestone = ParseMilestone(glMR.Milestone)
+
+	return mr
+}
+
+// ParseIssueNotes fetches all notes on an issue with pagination, oldest first
+func ParseIssueNotes(ctx context.Context, client *gitlab.Client, pid string, iid int) ([]Note, error) {
+	var notes []Note
+
+	opts := &gitlab.ListIssueNotesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("asc"),
+	}
+
+	for {
+		glNotes, resp, err := client.Notes.ListIssueNotes(pid, int64(iid), opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list issue notes")
+		}
+
+		for _, glNote := range glNotes {
+			notes = append(notes, ParseNote(glNote))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	sortNotesByTime(notes)
+	return notes, nil
+}
+
+// ParseMergeRequestNotes fetches all notes on a merge request with pagination, oldest first
+// Diff comments from code review are included with their file position
+func ParseMergeRequestNotes(ctx context.Context, client *gitlab.Client, pid string, iid int) ([]Note, error) {
+	var notes []Note
+
+	opts := &gitlab.ListMergeRequestNotesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("asc"),
+	}
+
+	for {
+		glNotes, resp, err := client.Notes.ListMergeRequestNotes(pid, int64(iid), opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list merge request notes")
+		}
+
+		for _, glNote := range glNotes {
+			notes = append(notes, ParseNote(glNote))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	sortNotesByTime(notes)
+	return notes, nil
+}
+
+// ParseApprovals fetches the users who currently approve a merge request
+func ParseApprovals(ctx context.Context, client *gitlab.Client, pid string, iid int) ([]Approval, error) {
+	config, _, err := client.MergeRequestApprovals.GetConfiguration(pid, int64(iid), gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, handleAPIError(err, "failed to get merge request approvals")
+	}
+
+	approvals := make([]Approval, 0, len(config.ApprovedBy))
+	for _, approver := range config.ApprovedBy {
+		if approver == nil || approver.User == nil {
+			continue
+		}
+		approvals = append(approvals, Approval{Author: approver.User.Username, Name: approver.User.Name})
+	}
+	return approvals, nil
+}
+
+// ParseMergeRequestChanges fills in the files and commits of a merge request
+func ParseMergeRequestChanges(ctx context.Context, client *gitlab.Client, pid string, mr *MergeRequest) error {
+	files, err := ParseMergeRequestFiles(ctx, client, pid, mr.IID)
+	if err != nil {
+		return err
+	}
+	commits, err := ParseMergeRequestCommits(ctx, client, pid, mr.IID)
+	if err != nil {
+		return err
+	}
+
+	mr.Files = files
+	mr.CommitSHAs = commits
+	return nil
+}
+
+// ParseMergeRequestFiles fetches the paths changed by a merge request with pagination
+// Renamed files contribute both their old and new path
+func ParseMergeRequestFiles(ctx context.Context, client *gitlab.Client, pid string, iid int) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	opts := &gitlab.ListMergeRequestDiffsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}
+
+	for {
+		diffs, resp, err := client.MergeRequests.ListMergeRequestDiffs(pid, int64(iid), opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list merge request diffs")
+		}
+
+		for _, diff := range diffs {
+			for _, path := range []string{diff.NewPath, diff.OldPath} {
+				if path != "" && !seen[path] {
+					seen[path] = true
+					files = append(files, path)
+				}
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+// ParseMergeRequestCommits fetches the SHAs of a merge request's commits with pagination, oldest first
+func ParseMergeRequestCommits(ctx context.Context, client *gitlab.Client, pid string, iid int) ([]string, error) {
+	var shas []string
+
+	opts := &gitlab.GetMergeRequestCommitsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}
+
+	for {
+		commits, resp, err := client.MergeRequests.GetMergeRequestCommits(pid, int64(iid), opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, handleAPIError(err, "failed to list merge request commits")
+		}
+
+		for _, commit := range commits {
+			shas = append(shas, commit.ID)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// GitLab lists merge request commits newest first
+	for i, j := 0, len(shas)-1; i < j; i, j = i+1, j-1 {
+		shas[i], shas[j] = shas[j], shas[i]
+	}
+	return shas, nil
+}
+
+// ParseNote converts a client-go Note to our Note struct
+func ParseNote(glNote *gitlab.Note) Note {
+	note := Note{
+		ID:         glNote.ID,
+		Author:     glNote.Author.Username,
+		Body:       glNote.Body,
+		System:     glNote.System,
+		CommitID:   glNote.CommitID,
+		Resolvable: glNote.Resolvable,
+		Resolved:   glNote.Resolved,
+		ResolvedBy: glNote.ResolvedBy.Username,
+	}
+
+	if glNote.CreatedAt != nil {
+		note.CreatedAt = *glNote.CreatedAt
+	}
+	if glNote.UpdatedAt != nil {
+		note.UpdatedAt = *glNote.UpdatedAt
+	}
+
+	// Diff comments anchor to the new side of the diff, or the old side for removed lines
+	if position := glNote.Position; position != nil {
+		note.Path = position.NewPath
+		note.Line = int(position.NewLine)
+		if note.Line == 0 {
+			note.Line = int(position.OldLine)
+		}
+		if note.Path == "" {
+			note.Path = position.OldPath
+		}
+		if note.CommitID == "" {
+			note.CommitID = position.HeadSHA
+		}
+	}
+
+	return note
+}
+
+// ParseMilestone converts a client-go Milestone to our Milestone struct
+func ParseMilestone(glMilestone *gitlab.Milestone) *Milestone {
+	if glMilestone == nil {
+		return nil
+	}
+
+	milestone := &Milestone{
+		ID:    glMilestone.ID,
+		IID:   int(glMilestone.IID),
+		Title: glMilestone.Title,
+		State: glMilestone.State,
+	}
+	if glMilestone.DueDate != nil {
+		due := time.Time(*glMilestone.DueDate)
+		milestone.DueDate = &due
+	}
+
+	return milestone
+}
+
+// usernames returns the usernames of a list of users
+func usernames(users []*gitlab.BasicUser) []string {
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		if user != nil {
+			names = append(names, user.Username)
+		}
+	}
+	return names
+}
+
+// handleAPIError wraps API errors with context
+// client-go already retries rate-limited (429) requests before returning
+func handleAPIError(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// sortNotesByTime sorts notes by creation time, then ID
+func sortNotesByTime(notes []Note) {
+	sort.SliceStable(notes, func(i, j int) bool {
+		if notes[i].CreatedAt.Equal(notes[j].CreatedAt) {
+			return notes[i].ID < notes[j].ID
+		}
+		return notes[i].CreatedAt.Before(notes[j].CreatedAt)
+	})
+}
@@ -0,0 +1,186 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestServer serves canned GitLab API responses keyed by request path
+func newTestServer(t *testing.T, routes map[string]func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := routes[r.URL.Path]
+		if !ok {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+}
+
+func TestListAllIssues_Paginates(t *testing.T) {
+	server := newTestServer(t, map[string]func(http.ResponseWriter, *http.Request){
+		"/api/v4/projects/group/sub/app/issues": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("X-Next-Page", "2")
+				w.Write([]byte(`[{"id": 101, "iid": 1, "title": "First", "state": "opened", "author": {"username": "alice"}}]`))
+				return
+			}
+			w.Write([]byte(`[{"id": 102, "iid": 2, "title": "Second", "state": "closed", "author": {"username": "bob"},
+				"milestone": {"id": 5, "iid": 1, "title": "v1.0", "due_date": "2024-06-01"}}]`))
+		},
+	})
+	defer server.Close()
+
+	client, err := NewClient("test-token", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	glIssues, err := ListAllIssues(context.Background(), client, ProjectPath("group/sub", "app"))
+	if err != nil {
+		t.Fatalf("ListAllIssues failed: %v", err)
+	}
+	if len(glIssues) != 2 {
+		t.Fatalf("Expected 2 issues across 2 pages, got %d", len(glIssues))
+	}
+
+	issue := ParseIssue(glIssues[1])
+	if issue.IID != 2 || issue.Author != "bob" || issue.State != "closed" {
+		t.Errorf("Unexpected parsed issue: %+v", issue)
+	}
+	if issue.Milestone == nil || issue.Milestone.Title != "v1.0" || issue.Milestone.DueDate == nil {
+		t.Errorf("Expected milestone v1.0 with due date, got %+v", issue.Milestone)
+	}
+}
+
+func TestListMergeRequestsSince_FiltersByUpdate(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	var updatedAfter string
+	server := newTestServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/api/v4/projects/group/project/merge_requests": func(w http.ResponseWriter, r *http.Request) {
+			updatedAfter = r.URL.Query().Get("updated_after")
+			w.Write([]byte(`[{"id": 1, "iid": 7, "title": "Recent"}]`))
+		},
+	})
+	defer server.Close()
+
+	client, err := NewClient("test-token", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	mrs, err := ListMergeRequestsSince(context.Background(), client, ProjectPath("group", "project"), since)
+	if err != nil {
+		t.Fatalf("ListMergeRequestsSince failed: %v", err)
+	}
+	if len(mrs) != 1 {
+		t.Errorf("Expected 1 merge request, got %d", len(mrs))
+	}
+	if parsed, err := time.Parse(time.RFC3339, updatedAfter); err != nil || !parsed.Equal(since) {
+		t.Errorf("Expected updated_after=%s, got %q", since.Format(time.RFC3339), updatedAfter)
+	}
+}
+
+func TestParseMergeRequestNotes(t *testing.T) {
+	server := newTestServer(t, map[string]func(http.ResponseWriter, *http.Request){
+		"/api/v4/projects/group/app/merge_requests/7/notes": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[
+				{"id": 3, "body": "Leaks a handle", "author": {"username": "carol"}, "created_at": "2024-05-01T11:00:00Z",
+					"resolvable": true, "resolved": true, "resolved_by": {"username": "alice"},
+					"position": {"head_sha": "abc123", "new_path": "cache.go", "new_line": 42}},
+				{"id": 2, "body": "added ~bug label", "system": true, "author": {"username": "bob"}, "created_at": "2024-05-01T10:00:00Z"}
+			]`))
+		},
+	})
+	defer server.Close()
+
+	client, err := NewClient("test-token", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	notes, err := ParseMergeRequestNotes(context.Background(), client, "group/app", 7)
+	if err != nil {
+		t.Fatalf("ParseMergeRequestNotes failed: %v", err)
+	}
+	if len(notes) != 2 || notes[0].ID != 2 {
+		t.Fatalf("Expected 2 notes oldest first, got %+v", notes)
+	}
+	if !notes[0].System {
+		t.Error("Expected the label change to be a system note")
+	}
+
+	diff := notes[1]
+	if diff.Path != "cache.go" || diff.Line != 42 || diff.CommitID != "abc123" {
+		t.Errorf("Expected diff position cache.go:42 at abc123, got %+v", diff)
+	}
+	if !diff.Resolved || diff.ResolvedBy != "alice" {
+		t.Errorf("Expected thread resolved by alice, got %+v", diff)
+	}
+}
+
+func TestParseMergeRequestChanges(t *testing.T) {
+	server := newTestServer(t, map[string]func(http.ResponseWriter, *http.Request){
+		"/api/v4/projects/group/app/merge_requests/7/diffs": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[
+				{"old_path": "parser.go", "new_path": "parser.go"},
+				{"old_path": "lexer.go", "new_path": "lexer/lexer.go", "renamed_file": true}
+			]`))
+		},
+		"/api/v4/projects/group/app/merge_requests/7/commits": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[{"id": "newest"}, {"id": "oldest"}]`))
+		},
+	})
+	defer server.Close()
+
+	client, err := NewClient("test-token", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	mr := &MergeRequest{IID: 7}
+	if err := ParseMergeRequestChanges(context.Background(), client, "group/app", mr); err != nil {
+		t.Fatalf("ParseMergeRequestChanges failed: %v", err)
+	}
+
+	wantFiles := []string{"parser.go", "lexer/lexer.go", "lexer.go"}
+	if len(mr.Files) != len(wantFiles) {
+		t.Fatalf("Expected files %v, got %v", wantFiles, mr.Files)
+	}
+	for i := range wantFiles {
+		if mr.Files[i] != wantFiles[i] {
+			t.Errorf("File %d: expected %q, got %q", i, wantFiles[i], mr.Files[i])
+		}
+	}
+	if len(mr.CommitSHAs) != 2 || mr.CommitSHAs[0] != "oldest" {
+		t.Errorf("Expected commits oldest first, got %v", mr.CommitSHAs)
+	}
+}
+
+func TestParseApprovals(t *testing.T) {
+	server := newTestServer(t, map[string]func(http.ResponseWriter, *http.Request){
+		"/api/v4/projects/group/app/merge_requests/7/approvals": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"approved": true, "approved_by": [{"user": {"username": "dave", "name": "Dave"}}]}`))
+		},
+	})
+	defer server.Close()
+
+	client, err := NewClient("test-token", server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	approvals, err := ParseApprovals(context.Background(), client, "group/app", 7)
+	if err != nil {
+		t.Fatalf("ParseApprovals failed: %v", err)
+	}
+	if len(approvals) != 1 || approvals[0].Author != "dave" || approvals[0].Name != "Dave" {
+		t.Errorf("Expected dave's approval, got %+v", approvals)
+	}
+}
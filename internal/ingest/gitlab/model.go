@@ -0,0 +1,81 @@
+package gitlab
+
+import "time"
+
+// Issue represents GitLab issue data with its notes
+type Issue struct {
+	ID        int64      `json:"id"`  // Instance-wide ID
+	IID       int        `json:"iid"` // Project-scoped number shown as #IID
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"` // opened, closed
+	Author    string     `json:"author"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	Labels    []string   `json:"labels"`
+	Assignees []string   `json:"assignees"`
+	Milestone *Milestone `json:"milestone,omitempty"`
+	Notes     []Note     `json:"notes"`
+	WebURL    string     `json:"web_url"`
+}
+
+// MergeRequest represents GitLab merge request data with notes and approvals
+// Designed to capture the same review context as a GitHub pull request
+type MergeRequest struct {
+	ID             int64      `json:"id"`  // Instance-wide ID
+	IID            int        `json:"iid"` // Project-scoped number shown as !IID
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	State          string     `json:"state"` // opened, closed, merged, locked
+	Author         string     `json:"author"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	MergedAt       *time.Time `json:"merged_at,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	Labels         []string   `json:"labels"`
+	Assignees      []string   `json:"assignees"`
+	Reviewers      []string   `json:"reviewers"`
+	Milestone      *Milestone `json:"milestone,omitempty"`
+	SourceBranch   string     `json:"source_branch"`
+	TargetBranch   string     `json:"target_branch"`
+	Draft          bool       `json:"draft"`
+	MergeCommitSHA string     `json:"merge_commit_sha,omitempty"` // Squash commit SHA when the MR was squashed
+	Notes          []Note     `json:"notes"`
+	Approvals      []Approval `json:"approvals"`
+	Files          []string   `json:"files,omitempty"`       // Paths changed by the MR (both sides of renames)
+	CommitSHAs     []string   `json:"commit_shas,omitempty"` // Commits on the MR branch, oldest first
+	WebURL         string     `json:"web_url"`
+}
+
+// Note represents a comment or system note on an issue or merge request
+// Notes with a Path are diff comments from a code review
+type Note struct {
+	ID         int64     `json:"id"`
+	Author     string    `json:"author"`
+	Body       string    `json:"body"`
+	System     bool      `json:"system"` // Generated by GitLab for process changes (labels, state, approvals)
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Path       string    `json:"path,omitempty"`
+	Line       int       `json:"line,omitempty"`
+	CommitID   string    `json:"commit_id,omitempty"`
+	Resolvable bool      `json:"resolvable"`
+	Resolved   bool      `json:"resolved"`
+	ResolvedBy string    `json:"resolved_by,omitempty"`
+}
+
+// Approval records a user who approved a merge request
+type Approval struct {
+	Author string `json:"author"`
+	Name   string `json:"name"`
+}
+
+// Milestone represents a GitLab milestone
+type Milestone struct {
+	ID      int64      `json:"id"`
+	IID     int        `json:"iid"`
+	Title   string     `json:"title"`
+	State   string     `json:"state"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
@@ -0,0 +1,391 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewClient when arguments are empty
+const (
+	URLEnv      = "JIRA_URL"
+	EmailEnv    = "JIRA_EMAIL"
+	TokenEnv    = "JIRA_API_TOKEN"
+	ProjectEnv  = "JIRA_PROJECT"
+	maxPageSize = 100
+)
+
+// searchFields are the issue fields requested from the search API
+var searchFields = []string{
+	"summary", "description", "issuetype", "status", "priority", "reporter", "assignee",
+	"labels", "fixVersions", "parent", "created", "updated", "resolutiondate", "duedate", "comment",
+}
+
+// Client is a minimal Jira REST API (v2) client for Jira Cloud and Jira Server/Data Center
+type Client struct {
+	BaseURL string // Instance root, e.g. "https://example.atlassian.net"
+
+	// Cloud selects the token-paginated search endpoint used by Jira Cloud
+	Cloud bool
+
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Jira client
+// Empty arguments are read from JIRA_URL, JIRA_EMAIL and JIRA_API_TOKEN
+// With an email the token is sent as a Jira Cloud API token (basic auth); without one it is sent
+// as a Server/Data Center personal access token (bearer auth)
+func NewClient(baseURL, email, token string) *Client {
+	if baseURL == "" {
+		baseURL = os.Getenv(URLEnv)
+	}
+	if email == "" {
+		email = os.Getenv(EmailEnv)
+	}
+	if token == "" {
+		token = os.Getenv(TokenEnv)
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &Client{
+		BaseURL:    baseURL,
+		Cloud:      isCloudURL(baseURL),
+		email:      email,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// isCloudURL reports whether a Jira instance is hosted by Atlassian
+func isCloudURL(baseURL string) bool {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(parsed.Hostname()), ".atlassian.net")
+}
+
+// ProjectJQL returns a query for every issue in a project, oldest first
+func ProjectJQL(project string) string {
+	return fmt.Sprintf(`project = "%s" ORDER BY created ASC`, strings.ReplaceAll(project, `"`, `\"`))
+}
+
+// get performs an authenticated GET and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("jira base URL is not configured (set %s)", URLEnv)
+	}
+
+	endpoint := c.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create jira request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		msg := strings.TrimSpace(string(body))
+		if retryAfter := resp.Header.Get("Retry-After"); resp.StatusCode == http.StatusTooManyRequests && retryAfter != "" {
+			msg = fmt.Sprintf("rate limited, retry after %ss", retryAfter)
+		}
+		return fmt.Errorf("jira request %s failed: %s: %s", path, resp.Status, msg)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode jira response: %w", err)
+	}
+	return nil
+}
+
+// rawUser is a Jira user as returned by the REST API
+type rawUser struct {
+	AccountID    string `json:"accountId"`
+	Name         string `json:"name"` // Server/Data Center username
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// rawComment is a comment as returned by the REST API
+type rawComment struct {
+	ID      string  `json:"id"`
+	Author  rawUser `json:"author"`
+	Body    string  `json:"body"`
+	Created string  `json:"created"`
+	Updated string  `json:"updated"`
+}
+
+// rawComments is a page of comments, embedded in search results or from the comment endpoint
+type rawComments struct {
+	Comments []rawComment `json:"comments"`
+	StartAt  int          `json:"startAt"`
+	Total    int          `json:"total"`
+}
+
+// rawIssue is an issue as returned by the search API with the changelog expanded
+type rawIssue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+		IssueType   struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Reporter    *rawUser `json:"reporter"`
+		Assignee    *rawUser `json:"assignee"`
+		Labels      []string `json:"labels"`
+		FixVersions []struct {
+			Name string `json:"name"`
+		} `json:"fixVersions"`
+		Parent *struct {
+			Key string `json:"key"`
+		} `json:"parent"`
+		Created        string       `json:"created"`
+		Updated        string       `json:"updated"`
+		ResolutionDate string       `json:"resolutiondate"`
+		DueDate        string       `json:"duedate"`
+		Comment        *rawComments `json:"comment"`
+	} `json:"fields"`
+	Changelog *struct {
+		Histories []struct {
+			ID      string  `json:"id"`
+			Author  rawUser `json:"author"`
+			Created string  `json:"created"`
+			Items   []struct {
+				Field      string `json:"field"`
+				FromString string `json:"fromString"`
+				ToString   string `json:"toString"`
+			} `json:"items"`
+		} `json:"histories"`
+	} `json:"changelog"`
+}
+
+// SearchIssues fetches every issue matching a JQL query with pagination, including comments
+// and status transitions
+// The search API embeds at most 100 changelog entries per issue, so very old transitions of
+// long-lived issues may be missing
+func SearchIssues(ctx context.Context, client *Client, jql string) ([]Issue, error) {
+	query := url.Values{}
+	query.Set("jql", jql)
+	query.Set("fields", strings.Join(searchFields, ","))
+	query.Set("expand", "changelog")
+	query.Set("maxResults", strconv.Itoa(maxPageSize))
+
+	path := "/rest/api/2/search"
+	if client.Cloud {
+		path = "/rest/api/2/search/jql"
+	}
+
+	var issues []Issue
+	startAt := 0
+	for {
+		if !client.Cloud {
+			query.Set("startAt", strconv.Itoa(startAt))
+		}
+
+		var page struct {
+			Issues        []rawIssue `json:"issues"`
+			Total         int        `json:"total"`
+			NextPageToken string     `json:"nextPageToken"`
+			IsLast        bool       `json:"isLast"`
+		}
+		if err := client.get(ctx, path, query, &page); err != nil {
+			return nil, fmt.Errorf("failed to search issues: %w", err)
+		}
+
+		for _, raw := range page.Issues {
+			issue := parseIssue(raw, client.BaseURL)
+
+			// Search results embed only the first page of comments
+			if raw.Fields.Comment != nil && raw.Fields.Comment.Total > len(raw.Fields.Comment.Comments) {
+				comments, err := ListComments(ctx, client, raw.Key)
+				if err != nil {
+					return nil, err
+				}
+				issue.Comments = comments
+			}
+
+			issues = append(issues, issue)
+		}
+
+		if client.Cloud {
+			if page.IsLast || page.NextPageToken == "" {
+				break
+			}
+			query.Set("nextPageToken", page.NextPageToken)
+			continue
+		}
+
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			break
+		}
+	}
+
+	return issues, nil
+}
+
+// ListComments fetches all comments on an issue with pagination, oldest first
+func ListComments(ctx context.Context, client *Client, key string) ([]Comment, error) {
+	var comments []Comment
+	query := url.Values{}
+	query.Set("maxResults", strconv.Itoa(maxPageSize))
+
+	for {
+		query.Set("startAt", strconv.Itoa(len(comments)))
+
+		var page rawComments
+		if err := client.get(ctx, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", query, &page); err != nil {
+			return nil, fmt.Errorf("failed to list comments of %s: %w", key, err)
+		}
+
+		for _, raw := range page.Comments {
+			comments = append(comments, parseComment(raw))
+		}
+
+		if len(page.Comments) == 0 || len(comments) >= page.Total {
+			break
+		}
+	}
+
+	return comments, nil
+}
+
+// parseIssue converts a REST API issue to our Issue struct
+// baseURL is used to build the issue's browse link
+func parseIssue(raw rawIssue, baseURL string) Issue {
+	fields := raw.Fields
+	issue := Issue{
+		ID:             raw.ID,
+		Key:            raw.Key,
+		Summary:        fields.Summary,
+		Description:    fields.Description,
+		Type:           fields.IssueType.Name,
+		Status:         fields.Status.Name,
+		StatusCategory: fields.Status.StatusCategory.Key,
+		Labels:         append([]string{}, fields.Labels...),
+		Created:        parseTime(fields.Created),
+		Updated:        parseTime(fields.Updated),
+		URL:            baseURL + "/browse/" + raw.Key,
+	}
+
+	if fields.Priority != nil {
+		issue.Priority = fields.Priority.Name
+	}
+	if fields.Reporter != nil {
+		issue.Reporter = parseUser(*fields.Reporter)
+	}
+	if fields.Assignee != nil {
+		assignee := parseUser(*fields.Assignee)
+		issue.Assignee = &assignee
+	}
+	for _, version := range fields.FixVersions {
+		issue.FixVersions = append(issue.FixVersions, version.Name)
+	}
+	if fields.Parent != nil {
+		issue.ParentKey = fields.Parent.Key
+	}
+	if resolved := parseTime(fields.ResolutionDate); !resolved.IsZero() {
+		issue.Resolved = &resolved
+	}
+	if due, err := time.Parse("2006-01-02", fields.DueDate); err == nil {
+		issue.DueDate = &due
+	}
+
+	issue.Comments = make([]Comment, 0)
+	if fields.Comment != nil {
+		for _, raw := range fields.Comment.Comments {
+			issue.Comments = append(issue.Comments, parseComment(raw))
+		}
+	}
+
+	issue.Transitions = make([]Transition, 0)
+	if raw.Changelog != nil {
+		for _, history := range raw.Changelog.Histories {
+			for _, item := range history.Items {
+				if item.Field != "status" {
+					continue
+				}
+				issue.Transitions = append(issue.Transitions, Transition{
+					ID:     history.ID,
+					Author: parseUser(history.Author),
+					From:   item.FromString,
+					To:     item.ToString,
+					At:     parseTime(history.Created),
+				})
+			}
+		}
+	}
+	sortTransitions(issue.Transitions)
+
+	return issue
+}
+
+// parseComment converts a REST API comment to our Comment struct
+func parseComment(raw rawComment) Comment {
+	return Comment{
+		ID:      raw.ID,
+		Author:  parseUser(raw.Author),
+		Body:    raw.Body,
+		Created: parseTime(raw.Created),
+		Updated: parseTime(raw.Updated),
+	}
+}
+
+// parseUser converts a REST API user to our User struct
+func parseUser(raw rawUser) User {
+	user := User{AccountID: raw.AccountID, DisplayName: raw.DisplayName, Email: raw.EmailAddress}
+	if user.DisplayName == "" {
+		user.DisplayName = raw.Name
+	}
+	return user
+}
+
+// parseTime parses Jira's timestamp format ("2024-05-01T10:00:00.000+0000"), returning zero on failure
+func parseTime(value string) time.Time {
+	for _, layout := range []string{"2006-01-02T15:04:05.000-0700", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// sortTransitions orders transitions oldest first; Cloud returns changelog entries newest first
+func sortTransitions(transitions []Transition) {
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].At.Before(transitions[j].At) })
+}
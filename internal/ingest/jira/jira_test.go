@@ -0,0 +1,138 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSearchIssues_ServerPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer pat" {
+			t.Errorf("Expected bearer auth without an email, got %q", auth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/rest/api/2/search":
+			if r.URL.Query().Get("expand") != "changelog" {
+				t.Error("Expected the changelog to be expanded")
+			}
+			if r.URL.Query().Get("startAt") == "0" {
+				w.Write([]byte(`{"startAt": 0, "total": 2, "issues": [{"id": "10001", "key": "PROJ-1", "fields": {
+					"summary": "Checkout epic", "issuetype": {"name": "Epic"},
+					"status": {"name": "In Progress", "statusCategory": {"key": "indeterminate"}},
+					"reporter": {"displayName": "Alice", "emailAddress": "alice@example.com"},
+					"created": "2024-05-01T10:00:00.000+0000", "updated": "2024-05-02T10:00:00.000+0000",
+					"comment": {"total": 0, "comments": []}}}]}`))
+				return
+			}
+			w.Write([]byte(`{"startAt": 1, "total": 2, "issues": [{"id": "10002", "key": "PROJ-2", "fields": {
+				"summary": "Pay with card", "issuetype": {"name": "Story"}, "parent": {"key": "PROJ-1"},
+				"status": {"name": "Done", "statusCategory": {"key": "done"}},
+				"reporter": {"name": "bob"}, "fixVersions": [{"name": "1.2"}], "duedate": "2024-06-01",
+				"created": "2024-05-03T10:00:00.000+0000", "resolutiondate": "2024-05-05T10:00:00.000+0000",
+				"comment": {"total": 2, "comments": [{"id": "1", "body": "first"}]}},
+				"changelog": {"histories": [
+					{"id": "h2", "author": {"displayName": "Bob"}, "created": "2024-05-05T10:00:00.000+0000",
+						"items": [{"field": "status", "fromString": "In Progress", "toString": "Done"}]},
+					{"id": "h1", "author": {"displayName": "Bob"}, "created": "2024-05-04T10:00:00.000+0000",
+						"items": [{"field": "assignee", "toString": "Bob"}, {"field": "status", "fromString": "To Do", "toString": "In Progress"}]}
+				]}}]}`))
+		case "/rest/api/2/issue/PROJ-2/comment":
+			w.Write([]byte(`{"startAt": 0, "total": 2, "comments": [
+				{"id": "1", "author": {"displayName": "Carol"}, "body": "first", "created": "2024-05-03T11:00:00.000+0000"},
+				{"id": "2", "author": {"displayName": "Dave"}, "body": "second", "created": "2024-05-03T12:00:00.000+0000"}
+			]}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv(EmailEnv, "")
+	client := NewClient(server.URL, "", "pat")
+
+	issues, err := SearchIssues(context.Background(), client, ProjectJQL("PROJ"))
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues across 2 pages, got %d", len(issues))
+	}
+
+	epic := issues[0]
+	if epic.Key != "PROJ-1" || epic.Type != "Epic" || epic.Reporter.Email != "alice@example.com" {
+		t.Errorf("Unexpected epic: %+v", epic)
+	}
+	if epic.URL != server.URL+"/browse/PROJ-1" {
+		t.Errorf("Expected browse URL, got %s", epic.URL)
+	}
+
+	story := issues[1]
+	if story.ParentKey != "PROJ-1" || story.StatusCategory != StatusCategoryDone || story.Reporter.DisplayName != "bob" {
+		t.Errorf("Unexpected story: %+v", story)
+	}
+	if story.Resolved == nil || story.DueDate == nil || len(story.FixVersions) != 1 {
+		t.Errorf("Expected resolution, due date and fix version, got %+v", story)
+	}
+	if len(story.Comments) != 2 || story.Comments[1].Author.DisplayName != "Dave" {
+		t.Errorf("Expected all comments to be fetched, got %+v", story.Comments)
+	}
+	if len(story.Transitions) != 2 || story.Transitions[0].To != "In Progress" || story.Transitions[1].To != "Done" {
+		t.Errorf("Expected status transitions oldest first, got %+v", story.Transitions)
+	}
+}
+
+func TestSearchIssues_CloudTokenPagination(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/2/search/jql" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "token" {
+			t.Error("Expected basic auth with email and API token")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("nextPageToken") == "" {
+			w.Write([]byte(`{"nextPageToken": "p2", "issues": [{"id": "1", "key": "OPS-1", "fields": {"summary": "One"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"isLast": true, "issues": [{"id": "2", "key": "OPS-2", "fields": {"summary": "Two"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "me@example.com", "token")
+	client.Cloud = true
+
+	issues, err := SearchIssues(context.Background(), client, ProjectJQL("OPS"))
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if requests != 2 || len(issues) != 2 || issues[1].Key != "OPS-2" {
+		t.Errorf("Expected 2 issues over 2 requests, got %d issues in %d requests", len(issues), requests)
+	}
+}
+
+func TestNewClient_DetectsCloud(t *testing.T) {
+	if !NewClient("https://example.atlassian.net/", "", "").Cloud {
+		t.Error("Expected atlassian.net to use the Cloud search endpoint")
+	}
+	if NewClient("https://jira.example.com", "", "").Cloud {
+		t.Error("Expected a self-hosted instance to use the Server search endpoint")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	got := parseTime("2024-05-01T10:00:00.000+0200")
+	if want := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if !parseTime("").IsZero() {
+		t.Error("Expected an empty timestamp to parse as zero")
+	}
+}
@@ -0,0 +1,56 @@
+package jira
+
+import "time"
+
+// Issue represents a Jira issue (story, bug, task, epic, ...) with its comments and status history
+type Issue struct {
+	ID             string       `json:"id"`
+	Key            string       `json:"key"` // e.g. "PROJ-123"
+	Summary        string       `json:"summary"`
+	Description    string       `json:"description"`
+	Type           string       `json:"type"` // Issue type name, e.g. "Story", "Bug", "Epic"
+	Status         string       `json:"status"`
+	StatusCategory string       `json:"status_category"` // new, indeterminate, done
+	Priority       string       `json:"priority,omitempty"`
+	Reporter       User         `json:"reporter"`
+	Assignee       *User        `json:"assignee,omitempty"`
+	Labels         []string     `json:"labels"`
+	FixVersions    []string     `json:"fix_versions,omitempty"`
+	ParentKey      string       `json:"parent_key,omitempty"` // Epic or parent issue
+	Created        time.Time    `json:"created"`
+	Updated        time.Time    `json:"updated"`
+	Resolved       *time.Time   `json:"resolved,omitempty"`
+	DueDate        *time.Time   `json:"due_date,omitempty"`
+	Comments       []Comment    `json:"comments"`
+	Transitions    []Transition `json:"transitions"` // Status changes, oldest first
+	URL            string       `json:"url"`
+}
+
+// User is a Jira account as shown on issues and comments
+// Email is empty when the account's privacy settings hide it
+type User struct {
+	AccountID   string `json:"account_id,omitempty"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email,omitempty"`
+}
+
+// Comment represents a comment on a Jira issue
+type Comment struct {
+	ID      string    `json:"id"`
+	Author  User      `json:"author"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Transition records an issue moving between workflow statuses
+type Transition struct {
+	ID     string    `json:"id"` // Changelog history ID
+	Author User      `json:"author"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+}
+
+// StatusCategoryDone is the status category of resolved issues in every Jira workflow
+const StatusCategoryDone = "done"
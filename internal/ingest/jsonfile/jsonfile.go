@@ -0,0 +1,147 @@
+// Package jsonfile reads development artifacts exported from trackers thunk has no adapter for
+package jsonfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathEnv names an artifact import file read by the orchestrator
+const PathEnv = "THUNK_ARTIFACTS_FILE"
+
+// ReadFile reads and validates the artifacts of an import file
+// Files ending in .ndjson or .jsonl hold one artifact per line; other files hold a JSON array
+// of artifacts or an object with an "artifacts" array
+func ReadFile(path string) ([]Artifact, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact file: %w", err)
+	}
+	defer file.Close()
+
+	var artifacts []Artifact
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		artifacts, err = ReadNDJSON(file)
+	default:
+		artifacts, err = ReadJSON(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return artifacts, nil
+}
+
+// ReadJSON reads and validates a JSON array of artifacts, or an object with an "artifacts" array
+func ReadJSON(r io.Reader) ([]Artifact, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []Artifact
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var document struct {
+			Artifacts []Artifact `json:"artifacts"`
+		}
+		if err := json.Unmarshal(trimmed, &document); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		artifacts = document.Artifacts
+	} else if err := json.Unmarshal(trimmed, &artifacts); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	for i := range artifacts {
+		if err := normalize(&artifacts[i]); err != nil {
+			return nil, fmt.Errorf("artifact %d: %w", i+1, err)
+		}
+	}
+	return artifacts, nil
+}
+
+// ReadNDJSON reads and validates newline-delimited JSON, one artifact per line
+// Blank lines are skipped; errors report the 1-based line number
+func ReadNDJSON(r io.Reader) ([]Artifact, error) {
+	reader := bufio.NewReader(r)
+	var artifacts []Artifact
+
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			var artifact Artifact
+			if err := json.Unmarshal(trimmed, &artifact); err != nil {
+				return nil, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+			}
+			if err := normalize(&artifact); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			artifacts = append(artifacts, artifact)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return artifacts, nil
+		}
+	}
+}
+
+// normalize validates an artifact and fills in its defaults
+func normalize(artifact *Artifact) error {
+	artifact.Type = strings.ToLower(strings.TrimSpace(artifact.Type))
+	switch artifact.Type {
+	case TypeIssue, TypePullRequest, TypeMergeRequest:
+		if artifact.Number <= 0 {
+			return fmt.Errorf("%s requires a positive number", artifact.Type)
+		}
+		if artifact.ID == "" {
+			artifact.ID = fmt.Sprintf("%s-%d", artifact.Type, artifact.Number)
+		}
+	case TypeTicket:
+		if artifact.Key == "" {
+			return fmt.Errorf("ticket requires a key")
+		}
+		if artifact.ID == "" {
+			artifact.ID = "ticket-" + artifact.Key
+		}
+	case "":
+		return fmt.Errorf("missing type")
+	default:
+		return fmt.Errorf("unknown type %q (expected %s, %s, %s or %s)",
+			artifact.Type, TypeIssue, TypePullRequest, TypeMergeRequest, TypeTicket)
+	}
+
+	if artifact.CreatedAt.IsZero() {
+		return fmt.Errorf("%s is missing created_at", artifact.ID)
+	}
+	if artifact.State == "" {
+		artifact.State = "open"
+	}
+
+	for i := range artifact.Discussions {
+		discussion := &artifact.Discussions[i]
+		if discussion.Type == "" {
+			discussion.Type = DiscussionComment
+		}
+		switch discussion.Type {
+		case DiscussionComment, DiscussionReview, DiscussionReviewThread, DiscussionEvent:
+		default:
+			return fmt.Errorf("%s discussion %d: unknown type %q", artifact.ID, i+1, discussion.Type)
+		}
+		if discussion.ID == "" {
+			discussion.ID = fmt.Sprintf("%s-discussion-%d", artifact.ID, i+1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFile_NDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artifacts.ndjson")
+	content := `{"type": "issue", "number": 12, "title": "Login fails", "created_at": "2024-05-01T10:00:00Z"}
+
+{"type": "ticket", "key": "OPS-3", "title": "Rotate certs", "state": "closed", "created_at": "2024-05-02T10:00:00Z", "discussions": [{"author": {"name": "Bob"}, "body": "done", "created_at": "2024-05-03T10:00:00Z"}]}
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("Expected 2 artifacts, got %d", len(artifacts))
+	}
+
+	if issue := artifacts[0]; issue.ID != "issue-12" || issue.State != "open" {
+		t.Errorf("Expected default ID and state, got %+v", issue)
+	}
+	ticket := artifacts[1]
+	if ticket.ID != "ticket-OPS-3" || ticket.State != "closed" {
+		t.Errorf("Unexpected ticket: %+v", ticket)
+	}
+	if len(ticket.Discussions) != 1 || ticket.Discussions[0].Type != DiscussionComment || ticket.Discussions[0].ID != "ticket-OPS-3-discussion-1" {
+		t.Errorf("Expected a defaulted comment, got %+v", ticket.Discussions)
+	}
+}
+
+func TestReadJSON_ArrayAndObject(t *testing.T) {
+	inputs := []string{
+		`[{"type": "pull_request", "number": 7, "created_at": "2024-05-01T10:00:00Z", "commit_shas": ["abc"]}]`,
+		`{"artifacts": [{"type": "pull_request", "number": 7, "created_at": "2024-05-01T10:00:00Z", "commit_shas": ["abc"]}]}`,
+	}
+
+	for _, input := range inputs {
+		artifacts, err := ReadJSON(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ReadJSON(%s) failed: %v", input, err)
+		}
+		if len(artifacts) != 1 || artifacts[0].ID != "pull_request-7" || len(artifacts[0].CommitSHAs) != 1 {
+			t.Errorf("Unexpected artifacts from %s: %+v", input, artifacts)
+		}
+	}
+}
+
+func TestReadNDJSON_ReportsLine(t *testing.T) {
+	input := `{"type": "issue", "number": 1, "created_at": "2024-05-01T10:00:00Z"}
+{"type": "story", "number": 2, "created_at": "2024-05-01T10:00:00Z"}`
+
+	_, err := ReadNDJSON(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 2") || !strings.Contains(err.Error(), `"story"`) {
+		t.Errorf("Expected an unknown type error on line 2, got %v", err)
+	}
+}
+
+func TestNormalize_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		artifact Artifact
+		wantErr  string
+	}{
+		{"missing type", Artifact{Number: 1}, "missing type"},
+		{"issue without number", Artifact{Type: "issue"}, "positive number"},
+		{"ticket without key", Artifact{Type: "ticket"}, "requires a key"},
+		{"missing created_at", Artifact{Type: "issue", Number: 1}, "created_at"},
+	}
+
+	for _, tt := range tests {
+		err := normalize(&tt.artifact)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package jsonfile
+
+import "time"
+
+// Artifact is one record of an artifact import file
+// The schema is documented in the README ("Importing Artifacts"); field names are stable
+type Artifact struct {
+	ID        string     `json:"id,omitempty"`  // Defaults to "<type>-<number>", or "ticket-<key>" for tickets
+	Type      string     `json:"type"`          // issue, pull_request, merge_request or ticket
+	Number    int        `json:"number"`        // Referenced as #N (!N for merge requests)
+	Key       string     `json:"key,omitempty"` // Ticket key referenced in commits, e.g. "OPS-12"
+	Title     string     `json:"title"`
+	Body      string     `json:"body,omitempty"`
+	State     string     `json:"state,omitempty"` // open, closed or merged; defaults to open
+	Author    Person     `json:"author"`
+	Assignees []string   `json:"assignees,omitempty"`
+	Labels    []string   `json:"labels,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	MergedAt  *time.Time `json:"merged_at,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	Milestone string     `json:"milestone,omitempty"`
+	Priority  string     `json:"priority,omitempty"`
+	Parent    string     `json:"parent,omitempty"` // Key of the epic or parent ticket
+
+	// Pull/merge request linking: any of these ties the request to the commits it shipped
+	BaseBranch     string   `json:"base_branch,omitempty"`
+	HeadBranch     string   `json:"head_branch,omitempty"`
+	MergeCommitSHA string   `json:"merge_commit_sha,omitempty"`
+	CommitSHAs     []string `json:"commit_shas,omitempty"`
+	Files          []string `json:"files,omitempty"`
+	Closes         []int    `json:"closes,omitempty"` // Numbers of the issues the request closes
+
+	Discussions []Discussion `json:"discussions,omitempty"`
+}
+
+// Person is the author of an artifact or discussion
+type Person struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// Discussion is a comment, review or process event on an artifact
+type Discussion struct {
+	ID          string    `json:"id,omitempty"`   // Defaults to the discussion's position
+	Type        string    `json:"type,omitempty"` // comment, review, review_thread or event; defaults to comment
+	Author      Person    `json:"author"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	ParentID    string    `json:"parent_id,omitempty"`    // Discussion replied to
+	ReviewState string    `json:"review_state,omitempty"` // approved, changes_requested or commented
+	FilePath    string    `json:"file_path,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	CommitHash  string    `json:"commit_hash,omitempty"`
+	Resolved    bool      `json:"resolved,omitempty"`
+	Event       string    `json:"event,omitempty"` // For events, e.g. labeled, assigned, closed
+}
+
+// Artifact types accepted in import files
+const (
+	TypeIssue        = "issue"
+	TypePullRequest  = "pull_request"
+	TypeMergeRequest = "merge_request"
+	TypeTicket       = "ticket"
+)
+
+// Discussion types accepted in import files
+const (
+	DiscussionComment      = "comment"
+	DiscussionReview       = "review"
+	DiscussionReviewThread = "review_thread"
+	DiscussionEvent        = "event"
+)
@@ -0,0 +1,336 @@
+package linear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewClient and the orchestrator
+const (
+	TokenEnv = "LINEAR_API_KEY"
+	TeamEnv  = "LINEAR_TEAM"
+
+	// DefaultEndpoint is Linear's GraphQL API
+	DefaultEndpoint = "https://api.linear.app/graphql"
+
+	// Linear limits query complexity, so issues are fetched in small pages with their nested
+	// comments and history capped per issue
+	issuePageSize  = 25
+	nestedPageSize = 50
+)
+
+// Client is a minimal Linear GraphQL API client
+type Client struct {
+	Endpoint string
+
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Linear client
+// An empty token is read from LINEAR_API_KEY; personal API keys are sent as-is and OAuth
+// access tokens as bearer tokens
+func NewClient(token string) *Client {
+	if token == "" {
+		token = os.Getenv(TokenEnv)
+	}
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// query performs an authenticated GraphQL request and decodes the response data into out
+func (c *Client) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	if c.token == "" {
+		return fmt.Errorf("linear API key is not configured (set %s)", TokenEnv)
+	}
+
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode linear query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create linear request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if strings.HasPrefix(c.token, "lin_api_") {
+		req.Header.Set("Authorization", c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("linear request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("linear request failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to decode linear response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		return fmt.Errorf("linear query failed: %s", envelope.Errors[0].Message)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode linear response data: %w", err)
+	}
+	return nil
+}
+
+const issuesQuery = `query($team: String!, $after: String, $first: Int!, $nested: Int!) {
+  issues(first: $first, after: $after, orderBy: createdAt, filter: {team: {key: {eq: $team}}}) {
+    nodes {
+      id identifier number title description url branchName priorityLabel
+      createdAt updatedAt completedAt canceledAt dueDate
+      state { name type }
+      creator { name email }
+      assignee { name email }
+      labels { nodes { name } }
+      project { name }
+      cycle { id number name startsAt endsAt }
+      parent { identifier }
+      comments(first: $nested) { nodes { id body createdAt updatedAt user { name email } } }
+      history(first: $nested) { nodes { id createdAt actor { name email } fromState { name } toState { name } } }
+    }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+// rawUser is a Linear user as returned by the GraphQL API
+type rawUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// rawIssue is an issue as returned by issuesQuery
+type rawIssue struct {
+	ID            string     `json:"id"`
+	Identifier    string     `json:"identifier"`
+	Number        float64    `json:"number"` // Linear types issue numbers as Float
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	URL           string     `json:"url"`
+	BranchName    string     `json:"branchName"`
+	PriorityLabel string     `json:"priorityLabel"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	CompletedAt   *time.Time `json:"completedAt"`
+	CanceledAt    *time.Time `json:"canceledAt"`
+	DueDate       string     `json:"dueDate"`
+	State         struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"state"`
+	Creator  *rawUser `json:"creator"`
+	Assignee *rawUser `json:"assignee"`
+	Labels   struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Project *struct {
+		Name string `json:"name"`
+	} `json:"project"`
+	Cycle *struct {
+		ID       string    `json:"id"`
+		Number   float64   `json:"number"`
+		Name     string    `json:"name"`
+		StartsAt time.Time `json:"startsAt"`
+		EndsAt   time.Time `json:"endsAt"`
+	} `json:"cycle"`
+	Parent *struct {
+		Identifier string `json:"identifier"`
+	} `json:"parent"`
+	Comments struct {
+		Nodes []struct {
+			ID        string    `json:"id"`
+			Body      string    `json:"body"`
+			CreatedAt time.Time `json:"createdAt"`
+			UpdatedAt time.Time `json:"updatedAt"`
+			User      *rawUser  `json:"user"`
+		} `json:"nodes"`
+	} `json:"comments"`
+	History struct {
+		Nodes []struct {
+			ID        string    `json:"id"`
+			CreatedAt time.Time `json:"createdAt"`
+			Actor     *rawUser  `json:"actor"`
+			FromState *struct {
+				Name string `json:"name"`
+			} `json:"fromState"`
+			ToState *struct {
+				Name string `json:"name"`
+			} `json:"toState"`
+		} `json:"nodes"`
+	} `json:"history"`
+}
+
+// ListTeamIssues fetches every issue of a team (by team key, e.g. "ENG") with pagination,
+// including comments and workflow state transitions
+// At most 50 comments and history entries are fetched per issue
+func ListTeamIssues(ctx context.Context, client *Client, team string) ([]Issue, error) {
+	variables := map[string]any{"team": team, "first": issuePageSize, "nested": nestedPageSize}
+
+	var issues []Issue
+	for {
+		var page struct {
+			Issues struct {
+				Nodes    []rawIssue `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		if err := client.query(ctx, issuesQuery, variables, &page); err != nil {
+			return nil, fmt.Errorf("failed to list issues of team %s: %w", team, err)
+		}
+
+		for _, raw := range page.Issues.Nodes {
+			issues = append(issues, parseIssue(raw))
+		}
+
+		if !page.Issues.PageInfo.HasNextPage || page.Issues.PageInfo.EndCursor == "" {
+			break
+		}
+		variables["after"] = page.Issues.PageInfo.EndCursor
+	}
+
+	return issues, nil
+}
+
+// parseIssue converts a GraphQL issue to our Issue struct
+func parseIssue(raw rawIssue) Issue {
+	issue := Issue{
+		ID:          raw.ID,
+		Identifier:  raw.Identifier,
+		Number:      int(raw.Number),
+		Title:       raw.Title,
+		Description: raw.Description,
+		State:       raw.State.Name,
+		StateType:   raw.State.Type,
+		Priority:    raw.PriorityLabel,
+		Creator:     parseUser(raw.Creator),
+		BranchName:  raw.BranchName,
+		CreatedAt:   raw.CreatedAt,
+		UpdatedAt:   raw.UpdatedAt,
+		CompletedAt: raw.CompletedAt,
+		CanceledAt:  raw.CanceledAt,
+		URL:         raw.URL,
+	}
+
+	if raw.Assignee != nil {
+		assignee := parseUser(raw.Assignee)
+		issue.Assignee = &assignee
+	}
+	issue.Labels = make([]string, 0, len(raw.Labels.Nodes))
+	for _, label := range raw.Labels.Nodes {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	if raw.Project != nil {
+		issue.Project = raw.Project.Name
+	}
+	if raw.Cycle != nil {
+		issue.Cycle = &Cycle{
+			ID:       raw.Cycle.ID,
+			Number:   int(raw.Cycle.Number),
+			Name:     raw.Cycle.Name,
+			StartsAt: raw.Cycle.StartsAt,
+			EndsAt:   raw.Cycle.EndsAt,
+		}
+	}
+	if raw.Parent != nil {
+		issue.ParentKey = raw.Parent.Identifier
+	}
+	if due, err := time.Parse("2006-01-02", raw.DueDate); err == nil {
+		issue.DueDate = &due
+	}
+
+	issue.Comments = make([]Comment, 0, len(raw.Comments.Nodes))
+	for _, comment := range raw.Comments.Nodes {
+		issue.Comments = append(issue.Comments, Comment{
+			ID:        comment.ID,
+			Author:    parseUser(comment.User),
+			Body:      comment.Body,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		})
+	}
+	sort.SliceStable(issue.Comments, func(i, j int) bool {
+		return issue.Comments[i].CreatedAt.Before(issue.Comments[j].CreatedAt)
+	})
+
+	// History also records title, label and assignee edits; only state changes are transitions
+	issue.Transitions = make([]Transition, 0)
+	for _, entry := range raw.History.Nodes {
+		if entry.ToState == nil {
+			continue
+		}
+		transition := Transition{
+			ID:     entry.ID,
+			Author: parseUser(entry.Actor),
+			To:     entry.ToState.Name,
+			At:     entry.CreatedAt,
+		}
+		if entry.FromState != nil {
+			transition.From = entry.FromState.Name
+		}
+		issue.Transitions = append(issue.Transitions, transition)
+	}
+	sort.SliceStable(issue.Transitions, func(i, j int) bool {
+		return issue.Transitions[i].At.Before(issue.Transitions[j].At)
+	})
+
+	return issue
+}
+
+// parseUser converts a GraphQL user, which is null for integrations and deleted accounts
+func parseUser(raw *rawUser) User {
+	if raw == nil {
+		return User{}
+	}
+	return User{Name: raw.Name, Email: raw.Email}
+}
+
+// ClosedAt returns when an issue was completed or canceled, or nil while it is open
+func (i *Issue) ClosedAt() *time.Time {
+	if i.CompletedAt != nil {
+		return i.CompletedAt
+	}
+	return i.CanceledAt
+}
+
+// IsClosed reports whether an issue is in a completed or canceled workflow state
+func (i *Issue) IsClosed() bool {
+	return i.StateType == StateTypeCompleted || i.StateType == StateTypeCanceled
+}
+
+// Title returns a cycle's name, falling back to "Cycle N" for unnamed cycles
+func (c *Cycle) Title() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("Cycle %d", c.Number)
+}
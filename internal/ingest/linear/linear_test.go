@@ -0,0 +1,114 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListTeamIssues_Pagination(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "lin_api_key" {
+			t.Errorf("Expected the personal API key without a bearer prefix, got %q", auth)
+		}
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if body.Variables["team"] != "ENG" {
+			t.Errorf("Expected team ENG, got %v", body.Variables["team"])
+		}
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+
+		if body.Variables["after"] == nil {
+			w.Write([]byte(`{"data": {"issues": {"nodes": [{"id": "uuid-1", "identifier": "ENG-41", "number": 41,
+				"title": "Login epic", "state": {"name": "In Progress", "type": "started"},
+				"creator": {"name": "Alice", "email": "alice@example.com"},
+				"labels": {"nodes": [{"name": "auth"}]}, "comments": {"nodes": []}, "history": {"nodes": []},
+				"createdAt": "2024-05-01T10:00:00.000Z", "updatedAt": "2024-05-02T10:00:00.000Z"}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"issues": {"nodes": [{"id": "uuid-2", "identifier": "ENG-42", "number": 42,
+			"title": "Fix login", "state": {"name": "Done", "type": "completed"}, "priorityLabel": "High",
+			"creator": null, "assignee": {"name": "Bob"}, "project": {"name": "Auth revamp"},
+			"cycle": {"id": "cy-1", "number": 4, "name": null, "startsAt": "2024-05-06T00:00:00.000Z", "endsAt": "2024-05-20T00:00:00.000Z"},
+			"parent": {"identifier": "ENG-41"}, "dueDate": "2024-05-31", "branchName": "bob/eng-42-fix-login",
+			"createdAt": "2024-05-03T10:00:00.000Z", "completedAt": "2024-05-08T10:00:00.000Z",
+			"labels": {"nodes": []},
+			"comments": {"nodes": [
+				{"id": "c2", "body": "second", "createdAt": "2024-05-04T12:00:00.000Z", "user": {"name": "Carol"}},
+				{"id": "c1", "body": "first", "createdAt": "2024-05-04T11:00:00.000Z", "user": {"name": "Dave"}}
+			]},
+			"history": {"nodes": [
+				{"id": "h3", "createdAt": "2024-05-08T10:00:00.000Z", "actor": {"name": "Bob"}, "fromState": {"name": "In Progress"}, "toState": {"name": "Done"}},
+				{"id": "h2", "createdAt": "2024-05-05T10:00:00.000Z", "actor": {"name": "Bob"}, "fromState": null, "toState": null},
+				{"id": "h1", "createdAt": "2024-05-04T10:00:00.000Z", "actor": {"name": "Bob"}, "fromState": {"name": "Todo"}, "toState": {"name": "In Progress"}}
+			]}}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "c2"}}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("lin_api_key")
+	client.Endpoint = server.URL
+
+	issues, err := ListTeamIssues(context.Background(), client, "ENG")
+	if err != nil {
+		t.Fatalf("ListTeamIssues failed: %v", err)
+	}
+	if requests != 2 || len(issues) != 2 {
+		t.Fatalf("Expected 2 issues across 2 pages, got %d issues in %d requests", len(issues), requests)
+	}
+
+	epic := issues[0]
+	if epic.Identifier != "ENG-41" || epic.IsClosed() || epic.Creator.Email != "alice@example.com" || len(epic.Labels) != 1 {
+		t.Errorf("Unexpected epic: %+v", epic)
+	}
+
+	issue := issues[1]
+	if issue.Number != 42 || !issue.IsClosed() || issue.ClosedAt() == nil || issue.ParentKey != "ENG-41" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	if issue.Creator.Name != "" || issue.Assignee == nil || issue.Assignee.Name != "Bob" {
+		t.Errorf("Expected a null creator and Bob as assignee, got %+v / %+v", issue.Creator, issue.Assignee)
+	}
+	if issue.Cycle == nil || issue.Cycle.Title() != "Cycle 4" || issue.Project != "Auth revamp" || issue.DueDate == nil {
+		t.Errorf("Expected cycle, project and due date, got %+v", issue)
+	}
+	if len(issue.Comments) != 2 || issue.Comments[0].Body != "first" {
+		t.Errorf("Expected comments oldest first, got %+v", issue.Comments)
+	}
+	if len(issue.Transitions) != 2 || issue.Transitions[0].From != "Todo" || issue.Transitions[1].To != "Done" {
+		t.Errorf("Expected only state changes, oldest first, got %+v", issue.Transitions)
+	}
+}
+
+func TestQuery_GraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer oauth-token" {
+			t.Errorf("Expected bearer auth for OAuth tokens, got %q", auth)
+		}
+		w.Write([]byte(`{"data": null, "errors": [{"message": "Authentication required"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("oauth-token")
+	client.Endpoint = server.URL
+
+	if _, err := ListTeamIssues(context.Background(), client, "ENG"); err == nil {
+		t.Error("Expected GraphQL errors to fail the request")
+	}
+}
+
+func TestNewClient_RequiresToken(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+	if _, err := ListTeamIssues(context.Background(), NewClient(""), "ENG"); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+}
@@ -0,0 +1,69 @@
+package linear
+
+import "time"
+
+// Issue represents a Linear issue with its comments and workflow state history
+type Issue struct {
+	ID          string       `json:"id"`
+	Identifier  string       `json:"identifier"` // e.g. "ENG-42"
+	Number      int          `json:"number"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"` // Markdown
+	State       string       `json:"state"`       // Workflow state name, e.g. "In Review"
+	StateType   string       `json:"state_type"`  // triage, backlog, unstarted, started, completed, canceled
+	Priority    string       `json:"priority,omitempty"`
+	Creator     User         `json:"creator"`
+	Assignee    *User        `json:"assignee,omitempty"`
+	Labels      []string     `json:"labels"`
+	Project     string       `json:"project,omitempty"`
+	Cycle       *Cycle       `json:"cycle,omitempty"`
+	ParentKey   string       `json:"parent_key,omitempty"`  // Identifier of the parent issue
+	BranchName  string       `json:"branch_name,omitempty"` // Branch name Linear suggests for the issue
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	CanceledAt  *time.Time   `json:"canceled_at,omitempty"`
+	DueDate     *time.Time   `json:"due_date,omitempty"`
+	Comments    []Comment    `json:"comments"`
+	Transitions []Transition `json:"transitions"` // Workflow state changes, oldest first
+	URL         string       `json:"url"`
+}
+
+// User is a Linear workspace member
+type User struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// Cycle is a Linear team's time-boxed sprint
+type Cycle struct {
+	ID       string    `json:"id"`
+	Number   int       `json:"number"`
+	Name     string    `json:"name,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// Comment represents a comment on a Linear issue
+type Comment struct {
+	ID        string    `json:"id"`
+	Author    User      `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Transition records an issue moving between workflow states
+type Transition struct {
+	ID     string    `json:"id"` // Issue history entry ID
+	Author User      `json:"author"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+}
+
+// Workflow state types of finished issues
+const (
+	StateTypeCompleted = "completed"
+	StateTypeCanceled  = "canceled"
+)
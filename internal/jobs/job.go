@@ -0,0 +1,124 @@
+// Package jobs runs long analysis and indexing work in the background: jobs are queued in a store,
+// claimed by workers, retried with backoff when they fail and tracked with their progress until
+// they finish, so callers can enqueue work and come back for it later.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status is where a job is in its lifecycle
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Waiting for a worker, or for its next retry
+	StatusRunning   Status = "running"   // Claimed by a worker
+	StatusSucceeded Status = "succeeded" // Finished, with its result recorded
+	StatusFailed    Status = "failed"    // Failed on its last attempt, or with a permanent error
+	StatusCanceled  Status = "canceled"  // Canceled before it finished
+)
+
+// ParseStatus parses a status name, accepting the empty string as any status
+func ParseStatus(name string) (Status, error) {
+	switch status := Status(name); status {
+	case "", StatusPending, StatusRunning, StatusSucceeded, StatusFailed, StatusCanceled:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown job status %q: expected pending, running, succeeded, failed or canceled", name)
+}
+
+// Done reports whether a job with this status has finished, successfully or not
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+var (
+	ErrNotFound    = errors.New("job not found")
+	ErrUnknownKind = errors.New("unknown job kind")
+	ErrFinished    = errors.New("job already finished")
+	ErrNotFinished = errors.New("job has not failed or been canceled")
+	ErrCanceled    = errors.New("job canceled")
+)
+
+// Progress is how far a running job has got, as its handler last reported
+type Progress struct {
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+	Message string `json:"message,omitempty"`
+}
+
+// Job is a unit of background work of a registered kind, with the parameters its handler reads
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Params      json.RawMessage `json:"params,omitempty"`
+	Status      Status          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	Progress    Progress        `json:"progress"`
+	Error       string          `json:"error,omitempty"`  // Error of the last failed attempt
+	Result      json.RawMessage `json:"result,omitempty"` // What the handler returned, once succeeded
+
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"` // Refreshed while running, so interrupted jobs can be reclaimed
+	RunAt      time.Time `json:"run_at"`     // Earliest a pending job may start; retries wait out their backoff
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// DecodeParams unmarshals the job's parameters into v
+func (j *Job) DecodeParams(v any) error {
+	if err := json.Unmarshal(j.Params, v); err != nil {
+		return Permanent(fmt.Errorf("invalid %s job parameters: %w", j.Kind, err))
+	}
+	return nil
+}
+
+// Filter selects jobs to list; zero fields match every job
+type Filter struct {
+	Kind   string
+	Status Status
+	Limit  int // Most recent jobs to return; 0 returns all
+}
+
+// matches reports whether job passes the filter's kind and status
+func (f Filter) matches(job *Job) bool {
+	return (f.Kind == "" || job.Kind == f.Kind) && (f.Status == "" || job.Status == f.Status)
+}
+
+// permanentError marks an error that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one retrying won't fix, such as invalid parameters, so the job fails on
+// the attempt that returned it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent reports whether err was marked with Permanent
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// newID returns a random job ID
+func newID() (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(random), nil
+}
@@ -0,0 +1,382 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/logging"
+)
+
+// Handler does the work of a job, reporting its progress as it goes, and returns a result that
+// is recorded on the job as JSON. Errors are retried until the job runs out of attempts, unless
+// marked with Permanent; a handler must return once ctx is done.
+type Handler func(ctx context.Context, job *Job, report func(Progress)) (any, error)
+
+// Options configures a queue's workers and retries
+type Options struct {
+	Workers      int           // Jobs run at once by Run
+	MaxAttempts  int           // Attempts a job gets before it fails
+	RetryDelay   time.Duration // Wait before the first retry, doubling for each one after it
+	PollInterval time.Duration // How often idle workers, and Wait, check the store for changes
+	Lease        time.Duration // How long a running job goes without an update before another worker may reclaim it
+}
+
+// DefaultOptions returns options for one worker retrying a job twice
+func DefaultOptions() Options {
+	return Options{
+		Workers:      1,
+		MaxAttempts:  3,
+		RetryDelay:   30 * time.Second,
+		PollInterval: time.Second,
+		Lease:        time.Minute,
+	}
+}
+
+// Queue enqueues jobs in a store and runs them with the handlers registered for their kinds
+type Queue struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	running  map[string]context.CancelFunc // Jobs running in this process, by ID
+	wake     chan struct{}
+}
+
+// NewQueue creates a queue over store; zero options take their defaults
+func NewQueue(store Store, opts Options) *Queue {
+	defaults := DefaultOptions()
+	if opts.Workers <= 0 {
+		opts.Workers = defaults.Workers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaults.MaxAttempts
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaults.RetryDelay
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaults.PollInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaults.Lease
+	}
+	return &Queue{
+		store:    store,
+		opts:     opts,
+		now:      time.Now,
+		handlers: make(map[string]Handler),
+		running:  make(map[string]context.CancelFunc),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler that runs jobs of kind
+func (q *Queue) Register(kind string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = handler
+}
+
+// Enqueue adds a pending job of kind with params marshaled to JSON
+func (q *Queue) Enqueue(ctx context.Context, kind string, params any) (*Job, error) {
+	if q.handler(kind) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s job parameters: %w", kind, err)
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.now()
+	job := &Job{
+		ID:          id,
+		Kind:        kind,
+		Params:      data,
+		Status:      StatusPending,
+		MaxAttempts: q.opts.MaxAttempts,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		RunAt:       now,
+	}
+	if err := q.store.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// Get returns the job with the given ID
+func (q *Queue) Get(ctx context.Context, id string) (*Job, error) {
+	return q.store.Get(ctx, id)
+}
+
+// List returns the jobs matching filter, most recently created first
+func (q *Queue) List(ctx context.Context, filter Filter) ([]Job, error) {
+	return q.store.List(ctx, filter)
+}
+
+// Cancel cancels a job that hasn't finished. A job running in this process is stopped at once;
+// one running elsewhere stops when its worker next saves its progress.
+func (q *Queue) Cancel(ctx context.Context, id string) (*Job, error) {
+	job, err := q.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.Done() {
+		return nil, fmt.Errorf("%w: %s is %s", ErrFinished, id, job.Status)
+	}
+
+	now := q.now()
+	job.Status = StatusCanceled
+	job.UpdatedAt, job.FinishedAt = now, now
+	if err := q.store.Update(ctx, job); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	if cancel, ok := q.running[id]; ok {
+		cancel()
+	}
+	q.mu.Unlock()
+	return job, nil
+}
+
+// Retry queues a failed or canceled job to run again, with a fresh set of attempts
+func (q *Queue) Retry(ctx context.Context, id string) (*Job, error) {
+	job, err := q.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusFailed && job.Status != StatusCanceled {
+		return nil, fmt.Errorf("%w: %s is %s", ErrNotFinished, id, job.Status)
+	}
+
+	now := q.now()
+	job.Status = StatusPending
+	job.Attempts = 0
+	job.Progress = Progress{}
+	job.Error, job.Result = "", nil
+	job.UpdatedAt, job.RunAt = now, now
+	job.StartedAt, job.FinishedAt = time.Time{}, time.Time{}
+	if err := q.store.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// Wait returns a job once it has finished, checking the store every poll interval
+func (q *Queue) Wait(ctx context.Context, id string) (*Job, error) {
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		job, err := q.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Status.Done() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run works jobs with the configured number of workers until ctx is done, logging errors of the
+// store. Jobs interrupted by ctx are returned to pending without using up an attempt.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range q.opts.Workers {
+		wg.Go(func() { q.work(ctx) })
+	}
+	wg.Wait()
+}
+
+// RunPending works jobs one at a time until none is ready to run, and returns how many it ran;
+// jobs waiting out a retry delay are left pending
+func (q *Queue) RunPending(ctx context.Context) (int, error) {
+	ran := 0
+	for {
+		job, err := q.claim(ctx)
+		if err != nil || job == nil {
+			return ran, err
+		}
+		if err := q.run(ctx, job); err != nil {
+			return ran, err
+		}
+		ran++
+	}
+}
+
+// work claims and runs jobs until ctx is done, sleeping between polls while there are none
+func (q *Queue) work(ctx context.Context) {
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := q.claim(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to claim job", "error", err)
+		}
+		if job != nil {
+			if err := q.run(ctx, job); err != nil {
+				logging.FromContext(ctx).Error("Failed to run job", "job_id", job.ID, "kind", job.Kind, "error", err)
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim claims the next job of a registered kind
+func (q *Queue) claim(ctx context.Context) (*Job, error) {
+	q.mu.Lock()
+	kinds := slices.Sorted(maps.Keys(q.handlers))
+	q.mu.Unlock()
+	now := q.now()
+	return q.store.Claim(ctx, kinds, now, now.Add(-q.opts.Lease))
+}
+
+// run runs a claimed job with its handler and records the outcome
+func (q *Queue) run(ctx context.Context, job *Job) error {
+	handler := q.handler(job.Kind)
+	if handler == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, job.Kind)
+	}
+
+	jobCtx, cancel := context.WithCancel(logging.With(ctx, "job_id", job.ID, "kind", job.Kind))
+	defer cancel()
+	log := logging.FromContext(jobCtx)
+	q.mu.Lock()
+	q.running[job.ID] = cancel
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		delete(q.running, job.ID)
+		q.mu.Unlock()
+	}()
+
+	// Progress is saved as it's reported, and at least every third of a lease so the job isn't
+	// reclaimed while it runs; a save that finds the job canceled stops it
+	var mu sync.Mutex
+	save := func(progress *Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if progress != nil {
+			job.Progress = *progress
+		}
+		job.UpdatedAt = q.now()
+		if err := q.store.Update(ctx, job); errors.Is(err, ErrCanceled) {
+			cancel()
+		} else if err != nil && ctx.Err() == nil {
+			log.Warn("Failed to save job progress", "error", err)
+		}
+	}
+	heartbeat := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(q.opts.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeat:
+				return
+			case <-ticker.C:
+				save(nil)
+			}
+		}
+	}()
+
+	log.Info("Running job", "attempt", job.Attempts, "max_attempts", job.MaxAttempts)
+	result, err := q.call(jobCtx, handler, job, func(progress Progress) { save(&progress) })
+	close(heartbeat)
+
+	mu.Lock()
+	defer mu.Unlock()
+	now := q.now()
+	job.UpdatedAt = now
+	switch {
+	case err == nil:
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			job.Status, job.Error = StatusFailed, fmt.Sprintf("failed to encode result: %v", marshalErr)
+		} else {
+			job.Status, job.Result, job.Error = StatusSucceeded, data, ""
+		}
+		job.FinishedAt = now
+	case ctx.Err() != nil:
+		// Shutting down: the attempt didn't fail, so it isn't counted
+		job.Status, job.RunAt = StatusPending, now
+		job.Attempts--
+	case jobCtx.Err() != nil:
+		// Canceled, which the store already records
+		return nil
+	case isPermanent(err) || job.Attempts >= job.MaxAttempts:
+		job.Status, job.Error, job.FinishedAt = StatusFailed, err.Error(), now
+	default:
+		job.Status, job.Error = StatusPending, err.Error()
+		job.RunAt = now.Add(q.retryDelay(job.Attempts))
+	}
+
+	log.Info("Job attempt finished", "status", job.Status)
+	// The outcome is saved even when ctx is done, so the job isn't left running
+	if err := q.store.Update(context.WithoutCancel(ctx), job); err != nil && !errors.Is(err, ErrCanceled) {
+		return err
+	}
+	return nil
+}
+
+// call runs handler, turning a panic into a permanent error so one bad job can't stop the workers
+func (q *Queue) call(ctx context.Context, handler Handler, job *Job, report func(Progress)) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("handler panicked: %v", r))
+		}
+	}()
+	return handler(ctx, job, report)
+}
+
+// retryDelay returns the wait after a job's attempt failed, doubling with each attempt
+func (q *Queue) retryDelay(attempt int) time.Duration {
+	delay := q.opts.RetryDelay
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return delay
+}
+
+// handler returns the handler registered for kind, or nil
+func (q *Queue) handler(kind string) Handler {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.handlers[kind]
+}
+
+// notify wakes an idle worker to claim a newly pending job
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
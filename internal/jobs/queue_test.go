@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testQueue creates a queue over an in-memory store with delays short enough for tests
+func testQueue() *Queue {
+	return NewQueue(NewMemoryStore(), Options{MaxAttempts: 3, RetryDelay: time.Millisecond, PollInterval: 5 * time.Millisecond})
+}
+
+func TestQueue_RunsJobWithProgress(t *testing.T) {
+	ctx := context.Background()
+	queue := testQueue()
+	queue.Register("count", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+		var params struct{ To int }
+		if err := job.DecodeParams(&params); err != nil {
+			return nil, err
+		}
+		for i := 1; i <= params.To; i++ {
+			report(Progress{Done: i, Total: params.To, Message: "Counting"})
+		}
+		return map[string]int{"counted": params.To}, nil
+	})
+
+	job, err := queue.Enqueue(ctx, "count", map[string]int{"To": 3})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if job.Status != StatusPending {
+		t.Errorf("Expected a pending job, got %s", job.Status)
+	}
+	if ran, err := queue.RunPending(ctx); err != nil || ran != 1 {
+		t.Fatalf("Expected one job run, got %d (error %v)", ran, err)
+	}
+
+	job, err = queue.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if job.Status != StatusSucceeded || job.Attempts != 1 || job.FinishedAt.IsZero() {
+		t.Errorf("Expected the job to succeed on its first attempt, got %+v", job)
+	}
+	if job.Progress != (Progress{Done: 3, Total: 3, Message: "Counting"}) {
+		t.Errorf("Expected the last progress kept, got %+v", job.Progress)
+	}
+	if string(job.Result) != `{"counted":3}` {
+		t.Errorf("Expected the result recorded, got %s", job.Result)
+	}
+}
+
+func TestQueue_UnknownKind(t *testing.T) {
+	if _, err := testQueue().Enqueue(context.Background(), "missing", nil); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("Expected ErrUnknownKind, got %v", err)
+	}
+}
+
+func TestQueue_RetriesUntilAttemptsRunOut(t *testing.T) {
+	ctx := context.Background()
+	queue := testQueue()
+	calls := 0
+	queue.Register("flaky", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+		calls++
+		return nil, errors.New("rate limited")
+	})
+
+	job, err := queue.Enqueue(ctx, "flaky", nil)
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	queue.RunPending(ctx)
+	if job, _ = queue.Get(ctx, job.ID); job.Status != StatusPending || job.Attempts != 1 || job.Error != "rate limited" {
+		t.Fatalf("Expected the job to wait for a retry, got %+v", job)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	go queue.Run(waitCtx)
+	job, err = queue.Wait(waitCtx, job.ID)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if job.Status != StatusFailed || job.Attempts != 3 || calls != 3 {
+		t.Errorf("Expected the job to fail after 3 attempts, got %+v after %d calls", job, calls)
+	}
+}
+
+func TestQueue_PermanentErrorsAreNotRetried(t *testing.T) {
+	ctx := context.Background()
+	queue := testQueue()
+	queue.Register("count", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+		var params struct{ To int }
+		return nil, job.DecodeParams(&params)
+	})
+
+	job, _ := queue.Enqueue(ctx, "count", "not an object")
+	queue.RunPending(ctx)
+	if job, _ = queue.Get(ctx, job.ID); job.Status != StatusFailed || job.Attempts != 1 {
+		t.Errorf("Expected invalid parameters to fail the job at once, got %+v", job)
+	}
+}
+
+func TestQueue_CancelAndRetry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	queue := testQueue()
+	started := make(chan struct{})
+	block := true
+	queue.Register("slow", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+		if !block {
+			return "done", nil
+		}
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	job, _ := queue.Enqueue(ctx, "slow", nil)
+	done := make(chan struct{})
+	go func() {
+		queue.RunPending(ctx)
+		close(done)
+	}()
+	<-started
+	if _, err := queue.Cancel(ctx, job.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	<-done
+	if job, _ = queue.Get(ctx, job.ID); job.Status != StatusCanceled {
+		t.Fatalf("Expected the running job to be canceled, got %s", job.Status)
+	}
+	if _, err := queue.Cancel(ctx, job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Expected ErrFinished canceling a canceled job, got %v", err)
+	}
+
+	block = false
+	if _, err := queue.Retry(ctx, job.ID); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	queue.RunPending(ctx)
+	if job, _ = queue.Get(ctx, job.ID); job.Status != StatusSucceeded || job.Attempts != 1 {
+		t.Errorf("Expected the retried job to succeed, got %+v", job)
+	}
+	if _, err := queue.Retry(ctx, job.ID); !errors.Is(err, ErrNotFinished) {
+		t.Errorf("Expected ErrNotFinished retrying a succeeded job, got %v", err)
+	}
+}
+
+func TestQueue_ShutdownReturnsJobToPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := testQueue()
+	queue.Register("slow", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	job, _ := queue.Enqueue(context.Background(), "slow", nil)
+	queue.Run(ctx)
+	if job, _ = queue.Get(context.Background(), job.ID); job.Status != StatusPending || job.Attempts != 0 {
+		t.Errorf("Expected the interrupted job back in pending without using an attempt, got %+v", job)
+	}
+}
+
+func TestQueue_RecoversPanics(t *testing.T) {
+	ctx := context.Background()
+	queue := testQueue()
+	queue.Register("broken", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+		panic("nil map")
+	})
+
+	job, _ := queue.Enqueue(ctx, "broken", nil)
+	queue.RunPending(ctx)
+	if job, _ = queue.Get(ctx, job.ID); job.Status != StatusFailed || job.Error != "handler panicked: nil map" {
+		t.Errorf("Expected a panic to fail the job, got %+v", job)
+	}
+}
+
+func TestJob_MarshalJSON(t *testing.T) {
+	job := Job{ID: "1", Kind: "index", Status: StatusPending, Params: json.RawMessage(`{"repository":"."}`)}
+	data, err := json.Marshal(job)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded map[string]any
+	json.Unmarshal(data, &decoded)
+	if _, ok := decoded["finished_at"]; ok {
+		t.Errorf("Expected unset times omitted, got %s", data)
+	}
+	if decoded["params"].(map[string]any)["repository"] != "." {
+		t.Errorf("Expected params embedded as JSON, got %s", data)
+	}
+}
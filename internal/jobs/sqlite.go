@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver
+)
+
+// DatabaseEnv overrides where DefaultDatabasePath puts the job database
+const DatabaseEnv = "THUNK_JOBS_DB"
+
+// DefaultDatabasePath returns the job database: THUNK_JOBS_DB if set, otherwise jobs.sqlite in the
+// user config directory
+func DefaultDatabasePath() (string, error) {
+	if path := os.Getenv(DatabaseEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "thunk", "jobs.sqlite"), nil
+}
+
+// SQLiteStore keeps jobs in a SQLite database, so they survive restarts and can be enqueued,
+// worked and queried by separate processes sharing the file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+const jobsSchema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id               TEXT PRIMARY KEY,
+	kind             TEXT NOT NULL,
+	params           TEXT NOT NULL DEFAULT '',
+	status           TEXT NOT NULL,
+	attempts         INTEGER NOT NULL DEFAULT 0,
+	max_attempts     INTEGER NOT NULL,
+	progress_done    INTEGER NOT NULL DEFAULT 0,
+	progress_total   INTEGER NOT NULL DEFAULT 0,
+	progress_message TEXT NOT NULL DEFAULT '',
+	error            TEXT NOT NULL DEFAULT '',
+	result           TEXT NOT NULL DEFAULT '',
+	created_at       INTEGER NOT NULL,
+	updated_at       INTEGER NOT NULL,
+	run_at           INTEGER NOT NULL,
+	started_at       INTEGER NOT NULL DEFAULT 0,
+	finished_at      INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS jobs_claim ON jobs (status, run_at);
+`
+
+// jobColumns lists the columns scanJob reads, in order
+const jobColumns = `id, kind, params, status, attempts, max_attempts, progress_done, progress_total, progress_message,
+	error, result, created_at, updated_at, run_at, started_at, finished_at`
+
+// NewSQLiteStore opens or creates the job database at path
+func NewSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("job store path is required")
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create job store directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store: %w", err)
+	}
+	// A single connection serializes this process's writers; other processes wait on the busy timeout
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, "PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to configure job store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, jobsSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create job store schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Create adds a new job
+func (s *SQLiteStore) Create(ctx context.Context, job *Job) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO jobs (`+jobColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.Kind, string(job.Params), string(job.Status), job.Attempts, job.MaxAttempts,
+		job.Progress.Done, job.Progress.Total, job.Progress.Message, job.Error, string(job.Result),
+		unixNano(job.CreatedAt), unixNano(job.UpdatedAt), unixNano(job.RunAt), unixNano(job.StartedAt), unixNano(job.FinishedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// Get returns the job with the given ID
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Job, error) {
+	job, err := scanJob(s.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// List returns the jobs matching filter, most recently created first
+func (s *SQLiteStore) List(ctx context.Context, filter Filter) ([]Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE (? = '' OR kind = ?) AND (? = '' OR status = ?) ORDER BY created_at DESC, id"
+	args := []any{filter.Kind, filter.Kind, string(filter.Status), string(filter.Status)}
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Update saves a job, unless it was canceled while running
+func (s *SQLiteStore) Update(ctx context.Context, job *Job) error {
+	// Only a pending or canceled job may replace a cancellation, as retrying or canceling again does
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE jobs SET status = ?, attempts = ?, max_attempts = ?, progress_done = ?, progress_total = ?,
+			progress_message = ?, error = ?, result = ?, updated_at = ?, run_at = ?, started_at = ?, finished_at = ?
+		WHERE id = ? AND (status != 'canceled' OR ? IN ('pending', 'canceled'))`,
+		string(job.Status), job.Attempts, job.MaxAttempts, job.Progress.Done, job.Progress.Total,
+		job.Progress.Message, job.Error, string(job.Result), unixNano(job.UpdatedAt), unixNano(job.RunAt),
+		unixNano(job.StartedAt), unixNano(job.FinishedAt), job.ID, string(job.Status))
+	if err != nil {
+		return fmt.Errorf("failed to update job %s: %w", job.ID, err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update job %s: %w", job.ID, err)
+	} else if updated == 0 {
+		if _, err := s.Get(ctx, job.ID); err != nil {
+			return err
+		}
+		return ErrCanceled
+	}
+	return nil
+}
+
+// Claim marks the oldest claimable job of one of kinds as running and returns it, in one statement
+// so workers sharing the database never claim the same job
+func (s *SQLiteStore) Claim(ctx context.Context, kinds []string, now, staleBefore time.Time) (*Job, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+	args := []any{unixNano(now), unixNano(now)}
+	for _, kind := range kinds {
+		args = append(args, kind)
+	}
+	args = append(args, unixNano(now), unixNano(staleBefore))
+
+	job, err := scanJob(s.db.QueryRowContext(ctx, `
+		UPDATE jobs SET status = 'running', attempts = attempts + 1, started_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE kind IN (?`+strings.Repeat(", ?", len(kinds)-1)+`)
+				AND ((status = 'pending' AND run_at <= ?) OR (status = 'running' AND updated_at < ?))
+			ORDER BY run_at, created_at LIMIT 1)
+		RETURNING `+jobColumns, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// scanJob reads a job from a row of jobColumns
+func scanJob(row interface{ Scan(...any) error }) (*Job, error) {
+	var job Job
+	var params, status, result string
+	var createdAt, updatedAt, runAt, startedAt, finishedAt int64
+	err := row.Scan(&job.ID, &job.Kind, &params, &status, &job.Attempts, &job.MaxAttempts,
+		&job.Progress.Done, &job.Progress.Total, &job.Progress.Message, &job.Error, &result,
+		&createdAt, &updatedAt, &runAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = Status(status)
+	if params != "" {
+		job.Params = []byte(params)
+	}
+	if result != "" {
+		job.Result = []byte(result)
+	}
+	job.CreatedAt, job.UpdatedAt, job.RunAt = fromUnixNano(createdAt), fromUnixNano(updatedAt), fromUnixNano(runAt)
+	job.StartedAt, job.FinishedAt = fromUnixNano(startedAt), fromUnixNano(finishedAt)
+	return &job, nil
+}
+
+// unixNano stores a time as nanoseconds since the epoch, so times compare as integers; the zero
+// time is stored as 0
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano reads a time stored by unixNano
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
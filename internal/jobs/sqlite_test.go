@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testStores returns an in-memory store and a SQLite store in a temporary directory
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	sqlite, err := NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "jobs.sqlite"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	return map[string]Store{"memory": NewMemoryStore(), "sqlite": sqlite}
+}
+
+func TestStores_ClaimAndList(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			jobs := []Job{
+				{ID: "a", Kind: "index", Status: StatusPending, MaxAttempts: 3, CreatedAt: base, UpdatedAt: base, RunAt: base},
+				{ID: "b", Kind: "analyze", Status: StatusPending, MaxAttempts: 3, CreatedAt: base.Add(time.Second), UpdatedAt: base, RunAt: base},
+				{ID: "c", Kind: "index", Status: StatusPending, MaxAttempts: 3, CreatedAt: base.Add(2 * time.Second), UpdatedAt: base, RunAt: base.Add(time.Hour)},
+			}
+			for i := range jobs {
+				if err := store.Create(ctx, &jobs[i]); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+			}
+
+			now := base.Add(time.Minute)
+			claimed, err := store.Claim(ctx, []string{"index"}, now, now.Add(-time.Minute))
+			if err != nil || claimed == nil || claimed.ID != "a" || claimed.Status != StatusRunning || claimed.Attempts != 1 || !claimed.StartedAt.Equal(now) {
+				t.Fatalf("Expected job a claimed, got %+v (error %v)", claimed, err)
+			}
+			// b is of another kind and c waits for its retry
+			if claimed, err := store.Claim(ctx, []string{"index"}, now, now.Add(-time.Minute)); claimed != nil || err != nil {
+				t.Errorf("Expected nothing left to claim, got %+v (error %v)", claimed, err)
+			}
+			// A running job that stopped being updated is reclaimed
+			later := now.Add(time.Hour)
+			claimed, err = store.Claim(ctx, []string{"index"}, later, later.Add(-time.Minute))
+			if err != nil || claimed == nil || claimed.ID != "a" || claimed.Attempts != 2 {
+				t.Errorf("Expected the stale job a reclaimed, got %+v (error %v)", claimed, err)
+			}
+
+			listed, err := store.List(ctx, Filter{Kind: "index"})
+			if err != nil || len(listed) != 2 || listed[0].ID != "c" || listed[1].ID != "a" {
+				t.Errorf("Expected index jobs newest first, got %+v (error %v)", listed, err)
+			}
+			listed, _ = store.List(ctx, Filter{Status: StatusPending, Limit: 1})
+			if len(listed) != 1 || listed[0].ID != "c" {
+				t.Errorf("Expected the newest pending job, got %+v", listed)
+			}
+			if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStores_UpdateKeepsCancellation(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			job := Job{ID: "a", Kind: "index", Status: StatusCanceled, MaxAttempts: 3, CreatedAt: base, UpdatedAt: base, RunAt: base}
+			if err := store.Create(ctx, &job); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+
+			running := job
+			running.Status, running.Progress = StatusRunning, Progress{Done: 1, Total: 2}
+			if err := store.Update(ctx, &running); !errors.Is(err, ErrCanceled) {
+				t.Errorf("Expected ErrCanceled saving a canceled job's progress, got %v", err)
+			}
+
+			retried := job
+			retried.Status, retried.Result = StatusPending, []byte(`{"indexed":2}`)
+			if err := store.Update(ctx, &retried); err != nil {
+				t.Fatalf("Expected a canceled job to be retried, got %v", err)
+			}
+			got, err := store.Get(ctx, "a")
+			if err != nil || got.Status != StatusPending || string(got.Result) != `{"indexed":2}` || !got.CreatedAt.Equal(base) {
+				t.Errorf("Expected the job saved as pending, got %+v (error %v)", got, err)
+			}
+
+			missing := Job{ID: "missing", Status: StatusRunning}
+			if err := store.Update(ctx, &missing); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_SharedBetweenQueues(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.sqlite")
+	open := func() *Queue {
+		store, err := NewSQLiteStore(ctx, path)
+		if err != nil {
+			t.Fatalf("NewSQLiteStore failed: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		queue := NewQueue(store, Options{})
+		queue.Register("index", func(ctx context.Context, job *Job, report func(Progress)) (any, error) {
+			return "indexed", nil
+		})
+		return queue
+	}
+
+	// One process enqueues and another works the job
+	job, err := open().Enqueue(ctx, "index", map[string]string{"repository": "."})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	worker := open()
+	if ran, err := worker.RunPending(ctx); err != nil || ran != 1 {
+		t.Fatalf("Expected the job run by the other queue, got %d (error %v)", ran, err)
+	}
+	if job, err = worker.Get(ctx, job.ID); err != nil || job.Status != StatusSucceeded || string(job.Result) != `"indexed"` {
+		t.Errorf("Expected the job to succeed, got %+v (error %v)", job, err)
+	}
+}
@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store keeps jobs for a queue. Workers in several processes may share a store that persists, so
+// claiming a job must be atomic.
+type Store interface {
+	// Create adds a new job
+	Create(ctx context.Context, job *Job) error
+
+	// Get returns the job with the given ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// List returns the jobs matching filter, most recently created first
+	List(ctx context.Context, filter Filter) ([]Job, error)
+
+	// Update saves a job. Saving a job that is canceled in the store as running, succeeded or
+	// failed returns ErrCanceled, so a worker can't overwrite a cancellation made elsewhere.
+	Update(ctx context.Context, job *Job) error
+
+	// Claim marks the oldest claimable job of one of kinds as running and returns it, or nil when
+	// there is none. Pending jobs are claimable once their RunAt has passed, and running jobs once
+	// they were last updated before staleBefore, their worker having stopped.
+	Claim(ctx context.Context, kinds []string, now, staleBefore time.Time) (*Job, error)
+
+	// Close releases the store
+	Close() error
+}
+
+// MemoryStore keeps jobs in memory, for a queue whose jobs needn't outlive the process
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+// Create adds a new job
+func (s *MemoryStore) Create(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *job
+	s.jobs[job.ID] = &stored
+	return nil
+}
+
+// Get returns the job with the given ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *job
+	return &found, nil
+}
+
+// List returns the jobs matching filter, most recently created first
+func (s *MemoryStore) List(ctx context.Context, filter Filter) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if filter.matches(job) {
+			jobs = append(jobs, *job)
+		}
+	}
+	slices.SortFunc(jobs, func(a, b Job) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if filter.Limit > 0 && len(jobs) > filter.Limit {
+		jobs = jobs[:filter.Limit]
+	}
+	return jobs, nil
+}
+
+// Update saves a job, unless it was canceled while running
+func (s *MemoryStore) Update(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.jobs[job.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if overridesCancel(stored.Status, job.Status) {
+		return ErrCanceled
+	}
+	*stored = *job
+	return nil
+}
+
+// Claim marks the oldest claimable job of one of kinds as running and returns it
+func (s *MemoryStore) Claim(ctx context.Context, kinds []string, now, staleBefore time.Time) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var oldest *Job
+	for _, job := range s.jobs {
+		if !slices.Contains(kinds, job.Kind) || !claimable(job, now, staleBefore) {
+			continue
+		}
+		if oldest == nil || job.RunAt.Before(oldest.RunAt) || (job.RunAt.Equal(oldest.RunAt) && job.CreatedAt.Before(oldest.CreatedAt)) {
+			oldest = job
+		}
+	}
+	if oldest == nil {
+		return nil, nil
+	}
+	oldest.Status = StatusRunning
+	oldest.Attempts++
+	oldest.StartedAt, oldest.UpdatedAt = now, now
+	claimed := *oldest
+	return &claimed, nil
+}
+
+// Close does nothing; the jobs are dropped with the store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// claimable reports whether a worker may claim job
+func claimable(job *Job, now, staleBefore time.Time) bool {
+	switch job.Status {
+	case StatusPending:
+		return !job.RunAt.After(now)
+	case StatusRunning:
+		return job.UpdatedAt.Before(staleBefore)
+	}
+	return false
+}
+
+// overridesCancel reports whether saving a job as status would overwrite its cancellation
+func overridesCancel(stored, status Status) bool {
+	return stored == StatusCanceled && (status == StatusRunning || status == StatusSucceeded || status == StatusFailed)
+}
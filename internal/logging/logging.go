@@ -0,0 +1,145 @@
+// Package logging holds the structured logger the pipeline reports its progress and warnings on.
+// Packages log through FromContext, so the attributes a caller attaches to a context - the run,
+// repository or episode being worked on - are carried by every record logged under it. Library
+// consumers that don't want the pipeline's output set a discarding logger with SetLogger(Discard()).
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Attribute keys shared by the pipeline's log records
+const (
+	RunKey        = "run_id"
+	RepositoryKey = "repository"
+	EpisodeKey    = "episode_id"
+	ComponentKey  = "component"
+)
+
+// Format is how log records are written
+type Format string
+
+const (
+	FormatText Format = "text" // key=value pairs, one record per line
+	FormatJSON Format = "json" // a JSON object per line
+)
+
+// ParseFormat parses a log format name
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(s))); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	case "":
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (expected text or json)", s)
+	}
+}
+
+// ParseLevel parses a log level name: debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if strings.TrimSpace(s) == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// Options configures a logger made by New
+type Options struct {
+	Level  slog.Level
+	Format Format
+	Output io.Writer // Where records are written; stderr when nil
+}
+
+// New creates a logger writing records at or above the configured level in the configured format
+func New(opts Options) *slog.Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	if opts.Format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(output, handlerOpts))
+	}
+	return slog.New(slog.NewTextHandler(output, handlerOpts))
+}
+
+// Discard returns a logger that drops every record
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(New(Options{}))
+}
+
+// Logger returns the logger the pipeline logs on: text records at info level and above on stderr
+// unless SetLogger replaced it
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// SetLogger replaces the logger the pipeline logs on; nil discards every record
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = Discard()
+	}
+	logger.Store(l)
+}
+
+// contextKey keys the attributes attached to a context
+type contextKey struct{}
+
+// With returns a context carrying attributes, given as slog key-value pairs or attrs, that every
+// record logged through FromContext under it includes
+func With(ctx context.Context, args ...any) context.Context {
+	if len(args) == 0 {
+		return ctx
+	}
+	attached, _ := ctx.Value(contextKey{}).([]any)
+	return context.WithValue(ctx, contextKey{}, append(attached[:len(attached):len(attached)], args...))
+}
+
+// FromContext returns the logger with the attributes attached to ctx
+func FromContext(ctx context.Context) *slog.Logger {
+	l := Logger()
+	if attached, _ := ctx.Value(contextKey{}).([]any); len(attached) > 0 {
+		return l.With(attached...)
+	}
+	return l
+}
+
+// runKey keys the run ID attached to a context
+type runKey struct{}
+
+// WithRun returns a context identifying a run by a new random ID, logged as run_id, unless ctx
+// already belongs to one
+func WithRun(ctx context.Context) context.Context {
+	if RunID(ctx) != "" {
+		return ctx
+	}
+	b := make([]byte, 6)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+	return With(context.WithValue(ctx, runKey{}, id), RunKey, id)
+}
+
+// RunID returns the ID of the run ctx belongs to, or "" outside a run
+func RunID(ctx context.Context) string {
+	id, _ := ctx.Value(runKey{}).(string)
+	return id
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"WARN", slog.LevelWarn, false},
+		{" error ", slog.LevelError, false},
+		{"loud", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatText, "text": FormatText, "JSON": FormatJSON} {
+		got, err := ParseFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
+}
+
+func TestFromContext_Attributes(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetLogger(Logger())
+	SetLogger(New(Options{Format: FormatJSON, Output: &buf}))
+
+	ctx := WithRun(context.Background())
+	ctx = With(ctx, RepositoryKey, "owner/repo")
+	episodeCtx := With(ctx, EpisodeKey, "ep-1")
+	FromContext(episodeCtx).Info("generated")
+	FromContext(ctx).Debug("hidden")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 record at info level, got %d: %s", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record[RunKey] != RunID(ctx) || RunID(ctx) == "" {
+		t.Errorf("run_id = %v, want %q", record[RunKey], RunID(ctx))
+	}
+	if record[RepositoryKey] != "owner/repo" || record[EpisodeKey] != "ep-1" {
+		t.Errorf("record is missing context attributes: %v", record)
+	}
+}
+
+func TestWithRun_KeepsExistingRun(t *testing.T) {
+	ctx := WithRun(context.Background())
+	if again := WithRun(ctx); RunID(again) != RunID(ctx) {
+		t.Errorf("WithRun replaced run %q with %q", RunID(ctx), RunID(again))
+	}
+	if other := WithRun(context.Background()); RunID(other) == RunID(ctx) {
+		t.Error("separate runs should get separate IDs")
+	}
+}
+
+func TestWith_DoesNotShareAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetLogger(Logger())
+	SetLogger(New(Options{Output: &buf}))
+
+	base := With(context.Background(), "a", 1)
+	first := With(base, EpisodeKey, "first")
+	With(base, EpisodeKey, "second")
+	FromContext(first).Info("message")
+
+	if strings.Contains(buf.String(), "second") || !strings.Contains(buf.String(), "episode_id=first") {
+		t.Errorf("sibling contexts shared attributes: %s", buf.String())
+	}
+}
+
+func TestSetLogger_NilDiscards(t *testing.T) {
+	defer SetLogger(Logger())
+	SetLogger(nil)
+	if Logger().Enabled(context.Background(), slog.LevelError) {
+		t.Error("a nil logger should discard every record")
+	}
+}
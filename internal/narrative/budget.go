@@ -0,0 +1,528 @@
+package narrative
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// DefaultContextWindow is the context window assumed for models ContextWindow doesn't know, in tokens.
+const DefaultContextWindow = 8192
+
+// contextWindows are the context windows of known models, in tokens, by model name prefix.
+var contextWindows = map[string]int{
+	"gpt-3.5-turbo": 16385,
+	"gpt-4":         8192,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4.1":       1047576,
+	"gpt-5":         400000,
+	"o1":            200000,
+	"o3":            200000,
+	"o4-mini":       200000,
+}
+
+// ContextWindow returns the context window of a model in tokens, from the longest known prefix
+// of its name, or DefaultContextWindow for unknown models.
+func ContextWindow(model string) int {
+	window, longest := DefaultContextWindow, 0
+	for prefix, size := range contextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > longest {
+			window, longest = size, len(prefix)
+		}
+	}
+	return window
+}
+
+// promptMargin is the share of the context window left free for the chat message framing and
+// for the error of estimated token counts.
+const promptMargin = 0.05
+
+// Default shares of the prompt budget each condensable section may take.
+const (
+	defaultContextShare   = 0.5
+	defaultHistoryShare   = 0.25
+	defaultArtifactsShare = 0.15
+	defaultDiffsShare     = 0.2
+	defaultExemplarsShare = 0.15
+)
+
+var ErrPromptTooLarge = errors.New("prompt exceeds its token budget")
+
+// PromptBudget limits the tokens of assembled prompts so they fit the model's context window.
+// Sections larger than their share of MaxTokens are condensed: retrieved context keeps its most
+// relevant chunks, the last one truncated, while timelines, artifact lists, story arcs and code
+// diffs keep their leading entries, and few-shot exemplars those that fit whole. If the prompt is
+// still too long, exemplars go first, then diffs, then context, then history, then artifacts.
+type PromptBudget struct {
+	// MaxTokens is the most tokens a prompt may take; 0 is unlimited
+	MaxTokens int
+
+	// Tokenizer counts tokens (default: rag.DefaultTokenizer, an estimate)
+	Tokenizer rag.Tokenizer
+
+	// ContextShare is the share of MaxTokens retrieved context may take (default 0.5)
+	ContextShare float64
+
+	// HistoryShare is the share an episode's timeline or a project's story arcs may take (default 0.25)
+	HistoryShare float64
+
+	// ArtifactsShare is the share an episode's artifact list may take (default 0.15)
+	ArtifactsShare float64
+
+	// DiffsShare is the share an episode's code diffs may take (default 0.2)
+	DiffsShare float64
+
+	// ExemplarsShare is the share few-shot exemplars may take (default 0.15)
+	ExemplarsShare float64
+}
+
+// NewPromptBudget returns the budget for prompts to config's model: its context window, less the
+// tokens reserved for the response and a small margin. A contextWindow of 0 looks up the model's.
+func NewPromptBudget(config LLMConfig, contextWindow int, tokenizer rag.Tokenizer) PromptBudget {
+	if contextWindow <= 0 {
+		contextWindow = ContextWindow(config.Model)
+	}
+	maxTokens := contextWindow - config.MaxTokens - int(float64(contextWindow)*promptMargin)
+	return PromptBudget{MaxTokens: max(maxTokens, 1), Tokenizer: tokenizer}
+}
+
+// tokenizer returns the configured tokenizer or the default one.
+func (b PromptBudget) tokenizer() rag.Tokenizer {
+	if b.Tokenizer == nil {
+		return rag.DefaultTokenizer
+	}
+	return b.Tokenizer
+}
+
+// limit returns a share of MaxTokens, or of the default share when share is 0.
+func (b PromptBudget) limit(share, defaultShare float64) int {
+	if share <= 0 {
+		share = defaultShare
+	}
+	return int(float64(b.MaxTokens) * share)
+}
+
+// promptSection is a part of a prompt's data the budget can condense.
+type promptSection struct {
+	limit  int                 // Tokens the section may take before anything else is condensed
+	tokens func() int          // Current size of the section
+	shrink func(maxTokens int) // Condenses the section to at most maxTokens, emptying it at 0
+}
+
+// renderWithin renders data fit to the budget, recording the prompt's provenance when traced.
+func (t *PromptTemplates) renderWithin(typ PromptType, data func() any, sections []promptSection) (string, error) {
+	if t.trace == nil {
+		return t.fit(typ, data, sections)
+	}
+
+	supplied := promptContext(data())
+	prompt, err := t.fit(typ, data, sections)
+	if err != nil {
+		return "", err
+	}
+	version, err := t.Version(typ)
+	if err != nil {
+		return "", err
+	}
+	*t.trace = newProvenance(typ, version, prompt, supplied, promptContext(data()))
+	return prompt, nil
+}
+
+// fit renders data, first condensing sections over their limit, then condensing them in order
+// until the prompt fits the budget.
+func (t *PromptTemplates) fit(typ PromptType, data func() any, sections []promptSection) (string, error) {
+	if t.budget.MaxTokens <= 0 {
+		return t.Render(typ, data())
+	}
+	tok := t.budget.tokenizer()
+
+	for _, section := range sections {
+		if section.tokens() > section.limit {
+			section.shrink(section.limit)
+		}
+	}
+	for {
+		prompt, err := t.Render(typ, data())
+		if err != nil {
+			return "", err
+		}
+		over := tok.CountTokens(prompt) - t.budget.MaxTokens
+		if over <= 0 {
+			return prompt, nil
+		}
+
+		shrunk := false
+		for _, section := range sections {
+			size := section.tokens()
+			if size == 0 {
+				continue
+			}
+			section.shrink(max(size-over, 0))
+			if section.tokens() < size {
+				shrunk = true
+				break
+			}
+		}
+		if !shrunk {
+			return "", fmt.Errorf("%w: %d tokens over the %d token budget with every section condensed", ErrPromptTooLarge, over, t.budget.MaxTokens)
+		}
+	}
+}
+
+// chunkOverhead approximates the tokens of the heading each context chunk is listed under.
+const chunkOverhead = 16
+
+// minTruncatedChunk is the fewest tokens worth keeping of a chunk cut short; a chunk that
+// would be left with less is dropped instead.
+const minTruncatedChunk = 48
+
+// truncationMarker ends text cut short to fit the budget.
+const truncationMarker = " [...]"
+
+// chunkTokens returns the tokens context chunk texts take in a prompt, headings included.
+func chunkTokens(texts []string, tok rag.Tokenizer) int {
+	tokens := 0
+	for _, text := range texts {
+		tokens += chunkOverhead + tok.CountTokens(text)
+	}
+	return tokens
+}
+
+// condenseChunks keeps the leading chunk texts that fit in maxTokens, headings included, and the
+// beginning of the next one when enough of it fits.
+func condenseChunks(texts []string, maxTokens int, tok rag.Tokenizer) []string {
+	kept := make([]string, 0, len(texts))
+	remaining := maxTokens
+	for _, text := range texts {
+		tokens := chunkOverhead + tok.CountTokens(text)
+		if tokens <= remaining {
+			kept = append(kept, text)
+			remaining -= tokens
+			continue
+		}
+		if remaining-chunkOverhead >= minTruncatedChunk {
+			if truncated := truncateTokens(text, remaining-chunkOverhead, tok); truncated != "" {
+				kept = append(kept, truncated)
+			}
+		}
+		break
+	}
+	return kept
+}
+
+// truncateTokens cuts text at a word boundary to at most maxTokens, marker included, or returns ""
+// when not even one word fits.
+func truncateTokens(text string, maxTokens int, tok rag.Tokenizer) string {
+	if tok.CountTokens(text) <= maxTokens {
+		return text
+	}
+	var cuts []int
+	inWord := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if space && inWord {
+			cuts = append(cuts, i)
+		}
+		inWord = !space
+	}
+
+	// Binary search for the longest prefix that fits
+	best := ""
+	lo, hi := 0, len(cuts)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		candidate := text[:cuts[mid]] + truncationMarker
+		if tok.CountTokens(candidate) <= maxTokens {
+			best, lo = candidate, mid+1
+		} else {
+			hi = mid - 1
+		}
+	}
+	return best
+}
+
+// condenseLines keeps the leading lines of a Markdown section that fit in maxTokens, noting how
+// many lines were left out; a trailing blank line is kept, and "" is returned when nothing fits.
+func condenseLines(text string, maxTokens int, tok rag.Tokenizer) string {
+	if tok.CountTokens(text) <= maxTokens {
+		return text
+	}
+	body, trailer := text, ""
+	if strings.HasSuffix(text, "\n\n") {
+		body, trailer = text[:len(text)-1], "\n"
+	}
+	lines := strings.SplitAfter(body, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// Lines are tokenized separately, so summing their counts estimates a prefix without re-counting it
+	prefix := make([]int, len(lines)+1)
+	for i, line := range lines {
+		prefix[i+1] = prefix[i] + tok.CountTokens(line)
+	}
+	for keep := len(lines) - 1; keep >= 0; keep-- {
+		marker := fmt.Sprintf("- ... %d more lines left out to fit the context window\n", len(lines)-keep)
+		if prefix[keep]+tok.CountTokens(marker+trailer) > maxTokens {
+			continue
+		}
+		condensed := strings.Join(lines[:keep], "") + marker + trailer
+		if tok.CountTokens(condensed) <= maxTokens {
+			return condensed
+		}
+	}
+	return ""
+}
+
+// episodeSections are the parts of an episode prompt the budget condenses, in the order they give way.
+func (b PromptBudget) episodeSections(data *EpisodePromptData) []promptSection {
+	tok := b.tokenizer()
+	return []promptSection{
+		b.exemplarsSection(&data.Exemplars, tok),
+		{
+			limit:  b.limit(b.DiffsShare, defaultDiffsShare),
+			tokens: func() int { return chunkTokens(diffTexts(data.Diffs), tok) },
+			shrink: func(maxTokens int) { data.Diffs = condenseDiffs(data.Diffs, maxTokens, tok) },
+		},
+		{
+			limit: b.limit(b.ContextShare, defaultContextShare),
+			tokens: func() int {
+				return chunkTokens(contextTexts(data.Context), tok)
+			},
+			shrink: func(maxTokens int) {
+				data.Context = condenseContext(data.Context, maxTokens, tok)
+			},
+		},
+		{
+			limit:  b.limit(b.HistoryShare, defaultHistoryShare),
+			tokens: func() int { return tok.CountTokens(data.Timeline) },
+			shrink: func(maxTokens int) { data.Timeline = condenseLines(data.Timeline, maxTokens, tok) },
+		},
+		{
+			limit:  b.limit(b.ArtifactsShare, defaultArtifactsShare),
+			tokens: func() int { return tok.CountTokens(data.Artifacts) },
+			shrink: func(maxTokens int) { data.Artifacts = condenseLines(data.Artifacts, maxTokens, tok) },
+		},
+	}
+}
+
+// exemplarsSection is the few-shot exemplars of a prompt, which give way before anything else.
+func (b PromptBudget) exemplarsSection(exemplars *[]Exemplar, tok rag.Tokenizer) promptSection {
+	return promptSection{
+		limit: b.limit(b.ExemplarsShare, defaultExemplarsShare),
+		tokens: func() int {
+			tokens := 0
+			for _, exemplar := range *exemplars {
+				tokens += exemplarTokens(exemplar, tok)
+			}
+			return tokens
+		},
+		shrink: func(maxTokens int) { *exemplars = condenseExemplars(*exemplars, maxTokens, tok) },
+	}
+}
+
+// combineSections are the parts of a prompt combining part summaries the budget condenses, in the
+// order they give way; the summaries carry the whole episode, so they have no share of their own
+// and are only cut short, all evenly, when the prompt can't fit otherwise.
+func (b PromptBudget) combineSections(data *CombinePromptData) []promptSection {
+	tok := b.tokenizer()
+	return []promptSection{
+		b.exemplarsSection(&data.Exemplars, tok),
+		{
+			limit:  b.limit(b.ContextShare, defaultContextShare),
+			tokens: func() int { return chunkTokens(contextTexts(data.Context), tok) },
+			shrink: func(maxTokens int) { data.Context = condenseContext(data.Context, maxTokens, tok) },
+		},
+		{
+			limit: b.MaxTokens,
+			tokens: func() int {
+				tokens := 0
+				for _, summary := range data.Summaries {
+					tokens += tok.CountTokens(summary.Text)
+				}
+				return tokens
+			},
+			shrink: func(maxTokens int) {
+				if len(data.Summaries) == 0 {
+					return
+				}
+				each := maxTokens / len(data.Summaries)
+				for i := range data.Summaries {
+					data.Summaries[i].Text = truncateTokens(data.Summaries[i].Text, each, tok)
+				}
+			},
+		},
+	}
+}
+
+// projectSections are the parts of a project prompt the budget condenses, in the order they give way.
+func (b PromptBudget) projectSections(data *ProjectPromptData) []promptSection {
+	tok := b.tokenizer()
+	return []promptSection{
+		{
+			limit: b.limit(b.ContextShare, defaultContextShare),
+			tokens: func() int {
+				return chunkTokens(projectContextTexts(data.Context), tok)
+			},
+			shrink: func(maxTokens int) {
+				kept := condenseChunks(projectContextTexts(data.Context), maxTokens, tok)
+				context := make([]ProjectContextChunk, len(kept))
+				copy(context, data.Context)
+				for i, text := range kept {
+					context[i].Text = text
+				}
+				data.Context = context
+			},
+		},
+		{
+			limit:  b.limit(b.HistoryShare, defaultHistoryShare),
+			tokens: func() int { return arcTokens(data.Arcs, tok) },
+			shrink: func(maxTokens int) { data.Arcs = condenseArcs(data.Arcs, maxTokens, tok) },
+		},
+	}
+}
+
+// contributorSections are the parts of a contributor prompt the budget condenses, in the order they give way.
+func (b PromptBudget) contributorSections(data *ContributorPromptData) []promptSection {
+	tok := b.tokenizer()
+	return []promptSection{
+		{
+			limit:  b.limit(b.ContextShare, defaultContextShare),
+			tokens: func() int { return chunkTokens(contextTexts(data.Context), tok) },
+			shrink: func(maxTokens int) { data.Context = condenseContext(data.Context, maxTokens, tok) },
+		},
+		{
+			limit:  b.limit(b.HistoryShare, defaultHistoryShare),
+			tokens: func() int { return tok.CountTokens(data.Contributions) },
+			shrink: func(maxTokens int) { data.Contributions = condenseLines(data.Contributions, maxTokens, tok) },
+		},
+	}
+}
+
+// periodSections are the parts of a period prompt the budget condenses, in the order they give way.
+func (b PromptBudget) periodSections(data *PeriodPromptData) []promptSection {
+	tok := b.tokenizer()
+	return []promptSection{
+		{
+			limit:  b.limit(b.ContextShare, defaultContextShare),
+			tokens: func() int { return chunkTokens(contextTexts(data.Context), tok) },
+			shrink: func(maxTokens int) { data.Context = condenseContext(data.Context, maxTokens, tok) },
+		},
+		{
+			limit:  b.limit(b.HistoryShare, defaultHistoryShare),
+			tokens: func() int { return tok.CountTokens(data.Highlights) },
+			shrink: func(maxTokens int) { data.Highlights = condenseLines(data.Highlights, maxTokens, tok) },
+		},
+	}
+}
+
+// digestSections are the parts of a digest prompt the budget condenses; like part summaries, the
+// period summaries carry the digest, so they are only cut short, all evenly, when it can't fit otherwise.
+func (b PromptBudget) digestSections(data *DigestPromptData) []promptSection {
+	tok := b.tokenizer()
+	return []promptSection{
+		{
+			limit: b.MaxTokens,
+			tokens: func() int {
+				tokens := 0
+				for _, period := range data.Periods {
+					tokens += tok.CountTokens(period.Summary)
+				}
+				return tokens
+			},
+			shrink: func(maxTokens int) {
+				if len(data.Periods) == 0 {
+					return
+				}
+				each := maxTokens / len(data.Periods)
+				for i := range data.Periods {
+					data.Periods[i].Summary = truncateTokens(data.Periods[i].Summary, each, tok)
+				}
+			},
+		},
+	}
+}
+
+// contextTexts returns the texts of context chunks.
+func contextTexts(chunks []rag.ContextChunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	return texts
+}
+
+// projectContextTexts returns the texts of a project prompt's context chunks.
+func projectContextTexts(chunks []ProjectContextChunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	return texts
+}
+
+// condenseContext keeps the leading context chunks that fit in maxTokens, truncating the last.
+func condenseContext(chunks []rag.ContextChunk, maxTokens int, tok rag.Tokenizer) []rag.ContextChunk {
+	kept := condenseChunks(contextTexts(chunks), maxTokens, tok)
+	condensed := make([]rag.ContextChunk, len(kept))
+	copy(condensed, chunks)
+	for i, text := range kept {
+		condensed[i].Text = text
+	}
+	return condensed
+}
+
+// arcOutline approximates the text a story arc is listed with in the project prompt.
+func arcOutline(arc ArcPromptData) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s: %s (2006-01-02 to 2006-01-02, %d episodes, %d commits)\n\n", arc.ID, arc.Title, arc.EpisodeCount, arc.Commits))
+	for _, ep := range arc.Episodes {
+		b.WriteString(fmt.Sprintf("- %s (2006-01-02, %s): %s\n", ep.ID, ep.Category, ep.Title))
+	}
+	if arc.MoreEpisodes > 0 {
+		b.WriteString(fmt.Sprintf("- ... and %d more episodes\n", arc.MoreEpisodes))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// arcTokens returns the tokens story arcs take in a prompt.
+func arcTokens(arcs []ArcPromptData, tok rag.Tokenizer) int {
+	tokens := 0
+	for _, arc := range arcs {
+		tokens += tok.CountTokens(arcOutline(arc))
+	}
+	return tokens
+}
+
+// condenseArcs fits story arcs in maxTokens: fewer episodes are listed per arc, down to none, and
+// then the oldest arcs are left out.
+func condenseArcs(arcs []ArcPromptData, maxTokens int, tok rag.Tokenizer) []ArcPromptData {
+	listed := 0
+	for _, arc := range arcs {
+		listed = max(listed, len(arc.Episodes))
+	}
+
+	condensed := make([]ArcPromptData, len(arcs))
+	copy(condensed, arcs)
+	for n := listed - 1; n >= 0; n-- {
+		copy(condensed, arcs)
+		for i := range condensed {
+			if len(condensed[i].Episodes) > n {
+				condensed[i].MoreEpisodes += len(condensed[i].Episodes) - n
+				condensed[i].Episodes = condensed[i].Episodes[:n]
+			}
+		}
+		if arcTokens(condensed, tok) <= maxTokens {
+			return condensed
+		}
+	}
+	for len(condensed) > 0 && arcTokens(condensed, tok) > maxTokens {
+		condensed = condensed[1:]
+	}
+	return condensed
+}
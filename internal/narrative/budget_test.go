@@ -0,0 +1,233 @@
+package narrative
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := map[string]int{
+		"gpt-4o":              128000,
+		"gpt-4o-mini":         128000,
+		"gpt-4":               8192,
+		"gpt-4-turbo-preview": 128000,
+		"llama3.1":            DefaultContextWindow,
+		"":                    DefaultContextWindow,
+	}
+	for model, want := range tests {
+		if got := ContextWindow(model); got != want {
+			t.Errorf("%q: expected %d, got %d", model, want, got)
+		}
+	}
+
+	budget := NewPromptBudget(LLMConfig{Model: "gpt-4", MaxTokens: 2000}, 0, nil)
+	if want := 8192 - 2000 - 409; budget.MaxTokens != want {
+		t.Errorf("Expected %d tokens for the prompt, got %d", want, budget.MaxTokens)
+	}
+}
+
+// longEpisode returns an episode with many commits and a long artifact list
+func longEpisode() *cluster.Episode {
+	episode := &cluster.Episode{ID: "E1"}
+	for i := 0; i < 40; i++ {
+		episode.Commits = append(episode.Commits, git.Commit{
+			Hash:        fmt.Sprintf("c%06d", i),
+			Message:     "Refactor the retry loop to back off exponentially between attempts",
+			Author:      git.Author{Name: "Alice"},
+			CommittedAt: time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC),
+		})
+		episode.Artifacts = append(episode.Artifacts, cluster.Artifact{
+			Type:   cluster.ArtifactIssue,
+			Number: i + 1,
+			Title:  "Retries give up too early on flaky networks",
+		})
+	}
+	return episode
+}
+
+// longChunks returns context chunks of 100 words each, scoring 0.9, 0.8, ...
+func longChunks(n int) []rag.ContextChunk {
+	chunks := make([]rag.ContextChunk, n)
+	for i := range chunks {
+		chunks[i] = rag.ContextChunk{
+			EpisodeID: fmt.Sprintf("R%d", i),
+			Text:      strings.TrimSpace(strings.Repeat("retry backoff ", 50)),
+			Score:     0.9 - float32(i)/10,
+		}
+	}
+	return chunks
+}
+
+func TestEpisodePrompt_WithinBudget(t *testing.T) {
+	episode, chunks := longEpisode(), longChunks(5)
+	unlimited, err := AssemblePrompt(episode, chunks)
+	if err != nil {
+		t.Fatalf("AssemblePrompt failed: %v", err)
+	}
+
+	budget := PromptBudget{MaxTokens: 1200}
+	tok := budget.tokenizer()
+	if tok.CountTokens(unlimited) <= budget.MaxTokens {
+		t.Fatalf("Expected the unlimited prompt to exceed %d tokens, got %d", budget.MaxTokens, tok.CountTokens(unlimited))
+	}
+
+	prompt, err := DefaultPromptTemplates().WithBudget(budget).EpisodePrompt(episode, chunks)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+	if tokens := tok.CountTokens(prompt); tokens > budget.MaxTokens {
+		t.Errorf("Expected at most %d tokens, got %d", budget.MaxTokens, tokens)
+	}
+	for _, want := range []string{"**Episode ID:** E1", "**Episode R0**", "more lines left out to fit the context window", "# Task"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the condensed prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "**Episode R4**") {
+		t.Error("Expected the least relevant chunk to be left out first")
+	}
+	if strings.Contains(prompt, "- (none)") {
+		t.Error("Expected a condensed artifact list not to read as empty")
+	}
+}
+
+func TestEpisodePrompt_TooLarge(t *testing.T) {
+	budget := PromptBudget{MaxTokens: 100}
+	_, err := DefaultPromptTemplates().WithBudget(budget).EpisodePrompt(longEpisode(), longChunks(2))
+	if !errors.Is(err, ErrPromptTooLarge) {
+		t.Errorf("Expected ErrPromptTooLarge, got %v", err)
+	}
+}
+
+func TestProjectPrompt_WithinBudget(t *testing.T) {
+	data := ProjectPromptData{Question: "How did retries evolve?"}
+	for i := 0; i < 20; i++ {
+		arc := ArcPromptData{ID: fmt.Sprintf("A%d", i), Title: "Retry handling", EpisodeCount: 5}
+		for j := 0; j < 5; j++ {
+			arc.Episodes = append(arc.Episodes, ArcEpisodePromptData{ID: fmt.Sprintf("E%d-%d", i, j), Title: "Tune the retry backoff for flaky networks"})
+		}
+		data.Arcs = append(data.Arcs, arc)
+	}
+	for i, chunk := range longChunks(6) {
+		data.Context = append(data.Context, ProjectContextChunk{ContextChunk: chunk, Heading: fmt.Sprintf("Episode %d: %s", i+1, chunk.EpisodeID)})
+	}
+
+	budget := PromptBudget{MaxTokens: 1500}
+	prompt, err := DefaultPromptTemplates().WithBudget(budget).ProjectPrompt(data)
+	if err != nil {
+		t.Fatalf("ProjectPrompt failed: %v", err)
+	}
+	if tokens := budget.tokenizer().CountTokens(prompt); tokens > budget.MaxTokens {
+		t.Errorf("Expected at most %d tokens, got %d", budget.MaxTokens, tokens)
+	}
+	if !strings.Contains(prompt, "## Episode 1: R0") || strings.Contains(prompt, "## Episode 6: R5") {
+		t.Errorf("Expected the most relevant context kept and the least relevant dropped, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "## A19: Retry handling") || !strings.Contains(prompt, "more episodes") {
+		t.Errorf("Expected the latest arcs kept with fewer episodes listed, got:\n%s", prompt)
+	}
+	if len(data.Context[5].Text) == 0 || len(data.Arcs[0].Episodes) != 5 {
+		t.Error("Expected the caller's data to be left unchanged")
+	}
+}
+
+func TestCondenseLines(t *testing.T) {
+	tok := wordCounter{}
+	text := "**Timeline:**\n- one a b c d e f\n- two a b c d e f\n- three a b c d e f\n\n"
+	got := condenseLines(text, 21, tok)
+	want := "**Timeline:**\n- one a b c d e f\n- ... 2 more lines left out to fit the context window\n\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := condenseLines(text, 100, tok); got != text {
+		t.Errorf("Expected text within budget unchanged, got %q", got)
+	}
+	if got := condenseLines(text, 3, tok); got != "" {
+		t.Errorf("Expected nothing when not even the note fits, got %q", got)
+	}
+}
+
+func TestTruncateTokens(t *testing.T) {
+	tok := wordCounter{}
+	if got := truncateTokens("one two three four five", 4, tok); got != "one two three [...]" {
+		t.Errorf("Expected a cut at a word boundary, got %q", got)
+	}
+	if got := truncateTokens("one two", 1, tok); got != "" {
+		t.Errorf("Expected nothing when only the marker fits, got %q", got)
+	}
+}
+
+// wordCounter counts one token per whitespace-separated word
+type wordCounter struct{}
+
+func (wordCounter) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+func TestCombinePrompt_WithinBudget(t *testing.T) {
+	episode := longEpisode()
+	parts := episode.Split(20)
+	summaries := make([]PartSummary, len(parts))
+	for i := range parts {
+		summaries[i] = NewPartSummary(&parts[i], i+1, strings.TrimSpace(strings.Repeat("The retry loop was reworked. ", 80)))
+	}
+
+	budget := PromptBudget{MaxTokens: 1500}
+	prompt, err := DefaultPromptTemplates().WithBudget(budget).CombinePrompt(episode, summaries, longChunks(3))
+	if err != nil {
+		t.Fatalf("CombinePrompt failed: %v", err)
+	}
+	if tokens := budget.tokenizer().CountTokens(prompt); tokens > budget.MaxTokens {
+		t.Errorf("Expected at most %d tokens, got %d", budget.MaxTokens, tokens)
+	}
+	for i := range parts {
+		if want := fmt.Sprintf("## Part %d", i+1); !strings.Contains(prompt, want) {
+			t.Errorf("Expected every part to keep its summary, missing %q in:\n%s", want, prompt)
+		}
+	}
+	if !strings.Contains(prompt, truncationMarker) {
+		t.Error("Expected the part summaries to be shortened")
+	}
+	if len(summaries[0].Text) < 2000 {
+		t.Error("Expected the caller's summaries to be left unchanged")
+	}
+}
+
+func TestDigestPrompt_WithinBudget(t *testing.T) {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	periods := make([]DigestPeriod, 6)
+	for i := range periods {
+		periods[i] = DigestPeriod{
+			Label:   fmt.Sprintf("2024-W%02d", 10+i),
+			Start:   start.AddDate(0, 0, 7*i),
+			End:     start.AddDate(0, 0, 7*i+6),
+			Commits: 10,
+			Summary: strings.TrimSpace(strings.Repeat("The retry loop was reworked. ", 80)),
+		}
+	}
+
+	budget := PromptBudget{MaxTokens: 1500}
+	prompt, err := DefaultPromptTemplates().WithBudget(budget).DigestPrompt(DigestPromptData{Period: "week", Start: start, End: periods[5].End, Periods: periods})
+	if err != nil {
+		t.Fatalf("DigestPrompt failed: %v", err)
+	}
+	if tokens := budget.tokenizer().CountTokens(prompt); tokens > budget.MaxTokens {
+		t.Errorf("Expected at most %d tokens, got %d", budget.MaxTokens, tokens)
+	}
+	for _, period := range periods {
+		if want := "## " + period.Label; !strings.Contains(prompt, want) {
+			t.Errorf("Expected every period to keep its summary, missing %q in:\n%s", want, prompt)
+		}
+	}
+	if !strings.Contains(prompt, truncationMarker) {
+		t.Error("Expected the period summaries to be shortened")
+	}
+	if len(periods[0].Summary) < 2000 {
+		t.Error("Expected the caller's periods to be left unchanged")
+	}
+}
@@ -0,0 +1,148 @@
+package narrative
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// CacheDirEnv is the environment variable that overrides the default narrative cache directory.
+const CacheDirEnv = "THUNK_NARRATIVE_CACHE_DIR"
+
+// CacheKey identifies a generated narrative by everything that shapes it: when none of its fields
+// change, the cached narrative is reused instead of calling the LLM again.
+type CacheKey struct {
+	Content  string // Hash of the content the narrative describes, such as an episode's commits and artifacts
+	Template string // Version of the prompt templates used, see PromptTemplates.Version
+	Model    string
+	Style    Style
+}
+
+// hash returns the key's file name in the cache.
+func (k CacheKey) hash() string {
+	h := sha256.New()
+	for _, part := range []string{k.Content, k.Template, k.Model, string(k.Style.orDefault())} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CacheStats counts how a cache was used since it was opened.
+type CacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+	Writes int `json:"writes"`
+}
+
+// Cache stores generated narratives on disk, laid out like git's loose objects (two-character
+// fan-out directories) and written atomically. A nil cache caches nothing.
+type Cache struct {
+	dir    string
+	hits   atomic.Int64
+	misses atomic.Int64
+	writes atomic.Int64
+}
+
+// NewCache opens (creating if needed) a narrative cache rooted at dir.
+func NewCache(dir string) (*Cache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("narrative cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create narrative cache directory: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// DefaultCacheDir returns the narrative cache directory: THUNK_NARRATIVE_CACHE_DIR if set,
+// otherwise the user cache directory.
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+
+	return filepath.Join(userCache, "thunk", "narratives"), nil
+}
+
+// path returns the entry location for a key.
+func (c *Cache) path(key CacheKey) string {
+	hash := key.hash()
+	return filepath.Join(c.dir, hash[:2], hash[2:]+".json")
+}
+
+// Get returns the narrative cached under key, if present; unreadable or corrupt entries count as misses.
+func (c *Cache) Get(key CacheKey) (*Narrative, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	var narr Narrative
+	if err := json.Unmarshal(data, &narr); err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return &narr, true
+}
+
+// Put stores a narrative under key, replacing any narrative cached under it before.
+func (c *Cache) Put(key CacheKey, narr *Narrative) error {
+	if c == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(narr)
+	if err != nil {
+		return fmt.Errorf("failed to encode narrative %s: %w", narr.EpisodeID, err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create narrative cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write narrative cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write narrative cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write narrative cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write narrative cache entry: %w", err)
+	}
+
+	c.writes.Add(1)
+	return nil
+}
+
+// Stats returns how often the cache was hit, missed and written to since it was opened.
+func (c *Cache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	return CacheStats{Hits: int(c.hits.Load()), Misses: int(c.misses.Load()), Writes: int(c.writes.Load())}
+}
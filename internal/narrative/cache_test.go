@@ -0,0 +1,101 @@
+package narrative
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	key := CacheKey{Content: "abc", Template: "episode@1", Model: "gpt-4o"}
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	narr := &Narrative{EpisodeID: "E1", Text: "Retries were tuned.", GeneratedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Model: "gpt-4o", Style: StyleTechnical}
+	if err := cache.Put(key, narr); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	got, ok := cache.Get(key)
+	if !ok || got.Text != narr.Text || !got.GeneratedAt.Equal(narr.GeneratedAt) {
+		t.Errorf("Expected the cached narrative back, got %+v", got)
+	}
+
+	// An empty style is the default one; any other change to the key misses
+	if _, ok := cache.Get(CacheKey{Content: "abc", Template: "episode@1", Model: "gpt-4o", Style: StyleTechnical}); !ok {
+		t.Error("Expected the default style to share entries with an empty one")
+	}
+	for _, other := range []CacheKey{
+		{Content: "abd", Template: "episode@1", Model: "gpt-4o"},
+		{Content: "abc", Template: "episode@2", Model: "gpt-4o"},
+		{Content: "abc", Template: "episode@1", Model: "gpt-4o-mini"},
+		{Content: "abc", Template: "episode@1", Model: "gpt-4o", Style: StyleExecutive},
+	} {
+		if _, ok := cache.Get(other); ok {
+			t.Errorf("Expected a miss for %+v", other)
+		}
+	}
+
+	// A corrupt entry is a miss
+	if err := os.WriteFile(cache.path(key), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Error("Expected a corrupt entry to miss")
+	}
+
+	if stats := cache.Stats(); stats != (CacheStats{Hits: 2, Misses: 6, Writes: 1}) {
+		t.Errorf("Unexpected statistics %+v", stats)
+	}
+
+	var disabled *Cache
+	if err := disabled.Put(key, narr); err != nil {
+		t.Errorf("Expected a nil cache to ignore writes, got %v", err)
+	}
+	if _, ok := disabled.Get(key); ok || disabled.Stats() != (CacheStats{}) {
+		t.Error("Expected a nil cache to cache nothing")
+	}
+}
+
+func TestPromptTemplates_Version(t *testing.T) {
+	defaults := DefaultPromptTemplates()
+	episode, err := defaults.Version(PromptEpisode)
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	if again, _ := DefaultPromptTemplates().Version(PromptEpisode); again != episode {
+		t.Errorf("Expected a stable version, got %s and %s", episode, again)
+	}
+	if project, _ := defaults.Version(PromptProject); project == episode {
+		t.Error("Expected each type to have its own version")
+	}
+	if executive, _ := defaults.WithStyle(StyleExecutive).Version(PromptEpisode); executive == episode {
+		t.Error("Expected a style's own template to have its own version")
+	}
+
+	// Editing a partial the template includes changes its version
+	dir := writeTemplates(t, map[string]string{
+		"episode.tmpl": `{{.Episode.ID}} {{template "related-context" .Context}}`,
+	})
+	before, err := LoadPromptTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, partialsName), []byte(`{{define "related-context"}}{{range .}}{{.EpisodeID}}{{end}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	after, err := LoadPromptTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates failed: %v", err)
+	}
+	v1, _ := before.Version(PromptEpisode)
+	v2, _ := after.Version(PromptEpisode)
+	if v1 == episode || v1 == v2 {
+		t.Errorf("Expected edited templates and partials to change the version, got %s, %s and %s", episode, v1, v2)
+	}
+}
@@ -0,0 +1,153 @@
+package narrative
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// defaultDiffTokens is the most tokens diff hunks take in a prompt when DiffOptions leaves it unset.
+const defaultDiffTokens = 2000
+
+// DiffOptions selects the code changes included in episode prompts, so narratives can describe how
+// something was implemented rather than only what the commit subjects say. Hunks come from the
+// patches of the episode's commits, so commits must be parsed with git.ParseOptions.IncludePatch;
+// patches deferred with git.ParseOptions.LazyDiffs are loaded by Load. Hunks are included as the
+// commits carry them, so redact the episodes first (see redact.Redactor.Episodes). The zero value
+// includes no diffs.
+type DiffOptions struct {
+	// MaxFiles is the number of files, those with the most lines changed, whose hunks are included
+	MaxFiles int
+
+	// MaxTokens caps the tokens of the included hunks (default 2000); files past it are left out
+	// and the last one included is cut short
+	MaxTokens int
+}
+
+// enabled reports whether the options include any diffs.
+func (o DiffOptions) enabled() bool {
+	return o.MaxFiles > 0
+}
+
+// FileDiff is the change an episode made to one file, as the episode template lists it.
+type FileDiff struct {
+	Path      string
+	Language  string
+	Additions int
+	Deletions int
+	Hunks     string // Unified diff hunks of the episode's commits, oldest first
+}
+
+// Load loads the deferred patches of an episode's commits when the options include diffs, so only
+// the episodes narrated pay for computing them. A commit whose patches fail to load keeps its
+// line counts, and is left out of the hunks.
+func (o DiffOptions) Load(ctx context.Context, ep *cluster.Episode) error {
+	if !o.enabled() {
+		return nil
+	}
+	var errs []error
+	for i := range ep.Commits {
+		if err := ep.Commits[i].LoadDiffs(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithDiffs returns the templates including the code changes opts selects in episode prompts.
+func (t *PromptTemplates) WithDiffs(opts DiffOptions) *PromptTemplates {
+	templates := *t
+	templates.diffs = opts
+	return &templates
+}
+
+// episodeDiffs returns the hunks of the files an episode changed most, capped at opts.MaxTokens,
+// most changed first.
+func episodeDiffs(ep *cluster.Episode, opts DiffOptions, tok rag.Tokenizer) []FileDiff {
+	if !opts.enabled() {
+		return nil
+	}
+
+	// Commits are listed newest first, so walk them backwards to keep each file's hunks in order
+	files := make(map[string]*FileDiff)
+	hunks := make(map[string]*strings.Builder)
+	for i := len(ep.Commits) - 1; i >= 0; i-- {
+		for _, diff := range ep.Commits[i].Diffs {
+			if diff.IsBinary || diff.Patch == "" {
+				continue
+			}
+			file, ok := files[diff.FilePath]
+			if !ok {
+				file = &FileDiff{Path: diff.FilePath, Language: diff.Language}
+				files[diff.FilePath] = file
+				hunks[diff.FilePath] = &strings.Builder{}
+			}
+			file.Additions += diff.Additions
+			file.Deletions += diff.Deletions
+			hunks[diff.FilePath].WriteString(diff.Patch)
+		}
+	}
+
+	ranked := make([]*FileDiff, 0, len(files))
+	for path, file := range files {
+		file.Hunks = hunks[path].String()
+		ranked = append(ranked, file)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		ci, cj := ranked[i].Additions+ranked[i].Deletions, ranked[j].Additions+ranked[j].Deletions
+		if ci != cj {
+			return ci > cj
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+	if len(ranked) > opts.MaxFiles {
+		ranked = ranked[:opts.MaxFiles]
+	}
+
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultDiffTokens
+	}
+	diffs := make([]FileDiff, 0, len(ranked))
+	for _, file := range ranked {
+		diffs = append(diffs, *file)
+	}
+	return condenseDiffs(diffs, maxTokens, tok)
+}
+
+// condenseDiffs keeps the leading file diffs whose hunks fit in maxTokens, cutting the last short
+// at a line boundary.
+func condenseDiffs(diffs []FileDiff, maxTokens int, tok rag.Tokenizer) []FileDiff {
+	kept := condenseChunks(diffTexts(diffs), maxTokens, tok)
+	condensed := make([]FileDiff, 0, len(kept))
+	for i, hunks := range kept {
+		if hunks != diffs[i].Hunks {
+			// Cut at the last whole line, so no hunk ends mid-line
+			if cut := strings.LastIndex(hunks, "\n"); cut >= 0 {
+				hunks = hunks[:cut+1]
+			} else {
+				hunks = ""
+			}
+			if hunks == "" {
+				break
+			}
+		}
+		file := diffs[i]
+		file.Hunks = hunks
+		condensed = append(condensed, file)
+	}
+	return condensed
+}
+
+// diffTexts returns the hunks of each file diff.
+func diffTexts(diffs []FileDiff) []string {
+	texts := make([]string, len(diffs))
+	for i, file := range diffs {
+		texts[i] = file.Hunks
+	}
+	return texts
+}
@@ -0,0 +1,123 @@
+package narrative
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// diffEpisode returns an episode whose commits, newest first, change retry.go twice and a few other files
+func diffEpisode() *cluster.Episode {
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &cluster.Episode{ID: "E1", Commits: []git.Commit{
+		{Hash: "c2", Message: "Cap retries", CommittedAt: when.Add(time.Hour), Diffs: []git.Diff{
+			{FilePath: "retry.go", Additions: 2, Deletions: 1, Patch: "@@ -10,1 +10,2 @@\n-\tfor {\n+\tfor i := 0; i < maxRetries; i++ {\n+\t\twait *= 2\n"},
+			{FilePath: "logo.png", Additions: 0, IsBinary: true},
+		}},
+		{Hash: "c1", Message: "Add retries", CommittedAt: when, Diffs: []git.Diff{
+			{FilePath: "retry.go", Language: "Go", Additions: 3, Patch: "@@ -0,0 +1,3 @@\n+package retry\n+\n+const maxRetries = 3\n"},
+			{FilePath: "config.env", Additions: 2, Patch: "@@ -0,0 +1,2 @@\n+API_KEY=abc123def456\n+REGION=eu-west-1\n"},
+			{FilePath: "README.md", Additions: 1, Patch: "@@ -1 +1 @@\n+Retries\n"},
+		}},
+	}}
+}
+
+func TestEpisodeDiffs(t *testing.T) {
+	tok := PromptBudget{}.tokenizer()
+	if diffs := episodeDiffs(diffEpisode(), DiffOptions{}, tok); diffs != nil {
+		t.Errorf("Expected no diffs with the zero options, got %+v", diffs)
+	}
+
+	// The most changed files come first, each with its hunks oldest first
+	diffs := episodeDiffs(diffEpisode(), DiffOptions{MaxFiles: 2}, tok)
+	if len(diffs) != 2 || diffs[0].Path != "retry.go" || diffs[1].Path != "config.env" {
+		t.Fatalf("Expected retry.go then config.env, got %+v", diffs)
+	}
+	if diffs[0].Additions != 5 || diffs[0].Deletions != 1 || diffs[0].Language != "Go" {
+		t.Errorf("Expected retry.go's changes summed across commits, got %+v", diffs[0])
+	}
+	if added, capped := strings.Index(diffs[0].Hunks, "+package retry"), strings.Index(diffs[0].Hunks, "maxRetries; i++"); added < 0 || capped < added {
+		t.Errorf("Expected the hunks in commit order, got\n%s", diffs[0].Hunks)
+	}
+	if !strings.Contains(diffs[1].Hunks, "+API_KEY=abc123def456\n+REGION=eu-west-1") {
+		t.Errorf("Expected the hunks as the commits carry them, got\n%s", diffs[1].Hunks)
+	}
+
+	// The token cap drops the files past it and cuts the last one at a line
+	capped := episodeDiffs(diffEpisode(), DiffOptions{MaxFiles: 3, MaxTokens: chunkOverhead + minTruncatedChunk + 4}, tok)
+	if len(capped) != 1 || !strings.HasSuffix(capped[0].Hunks, "\n") || len(capped[0].Hunks) >= len(diffs[0].Hunks) {
+		t.Errorf("Expected retry.go cut short at a line, got %+v", capped)
+	}
+}
+
+func TestEpisodePrompt_Diffs(t *testing.T) {
+	templates := DefaultPromptTemplates()
+	plain, err := templates.EpisodePrompt(diffEpisode(), nil)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+	if strings.Contains(plain, "# Code Changes") {
+		t.Error("Expected no code changes without diff options")
+	}
+
+	prompt, err := templates.WithDiffs(DiffOptions{MaxFiles: 1}).EpisodePrompt(diffEpisode(), nil)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+	for _, want := range []string{"# Code Changes", "## retry.go (+5 / -1)", "```diff\n@@ -0,0 +1,3 @@\n+package retry\n", "+\t\twait *= 2\n```"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "config.env") {
+		t.Error("Expected only the most changed file")
+	}
+}
+
+func TestDiffOptions_Load(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "retry.go"), []byte("package retry\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	wt, _ := repo.Worktree()
+	if _, err := wt.Add("retry.go"); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+	sig := &object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := wt.Commit("Add retries", &gogit.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	commits, err := git.ParseCommitsWithOptions(ctx, repo, git.ParseOptions{LazyDiffs: true, IncludePatch: true})
+	if err != nil {
+		t.Fatalf("Failed to parse commits: %v", err)
+	}
+	ep := &cluster.Episode{ID: "E1", Commits: commits}
+
+	// Without diffs in the prompt the patches stay deferred
+	if err := (DiffOptions{}).Load(ctx, ep); err != nil || ep.Commits[0].DiffsLoaded() {
+		t.Fatalf("Expected the patches left unloaded without diff options (error %v)", err)
+	}
+
+	opts := DiffOptions{MaxFiles: 1}
+	if err := opts.Load(ctx, ep); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	diffs := episodeDiffs(ep, opts, PromptBudget{}.tokenizer())
+	if len(diffs) != 1 || !strings.Contains(diffs[0].Hunks, "+package retry") {
+		t.Errorf("Expected the loaded hunks of retry.go, got %+v", diffs)
+	}
+}
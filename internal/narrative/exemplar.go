@@ -0,0 +1,113 @@
+package narrative
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// exemplarCommits is the number of commits an exemplar made from an episode lists.
+const exemplarCommits = 10
+
+var ErrInvalidExemplar = errors.New("invalid exemplar")
+
+// Exemplar is an episode and the narrative written for it, included in episode prompts as a
+// few-shot example of the voice, structure and level of detail narratives should match.
+type Exemplar struct {
+	Episode   string `json:"episode"`         // The episode as the example shows it, such as its title and commits
+	Narrative string `json:"narrative"`       // The narrative written for it
+	Style     Style  `json:"style,omitempty"` // Style the exemplar is for; empty serves every style
+}
+
+// NewExemplar makes an exemplar of a narrative already written for an episode, such as one from the
+// narrative cache, describing the episode by its dates, size and commit subjects.
+func NewExemplar(ep *cluster.Episode, narr *Narrative) Exemplar {
+	var b strings.Builder
+	start, end := getTimeRange(ep.Commits)
+	fmt.Fprintf(&b, "Episode %s (%s to %s", ep.ID, formatDateOrNA(start), formatDateOrNA(end))
+	if ep.Category != "" {
+		fmt.Fprintf(&b, ", %s", ep.Category)
+	}
+	fmt.Fprintf(&b, "): %d commits", len(ep.Commits))
+	if authors := getUniqueAuthors(ep.Commits); len(authors) > 0 {
+		fmt.Fprintf(&b, " by %s", strings.Join(authors, ", "))
+	}
+	b.WriteString("\n")
+	for i, commit := range ep.Commits {
+		if i == exemplarCommits {
+			fmt.Fprintf(&b, "- ... and %d more commits\n", len(ep.Commits)-exemplarCommits)
+			break
+		}
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		fmt.Fprintf(&b, "- %s\n", subject)
+	}
+	return Exemplar{Episode: strings.TrimSpace(b.String()), Narrative: strings.TrimSpace(narr.Text), Style: narr.Style}
+}
+
+// LoadExemplars reads exemplars from a JSON file holding an array of them.
+func LoadExemplars(path string) ([]Exemplar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exemplars: %w", err)
+	}
+	var exemplars []Exemplar
+	if err := json.Unmarshal(data, &exemplars); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExemplar, err)
+	}
+	for i := range exemplars {
+		if strings.TrimSpace(exemplars[i].Episode) == "" || strings.TrimSpace(exemplars[i].Narrative) == "" {
+			return nil, fmt.Errorf("%w: exemplar %d needs an episode and a narrative", ErrInvalidExemplar, i+1)
+		}
+		if exemplars[i].Style != "" {
+			if exemplars[i].Style, err = ParseStyle(string(exemplars[i].Style)); err != nil {
+				return nil, fmt.Errorf("%w: exemplar %d: %w", ErrInvalidExemplar, i+1, err)
+			}
+		}
+	}
+	return exemplars, nil
+}
+
+// WithExemplars returns the templates including exemplars in episode prompts as few-shot examples;
+// each prompt gets those for its style and those for every style, in order.
+func (t *PromptTemplates) WithExemplars(exemplars []Exemplar) *PromptTemplates {
+	templates := *t
+	templates.exemplars = exemplars
+	return &templates
+}
+
+// styleExemplars returns the exemplars for the templates' style.
+func (t *PromptTemplates) styleExemplars() []Exemplar {
+	var exemplars []Exemplar
+	for _, exemplar := range t.exemplars {
+		if exemplar.Style == "" || exemplar.Style.orDefault() == t.Style() {
+			exemplars = append(exemplars, exemplar)
+		}
+	}
+	return exemplars
+}
+
+// condenseExemplars keeps the leading exemplars that fit in maxTokens whole, since a cut-off
+// example would teach the model to stop mid-sentence.
+func condenseExemplars(exemplars []Exemplar, maxTokens int, tok rag.Tokenizer) []Exemplar {
+	kept := exemplars[:0:0]
+	remaining := maxTokens
+	for _, exemplar := range exemplars {
+		tokens := exemplarTokens(exemplar, tok)
+		if tokens > remaining {
+			break
+		}
+		kept = append(kept, exemplar)
+		remaining -= tokens
+	}
+	return kept
+}
+
+// exemplarTokens approximates the tokens an exemplar takes in a prompt, headings included.
+func exemplarTokens(exemplar Exemplar, tok rag.Tokenizer) int {
+	return chunkOverhead + tok.CountTokens(exemplar.Episode) + tok.CountTokens(exemplar.Narrative)
+}
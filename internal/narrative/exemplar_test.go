@@ -0,0 +1,84 @@
+package narrative
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadExemplars(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	exemplars, err := LoadExemplars(write("exemplars.json", `[
+		{"episode": "Episode E1: Add retries", "narrative": "We added retries."},
+		{"episode": "Episode E2: Cap retries", "narrative": "Retries are capped now.", "style": "Executive"}
+	]`))
+	if err != nil {
+		t.Fatalf("LoadExemplars failed: %v", err)
+	}
+	if len(exemplars) != 2 || exemplars[0].Style != "" || exemplars[1].Style != StyleExecutive {
+		t.Errorf("Unexpected exemplars %+v", exemplars)
+	}
+
+	for name, content := range map[string]string{
+		"malformed.json": `{"episode": "E1"}`,
+		"empty.json":     `[{"episode": "Episode E1", "narrative": " "}]`,
+		"style.json":     `[{"episode": "Episode E1", "narrative": "Text.", "style": "poetic"}]`,
+	} {
+		if _, err := LoadExemplars(write(name, content)); !errors.Is(err, ErrInvalidExemplar) {
+			t.Errorf("%s: expected ErrInvalidExemplar, got %v", name, err)
+		}
+	}
+}
+
+func TestNewExemplar(t *testing.T) {
+	episode := longEpisode()
+	exemplar := NewExemplar(episode, &Narrative{EpisodeID: "E1", Text: " Retries back off now.\n", Style: StyleStandup})
+	if exemplar.Narrative != "Retries back off now." || exemplar.Style != StyleStandup {
+		t.Errorf("Expected the narrative's text and style, got %+v", exemplar)
+	}
+	if !strings.HasPrefix(exemplar.Episode, "Episode E1 (2024-01-01 to 2024-01-02): 40 commits by Alice\n") || !strings.HasSuffix(exemplar.Episode, "- ... and 30 more commits") {
+		t.Errorf("Expected the episode's summary and first commits, got\n%s", exemplar.Episode)
+	}
+}
+
+func TestEpisodePrompt_Exemplars(t *testing.T) {
+	exemplars := []Exemplar{
+		{Episode: "Episode E7: Add a cache", Narrative: "The team cached responses."},
+		{Episode: "Episode E8: Ship the cache", Narrative: "Leadership summary.", Style: StyleExecutive},
+	}
+	templates := DefaultPromptTemplates().WithExemplars(exemplars)
+
+	// Each style gets its own exemplars and those for every style
+	prompt, err := templates.EpisodePrompt(longEpisode(), nil)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "# Example Narratives") || !strings.Contains(prompt, "The team cached responses.") || strings.Contains(prompt, "Leadership summary.") {
+		t.Errorf("Expected only the exemplar for every style, got\n%s", prompt)
+	}
+	prompt, err = templates.WithStyle(StyleExecutive).CombinePrompt(longEpisode(), nil, nil)
+	if err != nil {
+		t.Fatalf("CombinePrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "The team cached responses.") || !strings.Contains(prompt, "Leadership summary.") {
+		t.Errorf("Expected both exemplars for the executive style, got\n%s", prompt)
+	}
+
+	// A tight budget drops whole exemplars rather than cutting one short
+	tok := PromptBudget{}.tokenizer()
+	kept := condenseExemplars(exemplars, exemplarTokens(exemplars[0], tok)+1, tok)
+	if len(kept) != 1 || kept[0] != exemplars[0] {
+		t.Errorf("Expected only the first exemplar to fit, got %+v", kept)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/Yates-Labs/thunk/internal/logging"
 )
 
 var (
@@ -24,13 +28,28 @@ type Narrative struct {
 
 	// Model is the LLM model used to generate this narrative
 	Model string `json:"model"`
+
+	// Style is the audience and tone the narrative was written for
+	Style Style `json:"style,omitempty"`
+
+	// Provenance records the prompt and retrieved context the narrative was written from, when known
+	Provenance *Provenance `json:"provenance,omitempty"`
 }
 
 // Generator produces narratives from episodes using an LLM.
-// It invokes an LLM on an already-assembled prompt.
+// It invokes an LLM on an already-assembled prompt, recovering from failed requests as set by the
+// config's RetryPolicy.
 type Generator struct {
 	llm    LLM
 	config LLMConfig
+
+	// newLLM creates the LLMs of fallback models, which are only created once needed
+	newLLM    func(LLMConfig) (LLM, error)
+	mu        sync.Mutex
+	fallbacks map[string]LLM
+
+	// sleep waits between retries
+	sleep func(context.Context, time.Duration) error
 }
 
 // NewGenerator creates a narrative generator with the given LLM implementation.
@@ -38,11 +57,15 @@ func NewGenerator(llm LLM, config LLMConfig) *Generator {
 	return &Generator{
 		llm:    llm,
 		config: config,
+		newLLM: NewLLM,
+		sleep:  sleep,
 	}
 }
 
 // Generate creates a narrative by invoking the LLM with an already-assembled prompt.
 // It must not perform retrieval or prompt construction.
+// Failed requests are retried, then sent to the fallback models in turn; the narrative records
+// the model that wrote it.
 func (g *Generator) Generate(ctx context.Context, episodeID string, prompt string) (*Narrative, error) {
 	if g.llm == nil {
 		return nil, fmt.Errorf("%w: LLM is required", ErrGenerationFailed)
@@ -54,15 +77,89 @@ func (g *Generator) Generate(ctx context.Context, episodeID string, prompt strin
 		return nil, fmt.Errorf("%w: prompt is required", ErrGenerationFailed)
 	}
 
-	text, err := g.llm.Generate(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("%w: LLM invocation failed: %w", ErrGenerationFailed, err)
+	var errs []error
+	for i, model := range g.models() {
+		llm := g.llm
+		if i > 0 {
+			var err error
+			if llm, err = g.fallback(model); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", model, err))
+				continue
+			}
+			logging.FromContext(ctx).Warn("Falling back to model", "model", model, "narrative", episodeID)
+		}
+
+		text, err := g.generateWithRetries(ctx, llm, prompt)
+		if err == nil {
+			return &Narrative{
+				EpisodeID:   episodeID,
+				Text:        text,
+				GeneratedAt: time.Now(),
+				Model:       model,
+				Style:       g.config.Style.orDefault(),
+			}, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", model, err))
+		if ctx.Err() != nil {
+			break
+		}
 	}
+	return nil, fmt.Errorf("%w: LLM invocation failed: %w", ErrGenerationFailed, errors.Join(errs...))
+}
 
-	return &Narrative{
-		EpisodeID:   episodeID,
-		Text:        text,
-		GeneratedAt: time.Now(),
-		Model:       g.config.Model,
-	}, nil
+// models returns the configured model followed by its fallbacks, each once.
+func (g *Generator) models() []string {
+	models := []string{g.config.Model}
+	for _, model := range g.config.Retry.FallbackModels {
+		if !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// generateWithRetries sends the prompt to llm, retrying failures that may be transient with backoff.
+func (g *Generator) generateWithRetries(ctx context.Context, llm LLM, prompt string) (string, error) {
+	policy := g.config.Retry
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			if err := g.sleep(ctx, policy.backoff(retry)); err != nil {
+				return "", err
+			}
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		}
+		text, err := llm.Generate(attemptCtx, prompt)
+		cancel()
+		if err == nil {
+			return text, nil
+		}
+		if ctx.Err() != nil || !retryable(err) || retry >= policy.MaxRetries {
+			return "", err
+		}
+		logging.FromContext(ctx).Warn("Retrying failed LLM request", "retry", retry+1, "max_retries", policy.MaxRetries, "error", err)
+	}
+}
+
+// fallback returns the LLM of a fallback model, creating it on first use.
+func (g *Generator) fallback(model string) (LLM, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if llm, ok := g.fallbacks[model]; ok {
+		return llm, nil
+	}
+	config := g.config
+	config.Model = model
+	llm, err := g.newLLM(config)
+	if err != nil {
+		return nil, err
+	}
+	if g.fallbacks == nil {
+		g.fallbacks = make(map[string]LLM)
+	}
+	g.fallbacks[model] = llm
+	return llm, nil
 }
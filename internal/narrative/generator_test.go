@@ -63,6 +63,10 @@ func TestGenerator_Generate_Success(t *testing.T) {
 		t.Errorf("expected model test-model, got %s", narrative.Model)
 	}
 
+	if narrative.Style != StyleTechnical {
+		t.Errorf("expected style technical by default, got %s", narrative.Style)
+	}
+
 	if narrative.GeneratedAt.IsZero() {
 		t.Error("generated timestamp is zero")
 	}
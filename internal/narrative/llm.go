@@ -1,12 +1,22 @@
 // Package narrative provides LLM-powered narrative generation for development episodes.
-// It defines a provider-agnostic LLM interface with concrete implementations for OpenAI
-// and deterministic mocks for testing. The generator consumes pre-assembled prompts and
-// returns structured narrative objects.
+// It defines a provider-agnostic LLM interface with concrete implementations for OpenAI,
+// local OpenAI-compatible endpoints, and deterministic mocks for testing. The generator
+// consumes pre-assembled prompts and returns structured narrative objects.
 package narrative
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/azureopenai"
+)
+
+// LLM providers accepted by NewLLM.
+const (
+	ProviderOpenAI = "openai" // OpenAI's API
+	ProviderLocal  = "local"  // Any OpenAI-compatible chat endpoint, such as Ollama, vLLM or LM Studio
 )
 
 var (
@@ -24,6 +34,13 @@ type LLM interface {
 
 // LLMConfig holds common configuration options for LLM providers.
 type LLMConfig struct {
+	// Provider selects the backend: "openai" (the default) or "local"
+	Provider string
+
+	// BaseURL is the endpoint of an OpenAI-compatible API (e.g., "http://localhost:11434/v1")
+	// Empty uses OpenAI, or Ollama's default address for the local provider
+	BaseURL string
+
 	// Model specifies the model identifier (e.g., "gpt-4", "gpt-3.5-turbo")
 	Model string
 
@@ -33,8 +50,19 @@ type LLMConfig struct {
 	// MaxTokens limits the response length (0 = use provider default)
 	MaxTokens int
 
-	// APIKey is the authentication key for the provider
+	// APIKey is the authentication key for the provider; optional for the local provider
 	APIKey string
+
+	// Azure, when set, sends OpenAI requests to an Azure OpenAI deployment instead, authenticated
+	// by its own key or Entra ID tokens rather than APIKey
+	Azure *azureopenai.Config
+
+	// Style is the audience and tone narratives are written for (empty = StyleTechnical)
+	Style Style
+
+	// Retry sets how narrative generation retries failed requests and which models it falls back
+	// to; while it allows retries, the OpenAI client's own retries are turned off
+	Retry RetryPolicy
 }
 
 // DefaultLLMConfig returns sensible defaults for narrative generation.
@@ -43,5 +71,18 @@ func DefaultLLMConfig() LLMConfig {
 		Model:       "gpt-4o",
 		Temperature: 0, // model default
 		MaxTokens:   2000,
+		Retry:       DefaultRetryPolicy(),
+	}
+}
+
+// NewLLM creates the LLM backend selected by config.Provider.
+func NewLLM(config LLMConfig) (LLM, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", ProviderOpenAI:
+		return NewOpenAILLM(config)
+	case ProviderLocal:
+		return NewLocalLLM(config)
+	default:
+		return nil, fmt.Errorf("%w: unknown provider %q (available: %s, %s)", ErrInvalidConfig, config.Provider, ProviderLocal, ProviderOpenAI)
 	}
 }
@@ -0,0 +1,48 @@
+package narrative
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// DefaultLocalBaseURL is where Ollama serves its OpenAI-compatible API.
+const DefaultLocalBaseURL = "http://localhost:11434/v1"
+
+// EnvLocalAPIKey holds the API key for local endpoints that require one, such as vLLM started with --api-key.
+const EnvLocalAPIKey = "THUNK_LLM_API_KEY"
+
+// NewLocalLLM creates an LLM backed by an OpenAI-compatible chat endpoint, such as Ollama,
+// vLLM or LM Studio, so narratives can be generated without sending code to a hosted provider.
+// The endpoint defaults to Ollama's; the API key is optional and OPENAI_API_KEY is never sent.
+func NewLocalLLM(config LLMConfig) (*OpenAILLM, error) {
+	if config.Model == "" {
+		return nil, fmt.Errorf("%w: missing model name (e.g. llama3.1)", ErrInvalidConfig)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultLocalBaseURL
+	}
+	apiKey := config.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(EnvLocalAPIKey)
+	}
+	if apiKey == "" {
+		// The client would otherwise fall back to OPENAI_API_KEY; local servers ignore the key
+		apiKey = ProviderLocal
+	}
+
+	client := openai.NewClient(append([]option.RequestOption{
+		option.WithBaseURL(baseURL),
+		option.WithAPIKey(apiKey),
+	}, retryOptions(config)...)...)
+
+	config.BaseURL = baseURL
+	return &OpenAILLM{
+		client: client,
+		config: config,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package narrative
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalLLM_Generate(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-should-not-leak")
+	t.Setenv(EnvLocalAPIKey, "")
+
+	var path, auth, model string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		var body struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		model = body.Model
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","object":"chat.completion","model":"llama3.1","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"A local narrative."}}]}`))
+	}))
+	defer server.Close()
+
+	llm, err := NewLLM(LLMConfig{Provider: "Local", BaseURL: server.URL + "/v1", Model: "llama3.1"})
+	if err != nil {
+		t.Fatalf("NewLLM failed: %v", err)
+	}
+	text, err := llm.Generate(context.Background(), "Summarize the episode")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if text != "A local narrative." {
+		t.Errorf("unexpected text: %q", text)
+	}
+	if path != "/v1/chat/completions" || model != "llama3.1" {
+		t.Errorf("expected a llama3.1 request to /v1/chat/completions, got %q to %q", model, path)
+	}
+	if auth == "Bearer sk-should-not-leak" {
+		t.Error("the OpenAI API key was sent to the local endpoint")
+	}
+}
+
+func TestNewLLM_Providers(t *testing.T) {
+	local, err := NewLocalLLM(LLMConfig{Model: "llama3.1"})
+	if err != nil {
+		t.Fatalf("NewLocalLLM failed: %v", err)
+	}
+	if local.config.BaseURL != DefaultLocalBaseURL {
+		t.Errorf("expected the Ollama endpoint by default, got %q", local.config.BaseURL)
+	}
+
+	if _, err := NewLocalLLM(LLMConfig{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a missing model to be rejected, got %v", err)
+	}
+	if _, err := NewLLM(LLMConfig{Provider: "anthropic", Model: "x"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected an unknown provider to be rejected, got %v", err)
+	}
+}
@@ -74,17 +74,25 @@ func generateMockResponse(prompt string) string {
 }
 
 func countCommitBullets(prompt string) int {
-	commitHeader := "**Commit Messages:**"
-	idx := strings.Index(prompt, commitHeader)
+	timelineHeader := "**Timeline:**"
+	idx := strings.Index(prompt, timelineHeader)
 	if idx >= 0 {
-		remainder := prompt[idx+len(commitHeader):]
+		remainder := prompt[idx+len(timelineHeader):]
 		// Take content until the next blank line.
 		if split := strings.SplitN(remainder, "\n\n", 2); len(split) > 0 {
 			remainder = split[0]
 		}
+		// Commit entries read "- [date ]commit <hash> ..."
 		count := 0
 		for _, line := range strings.Split(remainder, "\n") {
-			if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			entry, ok := strings.CutPrefix(strings.TrimSpace(line), "- ")
+			if !ok {
+				continue
+			}
+			if _, rest, dated := strings.Cut(entry, " "); dated && !strings.HasPrefix(entry, "commit ") {
+				entry = rest
+			}
+			if strings.HasPrefix(entry, "commit ") {
 				count++
 			}
 		}
@@ -16,9 +16,13 @@ type OpenAILLM struct {
 	config LLMConfig
 }
 
-// NewOpenAILLM creates an OpenAI-backed LLM implementation.
+// NewOpenAILLM creates an OpenAI-backed LLM implementation, or an Azure OpenAI one when config.Azure is set.
 // Returns an error if the API key is missing or invalid.
 func NewOpenAILLM(config LLMConfig) (*OpenAILLM, error) {
+	if config.Azure != nil {
+		return newAzureOpenAILLM(config)
+	}
+
 	// Use config API key or fall back to environment variable
 	apiKey := config.APIKey
 	if apiKey == "" {
@@ -31,9 +35,11 @@ func NewOpenAILLM(config LLMConfig) (*OpenAILLM, error) {
 		return nil, fmt.Errorf("%w: missing model name", ErrInvalidConfig)
 	}
 
-	client := openai.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if config.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(config.BaseURL))
+	}
+	client := openai.NewClient(append(opts, retryOptions(config)...)...)
 
 	return &OpenAILLM{
 		client: client,
@@ -41,6 +47,32 @@ func NewOpenAILLM(config LLMConfig) (*OpenAILLM, error) {
 	}, nil
 }
 
+// newAzureOpenAILLM creates an LLM calling the Azure OpenAI deployment in config.Azure.
+// Azure routes by deployment, so the model name is only reported, defaulting to the deployment.
+func newAzureOpenAILLM(config LLMConfig) (*OpenAILLM, error) {
+	opts, err := config.Azure.RequestOptions()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if config.Model == "" {
+		config.Model = config.Azure.Deployment
+	}
+
+	return &OpenAILLM{
+		client: openai.NewClient(append(opts, retryOptions(config)...)...),
+		config: config,
+	}, nil
+}
+
+// retryOptions turns off the client's own retries while config.Retry has the generator retry
+// requests, so a failed request isn't retried by both.
+func retryOptions(config LLMConfig) []option.RequestOption {
+	if config.Retry.MaxRetries > 0 {
+		return []option.RequestOption{option.WithMaxRetries(0)}
+	}
+	return nil
+}
+
 // Generate sends the prompt to OpenAI and returns the generated text.
 func (o *OpenAILLM) Generate(ctx context.Context, prompt string) (string, error) {
 	if prompt == "" {
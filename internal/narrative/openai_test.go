@@ -0,0 +1,41 @@
+package narrative
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/azureopenai"
+)
+
+func TestOpenAILLM_Azure(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("api-key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"An Azure narrative."}}]}`))
+	}))
+	defer server.Close()
+
+	config := DefaultLLMConfig()
+	config.Model = ""
+	config.Azure = &azureopenai.Config{Endpoint: server.URL, Deployment: "narratives", APIKey: "azure-key"}
+	llm, err := NewLLM(config)
+	if err != nil {
+		t.Fatalf("expected Azure to need no OpenAI key, got %v", err)
+	}
+	text, err := llm.Generate(context.Background(), "Summarize the episode")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if text != "An Azure narrative." || path != "/openai/deployments/narratives/chat/completions" || key != "azure-key" {
+		t.Errorf("unexpected response %q from a request to %q with key %q", text, path, key)
+	}
+	if model := llm.(*OpenAILLM).config.Model; model != "narratives" {
+		t.Errorf("expected the deployment as the model, got %q", model)
+	}
+}
@@ -16,94 +16,286 @@ var (
 	ErrMissingTargetEpisode = errors.New("target episode required for episode-level narrative")
 )
 
+// AssemblePrompt builds the prompt for an episode's narrative with the built-in template.
 func AssemblePrompt(targetEpisode *cluster.Episode, contextChunks []rag.ContextChunk) (string, error) {
-	if targetEpisode == nil {
-		return "", ErrMissingTargetEpisode
+	return DefaultPromptTemplates().EpisodePrompt(targetEpisode, contextChunks)
+}
+
+// episodePromptData gathers what the episode template renders, formatting the list sections
+func episodePromptData(ep *cluster.Episode, contextChunks []rag.ContextChunk) EpisodePromptData {
+	start, end := getTimeRange(ep.Commits)
+	data := EpisodePromptData{
+		Episode:   ep,
+		Start:     start,
+		End:       end,
+		Authors:   getUniqueAuthors(ep.Commits),
+		Languages: ep.GetPrimaryLanguages(3),
+		Context:   contextChunks,
 	}
 
-	// Sort context chunks by relevance score (highest first), even if already sorted.
-	sorted := make([]rag.ContextChunk, len(contextChunks))
-	copy(sorted, contextChunks)
-	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+	var b strings.Builder
+	writeCollaboration(&b, ep.GetCollaborationGraph())
+	data.Collaboration = b.String()
+
+	b.Reset()
+	writeIterations(&b, ep.GetIterations())
+	data.Iterations = b.String()
 
-	return assembleEpisodePrompt(targetEpisode, sorted), nil
+	b.Reset()
+	writeTimeline(&b, ep.GetTimeline())
+	data.Timeline = b.String()
+
+	b.Reset()
+	writeArtifacts(&b, ep.Artifacts)
+	data.Artifacts = b.String()
+
+	return data
 }
 
-func assembleEpisodePrompt(ep *cluster.Episode, contextChunks []rag.ContextChunk) string {
-	var b strings.Builder
+// writeArtifacts lists the episode's issues, pull requests, tickets and releases with their activity
+func writeArtifacts(b *strings.Builder, artifacts []cluster.Artifact) {
+	for _, a := range artifacts {
+		b.WriteString(fmt.Sprintf("- **%s:** %s\n", artifactLabel(&a), a.Title))
+		if a.Description != "" {
+			desc := a.Description
+			if len(desc) > 200 {
+				desc = desc[:200] + "..."
+			}
+			b.WriteString(fmt.Sprintf("  %s\n", desc))
+		}
+		writeBuilds(b, &a)
+		writeReviewThreads(b, &a)
+		writeProcessEvents(b, a.Discussions)
+	}
+}
 
-	b.WriteString("You are a technical writer specializing in software development narratives. ")
-	b.WriteString("Your task is to generate a coherent, human-readable narrative that explains ")
-	b.WriteString("what happened during this development episode and why it matters.\n\n")
+// maxCollaborationPairs caps the reviewer pairs listed in a prompt
+const maxCollaborationPairs = 5
 
-	b.WriteString("# Episode to Summarize\n\n")
-	b.WriteString(fmt.Sprintf("**Episode ID:** %s\n\n", ep.ID))
+// writeCollaboration lists who implemented the episode's commits and who reviewed them
+func writeCollaboration(b *strings.Builder, graph cluster.CollaborationGraph) {
+	if len(graph.Pairs) == 0 {
+		return
+	}
 
-	start, end := getTimeRange(ep.Commits)
-	authors := getUniqueAuthors(ep.Commits)
+	b.WriteString("**Collaboration:**\n")
+	for i, pair := range graph.Pairs {
+		if i >= maxCollaborationPairs {
+			b.WriteString(fmt.Sprintf("- ... and %d more pairs\n", len(graph.Pairs)-maxCollaborationPairs))
+			break
+		}
+		details := make([]string, 0, 3)
+		if pair.Reviews > 0 {
+			details = append(details, countOf(pair.Reviews, "review"))
+		}
+		if pair.Comments > 0 {
+			details = append(details, countOf(pair.Comments, "comment"))
+		}
+		if pair.Approved {
+			details = append(details, "approved")
+		}
+		b.WriteString(fmt.Sprintf("- %s implemented, %s reviewed (%s)\n", pair.Author, pair.Reviewer, strings.Join(details, ", ")))
+	}
+	b.WriteString("\n")
+}
 
-	b.WriteString(fmt.Sprintf("**Commits:** %d commits\n\n", len(ep.Commits)))
-	b.WriteString(fmt.Sprintf("**Time Range:** %s to %s\n\n", formatDateOrNA(start), formatDateOrNA(end)))
+// countOf formats a count with its noun, pluralized with a trailing "s"
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
 
-	if len(authors) == 0 {
-		b.WriteString("**Authors:** N/A\n\n")
-	} else {
-		b.WriteString(fmt.Sprintf("**Authors:** %s\n\n", strings.Join(authors, ", ")))
+// writeIterations reports progress for each sprint the episode's issues and PRs were planned in
+func writeIterations(b *strings.Builder, iterations []cluster.IterationProgress) {
+	if len(iterations) == 0 {
+		return
 	}
 
-	b.WriteString("**Commit Messages:**\n")
-	if len(ep.Commits) == 0 {
-		b.WriteString("- (none)\n\n")
-	} else {
-		for _, c := range ep.Commits {
-			hash := c.Hash
-			if len(hash) > 7 {
-				hash = hash[:7]
-			}
-			b.WriteString(fmt.Sprintf("- %s %s (by %s)\n", hash, c.Message, c.Author.Name))
-		}
-		b.WriteString("\n")
-	}
-
-	b.WriteString(fmt.Sprintf("**Related Artifacts:** %d items\n\n", len(ep.Artifacts)))
-	if len(ep.Artifacts) == 0 {
-		b.WriteString("- (none)\n\n")
-	} else {
-		for _, a := range ep.Artifacts {
-			b.WriteString(fmt.Sprintf("- **%s #%d:** %s\n", a.Type, a.Number, a.Title))
-			if a.Description != "" {
-				desc := a.Description
-				if len(desc) > 200 {
-					desc = desc[:200] + "..."
-				}
-				b.WriteString(fmt.Sprintf("  %s\n", desc))
-			}
+	b.WriteString("**Iterations:**\n")
+	for _, progress := range iterations {
+		it := progress.Iteration
+		// EndDate is exclusive; show the sprint's last day
+		last := it.EndDate
+		if last.After(it.StartDate) {
+			last = last.AddDate(0, 0, -1)
+		}
+		line := fmt.Sprintf("- %s (%s to %s): %d of %d items done",
+			it.Title, formatDateOrNA(it.StartDate), formatDateOrNA(last), progress.Done, progress.Items)
+
+		statuses := make([]string, 0, len(progress.Statuses))
+		for status := range progress.Statuses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for i, status := range statuses {
+			statuses[i] = fmt.Sprintf("%s: %d", status, progress.Statuses[status])
 		}
-		b.WriteString("\n")
+		if len(statuses) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(statuses, ", "))
+		}
+		b.WriteString(line + "\n")
 	}
+	b.WriteString("\n")
+}
 
-	if len(contextChunks) > 0 {
-		b.WriteString("# Related Development Context\n\n")
-		b.WriteString("The following are similar episodes from the repository history that may provide useful context:\n\n")
+// writeBuilds summarizes a pull or merge request's CI builds
+func writeBuilds(b *strings.Builder, a *cluster.Artifact) {
+	summary := a.GetBuildSummary()
+	if summary.Total == 0 {
+		return
+	}
 
-		for _, ch := range contextChunks {
-			b.WriteString(fmt.Sprintf("**Episode %s** (relevance: %.2f)\n", ch.EpisodeID, ch.Score))
-			b.WriteString(ch.Text + "\n\n")
+	line := fmt.Sprintf("  CI: %d builds, %d failed", summary.Total, summary.Failed)
+	if a.MergedAt != nil {
+		line = fmt.Sprintf("  CI: %d failed builds before merge (%d builds total)", summary.FailedBeforeMerge, summary.Total)
+	}
+	if summary.Latest != nil {
+		line += fmt.Sprintf(", latest %s", summary.Latest.Status)
+	}
+	b.WriteString(line + "\n")
+}
+
+// maxOpenReviewThreads caps the unresolved review concerns listed per artifact
+const maxOpenReviewThreads = 3
+
+// writeReviewThreads summarizes an artifact's code review threads and lists the unresolved ones
+func writeReviewThreads(b *strings.Builder, a *cluster.Artifact) {
+	threads := a.GetReviewThreads()
+	if len(threads) == 0 {
+		return
+	}
+
+	open := a.GetOpenReviewThreads()
+	b.WriteString(fmt.Sprintf("  Review threads: %d open, %d resolved\n", len(open), len(threads)-len(open)))
+	for i, thread := range open {
+		if i >= maxOpenReviewThreads {
+			b.WriteString(fmt.Sprintf("  - ... and %d more open threads\n", len(open)-maxOpenReviewThreads))
+			break
+		}
+		body := strings.Join(strings.Fields(thread.Body), " ")
+		if len(body) > 120 {
+			body = body[:120] + "..."
+		}
+		location := thread.FilePath
+		if thread.LineNumber > 0 {
+			location = fmt.Sprintf("%s:%d", thread.FilePath, thread.LineNumber)
 		}
+		b.WriteString(fmt.Sprintf("  - open on %s: %s (by %s)\n", location, body, thread.Author.Name))
 	}
+}
 
-	b.WriteString("# Task\n\n")
-	b.WriteString("Generate a narrative summary (2-4 paragraphs) that:\n")
-	b.WriteString("1. Explains what was accomplished in this episode\n")
-	b.WriteString("2. Describes the technical approach and key decisions\n")
-	b.WriteString("3. Connects this work to related development efforts\n")
-	b.WriteString("4. Highlights the impact and significance of the changes\n\n")
-	b.WriteString("Write in past tense, use clear technical language, and focus on the 'why' behind the changes, not just the 'what'. ")
-	b.WriteString("Do not invent details or motivations; base all statements strictly on the episode data and provided context. ")
-	b.WriteString("Use related episodes only for background and connections, not as actions performed in this episode. ")
-	b.WriteString("Explain technical decisions and tradeoffs rather than restating commit messages verbatim.\n")
+// maxTimelineEvents caps the timeline entries listed per episode
+const maxTimelineEvents = 50
 
-	return b.String()
+// writeTimeline lists commits and artifact activity in the order they happened
+// Process events are listed under their artifacts instead, and discussion bodies are left out
+func writeTimeline(b *strings.Builder, events []cluster.TimelineEvent) {
+	b.WriteString("**Timeline:**\n")
+	written := 0
+	for _, event := range events {
+		line := timelineLine(event)
+		if line == "" {
+			continue
+		}
+		if written >= maxTimelineEvents {
+			b.WriteString("- ... and more events\n")
+			break
+		}
+		if !event.Time.IsZero() {
+			line = event.Time.Format("2006-01-02") + " " + line
+		}
+		b.WriteString("- " + line + "\n")
+		written++
+	}
+	if written == 0 {
+		b.WriteString("- (none)\n")
+	}
+	b.WriteString("\n")
+}
+
+// timelineLine describes a timeline event, or returns "" for events the timeline leaves out
+func timelineLine(event cluster.TimelineEvent) string {
+	actor := event.Actor()
+	if actor == "" {
+		actor = "someone"
+	}
+
+	switch event.Type {
+	case cluster.TimelineCommit:
+		c := event.Commit
+		hash := c.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		if len(c.Tags) > 0 {
+			return fmt.Sprintf("commit %s %s (by %s) [released as %s]", hash, c.Message, c.Author.Name, strings.Join(c.Tags, ", "))
+		}
+		return fmt.Sprintf("commit %s %s (by %s)", hash, c.Message, c.Author.Name)
+	case cluster.TimelineOpened:
+		return fmt.Sprintf("%s opened %s: %s", actor, artifactLabel(event.Artifact), event.Artifact.Title)
+	case cluster.TimelineMerged:
+		return fmt.Sprintf("%s merged", artifactLabel(event.Artifact))
+	case cluster.TimelineClosed:
+		return fmt.Sprintf("%s closed", artifactLabel(event.Artifact))
+	case cluster.TimelineReleased:
+		return fmt.Sprintf("%s published: %s", artifactLabel(event.Artifact), event.Artifact.Title)
+	case cluster.TimelineDiscussion:
+		switch event.Discussion.Type {
+		case cluster.DiscussionReview:
+			if event.Discussion.ReviewState != "" {
+				return fmt.Sprintf("%s reviewed %s (%s)", actor, artifactLabel(event.Artifact), strings.ReplaceAll(event.Discussion.ReviewState, "_", " "))
+			}
+			return fmt.Sprintf("%s reviewed %s", actor, artifactLabel(event.Artifact))
+		case cluster.DiscussionReviewThread:
+			return fmt.Sprintf("%s commented on the code in %s", actor, artifactLabel(event.Artifact))
+		default:
+			return fmt.Sprintf("%s commented on %s", actor, artifactLabel(event.Artifact))
+		}
+	}
+	return ""
+}
+
+// artifactLabel names an artifact the way the artifact list does, e.g. "issue #7" or "release v1.2.0"
+func artifactLabel(a *cluster.Artifact) string {
+	switch {
+	case a.Type == cluster.ArtifactRelease:
+		return "release " + a.Metadata.TagName
+	case a.Type == cluster.ArtifactTicket && a.Metadata.TicketKey != "":
+		return "ticket " + a.Metadata.TicketKey
+	default:
+		return fmt.Sprintf("%s #%d", a.Type, a.Number)
+	}
+}
+
+// maxProcessEvents caps the process events listed per artifact
+const maxProcessEvents = 5
+
+// writeProcessEvents lists an artifact's process changes (labels, assignments, milestones, state)
+func writeProcessEvents(b *strings.Builder, discussions []cluster.Discussion) {
+	events := make([]cluster.Discussion, 0)
+	for _, d := range discussions {
+		if d.Type == cluster.DiscussionEvent {
+			events = append(events, d)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	b.WriteString("  Process:\n")
+	for i, event := range events {
+		if i >= maxProcessEvents {
+			b.WriteString(fmt.Sprintf("  - ... and %d more events\n", len(events)-maxProcessEvents))
+			break
+		}
+		actor := event.Author.Name
+		if actor == "" {
+			actor = "someone"
+		}
+		b.WriteString(fmt.Sprintf("  - %s %s %s\n", formatDateOrNA(event.CreatedAt), actor, event.Body))
+	}
 }
 
 func getTimeRange(commits []git.Commit) (time.Time, time.Time) {
@@ -149,3 +341,11 @@ func formatDateOrNA(t time.Time) string {
 	}
 	return t.Format("2006-01-02")
 }
+
+// joinWithAnd joins items as an English list, e.g. "Go, SQL and YAML"
+func joinWithAnd(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
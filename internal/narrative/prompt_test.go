@@ -107,6 +107,112 @@ func TestAssemblePrompt_NoContext(t *testing.T) {
 	}
 }
 
+func TestAssemblePrompt_IncludesReleaseTags(t *testing.T) {
+	episode := &cluster.Episode{
+		ID: "E1",
+		Commits: []git.Commit{
+			{
+				Hash:    "abc123def456",
+				Message: "Bump version",
+				Author:  git.Author{Name: "Alice"},
+				Tags:    []string{"v1.2.0"},
+			},
+		},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "released as v1.2.0") {
+		t.Fatal("missing release tag")
+	}
+}
+
+func TestAssemblePrompt_IncludesLanguages(t *testing.T) {
+	episode := &cluster.Episode{
+		ID: "E1",
+		Commits: []git.Commit{
+			{Hash: "abc123def456", Message: "Add store", Languages: map[string]int{"Go": 120, "SQL": 40, "YAML": 2}},
+		},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "**Languages:** primarily Go and SQL changes") {
+		t.Fatalf("missing language summary in prompt:\n%s", prompt)
+	}
+}
+
+func TestAssemblePrompt_IncludesCategory(t *testing.T) {
+	episode := &cluster.Episode{ID: "E1", Category: cluster.CategoryRefactor}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "**Category:** refactor") {
+		t.Fatalf("missing category in prompt:\n%s", prompt)
+	}
+}
+
+func TestAssemblePrompt_LowCohesion(t *testing.T) {
+	episode := &cluster.Episode{
+		ID:         "E1",
+		Commits:    []git.Commit{{Hash: "abc123def456", Message: "Add store"}, {Hash: "def456abc123", Message: "Update logo"}},
+		Cohesion:   0.21,
+		Confidence: map[string]float64{"abc123def456": 0.3, "def456abc123": 0.2},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "grouped with low confidence (cohesion 0.21)") {
+		t.Fatalf("missing low-cohesion framing in prompt:\n%s", prompt)
+	}
+
+	episode.Cohesion = 0.8
+	if prompt, _ := AssemblePrompt(episode, nil); strings.Contains(prompt, "low confidence") {
+		t.Error("Expected no low-cohesion framing for a cohesive episode")
+	}
+}
+
+func TestAssemblePrompt_Timeline(t *testing.T) {
+	at := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+	merged := at.Add(48 * time.Hour)
+	episode := &cluster.Episode{
+		ID:      "E1",
+		Commits: []git.Commit{{Hash: "abc123def456", Message: "Fix crash", Author: git.Author{Name: "Alice"}, CommittedAt: at.Add(24 * time.Hour)}},
+		Artifacts: []cluster.Artifact{
+			{Type: cluster.ArtifactIssue, Number: 7, Title: "Crash on startup", Author: git.Author{Name: "bob"}, CreatedAt: at,
+				Discussions: []cluster.Discussion{{Type: cluster.DiscussionComment, Author: git.Author{Name: "carol"}, Body: "Same here", CreatedAt: at.Add(time.Hour)}}},
+			{Type: cluster.ArtifactPullRequest, Number: 8, Title: "Fix crash", Author: git.Author{Name: "Alice"}, CreatedAt: at.Add(25 * time.Hour), MergedAt: &merged},
+		},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := strings.Join([]string{
+		"**Timeline:**",
+		"- 2024-01-20 bob opened issue #7: Crash on startup",
+		"- 2024-01-20 carol commented on issue #7",
+		"- 2024-01-21 commit abc123d Fix crash (by Alice)",
+		"- 2024-01-21 Alice opened pull_request #8: Fix crash",
+		"- 2024-01-22 pull_request #8 merged",
+	}, "\n")
+	if !strings.Contains(prompt, want) {
+		t.Fatalf("missing chronological timeline in prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Same here") {
+		t.Fatal("discussion bodies should not be included in the timeline")
+	}
+}
+
 func TestAssemblePrompt_ContextIncludesAllProvided(t *testing.T) {
 	episode := &cluster.Episode{
 		ID: "E1",
@@ -182,3 +288,183 @@ func TestGetUniqueAuthors(t *testing.T) {
 		t.Fatal("missing expected authors")
 	}
 }
+
+func TestAssemblePrompt_IncludesReleaseArtifacts(t *testing.T) {
+	episode := &cluster.Episode{
+		ID:      "E1",
+		Commits: []git.Commit{{Hash: "abc123def456", Message: "Bump version", Author: git.Author{Name: "Alice"}}},
+		Artifacts: []cluster.Artifact{{
+			Type:     cluster.ArtifactRelease,
+			Title:    "Parser overhaul",
+			Metadata: cluster.ArtifactMetadata{TagName: "v1.2.0"},
+		}},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "- **release v1.2.0:** Parser overhaul") {
+		t.Fatal("missing release artifact")
+	}
+}
+
+func TestAssemblePrompt_IncludesProcessEvents(t *testing.T) {
+	at := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+	episode := &cluster.Episode{
+		ID:      "E1",
+		Commits: []git.Commit{{Hash: "abc123def456", Message: "Fix crash", Author: git.Author{Name: "Alice"}}},
+		Artifacts: []cluster.Artifact{{
+			Type:   cluster.ArtifactIssue,
+			Number: 7,
+			Title:  "Crash on startup",
+			Discussions: []cluster.Discussion{
+				{Type: cluster.DiscussionComment, Author: git.Author{Name: "bob"}, Body: "Same here", CreatedAt: at},
+				{Type: cluster.DiscussionEvent, Author: git.Author{Name: "alice"}, Body: `added label "bug"`, CreatedAt: at},
+			},
+		}},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, `  - 2024-01-20 alice added label "bug"`) {
+		t.Fatalf("missing process event in prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Same here") {
+		t.Fatal("comments should not be listed as process events")
+	}
+}
+
+func TestAssemblePrompt_IncludesReviewThreads(t *testing.T) {
+	episode := &cluster.Episode{
+		ID:      "E1",
+		Commits: []git.Commit{{Hash: "abc123def456", Message: "Add cache", Author: git.Author{Name: "Alice"}}},
+		Artifacts: []cluster.Artifact{{
+			Type:   cluster.ArtifactPullRequest,
+			Number: 9,
+			Title:  "Add cache",
+			Discussions: []cluster.Discussion{
+				{ID: "review-comment-1", Type: cluster.DiscussionReviewThread, Author: git.Author{Name: "bob"},
+					Body: "Naming nit", FilePath: "cache.go", Resolved: true},
+				{ID: "review-comment-2", Type: cluster.DiscussionReviewThread, Author: git.Author{Name: "carol"},
+					Body: "This leaks\nfile handles", FilePath: "cache.go", LineNumber: 42},
+				{ID: "review-comment-3", Type: cluster.DiscussionReviewThread, ParentID: "review-comment-2",
+					Author: git.Author{Name: "alice"}, Body: "Will fix"},
+			},
+		}},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "  Review threads: 1 open, 1 resolved") {
+		t.Fatalf("missing review thread counts in prompt:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "  - open on cache.go:42: This leaks file handles (by carol)") {
+		t.Fatalf("missing open review concern in prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Naming nit") {
+		t.Fatal("resolved threads should only be counted")
+	}
+}
+
+func TestAssemblePrompt_IncludesIterations(t *testing.T) {
+	sprint := &cluster.Iteration{
+		Title:     "Sprint 4",
+		StartDate: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+	}
+	episode := &cluster.Episode{
+		ID: "E1",
+		Artifacts: []cluster.Artifact{
+			{Type: cluster.ArtifactIssue, Number: 1, State: "closed", Metadata: cluster.ArtifactMetadata{
+				Projects: []cluster.ProjectStatus{{Project: "Roadmap", Status: "Done", Iteration: sprint}}}},
+			{Type: cluster.ArtifactPullRequest, Number: 2, State: "open", Metadata: cluster.ArtifactMetadata{
+				Projects: []cluster.ProjectStatus{{Project: "Roadmap", Status: "In Review", Iteration: sprint}}}},
+		},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "- Sprint 4 (2024-05-06 to 2024-05-19): 1 of 2 items done (Done: 1, In Review: 1)"
+	if !strings.Contains(prompt, want) {
+		t.Fatalf("missing iteration progress in prompt:\n%s", prompt)
+	}
+}
+
+func TestAssemblePrompt_IncludesBuilds(t *testing.T) {
+	opened := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	merged := opened.Add(6 * time.Hour)
+	episode := &cluster.Episode{
+		ID: "E1",
+		Artifacts: []cluster.Artifact{
+			{Type: cluster.ArtifactPullRequest, Number: 2, State: "merged", MergedAt: &merged, Metadata: cluster.ArtifactMetadata{
+				Builds: []cluster.Build{
+					{Provider: "jenkins", Number: 1, Status: cluster.BuildFailed, StartedAt: opened},
+					{Provider: "jenkins", Number: 2, Status: cluster.BuildFailed, StartedAt: opened.Add(time.Hour)},
+					{Provider: "jenkins", Number: 3, Status: cluster.BuildSuccess, StartedAt: opened.Add(2 * time.Hour)},
+				}}},
+		},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "CI: 2 failed builds before merge (3 builds total), latest success"
+	if !strings.Contains(prompt, want) {
+		t.Fatalf("missing build summary in prompt:\n%s", prompt)
+	}
+}
+
+func TestAssemblePrompt_IncludesStats(t *testing.T) {
+	episode := &cluster.Episode{
+		ID: "E1",
+		Stats: cluster.EpisodeStats{
+			Additions:      120,
+			Deletions:      30,
+			FilesChanged:   6,
+			TopDirectories: []string{"internal/store", "cmd"},
+			ReviewComments: 4,
+		},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "**Size:** +120 / -30 lines across 6 files, 4 review comments") {
+		t.Fatalf("missing size in prompt:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "**Main Directories:** internal/store, cmd") {
+		t.Fatalf("missing directories in prompt:\n%s", prompt)
+	}
+}
+
+func TestAssemblePrompt_IncludesCollaboration(t *testing.T) {
+	alice := git.Author{Name: "Alice", Email: "alice@example.com"}
+	episode := &cluster.Episode{
+		ID:      "E1",
+		Commits: []git.Commit{{Hash: "abc123def456", Message: "Add store", Author: alice, PullRequestNumber: 3}},
+		Artifacts: []cluster.Artifact{{
+			Type:   cluster.ArtifactPullRequest,
+			Number: 3,
+			Discussions: []cluster.Discussion{
+				{ID: "r1", Type: cluster.DiscussionReview, Author: git.Author{Name: "Bob"}, ReviewState: "approved"},
+			},
+		}},
+	}
+
+	prompt, err := AssemblePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "- Alice implemented, Bob reviewed (1 review, approved)") {
+		t.Fatalf("missing collaboration in prompt:\n%s", prompt)
+	}
+}
@@ -0,0 +1,85 @@
+package narrative
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// Provenance records what a narrative was generated from, so what it says can be traced back to
+// its prompt and the retrieved context in it. The model is recorded on the Narrative itself.
+type Provenance struct {
+	Prompt     PromptType      `json:"prompt"`            // Type of the prompt the narrative was written from
+	Template   string          `json:"template"`          // Version of the prompt's template, see PromptTemplates.Version
+	PromptHash string          `json:"prompt_hash"`       // SHA-256 of the prompt sent to the model
+	Context    []ContextSource `json:"context,omitempty"` // Context chunks in the prompt, in prompt order
+}
+
+// ContextSource identifies a context chunk that was in a prompt.
+type ContextSource struct {
+	EpisodeID   string          `json:"episode_id"`
+	ChunkID     string          `json:"chunk_id,omitempty"`
+	Granularity rag.Granularity `json:"granularity,omitempty"`
+	Repository  string          `json:"repository,omitempty"`
+	Score       float32         `json:"score"`
+	TextHash    string          `json:"text_hash"`           // SHA-256 of the chunk's text as it appeared in the prompt
+	Truncated   bool            `json:"truncated,omitempty"` // The prompt budget cut the text short
+}
+
+// WithTrace returns the templates recording the provenance of each prompt they build in trace,
+// each prompt replacing the record of the one before.
+func (t *PromptTemplates) WithTrace(trace *Provenance) *PromptTemplates {
+	templates := *t
+	templates.trace = trace
+	return &templates
+}
+
+// newProvenance describes a rendered prompt, given the context chunks it was built with and those
+// left in it once the budget condensed it.
+func newProvenance(typ PromptType, version, prompt string, supplied, included []rag.ContextChunk) Provenance {
+	provenance := Provenance{Prompt: typ, Template: version, PromptHash: hashText(prompt)}
+	for i, chunk := range included {
+		provenance.Context = append(provenance.Context, ContextSource{
+			EpisodeID:   chunk.EpisodeID,
+			ChunkID:     chunk.ChunkID,
+			Granularity: chunk.Granularity,
+			Repository:  chunk.Repository,
+			Score:       chunk.Score,
+			TextHash:    hashText(chunk.Text),
+			// Condensing drops chunks from the end and shortens the last one kept, so kept chunks line up
+			Truncated: i < len(supplied) && supplied[i].Text != chunk.Text,
+		})
+	}
+	return provenance
+}
+
+// promptContext returns the context chunks in a prompt type's data.
+func promptContext(data any) []rag.ContextChunk {
+	switch data := data.(type) {
+	case EpisodePromptData:
+		return data.Context
+	case SummaryPromptData:
+		return data.Context
+	case CombinePromptData:
+		return data.Context
+	case ContributorPromptData:
+		return data.Context
+	case PeriodPromptData:
+		return data.Context
+	case ProjectPromptData:
+		chunks := make([]rag.ContextChunk, len(data.Context))
+		for i, chunk := range data.Context {
+			chunks[i] = chunk.ContextChunk
+		}
+		return chunks
+	default:
+		return nil
+	}
+}
+
+// hashText returns the hex SHA-256 of text.
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+package narrative
+
+import (
+	"testing"
+)
+
+func TestPromptTemplates_WithTrace(t *testing.T) {
+	var trace Provenance
+	episode, chunks := longEpisode(), longChunks(5)
+	templates := DefaultPromptTemplates().WithBudget(PromptBudget{MaxTokens: 1200}).WithTrace(&trace)
+	prompt, err := templates.EpisodePrompt(episode, chunks)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+
+	version, err := templates.Version(PromptEpisode)
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	if trace.Prompt != PromptEpisode || trace.Template != version || trace.PromptHash != hashText(prompt) {
+		t.Errorf("Expected the episode prompt's type, template version and hash, got %+v", trace)
+	}
+
+	// The budget keeps the best chunks, so the trace lists only those, the last cut short
+	if len(trace.Context) == 0 || len(trace.Context) >= len(chunks) {
+		t.Fatalf("Expected some but not all of the %d chunks to be traced, got %d", len(chunks), len(trace.Context))
+	}
+	for i, source := range trace.Context {
+		if source.EpisodeID != chunks[i].EpisodeID || source.Score != chunks[i].Score {
+			t.Errorf("Chunk %d: expected %s scoring %v, got %+v", i, chunks[i].EpisodeID, chunks[i].Score, source)
+		}
+		last := i == len(trace.Context)-1
+		if source.Truncated != last {
+			t.Errorf("Chunk %d: expected truncated %v, got %v", i, last, source.Truncated)
+		}
+		if !last && source.TextHash != hashText(chunks[i].Text) {
+			t.Errorf("Chunk %d: expected the hash of its full text, got %s", i, source.TextHash)
+		}
+	}
+
+	// Templates derived from traced ones record into the same trace
+	styled := templates.WithStyle(StyleExecutive)
+	if _, err := styled.ContributorPrompt(ContributorPromptData{Author: "Alice"}); err != nil {
+		t.Fatalf("ContributorPrompt failed: %v", err)
+	}
+	if trace.Prompt != PromptContributor || len(trace.Context) != 0 {
+		t.Errorf("Expected the contributor prompt to replace the trace, got %+v", trace)
+	}
+}
@@ -0,0 +1,85 @@
+package narrative
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// RetryPolicy sets how a Generator recovers from failed LLM requests: each model is retried with
+// exponential backoff, then the fallback models are tried in turn. The zero policy makes one
+// attempt with the configured model, bounded only by the caller's context.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries per model after its first attempt; requests rejected
+	// outright, such as for a bad API key, go straight to the next model
+	MaxRetries int
+
+	// InitialBackoff is the wait before the first retry, doubling before each later one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Timeout bounds each request, so a hung connection is retried instead of stalling the run (0 = no limit)
+	Timeout time.Duration
+
+	// FallbackModels are tried in order once the configured model has failed, e.g. "gpt-4o-mini"
+	// after "gpt-4o"; they use the configured provider and settings
+	FallbackModels []string
+}
+
+// DefaultRetryPolicy retries each request twice, starting a second apart, and gives each request two minutes.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     2,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Timeout:        2 * time.Minute,
+	}
+}
+
+// backoff returns the wait before the given retry, counted from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return wait
+}
+
+// retryable reports whether a failed request may succeed when sent again: network errors,
+// timeouts, rate limits and server errors may, while invalid requests and rejected credentials won't.
+func retryable(err error) bool {
+	if errors.Is(err, ErrInvalidConfig) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch code := apiErr.StatusCode; {
+		case code == http.StatusRequestTimeout, code == http.StatusConflict, code == http.StatusTooManyRequests:
+			return true
+		default:
+			return code >= http.StatusInternalServerError
+		}
+	}
+	return true
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+package narrative
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// scriptedLLM fails with its errors in turn, then answers
+type scriptedLLM struct {
+	mu     sync.Mutex
+	errs   []error
+	calls  int
+	answer string
+}
+
+func (l *scriptedLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls++
+	if len(l.errs) > 0 {
+		err := l.errs[0]
+		l.errs = l.errs[1:]
+		return "", err
+	}
+	return l.answer, nil
+}
+
+// hangingLLM never answers, returning only once its request is cancelled
+type hangingLLM struct {
+	calls int
+}
+
+func (l *hangingLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	l.calls++
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// apiError returns an OpenAI API error with the given HTTP status
+func apiError(status int) error {
+	req := httptest.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	return &openai.Error{StatusCode: status, Request: req, Response: &http.Response{StatusCode: status, Request: req}}
+}
+
+// retryGenerator returns a generator for llm that records its waits instead of sleeping
+func retryGenerator(llm LLM, policy RetryPolicy, fallbacks map[string]LLM) (*Generator, *[]time.Duration) {
+	var waits []time.Duration
+	gen := NewGenerator(llm, LLMConfig{Model: "gpt-4o", Retry: policy})
+	gen.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	gen.newLLM = func(config LLMConfig) (LLM, error) {
+		if llm, ok := fallbacks[config.Model]; ok {
+			return llm, nil
+		}
+		return nil, errors.New("unknown model")
+	}
+	return gen, &waits
+}
+
+func TestGenerator_Retries(t *testing.T) {
+	llm := &scriptedLLM{errs: []error{apiError(http.StatusServiceUnavailable), apiError(http.StatusTooManyRequests)}, answer: "Recovered."}
+	gen, waits := retryGenerator(llm, RetryPolicy{MaxRetries: 3, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second}, nil)
+
+	narr, err := gen.Generate(context.Background(), "E1", "prompt")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if narr.Text != "Recovered." || narr.Model != "gpt-4o" || llm.calls != 3 {
+		t.Errorf("Expected the third attempt to answer, got %+v after %d calls", narr, llm.calls)
+	}
+	if len(*waits) != 2 || (*waits)[0] != time.Second || (*waits)[1] != 2*time.Second {
+		t.Errorf("Expected waits of 1s and 2s, got %v", *waits)
+	}
+}
+
+func TestGenerator_Fallback(t *testing.T) {
+	primary := &scriptedLLM{errs: []error{apiError(http.StatusInternalServerError), apiError(http.StatusInternalServerError)}}
+	mini := &scriptedLLM{answer: "From the smaller model."}
+	policy := RetryPolicy{MaxRetries: 1, FallbackModels: []string{"missing", "gpt-4o-mini"}}
+	gen, _ := retryGenerator(primary, policy, map[string]LLM{"gpt-4o-mini": mini})
+
+	narr, err := gen.Generate(context.Background(), "E1", "prompt")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if narr.Model != "gpt-4o-mini" || narr.Text != "From the smaller model." {
+		t.Errorf("Expected the fallback model's narrative, got %+v", narr)
+	}
+	if primary.calls != 2 {
+		t.Errorf("Expected the primary model to be retried once, got %d calls", primary.calls)
+	}
+
+	// Rejected requests aren't retried, but still fall back
+	rejected := &scriptedLLM{errs: []error{apiError(http.StatusUnauthorized)}}
+	gen, waits := retryGenerator(rejected, policy, map[string]LLM{"gpt-4o-mini": &scriptedLLM{errs: []error{apiError(http.StatusUnauthorized)}}})
+	_, err = gen.Generate(context.Background(), "E1", "prompt")
+	if !errors.Is(err, ErrGenerationFailed) || rejected.calls != 1 || len(*waits) != 0 {
+		t.Errorf("Expected one attempt per model and ErrGenerationFailed, got %d calls, waits %v and %v", rejected.calls, *waits, err)
+	}
+}
+
+func TestGenerator_Timeout(t *testing.T) {
+	hanging := &hangingLLM{}
+	gen, _ := retryGenerator(hanging, RetryPolicy{MaxRetries: 1, Timeout: 10 * time.Millisecond}, nil)
+
+	_, err := gen.Generate(context.Background(), "E1", "prompt")
+	if !errors.Is(err, context.DeadlineExceeded) || hanging.calls != 2 {
+		t.Errorf("Expected both attempts to time out, got %d calls and %v", hanging.calls, err)
+	}
+
+	// A cancelled run stops without retrying
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hanging.calls = 0
+	if _, err := gen.Generate(ctx, "E1", "prompt"); !errors.Is(err, context.Canceled) || hanging.calls != 1 {
+		t.Errorf("Expected a single cancelled attempt, got %d calls and %v", hanging.calls, err)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 40: 5 * time.Second} {
+		if got := policy.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
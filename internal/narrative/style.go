@@ -0,0 +1,74 @@
+package narrative
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Style is the audience and tone a narrative is written for. Each style has its own prompt
+// templates, which set how long the narrative should be and what it dwells on.
+type Style string
+
+const (
+	// StyleTechnical is a narrative for engineers on the project, 2-4 paragraphs on what changed
+	// and why; it is the default.
+	StyleTechnical Style = "technical"
+
+	// StyleExecutive is a short summary for leadership of outcomes, impact and risks, without
+	// implementation detail.
+	StyleExecutive Style = "executive"
+
+	// StyleDeepDive is a long, detailed account for engineers of the design, tradeoffs and code
+	// involved.
+	StyleDeepDive Style = "deep-dive"
+
+	// StyleOnboarding is a guide for new contributors to the code and concepts a piece of work
+	// touched and how to find their way around them.
+	StyleOnboarding Style = "onboarding"
+
+	// StyleStandup is a few bullet points of what was done and what is still open, as said at a
+	// standup meeting.
+	StyleStandup Style = "standup"
+)
+
+// styles lists every style in the order errors and docs mention them.
+var styles = []Style{StyleTechnical, StyleExecutive, StyleDeepDive, StyleOnboarding, StyleStandup}
+
+var ErrUnknownStyle = errors.New("unknown narrative style")
+
+// Styles returns every narrative style.
+func Styles() []Style {
+	return append([]Style(nil), styles...)
+}
+
+// ParseStyle returns the style with the given name, ignoring case; an empty name is StyleTechnical.
+func ParseStyle(name string) (Style, error) {
+	style := Style(strings.ToLower(strings.TrimSpace(name)))
+	if style == "" {
+		return StyleTechnical, nil
+	}
+	for _, known := range styles {
+		if style == known {
+			return style, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q (supported: %s)", ErrUnknownStyle, name, styleNames())
+}
+
+// styleNames lists the style names for error messages.
+func styleNames() string {
+	names := make([]string, len(styles))
+	for i, style := range styles {
+		names[i] = string(style)
+	}
+	return strings.Join(names, ", ")
+}
+
+// orDefault returns the style, or StyleTechnical when it is empty.
+func (s Style) orDefault() Style {
+	if s == "" {
+		return StyleTechnical
+	}
+	return s
+}
@@ -0,0 +1,25 @@
+package narrative
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := map[string]Style{
+		"":           StyleTechnical,
+		"technical":  StyleTechnical,
+		"Executive":  StyleExecutive,
+		" deep-dive": StyleDeepDive,
+		"onboarding": StyleOnboarding,
+		"standup":    StyleStandup,
+	}
+	for name, want := range tests {
+		if got, err := ParseStyle(name); err != nil || got != want {
+			t.Errorf("%q: expected %q, got %q (error %v)", name, want, got, err)
+		}
+	}
+	if _, err := ParseStyle("haiku"); !errors.Is(err, ErrUnknownStyle) {
+		t.Errorf("Expected ErrUnknownStyle, got %v", err)
+	}
+}
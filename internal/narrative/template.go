@@ -0,0 +1,711 @@
+package narrative
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// PromptType names the kind of narrative a prompt template generates.
+type PromptType string
+
+const (
+	// PromptEpisode is the prompt for one episode's narrative, rendered from EpisodePromptData.
+	PromptEpisode PromptType = "episode"
+
+	// PromptProject is the prompt for answering a question about the project, rendered from ProjectPromptData.
+	PromptProject PromptType = "project"
+
+	// PromptSummary is the prompt summarizing one part of a huge episode, rendered from SummaryPromptData.
+	PromptSummary PromptType = "summary"
+
+	// PromptCombine is the prompt for a huge episode's narrative written from the summaries of its
+	// parts, rendered from CombinePromptData.
+	PromptCombine PromptType = "combine"
+
+	// PromptContributor is the prompt for a personal summary of one contributor's work, rendered
+	// from ContributorPromptData.
+	PromptContributor PromptType = "contributor"
+
+	// PromptPeriod is the prompt for a digest narrative of one week or month of work, rendered
+	// from PeriodPromptData.
+	PromptPeriod PromptType = "period"
+
+	// PromptDigest is the prompt for a digest's overview of the trends across its periods,
+	// rendered from DigestPromptData.
+	PromptDigest PromptType = "digest"
+)
+
+// promptTypes lists every prompt type in the order errors and docs mention them.
+var promptTypes = []PromptType{PromptEpisode, PromptProject, PromptSummary, PromptCombine, PromptContributor, PromptPeriod, PromptDigest}
+
+// templateExt is the file extension of prompt templates; each type's file is named after it, e.g. "episode.tmpl".
+const templateExt = ".tmpl"
+
+// partialsName is the file of named templates holding the sections prompt templates share,
+// such as the episode's details, which they include with {{template "episode-details" .}}.
+const partialsName = "partials" + templateExt
+
+// requiredPlaceholders are the data fields each prompt type must use, so a customized template
+// cannot silently drop the episode, the question or the retrieved context from its prompt.
+var requiredPlaceholders = map[PromptType][]string{
+	PromptEpisode:     {"Episode", "Context"},
+	PromptProject:     {"Question", "Context"},
+	PromptSummary:     {"Episode"},
+	PromptCombine:     {"Episode", "Summaries", "Context"},
+	PromptContributor: {"Author", "Contributions", "Context"},
+	PromptPeriod:      {"Label", "Highlights", "Context"},
+	PromptDigest:      {"Periods"},
+}
+
+var (
+	ErrInvalidTemplate = errors.New("invalid prompt template")
+	ErrUnknownTemplate = errors.New("unknown prompt template")
+)
+
+// The built-in templates of StyleTechnical are in templates/, and those of other styles in
+// templates/<style>/; a style without a template of its own for a type uses StyleTechnical's.
+//
+//go:embed templates/*.tmpl templates/*/*.tmpl
+var defaultTemplateFiles embed.FS
+
+// templateFuncs are the functions available to prompt templates besides text/template's builtins.
+var templateFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"joinAnd": joinWithAnd,
+	"date":    formatDateOrNA,
+}
+
+// EpisodePromptData is the data the episode template renders.
+// Collaboration, Iterations, Timeline and Artifacts are preformatted Markdown sections,
+// empty when the episode has nothing to list (Timeline always lists at least "(none)").
+type EpisodePromptData struct {
+	Episode       *cluster.Episode
+	Start, End    time.Time // Range of the episode's commit dates; zero without commits
+	Authors       []string  // Distinct commit authors, sorted
+	Languages     []string  // Up to three primary languages of the changes
+	Collaboration string
+	Iterations    string
+	Timeline      string
+	Artifacts     string
+	Diffs         []FileDiff         // Hunks of the most changed files, when DiffOptions include them
+	Exemplars     []Exemplar         // Few-shot examples of the narratives to write, if any
+	Context       []rag.ContextChunk // Related episodes, most relevant first
+}
+
+// SummaryPromptData is the data the summary template renders: one part of a huge episode, as
+// split by cluster.Episode.Split, with its position among the parts counted from 1.
+type SummaryPromptData struct {
+	EpisodePromptData
+	Part, Parts int
+}
+
+// CombinePromptData is the data the combine template renders.
+type CombinePromptData struct {
+	Episode    *cluster.Episode
+	Start, End time.Time // Range of the episode's commit dates; zero without commits
+	Authors    []string  // Distinct commit authors, sorted
+	Languages  []string  // Up to three primary languages of the changes
+	Summaries  []PartSummary
+	Exemplars  []Exemplar         // Few-shot examples of the narratives to write, if any
+	Context    []rag.ContextChunk // Related episodes, most relevant first
+}
+
+// PartSummary is the summary of one part of a huge episode.
+type PartSummary struct {
+	Part       int       // Position among the parts, counted from 1
+	Start, End time.Time // Range of the part's commit dates; zero without commits
+	Commits    int
+	Artifacts  int
+	Text       string
+}
+
+// NewPartSummary describes the summary of a part of an episode, at position index counted from 1.
+func NewPartSummary(part *cluster.Episode, index int, text string) PartSummary {
+	start, end := getTimeRange(part.Commits)
+	return PartSummary{
+		Part:      index,
+		Start:     start,
+		End:       end,
+		Commits:   len(part.Commits),
+		Artifacts: len(part.Artifacts),
+		Text:      text,
+	}
+}
+
+// ProjectPromptData is the data the project template renders.
+type ProjectPromptData struct {
+	Question     string
+	Episodes     int
+	Commits      int
+	Contributors int
+	Repositories []string  // Distinct repositories, sorted
+	Start, End   time.Time // Range of all commit dates; zero without commits
+	WorkMix      string    // Category counts across all episodes, e.g. "3 feature, 1 bugfix"
+	Quarters     []QuarterMix
+	Arcs         []ArcPromptData
+	Hotspots     []cluster.FileHotspot
+	Context      []ProjectContextChunk // Retrieved context, most relevant first
+}
+
+// ContributorPromptData is the data the contributor template renders.
+// Contributions is a preformatted Markdown list of the contributor's episodes, largest first.
+type ContributorPromptData struct {
+	Author        string    // The contributor as asked about, by name or email
+	Names         []string  // Names the contributor committed under, sorted
+	Since, Until  time.Time // Period asked about; zero for an open end
+	Start, End    time.Time // Range of the contributor's commit dates in the period
+	Episodes      int
+	Commits       int
+	Additions     int
+	Deletions     int
+	WorkMix       string   // Category counts across the contributor's episodes, e.g. "3 feature, 1 bugfix"
+	Collaborators []string // Other authors of the contributor's episodes, sorted
+	Contributions string
+	Context       []rag.ContextChunk // Related work, most relevant first
+}
+
+// PeriodPromptData is the data the period template renders.
+// Highlights is a preformatted Markdown list of the period's episodes, largest first.
+type PeriodPromptData struct {
+	Period       string    // "week" or "month"
+	Label        string    // e.g. "2024-W10" or "2024-03"
+	Start, End   time.Time // First and last day of the period
+	Episodes     int
+	Commits      int
+	Additions    int
+	Deletions    int
+	Contributors []string // Distinct commit authors in the period, sorted
+	WorkMix      string   // Category counts across the period's episodes, e.g. "3 feature, 1 bugfix"
+	Highlights   string
+	Context      []rag.ContextChunk // Earlier related work, most relevant first
+}
+
+// DigestPromptData is the data the digest template renders.
+type DigestPromptData struct {
+	Period       string    // "week" or "month"
+	Start, End   time.Time // First day of the first period and last day of the last
+	Episodes     int
+	Commits      int
+	Contributors []string // Distinct commit authors across the periods, sorted
+	Periods      []DigestPeriod
+}
+
+// DigestPeriod is one period of a digest, oldest first.
+type DigestPeriod struct {
+	Label        string
+	Start, End   time.Time // First and last day of the period
+	Episodes     int
+	Commits      int
+	Additions    int
+	Deletions    int
+	Contributors int
+	WorkMix      string
+	Summary      string // The period's narrative; empty for a period without commits
+}
+
+// QuarterMix is the work mix of one calendar quarter.
+type QuarterMix struct {
+	Quarter string // e.g. "2024 Q2"
+	Mix     string
+}
+
+// ArcPromptData outlines a story arc and its first episodes.
+type ArcPromptData struct {
+	ID, Title    string
+	Start, End   time.Time
+	EpisodeCount int
+	Commits      int
+	Episodes     []ArcEpisodePromptData
+	MoreEpisodes int // Episodes of the arc left out of Episodes
+}
+
+// ArcEpisodePromptData is one episode listed under its arc.
+type ArcEpisodePromptData struct {
+	ID       string
+	Start    time.Time
+	Category cluster.Category
+	Title    string
+}
+
+// ProjectContextChunk is a retrieved chunk with the heading it is listed under.
+type ProjectContextChunk struct {
+	rag.ContextChunk
+	Heading string
+}
+
+// PromptTemplates holds a template for every prompt type and style, the style prompts are
+// built in, and the budget they are fit to.
+type PromptTemplates struct {
+	templates map[templateKey]*template.Template
+	style     Style
+	budget    PromptBudget
+	diffs     DiffOptions
+	exemplars []Exemplar
+	trace     *Provenance // Records the provenance of each prompt built, if set
+}
+
+// templateKey identifies the template of a prompt type in a style.
+type templateKey struct {
+	style Style
+	typ   PromptType
+}
+
+// templatePath returns where a style's template of a type lives, relative to a template directory.
+func templatePath(style Style, typ PromptType) string {
+	if style == StyleTechnical {
+		return string(typ) + templateExt
+	}
+	return path.Join(string(style), string(typ)+templateExt)
+}
+
+// defaultPartials parses the embedded shared sections once.
+var defaultPartials = sync.OnceValue(func() *template.Template {
+	text, err := defaultTemplateFiles.ReadFile("templates/" + partialsName)
+	if err != nil {
+		panic(fmt.Sprintf("missing embedded prompt template %s: %v", partialsName, err))
+	}
+	return template.Must(template.New(partialsName).Funcs(templateFuncs).Parse(string(text)))
+})
+
+// defaultPromptTemplates parses the embedded templates once.
+var defaultPromptTemplates = sync.OnceValue(func() *PromptTemplates {
+	templates := &PromptTemplates{templates: make(map[templateKey]*template.Template), style: StyleTechnical}
+	for _, style := range styles {
+		for _, typ := range promptTypes {
+			name := templatePath(style, typ)
+			text, err := defaultTemplateFiles.ReadFile("templates/" + name)
+			if errors.Is(err, fs.ErrNotExist) && style != StyleTechnical {
+				continue
+			}
+			if err != nil {
+				panic(fmt.Sprintf("missing embedded prompt template %s: %v", name, err))
+			}
+			tmpl, err := parsePromptTemplate(typ, name, string(text), defaultPartials())
+			if err != nil {
+				panic(err)
+			}
+			templates.templates[templateKey{style, typ}] = tmpl
+		}
+	}
+	return templates
+})
+
+// DefaultPromptTemplates returns the built-in prompt templates.
+func DefaultPromptTemplates() *PromptTemplates {
+	return defaultPromptTemplates()
+}
+
+// LoadPromptTemplates returns the built-in templates overridden by the ones in dir, which are named after their
+// prompt type (episode.tmpl, project.tmpl, summary.tmpl, combine.tmpl, contributor.tmpl, period.tmpl, digest.tmpl); types without a file
+// keep the default.
+// Templates for a style other than StyleTechnical go in a subdirectory named after it, e.g. executive/episode.tmpl,
+// and a partials.tmpl may redefine the shared sections for the templates in dir.
+// Every template found is validated, so a mistake is reported at startup rather than mid-run.
+func LoadPromptTemplates(dir string) (*PromptTemplates, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template directory: %w", err)
+	}
+
+	partials := defaultPartials()
+	if text, err := os.ReadFile(filepath.Join(dir, partialsName)); err == nil {
+		if partials, err = template.Must(partials.Clone()).Parse(string(text)); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	defaults := DefaultPromptTemplates()
+	templates := &PromptTemplates{templates: make(map[templateKey]*template.Template, len(defaults.templates)), style: StyleTechnical}
+	for key, tmpl := range defaults.templates {
+		templates.templates[key] = tmpl
+	}
+
+	if err := templates.load(dir, StyleTechnical, entries, partials); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		style, err := ParseStyle(entry.Name())
+		if !entry.IsDir() || err != nil || style == StyleTechnical {
+			continue
+		}
+		styleEntries, err := os.ReadDir(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template directory: %w", err)
+		}
+		if err := templates.load(filepath.Join(dir, entry.Name()), style, styleEntries, partials); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// load parses the templates among a directory's entries as the style's.
+func (t *PromptTemplates) load(dir string, style Style, entries []os.DirEntry, partials *template.Template) error {
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != templateExt || name == partialsName {
+			continue
+		}
+		typ := PromptType(strings.TrimSuffix(name, templateExt))
+		if _, ok := requiredPlaceholders[typ]; !ok {
+			return fmt.Errorf("%w: %s (expected one of %s)", ErrUnknownTemplate, templatePath(style, typ), templateFileNames())
+		}
+		text, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		tmpl, err := parsePromptTemplate(typ, templatePath(style, typ), string(text), partials)
+		if err != nil {
+			return err
+		}
+		t.templates[templateKey{style, typ}] = tmpl
+	}
+	return nil
+}
+
+// templateFileNames lists the template file names LoadPromptTemplates recognizes.
+func templateFileNames() string {
+	names := make([]string, len(promptTypes))
+	for i, typ := range promptTypes {
+		names[i] = string(typ) + templateExt
+	}
+	return strings.Join(names, ", ")
+}
+
+// parsePromptTemplate parses a template alongside the shared sections in partials, and checks it
+// uses the type's required placeholders and renders sample data, which catches misspelled fields
+// and functions before any prompt is built.
+func parsePromptTemplate(typ PromptType, name, text string, partials *template.Template) (*template.Template, error) {
+	tmpl, err := template.Must(partials.Clone()).New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	used := make(map[string]bool)
+	collectFields(tmpl, tmpl.Tree.Root, used, make(map[string]bool))
+	var missing []string
+	for _, field := range requiredPlaceholders[typ] {
+		if !used[field] {
+			missing = append(missing, "{{."+field+"}}")
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s never uses %s", ErrInvalidTemplate, name, strings.Join(missing, ", "))
+	}
+
+	if err := tmpl.Execute(&strings.Builder{}, samplePromptData(typ)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// collectFields records the top-level data fields a template node references, whether as .Field or $.Field.
+// Fields referenced inside range and with blocks, and inside the named templates of set it includes,
+// are collected as well; since those change the dot, a nested .Field may name a field of an element
+// rather than of the data, which only makes the check more lenient. followed holds the named
+// templates already collected.
+func collectFields(set *template.Template, node parse.Node, used, followed map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(set, child, used, followed)
+		}
+	case *parse.ActionNode:
+		collectFields(set, n.Pipe, used, followed)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(set, cmd, used, followed)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(set, arg, used, followed)
+		}
+	case *parse.FieldNode:
+		used[n.Ident[0]] = true
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			used[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		collectFields(set, n.Node, used, followed)
+	case *parse.IfNode:
+		collectFields(set, n.Pipe, used, followed)
+		collectFields(set, n.List, used, followed)
+		collectFields(set, n.ElseList, used, followed)
+	case *parse.RangeNode:
+		collectFields(set, n.Pipe, used, followed)
+		collectFields(set, n.List, used, followed)
+		collectFields(set, n.ElseList, used, followed)
+	case *parse.WithNode:
+		collectFields(set, n.Pipe, used, followed)
+		collectFields(set, n.List, used, followed)
+		collectFields(set, n.ElseList, used, followed)
+	case *parse.TemplateNode:
+		collectFields(set, n.Pipe, used, followed)
+		if included := set.Lookup(n.Name); included != nil && included.Tree != nil && !followed[n.Name] {
+			followed[n.Name] = true
+			collectFields(set, included.Tree.Root, used, followed)
+		}
+	}
+}
+
+// samplePromptData returns data for a trial render of a type's template, with one of each list
+// element so templates are checked inside their range blocks too.
+func samplePromptData(typ PromptType) any {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	episode := EpisodePromptData{
+		Episode: &cluster.Episode{
+			ID:         "E1",
+			Repository: "owner/app",
+			Category:   "feature",
+			Stats:      cluster.EpisodeStats{FilesChanged: 1, MergeCount: 1, ReviewComments: 1, TopDirectories: []string{"internal"}},
+		},
+		Start:     date,
+		End:       date,
+		Authors:   []string{"Alice"},
+		Languages: []string{"Go"},
+		Artifacts: "- **pull_request #1:** Add a feature\n",
+		Diffs:     []FileDiff{{Path: "main.go", Language: "Go", Additions: 1, Hunks: "@@ -0,0 +1 @@\n+package main\n"}},
+		Exemplars: []Exemplar{{Episode: "Episode E0: Add a feature", Narrative: "The team added a feature."}},
+		Context:   []rag.ContextChunk{{EpisodeID: "E2"}},
+	}
+	switch typ {
+	case PromptProject:
+		return ProjectPromptData{
+			Question:     "What changed?",
+			Repositories: []string{"owner/api", "owner/app"},
+			Start:        date,
+			End:          date,
+			WorkMix:      "2 feature",
+			Quarters:     []QuarterMix{{Quarter: "2023 Q4", Mix: "1 feature"}, {Quarter: "2024 Q1", Mix: "1 feature"}},
+			Arcs: []ArcPromptData{{
+				ID: "A1", Start: date, End: date,
+				Episodes:     []ArcEpisodePromptData{{ID: "E1", Start: date, Category: "feature"}},
+				MoreEpisodes: 1,
+			}},
+			Hotspots: []cluster.FileHotspot{{Path: "main.go"}},
+			Context:  []ProjectContextChunk{{ContextChunk: rag.ContextChunk{EpisodeID: "E1"}, Heading: "Episode 1: E1"}},
+		}
+	case PromptSummary:
+		return SummaryPromptData{EpisodePromptData: episode, Part: 1, Parts: 2}
+	case PromptContributor:
+		return ContributorPromptData{
+			Author:        "alice@example.com",
+			Names:         []string{"Alice"},
+			Since:         date,
+			Start:         date,
+			End:           date,
+			Episodes:      1,
+			Commits:       1,
+			WorkMix:       "1 feature",
+			Collaborators: []string{"Bob"},
+			Contributions: "- **E1** (2024-01-15 to 2024-01-15, feature): Add a feature; 1 commits, +10 / -2 lines\n",
+			Context:       episode.Context,
+		}
+	case PromptPeriod:
+		return PeriodPromptData{
+			Period:       "week",
+			Label:        "2024-W03",
+			Start:        date,
+			End:          date.AddDate(0, 0, 6),
+			Episodes:     1,
+			Commits:      1,
+			Contributors: []string{"Alice"},
+			WorkMix:      "1 feature",
+			Highlights:   "- **E1** (2024-01-15 to 2024-01-15, feature): Add a feature; 1 commits by Alice\n",
+			Context:      episode.Context,
+		}
+	case PromptDigest:
+		return DigestPromptData{
+			Period:       "week",
+			Start:        date,
+			End:          date.AddDate(0, 0, 13),
+			Episodes:     1,
+			Commits:      1,
+			Contributors: []string{"Alice"},
+			Periods: []DigestPeriod{
+				{Label: "2024-W03", Start: date, End: date.AddDate(0, 0, 6), Episodes: 1, Commits: 1, Contributors: 1, WorkMix: "1 feature", Summary: "Added a feature."},
+				{Label: "2024-W04", Start: date.AddDate(0, 0, 7), End: date.AddDate(0, 0, 13)},
+			},
+		}
+	case PromptCombine:
+		return CombinePromptData{
+			Episode:   episode.Episode,
+			Start:     date,
+			End:       date,
+			Authors:   episode.Authors,
+			Languages: episode.Languages,
+			Summaries: []PartSummary{{Part: 1, Start: date, End: date, Text: "Added a feature."}},
+			Exemplars: episode.Exemplars,
+			Context:   episode.Context,
+		}
+	default:
+		return episode
+	}
+}
+
+// WithBudget returns the templates condensing the prompts they build to fit budget.
+func (t *PromptTemplates) WithBudget(budget PromptBudget) *PromptTemplates {
+	templates := *t
+	templates.budget = budget
+	return &templates
+}
+
+// WithStyle returns the templates building prompts in style; an empty style is StyleTechnical.
+func (t *PromptTemplates) WithStyle(style Style) *PromptTemplates {
+	templates := *t
+	templates.style = style.orDefault()
+	return &templates
+}
+
+// Style returns the style the templates build prompts in.
+func (t *PromptTemplates) Style() Style {
+	return t.style.orDefault()
+}
+
+// Render renders the template of a prompt type in the templates' style with its data, using
+// StyleTechnical's template when the style has none of its own for the type.
+func (t *PromptTemplates) Render(typ PromptType, data any) (string, error) {
+	tmpl, err := t.lookup(typ)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", typ, err)
+	}
+	return b.String(), nil
+}
+
+// lookup returns the template of a prompt type in the templates' style, falling back to StyleTechnical's.
+func (t *PromptTemplates) lookup(typ PromptType) (*template.Template, error) {
+	style := t.Style()
+	if _, err := ParseStyle(string(style)); err != nil {
+		return nil, err
+	}
+	tmpl, ok := t.templates[templateKey{style, typ}]
+	if !ok {
+		tmpl, ok = t.templates[templateKey{StyleTechnical, typ}]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, typ)
+	}
+	return tmpl, nil
+}
+
+// Version identifies the template Render uses for a prompt type, with the partials it may include,
+// so narratives cached under it are regenerated once the template is edited.
+func (t *PromptTemplates) Version(typ PromptType) (string, error) {
+	tmpl, err := t.lookup(typ)
+	if err != nil {
+		return "", err
+	}
+	defined := tmpl.Templates()
+	sort.Slice(defined, func(i, j int) bool { return defined[i].Name() < defined[j].Name() })
+	hash := sha256.New()
+	for _, named := range defined {
+		if named.Tree == nil || named.Tree.Root == nil {
+			continue
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00", named.Name(), named.Tree.Root.String())
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// EpisodePrompt builds the prompt for an episode's narrative, with the context chunks ordered by relevance.
+func (t *PromptTemplates) EpisodePrompt(targetEpisode *cluster.Episode, contextChunks []rag.ContextChunk) (string, error) {
+	if targetEpisode == nil {
+		return "", ErrMissingTargetEpisode
+	}
+
+	// Sort context chunks by relevance score (highest first), even if already sorted.
+	sorted := make([]rag.ContextChunk, len(contextChunks))
+	copy(sorted, contextChunks)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	data := episodePromptData(targetEpisode, sorted)
+	data.Diffs = episodeDiffs(targetEpisode, t.diffs, t.budget.tokenizer())
+	data.Exemplars = t.styleExemplars()
+	return t.renderWithin(PromptEpisode, func() any { return data }, t.budget.episodeSections(&data))
+}
+
+// SummaryPrompt builds the prompt summarizing a part of a huge episode, at position index of parts counted from 1.
+func (t *PromptTemplates) SummaryPrompt(part *cluster.Episode, index, parts int) (string, error) {
+	if part == nil {
+		return "", ErrMissingTargetEpisode
+	}
+	data := SummaryPromptData{EpisodePromptData: episodePromptData(part, nil), Part: index, Parts: parts}
+	data.Diffs = episodeDiffs(part, t.diffs, t.budget.tokenizer())
+	return t.renderWithin(PromptSummary, func() any { return data }, t.budget.episodeSections(&data.EpisodePromptData))
+}
+
+// CombinePrompt builds the prompt for a huge episode's narrative from the summaries of its parts,
+// with the context chunks ordered by relevance.
+func (t *PromptTemplates) CombinePrompt(targetEpisode *cluster.Episode, summaries []PartSummary, contextChunks []rag.ContextChunk) (string, error) {
+	if targetEpisode == nil {
+		return "", ErrMissingTargetEpisode
+	}
+
+	sorted := make([]rag.ContextChunk, len(contextChunks))
+	copy(sorted, contextChunks)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	start, end := getTimeRange(targetEpisode.Commits)
+	data := CombinePromptData{
+		Episode:   targetEpisode,
+		Start:     start,
+		End:       end,
+		Authors:   getUniqueAuthors(targetEpisode.Commits),
+		Languages: targetEpisode.GetPrimaryLanguages(3),
+		Summaries: append([]PartSummary(nil), summaries...),
+		Exemplars: t.styleExemplars(),
+		Context:   sorted,
+	}
+	return t.renderWithin(PromptCombine, func() any { return data }, t.budget.combineSections(&data))
+}
+
+// ContributorPrompt builds the prompt for a personal summary of a contributor's work.
+func (t *PromptTemplates) ContributorPrompt(data ContributorPromptData) (string, error) {
+	return t.renderWithin(PromptContributor, func() any { return data }, t.budget.contributorSections(&data))
+}
+
+// PeriodPrompt builds the prompt for a digest narrative of one period's work.
+func (t *PromptTemplates) PeriodPrompt(data PeriodPromptData) (string, error) {
+	return t.renderWithin(PromptPeriod, func() any { return data }, t.budget.periodSections(&data))
+}
+
+// DigestPrompt builds the prompt for a digest's overview of the trends across its periods.
+func (t *PromptTemplates) DigestPrompt(data DigestPromptData) (string, error) {
+	data.Periods = append([]DigestPeriod(nil), data.Periods...)
+	return t.renderWithin(PromptDigest, func() any { return data }, t.budget.digestSections(&data))
+}
+
+// ProjectPrompt builds the prompt for answering a question about the project.
+func (t *PromptTemplates) ProjectPrompt(data ProjectPromptData) (string, error) {
+	return t.renderWithin(PromptProject, func() any { return data }, t.budget.projectSections(&data))
+}
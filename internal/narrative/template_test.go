@@ -0,0 +1,182 @@
+package narrative
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// writeTemplates writes prompt templates into a new directory, keyed by file name
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoadPromptTemplates_Override(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"episode.tmpl": "Summarize {{.Episode.ID}} by {{join .Authors \" & \"}}.{{range .Context}} See {{.EpisodeID}}.{{end}}",
+		"notes.txt":    "not a template",
+	})
+	templates, err := LoadPromptTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates failed: %v", err)
+	}
+
+	episode := &cluster.Episode{ID: "E1", Commits: []git.Commit{
+		{Hash: "abc1234", Author: git.Author{Name: "Bob"}},
+		{Hash: "def5678", Author: git.Author{Name: "Alice"}},
+	}}
+	chunks := []rag.ContextChunk{{EpisodeID: "E3", Score: 0.5}, {EpisodeID: "E2", Score: 0.9}}
+	prompt, err := templates.EpisodePrompt(episode, chunks)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+	if want := "Summarize E1 by Alice & Bob. See E2. See E3."; prompt != want {
+		t.Errorf("Expected %q, got %q", want, prompt)
+	}
+
+	// Without a project.tmpl, project prompts keep the built-in template
+	project, err := templates.ProjectPrompt(ProjectPromptData{Question: "What changed?"})
+	if err != nil {
+		t.Fatalf("ProjectPrompt failed: %v", err)
+	}
+	builtIn, _ := DefaultPromptTemplates().ProjectPrompt(ProjectPromptData{Question: "What changed?"})
+	if project != builtIn {
+		t.Errorf("Expected the built-in project prompt, got:\n%s", project)
+	}
+}
+
+func TestLoadPromptTemplates_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  error
+		msg   string
+	}{
+		{"missing placeholder", map[string]string{"project.tmpl": "Answer: {{.Question}}"}, ErrInvalidTemplate, "{{.Context}}"},
+		{"misspelled field", map[string]string{"episode.tmpl": "{{.Episode.ID}} {{.Context}} {{.Authros}}"}, ErrInvalidTemplate, "Authros"},
+		{"syntax error", map[string]string{"episode.tmpl": "{{.Episode.ID} {{.Context}}"}, ErrInvalidTemplate, "episode.tmpl"},
+		{"unknown type", map[string]string{"episodes.tmpl": "{{.Episode}} {{.Context}}"}, ErrUnknownTemplate, "episodes.tmpl"},
+	}
+	for _, tt := range tests {
+		_, err := LoadPromptTemplates(writeTemplates(t, tt.files))
+		if !errors.Is(err, tt.want) || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: expected %v mentioning %q, got %v", tt.name, tt.want, tt.msg, err)
+		}
+	}
+
+	if _, err := LoadPromptTemplates(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestPromptTemplates_PlaceholdersThroughRoot(t *testing.T) {
+	// $.Context counts as using the context, even inside a block that changes the dot
+	dir := writeTemplates(t, map[string]string{
+		"project.tmpl": "{{with .Question}}{{.}}{{range $.Context}} {{.Heading}}{{end}}{{end}}",
+	})
+	templates, err := LoadPromptTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates failed: %v", err)
+	}
+	prompt, err := templates.ProjectPrompt(ProjectPromptData{
+		Question: "Why?",
+		Context:  []ProjectContextChunk{{Heading: "Episode 1: E1"}},
+	})
+	if err != nil || prompt != "Why? Episode 1: E1" {
+		t.Errorf("Unexpected prompt %q (error %v)", prompt, err)
+	}
+}
+
+func TestPromptTemplates_Styles(t *testing.T) {
+	episode := &cluster.Episode{ID: "E1", Commits: []git.Commit{{Hash: "abc1234", Author: git.Author{Name: "Alice"}}}}
+	technical, err := DefaultPromptTemplates().EpisodePrompt(episode, nil)
+	if err != nil {
+		t.Fatalf("EpisodePrompt failed: %v", err)
+	}
+
+	wants := map[Style]string{
+		StyleExecutive:  "executive summary",
+		StyleDeepDive:   "technical deep-dive",
+		StyleOnboarding: "onboarding guide",
+		StyleStandup:    "standup update",
+	}
+	for style, want := range wants {
+		templates := DefaultPromptTemplates().WithStyle(style)
+		if templates.Style() != style {
+			t.Errorf("%s: expected the style to be kept, got %s", style, templates.Style())
+		}
+		prompt, err := templates.EpisodePrompt(episode, nil)
+		if err != nil {
+			t.Fatalf("%s: EpisodePrompt failed: %v", style, err)
+		}
+		if prompt == technical || !strings.Contains(prompt, want) || !strings.Contains(prompt, "**Episode ID:** E1") {
+			t.Errorf("%s: expected its own prompt with the episode's details, got:\n%s", style, prompt)
+		}
+		project, err := templates.ProjectPrompt(ProjectPromptData{Question: "What changed?"})
+		if err != nil || !strings.Contains(project, "What changed?") || !strings.Contains(project, want) {
+			t.Errorf("%s: unexpected project prompt %q (error %v)", style, project, err)
+		}
+		combine, err := templates.CombinePrompt(episode, []PartSummary{{Part: 1, Text: "Added retries."}}, nil)
+		if err != nil || !strings.Contains(combine, "Added retries.") || !strings.Contains(combine, want) {
+			t.Errorf("%s: unexpected combine prompt %q (error %v)", style, combine, err)
+		}
+	}
+
+	// Summaries of parts read the same whatever the style of the final narrative
+	summary, _ := DefaultPromptTemplates().SummaryPrompt(episode, 1, 2)
+	if styled, err := DefaultPromptTemplates().WithStyle(StyleStandup).SummaryPrompt(episode, 1, 2); err != nil || styled != summary {
+		t.Errorf("Expected the technical summary prompt, got %q (error %v)", styled, err)
+	}
+
+	if _, err := DefaultPromptTemplates().WithStyle("haiku").EpisodePrompt(episode, nil); !errors.Is(err, ErrUnknownStyle) {
+		t.Errorf("Expected ErrUnknownStyle, got %v", err)
+	}
+}
+
+func TestLoadPromptTemplates_Styles(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"partials.tmpl": `{{define "related-context"}}{{range .}} See {{.EpisodeID}}.{{end}}{{end}}`,
+	})
+	if err := os.Mkdir(filepath.Join(dir, "executive"), 0o755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "executive", "episode.tmpl"), []byte(`Brief on {{.Episode.ID}}.{{template "related-context" .Context}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	templates, err := LoadPromptTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates failed: %v", err)
+	}
+
+	episode := &cluster.Episode{ID: "E1"}
+	chunks := []rag.ContextChunk{{EpisodeID: "E2", Score: 0.9}}
+	prompt, err := templates.WithStyle(StyleExecutive).EpisodePrompt(episode, chunks)
+	if err != nil || prompt != "Brief on E1. See E2." {
+		t.Errorf("Unexpected executive prompt %q (error %v)", prompt, err)
+	}
+	// The built-in standup template stays, and keeps the built-in shared sections
+	standup, err := templates.WithStyle(StyleStandup).EpisodePrompt(episode, chunks)
+	builtIn, _ := DefaultPromptTemplates().WithStyle(StyleStandup).EpisodePrompt(episode, chunks)
+	if err != nil || standup != builtIn {
+		t.Errorf("Expected the built-in standup prompt, got %q (error %v)", standup, err)
+	}
+
+	// A named template that is defined but never included doesn't count towards the placeholders
+	dir = writeTemplates(t, map[string]string{"episode.tmpl": `{{define "unused"}}{{.Context}}{{end}}{{.Episode.ID}}`})
+	if _, err := LoadPromptTemplates(dir); !errors.Is(err, ErrInvalidTemplate) || !strings.Contains(err.Error(), "{{.Context}}") {
+		t.Errorf("Expected ErrInvalidTemplate for the missing context, got %v", err)
+	}
+}
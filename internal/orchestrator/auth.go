@@ -0,0 +1,163 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/ingest/github"
+)
+
+// Environment variables read by AuthFromEnv
+const (
+	EnvGitToken         = "THUNK_GIT_TOKEN"    // HTTPS access token
+	EnvGitUsername      = "THUNK_GIT_USERNAME" // HTTPS basic auth user
+	EnvGitPassword      = "THUNK_GIT_PASSWORD" // HTTPS basic auth password
+	EnvSSHKey           = "THUNK_SSH_KEY"      // Path to SSH private key
+	EnvSSHKeyPassphrase = "THUNK_SSH_KEY_PASSPHRASE"
+	EnvAppID            = "GITHUB_APP_ID"
+	EnvAppInstallation  = "GITHUB_APP_INSTALLATION_ID"
+	EnvAppPrivateKey    = "GITHUB_APP_PRIVATE_KEY_PATH"
+)
+
+// Environment variables read by SignatureVerifierFromEnv
+const (
+	EnvGPGKeyRing     = "THUNK_GPG_KEYRING"     // Path to an armored public key ring
+	EnvAllowedSigners = "THUNK_ALLOWED_SIGNERS" // Path to an SSH allowed_signers file
+)
+
+// AuthFromEnv builds clone credentials from environment variables
+// If GitHub App variables are set, an installation token is minted and scoped to github.com;
+// otherwise THUNK_GIT_* and THUNK_SSH_* are used. Returns zero options when nothing is set
+func AuthFromEnv(ctx context.Context) (git.AuthOptions, error) {
+	auth := git.AuthOptions{
+		Token:            os.Getenv(EnvGitToken),
+		Username:         os.Getenv(EnvGitUsername),
+		Password:         os.Getenv(EnvGitPassword),
+		SSHKeyPath:       os.Getenv(EnvSSHKey),
+		SSHKeyPassphrase: os.Getenv(EnvSSHKeyPassphrase),
+	}
+
+	appID := os.Getenv(EnvAppID)
+	if appID == "" {
+		return auth, nil
+	}
+
+	token, err := githubAppToken(ctx, appID, os.Getenv(EnvAppInstallation), os.Getenv(EnvAppPrivateKey))
+	if err != nil {
+		return git.AuthOptions{}, err
+	}
+
+	auth.Token = token
+	auth.Username = ""
+	auth.Password = ""
+	auth.Host = "github.com"
+	return auth, nil
+}
+
+// scopeAuth restricts HTTPS credentials to the host a repository is cloned from, unless they are
+// already restricted, e.g. to github.com for a GitHub App
+// Without a known host, e.g. a local repository without an origin remote, HTTPS credentials are
+// dropped, since there is nothing to restrict them to and a local repository needs none
+func scopeAuth(auth git.AuthOptions, repo string) git.AuthOptions {
+	if auth.Host != "" {
+		return auth
+	}
+	auth.Host = repositoryHost(repo)
+	if auth.Host == "" {
+		auth.Token, auth.Username, auth.Password = "", "", ""
+	}
+	return auth
+}
+
+// submoduleAuth returns the credentials to ingest a submodule with: the superproject's credentials,
+// scoped by scopeAuth, when the submodule is on the same host, and none when it is on another, since
+// the submodule URLs in .gitmodules are up to the repository's authors
+func submoduleAuth(auth git.AuthOptions, source string) git.AuthOptions {
+	host := repositoryHost(source)
+	if host == "" || strings.EqualFold(host, auth.Host) {
+		return auth
+	}
+	return git.AuthOptions{}
+}
+
+// repositoryHost returns the hostname a repository is cloned from: the host of its URL, or of the
+// origin remote of a local clone; "" when it has neither
+func repositoryHost(repo string) string {
+	host := remoteHost(repo)
+	if host == "" {
+		gitRepo, err := git.OpenRepository(repo)
+		if err != nil {
+			return ""
+		}
+		host = remoteHost(git.GetRemoteURL(gitRepo, "origin"))
+	}
+	// AuthOptions.Host is compared with the hostname alone
+	hostname, _, _ := strings.Cut(host, ":")
+	return hostname
+}
+
+// githubAppToken mints a GitHub App installation token from environment-provided settings
+func githubAppToken(ctx context.Context, appID, installationID, keyPath string) (string, error) {
+	id, err := strconv.ParseInt(appID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", EnvAppID, err)
+	}
+	installation, err := strconv.ParseInt(installationID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", EnvAppInstallation, err)
+	}
+	if keyPath == "" {
+		return "", fmt.Errorf("%s is required when %s is set", EnvAppPrivateKey, EnvAppID)
+	}
+
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+
+	token, err := github.CreateInstallationToken(ctx, id, installation, key)
+	if err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
+
+// SignatureVerifierFromEnv builds a commit signature verifier from trusted key files in the environment
+// Returns nil if neither THUNK_GPG_KEYRING nor THUNK_ALLOWED_SIGNERS is set
+func SignatureVerifierFromEnv() (*git.SignatureVerifier, error) {
+	keyRingPath := os.Getenv(EnvGPGKeyRing)
+	signersPath := os.Getenv(EnvAllowedSigners)
+	if keyRingPath == "" && signersPath == "" {
+		return nil, nil
+	}
+
+	verifier := git.NewSignatureVerifier()
+
+	if keyRingPath != "" {
+		file, err := os.Open(keyRingPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", EnvGPGKeyRing, err)
+		}
+		defer file.Close()
+		if err := verifier.AddPGPKeyRing(file); err != nil {
+			return nil, err
+		}
+	}
+
+	if signersPath != "" {
+		file, err := os.Open(signersPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", EnvAllowedSigners, err)
+		}
+		defer file.Close()
+		if err := verifier.AddAllowedSigners(file); err != nil {
+			return nil, err
+		}
+	}
+
+	return verifier, nil
+}
@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+)
+
+func TestAuthFromEnv(t *testing.T) {
+	t.Setenv(EnvAppID, "")
+	t.Setenv(EnvGitToken, "secret")
+	t.Setenv(EnvSSHKey, "/home/alice/.ssh/id_ed25519")
+
+	auth, err := AuthFromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if auth.Token != "secret" || auth.SSHKeyPath != "/home/alice/.ssh/id_ed25519" {
+		t.Errorf("Unexpected auth options: %+v", auth)
+	}
+
+	// GitHub App settings are validated before any network call
+	t.Setenv(EnvAppID, "not-a-number")
+	if _, err := AuthFromEnv(context.Background()); err == nil {
+		t.Error("Expected error for invalid app ID")
+	}
+
+	t.Setenv(EnvAppID, "123")
+	t.Setenv(EnvAppInstallation, "456")
+	t.Setenv(EnvAppPrivateKey, "")
+	if _, err := AuthFromEnv(context.Background()); err == nil {
+		t.Error("Expected error for missing private key path")
+	}
+}
+
+func TestScopeAuth(t *testing.T) {
+	auth := git.AuthOptions{Token: "secret", SSHKeyPath: "/home/alice/.ssh/id_ed25519"}
+
+	scoped := scopeAuth(auth, "https://git.example.com:8443/team/app.git")
+	if scoped.Host != "git.example.com" || scoped.Token != "secret" {
+		t.Errorf("Expected the token scoped to git.example.com, got %+v", scoped)
+	}
+	if scoped := scopeAuth(auth, "git@github.com:acme/app.git"); scoped.Host != "github.com" {
+		t.Errorf("Expected the token scoped to github.com, got %+v", scoped)
+	}
+
+	// A local repository's host is that of its origin remote
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if scoped := scopeAuth(auth, dir); scoped.Host != "" || scoped.Token != "" || scoped.SSHKeyPath == "" {
+		t.Errorf("Expected HTTPS credentials dropped without a host, got %+v", scoped)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://gitlab.example.com/team/app.git"}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	if scoped := scopeAuth(auth, dir); scoped.Host != "gitlab.example.com" || scoped.Token != "secret" {
+		t.Errorf("Expected the token scoped to the origin's host, got %+v", scoped)
+	}
+
+	// Credentials that are already scoped, e.g. a GitHub App's, keep their host
+	app := git.AuthOptions{Token: "installation", Host: "github.com"}
+	if scoped := scopeAuth(app, "https://gitlab.com/team/app.git"); scoped != app {
+		t.Errorf("Expected scoped credentials unchanged, got %+v", scoped)
+	}
+}
+
+func TestSubmoduleAuth(t *testing.T) {
+	auth := git.AuthOptions{Token: "secret", Host: "github.com"}
+
+	if got := submoduleAuth(auth, "https://github.com/acme/lib.git"); got != auth {
+		t.Errorf("Expected the superproject's credentials on its host, got %+v", got)
+	}
+	if got := submoduleAuth(auth, "https://gitlab.attacker.example/lib.git"); !got.IsZero() {
+		t.Errorf("Expected no credentials for a submodule on another host, got %+v", got)
+	}
+	if got := submoduleAuth(git.AuthOptions{SSHKeyPath: "/key"}, "git@example.com:lib.git"); !got.IsZero() {
+		t.Errorf("Expected no credentials for a remote submodule of a superproject without a host, got %+v", got)
+	}
+}
+
+func TestSignatureVerifierFromEnv(t *testing.T) {
+	t.Setenv(EnvGPGKeyRing, "")
+	t.Setenv(EnvAllowedSigners, "")
+
+	verifier, err := SignatureVerifierFromEnv()
+	if err != nil || verifier != nil {
+		t.Errorf("Expected no verifier without configuration, got %v, %v", verifier, err)
+	}
+
+	t.Setenv(EnvAllowedSigners, filepath.Join(t.TempDir(), "missing"))
+	if _, err := SignatureVerifierFromEnv(); err == nil {
+		t.Error("Expected error for missing allowed signers file")
+	}
+
+	signers := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(signers, []byte("# no signers yet\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write allowed signers: %v", err)
+	}
+	t.Setenv(EnvAllowedSigners, signers)
+	if verifier, err := SignatureVerifierFromEnv(); err != nil || verifier == nil {
+		t.Errorf("Expected verifier, got %v, %v", verifier, err)
+	}
+}
@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// CacheStats reports how often this pipeline found narratives in its cache, missed them and
+// cached new ones; all zero without a cache.
+func (p *RAGPipeline) CacheStats() narrative.CacheStats {
+	return p.cache.Stats()
+}
+
+// cachedNarrative returns the narrative cached for content written with the templates of types, or
+// generates and caches it; ForceRegenerate skips the lookup, replacing the cached narrative
+func (p *RAGPipeline) cachedNarrative(ctx context.Context, id, content string, types []narrative.PromptType, generate func() (*narrative.Narrative, error)) (*narrative.Narrative, error) {
+	if p.cache == nil {
+		narr, err := generate()
+		if err != nil {
+			return nil, err
+		}
+		if err := narrativeGenerated(ctx, narr); err != nil {
+			return nil, err
+		}
+		return narr, nil
+	}
+
+	key, err := p.cacheKey(content, types)
+	if err != nil {
+		return nil, err
+	}
+	if !p.config.ForceRegenerate {
+		if narr, ok := p.cache.Get(key); ok {
+			p.logger(ctx).Debug("Using cached narrative", "narrative", id)
+			if err := narrativeGenerated(ctx, narr); err != nil {
+				return nil, err
+			}
+			return narr, nil
+		}
+	}
+
+	narr, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	if err := narrativeGenerated(ctx, narr); err != nil {
+		return nil, err
+	}
+	// Narratives a fallback model wrote aren't kept, so the configured model gets another chance next run
+	if narr.Model != p.config.LLMConfig.Model {
+		return narr, nil
+	}
+	// A failed write only costs a regeneration next time
+	if err := p.cache.Put(key, narr); err != nil {
+		p.logger(ctx).Warn("Failed to cache narrative", "narrative", id, "error", err)
+		report.FromContext(ctx).Fail(report.StageCache, id, err)
+	}
+	return narr, nil
+}
+
+// cacheKey identifies a narrative by its content, the versions of the templates it is written with,
+// and the models and style writing it
+func (p *RAGPipeline) cacheKey(content string, types []narrative.PromptType) (narrative.CacheKey, error) {
+	versions := make([]string, len(types))
+	for i, typ := range types {
+		version, err := p.prompts.Version(typ)
+		if err != nil {
+			return narrative.CacheKey{}, fmt.Errorf("failed to version %s template: %w", typ, err)
+		}
+		versions[i] = string(typ) + "@" + version
+	}
+	model := p.config.LLMConfig.Model
+	if slices.Contains(types, narrative.PromptSummary) {
+		model += "+" + summaryLLMConfig(p.config).Model
+	}
+	return narrative.CacheKey{
+		Content:  content,
+		Template: strings.Join(versions, ","),
+		Model:    model,
+		Style:    p.config.LLMConfig.Style,
+	}, nil
+}
+
+// episodeCacheEntry returns what identifies an episode's narrative in the cache: the hash of its
+// content and the prompt types it is written with
+func (p *RAGPipeline) episodeCacheEntry(episode *cluster.Episode) (string, []narrative.PromptType) {
+	types := []narrative.PromptType{narrative.PromptEpisode}
+	if p.summarizes(episode) {
+		types = []narrative.PromptType{narrative.PromptSummary, narrative.PromptCombine}
+	}
+	parts := []string{"episode", p.repositoryOf(episode), episode.ID, episodeFingerprint(episode)}
+	if diffs := p.config.Diffs; diffs.MaxFiles > 0 {
+		// Narratives written without diffs are not reused once diffs are included, nor the reverse
+		parts = append(parts, fmt.Sprintf("diffs:%d:%d", diffs.MaxFiles, diffs.MaxTokens))
+	}
+	for _, exemplar := range p.config.Exemplars {
+		parts = append(parts, "exemplar", string(exemplar.Style), exemplar.Episode, exemplar.Narrative)
+	}
+	return contentHash(parts...), types
+}
+
+// CachedExemplars returns exemplars of the narratives cached for episodes, such as those a team
+// has reviewed and approved, to pass to WithExemplars; episodes without a cached narrative are skipped.
+func (p *RAGPipeline) CachedExemplars(episodes []cluster.Episode) ([]narrative.Exemplar, error) {
+	var exemplars []narrative.Exemplar
+	for i := range episodes {
+		content, types := p.episodeCacheEntry(&episodes[i])
+		key, err := p.cacheKey(content, types)
+		if err != nil {
+			return nil, err
+		}
+		if narr, ok := p.cache.Get(key); ok {
+			exemplars = append(exemplars, narrative.NewExemplar(&episodes[i], narr))
+		}
+	}
+	return exemplars, nil
+}
+
+// contentHash hashes the parts identifying the content of a narrative
+func contentHash(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
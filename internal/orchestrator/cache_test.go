@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+func TestGenerateEpisodeNarrativeRAG_Cached(t *testing.T) {
+	ctx := context.Background()
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	embedded, _ := embedder.Embed(ctx, []string{"Tune retries", "Add retries"})
+	records := []rag.EpisodeRecord{
+		{EpisodeID: "E1", Text: "Tune retries", Embedding: embedded[0].Embedding},
+		{EpisodeID: "E0", Text: "Add retries", Embedding: embedded[1].Embedding},
+	}
+	if err := store.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	retriever, _ := rag.NewRetriever(embedder, store)
+	cache, err := narrative.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	llm := &recordingLLM{}
+	config := RAGConfig{TopK: 3, LLMConfig: narrative.LLMConfig{Model: "gpt-4o"}}
+	pipeline := &RAGPipeline{
+		config:    config,
+		retriever: retriever,
+		llm:       llm,
+		generator: narrative.NewGenerator(llm, config.LLMConfig),
+		prompts:   narrative.DefaultPromptTemplates(),
+		cache:     cache,
+	}
+
+	episode := summaryTestEpisode(3)
+	for range 2 {
+		narr, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode)
+		if err != nil {
+			t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+		}
+		// The provenance is kept with the cached narrative
+		if p := narr.Provenance; p == nil || p.Prompt != narrative.PromptEpisode || len(p.Context) != 1 || p.Context[0].EpisodeID != "E0" {
+			t.Errorf("Expected the episode prompt's provenance with the retrieved chunk, got %+v", p)
+		}
+	}
+	if len(llm.prompts) != 1 {
+		t.Errorf("Expected the second run to use the cached narrative, got %d LLM calls", len(llm.prompts))
+	}
+	if stats := pipeline.CacheStats(); stats != (narrative.CacheStats{Hits: 1, Misses: 1, Writes: 1}) {
+		t.Errorf("Unexpected cache statistics %+v", stats)
+	}
+
+	// New commits, another style and forced regeneration each call the LLM again
+	episode.Commits = append(episode.Commits, git.Commit{Hash: "c000099", Message: "Cap retries", CommittedAt: episode.Commits[2].CommittedAt})
+	if _, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode); err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	if _, err := pipeline.WithStyle(narrative.StyleExecutive).GenerateEpisodeNarrativeRAG(ctx, episode); err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	forced := *pipeline
+	forced.config.ForceRegenerate = true
+	if _, err := forced.GenerateEpisodeNarrativeRAG(ctx, episode); err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	if len(llm.prompts) != 4 {
+		t.Errorf("Expected changed content, style and forced runs to regenerate, got %d LLM calls", len(llm.prompts))
+	}
+	if _, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode); err != nil || len(llm.prompts) != 4 {
+		t.Errorf("Expected the forced narrative to be cached, got %d LLM calls (error %v)", len(llm.prompts), err)
+	}
+}
+
+func TestRAGPipeline_CachedExemplars(t *testing.T) {
+	ctx := context.Background()
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	embedded, _ := embedder.Embed(ctx, []string{"Tune retries"})
+	if err := store.Insert(ctx, []rag.EpisodeRecord{{EpisodeID: "E1", Text: "Tune retries", Embedding: embedded[0].Embedding}}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	retriever, _ := rag.NewRetriever(embedder, store)
+	cache, err := narrative.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	llm := &recordingLLM{}
+	config := RAGConfig{TopK: 3, LLMConfig: narrative.LLMConfig{Model: "gpt-4o"}}
+	pipeline := &RAGPipeline{
+		config:    config,
+		retriever: retriever,
+		llm:       llm,
+		generator: narrative.NewGenerator(llm, config.LLMConfig),
+		prompts:   narrative.DefaultPromptTemplates(),
+		cache:     cache,
+	}
+
+	episode := summaryTestEpisode(3)
+	if exemplars, err := pipeline.CachedExemplars([]cluster.Episode{*episode}); err != nil || len(exemplars) != 0 {
+		t.Fatalf("Expected no exemplars before generating, got %+v (error %v)", exemplars, err)
+	}
+	narr, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode)
+	if err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	exemplars, err := pipeline.CachedExemplars([]cluster.Episode{*episode})
+	if err != nil || len(exemplars) != 1 || exemplars[0].Narrative != narr.Text {
+		t.Fatalf("Expected the cached narrative as an exemplar, got %+v (error %v)", exemplars, err)
+	}
+
+	// The exemplar is shown in the prompt, and narratives cached without it are written again
+	if _, err := pipeline.WithExemplars(exemplars).GenerateEpisodeNarrativeRAG(ctx, episode); err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	if len(llm.prompts) != 2 || !strings.Contains(llm.prompts[1], "# Example Narratives") || !strings.Contains(llm.prompts[1], exemplars[0].Episode) {
+		t.Errorf("Expected a second prompt showing the exemplar, got %d prompts", len(llm.prompts))
+	}
+}
@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/report"
+	"github.com/Yates-Labs/thunk/internal/state"
+)
+
+// Run is a long pipeline run over a repository, checkpointed in a state store after each stage
+// (ingested, clustered, indexed) and after each narrative, so a run that crashed or was aborted,
+// e.g. by rate limits, resumes where it stopped instead of starting over
+type Run struct {
+	store      state.Store
+	checkpoint state.Checkpoint
+	narratives map[string]*narrative.Narrative
+}
+
+// StartRun starts a checkpointed run over repo, identified by key, e.g. a hash of the command and
+// settings whose results the run produces
+// With resume, the run continues from the checkpoint of an earlier run with the same key; without
+// it, or when the checkpoint is of another run, the checkpoint is dropped and the run starts over
+func StartRun(ctx context.Context, store state.Store, repo, key string, resume bool) (*Run, error) {
+	repository := RepositoryKey(repo)
+	run := &Run{
+		store:      store,
+		checkpoint: state.Checkpoint{Repository: repository, RunKey: key},
+		narratives: make(map[string]*narrative.Narrative),
+	}
+	log := logging.FromContext(ctx).With(logging.RepositoryKey, repo)
+
+	checkpoint, err := store.LoadCheckpoint(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil && resume && checkpoint.RunKey == key {
+		run.checkpoint = *checkpoint
+		for id, data := range checkpoint.Narratives {
+			var narr narrative.Narrative
+			if err := json.Unmarshal(data, &narr); err != nil {
+				log.Warn("Ignoring unreadable checkpointed narrative", logging.EpisodeKey, id, "error", err)
+				continue
+			}
+			run.narratives[id] = &narr
+		}
+		run.checkpoint.Narratives = nil
+		log.Info("Resuming run", "stage", checkpoint.Stage, "narratives", len(run.narratives), "checkpointed", checkpoint.UpdatedAt)
+		return run, nil
+	}
+
+	if checkpoint != nil {
+		if resume {
+			log.Warn("Checkpoint is of a different run, starting over", "stage", checkpoint.Stage)
+		}
+		if err := store.ClearCheckpoint(ctx, repository); err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+// Stage returns the last stage the run completed
+func (r *Run) Stage() state.Stage {
+	return r.checkpoint.Stage
+}
+
+// Analyze analyzes the repository like AnalyzeRepositoryWithConfig, checkpointing the ingested
+// activity and then the episodes; a resumed run reuses whichever it reached
+// A run that ingests the repository itself also records the analysis in the repository's state
+func (r *Run) Analyze(ctx context.Context, repo string, config cluster.GroupingConfig, token ...string) ([]cluster.Episode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled before analysis: %w", err)
+	}
+	ctx = logging.With(logging.WithRun(ctx), logging.RepositoryKey, repo)
+	log := logging.FromContext(ctx)
+
+	if r.checkpoint.Stage.Reached(state.StageClustered) {
+		episodes, err := cluster.UnmarshalEpisodes(r.checkpoint.Episodes)
+		if err == nil {
+			log.Info("Reusing checkpointed episodes", "episodes", len(episodes))
+			return episodes, nil
+		}
+		log.Warn("Ignoring unreadable checkpointed episodes", "error", err)
+		r.checkpoint.Stage = state.StageStarted
+	}
+
+	var activity *cluster.RepositoryActivity
+	var repoData *git.Repository
+	if r.checkpoint.Stage.Reached(state.StageIngested) && len(r.checkpoint.Activity) > 0 {
+		activity = &cluster.RepositoryActivity{}
+		if err := json.Unmarshal(r.checkpoint.Activity, activity); err != nil {
+			log.Warn("Ignoring unreadable checkpointed activity", "error", err)
+			activity = nil
+		} else {
+			log.Info("Reusing checkpointed activity", "commits", len(activity.Commits), "artifacts", len(activity.Artifacts))
+		}
+	}
+
+	if activity == nil {
+		auth, err := AuthFromEnv(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load git credentials: %w", err)
+		}
+		activity, repoData, err = ingestRepository(ctx, repo, ingestOptions{token: passedToken(token), envTokens: true, allBranches: config.AllBranches, auth: auth})
+		if err != nil {
+			return nil, reportError(ctx, fmt.Errorf("failed to ingest repository: %w", err))
+		}
+		if data, err := json.Marshal(activity); err != nil {
+			log.Warn("Failed to encode activity for the checkpoint", "error", err)
+		} else {
+			r.checkpoint.Activity = data
+			r.save(ctx, state.StageIngested)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled after ingestion: %w", err)
+	}
+
+	episodes := groupEpisodes(ctx, activity, config)
+	if err := episodesCreated(ctx, episodes); err != nil {
+		return nil, reportError(ctx, err)
+	}
+	if repoData != nil {
+		r.recordAnalysis(ctx, activity, repoData, episodes)
+	}
+
+	data, err := cluster.MarshalEpisodes(episodes)
+	if err != nil {
+		log.Warn("Failed to encode episodes for the checkpoint", "error", err)
+		return episodes, nil
+	}
+	// The episodes are all a resumed run needs, so the activity needn't be kept
+	r.checkpoint.Activity, r.checkpoint.Episodes = nil, data
+	r.save(ctx, state.StageClustered)
+	return episodes, nil
+}
+
+// recordAnalysis records the analysis in the repository's state, as AnalyzeRepositoryWithState
+// does, so a later incremental analysis only fetches the activity added after it
+// The run's results don't depend on it, so a failure is only reported
+func (r *Run) recordAnalysis(ctx context.Context, activity *cluster.RepositoryActivity, repoData *git.Repository, episodes []cluster.Episode) {
+	repoState, err := r.store.Load(ctx, r.checkpoint.Repository)
+	if err == nil {
+		recordAnalysis(repoState, activity, repoData, episodes)
+		err = r.store.Save(ctx, repoState)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to record repository state", "error", err)
+		report.FromContext(ctx).Fail(report.StageCheckpoints, "repository state", err)
+	}
+}
+
+// Index indexes the episodes with the pipeline, unless the run already did
+func (r *Run) Index(ctx context.Context, pipeline *RAGPipeline, episodes []cluster.Episode) error {
+	if r.checkpoint.Stage.Reached(state.StageIndexed) {
+		logging.FromContext(ctx).Info("Episodes already indexed by the resumed run", "episodes", len(episodes))
+		return nil
+	}
+	if err := pipeline.IndexEpisodes(ctx, episodes); err != nil {
+		return err
+	}
+	r.save(ctx, state.StageIndexed)
+	return nil
+}
+
+// Narrative generates the narrative of an episode with the pipeline and checkpoints it, or returns
+// the narrative the run already generated
+func (r *Run) Narrative(ctx context.Context, pipeline *RAGPipeline, episode *cluster.Episode) (*narrative.Narrative, error) {
+	if narr, ok := r.narratives[episode.ID]; ok {
+		return narr, nil
+	}
+
+	narr, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode)
+	if err != nil {
+		return nil, err
+	}
+	r.narratives[episode.ID] = narr
+
+	data, err := json.Marshal(narr)
+	if err == nil {
+		err = r.store.RecordNarrative(ctx, r.checkpoint.Repository, episode.ID, data)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to checkpoint narrative", logging.EpisodeKey, episode.ID, "error", err)
+		report.FromContext(ctx).Fail(report.StageCheckpoints, episode.ID, err)
+	}
+	return narr, nil
+}
+
+// Finish drops the run's checkpoint once the run has completed
+func (r *Run) Finish(ctx context.Context) error {
+	return r.store.ClearCheckpoint(ctx, r.checkpoint.Repository)
+}
+
+// save checkpoints the run at stage
+// A failed save only costs the work of the stage if the run is resumed, so the run carries on
+func (r *Run) save(ctx context.Context, stage state.Stage) {
+	r.checkpoint.Stage = stage
+	r.checkpoint.UpdatedAt = time.Now()
+	if err := r.store.SaveCheckpoint(ctx, &r.checkpoint); err != nil {
+		logging.FromContext(ctx).Warn("Failed to save checkpoint", "stage", stage, "error", err)
+		report.FromContext(ctx).Fail(report.StageCheckpoints, string(stage), err)
+		return
+	}
+	logging.FromContext(ctx).Debug("Saved checkpoint", "stage", stage)
+}
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/state"
+	gogit "github.com/go-git/go-git/v6"
+)
+
+func TestRun_Resume(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	store := state.NewMemoryStore()
+	run, err := StartRun(ctx, store, dir, "k1", false)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	episodes, err := run.Analyze(ctx, dir, cluster.DefaultGroupingConfig())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(episodes) != 1 || run.Stage() != state.StageClustered {
+		t.Fatalf("Expected 1 episode checkpointed as clustered, got %d at %q", len(episodes), run.Stage())
+	}
+	// The analysis is recorded, so an incremental analysis carries on from it
+	if repoState, _ := store.Load(ctx, RepositoryKey(dir)); repoState.LastCommitHash == "" || repoState.EpisodeVersion != 1 {
+		t.Errorf("Expected the analysis recorded in the repository state, got %+v", repoState)
+	}
+	if later, err := AnalyzeRepositoryWithState(ctx, dir, store, cluster.DefaultGroupingConfig()); err != nil || len(later) != 0 {
+		t.Errorf("Expected no new episodes after the run's analysis, got %d (error %v)", len(later), err)
+	}
+
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	vectors, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	llm := &recordingLLM{}
+	config := DefaultRAGConfig()
+	config.EmbedderDimension = 64
+	config.NarrativeCacheDir = ""
+	pipeline, err := NewRAGPipeline(ctx, config, WithEmbedder(embedder), WithVectorStore(vectors), WithLLM(llm))
+	if err != nil {
+		t.Fatalf("NewRAGPipeline failed: %v", err)
+	}
+	defer pipeline.Close()
+	if err := run.Index(ctx, pipeline, episodes); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if _, err := run.Narrative(ctx, pipeline, &episodes[0]); err != nil {
+		t.Fatalf("Narrative failed: %v", err)
+	}
+
+	// The run is aborted here; later commits aren't part of the run being resumed
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	resumed, err := StartRun(ctx, store, dir, "k1", true)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if resumed.Stage() != state.StageIndexed {
+		t.Fatalf("Expected the run resumed after indexing, got %q", resumed.Stage())
+	}
+	again, err := resumed.Analyze(ctx, dir, cluster.DefaultGroupingConfig())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(again) != 1 || len(again[0].Commits) != 1 || again[0].ID != episodes[0].ID {
+		t.Errorf("Expected the checkpointed episode reused, got %+v", again)
+	}
+	if err := resumed.Index(ctx, pipeline, again); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	narr, err := resumed.Narrative(ctx, pipeline, &again[0])
+	if err != nil {
+		t.Fatalf("Narrative failed: %v", err)
+	}
+	if narr.Text != "Summary." || len(llm.prompts) != 1 {
+		t.Errorf("Expected the checkpointed narrative without another LLM call, got %q after %d calls", narr.Text, len(llm.prompts))
+	}
+
+	// A different run starts over, and a finished run leaves nothing to resume
+	other, err := StartRun(ctx, store, dir, "k2", true)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if other.Stage() != state.StageStarted {
+		t.Errorf("Expected a different run to start over, got %q", other.Stage())
+	}
+	if episodes, err := other.Analyze(ctx, dir, cluster.DefaultGroupingConfig()); err != nil || len(episodes) == 0 {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if err := other.Finish(ctx); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	if checkpoint, _ := store.LoadCheckpoint(ctx, RepositoryKey(dir)); checkpoint != nil {
+		t.Errorf("Expected the checkpoint cleared, got stage %q", checkpoint.Stage)
+	}
+}
+
+func TestRun_ResumeFromActivity(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	// A run that crashed while clustering left only its activity
+	store := state.NewMemoryStore()
+	run, err := StartRun(ctx, store, dir, "k1", false)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if _, err := run.Analyze(ctx, dir, cluster.DefaultGroupingConfig()); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	checkpoint, _ := store.LoadCheckpoint(ctx, RepositoryKey(dir))
+	activity, _, err := ingestRepository(ctx, dir, ingestOptions{envTokens: true})
+	if err != nil {
+		t.Fatalf("ingestRepository failed: %v", err)
+	}
+	checkpoint.Stage, checkpoint.Episodes = state.StageIngested, nil
+	checkpoint.Activity, _ = json.Marshal(activity)
+	store.SaveCheckpoint(ctx, checkpoint)
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	resumed, err := StartRun(ctx, store, dir, "k1", true)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	episodes, err := resumed.Analyze(ctx, dir, cluster.DefaultGroupingConfig())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	commits := 0
+	for _, episode := range episodes {
+		commits += len(episode.Commits)
+	}
+	if commits != 1 || resumed.Stage() != state.StageClustered {
+		t.Errorf("Expected the checkpointed activity's 1 commit grouped, got %d at %q", commits, resumed.Stage())
+	}
+}
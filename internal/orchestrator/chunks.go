@@ -0,0 +1,189 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+const (
+	defaultChunkCommits = 5  // Commits per chunk record when the config leaves it unset
+	chunkFileLimit      = 20 // Files listed per commit in commit and chunk text
+)
+
+// episodeChunks splits an episode into the commit and chunk records indexed alongside its summary,
+// for whichever of those granularities the pipeline retrieves
+// Commits shared with other episodes are left to the episode they belong to
+func (p *RAGPipeline) episodeChunks(ep *cluster.Episode) []rag.Chunk {
+	commitLevel := p.retrieves(rag.GranularityCommit)
+	chunkLevel := p.retrieves(rag.GranularityChunk)
+	if !commitLevel && !chunkLevel {
+		return nil
+	}
+
+	commits := ep.GetPrimaryCommits()
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].CommittedAt.Before(commits[j].CommittedAt)
+	})
+
+	var chunks []rag.Chunk
+	if commitLevel {
+		for i := range commits {
+			chunks = append(chunks, commitChunk(ep, &commits[i]))
+		}
+	}
+	if chunkLevel {
+		chunks = append(chunks, windowChunks(ep, commits, p.config.ChunkCommits, p.config.ChunkOverlap)...)
+	}
+	return chunks
+}
+
+// retrieves reports whether project queries retrieve records of the given granularity
+func (p *RAGPipeline) retrieves(granularity rag.Granularity) bool {
+	for _, g := range p.config.Granularities {
+		if g == granularity {
+			return true
+		}
+	}
+	return false
+}
+
+// commitChunk describes a single commit: its message, author and the files it changed
+func commitChunk(ep *cluster.Episode, commit *git.Commit) rag.Chunk {
+	var b strings.Builder
+	if ep.Repository != "" {
+		b.WriteString(fmt.Sprintf("Repository: %s\n", ep.Repository))
+	}
+	b.WriteString(fmt.Sprintf("Commit %s by %s on %s\n", shortHash(commit), commit.Author.Name, commit.CommittedAt.Format("2006-01-02")))
+	b.WriteString(fmt.Sprintf("Episode: %s\n\n", generateEpisodeTitle(ep)))
+	b.WriteString(strings.TrimSpace(commit.Message) + "\n")
+	writeChangedFiles(&b, commit, "\nFiles changed:\n", "- ")
+
+	return rag.Chunk{
+		ID:          "commit:" + commit.Hash,
+		Granularity: rag.GranularityCommit,
+		Text:        b.String(),
+		StartDate:   commit.CommittedAt,
+		EndDate:     commit.CommittedAt,
+		Authors:     []string{commit.Author.Name},
+		Paths:       changedPaths([]git.Commit{*commit}),
+		CommitCount: 1,
+		FileCount:   len(commit.Diffs),
+	}
+}
+
+// windowChunks groups consecutive commits into chunks of size commits, each sharing overlap commits
+// with the one before so a change that spans a boundary is still described whole
+// Episodes no longer than one chunk are covered by their summary and get none
+func windowChunks(ep *cluster.Episode, commits []git.Commit, size, overlap int) []rag.Chunk {
+	if size <= 0 {
+		size = defaultChunkCommits
+	}
+	if len(commits) <= size {
+		return nil
+	}
+	step := size - max(overlap, 0)
+	if step < 1 {
+		step = 1
+	}
+
+	var chunks []rag.Chunk
+	for start := 0; ; start += step {
+		end := min(start+size, len(commits))
+		window := commits[start:end]
+
+		var b strings.Builder
+		if ep.Repository != "" {
+			b.WriteString(fmt.Sprintf("Repository: %s\n", ep.Repository))
+		}
+		b.WriteString(fmt.Sprintf("Commits %d-%d of %d in episode: %s\n\n", start+1, end, len(commits), generateEpisodeTitle(ep)))
+
+		authors := make([]string, 0, len(window))
+		seenAuthors := make(map[string]bool)
+		files := make(map[string]bool)
+		for i := range window {
+			commit := &window[i]
+			b.WriteString(fmt.Sprintf("- %s %s (by %s, %s)\n", shortHash(commit), commitSubject(commit), commit.Author.Name, commit.CommittedAt.Format("2006-01-02")))
+			writeChangedFiles(&b, commit, "", "    ")
+			if !seenAuthors[commit.Author.Name] {
+				seenAuthors[commit.Author.Name] = true
+				authors = append(authors, commit.Author.Name)
+			}
+			for _, diff := range commit.Diffs {
+				files[diff.FilePath] = true
+			}
+		}
+
+		chunks = append(chunks, rag.Chunk{
+			ID:          fmt.Sprintf("chunk:%d", len(chunks)+1),
+			Granularity: rag.GranularityChunk,
+			Text:        b.String(),
+			StartDate:   window[0].CommittedAt,
+			EndDate:     window[len(window)-1].CommittedAt,
+			Authors:     authors,
+			Paths:       changedPaths(window),
+			CommitCount: len(window),
+			FileCount:   len(files),
+		})
+		if end == len(commits) {
+			return chunks
+		}
+	}
+}
+
+// writeChangedFiles lists up to chunkFileLimit of a commit's files with their line counts
+func writeChangedFiles(b *strings.Builder, commit *git.Commit, header, indent string) {
+	if len(commit.Diffs) == 0 {
+		return
+	}
+	b.WriteString(header)
+	for i, diff := range commit.Diffs {
+		if i >= chunkFileLimit {
+			b.WriteString(fmt.Sprintf("%s... and %d more files\n", indent, len(commit.Diffs)-chunkFileLimit))
+			break
+		}
+		b.WriteString(fmt.Sprintf("%s%s (+%d/-%d)\n", indent, diff.FilePath, diff.Additions, diff.Deletions))
+	}
+}
+
+// changedPaths returns the files commits touched, renamed files under both names, sorted
+func changedPaths(commits []git.Commit) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, commit := range commits {
+		for _, diff := range commit.Diffs {
+			for _, p := range []string{diff.FilePath, diff.OldPath} {
+				if p != "" && !seen[p] {
+					seen[p] = true
+					paths = append(paths, p)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// commitSubject returns the first line of a commit's message
+func commitSubject(commit *git.Commit) string {
+	if commit.MessageSubject != "" {
+		return commit.MessageSubject
+	}
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	return subject
+}
+
+// shortHash returns a commit's abbreviated hash
+func shortHash(commit *git.Commit) string {
+	if commit.ShortHash != "" {
+		return commit.ShortHash
+	}
+	if len(commit.Hash) > 8 {
+		return commit.Hash[:8]
+	}
+	return commit.Hash
+}
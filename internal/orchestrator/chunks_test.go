@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+func chunkTestEpisode(commits int) cluster.Episode {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	ep := cluster.Episode{ID: "E1", Repository: "owner/app"}
+	// Newest first, as the episode's commit order shouldn't matter
+	for i := commits - 1; i >= 0; i-- {
+		ep.Commits = append(ep.Commits, git.Commit{
+			Hash:        fmt.Sprintf("c%d", i+1),
+			Message:     fmt.Sprintf("Change %d\n\nDetails", i+1),
+			Author:      git.Author{Name: fmt.Sprintf("dev%d", i%2)},
+			CommittedAt: base.Add(time.Duration(i) * time.Hour),
+			Diffs:       []git.Diff{{FilePath: fmt.Sprintf("pkg/file%d.go", i%3), Additions: 3, Deletions: 1}},
+		})
+	}
+	return ep
+}
+
+func TestWindowChunks_Overlap(t *testing.T) {
+	ep := chunkTestEpisode(8)
+	pipeline := &RAGPipeline{config: RAGConfig{Granularities: []rag.Granularity{rag.GranularityChunk}, ChunkCommits: 4, ChunkOverlap: 1}}
+
+	chunks := pipeline.episodeChunks(&ep)
+
+	// Windows of 4 stepping by 3: commits 1-4, 4-7 and 7-8
+	wantRanges := []string{"Commits 1-4 of 8", "Commits 4-7 of 8", "Commits 7-8 of 8"}
+	if len(chunks) != len(wantRanges) {
+		t.Fatalf("Expected %d chunks, got %d", len(wantRanges), len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.ID != fmt.Sprintf("chunk:%d", i+1) || chunk.Granularity != rag.GranularityChunk {
+			t.Errorf("Chunk %d: unexpected ID %q or granularity %q", i, chunk.ID, chunk.Granularity)
+		}
+		if !strings.Contains(chunk.Text, wantRanges[i]) || !strings.Contains(chunk.Text, "Repository: owner/app") {
+			t.Errorf("Chunk %d: expected %q, got:\n%s", i, wantRanges[i], chunk.Text)
+		}
+	}
+	if !strings.Contains(chunks[0].Text, "Change 4") || !strings.Contains(chunks[1].Text, "Change 4") {
+		t.Error("Expected consecutive chunks to share their boundary commit")
+	}
+	if chunks[0].CommitCount != 4 || chunks[2].CommitCount != 2 || chunks[0].FileCount != 3 {
+		t.Errorf("Unexpected counts: %+v", chunks[0])
+	}
+	if !chunks[0].StartDate.Before(chunks[0].EndDate) || len(chunks[0].Authors) != 2 {
+		t.Errorf("Unexpected dates or authors: %+v", chunks[0])
+	}
+
+	// An episode that fits in one chunk is covered by its summary
+	small := chunkTestEpisode(3)
+	if chunks := pipeline.episodeChunks(&small); len(chunks) != 0 {
+		t.Errorf("Expected no chunks for a 3-commit episode, got %d", len(chunks))
+	}
+}
+
+func TestEpisodeChunks_Commits(t *testing.T) {
+	ep := chunkTestEpisode(2)
+	ep.Memberships = map[string]float64{"c2": 0.6}
+
+	if chunks := (&RAGPipeline{}).episodeChunks(&ep); chunks != nil {
+		t.Errorf("Expected no chunks when only episodes are retrieved, got %d", len(chunks))
+	}
+
+	pipeline := &RAGPipeline{config: RAGConfig{Granularities: []rag.Granularity{rag.GranularityEpisode, rag.GranularityCommit}}}
+	chunks := pipeline.episodeChunks(&ep)
+	if len(chunks) != 1 {
+		t.Fatalf("Expected one commit chunk, skipping the shared commit, got %d", len(chunks))
+	}
+	chunk := chunks[0]
+	if chunk.ID != "commit:c1" || chunk.Granularity != rag.GranularityCommit || chunk.CommitCount != 1 || chunk.Authors[0] != "dev0" ||
+		len(chunk.Paths) != 1 || chunk.Paths[0] != "pkg/file0.go" {
+		t.Errorf("Unexpected commit chunk: %+v", chunk)
+	}
+	for _, want := range []string{"Commit c1 by dev0 on 2024-03-01", "Change 1\n\nDetails", "- pkg/file0.go (+3/-1)"} {
+		if !strings.Contains(chunk.Text, want) {
+			t.Errorf("Expected commit text to contain %q, got:\n%s", want, chunk.Text)
+		}
+	}
+}
+
+func TestAssembleProjectQueryPrompt_Granularity(t *testing.T) {
+	chunks := []rag.ContextChunk{
+		{EpisodeID: "E1", ChunkID: "commit:c1", Granularity: rag.GranularityCommit, Text: "Commit c1", Score: 0.8},
+		{EpisodeID: "E1", ChunkID: "chunk:2", Granularity: rag.GranularityChunk, Text: "Commits 4-7", Score: 0.7},
+	}
+
+	prompt, err := assembleProjectQueryPrompt(narrative.DefaultPromptTemplates(), "Who changed the retry logic?", nil, nil, chunks)
+	if err != nil {
+		t.Fatalf("assembleProjectQueryPrompt failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"## Commit 1: c1 from episode E1 (relevance: 0.80)",
+		"## Excerpt 2: chunk:2 of episode E1 (relevance: 0.70)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestQuerySearchOptions(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	pipeline := &RAGPipeline{config: RAGConfig{
+		Repository: "owner/app",
+		Filter:     rag.MetadataFilter{Since: since, Authors: []string{"bob"}, Paths: []string{"internal/retry"}},
+	}}
+
+	opts := pipeline.querySearchOptions()
+	if opts.Repository != "owner/app" || len(opts.Granularities) != 1 || opts.Granularities[0] != rag.GranularityEpisode {
+		t.Errorf("Expected episode-level retrieval scoped to owner/app, got %+v", opts)
+	}
+	if !opts.Since.Equal(since) || opts.Authors[0] != "bob" || opts.Paths[0] != "internal/retry" {
+		t.Errorf("Expected the configured filter, got %+v", opts.MetadataFilter)
+	}
+
+	summaries := pipeline.episodeSummaries([]cluster.Episode{chunkTestEpisode(3)})
+	if want := []string{"pkg/file0.go", "pkg/file1.go", "pkg/file2.go"}; strings.Join(summaries[0].Paths, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected the episode's paths %v, got %v", want, summaries[0].Paths)
+	}
+}
@@ -0,0 +1,256 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// DateRange bounds work by commit date; a zero Since or Until leaves that end open
+type DateRange struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Contains reports whether t falls within the range, both ends included
+func (r DateRange) Contains(t time.Time) bool {
+	return (r.Since.IsZero() || !t.Before(r.Since)) && (r.Until.IsZero() || !t.After(r.Until))
+}
+
+// GenerateContributorNarrative generates a personal summary of one contributor's work within dates,
+// such as what they shipped in a quarter, for reviews and records of accomplishments.
+// The author is matched by email or name, ignoring case; only their own commits within dates count
+// towards the episodes they are credited with, and related work is retrieved as context.
+func (p *RAGPipeline) GenerateContributorNarrative(
+	ctx context.Context,
+	author string,
+	dates DateRange,
+	episodes []cluster.Episode,
+) (*narrative.Narrative, error) {
+	narr, err := p.generateContributorNarrative(ctx, author, dates, episodes)
+	return narr, reportError(ctx, err)
+}
+
+// generateContributorNarrative runs the RAG stages for a contributor's narrative
+func (p *RAGPipeline) generateContributorNarrative(ctx context.Context, author string, dates DateRange, episodes []cluster.Episode) (*narrative.Narrative, error) {
+	author = strings.TrimSpace(author)
+	if author == "" {
+		return nil, fmt.Errorf("author cannot be empty")
+	}
+	log := p.logger(ctx).With("author", author)
+	log.Info("Generating contributor narrative")
+
+	episodes = p.redactor.Episodes(episodes)
+	contributions, names := contributorEpisodes(episodes, author, dates)
+	if len(contributions) == 0 {
+		return nil, fmt.Errorf("no commits by %s in the given date range", author)
+	}
+	log.Debug("Found episodes with commits by the contributor", "episodes", len(contributions))
+
+	// Stage 1: Retrieval - Get the indexed work of the contributor within the dates
+	options := p.querySearchOptions()
+	options.MetadataFilter.Authors = names
+	if !dates.Since.IsZero() {
+		options.MetadataFilter.Since = dates.Since
+	}
+	if !dates.Until.IsZero() {
+		options.MetadataFilter.Until = dates.Until
+	}
+	query := fmt.Sprintf("Work by %s", strings.Join(names, ", "))
+	log.Debug("Stage 1: retrieving episodes by the contributor", "top_k", p.config.TopK)
+	contextChunks, err := p.retriever.RetrieveContextForQuery(ctx, query, p.config.TopK, options)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval failed: %w", err)
+	}
+
+	// The contributor's episodes are listed in full, so context adds only the rest of their work
+	credited := make(map[string]bool, len(contributions))
+	for _, ep := range contributions {
+		credited[ep.ID] = true
+	}
+	related := contextChunks[:0]
+	for _, chunk := range contextChunks {
+		if !credited[chunk.EpisodeID] {
+			related = append(related, chunk)
+		}
+	}
+	contextChunks = rag.SelectContext(related, p.contextBudget())
+	log.Debug("Retrieved related context", "chunks", len(contextChunks))
+
+	// Stage 2: Prompt Assembly
+	contributions = p.classifyEpisodes(ctx, contributions)
+	log.Debug("Stage 2: assembling contributor prompt", "episodes", len(contributions))
+	var trace narrative.Provenance
+	prompt, err := p.prompts.WithTrace(&trace).ContributorPrompt(contributorPromptData(author, names, dates, contributions, episodes, contextChunks))
+	if err != nil {
+		return nil, fmt.Errorf("prompt assembly failed: %w", err)
+	}
+	log.Debug("Assembled prompt", "characters", len(prompt))
+
+	// Stage 3: LLM Generation
+	narr, err := p.generator.Generate(ctx, "contributor:"+author, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("narrative generation failed: %w", err)
+	}
+	narr.Provenance = &trace
+	log.Info("Generated contributor narrative", "characters", len(narr.Text), "model", narr.Model)
+	if err := narrativeGenerated(ctx, narr); err != nil {
+		return nil, err
+	}
+	return narr, nil
+}
+
+// isAuthor reports whether a commit or artifact author is the contributor, given by email or name
+func isAuthor(a git.Author, contributor string) bool {
+	return strings.EqualFold(strings.TrimSpace(a.Email), contributor) || strings.EqualFold(strings.TrimSpace(a.Name), contributor)
+}
+
+// contributorEpisodes returns the episodes the contributor committed to within dates, each narrowed
+// to those commits and to the artifacts they opened or that hold those commits, in time order,
+// along with the names the contributor committed under
+func contributorEpisodes(episodes []cluster.Episode, contributor string, dates DateRange) ([]cluster.Episode, []string) {
+	var contributions []cluster.Episode
+	names := make(map[string]bool)
+	for _, ep := range episodes {
+		own := ep
+		own.Commits = nil
+		own.Artifacts = nil
+		shas := make(map[string]bool)
+		for _, commit := range ep.Commits {
+			if isAuthor(commit.Author, contributor) && dates.Contains(commit.CommittedAt) {
+				own.Commits = append(own.Commits, commit)
+				shas[commit.Hash] = true
+				names[commit.Author.Name] = true
+			}
+		}
+		if len(own.Commits) == 0 {
+			continue
+		}
+		for _, artifact := range ep.Artifacts {
+			holds := false
+			for _, sha := range artifact.Metadata.CommitSHAs {
+				holds = holds || shas[sha]
+			}
+			if holds || isAuthor(artifact.Author, contributor) {
+				own.Artifacts = append(own.Artifacts, artifact)
+			}
+		}
+		own.Stats = own.ComputeStats()
+		contributions = append(contributions, own)
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].Commits[0].CommittedAt.Before(contributions[j].Commits[0].CommittedAt)
+	})
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if name != "" {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	return contributions, sorted
+}
+
+// contributorPromptData gathers the contributor's work for the contributor template; collaborators
+// are the other authors of the full episodes the contributions were taken from
+func contributorPromptData(author string, names []string, dates DateRange, contributions, episodes []cluster.Episode, contextChunks []rag.ContextChunk) narrative.ContributorPromptData {
+	data := narrative.ContributorPromptData{
+		Author:   author,
+		Names:    names,
+		Since:    dates.Since,
+		Until:    dates.Until,
+		Episodes: len(contributions),
+		WorkMix:  cluster.FormatCategoryCounts(cluster.CountCategories(contributions)),
+		Context:  contextChunks,
+	}
+
+	credited := make(map[string]bool, len(contributions))
+	for _, ep := range contributions {
+		credited[ep.ID] = true
+		data.Commits += len(ep.Commits)
+		data.Additions += ep.Stats.Additions
+		data.Deletions += ep.Stats.Deletions
+		start, end := ep.GetDateRange()
+		if data.Start.IsZero() || start.Before(data.Start) {
+			data.Start = start
+		}
+		if end.After(data.End) {
+			data.End = end
+		}
+	}
+
+	collaborators := make(map[string]bool)
+	for _, ep := range episodes {
+		if !credited[ep.ID] {
+			continue
+		}
+		for _, commit := range ep.Commits {
+			if !isAuthor(commit.Author, author) && commit.Author.Name != "" {
+				collaborators[commit.Author.Name] = true
+			}
+		}
+	}
+	for _, name := range names {
+		delete(collaborators, name)
+	}
+	for name := range collaborators {
+		data.Collaborators = append(data.Collaborators, name)
+	}
+	sort.Strings(data.Collaborators)
+
+	// Largest contributions first, so condensing the list to fit the prompt drops the smallest
+	var b strings.Builder
+	for _, ep := range largestFirst(contributions) {
+		b.WriteString(episodeLine(&ep))
+		b.WriteString("\n")
+	}
+	data.Contributions = b.String()
+	return data
+}
+
+// largestFirst returns a copy of episodes ordered by commits, then by lines changed, largest first
+func largestFirst(episodes []cluster.Episode) []cluster.Episode {
+	largest := make([]cluster.Episode, len(episodes))
+	copy(largest, episodes)
+	sort.SliceStable(largest, func(i, j int) bool {
+		if len(largest[i].Commits) != len(largest[j].Commits) {
+			return len(largest[i].Commits) > len(largest[j].Commits)
+		}
+		return largest[i].Stats.Additions+largest[i].Stats.Deletions > largest[j].Stats.Additions+largest[j].Stats.Deletions
+	})
+	return largest
+}
+
+// episodeLine lists an episode as a Markdown bullet with its dates, category, title, size and artifacts
+func episodeLine(ep *cluster.Episode) string {
+	var b strings.Builder
+	start, end := ep.GetDateRange()
+	title, _, _ := strings.Cut(generateEpisodeTitle(ep), "\n")
+	fmt.Fprintf(&b, "- **%s** (%s to %s", ep.ID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if ep.Category != "" {
+		fmt.Fprintf(&b, ", %s", ep.Category)
+	}
+	fmt.Fprintf(&b, "): %s; %d commits", title, len(ep.Commits))
+	if ep.Stats.Additions+ep.Stats.Deletions > 0 {
+		fmt.Fprintf(&b, ", +%d / -%d lines", ep.Stats.Additions, ep.Stats.Deletions)
+	}
+	if len(ep.Artifacts) > 0 {
+		refs := make([]string, len(ep.Artifacts))
+		for i, artifact := range ep.Artifacts {
+			refs[i] = fmt.Sprintf("%s #%d", artifact.Type, artifact.Number)
+			if artifact.State != "" {
+				refs[i] += " (" + artifact.State + ")"
+			}
+		}
+		fmt.Fprintf(&b, "; %s", strings.Join(refs, ", "))
+	}
+	return b.String()
+}
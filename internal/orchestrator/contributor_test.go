@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+var (
+	alice = git.Author{Name: "Alice", Email: "alice@example.com"}
+	bob   = git.Author{Name: "Bob", Email: "bob@example.com"}
+)
+
+// contributorTestEpisodes returns an episode Alice and Bob share in March, one of Bob's own, and
+// one of Alice's in January
+func contributorTestEpisodes() []cluster.Episode {
+	march := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	commit := func(hash string, author git.Author, at time.Time) git.Commit {
+		return git.Commit{Hash: hash, Author: author, CommittedAt: at, Message: "Change " + hash}
+	}
+	return []cluster.Episode{
+		{
+			ID: "E1",
+			Commits: []git.Commit{
+				commit("a000001", alice, march),
+				commit("b000001", bob, march.Add(time.Hour)),
+				commit("a000002", alice, march.Add(2*time.Hour)),
+			},
+			Artifacts: []cluster.Artifact{
+				{Type: cluster.ArtifactPullRequest, Number: 7, State: "merged", Author: bob, Metadata: cluster.ArtifactMetadata{CommitSHAs: []string{"a000002"}}},
+				{Type: cluster.ArtifactIssue, Number: 8, Author: bob},
+			},
+		},
+		{ID: "E2", Commits: []git.Commit{commit("b000002", bob, march)}},
+		{ID: "E3", Commits: []git.Commit{commit("a000003", alice, march.AddDate(0, -2, 0))}},
+	}
+}
+
+func TestContributorEpisodes(t *testing.T) {
+	march := DateRange{Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	contributions, names := contributorEpisodes(contributorTestEpisodes(), "ALICE@example.com", march)
+	if len(contributions) != 1 || contributions[0].ID != "E1" {
+		t.Fatalf("Expected only E1 in March, got %d episodes", len(contributions))
+	}
+	if got := len(contributions[0].Commits); got != 2 {
+		t.Errorf("Expected Alice's 2 commits, got %d", got)
+	}
+	if artifacts := contributions[0].Artifacts; len(artifacts) != 1 || artifacts[0].Number != 7 {
+		t.Errorf("Expected only the pull request holding Alice's commit, got %+v", artifacts)
+	}
+	if len(names) != 1 || names[0] != "Alice" {
+		t.Errorf("Expected the email to resolve to Alice, got %v", names)
+	}
+
+	if all, _ := contributorEpisodes(contributorTestEpisodes(), "alice", DateRange{}); len(all) != 2 || all[0].ID != "E3" {
+		t.Errorf("Expected both of Alice's episodes, oldest first, without a date range, got %d", len(all))
+	}
+}
+
+func TestGenerateContributorNarrative(t *testing.T) {
+	ctx := context.Background()
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	records := []rag.EpisodeRecord{
+		{EpisodeID: "E1", Text: "Work by Alice and Bob on retries", Authors: []string{"Alice", "Bob"}},
+		{EpisodeID: "E2", Text: "Work by Bob on logging", Authors: []string{"Bob"}},
+		{EpisodeID: "E9", Text: "Work by Alice on the parser", Authors: []string{"Alice"}},
+	}
+	for i, record := range records {
+		embedded, _ := embedder.Embed(ctx, []string{record.Text})
+		records[i].Embedding = embedded[0].Embedding
+	}
+	if err := store.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	retriever, _ := rag.NewRetriever(embedder, store)
+	llm := narrative.NewMockLLM("Alice shipped retries.")
+	pipeline := &RAGPipeline{
+		config:    RAGConfig{TopK: 5},
+		retriever: retriever,
+		llm:       llm,
+		generator: narrative.NewGenerator(llm, narrative.LLMConfig{}),
+		prompts:   narrative.DefaultPromptTemplates(),
+	}
+
+	narr, err := pipeline.GenerateContributorNarrative(ctx, "alice@example.com", DateRange{}, contributorTestEpisodes())
+	if err != nil {
+		t.Fatalf("GenerateContributorNarrative failed: %v", err)
+	}
+	if narr.EpisodeID != "contributor:alice@example.com" {
+		t.Errorf("Unexpected narrative ID %q", narr.EpisodeID)
+	}
+	for _, want := range []string{"**Contributor:** alice@example.com", "**Commits As:** Alice", "**E1**", "**E3**", "pull_request #7 (merged)", "**Worked With:** Bob", "**Episode E9**"} {
+		if !strings.Contains(llm.LastPrompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got:\n%s", want, llm.LastPrompt)
+		}
+	}
+	// Bob's own work is neither credited nor retrieved, and credited episodes aren't repeated as context
+	for _, unwanted := range []string{"**E2**", "**Episode E2**", "**Episode E1**"} {
+		if strings.Contains(llm.LastPrompt, unwanted) {
+			t.Errorf("Expected the prompt not to contain %q, got:\n%s", unwanted, llm.LastPrompt)
+		}
+	}
+
+	if _, err := pipeline.GenerateContributorNarrative(ctx, "carol", DateRange{}, contributorTestEpisodes()); err == nil {
+		t.Error("Expected an error for a contributor without commits")
+	}
+}
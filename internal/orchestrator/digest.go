@@ -0,0 +1,303 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// defaultDigestPeriods is how many periods a digest covers when its config leaves it unset
+const defaultDigestPeriods = 4
+
+// DigestConfig picks the calendar periods a digest covers
+type DigestConfig struct {
+	Period  cluster.Period // Week or month; empty is weekly
+	Periods int            // Number of periods, ending with the one holding Until; 0 is defaultDigestPeriods
+	Until   time.Time      // Moment the digest runs up to; zero is now
+}
+
+// Digest is a narrative per calendar period and an overview of the trends across them, for
+// recurring email or chat digests
+type Digest struct {
+	Period   cluster.Period       `json:"period"`
+	Overview *narrative.Narrative `json:"overview"`
+	Periods  []PeriodDigest       `json:"periods"` // Oldest first
+}
+
+// PeriodDigest is one period of a digest
+type PeriodDigest struct {
+	Label        string               `json:"label"`
+	Start        time.Time            `json:"start"` // First instant of the period
+	End          time.Time            `json:"end"`   // First instant of the next period
+	Episodes     int                  `json:"episodes"`
+	Commits      int                  `json:"commits"`
+	Additions    int                  `json:"additions"`
+	Deletions    int                  `json:"deletions"`
+	Contributors []string             `json:"contributors,omitempty"`
+	WorkMix      string               `json:"work_mix,omitempty"`
+	Narrative    *narrative.Narrative `json:"narrative,omitempty"` // Nil for a period without commits
+}
+
+// GenerateDigest buckets the work in episodes by calendar period and generates a narrative for each
+// period with commits, then an overview of the trends across the periods.
+// Each period's narrative covers only the commits made in it, with earlier related work retrieved
+// as context; the overview is written from the period narratives and their statistics. With a
+// narrative cache, periods whose work is unchanged, and an overview of unchanged periods, are not
+// generated again.
+func (p *RAGPipeline) GenerateDigest(ctx context.Context, episodes []cluster.Episode, config DigestConfig) (*Digest, error) {
+	digest, err := p.generateDigest(ctx, episodes, config)
+	return digest, reportError(ctx, err)
+}
+
+// generateDigest generates the period narratives and overview of a digest
+func (p *RAGPipeline) generateDigest(ctx context.Context, episodes []cluster.Episode, config DigestConfig) (*Digest, error) {
+	period, err := cluster.ParsePeriod(string(config.Period))
+	if err != nil {
+		return nil, err
+	}
+	periods := config.Periods
+	if periods <= 0 {
+		periods = defaultDigestPeriods
+	}
+	until := config.Until
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+	since := period.Add(period.Floor(until), 1-periods)
+	log := p.logger(ctx)
+	log.Info("Generating digest", "period", period, "periods", periods, "until", until.Format("2006-01-02"))
+
+	digest := &Digest{Period: period}
+	buckets := cluster.BucketByPeriod(p.redactor.Episodes(episodes), period, since, until)
+	for i := range buckets {
+		bucket := &buckets[i]
+		bucket.Episodes = p.classifyEpisodes(ctx, bucket.Episodes)
+		entry := periodDigest(bucket)
+		if len(bucket.Episodes) > 0 {
+			log.Info("Generating digest narrative", "label", bucket.Label, "episodes", entry.Episodes)
+			fingerprints := []string{"period", string(period), bucket.Label}
+			for j := range bucket.Episodes {
+				fingerprints = append(fingerprints, bucket.Episodes[j].ID, episodeFingerprint(&bucket.Episodes[j]))
+			}
+			entry.Narrative, err = p.cachedNarrative(ctx, "digest:"+bucket.Label, contentHash(fingerprints...), []narrative.PromptType{narrative.PromptPeriod}, func() (*narrative.Narrative, error) {
+				return p.generatePeriodNarrative(ctx, period, bucket, entry)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("narrative for %s failed: %w", bucket.Label, err)
+			}
+		}
+		digest.Periods = append(digest.Periods, entry)
+	}
+	if digest.Commits() == 0 {
+		return nil, fmt.Errorf("no commits in the %d %ss up to %s", periods, period, until.Format("2006-01-02"))
+	}
+
+	// The overview is written from the period narratives, so it is regenerated whenever one of them is
+	log.Info("Generating digest overview")
+	data := digestPromptData(period, digest.Periods)
+	summaries := []string{"digest", string(period)}
+	for _, entry := range data.Periods {
+		summaries = append(summaries, entry.Label, entry.Summary)
+	}
+	digest.Overview, err = p.cachedNarrative(ctx, "digest:overview", contentHash(summaries...), []narrative.PromptType{narrative.PromptDigest}, func() (*narrative.Narrative, error) {
+		var trace narrative.Provenance
+		prompt, err := p.prompts.WithTrace(&trace).DigestPrompt(data)
+		if err != nil {
+			return nil, fmt.Errorf("prompt assembly failed: %w", err)
+		}
+		narr, err := p.generator.Generate(ctx, "digest:overview", prompt)
+		if err != nil {
+			return nil, err
+		}
+		narr.Provenance = &trace
+		return narr, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("overview generation failed: %w", err)
+	}
+	log.Info("Generated digest", "periods", len(digest.Periods))
+	return digest, nil
+}
+
+// generatePeriodNarrative retrieves earlier work related to a period's episodes and generates its narrative
+func (p *RAGPipeline) generatePeriodNarrative(ctx context.Context, period cluster.Period, bucket *cluster.PeriodBucket, entry PeriodDigest) (*narrative.Narrative, error) {
+	// Stage 1: Retrieval - Work started before the period that its episodes build on
+	titles := make([]string, 0, len(bucket.Episodes))
+	current := make(map[string]bool, len(bucket.Episodes))
+	for _, ep := range bucket.Episodes {
+		title, _, _ := strings.Cut(generateEpisodeTitle(&ep), "\n")
+		titles = append(titles, title)
+		current[ep.ID] = true
+	}
+	options := p.querySearchOptions()
+	if before := bucket.Start.Add(-time.Nanosecond); options.MetadataFilter.Until.IsZero() || options.MetadataFilter.Until.After(before) {
+		options.MetadataFilter.Until = before
+	}
+	contextChunks, err := p.retriever.RetrieveContextForQuery(ctx, strings.Join(titles, "\n"), p.config.TopK, options)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval failed: %w", err)
+	}
+	earlier := contextChunks[:0]
+	for _, chunk := range contextChunks {
+		if !current[chunk.EpisodeID] {
+			earlier = append(earlier, chunk)
+		}
+	}
+	contextChunks = rag.SelectContext(earlier, p.contextBudget())
+
+	// Stage 2: Prompt Assembly - Largest episodes first, so condensing the list drops the smallest
+	var highlights strings.Builder
+	for _, ep := range largestFirst(bucket.Episodes) {
+		highlights.WriteString(episodeLine(&ep))
+		if authors := commitAuthors([]cluster.Episode{ep}); len(authors) > 0 {
+			fmt.Fprintf(&highlights, "; by %s", strings.Join(authors, ", "))
+		}
+		highlights.WriteString("\n")
+	}
+	var trace narrative.Provenance
+	prompt, err := p.prompts.WithTrace(&trace).PeriodPrompt(narrative.PeriodPromptData{
+		Period:       string(period),
+		Label:        entry.Label,
+		Start:        entry.Start,
+		End:          lastDay(entry.End),
+		Episodes:     entry.Episodes,
+		Commits:      entry.Commits,
+		Additions:    entry.Additions,
+		Deletions:    entry.Deletions,
+		Contributors: entry.Contributors,
+		WorkMix:      entry.WorkMix,
+		Highlights:   highlights.String(),
+		Context:      contextChunks,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prompt assembly failed: %w", err)
+	}
+
+	// Stage 3: LLM Generation
+	narr, err := p.generator.Generate(ctx, "digest:"+entry.Label, prompt)
+	if err != nil {
+		return nil, err
+	}
+	narr.Provenance = &trace
+	return narr, nil
+}
+
+// periodDigest summarizes the statistics of a period's work, without its narrative
+func periodDigest(bucket *cluster.PeriodBucket) PeriodDigest {
+	entry := PeriodDigest{
+		Label:        bucket.Label,
+		Start:        bucket.Start,
+		End:          bucket.End,
+		Episodes:     len(bucket.Episodes),
+		Contributors: commitAuthors(bucket.Episodes),
+	}
+	if len(bucket.Episodes) > 0 {
+		entry.WorkMix = cluster.FormatCategoryCounts(cluster.CountCategories(bucket.Episodes))
+	}
+	for _, ep := range bucket.Episodes {
+		entry.Commits += len(ep.Commits)
+		entry.Additions += ep.Stats.Additions
+		entry.Deletions += ep.Stats.Deletions
+	}
+	return entry
+}
+
+// digestPromptData gathers the periods of a digest, with their narratives, for the digest template
+func digestPromptData(period cluster.Period, periods []PeriodDigest) narrative.DigestPromptData {
+	data := narrative.DigestPromptData{Period: string(period)}
+	contributors := make(map[string]bool)
+	for _, entry := range periods {
+		summary := ""
+		if entry.Narrative != nil {
+			summary = strings.TrimSpace(entry.Narrative.Text)
+		}
+		data.Periods = append(data.Periods, narrative.DigestPeriod{
+			Label:        entry.Label,
+			Start:        entry.Start,
+			End:          lastDay(entry.End),
+			Episodes:     entry.Episodes,
+			Commits:      entry.Commits,
+			Additions:    entry.Additions,
+			Deletions:    entry.Deletions,
+			Contributors: len(entry.Contributors),
+			WorkMix:      entry.WorkMix,
+			Summary:      summary,
+		})
+		data.Episodes += entry.Episodes
+		data.Commits += entry.Commits
+		for _, name := range entry.Contributors {
+			contributors[name] = true
+		}
+	}
+	if len(periods) > 0 {
+		data.Start = periods[0].Start
+		data.End = lastDay(periods[len(periods)-1].End)
+	}
+	for name := range contributors {
+		data.Contributors = append(data.Contributors, name)
+	}
+	sort.Strings(data.Contributors)
+	return data
+}
+
+// commitAuthors returns the distinct names of the episodes' commit authors, sorted
+func commitAuthors(episodes []cluster.Episode) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ep := range episodes {
+		for _, commit := range ep.Commits {
+			if name := commit.Author.Name; name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lastDay returns the day before a period's exclusive end, its last day
+func lastDay(end time.Time) time.Time {
+	return end.AddDate(0, 0, -1)
+}
+
+// Commits returns the number of commits across the digest's periods
+func (d *Digest) Commits() int {
+	commits := 0
+	for _, entry := range d.Periods {
+		commits += entry.Commits
+	}
+	return commits
+}
+
+// Markdown renders the digest for an email or chat message: the overview, then each period, newest first
+func (d *Digest) Markdown() string {
+	var b strings.Builder
+	if len(d.Periods) > 0 {
+		first, last := d.Periods[0], d.Periods[len(d.Periods)-1]
+		fmt.Fprintf(&b, "# Development digest: %s to %s\n\n", first.Start.Format("2006-01-02"), lastDay(last.End).Format("2006-01-02"))
+	}
+	if d.Overview != nil {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(d.Overview.Text))
+	}
+	for i := len(d.Periods) - 1; i >= 0; i-- {
+		entry := d.Periods[i]
+		fmt.Fprintf(&b, "## %s (%s to %s)\n\n", entry.Label, entry.Start.Format("2006-01-02"), lastDay(entry.End).Format("2006-01-02"))
+		if entry.Narrative == nil {
+			b.WriteString("_No commits._\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "**%d episodes, %d commits, %d contributors**", entry.Episodes, entry.Commits, len(entry.Contributors))
+		if entry.WorkMix != "" {
+			fmt.Fprintf(&b, " · %s", entry.WorkMix)
+		}
+		fmt.Fprintf(&b, "\n\n%s\n\n", strings.TrimSpace(entry.Narrative.Text))
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
@@ -0,0 +1,136 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// recordingLLM answers every prompt with the same text, keeping the prompts in order
+type recordingLLM struct {
+	prompts []string
+}
+
+func (l *recordingLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	l.prompts = append(l.prompts, prompt)
+	return "Summary.", nil
+}
+
+func TestGenerateDigest(t *testing.T) {
+	ctx := context.Background()
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	january := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	records := []rag.EpisodeRecord{
+		{EpisodeID: "E1", Text: "Work by Alice and Bob on retries", StartDate: march, EndDate: march},
+		{EpisodeID: "E3", Text: "Work by Alice on the parser", StartDate: january, EndDate: january},
+	}
+	for i, record := range records {
+		embedded, _ := embedder.Embed(ctx, []string{record.Text})
+		records[i].Embedding = embedded[0].Embedding
+	}
+	if err := store.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	retriever, _ := rag.NewRetriever(embedder, store)
+	llm := narrative.NewMockLLM("The team shipped retries.")
+	pipeline := &RAGPipeline{
+		config:    RAGConfig{TopK: 5},
+		retriever: retriever,
+		llm:       llm,
+		generator: narrative.NewGenerator(llm, narrative.LLMConfig{}),
+		prompts:   narrative.DefaultPromptTemplates(),
+	}
+
+	// The week of 4 March holds E1 and E2; the week before has no commits
+	until := time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC)
+	digest, err := pipeline.GenerateDigest(ctx, contributorTestEpisodes(), DigestConfig{Period: cluster.PeriodWeek, Periods: 2, Until: until})
+	if err != nil {
+		t.Fatalf("GenerateDigest failed: %v", err)
+	}
+	if len(digest.Periods) != 2 || digest.Periods[0].Label != "2024-W09" || digest.Periods[1].Label != "2024-W10" {
+		t.Fatalf("Expected weeks 9 and 10, got %+v", digest.Periods)
+	}
+	if quiet := digest.Periods[0]; quiet.Narrative != nil || quiet.Commits != 0 {
+		t.Errorf("Expected no narrative for a week without commits, got %+v", quiet)
+	}
+	week := digest.Periods[1]
+	if week.Narrative == nil || week.Narrative.EpisodeID != "digest:2024-W10" {
+		t.Fatalf("Expected a narrative for week 10, got %+v", week.Narrative)
+	}
+	if week.Episodes != 2 || week.Commits != 4 || strings.Join(week.Contributors, ",") != "Alice,Bob" {
+		t.Errorf("Unexpected statistics for week 10: %+v", week)
+	}
+	if digest.Overview == nil || digest.Overview.EpisodeID != "digest:overview" {
+		t.Fatalf("Expected an overview, got %+v", digest.Overview)
+	}
+	for _, want := range []string{"| 2024-W09 | 0 | 0 |", "| 2024-W10 | 2 | 4 |", "## 2024-W10", "The team shipped retries.", "No commits."} {
+		if !strings.Contains(llm.LastPrompt, want) {
+			t.Errorf("Expected the overview prompt to contain %q, got:\n%s", want, llm.LastPrompt)
+		}
+	}
+
+	markdown := digest.Markdown()
+	if !strings.HasPrefix(markdown, "# Development digest: 2024-02-26 to 2024-03-10\n") {
+		t.Errorf("Unexpected digest heading:\n%s", markdown)
+	}
+	if strings.Index(markdown, "## 2024-W10") > strings.Index(markdown, "## 2024-W09") {
+		t.Errorf("Expected the newest week first:\n%s", markdown)
+	}
+
+	if _, err := pipeline.GenerateDigest(ctx, contributorTestEpisodes(), DigestConfig{Period: cluster.PeriodMonth, Periods: 1, Until: until.AddDate(1, 0, 0)}); err == nil {
+		t.Error("Expected an error for a digest without commits")
+	}
+}
+
+func TestGenerateDigest_PeriodContext(t *testing.T) {
+	ctx := context.Background()
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	january := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	records := []rag.EpisodeRecord{
+		{EpisodeID: "E1", Text: "Change a000001", StartDate: march, EndDate: march},
+		{EpisodeID: "E3", Text: "Change a000003", StartDate: january, EndDate: january},
+		{EpisodeID: "E9", Text: "Change a000009", StartDate: march.AddDate(0, 1, 0), EndDate: march.AddDate(0, 1, 0)},
+	}
+	for i, record := range records {
+		embedded, _ := embedder.Embed(ctx, []string{record.Text})
+		records[i].Embedding = embedded[0].Embedding
+	}
+	if err := store.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	retriever, _ := rag.NewRetriever(embedder, store)
+	llm := &recordingLLM{}
+	pipeline := &RAGPipeline{
+		config:    RAGConfig{TopK: 5},
+		retriever: retriever,
+		llm:       llm,
+		generator: narrative.NewGenerator(llm, narrative.LLMConfig{}),
+		prompts:   narrative.DefaultPromptTemplates(),
+	}
+
+	until := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := pipeline.GenerateDigest(ctx, contributorTestEpisodes(), DigestConfig{Period: cluster.PeriodMonth, Periods: 1, Until: until}); err != nil {
+		t.Fatalf("GenerateDigest failed: %v", err)
+	}
+	if len(llm.prompts) != 2 {
+		t.Fatalf("Expected a period narrative and an overview, got %d prompts", len(llm.prompts))
+	}
+	prompt := llm.prompts[0]
+	if !strings.Contains(prompt, "**Period:** 2024-03 (2024-03-01 to 2024-03-31)") || !strings.Contains(prompt, "**Episode E3**") {
+		t.Errorf("Expected March with earlier work as context, got:\n%s", prompt)
+	}
+	for _, unwanted := range []string{"**Episode E1**", "**Episode E9**"} {
+		if strings.Contains(prompt, unwanted) {
+			t.Errorf("Expected only earlier work as context, found %q in:\n%s", unwanted, prompt)
+		}
+	}
+}
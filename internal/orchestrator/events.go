@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// Hooks are callbacks run as analysis and the RAG pipeline produce episodes, narratives and index
+// entries, so publishers, metrics and validation can be added without changing the pipeline
+// Any hook may be nil. An error returned by OnEpisodeCreated, OnNarrativeGenerated or OnIndexed
+// fails the operation that raised the event, so hooks that only observe should return nil
+type Hooks struct {
+	// OnEpisodeCreated is called with every episode analysis produces, and every episode a live
+	// repository regroups, before it is returned
+	OnEpisodeCreated func(ctx context.Context, episode *cluster.Episode) error
+
+	// OnNarrativeGenerated is called with every narrative the pipeline generates or reads from its
+	// cache, before it is cached or returned
+	OnNarrativeGenerated func(ctx context.Context, narr *narrative.Narrative) error
+
+	// OnIndexed is called with the episodes just indexed or re-indexed
+	OnIndexed func(ctx context.Context, episodes []cluster.Episode) error
+
+	// OnError is called with every error that fails an analysis, index or narrative, including
+	// those of the repositories, submodules and episodes a batch skips
+	OnError func(ctx context.Context, err error)
+}
+
+// Events delivers pipeline events to every subscribed Hooks, in the order they subscribed
+// Attach it to the context of the calls whose events it should receive with WithEvents
+type Events struct {
+	mu            sync.RWMutex
+	subscriptions []*Hooks
+}
+
+// NewEvents creates an event bus without subscribers
+func NewEvents() *Events {
+	return &Events{}
+}
+
+// Subscribe adds hooks to the bus, returning a function that removes them again
+func (e *Events) Subscribe(hooks Hooks) (unsubscribe func()) {
+	subscription := &hooks
+	e.mu.Lock()
+	e.subscriptions = append(e.subscriptions, subscription)
+	e.mu.Unlock()
+
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.subscriptions = slices.DeleteFunc(e.subscriptions, func(s *Hooks) bool { return s == subscription })
+	}
+}
+
+// eventsKey keys the event bus attached to a context
+type eventsKey struct{}
+
+// WithEvents returns a context delivering the events of the analyses and pipeline calls made with
+// it to events
+func WithEvents(ctx context.Context, events *Events) context.Context {
+	return context.WithValue(ctx, eventsKey{}, events)
+}
+
+// eventsFrom returns the event bus attached to ctx, or nil
+func eventsFrom(ctx context.Context) *Events {
+	events, _ := ctx.Value(eventsKey{}).(*Events)
+	return events
+}
+
+// hooks returns the current subscribers
+func (e *Events) hooks() []*Hooks {
+	if e == nil {
+		return nil
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return slices.Clone(e.subscriptions)
+}
+
+// episodesCreated runs OnEpisodeCreated for each episode, stopping at the first error
+func episodesCreated(ctx context.Context, episodes []cluster.Episode) error {
+	for _, hooks := range eventsFrom(ctx).hooks() {
+		if hooks.OnEpisodeCreated == nil {
+			continue
+		}
+		for i := range episodes {
+			if err := hooks.OnEpisodeCreated(ctx, &episodes[i]); err != nil {
+				return fmt.Errorf("episode %s rejected: %w", episodes[i].ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// narrativeGenerated runs OnNarrativeGenerated, stopping at the first error
+func narrativeGenerated(ctx context.Context, narr *narrative.Narrative) error {
+	for _, hooks := range eventsFrom(ctx).hooks() {
+		if hooks.OnNarrativeGenerated == nil {
+			continue
+		}
+		if err := hooks.OnNarrativeGenerated(ctx, narr); err != nil {
+			return fmt.Errorf("narrative %s rejected: %w", narr.EpisodeID, err)
+		}
+	}
+	return nil
+}
+
+// episodesIndexed runs OnIndexed, stopping at the first error
+func episodesIndexed(ctx context.Context, episodes []cluster.Episode) error {
+	for _, hooks := range eventsFrom(ctx).hooks() {
+		if hooks.OnIndexed == nil {
+			continue
+		}
+		if err := hooks.OnIndexed(ctx, episodes); err != nil {
+			return fmt.Errorf("indexed episodes rejected: %w", err)
+		}
+	}
+	return nil
+}
+
+// reportError runs OnError with a non-nil err and returns err
+func reportError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, hooks := range eventsFrom(ctx).hooks() {
+		if hooks.OnError != nil {
+			hooks.OnError(ctx, err)
+		}
+	}
+	return err
+}
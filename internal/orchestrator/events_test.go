@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/state"
+	gogit "github.com/go-git/go-git/v6"
+)
+
+func TestEvents_Subscribe(t *testing.T) {
+	events := NewEvents()
+	ctx := WithEvents(context.Background(), events)
+
+	var calls []string
+	events.Subscribe(Hooks{OnIndexed: func(ctx context.Context, episodes []cluster.Episode) error {
+		calls = append(calls, "first")
+		return nil
+	}})
+	unsubscribe := events.Subscribe(Hooks{OnIndexed: func(ctx context.Context, episodes []cluster.Episode) error {
+		calls = append(calls, "second")
+		return nil
+	}})
+
+	if err := episodesIndexed(ctx, nil); err != nil {
+		t.Fatalf("episodesIndexed failed: %v", err)
+	}
+	unsubscribe()
+	if err := episodesIndexed(ctx, nil); err != nil {
+		t.Fatalf("episodesIndexed failed: %v", err)
+	}
+	if len(calls) != 3 || calls[0] != "first" || calls[1] != "second" || calls[2] != "first" {
+		t.Errorf("Expected subscribers called in order until unsubscribed, got %v", calls)
+	}
+
+	// Without a bus, events go nowhere
+	if err := episodesIndexed(context.Background(), nil); err != nil {
+		t.Errorf("Expected no error without a bus, got %v", err)
+	}
+}
+
+func TestEvents_RejectionStopsDelivery(t *testing.T) {
+	events := NewEvents()
+	ctx := WithEvents(context.Background(), events)
+	rejected := errors.New("no commits")
+
+	events.Subscribe(Hooks{OnEpisodeCreated: func(ctx context.Context, episode *cluster.Episode) error {
+		if len(episode.Commits) == 0 {
+			return rejected
+		}
+		return nil
+	}})
+	later := 0
+	events.Subscribe(Hooks{OnEpisodeCreated: func(ctx context.Context, episode *cluster.Episode) error {
+		later++
+		return nil
+	}})
+
+	err := episodesCreated(ctx, []cluster.Episode{*summaryTestEpisode(1), {ID: "E2"}})
+	if !errors.Is(err, rejected) {
+		t.Fatalf("Expected the rejection returned, got %v", err)
+	}
+	if later != 0 {
+		t.Errorf("Expected later subscribers skipped after a rejection, got %d calls", later)
+	}
+}
+
+func TestAnalyzeRepositoryWithState_Events(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	events := NewEvents()
+	ctx := WithEvents(context.Background(), events)
+	var created []string
+	events.Subscribe(Hooks{OnEpisodeCreated: func(ctx context.Context, episode *cluster.Episode) error {
+		created = append(created, episode.ID)
+		return nil
+	}})
+
+	episodes, err := AnalyzeRepositoryWithState(ctx, dir, state.NewMemoryStore(), cluster.DefaultGroupingConfig())
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if len(created) != len(episodes) || len(created) == 0 {
+		t.Errorf("Expected OnEpisodeCreated for each of %d episodes, got %v", len(episodes), created)
+	}
+}
+
+func TestRAGPipeline_Events(t *testing.T) {
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	retriever, _ := rag.NewRetriever(embedder, store)
+	cache, err := narrative.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	llm := &recordingLLM{}
+	config := RAGConfig{TopK: 3, LLMConfig: narrative.LLMConfig{Model: "gpt-4o"}}
+	pipeline := &RAGPipeline{
+		config:      config,
+		embedder:    embedder,
+		vectorStore: store,
+		retriever:   retriever,
+		llm:         llm,
+		generator:   narrative.NewGenerator(llm, config.LLMConfig),
+		prompts:     narrative.DefaultPromptTemplates(),
+		cache:       cache,
+	}
+
+	events := NewEvents()
+	ctx := WithEvents(context.Background(), events)
+	var indexed, generated int
+	var failures []error
+	reject := true
+	events.Subscribe(Hooks{
+		OnIndexed: func(ctx context.Context, episodes []cluster.Episode) error {
+			indexed += len(episodes)
+			return nil
+		},
+		OnNarrativeGenerated: func(ctx context.Context, narr *narrative.Narrative) error {
+			generated++
+			if reject {
+				return errors.New("too short")
+			}
+			return nil
+		},
+		OnError: func(ctx context.Context, err error) {
+			failures = append(failures, err)
+		},
+	})
+
+	episode := summaryTestEpisode(3)
+	if err := pipeline.IndexEpisodes(ctx, []cluster.Episode{*episode}); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+	if indexed != 1 {
+		t.Errorf("Expected OnIndexed with 1 episode, got %d", indexed)
+	}
+
+	// A rejected narrative fails the call, is reported, and isn't cached
+	if _, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode); err == nil {
+		t.Fatal("Expected the rejected narrative to fail generation")
+	}
+	if len(failures) != 1 || generated != 1 {
+		t.Errorf("Expected one reported failure after one narrative, got %v after %d", failures, generated)
+	}
+
+	reject = false
+	if _, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode); err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	if len(llm.prompts) != 2 {
+		t.Errorf("Expected the rejected narrative regenerated rather than cached, got %d LLM calls", len(llm.prompts))
+	}
+
+	// Cached narratives are delivered too
+	if _, err := pipeline.GenerateEpisodeNarrativeRAG(ctx, episode); err != nil {
+		t.Fatalf("GenerateEpisodeNarrativeRAG failed: %v", err)
+	}
+	if generated != 3 || len(llm.prompts) != 2 {
+		t.Errorf("Expected the cached narrative delivered without an LLM call, got %d narratives and %d calls", generated, len(llm.prompts))
+	}
+}
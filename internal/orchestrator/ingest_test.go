@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/adapter"
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+)
+
+// stubAdapter returns a fixed set of artifacts
+type stubAdapter struct {
+	artifacts []cluster.Artifact
+	token     string
+}
+
+func (a *stubAdapter) ConvertIssue(issue interface{}) (*cluster.Artifact, error) { return nil, nil }
+
+func (a *stubAdapter) ConvertPullRequest(pr interface{}) (*cluster.Artifact, error) { return nil, nil }
+
+func (a *stubAdapter) GetPlatform() cluster.SourcePlatform { return cluster.PlatformBitbucket }
+
+func (a *stubAdapter) FetchArtifacts(ctx context.Context, token, owner, repo string) ([]cluster.Artifact, error) {
+	a.token = token
+	return a.artifacts, nil
+}
+
+func TestAnalyzeRepository_RegisteredAdapter(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"git@bitbucket.org:team/project.git"}}); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	// Rebase merges keep the branch's message, so only the PR's merge SHA links the commit to it
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to read HEAD: %v", err)
+	}
+
+	stub := &stubAdapter{artifacts: []cluster.Artifact{{
+		ID:       "pr-7",
+		Number:   7,
+		Type:     cluster.ArtifactPullRequest,
+		Metadata: cluster.ArtifactMetadata{MergeCommitSHA: head.Hash().String()},
+	}}}
+	adapter.Register(cluster.PlatformBitbucket, func(baseURL string) adapter.Adapter { return stub })
+	defer adapter.Register(cluster.PlatformBitbucket, nil)
+
+	episodes, err := AnalyzeRepository(context.Background(), dir, "secret")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if stub.token != "secret" {
+		t.Errorf("Expected the registered adapter called with the token, got %q", stub.token)
+	}
+	if len(episodes) != 1 || len(episodes[0].Artifacts) != 1 || episodes[0].Artifacts[0].ID != "pr-7" {
+		t.Fatalf("Expected one episode linked to pr-7, got %+v", episodes)
+	}
+	if number := episodes[0].Commits[0].PullRequestNumber; number != 7 {
+		t.Errorf("Expected the merge commit to record PR 7, got %d", number)
+	}
+}
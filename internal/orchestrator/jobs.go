@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/jobs"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// Kinds of the background jobs RegisterJobs handles
+const (
+	JobAnalyze = "analyze"
+	JobIndex   = "index"
+)
+
+// RepositoryJob is the parameters of an analyze or index job: the local path or remote URL of
+// the repository to work on
+type RepositoryJob struct {
+	Repository string `json:"repository"`
+}
+
+// RepositoryJobResult is what an analyze or index job records when it succeeds
+type RepositoryJobResult struct {
+	Repository string `json:"repository"` // Key the episodes are indexed under
+	Episodes   int    `json:"episodes"`
+	Commits    int    `json:"commits"`
+	Indexed    int    `json:"indexed,omitempty"` // Episodes embedded by an index job, skipping those already indexed
+
+	// Report records the artifacts, submodules and other items that failed or were skipped
+	Report *report.RunReport `json:"report"`
+}
+
+// RegisterJobs registers handlers on queue for analyze jobs, which group a repository's history
+// into episodes, and index jobs, which also index the episodes into the vector store of config,
+// scoped to the repository. GitHub tokens and clone credentials are read from the environment,
+// as AnalyzeRepository does, so they are never stored with the jobs. Each index job creates its
+// pipeline with opts; concurrent jobs should share one store through WithSharedVectorStore, as
+// separately opened local stores on one file would overwrite each other's records.
+func RegisterJobs(queue *jobs.Queue, config RAGConfig, opts ...PipelineOption) {
+	queue.Register(JobAnalyze, func(ctx context.Context, job *jobs.Job, progress func(jobs.Progress)) (any, error) {
+		runReport := report.New()
+		repo, episodes, err := analyzeJob(report.With(ctx, runReport), job, progress)
+		if err != nil {
+			return nil, err
+		}
+		progress(jobs.Progress{Done: 1, Total: 1, Message: fmt.Sprintf("Found %d episodes", len(episodes))})
+		return jobResult(repo, episodes, runReport), nil
+	})
+
+	queue.Register(JobIndex, func(ctx context.Context, job *jobs.Job, progress func(jobs.Progress)) (any, error) {
+		runReport := report.New()
+		ctx = report.With(ctx, runReport)
+		repo, episodes, err := analyzeJob(ctx, job, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		result := jobResult(repo, episodes, runReport)
+		indexConfig := config
+		indexConfig.Repository = result.Repository
+		indexConfig.IndexProgress = func(indexed rag.IndexProgress) {
+			result.Indexed = indexed.EpisodesDone
+			progress(jobs.Progress{Done: indexed.EpisodesDone, Total: indexed.Episodes, Message: "Indexing episodes"})
+		}
+		progress(jobs.Progress{Total: len(episodes), Message: "Indexing episodes"})
+
+		pipeline, err := NewRAGPipeline(ctx, indexConfig, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create RAG pipeline: %w", err)
+		}
+		defer pipeline.Close()
+		if err := pipeline.IndexEpisodes(ctx, episodes); err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+}
+
+// analyzeJob analyzes the repository an analyze or index job names
+func analyzeJob(ctx context.Context, job *jobs.Job, progress func(jobs.Progress)) (string, []cluster.Episode, error) {
+	var params RepositoryJob
+	if err := job.DecodeParams(&params); err != nil {
+		return "", nil, err
+	}
+	repo := strings.TrimSpace(params.Repository)
+	if repo == "" {
+		return "", nil, jobs.Permanent(fmt.Errorf("%s job has no repository", job.Kind))
+	}
+
+	progress(jobs.Progress{Total: 1, Message: "Analyzing " + repo})
+	episodes, err := AnalyzeRepository(ctx, repo)
+	if err != nil {
+		return "", nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	return repo, episodes, nil
+}
+
+// jobResult counts what analyzing a repository found
+func jobResult(repo string, episodes []cluster.Episode, runReport *report.RunReport) RepositoryJobResult {
+	result := RepositoryJobResult{Repository: RepositoryKey(repo), Episodes: len(episodes), Report: runReport}
+	for _, ep := range episodes {
+		result.Commits += len(ep.Commits)
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/jobs"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	gogit "github.com/go-git/go-git/v6"
+)
+
+func TestRegisterJobs_Index(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", base)
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", base.Add(time.Hour))
+
+	config := DefaultRAGConfig()
+	config.EmbedderProvider = rag.EmbedderProviderFake
+	config.EmbedderDimension = 64
+	config.LocalStore = rag.DefaultLocalStoreConfig(filepath.Join(t.TempDir(), "episodes.db"))
+	config.LocalStore.Dimension = 64
+	config.LLMConfig = narrative.LLMConfig{Provider: narrative.ProviderLocal, BaseURL: "http://127.0.0.1:1/v1", Model: "llama3.1"}
+	config.SummaryModel = ""
+
+	queue := jobs.NewQueue(jobs.NewMemoryStore(), jobs.Options{})
+	RegisterJobs(queue, config)
+	job, err := queue.Enqueue(ctx, JobIndex, RepositoryJob{Repository: dir})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := queue.RunPending(ctx); err != nil {
+		t.Fatalf("RunPending failed: %v", err)
+	}
+
+	job, err = queue.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if job.Status != jobs.StatusSucceeded {
+		t.Fatalf("Expected the index job to succeed, got %s: %s", job.Status, job.Error)
+	}
+	var result RepositoryJobResult
+	if err := json.Unmarshal(job.Result, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if result.Repository != RepositoryKey(dir) || result.Episodes == 0 || result.Commits != 2 || result.Indexed != result.Episodes {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if job.Progress.Done != result.Episodes || job.Progress.Total != result.Episodes {
+		t.Errorf("Expected indexing progress complete, got %+v", job.Progress)
+	}
+}
+
+func TestRegisterJobs_InvalidParameters(t *testing.T) {
+	ctx := context.Background()
+	queue := jobs.NewQueue(jobs.NewMemoryStore(), jobs.Options{})
+	RegisterJobs(queue, DefaultRAGConfig())
+
+	job, err := queue.Enqueue(ctx, JobAnalyze, RepositoryJob{})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	queue.RunPending(ctx)
+	if job, _ = queue.Get(ctx, job.ID); job.Status != jobs.StatusFailed || job.Attempts != 1 {
+		t.Errorf("Expected a job without a repository to fail without retries, got %+v", job)
+	}
+}
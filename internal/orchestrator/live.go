@@ -0,0 +1,465 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/ingest/github/webhook"
+)
+
+// EpisodeSyncer keeps a search index in line with regrouped episodes (RAGPipeline implements it)
+type EpisodeSyncer interface {
+	SyncEpisodes(ctx context.Context, changed []cluster.Episode, removed []string) error
+}
+
+// LiveRepository keeps a repository's episodes current from webhook updates or polling
+// Pushes ingest only the commits after the last checkpoint; issue and PR events replace the
+// affected artifact. After every update only the episodes near it are regrouped (all of them
+// under semantic grouping) and only those that changed are sent to the syncer
+type LiveRepository struct {
+	repo   string
+	config cluster.GroupingConfig
+	auth   git.AuthOptions
+	token  string
+	syncer EpisodeSyncer
+
+	mu         sync.Mutex
+	activity   *cluster.RepositoryActivity
+	checkpoint git.Checkpoint
+	episodes   []cluster.Episode
+}
+
+// NewLiveRepository ingests a repository once and returns a handle that applies webhook updates to it
+// syncer may be nil when no index needs to be kept up to date
+// Token is automatically loaded from GITHUB_TOKEN (GITLAB_TOKEN for GitLab) environment variable if not provided
+func NewLiveRepository(ctx context.Context, repo string, config cluster.GroupingConfig, syncer EpisodeSyncer, token ...string) (*LiveRepository, error) {
+	auth, err := AuthFromEnv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load git credentials: %w", err)
+	}
+
+	activity, repoData, err := ingestRepository(ctx, repo, ingestOptions{token: passedToken(token), envTokens: true, allBranches: config.AllBranches, auth: auth})
+	if err != nil {
+		return nil, reportError(ctx, fmt.Errorf("failed to ingest repository: %w", err))
+	}
+
+	episodes := groupEpisodes(ctx, activity, config)
+	if err := episodesCreated(ctx, episodes); err != nil {
+		return nil, reportError(ctx, err)
+	}
+	activity.Episodes = episodes
+
+	return &LiveRepository{
+		repo:       repo,
+		config:     config,
+		auth:       auth,
+		token:      passedToken(token),
+		syncer:     syncer,
+		activity:   activity,
+		checkpoint: git.Checkpoint{Hash: repoData.HeadHash},
+		episodes:   episodes,
+	}, nil
+}
+
+// Episodes returns the current episodes
+func (l *LiveRepository) Episodes() []cluster.Episode {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	episodes := make([]cluster.Episode, len(l.episodes))
+	copy(episodes, l.episodes)
+	return episodes
+}
+
+// ApplyUpdate folds a webhook update into the repository's activity and regroups its episodes
+// Updates for other repositories and pushes to branches other than the default are ignored
+func (l *LiveRepository) ApplyUpdate(ctx context.Context, update webhook.Update) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.matches(update) {
+		return nil
+	}
+
+	var commits []git.Commit
+	var artifacts []cluster.Artifact
+	switch update.Event {
+	case webhook.EventPush:
+		if l.activity.DefaultBranch != "" && update.Ref != "refs/heads/"+l.activity.DefaultBranch {
+			return nil
+		}
+		pushed, err := l.applyPush(ctx, update.Forced)
+		if err != nil {
+			return err
+		}
+		commits = pushed
+	case webhook.EventIssue, webhook.EventPullRequest:
+		if update.Artifact == nil {
+			return nil
+		}
+		artifacts = []cluster.Artifact{mergeArtifactUpdate(l.activity.Artifacts, *update.Artifact)}
+	default:
+		return nil
+	}
+
+	_, _, err := l.apply(ctx, commits, artifacts)
+	return err
+}
+
+// Poll fetches the commits made since the last checkpoint and the artifacts changed since the last
+// fetch, regroups the episodes and returns the new or changed episodes and the IDs that disappeared
+// Remote repositories are fetched into the clone cache; local ones are read as they are on disk
+func (l *LiveRepository) Poll(ctx context.Context) ([]cluster.Episode, []string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fresh, repoData, err := ingestRepository(ctx, l.repo, ingestOptions{token: l.token, envTokens: true, since: l.checkpoint, allBranches: l.config.AllBranches, auth: l.auth})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to ingest new activity: %w", err)
+	}
+	l.activity.Tags = fresh.Tags
+	l.activity.FetchedAt = fresh.FetchedAt
+	if len(repoData.Commits) > 0 {
+		l.checkpoint = git.Checkpoint{Hash: repoData.HeadHash}
+	}
+
+	artifacts := changedArtifacts(l.activity.Artifacts, fresh.Artifacts)
+	if len(fresh.Commits) == 0 && len(artifacts) == 0 {
+		return nil, nil, nil
+	}
+	return l.apply(ctx, fresh.Commits, artifacts)
+}
+
+// WatchOptions configures LiveRepository.Watch
+type WatchOptions struct {
+	Interval time.Duration // Time between polls (default: DefaultWatchInterval)
+
+	// OnChange is called after each poll that changed episodes, with the new or changed episodes
+	// and the IDs that disappeared
+	// When it fails, the same changes are passed again with the next poll's, so a transient failure
+	// doesn't lose them; an *EpisodeError limits the retry to the episodes it names
+	OnChange func(ctx context.Context, changed []cluster.Episode, removed []string) error
+
+	// OnError is called when a poll or OnChange fails; watching goes on with the next poll
+	OnError func(err error)
+}
+
+// EpisodeError is returned by WatchOptions.OnChange when only some of the changed episodes failed,
+// e.g. narratives that hit a rate limit, so Watch passes just those again after the next poll
+type EpisodeError struct {
+	IDs []string // Episodes that failed
+	Err error
+}
+
+func (e *EpisodeError) Error() string {
+	return fmt.Sprintf("%d episodes failed: %v", len(e.IDs), e.Err)
+}
+
+func (e *EpisodeError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultWatchInterval is the time between polls when WatchOptions.Interval is zero
+const DefaultWatchInterval = 5 * time.Minute
+
+// Watch polls the repository until ctx is done, passing what each poll changed to OnChange
+func (l *LiveRepository) Watch(ctx context.Context, opts WatchOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pending pendingChanges
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Episodes a failed sync left behind are still passed on, as they have been regrouped
+		changed, removed, err := l.Poll(ctx)
+		l.reportWatchError(ctx, opts, err)
+		if opts.OnChange == nil {
+			continue
+		}
+		pending.add(changed, removed)
+		if pending.empty() {
+			continue
+		}
+
+		changed, removed = pending.take()
+		if err := opts.OnChange(ctx, changed, removed); err != nil {
+			pending.retry(changed, removed, err)
+			l.reportWatchError(ctx, opts, err)
+		}
+	}
+}
+
+// reportWatchError passes a failed poll or OnChange to OnError, unless watching was stopped
+func (l *LiveRepository) reportWatchError(ctx context.Context, opts WatchOptions, err error) {
+	if err != nil && ctx.Err() == nil && opts.OnError != nil {
+		opts.OnError(err)
+	}
+}
+
+// pendingChanges are the episode changes Watch has yet to pass to OnChange successfully
+type pendingChanges struct {
+	changed []cluster.Episode
+	removed []string
+}
+
+// add merges the changes of a poll, whose episodes replace pending ones with the same ID
+func (p *pendingChanges) add(changed []cluster.Episode, removed []string) {
+	gone := make(map[string]bool, len(removed)+len(changed))
+	for _, id := range removed {
+		gone[id] = true
+	}
+	for i := range changed {
+		gone[changed[i].ID] = true
+	}
+	kept := p.changed[:0]
+	for i := range p.changed {
+		if !gone[p.changed[i].ID] {
+			kept = append(kept, p.changed[i])
+		}
+	}
+	p.changed = append(kept, changed...)
+
+	reappeared := make(map[string]bool, len(changed))
+	for i := range changed {
+		reappeared[changed[i].ID] = true
+	}
+	keptRemoved := p.removed[:0]
+	for _, id := range p.removed {
+		if !reappeared[id] && !slices.Contains(removed, id) {
+			keptRemoved = append(keptRemoved, id)
+		}
+	}
+	p.removed = append(keptRemoved, removed...)
+}
+
+// empty reports whether there are no pending changes
+func (p *pendingChanges) empty() bool {
+	return len(p.changed) == 0 && len(p.removed) == 0
+}
+
+// take returns the pending changes and clears them
+func (p *pendingChanges) take() ([]cluster.Episode, []string) {
+	changed, removed := p.changed, p.removed
+	p.changed, p.removed = nil, nil
+	return changed, removed
+}
+
+// retry keeps the changes OnChange failed with err pending: the episodes an *EpisodeError names, or
+// every change for any other error
+func (p *pendingChanges) retry(changed []cluster.Episode, removed []string, err error) {
+	var episodeErr *EpisodeError
+	if !errors.As(err, &episodeErr) {
+		p.add(changed, removed)
+		return
+	}
+	var failed []cluster.Episode
+	for i := range changed {
+		if slices.Contains(episodeErr.IDs, changed[i].ID) {
+			failed = append(failed, changed[i])
+		}
+	}
+	p.add(failed, nil)
+}
+
+// apply regroups the episodes with new commits and artifacts and syncs the ones that changed
+func (l *LiveRepository) apply(ctx context.Context, commits []git.Commit, artifacts []cluster.Artifact) ([]cluster.Episode, []string, error) {
+	episodes := l.regroup(ctx, commits, artifacts)
+	changed, removed := diffEpisodes(l.episodes, episodes)
+	if err := episodesCreated(ctx, changed); err != nil {
+		return nil, nil, reportError(ctx, err)
+	}
+	l.episodes = episodes
+
+	if l.syncer != nil && (len(changed) > 0 || len(removed) > 0) {
+		if err := l.syncer.SyncEpisodes(ctx, changed, removed); err != nil {
+			return changed, removed, fmt.Errorf("failed to sync episodes: %w", err)
+		}
+	}
+	return changed, removed, nil
+}
+
+// changedArtifacts returns the fetched artifacts that are new or differ from the known ones
+func changedArtifacts(known, fetched []cluster.Artifact) []cluster.Artifact {
+	versions := make(map[string]string, len(known))
+	for i := range known {
+		versions[known[i].ID] = artifactVersion(&known[i])
+	}
+	var changed []cluster.Artifact
+	for i := range fetched {
+		if version, ok := versions[fetched[i].ID]; !ok || version != artifactVersion(&fetched[i]) {
+			changed = append(changed, fetched[i])
+		}
+	}
+	return changed
+}
+
+// artifactVersion identifies the version of an artifact by its update time, state and discussion
+func artifactVersion(artifact *cluster.Artifact) string {
+	return fmt.Sprintf("%s:%s:%d", artifact.UpdatedAt.UTC().Format("20060102150405"), artifact.State, len(artifact.Discussions))
+}
+
+// matches reports whether an update belongs to this repository
+func (l *LiveRepository) matches(update webhook.Update) bool {
+	if update.Owner == "" || l.activity.Owner == "" {
+		return true
+	}
+	return strings.EqualFold(update.Owner, l.activity.Owner) && strings.EqualFold(update.Repo, l.activity.RepositoryName)
+}
+
+// regroup folds new commits and artifacts into the activity and returns its episodes
+// Heuristic grouping only re-evaluates the episodes near the new activity; semantic grouping
+// compares commits across the whole history, so it regroups everything
+func (l *LiveRepository) regroup(ctx context.Context, commits []git.Commit, artifacts []cluster.Artifact) []cluster.Episode {
+	if l.config.Embedder == nil {
+		return l.activity.AppendAndRecluster(commits, artifacts, l.config)
+	}
+
+	l.activity.Commits = appendNewCommits(l.activity.Commits, commits)
+	for _, artifact := range artifacts {
+		l.activity.Artifacts = upsertArtifact(l.activity.Artifacts, artifact)
+	}
+	return groupEpisodes(ctx, l.activity, l.config)
+}
+
+// applyPush ingests commits added since the last checkpoint and returns them for regrouping
+// A forced push may have rewritten history, so the commit list is rebuilt from scratch and the
+// previous episodes are dropped so that everything is regrouped
+func (l *LiveRepository) applyPush(ctx context.Context, forced bool) ([]git.Commit, error) {
+	since := l.checkpoint
+	if forced {
+		since = git.Checkpoint{}
+	}
+
+	// Artifacts arrive through their own webhooks, so only git history is re-read here
+	fresh, repoData, err := ingestRepository(ctx, l.repo, ingestOptions{since: since, allBranches: l.config.AllBranches, auth: l.auth})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ingest pushed commits: %w", err)
+	}
+
+	if forced {
+		l.activity.Commits = fresh.Commits
+		l.activity.Episodes = nil
+	}
+	l.activity.Tags = fresh.Tags
+	l.activity.FetchedAt = fresh.FetchedAt
+	if len(repoData.Commits) > 0 || forced {
+		l.checkpoint = git.Checkpoint{Hash: repoData.HeadHash}
+	}
+	return fresh.Commits, nil
+}
+
+// appendNewCommits adds commits whose hashes are not already present
+func appendNewCommits(commits, added []git.Commit) []git.Commit {
+	seen := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		seen[commit.Hash] = true
+	}
+	for _, commit := range added {
+		if !seen[commit.Hash] {
+			seen[commit.Hash] = true
+			commits = append(commits, commit)
+		}
+	}
+	return commits
+}
+
+// upsertArtifact replaces the artifact with the same ID or appends it
+// Webhook payloads carry no comments, reviews, changed files or board placement, so those are
+// kept from the previously fetched artifact when the incoming one has none
+func upsertArtifact(artifacts []cluster.Artifact, incoming cluster.Artifact) []cluster.Artifact {
+	for i := range artifacts {
+		if artifacts[i].ID != incoming.ID {
+			continue
+		}
+
+		existing := artifacts[i]
+		if len(incoming.Discussions) == 0 {
+			incoming.Discussions = existing.Discussions
+		}
+		if len(incoming.Metadata.CommitSHAs) == 0 {
+			incoming.Metadata.CommitSHAs = existing.Metadata.CommitSHAs
+		}
+		if len(incoming.Metadata.Files) == 0 {
+			incoming.Metadata.Files = existing.Metadata.Files
+		}
+		if len(incoming.Metadata.Projects) == 0 {
+			incoming.Metadata.Projects = existing.Metadata.Projects
+		}
+		if len(incoming.Metadata.Builds) == 0 {
+			incoming.Metadata.Builds = existing.Metadata.Builds
+		}
+		artifacts[i] = incoming
+		return artifacts
+	}
+	return append(artifacts, incoming)
+}
+
+// mergeArtifactUpdate returns the incoming artifact with the fields its webhook payload lacks
+// filled in from the previously fetched one
+func mergeArtifactUpdate(artifacts []cluster.Artifact, incoming cluster.Artifact) cluster.Artifact {
+	merged := upsertArtifact(append([]cluster.Artifact(nil), artifacts...), incoming)
+	for _, artifact := range merged {
+		if artifact.ID == incoming.ID {
+			return artifact
+		}
+	}
+	return incoming
+}
+
+// diffEpisodes compares two groupings and returns the new or changed episodes and the IDs that disappeared
+func diffEpisodes(previous, current []cluster.Episode) ([]cluster.Episode, []string) {
+	before := make(map[string]string, len(previous))
+	for i := range previous {
+		before[previous[i].ID] = episodeFingerprint(&previous[i])
+	}
+
+	changed := make([]cluster.Episode, 0)
+	present := make(map[string]bool, len(current))
+	for i := range current {
+		present[current[i].ID] = true
+		if fingerprint, ok := before[current[i].ID]; !ok || fingerprint != episodeFingerprint(&current[i]) {
+			changed = append(changed, current[i])
+		}
+	}
+
+	removed := make([]string, 0)
+	for i := range previous {
+		if !present[previous[i].ID] {
+			removed = append(removed, previous[i].ID)
+		}
+	}
+	sort.Strings(removed)
+
+	return changed, removed
+}
+
+// episodeFingerprint identifies an episode's content by its commits and artifact versions
+func episodeFingerprint(ep *cluster.Episode) string {
+	var b strings.Builder
+	for _, commit := range ep.Commits {
+		b.WriteString(commit.Hash)
+		b.WriteByte(',')
+	}
+	b.WriteByte('|')
+	for _, artifact := range ep.Artifacts {
+		fmt.Fprintf(&b, "%s@%s,", artifact.ID, artifactVersion(&artifact))
+	}
+	return b.String()
+}
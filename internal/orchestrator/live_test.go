@@ -0,0 +1,298 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/ingest/github/webhook"
+	gogit "github.com/go-git/go-git/v6"
+)
+
+// recordingSyncer records the episodes passed to SyncEpisodes
+type recordingSyncer struct {
+	calls   int
+	changed []cluster.Episode
+	removed []string
+}
+
+func (s *recordingSyncer) SyncEpisodes(ctx context.Context, changed []cluster.Episode, removed []string) error {
+	s.calls++
+	s.changed = changed
+	s.removed = removed
+	return nil
+}
+
+func newTestLiveRepository(syncer EpisodeSyncer) *LiveRepository {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	activity := &cluster.RepositoryActivity{
+		Owner:          "Yates-Labs",
+		RepositoryName: "thunk",
+		Commits: []git.Commit{
+			{Hash: "aaaaaaa1", Message: "Add parser", Author: author, CommittedAt: base},
+			{Hash: "aaaaaaa2", Message: "Fix #12 in parser", Author: author, CommittedAt: base.Add(time.Hour)},
+			{Hash: "bbbbbbb1", Message: "Rewrite docs", Author: author, CommittedAt: base.Add(90 * 24 * time.Hour)},
+		},
+		Artifacts: []cluster.Artifact{{
+			ID:          "issue-12",
+			Number:      12,
+			Type:        cluster.ArtifactIssue,
+			State:       "open",
+			UpdatedAt:   base,
+			Discussions: []cluster.Discussion{{ID: "c1", Body: "Repro attached"}},
+		}},
+	}
+
+	config := cluster.DefaultGroupingConfig()
+	return &LiveRepository{
+		config:   config,
+		syncer:   syncer,
+		activity: activity,
+		episodes: activity.GroupIntoEpisodes(config),
+	}
+}
+
+func TestLiveRepository_ApplyArtifactUpdate(t *testing.T) {
+	syncer := &recordingSyncer{}
+	live := newTestLiveRepository(syncer)
+	if len(live.Episodes()) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(live.Episodes()))
+	}
+
+	closed := cluster.Artifact{ID: "issue-12", Number: 12, Type: cluster.ArtifactIssue, State: "closed",
+		UpdatedAt: time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)}
+	err := live.ApplyUpdate(context.Background(), webhook.Update{
+		Event: webhook.EventIssue, Owner: "yates-labs", Repo: "Thunk", Artifact: &closed,
+	})
+	if err != nil {
+		t.Fatalf("ApplyUpdate failed: %v", err)
+	}
+
+	first := live.Episodes()[0].ID
+	if syncer.calls != 1 || len(syncer.changed) != 1 || syncer.changed[0].ID != first || len(syncer.removed) != 0 {
+		t.Fatalf("Expected only %s to be resynced, got %d calls with %+v removed %v", first, syncer.calls, syncer.changed, syncer.removed)
+	}
+	artifact := live.Episodes()[0].Artifacts[0]
+	if artifact.State != "closed" {
+		t.Errorf("Expected the issue to be closed, got %q", artifact.State)
+	}
+	if len(artifact.Discussions) != 1 {
+		t.Errorf("Expected previously fetched discussions to be kept, got %d", len(artifact.Discussions))
+	}
+}
+
+func TestLiveRepository_IgnoresOtherRepositories(t *testing.T) {
+	syncer := &recordingSyncer{}
+	live := newTestLiveRepository(syncer)
+
+	other := cluster.Artifact{ID: "issue-12", Number: 12, Type: cluster.ArtifactIssue, State: "closed"}
+	if err := live.ApplyUpdate(context.Background(), webhook.Update{
+		Event: webhook.EventIssue, Owner: "someone", Repo: "else", Artifact: &other,
+	}); err != nil {
+		t.Fatalf("ApplyUpdate failed: %v", err)
+	}
+	if syncer.calls != 0 || live.activity.Artifacts[0].State != "open" {
+		t.Error("Expected an update for another repository to be ignored")
+	}
+}
+
+func TestLiveRepository_Poll(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", base)
+
+	syncer := &recordingSyncer{}
+	live, err := NewLiveRepository(ctx, dir, cluster.DefaultGroupingConfig(), syncer)
+	if err != nil {
+		t.Fatalf("NewLiveRepository failed: %v", err)
+	}
+
+	// Nothing new since the repository was ingested
+	changed, removed, err := live.Poll(ctx)
+	if err != nil || len(changed) != 0 || len(removed) != 0 || syncer.calls != 0 {
+		t.Fatalf("Expected no changes, got %+v and %v (error %v)", changed, removed, err)
+	}
+
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", base.Add(time.Hour))
+	changed, _, err = live.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(changed) != 1 || len(changed[0].Commits) != 2 || syncer.calls != 1 {
+		t.Errorf("Expected the episode to gain the new commit and be synced, got %+v", changed)
+	}
+	if commits := countEpisodeCommits(live.Episodes()); commits != 2 {
+		t.Errorf("Expected 2 commits across episodes, got %d", commits)
+	}
+}
+
+func TestLiveRepository_Watch(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", base)
+
+	live, err := NewLiveRepository(context.Background(), dir, cluster.DefaultGroupingConfig(), nil)
+	if err != nil {
+		t.Fatalf("NewLiveRepository failed: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", base.Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var changes [][]cluster.Episode
+	err = live.Watch(ctx, WatchOptions{
+		Interval: 10 * time.Millisecond,
+		OnChange: func(ctx context.Context, changed []cluster.Episode, removed []string) error {
+			changes = append(changes, changed)
+			cancel()
+			return nil
+		},
+		OnError: func(err error) { t.Errorf("Unexpected error: %v", err) },
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if len(changes) != 1 || len(changes[0]) != 1 || len(changes[0][0].Commits) != 2 {
+		t.Errorf("Expected one change with the new commit, got %+v", changes)
+	}
+}
+
+func TestLiveRepository_WatchRetriesFailedEpisodes(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", base)
+
+	live, err := NewLiveRepository(context.Background(), dir, cluster.DefaultGroupingConfig(), nil)
+	if err != nil {
+		t.Fatalf("NewLiveRepository failed: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", base.Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var changes [][]cluster.Episode
+	var failures int
+	err = live.Watch(ctx, WatchOptions{
+		Interval: 10 * time.Millisecond,
+		OnChange: func(ctx context.Context, changed []cluster.Episode, removed []string) error {
+			changes = append(changes, changed)
+			if len(changes) == 1 {
+				return &EpisodeError{IDs: []string{changed[0].ID}, Err: errors.New("rate limited")}
+			}
+			cancel()
+			return nil
+		},
+		OnError: func(err error) { failures++ },
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// The failed episode is passed again although the next poll found nothing new
+	if len(changes) != 2 || len(changes[1]) != 1 || changes[1][0].ID != changes[0][0].ID || failures != 1 {
+		t.Errorf("Expected the failed episode retried once, got %d calls and %d failures", len(changes), failures)
+	}
+}
+
+func TestPendingChanges(t *testing.T) {
+	episode := func(id string, commits int) cluster.Episode {
+		return cluster.Episode{ID: id, Commits: make([]git.Commit, commits)}
+	}
+
+	var pending pendingChanges
+	pending.add([]cluster.Episode{episode("E1", 1), episode("E2", 1)}, []string{"E3"})
+
+	// A failure without episode details keeps every change
+	changed, removed := pending.take()
+	if !pending.empty() {
+		t.Fatal("Expected take to clear the pending changes")
+	}
+	pending.retry(changed, removed, errors.New("publish failed"))
+	changed, removed = pending.take()
+	if len(changed) != 2 || len(removed) != 1 {
+		t.Fatalf("Expected every change kept, got %d changed and %v removed", len(changed), removed)
+	}
+
+	// An episode error keeps only the episodes it names
+	pending.retry(changed, removed, fmt.Errorf("narratives: %w", &EpisodeError{IDs: []string{"E2"}, Err: errors.New("rate limited")}))
+	if len(pending.changed) != 1 || pending.changed[0].ID != "E2" || len(pending.removed) != 0 {
+		t.Fatalf("Expected only E2 pending, got %+v and %v", pending.changed, pending.removed)
+	}
+
+	// Later polls replace pending episodes and drop removed ones
+	pending.add([]cluster.Episode{episode("E2", 3), episode("E4", 1)}, nil)
+	if len(pending.changed) != 2 || len(pending.changed[0].Commits) != 3 {
+		t.Errorf("Expected E2 replaced by its regrouped version, got %+v", pending.changed)
+	}
+	pending.add(nil, []string{"E2"})
+	changed, removed = pending.take()
+	if len(changed) != 1 || changed[0].ID != "E4" || len(removed) != 1 || removed[0] != "E2" {
+		t.Errorf("Expected E4 changed and E2 removed, got %+v and %v", changed, removed)
+	}
+}
+
+func TestChangedArtifacts(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	known := []cluster.Artifact{
+		{ID: "issue-1", State: "open", UpdatedAt: updated},
+		{ID: "issue-2", State: "open", UpdatedAt: updated},
+	}
+	fetched := []cluster.Artifact{
+		{ID: "issue-1", State: "open", UpdatedAt: updated},
+		{ID: "issue-2", State: "closed", UpdatedAt: updated.Add(time.Hour)},
+		{ID: "issue-3", State: "open", UpdatedAt: updated},
+	}
+	changed := changedArtifacts(known, fetched)
+	if len(changed) != 2 || changed[0].ID != "issue-2" || changed[1].ID != "issue-3" {
+		t.Errorf("Expected the closed and new issues, got %+v", changed)
+	}
+}
+
+func TestDiffEpisodes(t *testing.T) {
+	commit := func(hash string) git.Commit { return git.Commit{Hash: hash} }
+	previous := []cluster.Episode{
+		{ID: "E1", Commits: []git.Commit{commit("a")}},
+		{ID: "E2", Commits: []git.Commit{commit("b")}},
+		{ID: "E3", Commits: []git.Commit{commit("c")}},
+	}
+	current := []cluster.Episode{
+		{ID: "E1", Commits: []git.Commit{commit("a")}},
+		{ID: "E2", Commits: []git.Commit{commit("b"), commit("c")}},
+	}
+
+	changed, removed := diffEpisodes(previous, current)
+	if len(changed) != 1 || changed[0].ID != "E2" {
+		t.Errorf("Expected only E2 changed, got %+v", changed)
+	}
+	if len(removed) != 1 || removed[0] != "E3" {
+		t.Errorf("Expected E3 removed, got %v", removed)
+	}
+}
+
+func TestAppendNewCommits(t *testing.T) {
+	commits := appendNewCommits(
+		[]git.Commit{{Hash: "a"}, {Hash: "b"}},
+		[]git.Commit{{Hash: "b"}, {Hash: "c"}},
+	)
+	if len(commits) != 3 || commits[2].Hash != "c" {
+		t.Errorf("Expected duplicates skipped, got %+v", commits)
+	}
+}
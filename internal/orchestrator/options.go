@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+// Clusterer groups episodes into the story arcs project-level narratives are organized by
+// Without one, the pipeline groups them with cluster.GroupIntoArcs and RAGConfig.Arcs
+type Clusterer interface {
+	GroupIntoArcs(ctx context.Context, episodes []cluster.Episode) ([]cluster.Arc, error)
+}
+
+// PipelineOption replaces a backend NewRAGPipeline would otherwise construct from its config, so
+// alternative backends and test doubles can be injected
+type PipelineOption func(*pipelineBackends)
+
+// pipelineBackends are the backends injected into NewRAGPipeline; nil ones are constructed
+type pipelineBackends struct {
+	embedder    rag.Embedder
+	vectorStore rag.VectorStore
+	sharedStore bool // vectorStore is closed by whoever opened it, not the pipeline
+	llm         narrative.LLM
+	clusterer   Clusterer
+}
+
+// WithEmbedder embeds episodes and queries with embedder instead of the configured provider
+// Its vector dimension replaces RAGConfig.EmbedderDimension, so the vector store's schema is checked
+// against it
+func WithEmbedder(embedder rag.Embedder) PipelineOption {
+	return func(b *pipelineBackends) {
+		b.embedder = embedder
+	}
+}
+
+// WithVectorStore indexes and searches episodes in store instead of the configured one
+// The pipeline takes ownership of the store: closing the pipeline closes it
+func WithVectorStore(store rag.VectorStore) PipelineOption {
+	return func(b *pipelineBackends) {
+		b.vectorStore = store
+	}
+}
+
+// WithSharedVectorStore is WithVectorStore for a store other pipelines use too, such as one local
+// store file shared by concurrent jobs: closing the pipeline leaves the store open, for its opener
+// to close once every pipeline is done with it
+func WithSharedVectorStore(store rag.VectorStore) PipelineOption {
+	return func(b *pipelineBackends) {
+		b.vectorStore = store
+		b.sharedStore = true
+	}
+}
+
+// WithLLM writes narratives, summaries of large episodes and episode categories with llm instead
+// of the configured model; LLMConfig.Model still names the model narratives are recorded as
+// written by, and fallback models are still created from the config
+func WithLLM(llm narrative.LLM) PipelineOption {
+	return func(b *pipelineBackends) {
+		b.llm = llm
+	}
+}
+
+// WithClusterer groups episodes into story arcs with clusterer instead of RAGConfig.Arcs
+func WithClusterer(clusterer Clusterer) PipelineOption {
+	return func(b *pipelineBackends) {
+		b.clusterer = clusterer
+	}
+}
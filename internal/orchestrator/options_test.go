@@ -0,0 +1,203 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// fixedClusterer puts every episode into a single arc
+type fixedClusterer struct {
+	calls int
+}
+
+func (c *fixedClusterer) GroupIntoArcs(ctx context.Context, episodes []cluster.Episode) ([]cluster.Arc, error) {
+	c.calls++
+	return []cluster.Arc{{ID: "A1", Title: "Retry overhaul", Episodes: episodes}}, nil
+}
+
+func TestNewRAGPipeline_Options(t *testing.T) {
+	ctx := context.Background()
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	llm := &recordingLLM{}
+	clusterer := &fixedClusterer{}
+
+	// The default config names OpenAI and Milvus, neither of which is reached with injected backends
+	config := DefaultRAGConfig()
+	config.EmbedderDimension = 64
+	pipeline, err := NewRAGPipeline(ctx, config, WithEmbedder(embedder), WithVectorStore(store), WithLLM(llm), WithClusterer(clusterer))
+	if err != nil {
+		t.Fatalf("NewRAGPipeline failed: %v", err)
+	}
+	defer pipeline.Close()
+
+	first, second := *summaryTestEpisode(2), *summaryTestEpisode(1)
+	second.ID = "E2"
+	episodes := []cluster.Episode{first, second}
+	if err := pipeline.IndexEpisodes(ctx, episodes); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+	if found, _ := store.Query(ctx, "", []string{"E1", "E2"}); !found["E1"] || !found["E2"] {
+		t.Errorf("Expected both episodes in the injected store, got %v", found)
+	}
+
+	narr, err := pipeline.GenerateProjectNarrativeRAG(ctx, "How did retries change?", episodes)
+	if err != nil {
+		t.Fatalf("GenerateProjectNarrativeRAG failed: %v", err)
+	}
+	if narr.Text != "Summary." || len(llm.prompts) != 1 {
+		t.Fatalf("Expected the narrative written by the injected LLM, got %q after %d calls", narr.Text, len(llm.prompts))
+	}
+	if clusterer.calls != 1 || !strings.Contains(llm.prompts[0], "A1: Retry overhaul") {
+		t.Errorf("Expected the prompt organized by the injected clusterer's arcs, got:\n%s", llm.prompts[0])
+	}
+}
+
+// closingStore records whether the pipeline closed it
+type closingStore struct {
+	*rag.LocalStore
+	closed bool
+}
+
+func (s *closingStore) Close() error {
+	s.closed = true
+	return s.LocalStore.Close()
+}
+
+// probedEmbedder hides the embedder it wraps, so its dimension can only be probed
+type probedEmbedder struct {
+	rag.Embedder
+}
+
+func TestNewRAGPipeline_InjectedEmbedderDimension(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultRAGConfig()
+	config.EmbedderDimension = 32
+	config.NarrativeCacheDir = ""
+
+	// The store matches the configured dimension, but not the injected embedder's
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{Dimension: 32})
+	_, err := NewRAGPipeline(ctx, config, WithEmbedder(rag.NewHashEmbedder(64)), WithVectorStore(store), WithLLM(&recordingLLM{}))
+	if !errors.Is(err, rag.ErrOutdatedSchema) {
+		t.Errorf("Expected the store checked against the injected embedder's dimension, got %v", err)
+	}
+
+	if dimension, err := embedderDimension(ctx, probedEmbedder{rag.NewHashEmbedder(48)}); err != nil || dimension != 48 {
+		t.Errorf("Expected a probed dimension of 48, got %d (error %v)", dimension, err)
+	}
+}
+
+func TestNewRAGPipeline_ClosesStoreOnError(t *testing.T) {
+	// A narrative cache directory under a file can't be created, which fails after the store is open
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	config := DefaultRAGConfig()
+	config.EmbedderDimension = 64
+	config.NarrativeCacheDir = filepath.Join(file, "cache")
+
+	localStore, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	store := &closingStore{LocalStore: localStore}
+	if _, err := NewRAGPipeline(context.Background(), config, WithEmbedder(rag.NewHashEmbedder(64)), WithVectorStore(store), WithLLM(&recordingLLM{})); err == nil {
+		t.Fatal("Expected NewRAGPipeline to fail")
+	}
+	if !store.closed {
+		t.Error("Expected the vector store closed when the pipeline can't be created")
+	}
+}
+
+func TestNewRAGPipeline_SharedVectorStore(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultRAGConfig()
+	config.EmbedderDimension = 64
+	config.NarrativeCacheDir = ""
+
+	localStore, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	store := &closingStore{LocalStore: localStore}
+	for _, repository := range []string{"owner/app", "fork/app"} {
+		config.Repository = repository
+		pipeline, err := NewRAGPipeline(ctx, config, WithEmbedder(rag.NewHashEmbedder(64)), WithSharedVectorStore(store), WithLLM(&recordingLLM{}))
+		if err != nil {
+			t.Fatalf("NewRAGPipeline failed: %v", err)
+		}
+		if err := pipeline.IndexEpisodes(ctx, []cluster.Episode{*summaryTestEpisode(1)}); err != nil {
+			t.Fatalf("IndexEpisodes failed: %v", err)
+		}
+		pipeline.Close()
+	}
+	if store.closed {
+		t.Error("Expected closing the pipelines to leave the shared store open")
+	}
+	for _, repository := range []string{"owner/app", "fork/app"} {
+		if found, _ := store.Query(ctx, repository, []string{"E1"}); !found["E1"] {
+			t.Errorf("Expected E1 indexed for %s in the shared store", repository)
+		}
+	}
+
+	// A pipeline that fails after taking the store, on a cache directory under a file, leaves it open too
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	config.NarrativeCacheDir = filepath.Join(file, "cache")
+	if _, err := NewRAGPipeline(ctx, config, WithEmbedder(rag.NewHashEmbedder(64)), WithSharedVectorStore(store), WithLLM(&recordingLLM{})); err == nil {
+		t.Fatal("Expected NewRAGPipeline to fail")
+	}
+	if store.closed {
+		t.Error("Expected a failed pipeline to leave the shared store open")
+	}
+}
+
+func TestGenerateMultipleNarrativesRAG_Report(t *testing.T) {
+	embedder, _ := rag.NewEmbedder(rag.EmbedderProviderFake, "", 64)
+	store, _ := rag.NewLocalStore(rag.LocalStoreConfig{})
+	config := DefaultRAGConfig()
+	config.EmbedderDimension = 64
+	config.NarrativeCacheDir = ""
+	pipeline, err := NewRAGPipeline(context.Background(), config, WithEmbedder(embedder), WithVectorStore(store), WithLLM(&recordingLLM{}))
+	if err != nil {
+		t.Fatalf("NewRAGPipeline failed: %v", err)
+	}
+	defer pipeline.Close()
+
+	// A validator rejecting one narrative leaves its episode out, recorded in the run's report
+	events := NewEvents()
+	events.Subscribe(Hooks{OnNarrativeGenerated: func(ctx context.Context, narr *narrative.Narrative) error {
+		if narr.EpisodeID == "E2" {
+			return errors.New("too short")
+		}
+		return nil
+	}})
+	runReport := report.New()
+	ctx := report.With(WithEvents(context.Background(), events), runReport)
+
+	first, second := *summaryTestEpisode(2), *summaryTestEpisode(1)
+	second.ID = "E2"
+	episodes := []cluster.Episode{first, second}
+	if err := pipeline.IndexEpisodes(ctx, episodes); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+	narratives, err := pipeline.GenerateMultipleNarrativesRAG(ctx, episodes)
+	if err != nil {
+		t.Fatalf("GenerateMultipleNarrativesRAG failed: %v", err)
+	}
+	if len(narratives) != 1 {
+		t.Fatalf("Expected 1 narrative, got %d", len(narratives))
+	}
+	if counts := runReport.Counts()[report.StageNarratives]; counts != (report.Counts{Succeeded: 1, Skipped: 1}) {
+		t.Errorf("Expected 1 narrative generated and 1 skipped, got %+v", counts)
+	}
+	if entries := runReport.Entries(); len(entries) != 1 || entries[0].Item != "E2" || !entries[0].Skipped {
+		t.Errorf("Expected E2 reported as skipped, got %+v", entries)
+	}
+}
@@ -2,44 +2,61 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/Yates-Labs/thunk/internal/adapter"
 	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/ci"
 	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/ingest/github"
+	"github.com/Yates-Labs/thunk/internal/ingest/jira"
+	"github.com/Yates-Labs/thunk/internal/ingest/jsonfile"
+	"github.com/Yates-Labs/thunk/internal/ingest/linear"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/redact"
+	"github.com/Yates-Labs/thunk/internal/report"
+	"github.com/Yates-Labs/thunk/internal/state"
+	gogit "github.com/go-git/go-git/v6"
 )
 
 // AnalyzeRepository analyzes a Git repository and returns grouped episodes
 // The repo parameter can be either a local path or a remote URL
 // Uses default grouping configuration
-// Token is automatically loaded from GITHUB_TOKEN environment variable if not provided
+// Token is automatically loaded from GITHUB_TOKEN (GITLAB_TOKEN for GitLab) environment variable if not provided
 func AnalyzeRepository(ctx context.Context, repo string, token ...string) ([]cluster.Episode, error) {
 	config := cluster.DefaultGroupingConfig()
 	return AnalyzeRepositoryWithConfig(ctx, repo, config, token...)
 }
 
 // AnalyzeRepositoryWithConfig analyzes a repository with custom grouping configuration
-// Token is automatically loaded from GITHUB_TOKEN environment variable if not provided
+// Token is automatically loaded from GITHUB_TOKEN (GITLAB_TOKEN for GitLab) environment variable if not provided
+// Clone credentials for private repositories are read from the environment (see AuthFromEnv)
 func AnalyzeRepositoryWithConfig(ctx context.Context, repo string, config cluster.GroupingConfig, token ...string) ([]cluster.Episode, error) {
+	auth, err := AuthFromEnv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load git credentials: %w", err)
+	}
+
+	return AnalyzeRepositoryWithAuth(ctx, repo, config, auth, token...)
+}
+
+// AnalyzeRepositoryWithAuth analyzes a repository, cloning it with the given credentials
+// Token is automatically loaded from GITHUB_TOKEN (GITLAB_TOKEN for GitLab) environment variable if not provided
+func AnalyzeRepositoryWithAuth(ctx context.Context, repo string, config cluster.GroupingConfig, auth git.AuthOptions, token ...string) ([]cluster.Episode, error) {
 	// Check for context cancellation
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("context cancelled before analysis: %w", err)
 	}
-
-	// Extract token: use provided token, otherwise fall back to env var
-	var apiToken string
-	if len(token) > 0 && token[0] != "" {
-		apiToken = token[0]
-	} else {
-		apiToken = os.Getenv("GITHUB_TOKEN")
-	}
+	ctx = logging.With(logging.WithRun(ctx), logging.RepositoryKey, repo)
 
 	// Step 1: Ingest repository data
-	activity, err := ingestRepository(ctx, repo, apiToken)
+	activity, _, err := ingestRepository(ctx, repo, ingestOptions{token: passedToken(token), envTokens: true, allBranches: config.AllBranches, auth: auth})
 	if err != nil {
-		return nil, fmt.Errorf("failed to ingest repository: %w", err)
+		return nil, reportError(ctx, fmt.Errorf("failed to ingest repository: %w", err))
 	}
 
 	// Check for context cancellation after ingestion
@@ -48,15 +65,121 @@ func AnalyzeRepositoryWithConfig(ctx context.Context, repo string, config cluste
 	}
 
 	// Step 2: Group commits into episodes
-	episodes := activity.GroupIntoEpisodes(config)
+	episodes := groupEpisodes(ctx, activity, config)
+	if err := episodesCreated(ctx, episodes); err != nil {
+		return nil, reportError(ctx, err)
+	}
+
+	return episodes, nil
+}
+
+// AnalyzeRepositoryWithState analyzes only the activity added since the last run, keeping the
+// repository's state in store
+// Only commits made after the last one ingested, and issues and pull requests updated since the
+// last sync, are fetched. The state records both once the new activity has been processed, and the
+// repository's episode version is bumped when a run produces episodes that differ from the last
+func AnalyzeRepositoryWithState(ctx context.Context, repo string, store state.Store, config cluster.GroupingConfig, token ...string) ([]cluster.Episode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled before analysis: %w", err)
+	}
+	ctx = logging.With(logging.WithRun(ctx), logging.RepositoryKey, repo)
+
+	repoState, err := store.Load(ctx, RepositoryKey(repo))
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := AuthFromEnv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load git credentials: %w", err)
+	}
+
+	activity, repoData, err := ingestRepository(ctx, repo, ingestOptions{
+		token:          passedToken(token),
+		envTokens:      true,
+		since:          git.Checkpoint{Hash: repoState.LastCommitHash},
+		artifactsSince: repoState.SyncedAt,
+		allBranches:    config.AllBranches,
+		auth:           auth,
+	})
+	if err != nil {
+		return nil, reportError(ctx, fmt.Errorf("failed to ingest repository: %w", err))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled after ingestion: %w", err)
+	}
+
+	episodes := groupEpisodes(ctx, activity, config)
+	if err := episodesCreated(ctx, episodes); err != nil {
+		return nil, reportError(ctx, err)
+	}
+
+	// Only advance the state once the new activity has been processed
+	recordAnalysis(repoState, activity, repoData, episodes)
+	if err := store.Save(ctx, repoState); err != nil {
+		return nil, err
+	}
 
 	return episodes, nil
 }
 
+// recordAnalysis advances a repository's state past the activity an analysis ingested
+func recordAnalysis(repoState *state.RepositoryState, activity *cluster.RepositoryActivity, repoData *git.Repository, episodes []cluster.Episode) {
+	repoState.AnalyzedAt = time.Now()
+	if len(repoData.Commits) > 0 {
+		repoState.LastCommitHash = repoData.HeadHash
+		repoState.LastCommitTime = latestCommitTime(repoData.Commits)
+	}
+	if !activity.SyncedAt.IsZero() {
+		repoState.SyncedAt = activity.SyncedAt
+	}
+	// A run that found nothing new leaves the last episode set current
+	if len(episodes) > 0 {
+		ids := make([]string, len(episodes))
+		for i := range episodes {
+			ids[i] = episodes[i].ID
+		}
+		repoState.RecordEpisodes(ids)
+	}
+}
+
+// latestCommitTime returns the most recent commit time in a slice of commits
+func latestCommitTime(commits []git.Commit) time.Time {
+	var latest time.Time
+	for _, commit := range commits {
+		if commit.CommittedAt.After(latest) {
+			latest = commit.CommittedAt
+		}
+	}
+	return latest
+}
+
+// passedToken returns the API token passed to an analysis, or "" when none was, so
+// platformToken falls back to the token of the repository's platform
+func passedToken(token []string) string {
+	if len(token) > 0 {
+		return token[0]
+	}
+	return ""
+}
+
+// ingestOptions controls what ingestRepository reads and which credentials it uses
+type ingestOptions struct {
+	token          string          // API token passed for the repository
+	envTokens      bool            // Fall back to the platform's token from the environment (see platformToken)
+	since          git.Checkpoint  // Only ingest commits made after this checkpoint (zero = all)
+	artifactsSince time.Time       // Only fetch issues and pull requests updated since (zero = all)
+	revision       string          // Ingest the history of this commit instead of HEAD's (empty = HEAD)
+	allBranches    bool            // Ingest the commits of every branch, with their branch topology
+	auth           git.AuthOptions // Credentials for when the repository has to be cloned
+}
+
 // ingestRepository handles the ingestion of repository data
 // Supports both local paths and remote URLs
-// Detects platform from URL and fetches additional artifacts if token is provided
-func ingestRepository(ctx context.Context, repo, token string) (*cluster.RepositoryActivity, error) {
+// Detects platform from URL and fetches additional artifacts if a token is passed or, with
+// envTokens, the platform has one in the environment
+func ingestRepository(ctx context.Context, repo string, options ingestOptions) (*cluster.RepositoryActivity, *git.Repository, error) {
 	// Detect platform from URL or path
 	platform, owner, repoName := detectPlatform(repo)
 
@@ -64,20 +187,49 @@ func ingestRepository(ctx context.Context, repo, token string) (*cluster.Reposit
 	gitRepo, err := git.OpenRepository(repo)
 	if err != nil {
 		// If local open fails, try cloning from remote URL
-		gitRepo, err = git.CloneRepository(repo)
+		gitRepo, err = cloneRemoteRepository(ctx, repo, options.auth)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open or clone repository '%s': %w", repo, err)
+			return nil, nil, fmt.Errorf("failed to open or clone repository '%s': %w", repo, err)
 		}
 	}
 
-	// Parse repository with reasonable defaults
-	// maxCommits: 0 = unlimited, includePatch: false for performance
-	repoData, err := git.ParseRepository(gitRepo, repo, 0, false)
+	// Parse repository with reasonable defaults (unlimited commits, no patches for performance)
+	// Previously parsed commits are reused from the on-disk commit cache
+	opts := git.DefaultParseOptions()
+	opts.Cache = openCommitCache(ctx)
+	opts.Revision = options.revision
+	opts.AllBranches = options.allBranches
+
+	repoData, err := git.ParseRepositorySinceWithOptions(ctx, gitRepo, repo, options.since, opts)
+	if errors.Is(err, git.ErrCheckpointNotFound) {
+		// History was rewritten since the last run; fall back to a full parse
+		logging.FromContext(ctx).Warn("Checkpoint not found, re-analyzing full history", "checkpoint", options.since.Hash)
+		repoData, err = git.ParseRepositoryWithOptions(ctx, gitRepo, repo, opts)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse repository: %w", err)
+	}
+
+	// Merge author aliases using the repository's .mailmap
+	mailmap, err := git.ReadRepositoryMailmap(gitRepo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse repository: %w", err)
+		logging.FromContext(ctx).Warn("Failed to read mailmap", "error", err)
+	} else {
+		repoData.Commits = git.ResolveIdentities(repoData.Commits, mailmap)
+	}
+
+	// Verify commit signatures when trusted keys are configured
+	verifier, err := SignatureVerifierFromEnv()
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to load signature verification keys", "error", err)
+	} else if verifier != nil {
+		if err := git.VerifySignatures(ctx, gitRepo, repoData.Commits, verifier); err != nil {
+			return nil, nil, fmt.Errorf("failed to verify commit signatures: %w", err)
+		}
 	}
 
 	// If owner/repo not detected from URL, try to get from git remotes
+	platformURL := repo
 	if owner == "" || repoName == "" {
 		remoteURL := git.GetRemoteURL(gitRepo, "origin")
 		if remoteURL != "" {
@@ -86,6 +238,7 @@ func ingestRepository(ctx context.Context, repo, token string) (*cluster.Reposit
 				platform = detectedPlatform
 				owner = detectedOwner
 				repoName = detectedRepo
+				platformURL = remoteURL
 			}
 		}
 	}
@@ -98,32 +251,229 @@ func ingestRepository(ctx context.Context, repo, token string) (*cluster.Reposit
 		Owner:          owner,
 		DefaultBranch:  repoData.HeadBranch,
 		Commits:        repoData.Commits,
+		Tags:           repoData.Tags,
 		Artifacts:      []cluster.Artifact{},
 		FetchedAt:      time.Now(),
 	}
 
 	// Enrich with platform-specific artifacts if token provided
+	token := options.token
+	if options.envTokens {
+		token = platformToken(platform, platformURL, token)
+	}
 	if token != "" && owner != "" && repoName != "" {
-		if err := enrichWithArtifacts(ctx, activity, token, owner, repoName); err != nil {
+		if err := enrichWithArtifacts(ctx, activity, platformURL, token, owner, repoName, options.artifactsSince); err != nil {
 			// Log error but don't fail - continue with just git data
-			fmt.Printf("Warning: failed to fetch artifacts from %s: %v\n", platform, err)
+			logging.FromContext(ctx).Warn("Failed to fetch artifacts", "platform", platform, "error", err)
+			report.FromContext(ctx).Skip(report.StageArtifacts, string(platform), err)
+		} else {
+			activity.SyncedAt = activity.FetchedAt
+		}
+	}
+
+	// Tickets come from a separate tracker, whatever platform hosts the code
+	if project := os.Getenv(jira.ProjectEnv); project != "" {
+		if err := enrichFromSource(ctx, activity, adapter.NewJiraAdapter("", ""), project); err != nil {
+			logging.FromContext(ctx).Warn("Failed to fetch tickets", "platform", cluster.PlatformJira, "error", err)
+			report.FromContext(ctx).Skip(report.StageArtifacts, string(cluster.PlatformJira), err)
+		}
+	}
+	if team := os.Getenv(linear.TeamEnv); team != "" {
+		if err := enrichFromSource(ctx, activity, adapter.NewLinearAdapter(), team); err != nil {
+			logging.FromContext(ctx).Warn("Failed to fetch tickets", "platform", cluster.PlatformLinear, "error", err)
+			report.FromContext(ctx).Skip(report.StageArtifacts, string(cluster.PlatformLinear), err)
+		}
+	}
+
+	// Exported artifacts of trackers without an adapter
+	if path := os.Getenv(jsonfile.PathEnv); path != "" {
+		if err := enrichFromSource(ctx, activity, adapter.NewFileAdapter(path), ""); err != nil {
+			logging.FromContext(ctx).Warn("Failed to import artifacts", "path", path, "error", err)
+			report.FromContext(ctx).Skip(report.StageArtifacts, path, err)
+		}
+	}
+
+	// Link commits and pull requests by number now that every artifact source has been merged in
+	if links := activity.LinkPullRequests(); links > 0 {
+		logging.FromContext(ctx).Debug("Linked commits to pull requests", "links", links)
+	}
+
+	// CI results attach to the pull and merge requests they built, so they run after every artifact source
+	for _, provider := range ci.ProvidersFromEnv() {
+		if err := enrichWithBuilds(ctx, activity, provider); err != nil {
+			logging.FromContext(ctx).Warn("Failed to fetch builds", "provider", provider.Name(), "error", err)
+			report.FromContext(ctx).Skip(report.StageBuilds, provider.Name(), err)
 		}
 	}
 
-	return activity, nil
+	return activity, repoData, nil
+}
+
+// SemanticGroupingConfig returns the default grouping config with an OpenAI embedder for semantic grouping
+// Commit clustering compares commits with each other only, so a smaller model than the index's suffices
+// Commit texts are redacted by policy before they are embedded
+func SemanticGroupingConfig(policy redact.Policy) (cluster.GroupingConfig, error) {
+	config := cluster.DefaultGroupingConfig()
+	redactor, err := redact.New(policy)
+	if err != nil {
+		return config, err
+	}
+	embedder, err := rag.NewOpenAIEmbedder("text-embedding-3-small", 1536)
+	if err != nil {
+		return config, fmt.Errorf("failed to create embedder: %w", err)
+	}
+	config.Embedder = redactor.Embedder(rag.NewClusterEmbedder(embedder))
+	return config, nil
+}
+
+// groupEpisodes groups an activity's commits, semantically when the config carries an embedder
+// Falls back to heuristic grouping if embedding fails
+func groupEpisodes(ctx context.Context, activity *cluster.RepositoryActivity, config cluster.GroupingConfig) []cluster.Episode {
+	if config.Embedder != nil {
+		episodes, err := activity.GroupIntoEpisodesSemantic(ctx, config.Embedder, config)
+		if err == nil {
+			return episodes
+		}
+		logging.FromContext(ctx).Warn("Semantic grouping failed, using heuristic grouping", "error", err)
+		report.FromContext(ctx).Fail(report.StageGrouping, activity.RepositoryName, err)
+	}
+	return activity.GroupIntoEpisodes(config)
+}
+
+// openCommitCache opens the default parsed-commit cache
+// Returns nil (no caching) if the cache directory is unavailable
+func openCommitCache(ctx context.Context) *git.CommitCache {
+	dir, err := git.DefaultCommitCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	cache, err := git.NewCommitCache(dir)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Commit cache disabled", "error", err)
+		return nil
+	}
+	return cache
+}
+
+// openGitHubCache opens the default GitHub response cache
+// Returns nil (no caching) if the cache directory is unavailable
+func openGitHubCache(ctx context.Context) *github.HTTPCache {
+	dir, err := github.DefaultHTTPCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	cache, err := github.NewHTTPCache(dir)
+	if err != nil {
+		logging.FromContext(ctx).Warn("GitHub response cache disabled", "error", err)
+		return nil
+	}
+	return cache
+}
+
+// cloneRemoteRepository clones a remote repository through the on-disk clone cache
+// Falls back to an in-memory clone if no cache directory is available
+// HTTPS credentials are scoped to the repository's host, as they are for the repository's submodules
+func cloneRemoteRepository(ctx context.Context, url string, auth git.AuthOptions) (*gogit.Repository, error) {
+	auth = scopeAuth(auth, url)
+	cacheDir, err := git.DefaultCacheDir()
+	if err != nil {
+		return git.CloneRepositoryWithAuth(ctx, url, auth)
+	}
+
+	gitRepo, err := git.OpenOrCloneWithAuth(ctx, url, cacheDir, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the cache bounded; eviction failures are not fatal
+	if _, err := git.EvictCache(cacheDir, git.DefaultEvictionPolicy()); err != nil {
+		logging.FromContext(ctx).Warn("Failed to evict clone cache", "error", err)
+	}
+
+	return gitRepo, nil
 }
 
-// enrichWithArtifacts dispatches to platform-specific enrichment based on the activity's platform
-func enrichWithArtifacts(ctx context.Context, activity *cluster.RepositoryActivity, token, owner, repo string) error {
-	var platformAdapter adapter.Adapter
+// enrichFromSource adds artifacts from a source outside the code host (Jira project, Linear team or
+// import file) to the activity
+// Source credentials are read from the source's environment variables
+func enrichFromSource(ctx context.Context, activity *cluster.RepositoryActivity, source adapter.Adapter, project string) error {
+	artifacts, err := source.FetchArtifacts(ctx, "", "", project)
+	if err != nil {
+		return err
+	}
+
+	activity.Artifacts = append(activity.Artifacts, artifacts...)
+	report.FromContext(ctx).Succeed(report.StageArtifacts, len(artifacts))
+	return nil
+}
+
+// enrichWithBuilds records a CI provider's builds since the activity's first commit on its pull and merge requests
+func enrichWithBuilds(ctx context.Context, activity *cluster.RepositoryActivity, provider ci.Provider) error {
+	var since time.Time
+	for _, commit := range activity.Commits {
+		if since.IsZero() || commit.CommittedAt.Before(since) {
+			since = commit.CommittedAt
+		}
+	}
+
+	logging.FromContext(ctx).Info("Fetching builds", "provider", provider.Name())
+
+	builds, err := provider.ListBuilds(ctx, since)
+	if err != nil {
+		return err
+	}
 
-	switch activity.Platform {
+	matched := adapter.AttachBuilds(activity.Artifacts, builds)
+	report.FromContext(ctx).Succeed(report.StageBuilds, len(builds))
+	logging.FromContext(ctx).Info("Matched builds to pull requests", "provider", provider.Name(), "matched", matched, "builds", len(builds))
+	return nil
+}
+
+// platformToken returns the API token to use for a platform: the token passed for the
+// repository, or else GITHUB_TOKEN for GitHub and GITLAB_TOKEN for GitLab
+// Each environment token is only sent to its own platform. Since any host with a "gitlab" label is
+// taken for GitLab, GITLAB_TOKEN is further limited to gitlab.com and the GITLAB_HOST instance, over
+// HTTPS (see trustedGitLabURL); other platforms get no token from the environment, so their
+// artifacts are skipped
+func platformToken(platform cluster.SourcePlatform, platformURL, token string) string {
+	if token != "" {
+		return token
+	}
+	switch platform {
 	case cluster.PlatformGitHub:
-		platformAdapter = adapter.NewGitHubAdapter()
-	// ? This is where we would implement other platforms
-	default:
+		return os.Getenv("GITHUB_TOKEN")
+	case cluster.PlatformGitLab:
+		if trustedGitLabURL(platformURL) {
+			return os.Getenv("GITLAB_TOKEN")
+		}
+	}
+	return ""
+}
+
+// enrichWithArtifacts fetches the activity's artifacts with the adapter registered for its platform
+// repoURL locates the hosting instance for platforms that can be self-hosted, and only issues and
+// pull requests updated since are fetched (zero = all)
+func enrichWithArtifacts(ctx context.Context, activity *cluster.RepositoryActivity, repoURL, token, owner, repo string, since time.Time) error {
+	var baseURL string
+	if activity.Platform == cluster.PlatformGitLab {
+		baseURL = gitLabBaseURL(repoURL)
+	}
+
+	platformAdapter := adapter.ForPlatform(activity.Platform, baseURL)
+	if platformAdapter == nil {
 		return nil
 	}
+	switch platformAdapter := platformAdapter.(type) {
+	case *adapter.GitHubAdapter:
+		if platformAdapter.Cache == nil {
+			platformAdapter.Cache = openGitHubCache(ctx)
+		}
+		platformAdapter.Since = since
+	case *adapter.GitLabAdapter:
+		platformAdapter.Since = since
+	}
 
 	// Use the adapter to fetch artifacts
 	artifacts, err := platformAdapter.FetchArtifacts(ctx, token, owner, repo)
@@ -133,6 +483,7 @@ func enrichWithArtifacts(ctx context.Context, activity *cluster.RepositoryActivi
 
 	// Add artifacts to activity
 	activity.Artifacts = append(activity.Artifacts, artifacts...)
+	report.FromContext(ctx).Succeed(report.StageArtifacts, len(artifacts))
 
 	return nil
 }
@@ -2,14 +2,34 @@ package orchestrator
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Yates-Labs/thunk/internal/adapter"
 	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
 	githubmodel "github.com/Yates-Labs/thunk/internal/ingest/github"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
 )
 
+// TestMain keeps parsed commits and GitHub responses from tests out of the user's caches
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "thunk-cache-")
+	if err == nil {
+		os.Setenv(git.CommitCacheDirEnv, filepath.Join(dir, "commits"))
+		os.Setenv(githubmodel.HTTPCacheDirEnv, filepath.Join(dir, "github"))
+	}
+	code := m.Run()
+	if err == nil {
+		os.RemoveAll(dir)
+	}
+	os.Exit(code)
+}
+
 func TestAnalyzeRepository_RealRepo(t *testing.T) {
 	ctx := context.Background()
 
@@ -72,6 +92,46 @@ func TestAnalyzeRepository_InvalidRepo(t *testing.T) {
 	}
 }
 
+func TestAnalyzeRepository_AllBranches(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", base)
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatalf("Failed to create feature branch: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "feature.txt", "Add feature", base.Add(time.Hour))
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatalf("Failed to checkout master: %v", err)
+	}
+
+	config := cluster.DefaultGroupingConfig()
+	config.AllBranches = true
+	episodes, err := AnalyzeRepositoryWithConfig(ctx, dir, config)
+	if err != nil {
+		t.Fatalf("AnalyzeRepositoryWithConfig failed: %v", err)
+	}
+	var feature *git.Commit
+	for i := range episodes {
+		for j := range episodes[i].Commits {
+			if strings.TrimSpace(episodes[i].Commits[j].Message) == "Add feature" {
+				feature = &episodes[i].Commits[j]
+			}
+		}
+	}
+	if feature == nil || len(feature.Branches) != 1 || feature.Branches[0] != "feature" {
+		t.Errorf("Expected the feature branch's commit with its branch, got %+v", feature)
+	}
+}
+
 func TestAnalyzeRepositoryWithConfig(t *testing.T) {
 	// Create custom config with tighter time window
 	config := cluster.GroupingConfig{
@@ -144,6 +204,59 @@ func TestExtractRepoName(t *testing.T) {
 	}
 }
 
+func TestRepositoryKey(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/Yates-Labs/thunk.git": "Yates-Labs/thunk",
+		"git@github.com:Yates-Labs/thunk.git":     "Yates-Labs/thunk",
+		"https://gitlab.com/group/sub/project":    "group/sub/project",
+		"/path/to/myrepo/":                        "myrepo",
+	}
+	for input, expected := range tests {
+		if got := RepositoryKey(input); got != expected {
+			t.Errorf("RepositoryKey(%q) = %q, want %q", input, got, expected)
+		}
+	}
+
+	if got := RepositoryKey("."); got == "" || got == "." {
+		t.Errorf("Expected a local path to resolve to its directory name, got %q", got)
+	}
+}
+
+func TestIsRemoteRepository(t *testing.T) {
+	tests := map[string]bool{
+		"https://github.com/Yates-Labs/thunk": true,
+		"git@github.com:Yates-Labs/thunk.git": true,
+		"ssh://git@host:2222/repo.git":        true,
+		"/path/to/repo":                       false,
+		"../repo":                             false,
+		"file:///path/to/repo":                false,
+	}
+	for input, expected := range tests {
+		if got := IsRemoteRepository(input); got != expected {
+			t.Errorf("IsRemoteRepository(%q) = %v, want %v", input, got, expected)
+		}
+	}
+}
+
+func TestEpisodeSummaries_Repository(t *testing.T) {
+	pipeline := &RAGPipeline{config: RAGConfig{Repository: "owner/app"}}
+	episodes := []cluster.Episode{
+		{ID: "E1"},
+		{ID: "lib:E2", Repository: "owner/lib"},
+	}
+
+	summaries := pipeline.episodeSummaries(episodes)
+	if summaries[0].Repository != "owner/app" || summaries[1].Repository != "owner/lib" {
+		t.Errorf("Expected episodes indexed under owner/app and owner/lib, got %q and %q", summaries[0].Repository, summaries[1].Repository)
+	}
+	if opts := pipeline.searchOptions(); opts == nil || opts.Repository != "owner/app" {
+		t.Errorf("Expected retrieval scoped to owner/app, got %+v", opts)
+	}
+	if opts := (&RAGPipeline{}).searchOptions(); opts != nil {
+		t.Errorf("Expected unscoped retrieval without a repository, got %+v", opts)
+	}
+}
+
 func TestAnalyzeRepository_EndToEnd(t *testing.T) {
 	ctx := context.Background()
 
@@ -296,12 +409,48 @@ func TestDetectPlatform(t *testing.T) {
 			expectedOwner:    "Yates-Labs",
 			expectedRepo:     "thunk",
 		},
+		{
+			url:              "https://gitlab.com/group/subgroup/project",
+			expectedPlatform: cluster.PlatformGitLab,
+			expectedOwner:    "group/subgroup",
+			expectedRepo:     "project",
+		},
+		{
+			url:              "git@gitlab.com:group/project.git",
+			expectedPlatform: cluster.PlatformGitLab,
+			expectedOwner:    "group",
+			expectedRepo:     "project",
+		},
+		{
+			url:              "ssh://git@gitlab.example.com:2222/team/project.git",
+			expectedPlatform: cluster.PlatformGitLab,
+			expectedOwner:    "team",
+			expectedRepo:     "project",
+		},
+		{
+			url:              "https://gitlab.example.com/team/project/-/merge_requests/4",
+			expectedPlatform: cluster.PlatformGitLab,
+			expectedOwner:    "team",
+			expectedRepo:     "project",
+		},
+		{
+			url:              "git@bitbucket.org:team/project.git",
+			expectedPlatform: cluster.PlatformBitbucket,
+			expectedOwner:    "team",
+			expectedRepo:     "project",
+		},
 		{
 			url:              "/local/path/to/repo",
 			expectedPlatform: cluster.PlatformGit,
 			expectedOwner:    "",
 			expectedRepo:     "repo",
 		},
+		{
+			url:              "gitlab-tools/repo",
+			expectedPlatform: cluster.PlatformGit,
+			expectedOwner:    "",
+			expectedRepo:     "repo",
+		},
 	}
 
 	for _, tt := range tests {
@@ -382,3 +531,66 @@ func TestAnalyzeGitHubRepositoryPlaceholder(t *testing.T) {
 		t.Logf("Expected error without valid token: %v", err)
 	}
 }
+
+// TestDetectPlatform_GitLabHostEnv tests detection of self-hosted GitLab without "gitlab" in the hostname
+func TestDetectPlatform_GitLabHostEnv(t *testing.T) {
+	t.Setenv("GITLAB_HOST", "https://code.example.com")
+
+	platform, owner, repo := detectPlatform("https://code.example.com/team/project.git")
+	if platform != cluster.PlatformGitLab || owner != "team" || repo != "project" {
+		t.Errorf("Expected gitlab team/project, got %s %s/%s", platform, owner, repo)
+	}
+}
+
+// TestGitLabBaseURL tests API base detection for gitlab.com and self-hosted instances
+func TestGitLabBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"https://gitlab.com/group/project":                "",
+		"git@gitlab.example.com:team/project.git":         "https://gitlab.example.com/",
+		"http://gitlab.local:8080/team/project":           "http://gitlab.local:8080/",
+		"ssh://git@gitlab.example.com:2222/team/proj.git": "https://gitlab.example.com/",
+	}
+
+	for url, want := range tests {
+		if got := gitLabBaseURL(url); got != want {
+			t.Errorf("gitLabBaseURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+// TestPlatformToken tests that environment tokens are only used for their own platform, and
+// GITLAB_TOKEN only for gitlab.com and GITLAB_HOST over HTTPS
+func TestPlatformToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "github-secret")
+	t.Setenv("GITLAB_TOKEN", "gitlab-secret")
+	t.Setenv(gitLabHostEnv, "https://code.example.com/")
+
+	tests := []struct {
+		platform cluster.SourcePlatform
+		url      string
+		passed   string
+		want     string
+	}{
+		{cluster.PlatformGitHub, "https://github.com/owner/repo", "", "github-secret"},
+		{cluster.PlatformGitHub, "https://github.com/owner/repo", "passed", "passed"},
+		{cluster.PlatformGitLab, "https://gitlab.com/group/project", "", "gitlab-secret"},
+		{cluster.PlatformGitLab, "git@gitlab.com:group/project.git", "", "gitlab-secret"},
+		{cluster.PlatformGitLab, "https://code.example.com/team/project", "", "gitlab-secret"},
+		{cluster.PlatformGitLab, "http://code.example.com/team/project", "", ""},
+		{cluster.PlatformGitLab, "http://gitlab.com/group/project", "", ""},
+		{cluster.PlatformGitLab, "https://gitlab.attacker.example/team/project", "", ""},
+		{cluster.PlatformGitLab, "https://gitlab.attacker.example/team/project", "passed", "passed"},
+		{cluster.PlatformBitbucket, "https://bitbucket.org/owner/repo", "", ""},
+		{cluster.PlatformGit, "/path/to/repo", "", ""},
+	}
+	for _, tt := range tests {
+		if got := platformToken(tt.platform, tt.url, tt.passed); got != tt.want {
+			t.Errorf("platformToken(%s, %q, %q) = %q, want %q", tt.platform, tt.url, tt.passed, got, tt.want)
+		}
+	}
+
+	t.Setenv(gitLabHostEnv, "http://code.example.com")
+	if got := platformToken(cluster.PlatformGitLab, "https://code.example.com/team/project", ""); got != "" {
+		t.Errorf("Expected no GITLAB_TOKEN for a GITLAB_HOST served over HTTP, got %q", got)
+	}
+}
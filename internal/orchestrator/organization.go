@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/github"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// OrganizationOptions selects and groups the repositories of an organization
+type OrganizationOptions struct {
+	// Include limits analysis to repositories whose name matches one of these glob patterns (empty includes all)
+	Include []string
+
+	// Exclude skips repositories whose name matches one of these glob patterns, even if included
+	Exclude []string
+
+	// IncludeArchived also analyzes archived repositories
+	IncludeArchived bool
+
+	// IncludeForks also analyzes forks
+	IncludeForks bool
+
+	// Grouping configures how each repository's commits are grouped into episodes
+	Grouping cluster.GroupingConfig
+}
+
+// DefaultOrganizationOptions returns options that analyze every active, non-fork repository
+func DefaultOrganizationOptions() OrganizationOptions {
+	return OrganizationOptions{
+		Grouping: cluster.DefaultGroupingConfig(),
+	}
+}
+
+// AnalyzeOrganization analyzes every selected repository in a GitHub organization
+// Episodes are returned per repository (in repository name order), tagged with the repository's
+// full name and with IDs prefixed by its name (e.g. "api:E1") so they stay distinct across repositories
+// Repositories that fail to ingest are skipped with a warning
+// Token is automatically loaded from GITHUB_TOKEN environment variable if not provided
+func AnalyzeOrganization(ctx context.Context, org string, opts OrganizationOptions, token ...string) ([]cluster.Episode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled before analysis: %w", err)
+	}
+	ctx = logging.With(logging.WithRun(ctx), "organization", org)
+
+	if err := validatePatterns(append(append([]string{}, opts.Include...), opts.Exclude...)); err != nil {
+		return nil, err
+	}
+
+	var apiToken string
+	if len(token) > 0 && token[0] != "" {
+		apiToken = token[0]
+	} else {
+		apiToken = os.Getenv("GITHUB_TOKEN")
+	}
+
+	auth, err := AuthFromEnv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load git credentials: %w", err)
+	}
+
+	client := github.NewClientWithOptions(apiToken, github.ClientOptions{Cache: openGitHubCache(ctx)})
+	repos, err := github.FetchOrganizationRepos(ctx, client, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization repositories: %w", err)
+	}
+
+	episodes := make([]cluster.Episode, 0)
+	for _, repo := range selectRepositories(repos, opts) {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("context cancelled during analysis: %w", err)
+		}
+
+		repoCtx := logging.With(ctx, logging.RepositoryKey, repo.FullName)
+		activity, _, err := ingestRepository(repoCtx, repo.CloneURL, ingestOptions{token: apiToken, envTokens: true, allBranches: opts.Grouping.AllBranches, auth: auth})
+		if err != nil {
+			logging.FromContext(repoCtx).Warn("Failed to ingest repository", "error", err)
+			reportError(repoCtx, fmt.Errorf("failed to ingest repository %s: %w", repo.FullName, err))
+			report.FromContext(ctx).Skip(report.StageRepositories, repo.FullName, err)
+			continue
+		}
+		report.FromContext(ctx).Succeed(report.StageRepositories, 1)
+
+		episodes = append(episodes, tagRepositoryEpisodes(groupEpisodes(repoCtx, activity, opts.Grouping), repo)...)
+	}
+	if err := episodesCreated(ctx, episodes); err != nil {
+		return nil, reportError(ctx, err)
+	}
+
+	return episodes, nil
+}
+
+// selectRepositories filters an organization's repositories by the include/exclude patterns
+// and the archived/fork settings
+func selectRepositories(repos []github.Repository, opts OrganizationOptions) []github.Repository {
+	selected := make([]github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if repo.Archived && !opts.IncludeArchived {
+			continue
+		}
+		if repo.Fork && !opts.IncludeForks {
+			continue
+		}
+		if len(opts.Include) > 0 && !matchesAny(opts.Include, repo.Name) {
+			continue
+		}
+		if matchesAny(opts.Exclude, repo.Name) {
+			continue
+		}
+		selected = append(selected, repo)
+	}
+	return selected
+}
+
+// tagRepositoryEpisodes records which repository each episode came from
+func tagRepositoryEpisodes(episodes []cluster.Episode, repo github.Repository) []cluster.Episode {
+	for i := range episodes {
+		episodes[i].ID = repo.Name + ":" + episodes[i].ID
+		episodes[i].Repository = repo.FullName
+	}
+	return episodes
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePatterns rejects malformed glob patterns up front instead of silently matching nothing
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/ingest/github"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+func TestSelectRepositories(t *testing.T) {
+	repos := []github.Repository{
+		{Name: "api"},
+		{Name: "api-legacy"},
+		{Name: "web"},
+		{Name: "old", Archived: true},
+		{Name: "upstream", Fork: true},
+	}
+
+	names := func(repos []github.Repository) string {
+		parts := make([]string, len(repos))
+		for i, repo := range repos {
+			parts[i] = repo.Name
+		}
+		return strings.Join(parts, ",")
+	}
+
+	tests := []struct {
+		name string
+		opts OrganizationOptions
+		want string
+	}{
+		{"defaults skip archived and forks", OrganizationOptions{}, "api,api-legacy,web"},
+		{"include pattern", OrganizationOptions{Include: []string{"api*"}}, "api,api-legacy"},
+		{"exclude wins over include", OrganizationOptions{Include: []string{"api*"}, Exclude: []string{"*-legacy"}}, "api"},
+		{"archived and forks opted in", OrganizationOptions{IncludeArchived: true, IncludeForks: true, Exclude: []string{"api*"}}, "web,old,upstream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(selectRepositories(repos, tt.opts)); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	if err := validatePatterns([]string{"api-*", "web"}); err != nil {
+		t.Errorf("Expected valid patterns, got %v", err)
+	}
+	if err := validatePatterns([]string{"api-["}); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+}
+
+func TestTagRepositoryEpisodes(t *testing.T) {
+	episodes := tagRepositoryEpisodes([]cluster.Episode{{ID: "E1"}, {ID: "E2"}}, github.Repository{Name: "api", FullName: "acme/api"})
+
+	if episodes[0].ID != "api:E1" || episodes[1].ID != "api:E2" {
+		t.Errorf("Expected IDs prefixed with the repository name, got %s and %s", episodes[0].ID, episodes[1].ID)
+	}
+	if episodes[0].Repository != "acme/api" {
+		t.Errorf("Expected repository acme/api, got %q", episodes[0].Repository)
+	}
+}
+
+func TestAssembleProjectQueryPrompt_Repositories(t *testing.T) {
+	author := git.Author{Name: "Alice", Email: "alice@example.com"}
+	commit := git.Commit{Hash: "a1", Message: "Initial", Author: author, CommittedAt: time.Now()}
+	episodes := []cluster.Episode{
+		{ID: "web:E1", Repository: "acme/web", Commits: []git.Commit{commit}},
+		{ID: "api:E1", Repository: "acme/api", Commits: []git.Commit{commit}},
+	}
+
+	prompt, err := assembleProjectQueryPrompt(narrative.DefaultPromptTemplates(), "What changed?", episodes, nil, nil)
+	if err != nil {
+		t.Fatalf("assembleProjectQueryPrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "**Repositories:** 2 repositories (acme/api, acme/web)") {
+		t.Errorf("Expected repositories in the project overview, got:\n%s", prompt)
+	}
+
+	if summary := generateEpisodeSummaryText(&episodes[0]); !strings.HasPrefix(summary, "Repository: acme/web\n") {
+		t.Errorf("Expected summary to name the repository, got:\n%s", summary)
+	}
+}
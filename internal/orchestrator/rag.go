@@ -2,41 +2,165 @@ package orchestrator
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Yates-Labs/thunk/internal/azureopenai"
 	"github.com/Yates-Labs/thunk/internal/cluster"
 	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/logging"
 	"github.com/Yates-Labs/thunk/internal/narrative"
 	"github.com/Yates-Labs/thunk/internal/rag"
+	"github.com/Yates-Labs/thunk/internal/redact"
+	"github.com/Yates-Labs/thunk/internal/report"
+	"github.com/Yates-Labs/thunk/internal/state"
 )
 
 // RAGConfig holds configuration for the RAG-based narrative generation pipeline.
 type RAGConfig struct {
-	// TopK is the number of similar episodes to retrieve as context
+	// TopK is the number of similar episodes to retrieve as context; with AdaptiveTopK it is
+	// where the search starts
 	TopK int
 
 	// MaxContextSize is the maximum number of context chunks to include in the prompt
 	MaxContextSize int
 
+	// MaxContextTokens caps the estimated tokens of context chunk text in the prompt; 0 is unlimited
+	MaxContextTokens int
+
+	// MinScore drops retrieved chunks less similar than this; 0 keeps chunks of any score
+	MinScore float32
+
+	// AdaptiveTopK widens the search past TopK for as long as chunks score at least MinScore and
+	// fit in MaxContextTokens and MaxContextSize, instead of retrieving a fixed TopK
+	AdaptiveTopK bool
+
 	// ReindexOnDemand forces re-indexing of episodes before retrieval
 	ReindexOnDemand bool
 
+	// EmbedderProvider names the registered embedder to use: "openai" (the default) or "fake",
+	// a deterministic hash embedder that needs no API key or network
+	EmbedderProvider string
+
 	// EmbedderModel is the model to use for embeddings (e.g., "text-embedding-3-large")
 	EmbedderModel string
 
 	// EmbedderDimension is the vector dimension for embeddings
 	EmbedderDimension int
 
+	// EmbedderAzure, when set, embeds with an Azure OpenAI deployment instead of EmbedderProvider
+	// Set LLMConfig.Azure as well to generate narratives on Azure too
+	EmbedderAzure *azureopenai.Config
+
 	// LLMConfig holds the LLM configuration for narrative generation
 	LLMConfig narrative.LLMConfig
 
 	// MilvusConfig holds the Milvus vector store configuration
 	MilvusConfig rag.MilvusConfig
+
+	// LocalStore selects the in-process vector store instead of Milvus when its Path is set
+	LocalStore rag.LocalStoreConfig
+
+	// SQLiteStore selects the SQLite vector store with hybrid keyword search when its Path is set
+	SQLiteStore rag.SQLiteConfig
+
+	// Repository (owner/name) scopes the index so one vector store can hold many repositories:
+	// episodes without a repository of their own are indexed under it, and retrieval only returns
+	// episodes indexed under it; empty searches the whole store
+	Repository string
+
+	// MigrateSchema migrates a vector store whose schema or dimension doesn't match the embedder,
+	// re-embedding its episodes if needed, instead of refusing to start
+	MigrateSchema bool
+
+	// Granularities are the records project queries retrieve: whole-episode summaries, chunks of
+	// consecutive commits, or single commits; empty retrieves episode summaries only
+	// Commit and chunk records are only indexed when retrieved, so enabling them for an existing
+	// index takes a reindex
+	Granularities []rag.Granularity
+
+	// ChunkCommits is the number of commits per chunk record (default: 5)
+	ChunkCommits int
+
+	// ChunkOverlap is the number of commits each chunk record shares with the one before it
+	ChunkOverlap int
+
+	// Filter narrows project queries to records by date range, author and touched paths
+	Filter rag.MetadataFilter
+
+	// Arcs configures how project-level narratives group episodes into story arcs
+	Arcs cluster.ArcConfig
+
+	// ClassifyWithLLM asks the LLM for the category of episodes the rule-based classifier left uncategorized
+	ClassifyWithLLM bool
+
+	// IndexWorkers is the number of batches embedded concurrently while indexing (default: 1)
+	IndexWorkers int
+
+	// IndexProgress, if set, is called as indexing batches are flushed or fail
+	IndexProgress func(rag.IndexProgress)
+
+	// StateStore, if set, records the content hash of every episode indexed, so indexing skips
+	// episodes whose content hasn't changed since and re-embeds those whose content did, such as an
+	// episode whose issues were updated; episodes indexed before a store was used are re-embedded once
+	StateStore state.Store
+
+	// ContextWindow is the LLM's context window in tokens; prompts are condensed to fit it, less
+	// LLMConfig.MaxTokens for the answer; 0 looks up the model's (see narrative.ContextWindow)
+	ContextWindow int
+
+	// TokenizerFile is a tiktoken rank file (e.g. cl100k_base.tiktoken) to count tokens exactly with;
+	// empty estimates counts with rag.DefaultTokenizer
+	TokenizerFile string
+
+	// PromptDir holds prompt templates (episode.tmpl, project.tmpl, summary.tmpl, combine.tmpl, contributor.tmpl, period.tmpl, digest.tmpl)
+	// replacing the built-in ones, with a subdirectory per narrative style (e.g. executive/episode.tmpl);
+	// empty uses the built-in templates throughout
+	PromptDir string
+
+	// SummarizeAbove is the number of commits and artifacts above which an episode's narrative is
+	// written in two stages: parts of SummaryBatch items are summarized first, and the narrative is
+	// written from their summaries rather than condensed to fit the context window; 0 disables this
+	SummarizeAbove int
+
+	// SummaryBatch is the number of commits and artifacts summarized per part (default: 50)
+	SummaryBatch int
+
+	// SummaryModel is the model, typically a cheaper one, that summarizes parts; empty uses LLMConfig.Model
+	SummaryModel string
+
+	// NarrativeCacheDir keeps generated episode and digest narratives on disk, reused for as long as
+	// the work they describe, the prompt templates, the model and the style stay the same; empty
+	// disables caching (see narrative.DefaultCacheDir)
+	NarrativeCacheDir string
+
+	// ForceRegenerate generates narratives even when they are cached, replacing the cached ones
+	ForceRegenerate bool
+
+	// Diffs includes hunks of the files each episode changed most in its narrative prompt, with
+	// secrets redacted by the Redaction policy, so narratives can describe how the work was implemented; the commits must
+	// carry patches (see git.ParseOptions.IncludePatch), which are loaded for the episodes narrated
+	// when deferred with LazyDiffs; the zero value includes none
+	Diffs narrative.DiffOptions
+
+	// Exemplars are episode and narrative pairs included in episode prompts as few-shot examples,
+	// so narratives match a team's established voice (see narrative.LoadExemplars and
+	// RAGPipeline.CachedExemplars)
+	Exemplars []narrative.Exemplar
+
+	// Redaction is the policy for removing secrets and credentials from commit messages, diffs,
+	// artifacts and discussions before they are embedded or sent to the LLM; the zero value applies
+	// every built-in rule (see redact.Policy)
+	Redaction redact.Policy
 }
 
 // DefaultRAGConfig returns sensible defaults for the RAG pipeline.
@@ -49,6 +173,12 @@ func DefaultRAGConfig() RAGConfig {
 		EmbedderDimension: 3072,
 		LLMConfig:         narrative.DefaultLLMConfig(),
 		MilvusConfig:      rag.DefaultMilvusConfig(),
+		Arcs:              cluster.DefaultArcConfig(),
+		ChunkCommits:      defaultChunkCommits,
+		ChunkOverlap:      1,
+		SummarizeAbove:    defaultSummarizeAbove,
+		SummaryBatch:      defaultSummaryBatch,
+		SummaryModel:      "gpt-4o-mini",
 	}
 }
 
@@ -58,21 +188,100 @@ type RAGPipeline struct {
 	embedder    rag.Embedder
 	vectorStore rag.VectorStore
 	retriever   *rag.Retriever
+	llm         narrative.LLM
 	generator   *narrative.Generator
+	prompts     *narrative.PromptTemplates
+	tokenizer   rag.Tokenizer
+
+	// summarizer and summaryPrompts summarize the parts of huge episodes (see SummarizeAbove)
+	summarizer     *narrative.Generator
+	summaryPrompts *narrative.PromptTemplates
+
+	// cache keeps generated narratives between runs; nil caches nothing (see NarrativeCacheDir)
+	cache *narrative.Cache
+
+	// redactor removes secrets from episodes before they are embedded or prompted; nil when
+	// redaction is disabled
+	redactor *redact.Redactor
+
+	// clusterer groups episodes into story arcs; nil groups them by config.Arcs
+	clusterer Clusterer
+
+	// sharedStore leaves vectorStore open on Close (see WithSharedVectorStore)
+	sharedStore bool
 }
 
 // NewRAGPipeline creates a new RAG pipeline with the given configuration.
-func NewRAGPipeline(ctx context.Context, config RAGConfig) (*RAGPipeline, error) {
+// The embedder, vector store and LLM are constructed from the configuration unless options
+// inject them (see WithEmbedder, WithVectorStore, WithSharedVectorStore, WithLLM and WithClusterer).
+func NewRAGPipeline(ctx context.Context, config RAGConfig, opts ...PipelineOption) (_ *RAGPipeline, err error) {
+	var backends pipelineBackends
+	for _, opt := range opts {
+		opt(&backends)
+	}
+
+	// Load prompt templates and the tokenizer first, so a broken file is reported before connecting to anything
+	prompts := narrative.DefaultPromptTemplates()
+	if config.PromptDir != "" {
+		var err error
+		if prompts, err = narrative.LoadPromptTemplates(config.PromptDir); err != nil {
+			return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+		}
+	}
+	tokenizer := rag.DefaultTokenizer
+	if config.TokenizerFile != "" {
+		bpe, err := rag.LoadTiktoken(config.TokenizerFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tokenizer: %w", err)
+		}
+		tokenizer = bpe
+	}
+	style, err := narrative.ParseStyle(string(config.LLMConfig.Style))
+	if err != nil {
+		return nil, err
+	}
+	config.LLMConfig.Style = style
+	redactor, err := redact.New(config.Redaction)
+	if err != nil {
+		return nil, err
+	}
+	templates := prompts.WithDiffs(config.Diffs)
+	prompts = templates.WithStyle(style).WithBudget(narrative.NewPromptBudget(config.LLMConfig, config.ContextWindow, tokenizer)).WithExemplars(config.Exemplars)
+
 	// Initialize embedder
-	embedder, err := rag.NewOpenAIEmbedder(config.EmbedderModel, config.EmbedderDimension)
+	embedder := backends.embedder
+	switch {
+	case embedder != nil:
+	case config.EmbedderAzure != nil:
+		embedder, err = rag.NewAzureOpenAIEmbedder(*config.EmbedderAzure, config.EmbedderDimension)
+	default:
+		embedder, err = rag.NewEmbedder(config.EmbedderProvider, config.EmbedderModel, config.EmbedderDimension)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create embedder: %w", err)
 	}
+	// The schema is checked against the vectors the injected embedder actually produces
+	if backends.embedder != nil {
+		if config.EmbedderDimension, err = embedderDimension(ctx, embedder); err != nil {
+			return nil, err
+		}
+	}
 
 	// Initialize vector store
-	vectorStore, err := rag.NewMilvusStore(ctx, config.MilvusConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create vector store: %w", err)
+	vectorStore := backends.vectorStore
+	if vectorStore == nil {
+		if vectorStore, err = NewVectorStore(ctx, config); err != nil {
+			return nil, err
+		}
+	}
+	// Nothing else holds an owned store until the pipeline is returned
+	defer func() {
+		if err != nil && !backends.sharedStore {
+			vectorStore.Close()
+		}
+	}()
+	if err := ensureSchema(ctx, vectorStore, embedder, config); err != nil {
+		return nil, err
 	}
 
 	// Initialize retriever
@@ -82,73 +291,398 @@ func NewRAGPipeline(ctx context.Context, config RAGConfig) (*RAGPipeline, error)
 	}
 
 	// Initialize LLM
-	llm, err := narrative.NewOpenAILLM(config.LLMConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create LLM: %w", err)
+	llm := backends.llm
+	if llm == nil {
+		if llm, err = narrative.NewLLM(config.LLMConfig); err != nil {
+			return nil, fmt.Errorf("failed to create LLM: %w", err)
+		}
 	}
 
 	// Initialize generator
 	generator := narrative.NewGenerator(llm, config.LLMConfig)
 
+	// Initialize the summarizer for episodes too large to describe at once
+	summaryConfig := summaryLLMConfig(config)
+	summaryLLM := backends.llm
+	if summaryLLM == nil {
+		if summaryLLM, err = narrative.NewLLM(summaryConfig); err != nil {
+			return nil, fmt.Errorf("failed to create summary LLM: %w", err)
+		}
+	}
+
+	// Open the narrative cache, if any
+	var cache *narrative.Cache
+	if config.NarrativeCacheDir != "" {
+		if cache, err = narrative.NewCache(config.NarrativeCacheDir); err != nil {
+			return nil, err
+		}
+	}
+
 	return &RAGPipeline{
 		config:      config,
 		embedder:    embedder,
 		vectorStore: vectorStore,
 		retriever:   retriever,
+		llm:         llm,
 		generator:   generator,
+		prompts:     prompts,
+		tokenizer:   tokenizer,
+
+		summarizer:     narrative.NewGenerator(summaryLLM, summaryConfig),
+		summaryPrompts: templates.WithBudget(narrative.NewPromptBudget(summaryConfig, config.ContextWindow, tokenizer)),
+		cache:          cache,
+		redactor:       redactor,
+		clusterer:      backends.clusterer,
+		sharedStore:    backends.sharedStore,
 	}, nil
 }
 
+// embedderDimension returns the dimension of an embedder's vectors: that of a built-in embedder, or
+// else the length of a probe text's embedding
+func embedderDimension(ctx context.Context, embedder rag.Embedder) (int, error) {
+	var dimension int
+	switch e := embedder.(type) {
+	case *rag.HashEmbedder:
+		dimension = e.Dimension
+	case *rag.OpenAIEmbedder:
+		dimension = e.Dimension
+	}
+	if dimension > 0 {
+		return dimension, nil
+	}
+
+	records, err := embedder.Embed(ctx, []string{"dimension probe"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe embedder dimension: %w", err)
+	}
+	if len(records) == 0 || len(records[0].Embedding) == 0 {
+		return 0, fmt.Errorf("failed to probe embedder dimension: no embedding returned")
+	}
+	return len(records[0].Embedding), nil
+}
+
+// NewVectorStore opens the vector store config selects: the local store when its Path is set, then
+// the SQLite store, and Milvus otherwise
+func NewVectorStore(ctx context.Context, config RAGConfig) (rag.VectorStore, error) {
+	var store rag.VectorStore
+	var err error
+	switch {
+	case config.LocalStore.Path != "":
+		store, err = rag.NewLocalStore(config.LocalStore)
+	case config.SQLiteStore.Path != "":
+		store, err = rag.NewSQLiteStore(ctx, config.SQLiteStore)
+	default:
+		store, err = rag.NewMilvusStore(ctx, config.MilvusConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vector store: %w", err)
+	}
+	return store, nil
+}
+
+// schemaMu makes pipelines sharing a store check and migrate it one at a time
+var schemaMu sync.Mutex
+
+// ensureSchema checks that the vector store matches the current schema and the embedder's dimension,
+// migrating it when MigrateSchema is set, so a changed embedding model is caught before any insert
+func ensureSchema(ctx context.Context, vectorStore rag.VectorStore, embedder rag.Embedder, config RAGConfig) error {
+	store, ok := vectorStore.(rag.Migratable)
+	if !ok {
+		return nil
+	}
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	target := rag.SchemaVersion{Version: rag.CurrentSchemaVersion, Dimension: config.EmbedderDimension}
+	current, err := store.Schema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read vector store schema: %w", err)
+	}
+	if current.Compatible(target) {
+		return nil
+	}
+	if !config.MigrateSchema {
+		return fmt.Errorf("%w: vector store is %s, expected %s; enable schema migration to convert it", rag.ErrOutdatedSchema, current, target)
+	}
+
+	logging.FromContext(ctx).Info("Migrating vector store", "from", current, "to", target)
+	result, err := rag.Migrate(ctx, store, target, embedder)
+	if err != nil {
+		return fmt.Errorf("failed to migrate vector store: %w", err)
+	}
+	logging.FromContext(ctx).Info("Migrated vector store", "episodes", result.Records, "reembedded", result.Reembedded)
+	return nil
+}
+
 // Close releases resources held by the RAG pipeline.
 func (p *RAGPipeline) Close() error {
-	if p.vectorStore != nil {
+	if p.vectorStore != nil && !p.sharedStore {
 		return p.vectorStore.Close()
 	}
 	return nil
 }
 
+// WithStyle returns a pipeline writing narratives and answers in style, sharing this pipeline's
+// connections, so one pipeline can serve several audiences; closing either closes both.
+func (p *RAGPipeline) WithStyle(style narrative.Style) *RAGPipeline {
+	styled := *p
+	styled.config.LLMConfig.Style = style
+	styled.prompts = p.prompts.WithStyle(style)
+	styled.generator = narrative.NewGenerator(p.llm, styled.config.LLMConfig)
+	return &styled
+}
+
+// WithExemplars returns a pipeline including exemplars as few-shot examples in episode prompts in
+// place of the configured ones, sharing this pipeline's connections; closing either closes both.
+func (p *RAGPipeline) WithExemplars(exemplars []narrative.Exemplar) *RAGPipeline {
+	exemplified := *p
+	exemplified.config.Exemplars = exemplars
+	exemplified.prompts = p.prompts.WithExemplars(exemplars)
+	return &exemplified
+}
+
+// RedactionReport returns what the pipeline redacted from episodes before embedding or prompting them.
+func (p *RAGPipeline) RedactionReport() redact.Report {
+	return p.redactor.Report()
+}
+
+// traced returns a copy of the pipeline whose prompt templates record the provenance of each
+// prompt they build in trace
+func (p *RAGPipeline) traced(trace *narrative.Provenance) *RAGPipeline {
+	traced := *p
+	traced.prompts = p.prompts.WithTrace(trace)
+	return &traced
+}
+
+// logger returns the logger for work under ctx, naming the pipeline's repository when it has one
+func (p *RAGPipeline) logger(ctx context.Context) *slog.Logger {
+	log := logging.FromContext(ctx).With(logging.ComponentKey, "rag")
+	if p.config.Repository != "" {
+		log = log.With(logging.RepositoryKey, p.config.Repository)
+	}
+	return log
+}
+
 // IndexEpisodes indexes episode summaries into the vector store.
 // This should be called before generating narratives to ensure episodes are searchable.
+// Episodes already indexed under the same content-derived ID are skipped unless ReindexOnDemand is set.
+// With a StateStore, episodes are skipped when their content hash matches the one recorded instead.
 func (p *RAGPipeline) IndexEpisodes(ctx context.Context, episodes []cluster.Episode) error {
-	log.Printf("[RAG Pipeline] Indexing %d episodes", len(episodes))
+	err := p.indexEpisodes(ctx, episodes)
+	if err == nil {
+		err = episodesIndexed(ctx, episodes)
+	}
+	return reportError(ctx, err)
+}
 
-	// Convert episodes to summaries
-	summaries := make([]rag.EpisodeSummary, len(episodes))
-	for i, ep := range episodes {
-		startDate, endDate := ep.GetDateRange()
+// indexEpisodes indexes episode summaries into the vector store, without raising events
+func (p *RAGPipeline) indexEpisodes(ctx context.Context, episodes []cluster.Episode) error {
+	p.logger(ctx).Info("Indexing episodes", "episodes", len(episodes))
+
+	// Set up indexing options
+	// Skipping existing episodes also resumes an index interrupted part way through
+	opts := p.indexOptions()
+	opts.ForceReindex = p.config.ReindexOnDemand
+	opts.SkipExisting = !p.config.ReindexOnDemand
+
+	summaries := p.episodeSummaries(p.redactor.Episodes(episodes))
+	var hashes map[string]map[string]string
+	if p.config.StateStore != nil {
+		var err error
+		if summaries, hashes, err = p.changedSummaries(ctx, summaries, p.config.ReindexOnDemand); err != nil {
+			return err
+		}
+		// Changed episodes replace their records, which is harmless for new ones
+		opts.ForceReindex, opts.SkipExisting = true, false
+		p.logger(ctx).Info("Found episodes new or changed since they were last indexed", "changed", len(summaries))
+	}
+
+	// Index episodes
+	if err := rag.IndexEpisodes(ctx, summaries, p.embedder, p.vectorStore, opts); err != nil {
+		return fmt.Errorf("failed to index episodes: %w", err)
+	}
+	if err := p.recordIndexed(ctx, hashes); err != nil {
+		return err
+	}
+
+	p.logger(ctx).Info("Indexed episodes", "episodes", len(episodes))
+	return nil
+}
+
+// SyncEpisodes brings the index in line with regrouped episodes.
+// Changed episodes are re-embedded even if already indexed, and removed episode IDs are deleted.
+func (p *RAGPipeline) SyncEpisodes(ctx context.Context, changed []cluster.Episode, removed []string) error {
+	err := p.syncEpisodes(ctx, changed, removed)
+	if err == nil && len(changed) > 0 {
+		err = episodesIndexed(ctx, changed)
+	}
+	return reportError(ctx, err)
+}
+
+// syncEpisodes brings the index in line with regrouped episodes, without raising events
+func (p *RAGPipeline) syncEpisodes(ctx context.Context, changed []cluster.Episode, removed []string) error {
+	if len(removed) > 0 {
+		if err := p.vectorStore.Delete(ctx, p.config.Repository, removed); err != nil {
+			return fmt.Errorf("failed to delete removed episodes: %w", err)
+		}
+		if p.config.StateStore != nil {
+			if err := p.config.StateStore.ForgetIndexed(ctx, p.config.Repository, removed); err != nil {
+				return err
+			}
+		}
+	}
+
+	opts := p.indexOptions()
+	opts.ForceReindex = true
+	summaries := p.episodeSummaries(p.redactor.Episodes(changed))
+	if err := rag.IndexEpisodes(ctx, summaries, p.embedder, p.vectorStore, opts); err != nil {
+		return fmt.Errorf("failed to reindex episodes: %w", err)
+	}
+	if p.config.StateStore != nil {
+		_, hashes, err := p.changedSummaries(ctx, summaries, true)
+		if err != nil {
+			return err
+		}
+		if err := p.recordIndexed(ctx, hashes); err != nil {
+			return err
+		}
+	}
+
+	p.logger(ctx).Info("Synced episodes", "changed", len(changed), "removed", len(removed))
+	return nil
+}
+
+// changedSummaries returns the summaries whose content hash differs from the one the state store
+// recorded when they were last indexed, or all of them with all set, and the hashes of those
+// returned by repository and episode ID
+func (p *RAGPipeline) changedSummaries(ctx context.Context, summaries []rag.EpisodeSummary, all bool) ([]rag.EpisodeSummary, map[string]map[string]string, error) {
+	indexed := make(map[string]map[string]string)
+	hashes := make(map[string]map[string]string)
+	changed := make([]rag.EpisodeSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if _, ok := indexed[summary.Repository]; !ok && !all {
+			recorded, err := p.config.StateStore.IndexedHashes(ctx, summary.Repository)
+			if err != nil {
+				return nil, nil, err
+			}
+			indexed[summary.Repository] = recorded
+		}
+		hash, err := summaryHash(summary)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !all && indexed[summary.Repository][summary.EpisodeID] == hash {
+			continue
+		}
+		if hashes[summary.Repository] == nil {
+			hashes[summary.Repository] = make(map[string]string)
+		}
+		hashes[summary.Repository][summary.EpisodeID] = hash
+		changed = append(changed, summary)
+	}
+	return changed, hashes, nil
+}
+
+// recordIndexed records the content hashes of episodes just indexed in the state store
+func (p *RAGPipeline) recordIndexed(ctx context.Context, hashes map[string]map[string]string) error {
+	for repository, episodes := range hashes {
+		if err := p.config.StateStore.RecordIndexed(ctx, repository, episodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		summaryText := generateEpisodeSummaryText(&ep)
+// summaryHash hashes everything an episode's index records are built from
+func summaryHash(summary rag.EpisodeSummary) (string, error) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash episode %s: %w", summary.EpisodeID, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// indexOptions returns the indexing options shared by IndexEpisodes and SyncEpisodes
+func (p *RAGPipeline) indexOptions() rag.IndexOptions {
+	return rag.IndexOptions{
+		BatchSize:    10,
+		EmbedWorkers: p.config.IndexWorkers,
+		Progress:     p.config.IndexProgress,
+	}
+}
 
-		summaries[i] = rag.EpisodeSummary{
+// episodeSummaries converts episodes to index summaries under their repository or the pipeline's
+// The in-progress episode changes between runs, so it is never persisted in the index
+func (p *RAGPipeline) episodeSummaries(episodes []cluster.Episode) []rag.EpisodeSummary {
+	summaries := make([]rag.EpisodeSummary, 0, len(episodes))
+	for _, ep := range episodes {
+		if ep.IsWorkingEpisode() {
+			continue
+		}
+
+		startDate, endDate := ep.GetDateRange()
+
+		summaries = append(summaries, rag.EpisodeSummary{
 			EpisodeID:   ep.ID,
+			Repository:  p.repositoryOf(&ep),
 			Title:       generateEpisodeTitle(&ep),
-			Summary:     summaryText,
+			Summary:     generateEpisodeSummaryText(&ep),
 			StartDate:   startDate,
 			EndDate:     endDate,
 			Authors:     ep.GetAuthorNames(),
+			Paths:       changedPaths(ep.Commits),
 			CommitCount: len(ep.Commits),
 			FileCount:   ep.GetFileCount(),
-		}
+			Chunks:      p.episodeChunks(&ep),
+		})
 	}
+	return summaries
+}
 
-	// Set up indexing options
-	opts := rag.IndexOptions{
-		BatchSize:    10,
-		ForceReindex: p.config.ReindexOnDemand,
-		SkipExisting: !p.config.ReindexOnDemand,
+// repositoryOf returns the repository an episode is indexed under
+func (p *RAGPipeline) repositoryOf(ep *cluster.Episode) string {
+	if ep.Repository != "" {
+		return ep.Repository
 	}
+	return p.config.Repository
+}
 
-	// Index episodes
-	if err := rag.IndexEpisodes(ctx, summaries, p.embedder, p.vectorStore, opts); err != nil {
-		return fmt.Errorf("failed to index episodes: %w", err)
+// searchOptions scopes retrieval to the pipeline's repository, if it has one
+func (p *RAGPipeline) searchOptions() *rag.SearchOptions {
+	if p.config.Repository == "" {
+		return nil
 	}
+	return &rag.SearchOptions{Repository: p.config.Repository}
+}
 
-	log.Printf("[RAG Pipeline] Successfully indexed %d episodes", len(episodes))
-	return nil
+// querySearchOptions scopes project queries to the pipeline's repository, granularities and filter
+func (p *RAGPipeline) querySearchOptions() *rag.SearchOptions {
+	granularities := p.config.Granularities
+	if len(granularities) == 0 {
+		granularities = []rag.Granularity{rag.GranularityEpisode}
+	}
+	return &rag.SearchOptions{
+		Repository:     p.config.Repository,
+		Granularities:  granularities,
+		MetadataFilter: p.config.Filter,
+	}
+}
+
+// contextBudget returns how much retrieved context prompts may include
+func (p *RAGPipeline) contextBudget() rag.ContextBudget {
+	return rag.ContextBudget{
+		MinScore:  p.config.MinScore,
+		MaxTokens: p.config.MaxContextTokens,
+		MaxChunks: p.config.MaxContextSize,
+		Tokenizer: p.tokenizer,
+	}
 }
 
 // GenerateEpisodeNarrativeRAG generates a narrative for a specific episode using RAG.
 // The pipeline: retrieval -> prompt assembly -> LLM generation -> Narrative
+// With a narrative cache, an episode whose commits and artifacts are unchanged gets its cached
+// narrative back without retrieval or LLM calls.
 func (p *RAGPipeline) GenerateEpisodeNarrativeRAG(
 	ctx context.Context,
 	episode *cluster.Episode,
@@ -157,46 +691,67 @@ func (p *RAGPipeline) GenerateEpisodeNarrativeRAG(
 		return nil, fmt.Errorf("episode cannot be nil")
 	}
 
-	log.Printf("[RAG Pipeline] Generating narrative for episode %s", episode.ID)
+	ctx = logging.With(ctx, logging.EpisodeKey, episode.ID)
+	content, types := p.episodeCacheEntry(episode)
+	narr, err := p.cachedNarrative(ctx, episode.ID, content, types, func() (*narrative.Narrative, error) {
+		return p.generateEpisodeNarrative(ctx, episode)
+	})
+	return narr, reportError(ctx, err)
+}
+
+// generateEpisodeNarrative runs the RAG stages for an episode's narrative, bypassing the cache
+func (p *RAGPipeline) generateEpisodeNarrative(ctx context.Context, episode *cluster.Episode) (*narrative.Narrative, error) {
+	log := p.logger(ctx)
+	log.Info("Generating episode narrative")
 
 	// Stage 1: Retrieval - Get similar episodes as context
-	log.Printf("[RAG Pipeline] Stage 1: Retrieving top-%d similar episodes", p.config.TopK)
+	log.Debug("Stage 1: retrieving similar episodes", "top_k", p.config.TopK)
 	contextChunks, err := p.retriever.RetrieveContextForEpisode(
 		ctx,
 		episode.ID,
 		p.config.TopK,
-		nil, // No filtering
+		p.searchOptions(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("retrieval failed: %w", err)
 	}
-	log.Printf("[RAG Pipeline] Retrieved %d context chunks", len(contextChunks))
+	log.Debug("Retrieved context", "chunks", len(contextChunks))
 
-	// Apply max context size limit
-	if len(contextChunks) > p.config.MaxContextSize {
-		contextChunks = contextChunks[:p.config.MaxContextSize]
-		log.Printf("[RAG Pipeline] Trimmed context to %d chunks (max size)", p.config.MaxContextSize)
+	// Keep the chunks that fit the context budget
+	if selected := rag.SelectContext(contextChunks, p.contextBudget()); len(selected) < len(contextChunks) {
+		log.Debug("Trimmed context to the context budget", "from", len(contextChunks), "to", len(selected))
+		contextChunks = selected
 	}
 
 	// Stage 2: Prompt Assembly - Build prompt with episode and context
-	log.Printf("[RAG Pipeline] Stage 2: Assembling prompt with %d context chunks", len(contextChunks))
-	prompt, err := narrative.AssemblePrompt(episode, contextChunks)
+	log.Debug("Stage 2: assembling prompt", "chunks", len(contextChunks))
+	// Deferred patches are loaded first, so they are redacted with the rest of the episode
+	if err := p.config.Diffs.Load(ctx, episode); err != nil {
+		log.Warn("Failed to load diffs, leaving them out of the prompt", "error", err)
+	}
+	episode = &p.classifyEpisodes(ctx, p.redactor.Episodes([]cluster.Episode{*episode}))[0]
+	var trace narrative.Provenance
+	prompt, err := p.traced(&trace).episodePrompt(ctx, episode, contextChunks)
 	if err != nil {
 		return nil, fmt.Errorf("prompt assembly failed: %w", err)
 	}
-	log.Printf("[RAG Pipeline] Assembled prompt (%d characters)", len(prompt))
+	log.Debug("Assembled prompt", "characters", len(prompt))
 
 	// Stage 3: LLM Generation - Generate narrative
-	log.Printf("[RAG Pipeline] Stage 3: Generating narrative with LLM")
+	log.Debug("Stage 3: generating narrative with LLM")
 	narr, err := p.generator.Generate(ctx, episode.ID, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("narrative generation failed: %w", err)
 	}
-	log.Printf("[RAG Pipeline] Successfully generated narrative (%d characters)", len(narr.Text))
+	narr.Provenance = &trace
+	log.Info("Generated episode narrative", "characters", len(narr.Text), "model", narr.Model)
 
 	return narr, nil
 }
 
+// releaseRegex matches version references such as "v1.2", "1.2.3" or "v2.0.0-rc.1"
+var releaseRegex = regexp.MustCompile(`(?i)\b(v?\d+(?:\.\d+)+(?:-[0-9a-z.]+)?)\b`)
+
 // GenerateProjectNarrativeRAG generates a project-level narrative using RAG.
 // This retrieves relevant episodes across the entire repository to create a high-level summary.
 func (p *RAGPipeline) GenerateProjectNarrativeRAG(
@@ -204,20 +759,41 @@ func (p *RAGPipeline) GenerateProjectNarrativeRAG(
 	query string,
 	episodes []cluster.Episode,
 ) (*narrative.Narrative, error) {
-	log.Printf("[RAG Pipeline] Generating project narrative for query: %s", query)
+	narr, err := p.generateProjectNarrative(ctx, query, episodes)
+	return narr, reportError(ctx, err)
+}
+
+// generateProjectNarrative runs the RAG stages for a project-level narrative answering query
+func (p *RAGPipeline) generateProjectNarrative(ctx context.Context, query string, episodes []cluster.Episode) (*narrative.Narrative, error) {
+	log := p.logger(ctx)
+	log.Info("Generating project narrative", "query", query)
+	episodes = p.redactor.Episodes(episodes)
 
 	// Stage 1: Retrieval - Get most relevant episodes for the query
-	log.Printf("[RAG Pipeline] Stage 1: Retrieving top-%d relevant episodes", p.config.TopK)
-	contextChunks, err := p.retriever.RetrieveContextForQuery(
-		ctx,
-		query,
-		p.config.TopK,
-		nil, // No filtering
-	)
+	var contextChunks []rag.ContextChunk
+	var err error
+	if p.config.AdaptiveTopK {
+		log.Debug("Stage 1: retrieving relevant episodes within the context budget", "top_k", p.config.TopK)
+		contextChunks, err = p.retriever.RetrieveContextForQueryWithinBudget(
+			ctx,
+			query,
+			p.config.TopK,
+			p.contextBudget(),
+			p.querySearchOptions(),
+		)
+	} else {
+		log.Debug("Stage 1: retrieving relevant episodes", "top_k", p.config.TopK)
+		contextChunks, err = p.retriever.RetrieveContextForQuery(
+			ctx,
+			query,
+			p.config.TopK,
+			p.querySearchOptions(),
+		)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("retrieval failed: %w", err)
 	}
-	log.Printf("[RAG Pipeline] Retrieved %d context chunks", len(contextChunks))
+	log.Debug("Retrieved context", "chunks", len(contextChunks))
 
 	// Hybrid Search: Check for specific PR/Issue references in the query
 	// If found, manually find the episode containing that artifact and add it to context
@@ -239,28 +815,18 @@ func (p *RAGPipeline) GenerateProjectNarrativeRAG(
 					}
 
 					if found {
-						// Check if already in context
+						// Check if already in context; a commit from the episode doesn't cover the whole of it
 						alreadyInContext := false
 						for _, chunk := range contextChunks {
-							if chunk.EpisodeID == ep.ID {
+							if chunk.EpisodeID == ep.ID && chunk.IsEpisode() {
 								alreadyInContext = true
 								break
 							}
 						}
 
 						if !alreadyInContext {
-							// Create a synthetic chunk
-							startDate, endDate := ep.GetDateRange()
-							chunk := rag.ContextChunk{
-								EpisodeID:   ep.ID,
-								Text:        generateEpisodeSummaryText(&ep),
-								Score:       1.0, // Max score for exact match
-								StartDate:   startDate,
-								EndDate:     endDate,
-								Authors:     ep.GetAuthorNames(),
-								CommitCount: len(ep.Commits),
-								FileCount:   ep.GetFileCount(),
-							}
+							// Create a synthetic chunk with max score for exact match
+							chunk := episodeContextChunk(&ep, 1.0)
 
 							// Prepend to context chunks
 							contextChunks = append([]rag.ContextChunk{chunk}, contextChunks...)
@@ -272,48 +838,128 @@ func (p *RAGPipeline) GenerateProjectNarrativeRAG(
 		}
 	}
 
-	// Apply max context size limit
-	if len(contextChunks) > p.config.MaxContextSize {
-		contextChunks = contextChunks[:p.config.MaxContextSize]
-		log.Printf("[RAG Pipeline] Trimmed context to %d chunks (max size)", p.config.MaxContextSize)
+	// Version references ("what shipped in v1.2") pull in the episode that shipped that release
+	for _, match := range releaseRegex.FindAllStringSubmatch(query, -1) {
+		for _, ep := range episodes {
+			if !ep.HasRelease(match[1]) {
+				continue
+			}
+
+			alreadyInContext := false
+			for _, chunk := range contextChunks {
+				if chunk.EpisodeID == ep.ID && chunk.IsEpisode() {
+					alreadyInContext = true
+					break
+				}
+			}
+			if !alreadyInContext {
+				contextChunks = append([]rag.ContextChunk{episodeContextChunk(&ep, 1.0)}, contextChunks...)
+			}
+			break
+		}
+	}
+
+	// Work that is not on the remote yet is never in the index, so always include it directly
+	for _, ep := range episodes {
+		if ep.IsWorkingEpisode() {
+			contextChunks = append([]rag.ContextChunk{episodeContextChunk(&ep, 1.0)}, contextChunks...)
+			break
+		}
+	}
+
+	// Keep the chunks that fit the context budget
+	if selected := rag.SelectContext(contextChunks, p.contextBudget()); len(selected) < len(contextChunks) {
+		log.Debug("Trimmed context to the context budget", "from", len(contextChunks), "to", len(selected))
+		contextChunks = selected
 	}
 
-	// Stage 2: Assemble prompt with query and retrieved context
-	log.Printf("[RAG Pipeline] Stage 2: Assembling project-level prompt with %d context chunks", len(contextChunks))
-	prompt := assembleProjectQueryPrompt(query, episodes, contextChunks)
-	log.Printf("[RAG Pipeline] Assembled prompt (%d characters)", len(prompt))
+	// Stage 2: Assemble prompt with query and retrieved context, organized by story arc
+	episodes = p.classifyEpisodes(ctx, episodes)
+	arcs := p.projectArcs(ctx, episodes)
+	log.Debug("Stage 2: assembling project-level prompt", "arcs", len(arcs), "chunks", len(contextChunks))
+	var trace narrative.Provenance
+	prompt, err := assembleProjectQueryPrompt(p.prompts.WithTrace(&trace), query, episodes, arcs, contextChunks)
+	if err != nil {
+		return nil, fmt.Errorf("prompt assembly failed: %w", err)
+	}
+	log.Debug("Assembled prompt", "characters", len(prompt))
 	narr, err := p.generator.Generate(ctx, "project", prompt)
 	if err != nil {
 		return nil, fmt.Errorf("narrative generation failed: %w", err)
 	}
-	log.Printf("[RAG Pipeline] Successfully generated project narrative (%d characters)", len(narr.Text))
+	narr.Provenance = &trace
+	log.Info("Generated project narrative", "characters", len(narr.Text), "model", narr.Model)
+	if err := narrativeGenerated(ctx, narr); err != nil {
+		return nil, err
+	}
 
 	return narr, nil
 }
 
+// classifyEpisodes returns a copy of the episodes with the uncategorized ones classified by the LLM
+// when ClassifyWithLLM is set; failures are logged and leave the remaining episodes uncategorized
+func (p *RAGPipeline) classifyEpisodes(ctx context.Context, episodes []cluster.Episode) []cluster.Episode {
+	if !p.config.ClassifyWithLLM || p.llm == nil {
+		return episodes
+	}
+
+	classified := make([]cluster.Episode, len(episodes))
+	copy(classified, episodes)
+	if err := cluster.ClassifyUncategorized(ctx, classified, p.llm); err != nil {
+		p.logger(ctx).Warn("Failed to classify episodes", "error", err)
+		report.FromContext(ctx).Fail(report.StageClassification, "", err)
+	}
+	return classified
+}
+
+// projectArcs groups episodes into story arcs for project-level prompts
+// Semantic arcs embed with the pipeline's embedder; failures are logged and leave the prompt without arcs
+func (p *RAGPipeline) projectArcs(ctx context.Context, episodes []cluster.Episode) []cluster.Arc {
+	var arcs []cluster.Arc
+	var err error
+	if p.clusterer != nil {
+		arcs, err = p.clusterer.GroupIntoArcs(ctx, episodes)
+	} else {
+		config := p.config.Arcs
+		if config.Strategy == cluster.ArcBySemantic && config.Embedder == nil && p.embedder != nil {
+			config.Embedder = rag.NewClusterEmbedder(p.embedder)
+		}
+		arcs, err = cluster.GroupIntoArcs(ctx, episodes, config)
+	}
+	if err != nil {
+		p.logger(ctx).Warn("Failed to group episodes into arcs", "error", err)
+		report.FromContext(ctx).Fail(report.StageArcs, "", err)
+		return nil
+	}
+	return arcs
+}
+
 // GenerateMultipleNarrativesRAG generates narratives for multiple episodes efficiently.
 func (p *RAGPipeline) GenerateMultipleNarrativesRAG(
 	ctx context.Context,
 	episodes []cluster.Episode,
 ) ([]*narrative.Narrative, error) {
-	log.Printf("[RAG Pipeline] Generating narratives for %d episodes", len(episodes))
+	log := p.logger(ctx)
+	log.Info("Generating episode narratives", "episodes", len(episodes))
 
 	narratives := make([]*narrative.Narrative, 0, len(episodes))
 
 	for i, episode := range episodes {
-		log.Printf("[RAG Pipeline] Processing episode %d/%d: %s", i+1, len(episodes), episode.ID)
+		log.Debug("Processing episode", logging.EpisodeKey, episode.ID, "position", i+1, "episodes", len(episodes))
 
 		narr, err := p.GenerateEpisodeNarrativeRAG(ctx, &episode)
 		if err != nil {
-			log.Printf("[RAG Pipeline] Warning: Failed to generate narrative for episode %s: %v", episode.ID, err)
+			log.Warn("Failed to generate episode narrative", logging.EpisodeKey, episode.ID, "error", err)
 			// Continue with remaining episodes
+			report.FromContext(ctx).Skip(report.StageNarratives, episode.ID, err)
 			continue
 		}
 
 		narratives = append(narratives, narr)
+		report.FromContext(ctx).Succeed(report.StageNarratives, 1)
 	}
 
-	log.Printf("[RAG Pipeline] Successfully generated %d/%d narratives", len(narratives), len(episodes))
+	log.Info("Generated episode narratives", "generated", len(narratives), "episodes", len(episodes))
 	return narratives, nil
 }
 
@@ -331,9 +977,33 @@ func generateEpisodeTitle(ep *cluster.Episode) string {
 	return fmt.Sprintf("Episode %s", ep.ID)
 }
 
+// episodeContextChunk builds a context chunk directly from an episode, bypassing retrieval
+func episodeContextChunk(ep *cluster.Episode, score float32) rag.ContextChunk {
+	startDate, endDate := ep.GetDateRange()
+	return rag.ContextChunk{
+		EpisodeID:   ep.ID,
+		Repository:  ep.Repository,
+		Text:        generateEpisodeSummaryText(ep),
+		Score:       score,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Authors:     ep.GetAuthorNames(),
+		CommitCount: len(ep.Commits),
+		FileCount:   ep.GetFileCount(),
+	}
+}
+
 func generateEpisodeSummaryText(ep *cluster.Episode) string {
 	var summary string
 
+	// Name the repository so organization-wide queries can tell episodes apart
+	if ep.Repository != "" {
+		summary += fmt.Sprintf("Repository: %s\n\n", ep.Repository)
+	}
+	if ep.Category != "" {
+		summary += fmt.Sprintf("Category: %s\n\n", ep.Category)
+	}
+
 	// Add commit information
 	if len(ep.Commits) > 0 {
 		summary += fmt.Sprintf("Commits (%d):\n", len(ep.Commits))
@@ -358,8 +1028,12 @@ func generateEpisodeSummaryText(ep *cluster.Episode) string {
 			artifactType := artifact.Type
 			if artifactType == cluster.ArtifactPullRequest {
 				summary += fmt.Sprintf("- PR #%d: %s\n", artifact.Number, artifact.Title)
+			} else if artifactType == cluster.ArtifactRelease {
+				summary += fmt.Sprintf("- Release %s: %s\n", artifact.Metadata.TagName, artifact.Title)
 			} else if artifactType == cluster.ArtifactIssue {
 				summary += fmt.Sprintf("- Issue #%d: %s\n", artifact.Number, artifact.Title)
+			} else if artifactType == cluster.ArtifactTicket && artifact.Metadata.TicketKey != "" {
+				summary += fmt.Sprintf("- Ticket %s: %s\n", artifact.Metadata.TicketKey, artifact.Title)
 			} else {
 				summary += fmt.Sprintf("- %s #%d: %s\n", artifactType, artifact.Number, artifact.Title)
 			}
@@ -372,6 +1046,30 @@ func generateEpisodeSummaryText(ep *cluster.Episode) string {
 				}
 				summary += fmt.Sprintf("  Description: %s\n", desc)
 			}
+
+			if builds := artifact.GetBuildSummary(); builds.Total > 0 {
+				summary += fmt.Sprintf("  CI builds: %d total, %d failed\n", builds.Total, builds.Failed)
+			}
+
+			if threads := artifact.GetReviewThreads(); len(threads) > 0 {
+				open := len(artifact.GetOpenReviewThreads())
+				summary += fmt.Sprintf("  Review threads: %d open, %d resolved\n", open, len(threads)-open)
+			}
+		}
+	}
+
+	if iterations := ep.GetIterations(); len(iterations) > 0 {
+		titles := make([]string, len(iterations))
+		for i, progress := range iterations {
+			titles[i] = progress.Iteration.Title
+		}
+		summary += fmt.Sprintf("\nIterations: %s\n", strings.Join(titles, ", "))
+	}
+
+	if stats := ep.Stats; stats.FilesChanged > 0 {
+		summary += fmt.Sprintf("\nSize: +%d/-%d lines in %d files\n", stats.Additions, stats.Deletions, stats.FilesChanged)
+		if len(stats.TopFiles) > 0 {
+			summary += fmt.Sprintf("Top files: %s\n", strings.Join(stats.TopFiles, ", "))
 		}
 	}
 
@@ -439,63 +1137,102 @@ func getLatestDate(episodes []cluster.Episode) time.Time {
 	return latest
 }
 
-// assembleProjectQueryPrompt creates a prompt for answering a specific query about the project
-func assembleProjectQueryPrompt(query string, episodes []cluster.Episode, contextChunks []rag.ContextChunk) string {
-	var b strings.Builder
+// projectHotspotLimit caps the hotspots listed in project-level prompts
+const projectHotspotLimit = 10
 
-	b.WriteString("You are a technical writer specializing in software development narratives. ")
-	b.WriteString("Your task is to answer the following question about a software project ")
-	b.WriteString("based on the development history and relevant context provided.\n\n")
+// projectArcEpisodeLimit caps the episodes listed per arc in project-level prompts
+const projectArcEpisodeLimit = 5
 
-	b.WriteString("# Question\n\n")
-	b.WriteString(fmt.Sprintf("%s\n\n", query))
+// assembleProjectQueryPrompt creates a prompt for answering a specific query about the project
+// Arcs, when given, outline the project top-down before the retrieved episodes, which are tagged with their arc
+func assembleProjectQueryPrompt(prompts *narrative.PromptTemplates, query string, episodes []cluster.Episode, arcs []cluster.Arc, contextChunks []rag.ContextChunk) (string, error) {
+	return prompts.ProjectPrompt(projectPromptData(query, episodes, arcs, contextChunks))
+}
 
-	// Project overview
-	totalCommits := 0
-	allAuthors := make(map[string]bool)
-	var earliest, latest time.Time
+// projectPromptData gathers what the project template renders: an overview of all episodes,
+// the story arcs, the hotspots and the retrieved context under headings naming their episode and arc
+func projectPromptData(query string, episodes []cluster.Episode, arcs []cluster.Arc, contextChunks []rag.ContextChunk) narrative.ProjectPromptData {
+	data := narrative.ProjectPromptData{
+		Question:     query,
+		Episodes:     len(episodes),
+		Commits:      cluster.CountUniqueCommits(episodes),
+		Repositories: episodeRepositories(episodes),
+		WorkMix:      cluster.FormatCategoryCounts(cluster.CountCategories(episodes)),
+	}
 
+	allAuthors := make(map[string]bool)
 	for _, ep := range episodes {
-		totalCommits += len(ep.Commits)
 		for _, commit := range ep.Commits {
 			allAuthors[commit.Author.Name] = true
-			if earliest.IsZero() || commit.CommittedAt.Before(earliest) {
-				earliest = commit.CommittedAt
+			if data.Start.IsZero() || commit.CommittedAt.Before(data.Start) {
+				data.Start = commit.CommittedAt
 			}
-			if latest.IsZero() || commit.CommittedAt.After(latest) {
-				latest = commit.CommittedAt
+			if data.End.IsZero() || commit.CommittedAt.After(data.End) {
+				data.End = commit.CommittedAt
 			}
 		}
 	}
+	data.Contributors = len(allAuthors)
 
-	b.WriteString("# Project Overview\n\n")
-	b.WriteString(fmt.Sprintf("**Episodes:** %d development episodes\n\n", len(episodes)))
-	b.WriteString(fmt.Sprintf("**Total Commits:** %d commits\n\n", totalCommits))
-	b.WriteString(fmt.Sprintf("**Contributors:** %d unique authors\n\n", len(allAuthors)))
-	if !earliest.IsZero() && !latest.IsZero() {
-		b.WriteString(fmt.Sprintf("**Time Range:** %s to %s\n\n", earliest.Format("2006-01-02"), latest.Format("2006-01-02")))
+	for _, quarter := range cluster.CountCategoriesByQuarter(episodes) {
+		data.Quarters = append(data.Quarters, narrative.QuarterMix{Quarter: quarter.Quarter, Mix: cluster.FormatCategoryCounts(quarter.Counts)})
 	}
 
-	// Relevant context from RAG retrieval
-	if len(contextChunks) > 0 {
-		b.WriteString("# Relevant Development History\n\n")
-		b.WriteString("The following episodes are most relevant to your question:\n\n")
+	episodeArcs := make(map[string]string)
+	for _, arc := range arcs {
+		start, end := arc.GetDateRange()
+		arcData := narrative.ArcPromptData{
+			ID:           arc.ID,
+			Title:        arc.Title,
+			Start:        start,
+			End:          end,
+			EpisodeCount: len(arc.Episodes),
+			Commits:      arc.GetCommitCount(),
+			MoreEpisodes: max(len(arc.Episodes)-projectArcEpisodeLimit, 0),
+		}
+		for i, ep := range arc.Episodes {
+			episodeArcs[ep.ID] = arc.ID
+			if i >= projectArcEpisodeLimit {
+				continue
+			}
+			epStart, _ := ep.GetDateRange()
+			title, _, _ := strings.Cut(generateEpisodeTitle(&ep), "\n")
+			arcData.Episodes = append(arcData.Episodes, narrative.ArcEpisodePromptData{ID: ep.ID, Start: epStart, Category: ep.Category, Title: title})
+		}
+		data.Arcs = append(data.Arcs, arcData)
+	}
 
-		for i, ch := range contextChunks {
-			b.WriteString(fmt.Sprintf("## Episode %d: %s (relevance: %.2f)\n\n", i+1, ch.EpisodeID, ch.Score))
-			b.WriteString(ch.Text + "\n\n")
+	hotspots := cluster.ComputeHotspots(episodes)
+	data.Hotspots = hotspots[:min(len(hotspots), projectHotspotLimit)]
+
+	for i, ch := range contextChunks {
+		// Commits and chunks name the episode they came from
+		heading := fmt.Sprintf("Episode %d: %s", i+1, ch.EpisodeID)
+		switch ch.Granularity {
+		case rag.GranularityCommit:
+			heading = fmt.Sprintf("Commit %d: %s from episode %s", i+1, ch.CommitHash(), ch.EpisodeID)
+		case rag.GranularityChunk:
+			heading = fmt.Sprintf("Excerpt %d: %s of episode %s", i+1, ch.ChunkID, ch.EpisodeID)
 		}
+		if arcID, ok := episodeArcs[ch.EpisodeID]; ok {
+			heading += " in arc " + arcID
+		}
+		data.Context = append(data.Context, narrative.ProjectContextChunk{ContextChunk: ch, Heading: heading})
 	}
 
-	b.WriteString("# Task\n\n")
-	b.WriteString("Based on the relevant development history above, answer the question clearly and concisely.\n\n")
-	b.WriteString("Guidelines:\n")
-	b.WriteString("- Focus your answer specifically on what was asked\n")
-	b.WriteString("- Use 2-4 paragraphs unless the question requires more detail\n")
-	b.WriteString("- Base all statements strictly on the provided episode data\n")
-	b.WriteString("- Do not invent details or motivations not present in the history\n")
-	b.WriteString("- Use clear technical language and explain key concepts\n")
-	b.WriteString("- If the question cannot be fully answered from the available data, state what is known and what is uncertain\n\n")
+	return data
+}
 
-	return b.String()
+// episodeRepositories returns the distinct repositories episodes are tagged with, sorted
+func episodeRepositories(episodes []cluster.Episode) []string {
+	seen := make(map[string]bool)
+	repositories := make([]string, 0)
+	for _, ep := range episodes {
+		if ep.Repository != "" && !seen[ep.Repository] {
+			seen[ep.Repository] = true
+			repositories = append(repositories, ep.Repository)
+		}
+	}
+	sort.Strings(repositories)
+	return repositories
 }
@@ -0,0 +1,123 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/state"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// commitToLocalRepo writes and commits a file in a local test repository
+func commitToLocalRepo(t *testing.T, repo *gogit.Repository, dir, name, message string, when time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(message+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+	sig := &object.Signature{Name: "Alice", Email: "alice@example.com", When: when}
+	if _, err := wt.Commit(message, &gogit.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func TestAnalyzeRepositoryWithState_SQLite(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", baseTime)
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", baseTime.Add(time.Hour))
+
+	// Each run opens the database afresh, as separate invocations of the CLI do
+	statePath := filepath.Join(t.TempDir(), "state.sqlite")
+	config := cluster.DefaultGroupingConfig()
+	analyze := func() []cluster.Episode {
+		t.Helper()
+		store, err := state.NewSQLiteStore(ctx, statePath)
+		if err != nil {
+			t.Fatalf("Failed to open state store: %v", err)
+		}
+		defer store.Close()
+		episodes, err := AnalyzeRepositoryWithState(ctx, dir, store, config)
+		if err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+		return episodes
+	}
+
+	if episodes := analyze(); countEpisodeCommits(episodes) != 2 {
+		t.Fatalf("Expected 2 commits on first run, got %d", countEpisodeCommits(episodes))
+	}
+
+	// Second run with no new activity produces nothing
+	if episodes := analyze(); len(episodes) != 0 {
+		t.Errorf("Expected no episodes without new commits, got %d", len(episodes))
+	}
+
+	// Only the new commit is ingested
+	commitToLocalRepo(t, repo, dir, "c.txt", "Add c", baseTime.Add(2*time.Hour))
+	if episodes := analyze(); countEpisodeCommits(episodes) != 1 {
+		t.Errorf("Expected 1 new commit, got %d", countEpisodeCommits(episodes))
+	}
+}
+
+func TestAnalyzeRepositoryWithState(t *testing.T) {
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "a.txt", "Add a", baseTime)
+
+	store := state.NewMemoryStore()
+	config := cluster.DefaultGroupingConfig()
+	episodes, err := AnalyzeRepositoryWithState(ctx, dir, store, config)
+	if err != nil {
+		t.Fatalf("First analysis failed: %v", err)
+	}
+	first, _ := store.Load(ctx, RepositoryKey(dir))
+	if countEpisodeCommits(episodes) != 1 || first.LastCommitHash == "" || !first.LastCommitTime.Equal(baseTime) || first.EpisodeVersion != 1 {
+		t.Fatalf("Expected the first commit recorded as episode version 1, got %+v", first)
+	}
+
+	// Without new commits nothing is ingested, and the episode set stays current
+	if episodes, err = AnalyzeRepositoryWithState(ctx, dir, store, config); err != nil || len(episodes) != 0 {
+		t.Fatalf("Expected no episodes without new commits, got %d (error %v)", len(episodes), err)
+	}
+	commitToLocalRepo(t, repo, dir, "b.txt", "Add b", baseTime.Add(time.Hour))
+	if episodes, err = AnalyzeRepositoryWithState(ctx, dir, store, config); err != nil || countEpisodeCommits(episodes) != 1 {
+		t.Fatalf("Expected only the new commit, got %d (error %v)", countEpisodeCommits(episodes), err)
+	}
+	latest, _ := store.Load(ctx, RepositoryKey(dir))
+	if latest.LastCommitHash == first.LastCommitHash || latest.EpisodeVersion != 2 || !latest.SyncedAt.IsZero() {
+		t.Errorf("Expected the checkpoint advanced and episode version 2 without a platform sync, got %+v", latest)
+	}
+}
+
+func countEpisodeCommits(episodes []cluster.Episode) int {
+	total := 0
+	for _, ep := range episodes {
+		total += len(ep.Commits)
+	}
+	return total
+}
@@ -0,0 +1,157 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/report"
+)
+
+// DefaultSubmoduleDepth limits how many levels of nested submodules are ingested
+const DefaultSubmoduleDepth = 3
+
+// AnalyzeRepositoryWithSubmodules analyzes a repository and recursively each of its submodules
+// Submodule episodes are returned after the superproject's, with IDs prefixed by the
+// submodule path (e.g. "libs/auth:<commit hash>") so episodes from different repositories stay distinct
+// Each submodule is ingested at the commit its superproject pins
+// Token is automatically loaded from GITHUB_TOKEN (GITLAB_TOKEN for GitLab) environment variable if not provided
+func AnalyzeRepositoryWithSubmodules(ctx context.Context, repo string, config cluster.GroupingConfig, token ...string) ([]cluster.Episode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled before analysis: %w", err)
+	}
+	ctx = logging.With(logging.WithRun(ctx), logging.RepositoryKey, repo)
+
+	auth, err := AuthFromEnv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load git credentials: %w", err)
+	}
+
+	// Credentials only go to the superproject's host, and to the submodules hosted there
+	auth = scopeAuth(auth, repo)
+
+	activity, repoData, err := ingestRepository(ctx, repo, ingestOptions{token: passedToken(token), envTokens: true, auth: auth})
+	if err != nil {
+		return nil, reportError(ctx, fmt.Errorf("failed to ingest repository: %w", err))
+	}
+
+	activity.Submodules = ingestSubmodules(ctx, repo, repoData.Submodules, auth, DefaultSubmoduleDepth)
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled after ingestion: %w", err)
+	}
+
+	episodes := groupActivityTree(ctx, activity, config, "")
+	if err := episodesCreated(ctx, episodes); err != nil {
+		return nil, reportError(ctx, err)
+	}
+	return episodes, nil
+}
+
+// ingestSubmodules ingests each submodule of a superproject, recursing up to depth levels
+// Submodules that cannot be opened or cloned are skipped with a warning
+// Their URLs come from the repository, so they get no API token, neither the one passed for the
+// superproject nor the platform tokens of the environment, and their artifacts are skipped; clone
+// credentials are only used for submodules on the superproject's host
+func ingestSubmodules(ctx context.Context, parent string, submodules []git.Submodule, auth git.AuthOptions, depth int) []cluster.RepositoryActivity {
+	if depth <= 0 || len(submodules) == 0 {
+		return nil
+	}
+
+	activities := make([]cluster.RepositoryActivity, 0, len(submodules))
+	for _, submodule := range submodules {
+		if ctx.Err() != nil {
+			break
+		}
+
+		source, err := submoduleSource(parent, submodule)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Skipping submodule", "submodule", submodule.Path, "error", err)
+			report.FromContext(ctx).Skip(report.StageSubmodules, submodule.Path, err)
+			continue
+		}
+		sourceAuth := submoduleAuth(auth, source)
+		activity, repoData, err := ingestRepository(ctx, source, ingestOptions{revision: submodule.Hash, auth: sourceAuth})
+		if err != nil {
+			logging.FromContext(ctx).Warn("Failed to ingest submodule", "submodule", submodule.Path, "error", err)
+			reportError(ctx, fmt.Errorf("failed to ingest submodule %s: %w", submodule.Path, err))
+			report.FromContext(ctx).Skip(report.StageSubmodules, submodule.Path, err)
+			continue
+		}
+		report.FromContext(ctx).Succeed(report.StageSubmodules, 1)
+
+		activity.SubmodulePath = submodule.Path
+		activity.Submodules = ingestSubmodules(ctx, source, repoData.Submodules, sourceAuth, depth-1)
+		activities = append(activities, *activity)
+	}
+
+	return activities
+}
+
+// submoduleSource returns where to read a submodule from
+// A checked-out submodule inside a local superproject is used directly; otherwise the URL from
+// .gitmodules is resolved against the superproject's origin remote, as git does, or its location
+// .gitmodules is up to the repository's authors, so paths leaving the superproject and URLs naming
+// local repositories are rejected: absolute paths, file:// URLs and relative URLs that leave the
+// superproject's directory or host
+func submoduleSource(parent string, submodule git.Submodule) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(submodule.Path)) {
+		return "", fmt.Errorf("submodule path %q leaves the superproject", submodule.Path)
+	}
+	checkout := filepath.Join(parent, filepath.FromSlash(submodule.Path))
+	if _, err := os.Stat(filepath.Join(checkout, ".git")); err == nil {
+		return checkout, nil
+	}
+
+	url := submodule.URL
+	if !strings.HasPrefix(url, "./") && !strings.HasPrefix(url, "../") {
+		if !IsRemoteRepository(url) {
+			return "", fmt.Errorf("submodule URL %q names a local repository", url)
+		}
+		return url, nil
+	}
+
+	base := parent
+	if !IsRemoteRepository(base) {
+		if gitRepo, err := git.OpenRepository(base); err == nil {
+			if origin := git.GetRemoteURL(gitRepo, "origin"); IsRemoteRepository(origin) {
+				base = origin
+			}
+		}
+	}
+	source := git.ResolveSubmoduleURL(base, url)
+	if IsRemoteRepository(base) {
+		if remoteHost(source) != remoteHost(base) {
+			return "", fmt.Errorf("submodule URL %q leaves the superproject's host", url)
+		}
+		return source, nil
+	}
+	if rel, err := filepath.Rel(base, source); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("submodule URL %q leaves the superproject", url)
+	}
+	return source, nil
+}
+
+// groupActivityTree groups a repository and its submodules into episodes
+// Episodes from submodules have their IDs prefixed with the submodule path
+func groupActivityTree(ctx context.Context, activity *cluster.RepositoryActivity, config cluster.GroupingConfig, prefix string) []cluster.Episode {
+	episodes := groupEpisodes(ctx, activity, config)
+	if prefix != "" {
+		for i := range episodes {
+			episodes[i].ID = prefix + ":" + episodes[i].ID
+		}
+	}
+
+	for i := range activity.Submodules {
+		submodule := &activity.Submodules[i]
+		episodes = append(episodes, groupActivityTree(ctx, submodule, config, path.Join(prefix, submodule.SubmodulePath))...)
+	}
+
+	return episodes
+}
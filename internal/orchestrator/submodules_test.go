@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+)
+
+func TestAnalyzeRepositoryWithSubmodules(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	libDir := t.TempDir()
+	libRepo, err := gogit.PlainInit(libDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init submodule repository: %v", err)
+	}
+	commitToLocalRepo(t, libRepo, libDir, "lib.go", "Add lib", baseTime)
+	libHead, _ := libRepo.Head()
+	// Made after the commit the superproject pins, so it must not be ingested
+	commitToLocalRepo(t, libRepo, libDir, "later.go", "Add unpinned work", baseTime.Add(time.Hour))
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "main.go", "Initial commit", baseTime.Add(48*time.Hour))
+
+	// Check out the submodule and pin it with a gitlink index entry
+	if _, err := gogit.PlainClone(filepath.Join(dir, "libs", "lib"), &gogit.CloneOptions{URL: libDir}); err != nil {
+		t.Fatalf("Failed to check out submodule: %v", err)
+	}
+	idx, _ := repo.Storer.Index()
+	entry := idx.Add("libs/lib")
+	entry.Hash = libHead.Hash()
+	entry.Mode = filemode.Submodule
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+	modules := "[submodule \"libs/lib\"]\n\tpath = libs/lib\n\turl = ../lib\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte(modules), 0o644); err != nil {
+		t.Fatalf("Failed to write .gitmodules: %v", err)
+	}
+	wt, _ := repo.Worktree()
+	if _, err := wt.Add(".gitmodules"); err != nil {
+		t.Fatalf("Failed to stage .gitmodules: %v", err)
+	}
+	commitToLocalRepo(t, repo, dir, "deps.txt", "Add lib submodule", baseTime.Add(49*time.Hour))
+
+	episodes, err := AnalyzeRepositoryWithSubmodules(context.Background(), dir, cluster.DefaultGroupingConfig())
+	if err != nil {
+		t.Fatalf("Failed to analyze repository: %v", err)
+	}
+
+	var superEpisodes, libEpisodes int
+	for _, episode := range episodes {
+		if strings.HasPrefix(episode.ID, "libs/lib:") {
+			libEpisodes++
+			if len(episode.Commits) != 1 || episode.Commits[0].Message != "Add lib" {
+				t.Errorf("Expected only the pinned submodule commit, got %d commits starting with %q", len(episode.Commits), episode.Commits[0].Message)
+			}
+		} else {
+			superEpisodes++
+		}
+	}
+
+	if superEpisodes == 0 {
+		t.Error("Expected superproject episodes")
+	}
+	if libEpisodes != 1 {
+		t.Errorf("Expected 1 submodule episode, got %d", libEpisodes)
+	}
+}
+
+func TestSubmoduleSource(t *testing.T) {
+	local := t.TempDir()
+	tests := []struct {
+		parent string
+		path   string
+		url    string
+		want   string // "" when the submodule must be rejected
+	}{
+		{"https://github.com/owner/app", "libs/lib", "https://github.com/owner/lib.git", "https://github.com/owner/lib.git"},
+		{"https://github.com/owner/app", "libs/lib", "../lib", "https://github.com/owner/lib"},
+		{"git@github.com:owner/app.git", "libs/lib", "../lib.git", "git@github.com:owner/lib.git"},
+		{"https://github.com/owner/app", "libs/lib", "../../../evil.example.com/lib", ""},
+		{"https://github.com/owner/app", "../outside", "https://github.com/owner/lib.git", ""},
+		{"https://github.com/owner/app", "libs/lib", "/etc/secrets", ""},
+		{"https://github.com/owner/app", "libs/lib", "file:///etc/secrets", ""},
+		{"https://github.com/owner/app", "libs/lib", "lib", ""},
+		{local, "libs/lib", "./vendor/lib", filepath.Join(local, "vendor", "lib")},
+		{local, "libs/lib", "../sibling", ""},
+	}
+	for _, tt := range tests {
+		got, err := submoduleSource(tt.parent, git.Submodule{Path: tt.path, URL: tt.url})
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("submoduleSource(%q, %q, %q) = %q, expected it rejected", tt.parent, tt.path, tt.url, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("submoduleSource(%q, %q, %q) = %q, %v, want %q", tt.parent, tt.path, tt.url, got, err, tt.want)
+		}
+	}
+}
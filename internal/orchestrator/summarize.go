@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/logging"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/rag"
+)
+
+const (
+	defaultSummarizeAbove = 200 // Commits and artifacts above which episodes are summarized in parts
+	defaultSummaryBatch   = 50  // Commits and artifacts per summarized part when the config leaves it unset
+	summaryMaxTokens      = 600 // Answer tokens per part summary
+	summaryWorkers        = 4   // Parts summarized concurrently
+)
+
+// summaryLLMConfig returns the LLM configuration part summaries are generated with: the narrative
+// LLM's, with SummaryModel in place of its model and a shorter answer
+func summaryLLMConfig(config RAGConfig) narrative.LLMConfig {
+	summary := config.LLMConfig
+	if config.SummaryModel != "" {
+		summary.Model = config.SummaryModel
+	}
+	summary.MaxTokens = summaryMaxTokens
+	return summary
+}
+
+// episodePrompt assembles the prompt for an episode's narrative, first summarizing the episode in
+// parts when it has more than SummarizeAbove commits and artifacts
+func (p *RAGPipeline) episodePrompt(ctx context.Context, episode *cluster.Episode, contextChunks []rag.ContextChunk) (string, error) {
+	if !p.summarizes(episode) {
+		return p.prompts.EpisodePrompt(episode, contextChunks)
+	}
+	return p.summarizedEpisodePrompt(ctx, episode, contextChunks)
+}
+
+// summarizes reports whether an episode's narrative is written from summaries of its parts
+func (p *RAGPipeline) summarizes(episode *cluster.Episode) bool {
+	return p.config.SummarizeAbove > 0 && len(episode.Commits)+len(episode.Artifacts) > p.config.SummarizeAbove
+}
+
+// summarizedEpisodePrompt summarizes the episode in chronological parts with the summary model,
+// then assembles the narrative prompt from those summaries in place of the full episode
+func (p *RAGPipeline) summarizedEpisodePrompt(ctx context.Context, episode *cluster.Episode, contextChunks []rag.ContextChunk) (string, error) {
+	batch := p.config.SummaryBatch
+	if batch <= 0 {
+		batch = defaultSummaryBatch
+	}
+	parts := episode.Split(batch)
+	if len(parts) == 1 {
+		// Nothing to summarize separately
+		return p.prompts.EpisodePrompt(episode, contextChunks)
+	}
+	p.logger(ctx).Info("Summarizing episode in parts", logging.EpisodeKey, episode.ID, "parts", len(parts))
+
+	summaries := make([]narrative.PartSummary, len(parts))
+	errs := make([]error, len(parts))
+	sem := make(chan struct{}, summaryWorkers)
+	var wg sync.WaitGroup
+	for i := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prompt, err := p.summaryPrompts.SummaryPrompt(&parts[i], i+1, len(parts))
+			if err != nil {
+				errs[i] = fmt.Errorf("part %d: %w", i+1, err)
+				return
+			}
+			summary, err := p.summarizer.Generate(ctx, parts[i].ID, prompt)
+			if err != nil {
+				errs[i] = fmt.Errorf("part %d: %w", i+1, err)
+				return
+			}
+			summaries[i] = narrative.NewPartSummary(&parts[i], i+1, summary.Text)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return "", fmt.Errorf("failed to summarize episode %s: %w", episode.ID, err)
+	}
+
+	return p.prompts.CombinePrompt(episode, summaries, contextChunks)
+}
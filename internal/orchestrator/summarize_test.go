@@ -0,0 +1,103 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+)
+
+// partLLM answers each summary prompt with the part it was asked to summarize
+type partLLM struct {
+	mu      sync.Mutex
+	prompts int
+	err     error
+}
+
+func (l *partLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prompts++
+	if l.err != nil {
+		return "", l.err
+	}
+	for line := range strings.SplitSeq(prompt, "\n") {
+		if part, ok := strings.CutPrefix(line, "# Part "); ok {
+			return "Summary of part " + part, nil
+		}
+	}
+	return "Summary", nil
+}
+
+func summaryTestPipeline(summarizeAbove int, llm narrative.LLM) *RAGPipeline {
+	return &RAGPipeline{
+		config:         RAGConfig{SummarizeAbove: summarizeAbove, SummaryBatch: 4},
+		prompts:        narrative.DefaultPromptTemplates(),
+		summarizer:     narrative.NewGenerator(llm, narrative.LLMConfig{}),
+		summaryPrompts: narrative.DefaultPromptTemplates(),
+	}
+}
+
+func summaryTestEpisode(commits int) *cluster.Episode {
+	ep := &cluster.Episode{ID: "E1"}
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < commits; i++ {
+		ep.Commits = append(ep.Commits, git.Commit{
+			Hash:        fmt.Sprintf("c%06d", i),
+			Message:     "Tune retries",
+			Author:      git.Author{Name: "Ann"},
+			CommittedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	return ep
+}
+
+func TestEpisodePrompt_Summarized(t *testing.T) {
+	llm := &partLLM{}
+	prompt, err := summaryTestPipeline(5, llm).episodePrompt(context.Background(), summaryTestEpisode(10), nil)
+	if err != nil {
+		t.Fatalf("episodePrompt failed: %v", err)
+	}
+	if llm.prompts != 3 {
+		t.Errorf("Expected 3 parts of 10 commits summarized, got %d", llm.prompts)
+	}
+	for _, want := range []string{"Summary of part 1 of 3", "Summary of part 2 of 3", "Summary of part 3 of 3"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+	if strings.Index(prompt, "part 1 of 3") > strings.Index(prompt, "part 3 of 3") {
+		t.Error("Expected part summaries in chronological order")
+	}
+}
+
+func TestEpisodePrompt_BelowThreshold(t *testing.T) {
+	llm := &partLLM{}
+	for _, above := range []int{0, 10} {
+		prompt, err := summaryTestPipeline(above, llm).episodePrompt(context.Background(), summaryTestEpisode(10), nil)
+		if err != nil {
+			t.Fatalf("episodePrompt failed: %v", err)
+		}
+		if !strings.Contains(prompt, "c000009") {
+			t.Errorf("SummarizeAbove %d: expected the full episode in the prompt", above)
+		}
+	}
+	if llm.prompts != 0 {
+		t.Errorf("Expected no summaries, got %d", llm.prompts)
+	}
+}
+
+func TestEpisodePrompt_SummaryError(t *testing.T) {
+	failure := errors.New("rate limited")
+	_, err := summaryTestPipeline(5, &partLLM{err: failure}).episodePrompt(context.Background(), summaryTestEpisode(10), nil)
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the summary error, got %v", err)
+	}
+}
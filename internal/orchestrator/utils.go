@@ -1,6 +1,8 @@
 package orchestrator
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/Yates-Labs/thunk/internal/cluster"
@@ -36,6 +38,26 @@ func extractRepoName(repo string) string {
 	return name
 }
 
+// RepositoryKey returns the name a repository's episodes are indexed under in a shared vector store:
+// owner/name for hosted URLs, and the directory name for local paths
+func RepositoryKey(repo string) string {
+	if _, owner, name := detectPlatform(repo); owner != "" {
+		return owner + "/" + name
+	}
+	if remoteHost(repo) == "" {
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+	}
+	return extractRepoName(repo)
+}
+
+// IsRemoteRepository reports whether repo names a remote repository (an http(s), ssh or scp-style
+// URL) rather than a local path or file:// URL
+func IsRemoteRepository(repo string) bool {
+	return remoteHost(repo) != ""
+}
+
 // detectPlatform detects the source platform from a repository URL
 // Returns platform, owner, and repo name
 func detectPlatform(repoURL string) (cluster.SourcePlatform, string, string) {
@@ -45,7 +67,17 @@ func detectPlatform(repoURL string) (cluster.SourcePlatform, string, string) {
 		return cluster.PlatformGitHub, owner, repo
 	}
 
-	// ? We would add support for other platforms here.
+	// Check for GitLab (gitlab.com or a self-hosted instance)
+	if host := remoteHost(repoURL); isGitLabHost(host) {
+		owner, repo := parseGitLabURL(repoURL, host)
+		return cluster.PlatformGitLab, owner, repo
+	}
+
+	// Bitbucket has no built-in adapter, but one can be registered with adapter.Register
+	if strings.Contains(repoURL, "bitbucket.org") {
+		owner, repo := parseHostedGitURL(repoURL, "bitbucket.org")
+		return cluster.PlatformBitbucket, owner, repo
+	}
 
 	// Default to Git for local paths or unknown URLs
 	return cluster.PlatformGit, "", extractRepoName(repoURL)
@@ -78,3 +110,113 @@ func parseHostedGitURL(url, host string) (owner, repo string) {
 
 	return "", url
 }
+
+// gitLabHostEnv names the self-hosted GitLab instance GITLAB_TOKEN may be sent to, which is taken
+// for GitLab even when its hostname does not contain "gitlab"
+const gitLabHostEnv = "GITLAB_HOST"
+
+// remoteHost returns the lowercased host of a remote repository URL, or "" for local paths
+// HTTP(S) hosts keep their port; SSH ports are dropped since the API is served over HTTPS
+func remoteHost(repoURL string) string {
+	rest := repoURL
+	if i := strings.Index(rest, "://"); i >= 0 {
+		rest = rest[i+3:]
+	} else if at, colon := strings.Index(rest, "@"), strings.Index(rest, ":"); at < 0 || colon < at {
+		// Not scp-style (user@host:path) either, so this is a local path
+		return ""
+	}
+
+	if at := strings.Index(rest, "@"); at >= 0 && at < strings.IndexAny(rest+"/", "/") {
+		rest = rest[at+1:]
+	}
+	host := rest
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
+		if i := strings.Index(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+	}
+	return strings.ToLower(host)
+}
+
+// isGitLabHost reports whether a host serves GitLab: gitlab.com, any host with a "gitlab" label
+// (e.g. gitlab.example.com), or the host named by GITLAB_HOST
+func isGitLabHost(host string) bool {
+	if host == "" {
+		return false
+	}
+	hostname := strings.Split(host, ":")[0]
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "gitlab" {
+			return true
+		}
+	}
+	if configured := os.Getenv(gitLabHostEnv); configured != "" {
+		configured = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(configured, "https://"), "http://"), "/")
+		return strings.EqualFold(configured, host) || strings.EqualFold(configured, hostname)
+	}
+	return false
+}
+
+// trustedGitLabURL reports whether GITLAB_TOKEN may be sent to the API of a GitLab project URL:
+// only for gitlab.com or the exact host named by GITLAB_HOST, and never over plain HTTP
+func trustedGitLabURL(repoURL string) bool {
+	if strings.HasPrefix(repoURL, "http://") {
+		return false
+	}
+	host := remoteHost(repoURL)
+	if host == "gitlab.com" {
+		return true
+	}
+	configured := os.Getenv(gitLabHostEnv)
+	if configured == "" || strings.HasPrefix(configured, "http://") {
+		return false
+	}
+	configured = strings.TrimSuffix(strings.TrimPrefix(configured, "https://"), "/")
+	return strings.EqualFold(configured, host)
+}
+
+// parseGitLabURL splits a GitLab project URL into its namespace and project name
+// Namespaces may contain nested groups, so everything before the last path segment is the owner
+func parseGitLabURL(repoURL, host string) (owner, repo string) {
+	path := repoURL
+	if i := strings.Index(strings.ToLower(path), host); i >= 0 {
+		path = path[i+len(host):]
+	}
+
+	// Drop the scp-style separator or an SSH port ("host:group/project", "host:2222/group/project")
+	if strings.HasPrefix(path, ":") {
+		path = path[1:]
+		if strings.Contains(repoURL, "://") {
+			path = strings.TrimLeft(path, "0123456789")
+		}
+	}
+	path = strings.Trim(path, "/")
+
+	// Web URLs continue past the project with "/-/", e.g. ".../project/-/merge_requests/1"
+	if i := strings.Index(path, "/-/"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", path
+	}
+	return strings.Join(parts[:len(parts)-1], "/"), parts[len(parts)-1]
+}
+
+// gitLabBaseURL returns the API base for a GitLab project URL, or "" for gitlab.com
+func gitLabBaseURL(repoURL string) string {
+	host := remoteHost(repoURL)
+	if host == "" || host == "gitlab.com" {
+		return ""
+	}
+	scheme := "https"
+	if strings.HasPrefix(repoURL, "http://") {
+		scheme = "http"
+	}
+	return scheme + "://" + host + "/"
+}
@@ -0,0 +1,31 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+)
+
+// AnalyzeWorkingState builds the "in-progress" pseudo-episode for a local clone
+// It holds local-only commits and uncommitted changes; returns nil if there is no such work
+func AnalyzeWorkingState(ctx context.Context, path string) (*cluster.Episode, error) {
+	gitRepo, err := git.OpenRepository(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local repository '%s': %w", path, err)
+	}
+
+	state, err := git.ParseWorkingState(ctx, gitRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse working state: %w", err)
+	}
+
+	// Apply the same identity normalization as regular ingestion
+	if mailmap, err := git.ReadRepositoryMailmap(gitRepo); err == nil {
+		state.LocalCommits = git.ResolveIdentities(state.LocalCommits, mailmap)
+		state.Author = mailmap.Resolve(state.Author)
+	}
+
+	return cluster.NewWorkingEpisode(state), nil
+}
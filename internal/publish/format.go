@@ -0,0 +1,244 @@
+package publish
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+// Limits of Slack blocks and Discord embeds that messages are cut to fit
+const (
+	slackHeaderLimit   = 150
+	slackSectionLimit  = 3000
+	slackFieldLimit    = 10
+	slackBlockLimit    = 50
+	discordTitleLimit  = 256
+	discordTextLimit   = 4096
+	discordFieldLimit  = 1024
+	discordEmbedLimit  = 6000
+	discordFooterLimit = 2048
+
+	// linkLimit caps the links a message lists
+	linkLimit = 10
+)
+
+// categoryColors colors Discord embeds by the kind of work; other messages are blue
+var categoryColors = map[cluster.Category]int{
+	cluster.CategoryFeature:  0x2EA043,
+	cluster.CategoryBugfix:   0xCF222E,
+	cluster.CategoryRefactor: 0x8250DF,
+	cluster.CategoryChore:    0x6E7781,
+}
+
+const defaultColor = 0x0969DA
+
+// Markdown that the chat platforms render differently
+var (
+	headingPattern = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t]*$`)
+	bulletPattern  = regexp.MustCompile(`(?m)^(\s*)[-*]\s+`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+)
+
+// slackMessage is a Slack incoming webhook payload
+type slackMessage struct {
+	Text   string       `json:"text"` // Shown in notifications
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock is a header, section or context block
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a plain_text or mrkdwn text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackPayload formats a message as Slack blocks: its title, text, facts, links and footer
+func slackPayload(msg *Message) slackMessage {
+	payload := slackMessage{Text: msg.Title}
+	if msg.URL != "" {
+		title := fmt.Sprintf("*<%s|%s>*", msg.URL, slackEscape(msg.Title))
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: title}})
+	} else {
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(msg.Title, slackHeaderLimit)}})
+	}
+
+	// Leave room for the facts, links and footer
+	for _, chunk := range splitText(slackMarkdown(msg.Text), slackSectionLimit) {
+		if len(payload.Blocks) == slackBlockLimit-3 {
+			break
+		}
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: chunk}})
+	}
+
+	if len(msg.Facts) > 0 {
+		block := slackBlock{Type: "section"}
+		for _, fact := range msg.Facts[:min(len(msg.Facts), slackFieldLimit)] {
+			block.Fields = append(block.Fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", slackEscape(fact.Name), slackEscape(fact.Value))})
+		}
+		payload.Blocks = append(payload.Blocks, block)
+	}
+	if links := formatLinks(msg, func(label, url string) string { return fmt.Sprintf("<%s|%s>", url, slackEscape(label)) }); links != "" {
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: truncate(links, slackSectionLimit)}}})
+	}
+	// Slack has no timestamps on messages, so the footer dates the work
+	footer := msg.Footer
+	if !msg.Time.IsZero() {
+		footer = strings.TrimPrefix(footer+" · "+msg.Time.Format("2006-01-02"), " · ")
+	}
+	if footer != "" {
+		payload.Blocks = append(payload.Blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: slackEscape(footer)}}})
+	}
+	return payload
+}
+
+// slackMarkdown converts Markdown to Slack's mrkdwn: headings and **bold** become *bold*, list
+// items get bullets, [links](url) become <url|links>, and the characters Slack reserves are escaped
+func slackMarkdown(text string) string {
+	text = headingPattern.ReplaceAllString(text, "**$1**")
+	text = bulletPattern.ReplaceAllString(text, "$1• ")
+
+	// URLs are left unescaped, the text around and inside links is escaped
+	var b strings.Builder
+	last := 0
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(slackEscape(text[last:m[0]]))
+		fmt.Fprintf(&b, "<%s|%s>", text[m[4]:m[5]], slackEscape(text[m[2]:m[3]]))
+		last = m[1]
+	}
+	b.WriteString(slackEscape(text[last:]))
+	return boldPattern.ReplaceAllString(b.String(), "*$1*")
+}
+
+// slackEscape escapes the characters Slack reserves for links and mentions
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// discordMessage is a Discord webhook payload
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// discordEmbed is a rich embed of a Discord message
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+// discordField is a named value of an embed
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// discordFooter is the footer of an embed
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// discordPayload formats a message as a Discord embed colored by its category, its text cut to
+// fit the embed beside its facts and links
+func discordPayload(msg *Message) discordMessage {
+	embed := discordEmbed{
+		Title: truncate(msg.Title, discordTitleLimit),
+		URL:   msg.URL,
+		Color: defaultColor,
+	}
+	if color, ok := categoryColors[msg.Category]; ok {
+		embed.Color = color
+	}
+	if !msg.Time.IsZero() {
+		embed.Timestamp = msg.Time.UTC().Format(time.RFC3339)
+	}
+
+	used := len([]rune(embed.Title))
+	for _, fact := range msg.Facts {
+		field := discordField{Name: truncate(fact.Name, discordTitleLimit), Value: truncate(fact.Value, discordFieldLimit), Inline: true}
+		embed.Fields = append(embed.Fields, field)
+		used += len([]rune(field.Name)) + len([]rune(field.Value))
+	}
+	if links := formatLinks(msg, func(label, url string) string { return fmt.Sprintf("[%s](%s)", label, url) }); links != "" {
+		field := discordField{Name: "Links", Value: truncate(links, discordFieldLimit)}
+		embed.Fields = append(embed.Fields, field)
+		used += len([]rune(field.Name)) + len([]rune(field.Value))
+	}
+	if msg.Footer != "" {
+		embed.Footer = &discordFooter{Text: truncate(msg.Footer, discordFooterLimit)}
+		used += len([]rune(embed.Footer.Text))
+	}
+
+	text := headingPattern.ReplaceAllString(strings.TrimSpace(msg.Text), "**$1**")
+	embed.Description = truncate(text, min(discordTextLimit, discordEmbedLimit-used))
+	return discordMessage{Username: "thunk", Embeds: []discordEmbed{embed}}
+}
+
+// formatLinks lists a message's links, up to linkLimit, formatted by link
+func formatLinks(msg *Message, link func(label, url string) string) string {
+	var parts []string
+	for _, l := range msg.Links[:min(len(msg.Links), linkLimit)] {
+		parts = append(parts, link(l.Label, l.URL))
+	}
+	if extra := len(msg.Links) - linkLimit; extra > 0 {
+		parts = append(parts, fmt.Sprintf("and %d more", extra))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// splitText splits text into chunks of at most limit characters, at paragraph breaks where it can
+func splitText(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		for len([]rune(paragraph)) > limit {
+			if current.Len() > 0 {
+				chunks = append(chunks, current.String())
+				current.Reset()
+			}
+			runes := []rune(paragraph)
+			chunks = append(chunks, string(runes[:limit]))
+			paragraph = string(runes[limit:])
+		}
+		if current.Len() > 0 && len([]rune(current.String()))+2+len([]rune(paragraph)) > limit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(paragraph)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// truncate cuts text to at most limit characters, ending it with an ellipsis when cut
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	if limit <= 1 {
+		return string(runes[:max(limit, 0)])
+	}
+	return strings.TrimRight(string(runes[:limit-1]), " \n") + "…"
+}
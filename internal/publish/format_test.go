@@ -0,0 +1,90 @@
+package publish
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/export"
+)
+
+func TestSlackMarkdown(t *testing.T) {
+	text := "## Week 3\n\n**2 episodes** & more\n- See [PR #7](https://github.com/acme/app/pull/7?a=1&b=2) for <details>"
+	want := "*Week 3*\n\n*2 episodes* &amp; more\n• See <https://github.com/acme/app/pull/7?a=1&b=2|PR #7> for &lt;details&gt;"
+	if got := slackMarkdown(text); got != want {
+		t.Errorf("slackMarkdown() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSlackPayload(t *testing.T) {
+	msg := &Message{
+		Title:  "Add retries",
+		URL:    "https://github.com/acme/app/pull/7",
+		Text:   strings.Repeat("Retries back off. ", 160) + "\n\n" + strings.Repeat("They are capped. ", 12),
+		Facts:  []Fact{{Name: "Commits", Value: "2"}},
+		Links:  []export.Link{{Label: "PR #7", URL: "https://github.com/acme/app/pull/7"}},
+		Footer: "acme/app",
+		Time:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	payload := slackPayload(msg)
+
+	types := make([]string, len(payload.Blocks))
+	for i, block := range payload.Blocks {
+		types[i] = block.Type
+	}
+	if got := strings.Join(types, ","); got != "section,section,section,section,context,context" {
+		t.Fatalf("Expected the title, the text split in two, facts, links and footer, got %s", got)
+	}
+	if title := payload.Blocks[0].Text.Text; title != "*<https://github.com/acme/app/pull/7|Add retries>*" {
+		t.Errorf("Expected a linked title, got %q", title)
+	}
+	if text := payload.Blocks[2].Text.Text; !strings.HasPrefix(text, "They are capped.") {
+		t.Errorf("Expected the text split at the paragraph break, got %q", text)
+	}
+	if footer := payload.Blocks[5].Elements[0].Text; footer != "acme/app · 2024-01-02" {
+		t.Errorf("Expected the footer dated, got %q", footer)
+	}
+}
+
+func TestDiscordPayload(t *testing.T) {
+	links := make([]export.Link, 12)
+	for i := range links {
+		links[i] = export.Link{Label: "abc1234", URL: "https://github.com/acme/app/commit/abc1234"}
+	}
+	msg := &Message{
+		Category: "bugfix",
+		Title:    "Fix login",
+		Text:     "# Summary\n\n" + strings.Repeat("x", 5000),
+		Facts:    []Fact{{Name: "Commits", Value: "1"}},
+		Links:    links,
+		Footer:   "acme/app",
+		Time:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	embed := discordPayload(msg).Embeds[0]
+
+	if embed.Color != 0xCF222E || embed.Timestamp != "2024-01-02T00:00:00Z" || embed.Footer.Text != "acme/app" {
+		t.Errorf("Unexpected embed %+v", embed)
+	}
+	if !strings.HasPrefix(embed.Description, "**Summary**\n\n") || !strings.HasSuffix(embed.Description, "…") || len([]rune(embed.Description)) != discordTextLimit {
+		t.Errorf("Expected the heading in bold and the text cut to %d characters, got %d", discordTextLimit, len([]rune(embed.Description)))
+	}
+	if links := embed.Fields[1].Value; !strings.HasSuffix(links, " · and 2 more") || strings.Count(links, "[abc1234]") != linkLimit {
+		t.Errorf("Expected %d links and a count of the rest, got %q", linkLimit, links)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"a longer sentence", 9, "a longer…"},
+		{"héllo wörld", 6, "héllo…"},
+	} {
+		if got := truncate(tc.text, tc.limit); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.text, tc.limit, got, tc.want)
+		}
+	}
+}
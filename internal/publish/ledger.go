@@ -0,0 +1,92 @@
+package publish
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LedgerEnv overrides the location of the default ledger
+const LedgerEnv = "THUNK_PUBLISH_LEDGER"
+
+// Ledger records which messages each channel was sent, persisted between runs in a JSON file
+type Ledger struct {
+	path  string
+	posts map[string]map[string]time.Time // Channel name to message key to when it was posted
+}
+
+// DefaultLedgerPath returns the ledger file: THUNK_PUBLISH_LEDGER if set, otherwise published.json
+// in the user config directory, so clearing caches never re-posts old narratives
+func DefaultLedgerPath() (string, error) {
+	if path := os.Getenv(LedgerEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "thunk", "published.json"), nil
+}
+
+// LoadLedger reads the ledger at path, or starts an empty one if the file does not exist yet
+func LoadLedger(path string) (*Ledger, error) {
+	ledger := &Ledger{path: path, posts: make(map[string]map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ledger, nil
+		}
+		return nil, fmt.Errorf("failed to read publish ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &ledger.posts); err != nil {
+		return nil, fmt.Errorf("failed to parse publish ledger: %w", err)
+	}
+	return ledger, nil
+}
+
+// Posted reports whether a message was posted to a channel; a nil ledger has posted nothing
+func (l *Ledger) Posted(channel, key string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.posts[channel][key]
+	return ok
+}
+
+// Record notes that a message was posted to a channel
+func (l *Ledger) Record(channel, key string, at time.Time) {
+	if l == nil {
+		return
+	}
+	if l.posts[channel] == nil {
+		l.posts[channel] = make(map[string]time.Time)
+	}
+	l.posts[channel][key] = at
+}
+
+// Save writes the ledger back to its file
+func (l *Ledger) Save() error {
+	if l == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+	data, err := json.MarshalIndent(l.posts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode publish ledger: %w", err)
+	}
+
+	// Write atomically so an interrupted run never loses the record of earlier posts
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write publish ledger: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("failed to write publish ledger: %w", err)
+	}
+	return nil
+}
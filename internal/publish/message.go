@@ -0,0 +1,101 @@
+package publish
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+)
+
+// EpisodeMessage builds the message announcing an episode's narrative, titled after the episode
+// and linking its pull requests, issues and commits. repository names the repository the episode
+// is in, such as owner/name, unless the episode records its own.
+func EpisodeMessage(ep *cluster.Episode, narr *narrative.Narrative, repository string, opts export.Options) Message {
+	report := export.NewReport([]cluster.Episode{*ep}, opts).Episodes[0]
+	if ep.Repository != "" {
+		repository = ep.Repository
+	}
+
+	msg := Message{
+		Key:        fmt.Sprintf("%s:%s:%s", KindEpisode, repository, ep.ID),
+		Kind:       KindEpisode,
+		Repository: repository,
+		Category:   ep.Category,
+		Title:      report.Title,
+		Text:       strings.TrimSpace(narr.Text),
+		Links:      report.Links,
+		Time:       report.EndDate,
+		Facts: []Fact{
+			{Name: "Commits", Value: fmt.Sprint(report.CommitCount)},
+			{Name: "Authors", Value: strings.Join(report.Authors, ", ")},
+			{Name: "Lines", Value: fmt.Sprintf("+%d / -%d", report.Stats.Additions, report.Stats.Deletions)},
+		},
+	}
+	if msg.Title == "" {
+		msg.Title = "Episode " + ep.ID
+	}
+	// The title links to the episode's first pull request or issue
+	for _, link := range report.Links {
+		if link.Kind != "commit" {
+			msg.URL = link.URL
+			break
+		}
+	}
+	if ep.Category != "" {
+		msg.Facts = append(msg.Facts, Fact{Name: "Category", Value: string(ep.Category)})
+	}
+
+	footer := []string{"Episode " + ep.ID}
+	if repository != "" {
+		footer = append([]string{repository}, footer...)
+	}
+	if narr.Model != "" {
+		footer = append(footer, "written by "+narr.Model)
+	}
+	msg.Footer = strings.Join(footer, " · ")
+	return msg
+}
+
+// DigestMessage builds the message of a digest: its overview and a section per period, newest
+// first. A digest of the same periods is posted once, however often it is regenerated.
+func DigestMessage(digest *orchestrator.Digest, repository string) Message {
+	text := digest.Markdown()
+	title := "Development digest"
+	// The digest's heading becomes the message's title
+	if heading, rest, ok := strings.Cut(text, "\n"); ok && strings.HasPrefix(heading, "# ") {
+		title, text = strings.TrimPrefix(heading, "# "), rest
+	}
+
+	msg := Message{
+		Kind:       KindDigest,
+		Repository: repository,
+		Title:      title,
+		Text:       strings.TrimSpace(text),
+		Footer:     strings.TrimPrefix(repository+" · "+string(digest.Period)+"ly digest", " · "),
+	}
+	contributors := make(map[string]bool)
+	episodes := 0
+	for _, entry := range digest.Periods {
+		episodes += entry.Episodes
+		for _, name := range entry.Contributors {
+			contributors[name] = true
+		}
+	}
+	msg.Facts = []Fact{
+		{Name: "Episodes", Value: fmt.Sprint(episodes)},
+		{Name: "Commits", Value: fmt.Sprint(digest.Commits())},
+		{Name: "Contributors", Value: fmt.Sprint(len(contributors))},
+	}
+
+	key := []string{string(KindDigest), repository, string(digest.Period)}
+	if len(digest.Periods) > 0 {
+		first, last := digest.Periods[0], digest.Periods[len(digest.Periods)-1]
+		key = append(key, first.Start.Format("2006-01-02"), last.End.Format("2006-01-02"))
+		msg.Time = last.End
+	}
+	msg.Key = strings.Join(key, ":")
+	return msg
+}
@@ -0,0 +1,75 @@
+package publish
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+	"github.com/Yates-Labs/thunk/internal/ingest/git"
+	"github.com/Yates-Labs/thunk/internal/narrative"
+	"github.com/Yates-Labs/thunk/internal/orchestrator"
+)
+
+func TestEpisodeMessage(t *testing.T) {
+	when := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	ep := &cluster.Episode{ID: "E1", Category: cluster.CategoryFeature,
+		Commits: []git.Commit{{Hash: "aaaaaaa111", Message: "Add retries\n\nWith backoff.", Author: git.Author{Name: "Ann"}, CommittedAt: when,
+			Stats: git.CommitStats{Additions: 10, Deletions: 2}}},
+		Artifacts: []cluster.Artifact{{ID: "PR-7", Number: 7, Type: cluster.ArtifactPullRequest, Title: "Retries", URL: "https://github.com/acme/app/pull/7"}},
+	}
+	ep.Stats = ep.ComputeStats()
+	narr := &narrative.Narrative{EpisodeID: "E1", Text: " Retries now back off.\n", Model: "gpt-4o"}
+
+	msg := EpisodeMessage(ep, narr, "acme/app", export.Options{RepositoryURL: "https://github.com/acme/app"})
+	if msg.Key != "episode:acme/app:E1" || msg.Kind != KindEpisode || msg.Repository != "acme/app" || msg.Category != cluster.CategoryFeature {
+		t.Errorf("Unexpected routing of %+v", msg)
+	}
+	if msg.Title != "Add retries" || msg.URL != "https://github.com/acme/app/pull/7" || msg.Text != "Retries now back off." || !msg.Time.Equal(when) {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+	if len(msg.Links) != 2 || msg.Links[1].URL != "https://github.com/acme/app/commit/aaaaaaa111" {
+		t.Errorf("Expected the pull request and commit linked, got %+v", msg.Links)
+	}
+	if msg.Footer != "acme/app · Episode E1 · written by gpt-4o" {
+		t.Errorf("Unexpected footer %q", msg.Footer)
+	}
+
+	// Episodes of an organization's repositories keep their own repository
+	ep.Repository = "acme/api"
+	if msg := EpisodeMessage(ep, narr, "acme", export.Options{}); msg.Repository != "acme/api" || msg.Key != "episode:acme/api:E1" {
+		t.Errorf("Expected the episode's repository, got %+v", msg)
+	}
+}
+
+func TestDigestMessage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	digest := &orchestrator.Digest{
+		Period:   cluster.PeriodWeek,
+		Overview: &narrative.Narrative{Text: "A busy fortnight."},
+		Periods: []orchestrator.PeriodDigest{
+			{Label: "Week 1", Start: start, End: start.AddDate(0, 0, 7), Episodes: 2, Commits: 5, Contributors: []string{"Ann", "Bob"},
+				Narrative: &narrative.Narrative{Text: "Retries shipped."}},
+			{Label: "Week 2", Start: start.AddDate(0, 0, 7), End: start.AddDate(0, 0, 14), Episodes: 1, Commits: 1, Contributors: []string{"Ann"},
+				Narrative: &narrative.Narrative{Text: "Login fixed."}},
+		},
+	}
+
+	msg := DigestMessage(digest, "acme/app")
+	if msg.Key != "digest:acme/app:week:2024-01-01:2024-01-15" || msg.Kind != KindDigest {
+		t.Errorf("Expected the digest keyed by its periods, got %q", msg.Key)
+	}
+	if msg.Title != "Development digest: 2024-01-01 to 2024-01-14" || !strings.HasPrefix(msg.Text, "A busy fortnight.") {
+		t.Errorf("Expected the heading as the title, got %q and %q", msg.Title, msg.Text)
+	}
+	want := []Fact{{"Episodes", "3"}, {"Commits", "6"}, {"Contributors", "2"}}
+	for i := range want {
+		if msg.Facts[i] != want[i] {
+			t.Errorf("Expected fact %+v, got %+v", want[i], msg.Facts[i])
+		}
+	}
+	if msg.Footer != "acme/app · weekly digest" {
+		t.Errorf("Unexpected footer %q", msg.Footer)
+	}
+}
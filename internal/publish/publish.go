@@ -0,0 +1,270 @@
+// Package publish posts episode narratives and digests to Slack and Discord through incoming
+// webhooks. Channels choose what they receive by kind, category and repository, and a ledger of
+// what each channel was sent keeps reruns from posting the same narrative twice.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+	"github.com/Yates-Labs/thunk/internal/export"
+)
+
+// Platform is the chat service a webhook posts to
+type Platform string
+
+const (
+	PlatformSlack   Platform = "slack"
+	PlatformDiscord Platform = "discord"
+)
+
+// Kind is the kind of narrative a message carries
+type Kind string
+
+const (
+	KindEpisode Kind = "episode"
+	KindDigest  Kind = "digest"
+)
+
+// maxRateLimitRetries caps how often a post is retried after the webhook rate limits it
+const maxRateLimitRetries = 3
+
+// Message is a narrative ready to post, formatted as Slack blocks or a Discord embed
+type Message struct {
+	Key        string // Identifies what is posted, so each channel is sent it once
+	Kind       Kind
+	Repository string           // Repository the work is in; empty when unknown
+	Category   cluster.Category // Kind of work, for routing and the embed color; "" for digests
+	Title      string
+	URL        string        // Link of the title, such as the episode's pull request
+	Text       string        // Markdown body
+	Facts      []Fact        // Short statistics shown beside the text
+	Links      []export.Link // Pull requests, issues and commits
+	Footer     string
+	Time       time.Time // When the work ended; zero leaves the message undated
+}
+
+// Fact is a named statistic of a message, such as "Commits: 3"
+type Fact struct {
+	Name, Value string
+}
+
+// Channel is a webhook and the messages routed to it
+type Channel struct {
+	// Name identifies the channel in the ledger; renaming it posts everything to it again
+	Name string `json:"name"`
+
+	// Webhook is the incoming webhook URL; environment variables such as ${SLACK_WEBHOOK} are
+	// expanded, so the URL can be kept out of the config file
+	Webhook string `json:"webhook"`
+
+	// Platform is detected from the webhook URL when empty
+	Platform Platform `json:"platform,omitempty"`
+
+	// Kinds, Categories and Repositories (glob patterns) narrow what the channel receives; empty
+	// receives everything
+	Kinds        []Kind             `json:"kinds,omitempty"`
+	Categories   []cluster.Category `json:"categories,omitempty"`
+	Repositories []string           `json:"repositories,omitempty"`
+}
+
+// Config lists the channels to publish to
+type Config struct {
+	Channels []Channel `json:"channels"`
+}
+
+// LoadConfig reads the channels to publish to from a JSON file; a webhook URL instead of a path
+// publishes everything to that one webhook
+func LoadConfig(target string) (Config, error) {
+	if strings.HasPrefix(target, "https://") || strings.HasPrefix(target, "http://") {
+		return Config{Channels: []Channel{{Name: "default", Webhook: target}}}, nil
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read publish config: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("failed to parse publish config: %w", err)
+	}
+	return config, nil
+}
+
+// Result counts what a run of Publish did, per post to a channel
+type Result struct {
+	Posted  int
+	Skipped int // Already posted to the channel on an earlier run
+	Failed  int
+}
+
+// Publisher posts messages to the channels they are routed to
+type Publisher struct {
+	channels   []Channel
+	ledger     *Ledger
+	httpClient *http.Client
+}
+
+// New creates a publisher for the channels of config, recording what it posts in ledger; a nil
+// ledger posts every message each time
+func New(config Config, ledger *Ledger) (*Publisher, error) {
+	if len(config.Channels) == 0 {
+		return nil, fmt.Errorf("no channels to publish to")
+	}
+	channels := make([]Channel, len(config.Channels))
+	names := make(map[string]bool)
+	for i, channel := range config.Channels {
+		channel.Webhook = os.ExpandEnv(channel.Webhook)
+		switch {
+		case channel.Name == "":
+			return nil, fmt.Errorf("channel %d has no name", i+1)
+		case names[channel.Name]:
+			return nil, fmt.Errorf("duplicate channel %q", channel.Name)
+		case channel.Webhook == "":
+			return nil, fmt.Errorf("channel %q has no webhook", channel.Name)
+		}
+		names[channel.Name] = true
+
+		if channel.Platform == "" {
+			channel.Platform = detectPlatform(channel.Webhook)
+		}
+		if channel.Platform != PlatformSlack && channel.Platform != PlatformDiscord {
+			return nil, fmt.Errorf("channel %q: unknown platform %q (supported: %s, %s)", channel.Name, channel.Platform, PlatformSlack, PlatformDiscord)
+		}
+		for _, kind := range channel.Kinds {
+			if kind != KindEpisode && kind != KindDigest {
+				return nil, fmt.Errorf("channel %q: unknown kind %q (supported: %s, %s)", channel.Name, kind, KindEpisode, KindDigest)
+			}
+		}
+		for _, pattern := range channel.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("channel %q: invalid repository pattern %q: %w", channel.Name, pattern, err)
+			}
+		}
+		channels[i] = channel
+	}
+	return &Publisher{channels: channels, ledger: ledger, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// detectPlatform recognizes the platform of a webhook URL, or returns "" for other URLs
+func detectPlatform(webhook string) Platform {
+	switch {
+	case strings.Contains(webhook, "hooks.slack.com"):
+		return PlatformSlack
+	case strings.Contains(webhook, "discord.com/api/webhooks"), strings.Contains(webhook, "discordapp.com/api/webhooks"):
+		return PlatformDiscord
+	default:
+		return ""
+	}
+}
+
+// Publish posts each message to the channels it is routed to, skipping channels the ledger
+// records it was posted to. A failed post doesn't stop the others; its error is returned with
+// the rest, and the next run tries it again.
+func (p *Publisher) Publish(ctx context.Context, messages []Message) (Result, error) {
+	var result Result
+	var errs []error
+	for _, msg := range messages {
+		for _, channel := range p.channels {
+			if !channel.routes(&msg) {
+				continue
+			}
+			if p.ledger.Posted(channel.Name, msg.Key) {
+				result.Skipped++
+				continue
+			}
+			if err := p.post(ctx, channel, &msg); err != nil {
+				result.Failed++
+				errs = append(errs, fmt.Errorf("failed to post %s to %s: %w", msg.Key, channel.Name, err))
+				continue
+			}
+			result.Posted++
+			// Saved after every post, so an interrupted run doesn't post it again either
+			p.ledger.Record(channel.Name, msg.Key, time.Now().UTC())
+			if err := p.ledger.Save(); err != nil {
+				return result, errors.Join(append(errs, err)...)
+			}
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// routes reports whether the channel receives a message
+func (c *Channel) routes(msg *Message) bool {
+	if len(c.Kinds) > 0 && !slices.Contains(c.Kinds, msg.Kind) {
+		return false
+	}
+	if len(c.Categories) > 0 && !slices.Contains(c.Categories, msg.Category) {
+		return false
+	}
+	if len(c.Repositories) > 0 {
+		return slices.ContainsFunc(c.Repositories, func(pattern string) bool {
+			matched, _ := path.Match(pattern, msg.Repository)
+			return matched
+		})
+	}
+	return true
+}
+
+// post sends a message to a channel's webhook, waiting out rate limits
+func (p *Publisher) post(ctx context.Context, channel Channel, msg *Message) error {
+	var payload any
+	if channel.Platform == PlatformDiscord {
+		payload = discordPayload(msg)
+	} else {
+		payload = slackPayload(msg)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.Webhook, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("webhook request failed: %w", err)
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			select {
+			case <-time.After(retryAfter(resp.Header.Get("Retry-After"))):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook request failed: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+		}
+		return nil
+	}
+}
+
+// retryAfter parses a Retry-After header in seconds, which Discord sends with a fraction,
+// defaulting to a second
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		return time.Second
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
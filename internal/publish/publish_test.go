@@ -0,0 +1,168 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+// testWebhook records the payloads posted to it, rate limiting the first post when limited is set
+func testWebhook(t *testing.T, limited bool) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var posts []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limited {
+			limited = false
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Expected a JSON payload, got %s", body)
+		}
+		posts = append(posts, payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, &posts
+}
+
+func TestPublisher(t *testing.T) {
+	slack, slackPosts := testWebhook(t, false)
+	discord, discordPosts := testWebhook(t, true)
+	config := Config{Channels: []Channel{
+		{Name: "eng", Webhook: slack.URL, Platform: PlatformSlack},
+		{Name: "fixes", Webhook: discord.URL, Platform: PlatformDiscord, Kinds: []Kind{KindEpisode}, Categories: []cluster.Category{cluster.CategoryBugfix}, Repositories: []string{"acme/*"}},
+	}}
+	messages := []Message{
+		{Key: "episode:acme/app:E1", Kind: KindEpisode, Repository: "acme/app", Category: "feature", Title: "Add retries", Text: "Retries back off."},
+		{Key: "episode:acme/app:E2", Kind: KindEpisode, Repository: "acme/app", Category: "bugfix", Title: "Fix login", Text: "Logins work again."},
+		{Key: "episode:other/app:E3", Kind: KindEpisode, Repository: "other/app", Category: "bugfix", Title: "Fix crash"},
+		{Key: "digest:acme/app:week", Kind: KindDigest, Repository: "acme/app", Category: "bugfix", Title: "Development digest"},
+	}
+
+	ledgerPath := filepath.Join(t.TempDir(), "published.json")
+	ledger, err := LoadLedger(ledgerPath)
+	if err != nil {
+		t.Fatalf("LoadLedger failed: %v", err)
+	}
+	publisher, err := New(config, ledger)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	result, err := publisher.Publish(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Posted != 5 || result.Skipped != 0 || result.Failed != 0 {
+		t.Errorf("Expected 5 posts, got %+v", result)
+	}
+	if len(*slackPosts) != 4 {
+		t.Errorf("Expected every message posted to Slack, got %d posts", len(*slackPosts))
+	}
+	if len(*discordPosts) != 1 {
+		t.Fatalf("Expected only the acme bug fix posted to Discord, got %d posts", len(*discordPosts))
+	}
+	embed := (*discordPosts)[0]["embeds"].([]any)[0].(map[string]any)
+	if embed["title"] != "Fix login" {
+		t.Errorf("Expected the Fix login embed after the rate limit, got %v", embed)
+	}
+
+	// A rerun, with the ledger read back from its file, posts only what is new
+	ledger, err = LoadLedger(ledgerPath)
+	if err != nil {
+		t.Fatalf("LoadLedger failed: %v", err)
+	}
+	publisher, _ = New(config, ledger)
+	messages = append(messages, Message{Key: "episode:acme/app:E4", Kind: KindEpisode, Repository: "acme/app", Title: "Add metrics"})
+	result, err = publisher.Publish(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Posted != 1 || result.Skipped != 5 {
+		t.Errorf("Expected only the new episode posted, got %+v", result)
+	}
+}
+
+func TestPublisher_Failures(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	slack, posts := testWebhook(t, false)
+
+	config := Config{Channels: []Channel{
+		{Name: "broken", Webhook: failing.URL, Platform: PlatformSlack},
+		{Name: "eng", Webhook: slack.URL, Platform: PlatformSlack},
+	}}
+	ledger, _ := LoadLedger(filepath.Join(t.TempDir(), "published.json"))
+	publisher, err := New(config, ledger)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	result, err := publisher.Publish(context.Background(), []Message{{Key: "episode:acme/app:E1", Kind: KindEpisode, Title: "Add retries"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("Expected the webhook's error, got %v", err)
+	}
+	if result.Posted != 1 || result.Failed != 1 || len(*posts) != 1 {
+		t.Errorf("Expected the other channel still posted to, got %+v", result)
+	}
+	if ledger.Posted("broken", "episode:acme/app:E1") {
+		t.Error("Expected a failed post to be tried again next run")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	t.Setenv("TEST_SLACK_WEBHOOK", "https://hooks.slack.com/services/T0/B0/x")
+	publisher, err := New(Config{Channels: []Channel{{Name: "eng", Webhook: "${TEST_SLACK_WEBHOOK}"}}}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if channel := publisher.channels[0]; channel.Platform != PlatformSlack || !strings.HasPrefix(channel.Webhook, "https://hooks.slack.com/") {
+		t.Errorf("Expected the webhook expanded and detected as Slack, got %+v", channel)
+	}
+
+	for name, channels := range map[string][]Channel{
+		"no channels":      nil,
+		"no name":          {{Webhook: "https://hooks.slack.com/services/x"}},
+		"duplicate name":   {{Name: "a", Webhook: "https://hooks.slack.com/services/x"}, {Name: "a", Webhook: "https://hooks.slack.com/services/y"}},
+		"no webhook":       {{Name: "a", Webhook: "${TEST_UNSET_WEBHOOK}"}},
+		"unknown platform": {{Name: "a", Webhook: "https://example.com/hook"}},
+		"unknown kind":     {{Name: "a", Webhook: "https://discord.com/api/webhooks/1/x", Kinds: []Kind{"answer"}}},
+		"bad pattern":      {{Name: "a", Webhook: "https://discord.com/api/webhooks/1/x", Repositories: []string{"acme/["}}},
+	} {
+		if _, err := New(Config{Channels: channels}, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := LoadConfig("https://discord.com/api/webhooks/1/x")
+	if err != nil || len(config.Channels) != 1 || config.Channels[0].Name != "default" {
+		t.Errorf("Expected a single default channel for a webhook URL, got %+v (error %v)", config, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "channels.json")
+	data := `{"channels": [{"name": "releases", "webhook": "https://hooks.slack.com/services/x", "kinds": ["digest"], "categories": ["feature"]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if channel := config.Channels[0]; channel.Name != "releases" || channel.Kinds[0] != KindDigest || channel.Categories[0] != "feature" {
+		t.Errorf("Unexpected channel %+v", channel)
+	}
+}
@@ -0,0 +1,50 @@
+package rag
+
+const (
+	// charsPerToken approximates how many characters of English text and code make one LLM token
+	charsPerToken = 4
+
+	// maxAdaptiveTopK bounds adaptive retrieval when the budget sets no chunk limit
+	maxAdaptiveTopK = 100
+)
+
+// ContextBudget decides how much retrieved context goes into a prompt: chunks are taken best
+// first until one scores below MinScore or would overrun MaxTokens or MaxChunks
+// The zero value keeps every chunk
+type ContextBudget struct {
+	MinScore  float32   // Minimum similarity score; 0 keeps chunks of any score
+	MaxTokens int       // Tokens of chunk text to include; 0 is unlimited
+	MaxChunks int       // Chunks to include; 0 is unlimited
+	Tokenizer Tokenizer // Counts MaxTokens; nil estimates with EstimateTokens
+}
+
+// EstimateTokens approximates the LLM tokens in text by its length, without depending on a model's tokenizer
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// SelectContext returns the leading chunks that fit the budget; chunks must be ordered best first
+// Selection stops at the first chunk that doesn't fit rather than skipping to smaller ones,
+// so a less relevant chunk never displaces a more relevant one
+func SelectContext(chunks []ContextChunk, budget ContextBudget) []ContextChunk {
+	selected, _ := selectContext(chunks, budget)
+	return selected
+}
+
+// selectContext is SelectContext, also reporting whether the budget cut the chunks short
+func selectContext(chunks []ContextChunk, budget ContextBudget) ([]ContextChunk, bool) {
+	tokens := 0
+	for i, chunk := range chunks {
+		if budget.MaxChunks > 0 && i >= budget.MaxChunks {
+			return chunks[:i], true
+		}
+		if budget.MinScore > 0 && chunk.Score < budget.MinScore {
+			return chunks[:i], true
+		}
+		tokens += countTokens(budget.Tokenizer, chunk.Text)
+		if budget.MaxTokens > 0 && tokens > budget.MaxTokens {
+			return chunks[:i], true
+		}
+	}
+	return chunks, false
+}
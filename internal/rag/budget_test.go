@@ -0,0 +1,87 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// rankedChunks returns chunks of 40 characters (10 tokens) each, scoring 0.9, 0.8, ...
+func rankedChunks(n int) []ContextChunk {
+	chunks := make([]ContextChunk, n)
+	for i := range chunks {
+		chunks[i] = ContextChunk{
+			EpisodeID: fmt.Sprintf("E%d", i),
+			Text:      strings.Repeat("x", 40),
+			Score:     0.9 - float32(i)/10,
+		}
+	}
+	return chunks
+}
+
+func TestSelectContext(t *testing.T) {
+	chunks := rankedChunks(5)
+
+	tests := []struct {
+		name   string
+		budget ContextBudget
+		want   int
+	}{
+		{"zero budget keeps all", ContextBudget{}, 5},
+		{"score threshold", ContextBudget{MinScore: 0.65}, 3},
+		{"token budget", ContextBudget{MaxTokens: 25}, 2},
+		{"exact token budget", ContextBudget{MaxTokens: 30}, 3},
+		{"chunk limit", ContextBudget{MaxChunks: 1}, 1},
+		{"tightest wins", ContextBudget{MinScore: 0.55, MaxTokens: 100, MaxChunks: 3}, 3},
+	}
+	for _, tt := range tests {
+		if got := SelectContext(chunks, tt.budget); len(got) != tt.want {
+			t.Errorf("%s: expected %d chunks, got %d", tt.name, tt.want, len(got))
+		}
+	}
+
+	if got := EstimateTokens("abcde"); got != 2 {
+		t.Errorf("Expected 5 characters to round up to 2 tokens, got %d", got)
+	}
+}
+
+func TestRetrieveContextForQueryWithinBudget(t *testing.T) {
+	ctx := context.Background()
+	available := rankedChunks(9)
+	var requested []int
+	store := &mockVectorStore{searchFunc: func(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error) {
+		requested = append(requested, topK)
+		return available[:min(topK, len(available))], nil
+	}}
+	retriever, _ := NewRetriever(&mockEmbedder{}, store)
+
+	tests := []struct {
+		name      string
+		budget    ContextBudget
+		want      int
+		requested []int
+	}{
+		{"widens until the score drops", ContextBudget{MinScore: 0.35}, 6, []int{2, 4, 8}},
+		{"stops at the token budget", ContextBudget{MaxTokens: 30}, 3, []int{2, 4}},
+		{"stops at the chunk limit", ContextBudget{MaxChunks: 5}, 5, []int{2, 4, 5}},
+		{"stops when the store runs out", ContextBudget{}, 9, []int{2, 4, 8, 16}},
+	}
+	for _, tt := range tests {
+		requested = nil
+		chunks, err := retriever.RetrieveContextForQueryWithinBudget(ctx, "retry logic", 2, tt.budget, nil)
+		if err != nil {
+			t.Fatalf("%s: RetrieveContextForQueryWithinBudget failed: %v", tt.name, err)
+		}
+		if len(chunks) != tt.want {
+			t.Errorf("%s: expected %d chunks, got %d", tt.name, tt.want, len(chunks))
+		}
+		if fmt.Sprint(requested) != fmt.Sprint(tt.requested) {
+			t.Errorf("%s: expected searches for %v, got %v", tt.name, tt.requested, requested)
+		}
+	}
+
+	if _, err := retriever.RetrieveContextForQueryWithinBudget(ctx, "", 2, ContextBudget{}, nil); err == nil {
+		t.Error("Expected an error for an empty query")
+	}
+}
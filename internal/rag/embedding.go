@@ -5,7 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/Yates-Labs/thunk/internal/azureopenai"
+	"github.com/Yates-Labs/thunk/internal/cluster"
 	"github.com/joho/godotenv"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -37,6 +41,52 @@ type Embedder interface {
 	Embed(ctx context.Context, texts []string) ([]EmbeddingRecord, error)
 }
 
+// Embedder providers available to NewEmbedder
+const (
+	EmbedderProviderOpenAI = "openai" // OpenAI's embeddings API; the default
+	EmbedderProviderFake   = "fake"   // HashEmbedder, for tests and offline runs
+)
+
+// EmbedderFactory creates an embedder for a model and vector dimension
+type EmbedderFactory func(model string, dimension int) (Embedder, error)
+
+// embedderProviders maps provider names to their factories
+var embedderProviders = map[string]EmbedderFactory{
+	EmbedderProviderOpenAI: func(model string, dimension int) (Embedder, error) {
+		return NewOpenAIEmbedder(model, dimension)
+	},
+	EmbedderProviderFake: func(model string, dimension int) (Embedder, error) {
+		return NewHashEmbedder(dimension), nil
+	},
+}
+
+// RegisterEmbedder makes an embedder provider available to NewEmbedder, replacing any of the same name
+func RegisterEmbedder(provider string, factory EmbedderFactory) {
+	embedderProviders[strings.ToLower(provider)] = factory
+}
+
+// EmbedderProviders lists the registered provider names, sorted
+func EmbedderProviders() []string {
+	names := make([]string, 0, len(embedderProviders))
+	for name := range embedderProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewEmbedder creates an embedder from a registered provider; an empty provider is OpenAI
+func NewEmbedder(provider, model string, dimension int) (Embedder, error) {
+	if provider == "" {
+		provider = EmbedderProviderOpenAI
+	}
+	factory, ok := embedderProviders[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unknown embedder provider %q (available: %s)", provider, strings.Join(EmbedderProviders(), ", "))
+	}
+	return factory(model, dimension)
+}
+
 // OpenAIEmbedder implements the Embedder interface using OpenAI's API
 type OpenAIEmbedder struct {
 	client    openai.Client
@@ -60,6 +110,21 @@ func NewOpenAIEmbedder(model string, dimension int) (*OpenAIEmbedder, error) {
 	}, nil
 }
 
+// NewAzureOpenAIEmbedder creates an OpenAI embedder that calls an Azure OpenAI embedding deployment
+// The deployment decides the model; it is also reported as the records' model
+func NewAzureOpenAIEmbedder(config azureopenai.Config, dimension int) (*OpenAIEmbedder, error) {
+	opts, err := config.RequestOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAIEmbedder{
+		client:    openai.NewClient(opts...),
+		Model:     config.Deployment,
+		Dimension: dimension,
+	}, nil
+}
+
 // Embed generates embeddings for the provided texts using OpenAI's API
 func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
 	if len(texts) == 0 {
@@ -96,3 +161,34 @@ func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([]Embedding
 
 	return records, nil
 }
+
+// clusterEmbedder adapts an Embedder to cluster.Embedder for semantic episode grouping
+type clusterEmbedder struct {
+	embedder Embedder
+}
+
+// NewClusterEmbedder adapts an Embedder for cluster.GroupIntoEpisodesSemantic
+func NewClusterEmbedder(embedder Embedder) cluster.Embedder {
+	return clusterEmbedder{embedder: embedder}
+}
+
+// EmbedTexts returns one embedding per text in input order
+func (c clusterEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	records, err := c.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, record := range records {
+		if record.Index >= 0 && record.Index < len(embeddings) {
+			embeddings[record.Index] = record.Embedding
+		}
+	}
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			return nil, fmt.Errorf("%w: missing embedding for text %d", ErrEmbeddingFailed, i)
+		}
+	}
+	return embeddings, nil
+}
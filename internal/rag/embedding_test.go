@@ -2,8 +2,12 @@ package rag
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+
+	"github.com/Yates-Labs/thunk/internal/azureopenai"
 )
 
 func TestNewOpenAIEmbedder_MissingAPIKey(t *testing.T) {
@@ -70,3 +74,60 @@ func TestOpenAIEmbedder_Embed(t *testing.T) {
 		}
 	}
 }
+
+func TestNewAzureOpenAIEmbedder(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","model":"text-embedding-3-large","data":[{"object":"embedding","index":0,"embedding":[0.5,0.25]}],"usage":{"prompt_tokens":1,"total_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewAzureOpenAIEmbedder(azureopenai.Config{Endpoint: server.URL, Deployment: "embeddings", APIKey: "azure-key"}, 2)
+	if err != nil {
+		t.Fatalf("NewAzureOpenAIEmbedder failed: %v", err)
+	}
+	records, err := embedder.Embed(context.Background(), []string{"retry backoff"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if path != "/openai/deployments/embeddings/embeddings" {
+		t.Errorf("unexpected path: %s", path)
+	}
+	if len(records) != 1 || len(records[0].Embedding) != 2 || records[0].Model != "embeddings" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+
+	if _, err := NewAzureOpenAIEmbedder(azureopenai.Config{Endpoint: server.URL}, 2); err == nil {
+		t.Error("expected a missing deployment to be rejected")
+	}
+}
+
+func TestClusterEmbedder_OrdersByIndex(t *testing.T) {
+	embedder := &mockEmbedder{embedFunc: func(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+		// Return records out of order, as the API may
+		return []EmbeddingRecord{
+			{Index: 1, Embedding: []float32{0, 1}},
+			{Index: 0, Embedding: []float32{1, 0}},
+		}, nil
+	}}
+
+	embeddings, err := NewClusterEmbedder(embedder).EmbedTexts(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedTexts failed: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][1] != 1 {
+		t.Errorf("Expected embeddings in input order, got %v", embeddings)
+	}
+
+	embedder.embedFunc = func(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+		return []EmbeddingRecord{{Index: 0, Embedding: []float32{1}}}, nil
+	}
+	if _, err := NewClusterEmbedder(embedder).EmbedTexts(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("Expected an error when an embedding is missing")
+	}
+}
@@ -0,0 +1,125 @@
+package rag
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxPathEntries bounds the paths stored per record, directories included, so one sweeping
+// commit can't bloat the index; files beyond it don't match path filters
+const maxPathEntries = 1024
+
+// MetadataFilter narrows a search to records by what they cover rather than what they say,
+// e.g. "what did Bob do in March?"; the zero value matches every record
+type MetadataFilter struct {
+	Since   time.Time `json:"since,omitempty"`   // Only records ending at or after Since
+	Until   time.Time `json:"until,omitempty"`   // Only records starting at or before Until
+	Authors []string  `json:"authors,omitempty"` // Only records by any of these authors, matched case-insensitively
+	Paths   []string  `json:"paths,omitempty"`   // Only records touching any of these files or directories
+}
+
+// IsZero reports whether the filter matches every record
+func (f MetadataFilter) IsZero() bool {
+	return f.Since.IsZero() && f.Until.IsZero() && len(f.Authors) == 0 && len(f.Paths) == 0
+}
+
+// matches reports whether a record passes the filter
+func (f MetadataFilter) matches(record EpisodeRecord) bool {
+	if !f.Since.IsZero() && record.EndDate.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.StartDate.After(f.Until) {
+		return false
+	}
+	if len(f.Authors) > 0 && !matchesAuthor(record.Authors, f.Authors) {
+		return false
+	}
+	if len(f.Paths) > 0 && !matchesPath(record.Paths, f.Paths) {
+		return false
+	}
+	return true
+}
+
+// matchesAuthor reports whether any of authors is one of wanted, ignoring case
+func matchesAuthor(authors, wanted []string) bool {
+	for _, author := range authors {
+		for _, name := range wanted {
+			if strings.EqualFold(strings.TrimSpace(author), strings.TrimSpace(name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesPath reports whether any of paths is one of wanted or lies in a wanted directory
+func matchesPath(paths, wanted []string) bool {
+	for _, filter := range wanted {
+		filter = cleanPath(filter)
+		if filter == "" {
+			return true
+		}
+		for _, p := range paths {
+			if p == filter || strings.HasPrefix(p, filter+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cleanPath normalizes a repository-relative path filter: "./internal/rag/" becomes "internal/rag"
+func cleanPath(p string) string {
+	p = path.Clean("/" + strings.TrimSpace(p))
+	return strings.TrimPrefix(p, "/")
+}
+
+// pathPrefixes returns paths with every directory above them, sorted and capped at maxPathEntries,
+// so stores that can only test membership still match a directory filter
+func pathPrefixes(paths []string) []string {
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		for p = cleanPath(p); p != "" && p != "." && !seen[p]; p = path.Dir(p) {
+			seen[p] = true
+		}
+	}
+	prefixes := make([]string, 0, len(seen))
+	for p := range seen {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	if len(prefixes) > maxPathEntries {
+		prefixes = prefixes[:maxPathEntries]
+	}
+	return prefixes
+}
+
+// filePaths undoes pathPrefixes, dropping the entries that are directories of other entries
+func filePaths(prefixes []string) []string {
+	directories := make(map[string]bool)
+	for _, p := range prefixes {
+		for dir := path.Dir(p); dir != "." && dir != "/" && !directories[dir]; dir = path.Dir(dir) {
+			directories[dir] = true
+		}
+	}
+	files := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		if !directories[p] {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// authorKeys lowercases author names so stores matching exact values match them case-insensitively
+func authorKeys(authors []string) []string {
+	keys := make([]string, 0, len(authors))
+	for _, author := range authors {
+		if author = strings.ToLower(strings.TrimSpace(author)); author != "" {
+			keys = append(keys, author)
+		}
+	}
+	return distinct(keys)
+}
@@ -0,0 +1,70 @@
+package rag
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadataFilter_Matches(t *testing.T) {
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	record := EpisodeRecord{
+		StartDate: march.Add(-24 * time.Hour),
+		EndDate:   march.Add(24 * time.Hour),
+		Authors:   []string{"Bob Smith", "alice"},
+		Paths:     []string{"internal/rag/sqlite.go", "README.md"},
+	}
+
+	tests := []struct {
+		name   string
+		filter MetadataFilter
+		want   bool
+	}{
+		{"zero filter", MetadataFilter{}, true},
+		{"overlaps the range", MetadataFilter{Since: march, Until: april}, true},
+		{"ends before since", MetadataFilter{Since: april}, false},
+		{"starts after until", MetadataFilter{Until: march.Add(-48 * time.Hour)}, false},
+		{"author ignoring case", MetadataFilter{Authors: []string{"bob smith"}}, true},
+		{"other author", MetadataFilter{Authors: []string{"carol"}}, false},
+		{"exact file", MetadataFilter{Paths: []string{"README.md"}}, true},
+		{"directory", MetadataFilter{Paths: []string{"./internal/rag/"}}, true},
+		{"name prefix is not a directory", MetadataFilter{Paths: []string{"internal/ra"}}, false},
+		{"all must match", MetadataFilter{Since: march, Authors: []string{"alice"}, Paths: []string{"cmd"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(record); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPathPrefixes(t *testing.T) {
+	paths := []string{"internal/rag/sqlite.go", "internal/rag/milvus.go", "README.md", "internal/rag/sqlite.go"}
+
+	prefixes := pathPrefixes(paths)
+	want := []string{"README.md", "internal", "internal/rag", "internal/rag/milvus.go", "internal/rag/sqlite.go"}
+	if !reflect.DeepEqual(prefixes, want) {
+		t.Errorf("Expected %v, got %v", want, prefixes)
+	}
+
+	files := filePaths(prefixes)
+	if want := []string{"README.md", "internal/rag/milvus.go", "internal/rag/sqlite.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected the files back, got %v", files)
+	}
+}
+
+func TestContextChunk_Accessors(t *testing.T) {
+	commit := ContextChunk{ChunkID: "commit:abc123", Granularity: GranularityCommit, Authors: []string{"Smith, Jane", "bob"}}
+	if commit.IsEpisode() || commit.CommitHash() != "abc123" {
+		t.Errorf("Unexpected accessors for a commit chunk: %v %q", commit.IsEpisode(), commit.CommitHash())
+	}
+	if !commit.HasAuthor("smith, jane") || commit.HasAuthor("Smith") {
+		t.Error("Expected authors to match whole names, ignoring case")
+	}
+
+	episode := ContextChunk{}
+	if !episode.IsEpisode() || episode.CommitHash() != "" {
+		t.Error("Expected a chunk without a granularity to be a whole episode")
+	}
+}
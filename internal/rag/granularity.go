@@ -0,0 +1,94 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Granularity is how much of an episode a record covers
+type Granularity string
+
+const (
+	GranularityEpisode Granularity = "episode" // The whole episode's summary
+	GranularityChunk   Granularity = "chunk"   // A window of consecutive commits, overlapping its neighbors
+	GranularityCommit  Granularity = "commit"  // A single commit
+)
+
+// Granularities lists every granularity, coarsest first
+var Granularities = []Granularity{GranularityEpisode, GranularityChunk, GranularityCommit}
+
+// ParseGranularity parses a granularity name, case-insensitively
+func ParseGranularity(name string) (Granularity, error) {
+	granularity := Granularity(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range Granularities {
+		if granularity == known {
+			return granularity, nil
+		}
+	}
+	return "", fmt.Errorf("unknown granularity %q (want episode, chunk or commit)", name)
+}
+
+// Chunk is part of an episode indexed alongside its summary, so specific questions can match
+// the commits that answer them instead of the whole episode
+type Chunk struct {
+	ID          string      `json:"id"` // Unique within the episode, e.g. "commit:<hash>" or "chunk:2"
+	Granularity Granularity `json:"granularity"`
+	Text        string      `json:"text"`
+	StartDate   time.Time   `json:"start_date,omitempty"`
+	EndDate     time.Time   `json:"end_date,omitempty"`
+	Authors     []string    `json:"authors,omitempty"`
+	Paths       []string    `json:"paths,omitempty"` // Files the chunk's commits touched
+	CommitCount int         `json:"commit_count"`
+	FileCount   int         `json:"file_count"`
+}
+
+// granularityOf returns a record's granularity; records without one are whole episodes
+func granularityOf(granularity Granularity) Granularity {
+	if granularity == "" {
+		return GranularityEpisode
+	}
+	return granularity
+}
+
+// matchesGranularity reports whether granularity is one of allowed; no allowed granularities match all
+func matchesGranularity(granularity Granularity, allowed []Granularity) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	granularity = granularityOf(granularity)
+	for _, g := range allowed {
+		if granularityOf(g) == granularity {
+			return true
+		}
+	}
+	return false
+}
+
+// recordKey identifies a record within a store: its repository and episode, plus the chunk it
+// covers, if any
+func recordKey(repository, episodeID, chunkID string) string {
+	key := repository + "\x00" + episodeID
+	if chunkID == "" {
+		return key
+	}
+	return key + "#" + chunkID
+}
+
+// IsEpisode reports whether the chunk covers a whole episode rather than some of its commits
+func (c ContextChunk) IsEpisode() bool {
+	return granularityOf(c.Granularity) == GranularityEpisode
+}
+
+// CommitHash returns the commit a commit-granularity chunk covers, or "" for other chunks
+func (c ContextChunk) CommitHash() string {
+	if granularityOf(c.Granularity) != GranularityCommit {
+		return ""
+	}
+	return strings.TrimPrefix(c.ChunkID, "commit:")
+}
+
+// HasAuthor reports whether name is one of the chunk's authors, ignoring case
+func (c ContextChunk) HasAuthor(name string) bool {
+	return matchesAuthor(c.Authors, []string{name})
+}
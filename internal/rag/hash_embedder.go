@@ -0,0 +1,91 @@
+package rag
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// defaultHashDimension is the vector size of a HashEmbedder created without one
+const defaultHashDimension = 256
+
+// HashEmbedder embeds texts as feature-hashed bag-of-words vectors: every word is hashed to a
+// signed dimension, weighted by how often it occurs, and the vector is normalized
+// It needs no API key or network and always embeds a text the same way, so tests and demo runs
+// can index and retrieve offline; texts sharing words score as similar, but synonyms don't
+type HashEmbedder struct {
+	Dimension int
+}
+
+// NewHashEmbedder creates a deterministic embedder; a dimension of 0 uses 256
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	if dimension <= 0 {
+		dimension = defaultHashDimension
+	}
+	return &HashEmbedder{Dimension: dimension}
+}
+
+// Embed returns one hashed vector per text, in input order
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+	if len(texts) == 0 {
+		return nil, ErrEmptyTexts
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	records := make([]EmbeddingRecord, len(texts))
+	for i, text := range texts {
+		records[i] = EmbeddingRecord{
+			Text:      text,
+			Embedding: e.embed(text),
+			Index:     i,
+			Model:     EmbedderProviderFake,
+		}
+	}
+	return records, nil
+}
+
+// embed hashes the words of text into a unit vector
+func (e *HashEmbedder) embed(text string) []float32 {
+	dimension := e.Dimension
+	if dimension <= 0 {
+		dimension = defaultHashDimension
+	}
+
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		counts[word]++
+	}
+
+	vector := make([]float64, dimension)
+	for word, count := range counts {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		sum := h.Sum64()
+		// The top bit picks the sign, so colliding words tend to cancel rather than add up
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vector[sum%uint64(dimension)] += sign * (1 + math.Log(float64(count)))
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += value * value
+	}
+	embedding := make([]float32, dimension)
+	if norm == 0 {
+		return embedding
+	}
+	norm = math.Sqrt(norm)
+	for i, value := range vector {
+		embedding[i] = float32(value / norm)
+	}
+	return embedding
+}
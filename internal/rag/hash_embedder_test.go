@@ -0,0 +1,89 @@
+package rag
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHashEmbedder_Deterministic(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewHashEmbedder(64)
+
+	first, err := embedder.Embed(ctx, []string{"Add retry backoff to the HTTP client", ""})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	second, _ := NewHashEmbedder(64).Embed(ctx, []string{"add RETRY backoff to the http client"})
+	if !reflect.DeepEqual(first[0].Embedding, second[0].Embedding) {
+		t.Error("Expected the same words to embed identically regardless of case and embedder instance")
+	}
+	if len(first[0].Embedding) != 64 || first[1].Index != 1 || first[0].Model != EmbedderProviderFake {
+		t.Errorf("Unexpected record: %+v", first[0])
+	}
+	if norm := dot(first[0].Embedding, first[0].Embedding); norm < 0.999 || norm > 1.001 {
+		t.Errorf("Expected a unit vector, got squared norm %f", norm)
+	}
+	for _, value := range first[1].Embedding {
+		if value != 0 {
+			t.Fatal("Expected a text without words to embed as a zero vector")
+		}
+	}
+
+	if _, err := embedder.Embed(ctx, nil); err != ErrEmptyTexts {
+		t.Errorf("Expected ErrEmptyTexts, got %v", err)
+	}
+	if got := NewHashEmbedder(0).Dimension; got != defaultHashDimension {
+		t.Errorf("Expected the default dimension, got %d", got)
+	}
+}
+
+func TestNewEmbedder_Providers(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+
+	embedder, err := NewEmbedder("FAKE", "", 32)
+	if err != nil {
+		t.Fatalf("Expected the fake provider to need no API key, got %v", err)
+	}
+	if hash, ok := embedder.(*HashEmbedder); !ok || hash.Dimension != 32 {
+		t.Errorf("Expected a 32-dimension HashEmbedder, got %#v", embedder)
+	}
+
+	if _, err := NewEmbedder("", "text-embedding-3-small", 1536); err != ErrMissingAPIKey {
+		t.Errorf("Expected the default OpenAI provider to require a key, got %v", err)
+	}
+	if _, err := NewEmbedder("nope", "", 32); err == nil || !strings.Contains(err.Error(), "fake, openai") {
+		t.Errorf("Expected an error listing the providers, got %v", err)
+	}
+}
+
+// TestHashEmbedder_IndexAndRetrieve runs indexing and retrieval end to end without network access
+func TestHashEmbedder_IndexAndRetrieve(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewHashEmbedder(128)
+	store, _ := NewLocalStore(LocalStoreConfig{})
+
+	episodes := []EpisodeSummary{
+		{EpisodeID: "E1", Summary: "Added exponential retry backoff to the HTTP client"},
+		{EpisodeID: "E2", Summary: "Rewrote the YAML parser and fixed a crash on empty documents"},
+		{EpisodeID: "E3", Summary: "Updated contributor documentation and the changelog"},
+	}
+	if err := IndexEpisodes(ctx, episodes, embedder, store, DefaultIndexOptions()); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+
+	retriever, _ := NewRetriever(embedder, store)
+	chunks, err := retriever.RetrieveContextForQuery(ctx, "When did we fix the YAML parser crash?", 1, nil)
+	if err != nil {
+		t.Fatalf("RetrieveContextForQuery failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E2" {
+		t.Errorf("Expected the parser episode, got %+v", chunks)
+	}
+
+	chunks, _ = retriever.RetrieveContextForQuery(ctx, "retry backoff", 1, nil)
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected the retry episode, got %+v", chunks)
+	}
+}
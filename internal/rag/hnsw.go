@@ -0,0 +1,189 @@
+package rag
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// hnswIndex is a hierarchical navigable small world graph over normalized vectors
+// Nodes are identified by insertion order, which matches LocalStore's record positions
+// The level generator is seeded so rebuilding from the same records yields the same graph
+type hnswIndex struct {
+	m              int
+	efConstruction int
+	levelScale     float64
+	rng            *rand.Rand
+
+	vectors [][]float32
+	links   [][][]int // links[node][layer] lists the node's neighbors on that layer
+	entry   int
+	top     int // Highest layer of the entry point
+}
+
+// newHNSWIndex creates an empty graph keeping m neighbors per node on each layer
+func newHNSWIndex(m, efConstruction int) *hnswIndex {
+	if m < 2 {
+		m = 2
+	}
+	return &hnswIndex{
+		m:              m,
+		efConstruction: max(efConstruction, m),
+		levelScale:     1 / math.Log(float64(m)),
+		rng:            rand.New(rand.NewSource(1)),
+		entry:          -1,
+	}
+}
+
+// add inserts a vector as the next node
+func (h *hnswIndex) add(vector []float32) {
+	node := len(h.vectors)
+	level := int(-math.Log(1-h.rng.Float64()) * h.levelScale)
+	h.vectors = append(h.vectors, vector)
+	h.links = append(h.links, make([][]int, level+1))
+
+	if h.entry < 0 {
+		h.entry, h.top = node, level
+		return
+	}
+
+	current := h.entry
+	for layer := h.top; layer > level; layer-- {
+		current = h.greedyClosest(vector, current, layer)
+	}
+	for layer := min(level, h.top); layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, current, h.efConstruction, layer)
+		neighbors := h.selectNeighbors(candidates, h.maxLinks(layer))
+		h.links[node][layer] = neighbors
+		for _, neighbor := range neighbors {
+			h.connect(neighbor, node, layer)
+		}
+		current = candidates[0].index
+	}
+
+	if level > h.top {
+		h.entry, h.top = node, level
+	}
+}
+
+// search returns up to k nodes most similar to query, best first
+func (h *hnswIndex) search(query []float32, k, ef int) []scoredRecord {
+	if h.entry < 0 {
+		return nil
+	}
+	current := h.entry
+	for layer := h.top; layer > 0; layer-- {
+		current = h.greedyClosest(query, current, layer)
+	}
+	matches := h.searchLayer(query, current, max(ef, k), 0)
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// maxLinks is the neighbor cap for a layer; the base layer keeps twice as many
+func (h *hnswIndex) maxLinks(layer int) int {
+	if layer == 0 {
+		return 2 * h.m
+	}
+	return h.m
+}
+
+// greedyClosest walks a layer toward query from start until no neighbor is closer
+func (h *hnswIndex) greedyClosest(query []float32, start, layer int) int {
+	current, best := start, dot(query, h.vectors[start])
+	for improved := true; improved; {
+		improved = false
+		for _, neighbor := range h.links[current][layer] {
+			if score := dot(query, h.vectors[neighbor]); score > best {
+				current, best, improved = neighbor, score, true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer runs a best-first search of one layer and returns up to ef nodes, best first
+func (h *hnswIndex) searchLayer(query []float32, start, ef, layer int) []scoredRecord {
+	visited := map[int]bool{start: true}
+	first := scoredRecord{index: start, score: dot(query, h.vectors[start])}
+	candidates := &scoreHeap{items: []scoredRecord{first}, best: true}
+	results := &scoreHeap{items: []scoredRecord{first}}
+
+	for candidates.Len() > 0 {
+		candidate := heap.Pop(candidates).(scoredRecord)
+		if results.Len() >= ef && candidate.score < results.items[0].score {
+			break
+		}
+		for _, neighbor := range h.links[candidate.index][layer] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			scored := scoredRecord{index: neighbor, score: dot(query, h.vectors[neighbor])}
+			if results.Len() < ef || scored.score > results.items[0].score {
+				heap.Push(candidates, scored)
+				heap.Push(results, scored)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	matches := results.items
+	sortScored(matches)
+	return matches
+}
+
+// selectNeighbors keeps the most similar candidates, which are already sorted best first
+func (h *hnswIndex) selectNeighbors(candidates []scoredRecord, limit int) []int {
+	neighbors := make([]int, 0, min(limit, len(candidates)))
+	for _, candidate := range candidates {
+		if len(neighbors) == limit {
+			break
+		}
+		neighbors = append(neighbors, candidate.index)
+	}
+	return neighbors
+}
+
+// connect links node to neighbor on a layer, pruning the neighbor's least similar links over the cap
+func (h *hnswIndex) connect(neighbor, node, layer int) {
+	links := append(h.links[neighbor][layer], node)
+	if len(links) > h.maxLinks(layer) {
+		scored := make([]scoredRecord, len(links))
+		for i, link := range links {
+			scored[i] = scoredRecord{index: link, score: dot(h.vectors[neighbor], h.vectors[link])}
+		}
+		sortScored(scored)
+		links = h.selectNeighbors(scored, h.maxLinks(layer))
+	}
+	h.links[neighbor][layer] = links
+}
+
+// scoreHeap is a heap of scored nodes; best pops the highest score first, otherwise the lowest
+type scoreHeap struct {
+	items []scoredRecord
+	best  bool
+}
+
+func (s *scoreHeap) Len() int { return len(s.items) }
+
+func (s *scoreHeap) Less(i, j int) bool {
+	if s.best {
+		return s.items[i].score > s.items[j].score
+	}
+	return s.items[i].score < s.items[j].score
+}
+
+func (s *scoreHeap) Swap(i, j int) { s.items[i], s.items[j] = s.items[j], s.items[i] }
+
+func (s *scoreHeap) Push(x any) { s.items = append(s.items, x.(scoredRecord)) }
+
+func (s *scoreHeap) Pop() any {
+	last := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return last
+}
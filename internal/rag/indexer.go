@@ -2,29 +2,36 @@ package rag
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
 // SearchOptions provides filtering options for vector search
 type SearchOptions struct {
-	EpisodeIDs []string               `json:"episode_ids,omitempty"` // Filter by specific episode IDs
-	Repository string                 `json:"repository,omitempty"`  // Filter by repository name
-	Metadata   map[string]interface{} `json:"metadata,omitempty"`    // Additional metadata filters
+	EpisodeIDs    []string      `json:"episode_ids,omitempty"`   // Filter by specific episode IDs
+	Repository    string        `json:"repository,omitempty"`    // Filter by repository (owner/name) the episodes were indexed under
+	Granularities []Granularity `json:"granularities,omitempty"` // Filter by record granularity; empty searches all
+	QueryText     string        `json:"query_text,omitempty"`    // Free-text query for stores with keyword search
+
+	MetadataFilter // Filter by date range, author and touched paths
 }
 
 // ContextChunk represents a retrieved context with similarity score
 // Used for RAG to provide relevant episode context to LLMs
 type ContextChunk struct {
-	EpisodeID   string                 `json:"episode_id"`
-	Text        string                 `json:"text"`
-	Score       float32                `json:"score"` // Similarity score (cosine distance)
-	StartDate   time.Time              `json:"start_date"`
-	EndDate     time.Time              `json:"end_date"`
-	Authors     []string               `json:"authors"`
-	CommitCount int                    `json:"commit_count"`
-	FileCount   int                    `json:"file_count"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	EpisodeID   string      `json:"episode_id"`
+	ChunkID     string      `json:"chunk_id,omitempty"` // Set for commit and chunk records
+	Granularity Granularity `json:"granularity"`
+	Repository  string      `json:"repository,omitempty"`
+	Text        string      `json:"text"`
+	Score       float32     `json:"score"` // Similarity score (cosine distance)
+	StartDate   time.Time   `json:"start_date"`
+	EndDate     time.Time   `json:"end_date"`
+	Authors     []string    `json:"authors"`
+	CommitCount int         `json:"commit_count"`
+	FileCount   int         `json:"file_count"`
 }
 
 // DefaultIndexOptions returns sensible defaults for indexing
@@ -38,9 +45,10 @@ func DefaultIndexOptions() IndexOptions {
 
 // IndexEpisodes processes episode summaries and stores their embeddings in the vector store
 // This function:
-// 1. Converts each episode summary to text
-// 2. Generates embeddings in batches
-// 3. Stores embeddings with metadata in Milvus
+// 1. Converts each episode summary, and each of its commit and chunk records, to text
+// 2. Groups the records into batches of whole episodes
+// 3. Embeds batches on EmbedWorkers goroutines and hands them to InsertWorkers goroutines,
+// which insert and flush them, reporting Progress after each
 // 4. Supports re-indexing options (skip existing, force reindex)
 func IndexEpisodes(
 	ctx context.Context,
@@ -63,73 +71,220 @@ func IndexEpisodes(
 
 	// Handle re-indexing: delete existing episodes if force reindex is enabled
 	if opts.ForceReindex {
-		episodeIDs := make([]string, len(episodes))
-		for i, ep := range episodes {
-			episodeIDs[i] = ep.EpisodeID
-		}
-
-		if err := vectorStore.Delete(ctx, episodeIDs); err != nil {
-			return fmt.Errorf("failed to delete existing episodes: %w", err)
+		for repository, episodeIDs := range episodeIDsByRepository(episodes) {
+			if err := vectorStore.Delete(ctx, repository, episodeIDs); err != nil {
+				return fmt.Errorf("failed to delete existing episodes: %w", err)
+			}
 		}
 	}
 
 	// Filter episodes if skip existing is enabled
 	episodesToIndex := episodes
 	if opts.SkipExisting && !opts.ForceReindex {
-		// For simplicity, we'll process all and let duplicates be handled by deletion
-		// A more sophisticated implementation could query existing episode IDs first
+		// IDs are content-derived, so only episodes whose commits changed (and so got new IDs) are embedded
 		episodesToIndex = filterNewEpisodes(ctx, episodes, vectorStore)
 	}
 
-	// Process episodes in batches
-	for batchStart := 0; batchStart < len(episodesToIndex); batchStart += opts.BatchSize {
-		batchEnd := batchStart + opts.BatchSize
-		if batchEnd > len(episodesToIndex) {
-			batchEnd = len(episodesToIndex)
-		}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultIndexOptions().BatchSize
+	}
+	batches := episodeBatches(episodesToIndex, batchSize)
 
-		batch := episodesToIndex[batchStart:batchEnd]
+	pipeline := &indexPipeline{
+		embedder:    embedder,
+		vectorStore: vectorStore,
+		batchSize:   batchSize,
+		opts:        opts,
+		progress:    IndexProgress{Episodes: len(episodesToIndex)},
+	}
+	for _, batch := range batches {
+		pipeline.progress.Records += len(batch.records)
+	}
+	return pipeline.run(ctx, batches)
+}
 
-		// Convert episodes to text
-		texts := make([]string, len(batch))
-		for i, episode := range batch {
-			texts[i] = episode.Summary
-		}
+// indexBatch is a group of whole episodes' records, embedded, inserted and flushed together
+type indexBatch struct {
+	start    int // Offset of the first record among all records of the run, for error messages
+	episodes int
+	records  []EpisodeRecord
+}
 
-		// Generate embeddings for the batch
-		embeddingRecords, err := embedder.Embed(ctx, texts)
-		if err != nil {
-			return fmt.Errorf("failed to generate embeddings for batch starting at %d: %w", batchStart, err)
+// episodeBatches groups episodes' records into batches of about size records, never splitting
+// an episode, so a flushed batch always leaves its episodes completely indexed
+func episodeBatches(episodes []EpisodeSummary, size int) []indexBatch {
+	var batches []indexBatch
+	var current indexBatch
+	offset := 0
+	for _, episode := range episodes {
+		records := episodeRecords(episode)
+		if len(current.records) > 0 && len(current.records)+len(records) > size {
+			batches = append(batches, current)
+			current = indexBatch{start: offset}
 		}
+		current.episodes++
+		current.records = append(current.records, records...)
+		offset += len(records)
+	}
+	if len(current.records) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// indexPipeline carries the shared state of one IndexEpisodes run
+type indexPipeline struct {
+	embedder    Embedder
+	vectorStore VectorStore
+	batchSize   int
+	opts        IndexOptions
+
+	mu       sync.Mutex
+	progress IndexProgress
+	errs     []error
+}
 
-		// Use batch insert for efficient storage
-		episodeRecords := make([]EpisodeRecord, len(batch))
-		for i, episode := range batch {
-			episodeRecords[i] = EpisodeRecord{
-				EpisodeID:   episode.EpisodeID,
-				Text:        embeddingRecords[i].Text,
-				Embedding:   embeddingRecords[i].Embedding,
-				StartDate:   episode.StartDate,
-				EndDate:     episode.EndDate,
-				Authors:     episode.Authors,
-				CommitCount: episode.CommitCount,
-				FileCount:   episode.FileCount,
+// run embeds and stores batches through the worker stages; a failure stops further batches
+// from being embedded unless ContinueOnError is set, but batches already embedded are still stored
+func (p *indexPipeline) run(ctx context.Context, batches []indexBatch) error {
+	stop, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pending := make(chan indexBatch)
+	embedded := make(chan indexBatch, max(p.opts.InsertWorkers, 1))
+
+	go func() {
+		defer close(pending)
+		for _, batch := range batches {
+			select {
+			case pending <- batch:
+			case <-stop.Done():
+				return
 			}
 		}
+	}()
+
+	var embedders sync.WaitGroup
+	for range max(p.opts.EmbedWorkers, 1) {
+		embedders.Add(1)
+		go func() {
+			defer embedders.Done()
+			for batch := range pending {
+				if stop.Err() != nil {
+					continue
+				}
+				if err := p.embed(stop, batch); err != nil {
+					p.fail(batch, fmt.Errorf("failed to generate embeddings for batch starting at %d: %w", batch.start, err), cancel)
+					continue
+				}
+				// Inserters drain the channel until it closes, so this never blocks forever
+				embedded <- batch
+			}
+		}()
+	}
+	go func() {
+		embedders.Wait()
+		close(embedded)
+	}()
+
+	var inserters sync.WaitGroup
+	for range max(p.opts.InsertWorkers, 1) {
+		inserters.Add(1)
+		go func() {
+			defer inserters.Done()
+			for batch := range embedded {
+				// Drain without inserting once the caller cancels; not every store checks ctx
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := p.vectorStore.Insert(ctx, batch.records); err != nil {
+					p.fail(batch, fmt.Errorf("failed to insert batch starting at %d: %w", batch.start, err), cancel)
+					continue
+				}
+				if err := p.vectorStore.Flush(ctx); err != nil {
+					p.fail(batch, fmt.Errorf("failed to flush batch starting at %d: %w", batch.start, err), cancel)
+					continue
+				}
+				p.done(batch)
+			}
+		}()
+	}
+	inserters.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.errs) > 0 {
+		return errors.Join(p.errs...)
+	}
+	// Cancelled by the caller rather than by a failed batch
+	return ctx.Err()
+}
+
+// embed fills in a batch's embeddings, calling the embedder with at most batchSize texts at a time
+func (p *indexPipeline) embed(ctx context.Context, batch indexBatch) error {
+	for start := 0; start < len(batch.records); start += p.batchSize {
+		records := batch.records[start:min(start+p.batchSize, len(batch.records))]
 
-		if err := vectorStore.Insert(ctx, episodeRecords); err != nil {
-			return fmt.Errorf("failed to insert batch starting at %d: %w", batchStart, err)
+		// Convert records to text
+		texts := make([]string, len(records))
+		var size int64
+		for i, record := range records {
+			texts[i] = record.Text
+			size += int64(len(record.Text))
 		}
 
-		// Flush after each batch
-		if err := vectorStore.Flush(ctx); err != nil {
-			return fmt.Errorf("failed to flush batch starting at %d: %w", batchStart, err)
+		embeddingRecords, err := p.embedder.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		if len(embeddingRecords) != len(records) {
+			return fmt.Errorf("%w: expected %d embeddings, got %d", ErrEmbeddingFailed, len(records), len(embeddingRecords))
+		}
+		for i := range records {
+			records[i].Text = embeddingRecords[i].Text
+			records[i].Embedding = embeddingRecords[i].Embedding
 		}
-	}
 
+		p.mu.Lock()
+		p.progress.BytesEmbedded += size
+		p.mu.Unlock()
+	}
 	return nil
 }
 
+// done records a flushed batch and reports progress
+func (p *indexPipeline) done(batch indexBatch) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.EpisodesDone += batch.episodes
+	p.progress.RecordsDone += len(batch.records)
+	p.report()
+}
+
+// fail records a failed batch, cancelling the run unless ContinueOnError is set
+func (p *indexPipeline) fail(batch indexBatch, err error, cancel context.CancelFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Batches abandoned because an earlier failure cancelled the run aren't failures of their own
+	if len(p.errs) > 0 && !p.opts.ContinueOnError {
+		return
+	}
+	p.errs = append(p.errs, err)
+	p.progress.EpisodesFailed += batch.episodes
+	p.report()
+	if !p.opts.ContinueOnError {
+		cancel()
+	}
+}
+
+// report passes a snapshot of the progress to the callback; the caller holds the lock
+func (p *indexPipeline) report() {
+	if p.opts.Progress != nil {
+		p.opts.Progress(p.progress)
+	}
+}
+
 // filterNewEpisodes removes episodes that already exist in the vector store
 func filterNewEpisodes(
 	ctx context.Context,
@@ -140,27 +295,68 @@ func filterNewEpisodes(
 		return episodes
 	}
 
-	// Extract episode IDs
-	episodeIDs := make([]string, len(episodes))
-	for i, ep := range episodes {
-		episodeIDs[i] = ep.EpisodeID
-	}
-
-	// Query which episodes exist
-	existingMap, err := vectorStore.Query(ctx, episodeIDs)
-	if err != nil {
-		// If query fails, return all episodes to be safe
-		// The caller will handle any errors during insertion
-		return episodes
+	// Query which episodes exist in their own repository, so a fork's episodes are not skipped
+	// because its upstream shares their IDs
+	existing := make(map[string]map[string]bool)
+	for repository, episodeIDs := range episodeIDsByRepository(episodes) {
+		existingMap, err := vectorStore.Query(ctx, repository, episodeIDs)
+		if err != nil {
+			// If query fails, return all episodes to be safe
+			// The caller will handle any errors during insertion
+			return episodes
+		}
+		existing[repository] = existingMap
 	}
 
 	// Filter out existing episodes
 	newEpisodes := make([]EpisodeSummary, 0, len(episodes))
 	for _, ep := range episodes {
-		if !existingMap[ep.EpisodeID] {
+		if !existing[ep.Repository][ep.EpisodeID] {
 			newEpisodes = append(newEpisodes, ep)
 		}
 	}
 
 	return newEpisodes
 }
+
+// episodeIDsByRepository groups episode IDs by the repository their episodes belong to
+func episodeIDsByRepository(episodes []EpisodeSummary) map[string][]string {
+	grouped := make(map[string][]string)
+	for _, ep := range episodes {
+		grouped[ep.Repository] = append(grouped[ep.Repository], ep.EpisodeID)
+	}
+	return grouped
+}
+
+// episodeRecords converts an episode summary and its chunks into records awaiting embeddings
+func episodeRecords(episode EpisodeSummary) []EpisodeRecord {
+	records := make([]EpisodeRecord, 0, 1+len(episode.Chunks))
+	records = append(records, EpisodeRecord{
+		EpisodeID:   episode.EpisodeID,
+		Granularity: GranularityEpisode,
+		Repository:  episode.Repository,
+		Text:        episode.Summary,
+		StartDate:   episode.StartDate,
+		EndDate:     episode.EndDate,
+		Authors:     episode.Authors,
+		Paths:       episode.Paths,
+		CommitCount: episode.CommitCount,
+		FileCount:   episode.FileCount,
+	})
+	for _, chunk := range episode.Chunks {
+		records = append(records, EpisodeRecord{
+			EpisodeID:   episode.EpisodeID,
+			ChunkID:     chunk.ID,
+			Granularity: chunk.Granularity,
+			Repository:  episode.Repository,
+			Text:        chunk.Text,
+			StartDate:   chunk.StartDate,
+			EndDate:     chunk.EndDate,
+			Authors:     chunk.Authors,
+			Paths:       chunk.Paths,
+			CommitCount: chunk.CommitCount,
+			FileCount:   chunk.FileCount,
+		})
+	}
+	return records
+}
@@ -0,0 +1,155 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEpisodeBatches_KeepEpisodesWhole(t *testing.T) {
+	episodes := []EpisodeSummary{
+		{EpisodeID: "E1", Summary: "one", Chunks: []Chunk{{ID: "commit:a"}, {ID: "commit:b"}}},
+		{EpisodeID: "E2", Summary: "two"},
+		{EpisodeID: "E3", Summary: "three", Chunks: []Chunk{{ID: "commit:c"}, {ID: "commit:d"}, {ID: "commit:e"}}},
+	}
+
+	batches := episodeBatches(episodes, 4)
+	if len(batches) != 2 {
+		t.Fatalf("Expected 2 batches, got %d", len(batches))
+	}
+	if batches[0].episodes != 2 || len(batches[0].records) != 4 || batches[0].start != 0 {
+		t.Errorf("Unexpected first batch: %+v", batches[0])
+	}
+	// E3 has more records than the batch size but is never split
+	if batches[1].episodes != 1 || len(batches[1].records) != 4 || batches[1].start != 4 {
+		t.Errorf("Unexpected second batch: %+v", batches[1])
+	}
+}
+
+func TestIndexEpisodes_Progress(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{})
+	episodes := []EpisodeSummary{
+		{EpisodeID: "E1", Summary: "abcd", Chunks: []Chunk{{ID: "commit:a", Granularity: GranularityCommit, Text: "ef"}}},
+		{EpisodeID: "E2", Summary: "ghi"},
+		{EpisodeID: "E3", Summary: "jk"},
+	}
+
+	var reports []IndexProgress
+	opts := IndexOptions{
+		BatchSize:     2,
+		EmbedWorkers:  2,
+		InsertWorkers: 2,
+		Progress:      func(p IndexProgress) { reports = append(reports, p) },
+	}
+	if err := IndexEpisodes(ctx, episodes, &mockEmbedder{}, store, opts); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected a report per batch, got %+v", reports)
+	}
+	last := reports[len(reports)-1]
+	want := IndexProgress{Episodes: 3, EpisodesDone: 3, Records: 4, RecordsDone: 4, BytesEmbedded: 11}
+	if last != want {
+		t.Errorf("Expected %+v, got %+v", want, last)
+	}
+	if existing, _ := store.Query(ctx, "", []string{"E1", "E2", "E3"}); len(existing) != 3 {
+		t.Errorf("Expected every episode stored, got %v", existing)
+	}
+}
+
+func TestIndexEpisodes_ContinueOnError(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{})
+	embedder := &mockEmbedder{}
+	embedder.embedFunc = func(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+		if texts[0] == "broken" {
+			return nil, errors.New("rate limited")
+		}
+		return (&mockEmbedder{}).Embed(ctx, texts)
+	}
+	episodes := []EpisodeSummary{
+		{EpisodeID: "E1", Summary: "first"},
+		{EpisodeID: "E2", Summary: "broken"},
+		{EpisodeID: "E3", Summary: "third"},
+	}
+
+	var last IndexProgress
+	opts := IndexOptions{BatchSize: 1, ContinueOnError: true, Progress: func(p IndexProgress) { last = p }}
+	err := IndexEpisodes(ctx, episodes, embedder, store, opts)
+	if err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Fatalf("Expected the failed batch to be reported, got %v", err)
+	}
+	if last.EpisodesDone != 2 || last.EpisodesFailed != 1 {
+		t.Errorf("Expected 2 episodes done and 1 failed, got %+v", last)
+	}
+	if existing, _ := store.Query(ctx, "", []string{"E1", "E2", "E3"}); !existing["E1"] || existing["E2"] || !existing["E3"] {
+		t.Errorf("Expected the batches around the failure stored, got %v", existing)
+	}
+}
+
+func TestIndexEpisodes_Resume(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{})
+	episodes := []EpisodeSummary{
+		{EpisodeID: "E1", Summary: "first"},
+		{EpisodeID: "E2", Summary: "second"},
+		{EpisodeID: "E3", Summary: "third"},
+	}
+
+	// The second embedding call fails, stopping the run after the first batch
+	var calls atomic.Int32
+	failing := &mockEmbedder{embedFunc: func(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+		if calls.Add(1) == 2 {
+			return nil, errors.New("connection reset")
+		}
+		return (&mockEmbedder{}).Embed(ctx, texts)
+	}}
+	opts := IndexOptions{BatchSize: 1, SkipExisting: true}
+	if err := IndexEpisodes(ctx, episodes, failing, store, opts); err == nil {
+		t.Fatal("Expected the interrupted run to fail")
+	}
+	if existing, _ := store.Query(ctx, "", []string{"E1", "E2", "E3"}); !existing["E1"] || existing["E2"] || existing["E3"] {
+		t.Fatalf("Expected only the first batch stored, got %v", existing)
+	}
+
+	var embedded []string
+	resumed := &mockEmbedder{embedFunc: func(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+		embedded = append(embedded, texts...)
+		return (&mockEmbedder{}).Embed(ctx, texts)
+	}}
+	if err := IndexEpisodes(ctx, episodes, resumed, store, opts); err != nil {
+		t.Fatalf("Resumed IndexEpisodes failed: %v", err)
+	}
+	if strings.Join(embedded, ",") != "second,third" {
+		t.Errorf("Expected only the unflushed episodes embedded again, got %v", embedded)
+	}
+}
+
+func TestIndexEpisodes_SkipExistingPerRepository(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{})
+	upstream := []EpisodeSummary{{EpisodeID: "E1", Repository: "owner/app", Summary: "upstream"}}
+	if err := IndexEpisodes(ctx, upstream, &mockEmbedder{}, store, IndexOptions{SkipExisting: true}); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+
+	// A fork shares the upstream's history, and so its episode IDs
+	fork := []EpisodeSummary{{EpisodeID: "E1", Repository: "fork/app", Summary: "fork"}}
+	if err := IndexEpisodes(ctx, fork, &mockEmbedder{}, store, IndexOptions{SkipExisting: true}); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+	if existing, _ := store.Query(ctx, "fork/app", []string{"E1"}); !existing["E1"] {
+		t.Error("Expected the fork's E1 indexed despite the upstream's")
+	}
+
+	if err := IndexEpisodes(ctx, fork, &mockEmbedder{}, store, IndexOptions{ForceReindex: true}); err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+	if stats, _ := store.GetStats(ctx); stats["row_count"] != 2 {
+		t.Errorf("Expected reindexing the fork to keep the upstream's E1, got %v rows", stats["row_count"])
+	}
+}
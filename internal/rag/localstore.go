@@ -0,0 +1,456 @@
+package rag
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// localStoreVersion is bumped whenever the LocalStore file encoding changes
+const localStoreVersion = 1
+
+// LocalStoreConfig holds configuration for the in-process vector store
+type LocalStoreConfig struct {
+	Path      string // File records are persisted to on Flush and Close; empty keeps them in memory only
+	Dimension int    // Vector dimension; 0 takes the dimension of the first record, and a persisted store keeps its own
+
+	// HNSW searches an approximate nearest-neighbor graph instead of comparing every vector,
+	// which pays off from tens of thousands of episodes; the graph is rebuilt in memory, not persisted
+	// Filtered searches still compare every matching vector
+	HNSW           bool
+	M              int // HNSW neighbors per node (default: 16)
+	EfConstruction int // HNSW candidate list size while building (default: 200)
+	EfSearch       int // HNSW candidate list size while searching (default: 64)
+}
+
+// DefaultLocalStoreConfig returns a flat-search configuration persisted to path
+func DefaultLocalStoreConfig(path string) LocalStoreConfig {
+	return LocalStoreConfig{
+		Path:           path,
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+	}
+}
+
+// LocalStore implements VectorStore in process with cosine similarity search, so the RAG pipeline
+// runs without external services; records are kept in memory and persisted to a single file
+// Inserting a record replaces any record with the same repository, episode and chunk ID
+type LocalStore struct {
+	mu      sync.RWMutex
+	config  LocalStoreConfig
+	records []EpisodeRecord
+	unit    [][]float32 // Normalized embeddings, parallel to records
+	index   *hnswIndex  // Built lazily when HNSW is enabled; nil when stale
+	dirty   bool        // Records changed since the last persist
+}
+
+// localStoreFile is the persisted form of a LocalStore
+type localStoreFile struct {
+	Version   int
+	Dimension int
+	Records   []EpisodeRecord
+}
+
+// NewLocalStore creates an in-process vector store, loading the records persisted at config.Path
+// if the file exists; a file whose dimension differs from config.Dimension opens as is, so it can be
+// migrated, and rejects inserts until it is
+func NewLocalStore(config LocalStoreConfig) (*LocalStore, error) {
+	if config.Dimension < 0 {
+		return nil, ErrInvalidDimension
+	}
+	defaults := DefaultLocalStoreConfig(config.Path)
+	if config.M <= 0 {
+		config.M = defaults.M
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = defaults.EfConstruction
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = defaults.EfSearch
+	}
+
+	store := &LocalStore{config: config}
+	if config.Path == "" {
+		return store, nil
+	}
+
+	file, err := os.Open(config.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to open vector store: %w", err)
+	}
+	defer file.Close()
+
+	var persisted localStoreFile
+	if err := gob.NewDecoder(file).Decode(&persisted); err != nil {
+		return nil, fmt.Errorf("failed to read vector store %s: %w", config.Path, err)
+	}
+	if persisted.Version != localStoreVersion {
+		return nil, fmt.Errorf("unsupported vector store version %d in %s", persisted.Version, config.Path)
+	}
+	if persisted.Dimension > 0 {
+		store.config.Dimension = persisted.Dimension
+	}
+	store.records = persisted.Records
+	store.unit = make([][]float32, len(store.records))
+	for i, record := range store.records {
+		store.unit[i] = normalizeVector(record.Embedding)
+	}
+	return store, nil
+}
+
+// Insert adds episodes, replacing stored records with the same repository, episode and chunk ID
+func (s *LocalStore) Insert(ctx context.Context, episodes []EpisodeRecord) error {
+	if len(episodes) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, episode := range episodes {
+		if s.config.Dimension == 0 {
+			s.config.Dimension = len(episode.Embedding)
+		}
+		if len(episode.Embedding) != s.config.Dimension || s.config.Dimension == 0 {
+			return fmt.Errorf("%w: %w: expected %d, got %d for episode %s", ErrInsertFailed, ErrInvalidDimension, s.config.Dimension, len(episode.Embedding), episode.EpisodeID)
+		}
+	}
+
+	positions := make(map[string]int, len(s.records))
+	for i, record := range s.records {
+		positions[recordKey(record.Repository, record.EpisodeID, record.ChunkID)] = i
+	}
+	for _, episode := range episodes {
+		key := recordKey(episode.Repository, episode.EpisodeID, episode.ChunkID)
+		if i, ok := positions[key]; ok {
+			s.records[i] = episode
+			s.unit[i] = normalizeVector(episode.Embedding)
+			continue
+		}
+		positions[key] = len(s.records)
+		s.records = append(s.records, episode)
+		s.unit = append(s.unit, normalizeVector(episode.Embedding))
+	}
+
+	s.index = nil
+	s.dirty = true
+	return nil
+}
+
+// Flush writes the records to the store's file, if it has one and anything changed
+// The file is replaced atomically so an interrupted write never leaves a truncated store
+func (s *LocalStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persist()
+}
+
+// persist writes the records to disk; the caller holds the lock
+func (s *LocalStore) persist() error {
+	if s.config.Path == "" || !s.dirty {
+		return nil
+	}
+
+	if dir := filepath.Dir(s.config.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create vector store directory: %w", err)
+		}
+	}
+
+	tmp := s.config.Path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to write vector store: %w", err)
+	}
+	persisted := localStoreFile{Version: localStoreVersion, Dimension: s.config.Dimension, Records: s.records}
+	if err := gob.NewEncoder(file).Encode(persisted); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to encode vector store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write vector store: %w", err)
+	}
+	if err := os.Rename(tmp, s.config.Path); err != nil {
+		return fmt.Errorf("failed to write vector store: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// Search returns the topK records most similar to queryVector by cosine similarity, best first
+// With EpisodeIDs, Repository, Granularities or a metadata filter set only matching records are considered; a nil queryVector then
+// returns the episode ID matches unscored, which is how the retriever looks an episode up by ID
+func (s *LocalStore) Search(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var filter map[string]bool
+	var repository string
+	var granularities []Granularity
+	var metadata MetadataFilter
+	if opts != nil {
+		repository = opts.Repository
+		granularities = opts.Granularities
+		metadata = opts.MetadataFilter
+		if len(opts.EpisodeIDs) > 0 {
+			filter = make(map[string]bool, len(opts.EpisodeIDs))
+			for _, id := range opts.EpisodeIDs {
+				filter[id] = true
+			}
+		}
+	}
+	matchesFilter := func(record EpisodeRecord) bool {
+		return (filter == nil || filter[record.EpisodeID]) && (repository == "" || record.Repository == repository) &&
+			matchesGranularity(record.Granularity, granularities) && metadata.matches(record)
+	}
+
+	if queryVector == nil {
+		if filter == nil {
+			return nil, fmt.Errorf("%w: a query vector or episode IDs are required", ErrSearchFailed)
+		}
+		chunks := make([]ContextChunk, 0)
+		for i := range s.records {
+			if matchesFilter(s.records[i]) && len(chunks) < topK {
+				chunks = append(chunks, recordChunk(s.records[i], 0))
+			}
+		}
+		return chunks, nil
+	}
+
+	if len(queryVector) != s.config.Dimension {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrInvalidDimension, s.config.Dimension, len(queryVector))
+	}
+	if topK <= 0 {
+		return []ContextChunk{}, nil
+	}
+	query := normalizeVector(queryVector)
+
+	var matches []scoredRecord
+	if s.config.HNSW && filter == nil && repository == "" && len(granularities) == 0 && metadata.IsZero() {
+		if s.index == nil {
+			s.index = newHNSWIndex(s.config.M, s.config.EfConstruction)
+			for _, vector := range s.unit {
+				s.index.add(vector)
+			}
+		}
+		matches = s.index.search(query, topK, max(s.config.EfSearch, topK))
+	} else {
+		matches = make([]scoredRecord, 0, len(s.records))
+		for i := range s.records {
+			if !matchesFilter(s.records[i]) {
+				continue
+			}
+			matches = append(matches, scoredRecord{index: i, score: dot(query, s.unit[i])})
+		}
+		sortScored(matches)
+		if len(matches) > topK {
+			matches = matches[:topK]
+		}
+	}
+
+	chunks := make([]ContextChunk, len(matches))
+	for i, match := range matches {
+		chunks[i] = recordChunk(s.records[match.index], match.score)
+	}
+	return chunks, nil
+}
+
+// Query checks which episode IDs have any record in the store for repository ("" = any)
+func (s *LocalStore) Query(ctx context.Context, repository string, episodeIDs []string) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	existence := make(map[string]bool, len(episodeIDs))
+	for _, id := range episodeIDs {
+		existence[id] = false
+	}
+	for _, record := range s.records {
+		if _, ok := existence[record.EpisodeID]; ok && (repository == "" || record.Repository == repository) {
+			existence[record.EpisodeID] = true
+		}
+	}
+	return existence, nil
+}
+
+// Delete removes every record of the given episodes from repository ("" = every repository)
+func (s *LocalStore) Delete(ctx context.Context, repository string, episodeIDs []string) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remove := make(map[string]bool, len(episodeIDs))
+	for _, id := range episodeIDs {
+		remove[id] = true
+	}
+	records := s.records[:0]
+	unit := s.unit[:0]
+	for i, record := range s.records {
+		if !remove[record.EpisodeID] || (repository != "" && record.Repository != repository) {
+			records = append(records, record)
+			unit = append(unit, s.unit[i])
+		}
+	}
+	if len(records) != len(s.records) {
+		s.index = nil
+		s.dirty = true
+	}
+	s.records, s.unit = records, unit
+	return nil
+}
+
+// GetStats returns the record count, vector dimension, search method and file
+func (s *LocalStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index := "flat"
+	if s.config.HNSW {
+		index = "hnsw"
+	}
+	return map[string]interface{}{
+		"row_count": len(s.records),
+		"dimension": s.config.Dimension,
+		"index":     index,
+		"path":      s.config.Path,
+	}, nil
+}
+
+// Close persists pending changes
+func (s *LocalStore) Close() error {
+	return s.Flush(context.Background())
+}
+
+// Schema reports the store's layout; records always carry the current fields once loaded
+func (s *LocalStore) Schema(ctx context.Context) (SchemaVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return SchemaVersion{Version: CurrentSchemaVersion, Dimension: s.config.Dimension}, nil
+}
+
+// ExportRecords calls fn with a snapshot of the stored records
+func (s *LocalStore) ExportRecords(ctx context.Context, fn func([]EpisodeRecord) error) error {
+	s.mu.RLock()
+	records := make([]EpisodeRecord, len(s.records))
+	copy(records, s.records)
+	s.mu.RUnlock()
+
+	for start := 0; start < len(records); start += maxFilterValues {
+		if err := fn(records[start:min(start+maxFilterValues, len(records))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stage creates an in-memory store with the target dimension
+func (s *LocalStore) Stage(ctx context.Context, target SchemaVersion) (VectorStore, error) {
+	config := s.config
+	config.Path = ""
+	config.Dimension = target.Dimension
+	return NewLocalStore(config)
+}
+
+// Swap takes over a staged store's records and persists them, replacing the file atomically
+func (s *LocalStore) Swap(ctx context.Context, staged VectorStore) error {
+	next, ok := staged.(*LocalStore)
+	if !ok {
+		return fmt.Errorf("cannot swap a %T into a local store", staged)
+	}
+
+	next.mu.RLock()
+	records, unit, dimension := next.records, next.unit, next.config.Dimension
+	next.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oldRecords, oldUnit, oldDimension := s.records, s.unit, s.config.Dimension
+	s.records, s.unit, s.config.Dimension = records, unit, dimension
+	s.index = nil
+	s.dirty = true
+	if err := s.persist(); err != nil {
+		// Keep serving the old records, which are still what the file holds
+		s.records, s.unit, s.config.Dimension = oldRecords, oldUnit, oldDimension
+		return err
+	}
+	return nil
+}
+
+// Discard drops a staged store; it was only ever in memory
+func (s *LocalStore) Discard(ctx context.Context, staged VectorStore) error {
+	return nil
+}
+
+// scoredRecord is a record index with its similarity to a query
+type scoredRecord struct {
+	index int
+	score float32
+}
+
+// sortScored orders matches by score, best first, then by insertion order for determinism
+func sortScored(matches []scoredRecord) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].index < matches[j].index
+	})
+}
+
+// recordChunk converts a stored record into a search result
+func recordChunk(record EpisodeRecord, score float32) ContextChunk {
+	return ContextChunk{
+		EpisodeID:   record.EpisodeID,
+		ChunkID:     record.ChunkID,
+		Granularity: granularityOf(record.Granularity),
+		Repository:  record.Repository,
+		Text:        record.Text,
+		Score:       score,
+		StartDate:   record.StartDate,
+		EndDate:     record.EndDate,
+		Authors:     record.Authors,
+		CommitCount: record.CommitCount,
+		FileCount:   record.FileCount,
+	}
+}
+
+// normalizeVector returns a unit-length copy of vector, or a zero vector if it has no length
+func normalizeVector(vector []float32) []float32 {
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	unit := make([]float32, len(vector))
+	if norm == 0 {
+		return unit
+	}
+	norm = math.Sqrt(norm)
+	for i, value := range vector {
+		unit[i] = float32(float64(value) / norm)
+	}
+	return unit
+}
+
+// dot returns the dot product of two vectors of equal length, their cosine similarity when normalized
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,332 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func localRecord(id string, embedding ...float32) EpisodeRecord {
+	return EpisodeRecord{
+		EpisodeID:   id,
+		Text:        "episode " + id,
+		Embedding:   embedding,
+		StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Authors:     []string{"alice"},
+		CommitCount: 3,
+		FileCount:   2,
+	}
+}
+
+// TestLocalStore_SearchRanksByCosine tests that results are ordered by cosine similarity
+func TestLocalStore_SearchRanksByCosine(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(LocalStoreConfig{})
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+
+	err = store.Insert(ctx, []EpisodeRecord{
+		localRecord("E1", 1, 0, 0),
+		localRecord("E2", 0, 1, 0),
+		localRecord("E3", 10, 10, 0),
+	})
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	chunks, err := store.Search(ctx, []float32{1, 0.1, 0}, 2, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(chunks))
+	}
+	if chunks[0].EpisodeID != "E1" || chunks[1].EpisodeID != "E3" {
+		t.Errorf("Expected E1 then E3, got %s then %s", chunks[0].EpisodeID, chunks[1].EpisodeID)
+	}
+	if chunks[0].Score <= chunks[1].Score {
+		t.Errorf("Expected descending scores, got %f and %f", chunks[0].Score, chunks[1].Score)
+	}
+	if chunks[0].Text != "episode E1" || chunks[0].CommitCount != 3 || len(chunks[0].Authors) != 1 {
+		t.Errorf("Expected record metadata on result, got %+v", chunks[0])
+	}
+
+	if _, err := store.Search(ctx, []float32{1, 0}, 2, nil); err == nil {
+		t.Error("Expected error for query with wrong dimension")
+	}
+	if err := store.Insert(ctx, []EpisodeRecord{localRecord("E4", 1, 0)}); err == nil {
+		t.Error("Expected error for record with wrong dimension")
+	}
+}
+
+// TestLocalStore_FilterReplaceDelete tests episode ID filters, upserts, and deletes
+func TestLocalStore_FilterReplaceDelete(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{Dimension: 2})
+	store.Insert(ctx, []EpisodeRecord{localRecord("E1", 1, 0), localRecord("E2", 0, 1)})
+
+	chunks, err := store.Search(ctx, []float32{1, 0}, 5, &SearchOptions{EpisodeIDs: []string{"E2"}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E2" {
+		t.Errorf("Expected only E2, got %+v", chunks)
+	}
+
+	chunks, err = store.Search(ctx, nil, 1, &SearchOptions{EpisodeIDs: []string{"E1"}})
+	if err != nil {
+		t.Fatalf("Lookup by ID failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected E1 by ID, got %+v", chunks)
+	}
+
+	replacement := localRecord("E1", 0, 1)
+	replacement.Text = "rewritten"
+	store.Insert(ctx, []EpisodeRecord{replacement})
+	stats, _ := store.GetStats(ctx)
+	if stats["row_count"] != 2 {
+		t.Errorf("Expected replacement to keep 2 rows, got %v", stats["row_count"])
+	}
+	chunks, _ = store.Search(ctx, nil, 1, &SearchOptions{EpisodeIDs: []string{"E1"}})
+	if chunks[0].Text != "rewritten" {
+		t.Errorf("Expected replaced text, got %q", chunks[0].Text)
+	}
+
+	store.Delete(ctx, "", []string{"E1"})
+	existence, _ := store.Query(ctx, "", []string{"E1", "E2"})
+	if existence["E1"] || !existence["E2"] {
+		t.Errorf("Expected only E2 to exist, got %v", existence)
+	}
+}
+
+// TestLocalStore_RepositoryFilter tests that searches scoped to a repository skip other repositories
+func TestLocalStore_RepositoryFilter(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{HNSW: true})
+
+	app := localRecord("app:E1", 1, 0)
+	app.Repository = "owner/app"
+	lib := localRecord("lib:E1", 1, 0.1)
+	lib.Repository = "owner/lib"
+	store.Insert(ctx, []EpisodeRecord{app, lib})
+
+	chunks, err := store.Search(ctx, []float32{0, 1}, 5, &SearchOptions{Repository: "owner/app"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "app:E1" || chunks[0].Repository != "owner/app" {
+		t.Errorf("Expected only owner/app's episode, got %+v", chunks)
+	}
+
+	chunks, _ = store.Search(ctx, []float32{0, 1}, 5, nil)
+	if len(chunks) != 2 {
+		t.Errorf("Expected unscoped search to return both repositories, got %d results", len(chunks))
+	}
+}
+
+// TestLocalStore_SharedEpisodeIDs tests that a fork and its upstream keep episodes with the same ID apart
+func TestLocalStore_SharedEpisodeIDs(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{})
+
+	upstream := localRecord("E1", 1, 0)
+	upstream.Repository = "owner/app"
+	fork := localRecord("E1", 0, 1)
+	fork.Repository = "fork/app"
+	store.Insert(ctx, []EpisodeRecord{upstream, fork})
+	if stats, _ := store.GetStats(ctx); stats["row_count"] != 2 {
+		t.Fatalf("Expected both repositories' E1 to be stored, got %v rows", stats["row_count"])
+	}
+
+	store.Delete(ctx, "fork/app", []string{"E1"})
+	if existence, _ := store.Query(ctx, "fork/app", []string{"E1"}); existence["E1"] {
+		t.Error("Expected E1 to be deleted from the fork")
+	}
+	if existence, _ := store.Query(ctx, "owner/app", []string{"E1"}); !existence["E1"] {
+		t.Error("Expected deleting the fork's E1 to keep the upstream's")
+	}
+}
+
+// TestLocalStore_Granularity tests indexing an episode's commits and chunks alongside its summary
+func TestLocalStore_Granularity(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{})
+
+	summary := EpisodeSummary{
+		EpisodeID: "E1",
+		Summary:   "Reworked networking",
+		Chunks: []Chunk{
+			{ID: "commit:abc", Granularity: GranularityCommit, Text: "Commit abc: add retry backoff", CommitCount: 1},
+			{ID: "chunk:1", Granularity: GranularityChunk, Text: "Commits 1-2 of the episode", CommitCount: 2},
+		},
+	}
+	err := IndexEpisodes(ctx, []EpisodeSummary{summary, {EpisodeID: "E2", Summary: "Docs"}}, &mockEmbedder{}, store, IndexOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("IndexEpisodes failed: %v", err)
+	}
+	if stats, _ := store.GetStats(ctx); stats["row_count"] != 4 {
+		t.Errorf("Expected 4 records (2 summaries, a commit and a chunk), got %v", stats["row_count"])
+	}
+
+	chunks, err := store.Search(ctx, []float32{1, 0, 1}, 5, &SearchOptions{Granularities: []Granularity{GranularityCommit}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].ChunkID != "commit:abc" || chunks[0].Granularity != GranularityCommit || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected only the commit record, got %+v", chunks)
+	}
+
+	chunks, _ = store.Search(ctx, nil, 5, &SearchOptions{EpisodeIDs: []string{"E1"}, Granularities: []Granularity{GranularityEpisode}})
+	if len(chunks) != 1 || chunks[0].Text != "Reworked networking" || chunks[0].Granularity != GranularityEpisode {
+		t.Errorf("Expected the episode summary, got %+v", chunks)
+	}
+
+	// Reindexing replaces records by episode and chunk ID instead of adding duplicates
+	if err := IndexEpisodes(ctx, []EpisodeSummary{summary}, &mockEmbedder{}, store, IndexOptions{BatchSize: 10}); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if stats, _ := store.GetStats(ctx); stats["row_count"] != 4 {
+		t.Errorf("Expected reindexing to keep 4 records, got %v", stats["row_count"])
+	}
+
+	store.Delete(ctx, "", []string{"E1"})
+	existence, _ := store.Query(ctx, "", []string{"E1", "E2"})
+	if existence["E1"] || !existence["E2"] {
+		t.Errorf("Expected deleting E1 to remove all its records, got %v", existence)
+	}
+}
+
+// TestLocalStore_MetadataFilter tests queries scoped by date range, author and touched paths
+func TestLocalStore_MetadataFilter(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewLocalStore(LocalStoreConfig{HNSW: true})
+
+	march := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	bob := localRecord("E1", 1, 0, 1)
+	bob.Authors = []string{"Bob"}
+	bob.StartDate, bob.EndDate = march, march.Add(48*time.Hour)
+	bob.Paths = []string{"internal/retry/backoff.go"}
+	alice := localRecord("E2", 1, 0, 1)
+	alice.Paths = []string{"README.md"}
+	store.Insert(ctx, []EpisodeRecord{bob, alice})
+
+	retriever, _ := NewRetriever(&mockEmbedder{}, store)
+	filter := MetadataFilter{
+		Since:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Until:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		Authors: []string{"bob"},
+	}
+	chunks, err := retriever.RetrieveContextForQuery(ctx, "what did Bob do in March?", 5, &SearchOptions{MetadataFilter: filter})
+	if err != nil {
+		t.Fatalf("RetrieveContextForQuery failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected only Bob's March episode, got %+v", chunks)
+	}
+
+	chunks, _ = store.Search(ctx, []float32{1, 0, 1}, 5, &SearchOptions{MetadataFilter: MetadataFilter{Paths: []string{"internal/retry"}}})
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected only the episode touching internal/retry, got %+v", chunks)
+	}
+
+	chunks, _ = store.Search(ctx, []float32{1, 0, 1}, 5, &SearchOptions{MetadataFilter: MetadataFilter{Until: march.Add(-time.Hour)}})
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E2" {
+		t.Errorf("Expected only the episode started before Bob's, got %+v", chunks)
+	}
+}
+
+// TestLocalStore_Persistence tests that records survive a close and reopen
+func TestLocalStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "episodes.db")
+
+	store, err := NewLocalStore(LocalStoreConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	store.Insert(ctx, []EpisodeRecord{localRecord("E1", 1, 2, 3), localRecord("E2", 3, 2, 1)})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := NewLocalStore(LocalStoreConfig{Path: path})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	stats, _ := reopened.GetStats(ctx)
+	if stats["row_count"] != 2 || stats["dimension"] != 3 {
+		t.Errorf("Expected 2 rows of dimension 3, got %v", stats)
+	}
+	chunks, err := reopened.Search(ctx, []float32{1, 2, 3}, 1, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" || !chunks[0].StartDate.Equal(localRecord("E1").StartDate) {
+		t.Errorf("Expected persisted E1, got %+v", chunks)
+	}
+
+	mismatched, err := NewLocalStore(LocalStoreConfig{Path: path, Dimension: 4})
+	if err != nil {
+		t.Fatalf("Expected a store with a different dimension to open for migration, got: %v", err)
+	}
+	if schema, _ := mismatched.Schema(ctx); schema.Dimension != 3 {
+		t.Errorf("Expected the persisted dimension 3, got %d", schema.Dimension)
+	}
+	if err := mismatched.Insert(ctx, []EpisodeRecord{localRecord("E3", 1, 2, 3, 4)}); err == nil {
+		t.Error("Expected inserts of the configured dimension to fail until migrated")
+	}
+}
+
+// TestLocalStore_HNSWMatchesFlat tests that approximate search finds the exact nearest neighbors
+func TestLocalStore_HNSWMatchesFlat(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(7))
+	const dimension = 16
+
+	records := make([]EpisodeRecord, 500)
+	for i := range records {
+		embedding := make([]float32, dimension)
+		for d := range embedding {
+			embedding[d] = rng.Float32()*2 - 1
+		}
+		records[i] = localRecord(fmt.Sprintf("E%d", i), embedding...)
+	}
+
+	flat, _ := NewLocalStore(LocalStoreConfig{})
+	approximate, _ := NewLocalStore(LocalStoreConfig{HNSW: true})
+	flat.Insert(ctx, records)
+	approximate.Insert(ctx, records)
+
+	hits, total := 0, 0
+	for q := 0; q < 20; q++ {
+		query := make([]float32, dimension)
+		for d := range query {
+			query[d] = rng.Float32()*2 - 1
+		}
+		exact, _ := flat.Search(ctx, query, 5, nil)
+		found, err := approximate.Search(ctx, query, 5, nil)
+		if err != nil {
+			t.Fatalf("HNSW search failed: %v", err)
+		}
+		expected := make(map[string]bool)
+		for _, chunk := range exact {
+			expected[chunk.EpisodeID] = true
+		}
+		for _, chunk := range found {
+			if expected[chunk.EpisodeID] {
+				hits++
+			}
+		}
+		total += len(exact)
+	}
+
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Errorf("Expected HNSW recall of at least 0.9, got %.2f", recall)
+	}
+}
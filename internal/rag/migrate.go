@@ -0,0 +1,153 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CurrentSchemaVersion is the record layout stores create
+// Version 1 predates records carrying their repository; version 2 adds it, version 3 adds
+// the chunk ID and granularity of commit and chunk records, version 4 the author and path
+// arrays metadata filters match, and version 5 stores authors as a list rather than one
+// comma-separated string, so names containing commas survive
+const CurrentSchemaVersion = 5
+
+// migrateEmbedBatch bounds the texts re-embedded per embedder call during a migration
+const migrateEmbedBatch = 100
+
+// ErrUnsupportedSchema is returned for migrations to a schema version stores cannot create
+var ErrUnsupportedSchema = errors.New("unsupported schema version")
+
+// SchemaVersion identifies the layout of a vector store's records
+type SchemaVersion struct {
+	Version   int // Record fields, see CurrentSchemaVersion
+	Dimension int // Embedding dimension; 0 for an empty store that takes the first record's
+}
+
+// Compatible reports whether records for target can be stored without migrating
+func (s SchemaVersion) Compatible(target SchemaVersion) bool {
+	return s.Version == target.Version && (s.Dimension == 0 || s.Dimension == target.Dimension)
+}
+
+// String describes the schema for error messages and logs
+func (s SchemaVersion) String() string {
+	return fmt.Sprintf("v%d with %d dimensions", s.Version, s.Dimension)
+}
+
+// Migratable is a vector store whose records can be copied into a new schema and swapped in
+type Migratable interface {
+	VectorStore
+	Exportable
+
+	// Stage creates an empty store with the target schema, to be filled and then swapped in
+	Stage(ctx context.Context, target SchemaVersion) (VectorStore, error)
+
+	// Swap atomically replaces the store's records with those of a staged store
+	// On error the staged store is left in place, since it may hold the only copy of the records
+	Swap(ctx context.Context, staged VectorStore) error
+
+	// Discard removes a staged store after a failed migration
+	Discard(ctx context.Context, staged VectorStore) error
+}
+
+// MigrationResult describes a completed migration
+type MigrationResult struct {
+	From       SchemaVersion
+	To         SchemaVersion
+	Records    int  // Records copied into the new schema
+	Reembedded bool // Whether records were re-embedded because the dimension changed
+}
+
+// Migrate moves a store's records to the target schema: it stages a store with the new layout,
+// copies every record into it (re-embedding the episode text with embedder when the dimension
+// changes, e.g. after switching embedding models), and swaps it in, so searches never see a
+// half-migrated store and inserts don't fail on a mismatched schema
+// A zero target Version means CurrentSchemaVersion and a zero Dimension keeps the current one;
+// a store that is already compatible is left untouched
+func Migrate(ctx context.Context, store Migratable, target SchemaVersion, embedder Embedder) (MigrationResult, error) {
+	if target.Version == 0 {
+		target.Version = CurrentSchemaVersion
+	}
+	if target.Version != CurrentSchemaVersion {
+		return MigrationResult{}, fmt.Errorf("%w: %d, stores only create version %d", ErrUnsupportedSchema, target.Version, CurrentSchemaVersion)
+	}
+
+	current, err := store.Schema(ctx)
+	if err != nil {
+		return MigrationResult{}, fmt.Errorf("failed to read store schema: %w", err)
+	}
+	if target.Dimension == 0 {
+		target.Dimension = current.Dimension
+	}
+	result := MigrationResult{From: current, To: target}
+	if current.Compatible(target) {
+		return result, nil
+	}
+
+	result.Reembedded = current.Dimension != target.Dimension
+	if result.Reembedded && embedder == nil {
+		return result, fmt.Errorf("an embedder is required to migrate from %d to %d dimensions", current.Dimension, target.Dimension)
+	}
+
+	staged, err := store.Stage(ctx, target)
+	if err != nil {
+		return result, fmt.Errorf("failed to stage %s store: %w", target, err)
+	}
+
+	err = store.ExportRecords(ctx, func(records []EpisodeRecord) error {
+		if result.Reembedded {
+			if err := reembed(ctx, records, embedder, target.Dimension); err != nil {
+				return err
+			}
+		}
+		if err := staged.Insert(ctx, records); err != nil {
+			return err
+		}
+		result.Records += len(records)
+		return nil
+	})
+	if err == nil {
+		err = staged.Flush(ctx)
+	}
+	if err != nil {
+		if discardErr := store.Discard(ctx, staged); discardErr != nil {
+			return result, fmt.Errorf("migration to %s failed: %w (and discarding the staged store failed: %v)", target, err, discardErr)
+		}
+		return result, fmt.Errorf("migration to %s failed: %w", target, err)
+	}
+
+	if err := store.Swap(ctx, staged); err != nil {
+		return result, fmt.Errorf("failed to swap in the migrated store: %w", err)
+	}
+	return result, nil
+}
+
+// reembed replaces the records' embeddings with fresh ones for their text
+func reembed(ctx context.Context, records []EpisodeRecord, embedder Embedder, dimension int) error {
+	for start := 0; start < len(records); start += migrateEmbedBatch {
+		batch := records[start:min(start+migrateEmbedBatch, len(records))]
+		texts := make([]string, len(batch))
+		for i, record := range batch {
+			texts[i] = record.Text
+		}
+
+		embeddings, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to re-embed records: %w", err)
+		}
+		if len(embeddings) != len(batch) {
+			return fmt.Errorf("failed to re-embed records: got %d embeddings for %d texts", len(embeddings), len(batch))
+		}
+		for _, embedding := range embeddings {
+			if embedding.Index < 0 || embedding.Index >= len(batch) {
+				return fmt.Errorf("failed to re-embed records: embedding index %d out of range", embedding.Index)
+			}
+			if len(embedding.Embedding) != dimension {
+				return fmt.Errorf("%w: embedder returned %d dimensions, expected %d", ErrInvalidDimension, len(embedding.Embedding), dimension)
+			}
+			batch[embedding.Index].Embedding = embedding.Embedding
+		}
+	}
+	return nil
+}
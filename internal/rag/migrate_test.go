@@ -0,0 +1,143 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// dimensionEmbedder embeds every text as a vector of the given dimension
+type dimensionEmbedder struct {
+	dimension int
+	calls     int
+	fail      bool
+}
+
+func (d *dimensionEmbedder) Embed(ctx context.Context, texts []string) ([]EmbeddingRecord, error) {
+	d.calls++
+	if d.fail {
+		return nil, ErrEmbeddingFailed
+	}
+	records := make([]EmbeddingRecord, len(texts))
+	for i, text := range texts {
+		embedding := make([]float32, d.dimension)
+		embedding[0] = float32(len(text))
+		embedding[d.dimension-1] = 1
+		records[i] = EmbeddingRecord{Text: text, Embedding: embedding, Index: i}
+	}
+	return records, nil
+}
+
+func migrationStores(t *testing.T) map[string]Migratable {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	local, err := NewLocalStore(LocalStoreConfig{Path: filepath.Join(dir, "episodes.db")})
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	sqlite := newTestSQLiteStore(t, SQLiteConfig{Path: filepath.Join(dir, "episodes.sqlite")})
+
+	stores := map[string]Migratable{"local": local, "sqlite": sqlite}
+	for name, store := range stores {
+		records := []EpisodeRecord{localRecord("E1", 1, 0, 0), localRecord("E2", 0, 1, 0)}
+		records[1].Repository = "owner/app"
+		if err := store.Insert(ctx, records); err != nil {
+			t.Fatalf("%s: Insert failed: %v", name, err)
+		}
+	}
+	return stores
+}
+
+// TestMigrate_Reembeds tests that changing the dimension re-embeds and swaps in every record
+func TestMigrate_Reembeds(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range migrationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			embedder := &dimensionEmbedder{dimension: 5}
+			result, err := Migrate(ctx, store, SchemaVersion{Dimension: 5}, embedder)
+			if err != nil {
+				t.Fatalf("Migrate failed: %v", err)
+			}
+			if result.Records != 2 || !result.Reembedded || result.From.Dimension != 3 || result.To.Dimension != 5 {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+
+			schema, _ := store.Schema(ctx)
+			if schema != (SchemaVersion{Version: CurrentSchemaVersion, Dimension: 5}) {
+				t.Errorf("Expected the store to be v%d with 5 dimensions, got %s", CurrentSchemaVersion, schema)
+			}
+
+			chunks, err := store.Search(ctx, []float32{0, 0, 0, 0, 1}, 5, &SearchOptions{Repository: "owner/app"})
+			if err != nil {
+				t.Fatalf("Search after migration failed: %v", err)
+			}
+			if len(chunks) != 1 || chunks[0].EpisodeID != "E2" || chunks[0].Text != "episode E2" {
+				t.Errorf("Expected E2 with its text and repository after migration, got %+v", chunks)
+			}
+			if err := store.Insert(ctx, []EpisodeRecord{localRecord("E3", 1, 2, 3, 4, 5)}); err != nil {
+				t.Errorf("Expected inserts of the new dimension to succeed, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestMigrate_Persists tests that a migrated store reopens with the new dimension
+func TestMigrate_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "episodes.db")
+
+	store, _ := NewLocalStore(LocalStoreConfig{Path: path})
+	store.Insert(ctx, []EpisodeRecord{localRecord("E1", 1, 0, 0)})
+	if _, err := Migrate(ctx, store, SchemaVersion{Dimension: 2}, &dimensionEmbedder{dimension: 2}); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	reopened, err := NewLocalStore(LocalStoreConfig{Path: path, Dimension: 2})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if schema, _ := reopened.Schema(ctx); schema.Dimension != 2 {
+		t.Errorf("Expected the migrated file to hold 2-dimensional vectors, got %d", schema.Dimension)
+	}
+}
+
+// TestMigrate_NoOpAndErrors tests compatible stores, unsupported targets, and failed re-embedding
+func TestMigrate_NoOpAndErrors(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range migrationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			embedder := &dimensionEmbedder{dimension: 3}
+			result, err := Migrate(ctx, store, SchemaVersion{Dimension: 3}, embedder)
+			if err != nil || result.Records != 0 || embedder.calls != 0 {
+				t.Errorf("Expected a compatible store to be left alone, got %+v, %v", result, err)
+			}
+
+			if _, err := Migrate(ctx, store, SchemaVersion{Version: CurrentSchemaVersion + 1}, embedder); !errors.Is(err, ErrUnsupportedSchema) {
+				t.Errorf("Expected ErrUnsupportedSchema, got %v", err)
+			}
+			if _, err := Migrate(ctx, store, SchemaVersion{Dimension: 4}, nil); err == nil {
+				t.Error("Expected an error re-embedding without an embedder")
+			}
+
+			if _, err := Migrate(ctx, store, SchemaVersion{Dimension: 4}, &dimensionEmbedder{dimension: 4, fail: true}); !errors.Is(err, ErrEmbeddingFailed) {
+				t.Errorf("Expected the embedding failure, got %v", err)
+			}
+			if schema, _ := store.Schema(ctx); schema.Dimension != 3 {
+				t.Errorf("Expected a failed migration to leave 3 dimensions, got %d", schema.Dimension)
+			}
+			chunks, _ := store.Search(ctx, []float32{1, 0, 0}, 5, nil)
+			if len(chunks) != 2 {
+				t.Errorf("Expected a failed migration to keep both records, got %d", len(chunks))
+			}
+			if sqlite, ok := store.(*SQLiteStore); ok {
+				if _, err := os.Stat(sqlite.config.Path + ".migrating"); !os.IsNotExist(err) {
+					t.Errorf("Expected the staged database to be discarded, got %v", err)
+				}
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -25,6 +28,7 @@ var (
 	ErrInsertFailed     = errors.New("failed to insert records")
 	ErrSearchFailed     = errors.New("failed to search vectors")
 	ErrMissingMetadata  = errors.New("required metadata fields missing")
+	ErrOutdatedSchema   = errors.New("collection schema is outdated")
 )
 
 // MilvusConfig holds configuration for Milvus connection and collection
@@ -70,6 +74,7 @@ func DefaultMilvusConfig() MilvusConfig {
 type MilvusStore struct {
 	client client.Client
 	config MilvusConfig
+	schema SchemaVersion // Layout of the collection as found or created
 }
 
 // NewMilvusStore creates a new Milvus vector store instance
@@ -101,6 +106,7 @@ func NewMilvusStore(ctx context.Context, config MilvusConfig) (*MilvusStore, err
 }
 
 // ensureCollection creates the collection with schema if it doesn't exist
+// An existing collection is opened as is, even with an outdated schema, so it can be migrated
 func (m *MilvusStore) ensureCollection(ctx context.Context) error {
 	has, err := m.client.HasCollection(ctx, m.config.CollectionName)
 	if err != nil {
@@ -108,7 +114,7 @@ func (m *MilvusStore) ensureCollection(ctx context.Context) error {
 	}
 
 	if has {
-		return nil // Collection already exists
+		return m.loadSchema(ctx)
 	}
 
 	// Define schema for episode embeddings
@@ -129,6 +135,27 @@ func (m *MilvusStore) ensureCollection(ctx context.Context) error {
 					"max_length": "64",
 				},
 			},
+			{
+				Name:     "chunk_id",
+				DataType: entity.FieldTypeVarChar,
+				TypeParams: map[string]string{
+					"max_length": "128", // Empty for the episode summary
+				},
+			},
+			{
+				Name:     "granularity",
+				DataType: entity.FieldTypeVarChar,
+				TypeParams: map[string]string{
+					"max_length": "16",
+				},
+			},
+			{
+				Name:     "repository",
+				DataType: entity.FieldTypeVarChar,
+				TypeParams: map[string]string{
+					"max_length": "256", // owner/name, empty for unscoped episodes
+				},
+			},
 			{
 				Name:     "text",
 				DataType: entity.FieldTypeVarChar,
@@ -152,10 +179,30 @@ func (m *MilvusStore) ensureCollection(ctx context.Context) error {
 				DataType: entity.FieldTypeInt64, // Unix timestamp
 			},
 			{
-				Name:     "authors",
-				DataType: entity.FieldTypeVarChar,
+				Name:        "authors",
+				DataType:    entity.FieldTypeArray,
+				ElementType: entity.FieldTypeVarChar,
 				TypeParams: map[string]string{
-					"max_length": "1024", // Comma-separated author names
+					"max_length":   "256",
+					"max_capacity": fmt.Sprintf("%d", maxArrayAuthors), // Author names as given
+				},
+			},
+			{
+				Name:        "author_keys",
+				DataType:    entity.FieldTypeArray,
+				ElementType: entity.FieldTypeVarChar,
+				TypeParams: map[string]string{
+					"max_length":   "256",
+					"max_capacity": fmt.Sprintf("%d", maxArrayAuthors), // Lowercased names for author filters
+				},
+			},
+			{
+				Name:        "paths",
+				DataType:    entity.FieldTypeArray,
+				ElementType: entity.FieldTypeVarChar,
+				TypeParams: map[string]string{
+					"max_length":   "512",
+					"max_capacity": fmt.Sprintf("%d", maxPathEntries), // Touched files and their directories
 				},
 			},
 			{
@@ -189,17 +236,120 @@ func (m *MilvusStore) ensureCollection(ctx context.Context) error {
 		return fmt.Errorf("failed to load collection: %w", err)
 	}
 
+	m.schema = SchemaVersion{Version: CurrentSchemaVersion, Dimension: m.config.Dimension}
+	return nil
+}
+
+// loadSchema reads the layout of an existing collection: collections created before the
+// repository field was added are version 1, before the granularity field version 2, and before
+// the paths field version 3
+func (m *MilvusStore) loadSchema(ctx context.Context) error {
+	collection, err := m.client.DescribeCollection(ctx, m.config.CollectionName)
+	if err != nil {
+		return fmt.Errorf("failed to describe collection: %w", err)
+	}
+
+	m.schema = SchemaVersion{Version: 1}
+	if collection.Schema == nil {
+		return nil
+	}
+	for _, field := range collection.Schema.Fields {
+		switch field.Name {
+		case "repository":
+			m.schema.Version = max(m.schema.Version, 2)
+		case "granularity":
+			m.schema.Version = max(m.schema.Version, 3)
+		case "paths":
+			m.schema.Version = max(m.schema.Version, 4)
+		case "authors":
+			// Before version 5 authors were joined into one comma-separated string
+			if field.DataType == entity.FieldTypeArray {
+				m.schema.Version = max(m.schema.Version, 5)
+			}
+		case "embedding":
+			if dimension, err := strconv.Atoi(field.TypeParams["dim"]); err == nil {
+				m.schema.Dimension = dimension
+			}
+		}
+	}
 	return nil
 }
 
+// checkSchema rejects operations on a collection whose layout doesn't match the configuration,
+// which would otherwise fail inside Milvus with a less helpful error
+func (m *MilvusStore) checkSchema() error {
+	expected := SchemaVersion{Version: CurrentSchemaVersion, Dimension: m.config.Dimension}
+	if m.schema.Compatible(expected) {
+		return nil
+	}
+	return fmt.Errorf("%w: collection %s is %s, expected %s; migrate it first", ErrOutdatedSchema, m.config.CollectionName, m.schema, expected)
+}
+
+// maxArrayAuthors bounds the author names stored per record
+const maxArrayAuthors = 256
+
+// varCharArray converts strings to the byte slices Milvus array columns hold, dropping any
+// longer than the field's maxLength, which Milvus would reject along with the whole insert
+func varCharArray(values []string, maxLength int) [][]byte {
+	array := make([][]byte, 0, len(values))
+	for _, value := range values {
+		if len(value) <= maxLength {
+			array = append(array, []byte(value))
+		}
+	}
+	return array
+}
+
+// columnStrings returns row i of a varchar array column, or nil for other columns
+func columnStrings(column entity.Column, i int) []string {
+	arrays, ok := column.(*entity.ColumnVarCharArray)
+	if !ok {
+		return nil
+	}
+	values, err := arrays.ValueByIdx(i)
+	if err != nil {
+		return nil
+	}
+	strs := make([]string, len(values))
+	for j, value := range values {
+		strs[j] = string(value)
+	}
+	return strs
+}
+
+// columnAuthors returns the authors of row i: an array since schema version 5, and a
+// comma-separated string in older collections, which are read this way while migrating
+func columnAuthors(column entity.Column, i int) []string {
+	if _, ok := column.(*entity.ColumnVarCharArray); ok {
+		return columnStrings(column, i)
+	}
+	joined, _ := column.GetAsString(i)
+	return splitAuthors(joined)
+}
+
+// splitAuthors parses the comma-separated authors of collections older than schema version 5
+func splitAuthors(joined string) []string {
+	var authors []string
+	for _, author := range strings.Split(joined, ",") {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
 // EpisodeRecord represents an episode with its embedding and metadata for batch insertion
 type EpisodeRecord struct {
 	EpisodeID   string
+	ChunkID     string      // Commit or chunk within the episode; empty for the episode summary
+	Granularity Granularity // Empty means GranularityEpisode
+	Repository  string      // owner/name, so one collection can hold many repositories
 	Text        string
 	Embedding   []float32
 	StartDate   time.Time
 	EndDate     time.Time
 	Authors     []string
+	Paths       []string // Files touched, for path-filtered searches
 	CommitCount int
 	FileCount   int
 }
@@ -210,31 +360,39 @@ func (m *MilvusStore) Insert(ctx context.Context, episodes []EpisodeRecord) erro
 	if len(episodes) == 0 {
 		return nil
 	}
+	if err := m.checkSchema(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInsertFailed, err)
+	}
 
 	// Prepare column data for all episodes at once
 	episodeIDs := make([]string, len(episodes))
+	chunkIDs := make([]string, len(episodes))
+	granularities := make([]string, len(episodes))
+	repositories := make([]string, len(episodes))
 	texts := make([]string, len(episodes))
 	embeddings := make([][]float32, len(episodes))
 	startDates := make([]int64, len(episodes))
 	endDates := make([]int64, len(episodes))
-	authorsStr := make([]string, len(episodes))
+	authorLists := make([][][]byte, len(episodes))
+	authorKeyLists := make([][][]byte, len(episodes))
+	pathLists := make([][][]byte, len(episodes))
 	commitCounts := make([]int64, len(episodes))
 	fileCounts := make([]int64, len(episodes))
 
 	for i, ep := range episodes {
 		episodeIDs[i] = ep.EpisodeID
+		chunkIDs[i] = ep.ChunkID
+		granularities[i] = string(granularityOf(ep.Granularity))
+		repositories[i] = ep.Repository
 		texts[i] = ep.Text
 		embeddings[i] = ep.Embedding
 		startDates[i] = ep.StartDate.Unix()
 		endDates[i] = ep.EndDate.Unix()
 
-		// Join authors
-		if len(ep.Authors) > 0 {
-			authorsStr[i] = ep.Authors[0]
-			for j := 1; j < len(ep.Authors); j++ {
-				authorsStr[i] += "," + ep.Authors[j]
-			}
-		}
+		authorLists[i] = varCharArray(ep.Authors[:min(len(ep.Authors), maxArrayAuthors)], 256)
+		keys := authorKeys(ep.Authors)
+		authorKeyLists[i] = varCharArray(keys[:min(len(keys), maxArrayAuthors)], 256)
+		pathLists[i] = varCharArray(pathPrefixes(ep.Paths), 512)
 
 		commitCounts[i] = int64(ep.CommitCount)
 		fileCounts[i] = int64(ep.FileCount)
@@ -243,11 +401,16 @@ func (m *MilvusStore) Insert(ctx context.Context, episodes []EpisodeRecord) erro
 	// Insert all episodes in one operation
 	columns := []entity.Column{
 		entity.NewColumnVarChar("episode_id", episodeIDs),
+		entity.NewColumnVarChar("chunk_id", chunkIDs),
+		entity.NewColumnVarChar("granularity", granularities),
+		entity.NewColumnVarChar("repository", repositories),
 		entity.NewColumnVarChar("text", texts),
 		entity.NewColumnFloatVector("embedding", m.config.Dimension, embeddings),
 		entity.NewColumnInt64("start_date", startDates),
 		entity.NewColumnInt64("end_date", endDates),
-		entity.NewColumnVarChar("authors", authorsStr),
+		entity.NewColumnVarCharArray("authors", authorLists),
+		entity.NewColumnVarCharArray("author_keys", authorKeyLists),
+		entity.NewColumnVarCharArray("paths", pathLists),
 		entity.NewColumnInt64("commit_count", commitCounts),
 		entity.NewColumnInt64("file_count", fileCounts),
 	}
@@ -270,20 +433,47 @@ func (m *MilvusStore) Flush(ctx context.Context) error {
 
 // Search performs top-K similarity search with optional filtering
 func (m *MilvusStore) Search(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error) {
+	if err := m.checkSchema(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+	}
 	if len(queryVector) != m.config.Dimension {
 		return nil, fmt.Errorf("%w: expected %d, got %d", ErrInvalidDimension, m.config.Dimension, len(queryVector))
 	}
 
 	// Build filter expression
-	expr := ""
+	filter := &milvusFilter{}
 	if opts != nil {
 		if len(opts.EpisodeIDs) > 0 {
-			expr = fmt.Sprintf(`episode_id in ["%s"]`, opts.EpisodeIDs[0])
-			for i := 1; i < len(opts.EpisodeIDs); i++ {
-				expr = fmt.Sprintf(`%s or episode_id == "%s"`, expr, opts.EpisodeIDs[i])
+			filter.in("episode_id", opts.EpisodeIDs)
+		}
+		if opts.Repository != "" {
+			filter.equal("repository", opts.Repository)
+		}
+		if len(opts.Granularities) > 0 {
+			values := make([]string, len(opts.Granularities))
+			for i, granularity := range opts.Granularities {
+				values[i] = string(granularityOf(granularity))
 			}
+			filter.in("granularity", values)
+		}
+		if !opts.Since.IsZero() {
+			filter.atLeast("end_date", opts.Since.Unix())
+		}
+		if !opts.Until.IsZero() {
+			filter.atMost("start_date", opts.Until.Unix())
+		}
+		if len(opts.Authors) > 0 {
+			filter.containsAny("author_keys", authorKeys(opts.Authors))
+		}
+		if len(opts.Paths) > 0 {
+			paths := make([]string, len(opts.Paths))
+			for i, p := range opts.Paths {
+				paths[i] = cleanPath(p)
+			}
+			filter.containsAny("paths", paths)
 		}
 	}
+	expr := filter.String()
 
 	// Configure search parameters
 	sp, err := entity.NewIndexHNSWSearchParam(64) // ef parameter for search
@@ -293,7 +483,7 @@ func (m *MilvusStore) Search(ctx context.Context, queryVector []float32, topK in
 
 	// Perform vector search
 	vectors := []entity.Vector{entity.FloatVector(queryVector)}
-	outputFields := []string{"episode_id", "text", "start_date", "end_date", "authors", "commit_count", "file_count"}
+	outputFields := []string{"episode_id", "chunk_id", "granularity", "repository", "text", "start_date", "end_date", "authors", "commit_count", "file_count"}
 
 	results, err := m.client.Search(
 		ctx,
@@ -320,8 +510,7 @@ func (m *MilvusStore) Search(ctx context.Context, queryVector []float32, topK in
 
 	for i := 0; i < results[0].ResultCount; i++ {
 		chunk := ContextChunk{
-			Score:    results[0].Scores[i],
-			Metadata: make(map[string]interface{}),
+			Score: results[0].Scores[i],
 		}
 
 		// Extract fields
@@ -329,6 +518,12 @@ func (m *MilvusStore) Search(ctx context.Context, queryVector []float32, topK in
 			switch field.Name() {
 			case "episode_id":
 				chunk.EpisodeID = field.(*entity.ColumnVarChar).Data()[i]
+			case "chunk_id":
+				chunk.ChunkID = field.(*entity.ColumnVarChar).Data()[i]
+			case "granularity":
+				chunk.Granularity = Granularity(field.(*entity.ColumnVarChar).Data()[i])
+			case "repository":
+				chunk.Repository = field.(*entity.ColumnVarChar).Data()[i]
 			case "text":
 				chunk.Text = field.(*entity.ColumnVarChar).Data()[i]
 			case "start_date":
@@ -336,9 +531,7 @@ func (m *MilvusStore) Search(ctx context.Context, queryVector []float32, topK in
 			case "end_date":
 				chunk.EndDate = time.Unix(field.(*entity.ColumnInt64).Data()[i], 0)
 			case "authors":
-				authorsStr := field.(*entity.ColumnVarChar).Data()[i]
-				// Could parse comma-separated authors here if needed
-				chunk.Authors = []string{authorsStr}
+				chunk.Authors = columnAuthors(field, i)
 			case "commit_count":
 				chunk.CommitCount = int(field.(*entity.ColumnInt64).Data()[i])
 			case "file_count":
@@ -352,31 +545,12 @@ func (m *MilvusStore) Search(ctx context.Context, queryVector []float32, topK in
 	return chunks, nil
 }
 
-// Query checks which episode IDs exist in the store
-func (m *MilvusStore) Query(ctx context.Context, episodeIDs []string) (map[string]bool, error) {
+// Query checks which episode IDs have any record in the store for repository ("" = any)
+func (m *MilvusStore) Query(ctx context.Context, repository string, episodeIDs []string) (map[string]bool, error) {
 	if len(episodeIDs) == 0 {
 		return map[string]bool{}, nil
 	}
 
-	// Build filter expression for the given episode IDs
-	expr := fmt.Sprintf(`episode_id == "%s"`, episodeIDs[0])
-	for i := 1; i < len(episodeIDs); i++ {
-		expr = fmt.Sprintf(`%s or episode_id == "%s"`, expr, episodeIDs[i])
-	}
-
-	// Query the collection to get matching episode IDs
-	// We use a simple query to get just the episode_id field
-	results, err := m.client.Query(
-		ctx,
-		m.config.CollectionName,
-		nil, // partition names
-		expr,
-		[]string{"episode_id"},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query episodes: %w", err)
-	}
-
 	// Build existence map
 	existenceMap := make(map[string]bool, len(episodeIDs))
 	// Initialize all as non-existent
@@ -384,12 +558,27 @@ func (m *MilvusStore) Query(ctx context.Context, episodeIDs []string) (map[strin
 		existenceMap[id] = false
 	}
 
-	// Mark found episodes as existing
-	for _, column := range results {
-		if column.Name() == "episode_id" {
-			if varcharCol, ok := column.(*entity.ColumnVarChar); ok {
-				for _, id := range varcharCol.Data() {
-					existenceMap[id] = true
+	// Query the collection for matching episode IDs, a batch of IDs per request
+	// We use a simple query to get just the episode_id field
+	for _, expr := range scopedBatches(repository, episodeIDs) {
+		results, err := m.client.Query(
+			ctx,
+			m.config.CollectionName,
+			nil, // partition names
+			expr,
+			[]string{"episode_id"},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query episodes: %w", err)
+		}
+
+		// Mark found episodes as existing
+		for _, column := range results {
+			if column.Name() == "episode_id" {
+				if varcharCol, ok := column.(*entity.ColumnVarChar); ok {
+					for _, id := range varcharCol.Data() {
+						existenceMap[id] = true
+					}
 				}
 			}
 		}
@@ -398,19 +587,16 @@ func (m *MilvusStore) Query(ctx context.Context, episodeIDs []string) (map[strin
 	return existenceMap, nil
 }
 
-// Delete removes records by episode IDs
-func (m *MilvusStore) Delete(ctx context.Context, episodeIDs []string) error {
+// Delete removes every record of the given episodes from repository ("" = every repository)
+func (m *MilvusStore) Delete(ctx context.Context, repository string, episodeIDs []string) error {
 	if len(episodeIDs) == 0 {
 		return nil
 	}
 
-	expr := fmt.Sprintf(`episode_id == "%s"`, episodeIDs[0])
-	for i := 1; i < len(episodeIDs); i++ {
-		expr = fmt.Sprintf(`%s or episode_id == "%s"`, expr, episodeIDs[i])
-	}
-
-	if err := m.client.Delete(ctx, m.config.CollectionName, "", expr); err != nil {
-		return fmt.Errorf("failed to delete records: %w", err)
+	for _, expr := range scopedBatches(repository, episodeIDs) {
+		if err := m.client.Delete(ctx, m.config.CollectionName, "", expr); err != nil {
+			return fmt.Errorf("failed to delete records: %w", err)
+		}
 	}
 
 	return nil
@@ -435,3 +621,144 @@ func (m *MilvusStore) Close() error {
 	}
 	return nil
 }
+
+// Schema reports the collection's layout
+func (m *MilvusStore) Schema(ctx context.Context) (SchemaVersion, error) {
+	return m.schema, nil
+}
+
+// ExportRecords pages through every record in the collection, embeddings included
+func (m *MilvusStore) ExportRecords(ctx context.Context, fn func([]EpisodeRecord) error) error {
+	fields := []string{"episode_id", "text", "embedding", "start_date", "end_date", "authors", "commit_count", "file_count"}
+	if m.schema.Version >= 2 {
+		fields = append(fields, "repository")
+	}
+	if m.schema.Version >= 3 {
+		fields = append(fields, "chunk_id", "granularity")
+	}
+	if m.schema.Version >= 4 {
+		fields = append(fields, "paths")
+	}
+
+	iterator, err := m.client.QueryIterator(ctx, client.NewQueryIteratorOption(m.config.CollectionName).
+		WithOutputFields(fields...).
+		WithBatchSize(maxFilterValues))
+	if err != nil {
+		return fmt.Errorf("failed to export records: %w", err)
+	}
+
+	for {
+		results, err := iterator.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export records: %w", err)
+		}
+
+		records := make([]EpisodeRecord, results.Len())
+		for _, column := range results {
+			for i := range records {
+				record := &records[i]
+				switch column.Name() {
+				case "episode_id":
+					record.EpisodeID, _ = column.GetAsString(i)
+				case "chunk_id":
+					record.ChunkID, _ = column.GetAsString(i)
+				case "granularity":
+					granularity, _ := column.GetAsString(i)
+					record.Granularity = Granularity(granularity)
+				case "repository":
+					record.Repository, _ = column.GetAsString(i)
+				case "paths":
+					record.Paths = filePaths(columnStrings(column, i))
+				case "text":
+					record.Text, _ = column.GetAsString(i)
+				case "embedding":
+					if vectors, ok := column.(*entity.ColumnFloatVector); ok {
+						record.Embedding = vectors.Data()[i]
+					}
+				case "start_date":
+					seconds, _ := column.GetAsInt64(i)
+					record.StartDate = time.Unix(seconds, 0)
+				case "end_date":
+					seconds, _ := column.GetAsInt64(i)
+					record.EndDate = time.Unix(seconds, 0)
+				case "authors":
+					record.Authors = columnAuthors(column, i)
+				case "commit_count":
+					count, _ := column.GetAsInt64(i)
+					record.CommitCount = int(count)
+				case "file_count":
+					count, _ := column.GetAsInt64(i)
+					record.FileCount = int(count)
+				}
+			}
+		}
+
+		if err := fn(records); err != nil {
+			return err
+		}
+	}
+}
+
+// Stage creates a new collection with the target layout, named after the store's collection
+// The staged store shares the store's connection, so it must not be closed separately
+func (m *MilvusStore) Stage(ctx context.Context, target SchemaVersion) (VectorStore, error) {
+	config := m.config
+	config.CollectionName = fmt.Sprintf("%s_v%d_%d", m.config.CollectionName, target.Version, time.Now().Unix())
+	config.Dimension = target.Dimension
+
+	staged := &MilvusStore{client: m.client, config: config}
+	if err := staged.ensureCollection(ctx); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// Swap points the store's collection name at a staged collection and drops the old one
+// The configured name becomes an alias switched atomically with AlterAlias; a collection created
+// before migrations existed holds the name itself, so the first swap drops it before creating the alias
+func (m *MilvusStore) Swap(ctx context.Context, staged VectorStore) error {
+	next, ok := staged.(*MilvusStore)
+	if !ok {
+		return fmt.Errorf("cannot swap a %T into a Milvus store", staged)
+	}
+
+	current, err := m.client.DescribeCollection(ctx, m.config.CollectionName)
+	if err != nil {
+		return fmt.Errorf("failed to describe collection: %w", err)
+	}
+
+	if current.Name == m.config.CollectionName {
+		if err := m.client.DropCollection(ctx, current.Name); err != nil {
+			return fmt.Errorf("failed to drop collection %s: %w", current.Name, err)
+		}
+		if err := m.client.CreateAlias(ctx, next.config.CollectionName, m.config.CollectionName); err != nil {
+			return fmt.Errorf("failed to alias %s to %s: %w", m.config.CollectionName, next.config.CollectionName, err)
+		}
+	} else {
+		if err := m.client.AlterAlias(ctx, next.config.CollectionName, m.config.CollectionName); err != nil {
+			return fmt.Errorf("failed to alias %s to %s: %w", m.config.CollectionName, next.config.CollectionName, err)
+		}
+		if err := m.client.DropCollection(ctx, current.Name); err != nil {
+			return fmt.Errorf("failed to drop collection %s: %w", current.Name, err)
+		}
+	}
+
+	m.schema = next.schema
+	m.config.Dimension = next.config.Dimension
+	return nil
+}
+
+// Discard drops a staged collection
+func (m *MilvusStore) Discard(ctx context.Context, staged VectorStore) error {
+	next, ok := staged.(*MilvusStore)
+	if !ok {
+		return fmt.Errorf("cannot discard a %T from a Milvus store", staged)
+	}
+	if err := m.client.DropCollection(ctx, next.config.CollectionName); err != nil {
+		return fmt.Errorf("failed to drop collection %s: %w", next.config.CollectionName, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package rag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxFilterValues bounds the values in one `in [...]` list; longer ID lists are split into
+// several expressions so no single request exceeds Milvus's expression size limits
+const maxFilterValues = 1000
+
+// milvusFilter builds a boolean filter expression from clauses joined with "and"
+// Values are always quoted through quoteFilterString, never interpolated raw
+type milvusFilter struct {
+	clauses []string
+}
+
+// in restricts field to one of values; duplicate values are dropped and an empty list matches nothing
+func (f *milvusFilter) in(field string, values []string) *milvusFilter {
+	f.clauses = append(f.clauses, inClause(field, values))
+	return f
+}
+
+// equal restricts field to value
+func (f *milvusFilter) equal(field, value string) *milvusFilter {
+	f.clauses = append(f.clauses, field+" == "+quoteFilterString(value))
+	return f
+}
+
+// atLeast restricts an integer field to value or more
+func (f *milvusFilter) atLeast(field string, value int64) *milvusFilter {
+	f.clauses = append(f.clauses, field+" >= "+strconv.FormatInt(value, 10))
+	return f
+}
+
+// atMost restricts an integer field to value or less
+func (f *milvusFilter) atMost(field string, value int64) *milvusFilter {
+	f.clauses = append(f.clauses, field+" <= "+strconv.FormatInt(value, 10))
+	return f
+}
+
+// containsAny restricts an array field to rows holding at least one of values
+func (f *milvusFilter) containsAny(field string, values []string) *milvusFilter {
+	values = distinct(values)
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quoteFilterString(value)
+	}
+	f.clauses = append(f.clauses, "array_contains_any("+field+", ["+strings.Join(quoted, ", ")+"])")
+	return f
+}
+
+// String returns the expression, or "" when the filter has no clauses and so matches everything
+func (f *milvusFilter) String() string {
+	if len(f.clauses) == 1 {
+		return f.clauses[0]
+	}
+	parts := make([]string, len(f.clauses))
+	for i, clause := range f.clauses {
+		parts[i] = "(" + clause + ")"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// inBatches returns one `field in [...]` expression per batch of at most maxFilterValues distinct values
+func inBatches(field string, values []string) []string {
+	values = distinct(values)
+	exprs := make([]string, 0, (len(values)+maxFilterValues-1)/maxFilterValues)
+	for start := 0; start < len(values); start += maxFilterValues {
+		end := min(start+maxFilterValues, len(values))
+		exprs = append(exprs, inClause(field, values[start:end]))
+	}
+	return exprs
+}
+
+// scopedBatches is inBatches over episode IDs, each expression restricted to repository unless it is ""
+func scopedBatches(repository string, episodeIDs []string) []string {
+	exprs := inBatches("episode_id", episodeIDs)
+	if repository == "" {
+		return exprs
+	}
+	for i, expr := range exprs {
+		filter := (&milvusFilter{}).equal("repository", repository)
+		filter.clauses = append(filter.clauses, expr)
+		exprs[i] = filter.String()
+	}
+	return exprs
+}
+
+// inClause renders `field in ["a", "b"]` over the distinct values
+func inClause(field string, values []string) string {
+	values = distinct(values)
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quoteFilterString(value)
+	}
+	return field + " in [" + strings.Join(quoted, ", ") + "]"
+}
+
+// quoteFilterString renders value as a double-quoted expression string literal
+// Milvus unquotes string literals with Go's rules, so quotes, backslashes and control characters
+// are escaped exactly as strconv.Quote does and can't end the literal early
+func quoteFilterString(value string) string {
+	return strconv.Quote(value)
+}
+
+// distinct returns values without duplicates, keeping first occurrences in order
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,121 @@
+package rag
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseInClause reads the values back out of an `field in [...]` expression, failing if any
+// literal ends early or stray text appears between literals
+func parseInClause(t *testing.T, field, expr string) []string {
+	t.Helper()
+	rest, ok := strings.CutPrefix(expr, field+" in [")
+	if !ok {
+		t.Fatalf("Expected %q to start with an in clause on %s", expr, field)
+	}
+
+	var values []string
+	for rest != "]" {
+		literal, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			t.Fatalf("Expected a string literal at %q: %v", rest, err)
+		}
+		value, _ := strconv.Unquote(literal)
+		values = append(values, value)
+		rest = strings.TrimPrefix(rest[len(literal):], ", ")
+	}
+	return values
+}
+
+// TestMilvusFilter_AdversarialValues tests that hostile IDs stay inside their string literals
+func TestMilvusFilter_AdversarialValues(t *testing.T) {
+	ids := []string{
+		`E1`,
+		`E2" or episode_id != "`,
+		`"] or true or episode_id in ["`,
+		`back\slash\`,
+		`\"`,
+		"new\nline\ttab\x00nul",
+		`'single' quotes`,
+		"ünïcödé ✓",
+		"",
+	}
+
+	values := parseInClause(t, "episode_id", inClause("episode_id", ids))
+	if len(values) != len(ids) {
+		t.Fatalf("Expected %d values, got %d: %q", len(ids), len(values), values)
+	}
+	for i := range ids {
+		if values[i] != ids[i] {
+			t.Errorf("Value %d: expected %q, got %q", i, ids[i], values[i])
+		}
+	}
+
+	equal := (&milvusFilter{}).equal("repository", `owner/app" or repository != "`).String()
+	literal := strings.TrimPrefix(equal, "repository == ")
+	if unquoted, err := strconv.Unquote(literal); err != nil || unquoted != `owner/app" or repository != "` {
+		t.Errorf("Expected the repository to stay one literal, got %s", equal)
+	}
+}
+
+func TestMilvusFilter_Clauses(t *testing.T) {
+	if expr := (&milvusFilter{}).String(); expr != "" {
+		t.Errorf("Expected empty filter to match everything, got %q", expr)
+	}
+
+	expr := (&milvusFilter{}).in("episode_id", []string{"E1", "E2", "E1"}).String()
+	if expr != `episode_id in ["E1", "E2"]` {
+		t.Errorf("Unexpected single-clause filter: %s", expr)
+	}
+
+	expr = (&milvusFilter{}).in("episode_id", []string{"E1"}).equal("repository", "owner/app").String()
+	if expr != `(episode_id in ["E1"]) and (repository == "owner/app")` {
+		t.Errorf("Unexpected combined filter: %s", expr)
+	}
+
+	expr = (&milvusFilter{}).atLeast("end_date", 100).atMost("start_date", 200).containsAny("paths", []string{"internal/rag", `a"b`}).String()
+	if expr != `(end_date >= 100) and (start_date <= 200) and (array_contains_any(paths, ["internal/rag", "a\"b"]))` {
+		t.Errorf("Unexpected metadata filter: %s", expr)
+	}
+}
+
+func TestInBatches(t *testing.T) {
+	if batches := inBatches("episode_id", nil); len(batches) != 0 {
+		t.Errorf("Expected no batches for no IDs, got %v", batches)
+	}
+
+	ids := make([]string, 2*maxFilterValues+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("E%d", i)
+	}
+	ids = append(ids, "E0", "E1")
+
+	batches := inBatches("episode_id", ids)
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(batches))
+	}
+
+	var all []string
+	for _, batch := range batches {
+		values := parseInClause(t, "episode_id", batch)
+		if len(values) > maxFilterValues {
+			t.Errorf("Expected at most %d values per batch, got %d", maxFilterValues, len(values))
+		}
+		all = append(all, values...)
+	}
+	if len(all) != 2*maxFilterValues+1 || all[0] != "E0" || all[len(all)-1] != ids[2*maxFilterValues] {
+		t.Errorf("Expected every distinct ID once in order, got %d values", len(all))
+	}
+}
+
+func TestSplitAuthors(t *testing.T) {
+	if got := splitAuthors("alice, bob,,carol "); !reflect.DeepEqual(got, []string{"alice", "bob", "carol"}) {
+		t.Errorf("Unexpected authors: %v", got)
+	}
+	if got := splitAuthors(""); got != nil {
+		t.Errorf("Expected no authors, got %v", got)
+	}
+}
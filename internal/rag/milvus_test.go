@@ -68,7 +68,7 @@ func TestMilvusStore_Integration_FullWorkflow(t *testing.T) {
 	defer store.Close()
 
 	// Clean up any existing data
-	_ = store.Delete(ctx, []string{"episode-001", "episode-002"})
+	_ = store.Delete(ctx, "", []string{"episode-001", "episode-002"})
 
 	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", 1536)
 	if err != nil {
@@ -190,8 +190,8 @@ func TestMilvusStore_Integration_FullWorkflow(t *testing.T) {
 		if chunk.FileCount == 0 {
 			t.Error("file count is zero")
 		}
-		if len(chunk.Authors) == 0 {
-			t.Error("authors list is empty")
+		if len(chunk.Authors) != 2 || !chunk.HasAuthor("bob@example.com") {
+			t.Errorf("expected both authors back as a list, got %v", chunk.Authors)
 		}
 		t.Log("✓ Result fields validated")
 	}
@@ -204,7 +204,7 @@ func TestMilvusStore_Integration_FullWorkflow(t *testing.T) {
 	t.Logf("✓ Collection stats: %v", stats)
 
 	// Test 7: Delete one episode
-	err = store.Delete(ctx, []string{"episode-001"})
+	err = store.Delete(ctx, "", []string{"episode-001"})
 	if err != nil {
 		t.Fatalf("failed to delete episode-001: %v", err)
 	}
@@ -227,7 +227,7 @@ func TestMilvusStore_Integration_FullWorkflow(t *testing.T) {
 	t.Log("✓ Verified episode-001 deleted")
 
 	// Test 8: Clean up remaining data
-	err = store.Delete(ctx, []string{"episode-002"})
+	err = store.Delete(ctx, "", []string{"episode-002"})
 	if err != nil {
 		t.Fatalf("failed to delete episode-002: %v", err)
 	}
@@ -252,7 +252,7 @@ func TestMilvusStore_Integration_SearchSimilarity(t *testing.T) {
 	defer store.Close()
 
 	// Clean up
-	_ = store.Delete(ctx, []string{"sim-001"})
+	_ = store.Delete(ctx, "", []string{"sim-001"})
 
 	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", 1536)
 	if err != nil {
@@ -326,7 +326,7 @@ func TestMilvusStore_Integration_SearchSimilarity(t *testing.T) {
 	}
 
 	// Clean up
-	_ = store.Delete(ctx, []string{"sim-001"})
+	_ = store.Delete(ctx, "", []string{"sim-001"})
 }
 
 // Integration test: Multiple episodes and batch operations
@@ -349,7 +349,7 @@ func TestMilvusStore_Integration_BatchOperations(t *testing.T) {
 	episodeIDs := []string{"batch-001", "batch-002"}
 
 	// Clean up any existing data and wait for it to propagate
-	_ = store.Delete(ctx, episodeIDs)
+	_ = store.Delete(ctx, "", episodeIDs)
 
 	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", 1536)
 	if err != nil {
@@ -414,7 +414,7 @@ func TestMilvusStore_Integration_BatchOperations(t *testing.T) {
 	t.Logf("✓ Search returned %d results across all episodes", len(allResults))
 
 	// Batch delete
-	err = store.Delete(ctx, episodeIDs)
+	err = store.Delete(ctx, "", episodeIDs)
 	if err != nil {
 		t.Fatalf("failed to batch delete: %v", err)
 	}
@@ -454,7 +454,7 @@ func TestMilvusStore_Integration_LargeText(t *testing.T) {
 	}
 	defer store.Close()
 
-	_ = store.Delete(ctx, []string{"large-001"})
+	_ = store.Delete(ctx, "", []string{"large-001"})
 
 	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", 1536)
 	if err != nil {
@@ -516,5 +516,5 @@ func TestMilvusStore_Integration_LargeText(t *testing.T) {
 	t.Logf("✓ Retrieved large text (%d characters)", len(results[0].Text))
 
 	// Clean up
-	_ = store.Delete(ctx, []string{"large-001"})
+	_ = store.Delete(ctx, "", []string{"large-001"})
 }
@@ -8,17 +8,30 @@ import (
 // EpisodeSummary aggregates metrics and narrative for a cluster episode.
 type EpisodeSummary struct {
 	EpisodeID   string    `json:"episode_id"`
+	Repository  string    `json:"repository,omitempty"` // owner/name the episode is indexed under
 	Title       string    `json:"title,omitempty"`
 	Summary     string    `json:"summary"`
 	StartDate   time.Time `json:"start_date,omitempty"`
 	EndDate     time.Time `json:"end_date,omitempty"`
 	Authors     []string  `json:"authors,omitempty"`
+	Paths       []string  `json:"paths,omitempty"` // Files the episode touched
 	CommitCount int       `json:"commit_count"`
 	FileCount   int       `json:"file_count"`
+
+	// Chunks are indexed alongside the summary as commit- and chunk-granularity records
+	Chunks []Chunk `json:"chunks,omitempty"`
 }
 
 // VectorStore defines the interface for vector storage and similarity search
 // Implementations should support episode embeddings for RAG pipelines
+// Every store takes and returns the same typed EpisodeRecord and ContextChunk: MilvusStore for
+// a server, LocalStore for an in-process file, and SQLiteStore for hybrid keyword search
+// One store can hold many repositories: records are keyed by repository as well as episode and
+// chunk ID, so a fork and its upstream can hold episodes with the same ID side by side;
+// SearchOptions.Repository scopes a search to one repository, as the repository argument scopes
+// Query and Delete, and "" addresses every repository
+// An episode may be stored as several records: its summary plus commit and chunk records; Query
+// reports an episode present if any of its records is, and Delete removes them all
 type VectorStore interface {
 	// Insert efficiently inserts multiple episodes in a single operation
 	Insert(ctx context.Context, episodes []EpisodeRecord) error
@@ -29,12 +42,12 @@ type VectorStore interface {
 	// Search performs top-K similarity search with optional filtering
 	Search(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error)
 
-	// Query checks which episode IDs exist in the store
+	// Query checks which episode IDs exist in the store for a repository ("" = any repository)
 	// Returns a map where keys are episode IDs and values indicate existence
-	Query(ctx context.Context, episodeIDs []string) (map[string]bool, error)
+	Query(ctx context.Context, repository string, episodeIDs []string) (map[string]bool, error)
 
-	// Delete removes records by episode IDs
-	Delete(ctx context.Context, episodeIDs []string) error
+	// Delete removes records by episode IDs from a repository ("" = every repository)
+	Delete(ctx context.Context, repository string, episodeIDs []string) error
 
 	// GetStats returns collection statistics (record count, index status, etc.)
 	GetStats(ctx context.Context) (map[string]interface{}, error)
@@ -45,12 +58,37 @@ type VectorStore interface {
 
 // IndexOptions provides configuration for episode indexing
 type IndexOptions struct {
-	// BatchSize determines how many episodes to embed at once
+	// BatchSize determines how many records (summaries, commits and chunks) to embed at once
 	BatchSize int
 
 	// ForceReindex will delete and re-insert episodes even if they exist
 	ForceReindex bool
 
 	// SkipExisting will check if episode already exists and skip if present
+	// Episode IDs are derived from their commits, so an existing ID means the same commits are already indexed
+	// Batches hold whole episodes, so rerunning an interrupted index with SkipExisting resumes after
+	// the last batch that was flushed
 	SkipExisting bool
+
+	// EmbedWorkers and InsertWorkers set how many batches are embedded and inserted concurrently
+	// (default: 1 each, which keeps insertion in episode order)
+	EmbedWorkers  int
+	InsertWorkers int
+
+	// ContinueOnError keeps indexing the remaining batches after one fails; the failures are
+	// reported through Progress and returned together once every batch has been tried
+	ContinueOnError bool
+
+	// Progress is called after every batch is flushed or fails, one call at a time
+	Progress func(IndexProgress)
+}
+
+// IndexProgress reports how far an IndexEpisodes run has got
+type IndexProgress struct {
+	Episodes       int   // Episodes to index in this run, after skipping existing ones
+	EpisodesDone   int   // Episodes whose records are all inserted and flushed
+	EpisodesFailed int   // Episodes in batches that failed
+	Records        int   // Records to index: summaries plus commit and chunk records
+	RecordsDone    int   // Records inserted and flushed
+	BytesEmbedded  int64 // Text sent to the embedder so far
 }
@@ -27,6 +27,7 @@ func NewRetriever(embedder Embedder, vectorStore VectorStore) (*Retriever, error
 }
 
 // RetrieveContextForEpisode retrieves topK similar episodes based on a given episode ID.
+// It compares whole-episode summaries unless opts asks for other granularities.
 func (r *Retriever) RetrieveContextForEpisode(
 	ctx context.Context,
 	episodeID string,
@@ -40,12 +41,23 @@ func (r *Retriever) RetrieveContextForEpisode(
 		return nil, fmt.Errorf("topK must be positive, got %d", topK)
 	}
 
+	searchOpts := &SearchOptions{Granularities: []Granularity{GranularityEpisode}}
+	if opts != nil {
+		searchOpts.Repository = opts.Repository
+		searchOpts.MetadataFilter = opts.MetadataFilter
+		if len(opts.Granularities) > 0 {
+			searchOpts.Granularities = opts.Granularities
+		}
+	}
+
 	episodeFilter := &SearchOptions{
-		EpisodeIDs: []string{episodeID},
+		Repository:    searchOpts.Repository,
+		EpisodeIDs:    []string{episodeID},
+		Granularities: []Granularity{GranularityEpisode},
 	}
 
 	// Check if episode exists
-	existenceMap, err := r.vectorStore.Query(ctx, []string{episodeID})
+	existenceMap, err := r.vectorStore.Query(ctx, searchOpts.Repository, []string{episodeID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to check episode existence: %w", err)
 	}
@@ -53,12 +65,6 @@ func (r *Retriever) RetrieveContextForEpisode(
 		return nil, fmt.Errorf("episode %s not found in vector store", episodeID)
 	}
 
-	searchOpts := &SearchOptions{}
-	if opts != nil {
-		searchOpts.Repository = opts.Repository
-		searchOpts.Metadata = opts.Metadata
-	}
-
 	// Retrieve the episode to get its text
 	episodeChunks, err := r.vectorStore.Search(ctx, nil, 1, episodeFilter)
 	if err != nil {
@@ -101,6 +107,7 @@ func (r *Retriever) RetrieveContextForEpisode(
 }
 
 // RetrieveContextForQuery performs semantic search using a free-text query.
+// opts can scope the search by repository, granularity, date range, author and touched paths.
 func (r *Retriever) RetrieveContextForQuery(
 	ctx context.Context,
 	query string,
@@ -114,19 +121,13 @@ func (r *Retriever) RetrieveContextForQuery(
 		return nil, fmt.Errorf("topK must be positive, got %d", topK)
 	}
 
-	// Generate embedding for the query
-	embeddingRecords, err := r.embedder.Embed(ctx, []string{query})
+	queryVector, err := r.embedQuery(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to embed query: %w", err)
-	}
-	if len(embeddingRecords) == 0 {
-		return nil, fmt.Errorf("no embedding generated for query")
+		return nil, err
 	}
 
-	queryVector := embeddingRecords[0].Embedding
-
 	// Perform vector similarity search
-	chunks, err := r.vectorStore.Search(ctx, queryVector, topK, opts)
+	chunks, err := r.vectorStore.Search(ctx, queryVector, topK, querySearchOptions(query, opts))
 	if err != nil {
 		return nil, fmt.Errorf("failed to search for query: %w", err)
 	}
@@ -134,6 +135,71 @@ func (r *Retriever) RetrieveContextForQuery(
 	return chunks, nil
 }
 
+// RetrieveContextForQueryWithinBudget performs semantic search using a free-text query, sizing the
+// results by budget instead of a fixed count: it starts with topK results and keeps widening the
+// search until a chunk scores below budget.MinScore, the chunks fill budget.MaxTokens or
+// budget.MaxChunks, or the store runs out of matches.
+func (r *Retriever) RetrieveContextForQueryWithinBudget(
+	ctx context.Context,
+	query string,
+	topK int,
+	budget ContextBudget,
+	opts *SearchOptions,
+) ([]ContextChunk, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if topK <= 0 {
+		return nil, fmt.Errorf("topK must be positive, got %d", topK)
+	}
+
+	queryVector, err := r.embedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	searchOpts := querySearchOptions(query, opts)
+
+	limit := maxAdaptiveTopK
+	if budget.MaxChunks > 0 {
+		limit = budget.MaxChunks
+	}
+	topK = min(topK, limit)
+	for {
+		chunks, err := r.vectorStore.Search(ctx, queryVector, topK, searchOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for query: %w", err)
+		}
+		selected, full := selectContext(chunks, budget)
+		if full || len(chunks) < topK || topK >= limit {
+			return selected, nil
+		}
+		topK = min(topK*2, limit)
+	}
+}
+
+// embedQuery embeds a free-text query as a search vector
+func (r *Retriever) embedQuery(ctx context.Context, query string) ([]float32, error) {
+	embeddingRecords, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(embeddingRecords) == 0 {
+		return nil, fmt.Errorf("no embedding generated for query")
+	}
+	return embeddingRecords[0].Embedding, nil
+}
+
+// querySearchOptions copies opts with the query text, for stores that combine keyword and vector search
+func querySearchOptions(query string, opts *SearchOptions) *SearchOptions {
+	searchOpts := &SearchOptions{QueryText: query}
+	if opts != nil {
+		copied := *opts
+		copied.QueryText = query
+		searchOpts = &copied
+	}
+	return searchOpts
+}
+
 // RetrieveContextForQueryWithFilters is a convenience function for semantic search with explicit filter parameters.
 func (r *Retriever) RetrieveContextForQueryWithFilters(
 	ctx context.Context,
@@ -38,10 +38,10 @@ func (m *mockEmbedder) Embed(ctx context.Context, texts []string) ([]EmbeddingRe
 type mockVectorStore struct {
 	episodes     map[string]EpisodeRecord
 	searchFunc   func(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error)
-	queryFunc    func(ctx context.Context, episodeIDs []string) (map[string]bool, error)
+	queryFunc    func(ctx context.Context, repository string, episodeIDs []string) (map[string]bool, error)
 	insertFunc   func(ctx context.Context, episodes []EpisodeRecord) error
 	flushFunc    func(ctx context.Context) error
-	deleteFunc   func(ctx context.Context, episodeIDs []string) error
+	deleteFunc   func(ctx context.Context, repository string, episodeIDs []string) error
 	getStatsFunc func(ctx context.Context) (map[string]interface{}, error)
 	closeFunc    func() error
 }
@@ -112,9 +112,9 @@ func (m *mockVectorStore) Search(ctx context.Context, queryVector []float32, top
 	return chunks, nil
 }
 
-func (m *mockVectorStore) Query(ctx context.Context, episodeIDs []string) (map[string]bool, error) {
+func (m *mockVectorStore) Query(ctx context.Context, repository string, episodeIDs []string) (map[string]bool, error) {
 	if m.queryFunc != nil {
-		return m.queryFunc(ctx, episodeIDs)
+		return m.queryFunc(ctx, repository, episodeIDs)
 	}
 	result := make(map[string]bool)
 	for _, id := range episodeIDs {
@@ -124,9 +124,9 @@ func (m *mockVectorStore) Query(ctx context.Context, episodeIDs []string) (map[s
 	return result, nil
 }
 
-func (m *mockVectorStore) Delete(ctx context.Context, episodeIDs []string) error {
+func (m *mockVectorStore) Delete(ctx context.Context, repository string, episodeIDs []string) error {
 	if m.deleteFunc != nil {
-		return m.deleteFunc(ctx, episodeIDs)
+		return m.deleteFunc(ctx, repository, episodeIDs)
 	}
 	for _, id := range episodeIDs {
 		delete(m.episodes, id)
@@ -250,6 +250,26 @@ func TestRetrieveContextForQuery(t *testing.T) {
 		if chunks[0].EpisodeID != "ep1" {
 			t.Errorf("Expected episode ep1, got %s", chunks[0].EpisodeID)
 		}
+		if opts.QueryText != "" {
+			t.Errorf("Expected caller's options to be left unchanged, got query text %q", opts.QueryText)
+		}
+	})
+
+	t.Run("Passes query text", func(t *testing.T) {
+		var received *SearchOptions
+		textStore := &mockVectorStore{
+			searchFunc: func(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error) {
+				received = opts
+				return nil, nil
+			},
+		}
+		textRetriever, _ := NewRetriever(embedder, textStore)
+		if _, err := textRetriever.RetrieveContextForQuery(ctx, "parser crash", 1, nil); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if received == nil || received.QueryText != "parser crash" {
+			t.Errorf("Expected query text to reach the store, got %+v", received)
+		}
 	})
 }
 
@@ -0,0 +1,258 @@
+package rag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// snapshotFormat names the file format in a snapshot's header
+	snapshotFormat = "thunk-snapshot"
+
+	// SnapshotVersion is the snapshot file layout ExportSnapshot writes
+	SnapshotVersion = 1
+
+	// snapshotBatch bounds the records inserted per store call while importing
+	snapshotBatch = 100
+)
+
+// ErrInvalidSnapshot is returned for files that are not complete thunk snapshots
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// Exportable is a vector store whose records can be read back out, embeddings included
+type Exportable interface {
+	// Schema reports the layout of the store's existing records
+	Schema(ctx context.Context) (SchemaVersion, error)
+
+	// ExportRecords calls fn with every stored record, in batches, embeddings included
+	ExportRecords(ctx context.Context, fn func([]EpisodeRecord) error) error
+}
+
+// SnapshotResult describes an exported or imported snapshot
+type SnapshotResult struct {
+	Schema  SchemaVersion // Layout of the records in the snapshot
+	Records int           // Records written or read
+}
+
+// A snapshot is JSON Lines: a header, one line per record, and an end line counting the records,
+// so a truncated file is detected instead of silently importing part of a store
+type snapshotLine struct {
+	Header *snapshotHeader `json:"header,omitempty"`
+	Record *snapshotRecord `json:"record,omitempty"`
+	End    *snapshotEnd    `json:"end,omitempty"`
+}
+
+type snapshotHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Schema    int       `json:"schema"`
+	Dimension int       `json:"dimension"`
+	Created   time.Time `json:"created"`
+}
+
+// snapshotRecord mirrors EpisodeRecord with stable field names, so the file format doesn't
+// change when the struct does
+type snapshotRecord struct {
+	EpisodeID   string      `json:"episode_id"`
+	ChunkID     string      `json:"chunk_id,omitempty"`
+	Granularity Granularity `json:"granularity,omitempty"`
+	Repository  string      `json:"repository,omitempty"`
+	Text        string      `json:"text"`
+	Embedding   []float32   `json:"embedding"`
+	StartDate   time.Time   `json:"start_date"`
+	EndDate     time.Time   `json:"end_date"`
+	Authors     []string    `json:"authors,omitempty"`
+	Paths       []string    `json:"paths,omitempty"`
+	CommitCount int         `json:"commit_count"`
+	FileCount   int         `json:"file_count"`
+}
+
+type snapshotEnd struct {
+	Records int `json:"records"`
+}
+
+// ExportSnapshot writes every record of a store, text, embeddings and metadata included, to w
+// The snapshot doesn't depend on the store backend, so it can back up a store, seed one in CI
+// without calling the embedder, or move an index between Milvus, SQLite and local files
+func ExportSnapshot(ctx context.Context, store Exportable, w io.Writer) (SnapshotResult, error) {
+	schema, err := store.Schema(ctx)
+	if err != nil {
+		return SnapshotResult{}, fmt.Errorf("failed to read store schema: %w", err)
+	}
+	result := SnapshotResult{Schema: schema}
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	header := &snapshotHeader{
+		Format:    snapshotFormat,
+		Version:   SnapshotVersion,
+		Schema:    schema.Version,
+		Dimension: schema.Dimension,
+		Created:   time.Now().UTC(),
+	}
+	if err := encoder.Encode(snapshotLine{Header: header}); err != nil {
+		return result, fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+
+	err = store.ExportRecords(ctx, func(records []EpisodeRecord) error {
+		for _, record := range records {
+			// An empty store reports no dimension, but records exported since may have one
+			if result.Schema.Dimension == 0 {
+				result.Schema.Dimension = len(record.Embedding)
+			}
+			if len(record.Embedding) != result.Schema.Dimension {
+				return fmt.Errorf("%w: expected %d, got %d for episode %s", ErrInvalidDimension, result.Schema.Dimension, len(record.Embedding), record.EpisodeID)
+			}
+			line := snapshotLine{Record: &snapshotRecord{
+				EpisodeID:   record.EpisodeID,
+				ChunkID:     record.ChunkID,
+				Granularity: record.Granularity,
+				Repository:  record.Repository,
+				Text:        record.Text,
+				Embedding:   record.Embedding,
+				StartDate:   record.StartDate,
+				EndDate:     record.EndDate,
+				Authors:     record.Authors,
+				Paths:       record.Paths,
+				CommitCount: record.CommitCount,
+				FileCount:   record.FileCount,
+			}}
+			if err := encoder.Encode(line); err != nil {
+				return err
+			}
+			result.Records++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to export records: %w", err)
+	}
+
+	if err := encoder.Encode(snapshotLine{End: &snapshotEnd{Records: result.Records}}); err != nil {
+		return result, fmt.Errorf("failed to write snapshot end: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return result, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return result, nil
+}
+
+// ImportSnapshot inserts the records of a snapshot written by ExportSnapshot into a store
+// Episodes in the snapshot replace any records the store holds for them; other episodes are kept
+// The store must take the snapshot's embedding dimension: import into an empty store, or use
+// Migrate to re-embed afterwards
+func ImportSnapshot(ctx context.Context, store VectorStore, r io.Reader) (SnapshotResult, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	var first snapshotLine
+	if err := decoder.Decode(&first); err != nil {
+		return SnapshotResult{}, fmt.Errorf("%w: failed to read header: %w", ErrInvalidSnapshot, err)
+	}
+	header := first.Header
+	if header == nil || header.Format != snapshotFormat {
+		return SnapshotResult{}, fmt.Errorf("%w: missing %s header", ErrInvalidSnapshot, snapshotFormat)
+	}
+	if header.Version > SnapshotVersion {
+		return SnapshotResult{}, fmt.Errorf("%w: version %d is newer than %d", ErrInvalidSnapshot, header.Version, SnapshotVersion)
+	}
+	if header.Schema > CurrentSchemaVersion {
+		return SnapshotResult{}, fmt.Errorf("%w: %d, stores only create version %d", ErrUnsupportedSchema, header.Schema, CurrentSchemaVersion)
+	}
+	result := SnapshotResult{Schema: SchemaVersion{Version: header.Schema, Dimension: header.Dimension}}
+
+	// Fail before touching the store rather than on the first insert
+	if exportable, ok := store.(Exportable); ok && header.Dimension > 0 {
+		schema, err := exportable.Schema(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to read store schema: %w", err)
+		}
+		if schema.Dimension != 0 && schema.Dimension != header.Dimension {
+			return result, fmt.Errorf("%w: snapshot has %d dimensions, store has %d", ErrInvalidDimension, header.Dimension, schema.Dimension)
+		}
+	}
+
+	// Episodes are deleted the first time they appear, so stores that append rather than
+	// replace records don't end up with duplicates
+	seen := make(map[string]bool)
+	batch := make([]EpisodeRecord, 0, snapshotBatch)
+	insert := func() error {
+		replaced := make(map[string][]string)
+		for _, record := range batch {
+			if key := recordKey(record.Repository, record.EpisodeID, ""); !seen[key] {
+				seen[key] = true
+				replaced[record.Repository] = append(replaced[record.Repository], record.EpisodeID)
+			}
+		}
+		for repository, episodeIDs := range replaced {
+			if err := store.Delete(ctx, repository, episodeIDs); err != nil {
+				return fmt.Errorf("failed to replace existing episodes: %w", err)
+			}
+		}
+		if err := store.Insert(ctx, batch); err != nil {
+			return fmt.Errorf("failed to insert records: %w", err)
+		}
+		result.Records += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var line snapshotLine
+		if err := decoder.Decode(&line); err != nil {
+			if err == io.EOF {
+				return result, fmt.Errorf("%w: truncated after %d records", ErrInvalidSnapshot, result.Records+len(batch))
+			}
+			return result, fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		}
+
+		if line.End != nil {
+			if len(batch) > 0 {
+				if err := insert(); err != nil {
+					return result, err
+				}
+			}
+			if line.End.Records != result.Records {
+				return result, fmt.Errorf("%w: expected %d records, read %d", ErrInvalidSnapshot, line.End.Records, result.Records)
+			}
+			if err := store.Flush(ctx); err != nil {
+				return result, fmt.Errorf("failed to flush imported records: %w", err)
+			}
+			return result, nil
+		}
+
+		record := line.Record
+		if record == nil {
+			return result, fmt.Errorf("%w: line %d is neither a record nor the end", ErrInvalidSnapshot, result.Records+len(batch)+2)
+		}
+		if header.Dimension > 0 && len(record.Embedding) != header.Dimension {
+			return result, fmt.Errorf("%w: %w: expected %d, got %d for episode %s", ErrInvalidSnapshot, ErrInvalidDimension, header.Dimension, len(record.Embedding), record.EpisodeID)
+		}
+		batch = append(batch, EpisodeRecord{
+			EpisodeID:   record.EpisodeID,
+			ChunkID:     record.ChunkID,
+			Granularity: record.Granularity,
+			Repository:  record.Repository,
+			Text:        record.Text,
+			Embedding:   record.Embedding,
+			StartDate:   record.StartDate,
+			EndDate:     record.EndDate,
+			Authors:     record.Authors,
+			Paths:       record.Paths,
+			CommitCount: record.CommitCount,
+			FileCount:   record.FileCount,
+		})
+		if len(batch) == snapshotBatch {
+			if err := insert(); err != nil {
+				return result, err
+			}
+		}
+	}
+}
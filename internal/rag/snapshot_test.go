@@ -0,0 +1,125 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSnapshot_RoundTrip tests that a local store's records survive a move to SQLite unchanged
+func TestSnapshot_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	local, _ := NewLocalStore(LocalStoreConfig{})
+	commit := localRecord("E1", 0, 1, 0)
+	commit.ChunkID = "commit:abc123"
+	commit.Granularity = GranularityCommit
+	commit.Repository = "owner/app"
+	commit.Paths = []string{"internal", "internal/rag", "internal/rag/snapshot.go"}
+	records := []EpisodeRecord{localRecord("E1", 1, 0, 0), commit, localRecord("E2", 0.25, 0.5, 1)}
+	if err := local.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	exported, err := ExportSnapshot(ctx, local, &buf)
+	if err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+	if exported.Records != 3 || exported.Schema.Dimension != 3 {
+		t.Errorf("Unexpected export result: %+v", exported)
+	}
+
+	sqlite := newTestSQLiteStore(t, SQLiteConfig{})
+	imported, err := ImportSnapshot(ctx, sqlite, &buf)
+	if err != nil {
+		t.Fatalf("ImportSnapshot failed: %v", err)
+	}
+	if imported != exported {
+		t.Errorf("Expected the import to match the export, got %+v and %+v", imported, exported)
+	}
+
+	got := make(map[string]EpisodeRecord)
+	sqlite.ExportRecords(ctx, func(batch []EpisodeRecord) error {
+		for _, record := range batch {
+			got[recordKey(record.Repository, record.EpisodeID, record.ChunkID)] = record
+		}
+		return nil
+	})
+	for _, want := range records {
+		record := got[recordKey(want.Repository, want.EpisodeID, want.ChunkID)]
+		want.Granularity = granularityOf(want.Granularity)
+		if !reflect.DeepEqual(record, want) {
+			t.Errorf("Expected %+v, got %+v", want, record)
+		}
+	}
+}
+
+// TestImportSnapshot_ReplacesEpisodes tests that imported episodes replace stored ones and others are kept
+func TestImportSnapshot_ReplacesEpisodes(t *testing.T) {
+	ctx := context.Background()
+	source, _ := NewLocalStore(LocalStoreConfig{})
+	source.Insert(ctx, []EpisodeRecord{localRecord("E1", 1, 0)})
+	var buf bytes.Buffer
+	if _, err := ExportSnapshot(ctx, source, &buf); err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+
+	target, _ := NewLocalStore(LocalStoreConfig{})
+	stale := localRecord("E1", 0, 1)
+	stale.ChunkID = "chunk:1"
+	target.Insert(ctx, []EpisodeRecord{stale, localRecord("E2", 0, 1)})
+	if _, err := ImportSnapshot(ctx, target, &buf); err != nil {
+		t.Fatalf("ImportSnapshot failed: %v", err)
+	}
+
+	var keys []string
+	target.ExportRecords(ctx, func(batch []EpisodeRecord) error {
+		for _, record := range batch {
+			keys = append(keys, recordKey(record.Repository, record.EpisodeID, record.ChunkID))
+		}
+		return nil
+	})
+	if len(keys) != 2 || strings.Contains(strings.Join(keys, ","), "chunk:1") {
+		t.Errorf("Expected E1 replaced and E2 kept, got %v", keys)
+	}
+}
+
+// TestImportSnapshot_Invalid tests that truncated, foreign and mismatched snapshots leave the store alone
+func TestImportSnapshot_Invalid(t *testing.T) {
+	ctx := context.Background()
+	source, _ := NewLocalStore(LocalStoreConfig{})
+	source.Insert(ctx, []EpisodeRecord{localRecord("E1", 1, 0), localRecord("E2", 0, 1)})
+	var buf bytes.Buffer
+	ExportSnapshot(ctx, source, &buf)
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	tests := []struct {
+		name     string
+		snapshot string
+		want     error
+	}{
+		{"truncated", strings.Join(lines[:2], ""), ErrInvalidSnapshot},
+		{"not a snapshot", `{"episode_id":"E1"}`, ErrInvalidSnapshot},
+		{"wrong end count", strings.Join(lines[:2], "") + `{"end":{"records":2}}` + "\n", ErrInvalidSnapshot},
+		{"newer schema", strings.Replace(buf.String(), fmt.Sprintf(`"schema":%d`, CurrentSchemaVersion), `"schema":99`, 1), ErrUnsupportedSchema},
+	}
+	for _, tt := range tests {
+		target, _ := NewLocalStore(LocalStoreConfig{})
+		if _, err := ImportSnapshot(ctx, target, strings.NewReader(tt.snapshot)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	other, _ := NewLocalStore(LocalStoreConfig{})
+	other.Insert(ctx, []EpisodeRecord{localRecord("E3", 1, 0, 0)})
+	if _, err := ImportSnapshot(ctx, other, strings.NewReader(buf.String())); !errors.Is(err, ErrInvalidDimension) {
+		t.Errorf("Expected a dimension mismatch, got %v", err)
+	}
+	if existing, _ := other.Query(ctx, "", []string{"E1"}); existing["E1"] {
+		t.Error("Expected nothing imported into a store of another dimension")
+	}
+}
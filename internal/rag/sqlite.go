@@ -0,0 +1,639 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver with FTS5
+)
+
+// SQLiteConfig holds configuration for the SQLite vector store
+type SQLiteConfig struct {
+	Path      string // Database file, created if missing
+	Dimension int    // Vector dimension; 0 takes the dimension of the first inserted record, and a populated database keeps its own
+
+	// KeywordWeight blends FTS5 keyword relevance into the cosine score when a search carries
+	// SearchOptions.QueryText: 0 searches by vector only, 1 by keywords only
+	KeywordWeight float64
+}
+
+// DefaultSQLiteConfig returns a hybrid-search configuration for the database at path
+func DefaultSQLiteConfig(path string) SQLiteConfig {
+	return SQLiteConfig{
+		Path:          path,
+		KeywordWeight: 0.3,
+	}
+}
+
+// SQLiteStore implements VectorStore on a single SQLite database: embeddings are stored as blobs
+// and scanned for cosine similarity, and an FTS5 index over episode text adds keyword relevance
+// for hybrid search; it needs no services or cgo, which suits laptops and air-gapped environments
+// Inserting a record replaces any record with the same repository, episode and chunk ID
+type SQLiteStore struct {
+	db     *sql.DB
+	config SQLiteConfig
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS episodes (
+	episode_id   TEXT NOT NULL,
+	chunk_id     TEXT NOT NULL DEFAULT '',
+	granularity  TEXT NOT NULL DEFAULT 'episode',
+	repository   TEXT NOT NULL DEFAULT '',
+	text         TEXT NOT NULL,
+	embedding    BLOB NOT NULL,
+	start_date   TEXT NOT NULL,
+	end_date     TEXT NOT NULL,
+	authors      TEXT NOT NULL,
+	paths        TEXT NOT NULL DEFAULT '[]',
+	commit_count INTEGER NOT NULL,
+	file_count   INTEGER NOT NULL,
+	PRIMARY KEY (repository, episode_id, chunk_id)
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS episodes_fts USING fts5(repository UNINDEXED, episode_id UNINDEXED, chunk_id UNINDEXED, text);
+`
+
+// sqliteReindex replaces the keyword index of a rebuilt table with one in the current layout
+const sqliteReindex = `
+DROP TABLE episodes_legacy;
+DROP TABLE episodes_fts;
+CREATE VIRTUAL TABLE episodes_fts USING fts5(repository UNINDEXED, episode_id UNINDEXED, chunk_id UNINDEXED, text);
+INSERT INTO episodes_fts (repository, episode_id, chunk_id, text) SELECT repository, episode_id, chunk_id, text FROM episodes;
+`
+
+// sqliteRebuild moves records from a table keyed by episode ID alone into the current layout,
+// as whole-episode records, and reindexes their text
+const sqliteRebuild = `
+ALTER TABLE episodes RENAME TO episodes_legacy;
+` + sqliteSchema + `
+INSERT INTO episodes (episode_id, repository, text, embedding, start_date, end_date, authors, commit_count, file_count)
+	SELECT episode_id, repository, text, embedding, start_date, end_date, authors, commit_count, file_count
+	FROM episodes_legacy ORDER BY rowid;
+` + sqliteReindex
+
+// sqliteRekey moves records from a table keyed by episode and chunk ID into the current layout,
+// keyed by repository as well, and reindexes their text
+const sqliteRekey = `
+ALTER TABLE episodes RENAME TO episodes_legacy;
+` + sqliteSchema + `
+INSERT INTO episodes (episode_id, chunk_id, granularity, repository, text, embedding, start_date, end_date, authors, paths, commit_count, file_count)
+	SELECT episode_id, chunk_id, granularity, repository, text, embedding, start_date, end_date, authors, paths, commit_count, file_count
+	FROM episodes_legacy ORDER BY rowid;
+` + sqliteReindex
+
+// NewSQLiteStore opens or creates the database at config.Path; a database whose dimension differs
+// from config.Dimension opens as is, so it can be migrated, and rejects inserts until it is
+func NewSQLiteStore(ctx context.Context, config SQLiteConfig) (*SQLiteStore, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("sqlite store path is required")
+	}
+	if config.Dimension < 0 {
+		return nil, ErrInvalidDimension
+	}
+	if config.KeywordWeight < 0 || config.KeywordWeight > 1 {
+		return nil, fmt.Errorf("keyword weight must be between 0 and 1, got %g", config.KeywordWeight)
+	}
+
+	if dir := filepath.Dir(config.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create sqlite store directory: %w", err)
+		}
+	}
+
+	store := &SQLiteStore{config: config}
+	if err := store.open(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// open connects to the database file and prepares its schema
+func (s *SQLiteStore) open(ctx context.Context) error {
+	db, err := sql.Open("sqlite", s.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open sqlite store: %w", err)
+	}
+	// A single connection serializes writers and keeps the database consistent across calls
+	db.SetMaxOpenConns(1)
+
+	s.db = db
+	if err := s.init(ctx); err != nil {
+		db.Close()
+		return err
+	}
+	return nil
+}
+
+// init creates the schema and reconciles the configured dimension with stored vectors
+func (s *SQLiteStore) init(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "PRAGMA busy_timeout = 5000"); err != nil {
+		return fmt.Errorf("failed to configure sqlite store: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create sqlite schema: %w", err)
+	}
+	if err := s.migrate(ctx); err != nil {
+		return err
+	}
+
+	var size int
+	err := s.db.QueryRowContext(ctx, "SELECT length(embedding) FROM episodes LIMIT 1").Scan(&size)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read sqlite store: %w", err)
+	}
+
+	s.config.Dimension = size / 4
+	return nil
+}
+
+// migrate brings databases created by earlier versions up to the current layout: a missing
+// repository column leaves their episodes unscoped, matching searches without a repository filter,
+// a table keyed by episode ID alone is rebuilt so episodes can also hold commit and chunk records,
+// records from before paths were stored match no path filter until reindexed, and a table keyed
+// without the repository is rebuilt so a fork and its upstream can store the same episode IDs
+func (s *SQLiteStore) migrate(ctx context.Context) error {
+	columns, err := s.columns(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !columns["repository"] {
+		if _, err := s.db.ExecContext(ctx, "ALTER TABLE episodes ADD COLUMN repository TEXT NOT NULL DEFAULT ''"); err != nil {
+			return fmt.Errorf("failed to migrate sqlite schema: %w", err)
+		}
+	}
+	rebuild := sqliteRebuild
+	if columns["chunk_id"] {
+		if !columns["paths"] {
+			if _, err := s.db.ExecContext(ctx, "ALTER TABLE episodes ADD COLUMN paths TEXT NOT NULL DEFAULT '[]'"); err != nil {
+				return fmt.Errorf("failed to migrate sqlite schema: %w", err)
+			}
+		}
+		var keyed int
+		if err := s.db.QueryRowContext(ctx, "SELECT pk FROM pragma_table_info('episodes') WHERE name = 'repository'").Scan(&keyed); err != nil {
+			return fmt.Errorf("failed to read sqlite schema: %w", err)
+		}
+		if keyed > 0 {
+			return nil
+		}
+		rebuild = sqliteRekey
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to migrate sqlite schema: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, rebuild); err != nil {
+		return fmt.Errorf("failed to migrate sqlite schema: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to migrate sqlite schema: %w", err)
+	}
+	return nil
+}
+
+// columns returns the names of the episodes table's columns
+func (s *SQLiteStore) columns(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM pragma_table_info('episodes')")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sqlite schema: %w", err)
+	}
+	// Closing releases the store's single connection before the table is altered
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to read sqlite schema: %w", err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sqlite schema: %w", err)
+	}
+	return columns, nil
+}
+
+// Insert adds episodes, replacing stored records with the same repository, episode and chunk ID
+func (s *SQLiteStore) Insert(ctx context.Context, episodes []EpisodeRecord) error {
+	if len(episodes) == 0 {
+		return nil
+	}
+
+	dimension := s.config.Dimension
+	if dimension == 0 {
+		dimension = len(episodes[0].Embedding)
+	}
+	for _, episode := range episodes {
+		if len(episode.Embedding) != dimension || dimension == 0 {
+			return fmt.Errorf("%w: %w: expected %d, got %d for episode %s", ErrInsertFailed, ErrInvalidDimension, dimension, len(episode.Embedding), episode.EpisodeID)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInsertFailed, err)
+	}
+	defer tx.Rollback()
+
+	for _, episode := range episodes {
+		authors, err := json.Marshal(episode.Authors)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInsertFailed, err)
+		}
+		paths, err := json.Marshal(episode.Paths)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInsertFailed, err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO episodes (episode_id, chunk_id, granularity, repository, text, embedding, start_date, end_date, authors, paths, commit_count, file_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(repository, episode_id, chunk_id) DO UPDATE SET
+				granularity = excluded.granularity, text = excluded.text,
+				embedding = excluded.embedding, start_date = excluded.start_date, end_date = excluded.end_date,
+				authors = excluded.authors, paths = excluded.paths, commit_count = excluded.commit_count,
+				file_count = excluded.file_count`,
+			episode.EpisodeID, episode.ChunkID, string(granularityOf(episode.Granularity)), episode.Repository,
+			episode.Text, encodeVector(episode.Embedding),
+			episode.StartDate.Format(time.RFC3339Nano), episode.EndDate.Format(time.RFC3339Nano),
+			string(authors), string(paths), episode.CommitCount, episode.FileCount)
+		if err != nil {
+			return fmt.Errorf("%w: episode %s: %w", ErrInsertFailed, episode.EpisodeID, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM episodes_fts WHERE repository = ? AND episode_id = ? AND chunk_id = ?", episode.Repository, episode.EpisodeID, episode.ChunkID); err != nil {
+			return fmt.Errorf("%w: episode %s: %w", ErrInsertFailed, episode.EpisodeID, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO episodes_fts (repository, episode_id, chunk_id, text) VALUES (?, ?, ?, ?)", episode.Repository, episode.EpisodeID, episode.ChunkID, episode.Text); err != nil {
+			return fmt.Errorf("%w: episode %s: %w", ErrInsertFailed, episode.EpisodeID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInsertFailed, err)
+	}
+	s.config.Dimension = dimension
+	return nil
+}
+
+// Flush is a no-op: every Insert and Delete commits its own transaction
+func (s *SQLiteStore) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Search returns the topK records most similar to queryVector, best first
+// When opts carries QueryText and KeywordWeight is set, each score blends cosine similarity with
+// FTS5 keyword relevance scaled to [0, 1]; with a nil queryVector the search is keyword-only
+// With EpisodeIDs, Repository, Granularities or a metadata filter set only matching records are considered; a nil queryVector and no
+// QueryText then returns them unscored, which is how the retriever looks an episode up by ID
+func (s *SQLiteStore) Search(ctx context.Context, queryVector []float32, topK int, opts *SearchOptions) ([]ContextChunk, error) {
+	var episodeIDs []string
+	var repository, queryText string
+	var granularities []Granularity
+	var metadata MetadataFilter
+	if opts != nil {
+		metadata = opts.MetadataFilter
+		episodeIDs = opts.EpisodeIDs
+		repository = opts.Repository
+		granularities = opts.Granularities
+		queryText = opts.QueryText
+	}
+	if queryVector != nil && len(queryVector) != s.config.Dimension {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrInvalidDimension, s.config.Dimension, len(queryVector))
+	}
+	if topK <= 0 {
+		return []ContextChunk{}, nil
+	}
+
+	keywordWeight := s.config.KeywordWeight
+	if queryVector == nil {
+		keywordWeight = 1
+	}
+	var keywords map[string]float32
+	if queryText != "" && keywordWeight > 0 {
+		var err error
+		if keywords, err = s.keywordScores(ctx, queryText); err != nil {
+			return nil, err
+		}
+	}
+
+	if queryVector == nil && keywords == nil && len(episodeIDs) == 0 {
+		return nil, fmt.Errorf("%w: a query vector, query text, or episode IDs are required", ErrSearchFailed)
+	}
+
+	records, err := s.records(ctx, episodeIDs, repository, granularities)
+	if err != nil {
+		return nil, err
+	}
+
+	var query []float32
+	if queryVector != nil {
+		query = normalizeVector(queryVector)
+	}
+	matches := make([]scoredRecord, 0, len(records))
+	for i, record := range records {
+		if !metadata.matches(record) {
+			continue
+		}
+		keyword, matched := keywords[recordKey(record.Repository, record.EpisodeID, record.ChunkID)]
+		if queryVector == nil && keywords != nil && !matched {
+			continue
+		}
+		var score float32
+		if query != nil {
+			score = dot(query, normalizeVector(record.Embedding))
+		}
+		if keywords != nil {
+			score = float32(1-keywordWeight)*score + float32(keywordWeight)*keyword
+		}
+		matches = append(matches, scoredRecord{index: i, score: score})
+	}
+	sortScored(matches)
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+
+	chunks := make([]ContextChunk, len(matches))
+	for i, match := range matches {
+		chunks[i] = recordChunk(records[match.index], match.score)
+	}
+	return chunks, nil
+}
+
+// keywordScores ranks records against the words of text with FTS5 BM25, scaled so the best match
+// scores 1, keyed by recordKey; it returns nil when text has no searchable words
+func (s *SQLiteStore) keywordScores(ctx context.Context, text string) (map[string]float32, error) {
+	match := ftsQuery(text)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT repository, episode_id, chunk_id, bm25(episodes_fts) FROM episodes_fts WHERE episodes_fts MATCH ?", match)
+	if err != nil {
+		return nil, fmt.Errorf("%w: keyword search: %w", ErrSearchFailed, err)
+	}
+	defer rows.Close()
+
+	scores := make(map[string]float32)
+	var best float64
+	for rows.Next() {
+		var repository, episodeID, chunkID string
+		var rank float64
+		if err := rows.Scan(&repository, &episodeID, &chunkID, &rank); err != nil {
+			return nil, fmt.Errorf("%w: keyword search: %w", ErrSearchFailed, err)
+		}
+		// BM25 ranks are negative, more negative being more relevant
+		relevance := -rank
+		scores[recordKey(repository, episodeID, chunkID)] = float32(relevance)
+		best = math.Max(best, relevance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: keyword search: %w", ErrSearchFailed, err)
+	}
+
+	for id, relevance := range scores {
+		if best > 0 {
+			scores[id] = relevance / float32(best)
+		} else {
+			scores[id] = 1
+		}
+	}
+	return scores, nil
+}
+
+// ftsQuery turns free text into an FTS5 query matching any of its words, quoting each so
+// punctuation in the question can't be read as query syntax
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"`
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// records loads stored records in insertion order, limited to episodeIDs, repository and granularities when given
+func (s *SQLiteStore) records(ctx context.Context, episodeIDs []string, repository string, granularities []Granularity) ([]EpisodeRecord, error) {
+	query := "SELECT episode_id, chunk_id, granularity, repository, text, embedding, start_date, end_date, authors, paths, commit_count, file_count FROM episodes"
+	var conditions []string
+	var args []any
+	if len(episodeIDs) > 0 {
+		conditions = append(conditions, "episode_id IN ("+strings.TrimSuffix(strings.Repeat("?,", len(episodeIDs)), ",")+")")
+		for _, id := range episodeIDs {
+			args = append(args, id)
+		}
+	}
+	if repository != "" {
+		conditions = append(conditions, "repository = ?")
+		args = append(args, repository)
+	}
+	if len(granularities) > 0 {
+		conditions = append(conditions, "granularity IN ("+strings.TrimSuffix(strings.Repeat("?,", len(granularities)), ",")+")")
+		for _, granularity := range granularities {
+			args = append(args, string(granularityOf(granularity)))
+		}
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY rowid"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+	}
+	defer rows.Close()
+
+	var records []EpisodeRecord
+	for rows.Next() {
+		var record EpisodeRecord
+		var embedding []byte
+		var startDate, endDate, authors, paths, granularity string
+		if err := rows.Scan(&record.EpisodeID, &record.ChunkID, &granularity, &record.Repository, &record.Text, &embedding, &startDate, &endDate, &authors, &paths, &record.CommitCount, &record.FileCount); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+		}
+		record.Granularity = Granularity(granularity)
+		record.Embedding = decodeVector(embedding)
+		if record.StartDate, err = time.Parse(time.RFC3339Nano, startDate); err != nil {
+			return nil, fmt.Errorf("%w: episode %s start date: %w", ErrSearchFailed, record.EpisodeID, err)
+		}
+		if record.EndDate, err = time.Parse(time.RFC3339Nano, endDate); err != nil {
+			return nil, fmt.Errorf("%w: episode %s end date: %w", ErrSearchFailed, record.EpisodeID, err)
+		}
+		if err := json.Unmarshal([]byte(authors), &record.Authors); err != nil {
+			return nil, fmt.Errorf("%w: episode %s authors: %w", ErrSearchFailed, record.EpisodeID, err)
+		}
+		if err := json.Unmarshal([]byte(paths), &record.Paths); err != nil {
+			return nil, fmt.Errorf("%w: episode %s paths: %w", ErrSearchFailed, record.EpisodeID, err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+	}
+	return records, nil
+}
+
+// Query checks which episode IDs have any record in the store for repository ("" = any)
+func (s *SQLiteStore) Query(ctx context.Context, repository string, episodeIDs []string) (map[string]bool, error) {
+	existence := make(map[string]bool, len(episodeIDs))
+	for _, id := range episodeIDs {
+		var found int
+		err := s.db.QueryRowContext(ctx, "SELECT 1 FROM episodes WHERE episode_id = ? AND (? = '' OR repository = ?) LIMIT 1", id, repository, repository).Scan(&found)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to query episodes: %w", err)
+		}
+		existence[id] = err == nil
+	}
+	return existence, nil
+}
+
+// Delete removes every record of the given episodes from repository ("" = every repository)
+func (s *SQLiteStore) Delete(ctx context.Context, repository string, episodeIDs []string) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete episodes: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range episodeIDs {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM episodes WHERE episode_id = ? AND (? = '' OR repository = ?)", id, repository, repository); err != nil {
+			return fmt.Errorf("failed to delete episode %s: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM episodes_fts WHERE episode_id = ? AND (? = '' OR repository = ?)", id, repository, repository); err != nil {
+			return fmt.Errorf("failed to delete episode %s: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete episodes: %w", err)
+	}
+	return nil
+}
+
+// GetStats returns the record count, vector dimension, keyword weight and file
+func (s *SQLiteStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT count(*) FROM episodes").Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	return map[string]interface{}{
+		"row_count":      count,
+		"dimension":      s.config.Dimension,
+		"index":          "flat+fts5",
+		"keyword_weight": s.config.KeywordWeight,
+		"path":           s.config.Path,
+	}, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Schema reports the database's layout; opening a database migrates its columns to the current fields
+func (s *SQLiteStore) Schema(ctx context.Context) (SchemaVersion, error) {
+	return SchemaVersion{Version: CurrentSchemaVersion, Dimension: s.config.Dimension}, nil
+}
+
+// ExportRecords calls fn with the stored records, in batches
+func (s *SQLiteStore) ExportRecords(ctx context.Context, fn func([]EpisodeRecord) error) error {
+	records, err := s.records(ctx, nil, "", nil)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(records); start += maxFilterValues {
+		if err := fn(records[start:min(start+maxFilterValues, len(records))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stage creates an empty database with the target dimension next to the store's own
+func (s *SQLiteStore) Stage(ctx context.Context, target SchemaVersion) (VectorStore, error) {
+	config := s.config
+	config.Path = s.config.Path + ".migrating"
+	config.Dimension = target.Dimension
+	if err := os.Remove(config.Path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to clear staged sqlite store: %w", err)
+	}
+	return NewSQLiteStore(ctx, config)
+}
+
+// Swap replaces the database file with a staged one by renaming it over the original
+func (s *SQLiteStore) Swap(ctx context.Context, staged VectorStore) error {
+	next, ok := staged.(*SQLiteStore)
+	if !ok {
+		return fmt.Errorf("cannot swap a %T into a sqlite store", staged)
+	}
+	if err := next.Close(); err != nil {
+		return fmt.Errorf("failed to close staged sqlite store: %w", err)
+	}
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close sqlite store: %w", err)
+	}
+
+	// Reopen whichever file is in place; an empty staged database has no rows to read its dimension from
+	renameErr := os.Rename(next.config.Path, s.config.Path)
+	if renameErr == nil {
+		s.config.Dimension = next.config.Dimension
+	}
+	if err := s.open(ctx); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("failed to swap in staged sqlite store: %w", renameErr)
+	}
+	return nil
+}
+
+// Discard closes and deletes a staged database
+func (s *SQLiteStore) Discard(ctx context.Context, staged VectorStore) error {
+	next, ok := staged.(*SQLiteStore)
+	if !ok {
+		return fmt.Errorf("cannot discard a %T from a sqlite store", staged)
+	}
+	next.Close()
+	if err := os.Remove(next.config.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete staged sqlite store: %w", err)
+	}
+	return nil
+}
+
+// encodeVector stores a vector as little-endian float32s
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+// decodeVector reads a vector written by encodeVector
+func decodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,339 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSQLiteStore(t *testing.T, config SQLiteConfig) *SQLiteStore {
+	t.Helper()
+	if config.Path == "" {
+		config.Path = filepath.Join(t.TempDir(), "episodes.db")
+	}
+	store, err := NewSQLiteStore(context.Background(), config)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// TestSQLiteStore_VectorSearch tests cosine ranking, filters, upserts, and deletes
+func TestSQLiteStore_VectorSearch(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLiteStore(t, SQLiteConfig{})
+
+	err := store.Insert(ctx, []EpisodeRecord{
+		localRecord("E1", 1, 0, 0),
+		localRecord("E2", 0, 1, 0),
+		localRecord("E3", 10, 10, 0),
+	})
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	chunks, err := store.Search(ctx, []float32{1, 0.1, 0}, 2, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 2 || chunks[0].EpisodeID != "E1" || chunks[1].EpisodeID != "E3" {
+		t.Fatalf("Expected E1 then E3, got %+v", chunks)
+	}
+	if chunks[0].Text != "episode E1" || chunks[0].CommitCount != 3 || len(chunks[0].Authors) != 1 ||
+		!chunks[0].StartDate.Equal(localRecord("E1").StartDate) {
+		t.Errorf("Expected record metadata on result, got %+v", chunks[0])
+	}
+
+	chunks, err = store.Search(ctx, nil, 1, &SearchOptions{EpisodeIDs: []string{"E2"}})
+	if err != nil {
+		t.Fatalf("Lookup by ID failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E2" {
+		t.Errorf("Expected E2 by ID, got %+v", chunks)
+	}
+
+	replacement := localRecord("E2", 0, 0, 1)
+	replacement.Text = "rewritten"
+	if err := store.Insert(ctx, []EpisodeRecord{replacement}); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	stats, _ := store.GetStats(ctx)
+	if stats["row_count"] != 3 {
+		t.Errorf("Expected replacement to keep 3 rows, got %v", stats["row_count"])
+	}
+
+	if err := store.Delete(ctx, "", []string{"E1"}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	existence, _ := store.Query(ctx, "", []string{"E1", "E2"})
+	if existence["E1"] || !existence["E2"] {
+		t.Errorf("Expected only E2 to exist, got %v", existence)
+	}
+
+	if err := store.Insert(ctx, []EpisodeRecord{localRecord("E4", 1, 0)}); err == nil {
+		t.Error("Expected error for record with wrong dimension")
+	}
+}
+
+// TestSQLiteStore_HybridSearch tests that query text lifts keyword matches over closer vectors
+func TestSQLiteStore_HybridSearch(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLiteStore(t, SQLiteConfig{KeywordWeight: 0.5})
+
+	parser := localRecord("E1", 0.8, 0.6)
+	parser.Text = "Fixed a crash in the YAML parser"
+	login := localRecord("E2", 1, 0)
+	login.Text = "Added the login page"
+	store.Insert(ctx, []EpisodeRecord{parser, login})
+
+	chunks, err := store.Search(ctx, []float32{1, 0}, 2, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if chunks[0].EpisodeID != "E2" {
+		t.Errorf("Expected vector search to rank E2 first, got %s", chunks[0].EpisodeID)
+	}
+
+	chunks, err = store.Search(ctx, []float32{1, 0}, 2, &SearchOptions{QueryText: "parser crash?"})
+	if err != nil {
+		t.Fatalf("Hybrid search failed: %v", err)
+	}
+	if chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected hybrid search to rank E1 first, got %s", chunks[0].EpisodeID)
+	}
+
+	chunks, err = store.Search(ctx, nil, 5, &SearchOptions{QueryText: "login"})
+	if err != nil {
+		t.Fatalf("Keyword search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E2" || chunks[0].Score != 1 {
+		t.Errorf("Expected keyword search to find only E2, got %+v", chunks)
+	}
+
+	store.Delete(ctx, "", []string{"E2"})
+	chunks, _ = store.Search(ctx, nil, 5, &SearchOptions{QueryText: "login"})
+	if len(chunks) != 0 {
+		t.Errorf("Expected deleted episode to leave the keyword index, got %+v", chunks)
+	}
+}
+
+// TestSQLiteStore_Reopen tests that records and their dimension survive reopening the database
+func TestSQLiteStore_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "episodes.db")
+
+	store := newTestSQLiteStore(t, SQLiteConfig{Path: path})
+	store.Insert(ctx, []EpisodeRecord{localRecord("E1", 1, 2, 3)})
+	store.Close()
+
+	reopened := newTestSQLiteStore(t, SQLiteConfig{Path: path})
+	stats, _ := reopened.GetStats(ctx)
+	if stats["row_count"] != 1 || stats["dimension"] != 3 {
+		t.Errorf("Expected 1 row of dimension 3, got %v", stats)
+	}
+	reopened.Close()
+
+	mismatched := newTestSQLiteStore(t, SQLiteConfig{Path: path, Dimension: 4})
+	if schema, _ := mismatched.Schema(ctx); schema.Dimension != 3 {
+		t.Errorf("Expected the stored dimension 3, got %d", schema.Dimension)
+	}
+	if err := mismatched.Insert(ctx, []EpisodeRecord{localRecord("E2", 1, 2, 3, 4)}); err == nil {
+		t.Error("Expected inserts of the configured dimension to fail until migrated")
+	}
+}
+
+// TestSQLiteStore_Repository tests repository-scoped searches and migrating a legacy database
+func TestSQLiteStore_Repository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "episodes.db")
+
+	// A database from before the repository column existed
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE episodes (
+			episode_id TEXT PRIMARY KEY, text TEXT NOT NULL, embedding BLOB NOT NULL,
+			start_date TEXT NOT NULL, end_date TEXT NOT NULL, authors TEXT NOT NULL,
+			commit_count INTEGER NOT NULL, file_count INTEGER NOT NULL
+		);
+		INSERT INTO episodes VALUES ('E0', 'old episode', X'0000803F00000000', '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z', '[]', 1, 1);`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Creating legacy schema failed: %v", err)
+	}
+
+	store := newTestSQLiteStore(t, SQLiteConfig{Path: path, KeywordWeight: 0.3})
+	app := localRecord("app:E1", 1, 0)
+	app.Repository = "owner/app"
+	lib := localRecord("lib:E1", 1, 0.1)
+	lib.Repository = "owner/lib"
+	if err := store.Insert(ctx, []EpisodeRecord{app, lib}); err != nil {
+		t.Fatalf("Insert into migrated store failed: %v", err)
+	}
+
+	chunks, err := store.Search(ctx, []float32{0, 1}, 5, &SearchOptions{Repository: "owner/app", QueryText: "episode"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "app:E1" || chunks[0].Repository != "owner/app" {
+		t.Errorf("Expected only owner/app's episode, got %+v", chunks)
+	}
+
+	chunks, _ = store.Search(ctx, []float32{0, 1}, 5, nil)
+	if len(chunks) != 3 {
+		t.Errorf("Expected unscoped search to return all 3 episodes, got %d", len(chunks))
+	}
+
+	// Legacy records become whole-episode records, still found by keyword
+	chunks, _ = store.Search(ctx, nil, 5, &SearchOptions{QueryText: "old", Granularities: []Granularity{GranularityEpisode}})
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E0" || chunks[0].Granularity != GranularityEpisode {
+		t.Errorf("Expected the migrated episode, got %+v", chunks)
+	}
+}
+
+// TestSQLiteStore_SharedEpisodeIDs tests migrating a database keyed without the repository and
+// keeping a fork's episodes apart from its upstream's with the same ID
+func TestSQLiteStore_SharedEpisodeIDs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "episodes.db")
+
+	// A database from before records were keyed by repository
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE episodes (
+			episode_id TEXT NOT NULL, chunk_id TEXT NOT NULL DEFAULT '', granularity TEXT NOT NULL DEFAULT 'episode',
+			repository TEXT NOT NULL DEFAULT '', text TEXT NOT NULL, embedding BLOB NOT NULL,
+			start_date TEXT NOT NULL, end_date TEXT NOT NULL, authors TEXT NOT NULL, paths TEXT NOT NULL DEFAULT '[]',
+			commit_count INTEGER NOT NULL, file_count INTEGER NOT NULL,
+			PRIMARY KEY (episode_id, chunk_id)
+		);
+		CREATE VIRTUAL TABLE episodes_fts USING fts5(episode_id UNINDEXED, chunk_id UNINDEXED, text);
+		INSERT INTO episodes VALUES ('E1', '', 'episode', 'owner/app', 'upstream episode', X'0000803F00000000', '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z', '[]', '[]', 1, 1);
+		INSERT INTO episodes_fts VALUES ('E1', '', 'upstream episode');`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Creating legacy schema failed: %v", err)
+	}
+
+	store := newTestSQLiteStore(t, SQLiteConfig{Path: path, KeywordWeight: 0.3})
+	fork := localRecord("E1", 0, 1)
+	fork.Repository = "fork/app"
+	fork.Text = "fork episode"
+	if err := store.Insert(ctx, []EpisodeRecord{fork}); err != nil {
+		t.Fatalf("Insert into migrated store failed: %v", err)
+	}
+	if stats, _ := store.GetStats(ctx); stats["row_count"] != 2 {
+		t.Fatalf("Expected both repositories' E1 to be stored, got %v rows", stats["row_count"])
+	}
+
+	chunks, err := store.Search(ctx, nil, 5, &SearchOptions{Repository: "owner/app", QueryText: "upstream"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Text != "upstream episode" {
+		t.Errorf("Expected the migrated upstream episode, got %+v", chunks)
+	}
+
+	if err := store.Delete(ctx, "fork/app", []string{"E1"}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if existence, _ := store.Query(ctx, "fork/app", []string{"E1"}); existence["E1"] {
+		t.Error("Expected E1 to be deleted from the fork")
+	}
+	if existence, _ := store.Query(ctx, "owner/app", []string{"E1"}); !existence["E1"] {
+		t.Error("Expected deleting the fork's E1 to keep the upstream's")
+	}
+	if chunks, _ := store.Search(ctx, nil, 5, &SearchOptions{QueryText: "fork"}); len(chunks) != 0 {
+		t.Errorf("Expected the fork's keywords to be removed, got %+v", chunks)
+	}
+}
+
+// TestSQLiteStore_Granularity tests commit records matching keywords apart from their episode
+func TestSQLiteStore_Granularity(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLiteStore(t, SQLiteConfig{KeywordWeight: 0.5})
+
+	episode := localRecord("E1", 1, 0)
+	episode.Text = "Reworked the networking layer"
+	commit := localRecord("E1", 1, 0)
+	commit.ChunkID = "commit:abc"
+	commit.Granularity = GranularityCommit
+	commit.Text = "Commit abc by bob: add retry backoff"
+	if err := store.Insert(ctx, []EpisodeRecord{episode, commit, localRecord("E2", 0, 1)}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	chunks, err := store.Search(ctx, []float32{1, 0}, 5, &SearchOptions{QueryText: "retry backoff"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 3 || chunks[0].ChunkID != "commit:abc" || chunks[0].Granularity != GranularityCommit {
+		t.Errorf("Expected the commit record to rank first, got %+v", chunks)
+	}
+
+	chunks, _ = store.Search(ctx, []float32{1, 0}, 5, &SearchOptions{Granularities: []Granularity{GranularityEpisode}})
+	if len(chunks) != 2 || chunks[0].ChunkID != "" || chunks[0].Granularity != GranularityEpisode {
+		t.Errorf("Expected only episode records, got %+v", chunks)
+	}
+
+	if err := store.Delete(ctx, "", []string{"E1"}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if stats, _ := store.GetStats(ctx); stats["row_count"] != 1 {
+		t.Errorf("Expected deleting E1 to remove all its records, got %v", stats["row_count"])
+	}
+	if chunks, _ := store.Search(ctx, nil, 5, &SearchOptions{QueryText: "retry"}); len(chunks) != 0 {
+		t.Errorf("Expected the commit's keywords to be removed, got %+v", chunks)
+	}
+}
+
+// TestSQLiteStore_MetadataFilter tests keyword and vector searches scoped by author and touched paths
+func TestSQLiteStore_MetadataFilter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "episodes.db")
+	store := newTestSQLiteStore(t, SQLiteConfig{Path: path, KeywordWeight: 0.3})
+
+	retry := localRecord("E1", 1, 0)
+	retry.Text = "Tuned retry backoff"
+	retry.Authors = []string{"Bob"}
+	retry.Paths = []string{"internal/retry/backoff.go"}
+	docs := localRecord("E2", 1, 0)
+	docs.Text = "Documented retry settings"
+	docs.Paths = []string{"docs/retry.md"}
+	if err := store.Insert(ctx, []EpisodeRecord{retry, docs}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	opts := &SearchOptions{QueryText: "retry", MetadataFilter: MetadataFilter{Paths: []string{"internal"}}}
+	chunks, err := store.Search(ctx, []float32{1, 0}, 5, opts)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected only the episode touching internal/, got %+v", chunks)
+	}
+
+	// Paths survive a reopen
+	store.Close()
+	store = newTestSQLiteStore(t, SQLiteConfig{Path: path})
+	chunks, _ = store.Search(ctx, nil, 5, &SearchOptions{EpisodeIDs: []string{"E1", "E2"}, MetadataFilter: MetadataFilter{Authors: []string{"BOB"}}})
+	if len(chunks) != 1 || chunks[0].EpisodeID != "E1" {
+		t.Errorf("Expected only Bob's episode, got %+v", chunks)
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	if got := ftsQuery(`parser "crash" OR-fix?`); got != `"parser" OR "crash" OR "OR" OR "fix"` {
+		t.Errorf("Unexpected FTS query: %s", got)
+	}
+	if got := ftsQuery("?!"); got != "" {
+		t.Errorf("Expected empty query for punctuation, got %q", got)
+	}
+}
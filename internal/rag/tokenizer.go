@@ -0,0 +1,190 @@
+package rag
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidTokenizer is returned for tokenizer rank files that can't be parsed
+var ErrInvalidTokenizer = errors.New("invalid tokenizer file")
+
+// Tokenizer counts the LLM tokens in text
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// pretokenizePattern splits text the way tiktoken's cl100k_base and o200k_base encodings do before
+// merging bytes: contractions, words with one leading non-letter, numbers of up to three digits,
+// punctuation runs, newline runs and other whitespace
+// RE2 has no lookahead for tiktoken's `\s+(?!\S)`; splitText emulates it
+var pretokenizePattern = regexp.MustCompile(`^(?:(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+)`)
+
+// splitText splits text into the pieces tiktoken encodes separately
+func splitText(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		end := len(text)
+		if loc := pretokenizePattern.FindStringIndex(text); loc != nil && loc[1] > 0 {
+			end = loc[1]
+		}
+		// A run of spaces before a word leaves its last space to the word, as `\s+(?!\S)` does
+		if end < len(text) && end > 1 && strings.TrimSpace(text[:end]) == "" && !strings.ContainsAny(text[:end], "\r\n") {
+			_, size := utf8.DecodeLastRuneInString(text[:end])
+			end -= size
+		}
+		pieces = append(pieces, text[:end])
+		text = text[end:]
+	}
+	return pieces
+}
+
+// ApproxTokenizer estimates token counts without a vocabulary: text is split the way tiktoken
+// splits it, and each piece is priced by its kind and length
+// For exact counts, load the model's encoding with LoadTiktoken instead
+type ApproxTokenizer struct{}
+
+// CountTokens returns the estimated tokens in text
+func (ApproxTokenizer) CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range splitText(text) {
+		tokens += approxPieceTokens(piece)
+	}
+	return tokens
+}
+
+// approxPieceTokens estimates the tokens of one piece: short words and digit groups are one token,
+// longer words about one per five letters, punctuation one per three characters and whitespace one
+func approxPieceTokens(piece string) int {
+	trimmed := strings.TrimLeftFunc(piece, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+	r, _ := utf8.DecodeRuneInString(trimmed)
+	switch {
+	case strings.TrimSpace(piece) == "":
+		return 1
+	case unicode.IsNumber(r):
+		return 1
+	case unicode.IsLetter(r):
+		if !isASCII(trimmed) {
+			// Non-Latin scripts take about one token per character
+			return utf8.RuneCountInString(trimmed)
+		}
+		return 1 + (len(trimmed)-1)/5
+	default:
+		return 1 + (utf8.RuneCountInString(strings.TrimSpace(piece))-1)/3
+	}
+}
+
+// isASCII reports whether s has only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultTokenizer is the tokenizer used when none is configured
+var DefaultTokenizer Tokenizer = ApproxTokenizer{}
+
+// BPETokenizer counts tokens exactly as tiktoken does, from an encoding's byte pair ranks
+// Special tokens such as <|endoftext|> are counted as ordinary text
+type BPETokenizer struct {
+	ranks map[string]int
+}
+
+// LoadTiktoken reads a tiktoken rank file (e.g. cl100k_base.tiktoken or o200k_base.tiktoken)
+func LoadTiktoken(path string) (*BPETokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tokenizer file: %w", err)
+	}
+	defer f.Close()
+	return ReadTiktoken(f)
+}
+
+// ReadTiktoken parses tiktoken ranks: one base64-encoded token and its rank per line
+func ReadTiktoken(r io.Reader) (*BPETokenizer, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		encoded, rankText, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d has no rank", ErrInvalidTokenizer, line)
+		}
+		token, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidTokenizer, line, err)
+		}
+		rank, err := strconv.Atoi(rankText)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidTokenizer, line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tokenizer file: %w", err)
+	}
+	if len(ranks) < 256 {
+		return nil, fmt.Errorf("%w: %d tokens, fewer than the 256 single bytes", ErrInvalidTokenizer, len(ranks))
+	}
+	return &BPETokenizer{ranks: ranks}, nil
+}
+
+// CountTokens returns the number of tokens text encodes to
+func (t *BPETokenizer) CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range splitText(text) {
+		if _, ok := t.ranks[piece]; ok {
+			tokens++
+			continue
+		}
+		tokens += t.mergeCount(piece)
+	}
+	return tokens
+}
+
+// mergeCount applies byte pair merges to a piece, lowest rank first, and returns the tokens left
+func (t *BPETokenizer) mergeCount(piece string) int {
+	// bounds[i] is where the i-th part of the piece starts; the last entry is the end
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := t.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+	}
+	return len(bounds) - 1
+}
+
+// countTokens counts text with tokenizer, or estimates it when tokenizer is nil
+func countTokens(tokenizer Tokenizer, text string) int {
+	if tokenizer == nil {
+		return EstimateTokens(text)
+	}
+	return tokenizer.CountTokens(text)
+}
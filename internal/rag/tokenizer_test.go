@@ -0,0 +1,96 @@
+package rag
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitText(t *testing.T) {
+	got := splitText("Hello world  foo\n\nbar's 12345")
+	want := []string{"Hello", " world", " ", " foo", "\n\n", "bar", "'s", " ", "123", "45"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if strings.Join(splitText("func main() {\n\treturn\n}"), "") != "func main() {\n\treturn\n}" {
+		t.Error("Expected the pieces to join back into the text")
+	}
+}
+
+// tiktokenRanks returns a rank file with every byte plus the given merged tokens, ranked in order
+func tiktokenRanks(merges ...string) string {
+	var b strings.Builder
+	for i := 0; i < 256; i++ {
+		b.WriteString(fmt.Sprintf("%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i))
+	}
+	for i, token := range merges {
+		b.WriteString(fmt.Sprintf("%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 256+i))
+	}
+	return b.String()
+}
+
+func TestBPETokenizer(t *testing.T) {
+	tokenizer, err := ReadTiktoken(strings.NewReader(tiktokenRanks("ab", "cd", "abcd", " x")))
+	if err != nil {
+		t.Fatalf("ReadTiktoken failed: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"abcd", 1},   // A whole token
+		{"abcde", 2},  // ab + cd merge first, then abcd, leaving e
+		{"xyz", 3},    // No merges
+		{"abcd x", 2}, // Pieces are encoded separately
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := tokenizer.CountTokens(tt.text); got != tt.want {
+			t.Errorf("%q: expected %d tokens, got %d", tt.text, tt.want, got)
+		}
+	}
+
+	for _, file := range []string{"YWI=\n", "YWI= 1\n", "!!! 1\n" + tiktokenRanks()} {
+		if _, err := ReadTiktoken(strings.NewReader(file)); !errors.Is(err, ErrInvalidTokenizer) {
+			t.Errorf("Expected ErrInvalidTokenizer for %.20q, got %v", file, err)
+		}
+	}
+}
+
+func TestApproxTokenizer(t *testing.T) {
+	tokenizer := ApproxTokenizer{}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"hello world", 2},
+		{"internationalization", 4},
+		{"x := 1234", 5}, // x, " :=", " ", 123 and 4
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := tokenizer.CountTokens(tt.text); got != tt.want {
+			t.Errorf("%q: expected %d tokens, got %d", tt.text, tt.want, got)
+		}
+	}
+}
+
+// wordTokenizer counts one token per whitespace-separated word
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+func TestSelectContext_Tokenizer(t *testing.T) {
+	chunks := []ContextChunk{
+		{EpisodeID: "E1", Text: "one two three", Score: 0.9},
+		{EpisodeID: "E2", Text: "four five", Score: 0.8},
+		{EpisodeID: "E3", Text: "six", Score: 0.7},
+	}
+	if got := SelectContext(chunks, ContextBudget{MaxTokens: 5, Tokenizer: wordTokenizer{}}); len(got) != 2 {
+		t.Errorf("Expected the chunks of 5 words, got %d chunks", len(got))
+	}
+}
@@ -0,0 +1,371 @@
+// Package redact removes secrets and credentials from repository text before it is sent to
+// embedding or LLM providers.
+// Rules match well-known token formats, private keys and credentials assigned in code or config,
+// and long random-looking strings are caught by their entropy; a policy adds patterns to redact,
+// exempts patterns that must be kept, and switches rules off by name.
+package redact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Yates-Labs/thunk/internal/cluster"
+)
+
+// Marker replaces every redacted secret
+const Marker = "[REDACTED]"
+
+// Sources of text, as a Report counts redactions by them
+const (
+	SourceCommitMessage = "commit_message"
+	SourceDiff          = "diff"
+	SourceArtifact      = "artifact" // Titles and descriptions of issues, pull requests and tickets
+	SourceDiscussion    = "discussion"
+)
+
+// Names of the rules that aren't patterns
+const (
+	RuleHighEntropy = "high_entropy" // Long strings of random-looking letters and digits
+	RuleDeny        = "deny"         // Patterns added by Policy.Deny
+)
+
+// Defaults for detecting high-entropy strings
+const (
+	DefaultMinEntropy       = 4.0 // Shannon entropy in bits per character
+	DefaultMinEntropyLength = 24
+)
+
+// ErrInvalidPolicy is returned for policies with malformed patterns or unknown rule names
+var ErrInvalidPolicy = errors.New("invalid redaction policy")
+
+// Rule finds one kind of secret; when the pattern has a group, the group is kept and only the rest
+// of the match is redacted, so "password = " stays readable around the marker
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// defaultRules are the built-in rules, applied in order
+var defaultRules = []Rule{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"gitlab_token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"openai_key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}\b`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{"bearer_token", regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]{16,}=*`)},
+	{"url_credentials", regexp.MustCompile(`([a-z][a-z0-9+.-]*://[^/\s:@]+:)[^@\s/]+@`)},
+	// Quoted values assigned in code or config
+	{"assigned_secret", regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)[a-z0-9_-]*["']?\s*(?::=|=>|[:=])\s*["'])[^\s"']{6,}`)},
+	// Unquoted values in env and ini files, such as API_KEY=...
+	{"env_secret", regexp.MustCompile(`(?im)^([+ -]?\s*(?:export\s+)?[a-z0-9_.]*(?:password|passwd|secret|token|api_?key|access_?key)[a-z0-9_.]*[ \t]*[=:][ \t]*)[^\s"'#()]{6,}[ \t]*$`)},
+}
+
+// entropyCandidate matches strings long enough to be checked for entropy, split at characters
+// like / and . so paths, URLs and dotted names are looked at piece by piece
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+_=-]+`)
+
+// hexString matches hexadecimal strings, such as commit hashes and checksums, which look random
+// but aren't secrets
+var hexString = regexp.MustCompile(`^[0-9a-fA-F-]+$`)
+
+// DefaultRules returns the names of the built-in rules, in the order they are applied
+func DefaultRules() []string {
+	names := make([]string, 0, len(defaultRules)+1)
+	for _, rule := range defaultRules {
+		names = append(names, rule.Name)
+	}
+	return append(names, RuleHighEntropy)
+}
+
+// Policy configures a Redactor; the zero policy applies every built-in rule
+type Policy struct {
+	// Disabled sends text unredacted
+	Disabled bool
+
+	// Deny adds patterns to redact, such as internal hostnames or customer names
+	Deny []string
+
+	// Allow exempts matches of these patterns, such as documented example keys, from redaction
+	Allow []string
+
+	// DisabledRules switches built-in rules off by name (see DefaultRules)
+	DisabledRules []string
+
+	// MinEntropy and MinEntropyLength tune the high_entropy rule: strings of at least
+	// MinEntropyLength characters mixing letters and digits are redacted when their entropy reaches
+	// MinEntropy bits per character (defaults DefaultMinEntropy and DefaultMinEntropyLength)
+	MinEntropy       float64
+	MinEntropyLength int
+}
+
+// Report counts what a Redactor redacted since it was created
+type Report struct {
+	Redactions int            `json:"redactions"`
+	Allowed    int            `json:"allowed"` // Matches kept because an Allow pattern exempted them
+	ByRule     map[string]int `json:"by_rule,omitempty"`
+	BySource   map[string]int `json:"by_source,omitempty"`
+}
+
+// Redactor redacts secrets from text and counts what it redacted; it is safe for concurrent use.
+// A nil Redactor redacts nothing.
+type Redactor struct {
+	rules            []Rule
+	allow            []*regexp.Regexp
+	entropy          bool
+	minEntropy       float64
+	minEntropyLength int
+
+	mu     sync.Mutex
+	report Report
+}
+
+// New creates a redactor applying policy; a disabled policy returns a nil Redactor
+func New(policy Policy) (*Redactor, error) {
+	if policy.Disabled {
+		return nil, nil
+	}
+
+	known := DefaultRules()
+	for _, name := range policy.DisabledRules {
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("%w: unknown rule %q (known: %s)", ErrInvalidPolicy, name, strings.Join(known, ", "))
+		}
+	}
+
+	r := &Redactor{
+		entropy:          !slices.Contains(policy.DisabledRules, RuleHighEntropy),
+		minEntropy:       policy.MinEntropy,
+		minEntropyLength: policy.MinEntropyLength,
+		report:           Report{ByRule: make(map[string]int), BySource: make(map[string]int)},
+	}
+	if r.minEntropy <= 0 {
+		r.minEntropy = DefaultMinEntropy
+	}
+	if r.minEntropyLength <= 0 {
+		r.minEntropyLength = DefaultMinEntropyLength
+	}
+	for _, rule := range defaultRules {
+		if !slices.Contains(policy.DisabledRules, rule.Name) {
+			r.rules = append(r.rules, rule)
+		}
+	}
+	for _, pattern := range policy.Deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: deny pattern %q: %w", ErrInvalidPolicy, pattern, err)
+		}
+		r.rules = append(r.rules, Rule{Name: RuleDeny, Pattern: re})
+	}
+	for _, pattern := range policy.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: allow pattern %q: %w", ErrInvalidPolicy, pattern, err)
+		}
+		r.allow = append(r.allow, re)
+	}
+	return r, nil
+}
+
+// Text redacts text from source, counting what it redacted in the report
+func (r *Redactor) Text(source, text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	redacted, counts := r.redact(text)
+	if len(counts) == 0 {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for rule, n := range counts {
+		if rule == "" {
+			r.report.Allowed += n
+			continue
+		}
+		r.report.Redactions += n
+		r.report.ByRule[rule] += n
+		r.report.BySource[source] += n
+	}
+	return redacted
+}
+
+// redact applies the rules to text, returning it redacted and the number of redactions per rule,
+// with exempted matches counted under ""
+func (r *Redactor) redact(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	for _, rule := range r.rules {
+		text = r.apply(rule, text, counts)
+	}
+	if r.entropy {
+		text = entropyCandidate.ReplaceAllStringFunc(text, func(candidate string) string {
+			if !r.highEntropy(candidate) {
+				return candidate
+			}
+			if r.allowed(candidate) {
+				counts[""]++
+				return candidate
+			}
+			counts[RuleHighEntropy]++
+			return Marker
+		})
+	}
+	return text, counts
+}
+
+// apply replaces the rule's matches in text, keeping the pattern's first group and exempted matches
+func (r *Redactor) apply(rule Rule, text string, counts map[string]int) string {
+	matches := rule.Pattern.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start := m[0]
+		if len(m) >= 4 && m[3] >= 0 {
+			start = m[3]
+		}
+		secret := text[start:m[1]]
+		if secret == "" || secret == Marker {
+			continue
+		}
+		if r.allowed(secret) {
+			counts[""]++
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(Marker)
+		last = m[1]
+		counts[rule.Name]++
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// allowed reports whether an Allow pattern exempts a match
+func (r *Redactor) allowed(secret string) bool {
+	for _, re := range r.allow {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// highEntropy reports whether a string is long and random enough to be a secret: it mixes
+// letters and digits, isn't hexadecimal, and reaches the minimum entropy
+func (r *Redactor) highEntropy(s string) bool {
+	if len(s) < r.minEntropyLength || hexString.MatchString(s) {
+		return false
+	}
+	if !strings.ContainsAny(s, "0123456789") || strings.IndexFunc(s, isLetter) < 0 {
+		return false
+	}
+	return entropy(s) >= r.minEntropy
+}
+
+// isLetter reports whether r is an ASCII letter
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+	bits := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(len(s))
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// Episodes returns copies of the episodes with secrets redacted from their commit messages, diffs,
+// artifact titles and descriptions, and discussion bodies; the episodes passed in are left as they are
+func (r *Redactor) Episodes(episodes []cluster.Episode) []cluster.Episode {
+	if r == nil {
+		return episodes
+	}
+	redacted := make([]cluster.Episode, len(episodes))
+	for i, ep := range episodes {
+		ep.Commits = slices.Clone(ep.Commits)
+		for j := range ep.Commits {
+			commit := &ep.Commits[j]
+			commit.Message = r.Text(SourceCommitMessage, commit.Message)
+			// The subject and body repeat the message, so their redactions aren't counted again
+			commit.MessageSubject, _ = r.redact(commit.MessageSubject)
+			commit.MessageBody, _ = r.redact(commit.MessageBody)
+			commit.Diffs = slices.Clone(commit.Diffs)
+			for k := range commit.Diffs {
+				commit.Diffs[k].Patch = r.Text(SourceDiff, commit.Diffs[k].Patch)
+			}
+		}
+		ep.Artifacts = slices.Clone(ep.Artifacts)
+		for j := range ep.Artifacts {
+			artifact := &ep.Artifacts[j]
+			artifact.Title = r.Text(SourceArtifact, artifact.Title)
+			artifact.Description = r.Text(SourceArtifact, artifact.Description)
+			artifact.Discussions = slices.Clone(artifact.Discussions)
+			for k := range artifact.Discussions {
+				artifact.Discussions[k].Body = r.Text(SourceDiscussion, artifact.Discussions[k].Body)
+			}
+		}
+		redacted[i] = ep
+	}
+	return redacted
+}
+
+// Embedder wraps a cluster embedder so the commit texts it embeds, such as for semantic grouping,
+// are redacted before they are sent
+func (r *Redactor) Embedder(embedder cluster.Embedder) cluster.Embedder {
+	if r == nil {
+		return embedder
+	}
+	return redactingEmbedder{redactor: r, embedder: embedder}
+}
+
+// redactingEmbedder redacts texts before passing them on to its embedder
+type redactingEmbedder struct {
+	redactor *Redactor
+	embedder cluster.Embedder
+}
+
+// EmbedTexts redacts the texts and returns their embeddings in input order
+func (e redactingEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	redacted := make([]string, len(texts))
+	for i, text := range texts {
+		redacted[i] = e.redactor.Text(SourceCommitMessage, text)
+	}
+	return e.embedder.EmbedTexts(ctx, redacted)
+}
+
+// Report returns what the redactor redacted since it was created
+func (r *Redactor) Report() Report {
+	if r == nil {
+		return Report{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := Report{Redactions: r.report.Redactions, Allowed: r.report.Allowed, ByRule: make(map[string]int), BySource: make(map[string]int)}
+	for rule, n := range r.report.ByRule {
+		report.ByRule[rule] = n
+	}
+	for source, n := range r.report.BySource {
+		report.BySource[source] = n
+	}
+	return report
+}
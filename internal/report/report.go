@@ -0,0 +1,220 @@
+// Package report collects the per-item failures of a run that carries on past them, such as
+// artifacts that couldn't be converted or episodes whose narrative couldn't be generated, so
+// automation can decide whether a run's results are acceptable
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Stages of a run whose items are reported
+const (
+	StageRepositories   = "repositories"   // Repositories of an organization
+	StageSubmodules     = "submodules"     // Submodules of a superproject
+	StageArtifacts      = "artifacts"      // Issues, pull requests, tickets and releases
+	StageBuilds         = "builds"         // CI builds
+	StageIdentities     = "identities"     // Platform logins resolved to commit identities
+	StageGrouping       = "grouping"       // Semantic grouping of commits
+	StageClassification = "classification" // Episode categories
+	StageArcs           = "arcs"           // Story arcs of project narratives
+	StageNarratives     = "narratives"     // Episode narratives
+	StageCache          = "cache"          // Narrative cache writes
+	StageCheckpoints    = "checkpoints"    // Checkpoints of resumable runs
+)
+
+// Entry is one item of a stage that failed
+type Entry struct {
+	Stage string `json:"stage"`
+	Item  string `json:"item,omitempty"` // What failed, e.g. "pull request #12" or an episode ID
+	Error string `json:"error"`
+
+	// Skipped is set when the item was left out of the results, rather than included without
+	// the data that failed
+	Skipped bool `json:"skipped,omitempty"`
+
+	err error
+}
+
+// Counts tallies a stage's items by outcome
+type Counts struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// RunReport records the items of a run that succeeded, failed or were skipped, by stage
+// It is safe for concurrent use, and its methods do nothing on a nil report, so code that
+// reports into the report of its context needn't check whether there is one
+type RunReport struct {
+	mu      sync.Mutex
+	entries []Entry
+	counts  map[string]*Counts
+}
+
+// New creates an empty report
+func New() *RunReport {
+	return &RunReport{counts: make(map[string]*Counts)}
+}
+
+// Succeed counts n items of stage that succeeded
+func (r *RunReport) Succeed(stage string, n int) {
+	if r == nil || n <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage(stage).Succeeded += n
+}
+
+// Fail records an item of stage that failed but is still part of the results, e.g. a pull
+// request whose review threads couldn't be fetched
+func (r *RunReport) Fail(stage, item string, err error) {
+	r.add(Entry{Stage: stage, Item: item, err: err})
+}
+
+// Skip records an item of stage that was left out of the results because of err
+func (r *RunReport) Skip(stage, item string, err error) {
+	r.add(Entry{Stage: stage, Item: item, Skipped: true, err: err})
+}
+
+// add records a failed or skipped entry
+func (r *RunReport) add(entry Entry) {
+	if r == nil {
+		return
+	}
+	if entry.err == nil {
+		entry.err = errors.New("unknown error")
+	}
+	entry.Error = entry.err.Error()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	if entry.Skipped {
+		r.stage(entry.Stage).Skipped++
+	} else {
+		r.stage(entry.Stage).Failed++
+	}
+}
+
+// stage returns a stage's counts, adding them on first use
+func (r *RunReport) stage(stage string) *Counts {
+	counts, ok := r.counts[stage]
+	if !ok {
+		counts = &Counts{}
+		r.counts[stage] = counts
+	}
+	return counts
+}
+
+// Entries returns the failed and skipped items in the order they were recorded
+func (r *RunReport) Entries() []Entry {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.entries)
+}
+
+// Counts returns the counts of every stage with reported items
+func (r *RunReport) Counts() map[string]Counts {
+	counts := make(map[string]Counts)
+	if r == nil {
+		return counts
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for stage, c := range r.counts {
+		counts[stage] = *c
+	}
+	return counts
+}
+
+// Failed returns the number of failed and skipped items
+func (r *RunReport) Failed() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// OK reports whether every item succeeded
+func (r *RunReport) OK() bool {
+	return r.Failed() == 0
+}
+
+// Err joins the errors of the failed and skipped items, or returns nil when every item succeeded
+// Each error is prefixed with its stage and item and wraps the original, so errors.Is sees it
+func (r *RunReport) Err() error {
+	var errs []error
+	for _, entry := range r.Entries() {
+		if entry.Item == "" {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Stage, entry.err))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s: %w", entry.Stage, entry.Item, entry.err))
+	}
+	return errors.Join(errs...)
+}
+
+// MarshalJSON writes the report's counts by stage and its failed and skipped items
+func (r *RunReport) MarshalJSON() ([]byte, error) {
+	entries := r.Entries()
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return json.Marshal(struct {
+		OK      bool              `json:"ok"`
+		Counts  map[string]Counts `json:"counts"`
+		Entries []Entry           `json:"entries"`
+	}{
+		OK:      len(entries) == 0,
+		Counts:  r.Counts(),
+		Entries: entries,
+	})
+}
+
+// UnmarshalJSON reads a report written by MarshalJSON, e.g. from a job's result
+func (r *RunReport) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		Counts  map[string]Counts `json:"counts"`
+		Entries []Entry           `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts = make(map[string]*Counts, len(decoded.Counts))
+	for stage, counts := range decoded.Counts {
+		r.counts[stage] = &counts
+	}
+	r.entries = decoded.Entries
+	for i := range r.entries {
+		r.entries[i].err = errors.New(r.entries[i].Error)
+	}
+	return nil
+}
+
+// reportKey keys the report attached to a context
+type reportKey struct{}
+
+// With returns a context recording the items of the calls made with it in r
+func With(ctx context.Context, r *RunReport) context.Context {
+	return context.WithValue(ctx, reportKey{}, r)
+}
+
+// FromContext returns the report attached to ctx, or nil, whose methods do nothing
+func FromContext(ctx context.Context) *RunReport {
+	r, _ := ctx.Value(reportKey{}).(*RunReport)
+	return r
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunReport(t *testing.T) {
+	r := New()
+	if !r.OK() || r.Err() != nil {
+		t.Fatalf("Expected an empty report to be OK, got %v", r.Err())
+	}
+
+	notFound := errors.New("not found")
+	r.Succeed(StageArtifacts, 3)
+	r.Fail(StageArtifacts, "pull request #4 review threads", notFound)
+	r.Skip(StageNarratives, "E2", errors.New("rate limited"))
+	r.Fail(StageArcs, "", errors.New("no embedder"))
+
+	if r.OK() || r.Failed() != 3 {
+		t.Errorf("Expected 3 failed items, got %d", r.Failed())
+	}
+	counts := r.Counts()
+	if counts[StageArtifacts] != (Counts{Succeeded: 3, Failed: 1}) || counts[StageNarratives] != (Counts{Skipped: 1}) {
+		t.Errorf("Unexpected counts: %+v", counts)
+	}
+	entries := r.Entries()
+	if len(entries) != 3 || !entries[1].Skipped || entries[1].Item != "E2" || entries[1].Error != "rate limited" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	err := r.Err()
+	if !errors.Is(err, notFound) {
+		t.Errorf("Expected the joined error to wrap the original, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "artifacts: pull request #4 review threads: not found") || !strings.Contains(err.Error(), "arcs: no embedder") {
+		t.Errorf("Expected errors prefixed with stage and item, got %v", err)
+	}
+}
+
+func TestRunReport_JSON(t *testing.T) {
+	r := New()
+	r.Succeed(StageSubmodules, 1)
+	r.Skip(StageSubmodules, "vendor/lib", errors.New("clone failed"))
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"ok":false`) {
+		t.Errorf("Expected the report marked not OK, got %s", data)
+	}
+
+	decoded := New()
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Counts()[StageSubmodules] != (Counts{Succeeded: 1, Skipped: 1}) || decoded.Err() == nil {
+		t.Errorf("Expected the report round-tripped, got %+v", decoded.Counts())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	// Without a report, recording does nothing
+	missing := FromContext(context.Background())
+	missing.Skip(StageArtifacts, "issue #1", errors.New("bad"))
+	if !missing.OK() || missing.Entries() != nil {
+		t.Errorf("Expected a nil report to record nothing")
+	}
+
+	r := New()
+	FromContext(With(context.Background(), r)).Skip(StageArtifacts, "issue #1", errors.New("bad"))
+	if r.Failed() != 1 {
+		t.Errorf("Expected the context's report to record the item, got %d", r.Failed())
+	}
+}
@@ -0,0 +1,48 @@
+package state
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stage is how far a long pipeline run over a repository has got
+type Stage string
+
+// Stages a run is checkpointed after, in order
+const (
+	StageStarted   Stage = ""          // Nothing done yet
+	StageIngested  Stage = "ingested"  // Commits and artifacts ingested into an activity
+	StageClustered Stage = "clustered" // Activity grouped into episodes
+	StageIndexed   Stage = "indexed"   // Episodes indexed; narratives are checkpointed one by one from here
+)
+
+// stageOrder ranks the stages so checkpoints can be compared
+var stageOrder = map[Stage]int{StageStarted: 0, StageIngested: 1, StageClustered: 2, StageIndexed: 3}
+
+// Reached reports whether s is stage or a later one
+func (s Stage) Reached(stage Stage) bool {
+	return stageOrder[s] >= stageOrder[stage]
+}
+
+// Checkpoint is what a long pipeline run over a repository has completed, saved after each stage
+// so a run that crashed or was aborted can resume where it stopped instead of starting over
+type Checkpoint struct {
+	Repository string `json:"repository"`
+
+	// RunKey identifies the command and settings of the run, so only the same run resumes from it
+	RunKey string `json:"run_key"`
+
+	Stage Stage `json:"stage"`
+
+	// Activity is the ingested activity once StageIngested is reached, as JSON
+	Activity json.RawMessage `json:"activity,omitempty"`
+
+	// Episodes are the grouped episodes once StageClustered is reached, as written by
+	// cluster.MarshalEpisodes
+	Episodes json.RawMessage `json:"episodes,omitempty"`
+
+	// Narratives are the narratives generated so far, as JSON, by episode ID
+	Narratives map[string]json.RawMessage `json:"narratives,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
@@ -0,0 +1,328 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver
+)
+
+// SQLiteStore keeps repository state in a SQLite database, shared by every command and process
+// that opens the file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+const stateSchema = `
+CREATE TABLE IF NOT EXISTS repositories (
+	repository       TEXT PRIMARY KEY,
+	last_commit_hash TEXT NOT NULL DEFAULT '',
+	last_commit_time INTEGER NOT NULL DEFAULT 0,
+	synced_at        INTEGER NOT NULL DEFAULT 0,
+	episode_version  INTEGER NOT NULL DEFAULT 0,
+	episode_set_hash TEXT NOT NULL DEFAULT '',
+	analyzed_at      INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS indexed_episodes (
+	repository   TEXT NOT NULL,
+	episode_id   TEXT NOT NULL,
+	content_hash TEXT NOT NULL,
+	indexed_at   INTEGER NOT NULL,
+	PRIMARY KEY (repository, episode_id)
+);
+CREATE TABLE IF NOT EXISTS checkpoints (
+	repository TEXT PRIMARY KEY,
+	run_key    TEXT NOT NULL,
+	stage      TEXT NOT NULL,
+	activity   BLOB,
+	episodes   BLOB,
+	updated_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS checkpoint_narratives (
+	repository TEXT NOT NULL,
+	episode_id TEXT NOT NULL,
+	narrative  BLOB NOT NULL,
+	PRIMARY KEY (repository, episode_id)
+);
+`
+
+// NewSQLiteStore opens or creates the state database at path
+func NewSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("state store path is required")
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create state store directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	// A single connection serializes this process's writers; other processes wait on the busy timeout
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, "PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to configure state store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, stateSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create state store schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load returns a repository's state
+func (s *SQLiteStore) Load(ctx context.Context, repository string) (*RepositoryState, error) {
+	state, err := scanState(s.db.QueryRowContext(ctx, `
+		SELECT repository, last_commit_hash, last_commit_time, synced_at, episode_version, episode_set_hash, analyzed_at
+		FROM repositories WHERE repository = ?`, repository))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &RepositoryState{Repository: repository}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state of %s: %w", repository, err)
+	}
+	return state, nil
+}
+
+// Save writes a repository's analysis state
+func (s *SQLiteStore) Save(ctx context.Context, state *RepositoryState) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO repositories (repository, last_commit_hash, last_commit_time, synced_at, episode_version, episode_set_hash, analyzed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repository) DO UPDATE SET
+			last_commit_hash = excluded.last_commit_hash, last_commit_time = excluded.last_commit_time,
+			synced_at = excluded.synced_at, episode_version = excluded.episode_version,
+			episode_set_hash = excluded.episode_set_hash, analyzed_at = excluded.analyzed_at`,
+		state.Repository, state.LastCommitHash, unixNano(state.LastCommitTime), unixNano(state.SyncedAt),
+		state.EpisodeVersion, state.EpisodeSetHash, unixNano(state.AnalyzedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save state of %s: %w", state.Repository, err)
+	}
+	return nil
+}
+
+// Repositories returns the state of every repository saved
+func (s *SQLiteStore) Repositories(ctx context.Context) ([]RepositoryState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT repository, last_commit_hash, last_commit_time, synced_at, episode_version, episode_set_hash, analyzed_at
+		FROM repositories ORDER BY repository`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []RepositoryState
+	for rows.Next() {
+		state, err := scanState(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repository states: %w", err)
+		}
+		states = append(states, *state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list repository states: %w", err)
+	}
+	return states, nil
+}
+
+// IndexedHashes returns the content hash each of a repository's indexed episodes had
+func (s *SQLiteStore) IndexedHashes(ctx context.Context, repository string) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT episode_id, content_hash FROM indexed_episodes WHERE repository = ?", repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexed episodes of %s: %w", repository, err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			return nil, fmt.Errorf("failed to read indexed episodes of %s: %w", repository, err)
+		}
+		hashes[id] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read indexed episodes of %s: %w", repository, err)
+	}
+	return hashes, nil
+}
+
+// RecordIndexed records the content hashes of episodes just indexed, in one transaction
+func (s *SQLiteStore) RecordIndexed(ctx context.Context, repository string, hashes map[string]string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record indexed episodes: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixNano()
+	for id, hash := range hashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO indexed_episodes (repository, episode_id, content_hash, indexed_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT(repository, episode_id) DO UPDATE SET content_hash = excluded.content_hash, indexed_at = excluded.indexed_at`,
+			repository, id, hash, now); err != nil {
+			return fmt.Errorf("failed to record indexed episode %s: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record indexed episodes: %w", err)
+	}
+	return nil
+}
+
+// ForgetIndexed drops the hashes of episodes removed from the index
+func (s *SQLiteStore) ForgetIndexed(ctx context.Context, repository string, episodeIDs []string) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to forget indexed episodes: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range episodeIDs {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM indexed_episodes WHERE repository = ? AND episode_id = ?", repository, id); err != nil {
+			return fmt.Errorf("failed to forget indexed episode %s: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to forget indexed episodes: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint returns a repository's checkpoint with its narratives
+func (s *SQLiteStore) LoadCheckpoint(ctx context.Context, repository string) (*Checkpoint, error) {
+	checkpoint := Checkpoint{Repository: repository}
+	var stage string
+	var activity, episodes []byte
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx, "SELECT run_key, stage, activity, episodes, updated_at FROM checkpoints WHERE repository = ?", repository).
+		Scan(&checkpoint.RunKey, &stage, &activity, &episodes, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint of %s: %w", repository, err)
+	}
+	checkpoint.Stage, checkpoint.UpdatedAt = Stage(stage), fromUnixNano(updatedAt)
+	checkpoint.Activity, checkpoint.Episodes = activity, episodes
+
+	rows, err := s.db.QueryContext(ctx, "SELECT episode_id, narrative FROM checkpoint_narratives WHERE repository = ?", repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpointed narratives of %s: %w", repository, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var narrative []byte
+		if err := rows.Scan(&id, &narrative); err != nil {
+			return nil, fmt.Errorf("failed to read checkpointed narratives of %s: %w", repository, err)
+		}
+		if checkpoint.Narratives == nil {
+			checkpoint.Narratives = make(map[string]json.RawMessage)
+		}
+		checkpoint.Narratives[id] = narrative
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpointed narratives of %s: %w", repository, err)
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint writes a repository's checkpoint, except its narratives
+func (s *SQLiteStore) SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO checkpoints (repository, run_key, stage, activity, episodes, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repository) DO UPDATE SET
+			run_key = excluded.run_key, stage = excluded.stage, activity = excluded.activity,
+			episodes = excluded.episodes, updated_at = excluded.updated_at`,
+		checkpoint.Repository, checkpoint.RunKey, string(checkpoint.Stage), []byte(checkpoint.Activity),
+		[]byte(checkpoint.Episodes), unixNano(checkpoint.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint of %s: %w", checkpoint.Repository, err)
+	}
+	return nil
+}
+
+// RecordNarrative adds a narrative generated by a repository's checkpointed run
+func (s *SQLiteStore) RecordNarrative(ctx context.Context, repository, episodeID string, narrative json.RawMessage) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO checkpoint_narratives (repository, episode_id, narrative) VALUES (?, ?, ?)
+		ON CONFLICT(repository, episode_id) DO UPDATE SET narrative = excluded.narrative`,
+		repository, episodeID, []byte(narrative))
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint narrative %s: %w", episodeID, err)
+	}
+	return nil
+}
+
+// ClearCheckpoint drops a repository's checkpoint and its narratives, in one transaction
+func (s *SQLiteStore) ClearCheckpoint(ctx context.Context, repository string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to clear checkpoint of %s: %w", repository, err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM checkpoint_narratives WHERE repository = ?",
+		"DELETE FROM checkpoints WHERE repository = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, repository); err != nil {
+			return fmt.Errorf("failed to clear checkpoint of %s: %w", repository, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to clear checkpoint of %s: %w", repository, err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// scanState reads a repository's state from a row of the repositories table
+func scanState(row interface{ Scan(...any) error }) (*RepositoryState, error) {
+	var state RepositoryState
+	var lastCommitTime, syncedAt, analyzedAt int64
+	err := row.Scan(&state.Repository, &state.LastCommitHash, &lastCommitTime, &syncedAt,
+		&state.EpisodeVersion, &state.EpisodeSetHash, &analyzedAt)
+	if err != nil {
+		return nil, err
+	}
+	state.LastCommitTime, state.SyncedAt, state.AnalyzedAt = fromUnixNano(lastCommitTime), fromUnixNano(syncedAt), fromUnixNano(analyzedAt)
+	return &state, nil
+}
+
+// unixNano stores a time as nanoseconds since the epoch, the zero time as 0
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano reads a time stored by unixNano
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
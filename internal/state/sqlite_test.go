@@ -0,0 +1,120 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testStores returns an in-memory store and a SQLite store in a temporary directory
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	sqlite, err := NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "state.sqlite"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	return map[string]Store{"memory": NewMemoryStore(), "sqlite": sqlite}
+}
+
+func TestStores_RepositoryState(t *testing.T) {
+	ctx := context.Background()
+	committed := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			state, err := store.Load(ctx, "acme/app")
+			if err != nil || state.Repository != "acme/app" || state.LastCommitHash != "" {
+				t.Fatalf("Expected a new state for an unknown repository, got %+v (error %v)", state, err)
+			}
+
+			state.LastCommitHash, state.LastCommitTime, state.SyncedAt = "abc123", committed, committed.Add(time.Hour)
+			state.RecordEpisodes([]string{"E1"})
+			if err := store.Save(ctx, state); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			state.LastCommitHash = "def456"
+			if err := store.Save(ctx, state); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			store.Save(ctx, &RepositoryState{Repository: "acme/api"})
+
+			loaded, err := store.Load(ctx, "acme/app")
+			if err != nil || *loaded != *state {
+				t.Errorf("Expected %+v, got %+v (error %v)", state, loaded, err)
+			}
+			states, err := store.Repositories(ctx)
+			if err != nil || len(states) != 2 || states[0].Repository != "acme/api" || states[1].LastCommitHash != "def456" {
+				t.Errorf("Expected both repositories by name, got %+v (error %v)", states, err)
+			}
+		})
+	}
+}
+
+func TestStores_IndexedHashes(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.RecordIndexed(ctx, "acme/app", map[string]string{"E1": "h1", "E2": "h2"}); err != nil {
+				t.Fatalf("RecordIndexed failed: %v", err)
+			}
+			store.RecordIndexed(ctx, "acme/api", map[string]string{"E1": "other"})
+			store.RecordIndexed(ctx, "acme/app", map[string]string{"E2": "h2b"})
+			if err := store.ForgetIndexed(ctx, "acme/app", []string{"E1"}); err != nil {
+				t.Fatalf("ForgetIndexed failed: %v", err)
+			}
+
+			hashes, err := store.IndexedHashes(ctx, "acme/app")
+			if err != nil || len(hashes) != 1 || hashes["E2"] != "h2b" {
+				t.Errorf("Expected only E2 with its new hash, got %v (error %v)", hashes, err)
+			}
+			if hashes, _ := store.IndexedHashes(ctx, "acme/api"); hashes["E1"] != "other" {
+				t.Errorf("Expected other repositories untouched, got %v", hashes)
+			}
+		})
+	}
+}
+
+func TestStores_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+	updated := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if checkpoint, err := store.LoadCheckpoint(ctx, "acme/app"); err != nil || checkpoint != nil {
+				t.Fatalf("Expected no checkpoint for an unknown repository, got %+v (error %v)", checkpoint, err)
+			}
+
+			checkpoint := &Checkpoint{Repository: "acme/app", RunKey: "k1", Stage: StageIngested, Activity: []byte(`{"commits":[]}`), UpdatedAt: updated}
+			if err := store.SaveCheckpoint(ctx, checkpoint); err != nil {
+				t.Fatalf("SaveCheckpoint failed: %v", err)
+			}
+			// A clustered run keeps its episodes instead of its activity
+			checkpoint.Stage, checkpoint.Activity, checkpoint.Episodes = StageIndexed, nil, []byte(`{"episodes":[]}`)
+			if err := store.SaveCheckpoint(ctx, checkpoint); err != nil {
+				t.Fatalf("SaveCheckpoint failed: %v", err)
+			}
+			if err := store.RecordNarrative(ctx, "acme/app", "E1", []byte(`{"text":"One"}`)); err != nil {
+				t.Fatalf("RecordNarrative failed: %v", err)
+			}
+
+			loaded, err := store.LoadCheckpoint(ctx, "acme/app")
+			if err != nil || loaded == nil {
+				t.Fatalf("LoadCheckpoint failed: %v", err)
+			}
+			if loaded.RunKey != "k1" || loaded.Stage != StageIndexed || len(loaded.Activity) != 0 ||
+				string(loaded.Episodes) != `{"episodes":[]}` || !loaded.UpdatedAt.Equal(updated) {
+				t.Errorf("Expected the last checkpoint saved, got %+v", loaded)
+			}
+			if len(loaded.Narratives) != 1 || string(loaded.Narratives["E1"]) != `{"text":"One"}` {
+				t.Errorf("Expected the recorded narrative, got %v", loaded.Narratives)
+			}
+
+			if err := store.ClearCheckpoint(ctx, "acme/app"); err != nil {
+				t.Fatalf("ClearCheckpoint failed: %v", err)
+			}
+			if checkpoint, err := store.LoadCheckpoint(ctx, "acme/app"); err != nil || checkpoint != nil {
+				t.Errorf("Expected the checkpoint cleared, got %+v (error %v)", checkpoint, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// Package state records how far each repository has been analyzed and indexed: the last commit
+// ingested, when its platform artifacts were last synced, which version of its episode set is
+// current, and the content hash of every episode indexed. Incremental ingestion, re-clustering and
+// idempotent indexing read it to skip work already done.
+package state
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RepositoryState is what has been analyzed of a repository, keyed by the repository's path,
+// URL or index key
+type RepositoryState struct {
+	Repository     string    `json:"repository"`
+	LastCommitHash string    `json:"last_commit_hash,omitempty"` // Head when commits were last ingested
+	LastCommitTime time.Time `json:"last_commit_time,omitzero"`
+	SyncedAt       time.Time `json:"synced_at,omitzero"` // When issues and pull requests were last fetched from the platform
+
+	// EpisodeVersion counts the changes to the set of episodes analysis has produced, identified by
+	// EpisodeSetHash, so consumers can tell whether episodes they built on are still current
+	EpisodeVersion int    `json:"episode_version"`
+	EpisodeSetHash string `json:"episode_set_hash,omitempty"`
+
+	AnalyzedAt time.Time `json:"analyzed_at,omitzero"`
+}
+
+// RecordEpisodes records the IDs of the episodes analysis produced, bumping EpisodeVersion and
+// reporting true when they differ from the last set recorded
+func (s *RepositoryState) RecordEpisodes(ids []string) bool {
+	hash := EpisodeSetHash(ids)
+	if hash == s.EpisodeSetHash {
+		return false
+	}
+	s.EpisodeSetHash = hash
+	s.EpisodeVersion++
+	return true
+}
+
+// EpisodeSetHash identifies a set of episode IDs, whatever their order
+func EpisodeSetHash(ids []string) string {
+	sorted := slices.Sorted(slices.Values(ids))
+	h := sha256.New()
+	for _, id := range sorted {
+		h.Write([]byte(id))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Store keeps repository state between runs. Analysis state and indexed hashes are written
+// separately, so an analysis and an index of the same repository never overwrite each other.
+type Store interface {
+	// Load returns a repository's state, or a new state naming it if none was saved
+	Load(ctx context.Context, repository string) (*RepositoryState, error)
+
+	// Save writes a repository's analysis state
+	Save(ctx context.Context, state *RepositoryState) error
+
+	// Repositories returns the state of every repository saved, by repository
+	Repositories(ctx context.Context) ([]RepositoryState, error)
+
+	// IndexedHashes returns the content hash each of a repository's indexed episodes had, by ID
+	IndexedHashes(ctx context.Context, repository string) (map[string]string, error)
+
+	// RecordIndexed records the content hashes of episodes just indexed, by ID
+	RecordIndexed(ctx context.Context, repository string, hashes map[string]string) error
+
+	// ForgetIndexed drops the hashes of episodes removed from the index
+	ForgetIndexed(ctx context.Context, repository string, episodeIDs []string) error
+
+	// LoadCheckpoint returns a repository's checkpoint with its narratives, or nil if none was saved
+	LoadCheckpoint(ctx context.Context, repository string) (*Checkpoint, error)
+
+	// SaveCheckpoint writes a repository's checkpoint, except its narratives, which RecordNarrative
+	// adds one by one
+	SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error
+
+	// RecordNarrative adds a narrative generated by a repository's checkpointed run
+	RecordNarrative(ctx context.Context, repository, episodeID string, narrative json.RawMessage) error
+
+	// ClearCheckpoint drops a repository's checkpoint and its narratives, once its run has finished
+	ClearCheckpoint(ctx context.Context, repository string) error
+
+	// Close releases the store
+	Close() error
+}
+
+// PathEnv overrides where DefaultPath puts the state database
+const PathEnv = "THUNK_STATE_DB"
+
+// DefaultPath returns the state database: THUNK_STATE_DB if set, otherwise state.sqlite in the
+// user config directory
+func DefaultPath() (string, error) {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "thunk", "state.sqlite"), nil
+}
+
+// MemoryStore keeps repository state in memory, for runs that needn't remember it
+type MemoryStore struct {
+	mu          sync.Mutex
+	states      map[string]RepositoryState
+	indexed     map[string]map[string]string
+	checkpoints map[string]Checkpoint
+	narratives  map[string]map[string]json.RawMessage
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states:      make(map[string]RepositoryState),
+		indexed:     make(map[string]map[string]string),
+		checkpoints: make(map[string]Checkpoint),
+		narratives:  make(map[string]map[string]json.RawMessage),
+	}
+}
+
+// Load returns a repository's state
+func (s *MemoryStore) Load(ctx context.Context, repository string) (*RepositoryState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[repository]; ok {
+		return &state, nil
+	}
+	return &RepositoryState{Repository: repository}, nil
+}
+
+// Save writes a repository's analysis state
+func (s *MemoryStore) Save(ctx context.Context, state *RepositoryState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.Repository] = *state
+	return nil
+}
+
+// Repositories returns the state of every repository saved
+func (s *MemoryStore) Repositories(ctx context.Context) ([]RepositoryState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]RepositoryState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b RepositoryState) int {
+		return strings.Compare(a.Repository, b.Repository)
+	})
+	return states, nil
+}
+
+// IndexedHashes returns the content hash each of a repository's indexed episodes had
+func (s *MemoryStore) IndexedHashes(ctx context.Context, repository string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hashes := make(map[string]string, len(s.indexed[repository]))
+	for id, hash := range s.indexed[repository] {
+		hashes[id] = hash
+	}
+	return hashes, nil
+}
+
+// RecordIndexed records the content hashes of episodes just indexed
+func (s *MemoryStore) RecordIndexed(ctx context.Context, repository string, hashes map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.indexed[repository] == nil {
+		s.indexed[repository] = make(map[string]string)
+	}
+	for id, hash := range hashes {
+		s.indexed[repository][id] = hash
+	}
+	return nil
+}
+
+// ForgetIndexed drops the hashes of episodes removed from the index
+func (s *MemoryStore) ForgetIndexed(ctx context.Context, repository string, episodeIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range episodeIDs {
+		delete(s.indexed[repository], id)
+	}
+	return nil
+}
+
+// LoadCheckpoint returns a repository's checkpoint with its narratives
+func (s *MemoryStore) LoadCheckpoint(ctx context.Context, repository string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint, ok := s.checkpoints[repository]
+	if !ok {
+		return nil, nil
+	}
+	checkpoint.Narratives = maps.Clone(s.narratives[repository])
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint writes a repository's checkpoint, except its narratives
+func (s *MemoryStore) SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *checkpoint
+	saved.Narratives = nil
+	s.checkpoints[checkpoint.Repository] = saved
+	return nil
+}
+
+// RecordNarrative adds a narrative generated by a repository's checkpointed run
+func (s *MemoryStore) RecordNarrative(ctx context.Context, repository, episodeID string, narrative json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.narratives[repository] == nil {
+		s.narratives[repository] = make(map[string]json.RawMessage)
+	}
+	s.narratives[repository][episodeID] = narrative
+	return nil
+}
+
+// ClearCheckpoint drops a repository's checkpoint and its narratives
+func (s *MemoryStore) ClearCheckpoint(ctx context.Context, repository string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, repository)
+	delete(s.narratives, repository)
+	return nil
+}
+
+// Close does nothing; the state is dropped with the store
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package state
+
+import "testing"
+
+func TestRepositoryState_RecordEpisodes(t *testing.T) {
+	state := RepositoryState{Repository: "acme/app"}
+	if !state.RecordEpisodes([]string{"E1", "E2"}) || state.EpisodeVersion != 1 {
+		t.Fatalf("Expected the first episode set recorded as version 1, got %+v", state)
+	}
+	if state.RecordEpisodes([]string{"E2", "E1"}) || state.EpisodeVersion != 1 {
+		t.Errorf("Expected the same set in another order to keep the version, got %+v", state)
+	}
+	if !state.RecordEpisodes([]string{"E1", "E3"}) || state.EpisodeVersion != 2 {
+		t.Errorf("Expected a changed set to bump the version, got %+v", state)
+	}
+}
+
+func TestEpisodeSetHash(t *testing.T) {
+	if EpisodeSetHash([]string{"ab", "c"}) == EpisodeSetHash([]string{"a", "bc"}) {
+		t.Error("Expected IDs to be hashed separately, not concatenated")
+	}
+}
+
+func TestStage_Reached(t *testing.T) {
+	if !StageIndexed.Reached(StageClustered) || !StageClustered.Reached(StageClustered) {
+		t.Error("Expected a stage to reach itself and the stages before it")
+	}
+	if StageIngested.Reached(StageClustered) || StageStarted.Reached(StageIngested) {
+		t.Error("Expected a stage not to reach the stages after it")
+	}
+}